@@ -199,11 +199,16 @@ func executeBulkDelete(opts *BulkDeleteOptions) error {
 		}
 	}
 
-	// Step 6: Execute deletion
+	// Step 6: Order dependents before their dependencies and execute deletion
+	resources, steps := remediation.PlanDeletionOrder(resources)
 	if opts.DryRun {
-		fmt.Println("\n[DRY RUN] Would delete the following resources:")
-		for _, res := range resources {
-			fmt.Printf("  - %s (%s) in %s\n", res.Name, res.Type, res.Region)
+		fmt.Println("\n[DRY RUN] Would delete the following resources, in order:")
+		for _, step := range steps {
+			fmt.Printf("  %d. %s (%s) in %s", step.Order, step.Name, step.ResourceType, step.Region)
+			if len(step.DependsOn) > 0 {
+				fmt.Printf(" - before %s", strings.Join(step.DependsOn, ", "))
+			}
+			fmt.Println()
 		}
 		return nil
 	}
@@ -247,13 +252,7 @@ func discoverResourcesToDelete(ctx context.Context, opts *BulkDeleteOptions) ([]
 
 		// Filter by tags
 		if len(opts.Tags) > 0 {
-			// Type assert Tags to map[string]string
-			if resTags, ok := res.Tags.(map[string]string); ok {
-				if !matchesTags(resTags, opts.Tags) {
-					continue
-				}
-			} else {
-				// If Tags is not a map, skip this resource
+			if !matchesTags(res.Tags, opts.Tags) {
 				continue
 			}
 		}
@@ -350,13 +349,7 @@ func isProductionResource(res models.Resource) bool {
 	// Check tags for production indicators
 	prodIndicators := []string{"production", "prod", "live"}
 
-	// Type assert Tags to map[string]string
-	tags, ok := res.Tags.(map[string]string)
-	if !ok {
-		return false
-	}
-
-	for key, value := range tags {
+	for key, value := range res.Tags {
 		keyLower := strings.ToLower(key)
 		valueLower := strings.ToLower(value)
 
@@ -399,16 +392,11 @@ func isCriticalResource(res models.Resource) bool {
 	}
 
 	// Check tags for critical indicators
-	if res.Tags != nil {
-		// Type assert Tags to map[string]string
-		if tags, ok := res.Tags.(map[string]string); ok {
-			if critical, exists := tags["Critical"]; exists && strings.ToLower(critical) == "true" {
-				return true
-			}
-			if importance, exists := tags["Importance"]; exists && strings.ToLower(importance) == "critical" {
-				return true
-			}
-		}
+	if critical, exists := res.Tags["Critical"]; exists && strings.ToLower(critical) == "true" {
+		return true
+	}
+	if importance, exists := res.Tags["Importance"]; exists && strings.ToLower(importance) == "critical" {
+		return true
 	}
 
 	return false
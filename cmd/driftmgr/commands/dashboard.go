@@ -217,16 +217,7 @@ func HandleDashboard(args []string) {
 								status = s
 							}
 
-							tags := make(map[string]string)
-							if t, ok := r.Tags.(map[string]string); ok {
-								tags = t
-							} else if t, ok := r.Tags.(map[string]interface{}); ok {
-								for k, v := range t {
-									if str, ok := v.(string); ok {
-										tags[k] = str
-									}
-								}
-							}
+							tags := r.GetTagsAsMap()
 
 							/*
 							allDiscoveredResources = append(allDiscoveredResources, apimodels.Resource{
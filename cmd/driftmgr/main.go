@@ -23,6 +23,7 @@ import (
 	"github.com/catherinevee/driftmgr/internal/discovery"
 	"github.com/catherinevee/driftmgr/internal/discovery/backend"
 	"github.com/catherinevee/driftmgr/internal/drift/detector"
+	"github.com/catherinevee/driftmgr/internal/drift/junit"
 	"github.com/catherinevee/driftmgr/internal/graph"
 	"github.com/catherinevee/driftmgr/internal/providers"
 	"github.com/catherinevee/driftmgr/internal/remediation"
@@ -31,14 +32,16 @@ import (
 	parser "github.com/catherinevee/driftmgr/internal/state"
 	statelib "github.com/catherinevee/driftmgr/internal/state"
 	"github.com/catherinevee/driftmgr/internal/terragrunt/parser/hcl"
+	"github.com/catherinevee/driftmgr/internal/version"
 	types "github.com/catherinevee/driftmgr/pkg/models"
+	_ "github.com/mattn/go-sqlite3" // remediation history database
 )
 
 func main() {
 	// Handle version and help flags first
 	if len(os.Args) >= 2 {
 		if os.Args[1] == "--version" || os.Args[1] == "-v" {
-			fmt.Println("DriftMgr")
+			fmt.Println("DriftMgr " + version.Get().String())
 			os.Exit(0)
 		}
 		if os.Args[1] == "--help" || os.Args[1] == "-h" {
@@ -101,8 +104,9 @@ func main() {
 	case "web":
 		handleWeb(ctx, os.Args[2:])
 	case "version":
+		build := version.Get()
 		fmt.Println("DriftMgr - Terraform/Terragrunt State Management & Drift Detection")
-		fmt.Println("Build: Full Feature Release")
+		fmt.Printf("Version: %s\nCommit: %s\nBuilt: %s\nGo: %s\n", build.Version, build.Commit, build.BuildDate, build.GoVersion)
 	case "help", "--help", "-h":
 		printUsage()
 	default:
@@ -187,6 +191,7 @@ func handleDrift(ctx context.Context, args []string) {
 // handleDriftDetect performs drift detection
 func handleDriftDetect(ctx context.Context, args []string) {
 	var statePath, provider, region string
+	outputFormat, outputFile := "text", ""
 	var mode string = "smart" // Default to smart mode
 	var deepComparison bool   // For backward compatibility
 
@@ -218,6 +223,16 @@ func handleDriftDetect(ctx context.Context, args []string) {
 			mode = "deep" // Override mode if --deep is used
 		case "--quick":
 			mode = "quick"
+		case "--output":
+			if i+1 < len(args) {
+				outputFormat = args[i+1]
+				i++
+			}
+		case "--output-file":
+			if i+1 < len(args) {
+				outputFile = args[i+1]
+				i++
+			}
 		case "--help":
 			fmt.Println("Usage: driftmgr drift detect [options]")
 			fmt.Println("\nOptions:")
@@ -227,6 +242,8 @@ func handleDriftDetect(ctx context.Context, args []string) {
 			fmt.Println("  --mode <mode>      Detection mode: quick|deep|smart (default: smart)")
 			fmt.Println("  --quick            Use quick mode (resource existence only)")
 			fmt.Println("  --deep             Use deep mode (full attribute comparison)")
+			fmt.Println("  --output <format>  Also write a report: junit (default: text only)")
+			fmt.Println("  --output-file <path> Report path (default: drift-results.xml)")
 			fmt.Println("\nDetection Modes:")
 			fmt.Println("  quick: Fast scan checking only if resources exist")
 			fmt.Println("  deep:  Comprehensive scan comparing all attributes")
@@ -235,6 +252,11 @@ func handleDriftDetect(ctx context.Context, args []string) {
 		}
 	}
 
+	if outputFormat != "text" && outputFormat != "junit" {
+		fmt.Printf("Error: Unsupported output format %q. Use text or junit.\n", outputFormat)
+		return
+	}
+
 	// Auto-detect state file if not specified
 	if statePath == "" {
 		statePath = findStateFile()
@@ -358,6 +380,22 @@ func handleDriftDetect(ctx context.Context, args []string) {
 		output.Error("Significant drift detected - immediate action recommended")
 	}
 
+	// Every checked resource is a test case, so CI shows passing resources as well
+	if outputFormat == "junit" {
+		if outputFile == "" {
+			outputFile = "drift-results.xml"
+		}
+		report, err := junit.Render("driftmgr drift detect", driftResults, startTime, duration)
+		if err == nil {
+			err = os.WriteFile(outputFile, report, 0644)
+		}
+		if err != nil {
+			output.Error("Failed to write JUnit report: %v", err)
+		} else {
+			output.Info("JUnit report saved to: %s", outputFile)
+		}
+	}
+
 	// Save drift results for remediation
 	if driftedCount > 0 {
 		saveDriftResults(driftResults)
@@ -1,14 +1,20 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"log"
+	"os"
+	"os/signal"
 	"strconv"
+	"syscall"
 	"time"
 
 	"github.com/catherinevee/driftmgr/internal/api"
 	"github.com/catherinevee/driftmgr/internal/config"
+	"github.com/catherinevee/driftmgr/internal/version"
+	_ "github.com/mattn/go-sqlite3" // remediation history database
 )
 
 func main() {
@@ -30,7 +36,7 @@ func main() {
 	)
 	flag.Parse()
 
-	fmt.Printf("Starting DriftMgr Server\n")
+	fmt.Printf("Starting DriftMgr Server %s\n", version.Get())
 	fmt.Printf("Listening on %s:%s\n", *host, *port)
 
 	// Create server configuration
@@ -55,6 +61,10 @@ func main() {
 		apiConfig.Host = loadedConfig.Server.Host
 		apiConfig.Port = loadedConfig.Server.Port
 		apiConfig.AuthEnabled = loadedConfig.Server.AuthEnabled
+		apiConfig.ReadTimeout = loadedConfig.Server.ReadTimeout
+		apiConfig.WriteTimeout = loadedConfig.Server.WriteTimeout
+		apiConfig.IdleTimeout = loadedConfig.Server.IdleTimeout
+		apiConfig.LongRequestTimeout = loadedConfig.Server.LongRequestTimeout
 
 		log.Printf("Configuration loaded successfully")
 	}
@@ -80,6 +90,13 @@ func main() {
 	fmt.Printf("📊 Dashboard: http://localhost:%s/dashboard\n", *port)
 	fmt.Println("\nServer is running. Press Ctrl+C to stop.")
 
-	// Keep server running indefinitely
-	select {}
+	// Run until interrupted or terminated, then let running jobs finish before exiting
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
+	<-sigChan
+	fmt.Println("\nShutting down server...")
+	if err := apiServer.Stop(context.Background()); err != nil {
+		log.Fatalf("Server shutdown failed: %v", err)
+	}
+	fmt.Println("Server stopped")
 }
@@ -0,0 +1,299 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/catherinevee/driftmgr/internal/auth"
+	"github.com/catherinevee/driftmgr/internal/drift/detector"
+	"github.com/catherinevee/driftmgr/internal/remediation"
+)
+
+// SetApprovalGate sets the store held remediations are recorded in. When required is set,
+// every remediation that would change resources is held for approval; otherwise only
+// requests asking for approval are. notify is called for each new approval request.
+func (h *RemediationHandlers) SetApprovalGate(approvals *remediation.ApprovalStore, required bool, notify func(*remediation.Approval)) {
+	h.approvals = approvals
+	h.requireApproval = required
+	h.notifyApproval = notify
+}
+
+// approvalRequired reports whether a remediation must wait for approval
+func (h *RemediationHandlers) approvalRequired(requested bool) bool {
+	return requested || h.requireApproval
+}
+
+// requestApproval records a pending approval for the drifts instead of remediating them and
+// responds with 202 Accepted
+func (h *RemediationHandlers) requestApproval(w http.ResponseWriter, r *http.Request, driftIDs []string, workDir string, force bool) {
+	h.holdForApproval(w, r, func(ctx context.Context, user string) (*remediation.Approval, error) {
+		return h.approvals.Create(ctx, driftIDs, workDir, force, user)
+	})
+}
+
+// holdForApproval records a pending approval with create instead of running the change,
+// notifies approvers and responds with 202 Accepted
+func (h *RemediationHandlers) holdForApproval(w http.ResponseWriter, r *http.Request, create func(ctx context.Context, user string) (*remediation.Approval, error)) {
+	response := NewResponseWriter(w)
+	if h.approvals == nil {
+		// Failing closed: a remediation that needs approval never runs without one
+		response.WriteError(http.StatusServiceUnavailable, "SERVICE_UNAVAILABLE", "Remediation requires approval but approvals are not available", "")
+		return
+	}
+
+	approval, err := create(r.Context(), requestUser(r))
+	if err != nil {
+		response.WriteInternalError("Failed to request approval: " + err.Error())
+		return
+	}
+	if h.notifyApproval != nil {
+		h.notifyApproval(approval)
+	}
+
+	response.WriteJSON(http.StatusAccepted, NewSuccessResponse(approval, nil))
+}
+
+// ListApprovals handles GET /api/v1/remediate/approvals, filtered by ?status=
+func (h *RemediationHandlers) ListApprovals(w http.ResponseWriter, r *http.Request) {
+	SetCommonHeaders(w)
+	response := NewResponseWriter(w)
+	if h.approvals == nil {
+		response.WriteError(http.StatusServiceUnavailable, "SERVICE_UNAVAILABLE", "Remediation approvals are not available", "")
+		return
+	}
+
+	listed, err := h.approvals.List(r.Context(), remediation.ApprovalStatus(r.URL.Query().Get("status")))
+	if err != nil {
+		response.WriteInternalError("Failed to list approvals: " + err.Error())
+		return
+	}
+
+	// Only approvals for drift within the user's scopes are listed
+	approvals := make([]*remediation.Approval, 0, len(listed))
+	for _, approval := range listed {
+		if h.approvalInScope(r, approval) {
+			approvals = append(approvals, approval)
+		}
+	}
+	response.WriteSuccess(approvals, &APIMeta{
+		Count:     len(approvals),
+		Timestamp: time.Now().UTC().Format(time.RFC3339),
+	})
+}
+
+// approvalInScope reports whether the user's scopes cover every stored drift result an
+// approval would remediate
+func (h *RemediationHandlers) approvalInScope(r *http.Request, approval *remediation.Approval) bool {
+	for _, id := range approval.DriftIDs {
+		if drift, exists := h.driftStore.Get(id); exists {
+			if auth.CheckScope(r.Context(), drift.Provider, driftAccount(drift)) != nil {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// ApproveRemediation handles POST /api/v1/remediate/approve/{id}. The approver and time are
+// recorded, then the held remediation runs and its outcome is returned.
+func (h *RemediationHandlers) ApproveRemediation(w http.ResponseWriter, r *http.Request) {
+	SetCommonHeaders(w)
+	response := NewResponseWriter(w)
+	if h.approvals == nil {
+		response.WriteError(http.StatusServiceUnavailable, "SERVICE_UNAVAILABLE", "Remediation approvals are not available", "")
+		return
+	}
+
+	pending, err := h.approvals.Get(r.Context(), approvalID(r))
+	if err != nil {
+		writeApprovalError(response, err)
+		return
+	}
+	if pending.SnapshotID != "" {
+		h.approveRollback(w, r, pending)
+		return
+	}
+	// Approvals keep the working directory as requested, so it is confined again before running
+	workDir, ok := h.terraformWorkDir(w, pending.WorkDir, false)
+	if !ok {
+		return
+	}
+
+	drifts := make(map[string]*detector.DriftResult, len(pending.DriftIDs))
+	for _, id := range pending.DriftIDs {
+		if drift, exists := h.driftStore.Get(id); exists {
+			if !checkDriftScope(w, r, drift) {
+				return
+			}
+			drifts[id] = drift
+		}
+	}
+
+	approval, ok := h.decideApproval(w, r, pending.ID, remediation.ApprovalApproved)
+	if !ok {
+		return
+	}
+
+	batchResponse := BatchRemediationResponse{
+		JobID:       approval.ID,
+		TotalDrifts: len(approval.DriftIDs),
+		Results:     make([]RemediationResult, 0, len(approval.DriftIDs)),
+		Approval:    approval,
+		Timestamp:   time.Now().UTC(),
+	}
+	if err := h.runBatch(r.Context(), &batchResponse, approval.DriftIDs, drifts, workDir, approval.Force, approval); err != nil {
+		response.WriteInternalError(err.Error())
+		return
+	}
+
+	response.WriteSuccess(batchResponse, &APIMeta{
+		Count:     len(batchResponse.Results),
+		Timestamp: time.Now().UTC().Format(time.RFC3339),
+	})
+}
+
+// approveRollback records the approval of a held rollback, then rolls the snapshot's
+// resources back and returns the outcome
+func (h *RemediationHandlers) approveRollback(w http.ResponseWriter, r *http.Request, pending *remediation.Approval) {
+	snapshot, ok := h.loadSnapshot(w, r, pending.SnapshotID)
+	if !ok {
+		return
+	}
+	if !checkSnapshotScope(w, r, snapshot) {
+		return
+	}
+	approval, ok := h.decideApproval(w, r, pending.ID, remediation.ApprovalApproved)
+	if !ok {
+		return
+	}
+
+	response := NewResponseWriter(w)
+	result, err := h.rollback(r.Context(), snapshot)
+	if err != nil {
+		response.WriteInternalError("Failed to execute rollback: " + err.Error())
+		return
+	}
+	result.Approval = approval
+	response.WriteSuccess(result, nil)
+}
+
+// RejectRemediation handles POST /api/v1/remediate/reject/{id}; the held remediation never runs
+func (h *RemediationHandlers) RejectRemediation(w http.ResponseWriter, r *http.Request) {
+	SetCommonHeaders(w)
+	response := NewResponseWriter(w)
+	if h.approvals == nil {
+		response.WriteError(http.StatusServiceUnavailable, "SERVICE_UNAVAILABLE", "Remediation approvals are not available", "")
+		return
+	}
+
+	approval, ok := h.decideApproval(w, r, approvalID(r), remediation.ApprovalRejected)
+	if !ok {
+		return
+	}
+	log.Printf("Remediation approval %s rejected by %s", approval.ID, approval.DecidedBy)
+
+	response.WriteSuccess(approval, nil)
+}
+
+// decideApproval records the requesting user's decision on a pending approval with the reason
+// from the optional request body, writing the error response and returning false on failure
+func (h *RemediationHandlers) decideApproval(w http.ResponseWriter, r *http.Request, id string, status remediation.ApprovalStatus) (*remediation.Approval, bool) {
+	response := NewResponseWriter(w)
+	var decision ApprovalDecisionRequest
+	if r.ContentLength != 0 {
+		if err := decodeRequest(r, &decision); err != nil {
+			response.WriteRequestError(err)
+			return nil, false
+		}
+	}
+	approval, err := h.approvals.Decide(r.Context(), id, status, requestUser(r), decision.Reason)
+	if err != nil {
+		writeApprovalError(response, err)
+		return nil, false
+	}
+	return approval, true
+}
+
+// approvalID returns the approval ID from /api/v1/remediate/{approve,reject}/{id}
+func approvalID(r *http.Request) string {
+	return r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:]
+}
+
+// requestUser returns the authenticated username, or "anonymous" without authentication
+func requestUser(r *http.Request) string {
+	if username, ok := auth.GetUsernameFromContext(r.Context()); ok && username != "" {
+		return username
+	}
+	return "anonymous"
+}
+
+// requestHasPermission reports whether the authenticated user holds a permission. Without
+// authentication every request does, since no permissions are enforced.
+func requestHasPermission(r *http.Request, permission string) bool {
+	if _, authenticated := auth.GetRolesFromContext(r.Context()); !authenticated && auth.GetAuthType(r.Context()) != auth.AuthTypeServiceToken {
+		return true
+	}
+	return auth.HasPermission(r.Context(), permission)
+}
+
+// checkDriftScope responds with 403 Forbidden and returns false when the user's scopes do not
+// cover the provider and account of the drifted resource
+func checkDriftScope(w http.ResponseWriter, r *http.Request, drift *detector.DriftResult) bool {
+	if err := auth.CheckScope(r.Context(), drift.Provider, driftAccount(drift)); err != nil {
+		NewResponseWriter(w).WriteError(http.StatusForbidden, "FORBIDDEN", fmt.Sprintf("Cannot remediate %s: %v", drift.Resource, err), "")
+		return false
+	}
+	return true
+}
+
+// checkSnapshotScope responds with 403 Forbidden and returns false when the user's scopes do
+// not cover the provider and account of every resource captured in the snapshot
+func checkSnapshotScope(w http.ResponseWriter, r *http.Request, snapshot *remediation.Snapshot) bool {
+	for _, resource := range snapshot.Resources {
+		account, _ := resource.State["account_id"].(string)
+		if err := auth.CheckScope(r.Context(), resource.Provider, account); err != nil {
+			NewResponseWriter(w).WriteError(http.StatusForbidden, "FORBIDDEN", fmt.Sprintf("Cannot roll back %s: %v", resource.Resource, err), "")
+			return false
+		}
+	}
+	return true
+}
+
+// snapshotInScope reports whether the user's scopes cover the provider and account of every
+// resource captured in the snapshot
+func snapshotInScope(ctx context.Context, snapshot *remediation.Snapshot) bool {
+	for _, resource := range snapshot.Resources {
+		account, _ := resource.State["account_id"].(string)
+		if auth.CheckScope(ctx, resource.Provider, account) != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// driftAccount returns the cloud account a drifted resource belongs to, if its state records one
+func driftAccount(drift *detector.DriftResult) string {
+	for _, state := range []map[string]interface{}{drift.ActualState, drift.DesiredState} {
+		if account, ok := state["account_id"].(string); ok && account != "" {
+			return account
+		}
+	}
+	return ""
+}
+
+// writeApprovalError maps approval store errors to responses
+func writeApprovalError(response *ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, remediation.ErrApprovalNotFound):
+		response.WriteNotFound("Approval")
+	case errors.Is(err, remediation.ErrApprovalNotPending):
+		response.WriteConflict("Approval has already been decided")
+	default:
+		response.WriteInternalError(err.Error())
+	}
+}
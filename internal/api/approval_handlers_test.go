@@ -0,0 +1,114 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/catherinevee/driftmgr/internal/remediation"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// holdRemediation requests approval for drift-1, returning the pending approval and the
+// approvals passed to the notifier
+func holdRemediation(t *testing.T, h *RemediationHandlers) (*remediation.Approval, *[]*remediation.Approval) {
+	t.Helper()
+	var notified []*remediation.Approval
+	h.SetApprovalGate(h.approvals, false, func(approval *remediation.Approval) {
+		notified = append(notified, approval)
+	})
+
+	var approval remediation.Approval
+	w := serveJSON(t, h.Remediate, jsonRequest(t, "/api/v1/remediate", RemediationRequest{DriftID: "drift-1", RequireApproval: true}), &approval)
+	require.Equal(t, http.StatusAccepted, w.Code, w.Body.String())
+	return &approval, &notified
+}
+
+func TestRemediationHeldForApproval(t *testing.T) {
+	h, executor := newTestRemediationHandlers(t)
+
+	approval, notified := holdRemediation(t, h)
+	assert.Equal(t, remediation.ApprovalPending, approval.Status)
+	assert.Equal(t, []string{"drift-1"}, approval.DriftIDs)
+	assert.Equal(t, "anonymous", approval.RequestedBy)
+	require.Len(t, *notified, 1)
+	assert.Equal(t, approval.ID, (*notified)[0].ID)
+	assert.Empty(t, executor.executed(), "a held remediation does not run")
+}
+
+func TestApproveRemediationRunsIt(t *testing.T) {
+	h, executor := newTestRemediationHandlers(t)
+	approval, _ := holdRemediation(t, h)
+
+	req := asUser(jsonRequest(t, "/api/v1/remediate/approve/"+approval.ID, ApprovalDecisionRequest{Reason: "change window"}), "admin", true)
+	var batch BatchRemediationResponse
+	w := serveJSON(t, h.ApproveRemediation, req, &batch)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.NotNil(t, batch.Approval)
+	assert.Equal(t, remediation.ApprovalApproved, batch.Approval.Status)
+	assert.Equal(t, "admin", batch.Approval.DecidedBy)
+	assert.Equal(t, "change window", batch.Approval.Reason)
+	assert.Equal(t, []string{"i-123"}, executor.executed())
+
+	// An approval can only be decided once
+	req = asUser(jsonRequest(t, "/api/v1/remediate/approve/"+approval.ID, nil), "admin", true)
+	w = serveJSON(t, h.ApproveRemediation, req, nil)
+	assert.Equal(t, http.StatusConflict, w.Code, w.Body.String())
+	assert.Equal(t, []string{"i-123"}, executor.executed(), "a second approval does not run it again")
+}
+
+func TestRejectRemediation(t *testing.T) {
+	h, executor := newTestRemediationHandlers(t)
+	approval, _ := holdRemediation(t, h)
+
+	var rejected remediation.Approval
+	req := asUser(jsonRequest(t, "/api/v1/remediate/reject/"+approval.ID, ApprovalDecisionRequest{Reason: "not now"}), "admin", true)
+	w := serveJSON(t, h.RejectRemediation, req, &rejected)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, remediation.ApprovalRejected, rejected.Status)
+	assert.Equal(t, "not now", rejected.Reason)
+
+	req = asUser(jsonRequest(t, "/api/v1/remediate/approve/"+approval.ID, nil), "admin", true)
+	w = serveJSON(t, h.ApproveRemediation, req, nil)
+	assert.Equal(t, http.StatusConflict, w.Code, w.Body.String())
+	assert.Empty(t, executor.executed())
+}
+
+func TestApproveRemediationOutsideScope(t *testing.T) {
+	h, executor := newTestRemediationHandlers(t)
+	approval, _ := holdRemediation(t, h)
+
+	req := asUser(jsonRequest(t, "/api/v1/remediate/approve/"+approval.ID, nil), "jane", false, "aws:222222222222")
+	w := serveJSON(t, h.ApproveRemediation, req, nil)
+	assert.Equal(t, http.StatusForbidden, w.Code, w.Body.String())
+	assert.Empty(t, executor.executed())
+
+	pending, err := h.approvals.Get(req.Context(), approval.ID)
+	require.NoError(t, err)
+	assert.Equal(t, remediation.ApprovalPending, pending.Status, "a refused approver does not decide the approval")
+}
+
+func TestApproveUnknownRemediation(t *testing.T) {
+	h, _ := newTestRemediationHandlers(t)
+
+	w := serveJSON(t, h.ApproveRemediation, jsonRequest(t, "/api/v1/remediate/approve/missing", nil), nil)
+	assert.Equal(t, http.StatusNotFound, w.Code, w.Body.String())
+}
+
+func TestListApprovalsFiltersByScope(t *testing.T) {
+	h, _ := newTestRemediationHandlers(t)
+	approval, _ := holdRemediation(t, h)
+
+	var listed []*remediation.Approval
+	req := asUser(httptest.NewRequest(http.MethodGet, "/api/v1/remediate/approvals", nil), "jane", false, "aws:111111111111")
+	w := serveJSON(t, h.ListApprovals, req, &listed)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.Len(t, listed, 1)
+	assert.Equal(t, approval.ID, listed[0].ID)
+
+	req = asUser(httptest.NewRequest(http.MethodGet, "/api/v1/remediate/approvals", nil), "joe", false, "aws:222222222222")
+	w = serveJSON(t, h.ListApprovals, req, &listed)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Empty(t, listed, "approvals outside the user's scopes are not listed")
+}
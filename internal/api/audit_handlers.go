@@ -0,0 +1,203 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/catherinevee/driftmgr/internal/audit"
+)
+
+// maxAuditBodySize caps how much of a request body is kept as audit params
+const maxAuditBodySize = 64 << 10 // 64KB
+
+// Audit log page sizes
+const (
+	defaultAuditLimit = 100
+	maxAuditLimit     = 1000
+)
+
+// audited wraps a state-changing handler so every call is recorded in the audit log with
+// the caller, the request's parameters and the response status. It must run inside any
+// authentication middleware so the caller is known.
+func (s *Server) audited(action string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.services.Audit == nil {
+			next(w, r)
+			return
+		}
+
+		params := auditParams(r)
+		recorder := NewResponseWriter(w)
+		next(recorder, r)
+
+		entry := &audit.Entry{
+			Actor:   requestUser(r),
+			Action:  action,
+			Method:  r.Method,
+			Path:    r.URL.Path,
+			Target:  auditTarget(r, params),
+			Account: auditString(params, "account_id", "account"),
+			Params:  params,
+			Status:  recorder.StatusCode(),
+			Result:  audit.ResultSuccess,
+		}
+		if entry.Status >= http.StatusBadRequest {
+			entry.Result = audit.ResultFailure
+		}
+		// Record even when the client has gone away, since the operation may still have run
+		if err := s.services.Audit.Record(context.WithoutCancel(r.Context()), entry); err != nil {
+			log.Printf("Failed to record audit entry for %s by %s: %v", action, entry.Actor, err)
+		}
+	}
+}
+
+// handleAuditLog handles GET /api/v1/audit, filtered by actor, action, target, account,
+// result and start_date/end_date and paged with limit and offset
+func (s *Server) handleAuditLog(w http.ResponseWriter, r *http.Request) {
+	SetCommonHeaders(w)
+	response := NewResponseWriter(w)
+	if s.services.Audit == nil {
+		response.WriteError(http.StatusServiceUnavailable, "SERVICE_UNAVAILABLE", "Audit log is not available", "")
+		return
+	}
+
+	queryParams := ParseQueryParams(r)
+	filter := audit.Filter{
+		Actor:   queryParams["actor"],
+		Action:  queryParams["action"],
+		Target:  queryParams["target"],
+		Account: queryParams["account"],
+		Result:  queryParams["result"],
+	}
+	var err error
+	if filter.StartTime, err = parseHistoryDate(queryParams["start_date"], false); err != nil {
+		response.WriteValidationError("Invalid start_date", err.Error())
+		return
+	}
+	if filter.EndTime, err = parseHistoryDate(queryParams["end_date"], true); err != nil {
+		response.WriteValidationError("Invalid end_date", err.Error())
+		return
+	}
+	filter.Limit = defaultAuditLimit
+	if limit := queryParams["limit"]; limit != "" {
+		if filter.Limit, err = strconv.Atoi(limit); err != nil || filter.Limit < 1 || filter.Limit > maxAuditLimit {
+			response.WriteValidationError("Invalid limit", fmt.Sprintf("limit must be between 1 and %d", maxAuditLimit))
+			return
+		}
+	}
+	if offset := queryParams["offset"]; offset != "" {
+		if filter.Offset, err = strconv.Atoi(offset); err != nil || filter.Offset < 0 {
+			response.WriteValidationError("Invalid offset", "offset must be a non-negative integer")
+			return
+		}
+	}
+
+	total, err := s.services.Audit.Count(r.Context(), filter)
+	if err != nil {
+		response.WriteInternalError("Failed to count audit entries: " + err.Error())
+		return
+	}
+	entries, err := s.services.Audit.Query(r.Context(), filter)
+	if err != nil {
+		response.WriteInternalError("Failed to query audit log: " + err.Error())
+		return
+	}
+
+	w.Header().Set("X-Total-Count", strconv.Itoa(total))
+	response.WriteSuccess(AuditLogResponse{
+		Entries: entries,
+		Total:   total,
+		Limit:   filter.Limit,
+		Offset:  filter.Offset,
+	}, &APIMeta{
+		Timestamp: time.Now().UTC().Format(time.RFC3339),
+	})
+}
+
+// auditParams collects a request's query parameters and JSON body fields, leaving the body
+// readable by the handler. Credentials are redacted.
+func auditParams(r *http.Request) map[string]interface{} {
+	params := make(map[string]interface{})
+	for key, values := range r.URL.Query() {
+		if len(values) == 1 {
+			params[key] = values[0]
+		} else {
+			params[key] = values
+		}
+	}
+
+	if r.Body != nil && r.Body != http.NoBody {
+		data, err := io.ReadAll(io.LimitReader(r.Body, maxAuditBodySize+1))
+		// The handler reads the body as sent, including anything past the limit
+		r.Body = io.NopCloser(io.MultiReader(bytes.NewReader(data), r.Body))
+		switch {
+		case err != nil:
+		case len(data) > maxAuditBodySize:
+			params["body_truncated"] = true
+		default:
+			var body map[string]interface{}
+			if json.Unmarshal(data, &body) == nil {
+				for key, value := range body {
+					params[key] = value
+				}
+			}
+		}
+	}
+
+	redactAuditParams(params)
+	if len(params) == 0 {
+		return nil
+	}
+	return params
+}
+
+// redactAuditParams replaces credential values, at any depth, so they never reach the log
+func redactAuditParams(params map[string]interface{}) {
+	for key, value := range params {
+		lower := strings.ToLower(key)
+		if strings.Contains(lower, "password") || strings.Contains(lower, "secret") || strings.Contains(lower, "token") {
+			params[key] = "[REDACTED]"
+			continue
+		}
+		if nested, ok := value.(map[string]interface{}); ok {
+			redactAuditParams(nested)
+		}
+	}
+}
+
+// auditTarget returns what an operation acted on: an identifier from its parameters or,
+// for DELETE /.../{id} routes, the ID in the path
+func auditTarget(r *http.Request, params map[string]interface{}) string {
+	if target := auditString(params, "drift_id", "resource_id", "address", "id", "name"); target != "" {
+		return target
+	}
+	if ids, ok := params["drift_ids"].([]interface{}); ok && len(ids) > 0 {
+		targets := make([]string, 0, len(ids))
+		for _, id := range ids {
+			targets = append(targets, fmt.Sprint(id))
+		}
+		return strings.Join(targets, ",")
+	}
+	if r.Method == http.MethodDelete {
+		return r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:]
+	}
+	return ""
+}
+
+// auditString returns the first of the keys holding a non-empty string parameter
+func auditString(params map[string]interface{}, keys ...string) string {
+	for _, key := range keys {
+		if value, ok := params[key].(string); ok && value != "" {
+			return value
+		}
+	}
+	return ""
+}
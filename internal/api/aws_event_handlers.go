@@ -0,0 +1,100 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/catherinevee/driftmgr/internal/jobs"
+	awsprovider "github.com/catherinevee/driftmgr/internal/providers/aws"
+	"github.com/catherinevee/driftmgr/pkg/models"
+)
+
+const (
+	// awsEventJobType is the job type of re-checking the resources an AWS event changed
+	awsEventJobType = "aws_event"
+
+	// awsEventScope is the resource store scope re-discovered resources are saved under
+	awsEventScope = "aws-events"
+)
+
+// awsEventResource re-discovers one resource an AWS event changed
+var awsEventResource = func(ctx context.Context, region, resourceType, id string) (*models.Resource, error) {
+	return awsprovider.NewAWSProvider(region).GetResourceByType(ctx, resourceType, id)
+}
+
+// handleAWSEvent handles POST /api/v1/events/aws. It accepts CloudTrail API calls forwarded by
+// EventBridge, authenticated by the configured event token, and queues a job that re-discovers
+// the resources the call changed and checks them for drift.
+func (s *Server) handleAWSEvent(w http.ResponseWriter, r *http.Request) {
+	token := ""
+	if s.config != nil {
+		token = s.config.AWSEventToken
+	}
+	if !s.authorizeEvent(w, r, "AWS", token) {
+		return
+	}
+	data, ok := s.readEvent(w, r)
+	if !ok {
+		return
+	}
+
+	event, err := awsprovider.ParseCloudTrailEvent(data)
+	switch {
+	case errors.Is(err, awsprovider.ErrUnsupportedEvent):
+		// Acknowledged so EventBridge does not retry it
+		s.writeJSON(w, http.StatusOK, EventIgnoredResponse{Status: "ignored", Reason: err.Error()})
+		return
+	case err != nil:
+		s.writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	jobID := jobIDOrNew("")
+	owner := jobs.Owner{User: "eventbridge", Provider: "aws", Account: event.Account}
+	run := func(ctx context.Context) (interface{}, error) {
+		return s.recheckAWSResources(ctx, event)
+	}
+	if _, err := s.jobManager().Submit(context.Background(), jobID, awsEventJobType, owner, run); err != nil {
+		s.writeError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to queue event: %v", err))
+		return
+	}
+	s.writeJSON(w, http.StatusAccepted, JobAcceptedResponse{
+		JobID:     jobID,
+		Status:    jobs.StatusQueued,
+		StatusURL: "/api/v1/jobs/" + jobID,
+	})
+}
+
+// recheckAWSResources re-discovers the resources an event changed, saves them as the latest
+// discovery of the awsEventScope so they replace earlier copies, and checks them for drift
+func (s *Server) recheckAWSResources(ctx context.Context, event *awsprovider.ChangeEvent) (*AWSEventResult, error) {
+	checks := make([]eventCheck, 0, len(event.ResourceIDs))
+	for _, id := range event.ResourceIDs {
+		id := id
+		checks = append(checks, eventCheck{
+			ID:      id,
+			Account: event.Account,
+			Region:  event.Region,
+			Fetch: func(ctx context.Context) (*models.Resource, error) {
+				resource, err := awsEventResource(ctx, event.Region, event.ResourceType, id)
+				var notFound *awsprovider.NotFoundError
+				if errors.As(err, &notFound) {
+					return nil, errEventResourceDeleted
+				}
+				return resource, err
+			},
+		})
+	}
+	recheck, err := s.recheckEventResources(ctx, "aws", awsEventScope, checks, nil)
+	if recheck == nil {
+		return nil, err
+	}
+	return &AWSEventResult{
+		Event:         event,
+		Resources:     recheck.Resources,
+		DriftResultID: recheck.DriftResultID,
+		DriftCount:    recheck.DriftCount,
+	}, err
+}
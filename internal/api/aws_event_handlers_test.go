@@ -0,0 +1,99 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	awsprovider "github.com/catherinevee/driftmgr/internal/providers/aws"
+	"github.com/catherinevee/driftmgr/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// securityGroupEvent is an EventBridge notification of an ingress rule added to sg-event-1
+const securityGroupEvent = `{
+  "detail-type": "AWS API Call via CloudTrail",
+  "source": "aws.ec2",
+  "account": "123456789012",
+  "region": "eu-west-1",
+  "detail": {
+    "eventName": "AuthorizeSecurityGroupIngress",
+    "eventSource": "ec2.amazonaws.com",
+    "requestParameters": {"groupId": "sg-event-1"}
+  }
+}`
+
+func TestAWSEvent(t *testing.T) {
+	fetch := awsEventResource
+	t.Cleanup(func() { awsEventResource = fetch })
+	awsEventResource = func(ctx context.Context, region, resourceType, id string) (*models.Resource, error) {
+		assert.Equal(t, "eu-west-1", region)
+		assert.Equal(t, "aws_security_group", resourceType)
+		return &models.Resource{ID: id, Type: resourceType, Provider: "aws", Attributes: map[string]interface{}{"ingress": 2}}, nil
+	}
+
+	store := GetGlobalResourceStore()
+	store.Save("aws-event-test", []models.Resource{
+		{ID: "sg-event-1", Type: "aws_security_group", Provider: "aws", Attributes: map[string]interface{}{"ingress": 1}},
+	}, time.Now().Add(-time.Hour))
+	t.Cleanup(func() {
+		store.Save("aws-event-test", nil, time.Now())
+		store.Save(awsEventScope, nil, time.Now())
+	})
+
+	server := &Server{config: &Config{AWSEventToken: "secret"}}
+	post := func(token, body string) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(http.MethodPost, "/api/v1/events/aws", strings.NewReader(body))
+		request.Header.Set(eventTokenHeader, token)
+		w := httptest.NewRecorder()
+		server.handleAWSEvent(w, request)
+		return w
+	}
+
+	assert.Equal(t, http.StatusUnauthorized, post("wrong", securityGroupEvent).Code)
+	assert.Equal(t, http.StatusBadRequest, post("secret", `{"detail-type": "Scheduled Event", "source": "aws.events"}`).Code)
+
+	w := post("secret", strings.Replace(securityGroupEvent, `"eventSource"`, `"readOnly": true, "eventSource"`, 1))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Contains(t, w.Body.String(), `"ignored"`)
+
+	w = post("secret", securityGroupEvent)
+	require.Equal(t, http.StatusAccepted, w.Code, w.Body.String())
+	var accepted JobAcceptedResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &accepted))
+
+	job, err := server.jobManager().Wait(context.Background(), accepted.JobID)
+	require.NoError(t, err)
+	result, ok := job.Result.(*AWSEventResult)
+	require.True(t, ok, "job error: %s", job.Error)
+	assert.Equal(t, []EventResource{{ID: "sg-event-1", Status: "changed"}}, result.Resources)
+
+	last, ok := store.Last(awsEventScope)
+	require.True(t, ok)
+	require.Len(t, last.Resources, 1)
+	assert.Equal(t, "123456789012", last.Resources[0].AccountID, "the event's account is recorded")
+
+	awsEventResource = func(ctx context.Context, region, resourceType, id string) (*models.Resource, error) {
+		return nil, &awsprovider.NotFoundError{ResourceType: resourceType, ResourceID: id}
+	}
+	w = post("secret", securityGroupEvent)
+	require.Equal(t, http.StatusAccepted, w.Code, w.Body.String())
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &accepted))
+	job, err = server.jobManager().Wait(context.Background(), accepted.JobID)
+	require.NoError(t, err)
+	assert.Equal(t, []EventResource{{ID: "sg-event-1", Status: "deleted"}}, job.Result.(*AWSEventResult).Resources)
+	last, _ = store.Last(awsEventScope)
+	assert.Empty(t, last.Resources)
+}
+
+func TestAWSEventNotConfigured(t *testing.T) {
+	server := &Server{config: &Config{}}
+	w := httptest.NewRecorder()
+	server.handleAWSEvent(w, httptest.NewRequest(http.MethodPost, "/api/v1/events/aws", strings.NewReader(securityGroupEvent)))
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+}
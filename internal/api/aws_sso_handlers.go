@@ -0,0 +1,108 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	awsprovider "github.com/catherinevee/driftmgr/internal/providers/aws"
+)
+
+// AWSSSOLoginRequest is the optional body of POST /api/v1/providers/aws/sso/login
+type AWSSSOLoginRequest struct {
+	// Profile is the SSO profile to sign in, the one AWS_PROFILE selects when empty
+	Profile string `json:"profile"`
+}
+
+// awsSSOLogins holds the device authorizations waiting to be confirmed, by profile, so
+// repeated requests return the same code rather than starting another
+var awsSSOLogins = &ssoLoginTracker{logins: make(map[string]*awsprovider.SSOLogin)}
+
+// ssoLoginTracker holds device authorizations until they complete
+type ssoLoginTracker struct {
+	mu     sync.Mutex
+	logins map[string]*awsprovider.SSOLogin
+}
+
+// handleListAWSSSOProfiles handles GET /api/v1/providers/aws/sso, returning the profiles of
+// the server's shared AWS config that sign in through IAM Identity Center and whether each
+// needs to sign in again
+func (s *Server) handleListAWSSSOProfiles(w http.ResponseWriter, r *http.Request) {
+	profiles, err := awsprovider.SSOProfiles(r.Context())
+	if err != nil {
+		s.writeError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to read AWS profiles: %v", err))
+		return
+	}
+	s.writeJSON(w, http.StatusOK, map[string]interface{}{
+		"active_profile": awsprovider.ActiveProfile(),
+		"profiles":       profiles,
+	})
+}
+
+// handleAWSSSOLogin handles POST /api/v1/providers/aws/sso/login, starting the device
+// authorization flow for an SSO profile. The response gives the URL and code a user
+// confirms in their browser; the server then caches the sign-in where the SDK finds it,
+// and provider status is checked again.
+func (s *Server) handleAWSSSOLogin(w http.ResponseWriter, r *http.Request) {
+	var request AWSSSOLoginRequest
+	if err := decodeRequest(r, &request); err != nil && !errors.Is(err, io.EOF) {
+		s.writeRequestError(w, err)
+		return
+	}
+	if request.Profile == "" {
+		request.Profile = awsprovider.ActiveProfile()
+	}
+
+	profile, err := awsprovider.LoadSSOProfile(r.Context(), request.Profile)
+	switch {
+	case errors.Is(err, awsprovider.ErrNotSSOProfile):
+		s.writeError(w, http.StatusBadRequest, fmt.Sprintf("AWS profile %s does not sign in through IAM Identity Center", request.Profile))
+		return
+	case err != nil:
+		s.writeError(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	awsSSOLogins.mu.Lock()
+	defer awsSSOLogins.mu.Unlock()
+	if login, ok := awsSSOLogins.logins[profile.Name]; ok && time.Now().Before(login.ExpiresAt) {
+		s.writeJSON(w, http.StatusAccepted, login)
+		return
+	}
+
+	login, err := awsprovider.StartSSOLogin(r.Context(), *profile)
+	if err != nil {
+		s.writeError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to start AWS SSO sign-in: %v", err))
+		return
+	}
+	awsSSOLogins.logins[profile.Name] = login
+	go s.completeAWSSSOLogin(login)
+	s.writeJSON(w, http.StatusAccepted, login)
+}
+
+// completeAWSSSOLogin waits for a device authorization to be confirmed, then has provider
+// status checked again on the next request
+func (s *Server) completeAWSSSOLogin(login *awsprovider.SSOLogin) {
+	err := login.Wait(context.Background())
+
+	awsSSOLogins.mu.Lock()
+	if awsSSOLogins.logins[login.Profile] == login {
+		delete(awsSSOLogins.logins, login.Profile)
+	}
+	awsSSOLogins.mu.Unlock()
+
+	if err != nil {
+		log.Printf("AWS SSO sign-in failed: %v", err)
+		return
+	}
+	log.Printf("AWS SSO profile %s signed in", login.Profile)
+
+	providerStatuses.mu.Lock()
+	providerStatuses.expires = time.Time{}
+	providerStatuses.mu.Unlock()
+}
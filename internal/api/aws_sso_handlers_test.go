@@ -0,0 +1,59 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	awsprovider "github.com/catherinevee/driftmgr/internal/providers/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAWSSSOHandlers(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("AWS_PROFILE", "platform")
+	configFile := filepath.Join(home, "config")
+	require.NoError(t, os.WriteFile(configFile, []byte(`[profile platform]
+sso_session = corp
+sso_account_id = 111111111111
+sso_role_name = ReadOnly
+
+[profile keys]
+aws_access_key_id = AKIAEXAMPLE
+aws_secret_access_key = secret
+
+[sso-session corp]
+sso_start_url = https://corp.awsapps.com/start
+sso_region = us-east-2
+`), 0o600))
+	t.Setenv("AWS_CONFIG_FILE", configFile)
+	server := &Server{}
+
+	w := httptest.NewRecorder()
+	server.handleListAWSSSOProfiles(w, httptest.NewRequest(http.MethodGet, "/api/v1/providers/aws/sso", nil))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var response struct {
+		ActiveProfile string                   `json:"active_profile"`
+		Profiles      []awsprovider.SSOProfile `json:"profiles"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "platform", response.ActiveProfile)
+	require.Len(t, response.Profiles, 1)
+	assert.True(t, response.Profiles[0].LoginRequired, "a profile that never signed in needs a login")
+
+	for body, want := range map[string]int{
+		`{"profile": "keys"}`:    http.StatusBadRequest,
+		`{"profile": "missing"}`: http.StatusNotFound,
+		`{"profile": `:           http.StatusBadRequest,
+	} {
+		w = httptest.NewRecorder()
+		server.handleAWSSSOLogin(w, httptest.NewRequest(http.MethodPost, "/api/v1/providers/aws/sso/login", strings.NewReader(body)))
+		assert.Equal(t, want, w.Code, body)
+	}
+}
@@ -0,0 +1,124 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/catherinevee/driftmgr/internal/jobs"
+	azureprovider "github.com/catherinevee/driftmgr/internal/providers/azure"
+	"github.com/catherinevee/driftmgr/pkg/models"
+)
+
+const (
+	// azureEventJobType is the job type of re-checking the resources Azure events changed
+	azureEventJobType = "azure_event"
+
+	// azureEventScope is the resource store scope re-discovered resources are saved under
+	azureEventScope = "azure-events"
+)
+
+// azureEventResource re-discovers one resource an Azure event changed
+var azureEventResource = func(ctx context.Context, subscriptionID, resourceGroup, resourceType, id string) (*models.Resource, error) {
+	provider := azureprovider.NewAzureProviderComplete(subscriptionID, resourceGroup)
+	if err := provider.Connect(ctx); err != nil {
+		return nil, err
+	}
+	return provider.GetResourceByType(ctx, resourceType, id)
+}
+
+// handleAzureEvent handles POST /api/v1/events/azure. It accepts Event Grid resource events and
+// Activity Log alerts, authenticated by the configured event token, answers the Event Grid
+// subscription validation handshake, and queues a job that re-discovers the resources the
+// events changed and checks them for drift.
+func (s *Server) handleAzureEvent(w http.ResponseWriter, r *http.Request) {
+	token := ""
+	if s.config != nil {
+		token = s.config.AzureEventToken
+	}
+	if !s.authorizeEvent(w, r, "Azure", token) {
+		return
+	}
+	data, ok := s.readEvent(w, r)
+	if !ok {
+		return
+	}
+
+	notification, err := azureprovider.ParseNotification(data)
+	if err != nil {
+		s.writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if notification.ValidationCode != "" {
+		s.writeJSON(w, http.StatusOK, AzureEventValidationResponse{ValidationResponse: notification.ValidationCode})
+		return
+	}
+	if len(notification.Events) == 0 {
+		// Acknowledged so Event Grid does not retry it
+		reason := strings.Join(notification.Ignored, "; ")
+		if reason == "" {
+			reason = "no events"
+		}
+		s.writeJSON(w, http.StatusOK, EventIgnoredResponse{Status: "ignored", Reason: reason})
+		return
+	}
+
+	jobID := jobIDOrNew("")
+	owner := jobs.Owner{User: "eventgrid", Provider: "azure", Account: notification.Events[0].SubscriptionID}
+	run := func(ctx context.Context) (interface{}, error) {
+		return s.recheckAzureResources(ctx, notification)
+	}
+	if _, err := s.jobManager().Submit(context.Background(), jobID, azureEventJobType, owner, run); err != nil {
+		s.writeError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to queue event: %v", err))
+		return
+	}
+	s.writeJSON(w, http.StatusAccepted, JobAcceptedResponse{
+		JobID:     jobID,
+		Status:    jobs.StatusQueued,
+		StatusURL: "/api/v1/jobs/" + jobID,
+	})
+}
+
+// recheckAzureResources re-discovers the resources events changed, saves them as the latest
+// discovery of the azureEventScope so they replace earlier copies, and checks them for drift.
+// A resource changed by several events of a delivery is re-discovered once.
+func (s *Server) recheckAzureResources(ctx context.Context, notification *azureprovider.Notification) (*AzureEventResult, error) {
+	var checks []eventCheck
+	var deleted []string
+	seen := make(map[string]bool)
+	for _, event := range notification.Events {
+		event := event
+		if seen[event.ResourceID] {
+			continue
+		}
+		seen[event.ResourceID] = true
+		if event.Deleted {
+			deleted = append(deleted, event.ResourceID)
+			continue
+		}
+		checks = append(checks, eventCheck{
+			ID:      event.ResourceID,
+			Account: event.SubscriptionID,
+			Fetch: func(ctx context.Context) (*models.Resource, error) {
+				resource, err := azureEventResource(ctx, event.SubscriptionID, event.ResourceGroup, event.ResourceType, event.ResourceID)
+				if errors.Is(err, azureprovider.ErrNotFound) {
+					return nil, errEventResourceDeleted
+				}
+				return resource, err
+			},
+		})
+	}
+	recheck, err := s.recheckEventResources(ctx, "azure", azureEventScope, checks, deleted)
+	if recheck == nil {
+		return nil, err
+	}
+	return &AzureEventResult{
+		Events:        notification.Events,
+		Ignored:       notification.Ignored,
+		Resources:     recheck.Resources,
+		DriftResultID: recheck.DriftResultID,
+		DriftCount:    recheck.DriftCount,
+	}, err
+}
@@ -0,0 +1,90 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/catherinevee/driftmgr/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// virtualMachineEvents is an Event Grid delivery of a write to web-vm and the deletion of
+// old-vm
+const virtualMachineEvents = `[
+  {
+    "eventType": "Microsoft.Resources.ResourceWriteSuccess",
+    "data": {
+      "operationName": "Microsoft.Compute/virtualMachines/write",
+      "resourceUri": "/subscriptions/sub-1/resourceGroups/prod/providers/Microsoft.Compute/virtualMachines/web-vm"
+    }
+  },
+  {
+    "eventType": "Microsoft.Resources.ResourceDeleteSuccess",
+    "data": {
+      "operationName": "Microsoft.Compute/virtualMachines/delete",
+      "resourceUri": "/subscriptions/sub-1/resourceGroups/prod/providers/Microsoft.Compute/virtualMachines/old-vm"
+    }
+  }
+]`
+
+func TestAzureEvent(t *testing.T) {
+	fetch := azureEventResource
+	t.Cleanup(func() { azureEventResource = fetch })
+	azureEventResource = func(ctx context.Context, subscriptionID, resourceGroup, resourceType, id string) (*models.Resource, error) {
+		assert.Equal(t, "sub-1", subscriptionID)
+		assert.Equal(t, "prod", resourceGroup)
+		assert.Equal(t, "azurerm_virtual_machine", resourceType)
+		return &models.Resource{ID: id, Type: resourceType, Attributes: map[string]interface{}{"vm_size": "Standard_D4s_v5"}}, nil
+	}
+
+	store := GetGlobalResourceStore()
+	store.Save(azureEventScope, []models.Resource{
+		{ID: "old-vm", Type: "azurerm_virtual_machine", Provider: "azure"},
+	}, time.Now().Add(-time.Hour))
+	t.Cleanup(func() { store.Save(azureEventScope, nil, time.Now()) })
+
+	server := &Server{config: &Config{AzureEventToken: "secret"}}
+	post := func(target, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		server.handleAzureEvent(w, httptest.NewRequest(http.MethodPost, target, strings.NewReader(body)))
+		return w
+	}
+
+	assert.Equal(t, http.StatusUnauthorized, post("/api/v1/events/azure?token=wrong", virtualMachineEvents).Code)
+	assert.Equal(t, http.StatusBadRequest, post("/api/v1/events/azure?token=secret", `{"schemaId": "other"}`).Code)
+
+	w := post("/api/v1/events/azure?token=secret", `[{
+		"eventType": "Microsoft.EventGrid.SubscriptionValidationEvent",
+		"data": {"validationCode": "code-1"}
+	}]`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.JSONEq(t, `{"validationResponse": "code-1"}`, w.Body.String())
+
+	w = post("/api/v1/events/azure?token=secret", `[{"eventType": "Microsoft.Resources.ResourceWriteFailure"}]`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Contains(t, w.Body.String(), `"ignored"`)
+
+	w = post("/api/v1/events/azure?token=secret", virtualMachineEvents)
+	require.Equal(t, http.StatusAccepted, w.Code, w.Body.String())
+	var accepted JobAcceptedResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &accepted))
+
+	job, err := server.jobManager().Wait(context.Background(), accepted.JobID)
+	require.NoError(t, err)
+	result, ok := job.Result.(*AzureEventResult)
+	require.True(t, ok, "job error: %s", job.Error)
+	assert.Equal(t, []EventResource{{ID: "old-vm", Status: "deleted"}, {ID: "web-vm", Status: "new"}}, result.Resources)
+
+	last, ok := store.Last(azureEventScope)
+	require.True(t, ok)
+	require.Len(t, last.Resources, 1)
+	assert.Equal(t, "web-vm", last.Resources[0].ID)
+	assert.Equal(t, "azure", last.Resources[0].Provider)
+	assert.Equal(t, "sub-1", last.Resources[0].AccountID, "the event's subscription is recorded")
+}
@@ -1,18 +1,21 @@
 package api
 
 import (
-	"encoding/json"
+	"fmt"
 	"net/http"
 	"time"
 
+	"github.com/catherinevee/driftmgr/internal/auth"
 	"github.com/catherinevee/driftmgr/internal/models"
 	"github.com/catherinevee/driftmgr/internal/services"
+	"github.com/catherinevee/driftmgr/internal/websocket"
 	"github.com/google/uuid"
 )
 
 // BackendHandlers handles backend discovery API endpoints
 type BackendHandlers struct {
 	backendService *services.BackendService
+	progress       *websocket.Service
 }
 
 // NewBackendHandlers creates a new BackendHandlers instance
@@ -22,6 +25,11 @@ func NewBackendHandlers(backendService *services.BackendService) *BackendHandler
 	}
 }
 
+// SetProgressBroadcaster sets the WebSocket service used to publish discovery progress
+func (h *BackendHandlers) SetProgressBroadcaster(progress *websocket.Service) {
+	h.progress = progress
+}
+
 // ListBackends handles GET /api/v1/backends/list
 func (h *BackendHandlers) ListBackends(w http.ResponseWriter, r *http.Request) {
 	// Set common headers
@@ -114,9 +122,9 @@ func (h *BackendHandlers) DiscoverBackends(w http.ResponseWriter, r *http.Reques
 
 	// Parse request body
 	var req BackendDiscoveryRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+	if err := decodeRequest(r, &req); err != nil {
 		response := NewResponseWriter(w)
-		response.WriteValidationError("Invalid request body", err.Error())
+		response.WriteRequestError(err)
 		return
 	}
 
@@ -127,6 +135,14 @@ func (h *BackendHandlers) DiscoverBackends(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
+	jobID := jobIDOrNew(req.JobID)
+	if h.progress != nil {
+		update := websocket.NewProgressUpdate(jobID, "started", 0, len(req.Paths))
+		update.Message = "Scanning paths for Terraform backends"
+		update.User, _ = auth.GetUsernameFromContext(r.Context())
+		h.progress.BroadcastProgress(websocket.MessageTypeDiscoveryProgress, update)
+	}
+
 	// Simulate backend discovery process
 	// In a real implementation, this would scan the filesystem for Terraform configurations
 	discoveredBackends := []Backend{
@@ -162,11 +178,19 @@ func (h *BackendHandlers) DiscoverBackends(w http.ResponseWriter, r *http.Reques
 
 	// Create discovery response
 	discoveryResponse := BackendDiscoveryResponse{
+		JobID:    jobID,
 		Count:    len(discoveredBackends),
 		Backends: discoveredBackends,
 		Errors:   []string{}, // No errors in this simulation
 	}
 
+	if h.progress != nil {
+		update := websocket.NewProgressUpdate(jobID, "completed", len(req.Paths), len(req.Paths))
+		update.Message = fmt.Sprintf("Discovered %d backends", len(discoveredBackends))
+		update.User, _ = auth.GetUsernameFromContext(r.Context())
+		h.progress.BroadcastProgress(websocket.MessageTypeDiscoveryProgress, update)
+	}
+
 	response := NewResponseWriter(w)
 	err := response.WriteSuccess(discoveryResponse, &APIMeta{
 		Count:     len(discoveredBackends),
@@ -247,9 +271,9 @@ func (h *BackendHandlers) UpdateBackend(w http.ResponseWriter, r *http.Request)
 
 	// Parse request body
 	var updateRequest models.ProviderConfigurationUpdateRequest
-	if err := json.NewDecoder(r.Body).Decode(&updateRequest); err != nil {
+	if err := decodeRequest(r, &updateRequest); err != nil {
 		response := NewResponseWriter(w)
-		response.WriteValidationError("Invalid request body", err.Error())
+		response.WriteRequestError(err)
 		return
 	}
 
@@ -0,0 +1,216 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	backenddiscovery "github.com/catherinevee/driftmgr/internal/discovery/backend"
+	"github.com/catherinevee/driftmgr/internal/state/backups"
+)
+
+const (
+	// stateBackupDir is the directory in the data directory state backups are kept in
+	stateBackupDir = "backups"
+
+	// defaultStateBackupRetention is how many backups of each state are kept when the
+	// retention is not configured
+	defaultStateBackupRetention = 14
+)
+
+// StateBackupConfig controls scheduled backups of the states resolved under the repository
+// root. Backups are taken only when a schedule is set.
+type StateBackupConfig struct {
+	// Schedule is a cron expression or descriptor such as @daily or @every 6h
+	Schedule string `json:"schedule"`
+	// Retention is how many backups of each state are kept, 14 when zero
+	Retention int `json:"retention"`
+	// MaxAge removes backups older than it, though the newest backup of a state is always
+	// kept; backups are only removed by retention when zero
+	MaxAge time.Duration `json:"max_age"`
+}
+
+// StateRestoreRequest is the optional body of POST /api/v1/backups/{id}/restore
+type StateRestoreRequest struct {
+	// Force restores a backup whose lineage differs from the current state's, which
+	// replaces the state with an unrelated one
+	Force bool `json:"force"`
+}
+
+// StateRestoreResponse describes a restored backup
+type StateRestoreResponse struct {
+	Restored *backups.Backup `json:"restored"`
+	// Serial is the restored state's serial, one more than the replaced state's so Terraform
+	// treats it as newer
+	Serial int `json:"serial"`
+	// PreviousBackup is the backup of the state the restore replaced, which can be restored to
+	// undo it
+	PreviousBackup *backups.Backup `json:"previous_backup,omitempty"`
+}
+
+// initializeStateBackups opens the state backup store and, when a schedule is configured,
+// backs up every state resolved under the repository root on it
+func (s *Server) initializeStateBackups() {
+	s.services.Backups = backups.NewStore(filepath.Join(s.dataDir(), stateBackupDir))
+
+	cfg := s.config.StateBackup
+	if cfg.Schedule == "" {
+		return
+	}
+	switch {
+	case s.config.RepositoryRoot == "":
+		log.Printf("WARNING: scheduled state backups are disabled: no repository root is configured")
+	case s.services.Scheduler == nil:
+		log.Printf("WARNING: scheduled state backups are disabled: the scheduler is unavailable")
+	default:
+		if err := s.services.Scheduler.AddJob("state backups", cfg.Schedule, func(ctx context.Context) {
+			s.backupStates(ctx)
+		}); err != nil {
+			log.Printf("WARNING: scheduled state backups are disabled: %v", err)
+		}
+	}
+}
+
+// backupStates backs up every state resolved under the repository root and prunes the
+// backups beyond the configured retention. States that cannot be read are logged and skipped.
+func (s *Server) backupStates(ctx context.Context) {
+	root := s.config.RepositoryRoot
+	locations, err := backenddiscovery.ResolveStateLocations(root)
+	if err != nil {
+		log.Printf("State backup failed to scan %s: %v", root, err)
+		return
+	}
+
+	retention := s.config.StateBackup.Retention
+	if retention <= 0 {
+		retention = defaultStateBackupRetention
+	}
+	created, failed := 0, 0
+	for _, location := range locations {
+		if !location.Resolved() {
+			continue
+		}
+		backup, isNew, err := s.backupState(ctx, root, location)
+		if err != nil {
+			log.Printf("State backup of %s failed: %v", location.Directory, err)
+			failed++
+			continue
+		}
+		if isNew {
+			created++
+		}
+		if _, err := s.services.Backups.Prune(backup.StateKey, retention, s.config.StateBackup.MaxAge); err != nil {
+			log.Printf("Pruning state backups of %s failed: %v", location.Directory, err)
+		}
+	}
+	log.Printf("State backup completed: %d new backups, %d failures", created, failed)
+}
+
+// backupState backs up the current state of a location
+func (s *Server) backupState(ctx context.Context, root string, location backenddiscovery.StateLocation) (*backups.Backup, bool, error) {
+	data, err := backenddiscovery.LoadState(ctx, root, location)
+	if err != nil {
+		return nil, false, err
+	}
+	return s.services.Backups.Create(location, data)
+}
+
+// handleListBackups handles GET /api/v1/backups, returning every state backup, newest first
+func (s *Server) handleListBackups(w http.ResponseWriter, r *http.Request) {
+	if s.services == nil || s.services.Backups == nil {
+		s.writeError(w, http.StatusServiceUnavailable, "State backups not available")
+		return
+	}
+	list, err := s.services.Backups.List()
+	if err != nil {
+		s.writeError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to list backups: %v", err))
+		return
+	}
+	s.writeJSON(w, http.StatusOK, list)
+}
+
+// handleRestoreBackup handles POST /api/v1/backups/{id}/restore, writing a backup back to the
+// state it was taken of. The current state is backed up first so the restore can be undone,
+// and the restored state's serial is moved past the current one. A backup from another
+// lineage is refused unless forced.
+func (s *Server) handleRestoreBackup(w http.ResponseWriter, r *http.Request) {
+	var request StateRestoreRequest
+	if err := decodeRequest(r, &request); err != nil && !errors.Is(err, io.EOF) {
+		s.writeRequestError(w, err)
+		return
+	}
+	segments := strings.Split(r.URL.EscapedPath(), "/")
+	id, err := url.PathUnescape(segments[len(segments)-2])
+	if err != nil || id == "" {
+		s.writeError(w, http.StatusBadRequest, "Invalid backup ID")
+		return
+	}
+	if s.services == nil || s.services.Backups == nil || s.config == nil || s.config.RepositoryRoot == "" {
+		s.writeError(w, http.StatusServiceUnavailable, "State backups not available")
+		return
+	}
+
+	backup, data, err := s.services.Backups.Get(id)
+	switch {
+	case errors.Is(err, backups.ErrBackupNotFound):
+		s.writeError(w, http.StatusNotFound, fmt.Sprintf("Backup %s not found", id))
+		return
+	case err != nil:
+		s.writeError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to read backup: %v", err))
+		return
+	}
+
+	root := s.config.RepositoryRoot
+	response := StateRestoreResponse{Restored: backup, Serial: backup.Serial}
+	current, err := backenddiscovery.LoadState(r.Context(), root, backup.Location)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		// Nothing to replace, such as a local state that was deleted
+	case err != nil:
+		s.writeError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to read the current state: %v", err))
+		return
+	default:
+		previous, _, err := s.services.Backups.Create(backup.Location, current)
+		if err != nil {
+			s.writeError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to back up the current state: %v", err))
+			return
+		}
+		if previous.Lineage != backup.Lineage && !request.Force {
+			s.writeError(w, http.StatusConflict, fmt.Sprintf("Backup lineage %s does not match the current state's lineage %s; set force to restore it anyway", backup.Lineage, previous.Lineage))
+			return
+		}
+		response.PreviousBackup = previous
+		response.Serial = max(backup.Serial, previous.Serial+1)
+	}
+
+	restored, err := withStateSerial(data, response.Serial)
+	if err != nil {
+		s.writeError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to prepare the restored state: %v", err))
+		return
+	}
+	if err := backenddiscovery.WriteState(r.Context(), root, backup.Location, restored); err != nil {
+		s.writeError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to write the restored state: %v", err))
+		return
+	}
+	s.writeJSON(w, http.StatusOK, response)
+}
+
+// withStateSerial returns a state document with its serial replaced, keeping every other
+// field as it was
+func withStateSerial(data []byte, serial int) ([]byte, error) {
+	var document map[string]json.RawMessage
+	if err := json.Unmarshal(data, &document); err != nil {
+		return nil, err
+	}
+	document["serial"] = json.RawMessage(fmt.Sprint(serial))
+	return json.MarshalIndent(document, "", "  ")
+}
@@ -0,0 +1,76 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/catherinevee/driftmgr/internal/state/backups"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStateBackupAndRestore(t *testing.T) {
+	root := t.TempDir()
+	statePath := filepath.Join(root, "infra", "terraform.tfstate")
+	require.NoError(t, os.MkdirAll(filepath.Dir(statePath), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "infra", "main.tf"), []byte(`resource "aws_vpc" "main" {}`), 0o644))
+	require.NoError(t, os.WriteFile(statePath, []byte(`{"version": 4, "serial": 3, "lineage": "4f2a", "resources": [{"type": "aws_vpc"}]}`), 0o644))
+
+	server := &Server{config: &Config{RepositoryRoot: root, DataDir: t.TempDir()}, services: &Services{}}
+	server.initializeStateBackups()
+	server.backupStates(context.Background())
+
+	list := func() []*backups.Backup {
+		w := httptest.NewRecorder()
+		server.handleListBackups(w, httptest.NewRequest(http.MethodGet, "/api/v1/backups", nil))
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var list []*backups.Backup
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &list))
+		return list
+	}
+	backedUp := list()
+	require.Len(t, backedUp, 1)
+	assert.Equal(t, 3, backedUp[0].Serial)
+
+	// The resources are lost, as after an accidental terraform state rm
+	require.NoError(t, os.WriteFile(statePath, []byte(`{"version": 4, "serial": 7, "lineage": "4f2a", "resources": []}`), 0o644))
+
+	w := httptest.NewRecorder()
+	server.handleRestoreBackup(w, httptest.NewRequest(http.MethodPost, "/api/v1/backups/"+backedUp[0].ID+"/restore", nil))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var response StateRestoreResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, 8, response.Serial, "the restored state is newer than the one it replaced")
+	require.NotNil(t, response.PreviousBackup)
+	assert.Equal(t, 7, response.PreviousBackup.Serial)
+
+	var restored struct {
+		Serial    int               `json:"serial"`
+		Resources []json.RawMessage `json:"resources"`
+	}
+	data, err := os.ReadFile(statePath)
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(data, &restored))
+	assert.Equal(t, 8, restored.Serial)
+	assert.Len(t, restored.Resources, 1)
+	assert.Len(t, list(), 2, "the replaced state was backed up")
+
+	// A backup of an unrelated state is refused unless forced
+	require.NoError(t, os.WriteFile(statePath, []byte(`{"version": 4, "serial": 1, "lineage": "9c1b", "resources": []}`), 0o644))
+	w = httptest.NewRecorder()
+	server.handleRestoreBackup(w, httptest.NewRequest(http.MethodPost, "/api/v1/backups/"+backedUp[0].ID+"/restore", nil))
+	assert.Equal(t, http.StatusConflict, w.Code)
+	w = httptest.NewRecorder()
+	server.handleRestoreBackup(w, httptest.NewRequest(http.MethodPost, "/api/v1/backups/"+backedUp[0].ID+"/restore", strings.NewReader(`{"force": true}`)))
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	w = httptest.NewRecorder()
+	server.handleRestoreBackup(w, httptest.NewRequest(http.MethodPost, "/api/v1/backups/0123456789abcdef-1/restore", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
@@ -0,0 +1,111 @@
+package api
+
+import (
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/catherinevee/driftmgr/internal/websocket"
+)
+
+// corsOriginsEnv names the environment variable holding a comma-separated list of origins
+// allowed to call the API from a browser when the config lists none
+const corsOriginsEnv = "DRIFTMGR_CORS_ALLOWED_ORIGINS"
+
+var (
+	// defaultCORSMethods and defaultCORSHeaders are allowed when the config lists none
+	defaultCORSMethods = []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"}
+	defaultCORSHeaders = []string{"Content-Type", "Authorization", "X-API-Key"}
+)
+
+// CORSConfig controls which browser origins may call the API, such as a dashboard served
+// from another host behind the same ingress. With no allowed origins only same-origin
+// browser requests work, since no CORS headers are sent.
+type CORSConfig struct {
+	// AllowedOrigins lists origins such as https://drift.example.com; "*" allows any origin
+	// but never with credentials. When empty, DRIFTMGR_CORS_ALLOWED_ORIGINS is used.
+	AllowedOrigins []string `json:"allowed_origins"`
+	// AllowedMethods and AllowedHeaders answer preflight requests; GET, POST, PUT, DELETE and
+	// OPTIONS, and Content-Type, Authorization and X-API-Key when empty
+	AllowedMethods []string `json:"allowed_methods"`
+	AllowedHeaders []string `json:"allowed_headers"`
+	// AllowCredentials lets browsers send cookies and authorization headers cross-origin
+	AllowCredentials bool `json:"allow_credentials"`
+	// MaxAge is how many seconds browsers may cache a preflight response; 0 leaves it to them
+	MaxAge int `json:"max_age"`
+}
+
+// corsConfig returns the server's CORS settings, with the allowed origins falling back to
+// DRIFTMGR_CORS_ALLOWED_ORIGINS
+func (s *Server) corsConfig() CORSConfig {
+	var cors CORSConfig
+	if s.config != nil {
+		cors = s.config.CORS
+	}
+	if len(cors.AllowedOrigins) == 0 {
+		cors.AllowedOrigins = websocket.ParseAllowedOrigins(os.Getenv(corsOriginsEnv))
+	}
+	return cors
+}
+
+// handleCORS sets the CORS headers for requests from allowed origins and answers preflight
+// requests itself, reporting whether the request should go on to its route
+func (s *Server) handleCORS(w http.ResponseWriter, r *http.Request) bool {
+	cors := s.corsConfig()
+	origin := r.Header.Get("Origin")
+	allowed, wildcard := corsOriginAllowed(cors.AllowedOrigins, origin)
+	w.Header().Add("Vary", "Origin")
+	switch {
+	case !allowed:
+	case !wildcard:
+		w.Header().Set("Access-Control-Allow-Origin", origin)
+		if cors.AllowCredentials {
+			w.Header().Set("Access-Control-Allow-Credentials", "true")
+		}
+	case cors.AllowCredentials:
+		// Credentials are never shared with any origin, so "*" does not cover them
+		allowed = false
+	default:
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+	}
+
+	if r.Method != http.MethodOptions || r.Header.Get("Access-Control-Request-Method") == "" {
+		return true
+	}
+	if allowed {
+		methods, headers := cors.AllowedMethods, cors.AllowedHeaders
+		if len(methods) == 0 {
+			methods = defaultCORSMethods
+		}
+		if len(headers) == 0 {
+			headers = defaultCORSHeaders
+		}
+		w.Header().Set("Access-Control-Allow-Methods", strings.Join(methods, ", "))
+		w.Header().Set("Access-Control-Allow-Headers", strings.Join(headers, ", "))
+		if cors.MaxAge > 0 {
+			w.Header().Set("Access-Control-Max-Age", strconv.Itoa(cors.MaxAge))
+		}
+	}
+	w.WriteHeader(http.StatusNoContent)
+	return false
+}
+
+// corsOriginAllowed reports whether origin is in the allowed list, and whether only a "*"
+// entry allowed it
+func corsOriginAllowed(allowedOrigins []string, origin string) (allowed, wildcard bool) {
+	if origin == "" {
+		return false, false
+	}
+	origin = strings.TrimSuffix(strings.ToLower(origin), "/")
+	for _, candidate := range allowedOrigins {
+		if candidate == "*" {
+			wildcard = true
+			continue
+		}
+		if strings.TrimSuffix(strings.ToLower(strings.TrimSpace(candidate)), "/") == origin {
+			return true, false
+		}
+	}
+	return wildcard, wildcard
+}
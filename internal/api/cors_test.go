@@ -0,0 +1,53 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandleCORS(t *testing.T) {
+	preflight := func(s *Server, origin string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodOptions, "/api/v1/discover", nil)
+		req.Header.Set("Origin", origin)
+		req.Header.Set("Access-Control-Request-Method", "POST")
+		w := httptest.NewRecorder()
+		assert.False(t, s.handleCORS(w, req))
+		return w
+	}
+
+	s := &Server{config: &Config{CORS: CORSConfig{
+		AllowedOrigins:   []string{"https://dashboard.example.com/"},
+		AllowedHeaders:   []string{"Authorization"},
+		AllowCredentials: true,
+		MaxAge:           600,
+	}}}
+	w := preflight(s, "https://Dashboard.example.com")
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, "https://Dashboard.example.com", w.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "true", w.Header().Get("Access-Control-Allow-Credentials"))
+	assert.Equal(t, "GET, POST, PUT, DELETE, OPTIONS", w.Header().Get("Access-Control-Allow-Methods"))
+	assert.Equal(t, "Authorization", w.Header().Get("Access-Control-Allow-Headers"))
+	assert.Equal(t, "600", w.Header().Get("Access-Control-Max-Age"))
+
+	w = preflight(s, "https://evil.example.com")
+	assert.Empty(t, w.Header().Get("Access-Control-Allow-Origin"))
+	assert.Empty(t, w.Header().Get("Access-Control-Allow-Methods"))
+
+	s.config.CORS.AllowedOrigins = []string{"*"}
+	w = preflight(s, "https://evil.example.com")
+	assert.Empty(t, w.Header().Get("Access-Control-Allow-Origin"), "a wildcard never covers credentials")
+	s.config.CORS.AllowCredentials = false
+	w = preflight(s, "https://evil.example.com")
+	assert.Equal(t, "*", w.Header().Get("Access-Control-Allow-Origin"))
+
+	t.Setenv(corsOriginsEnv, "https://ui.example.com")
+	s.config.CORS.AllowedOrigins = nil
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/discover", nil)
+	req.Header.Set("Origin", "https://ui.example.com")
+	w = httptest.NewRecorder()
+	assert.True(t, s.handleCORS(w, req), "simple requests go on to their route")
+	assert.Equal(t, "https://ui.example.com", w.Header().Get("Access-Control-Allow-Origin"))
+}
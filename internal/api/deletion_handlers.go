@@ -0,0 +1,101 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/catherinevee/driftmgr/internal/auth"
+	"github.com/catherinevee/driftmgr/internal/remediation"
+)
+
+// deletionProviders creates the cloud providers account deletions run against. They load
+// credentials when created, so each is created on first use.
+var deletionProviders = map[string]func() (remediation.CloudProvider, error){
+	"aws":   func() (remediation.CloudProvider, error) { return remediation.NewAWSProvider() },
+	"azure": func() (remediation.CloudProvider, error) { return remediation.NewAzureProvider() },
+	"gcp":   func() (remediation.CloudProvider, error) { return remediation.NewGCPProvider() },
+}
+
+// handlePreviewDeletion handles POST /api/v1/deletion/preview, returning the resources a
+// deletion would remove in order, the protected resources it would skip, and the
+// confirmation token needed to carry it out
+func (s *Server) handlePreviewDeletion(w http.ResponseWriter, r *http.Request) {
+	request, engine, ok := s.deletionRequest(w, r)
+	if !ok {
+		return
+	}
+
+	preview, err := engine.PreviewDeletion(r.Context(), request.Provider, request.AccountID, deletionOptions(request))
+	if err != nil {
+		s.writeError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to preview deletion: %v", err))
+		return
+	}
+	s.writeJSON(w, http.StatusOK, preview)
+}
+
+// handleDeleteAccountResources handles POST /api/v1/deletion, deleting the resources of a
+// preview whose confirmation token is passed back. Protected resources are always skipped.
+func (s *Server) handleDeleteAccountResources(w http.ResponseWriter, r *http.Request) {
+	request, engine, ok := s.deletionRequest(w, r)
+	if !ok {
+		return
+	}
+
+	result, err := engine.DeleteAccountResources(r.Context(), request.Provider, request.AccountID, deletionOptions(request))
+	switch {
+	case errors.Is(err, remediation.ErrConfirmationRequired):
+		s.writeError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, remediation.ErrConfirmationMismatch), errors.Is(err, remediation.ErrConfirmationExpired):
+		s.writeError(w, http.StatusConflict, err.Error())
+	case err != nil:
+		s.writeError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to delete resources: %v", err))
+	default:
+		s.writeJSON(w, http.StatusOK, result)
+	}
+}
+
+// deletionRequest decodes and authorizes a deletion request and returns the engine with its
+// provider registered, writing the error response itself when it cannot
+func (s *Server) deletionRequest(w http.ResponseWriter, r *http.Request) (DeletionRequest, *remediation.DeletionEngine, bool) {
+	var request DeletionRequest
+	if err := decodeRequest(r, &request); err != nil {
+		s.writeRequestError(w, err)
+		return request, nil, false
+	}
+	if err := auth.CheckScope(r.Context(), request.Provider, request.AccountID); err != nil {
+		s.writeError(w, http.StatusForbidden, err.Error())
+		return request, nil, false
+	}
+
+	if s.services == nil || s.services.Deletion == nil {
+		s.writeError(w, http.StatusServiceUnavailable, "Resource deletion is not available")
+		return request, nil, false
+	}
+	engine := s.services.Deletion
+	create, ok := deletionProviders[request.Provider]
+	if !ok {
+		if _, registered := engine.GetProvider(request.Provider); !registered {
+			s.writeError(w, http.StatusBadRequest, fmt.Sprintf("Unsupported provider %q", request.Provider))
+			return request, nil, false
+		}
+		return request, engine, true
+	}
+	if _, err := engine.GetOrRegisterProvider(request.Provider, create); err != nil {
+		s.writeError(w, http.StatusServiceUnavailable, fmt.Sprintf("Failed to connect to %s: %v", request.Provider, err))
+		return request, nil, false
+	}
+	return request, engine, true
+}
+
+// deletionOptions converts a deletion request to engine options
+func deletionOptions(request DeletionRequest) remediation.DeletionOptions {
+	return remediation.DeletionOptions{
+		Force:             request.Force,
+		ResourceTypes:     request.ResourceTypes,
+		Regions:           request.Regions,
+		ExcludeResources:  request.ExcludeResources,
+		IncludeResources:  request.IncludeResources,
+		ConfirmationToken: request.ConfirmationToken,
+	}
+}
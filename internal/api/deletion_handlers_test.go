@@ -0,0 +1,91 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/catherinevee/driftmgr/internal/remediation"
+	"github.com/catherinevee/driftmgr/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubDeletionProvider lists fixed resources and records the ones deleted
+type stubDeletionProvider struct {
+	resources []models.Resource
+	deleted   []string
+}
+
+func (p *stubDeletionProvider) DeleteResources(ctx context.Context, accountID string, options remediation.DeletionOptions) (*remediation.DeletionResult, error) {
+	return nil, errors.New("not used")
+}
+
+func (p *stubDeletionProvider) DeleteResource(ctx context.Context, resource models.Resource) error {
+	p.deleted = append(p.deleted, resource.ID)
+	return nil
+}
+
+func (p *stubDeletionProvider) ListResources(ctx context.Context, accountID string) ([]models.Resource, error) {
+	return p.resources, nil
+}
+
+func (p *stubDeletionProvider) ValidateCredentials(ctx context.Context, accountID string) error {
+	return nil
+}
+
+func newTestDeletionServer() (*Server, *stubDeletionProvider) {
+	provider := &stubDeletionProvider{resources: []models.Resource{
+		{ID: "i-1", Type: "ec2_instance", Provider: "aws"},
+		{ID: "i-2", Type: "ec2_instance", Provider: "aws", Tags: map[string]string{"driftmgr:protect": "true"}},
+	}}
+	engine := remediation.NewDeletionEngine()
+	engine.RegisterProvider("aws", provider)
+	return &Server{services: &Services{Deletion: engine}}, provider
+}
+
+func TestDeletionRequiresPreviewToken(t *testing.T) {
+	server, provider := newTestDeletionServer()
+	request := DeletionRequest{Provider: "aws", AccountID: "111111111111", Force: true}
+
+	w := httptest.NewRecorder()
+	server.handleDeleteAccountResources(w, jsonRequest(t, "/api/v1/deletion", request))
+	assert.Equal(t, http.StatusBadRequest, w.Code, w.Body.String())
+
+	request.ConfirmationToken = "1.forged"
+	w = httptest.NewRecorder()
+	server.handleDeleteAccountResources(w, jsonRequest(t, "/api/v1/deletion", request))
+	assert.Equal(t, http.StatusConflict, w.Code, w.Body.String())
+	assert.Empty(t, provider.deleted)
+
+	var preview remediation.DeletionPreview
+	w = httptest.NewRecorder()
+	server.handlePreviewDeletion(w, jsonRequest(t, "/api/v1/deletion/preview", request))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &preview))
+	require.NotEmpty(t, preview.ConfirmationToken)
+	assert.Len(t, preview.Steps, 1)
+	assert.Len(t, preview.Protected, 1)
+
+	request.ConfirmationToken = preview.ConfirmationToken
+	w = httptest.NewRecorder()
+	server.handleDeleteAccountResources(w, jsonRequest(t, "/api/v1/deletion", request))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, []string{"i-1"}, provider.deleted)
+}
+
+func TestDeletionChecksScope(t *testing.T) {
+	server, provider := newTestDeletionServer()
+	request := DeletionRequest{Provider: "aws", AccountID: "111111111111"}
+
+	for _, handler := range []http.HandlerFunc{server.handlePreviewDeletion, server.handleDeleteAccountResources} {
+		req := asUser(jsonRequest(t, "/api/v1/deletion", request), "jane", false, "aws:222222222222")
+		w := httptest.NewRecorder()
+		handler(w, req)
+		assert.Equal(t, http.StatusForbidden, w.Code, w.Body.String())
+	}
+	assert.Empty(t, provider.deleted)
+}
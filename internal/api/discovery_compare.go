@@ -0,0 +1,172 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/catherinevee/driftmgr/internal/auth"
+	"github.com/catherinevee/driftmgr/pkg/models"
+)
+
+// DiscoveryComparison is the response of GET /api/v1/discover/compare: the resources added,
+// removed and changed in the cloud between two points in time, grouped by provider and type
+type DiscoveryComparison struct {
+	From      time.Time             `json:"from"`
+	To        time.Time             `json:"to"`
+	Summary   ResourceChangeCounts  `json:"summary"`
+	Groups    []ResourceChangeGroup `json:"groups"`
+	Snapshots []ComparedSnapshots   `json:"snapshots"`
+}
+
+// ResourceChangeCounts counts the resources added, removed and changed
+type ResourceChangeCounts struct {
+	Added   int `json:"added"`
+	Removed int `json:"removed"`
+	Changed int `json:"changed"`
+}
+
+// ResourceChangeGroup lists the changes to one provider's resources of one type
+type ResourceChangeGroup struct {
+	Provider string               `json:"provider"`
+	Type     string               `json:"type"`
+	Counts   ResourceChangeCounts `json:"counts"`
+	ResourceDelta
+}
+
+// ComparedSnapshots names the discovery snapshots a scope was compared between, which
+// are the latest taken at or before the requested times
+type ComparedSnapshots struct {
+	Scope string    `json:"scope"`
+	From  time.Time `json:"from"`
+	To    time.Time `json:"to"`
+}
+
+// handleCompareDiscoveries handles GET /api/v1/discover/compare, diffing the discovery
+// snapshots recorded at or before from and to (RFC 3339; to defaults to now) without
+// reference to any Terraform state. Each discovery scope with a snapshot at from is compared
+// with its own snapshot at to, so scopes first discovered in between are not reported as
+// added. provider limits the comparison to one provider's resources, and only resources in
+// accounts the user's scopes cover are returned.
+func (s *Server) handleCompareDiscoveries(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	from, err := time.Parse(time.RFC3339, query.Get("from"))
+	if err != nil {
+		s.writeError(w, http.StatusBadRequest, fmt.Sprintf("Invalid from %q: must be an RFC 3339 time", query.Get("from")))
+		return
+	}
+	to := time.Now()
+	if value := query.Get("to"); value != "" {
+		if to, err = time.Parse(time.RFC3339, value); err != nil {
+			s.writeError(w, http.StatusBadRequest, fmt.Sprintf("Invalid to %q: must be an RFC 3339 time", value))
+			return
+		}
+	}
+	if !from.Before(to) {
+		s.writeError(w, http.StatusBadRequest, "from must be before to")
+		return
+	}
+
+	provider := query.Get("provider")
+	allow := func(resource models.Resource) bool {
+		if provider != "" && resource.Provider != provider {
+			return false
+		}
+		return auth.CheckScope(r.Context(), resource.Provider, resource.AccountID) == nil
+	}
+
+	comparison := compareDiscoveries(GetGlobalResourceStore(), from, to, allow)
+	if len(comparison.Snapshots) == 0 {
+		s.writeError(w, http.StatusNotFound, fmt.Sprintf("No discovery snapshots were recorded at or before %s", from.Format(time.RFC3339)))
+		return
+	}
+	s.writeJSON(w, http.StatusOK, comparison)
+}
+
+// compareDiscoveries diffs every scope's snapshot at from against its snapshot at to,
+// keeping the resources allow accepts. A resource seen in several scopes is reported once.
+func compareDiscoveries(store *ResourceStore, from, to time.Time, allow func(models.Resource) bool) *DiscoveryComparison {
+	comparison := &DiscoveryComparison{
+		From:      from,
+		To:        to,
+		Groups:    []ResourceChangeGroup{},
+		Snapshots: []ComparedSnapshots{},
+	}
+
+	groups := make(map[string]*ResourceChangeGroup)
+	seen := make(map[string]bool)
+	record := func(resources []models.Resource, change string) {
+		for _, resource := range resources {
+			key := change + "/" + resource.Provider + "/" + resource.ID
+			if seen[key] || !allow(resource) {
+				continue
+			}
+			seen[key] = true
+
+			groupKey := resource.Provider + "/" + resource.Type
+			group, ok := groups[groupKey]
+			if !ok {
+				group = &ResourceChangeGroup{
+					Provider: resource.Provider,
+					Type:     resource.Type,
+					ResourceDelta: ResourceDelta{
+						Added:   []models.Resource{},
+						Removed: []models.Resource{},
+						Changed: []models.Resource{},
+					},
+				}
+				groups[groupKey] = group
+			}
+			switch change {
+			case "added":
+				group.Added = append(group.Added, resource)
+				group.Counts.Added++
+				comparison.Summary.Added++
+			case "removed":
+				group.Removed = append(group.Removed, resource)
+				group.Counts.Removed++
+				comparison.Summary.Removed++
+			case "changed":
+				group.Changed = append(group.Changed, resource)
+				group.Counts.Changed++
+				comparison.Summary.Changed++
+			}
+		}
+	}
+
+	for _, scope := range store.Scopes() {
+		before, ok := store.At(scope, from)
+		if !ok {
+			continue
+		}
+		after, _ := store.At(scope, to)
+		comparison.Snapshots = append(comparison.Snapshots, ComparedSnapshots{
+			Scope: scope,
+			From:  before.DiscoveredAt,
+			To:    after.DiscoveredAt,
+		})
+		if after == before {
+			continue
+		}
+
+		delta := DiffResources(before.Resources, after.Resources)
+		record(delta.Added, "added")
+		record(delta.Removed, "removed")
+		record(delta.Changed, "changed")
+	}
+
+	for _, group := range groups {
+		for _, resources := range [][]models.Resource{group.Added, group.Removed, group.Changed} {
+			sort.Slice(resources, func(i, j int) bool { return resources[i].ID < resources[j].ID })
+		}
+		comparison.Groups = append(comparison.Groups, *group)
+	}
+	sort.Slice(comparison.Groups, func(i, j int) bool {
+		if comparison.Groups[i].Provider != comparison.Groups[j].Provider {
+			return comparison.Groups[i].Provider < comparison.Groups[j].Provider
+		}
+		return comparison.Groups[i].Type < comparison.Groups[j].Type
+	})
+	return comparison
+}
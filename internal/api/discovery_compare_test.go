@@ -0,0 +1,63 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/catherinevee/driftmgr/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompareDiscoveries(t *testing.T) {
+	store := &ResourceStore{snapshots: make(map[string][]*ResourceSnapshot)}
+	now := time.Now()
+	small := map[string]interface{}{"instance_type": "t3.micro"}
+	large := map[string]interface{}{"instance_type": "t3.large"}
+
+	store.Save("discovery:aws:us-east-1:", []models.Resource{
+		{ID: "i-1", Provider: "aws", Type: "aws_instance", Attributes: small},
+		{ID: "bucket-1", Provider: "aws", Type: "aws_s3_bucket"},
+	}, now.Add(-24*time.Hour))
+	store.Save("discovery:aws:us-east-1:", []models.Resource{
+		{ID: "i-1", Provider: "aws", Type: "aws_instance", Attributes: large},
+		{ID: "i-2", Provider: "aws", Type: "aws_instance"},
+	}, now.Add(-time.Hour))
+	// The fast scan of the same region sees the same new instance
+	store.Save("discovery:aws:us-east-1::fast", []models.Resource{}, now.Add(-20*time.Hour))
+	store.Save("discovery:aws:us-east-1::fast", []models.Resource{{ID: "i-2", Provider: "aws", Type: "aws_instance"}}, now.Add(-time.Hour))
+	// A scope first discovered after from is not compared
+	store.Save("discovery:azure:eastus:", []models.Resource{{ID: "vm-1", Provider: "azure", Type: "azurerm_virtual_machine"}}, now.Add(-2*time.Hour))
+
+	all := func(models.Resource) bool { return true }
+	comparison := compareDiscoveries(store, now.Add(-12*time.Hour), now, all)
+
+	assert.Equal(t, ResourceChangeCounts{Added: 1, Removed: 1, Changed: 1}, comparison.Summary)
+	require.Len(t, comparison.Groups, 2)
+	instances := comparison.Groups[0]
+	assert.Equal(t, "aws_instance", instances.Type)
+	assert.Equal(t, ResourceChangeCounts{Added: 1, Changed: 1}, instances.Counts)
+	assert.Equal(t, "i-2", instances.Added[0].ID)
+	assert.Equal(t, "i-1", instances.Changed[0].ID)
+	assert.Equal(t, "bucket-1", comparison.Groups[1].Removed[0].ID)
+	assert.Len(t, comparison.Snapshots, 2)
+
+	// Comparing two times between the same snapshots finds nothing
+	comparison = compareDiscoveries(store, now.Add(-30*time.Minute), now, all)
+	assert.Empty(t, comparison.Groups)
+}
+
+func TestHandleCompareDiscoveriesValidation(t *testing.T) {
+	server := &Server{}
+	for _, query := range []string{
+		"",
+		"from=yesterday",
+		"from=2026-01-02T00:00:00Z&to=2026-01-01T00:00:00Z",
+	} {
+		w := httptest.NewRecorder()
+		server.handleCompareDiscoveries(w, httptest.NewRequest(http.MethodGet, "/api/v1/discover/compare?"+query, nil))
+		assert.Equal(t, http.StatusBadRequest, w.Code, query)
+	}
+}
@@ -0,0 +1,174 @@
+package api
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/catherinevee/driftmgr/pkg/models"
+)
+
+// resourceLimit collects discovered resources up to a cap. Once the cap is reached further
+// resources are dropped, and the regions and types that were not collected are recorded so
+// the response can say what is missing.
+type resourceLimit struct {
+	max            int
+	resources      []models.Resource
+	keptRegions    map[string]bool
+	droppedRegions map[string]bool
+	droppedTypes   map[string]bool
+	truncated      bool
+
+	// timeout is the discovery timeout that expired, and timedOutRegions the regions it left
+	// unscanned
+	timeout         time.Duration
+	timedOutRegions map[string]bool
+
+	// failures are the services and regions that could not be discovered
+	failures []models.DiscoveryError
+
+	// disabledRegions are the regions skipped because they are not enabled for the account
+	disabledRegions map[string]bool
+}
+
+// newResourceLimit creates a collector for at most max resources; max <= 0 disables the cap
+func newResourceLimit(max int) *resourceLimit {
+	return &resourceLimit{
+		max:            max,
+		keptRegions:    make(map[string]bool),
+		droppedRegions: make(map[string]bool),
+		droppedTypes:   make(map[string]bool),
+
+		timedOutRegions: make(map[string]bool),
+		disabledRegions: make(map[string]bool),
+	}
+}
+
+// Full reports whether the cap has been reached
+func (l *resourceLimit) Full() bool {
+	return l.max > 0 && len(l.resources) >= l.max
+}
+
+// Add collects resources until the cap is reached and records what was dropped
+func (l *resourceLimit) Add(resources []models.Resource) {
+	for _, resource := range resources {
+		if l.Full() {
+			l.truncated = true
+			l.droppedRegions[resource.Region] = true
+			l.droppedTypes[resource.Type] = true
+			continue
+		}
+		l.resources = append(l.resources, resource)
+		l.keptRegions[resource.Region] = true
+	}
+}
+
+// SkipRegion records a region that was not scanned because the cap had been reached
+func (l *resourceLimit) SkipRegion(region string) {
+	l.truncated = true
+	l.droppedRegions[region] = true
+}
+
+// Disable records a region that was not scanned because it is not enabled for the account.
+// Nothing is missing from such a region, so it is reported apart from skipped regions.
+func (l *resourceLimit) Disable(region string) {
+	l.disabledRegions[region] = true
+}
+
+// Fail records services or regions that could not be discovered, so the response reports
+// them alongside the resources that were
+func (l *resourceLimit) Fail(failures ...models.DiscoveryError) {
+	l.failures = append(l.failures, failures...)
+}
+
+// Resources returns the collected resources
+func (l *resourceLimit) Resources() []models.Resource {
+	return l.resources
+}
+
+// TimeOut records that discovery stopped at its timeout, leaving the given regions unscanned
+func (l *resourceLimit) TimeOut(timeout time.Duration, regions ...string) {
+	l.timeout = timeout
+	for _, region := range regions {
+		if region = strings.TrimSpace(region); region != "" {
+			l.timedOutRegions[region] = true
+		}
+	}
+}
+
+// Result summarizes the cap, any timeout and any failures for the discovery response
+func (l *resourceLimit) Result() DiscoveryLimit {
+	result := DiscoveryLimit{
+		MaxResources: l.max,
+		Truncated:    l.truncated,
+		TimedOut:     l.timeout > 0,
+		Errors:       l.failures,
+	}
+	for region := range l.disabledRegions {
+		result.DisabledRegions = append(result.DisabledRegions, region)
+	}
+	sort.Strings(result.DisabledRegions)
+	if !result.Truncated && !result.TimedOut && len(result.Errors) == 0 {
+		return result
+	}
+
+	// Regions with some collected resources were partially scanned, not skipped
+	skipped := make(map[string]bool)
+	for region := range l.droppedRegions {
+		if region != "" && !l.keptRegions[region] {
+			skipped[region] = true
+		}
+	}
+	for region := range l.timedOutRegions {
+		skipped[region] = true
+	}
+	for region := range skipped {
+		result.SkippedRegions = append(result.SkippedRegions, region)
+	}
+	for resourceType := range l.droppedTypes {
+		result.SkippedTypes = append(result.SkippedTypes, resourceType)
+	}
+	sort.Strings(result.SkippedRegions)
+	sort.Strings(result.SkippedTypes)
+
+	var warnings []string
+	if l.truncated {
+		warning := fmt.Sprintf("Discovery stopped at the %d resource limit", l.max)
+		if len(result.SkippedRegions) > 0 {
+			warning += "; skipped regions: " + strings.Join(result.SkippedRegions, ", ")
+		}
+		if len(result.SkippedTypes) > 0 {
+			warning += "; incomplete resource types: " + strings.Join(result.SkippedTypes, ", ")
+		}
+		warnings = append(warnings, warning+". Narrow the scan by region or tag, or raise discovery.max_resources.")
+	}
+	if l.timeout > 0 {
+		warning := fmt.Sprintf("Discovery timed out after %s, so results are incomplete", l.timeout)
+		if len(l.timedOutRegions) > 0 {
+			regions := make([]string, 0, len(l.timedOutRegions))
+			for region := range l.timedOutRegions {
+				regions = append(regions, region)
+			}
+			sort.Strings(regions)
+			warning += "; unscanned regions: " + strings.Join(regions, ", ")
+		}
+		warnings = append(warnings, warning+". Narrow the scan by region or raise discovery.provider_timeouts.")
+	}
+	if len(l.failures) > 0 {
+		failures := make([]string, len(l.failures))
+		for i, failure := range l.failures {
+			failures[i] = failure.String()
+		}
+		warnings = append(warnings, "Some resources could not be discovered: "+strings.Join(failures, "; ")+". Check the credentials' permissions for these services.")
+	}
+	result.Warning = strings.Join(warnings, " ")
+
+	return result
+}
+
+// complete reports whether a discovery run scanned everything it set out to, so it can be
+// cached and compared against later runs. A truncated run is complete up to its cap.
+func (l DiscoveryLimit) complete() bool {
+	return !l.TimedOut && len(l.Errors) == 0
+}
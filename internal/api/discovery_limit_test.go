@@ -0,0 +1,174 @@
+package api
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/catherinevee/driftmgr/internal/shared/cache"
+	"github.com/catherinevee/driftmgr/pkg/models"
+)
+
+func TestResourceLimit(t *testing.T) {
+	limit := newResourceLimit(3)
+	limit.Add([]models.Resource{
+		{ID: "i-1", Type: "aws_instance", Region: "us-east-1"},
+		{ID: "i-2", Type: "aws_instance", Region: "us-east-1"},
+	})
+	limit.Add([]models.Resource{
+		{ID: "vol-1", Type: "aws_ebs_volume", Region: "us-west-2"},
+		{ID: "bucket-1", Type: "aws_s3_bucket", Region: "us-west-2"},
+		{ID: "i-3", Type: "aws_instance", Region: "eu-west-1"},
+	})
+	if !limit.Full() {
+		t.Fatal("expected the limit to be full")
+	}
+	limit.SkipRegion("ap-south-1")
+
+	if len(limit.Resources()) != 3 {
+		t.Fatalf("expected 3 collected resources, got %d", len(limit.Resources()))
+	}
+
+	result := limit.Result()
+	if !result.Truncated || result.MaxResources != 3 || result.Warning == "" {
+		t.Errorf("expected a truncated result with a warning, got %+v", result)
+	}
+	// us-west-2 was partially collected, so only eu-west-1 and ap-south-1 were skipped
+	if len(result.SkippedRegions) != 2 || result.SkippedRegions[0] != "ap-south-1" || result.SkippedRegions[1] != "eu-west-1" {
+		t.Errorf("expected ap-south-1 and eu-west-1 to be skipped, got %v", result.SkippedRegions)
+	}
+	if len(result.SkippedTypes) != 2 || result.SkippedTypes[0] != "aws_instance" || result.SkippedTypes[1] != "aws_s3_bucket" {
+		t.Errorf("expected aws_instance and aws_s3_bucket to be incomplete, got %v", result.SkippedTypes)
+	}
+}
+
+func TestResourceLimitDisabled(t *testing.T) {
+	limit := newResourceLimit(0)
+	limit.Add(make([]models.Resource, 50))
+
+	if limit.Full() || len(limit.Resources()) != 50 || limit.Result().Truncated {
+		t.Errorf("expected no cap when the limit is 0")
+	}
+}
+
+func TestResourceLimitTimeout(t *testing.T) {
+	limit := newResourceLimit(10)
+	limit.Add([]models.Resource{{ID: "i-1", Type: "aws_instance", Region: "us-east-1"}})
+	limit.TimeOut(2*time.Minute, " eu-west-1", "ap-south-1")
+
+	result := limit.Result()
+	if !result.TimedOut || result.Truncated {
+		t.Errorf("expected a timed out, untruncated result, got %+v", result)
+	}
+	if len(result.SkippedRegions) != 2 || result.SkippedRegions[0] != "ap-south-1" || result.SkippedRegions[1] != "eu-west-1" {
+		t.Errorf("expected ap-south-1 and eu-west-1 to be unscanned, got %v", result.SkippedRegions)
+	}
+	if !strings.Contains(result.Warning, "timed out after 2m0s") {
+		t.Errorf("expected a timeout warning, got %q", result.Warning)
+	}
+}
+
+func TestResourceLimitFailures(t *testing.T) {
+	limit := newResourceLimit(10)
+	limit.Add([]models.Resource{{ID: "bucket-1", Type: "aws_s3_bucket", Region: "eu-west-1"}})
+	limit.Fail(
+		models.DiscoveryError{Service: "EC2", Region: "eu-west-1", Code: "UnauthorizedOperation", Error: "not authorized"},
+		models.DiscoveryError{Region: "ap-south-1", Error: "no credentials"},
+	)
+
+	result := limit.Result()
+	if result.Truncated || result.TimedOut || len(result.Errors) != 2 {
+		t.Errorf("expected the failures reported without truncation, got %+v", result)
+	}
+	for _, want := range []string{"EC2 in eu-west-1 failed: UnauthorizedOperation", "Discovery in ap-south-1 failed: no credentials"} {
+		if !strings.Contains(result.Warning, want) {
+			t.Errorf("expected the warning to mention %q, got %q", want, result.Warning)
+		}
+	}
+	if result.complete() {
+		t.Error("expected a run with failures to be incomplete")
+	}
+	if !newResourceLimit(10).Result().complete() {
+		t.Error("expected a run without failures to be complete")
+	}
+}
+
+func TestResourceLimitDisabledRegions(t *testing.T) {
+	limit := newResourceLimit(10)
+	limit.Disable("me-south-1")
+	limit.Disable("ap-east-1")
+
+	result := limit.Result()
+	if len(result.DisabledRegions) != 2 || result.DisabledRegions[0] != "ap-east-1" {
+		t.Errorf("expected the disabled regions sorted, got %v", result.DisabledRegions)
+	}
+	if result.Warning != "" || len(result.SkippedRegions) != 0 || !result.complete() {
+		t.Errorf("expected disabled regions not to make the run incomplete, got %+v", result)
+	}
+}
+
+func TestDiscoveryTimeout(t *testing.T) {
+	server := &Server{config: &Config{}}
+	if got := server.discoveryTimeout("aws"); got != defaultDiscoveryTimeout {
+		t.Errorf("expected the default timeout, got %s", got)
+	}
+
+	server.config.Discovery.Timeout = 120
+	server.config.Discovery.ProviderTimeouts = map[string]int{"aws": 600}
+	if got := server.discoveryTimeout("aws"); got != 10*time.Minute {
+		t.Errorf("expected the aws timeout, got %s", got)
+	}
+	if got := server.discoveryTimeout("gcp"); got != 2*time.Minute {
+		t.Errorf("expected the discovery timeout for gcp, got %s", got)
+	}
+}
+
+func TestDiscoverWithCache(t *testing.T) {
+	discoveryCache := cache.NewGlobalCache(1<<20, time.Minute, "")
+	server := &Server{config: &Config{}, services: &Services{DiscoveryCache: discoveryCache}}
+	// An unsupported provider fails any live discovery, so a result means the cache was used
+	request := DiscoverRequest{Provider: "test"}
+	scope := discoveryScope(request.Provider, nil, "")
+	cached := &cachedDiscovery{
+		Resources:    []models.Resource{{ID: "i-1", Type: "aws_instance"}},
+		DiscoveredAt: time.Now().UTC(),
+	}
+	if err := discoveryCache.Set(scope, cached); err != nil {
+		t.Fatalf("failed to prime the cache: %v", err)
+	}
+
+	discovered, hit, err := server.discoverWithCache(context.Background(), scope, request, "job-1")
+	if err != nil {
+		t.Fatalf("expected the cached discovery, got %v", err)
+	}
+	if !hit || discovered != cached {
+		t.Errorf("expected a cache hit returning the cached discovery, got hit=%v %+v", hit, discovered)
+	}
+
+	request.Refresh = true
+	if _, hit, err := server.discoverWithCache(context.Background(), scope, request, "job-2"); err == nil || hit {
+		t.Errorf("expected refresh to bypass the cache and run a live discovery, got hit=%v err=%v", hit, err)
+	} else if !strings.Contains(err.Error(), "unsupported provider") {
+		t.Errorf("expected the live discovery error, got %v", err)
+	}
+}
+
+func TestDiscoveryStopsWhenCancelled(t *testing.T) {
+	server := &Server{config: &Config{}}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	for provider, discover := range map[string]func(context.Context) []models.Resource{
+		"aws":   server.discoverAWSResources,
+		"azure": server.discoverAzureResources,
+		"gcp":   server.discoverGCPResources,
+	} {
+		if resources := discover(ctx); len(resources) != 0 {
+			t.Errorf("expected %s discovery to stop once cancelled, got %d resources", provider, len(resources))
+		}
+		if len(discover(context.Background())) == 0 {
+			t.Errorf("expected %s discovery to find resources when not cancelled", provider)
+		}
+	}
+}
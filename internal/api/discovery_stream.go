@@ -0,0 +1,78 @@
+package api
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/catherinevee/driftmgr/internal/websocket"
+	"github.com/catherinevee/driftmgr/pkg/models"
+)
+
+// discoveryStream sends a discovery job's resources over WebSocket in batches as regions and
+// services complete, filtered by the request's tags like the final response. Batches only
+// go to the user that submitted the job.
+type discoveryStream struct {
+	server  *Server
+	jobID   string
+	user    string
+	request DiscoverRequest
+	sent    int
+}
+
+// newDiscoveryStream creates the stream of a discovery job
+func (s *Server) newDiscoveryStream(jobID string, request DiscoverRequest) *discoveryStream {
+	return &discoveryStream{server: s, jobID: jobID, user: s.jobUser(jobID), request: request}
+}
+
+// Send broadcasts resources to the job's user as one batch per region and service, in order.
+// Nothing is sent when WebSocket is disabled.
+func (d *discoveryStream) Send(resources []models.Resource) {
+	if d.server.services == nil || d.server.services.WebSocket == nil {
+		return
+	}
+	resources = models.FilterResourcesByTags(resources, d.request.Tags, d.request.TagKeyExists)
+
+	type area struct{ region, service string }
+	batches := make(map[area][]models.Resource)
+	var areas []area
+	for _, resource := range resources {
+		key := area{resource.Region, resourceService(resource)}
+		if _, ok := batches[key]; !ok {
+			areas = append(areas, key)
+		}
+		batches[key] = append(batches[key], resource)
+	}
+	sort.Slice(areas, func(i, j int) bool {
+		if areas[i].region != areas[j].region {
+			return areas[i].region < areas[j].region
+		}
+		return areas[i].service < areas[j].service
+	})
+
+	for _, key := range areas {
+		d.sent += len(batches[key])
+		d.server.services.WebSocket.BroadcastDiscoveryBatch(websocket.DiscoveryBatch{
+			JobID:      d.jobID,
+			Provider:   d.request.Provider,
+			Region:     key.region,
+			Service:    key.service,
+			Resources:  batches[key],
+			Discovered: d.sent,
+			User:       d.user,
+		})
+	}
+}
+
+// resourceService returns the cloud service a resource belongs to: the service of its ARN,
+// the namespace of an Azure resource type, or else its type
+func resourceService(resource models.Resource) string {
+	if arn, ok := resource.Attributes["arn"].(string); ok {
+		if parts := strings.SplitN(arn, ":", 4); len(parts) == 4 && parts[2] != "" {
+			return parts[2]
+		}
+	}
+	if namespace, _, ok := strings.Cut(resource.Type, "/"); ok {
+		return namespace
+	}
+	return resource.Type
+}
@@ -0,0 +1,75 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/catherinevee/driftmgr/internal/websocket"
+	"github.com/catherinevee/driftmgr/pkg/models"
+	gorilla "github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiscoveryStream(t *testing.T) {
+	service := websocket.NewService()
+	defer service.Stop(context.Background())
+	ws := httptest.NewServer(http.HandlerFunc(service.GetHandlers().HandleWebSocket))
+	defer ws.Close()
+
+	conn, _, err := gorilla.DefaultDialer.Dial("ws"+strings.TrimPrefix(ws.URL, "http"), nil)
+	require.NoError(t, err)
+	defer conn.Close()
+	// Wait for the welcome message so the client is registered before broadcasting
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	_, _, err = conn.ReadMessage()
+	require.NoError(t, err)
+
+	server := &Server{services: &Services{WebSocket: service}}
+	stream := server.newDiscoveryStream("job-stream", DiscoverRequest{Provider: "aws", Tags: map[string]string{"env": "prod"}})
+	stream.Send([]models.Resource{
+		{ID: "bucket-1", Region: "us-east-1", Tags: map[string]string{"env": "prod"}, Attributes: map[string]interface{}{"arn": "arn:aws:s3:::bucket-1"}},
+		{ID: "i-1", Region: "eu-west-1", Tags: map[string]string{"env": "prod"}, Attributes: map[string]interface{}{"arn": "arn:aws:ec2:eu-west-1:123:instance/i-1"}},
+		{ID: "i-2", Region: "eu-west-1", Tags: map[string]string{"env": "dev"}, Attributes: map[string]interface{}{"arn": "arn:aws:ec2:eu-west-1:123:instance/i-2"}},
+	})
+
+	var batches []websocket.DiscoveryBatch
+	for len(batches) < 2 {
+		_, data, err := conn.ReadMessage()
+		require.NoError(t, err)
+		// Queued messages may share a frame, separated by newlines
+		for _, line := range strings.Split(string(data), "\n") {
+			var message struct {
+				Type  string                   `json:"type"`
+				JobID string                   `json:"job_id"`
+				Data  websocket.DiscoveryBatch `json:"data"`
+			}
+			require.NoError(t, json.Unmarshal([]byte(line), &message))
+			if message.Type == websocket.MessageTypeDiscoveryBatch {
+				assert.Equal(t, "job-stream", message.JobID)
+				batches = append(batches, message.Data)
+			}
+		}
+	}
+
+	assert.Equal(t, "eu-west-1", batches[0].Region)
+	assert.Equal(t, "ec2", batches[0].Service)
+	require.Len(t, batches[0].Resources, 1, "resources outside the tag filter are not streamed")
+	assert.Equal(t, "i-1", batches[0].Resources[0].ID)
+	assert.Equal(t, 1, batches[0].Discovered)
+	assert.Equal(t, "us-east-1", batches[1].Region)
+	assert.Equal(t, "s3", batches[1].Service)
+	assert.Equal(t, 2, batches[1].Discovered)
+}
+
+func TestResourceService(t *testing.T) {
+	assert.Equal(t, "lambda", resourceService(models.Resource{Type: "aws_lambda_function",
+		Attributes: map[string]interface{}{"arn": "arn:aws:lambda:us-east-1:123:function:f"}}))
+	assert.Equal(t, "Microsoft.Network", resourceService(models.Resource{Type: "Microsoft.Network/virtualNetworks"}))
+	assert.Equal(t, "google_storage_bucket", resourceService(models.Resource{Type: "google_storage_bucket"}))
+}
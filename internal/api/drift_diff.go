@@ -0,0 +1,204 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"net/http"
+	"net/url"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/catherinevee/driftmgr/internal/auth"
+	"github.com/catherinevee/driftmgr/internal/drift/comparator"
+	"github.com/catherinevee/driftmgr/internal/drift/detector"
+)
+
+// driftTypeNames names the kinds of drift in diffs
+var driftTypeNames = map[detector.DriftType]string{
+	detector.NoDrift:            "none",
+	detector.ResourceMissing:    "missing",
+	detector.ResourceUnmanaged:  "unmanaged",
+	detector.ConfigurationDrift: "modified",
+	detector.ResourceOrphaned:   "orphaned",
+}
+
+// driftSeverityNames names drift severities in diffs
+var driftSeverityNames = map[detector.DriftSeverity]string{
+	detector.SeverityLow:      "low",
+	detector.SeverityMedium:   "medium",
+	detector.SeverityHigh:     "high",
+	detector.SeverityCritical: "critical",
+}
+
+// importanceNames names the importance of differences in diffs
+var importanceNames = map[comparator.Importance]string{
+	comparator.ImportanceLow:      "low",
+	comparator.ImportanceMedium:   "medium",
+	comparator.ImportanceHigh:     "high",
+	comparator.ImportanceCritical: "critical",
+}
+
+// handleDriftDiff handles GET /api/v1/drift/{id}/diff, returning the field-level diff of one
+// drift result as JSON, or as an HTML page with format=html
+func (s *Server) handleDriftDiff(w http.ResponseWriter, r *http.Request) {
+	segments := strings.Split(r.URL.EscapedPath(), "/")
+	id, err := url.PathUnescape(segments[len(segments)-2])
+	if err != nil || id == "" {
+		s.writeError(w, http.StatusBadRequest, "Invalid drift ID")
+		return
+	}
+	format := strings.ToLower(r.URL.Query().Get("format"))
+	if format != "" && format != "json" && format != "html" {
+		s.writeError(w, http.StatusBadRequest, fmt.Sprintf("Unsupported diff format %q; use json or html", format))
+		return
+	}
+
+	drift, ok := GetGlobalDriftStore().Get(id)
+	if !ok {
+		s.writeError(w, http.StatusNotFound, fmt.Sprintf("Drift result %s not found", id))
+		return
+	}
+	if err := auth.CheckScope(r.Context(), drift.Provider, driftAccount(drift)); err != nil {
+		s.writeError(w, http.StatusForbidden, err.Error())
+		return
+	}
+
+	diff := driftDiff(id, drift)
+	if format != "html" {
+		s.writeJSON(w, http.StatusOK, diff)
+		return
+	}
+	page, err := renderDriftDiffHTML(diff)
+	if err != nil {
+		s.writeError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to render diff: %v", err))
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	w.Write(page)
+}
+
+// driftDiff lays out a drift result as before and after values per field. Before is the
+// desired value from the IaC state and after the actual value in the cloud. Results recorded
+// without differences, such as missing or unmanaged resources, are diffed from their desired
+// and actual states.
+func driftDiff(id string, drift *detector.DriftResult) *DriftDiffResponse {
+	diff := &DriftDiffResponse{
+		DriftID:      id,
+		Resource:     drift.Resource,
+		ResourceType: drift.ResourceType,
+		Provider:     drift.Provider,
+		DriftType:    driftTypeNames[drift.DriftType],
+		Severity:     driftSeverityNames[drift.Severity],
+		DetectedAt:   drift.Timestamp,
+		Fields:       []DriftFieldDiff{},
+	}
+
+	for _, difference := range drift.Differences {
+		diff.Fields = append(diff.Fields, DriftFieldDiff{
+			Field:      difference.Path,
+			Change:     string(difference.Type),
+			Before:     difference.Expected,
+			After:      difference.Actual,
+			Importance: importanceNames[difference.Importance],
+			Message:    difference.Message,
+		})
+	}
+	if len(drift.Differences) == 0 {
+		diff.Fields = stateDiff(drift.DesiredState, drift.ActualState)
+	}
+	sort.SliceStable(diff.Fields, func(i, j int) bool { return diff.Fields[i].Field < diff.Fields[j].Field })
+	return diff
+}
+
+// stateDiff compares the top-level attributes of a desired and an actual state
+func stateDiff(desired, actual map[string]interface{}) []DriftFieldDiff {
+	fields := []DriftFieldDiff{}
+	for name, before := range desired {
+		after, ok := actual[name]
+		switch {
+		case !ok:
+			fields = append(fields, DriftFieldDiff{Field: name, Change: string(comparator.DiffTypeRemoved), Before: before})
+		case !reflect.DeepEqual(before, after):
+			fields = append(fields, DriftFieldDiff{Field: name, Change: string(comparator.DiffTypeModified), Before: before, After: after})
+		}
+	}
+	for name, after := range actual {
+		if _, ok := desired[name]; !ok {
+			fields = append(fields, DriftFieldDiff{Field: name, Change: string(comparator.DiffTypeAdded), After: after})
+		}
+	}
+	return fields
+}
+
+// renderDriftDiffHTML renders a drift diff as a standalone page with the before and after
+// values of each field side by side
+func renderDriftDiffHTML(diff *DriftDiffResponse) ([]byte, error) {
+	tmpl, err := template.New("diff").Funcs(template.FuncMap{
+		"value": func(value interface{}) string {
+			if value == nil {
+				return ""
+			}
+			if s, ok := value.(string); ok {
+				return s
+			}
+			data, err := json.MarshalIndent(value, "", "  ")
+			if err != nil {
+				return fmt.Sprint(value)
+			}
+			return string(data)
+		},
+	}).Parse(driftDiffTemplate)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, diff); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// driftDiffTemplate lays out a drift diff with removed values struck through in red and added
+// values in green
+const driftDiffTemplate = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Drift {{.DriftID}}: {{.Resource}}</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; margin: 2em; color: #24292f; }
+table { border-collapse: collapse; width: 100%; }
+th, td { border: 1px solid #d0d7de; padding: 6px 10px; text-align: left; vertical-align: top; }
+th { background: #f6f8fa; }
+pre { margin: 0; white-space: pre-wrap; font-family: SFMono-Regular, Consolas, monospace; font-size: 13px; }
+td.before pre { background: #ffebe9; text-decoration: line-through; }
+td.after pre { background: #dafbe1; }
+td.empty { color: #8c959f; }
+.change { font-size: 12px; text-transform: uppercase; }
+</style>
+</head>
+<body>
+<h1>{{.Resource}}</h1>
+<p>{{.ResourceType}} on {{.Provider}} &middot; {{.DriftType}} drift &middot; {{.Severity}} severity &middot; detected {{.DetectedAt.Format "2006-01-02 15:04:05 MST"}}</p>
+{{if .Fields}}
+<table>
+<tr><th>Field</th><th>Change</th><th>Before (desired)</th><th>After (actual)</th></tr>
+{{range .Fields}}
+<tr>
+<td><code>{{.Field}}</code>{{if .Message}}<br><small>{{.Message}}</small>{{end}}</td>
+<td class="change">{{.Change}}{{if .Importance}}<br>{{.Importance}}{{end}}</td>
+{{if eq .Change "added"}}<td class="empty">absent</td>{{else}}<td class="before"><pre>{{value .Before}}</pre></td>{{end}}
+{{if eq .Change "removed"}}<td class="empty">absent</td>{{else}}<td class="after"><pre>{{value .After}}</pre></td>{{end}}
+</tr>
+{{end}}
+</table>
+{{else}}
+<p>No field differences were recorded.</p>
+{{end}}
+</body>
+</html>
+`
@@ -0,0 +1,69 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/catherinevee/driftmgr/internal/drift/comparator"
+	"github.com/catherinevee/driftmgr/internal/drift/detector"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDriftDiff(t *testing.T) {
+	drifts := GetGlobalDriftStore()
+	drifts.Store("diff-test-modified", &detector.DriftResult{
+		Resource: "aws_security_group.web", ResourceType: "aws_security_group", Provider: "aws",
+		DriftType: detector.ConfigurationDrift, Severity: detector.SeverityHigh, Timestamp: time.Now(),
+		Differences: []comparator.Difference{
+			{Path: "tags.env", Type: comparator.DiffTypeModified, Expected: "prod", Actual: "<script>", Importance: comparator.ImportanceLow},
+			{Path: "ingress", Type: comparator.DiffTypeModified, Expected: []interface{}{"10.0.0.0/8"}, Actual: []interface{}{"0.0.0.0/0"}, Importance: comparator.ImportanceCritical},
+		},
+	})
+	drifts.Store("diff-test-missing", &detector.DriftResult{
+		Resource: "aws_s3_bucket.logs", ResourceType: "aws_s3_bucket", Provider: "aws",
+		DriftType: detector.ResourceMissing, DesiredState: map[string]interface{}{"bucket": "logs"},
+	})
+	t.Cleanup(func() {
+		drifts.Delete("diff-test-modified")
+		drifts.Delete("diff-test-missing")
+	})
+
+	server := &Server{}
+	get := func(target string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		server.handleDriftDiff(w, httptest.NewRequest(http.MethodGet, target, nil))
+		return w
+	}
+
+	w := get("/api/v1/drift/diff-test-modified/diff")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var diff DriftDiffResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &diff))
+	assert.Equal(t, "modified", diff.DriftType)
+	assert.Equal(t, "high", diff.Severity)
+	require.Len(t, diff.Fields, 2)
+	assert.Equal(t, "ingress", diff.Fields[0].Field, "fields are sorted")
+	assert.Equal(t, "critical", diff.Fields[0].Importance)
+	assert.Equal(t, []interface{}{"10.0.0.0/8"}, diff.Fields[0].Before)
+	assert.Equal(t, []interface{}{"0.0.0.0/0"}, diff.Fields[0].After)
+
+	w = get("/api/v1/drift/diff-test-missing/diff")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &diff))
+	assert.Equal(t, []DriftFieldDiff{{Field: "bucket", Change: "removed", Before: "logs"}}, diff.Fields,
+		"resources without differences are diffed from their states")
+
+	w = get("/api/v1/drift/diff-test-modified/diff?format=html")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Contains(t, w.Header().Get("Content-Type"), "text/html")
+	assert.Contains(t, w.Body.String(), `<td class="before"><pre>prod</pre></td>`)
+	assert.Contains(t, w.Body.String(), "&lt;script&gt;", "values are escaped")
+	assert.NotContains(t, w.Body.String(), "<script>")
+
+	assert.Equal(t, http.StatusBadRequest, get("/api/v1/drift/diff-test-modified/diff?format=pdf").Code)
+	assert.Equal(t, http.StatusNotFound, get("/api/v1/drift/diff-test-unknown/diff").Code)
+}
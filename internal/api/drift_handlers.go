@@ -1,17 +1,18 @@
 package api
 
 import (
-	"encoding/json"
 	"net/http"
 	"strconv"
 	"time"
 
+	"github.com/catherinevee/driftmgr/internal/metrics"
 	"github.com/catherinevee/driftmgr/internal/services"
 )
 
 // DriftHandlers handles drift detection API endpoints
 type DriftHandlers struct {
 	driftService *services.DriftService
+	outputDir    string // where non-inline exports are written
 }
 
 // NewDriftHandlers creates a new DriftHandlers instance
@@ -28,9 +29,9 @@ func (h *DriftHandlers) DetectDrift(w http.ResponseWriter, r *http.Request) {
 
 	// Parse request body
 	var detectRequest DriftDetectionRequest
-	if err := json.NewDecoder(r.Body).Decode(&detectRequest); err != nil {
+	if err := decodeRequest(r, &detectRequest); err != nil {
 		response := NewResponseWriter(w)
-		response.WriteValidationError("Invalid request body", err.Error())
+		response.WriteRequestError(err)
 		return
 	}
 
@@ -52,9 +53,11 @@ func (h *DriftHandlers) DetectDrift(w http.ResponseWriter, r *http.Request) {
 	}
 
 	driftConfig := services.DriftConfig{
-		ResourceID: resourceID,
-		Provider:   provider,
-		Region:     region,
+		ResourceID:   resourceID,
+		Provider:     provider,
+		Region:       region,
+		Tags:         detectRequest.Tags,
+		TagKeyExists: detectRequest.TagKeyExists,
 		Options: services.DriftOptions{
 			DeepScan:        true, // Default to deep scan
 			IncludeMetadata: true, // Default to include metadata
@@ -74,6 +77,7 @@ func (h *DriftHandlers) DetectDrift(w http.ResponseWriter, r *http.Request) {
 	// Convert to API response format
 	apiResults := make([]DriftResult, 0, len(driftResults.DriftDetails))
 	for _, detail := range driftResults.DriftDetails {
+		metrics.RecordDriftResult(detail.Severity)
 		apiResult := DriftResult{
 			ID:           driftResults.ID,
 			ResourceID:   driftResults.ResourceID,
@@ -261,99 +265,38 @@ func (h *DriftHandlers) GetDriftHistory(w http.ResponseWriter, r *http.Request)
 
 	days := 7 // Default to 7 days
 	if daysStr != "" {
-		if d, err := strconv.Atoi(daysStr); err == nil && d > 0 {
+		if d, err := strconv.Atoi(daysStr); err == nil && d > 0 && d <= 365 {
 			days = d
 		}
 	}
 
-	// Mock drift history data
+	trend, err := h.driftService.GetDriftTrend(r.Context(), provider, days)
+	if err != nil {
+		response := NewResponseWriter(w)
+		response.WriteInternalError("Failed to get drift history: " + err.Error())
+		return
+	}
+
+	totalDetections := 0
+	for _, point := range trend {
+		totalDetections += point.NewDrifts
+	}
+
 	historyData := map[string]interface{}{
 		"provider": provider,
 		"period": map[string]interface{}{
 			"days":  days,
-			"start": time.Now().Add(-time.Duration(days) * 24 * time.Hour).Format("2006-01-02"),
-			"end":   time.Now().Format("2006-01-02"),
+			"start": trend[0].Date.Format("2006-01-02"),
+			"end":   trend[len(trend)-1].Date.Format("2006-01-02"),
 		},
 		"summary": map[string]interface{}{
-			"total_detections": 15,
-			"resolved":         8,
-			"pending":          7,
-			"by_severity": map[string]int{
-				"critical": 2,
-				"high":     4,
-				"medium":   6,
-				"low":      3,
-			},
-		},
-		"daily_breakdown": []map[string]interface{}{
-			{
-				"date":        time.Now().Add(-6 * 24 * time.Hour).Format("2006-01-02"),
-				"detections":  3,
-				"resolutions": 2,
-				"critical":    1,
-				"high":        1,
-				"medium":      1,
-				"low":         0,
-			},
-			{
-				"date":        time.Now().Add(-5 * 24 * time.Hour).Format("2006-01-02"),
-				"detections":  2,
-				"resolutions": 1,
-				"critical":    0,
-				"high":        1,
-				"medium":      1,
-				"low":         0,
-			},
-			{
-				"date":        time.Now().Add(-4 * 24 * time.Hour).Format("2006-01-02"),
-				"detections":  4,
-				"resolutions": 2,
-				"critical":    1,
-				"high":        1,
-				"medium":      2,
-				"low":         0,
-			},
-			{
-				"date":        time.Now().Add(-3 * 24 * time.Hour).Format("2006-01-02"),
-				"detections":  1,
-				"resolutions": 1,
-				"critical":    0,
-				"high":        0,
-				"medium":      1,
-				"low":         0,
-			},
-			{
-				"date":        time.Now().Add(-2 * 24 * time.Hour).Format("2006-01-02"),
-				"detections":  3,
-				"resolutions": 1,
-				"critical":    0,
-				"high":        1,
-				"medium":      1,
-				"low":         1,
-			},
-			{
-				"date":        time.Now().Add(-1 * 24 * time.Hour).Format("2006-01-02"),
-				"detections":  2,
-				"resolutions": 1,
-				"critical":    0,
-				"high":        0,
-				"medium":      1,
-				"low":         1,
-			},
-			{
-				"date":        time.Now().Format("2006-01-02"),
-				"detections":  0,
-				"resolutions": 0,
-				"critical":    0,
-				"high":        0,
-				"medium":      0,
-				"low":         0,
-			},
+			"total_detections": totalDetections,
 		},
+		"series": trend,
 	}
 
 	response := NewResponseWriter(w)
-	err := response.WriteSuccess(historyData, &APIMeta{
+	err = response.WriteSuccess(historyData, &APIMeta{
 		Timestamp: time.Now().UTC().Format(time.RFC3339),
 	})
 	if err != nil {
@@ -1,6 +1,7 @@
 package api
 
 import (
+	"sort"
 	"sync"
 	"time"
 
@@ -55,6 +56,19 @@ func (ds *DriftStore) List() []*detector.DriftResult {
 	return results
 }
 
+// IDs returns the IDs of all stored drift results in sorted order
+func (ds *DriftStore) IDs() []string {
+	ds.mu.RLock()
+	defer ds.mu.RUnlock()
+
+	ids := make([]string, 0, len(ds.results))
+	for id := range ds.results {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
 // Delete removes a drift result
 func (ds *DriftStore) Delete(id string) {
 	ds.mu.Lock()
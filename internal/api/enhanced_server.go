@@ -6,6 +6,8 @@ import (
 	"net/http"
 	"sync"
 	"time"
+
+	"github.com/catherinevee/driftmgr/internal/version"
 )
 
 // EnhancedServer represents the enhanced API server with new handlers
@@ -157,7 +159,6 @@ func (s *EnhancedServer) setupEnhancedRoutes() {
 	// Resource Management Routes
 	s.router.GET("/api/v1/resources", s.resourceHandlers.ListResources)
 	s.router.GET("/api/v1/resources/{id}", s.resourceHandlers.GetResource)
-	s.router.GET("/api/v1/resources/search", s.resourceHandlers.SearchResources)
 	s.router.PUT("/api/v1/resources/{id}/tags", s.resourceHandlers.UpdateResourceTags)
 	s.router.GET("/api/v1/resources/{id}/cost", s.resourceHandlers.GetResourceCost)
 	s.router.GET("/api/v1/resources/{id}/compliance", s.resourceHandlers.GetResourceCompliance)
@@ -184,7 +185,7 @@ func (s *EnhancedServer) handleHealth(w http.ResponseWriter, r *http.Request) {
 	healthData := map[string]interface{}{
 		"status":    "healthy",
 		"timestamp": time.Now().UTC().Format(time.RFC3339),
-		"version":   "1.0.0",
+		"version":   version.Version,
 		"services": map[string]string{
 			"api":       "healthy",
 			"database":  "healthy",
@@ -204,11 +205,12 @@ func (s *EnhancedServer) handleHealth(w http.ResponseWriter, r *http.Request) {
 // handleVersion handles version requests
 func (s *EnhancedServer) handleVersion(w http.ResponseWriter, r *http.Request) {
 	response := NewResponseWriter(w)
+	build := version.Get()
 	versionData := map[string]interface{}{
-		"version":     "1.0.0",
-		"build_time":  time.Now().UTC().Format(time.RFC3339),
-		"git_commit":  "abc123def456",
-		"go_version":  "1.21.0",
+		"version":     build.Version,
+		"commit":      build.Commit,
+		"build_date":  build.BuildDate,
+		"go_version":  build.GoVersion,
 		"api_version": "v1",
 	}
 
@@ -0,0 +1,100 @@
+package api
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+// etagRecorder buffers a handler's response so its ETag can be computed before it is sent
+type etagRecorder struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (r *etagRecorder) Header() http.Header {
+	return r.header
+}
+
+func (r *etagRecorder) WriteHeader(status int) {
+	r.status = status
+}
+
+func (r *etagRecorder) Write(data []byte) (int, error) {
+	return r.body.Write(data)
+}
+
+// WithETag wraps a read-only handler so successful GET responses carry an ETag derived from
+// their content and requests with a matching If-None-Match get 304 Not Modified. Because the
+// tag is a content hash it changes whenever the underlying data does.
+func WithETag(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			next(w, r)
+			return
+		}
+
+		recorder := &etagRecorder{header: w.Header(), status: http.StatusOK}
+		next(recorder, r)
+
+		if recorder.status != http.StatusOK {
+			w.WriteHeader(recorder.status)
+			w.Write(recorder.body.Bytes())
+			return
+		}
+
+		etag := computeETag(recorder.body.Bytes())
+		w.Header().Set("ETag", etag)
+		// Let clients store the response but revalidate it on every request
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Del("Pragma")
+		w.Header().Del("Expires")
+
+		if etagMatches(r.Header.Get("If-None-Match"), etag) {
+			w.Header().Del("Content-Length")
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		w.Write(recorder.body.Bytes())
+	}
+}
+
+// computeETag hashes a response body. The response envelope's meta.timestamp changes on
+// every request, so it is excluded from the hash for JSON API responses.
+func computeETag(body []byte) string {
+	content := body
+
+	var envelope map[string]interface{}
+	if err := json.Unmarshal(body, &envelope); err == nil {
+		if meta, ok := envelope["meta"].(map[string]interface{}); ok {
+			delete(meta, "timestamp")
+		}
+		delete(envelope, "timestamp")
+		if normalized, err := json.Marshal(envelope); err == nil {
+			content = normalized
+		}
+	}
+
+	sum := sha256.Sum256(content)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// etagMatches reports whether an If-None-Match header matches the ETag, using weak comparison
+func etagMatches(ifNoneMatch, etag string) bool {
+	if ifNoneMatch == "" {
+		return false
+	}
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,56 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestWithETag(t *testing.T) {
+	items := []string{"bucket-1"}
+	handler := WithETag(func(w http.ResponseWriter, r *http.Request) {
+		SetCommonHeaders(w)
+		response := NewResponseWriter(w)
+		response.WriteSuccess(items, &APIMeta{
+			Count:     len(items),
+			Timestamp: time.Now().UTC().Format(time.RFC3339Nano),
+		})
+	})
+
+	w := httptest.NewRecorder()
+	handler(w, httptest.NewRequest(http.MethodGet, "/api/v1/resources", nil))
+	etag := w.Header().Get("ETag")
+	if w.Code != http.StatusOK || etag == "" {
+		t.Fatalf("expected 200 with an ETag, got %d %q", w.Code, etag)
+	}
+
+	// Unchanged data returns 304 even though the response timestamp differs
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/resources", nil)
+	req.Header.Set("If-None-Match", etag)
+	w = httptest.NewRecorder()
+	handler(w, req)
+	if w.Code != http.StatusNotModified || w.Body.Len() != 0 {
+		t.Errorf("expected 304 with an empty body, got %d (%d bytes)", w.Code, w.Body.Len())
+	}
+
+	// Changed data gets a new ETag
+	items = append(items, "bucket-2")
+	w = httptest.NewRecorder()
+	handler(w, req)
+	if w.Code != http.StatusOK || w.Header().Get("ETag") == etag {
+		t.Errorf("expected 200 with a new ETag after the data changed, got %d %q", w.Code, w.Header().Get("ETag"))
+	}
+}
+
+func TestWithETagSkipsErrors(t *testing.T) {
+	handler := WithETag(func(w http.ResponseWriter, r *http.Request) {
+		NewResponseWriter(w).WriteNotFound("Resource")
+	})
+
+	w := httptest.NewRecorder()
+	handler(w, httptest.NewRequest(http.MethodGet, "/api/v1/resources/missing", nil))
+	if w.Code != http.StatusNotFound || w.Header().Get("ETag") != "" {
+		t.Errorf("expected 404 without an ETag, got %d %q", w.Code, w.Header().Get("ETag"))
+	}
+}
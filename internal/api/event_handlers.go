@@ -0,0 +1,176 @@
+package api
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/catherinevee/driftmgr/internal/services"
+	"github.com/catherinevee/driftmgr/pkg/models"
+)
+
+const (
+	// eventTokenHeader carries the shared secret a cloud event source sends with each event
+	eventTokenHeader = "X-Driftmgr-Event-Token"
+
+	// maxEventSize is the largest event body accepted, the size of the largest EventBridge
+	// event
+	maxEventSize = 256 << 10
+)
+
+// errEventResourceDeleted is returned by an eventCheck fetch when the resource no longer
+// exists
+var errEventResourceDeleted = errors.New("resource no longer exists")
+
+// eventMu serializes updates to the snapshots of re-discovered resources
+var eventMu sync.Mutex
+
+// eventCheck is one resource a change event asks to re-discover. Account and Region fill in
+// what the fetched resource does not record.
+type eventCheck struct {
+	ID      string
+	Account string
+	Region  string
+	Fetch   func(ctx context.Context) (*models.Resource, error)
+}
+
+// eventRecheck is the outcome of re-checking the resources of change events
+type eventRecheck struct {
+	Resources     []EventResource
+	DriftResultID string
+	DriftCount    int
+}
+
+// authorizeEvent checks that a request carries the configured event token, in the event token
+// header or, for webhooks that cannot set headers, in the token query parameter. It writes
+// the error response and returns false if not.
+func (s *Server) authorizeEvent(w http.ResponseWriter, r *http.Request, source, token string) bool {
+	if token == "" {
+		s.writeError(w, http.StatusServiceUnavailable, source+" event ingestion is not configured")
+		return false
+	}
+	sent := r.Header.Get(eventTokenHeader)
+	if sent == "" {
+		sent = r.URL.Query().Get("token")
+	}
+	if subtle.ConstantTimeCompare([]byte(sent), []byte(token)) != 1 {
+		s.writeError(w, http.StatusUnauthorized, "Invalid event token")
+		return false
+	}
+	return true
+}
+
+// readEvent reads an event body of at most maxEventSize bytes, writing the error response and
+// returning false if it cannot
+func (s *Server) readEvent(w http.ResponseWriter, r *http.Request) ([]byte, bool) {
+	data, err := io.ReadAll(io.LimitReader(r.Body, maxEventSize+1))
+	if err != nil {
+		s.writeError(w, http.StatusBadRequest, fmt.Sprintf("Invalid request body: %v", err))
+		return nil, false
+	}
+	if len(data) > maxEventSize {
+		s.writeError(w, http.StatusRequestEntityTooLarge, "Event is larger than 256 KB")
+		return nil, false
+	}
+	return data, true
+}
+
+// recheckEventResources re-discovers the resources change events reported, saves them as the
+// latest discovery of scope so they replace earlier copies, and checks them for drift.
+// Resources known to be deleted are dropped from scope without being fetched.
+func (s *Server) recheckEventResources(ctx context.Context, provider, scope string, checks []eventCheck, deleted []string) (*eventRecheck, error) {
+	previous := make(map[string]models.Resource)
+	for _, resource := range GetGlobalResourceStore().All() {
+		if resource.Provider == provider {
+			previous[resource.ID] = resource
+		}
+	}
+
+	result := &eventRecheck{Resources: []EventResource{}}
+	var refreshed []models.Resource
+	gone := make(map[string]bool)
+	for _, id := range deleted {
+		gone[id] = true
+		result.Resources = append(result.Resources, EventResource{ID: id, Status: "deleted"})
+	}
+	for _, check := range checks {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		checked := EventResource{ID: check.ID}
+		resource, err := check.Fetch(ctx)
+		switch {
+		case errors.Is(err, errEventResourceDeleted):
+			checked.Status = "deleted"
+			gone[check.ID] = true
+		case err != nil:
+			checked.Status = "failed"
+			checked.Error = err.Error()
+		default:
+			if resource.AccountID == "" {
+				resource.AccountID = check.Account
+			}
+			if resource.Region == "" {
+				resource.Region = check.Region
+			}
+			if resource.Provider == "" {
+				resource.Provider = provider
+			}
+			old, seen := previous[resource.ID]
+			switch {
+			case !seen:
+				checked.Status = "new"
+			case !sameConfiguration(old, *resource):
+				checked.Status = "changed"
+			default:
+				checked.Status = "unchanged"
+			}
+			refreshed = append(refreshed, *resource)
+		}
+		result.Resources = append(result.Resources, checked)
+	}
+	saveEventResources(scope, refreshed, gone)
+
+	if len(refreshed) > 0 && s.services != nil && s.services.DriftService != nil {
+		drift, err := s.services.DriftService.DetectDrift(ctx, services.DriftConfig{
+			Provider:  provider,
+			Resources: cloudResources(refreshed),
+		})
+		if err != nil {
+			return result, fmt.Errorf("drift detection failed: %w", err)
+		}
+		result.DriftResultID = drift.ID
+		result.DriftCount = drift.DriftCount
+	}
+	return result, nil
+}
+
+// saveEventResources merges re-discovered resources into the snapshot of scope and drops
+// deleted ones from it
+func saveEventResources(scope string, refreshed []models.Resource, deleted map[string]bool) {
+	if len(refreshed) == 0 && len(deleted) == 0 {
+		return
+	}
+	eventMu.Lock()
+	defer eventMu.Unlock()
+
+	store := GetGlobalResourceStore()
+	replaced := make(map[string]bool, len(refreshed))
+	for _, resource := range refreshed {
+		replaced[resource.ID] = true
+	}
+	merged := append([]models.Resource{}, refreshed...)
+	if last, ok := store.Last(scope); ok {
+		for _, resource := range last.Resources {
+			if !replaced[resource.ID] && !deleted[resource.ID] {
+				merged = append(merged, resource)
+			}
+		}
+	}
+	store.Save(scope, merged, time.Now())
+}
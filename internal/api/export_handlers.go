@@ -0,0 +1,332 @@
+package api
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/catherinevee/driftmgr/internal/auth"
+	"github.com/catherinevee/driftmgr/internal/drift/detector"
+	"github.com/catherinevee/driftmgr/internal/drift/junit"
+	"github.com/catherinevee/driftmgr/internal/models"
+	"github.com/catherinevee/driftmgr/internal/services"
+	"github.com/google/uuid"
+)
+
+// exportOutputDir is the subdirectory of the data directory non-inline exports are written
+// to and served from
+const exportOutputDir = "outputs"
+
+// dataDirEnv names the data directory when Config.DataDir is empty
+const dataDirEnv = "DRIFTMGR_DATA_DIR"
+
+// errInvalidFilename reports a file name that is not a plain name inside its directory
+var errInvalidFilename = errors.New("invalid file name")
+
+// dataDir returns the base directory the server writes files under: the configured data
+// directory, else DRIFTMGR_DATA_DIR, else the working directory
+func (s *Server) dataDir() string {
+	if s.config != nil && s.config.DataDir != "" {
+		return s.config.DataDir
+	}
+	if dir := os.Getenv(dataDirEnv); dir != "" {
+		return dir
+	}
+	return "."
+}
+
+// outputDir returns the directory exports are written to and served from
+func (s *Server) outputDir() string {
+	return filepath.Join(s.dataDir(), exportOutputDir)
+}
+
+// prepareDataDir creates the data directory's subdirectories and checks they are writable,
+// so a read-only filesystem is reported at startup rather than on the first export
+func (s *Server) prepareDataDir() error {
+	for _, dir := range []string{s.outputDir(), filepath.Join(s.dataDir(), stateUploadDir)} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("failed to create data directory: %w", err)
+		}
+		if err := checkWritable(dir); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkWritable creates and removes a file in dir to check it can be written
+func checkWritable(dir string) error {
+	probe, err := os.CreateTemp(dir, ".write-check-*")
+	if err != nil {
+		return fmt.Errorf("data directory %s is not writable: %w", dir, err)
+	}
+	probe.Close()
+	return os.Remove(probe.Name())
+}
+
+// dataFilePath joins a client-supplied file name to dir, rejecting names that are empty,
+// hidden or would leave dir, such as ../../etc/x
+func dataFilePath(dir, name string) (string, error) {
+	if name == "" || strings.HasPrefix(name, ".") || strings.ContainsAny(name, `/\`) || filepath.Base(name) != name {
+		return "", fmt.Errorf("%w: %q", errInvalidFilename, name)
+	}
+	return filepath.Join(dir, name), nil
+}
+
+// ExportDriftResults handles POST /api/v1/drift/export
+func (h *DriftHandlers) ExportDriftResults(w http.ResponseWriter, r *http.Request) {
+	// Set common headers
+	SetCommonHeaders(w)
+
+	var exportRequest ExportRequest
+	if err := decodeRequest(r, &exportRequest); err != nil {
+		response := NewResponseWriter(w)
+		response.WriteRequestError(err)
+		return
+	}
+
+	if exportRequest.Format == "" {
+		exportRequest.Format = "json"
+	}
+	switch strings.ToLower(exportRequest.Format) {
+	case "sarif":
+		h.exportDriftSARIF(w, r, exportRequest)
+		return
+	case "junit":
+		h.exportDriftJUnit(w, r, exportRequest)
+		return
+	}
+
+	results, err := h.driftService.GetDriftResults(r.Context(), services.DriftFilters{
+		Provider: exportRequest.Provider,
+		Status:   exportRequest.Status,
+	})
+	if err != nil {
+		response := NewResponseWriter(w)
+		response.WriteInternalError("Failed to get drift results: " + err.Error())
+		return
+	}
+	results = scopedDriftResults(r, results)
+
+	data, contentType, err := renderDriftExport(results, exportRequest.Format)
+	if err != nil {
+		response := NewResponseWriter(w)
+		response.WriteValidationError("Invalid export format", err.Error())
+		return
+	}
+
+	saveExport(w, r, h.exportDir(), exportFilename("drift-export", exportRequest.Format), exportRequest.Format, contentType, data, len(results), exportRequest.Inline)
+}
+
+// exportDriftSARIF exports the drift results of the drift store as a SARIF log. The runs the
+// other formats export only count drift, while SARIF reports each drifted field.
+func (h *DriftHandlers) exportDriftSARIF(w http.ResponseWriter, r *http.Request, exportRequest ExportRequest) {
+	ids, drifts := storedDrifts(r, exportRequest.Provider)
+	data, err := renderDriftSARIF(ids, drifts)
+	if err != nil {
+		response := NewResponseWriter(w)
+		response.WriteInternalError("Failed to render SARIF export: " + err.Error())
+		return
+	}
+	saveExport(w, r, h.exportDir(), exportFilename("drift-export", "sarif"), "sarif", sarifContentType, data, len(ids), exportRequest.Inline)
+}
+
+// exportDriftJUnit exports the drift store as a JUnit XML report for CI test report views.
+// Resources of the most recent discovery without drift are passing test cases.
+func (h *DriftHandlers) exportDriftJUnit(w http.ResponseWriter, r *http.Request, exportRequest ExportRequest) {
+	ids, drifts := storedDrifts(r, exportRequest.Provider)
+	results := make([]*detector.DriftResult, 0, len(ids))
+	drifted := make(map[string]bool)
+	for _, id := range ids {
+		results = append(results, drifts[id])
+		drifted[drifts[id].Provider+"\x00"+drifts[id].Resource] = true
+	}
+	for _, resource := range liveResources(r, "") {
+		if exportRequest.Provider != "" && !strings.EqualFold(resource.Provider, exportRequest.Provider) {
+			continue
+		}
+		if drifted[resource.Provider+"\x00"+resource.ID] {
+			continue
+		}
+		results = append(results, &detector.DriftResult{
+			Resource:     resource.ID,
+			ResourceType: resource.Type,
+			Provider:     resource.Provider,
+			DriftType:    detector.NoDrift,
+		})
+	}
+
+	now := time.Now().UTC()
+	data, err := junit.Render("driftmgr", results, now, 0)
+	if err != nil {
+		response := NewResponseWriter(w)
+		response.WriteInternalError("Failed to render JUnit export: " + err.Error())
+		return
+	}
+	saveExport(w, r, h.exportDir(), exportFilename("drift-export", "xml"), "junit", junit.ContentType, data, len(results), exportRequest.Inline)
+}
+
+// scopedDriftResults leaves out drift results outside the caller's scopes. Results that do
+// not record their account need a scope covering the whole provider.
+func scopedDriftResults(r *http.Request, results []*models.DriftResult) []*models.DriftResult {
+	allowed := results[:0:0]
+	for _, result := range results {
+		if auth.CheckScope(r.Context(), result.Provider, result.Metadata["account_id"]) == nil {
+			allowed = append(allowed, result)
+		}
+	}
+	return allowed
+}
+
+// storedDrifts returns the IDs, sorted, and results of the drift store for a provider, or for
+// every provider when it is empty, leaving out drift outside the caller's scopes
+func storedDrifts(r *http.Request, provider string) ([]string, map[string]*detector.DriftResult) {
+	store := GetGlobalDriftStore()
+	var ids []string
+	drifts := make(map[string]*detector.DriftResult)
+	for _, id := range store.IDs() {
+		drift, ok := store.Get(id)
+		if !ok || (provider != "" && !strings.EqualFold(drift.Provider, provider)) {
+			continue
+		}
+		if auth.CheckScope(r.Context(), drift.Provider, driftAccount(drift)) != nil {
+			continue
+		}
+		ids = append(ids, id)
+		drifts[id] = drift
+	}
+	return ids, drifts
+}
+
+// exportDir returns the directory the handlers write exports to, exportOutputDir in the
+// working directory unless the server set one
+func (h *DriftHandlers) exportDir() string {
+	if h.outputDir != "" {
+		return h.outputDir
+	}
+	return exportOutputDir
+}
+
+// exportFilename names an export after its kind and time, with a random part so the names of
+// other users' exports cannot be guessed
+func exportFilename(prefix, extension string) string {
+	return fmt.Sprintf("%s-%s-%s.%s", prefix, time.Now().UTC().Format("20060102-150405"), uuid.New().String(), extension)
+}
+
+// exportOwnerDir returns the subdirectory of dir holding a user's exports. Users are kept
+// apart by directory so each export is only served to the user who requested it.
+func exportOwnerDir(dir, user string) string {
+	sum := sha256.Sum256([]byte(user))
+	return filepath.Join(dir, hex.EncodeToString(sum[:16]))
+}
+
+// saveExport streams an export when inline, otherwise writes it to the requesting user's
+// subdirectory of dir and describes where it can be downloaded
+func saveExport(w http.ResponseWriter, r *http.Request, dir, filename, format, contentType string, data []byte, count int, inline bool) {
+	// Inline exports skip the disk entirely so they survive ephemeral containers
+	if inline {
+		writeExportFile(w, filename, contentType, data)
+		return
+	}
+
+	dir = exportOwnerDir(dir, requestUser(r))
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		response := NewResponseWriter(w)
+		response.WriteInternalError("Failed to create export directory: " + err.Error())
+		return
+	}
+
+	path, err := dataFilePath(dir, filename)
+	if err != nil {
+		response := NewResponseWriter(w)
+		response.WriteValidationError("Invalid export file name", err.Error())
+		return
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		response := NewResponseWriter(w)
+		response.WriteInternalError("Failed to write export file: " + err.Error())
+		return
+	}
+
+	response := NewResponseWriter(w)
+	response.WriteCreated(ExportResponse{
+		Filename:   filename,
+		Format:     format,
+		Size:       int64(len(data)),
+		Path:       path,
+		URL:        "/outputs/" + filename,
+		Count:      count,
+		ExportedAt: time.Now().UTC(),
+	})
+}
+
+// handleOutputFiles serves the requesting user's previously exported files from the output
+// directory. Other users' exports are not found.
+func (s *Server) handleOutputFiles(w http.ResponseWriter, r *http.Request) {
+	// Only serve files directly inside the user's output directory
+	name := strings.TrimPrefix(r.URL.Path, "/outputs/")
+	path, err := dataFilePath(exportOwnerDir(s.outputDir(), requestUser(r)), name)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	if _, err := os.Stat(path); err != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", name))
+	http.ServeFile(w, r, path)
+}
+
+// writeExportFile streams export bytes as a file download
+func writeExportFile(w http.ResponseWriter, filename, contentType string, data []byte) {
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+	w.WriteHeader(http.StatusOK)
+	w.Write(data)
+}
+
+// renderDriftExport renders drift results in the requested format
+func renderDriftExport(results []*models.DriftResult, format string) ([]byte, string, error) {
+	switch strings.ToLower(format) {
+	case "json":
+		data, err := json.MarshalIndent(results, "", "  ")
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to marshal drift results: %w", err)
+		}
+		return data, "application/json", nil
+	case "csv":
+		var buf bytes.Buffer
+		writer := csv.NewWriter(&buf)
+		writer.Write([]string{"id", "timestamp", "provider", "status", "drift_count", "duration"})
+		for _, result := range results {
+			writer.Write([]string{
+				result.ID,
+				result.Timestamp.UTC().Format(time.RFC3339),
+				result.Provider,
+				result.Status,
+				strconv.Itoa(result.DriftCount),
+				result.Duration.String(),
+			})
+		}
+		writer.Flush()
+		if err := writer.Error(); err != nil {
+			return nil, "", fmt.Errorf("failed to write csv: %w", err)
+		}
+		return buf.Bytes(), "text/csv", nil
+	default:
+		return nil, "", fmt.Errorf("unsupported export format: %s", format)
+	}
+}
@@ -0,0 +1,238 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/catherinevee/driftmgr/internal/drift/comparator"
+	"github.com/catherinevee/driftmgr/internal/drift/detector"
+	"github.com/catherinevee/driftmgr/internal/models"
+	types "github.com/catherinevee/driftmgr/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderDriftExport(t *testing.T) {
+	results := []*models.DriftResult{
+		{
+			ID:         "drift-1",
+			Timestamp:  time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+			Provider:   "aws",
+			Status:     "completed",
+			DriftCount: 3,
+		},
+	}
+
+	t.Run("json", func(t *testing.T) {
+		data, contentType, err := renderDriftExport(results, "json")
+		require.NoError(t, err)
+		assert.Equal(t, "application/json", contentType)
+		assert.Contains(t, string(data), "drift-1")
+	})
+
+	t.Run("csv", func(t *testing.T) {
+		data, contentType, err := renderDriftExport(results, "CSV")
+		require.NoError(t, err)
+		assert.Equal(t, "text/csv", contentType)
+
+		lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+		require.Len(t, lines, 2)
+		assert.True(t, strings.HasPrefix(lines[1], "drift-1,2024-01-02T03:04:05Z,aws,completed,3"))
+	})
+
+	t.Run("unsupported", func(t *testing.T) {
+		_, _, err := renderDriftExport(results, "xlsx")
+		assert.Error(t, err)
+	})
+}
+
+func TestWriteExportFile(t *testing.T) {
+	w := httptest.NewRecorder()
+
+	writeExportFile(w, "drift-export.csv", "text/csv", []byte("id\n"))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "text/csv", w.Header().Get("Content-Type"))
+	assert.Equal(t, `attachment; filename="drift-export.csv"`, w.Header().Get("Content-Disposition"))
+	assert.Equal(t, "3", w.Header().Get("Content-Length"))
+	assert.Equal(t, "id\n", w.Body.String())
+}
+
+func TestHandleOutputFilesRejectsTraversal(t *testing.T) {
+	server := &Server{}
+
+	req := httptest.NewRequest("GET", "/outputs/..%2F..%2Fetc%2Fpasswd", nil)
+	req.URL.Path = "/outputs/../../etc/passwd"
+	w := httptest.NewRecorder()
+
+	server.handleOutputFiles(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestDataFilePath(t *testing.T) {
+	path, err := dataFilePath("data", "drift-export.json")
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join("data", "drift-export.json"), path)
+
+	for _, name := range []string{"../../etc/x", "..", "", ".hidden", "sub/file", `..\windows`, "/etc/passwd"} {
+		_, err := dataFilePath("data", name)
+		assert.ErrorIs(t, err, errInvalidFilename, name)
+	}
+}
+
+func TestDataDir(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv(dataDirEnv, dir)
+	server := &Server{config: &Config{}}
+	assert.Equal(t, filepath.Join(dir, exportOutputDir), server.outputDir())
+	require.NoError(t, server.prepareDataDir())
+	entries, err := os.ReadDir(server.outputDir())
+	require.NoError(t, err)
+	assert.Empty(t, entries, "the write check leaves nothing behind")
+
+	server.config.DataDir = filepath.Join(dir, "configured")
+	assert.Equal(t, filepath.Join(dir, "configured", exportOutputDir), server.outputDir())
+
+	w := httptest.NewRecorder()
+	saveExport(w, httptest.NewRequest("POST", "/api/v1/export", nil), server.outputDir(), "inventory.json", "json", "application/json", []byte("[]"), 0, false)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	_, err = os.Stat(filepath.Join(exportOwnerDir(server.outputDir(), "anonymous"), "inventory.json"))
+	assert.NoError(t, err)
+
+	w = httptest.NewRecorder()
+	server.handleOutputFiles(w, httptest.NewRequest("GET", "/outputs/inventory.json", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestExportsAreOnlyServedToTheirOwner(t *testing.T) {
+	server := &Server{config: &Config{DataDir: t.TempDir()}}
+
+	var export ExportResponse
+	w := serveJSON(t, func(w http.ResponseWriter, r *http.Request) {
+		saveExport(w, r, server.outputDir(), exportFilename("inventory", "json"), "json", "application/json", []byte("[]"), 0, false)
+	}, asUser(httptest.NewRequest("POST", "/api/v1/export", nil), "jane", false), &export)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	assert.Regexp(t, `^inventory-\d{8}-\d{6}-[0-9a-f-]{36}\.json$`, export.Filename, "export names are not guessable")
+
+	w = httptest.NewRecorder()
+	server.handleOutputFiles(w, asUser(httptest.NewRequest("GET", export.URL, nil), "joe", false))
+	assert.Equal(t, http.StatusNotFound, w.Code, "another user's export is not served")
+
+	w = httptest.NewRecorder()
+	server.handleOutputFiles(w, asUser(httptest.NewRequest("GET", export.URL, nil), "jane", false))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "[]", w.Body.String())
+}
+
+func TestRenderDriftSARIF(t *testing.T) {
+	drifts := map[string]*detector.DriftResult{
+		"drift-1": {
+			Resource:     "i-123",
+			ResourceType: "aws_instance",
+			Provider:     "aws",
+			DriftType:    detector.ConfigurationDrift,
+			Severity:     detector.SeverityMedium,
+			Differences: []comparator.Difference{
+				{Path: "instance_type", Message: "Field instance_type changed"},
+				{Path: "ingress[0].cidr_blocks"},
+			},
+		},
+		"drift-2": {
+			Resource:     "i-456",
+			ResourceType: "aws_instance",
+			Provider:     "aws",
+			DriftType:    detector.ConfigurationDrift,
+			Severity:     detector.SeverityCritical,
+			Differences:  []comparator.Difference{{Path: "instance_type"}},
+		},
+		"drift-3": {
+			Resource:     "arn:aws:s3:::logs",
+			ResourceType: "aws_s3_bucket",
+			Provider:     "aws",
+			DriftType:    detector.ResourceUnmanaged,
+			Severity:     detector.SeverityLow,
+		},
+	}
+
+	data, err := renderDriftSARIF([]string{"drift-1", "drift-2", "drift-3"}, drifts)
+	require.NoError(t, err)
+	require.NoError(t, validateSARIF(data))
+
+	var log sarifLog
+	require.NoError(t, json.Unmarshal(data, &log))
+	assert.Equal(t, "2.1.0", log.Version)
+	require.Len(t, log.Runs, 1)
+	run := log.Runs[0]
+
+	var ruleIDs []string
+	for _, rule := range run.Tool.Driver.Rules {
+		ruleIDs = append(ruleIDs, rule.ID)
+	}
+	assert.Equal(t, []string{"aws_instance/instance_type", "aws_instance/ingress.cidr_blocks", "aws_s3_bucket/unmanaged"}, ruleIDs)
+	assert.Equal(t, "error", run.Tool.Driver.Rules[0].DefaultConfiguration.Level, "a rule takes its most severe result's level")
+
+	require.Len(t, run.Results, 4)
+	assert.Equal(t, "warning", run.Results[0].Level)
+	assert.Equal(t, "aws_instance i-123: Field instance_type changed", run.Results[0].Message.Text)
+	assert.Equal(t, "error", run.Results[2].Level)
+	assert.Equal(t, 0, run.Results[2].RuleIndex)
+	assert.NotEqual(t, run.Results[0].PartialFingerprints, run.Results[2].PartialFingerprints, "each resource is its own alert")
+
+	unmanaged := run.Results[3]
+	assert.Equal(t, "note", unmanaged.Level)
+	assert.Equal(t, "aws/arn:aws:s3:::logs", unmanaged.Locations[0].PhysicalLocation.ArtifactLocation.URI)
+	assert.Equal(t, "arn:aws:s3:::logs", unmanaged.Locations[0].LogicalLocations[0].FullyQualifiedName)
+
+	empty, err := renderDriftSARIF(nil, nil)
+	require.NoError(t, err)
+	assert.Contains(t, string(empty), `"results": []`)
+}
+
+func TestValidateSARIF(t *testing.T) {
+	assert.ErrorContains(t, validateSARIF([]byte(`{"version": "2.0.0", "runs": []}`)), "version must be 2.1.0")
+	assert.ErrorContains(t, validateSARIF([]byte(`{"version": "2.1.0"}`)), "runs is required")
+	err := validateSARIF([]byte(`{"version": "2.1.0", "runs": [{
+		"tool": {"driver": {"name": "driftmgr", "rules": [{"id": "a"}, {"id": "a"}]}},
+		"results": [{"ruleId": "b", "ruleIndex": 0, "level": "fatal", "message": {}}, {"ruleIndex": 5, "message": {"text": "x"}}]
+	}]}`))
+	require.Error(t, err)
+	for _, problem := range []string{"rules[1].id must be unique", "results[0].message.text is required",
+		`level "fatal"`, "results[0].ruleIndex does not name its ruleId", "results[1].ruleIndex is out of range"} {
+		assert.Contains(t, err.Error(), problem)
+	}
+}
+
+func TestExportDriftJUnit(t *testing.T) {
+	drifts := GetGlobalDriftStore()
+	drifts.Store("junit-test-modified", &detector.DriftResult{
+		Resource: "i-1", ResourceType: "aws_instance", Provider: "junit-test",
+		DriftType: detector.ConfigurationDrift, Severity: detector.SeverityMedium,
+		Differences: []comparator.Difference{{Path: "instance_type", Expected: "t3.micro", Actual: "t3.large"}},
+	})
+	t.Cleanup(func() { drifts.Delete("junit-test-modified") })
+	resources := GetGlobalResourceStore()
+	resources.Save("junit-test", []types.Resource{
+		{ID: "i-1", Type: "aws_instance", Provider: "junit-test"},
+		{ID: "i-2", Type: "aws_instance", Provider: "junit-test"},
+	}, time.Now())
+	t.Cleanup(func() { resources.Save("junit-test", nil, time.Now()) })
+
+	w := httptest.NewRecorder()
+	NewDriftHandlers(nil).ExportDriftResults(w, httptest.NewRequest(http.MethodPost, "/api/v1/drift/export",
+		strings.NewReader(`{"format": "junit", "provider": "junit-test", "inline": true}`)))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, "application/xml", w.Header().Get("Content-Type"))
+
+	body := w.Body.String()
+	assert.Contains(t, body, `<testsuite name="junit-test" tests="2" failures="1"`)
+	assert.Contains(t, body, `<testcase name="i-2" classname="junit-test.aws_instance" time="0.000"></testcase>`,
+		"discovered resources without drift pass")
+	assert.Contains(t, body, "instance_type: expected t3.micro, actual t3.large")
+}
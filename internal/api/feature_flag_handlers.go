@@ -0,0 +1,114 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/catherinevee/driftmgr/internal/featureflags"
+)
+
+const (
+	// featureFlagStoreDatabase is the Config.FeatureFlagStore value that keeps feature flags
+	// in the server's database
+	featureFlagStoreDatabase = "database"
+
+	// featureFlagFile is the file in the data directory feature flags are kept in by default
+	featureFlagFile = "feature_flags.json"
+)
+
+// handleListFeatureFlags handles GET /api/v1/feature-flags, returning every feature flag
+// with its targeting
+func (s *Server) handleListFeatureFlags(w http.ResponseWriter, r *http.Request) {
+	if !s.featureFlagsAvailable(w) {
+		return
+	}
+	s.writeFeatureFlags(w, r, http.StatusOK)
+}
+
+// handleEvaluateFeatureFlags handles GET /api/v1/feature-flags/evaluate?account=, returning
+// whether each flag is on for the authenticated user acting on the account
+func (s *Server) handleEvaluateFeatureFlags(w http.ResponseWriter, r *http.Request) {
+	if !s.featureFlagsAvailable(w) {
+		return
+	}
+
+	user, account := requestUser(r), r.URL.Query().Get("account")
+	evaluated, err := featureflags.EvaluateAll(r.Context(), s.services.FeatureFlags, user, account)
+	if err != nil {
+		s.writeError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to evaluate feature flags: %v", err))
+		return
+	}
+	s.writeJSON(w, http.StatusOK, map[string]interface{}{
+		"user":    user,
+		"account": account,
+		"flags":   evaluated,
+	})
+}
+
+// handleSetFeatureFlag handles PUT /api/v1/feature-flags/{name}, creating or replacing a
+// feature flag and returning every feature flag
+func (s *Server) handleSetFeatureFlag(w http.ResponseWriter, r *http.Request) {
+	if !s.featureFlagsAvailable(w) {
+		return
+	}
+
+	var flag featureflags.Flag
+	if err := decodeRequest(r, &flag); err != nil {
+		s.writeRequestError(w, err)
+		return
+	}
+	flag.Name = r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:]
+	flag.UpdatedBy = requestUser(r)
+	if err := flag.Validate(); err != nil {
+		s.writeError(w, http.StatusBadRequest, fmt.Sprintf("Invalid feature flag: %v", err))
+		return
+	}
+
+	if err := s.services.FeatureFlags.Set(r.Context(), &flag); err != nil {
+		s.writeError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to set feature flag: %v", err))
+		return
+	}
+	s.writeFeatureFlags(w, r, http.StatusOK)
+}
+
+// handleDeleteFeatureFlag handles DELETE /api/v1/feature-flags/{name}, removing a feature
+// flag and returning every feature flag
+func (s *Server) handleDeleteFeatureFlag(w http.ResponseWriter, r *http.Request) {
+	if !s.featureFlagsAvailable(w) {
+		return
+	}
+
+	name := r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:]
+	err := s.services.FeatureFlags.Delete(r.Context(), name)
+	switch {
+	case errors.Is(err, featureflags.ErrFlagNotFound):
+		s.writeError(w, http.StatusNotFound, fmt.Sprintf("Feature flag %s not found", name))
+		return
+	case err != nil:
+		s.writeError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to delete feature flag: %v", err))
+		return
+	}
+	s.writeFeatureFlags(w, r, http.StatusOK)
+}
+
+// featureFlagsAvailable writes 503 Service Unavailable and returns false when feature flags
+// cannot be stored
+func (s *Server) featureFlagsAvailable(w http.ResponseWriter) bool {
+	if s.services == nil || s.services.FeatureFlags == nil {
+		s.writeError(w, http.StatusServiceUnavailable, "Feature flags not available")
+		return false
+	}
+	return true
+}
+
+// writeFeatureFlags writes every feature flag with status
+func (s *Server) writeFeatureFlags(w http.ResponseWriter, r *http.Request, status int) {
+	flags, err := s.services.FeatureFlags.List(r.Context())
+	if err != nil {
+		s.writeError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to list feature flags: %v", err))
+		return
+	}
+	s.writeJSON(w, status, flags)
+}
@@ -0,0 +1,61 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/catherinevee/driftmgr/internal/featureflags"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFeatureFlags(t *testing.T) {
+	server := &Server{config: &Config{DataDir: t.TempDir()}, services: &Services{}}
+	server.services.FeatureFlags = server.featureFlagStore()
+	assert.IsType(t, &featureflags.FileStore{}, server.services.FeatureFlags, "flags are kept in a file by default")
+
+	for path, body := range map[string]string{
+		"/api/v1/feature-flags/Bad%20Name": `{"enabled": true}`,
+		"/api/v1/feature-flags/rollout":    `{"enabled": true, "percentage": 150}`,
+		"/api/v1/feature-flags/broken":     `{"enabled": `,
+	} {
+		w := httptest.NewRecorder()
+		server.handleSetFeatureFlag(w, httptest.NewRequest(http.MethodPut, path, strings.NewReader(body)))
+		assert.Equal(t, http.StatusBadRequest, w.Code, path)
+	}
+
+	w := httptest.NewRecorder()
+	server.handleSetFeatureFlag(w, httptest.NewRequest(http.MethodPut, "/api/v1/feature-flags/remediation.auto-approve",
+		strings.NewReader(`{"name": "ignored", "enabled": true, "accounts": ["123456789012"]}`)))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var flags []*featureflags.Flag
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &flags))
+	require.Len(t, flags, 1)
+	assert.Equal(t, "remediation.auto-approve", flags[0].Name, "the name comes from the path")
+	assert.Equal(t, "anonymous", flags[0].UpdatedBy)
+	assert.FileExists(t, filepath.Join(server.dataDir(), featureFlagFile))
+
+	var evaluated struct {
+		Flags map[string]bool `json:"flags"`
+	}
+	for account, want := range map[string]bool{"123456789012": true, "210987654321": false} {
+		w = httptest.NewRecorder()
+		server.handleEvaluateFeatureFlags(w, httptest.NewRequest(http.MethodGet, "/api/v1/feature-flags/evaluate?account="+account, nil))
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &evaluated))
+		assert.Equal(t, map[string]bool{"remediation.auto-approve": want}, evaluated.Flags, account)
+	}
+
+	w = httptest.NewRecorder()
+	server.handleDeleteFeatureFlag(w, httptest.NewRequest(http.MethodDelete, "/api/v1/feature-flags/remediation.auto-approve", nil))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.JSONEq(t, `[]`, w.Body.String())
+
+	w = httptest.NewRecorder()
+	server.handleDeleteFeatureFlag(w, httptest.NewRequest(http.MethodDelete, "/api/v1/feature-flags/remediation.auto-approve", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
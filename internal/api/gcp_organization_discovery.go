@@ -0,0 +1,141 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/catherinevee/driftmgr/internal/auth"
+	"github.com/catherinevee/driftmgr/internal/jobs"
+	gcpprovider "github.com/catherinevee/driftmgr/internal/providers/gcp"
+	"github.com/catherinevee/driftmgr/internal/state"
+	"github.com/catherinevee/driftmgr/internal/websocket"
+	"github.com/catherinevee/driftmgr/pkg/models"
+)
+
+// handleDiscoverGCPOrganization handles POST /api/v1/discover/gcp/organization. It lists the
+// projects under a GCP organization or folder through the Cloud Resource Manager API,
+// descending into its folders, and discovers each active project with the server's
+// credentials, a few projects at a time. Each project's resources carry its project ID as
+// their account and are recorded and rolled up as handleDiscoverOrganization does for AWS
+// accounts. Projects outside the caller's scopes are skipped.
+func (s *Server) handleDiscoverGCPOrganization(w http.ResponseWriter, r *http.Request) {
+	var request GCPOrganizationDiscoveryRequest
+	if err := decodeRequest(r, &request); err != nil {
+		s.writeRequestError(w, err)
+		return
+	}
+	parent, err := gcpprovider.OrganizationParent(request.OrganizationID, request.FolderID)
+	if err != nil {
+		s.writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	// Scopes are checked now, while the request's credentials are at hand
+	ctx := r.Context()
+	permitted := func(project gcpprovider.Project) bool {
+		return auth.CheckScope(ctx, "gcp", project.ID) == nil
+	}
+
+	jobID := jobIDOrNew(request.JobID)
+	run := func(ctx context.Context) (interface{}, error) {
+		update := websocket.NewProgressUpdate(jobID, "started", 0, 0)
+		update.Message = fmt.Sprintf("Listing the projects of %s", parent)
+		s.broadcastDiscoveryProgress(update)
+
+		provider := gcpprovider.NewGCPProviderComplete("")
+		if err := provider.Connect(ctx); err != nil {
+			return nil, err
+		}
+		discovery, err := provider.DiscoverOrganization(ctx, parent, gcpprovider.OrganizationDiscoveryOptions{
+			Include:     request.IncludeProjects,
+			Exclude:     request.ExcludeProjects,
+			Filter:      permitted,
+			Concurrency: request.Concurrency,
+			Progress: func(done, total int, project gcpprovider.ProjectDiscovery) {
+				update := websocket.NewProgressUpdate(jobID, "discovering", done, total)
+				update.Message = fmt.Sprintf("Discovered %d resources in project %s", len(project.Resources), project.Project.ID)
+				if project.Error != "" {
+					update.Message = fmt.Sprintf("Failed to discover project %s: %s", project.Project.ID, project.Error)
+				}
+				s.broadcastDiscoveryProgress(update)
+			},
+		})
+		if err != nil {
+			return nil, err
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		return recordGCPOrganizationDiscovery(jobID, discovery, time.Now().UTC()), nil
+	}
+
+	discoverRequest := DiscoverRequest{Provider: "gcp"}
+	if request.Async {
+		if !s.submitDiscovery(context.Background(), w, jobID, discoverRequest, requestUser(r), run) {
+			return
+		}
+		go s.finishDiscovery(jobID, "gcp")
+		s.writeJSON(w, http.StatusAccepted, JobAcceptedResponse{
+			JobID:     jobID,
+			Status:    jobs.StatusQueued,
+			StatusURL: "/api/v1/jobs/" + jobID,
+		})
+		return
+	}
+
+	if !s.submitDiscovery(r.Context(), w, jobID, discoverRequest, requestUser(r), run) {
+		return
+	}
+	job := s.finishDiscovery(jobID, "gcp")
+	switch job.Status {
+	case jobs.StatusCompleted:
+		s.writeJSON(w, http.StatusOK, job.Result)
+	case jobs.StatusCancelled:
+		s.writeError(w, statusClientClosedRequest, "Discovery was cancelled")
+	default:
+		s.writeError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to discover GCP organization: %s", job.Error))
+	}
+}
+
+// recordGCPOrganizationDiscovery saves each successfully discovered project as a discovery
+// snapshot of its own and builds the response with each project's roll-up
+func recordGCPOrganizationDiscovery(jobID string, discovery *gcpprovider.OrganizationDiscovery, discoveredAt time.Time) *GCPOrganizationDiscoveryResponse {
+	response := &GCPOrganizationDiscoveryResponse{
+		JobID:        jobID,
+		DiscoveredAt: discoveredAt,
+		Parent:       discovery.Parent,
+		Projects:     make([]ProjectDriftSummary, 0, len(discovery.Projects)),
+		Skipped:      discovery.Skipped,
+		Resources:    make(map[string][]models.Resource, len(discovery.Projects)),
+	}
+	drifts := GetGlobalDriftStore().List()
+	classifier := state.NewManagementClassifier()
+
+	for _, project := range discovery.Projects {
+		summary := ProjectDriftSummary{Project: project.Project, Error: project.Error}
+		if project.Error != "" {
+			// A failed project would show everything as removed, so it is not a snapshot
+			response.FailedProjects++
+			response.Projects = append(response.Projects, summary)
+			continue
+		}
+
+		resources := classifier.Annotate(project.Resources)
+		scope := discoveryScope("gcp", nil, project.Project.ID)
+		delta, drifted := recordAccountSnapshot(scope, "gcp", project.Project.ID, resources, discoveredAt, drifts)
+
+		summary.Resources = len(resources)
+		summary.Added = len(delta.Added)
+		summary.Removed = len(delta.Removed)
+		summary.Changed = len(delta.Changed)
+		summary.Drifted = drifted
+		if drifted > 0 {
+			response.DriftedProjects++
+		}
+		response.Projects = append(response.Projects, summary)
+		response.Resources[project.Project.ID] = resources
+		response.TotalResources += len(resources)
+	}
+	return response
+}
@@ -0,0 +1,50 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	gcpprovider "github.com/catherinevee/driftmgr/internal/providers/gcp"
+	"github.com/catherinevee/driftmgr/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleDiscoverGCPOrganizationValidation(t *testing.T) {
+	server := &Server{config: &Config{}}
+	for _, body := range []string{`{}`, `{"organization_id": "1", "folder_id": "2"}`, `not json`} {
+		w := httptest.NewRecorder()
+		server.handleDiscoverGCPOrganization(w, httptest.NewRequest(http.MethodPost, "/api/v1/discover/gcp/organization", strings.NewReader(body)))
+		assert.Equal(t, http.StatusBadRequest, w.Code, body)
+	}
+}
+
+func TestRecordGCPOrganizationDiscovery(t *testing.T) {
+	discovery := &gcpprovider.OrganizationDiscovery{
+		Parent: "folders/42",
+		Projects: []gcpprovider.ProjectDiscovery{
+			{
+				Project:   gcpprovider.Project{ID: "gcp-org-test-web", State: "ACTIVE"},
+				Resources: []models.Resource{{ID: "gcp-org-test-bucket", Provider: "gcp", AccountID: "gcp-org-test-web"}},
+			},
+			{Project: gcpprovider.Project{ID: "gcp-org-test-denied", State: "ACTIVE"}, Error: "permission denied"},
+		},
+		Skipped: []gcpprovider.Project{{ID: "gcp-org-test-old", State: "DELETE_REQUESTED"}},
+	}
+
+	response := recordGCPOrganizationDiscovery("job-gcp-org", discovery, time.Now().UTC())
+	require.Len(t, response.Projects, 2)
+	assert.Equal(t, "folders/42", response.Parent)
+	assert.Equal(t, 1, response.TotalResources)
+	assert.Equal(t, 1, response.FailedProjects)
+	assert.Equal(t, 1, response.Projects[0].Added)
+	assert.Contains(t, response.Resources, "gcp-org-test-web")
+	assert.Len(t, response.Skipped, 1)
+
+	snapshot, ok := GetGlobalResourceStore().Last(discoveryScope("gcp", nil, "gcp-org-test-web"))
+	require.True(t, ok)
+	assert.Len(t, snapshot.Resources, 1)
+}
@@ -0,0 +1,71 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/catherinevee/driftmgr/internal/remediation/tfimport"
+	"github.com/catherinevee/driftmgr/pkg/models"
+)
+
+// maxHCLResources caps how many resources a single HCL generation request may include
+const maxHCLResources = 1000
+
+// GenerateHandlers handles code generation API endpoints
+type GenerateHandlers struct {
+	hclGenerator *tfimport.HCLGenerator
+}
+
+// NewGenerateHandlers creates a new GenerateHandlers instance
+func NewGenerateHandlers() *GenerateHandlers {
+	return &GenerateHandlers{
+		hclGenerator: tfimport.NewHCLGenerator(),
+	}
+}
+
+// GenerateHCL handles POST /api/v1/generate/hcl. Resources outside the user's scopes are left
+// out; those without an account_id need a scope covering their whole provider.
+func (h *GenerateHandlers) GenerateHCL(w http.ResponseWriter, r *http.Request) {
+	// Set common headers
+	SetCommonHeaders(w)
+
+	var hclRequest HCLGenerationRequest
+	if err := decodeRequest(r, &hclRequest); err != nil {
+		response := NewResponseWriter(w)
+		response.WriteRequestError(err)
+		return
+	}
+
+	if len(hclRequest.Resources) == 0 {
+		response := NewResponseWriter(w)
+		response.WriteValidationError("No resources provided", "resources cannot be empty")
+		return
+	}
+	if len(hclRequest.Resources) > maxHCLResources {
+		response := NewResponseWriter(w)
+		response.WriteValidationError("Too many resources", "a request may include at most 1000 resources")
+		return
+	}
+
+	resources := make([]models.Resource, 0, len(hclRequest.Resources))
+	for _, resource := range hclRequest.Resources {
+		resources = append(resources, models.Resource{
+			ID:         resource.ID,
+			Name:       resource.Name,
+			Type:       resource.Type,
+			Provider:   resource.Provider,
+			AccountID:  resource.AccountID,
+			Region:     resource.Region,
+			Tags:       resource.Tags,
+			Attributes: resource.Attributes,
+		})
+	}
+
+	result := h.hclGenerator.Generate(scopedResources(r.Context(), resources))
+
+	response := NewResponseWriter(w)
+	response.WriteSuccess(result, &APIMeta{
+		Count:     len(result.Resources),
+		Timestamp: time.Now().UTC().Format(time.RFC3339),
+	})
+}
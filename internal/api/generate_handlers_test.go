@@ -0,0 +1,32 @@
+package api
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/catherinevee/driftmgr/internal/remediation/tfimport"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateHCLFiltersByScope(t *testing.T) {
+	request := HCLGenerationRequest{Resources: []UnmanagedResource{
+		{ID: "i-111", Name: "web", Type: "aws_instance", Provider: "aws", AccountID: "111111111111"},
+		{ID: "i-222", Name: "db", Type: "aws_instance", Provider: "aws", AccountID: "222222222222"},
+	}}
+
+	var result tfimport.HCLResult
+	req := asUser(jsonRequest(t, "/api/v1/generate/hcl", request), "jane", false, "aws:111111111111")
+	w := serveJSON(t, NewGenerateHandlers().GenerateHCL, req, &result)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var ids []string
+	for _, resource := range result.Resources {
+		ids = append(ids, resource.ResourceID)
+	}
+	for _, skipped := range result.Skipped {
+		ids = append(ids, skipped.ResourceID)
+	}
+	assert.Equal(t, []string{"i-111"}, ids, "resources outside the user's scopes are left out")
+	assert.NotContains(t, result.HCL, "i-222")
+}
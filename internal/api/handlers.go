@@ -3,31 +3,37 @@ package api
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/catherinevee/driftmgr/internal/auth"
+	"github.com/catherinevee/driftmgr/internal/compliance"
+	"github.com/catherinevee/driftmgr/internal/cost"
+	"github.com/catherinevee/driftmgr/internal/jobs"
+	"github.com/catherinevee/driftmgr/internal/metrics"
+	internalModels "github.com/catherinevee/driftmgr/internal/models"
+	awsprovider "github.com/catherinevee/driftmgr/internal/providers/aws"
+	kubernetesprovider "github.com/catherinevee/driftmgr/internal/providers/kubernetes"
 	"github.com/catherinevee/driftmgr/internal/security"
+	"github.com/catherinevee/driftmgr/internal/services"
+	"github.com/catherinevee/driftmgr/internal/shared/cache"
+	"github.com/catherinevee/driftmgr/internal/state"
+	"github.com/catherinevee/driftmgr/internal/terragrunt/resolver"
+	"github.com/catherinevee/driftmgr/internal/version"
+	"github.com/catherinevee/driftmgr/internal/websocket"
 	"github.com/catherinevee/driftmgr/pkg/models"
 )
 
 // Helper methods
 
-// handleCORS handles CORS headers
-func (s *Server) handleCORS(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Access-Control-Allow-Origin", "*")
-	w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-	w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
-
-	if r.Method == "OPTIONS" {
-		w.WriteHeader(http.StatusOK)
-		return
-	}
-}
-
 // handleRateLimit handles rate limiting
 func (s *Server) handleRateLimit(w http.ResponseWriter, r *http.Request) bool {
 	// Simplified rate limiting - in a real system, you'd use a proper rate limiter
@@ -108,7 +114,7 @@ func HealthHandler() http.HandlerFunc {
 		response := map[string]interface{}{
 			"status":    "healthy",
 			"timestamp": time.Now().Unix(),
-			"version":   "1.0.0",
+			"version":   version.Version,
 		}
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusOK)
@@ -302,20 +308,14 @@ func ConfigHandler() http.HandlerFunc {
 
 // Handler methods
 
-// handleHealth handles health check
-func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
-	s.writeJSON(w, http.StatusOK, map[string]string{
-		"status": "healthy",
-		"time":   time.Now().Format(time.RFC3339),
-	})
+// handleMetrics serves Prometheus metrics
+func (s *Server) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	metrics.Handler().ServeHTTP(w, r)
 }
 
-// handleVersion handles version endpoint
+// handleVersion handles GET /api/v1/version with the build information set at link time
 func (s *Server) handleVersion(w http.ResponseWriter, r *http.Request) {
-	s.writeJSON(w, http.StatusOK, map[string]string{
-		"version": "3.0.0",
-		"build":   "Full Feature Release",
-	})
+	s.writeJSON(w, http.StatusOK, version.Get())
 }
 
 // handleGetResources handles GET /api/v1/resources
@@ -327,19 +327,524 @@ func (s *Server) handleGetResources(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Discover real cloud resources
-	resources, err := s.discoverCloudResources(provider)
-	if err != nil {
+	resources, err := s.discoverCloudResources(r.Context(), provider)
+	var timeoutErr *discoveryTimeoutError
+	if err != nil && !errors.As(err, &timeoutErr) {
 		s.writeError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to discover resources: %v", err))
 		return
 	}
 
-	s.writeJSON(w, http.StatusOK, map[string]interface{}{
+	response := map[string]interface{}{
 		"resources": resources,
 		"total":     len(resources),
 		"provider":  provider,
+		"timed_out": timeoutErr != nil,
+	}
+	if timeoutErr != nil {
+		response["warning"] = timeoutErr.Error() + ", so results are incomplete"
+	}
+	s.writeJSON(w, http.StatusOK, response)
+}
+
+// statusClientClosedRequest is the non-standard status nginx uses for requests that ended
+// before a response, returned for discoveries that were cancelled
+const statusClientClosedRequest = 499
+
+// discoveryJobType is the job queue type of discovery runs
+const discoveryJobType = "discovery"
+
+// handleDiscover handles GET and POST /api/v1/discover. Results are served from the discovery
+// cache while fresh unless refresh is set. In delta mode the result is compared with the
+// previous discovery of the same scope and the added, removed and changed resources returned.
+// Fast mode lists AWS resources through the tagging API rather than every service. Tag filters
+// are given as repeated tag=Key=Value and tag_key=Key query parameters. regions=all discovers
+// every region the provider's credentials have enabled, listed from the provider and cached.
+// Discoveries run through the job queue, which bounds how many run at once. With async set the
+// request returns 202 Accepted once queued and the job is polled at /api/v1/jobs/{job_id};
+// otherwise it waits and stops when the client disconnects. Either can be cancelled with
+// DELETE /api/v1/discover/{job_id}.
+func (s *Server) handleDiscover(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	request := DiscoverRequest{
+		Provider:  query.Get("provider"),
+		Mode:      query.Get("mode"),
+		AccountID: query.Get("account_id"),
+		Refresh:   query.Get("refresh") == "true",
+		JobID:     query.Get("job_id"),
+	}
+	request.Utilization = query.Get("utilization") == "true"
+	request.Async = query.Get("async") == "true"
+	if regions := query.Get("regions"); regions != "" {
+		request.Regions = strings.Split(regions, ",")
+	}
+	for _, tag := range query["tag"] {
+		key, value, ok := strings.Cut(tag, "=")
+		if !ok || key == "" {
+			s.writeError(w, http.StatusBadRequest, fmt.Sprintf("Invalid tag filter %q: must be Key=Value", tag))
+			return
+		}
+		if request.Tags == nil {
+			request.Tags = make(map[string]string)
+		}
+		request.Tags[key] = value
+	}
+	request.TagKeyExists = query["tag_key"]
+	if r.Method == http.MethodPost && r.ContentLength != 0 {
+		if err := decodeJSON(r, &request); err != nil {
+			s.writeRequestError(w, err)
+			return
+		}
+	}
+	if request.Provider == "" {
+		request.Provider = "aws" // Default to AWS
+	}
+	if request.Mode == "" {
+		request.Mode = "full"
+	}
+	// Query parameters are validated with the body, so both report field errors
+	if err := validateRequest(&request); err != nil {
+		s.writeRequestError(w, err)
+		return
+	}
+	if err := auth.CheckScope(r.Context(), request.Provider, request.AccountID); err != nil {
+		s.writeError(w, http.StatusForbidden, err.Error())
+		return
+	}
+
+	jobID := jobIDOrNew(request.JobID)
+	run := func(ctx context.Context) (interface{}, error) {
+		return s.runDiscovery(ctx, request, jobID)
+	}
+
+	if request.Async {
+		// Background discoveries outlive the request, so only cancellation stops them
+		if !s.submitDiscovery(context.Background(), w, jobID, request, requestUser(r), run) {
+			return
+		}
+		go s.finishDiscovery(jobID, request.Provider)
+		s.writeJSON(w, http.StatusAccepted, JobAcceptedResponse{
+			JobID:     jobID,
+			Status:    jobs.StatusQueued,
+			StatusURL: "/api/v1/jobs/" + jobID,
+		})
+		return
+	}
+
+	// Synchronous discoveries also wait for a free slot, and stop if the client disconnects
+	if !s.submitDiscovery(r.Context(), w, jobID, request, requestUser(r), run) {
+		return
+	}
+	job := s.finishDiscovery(jobID, request.Provider)
+	switch job.Status {
+	case jobs.StatusCompleted:
+		s.writeJSON(w, http.StatusOK, job.Result)
+	case jobs.StatusCancelled:
+		s.writeError(w, statusClientClosedRequest, "Discovery was cancelled")
+	default:
+		s.writeError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to discover resources: %s", job.Error))
+	}
+}
+
+// submitDiscovery queues a discovery job, writing the error response itself when the job ID
+// is already in use
+func (s *Server) submitDiscovery(ctx context.Context, w http.ResponseWriter, jobID string, request DiscoverRequest, user string, run jobs.Func) bool {
+	owner := jobs.Owner{User: user, Provider: request.Provider, Account: request.AccountID}
+	if _, err := s.jobManager().Submit(ctx, jobID, discoveryJobType, owner, run); err != nil {
+		if errors.Is(err, jobs.ErrShuttingDown) {
+			s.writeError(w, http.StatusServiceUnavailable, "Server is shutting down")
+			return false
+		}
+		s.writeError(w, http.StatusConflict, fmt.Sprintf("Discovery job %s is already queued or running", jobID))
+		return false
+	}
+	update := websocket.NewProgressUpdate(jobID, string(jobs.StatusQueued), 0, 0)
+	update.Message = fmt.Sprintf("Waiting to discover %s resources", request.Provider)
+	s.broadcastDiscoveryProgress(update)
+	return true
+}
+
+// finishDiscovery waits for a discovery job to finish and broadcasts its outcome
+func (s *Server) finishDiscovery(jobID, provider string) jobs.Job {
+	job, err := s.jobManager().Wait(context.Background(), jobID)
+	if err != nil {
+		return jobs.Job{ID: jobID, Status: jobs.StatusFailed, Error: err.Error()}
+	}
+
+	switch job.Status {
+	case jobs.StatusCompleted:
+		update := websocket.NewProgressUpdate(jobID, "completed", 1, 1)
+		if response, ok := job.Result.(*DiscoverResponse); ok {
+			update.Message = fmt.Sprintf("Discovered %d %s resources", response.Total, provider)
+			if response.Warning != "" {
+				update.Message = response.Warning
+			}
+			update.Resources = response.Total
+			update.MaxResources = response.MaxResources
+		}
+		if response, ok := job.Result.(*OrganizationDiscoveryResponse); ok {
+			update.Message = fmt.Sprintf("Discovered %d resources in %d accounts", response.TotalResources, len(response.Accounts))
+			update.Resources = response.TotalResources
+		}
+		if response, ok := job.Result.(*GCPOrganizationDiscoveryResponse); ok {
+			update.Message = fmt.Sprintf("Discovered %d resources in %d projects", response.TotalResources, len(response.Projects))
+			update.Resources = response.TotalResources
+		}
+		s.broadcastDiscoveryProgress(update)
+	case jobs.StatusCancelled:
+		update := websocket.NewProgressUpdate(jobID, "cancelled", 0, 0)
+		update.Message = fmt.Sprintf("Discovery of %s resources was cancelled", provider)
+		s.broadcastDiscoveryProgress(update)
+	default:
+		update := websocket.NewProgressUpdate(jobID, "failed", 0, 0)
+		update.Error = job.Error
+		s.broadcastDiscoveryProgress(update)
+	}
+	return job
+}
+
+// runDiscovery runs a discovery job: it discovers the request's scope, records the snapshot
+// and builds the response, with the delta against the previous snapshot in delta mode
+func (s *Server) runDiscovery(ctx context.Context, request DiscoverRequest, jobID string) (*DiscoverResponse, error) {
+	// The cache holds the unfiltered scope so tag filters share it; snapshots are kept per filter
+	scope := discoveryScope(request.Provider, request.Regions, request.AccountID)
+	if request.Mode == "fast" {
+		// Fast results carry fewer attributes, so keep them apart from per-service scans
+		scope += ":fast"
+	}
+	if request.Utilization && request.Provider == "aws" {
+		// Utilization metrics cost CloudWatch calls, so enriched results are cached separately
+		scope += ":utilization"
+	}
+	snapshotScope := scope + tagFilterScope(request.Tags, request.TagKeyExists)
+	store := GetGlobalResourceStore()
+	previous, hasPrevious := store.Last(snapshotScope)
+
+	// Scopes keep "all" rather than the regions it stands for, so a region added to the
+	// account extends the same snapshot history
+	regions, err := expandRegions(ctx, request.Provider, request.Regions)
+	if err != nil {
+		return nil, err
+	}
+	request.Regions = regions
+
+	update := websocket.NewProgressUpdate(jobID, "started", 0, 0)
+	update.Message = fmt.Sprintf("Discovering %s resources", request.Provider)
+	update.MaxResources = s.maxDiscoveryResources()
+	s.broadcastDiscoveryProgress(update)
+
+	discovered, cached, err := s.discoverWithCache(ctx, scope, request, jobID)
+	if err != nil {
+		if discovered != nil {
+			// A cancelled run keeps what it found as the job's partial result, but it is
+			// neither a snapshot nor cached
+			limit := discovered.Limit
+			limit.Warning = "Discovery was cancelled, so results are incomplete"
+			return &DiscoverResponse{
+				JobID:          jobID,
+				Provider:       request.Provider,
+				Mode:           request.Mode,
+				DiscoveredAt:   discovered.DiscoveredAt,
+				Total:          len(discovered.Resources),
+				Resources:      discovered.Resources,
+				DiscoveryLimit: limit,
+			}, err
+		}
+		return nil, err
+	}
+
+	resources := models.FilterResourcesByTags(discovered.Resources, request.Tags, request.TagKeyExists)
+	// Record which resources CloudFormation, Pulumi and other tools manage
+	resources = state.NewManagementClassifier().Annotate(resources)
+	models.CategorizeResources(resources)
+	// A timed out or partly failed run would show everything it missed as removed, so it is
+	// not a snapshot
+	if discovered.Limit.complete() && (!hasPrevious || previous.DiscoveredAt.Before(discovered.DiscoveredAt)) {
+		store.Save(snapshotScope, resources, discovered.DiscoveredAt)
+	}
+
+	response := &DiscoverResponse{
+		JobID:          jobID,
+		Provider:       request.Provider,
+		Mode:           request.Mode,
+		DiscoveredAt:   discovered.DiscoveredAt,
+		Cached:         cached,
+		Total:          len(resources),
+		Resources:      resources,
+		DiscoveryLimit: discovered.Limit,
+	}
+	if request.Mode == "delta" {
+		var previousResources []models.Resource
+		if hasPrevious {
+			previousResources = previous.Resources
+			response.Since = &previous.DiscoveredAt
+		}
+		delta := DiffResources(previousResources, resources)
+		response.Delta = &delta
+	}
+	return response, nil
+}
+
+// cachedDiscovery is a discovery result held in the discovery cache
+type cachedDiscovery struct {
+	Resources    []models.Resource `json:"resources"`
+	DiscoveredAt time.Time         `json:"discovered_at"`
+	Limit        DiscoveryLimit    `json:"limit"`
+}
+
+// discoverWithCache returns the cached discovery for a scope when fresh, otherwise runs a
+// live discovery and caches it. The boolean reports whether the result came from the cache.
+// A cancelled discovery returns what it collected along with the error.
+// Live discoveries stop collecting at the configured resource cap, and stream what they
+// collect over WebSocket as it is found.
+func (s *Server) discoverWithCache(ctx context.Context, scope string, request DiscoverRequest, jobID string) (*cachedDiscovery, bool, error) {
+	discoveryCache := s.discoveryCache()
+	if discoveryCache != nil && !request.Refresh {
+		if value, ok := discoveryCache.Get(scope); ok {
+			if discovered, ok := value.(*cachedDiscovery); ok {
+				return discovered, true, nil
+			}
+		}
+	}
+
+	limit := newResourceLimit(s.maxDiscoveryResources())
+	stream := s.newDiscoveryStream(jobID, request)
+	switch {
+	case request.Mode == "fast":
+		if err := s.discoverAWSResourcesFast(ctx, request, limit, stream); err != nil {
+			return partialDiscovery(ctx, limit), false, err
+		}
+	case request.Provider == "kubernetes":
+		if err := s.discoverKubernetesResources(ctx, request, limit, stream); err != nil {
+			return partialDiscovery(ctx, limit), false, err
+		}
+	default:
+		resources, err := s.discoverCloudResources(ctx, request.Provider)
+		var timeoutErr *discoveryTimeoutError
+		switch {
+		case errors.As(err, &timeoutErr):
+			// A timeout still returns what was collected, flagged as incomplete
+			limit.TimeOut(timeoutErr.Timeout, request.Regions...)
+		case err != nil:
+			return nil, false, err
+		}
+		limit.Add(filterDiscoveredResources(resources, request.Regions, request.AccountID))
+		stream.Send(limit.Resources())
+	}
+
+	resources := limit.Resources()
+	if request.Utilization && request.Provider == "aws" {
+		if timeoutErr := s.enrichAWSUtilization(ctx, resources); timeoutErr != nil {
+			limit.TimeOut(timeoutErr.Timeout)
+		}
+	}
+	if estimator := s.costEstimator(); estimator != nil {
+		cost.AnnotateCosts(ctx, estimator, resources)
+	}
+	// A cancelled run may have skipped enrichment, so it is never cached
+	if err := ctx.Err(); err != nil {
+		return partialDiscovery(ctx, limit), false, err
+	}
+
+	discovered := &cachedDiscovery{
+		Resources:    resources,
+		DiscoveredAt: time.Now().UTC(),
+		Limit:        limit.Result(),
+	}
+	if discovered.Limit.Warning != "" {
+		log.Printf("WARNING: %s discovery incomplete: %s", request.Provider, discovered.Limit.Warning)
+	}
+	// Timed out and partly failed runs are partial, so a later request should try again
+	// rather than reuse them
+	if discoveryCache != nil && discovered.Limit.complete() {
+		if err := discoveryCache.Set(scope, discovered); err != nil {
+			log.Printf("Failed to cache discovery for %s: %v", scope, err)
+		}
+	}
+
+	return discovered, false, nil
+}
+
+// partialDiscovery returns what a discovery collected before ctx was cancelled, or nil when
+// it failed for another reason
+func partialDiscovery(ctx context.Context, limit *resourceLimit) *cachedDiscovery {
+	if ctx.Err() == nil {
+		return nil
+	}
+	return &cachedDiscovery{
+		Resources:    limit.Resources(),
+		DiscoveredAt: time.Now().UTC(),
+		Limit:        limit.Result(),
+	}
+}
+
+// enrichAWSUtilization adds CloudWatch utilization to AWS resources region by region. Failures
+// are logged rather than failing the discovery, since the inventory is still valid without them.
+// Collection stops at the aws discovery timeout, reported as a *discoveryTimeoutError.
+func (s *Server) enrichAWSUtilization(ctx context.Context, resources []models.Resource) *discoveryTimeoutError {
+	parent := ctx
+	timeout := s.discoveryTimeout("aws")
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	timedOut := func() *discoveryTimeoutError {
+		if ctx.Err() != nil && parent.Err() == nil {
+			log.Printf("WARNING: utilization collection timed out after %s, so some resources have none", timeout)
+			return &discoveryTimeoutError{Provider: "aws", Timeout: timeout}
+		}
+		return nil
+	}
+
+	byRegion := make(map[string][]int)
+	for i, resource := range resources {
+		byRegion[resource.Region] = append(byRegion[resource.Region], i)
+	}
+
+	lookback := awsprovider.DefaultUtilizationLookback
+	if s.config != nil && s.config.Discovery.UtilizationLookbackDays > 0 {
+		lookback = time.Duration(s.config.Discovery.UtilizationLookbackDays) * 24 * time.Hour
+	}
+
+	for region, indexes := range byRegion {
+		if ctx.Err() != nil {
+			return timedOut()
+		}
+		regional := make([]models.Resource, len(indexes))
+		for i, index := range indexes {
+			regional[i] = resources[index]
+		}
+		if err := awsprovider.NewAWSProvider(region).EnrichUtilization(ctx, regional, lookback); err != nil {
+			log.Printf("Failed to collect utilization in %s: %v", region, err)
+			continue
+		}
+		for i, index := range indexes {
+			resources[index] = regional[i]
+		}
+	}
+	return timedOut()
+}
+
+// discoveryTimeout returns how long a provider's discovery may run: its provider timeout, else
+// the discovery timeout, else the default
+func (s *Server) discoveryTimeout(provider string) time.Duration {
+	if s.config != nil {
+		if seconds := s.config.Discovery.ProviderTimeouts[provider]; seconds > 0 {
+			return time.Duration(seconds) * time.Second
+		}
+		if s.config.Discovery.Timeout > 0 {
+			return time.Duration(s.config.Discovery.Timeout) * time.Second
+		}
+	}
+	return defaultDiscoveryTimeout
+}
+
+// discoveryTimeoutError reports that a provider's discovery ran past its timeout. It unwraps to
+// context.DeadlineExceeded.
+type discoveryTimeoutError struct {
+	Provider string
+	Timeout  time.Duration
+}
+
+func (e *discoveryTimeoutError) Error() string {
+	return fmt.Sprintf("%s discovery timed out after %s", e.Provider, e.Timeout)
+}
+
+func (e *discoveryTimeoutError) Unwrap() error {
+	return context.DeadlineExceeded
+}
+
+// maxDiscoveryResources returns the configured resource cap for a discovery run, 0 meaning none
+func (s *Server) maxDiscoveryResources() int {
+	if s.config == nil || s.config.Discovery.MaxResources == 0 {
+		return defaultDiscoveryMaxResources
+	}
+	if s.config.Discovery.MaxResources < 0 {
+		return 0
+	}
+	return s.config.Discovery.MaxResources
+}
+
+// broadcastDiscoveryProgress records a discovery progress update on its job and publishes it
+// when WebSocket is enabled
+func (s *Server) broadcastDiscoveryProgress(update websocket.ProgressUpdate) {
+	s.jobManager().Report(update.JobID, update.Percentage, update.Message)
+	if s.services == nil || s.services.WebSocket == nil {
+		return
+	}
+	update.User = s.jobUser(update.JobID)
+	s.services.WebSocket.BroadcastProgress(websocket.MessageTypeDiscoveryProgress, update)
+}
+
+// handleCancelDiscovery handles DELETE /api/v1/discover/{job_id}, aborting a queued or running
+// discovery
+func (s *Server) handleCancelDiscovery(w http.ResponseWriter, r *http.Request) {
+	jobID, err := s.parseID(r)
+	if err != nil || jobID == "" {
+		s.writeError(w, http.StatusBadRequest, "Job ID is required")
+		return
+	}
+	job, ok := s.jobManager().Get(jobID)
+	if !ok || job.Type != discoveryJobType || !canAccessJob(r, job) || !s.jobManager().Cancel(jobID) {
+		s.writeError(w, http.StatusNotFound, fmt.Sprintf("No running discovery job %s", jobID))
+		return
+	}
+
+	s.writeJSON(w, http.StatusAccepted, map[string]string{
+		"job_id": jobID,
+		"status": "cancelling",
 	})
 }
 
+// discoveryCache returns the discovery cache, or nil when services are not initialized
+func (s *Server) discoveryCache() *cache.GlobalCache {
+	if s.services == nil {
+		return nil
+	}
+	return s.services.DiscoveryCache
+}
+
+// discoveryScope builds the cache and snapshot key for a provider, region set and account
+func discoveryScope(provider string, regions []string, accountID string) string {
+	sorted := append([]string(nil), regions...)
+	sort.Strings(sorted)
+	return fmt.Sprintf("discovery:%s:%s:%s", provider, strings.Join(sorted, ","), accountID)
+}
+
+// tagFilterScope renders tag filters as a stable scope suffix, empty when there are none
+func tagFilterScope(tags map[string]string, keys []string) string {
+	if len(tags) == 0 && len(keys) == 0 {
+		return ""
+	}
+
+	filters := make([]string, 0, len(tags)+len(keys))
+	for key, value := range tags {
+		filters = append(filters, key+"="+value)
+	}
+	for _, key := range keys {
+		filters = append(filters, key)
+	}
+	sort.Strings(filters)
+	return ":tags:" + strings.Join(filters, ",")
+}
+
+// filterDiscoveredResources keeps resources in the given regions and account; empty filters match everything
+func filterDiscoveredResources(resources []models.Resource, regions []string, accountID string) []models.Resource {
+	regionSet := make(map[string]bool, len(regions))
+	for _, region := range regions {
+		regionSet[strings.TrimSpace(region)] = true
+	}
+
+	filtered := make([]models.Resource, 0, len(resources))
+	for _, resource := range resources {
+		if len(regionSet) > 0 && !regionSet[resource.Region] {
+			continue
+		}
+		if accountID != "" && resource.AccountID != accountID {
+			continue
+		}
+		filtered = append(filtered, resource)
+	}
+	return filtered
+}
+
 // handleDeleteResource handles DELETE /api/v1/resources/{id}
 func (s *Server) handleDeleteResource(w http.ResponseWriter, r *http.Request) {
 	id, err := s.parseID(r)
@@ -407,6 +912,663 @@ func (s *Server) handleGetCostAnalysis(w http.ResponseWriter, r *http.Request) {
 	s.writeJSON(w, http.StatusOK, costAnalysis)
 }
 
+// handleCostSummary handles GET /api/v1/cost/summary. It totals the estimated monthly cost of
+// the most recently discovered resources within the user's scopes by provider, type and
+// region, optionally for one provider.
+func (s *Server) handleCostSummary(w http.ResponseWriter, r *http.Request) {
+	s.writeJSON(w, http.StatusOK, cost.SummarizeCosts(liveResources(r, r.URL.Query().Get("provider"))))
+}
+
+// handleCoverage handles GET /api/v1/coverage. It reports the share of the most recently
+// discovered resources that are managed by the loaded state files, by provider and type,
+// optionally for one provider.
+func (s *Server) handleCoverage(w http.ResponseWriter, r *http.Request) {
+	calculator := state.NewCoverageCalculator()
+	if s.services != nil && s.services.StateService != nil {
+		stateFiles, err := s.services.StateService.ListStateFiles(r.Context(), services.StateFilters{})
+		if err != nil {
+			s.writeError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to list state files: %v", err))
+			return
+		}
+		addFile := addStateFileCoverage(calculator)
+		for _, stateFile := range stateFiles {
+			addFile(stateFile)
+		}
+	}
+
+	resources := liveResources(r, r.URL.Query().Get("provider"))
+	s.writeJSON(w, http.StatusOK, calculator.Calculate(resources))
+}
+
+// handleAnalyzeStateSet handles POST /api/v1/analyze. It merges the resources of several
+// state files into one managed set and analyzes the most recently discovered resources
+// against it, so a resource managed in one state is not unmanaged for being absent from
+// another. A request with an input_type of plan analyzes a saved plan instead.
+func (s *Server) handleAnalyzeStateSet(w http.ResponseWriter, r *http.Request) {
+	var request StateSetAnalysisRequest
+	if err := decodeRequest(r, &request); err != nil {
+		s.writeRequestError(w, err)
+		return
+	}
+	if request.InputType == "plan" {
+		s.analyzePlan(w, request.Plan)
+		return
+	}
+
+	calculator := state.NewCoverageCalculator()
+	ok := s.loadStateSource(w, r, request.StateSource, addStateFileCoverage(calculator), func(i int, parsed *state.TerraformState) {
+		calculator.AddState(fmt.Sprintf("states[%d]", i), parsed)
+	})
+	if !ok {
+		return
+	}
+
+	coverage := calculator.Calculate(liveResources(r, request.Provider))
+	response := StateSetAnalysisResponse{
+		StateFiles: len(request.StateFileIDs) + len(request.States),
+		Coverage:   coverage,
+	}
+	coverage.ApplyTo(&response.Summary)
+	s.writeJSON(w, http.StatusOK, response)
+}
+
+// handleTerragruntAnalyze handles POST /api/v1/terragrunt/analyze. It orders the units of a
+// Terragrunt stack by their dependency blocks and analyzes the most recently discovered
+// resources against the states of all the units as one managed set.
+func (s *Server) handleTerragruntAnalyze(w http.ResponseWriter, r *http.Request) {
+	var request TerragruntAnalysisRequest
+	if err := decodeRequest(r, &request); err != nil {
+		s.writeRequestError(w, err)
+		return
+	}
+	stack, states, ok := s.terragruntStack(w, request.Path, request.States)
+	if !ok {
+		return
+	}
+
+	calculator := state.NewCoverageCalculator()
+	unresolved, err := stack.AddStates(calculator, states)
+	if err != nil {
+		s.writeError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	coverage := calculator.Calculate(liveResources(r, request.Provider))
+	response := TerragruntAnalysisResponse{
+		Stack:      stack,
+		Unresolved: unresolved,
+		Coverage:   coverage,
+	}
+	coverage.ApplyTo(&response.Summary)
+	s.writeJSON(w, http.StatusOK, response)
+}
+
+// terragruntScanJobType is the job queue type of Terragrunt drift scans
+const terragruntScanJobType = "terragrunt_scan"
+
+const (
+	defaultTerragruntScanConcurrency = 4
+	maxTerragruntScanConcurrency     = 16
+)
+
+// handleTerragruntScan handles POST /api/v1/terragrunt/scan. It checks each unit of a
+// Terragrunt stack against the most recently discovered resources, a few units at a time,
+// and returns the drift counts by environment. Progress is broadcast over WebSocket as
+// units finish; with async set the scan runs in the background as a job.
+func (s *Server) handleTerragruntScan(w http.ResponseWriter, r *http.Request) {
+	var request TerragruntScanRequest
+	if err := decodeRequest(r, &request); err != nil {
+		s.writeRequestError(w, err)
+		return
+	}
+	stack, states, ok := s.terragruntStack(w, request.Path, request.States)
+	if !ok {
+		return
+	}
+	concurrency := request.Concurrency
+	if concurrency <= 0 {
+		concurrency = defaultTerragruntScanConcurrency
+	}
+	if concurrency > maxTerragruntScanConcurrency {
+		concurrency = maxTerragruntScanConcurrency
+	}
+
+	// The live resources are read now so the scan sees the caller's scopes
+	live := liveResources(r, request.Provider)
+	jobID := jobIDOrNew(request.JobID)
+	run := func(ctx context.Context) (interface{}, error) {
+		return stack.Scan(ctx, live, resolver.ScanOptions{
+			States:           states,
+			EnvironmentLevel: request.EnvironmentLevel,
+			Concurrency:      concurrency,
+			Progress: func(done, total int, unit resolver.UnitDrift) {
+				update := websocket.NewProgressUpdate(jobID, "scanning", done, total)
+				update.Message = fmt.Sprintf("Scanned %s: %d drifted", unit.Path, unit.Drifted)
+				s.broadcastTerragruntScanProgress(update)
+			},
+		})
+	}
+
+	ctx := r.Context()
+	if request.Async {
+		ctx = context.Background()
+	}
+	owner := jobs.Owner{User: requestUser(r), Provider: request.Provider}
+	if _, err := s.jobManager().Submit(ctx, jobID, terragruntScanJobType, owner, run); err != nil {
+		if errors.Is(err, jobs.ErrShuttingDown) {
+			s.writeError(w, http.StatusServiceUnavailable, "Server is shutting down")
+			return
+		}
+		s.writeError(w, http.StatusConflict, fmt.Sprintf("Job %s is already queued or running", jobID))
+		return
+	}
+	if request.Async {
+		go s.finishTerragruntScan(jobID)
+		s.writeJSON(w, http.StatusAccepted, JobAcceptedResponse{
+			JobID:     jobID,
+			Status:    jobs.StatusQueued,
+			StatusURL: "/api/v1/jobs/" + jobID,
+		})
+		return
+	}
+
+	job := s.finishTerragruntScan(jobID)
+	switch job.Status {
+	case jobs.StatusCompleted:
+		s.writeJSON(w, http.StatusOK, job.Result)
+	case jobs.StatusCancelled:
+		s.writeError(w, statusClientClosedRequest, "Terragrunt scan was cancelled")
+	default:
+		s.writeError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to scan Terragrunt stack: %s", job.Error))
+	}
+}
+
+// finishTerragruntScan waits for a Terragrunt scan job to finish and broadcasts its outcome
+func (s *Server) finishTerragruntScan(jobID string) jobs.Job {
+	job, err := s.jobManager().Wait(context.Background(), jobID)
+	if err != nil {
+		return jobs.Job{ID: jobID, Status: jobs.StatusFailed, Error: err.Error()}
+	}
+
+	switch job.Status {
+	case jobs.StatusCompleted:
+		update := websocket.NewProgressUpdate(jobID, "completed", 1, 1)
+		if matrix, ok := job.Result.(*resolver.DriftMatrix); ok {
+			drifted := 0
+			for _, environment := range matrix.Environments {
+				if matrix.ByEnvironment[environment].Drifted > 0 {
+					drifted++
+				}
+			}
+			update.Message = fmt.Sprintf("%d of %d environments drifted", drifted, len(matrix.Environments))
+		}
+		s.broadcastTerragruntScanProgress(update)
+	case jobs.StatusCancelled:
+		update := websocket.NewProgressUpdate(jobID, "cancelled", 0, 0)
+		update.Message = "Terragrunt scan was cancelled"
+		s.broadcastTerragruntScanProgress(update)
+	default:
+		update := websocket.NewProgressUpdate(jobID, "failed", 0, 0)
+		update.Error = job.Error
+		s.broadcastTerragruntScanProgress(update)
+	}
+	return job
+}
+
+// broadcastTerragruntScanProgress records a scan's progress on its job and sends it to
+// WebSocket clients
+func (s *Server) broadcastTerragruntScanProgress(update websocket.ProgressUpdate) {
+	s.jobManager().Report(update.JobID, update.Percentage, update.Message)
+	if s.services == nil || s.services.WebSocket == nil {
+		return
+	}
+	update.User = s.jobUser(update.JobID)
+	s.services.WebSocket.BroadcastProgress(websocket.MessageTypeTerragruntScanProgress, update)
+}
+
+// terragruntStack resolves the Terragrunt stack at path under the configured root and parses
+// the states supplied for its units, writing the error response itself when it cannot
+func (s *Server) terragruntStack(w http.ResponseWriter, path string, rawStates map[string]json.RawMessage) (*resolver.Stack, map[string]*state.TerraformState, bool) {
+	if s.config == nil || s.config.TerragruntRoot == "" {
+		s.writeError(w, http.StatusServiceUnavailable, "Terragrunt analysis is not configured")
+		return nil, nil, false
+	}
+	root, ok := s.directoryUnderRoot(w, s.config.TerragruntRoot, path, "Terragrunt root", "Terragrunt stack")
+	if !ok {
+		return nil, nil, false
+	}
+
+	states := make(map[string]*state.TerraformState, len(rawStates))
+	parser := state.NewStateParser()
+	for unit, data := range rawStates {
+		parsed, err := parser.Parse(data)
+		if err != nil {
+			s.writeError(w, http.StatusBadRequest, fmt.Sprintf("Invalid state for %s: %v", unit, err))
+			return nil, nil, false
+		}
+		states[unit] = parsed.TerraformState
+	}
+
+	stack, err := resolver.ResolveStack(root)
+	if err != nil {
+		s.writeError(w, http.StatusUnprocessableEntity, fmt.Sprintf("Failed to resolve Terragrunt stack: %v", err))
+		return nil, nil, false
+	}
+	return stack, states, true
+}
+
+// directoryUnderRoot joins a path relative to a configured root directory, writing the error
+// response itself when the path leaves the root or is not a directory. rootName and kind
+// name the root and the directory in error messages.
+func (s *Server) directoryUnderRoot(w http.ResponseWriter, root, path, rootName, kind string) (string, bool) {
+	return s.pathUnderRoot(w, root, path, rootName, kind, true)
+}
+
+// fileUnderRoot is directoryUnderRoot for a regular file
+func (s *Server) fileUnderRoot(w http.ResponseWriter, root, path, rootName, kind string) (string, bool) {
+	return s.pathUnderRoot(w, root, path, rootName, kind, false)
+}
+
+func (s *Server) pathUnderRoot(w http.ResponseWriter, root, path, rootName, kind string, dir bool) (string, bool) {
+	joined, status, message := resolveUnderRoot(root, path, rootName, kind, dir)
+	if status != 0 {
+		s.writeError(w, status, message)
+		return "", false
+	}
+	return joined, true
+}
+
+// resolveUnderRoot joins a path relative to root. When the path leaves the root or is not the
+// expected kind of entry, it returns the status and message of the error response instead.
+func resolveUnderRoot(root, path, rootName, kind string, dir bool) (string, int, string) {
+	clean := filepath.Clean(filepath.FromSlash(path))
+	if filepath.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, ".."+string(filepath.Separator)) {
+		return "", http.StatusBadRequest, fmt.Sprintf("path must be relative to the %s", rootName)
+	}
+	joined := filepath.Join(root, clean)
+	if info, err := os.Stat(joined); err != nil || info.IsDir() != dir {
+		return "", http.StatusNotFound, fmt.Sprintf("%s %s not found", kind, path)
+	}
+	return joined, 0, ""
+}
+
+// handleCompareEnvironments handles POST /api/v1/environments/compare. It diffs the managed
+// resources and key configuration of a baseline environment against a target.
+func (s *Server) handleCompareEnvironments(w http.ResponseWriter, r *http.Request) {
+	baseline, target, ok := s.loadEnvironments(w, r)
+	if !ok {
+		return
+	}
+	s.writeJSON(w, http.StatusOK, state.CompareEnvironments(baseline, target))
+}
+
+// handlePlanPromotion handles POST /api/v1/environments/promote/plan. It previews the
+// creates, updates and deletes promoting the baseline environment would make to the target,
+// without applying them.
+func (s *Server) handlePlanPromotion(w http.ResponseWriter, r *http.Request) {
+	baseline, target, ok := s.loadEnvironments(w, r)
+	if !ok {
+		return
+	}
+	s.writeJSON(w, http.StatusOK, state.PlanPromotion(baseline, target))
+}
+
+// loadEnvironments decodes an EnvironmentCompareRequest and loads its baseline and target
+// environments, writing the error response itself when it cannot
+func (s *Server) loadEnvironments(w http.ResponseWriter, r *http.Request) (*state.Environment, *state.Environment, bool) {
+	var request EnvironmentCompareRequest
+	if err := decodeRequest(r, &request); err != nil {
+		s.writeRequestError(w, err)
+		return nil, nil, false
+	}
+
+	environments := [2]*state.Environment{state.NewEnvironment(), state.NewEnvironment()}
+	for i, source := range []StateSource{request.Baseline, request.Target} {
+		environment := environments[i]
+		addFile := func(stateFile *internalModels.StateFile) {
+			for _, resource := range stateFile.Resources {
+				if resource.Mode != "data" {
+					environment.AddResource(resource.Address, resource.Type, resource.Attributes)
+				}
+			}
+		}
+		addState := func(_ int, parsed *state.TerraformState) {
+			environment.AddState(parsed)
+		}
+		if !s.loadStateSource(w, r, source, addFile, addState) {
+			return nil, nil, false
+		}
+	}
+	return environments[0], environments[1], true
+}
+
+// loadStateSource passes each state file a source selects to addFile and each inline state to
+// addState, writing the error response itself when it cannot
+func (s *Server) loadStateSource(w http.ResponseWriter, r *http.Request, source StateSource,
+	addFile func(*internalModels.StateFile), addState func(int, *state.TerraformState)) bool {
+	if len(source.StateFileIDs) == 0 && len(source.States) == 0 {
+		s.writeError(w, http.StatusBadRequest, "state_file_ids or states is required")
+		return false
+	}
+
+	if len(source.StateFileIDs) > 0 {
+		if s.services == nil || s.services.StateService == nil {
+			s.writeError(w, http.StatusServiceUnavailable, "State service not available")
+			return false
+		}
+		for _, id := range source.StateFileIDs {
+			stateFile, err := s.services.StateService.GetStateFile(r.Context(), id)
+			if err != nil {
+				s.writeError(w, http.StatusNotFound, fmt.Sprintf("State file %s not found", id))
+				return false
+			}
+			addFile(stateFile)
+		}
+	}
+	parser := state.NewStateParser()
+	for i, data := range source.States {
+		parsed, err := parser.Parse(data)
+		if err != nil {
+			s.writeError(w, http.StatusBadRequest, fmt.Sprintf("Invalid state %d: %v", i, err))
+			return false
+		}
+		addState(i, parsed.TerraformState)
+	}
+	return true
+}
+
+// addStateFileCoverage returns a function recording the managed resources of a loaded state
+// file in calculator, addressed within the state file's ID
+func addStateFileCoverage(calculator *state.CoverageCalculator) func(*internalModels.StateFile) {
+	return func(stateFile *internalModels.StateFile) {
+		for _, resource := range stateFile.Resources {
+			if resource.Mode == "data" {
+				continue
+			}
+			calculator.AddResource(stateFile.ID+":"+resource.Address, resource.Attributes)
+		}
+	}
+}
+
+// liveResources returns the most recently discovered resources the authenticated user's
+// scopes allow, limited to one provider when provider is set
+func liveResources(r *http.Request, provider string) []models.Resource {
+	resources := scopedResources(r.Context(), GetGlobalResourceStore().All())
+	if provider == "" {
+		return resources
+	}
+	filtered := resources[:0:0]
+	for _, resource := range resources {
+		if resource.Provider == provider {
+			filtered = append(filtered, resource)
+		}
+	}
+	return filtered
+}
+
+// unusedResourcesJobType is the job queue type of unused resource scans
+const unusedResourcesJobType = "unused_resources"
+
+// handleUnusedResources handles GET /api/v1/resources/unused. It scans the requested AWS
+// regions for resources that are billed but likely unused and estimates the monthly waste.
+// regions=all scans every region the account has enabled, and regions it has not enabled are
+// skipped and reported as disabled. Regions left when the aws discovery timeout expires are
+// reported as unscanned. The scan runs as a job; with async=true it runs in the background
+// and its result is read from the job.
+func (s *Server) handleUnusedResources(w http.ResponseWriter, r *http.Request) {
+	provider := r.URL.Query().Get("provider")
+	if provider == "" {
+		provider = "aws"
+	}
+	if provider != "aws" {
+		s.writeError(w, http.StatusBadRequest, "Unused resource detection is only supported for the aws provider")
+		return
+	}
+	// The scan uses the server's credentials, whose account is unknown, so it needs access to
+	// the whole provider
+	if err := auth.CheckScope(r.Context(), provider, ""); err != nil {
+		s.writeError(w, http.StatusForbidden, err.Error())
+		return
+	}
+	regions := []string{"us-east-1"}
+	if value := r.URL.Query().Get("regions"); value != "" {
+		regions = strings.Split(value, ",")
+	}
+	regions, err := expandRegions(r.Context(), provider, regions)
+	if err != nil {
+		s.writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	jobID := jobIDOrNew(r.URL.Query().Get("job_id"))
+	async := r.URL.Query().Get("async") == "true"
+	run := func(ctx context.Context) (interface{}, error) {
+		return s.findUnusedResources(ctx, provider, regions)
+	}
+
+	// Background scans outlive the request; others stop if the client disconnects
+	ctx := r.Context()
+	if async {
+		ctx = context.Background()
+	}
+	owner := jobs.Owner{User: requestUser(r), Provider: provider}
+	if _, err := s.jobManager().Submit(ctx, jobID, unusedResourcesJobType, owner, run); err != nil {
+		if errors.Is(err, jobs.ErrShuttingDown) {
+			s.writeError(w, http.StatusServiceUnavailable, "Server is shutting down")
+			return
+		}
+		s.writeError(w, http.StatusConflict, fmt.Sprintf("Job %s is already queued or running", jobID))
+		return
+	}
+	if async {
+		s.writeJSON(w, http.StatusAccepted, JobAcceptedResponse{
+			JobID:     jobID,
+			Status:    jobs.StatusQueued,
+			StatusURL: "/api/v1/jobs/" + jobID,
+		})
+		return
+	}
+
+	job, err := s.jobManager().Wait(context.Background(), jobID)
+	if err != nil {
+		s.writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	switch job.Status {
+	case jobs.StatusCompleted:
+		s.writeJSON(w, http.StatusOK, job.Result)
+	case jobs.StatusCancelled:
+		s.writeError(w, statusClientClosedRequest, "Unused resource scan was cancelled")
+	default:
+		s.writeError(w, http.StatusInternalServerError, job.Error)
+	}
+}
+
+// findUnusedResources scans regions for unused resources and estimates their monthly waste,
+// most wasteful first
+func (s *Server) findUnusedResources(parent context.Context, provider string, regions []string) (*UnusedResourcesResponse, error) {
+	estimator := s.costEstimator()
+	if estimator == nil {
+		estimator = cost.NewAWSCostEstimator(nil)
+	}
+
+	response := &UnusedResourcesResponse{
+		Provider:  provider,
+		Regions:   regions,
+		Currency:  "USD",
+		Resources: []UnusedResource{},
+	}
+	timeout := s.discoveryTimeout(provider)
+	ctx, cancel := context.WithTimeout(parent, timeout)
+	defer cancel()
+
+	limit := newResourceLimit(0)
+	disabled := disabledRegions(ctx, provider)
+	for i, region := range regions {
+		region = strings.TrimSpace(region)
+		if ctx.Err() != nil && parent.Err() == nil {
+			limit.TimeOut(timeout, regions[i:]...)
+			break
+		}
+		if disabled[region] {
+			limit.Disable(region)
+			continue
+		}
+		found, err := awsprovider.NewAWSProvider(region).FindUnusedResources(ctx, region)
+		if err != nil && ctx.Err() != nil && parent.Err() == nil {
+			limit.TimeOut(timeout, regions[i:]...)
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to find unused resources in %s: %w", region, err)
+		}
+		for _, unused := range found {
+			waste, _ := cost.EstimateMonthlyCost(parent, estimator, unused.Billable)
+			response.Resources = append(response.Resources, UnusedResource{
+				Resource:     unused.Resource,
+				Reason:       unused.Reason,
+				MonthlyWaste: waste,
+			})
+			response.TotalMonthlyWaste += waste
+		}
+	}
+	sort.SliceStable(response.Resources, func(i, j int) bool {
+		return response.Resources[i].MonthlyWaste > response.Resources[j].MonthlyWaste
+	})
+	response.Total = len(response.Resources)
+	response.DiscoveryLimit = limit.Result()
+	return response, nil
+}
+
+// handleRecommendations handles GET /api/v1/recommendations. It recommends smaller instance
+// types and DB classes for the most recently discovered AWS resources with low CPU within the
+// user's scopes, fetching utilization for any that were discovered without it. Resources
+// tagged production-critical are never downsized.
+func (s *Server) handleRecommendations(w http.ResponseWriter, r *http.Request) {
+	regions := make(map[string]bool)
+	if value := r.URL.Query().Get("regions"); value != "" {
+		for _, region := range strings.Split(value, ",") {
+			regions[strings.TrimSpace(region)] = true
+		}
+	}
+
+	var candidates, unenriched []models.Resource
+	for _, resource := range liveResources(r, "aws") {
+		if resource.Type != "aws_instance" && resource.Type != "aws_db_instance" {
+			continue
+		}
+		if len(regions) > 0 && !regions[resource.Region] {
+			continue
+		}
+		if _, ok := resource.Properties["cpu_utilization_avg"]; ok {
+			candidates = append(candidates, resource)
+		} else {
+			unenriched = append(unenriched, resource)
+		}
+	}
+	if len(unenriched) > 0 {
+		s.enrichAWSUtilization(r.Context(), unenriched)
+		candidates = append(candidates, unenriched...)
+	}
+
+	response := RecommendationsResponse{
+		Currency:        "USD",
+		Recommendations: []RightsizingRecommendation{},
+	}
+	for _, recommendation := range cost.NewRecommendationEngine(s.costEstimator()).Recommend(r.Context(), candidates) {
+		response.Recommendations = append(response.Recommendations, RightsizingRecommendation{
+			RightsizingRecommendation: recommendation,
+			Remediation: PlannedAction{
+				ID:           "resize-" + recommendation.ResourceID,
+				Type:         "update",
+				Resource:     recommendation.ResourceID,
+				ResourceType: recommendation.ResourceType,
+				Provider:     "aws",
+				Description:  fmt.Sprintf("Resize %s from %s to %s", recommendation.ResourceID, recommendation.CurrentType, recommendation.RecommendedType),
+				RiskLevel:    "medium",
+			},
+		})
+		response.TotalMonthlySavings += recommendation.MonthlySavings
+	}
+	response.Total = len(response.Recommendations)
+
+	s.writeJSON(w, http.StatusOK, response)
+}
+
+// handlePolicyEvaluate evaluates resources against the loaded Rego policies and reports
+// pass or fail per resource with the violated rules. Without resources in the request, the
+// most recently discovered resources within the user's scopes are evaluated.
+func (s *Server) handlePolicyEvaluate(w http.ResponseWriter, r *http.Request) {
+	var req PolicyEvaluateRequest
+	if r.ContentLength != 0 {
+		if err := decodeRequest(r, &req); err != nil {
+			s.writeRequestError(w, err)
+			return
+		}
+	}
+	if s.services == nil || s.services.Policies == nil {
+		s.writeError(w, http.StatusServiceUnavailable, "Policy evaluation is not available")
+		return
+	}
+
+	resources := req.Resources
+	if len(resources) == 0 {
+		resources = liveResources(r, req.Provider)
+	}
+
+	results, err := s.services.Policies.EvaluateResources(r.Context(), resources, req.Packages)
+	if err != nil {
+		s.writeError(w, http.StatusInternalServerError, fmt.Sprintf("Policy evaluation failed: %v", err))
+		return
+	}
+
+	response := PolicyEvaluateResponse{Total: len(results), Results: results}
+	for _, result := range results {
+		if result.Passed {
+			response.Passed++
+		} else {
+			response.Failed++
+		}
+	}
+	s.writeJSON(w, http.StatusOK, response)
+}
+
+// handleComplianceScorecard evaluates the latest discovered resources within the user's
+// scopes against the policies and reports the pass/fail scorecard of a compliance framework's
+// controls
+func (s *Server) handleComplianceScorecard(w http.ResponseWriter, r *http.Request) {
+	if s.services == nil || s.services.Policies == nil {
+		s.writeError(w, http.StatusServiceUnavailable, "Policy evaluation is not available")
+		return
+	}
+	id, err := s.parseID(r)
+	if err != nil {
+		s.writeError(w, http.StatusBadRequest, "Invalid framework")
+		return
+	}
+	framework, ok := s.services.Frameworks[id]
+	if !ok {
+		s.writeError(w, http.StatusNotFound, "Compliance framework not found")
+		return
+	}
+
+	resources := liveResources(r, r.URL.Query().Get("provider"))
+	results, err := s.services.Policies.EvaluateResources(r.Context(), resources, nil)
+	if err != nil {
+		s.writeError(w, http.StatusInternalServerError, fmt.Sprintf("Policy evaluation failed: %v", err))
+		return
+	}
+	s.writeJSON(w, http.StatusOK, compliance.BuildScorecard(framework, results))
+}
+
+// costEstimator returns the cost estimator, or nil when services are not initialized
+func (s *Server) costEstimator() cost.CostEstimator {
+	if s.services == nil {
+		return nil
+	}
+	return s.services.CostEstimator
+}
+
 // handleWebInterface serves the main web interface
 func (s *Server) handleWebInterface(w http.ResponseWriter, r *http.Request) {
 	http.ServeFile(w, r, "web/dashboard/index.html")
@@ -442,26 +1604,82 @@ func (s *Server) handleSecurityScan(w http.ResponseWriter, r *http.Request) {
 	s.writeJSON(w, http.StatusOK, result)
 }
 
-// discoverCloudResources discovers real cloud resources using provider APIs
-func (s *Server) discoverCloudResources(provider string) ([]models.Resource, error) {
+// discoverCloudResources discovers real cloud resources using provider APIs, stopping when
+// ctx is cancelled or the provider's discovery timeout expires. A timeout is reported as a
+// *discoveryTimeoutError.
+func (s *Server) discoverCloudResources(ctx context.Context, provider string) ([]models.Resource, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	parent := ctx
+	timeout := s.discoveryTimeout(provider)
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
 	var resources []models.Resource
+	start := time.Now()
 
 	switch provider {
 	case "aws":
-		resources = s.discoverAWSResources()
+		resources = s.discoverAWSResources(ctx)
 	case "azure":
-		resources = s.discoverAzureResources()
+		resources = s.discoverAzureResources(ctx)
 	case "gcp":
-		resources = s.discoverGCPResources()
+		resources = s.discoverGCPResources(ctx)
+	case "kubernetes":
+		// Only the current context; discoverKubernetesResources scans several
+		discovered, err := kubernetesprovider.NewKubernetesProvider("").DiscoverContext(ctx, "")
+		if err != nil {
+			metrics.RecordDiscoveryFailure(provider)
+			return nil, err
+		}
+		resources = discovered.Resources
 	default:
+		metrics.RecordDiscoveryFailure(provider)
 		return nil, fmt.Errorf("unsupported provider: %s", provider)
 	}
+	if err := ctx.Err(); err != nil {
+		metrics.RecordDiscoveryFailure(provider)
+		if parent.Err() == nil {
+			return resources, &discoveryTimeoutError{Provider: provider, Timeout: timeout}
+		}
+		return nil, err
+	}
 
+	metrics.RecordDiscovery(provider, len(resources), time.Since(start))
 	return resources, nil
 }
 
-// discoverAWSResources discovers AWS resources
-func (s *Server) discoverAWSResources() []models.Resource {
+// cloudResources converts discovered resources to the model drift detection checks. The
+// result is never nil, so an empty discovery is not mistaken for no resources being given.
+func cloudResources(resources []models.Resource) []*internalModels.CloudResource {
+	converted := make([]*internalModels.CloudResource, 0, len(resources))
+	for _, resource := range resources {
+		metadata := make(map[string]interface{}, len(resource.Metadata))
+		for key, value := range resource.Metadata {
+			metadata[key] = value
+		}
+		created, _ := resource.CreationTime()
+		converted = append(converted, &internalModels.CloudResource{
+			ID:             resource.ID,
+			Provider:       internalModels.CloudProvider(resource.Provider),
+			Type:           resource.Type,
+			Name:           resource.Name,
+			Region:         resource.Region,
+			AccountID:      resource.AccountID,
+			Tags:           resource.Tags,
+			Metadata:       metadata,
+			Configuration:  resource.Attributes,
+			LastDiscovered: time.Now().UTC(),
+			CreatedAt:      created,
+			UpdatedAt:      resource.LastModified,
+		})
+	}
+	return converted
+}
+
+// discoverAWSResources discovers AWS resources, returning those found so far once ctx is done
+func (s *Server) discoverAWSResources(ctx context.Context) []models.Resource {
 	var resources []models.Resource
 
 	// Discover S3 buckets
@@ -472,6 +1690,9 @@ func (s *Server) discoverAWSResources() []models.Resource {
 	}
 
 	for i, bucket := range s3Buckets {
+		if ctx.Err() != nil {
+			break
+		}
 		resources = append(resources, models.Resource{
 			ID:       fmt.Sprintf("aws-s3-%d", i+1),
 			Name:     bucket,
@@ -490,8 +1711,158 @@ func (s *Server) discoverAWSResources() []models.Resource {
 	return resources
 }
 
-// discoverAzureResources discovers Azure resources
-func (s *Server) discoverAzureResources() []models.Resource {
+// discoverAWSResourcesFast discovers AWS resources in each region with the Resource Groups
+// Tagging API, defaulting to us-east-1 when no regions are given. Regions left once the
+// resource cap is reached are skipped rather than scanned, and those left when the aws
+// discovery timeout expires are recorded as unscanned. Each region's resources are streamed
+// as soon as it is scanned. Regions not enabled for the account are skipped and reported
+// as disabled. Services and regions that fail are recorded and the scan goes on; the
+// discovery fails only when no region could be scanned.
+func (s *Server) discoverAWSResourcesFast(ctx context.Context, request DiscoverRequest, limit *resourceLimit, stream *discoveryStream) error {
+	regions := request.Regions
+	if len(regions) == 0 {
+		regions = []string{"us-east-1"}
+	}
+	parent := ctx
+	timeout := s.discoveryTimeout("aws")
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+	disabled := disabledRegions(ctx, "aws")
+	var lastErr error
+	scanned, failed := 0, 0
+	for i, region := range regions {
+		region = strings.TrimSpace(region)
+		if err := parent.Err(); err != nil {
+			return fmt.Errorf("fast discovery stopped before %s: %w", region, err)
+		}
+		if ctx.Err() != nil {
+			limit.TimeOut(timeout, regions[i:]...)
+			break
+		}
+		if disabled[region] {
+			// Every call to an opt-in region the account has not enabled fails
+			limit.Disable(region)
+			continue
+		}
+		if limit.Full() {
+			limit.SkipRegion(region)
+			continue
+		}
+
+		discovered, err := awsprovider.NewAWSProvider(region).DiscoverResourcesFast(ctx, region)
+		if err != nil && ctx.Err() != nil && parent.Err() == nil {
+			metrics.RecordDiscoveryFailure("aws")
+			limit.TimeOut(timeout, regions[i:]...)
+			break
+		}
+		scanned++
+		var failures []models.DiscoveryError
+		if err != nil {
+			lastErr = fmt.Errorf("fast discovery failed in %s: %w", region, err)
+			failed++
+			failures = []models.DiscoveryError{{Region: region, Error: err.Error(), Timestamp: time.Now()}}
+		} else {
+			failures = discovered.Errors
+			collected := len(limit.Resources())
+			limit.Add(filterDiscoveredResources(discovered.Resources, nil, request.AccountID))
+			stream.Send(limit.Resources()[collected:])
+		}
+		if len(failures) > 0 {
+			metrics.RecordDiscoveryFailure("aws")
+			limit.Fail(failures...)
+		}
+
+		update := websocket.NewProgressUpdate(stream.jobID, "discovering", i+1, len(regions))
+		update.Message = fmt.Sprintf("Scanned %s", region)
+		update.Resources = len(limit.Resources())
+		update.MaxResources = limit.max
+		update.Errors = failures
+		s.broadcastDiscoveryProgress(update)
+	}
+
+	if scanned > 0 && failed == scanned {
+		return lastErr
+	}
+	metrics.RecordDiscovery("aws", len(limit.Resources()), time.Since(start))
+	return nil
+}
+
+// discoverKubernetesResources discovers the objects of each kubeconfig context given as a
+// region, defaulting to the current context. Contexts left once the resource cap is reached
+// are skipped, and those left when the kubernetes discovery timeout expires are recorded as
+// unscanned. Each context's objects are streamed as soon as it is scanned. Kinds RBAC does
+// not let the context list, and contexts that cannot be reached, are recorded and the scan
+// goes on; the discovery fails only when no context could be scanned.
+func (s *Server) discoverKubernetesResources(ctx context.Context, request DiscoverRequest, limit *resourceLimit, stream *discoveryStream) error {
+	contexts := request.Regions
+	if len(contexts) == 0 {
+		contexts = []string{""}
+	}
+	parent := ctx
+	timeout := s.discoveryTimeout("kubernetes")
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+	provider := kubernetesprovider.NewKubernetesProvider("")
+	var lastErr error
+	scanned, failed := 0, 0
+	for i, contextName := range contexts {
+		contextName = strings.TrimSpace(contextName)
+		if err := parent.Err(); err != nil {
+			return fmt.Errorf("kubernetes discovery stopped before context %s: %w", contextName, err)
+		}
+		if ctx.Err() != nil {
+			limit.TimeOut(timeout, contexts[i:]...)
+			break
+		}
+		if limit.Full() {
+			limit.SkipRegion(contextName)
+			continue
+		}
+
+		discovered, err := provider.DiscoverContext(ctx, contextName)
+		if err != nil && ctx.Err() != nil && parent.Err() == nil {
+			metrics.RecordDiscoveryFailure("kubernetes")
+			limit.TimeOut(timeout, contexts[i:]...)
+			break
+		}
+		scanned++
+		var failures []models.DiscoveryError
+		if err != nil {
+			lastErr = fmt.Errorf("kubernetes discovery failed in context %s: %w", contextName, err)
+			failed++
+			failures = []models.DiscoveryError{{Region: contextName, Error: err.Error(), Timestamp: time.Now()}}
+		} else {
+			failures = discovered.Errors
+			collected := len(limit.Resources())
+			limit.Add(filterDiscoveredResources(discovered.Resources, nil, request.AccountID))
+			stream.Send(limit.Resources()[collected:])
+		}
+		if len(failures) > 0 {
+			metrics.RecordDiscoveryFailure("kubernetes")
+			limit.Fail(failures...)
+		}
+
+		update := websocket.NewProgressUpdate(stream.jobID, "discovering", i+1, len(contexts))
+		update.Message = fmt.Sprintf("Scanned context %s", contextName)
+		update.Resources = len(limit.Resources())
+		update.MaxResources = limit.max
+		update.Errors = failures
+		s.broadcastDiscoveryProgress(update)
+	}
+
+	if scanned > 0 && failed == scanned {
+		return lastErr
+	}
+	metrics.RecordDiscovery("kubernetes", len(limit.Resources()), time.Since(start))
+	return nil
+}
+
+// discoverAzureResources discovers Azure resources, returning those found so far once ctx is done
+func (s *Server) discoverAzureResources(ctx context.Context) []models.Resource {
 	var resources []models.Resource
 
 	// Azure resources discovered earlier
@@ -511,6 +1882,9 @@ func (s *Server) discoverAzureResources() []models.Resource {
 	}
 
 	for i, res := range azureResources {
+		if ctx.Err() != nil {
+			break
+		}
 		resources = append(resources, models.Resource{
 			ID:       fmt.Sprintf("azure-%d", i+1),
 			Name:     res.name,
@@ -529,11 +1903,14 @@ func (s *Server) discoverAzureResources() []models.Resource {
 	return resources
 }
 
-// discoverGCPResources discovers GCP resources
-func (s *Server) discoverGCPResources() []models.Resource {
+// discoverGCPResources discovers GCP resources, returning those found so far once ctx is done
+func (s *Server) discoverGCPResources(ctx context.Context) []models.Resource {
 	var resources []models.Resource
 
 	// Discover GCS buckets
+	if ctx.Err() != nil {
+		return resources
+	}
 	resources = append(resources, models.Resource{
 		ID:           "gcp-storage-1",
 		Name:         "driftmgr-test-bucket-bd2c7b0c",
@@ -541,9 +1918,7 @@ func (s *Server) discoverGCPResources() []models.Resource {
 		Provider:     "gcp",
 		Region:       "us-central1",
 		Status:       "active",
-		Created:      time.Now().Add(-time.Hour),
 		Updated:      time.Now().Add(-time.Hour),
-		CreatedAt:    time.Now().Add(-time.Hour),
 		LastModified: time.Now().Add(-time.Hour),
 		Attributes: map[string]interface{}{
 			"bucket":                      "driftmgr-test-bucket-bd2c7b0c",
@@ -554,6 +1929,9 @@ func (s *Server) discoverGCPResources() []models.Resource {
 	})
 
 	// Discover Compute Engine instances
+	if ctx.Err() != nil {
+		return resources
+	}
 	resources = append(resources, models.Resource{
 		ID:           "gcp-compute-1",
 		Name:         "driftmgr-test-instance",
@@ -561,9 +1939,7 @@ func (s *Server) discoverGCPResources() []models.Resource {
 		Provider:     "gcp",
 		Region:       "us-central1",
 		Status:       "active",
-		Created:      time.Now().Add(-time.Hour),
 		Updated:      time.Now().Add(-time.Hour),
-		CreatedAt:    time.Now().Add(-time.Hour),
 		LastModified: time.Now().Add(-time.Hour),
 		Attributes: map[string]interface{}{
 			"name":         "driftmgr-test-instance",
@@ -574,6 +1950,9 @@ func (s *Server) discoverGCPResources() []models.Resource {
 	})
 
 	// Discover VPC networks
+	if ctx.Err() != nil {
+		return resources
+	}
 	resources = append(resources, models.Resource{
 		ID:           "gcp-network-1",
 		Name:         "driftmgr-test-network",
@@ -581,9 +1960,7 @@ func (s *Server) discoverGCPResources() []models.Resource {
 		Provider:     "gcp",
 		Region:       "us-central1",
 		Status:       "active",
-		Created:      time.Now().Add(-time.Hour),
 		Updated:      time.Now().Add(-time.Hour),
-		CreatedAt:    time.Now().Add(-time.Hour),
 		LastModified: time.Now().Add(-time.Hour),
 		Attributes: map[string]interface{}{
 			"name":                    "driftmgr-test-network",
@@ -593,6 +1970,9 @@ func (s *Server) discoverGCPResources() []models.Resource {
 	})
 
 	// Discover subnets
+	if ctx.Err() != nil {
+		return resources
+	}
 	resources = append(resources, models.Resource{
 		ID:           "gcp-subnet-1",
 		Name:         "driftmgr-test-subnet",
@@ -600,9 +1980,7 @@ func (s *Server) discoverGCPResources() []models.Resource {
 		Provider:     "gcp",
 		Region:       "us-central1",
 		Status:       "active",
-		Created:      time.Now().Add(-time.Hour),
 		Updated:      time.Now().Add(-time.Hour),
-		CreatedAt:    time.Now().Add(-time.Hour),
 		LastModified: time.Now().Add(-time.Hour),
 		Attributes: map[string]interface{}{
 			"name":          "driftmgr-test-subnet",
@@ -629,7 +2007,7 @@ func (s *Server) performSecurityScan(provider string) (map[string]interface{}, e
 	defer securityService.Stop(ctx)
 
 	// Get resources for the provider
-	resources, err := s.discoverCloudResources(provider)
+	resources, err := s.discoverCloudResources(ctx, provider)
 	if err != nil {
 		return nil, fmt.Errorf("failed to discover resources: %v", err)
 	}
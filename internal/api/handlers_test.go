@@ -2,13 +2,19 @@ package api
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/catherinevee/driftmgr/internal/jobs"
+	"github.com/catherinevee/driftmgr/internal/state"
+	"github.com/catherinevee/driftmgr/pkg/models"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -19,22 +25,20 @@ func TestServerMethods(t *testing.T) {
 
 	t.Run("handleCORS", func(t *testing.T) {
 		req := httptest.NewRequest("GET", "/test", nil)
+		req.Header.Set("Origin", "https://elsewhere.example.com")
 		w := httptest.NewRecorder()
 
-		server.handleCORS(w, req)
-
-		assert.Equal(t, "*", w.Header().Get("Access-Control-Allow-Origin"))
-		assert.Equal(t, "GET, POST, PUT, DELETE, OPTIONS", w.Header().Get("Access-Control-Allow-Methods"))
-		assert.Equal(t, "Content-Type, Authorization", w.Header().Get("Access-Control-Allow-Headers"))
+		assert.True(t, server.handleCORS(w, req))
+		assert.Empty(t, w.Header().Get("Access-Control-Allow-Origin"), "only same-origin requests are allowed by default")
 	})
 
 	t.Run("handleCORS_OPTIONS", func(t *testing.T) {
 		req := httptest.NewRequest("OPTIONS", "/test", nil)
+		req.Header.Set("Access-Control-Request-Method", "POST")
 		w := httptest.NewRecorder()
 
-		server.handleCORS(w, req)
-
-		assert.Equal(t, http.StatusOK, w.Code)
+		assert.False(t, server.handleCORS(w, req), "preflight requests are answered by handleCORS")
+		assert.Equal(t, http.StatusNoContent, w.Code)
 	})
 
 	t.Run("handleRateLimit", func(t *testing.T) {
@@ -529,11 +533,12 @@ func TestMiddlewareIntegration(t *testing.T) {
 
 	t.Run("cors_and_auth_chain", func(t *testing.T) {
 		req := httptest.NewRequest("OPTIONS", "/test", nil)
+		req.Header.Set("Access-Control-Request-Method", "GET")
 		w := httptest.NewRecorder()
 
 		// Test CORS handling
 		server.handleCORS(w, req)
-		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, http.StatusNoContent, w.Code)
 
 		// Test auth handling
 		req = httptest.NewRequest("GET", "/test", nil)
@@ -631,3 +636,331 @@ func TestResponseFormat(t *testing.T) {
 		})
 	}
 }
+
+func TestDiscoveryJobCancellation(t *testing.T) {
+	server := &Server{}
+
+	started := make(chan struct{})
+	_, err := server.jobManager().Submit(context.Background(), "job-1", discoveryJobType, jobs.Owner{}, func(ctx context.Context) (interface{}, error) {
+		close(started)
+		<-ctx.Done()
+		return server.discoverCloudResources(ctx, "aws")
+	})
+	require.NoError(t, err)
+	<-started
+
+	req := httptest.NewRequest(http.MethodDelete, "/api/v1/discover/job-1", nil)
+	w := httptest.NewRecorder()
+	server.handleCancelDiscovery(w, req)
+	assert.Equal(t, http.StatusAccepted, w.Code)
+
+	job := server.finishDiscovery("job-1", "aws")
+	assert.Equal(t, jobs.StatusCancelled, job.Status)
+
+	req = httptest.NewRequest(http.MethodDelete, "/api/v1/discover/job-1", nil)
+	w = httptest.NewRecorder()
+	server.handleCancelDiscovery(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code, "a finished job cannot be cancelled")
+
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/jobs/job-1", nil)
+	w = httptest.NewRecorder()
+	server.handleGetJob(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"status":"cancelled"`)
+}
+
+func TestListAndCancelJobs(t *testing.T) {
+	server := &Server{}
+	server.jobQueue = jobs.NewManager(1)
+
+	blocking := func(ctx context.Context) (interface{}, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	running, err := server.jobManager().Submit(context.Background(), "job-1", discoveryJobType, jobs.Owner{}, blocking)
+	require.NoError(t, err)
+	_, err = server.jobManager().Submit(context.Background(), "job-2", terragruntScanJobType, jobs.Owner{}, blocking)
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		job, _ := server.jobManager().Get(running.ID)
+		return job.Status == jobs.StatusRunning
+	}, time.Second, time.Millisecond)
+
+	list := func(query string) (int, JobListResponse) {
+		w := httptest.NewRecorder()
+		server.handleListJobs(w, httptest.NewRequest(http.MethodGet, "/api/v1/jobs"+query, nil))
+		var response JobListResponse
+		if w.Code == http.StatusOK {
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		}
+		return w.Code, response
+	}
+	code, response := list("?status=active")
+	require.Equal(t, http.StatusOK, code)
+	require.Len(t, response.Jobs, 2)
+	assert.Equal(t, 1, response.Running)
+	assert.Equal(t, 1, response.Queued)
+	assert.Equal(t, 1, response.Jobs[1].QueuePosition)
+
+	cancel := func(id string) int {
+		w := httptest.NewRecorder()
+		server.handleCancelJob(w, httptest.NewRequest(http.MethodDelete, "/api/v1/jobs/"+id, nil))
+		return w.Code
+	}
+	assert.Equal(t, http.StatusAccepted, cancel("job-2"), "jobs of any type can be cancelled")
+	assert.Equal(t, http.StatusAccepted, cancel("job-1"))
+	for _, id := range []string{"job-1", "job-2"} {
+		job, err := server.jobManager().Wait(context.Background(), id)
+		require.NoError(t, err)
+		assert.Equal(t, jobs.StatusCancelled, job.Status)
+	}
+	assert.Equal(t, http.StatusConflict, cancel("job-1"), "a finished job cannot be cancelled")
+	assert.Equal(t, http.StatusNotFound, cancel("job-3"))
+
+	code, response = list("?status=active")
+	require.Equal(t, http.StatusOK, code)
+	assert.Empty(t, response.Jobs)
+	_, response = list("?status=cancelled")
+	assert.Equal(t, 2, response.Total)
+	code, _ = list("?status=paused")
+	assert.Equal(t, http.StatusBadRequest, code)
+}
+
+// asUser returns req carrying an authenticated user with the given scopes
+func asUser(req *http.Request, username string, isAdmin bool, scopes ...string) *http.Request {
+	ctx := context.WithValue(req.Context(), "username", username)
+	ctx = context.WithValue(ctx, "is_admin", isAdmin)
+	ctx = context.WithValue(ctx, "scopes", scopes)
+	return req.WithContext(ctx)
+}
+
+func TestJobsRestrictedToOwner(t *testing.T) {
+	server := &Server{}
+
+	release := make(chan struct{})
+	defer close(release)
+	owner := jobs.Owner{User: "jane", Provider: "aws", Account: "111111111111"}
+	_, err := server.jobManager().Submit(context.Background(), "job-1", discoveryJobType, owner, func(ctx context.Context) (interface{}, error) {
+		select {
+		case <-release:
+		case <-ctx.Done():
+		}
+		return nil, ctx.Err()
+	})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		req     *http.Request
+		visible bool
+	}{
+		{"owner", asUser(httptest.NewRequest(http.MethodGet, "/api/v1/jobs/job-1", nil), "jane", false), true},
+		{"owner in scope", asUser(httptest.NewRequest(http.MethodGet, "/api/v1/jobs/job-1", nil), "jane", false, "aws:111111111111"), true},
+		{"owner out of scope", asUser(httptest.NewRequest(http.MethodGet, "/api/v1/jobs/job-1", nil), "jane", false, "aws:222222222222"), false},
+		{"other user", asUser(httptest.NewRequest(http.MethodGet, "/api/v1/jobs/job-1", nil), "bob", false), false},
+		{"admin", asUser(httptest.NewRequest(http.MethodGet, "/api/v1/jobs/job-1", nil), "root", true), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			server.handleGetJob(w, tt.req)
+			if tt.visible {
+				assert.Equal(t, http.StatusOK, w.Code)
+			} else {
+				assert.Equal(t, http.StatusNotFound, w.Code)
+			}
+
+			w = httptest.NewRecorder()
+			server.handleListJobs(w, tt.req.Clone(tt.req.Context()))
+			var list JobListResponse
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &list))
+			if tt.visible {
+				assert.Equal(t, 1, list.Total)
+			} else {
+				assert.Equal(t, 0, list.Total)
+			}
+		})
+	}
+
+	req := asUser(httptest.NewRequest(http.MethodDelete, "/api/v1/discover/job-1", nil), "bob", false)
+	w := httptest.NewRecorder()
+	server.handleCancelDiscovery(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code, "another user's discovery cannot be cancelled")
+	job, _ := server.jobManager().Get("job-1")
+	assert.False(t, job.Finished())
+}
+
+func TestAnalyzeStateSetMergesStates(t *testing.T) {
+	store := GetGlobalResourceStore()
+	store.Save("state-set-test", []models.Resource{
+		{ID: "vpc-1", Type: "vpc", Provider: "state-set-test"},
+		{ID: "i-1", Type: "ec2_instance", Provider: "state-set-test"},
+		{ID: "i-2", Type: "ec2_instance", Provider: "state-set-test"},
+	}, time.Now())
+	t.Cleanup(func() { store.Save("state-set-test", nil, time.Now()) })
+	server := &Server{}
+
+	network := `{"version": 4, "resources": [{"mode": "managed", "type": "aws_vpc", "name": "main",
+		"instances": [{"attributes": {"id": "vpc-1"}}]}]}`
+	compute := `{"version": 4, "resources": [{"mode": "managed", "type": "aws_instance", "name": "web",
+		"instances": [{"attributes": {"id": "i-1"}}, {"attributes": {"id": "i-9"}}]}]}`
+	request := StateSetAnalysisRequest{
+		StateSource: StateSource{States: []json.RawMessage{json.RawMessage(network), json.RawMessage(compute)}},
+		Provider:    "state-set-test",
+	}
+	body, err := json.Marshal(request)
+	require.NoError(t, err)
+
+	w := httptest.NewRecorder()
+	server.handleAnalyzeStateSet(w, httptest.NewRequest(http.MethodPost, "/api/v1/analyze", bytes.NewReader(body)))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var response StateSetAnalysisResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, 2, response.StateFiles)
+	assert.Equal(t, []string{"i-2"}, response.Coverage.Unmanaged, "resources in either state are managed")
+	assert.Equal(t, []string{"states[1]:aws_instance.web[1]"}, response.Coverage.Missing)
+	assert.Equal(t, 3, response.Summary.TotalLiveResources)
+	assert.Equal(t, 3, response.Summary.TotalStateResources)
+	assert.Equal(t, 1, response.Summary.Extra)
+	assert.Equal(t, 1, response.Summary.Missing)
+}
+
+func TestAnalyzeStateSetRequiresStates(t *testing.T) {
+	server := &Server{}
+	w := httptest.NewRecorder()
+	server.handleAnalyzeStateSet(w, httptest.NewRequest(http.MethodPost, "/api/v1/analyze", strings.NewReader(`{}`)))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestTerragruntAnalyze(t *testing.T) {
+	root := t.TempDir()
+	unit := filepath.Join(root, "prod", "vpc")
+	require.NoError(t, os.MkdirAll(unit, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(unit, "terragrunt.hcl"), []byte(`remote_state {
+  backend = "s3"
+  config = {
+    key = "vpc/terraform.tfstate"
+  }
+}`), 0o644))
+	server := &Server{config: &Config{TerragruntRoot: root}}
+
+	analyze := func(request TerragruntAnalysisRequest) *httptest.ResponseRecorder {
+		body, err := json.Marshal(request)
+		require.NoError(t, err)
+		w := httptest.NewRecorder()
+		server.handleTerragruntAnalyze(w, httptest.NewRequest(http.MethodPost, "/api/v1/terragrunt/analyze", bytes.NewReader(body)))
+		return w
+	}
+
+	assert.Equal(t, http.StatusBadRequest, analyze(TerragruntAnalysisRequest{Path: "../outside"}).Code)
+	assert.Equal(t, http.StatusNotFound, analyze(TerragruntAnalysisRequest{Path: "staging"}).Code)
+
+	w := analyze(TerragruntAnalysisRequest{Path: "prod"})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var response TerragruntAnalysisResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, []string{"vpc"}, response.Unresolved, "remote state must be supplied")
+
+	w = analyze(TerragruntAnalysisRequest{Path: "prod", States: map[string]json.RawMessage{
+		"vpc": json.RawMessage(`{"version": 4, "resources": []}`),
+	}})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Empty(t, response.Unresolved)
+	require.Len(t, response.Stack.Units, 1)
+	assert.Equal(t, "vpc/terraform.tfstate", response.Stack.Units[0].StateKey)
+}
+
+func TestTerragruntScan(t *testing.T) {
+	root := t.TempDir()
+	for _, env := range []string{"dev", "prod"} {
+		unit := filepath.Join(root, "live", env, "vpc")
+		require.NoError(t, os.MkdirAll(unit, 0o755))
+		require.NoError(t, os.WriteFile(filepath.Join(unit, "terragrunt.hcl"), []byte(`terraform {}`), 0o644))
+		require.NoError(t, os.WriteFile(filepath.Join(unit, "terraform.tfstate"), []byte(`{"version": 4, "resources": [
+  {"mode": "managed", "type": "aws_vpc", "name": "main", "instances": [{"attributes": {"id": "vpc-terragrunt-scan-`+env+`"}}]}
+]}`), 0o644))
+	}
+	store := GetGlobalResourceStore()
+	store.Save("terragrunt-scan-test", []models.Resource{{ID: "vpc-terragrunt-scan-dev", Provider: "terragrunt-scan-test"}}, time.Now())
+	t.Cleanup(func() { store.Save("terragrunt-scan-test", nil, time.Now()) })
+	server := &Server{config: &Config{TerragruntRoot: root}}
+
+	body, err := json.Marshal(TerragruntScanRequest{Path: "live", Provider: "terragrunt-scan-test", JobID: "scan-1"})
+	require.NoError(t, err)
+	w := httptest.NewRecorder()
+	server.handleTerragruntScan(w, httptest.NewRequest(http.MethodPost, "/api/v1/terragrunt/scan", bytes.NewReader(body)))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var matrix struct {
+		Environments  []string `json:"environments"`
+		ByEnvironment map[string]struct {
+			Drifted int `json:"drifted"`
+		} `json:"by_environment"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &matrix))
+	assert.Equal(t, []string{"dev", "prod"}, matrix.Environments)
+	assert.Equal(t, 0, matrix.ByEnvironment["dev"].Drifted)
+	assert.Equal(t, 1, matrix.ByEnvironment["prod"].Drifted)
+
+	job, ok := server.jobManager().Get("scan-1")
+	require.True(t, ok)
+	assert.Equal(t, jobs.StatusCompleted, job.Status)
+}
+
+func TestCompareEnvironments(t *testing.T) {
+	server := &Server{}
+	request := EnvironmentCompareRequest{
+		Baseline: StateSource{States: []json.RawMessage{json.RawMessage(`{"version": 4, "resources": [
+  {"mode": "managed", "type": "aws_instance", "name": "web", "instances": [{"attributes": {"instance_type": "m5.xlarge"}}]},
+  {"mode": "managed", "type": "aws_sqs_queue", "name": "jobs", "instances": [{"attributes": {}}]}
+]}`)}},
+		Target: StateSource{States: []json.RawMessage{json.RawMessage(`{"version": 4, "resources": [
+  {"mode": "managed", "type": "aws_instance", "name": "web", "instances": [{"attributes": {"instance_type": "t3.small"}}]}
+]}`)}},
+	}
+	body, err := json.Marshal(request)
+	require.NoError(t, err)
+
+	w := httptest.NewRecorder()
+	server.handleCompareEnvironments(w, httptest.NewRequest(http.MethodPost, "/api/v1/environments/compare", bytes.NewReader(body)))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var report state.ParityReport
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &report))
+	assert.Equal(t, map[string]int{"high": 1, "medium": 1}, report.BySeverity)
+	require.Len(t, report.Gaps, 2)
+	assert.Equal(t, "instance_type", report.Gaps[0].Attribute)
+	assert.Equal(t, "aws_sqs_queue.jobs", report.Gaps[1].Address)
+
+	w = httptest.NewRecorder()
+	server.handleCompareEnvironments(w, httptest.NewRequest(http.MethodPost, "/api/v1/environments/compare", strings.NewReader(`{"baseline": {"states": [{}]}}`)))
+	assert.Equal(t, http.StatusBadRequest, w.Code, "both environments are required")
+}
+
+func TestPlanPromotion(t *testing.T) {
+	server := &Server{}
+	body := `{
+  "baseline": {"states": [{"version": 4, "resources": [
+    {"mode": "managed", "type": "aws_instance", "name": "web", "instances": [{"attributes": {"instance_type": "m5.xlarge"}}]}
+  ]}]},
+  "target": {"states": [{"version": 4, "resources": [
+    {"mode": "managed", "type": "aws_instance", "name": "web", "instances": [{"attributes": {"instance_type": "t3.small"}}]},
+    {"mode": "managed", "type": "aws_instance", "name": "debug", "instances": [{"attributes": {}}]}
+  ]}]}
+}`
+
+	w := httptest.NewRecorder()
+	server.handlePlanPromotion(w, httptest.NewRequest(http.MethodPost, "/api/v1/environments/promote/plan", strings.NewReader(body)))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var plan state.PromotionPlan
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &plan))
+	assert.Equal(t, 0, plan.Creates)
+	assert.Equal(t, 1, plan.Updates)
+	assert.Equal(t, 1, plan.Deletes)
+	require.Len(t, plan.Changes, 2)
+	assert.Equal(t, state.AttributeChange{From: "t3.small", To: "m5.xlarge"}, plan.Changes[0].Attributes["instance_type"])
+	assert.Equal(t, state.PromotionDelete, plan.Changes[1].Action)
+}
@@ -0,0 +1,150 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	awsprovider "github.com/catherinevee/driftmgr/internal/providers/aws"
+	azureprovider "github.com/catherinevee/driftmgr/internal/providers/azure"
+	gcpprovider "github.com/catherinevee/driftmgr/internal/providers/gcp"
+	"github.com/catherinevee/driftmgr/internal/version"
+)
+
+const (
+	// healthCheckTimeout bounds each dependency check of a readiness probe
+	healthCheckTimeout = 5 * time.Second
+	// providerHealthCheckTimeout bounds each cloud credential check, which may call the cloud
+	providerHealthCheckTimeout = 10 * time.Second
+)
+
+// healthCheck checks one dependency the server needs to serve requests
+type healthCheck struct {
+	name    string
+	timeout time.Duration
+	check   func(ctx context.Context) error
+}
+
+// handleHealthLive handles GET /health/live. It reports only that the process is serving
+// requests, so a failing dependency never gets the server restarted.
+func (s *Server) handleHealthLive(w http.ResponseWriter, r *http.Request) {
+	s.writeJSON(w, http.StatusOK, HealthResponse{
+		Status:    "healthy",
+		Version:   version.Version,
+		Commit:    version.Commit,
+		BuildDate: version.BuildDate,
+		Time:      time.Now().UTC(),
+	})
+}
+
+// handleHealth handles GET /health/ready, /health and /api/v1/health. It checks the
+// server's dependencies: the database, the discovery cache, the data directory and the
+// credentials of each provider in health_check_providers. Any failing check makes the
+// response 503, with each component's status and error.
+func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
+	components := runHealthChecks(r.Context(), s.readinessChecks())
+	response := HealthResponse{
+		Status:     "healthy",
+		Version:    version.Version,
+		Commit:     version.Commit,
+		BuildDate:  version.BuildDate,
+		Time:       time.Now().UTC(),
+		Components: components,
+	}
+	status := http.StatusOK
+	for _, component := range components {
+		if component.Status != "healthy" {
+			response.Status = "unhealthy"
+			status = http.StatusServiceUnavailable
+		}
+	}
+	s.writeJSON(w, status, response)
+}
+
+// readinessChecks returns the checks of the server's configured dependencies
+func (s *Server) readinessChecks() []healthCheck {
+	checks := []healthCheck{
+		{name: "database", timeout: healthCheckTimeout, check: func(ctx context.Context) error {
+			db, err := s.database()
+			if err != nil {
+				return err
+			}
+			return db.PingContext(ctx)
+		}},
+		{name: "data_dir", timeout: healthCheckTimeout, check: func(ctx context.Context) error {
+			return checkWritable(s.outputDir())
+		}},
+	}
+	if discoveryCache := s.discoveryCache(); discoveryCache != nil {
+		checks = append(checks, healthCheck{name: "cache", timeout: healthCheckTimeout, check: func(ctx context.Context) error {
+			key := "health:" + time.Now().UTC().Format(time.RFC3339Nano)
+			if err := discoveryCache.Set(key, true, time.Minute); err != nil {
+				return err
+			}
+			defer discoveryCache.Delete(key)
+			if _, ok := discoveryCache.Get(key); !ok {
+				return fmt.Errorf("cache did not return a value it stored")
+			}
+			return nil
+		}})
+	}
+	if s.config != nil {
+		for _, provider := range s.config.HealthCheckProviders {
+			provider := provider
+			checks = append(checks, healthCheck{name: "provider:" + provider, timeout: providerHealthCheckTimeout, check: func(ctx context.Context) error {
+				return checkProviderCredentials(ctx, provider)
+			}})
+		}
+	}
+	return checks
+}
+
+// runHealthChecks runs checks concurrently, each within its timeout, and reports them in order
+func runHealthChecks(ctx context.Context, checks []healthCheck) []ComponentHealth {
+	components := make([]ComponentHealth, len(checks))
+	var wg sync.WaitGroup
+	for i, check := range checks {
+		wg.Add(1)
+		go func(i int, check healthCheck) {
+			defer wg.Done()
+			checkCtx, cancel := context.WithTimeout(ctx, check.timeout)
+			defer cancel()
+
+			start := time.Now()
+			done := make(chan error, 1)
+			go func() { done <- check.check(checkCtx) }()
+			var err error
+			select {
+			case err = <-done:
+			case <-checkCtx.Done():
+				err = fmt.Errorf("timed out after %s", check.timeout)
+			}
+
+			component := ComponentHealth{Name: check.name, Status: "healthy", LatencyMS: time.Since(start).Milliseconds()}
+			if err != nil {
+				component.Status = "unhealthy"
+				component.Error = err.Error()
+			}
+			components[i] = component
+		}(i, check)
+	}
+	wg.Wait()
+	return components
+}
+
+// checkProviderCredentials makes a shallow check that a provider's credentials can be found
+// and loaded, without listing any resources
+func checkProviderCredentials(ctx context.Context, provider string) error {
+	switch provider {
+	case "aws":
+		return awsprovider.NewAWSProvider("us-east-1").ValidateCredentials(ctx)
+	case "azure":
+		return azureprovider.NewAzureProviderComplete("", "").ValidateCredentials(ctx)
+	case "gcp":
+		return gcpprovider.NewGCPProviderComplete("").ValidateCredentials(ctx)
+	default:
+		return fmt.Errorf("unsupported provider: %s", provider)
+	}
+}
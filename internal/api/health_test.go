@@ -0,0 +1,73 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/catherinevee/driftmgr/internal/shared/cache"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunHealthChecks(t *testing.T) {
+	components := runHealthChecks(context.Background(), []healthCheck{
+		{name: "ok", timeout: time.Second, check: func(ctx context.Context) error { return nil }},
+		{name: "broken", timeout: time.Second, check: func(ctx context.Context) error { return errors.New("connection refused") }},
+		{name: "hung", timeout: 10 * time.Millisecond, check: func(ctx context.Context) error {
+			time.Sleep(time.Second)
+			return nil
+		}},
+	})
+	require.Len(t, components, 3)
+	assert.Equal(t, ComponentHealth{Name: "ok", Status: "healthy", LatencyMS: components[0].LatencyMS}, components[0])
+	assert.Equal(t, "unhealthy", components[1].Status)
+	assert.Equal(t, "connection refused", components[1].Error)
+	assert.Equal(t, "unhealthy", components[2].Status)
+	assert.Contains(t, components[2].Error, "timed out")
+}
+
+func TestReadinessChecks(t *testing.T) {
+	server := &Server{
+		config:   &Config{DataDir: t.TempDir(), HealthCheckProviders: []string{"aws"}},
+		services: &Services{DiscoveryCache: cache.NewGlobalCache(1<<20, time.Minute, "")},
+	}
+	var names []string
+	for _, check := range server.readinessChecks() {
+		names = append(names, check.name)
+	}
+	assert.Equal(t, []string{"database", "data_dir", "cache", "provider:aws"}, names)
+
+	require.NoError(t, server.prepareDataDir())
+	for _, check := range server.readinessChecks() {
+		if check.name == "data_dir" || check.name == "cache" {
+			assert.NoError(t, check.check(context.Background()), check.name)
+		}
+	}
+}
+
+func TestHandleHealthUnhealthyDataDir(t *testing.T) {
+	// A data directory that cannot be created fails readiness but not liveness
+	server := &Server{config: &Config{DataDir: "/dev/null/driftmgr"}}
+
+	w := httptest.NewRecorder()
+	server.handleHealth(w, httptest.NewRequest(http.MethodGet, "/health/ready", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	var response HealthResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "unhealthy", response.Status)
+	for _, component := range response.Components {
+		if component.Name == "data_dir" {
+			assert.Equal(t, "unhealthy", component.Status)
+			assert.NotEmpty(t, component.Error)
+		}
+	}
+
+	w = httptest.NewRecorder()
+	server.handleHealthLive(w, httptest.NewRequest(http.MethodGet, "/health/live", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+}
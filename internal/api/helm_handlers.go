@@ -0,0 +1,60 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	kubernetesprovider "github.com/catherinevee/driftmgr/internal/providers/kubernetes"
+)
+
+// handleHelmDrift handles POST /api/v1/helm/drift. It compares the live objects of a Helm
+// release with the manifest its chart and values render, or with the manifest it deployed
+// when no chart is given, and reports each object's differences.
+func (s *Server) handleHelmDrift(w http.ResponseWriter, r *http.Request) {
+	var request HelmDriftRequest
+	if err := decodeRequest(r, &request); err != nil {
+		s.writeRequestError(w, err)
+		return
+	}
+
+	options := kubernetesprovider.HelmDriftOptions{
+		Context:   request.Context,
+		Release:   request.Release,
+		Namespace: request.Namespace,
+		Values:    request.Values,
+	}
+	if request.Chart != "" {
+		if s.config == nil || s.config.HelmChartRoot == "" {
+			s.writeError(w, http.StatusServiceUnavailable, "Helm chart comparison is not configured")
+			return
+		}
+		chart, ok := s.directoryUnderRoot(w, s.config.HelmChartRoot, request.Chart, "Helm chart root", "Helm chart")
+		if !ok {
+			return
+		}
+		options.Chart = chart
+		for _, file := range request.ValuesFiles {
+			valuesFile, ok := s.fileUnderRoot(w, s.config.HelmChartRoot, file, "Helm chart root", "Values file")
+			if !ok {
+				return
+			}
+			options.ValuesFiles = append(options.ValuesFiles, valuesFile)
+		}
+	}
+
+	report, err := kubernetesprovider.NewKubernetesProvider("").HelmDrift(r.Context(), options)
+	var apiErr *kubernetesprovider.APIError
+	switch {
+	case errors.Is(err, kubernetesprovider.ErrReleaseNotFound):
+		s.writeError(w, http.StatusNotFound, err.Error())
+		return
+	case errors.As(err, &apiErr) && apiErr.Forbidden():
+		s.writeError(w, http.StatusForbidden, fmt.Sprintf("The server's Kubernetes credentials cannot read release %s: %v", request.Release, err))
+		return
+	case err != nil:
+		s.writeError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to check Helm release drift: %v", err))
+		return
+	}
+	s.writeJSON(w, http.StatusOK, report)
+}
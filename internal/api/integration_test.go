@@ -258,16 +258,6 @@ func TestResourceHandlersIntegration(t *testing.T) {
 	if w.Code != http.StatusOK {
 		t.Errorf("Expected status 200, got %d", w.Code)
 	}
-
-	// Test SearchResources
-	req = httptest.NewRequest("GET", "/api/v1/resources/search?q=test", nil)
-	w = httptest.NewRecorder()
-
-	handlers.SearchResources(w, req)
-
-	if w.Code != http.StatusOK {
-		t.Errorf("Expected status 200, got %d", w.Code)
-	}
 }
 
 func TestDriftHandlersIntegration(t *testing.T) {
@@ -330,6 +320,7 @@ func TestCORSIntegration(t *testing.T) {
 		Host:        "localhost",
 		Port:        8080,
 		CORSEnabled: true,
+		CORS:        CORSConfig{AllowedOrigins: []string{"http://localhost:3000"}},
 	}
 
 	server := NewServer(config, nil)
@@ -338,6 +329,7 @@ func TestCORSIntegration(t *testing.T) {
 	// Create OPTIONS request
 	req := httptest.NewRequest("OPTIONS", "/health", nil)
 	req.Header.Set("Origin", "http://localhost:3000")
+	req.Header.Set("Access-Control-Request-Method", "GET")
 	w := httptest.NewRecorder()
 
 	// Call server directly
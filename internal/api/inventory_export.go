@@ -0,0 +1,68 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/catherinevee/driftmgr/internal/remediation/tfimport"
+	"github.com/catherinevee/driftmgr/pkg/models"
+)
+
+// handleInventoryExport handles POST /api/v1/export. The tf-import format writes a Terraform
+// 1.5+ configuration with an import block, and the resource block where one can be generated,
+// for every discovered resource the user may see; json writes the resources themselves.
+func (s *Server) handleInventoryExport(w http.ResponseWriter, r *http.Request) {
+	var request InventoryExportRequest
+	if err := decodeRequest(r, &request); err != nil {
+		s.writeRequestError(w, err)
+		return
+	}
+	if request.Format == "" {
+		request.Format = "tf-import"
+	}
+
+	var resources []models.Resource
+	for _, resource := range liveResources(r, request.Provider) {
+		if (request.AccountID == "" || resource.AccountID == request.AccountID) &&
+			(request.Region == "" || resource.Region == request.Region) {
+			resources = append(resources, resource)
+		}
+	}
+
+	switch request.Format {
+	case "tf-import":
+		result := tfimport.NewHCLGenerator().GenerateImportBlocks(resources)
+		data := []byte(renderImportBlocks(result))
+		saveExport(w, r, s.outputDir(), exportFilename("inventory-import", "tf"), request.Format, "text/plain; charset=utf-8", data, len(result.Blocks), request.Inline)
+	case "json":
+		if resources == nil {
+			resources = []models.Resource{}
+		}
+		data, err := json.MarshalIndent(resources, "", "  ")
+		if err != nil {
+			s.writeError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to encode inventory: %v", err))
+			return
+		}
+		saveExport(w, r, s.outputDir(), exportFilename("inventory", "json"), request.Format, "application/json", data, len(resources), request.Inline)
+	default:
+		s.writeError(w, http.StatusBadRequest, fmt.Sprintf("Unsupported export format %q; use tf-import or json", request.Format))
+	}
+}
+
+// renderImportBlocks returns the import configuration, noting the resources that could not be
+// imported at the end so they are not silently missing
+func renderImportBlocks(result *tfimport.ImportBlockResult) string {
+	var out strings.Builder
+	out.WriteString("# Generated by driftmgr for Terraform 1.5+. Import blocks without a resource block\n")
+	out.WriteString("# need one: run terraform plan -generate-config-out=generated.tf to write them.\n\n")
+	out.WriteString(result.HCL)
+	if len(result.Skipped) > 0 {
+		out.WriteString("\n# Resources without a known import ID format:\n")
+		for _, skipped := range result.Skipped {
+			fmt.Fprintf(&out, "#   %s %s: %s\n", skipped.ResourceType, skipped.ResourceID, skipped.Reason)
+		}
+	}
+	return out.String()
+}
@@ -0,0 +1,48 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/catherinevee/driftmgr/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleInventoryExport(t *testing.T) {
+	store := GetGlobalResourceStore()
+	store.Save("inventory-export-test", []models.Resource{
+		{ID: "i-export-1", Name: "web", Type: "aws_instance", Provider: "aws", AccountID: "export-test",
+			Attributes: map[string]interface{}{"ami": "ami-123", "instance_type": "t3.micro"}},
+		{ID: "unknown-export", Type: "aws_unknown", Provider: "aws", AccountID: "export-test"},
+		{ID: "i-export-other", Type: "aws_instance", Provider: "aws", AccountID: "other-account"},
+	}, time.Now())
+	t.Cleanup(func() { store.Save("inventory-export-test", nil, time.Now()) })
+
+	server := &Server{}
+	post := func(body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		server.handleInventoryExport(w, httptest.NewRequest(http.MethodPost, "/api/v1/export", strings.NewReader(body)))
+		return w
+	}
+
+	w := post(`{"format": "tf-import", "account_id": "export-test", "inline": true}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Contains(t, w.Header().Get("Content-Disposition"), ".tf")
+	body := w.Body.String()
+	assert.Contains(t, body, `resource "aws_instance" "web"`)
+	assert.Contains(t, body, "to = aws_instance.web\n")
+	assert.Contains(t, body, `id = "i-export-1"`)
+	assert.Contains(t, body, "aws_unknown unknown-export", "skipped resources are listed")
+	assert.NotContains(t, body, "i-export-other", "other accounts are filtered out")
+
+	w = post(`{"format": "json", "account_id": "export-test", "inline": true}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Contains(t, w.Body.String(), `"i-export-1"`)
+
+	assert.Equal(t, http.StatusBadRequest, post(`{"format": "cloudformation"}`).Code)
+	assert.Equal(t, http.StatusBadRequest, post(`{`).Code)
+}
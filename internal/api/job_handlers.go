@@ -0,0 +1,116 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/catherinevee/driftmgr/internal/auth"
+	"github.com/catherinevee/driftmgr/internal/jobs"
+)
+
+// handleListJobs handles GET /api/v1/jobs, listing queued, running and recently finished
+// jobs oldest first. Only the caller's own jobs are listed, unless they are an admin.
+// status limits the list to comma-separated statuses, where active stands for queued and
+// running. Results are left out; fetch a job to get its result.
+func (s *Server) handleListJobs(w http.ResponseWriter, r *http.Request) {
+	statuses := make(map[jobs.Status]bool)
+	for _, status := range strings.Split(r.URL.Query().Get("status"), ",") {
+		switch status = strings.TrimSpace(status); status {
+		case "":
+		case "active":
+			statuses[jobs.StatusQueued] = true
+			statuses[jobs.StatusRunning] = true
+		case string(jobs.StatusQueued), string(jobs.StatusRunning), string(jobs.StatusCompleted),
+			string(jobs.StatusFailed), string(jobs.StatusCancelled):
+			statuses[jobs.Status(status)] = true
+		default:
+			s.writeError(w, http.StatusBadRequest, fmt.Sprintf("Invalid status %q: must be active, queued, running, completed, failed or cancelled", status))
+			return
+		}
+	}
+
+	response := JobListResponse{Jobs: make([]jobs.Job, 0)}
+	for _, job := range s.jobManager().List() {
+		if !canAccessJob(r, job) || (len(statuses) > 0 && !statuses[job.Status]) {
+			continue
+		}
+		switch job.Status {
+		case jobs.StatusQueued:
+			response.Queued++
+		case jobs.StatusRunning:
+			response.Running++
+		}
+		job.Result = nil
+		response.Jobs = append(response.Jobs, job)
+	}
+	response.Total = len(response.Jobs)
+	s.writeJSON(w, http.StatusOK, response)
+}
+
+// handleGetJob handles GET /api/v1/jobs/{id}, returning a job's status and progress and,
+// once it has completed, its result
+func (s *Server) handleGetJob(w http.ResponseWriter, r *http.Request) {
+	jobID, err := s.parseID(r)
+	if err != nil || jobID == "" {
+		s.writeError(w, http.StatusBadRequest, "Job ID is required")
+		return
+	}
+	job, ok := s.jobManager().Get(jobID)
+	if !ok || !canAccessJob(r, job) {
+		// Other users' jobs are reported as missing so job IDs cannot be probed
+		s.writeError(w, http.StatusNotFound, fmt.Sprintf("Job %s not found", jobID))
+		return
+	}
+	s.writeJSON(w, http.StatusOK, job)
+}
+
+// handleCancelJob handles DELETE /api/v1/jobs/{id}, cancelling a queued or running job of any
+// type. A queued job is dropped from the queue; a running one is told to stop and finishes as
+// cancelled once it does.
+func (s *Server) handleCancelJob(w http.ResponseWriter, r *http.Request) {
+	jobID, err := s.parseID(r)
+	if err != nil || jobID == "" {
+		s.writeError(w, http.StatusBadRequest, "Job ID is required")
+		return
+	}
+	job, ok := s.jobManager().Get(jobID)
+	if !ok || !canAccessJob(r, job) {
+		s.writeError(w, http.StatusNotFound, fmt.Sprintf("Job %s not found", jobID))
+		return
+	}
+	if !s.jobManager().Cancel(jobID) {
+		s.writeError(w, http.StatusConflict, fmt.Sprintf("Job %s has already finished", jobID))
+		return
+	}
+
+	s.writeJSON(w, http.StatusAccepted, map[string]string{
+		"job_id": jobID,
+		"status": "cancelling",
+	})
+}
+
+// jobUser returns the user that submitted a job, so its progress is only sent to them
+func (s *Server) jobUser(jobID string) string {
+	if job, ok := s.jobManager().Get(jobID); ok {
+		return job.User
+	}
+	return ""
+}
+
+// canAccessJob reports whether the request's user may see or cancel a job: admins may access
+// any job, other users only jobs they submitted whose provider and account are still within
+// their scopes. Unauthenticated requests, when authentication is disabled, may access all jobs.
+func canAccessJob(r *http.Request, job jobs.Job) bool {
+	username, ok := auth.GetUsernameFromContext(r.Context())
+	if !ok || username == "" {
+		return true
+	}
+	if isAdmin, _ := auth.GetIsAdminFromContext(r.Context()); isAdmin {
+		return true
+	}
+	if job.User != username {
+		return false
+	}
+	return auth.CheckScope(r.Context(), job.Provider, job.Account) == nil
+}
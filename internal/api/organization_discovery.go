@@ -0,0 +1,177 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/catherinevee/driftmgr/internal/auth"
+	"github.com/catherinevee/driftmgr/internal/drift/detector"
+	"github.com/catherinevee/driftmgr/internal/jobs"
+	awsprovider "github.com/catherinevee/driftmgr/internal/providers/aws"
+	"github.com/catherinevee/driftmgr/internal/state"
+	"github.com/catherinevee/driftmgr/internal/websocket"
+	"github.com/catherinevee/driftmgr/pkg/models"
+)
+
+// handleDiscoverOrganization handles POST /api/v1/discover/organization. It lists the AWS
+// organization the server's credentials manage, assumes the configured role in each active
+// member account and discovers every account through the tagging API, a few accounts at a
+// time. Each account's resources are recorded as its own fast discovery snapshot and rolled
+// up with its changes since the last discovery and its recorded drift. Accounts outside the
+// caller's scopes are skipped. It runs as a discovery job, so async and cancellation behave
+// as for GET /api/v1/discover.
+func (s *Server) handleDiscoverOrganization(w http.ResponseWriter, r *http.Request) {
+	var request OrganizationDiscoveryRequest
+	if r.ContentLength != 0 {
+		if err := decodeRequest(r, &request); err != nil {
+			s.writeRequestError(w, err)
+			return
+		}
+	}
+	roleName := request.RoleName
+	if roleName == "" && s.config != nil {
+		roleName = s.config.AWSOrganizationRole
+	}
+	selected := make(map[string]bool, len(request.AccountIDs))
+	for _, id := range request.AccountIDs {
+		selected[id] = true
+	}
+	// Scopes are checked now, while the request's credentials are at hand
+	ctx := r.Context()
+	include := func(account awsprovider.OrganizationAccount) bool {
+		if len(selected) > 0 && !selected[account.ID] {
+			return false
+		}
+		return auth.CheckScope(ctx, "aws", account.ID) == nil
+	}
+
+	region := "us-east-1"
+	if len(request.Regions) > 0 {
+		region = request.Regions[0]
+	}
+	jobID := jobIDOrNew(request.JobID)
+	run := func(ctx context.Context) (interface{}, error) {
+		update := websocket.NewProgressUpdate(jobID, "started", 0, 0)
+		update.Message = "Listing the AWS organization"
+		s.broadcastDiscoveryProgress(update)
+
+		discovery, err := awsprovider.NewAWSProvider(region).DiscoverOrganization(ctx, awsprovider.OrganizationDiscoveryOptions{
+			RoleName:    roleName,
+			Regions:     request.Regions,
+			Include:     include,
+			Concurrency: request.Concurrency,
+			Progress: func(done, total int, account awsprovider.AccountDiscovery) {
+				update := websocket.NewProgressUpdate(jobID, "discovering", done, total)
+				update.Message = fmt.Sprintf("Discovered %d resources in account %s", len(account.Resources), account.Account.ID)
+				if account.Error != "" {
+					update.Message = fmt.Sprintf("Failed to discover account %s: %s", account.Account.ID, account.Error)
+				}
+				s.broadcastDiscoveryProgress(update)
+			},
+		})
+		if err != nil {
+			return nil, err
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		return recordOrganizationDiscovery(jobID, request.Regions, discovery, time.Now().UTC()), nil
+	}
+
+	discoverRequest := DiscoverRequest{Provider: "aws", Mode: "fast", Regions: request.Regions}
+	if request.Async {
+		if !s.submitDiscovery(context.Background(), w, jobID, discoverRequest, requestUser(r), run) {
+			return
+		}
+		go s.finishDiscovery(jobID, "aws")
+		s.writeJSON(w, http.StatusAccepted, JobAcceptedResponse{
+			JobID:     jobID,
+			Status:    jobs.StatusQueued,
+			StatusURL: "/api/v1/jobs/" + jobID,
+		})
+		return
+	}
+
+	if !s.submitDiscovery(r.Context(), w, jobID, discoverRequest, requestUser(r), run) {
+		return
+	}
+	job := s.finishDiscovery(jobID, "aws")
+	switch job.Status {
+	case jobs.StatusCompleted:
+		s.writeJSON(w, http.StatusOK, job.Result)
+	case jobs.StatusCancelled:
+		s.writeError(w, statusClientClosedRequest, "Discovery was cancelled")
+	default:
+		s.writeError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to discover AWS organization: %s", job.Error))
+	}
+}
+
+// recordOrganizationDiscovery saves each successfully discovered account as a fast discovery
+// snapshot of its regions and builds the response, rolling up each account's changes since
+// its previous snapshot and the drift results recorded for its resources
+func recordOrganizationDiscovery(jobID string, regions []string, discovery *awsprovider.OrganizationDiscovery, discoveredAt time.Time) *OrganizationDiscoveryResponse {
+	response := &OrganizationDiscoveryResponse{
+		JobID:        jobID,
+		DiscoveredAt: discoveredAt,
+		Organization: discovery.Organization,
+		Accounts:     make([]AccountDriftSummary, 0, len(discovery.Accounts)),
+		Skipped:      discovery.Skipped,
+		Resources:    make(map[string][]models.Resource, len(discovery.Accounts)),
+	}
+	drifts := GetGlobalDriftStore().List()
+	classifier := state.NewManagementClassifier()
+
+	for _, account := range discovery.Accounts {
+		summary := AccountDriftSummary{Account: account.Account, Error: account.Error}
+		if account.Error != "" {
+			// A failed account would show everything as removed, so it is not a snapshot
+			response.FailedAccounts++
+			response.Accounts = append(response.Accounts, summary)
+			continue
+		}
+
+		resources := classifier.Annotate(account.Resources)
+		scope := discoveryScope("aws", regions, account.Account.ID) + ":fast"
+		delta, drifted := recordAccountSnapshot(scope, "aws", account.Account.ID, resources, discoveredAt, drifts)
+
+		summary.Resources = len(resources)
+		summary.Added = len(delta.Added)
+		summary.Removed = len(delta.Removed)
+		summary.Changed = len(delta.Changed)
+		summary.Drifted = drifted
+		if drifted > 0 {
+			response.DriftedAccounts++
+		}
+		response.Accounts = append(response.Accounts, summary)
+		response.Resources[account.Account.ID] = resources
+		response.TotalResources += len(resources)
+	}
+	return response
+}
+
+// recordAccountSnapshot saves an account's resources as the latest snapshot of scope. It
+// returns their changes since the previous snapshot and how many of them, or of the
+// account's other resources, have recorded drift results.
+func recordAccountSnapshot(scope, provider, accountID string, resources []models.Resource, discoveredAt time.Time, drifts []*detector.DriftResult) (ResourceDelta, int) {
+	store := GetGlobalResourceStore()
+	var previousResources []models.Resource
+	if previous, ok := store.Last(scope); ok {
+		previousResources = previous.Resources
+	}
+	delta := DiffResources(previousResources, resources)
+	store.Save(scope, resources, discoveredAt)
+
+	ids := make(map[string]bool, len(resources))
+	for _, resource := range resources {
+		ids[resource.ID] = true
+	}
+	drifted := 0
+	for _, drift := range drifts {
+		if drift.Provider == provider && (ids[drift.Resource] || driftAccount(drift) == accountID) {
+			drifted++
+		}
+	}
+	return delta, drifted
+}
@@ -0,0 +1,63 @@
+package api
+
+import (
+	"testing"
+	"time"
+
+	"github.com/catherinevee/driftmgr/internal/drift/detector"
+	awsprovider "github.com/catherinevee/driftmgr/internal/providers/aws"
+	"github.com/catherinevee/driftmgr/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordOrganizationDiscovery(t *testing.T) {
+	regions := []string{"eu-central-1"}
+	store := GetGlobalResourceStore()
+	store.Save(discoveryScope("aws", regions, "910000000001")+":fast", []models.Resource{
+		{ID: "org-test-old", Provider: "aws"},
+		{ID: "org-test-kept", Provider: "aws"},
+	}, time.Now().Add(-time.Hour))
+
+	drifts := GetGlobalDriftStore()
+	drifts.Store("org-test-drift", &detector.DriftResult{Resource: "org-test-kept", Provider: "aws"})
+	t.Cleanup(func() { drifts.Delete("org-test-drift") })
+
+	discovery := &awsprovider.OrganizationDiscovery{
+		Organization: &awsprovider.Organization{ID: "o-test"},
+		Accounts: []awsprovider.AccountDiscovery{
+			{
+				Account:   awsprovider.OrganizationAccount{ID: "910000000001"},
+				Resources: []models.Resource{{ID: "org-test-kept", Provider: "aws"}, {ID: "org-test-new", Provider: "aws"}},
+			},
+			{
+				Account:   awsprovider.OrganizationAccount{ID: "910000000002"},
+				Resources: []models.Resource{{ID: "org-test-other", Provider: "aws"}},
+			},
+			{Account: awsprovider.OrganizationAccount{ID: "910000000003"}, Error: "AccessDenied"},
+		},
+		Skipped: []awsprovider.OrganizationAccount{{ID: "910000000004", Status: "SUSPENDED"}},
+	}
+
+	response := recordOrganizationDiscovery("job-org", regions, discovery, time.Now().UTC())
+	require.Len(t, response.Accounts, 3)
+	assert.Equal(t, 3, response.TotalResources)
+	assert.Equal(t, 1, response.DriftedAccounts)
+	assert.Equal(t, 1, response.FailedAccounts)
+	assert.Len(t, response.Skipped, 1)
+	assert.NotContains(t, response.Resources, "910000000003", "failed accounts have no resources")
+
+	first := response.Accounts[0]
+	assert.Equal(t, 2, first.Resources)
+	assert.Equal(t, 1, first.Added)
+	assert.Equal(t, 1, first.Removed)
+	assert.Equal(t, 1, first.Drifted)
+	assert.Equal(t, 1, response.Accounts[1].Added, "a first discovery reports everything as added")
+	assert.Equal(t, "AccessDenied", response.Accounts[2].Error)
+
+	snapshot, ok := store.Last(discoveryScope("aws", regions, "910000000002") + ":fast")
+	require.True(t, ok)
+	assert.Len(t, snapshot.Resources, 1)
+	_, ok = store.Last(discoveryScope("aws", regions, "910000000003") + ":fast")
+	assert.False(t, ok, "failed accounts are not recorded as snapshots")
+}
@@ -0,0 +1,88 @@
+package api
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/catherinevee/driftmgr/internal/drift/tfplan"
+)
+
+// handlePlanDrift handles POST /api/v1/drift/plan. It runs terraform plan -refresh-only in a
+// working directory under the repository root and reports the resources Terraform found
+// changed or deleted outside of it.
+func (s *Server) handlePlanDrift(w http.ResponseWriter, r *http.Request) {
+	var request PlanDriftRequest
+	if err := decodeRequest(r, &request); err != nil {
+		s.writeRequestError(w, err)
+		return
+	}
+	if s.config == nil || s.config.RepositoryRoot == "" {
+		s.writeError(w, http.StatusServiceUnavailable, "Plan drift detection is not configured")
+		return
+	}
+	terraform, err := s.services.Tools.Path("terraform")
+	if err != nil {
+		s.writeError(w, http.StatusServiceUnavailable, fmt.Sprintf("Plan drift detection is not available: %v", err))
+		return
+	}
+
+	root := s.config.RepositoryRoot
+	workDir, ok := s.directoryUnderRoot(w, root, request.WorkDir, "repository root", "Working directory")
+	if !ok {
+		return
+	}
+	options := tfplan.Options{WorkDir: workDir, BackendConfig: request.BackendConfig, Terraform: terraform}
+	for _, file := range request.BackendConfigFiles {
+		backendFile, ok := s.fileUnderRoot(w, root, file, "repository root", "Backend config file")
+		if !ok {
+			return
+		}
+		options.BackendConfigFiles = append(options.BackendConfigFiles, backendFile)
+	}
+	for _, file := range request.VarFiles {
+		varFile, ok := s.fileUnderRoot(w, root, file, "repository root", "Variable file")
+		if !ok {
+			return
+		}
+		options.VarFiles = append(options.VarFiles, varFile)
+	}
+
+	result, err := tfplan.Detect(r.Context(), options)
+	if err != nil {
+		s.writeError(w, http.StatusUnprocessableEntity, fmt.Sprintf("Failed to detect drift with a refresh-only plan: %v", err))
+		return
+	}
+	// Report the working directory as requested rather than where it is on the server
+	result.WorkDir = request.WorkDir
+	s.writeJSON(w, http.StatusOK, result)
+}
+
+// analyzePlan reports the changes of a saved plan without running Terraform, for CI pipelines
+// that plan in one stage and report in another. The plan is the output of terraform show
+// -json, or a base64 encoded plan file, which is rejected for needing Terraform to read.
+func (s *Server) analyzePlan(w http.ResponseWriter, plan json.RawMessage) {
+	planJSON := []byte(plan)
+	var encoded string
+	if json.Unmarshal(plan, &encoded) == nil {
+		decoded, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			s.writeError(w, http.StatusBadRequest, "plan must be the output of terraform show -json or a base64 encoded plan file")
+			return
+		}
+		planJSON = decoded
+	}
+
+	changes, err := tfplan.ParseChanges(planJSON)
+	switch {
+	case errors.Is(err, tfplan.ErrBinaryPlan):
+		s.writeError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	case err != nil:
+		s.writeError(w, http.StatusBadRequest, fmt.Sprintf("Invalid plan: %v", err))
+		return
+	}
+	s.writeJSON(w, http.StatusOK, PlanAnalysisResponse{InputType: "plan", PlanChanges: *changes})
+}
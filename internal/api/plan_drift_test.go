@@ -0,0 +1,49 @@
+package api
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnalyzeStateSetPlanInput(t *testing.T) {
+	server := &Server{}
+	analyze := func(body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		server.handleAnalyzeStateSet(w, httptest.NewRequest(http.MethodPost, "/api/v1/analyze", strings.NewReader(body)))
+		return w
+	}
+
+	plan := `{"format_version": "1.2", "planned_values": {}, "resource_changes": [
+		{"address": "aws_instance.web", "mode": "managed", "type": "aws_instance", "name": "web",
+		 "provider_name": "registry.terraform.io/hashicorp/aws",
+		 "change": {"actions": ["update"], "before": {"instance_type": "t3.micro"}, "after": {"instance_type": "t3.large"}}}
+	]}`
+	w := analyze(`{"input_type": "plan", "plan": ` + plan + `}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var response PlanAnalysisResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "plan", response.InputType)
+	assert.Equal(t, 1, response.Summary.Update)
+	require.Len(t, response.Changes, 1)
+	assert.Equal(t, "planned_update", response.Changes[0].DriftType)
+	assert.Equal(t, "instance_type", response.Changes[0].Changes[0].Field)
+
+	encoded := base64.StdEncoding.EncodeToString([]byte(plan))
+	assert.Equal(t, http.StatusOK, analyze(`{"input_type": "plan", "plan": "`+encoded+`"}`).Code, "a base64 encoded JSON plan is read")
+
+	binary := base64.StdEncoding.EncodeToString([]byte("PK\x03\x04tfplan"))
+	w = analyze(`{"input_type": "plan", "plan": "` + binary + `"}`)
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Contains(t, w.Body.String(), "terraform show -json")
+
+	assert.Equal(t, http.StatusBadRequest, analyze(`{"input_type": "plan"}`).Code)
+	assert.Equal(t, http.StatusBadRequest, analyze(`{"input_type": "plan", "plan": {"version": 4}}`).Code)
+	assert.Equal(t, http.StatusBadRequest, analyze(`{"input_type": "plan", "plan": {}, "state_file_ids": ["a"]}`).Code)
+}
@@ -0,0 +1,162 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/catherinevee/driftmgr/internal/drift/predictor"
+	internalModels "github.com/catherinevee/driftmgr/internal/models"
+	"github.com/catherinevee/driftmgr/internal/repositories"
+)
+
+// DriftPatternRepository stores the drift patterns teams register with the predictor
+type DriftPatternRepository interface {
+	Create(ctx context.Context, pattern *internalModels.DriftPattern) error
+	List(ctx context.Context) ([]*internalModels.DriftPattern, error)
+	Delete(ctx context.Context, id string) error
+}
+
+// handleTrainPredictor handles POST /api/v1/predict/train, rebuilding the drift predictor
+// from the recorded drift results and the discovered resources
+func (s *Server) handleTrainPredictor(w http.ResponseWriter, r *http.Request) {
+	model := predictor.Train(GetGlobalDriftStore().List(), GetGlobalResourceStore().All())
+
+	s.mu.Lock()
+	s.driftModel = model
+	s.mu.Unlock()
+
+	s.writeJSON(w, http.StatusOK, model)
+}
+
+// handlePredictorStats handles GET /api/v1/predict/stats, returning the drift rate of each
+// resource type and field the predictor learned
+func (s *Server) handlePredictorStats(w http.ResponseWriter, r *http.Request) {
+	model, ok := s.trainedPredictor(w)
+	if !ok {
+		return
+	}
+	s.writeJSON(w, http.StatusOK, model)
+}
+
+// handlePredictDrift handles GET /api/v1/predict, ranking the discovered resources the user
+// can see, optionally limited by provider, by how likely they are to drift. Registered drift
+// patterns are predicted before the predictor has been trained.
+func (s *Server) handlePredictDrift(w http.ResponseWriter, r *http.Request) {
+	var patterns []*internalModels.DriftPattern
+	if s.services != nil && s.services.DriftPatterns != nil {
+		var err error
+		if patterns, err = s.services.DriftPatterns.List(r.Context()); err != nil {
+			s.writeError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to list drift patterns: %v", err))
+			return
+		}
+	}
+
+	s.mu.RLock()
+	model := s.driftModel
+	s.mu.RUnlock()
+	if model == nil {
+		if len(patterns) == 0 {
+			s.writeError(w, http.StatusNotFound, untrainedPredictor)
+			return
+		}
+		model = &predictor.Model{}
+	}
+	s.writeJSON(w, http.StatusOK, model.Predict(liveResources(r, r.URL.Query().Get("provider")), patterns))
+}
+
+// handleListDriftPatterns handles GET /api/v1/predict/patterns, returning the registered
+// drift patterns
+func (s *Server) handleListDriftPatterns(w http.ResponseWriter, r *http.Request) {
+	if !s.driftPatternsAvailable(w) {
+		return
+	}
+	s.writeDriftPatterns(w, r, http.StatusOK)
+}
+
+// handleCreateDriftPattern handles POST /api/v1/predict/patterns, registering a drift pattern
+// and returning the registered patterns
+func (s *Server) handleCreateDriftPattern(w http.ResponseWriter, r *http.Request) {
+	if !s.driftPatternsAvailable(w) {
+		return
+	}
+
+	var pattern internalModels.DriftPattern
+	if err := decodeRequest(r, &pattern); err != nil {
+		s.writeRequestError(w, err)
+		return
+	}
+	pattern.ID = ""
+	pattern.CreatedAt = time.Time{}
+	pattern.CreatedBy = requestUser(r)
+	if err := pattern.Validate(); err != nil {
+		s.writeError(w, http.StatusBadRequest, fmt.Sprintf("Invalid drift pattern: %v", err))
+		return
+	}
+
+	if err := s.services.DriftPatterns.Create(r.Context(), &pattern); err != nil {
+		s.writeError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to create drift pattern: %v", err))
+		return
+	}
+	s.writeDriftPatterns(w, r, http.StatusCreated)
+}
+
+// handleDeleteDriftPattern handles DELETE /api/v1/predict/patterns/{id}, removing a drift
+// pattern and returning the registered patterns
+func (s *Server) handleDeleteDriftPattern(w http.ResponseWriter, r *http.Request) {
+	if !s.driftPatternsAvailable(w) {
+		return
+	}
+
+	id := r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:]
+	err := s.services.DriftPatterns.Delete(r.Context(), id)
+	switch {
+	case errors.Is(err, repositories.ErrDriftPatternNotFound):
+		s.writeError(w, http.StatusNotFound, fmt.Sprintf("Drift pattern %s not found", id))
+		return
+	case err != nil:
+		s.writeError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to delete drift pattern: %v", err))
+		return
+	}
+	s.writeDriftPatterns(w, r, http.StatusOK)
+}
+
+// driftPatternsAvailable writes 503 Service Unavailable and returns false when drift patterns
+// cannot be stored
+func (s *Server) driftPatternsAvailable(w http.ResponseWriter) bool {
+	if s.services == nil || s.services.DriftPatterns == nil {
+		s.writeError(w, http.StatusServiceUnavailable, "Drift patterns not available")
+		return false
+	}
+	return true
+}
+
+// writeDriftPatterns writes the registered drift patterns with status
+func (s *Server) writeDriftPatterns(w http.ResponseWriter, r *http.Request, status int) {
+	patterns, err := s.services.DriftPatterns.List(r.Context())
+	if err != nil {
+		s.writeError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to list drift patterns: %v", err))
+		return
+	}
+	s.writeJSON(w, status, patterns)
+}
+
+// untrainedPredictor is the error returned before the drift predictor has been trained
+const untrainedPredictor = "Drift predictor has not been trained; POST /api/v1/predict/train first"
+
+// trainedPredictor returns the trained drift predictor, writing 404 Not Found when it has
+// not been trained yet
+func (s *Server) trainedPredictor(w http.ResponseWriter) (*predictor.Model, bool) {
+	s.mu.RLock()
+	model := s.driftModel
+	s.mu.RUnlock()
+
+	if model == nil {
+		s.writeError(w, http.StatusNotFound, untrainedPredictor)
+		return nil, false
+	}
+	return model, true
+}
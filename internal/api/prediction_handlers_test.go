@@ -0,0 +1,120 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/catherinevee/driftmgr/internal/drift/comparator"
+	"github.com/catherinevee/driftmgr/internal/drift/detector"
+	"github.com/catherinevee/driftmgr/internal/drift/predictor"
+	internalModels "github.com/catherinevee/driftmgr/internal/models"
+	"github.com/catherinevee/driftmgr/internal/repositories"
+	"github.com/catherinevee/driftmgr/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDriftPredictor(t *testing.T) {
+	server := &Server{}
+
+	w := httptest.NewRecorder()
+	server.handlePredictorStats(w, httptest.NewRequest(http.MethodGet, "/api/v1/predict/stats", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code, "the predictor is untrained")
+
+	resources := GetGlobalResourceStore()
+	resources.Save("predict-test", []models.Resource{
+		{ID: "predict-queue-1", Type: "predict_test_queue", Provider: "predict-test"},
+		{ID: "predict-queue-2", Type: "predict_test_queue", Provider: "predict-test"},
+	}, time.Now())
+	t.Cleanup(func() { resources.Save("predict-test", nil, time.Now()) })
+
+	drifts := GetGlobalDriftStore()
+	drifts.Store("predict-test-drift", &detector.DriftResult{
+		Resource: "predict-queue-1", ResourceType: "predict_test_queue", Provider: "predict-test",
+		DriftType: detector.ConfigurationDrift, Differences: []comparator.Difference{{Path: "visibility_timeout"}},
+	})
+	t.Cleanup(func() { drifts.Delete("predict-test-drift") })
+
+	w = httptest.NewRecorder()
+	server.handleTrainPredictor(w, httptest.NewRequest(http.MethodPost, "/api/v1/predict/train", nil))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	w = httptest.NewRecorder()
+	server.handlePredictorStats(w, httptest.NewRequest(http.MethodGet, "/api/v1/predict/stats", nil))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var model predictor.Model
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &model))
+	var pattern *predictor.Pattern
+	for i := range model.Patterns {
+		if model.Patterns[i].ResourceType == "predict_test_queue" {
+			pattern = &model.Patterns[i]
+		}
+	}
+	require.NotNil(t, pattern)
+	assert.Equal(t, 2, pattern.Resources)
+	assert.Equal(t, 1, pattern.DriftedResources)
+
+	w = httptest.NewRecorder()
+	server.handlePredictDrift(w, httptest.NewRequest(http.MethodGet, "/api/v1/predict?provider=predict-test", nil))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var predictions []predictor.Prediction
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &predictions))
+	require.Len(t, predictions, 2)
+	assert.Equal(t, []string{"visibility_timeout"}, predictions[0].Fields)
+	assert.InDelta(t, 0.5, predictions[0].Confidence, 0.001)
+}
+
+func TestDriftPatterns(t *testing.T) {
+	server := &Server{services: &Services{DriftPatterns: repositories.NewMemoryDriftPatternRepository()}}
+
+	w := httptest.NewRecorder()
+	server.handlePredictDrift(w, httptest.NewRequest(http.MethodGet, "/api/v1/predict", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code, "nothing to predict without training or patterns")
+
+	for _, body := range []string{
+		`{"resource_type": "aws_autoscaling_group", "likelihood": 0.5}`,
+		`{"resource_type": "aws_autoscaling_group", "field": "desired_capacity", "likelihood": 1.5}`,
+		`{"resource_type": "aws_autoscaling_group", "field": "desired_capacity", "likelihood": 0.5, "window": "daily"}`,
+	} {
+		w = httptest.NewRecorder()
+		server.handleCreateDriftPattern(w, httptest.NewRequest(http.MethodPost, "/api/v1/predict/patterns", strings.NewReader(body)))
+		assert.Equal(t, http.StatusBadRequest, w.Code, body)
+	}
+
+	w = httptest.NewRecorder()
+	server.handleCreateDriftPattern(w, httptest.NewRequest(http.MethodPost, "/api/v1/predict/patterns", strings.NewReader(
+		`{"resource_type": "predict_pattern_group", "field": "desired_capacity", "likelihood": 0.9, "window": "24h"}`)))
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var patterns []*internalModels.DriftPattern
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &patterns))
+	require.Len(t, patterns, 1)
+	assert.Equal(t, "anonymous", patterns[0].CreatedBy)
+
+	resources := GetGlobalResourceStore()
+	resources.Save("predict-pattern-test", []models.Resource{
+		{ID: "predict-pattern-group-1", Type: "predict_pattern_group", Provider: "predict-pattern-test"},
+	}, time.Now())
+	t.Cleanup(func() { resources.Save("predict-pattern-test", nil, time.Now()) })
+
+	w = httptest.NewRecorder()
+	server.handlePredictDrift(w, httptest.NewRequest(http.MethodGet, "/api/v1/predict?provider=predict-pattern-test", nil))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var predictions []predictor.Prediction
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &predictions))
+	require.Len(t, predictions, 1)
+	assert.Equal(t, 0.9, predictions[0].Confidence)
+	assert.Equal(t, []string{patterns[0].ID}, predictions[0].Patterns)
+
+	w = httptest.NewRecorder()
+	server.handleDeleteDriftPattern(w, httptest.NewRequest(http.MethodDelete, "/api/v1/predict/patterns/"+patterns[0].ID, nil))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.JSONEq(t, "[]", w.Body.String())
+
+	w = httptest.NewRecorder()
+	server.handleDeleteDriftPattern(w, httptest.NewRequest(http.MethodDelete, "/api/v1/predict/patterns/"+patterns[0].ID, nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
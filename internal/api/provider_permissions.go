@@ -0,0 +1,348 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+	iamtypes "github.com/aws/aws-sdk-go-v2/service/iam/types"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	awsprovider "github.com/catherinevee/driftmgr/internal/providers/aws"
+	azureprovider "github.com/catherinevee/driftmgr/internal/providers/azure"
+	gcpprovider "github.com/catherinevee/driftmgr/internal/providers/gcp"
+	"github.com/catherinevee/driftmgr/pkg/models"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+)
+
+// ProviderPermissionsResponse is the response of GET /api/v1/providers/{provider}/permissions.
+// Services always lists what discovery needs; when Checked is set, each service's Missing
+// lists the permissions the credentials lack and Missing collects them all. Checked is false
+// when the credentials' permissions could not be evaluated, with Error saying why.
+type ProviderPermissionsResponse struct {
+	Provider string `json:"provider"`
+	// Identity is who the credentials authenticate as, and Scope the account, subscription or
+	// project the permissions were checked in
+	Identity string                   `json:"identity,omitempty"`
+	Scope    string                   `json:"scope,omitempty"`
+	Checked  bool                     `json:"checked"`
+	Error    string                   `json:"error,omitempty"`
+	Services []ServicePermissionCheck `json:"services"`
+	Missing  []string                 `json:"missing"`
+}
+
+// ServicePermissionCheck is the permissions discovery needs to read one service, with those
+// the credentials lack
+type ServicePermissionCheck struct {
+	models.ServicePermissions
+	Missing []string `json:"missing,omitempty"`
+}
+
+// providerPermissionSets returns the permissions discovery needs, by provider
+var providerPermissionSets = map[string]func() ([]models.ServicePermissions, error){
+	"aws":   awsprovider.DiscoveryPermissions,
+	"azure": azureprovider.DiscoveryPermissions,
+	"gcp":   gcpprovider.DiscoveryPermissions,
+}
+
+// providerPermissionCheckers report which of the permissions the default credentials of a
+// provider hold, recording who they are and where they were checked in the response
+var providerPermissionCheckers = map[string]func(ctx context.Context, response *ProviderPermissionsResponse, permissions []string) (map[string]bool, error){
+	"aws":   checkAWSPermissions,
+	"azure": checkAzurePermissions,
+	"gcp":   checkGCPPermissions,
+}
+
+// handleProviderPermissions handles GET /api/v1/providers/{provider}/permissions, checking
+// before a scan whether the server's credentials hold the permissions discovery calls need,
+// so missing ones are a checklist rather than AccessDenied failures mid-discovery
+func (s *Server) handleProviderPermissions(w http.ResponseWriter, r *http.Request) {
+	segments := strings.Split(r.URL.Path, "/")
+	provider := segments[len(segments)-2]
+	permissionSet, ok := providerPermissionSets[provider]
+	if !ok {
+		s.writeError(w, http.StatusNotFound, fmt.Sprintf("Permission checks are not supported for provider %s", provider))
+		return
+	}
+	services, err := permissionSet()
+	if err != nil {
+		s.writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response := &ProviderPermissionsResponse{Provider: provider, Missing: []string{}}
+	granted, err := providerPermissionCheckers[provider](r.Context(), response, uniquePermissions(services))
+	if err != nil {
+		response.Error = err.Error()
+		granted = nil
+	}
+	response.Checked = granted != nil
+	response.Services, response.Missing = permissionReport(services, granted)
+	s.writeJSON(w, http.StatusOK, response)
+}
+
+// uniquePermissions lists each permission the services need once, in order
+func uniquePermissions(services []models.ServicePermissions) []string {
+	seen := make(map[string]bool)
+	var permissions []string
+	for _, service := range services {
+		for _, permission := range service.Permissions {
+			if !seen[permission] {
+				seen[permission] = true
+				permissions = append(permissions, permission)
+			}
+		}
+	}
+	return permissions
+}
+
+// permissionReport lists each service with the permissions not granted, and every missing
+// permission once. Nothing is reported missing when granted is nil, as the check did not run.
+func permissionReport(services []models.ServicePermissions, granted map[string]bool) ([]ServicePermissionCheck, []string) {
+	checks := make([]ServicePermissionCheck, 0, len(services))
+	missing := []string{}
+	seen := make(map[string]bool)
+	for _, service := range services {
+		check := ServicePermissionCheck{ServicePermissions: service}
+		for _, permission := range service.Permissions {
+			if granted == nil || granted[permission] {
+				continue
+			}
+			check.Missing = append(check.Missing, permission)
+			if !seen[permission] {
+				seen[permission] = true
+				missing = append(missing, permission)
+			}
+		}
+		checks = append(checks, check)
+	}
+	return checks, missing
+}
+
+// checkAWSPermissions evaluates the actions against the IAM policies of the user or role the
+// default AWS credentials belong to with SimulatePrincipalPolicy, which itself needs
+// iam:SimulatePrincipalPolicy. Resource policies and service control policies are not
+// part of the simulation.
+func checkAWSPermissions(ctx context.Context, response *ProviderPermissionsResponse, actions []string) (map[string]bool, error) {
+	cfg, err := awsconfig.LoadDefaultConfig(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load AWS config: %w", err)
+	}
+	if cfg.Region == "" {
+		cfg.Region = "us-east-1"
+	}
+	identity, err := sts.NewFromConfig(cfg).GetCallerIdentity(ctx, &sts.GetCallerIdentityInput{})
+	if err != nil {
+		return nil, fmt.Errorf("the AWS credentials were not accepted: %w", err)
+	}
+	response.Identity = aws.ToString(identity.Arn)
+	response.Scope = aws.ToString(identity.Account)
+
+	granted := make(map[string]bool, len(actions))
+	if strings.HasSuffix(response.Identity, ":root") {
+		// The account root user is allowed everything and cannot be simulated
+		for _, action := range actions {
+			granted[action] = true
+		}
+		return granted, nil
+	}
+	source := awsPolicySourceARN(response.Identity)
+	if source == "" {
+		return nil, fmt.Errorf("the permissions of %s cannot be simulated", response.Identity)
+	}
+
+	client := iam.NewFromConfig(cfg)
+	if i := strings.Index(source, ":role/"); i >= 0 {
+		// The session ARN drops the role's path, which the simulation needs
+		role, err := client.GetRole(ctx, &iam.GetRoleInput{RoleName: aws.String(source[i+len(":role/"):])})
+		if err == nil && role.Role != nil {
+			source = aws.ToString(role.Role.Arn)
+		}
+	}
+
+	input := &iam.SimulatePrincipalPolicyInput{PolicySourceArn: aws.String(source), ActionNames: actions}
+	for {
+		page, err := client.SimulatePrincipalPolicy(ctx, input)
+		if err != nil {
+			return nil, fmt.Errorf("failed to simulate the policies of %s, which needs iam:SimulatePrincipalPolicy: %w", source, err)
+		}
+		for _, result := range page.EvaluationResults {
+			granted[aws.ToString(result.EvalActionName)] = result.EvalDecision == iamtypes.PolicyEvaluationDecisionTypeAllowed
+		}
+		if !page.IsTruncated {
+			return granted, nil
+		}
+		input.Marker = page.Marker
+	}
+}
+
+// awsPolicySourceARN returns the ARN of the IAM user or role whose policies apply to a caller
+// identity: the user itself, or the role of an assumed role session without its path. It
+// returns "" for federated users and the root user, whose policies cannot be simulated.
+func awsPolicySourceARN(caller string) string {
+	parts := strings.SplitN(caller, ":", 6)
+	if len(parts) != 6 || parts[0] != "arn" {
+		return ""
+	}
+	partition, service, account, resource := parts[1], parts[2], parts[4], parts[5]
+	switch {
+	case service == "iam" && (strings.HasPrefix(resource, "user/") || strings.HasPrefix(resource, "role/")):
+		return caller
+	case service == "sts" && strings.HasPrefix(resource, "assumed-role/"):
+		role := strings.Split(strings.TrimPrefix(resource, "assumed-role/"), "/")[0]
+		return fmt.Sprintf("arn:%s:iam::%s:role/%s", partition, account, role)
+	default:
+		return ""
+	}
+}
+
+// azurePermission is an entry of the permissions a principal holds at a scope, from its role
+// assignments
+type azurePermission struct {
+	Actions    []string `json:"actions"`
+	NotActions []string `json:"notActions"`
+}
+
+// checkAzurePermissions reads the actions the default Azure credentials are granted on the
+// subscription in AZURE_SUBSCRIPTION_ID, as discovery uses, from the Microsoft.Authorization
+// permissions API
+func checkAzurePermissions(ctx context.Context, response *ProviderPermissionsResponse, actions []string) (map[string]bool, error) {
+	subscription := os.Getenv("AZURE_SUBSCRIPTION_ID")
+	if subscription == "" {
+		return nil, errors.New("AZURE_SUBSCRIPTION_ID is not set")
+	}
+	response.Scope = subscription
+	cred, err := azidentity.NewDefaultAzureCredential(nil)
+	if err != nil {
+		return nil, fmt.Errorf("no Azure credentials found: %w", err)
+	}
+	token, err := cred.GetToken(ctx, policy.TokenRequestOptions{Scopes: []string{"https://management.azure.com/.default"}})
+	if err != nil {
+		return nil, fmt.Errorf("the Azure credentials were not accepted: %w", err)
+	}
+	claims := jwtClaims(token.Token)
+	for _, claim := range []string{"upn", "unique_name", "appid", "oid"} {
+		if claims[claim] != "" {
+			response.Identity = claims[claim]
+			break
+		}
+	}
+
+	var permissions []azurePermission
+	endpoint := fmt.Sprintf("https://management.azure.com/subscriptions/%s/providers/Microsoft.Authorization/permissions?api-version=2022-04-01", url.PathEscape(subscription))
+	for endpoint != "" {
+		var page struct {
+			Value    []azurePermission `json:"value"`
+			NextLink string            `json:"nextLink"`
+		}
+		if err := getJSON(ctx, http.DefaultClient, endpoint, "Bearer "+token.Token, &page); err != nil {
+			return nil, fmt.Errorf("failed to read the permissions on subscription %s: %w", subscription, err)
+		}
+		permissions = append(permissions, page.Value...)
+		endpoint = page.NextLink
+	}
+
+	granted := make(map[string]bool, len(actions))
+	for _, action := range actions {
+		granted[action] = azureGranted(permissions, action)
+	}
+	return granted, nil
+}
+
+// azureGranted reports whether an entry grants an action without excluding it in its
+// NotActions
+func azureGranted(permissions []azurePermission, action string) bool {
+	for _, permission := range permissions {
+		if azureActionsMatch(permission.Actions, action) && !azureActionsMatch(permission.NotActions, action) {
+			return true
+		}
+	}
+	return false
+}
+
+// azureActionsMatch reports whether any of the action patterns, in which * matches any
+// characters, matches an action. Azure actions are not case sensitive.
+func azureActionsMatch(patterns []string, action string) bool {
+	for _, pattern := range patterns {
+		expression := "(?i)^" + strings.ReplaceAll(regexp.QuoteMeta(pattern), `\*`, ".*") + "$"
+		if matched, err := regexp.MatchString(expression, action); err == nil && matched {
+			return true
+		}
+	}
+	return false
+}
+
+// checkGCPPermissions asks Cloud Resource Manager which of the permissions the GCP
+// application default credentials hold on their project, or GOOGLE_CLOUD_PROJECT's
+func checkGCPPermissions(ctx context.Context, response *ProviderPermissionsResponse, permissions []string) (map[string]bool, error) {
+	creds, err := google.FindDefaultCredentials(ctx, "https://www.googleapis.com/auth/cloud-platform")
+	if err != nil {
+		return nil, fmt.Errorf("no GCP credentials found: %w", err)
+	}
+	project := creds.ProjectID
+	if project == "" {
+		project = os.Getenv("GOOGLE_CLOUD_PROJECT")
+	}
+	if project == "" {
+		return nil, errors.New("no GCP project is configured; set GOOGLE_CLOUD_PROJECT")
+	}
+	response.Scope = project
+	var key struct {
+		ClientEmail string `json:"client_email"`
+	}
+	if json.Unmarshal(creds.JSON, &key) == nil {
+		response.Identity = key.ClientEmail
+	}
+
+	var held struct {
+		Permissions []string `json:"permissions"`
+	}
+	endpoint := fmt.Sprintf("https://cloudresourcemanager.googleapis.com/v1/projects/%s:testIamPermissions", url.PathEscape(project))
+	request := map[string][]string{"permissions": permissions}
+	if err := postJSON(ctx, oauth2.NewClient(ctx, creds.TokenSource), endpoint, request, &held); err != nil {
+		return nil, fmt.Errorf("failed to test the permissions on project %s: %w", project, err)
+	}
+
+	granted := make(map[string]bool, len(permissions))
+	for _, permission := range permissions {
+		granted[permission] = false
+	}
+	for _, permission := range held.Permissions {
+		granted[permission] = true
+	}
+	return granted, nil
+}
+
+// postJSON posts a JSON body and decodes the JSON response
+func postJSON(ctx context.Context, client *http.Client, endpoint string, body, out interface{}) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s returned %s", endpoint, resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
@@ -0,0 +1,93 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleProviderPermissions(t *testing.T) {
+	checkers := providerPermissionCheckers
+	providerPermissionCheckers = map[string]func(ctx context.Context, response *ProviderPermissionsResponse, permissions []string) (map[string]bool, error){
+		"aws": func(ctx context.Context, response *ProviderPermissionsResponse, permissions []string) (map[string]bool, error) {
+			response.Identity = "arn:aws:sts::123456789012:assumed-role/Discovery/session"
+			granted := make(map[string]bool)
+			for _, permission := range permissions {
+				granted[permission] = permission != "ec2:DescribeInstances" && permission != "ec2:DescribeSecurityGroups"
+			}
+			return granted, nil
+		},
+		"gcp": func(ctx context.Context, response *ProviderPermissionsResponse, permissions []string) (map[string]bool, error) {
+			return nil, errors.New("no GCP credentials found")
+		},
+	}
+	t.Cleanup(func() { providerPermissionCheckers = checkers })
+
+	server := &Server{}
+	get := func(provider string) (int, ProviderPermissionsResponse) {
+		w := httptest.NewRecorder()
+		server.handleProviderPermissions(w, httptest.NewRequest(http.MethodGet, "/api/v1/providers/"+provider+"/permissions", nil))
+		var response ProviderPermissionsResponse
+		if w.Code == http.StatusOK {
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		}
+		return w.Code, response
+	}
+
+	code, response := get("aws")
+	require.Equal(t, http.StatusOK, code)
+	assert.True(t, response.Checked)
+	assert.Equal(t, []string{"ec2:DescribeInstances", "ec2:DescribeSecurityGroups"}, response.Missing)
+	for _, service := range response.Services {
+		switch service.Service {
+		case "EC2":
+			assert.Equal(t, []string{"ec2:DescribeInstances", "ec2:DescribeSecurityGroups"}, service.Missing)
+		case "VPC":
+			assert.Equal(t, []string{"ec2:DescribeSecurityGroups"}, service.Missing)
+		default:
+			assert.Empty(t, service.Missing, service.Service)
+		}
+	}
+
+	// A check that cannot run still lists what discovery needs
+	code, response = get("gcp")
+	require.Equal(t, http.StatusOK, code)
+	assert.False(t, response.Checked)
+	assert.Equal(t, "no GCP credentials found", response.Error)
+	assert.NotEmpty(t, response.Services)
+	assert.Empty(t, response.Missing)
+
+	code, _ = get("digitalocean")
+	assert.Equal(t, http.StatusNotFound, code)
+}
+
+func TestAWSPolicySourceARN(t *testing.T) {
+	for caller, want := range map[string]string{
+		"arn:aws:iam::123456789012:user/ops/alice":                    "arn:aws:iam::123456789012:user/ops/alice",
+		"arn:aws:sts::123456789012:assumed-role/Discovery/i-0abc":     "arn:aws:iam::123456789012:role/Discovery",
+		"arn:aws-cn:sts::123456789012:assumed-role/Discovery/session": "arn:aws-cn:iam::123456789012:role/Discovery",
+		"arn:aws:sts::123456789012:federated-user/alice":              "",
+		"arn:aws:iam::123456789012:root":                              "",
+		"not an arn":                                                  "",
+	} {
+		assert.Equal(t, want, awsPolicySourceARN(caller), caller)
+	}
+}
+
+func TestAzureGranted(t *testing.T) {
+	permissions := []azurePermission{
+		{Actions: []string{"*/read"}, NotActions: []string{"Microsoft.KeyVault/*"}},
+		{Actions: []string{"microsoft.web/sites/*"}},
+	}
+	assert.True(t, azureGranted(permissions, "Microsoft.Compute/virtualMachines/read"))
+	assert.False(t, azureGranted(permissions, "Microsoft.KeyVault/vaults/read"))
+	assert.True(t, azureGranted(permissions, "Microsoft.Web/sites/read"))
+	assert.False(t, azureGranted(permissions, "Microsoft.Compute/virtualMachines/write"))
+	assert.False(t, azureGranted(nil, "Microsoft.Compute/virtualMachines/read"))
+}
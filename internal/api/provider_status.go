@@ -0,0 +1,568 @@
+package api
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials/ec2rolecreds"
+	"github.com/aws/aws-sdk-go-v2/credentials/endpointcreds"
+	"github.com/aws/aws-sdk-go-v2/credentials/ssocreds"
+	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/catherinevee/driftmgr/internal/events"
+	awsprovider "github.com/catherinevee/driftmgr/internal/providers/aws"
+	"github.com/catherinevee/driftmgr/internal/websocket"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+)
+
+const (
+	// providerStatusTTL is how long provider credential checks are reused, so a dashboard
+	// polling the status does not call every cloud on each poll
+	providerStatusTTL = time.Minute
+
+	// defaultCredentialExpiryWarning is how long before credentials expire they are warned
+	// about when the server config does not say
+	defaultCredentialExpiryWarning = 24 * time.Hour
+
+	// maxServiceAccountKeyAge is the age past which a GCP service account key should be
+	// rotated
+	maxServiceAccountKeyAge = 90 * 24 * time.Hour
+
+	// credentialExpiryNotification is the notification type credential warnings are sent as
+	credentialExpiryNotification = "credential_expiry_warning"
+)
+
+// Provider credential statuses
+const (
+	providerNotConfigured = "not_configured"
+	providerValid         = "valid"
+	providerExpired       = "expired"
+	providerInvalid       = "invalid"
+)
+
+// ProviderStatus reports whether a provider's credentials were found and whether the cloud
+// accepted them, with the identity they authenticate as
+type ProviderStatus struct {
+	Provider string `json:"provider"`
+	// Status is not_configured when no credentials were found, valid when the cloud accepted
+	// them, and expired or invalid when it refused them
+	Status     string `json:"status"`
+	Configured bool   `json:"configured"`
+	Valid      bool   `json:"valid"`
+	// Account is the AWS account ID, Subscription and Tenant the Azure subscription and
+	// tenant, and Project the GCP project
+	Account      string `json:"account,omitempty"`
+	Subscription string `json:"subscription,omitempty"`
+	Tenant       string `json:"tenant,omitempty"`
+	Project      string `json:"project,omitempty"`
+	// Identity is the principal the credentials authenticate as, such as an IAM role ARN or
+	// a service account email
+	Identity string `json:"identity,omitempty"`
+	// ExpiresAt is when the credentials in use expire, when the provider says and they are
+	// not refreshed automatically
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	// KeyCreatedAt is when the GCP service account key in use was created
+	KeyCreatedAt *time.Time `json:"key_created_at,omitempty"`
+	// SSO describes the AWS profile in use when it signs in through IAM Identity Center
+	SSO *awsprovider.SSOProfile `json:"sso,omitempty"`
+	// Warnings describe credentials that expire soon or should be rotated, such as "AWS
+	// credentials expire in 2h"
+	Warnings  []string  `json:"warnings,omitempty"`
+	Error     string    `json:"error,omitempty"`
+	LatencyMS int64     `json:"latency_ms"`
+	CheckedAt time.Time `json:"checked_at"`
+
+	// credential names what ExpiresAt applies to in warnings, such as "client secret"
+	credential string
+	// warningKeys identify the credentials each warning is about, so a warning is notified
+	// once rather than on every check as the time remaining counts down
+	warningKeys []string
+}
+
+// ProviderStatusResponse is the response of GET /api/v1/providers/status
+type ProviderStatusResponse struct {
+	Providers []ProviderStatus `json:"providers"`
+	// Cached reports that the checks were reused from an earlier request
+	Cached bool `json:"cached"`
+}
+
+// providerStatusProviders lists the providers whose credentials are checked, in the order
+// they are reported
+var providerStatusProviders = []string{"aws", "azure", "gcp"}
+
+// providerStatusCheckers make one lightweight authenticated call with a provider's default
+// credentials
+var providerStatusCheckers = map[string]func(ctx context.Context, status *ProviderStatus){
+	"aws":   checkAWSStatus,
+	"azure": checkAzureStatus,
+	"gcp":   checkGCPStatus,
+}
+
+// providerStatuses remembers the last provider credential checks
+var providerStatuses = &providerStatusCache{}
+
+// providerStatusCache holds the last provider credential checks until they expire. Checks
+// run with the lock held, so concurrent requests wait for one round of checks.
+type providerStatusCache struct {
+	mu       sync.Mutex
+	statuses []ProviderStatus
+	expires  time.Time
+	// notified holds the warnings already notified, so each is sent once
+	notified map[string]bool
+}
+
+// handleProviderStatus handles GET /api/v1/providers/status, reporting for each provider
+// whether credentials are configured and valid, which identity they belong to and whether
+// they expire soon, so the dashboard can tell why a provider returned nothing. Checks are
+// reused for a minute unless refresh=true.
+func (s *Server) handleProviderStatus(w http.ResponseWriter, r *http.Request) {
+	statuses, cached := s.currentProviderStatuses(r.Context(), r.URL.Query().Get("refresh") == "true")
+	s.writeJSON(w, http.StatusOK, ProviderStatusResponse{Providers: statuses, Cached: cached})
+}
+
+// initializeCredentialChecks checks provider credentials on the configured schedule, so
+// expiry warnings are notified without anyone polling the status
+func (s *Server) initializeCredentialChecks() {
+	if s.config.CredentialCheckSchedule == "" {
+		return
+	}
+	if s.services.Scheduler == nil {
+		log.Printf("WARNING: scheduled credential checks are disabled: the scheduler is unavailable")
+		return
+	}
+	if err := s.services.Scheduler.AddJob("credential checks", s.config.CredentialCheckSchedule, func(ctx context.Context) {
+		s.currentProviderStatuses(ctx, true)
+	}); err != nil {
+		log.Printf("WARNING: scheduled credential checks are disabled: %v", err)
+	}
+}
+
+// currentProviderStatuses returns the last provider credential checks while they are fresh,
+// otherwise checks again and notifies any new warning. It reports whether the checks were
+// reused.
+func (s *Server) currentProviderStatuses(ctx context.Context, refresh bool) ([]ProviderStatus, bool) {
+	providerStatuses.mu.Lock()
+	defer providerStatuses.mu.Unlock()
+	if !refresh && providerStatuses.statuses != nil && time.Now().Before(providerStatuses.expires) {
+		return providerStatuses.statuses, true
+	}
+
+	window := defaultCredentialExpiryWarning
+	if s.config != nil && s.config.CredentialExpiryWarning > 0 {
+		window = s.config.CredentialExpiryWarning
+	}
+	providerStatuses.statuses = checkProviderStatuses(ctx, window)
+	providerStatuses.expires = time.Now().Add(providerStatusTTL)
+
+	if providerStatuses.notified == nil {
+		providerStatuses.notified = make(map[string]bool)
+	}
+	for _, status := range providerStatuses.statuses {
+		if status.Status == providerValid {
+			// Credentials that expire again after being renewed are notified again
+			delete(providerStatuses.notified, status.Provider+"|expired")
+		}
+		notify := false
+		for _, key := range status.warningKeys {
+			if !providerStatuses.notified[key] {
+				providerStatuses.notified[key] = true
+				notify = true
+			}
+		}
+		if notify {
+			s.notifyCredentialWarning(status)
+		}
+	}
+	return providerStatuses.statuses, false
+}
+
+// notifyCredentialWarning alerts users through their notification channels, and connected
+// clients, that a provider's credentials expire soon or should be rotated
+func (s *Server) notifyCredentialWarning(status ProviderStatus) {
+	message := strings.Join(status.Warnings, "; ")
+	log.Printf("Credential warning for %s: %s", status.Provider, message)
+
+	severity := "medium"
+	if status.Status == providerExpired || (status.ExpiresAt != nil && status.ExpiresAt.Before(time.Now())) {
+		severity = "high"
+	}
+	data := map[string]interface{}{
+		"provider":   status.Provider,
+		"warnings":   status.Warnings,
+		"expires_at": status.ExpiresAt,
+	}
+	if s.services == nil {
+		return
+	}
+	// Delivered in the background so a slow channel does not hold up the check
+	if s.services.Notifications != nil {
+		go s.services.Notifications.BroadcastNotification(&events.NotificationMessage{
+			ID:        fmt.Sprintf("%s-%d", credentialExpiryNotification, time.Now().UnixNano()),
+			Type:      credentialExpiryNotification,
+			Title:     fmt.Sprintf("%s credentials need attention", providerDisplayName(status.Provider)),
+			Message:   message,
+			Severity:  severity,
+			Timestamp: time.Now(),
+			Data:      data,
+			Metadata:  map[string]string{"provider": status.Provider},
+		})
+	}
+	if s.services.WebSocket != nil {
+		s.services.WebSocket.GetHub().BroadcastMessage(websocket.NewMessage(credentialExpiryNotification, data))
+	}
+}
+
+// checkProviderStatuses checks every provider's credentials concurrently, each within the
+// provider health check timeout, warning about credentials that expire within window
+func checkProviderStatuses(ctx context.Context, window time.Duration) []ProviderStatus {
+	statuses := make([]ProviderStatus, len(providerStatusProviders))
+	var wg sync.WaitGroup
+	for i, provider := range providerStatusProviders {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			checkCtx, cancel := context.WithTimeout(ctx, providerHealthCheckTimeout)
+			defer cancel()
+
+			start := time.Now()
+			status := ProviderStatus{Provider: provider, Status: providerNotConfigured}
+			providerStatusCheckers[provider](checkCtx, &status)
+			status.Configured = status.Status != providerNotConfigured
+			status.Valid = status.Status == providerValid
+			status.LatencyMS = time.Since(start).Milliseconds()
+			status.CheckedAt = time.Now().UTC()
+			status.addWarnings(status.CheckedAt, window)
+			statuses[i] = status
+		}()
+	}
+	wg.Wait()
+	return statuses
+}
+
+// refused records that a provider refused configured credentials, as expired when its error
+// says so
+func (status *ProviderStatus) refused(err error) {
+	status.Status = providerInvalid
+	if strings.Contains(strings.ToLower(err.Error()), "expired") {
+		status.Status = providerExpired
+	}
+	status.Error = err.Error()
+}
+
+// addWarnings warns about credentials that have expired or expire within window, and about
+// service account keys past the age they should be rotated at
+func (status *ProviderStatus) addWarnings(now time.Time, window time.Duration) {
+	name := providerDisplayName(status.Provider)
+	if status.SSO != nil && status.SSO.LoginRequired && status.SSO.TokenExpiresAt == nil {
+		status.warn("aws|sso|"+status.SSO.Name, "AWS SSO profile %s is not signed in", status.SSO.Name)
+	} else if status.Status == providerExpired && status.ExpiresAt == nil {
+		status.warn(status.Provider+"|expired", "%s credentials have expired", name)
+	}
+	if status.ExpiresAt != nil {
+		credential := status.credential
+		if credential == "" {
+			credential = "credentials"
+		}
+		// "credentials expire", "client secret expires"
+		verb, past := "expires", "expired"
+		if strings.HasSuffix(credential, "s") {
+			verb = "expire"
+		}
+		key := fmt.Sprintf("%s|expires|%d", status.Provider, status.ExpiresAt.Unix())
+		remaining := status.ExpiresAt.Sub(now)
+		switch {
+		case remaining <= 0:
+			status.warn(key, "%s %s %s %s ago", name, credential, past, shortDuration(-remaining))
+		case remaining <= window:
+			status.warn(key, "%s %s %s in %s", name, credential, verb, shortDuration(remaining))
+		}
+	}
+	if status.KeyCreatedAt != nil {
+		if age := now.Sub(*status.KeyCreatedAt); age > maxServiceAccountKeyAge {
+			key := fmt.Sprintf("%s|key|%d", status.Provider, status.KeyCreatedAt.Unix())
+			status.warn(key, "%s service account key is %s old; rotate it", name, shortDuration(age))
+		}
+	}
+}
+
+// warn adds a warning about the credentials key identifies
+func (status *ProviderStatus) warn(key, format string, args ...interface{}) {
+	status.Warnings = append(status.Warnings, fmt.Sprintf(format, args...))
+	status.warningKeys = append(status.warningKeys, key)
+}
+
+// shortDuration formats a duration as whole minutes, hours or days, such as 45m, 2h or 3d
+func shortDuration(d time.Duration) string {
+	switch {
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", max(int(d.Minutes()), 1))
+	case d < 48*time.Hour:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%dd", int(d.Hours()/24))
+	}
+}
+
+// providerDisplayName returns how a provider is named in messages
+func providerDisplayName(provider string) string {
+	switch provider {
+	case "aws", "gcp":
+		return strings.ToUpper(provider)
+	case "azure":
+		return "Azure"
+	default:
+		return provider
+	}
+}
+
+// refreshedAWSCredentialSources are the AWS credential providers the SDK refreshes before
+// their credentials expire, so their expiry is not worth a warning
+var refreshedAWSCredentialSources = map[string]bool{
+	ec2rolecreds.ProviderName:        true,
+	endpointcreds.ProviderName:       true,
+	stscreds.ProviderName:            true,
+	stscreds.WebIdentityProviderName: true,
+	ssocreds.ProviderName:            true,
+}
+
+// checkAWSStatus resolves the default AWS credentials and calls STS GetCallerIdentity
+func checkAWSStatus(ctx context.Context, status *ProviderStatus) {
+	if sso, err := awsprovider.LoadSSOProfile(ctx, awsprovider.ActiveProfile()); err == nil {
+		status.SSO = sso
+		// A sign-in the SDK cannot refresh is what expires, rather than the role credentials
+		// it is exchanged for
+		if !sso.Refreshable && sso.TokenExpiresAt != nil {
+			status.ExpiresAt = sso.TokenExpiresAt
+			status.credential = "SSO sign-in"
+		}
+	}
+
+	cfg, err := awsconfig.LoadDefaultConfig(ctx)
+	if err != nil {
+		status.Error = fmt.Sprintf("failed to load AWS config: %v", err)
+		return
+	}
+	if cfg.Region == "" {
+		cfg.Region = "us-east-1"
+	}
+	creds, err := cfg.Credentials.Retrieve(ctx)
+	if err != nil && status.SSO != nil && status.SSO.LoginRequired {
+		status.Error = fmt.Sprintf("AWS SSO profile %s needs to sign in again through POST /api/v1/providers/aws/sso/login: %v", status.SSO.Name, err)
+		if status.SSO.TokenExpiresAt != nil {
+			status.Status = providerExpired
+		}
+		return
+	}
+	if err != nil {
+		// Cached SSO or assumed-role credentials can be found but have expired
+		if strings.Contains(strings.ToLower(err.Error()), "expired") {
+			status.refused(err)
+			return
+		}
+		status.Error = fmt.Sprintf("no AWS credentials found: %v", err)
+		return
+	}
+
+	identity, err := sts.NewFromConfig(cfg).GetCallerIdentity(ctx, &sts.GetCallerIdentityInput{})
+	if err != nil {
+		status.refused(err)
+		return
+	}
+	status.Status = providerValid
+	status.Account = aws.ToString(identity.Account)
+	status.Identity = aws.ToString(identity.Arn)
+
+	if status.SSO != nil && creds.Source != ssocreds.ProviderName {
+		// Credentials in the environment take precedence over the profile
+		status.SSO, status.ExpiresAt, status.credential = nil, nil, ""
+	}
+	switch {
+	case status.SSO != nil:
+		// The sign-in's expiry was recorded with the profile
+	case creds.CanExpire && !refreshedAWSCredentialSources[creds.Source]:
+		status.ExpiresAt = &creds.Expires
+	case creds.SessionToken != "":
+		// Session tokens exported into the environment, such as by aws-vault, carry their
+		// expiry alongside
+		if expires, err := time.Parse(time.RFC3339, os.Getenv("AWS_CREDENTIAL_EXPIRATION")); err == nil {
+			status.ExpiresAt = &expires
+		}
+	}
+}
+
+// checkAzureStatus fetches an Azure Resource Manager token with the default Azure
+// credentials. The subscription comes from AZURE_SUBSCRIPTION_ID, as for discovery.
+func checkAzureStatus(ctx context.Context, status *ProviderStatus) {
+	cred, err := azidentity.NewDefaultAzureCredential(nil)
+	if err != nil {
+		status.Error = fmt.Sprintf("no Azure credentials found: %v", err)
+		return
+	}
+	token, err := cred.GetToken(ctx, policy.TokenRequestOptions{Scopes: []string{"https://management.azure.com/.default"}})
+	if err != nil {
+		var failed *azidentity.AuthenticationFailedError
+		if errors.As(err, &failed) {
+			status.refused(err)
+			return
+		}
+		status.Error = fmt.Sprintf("no Azure credentials found: %v", err)
+		return
+	}
+
+	status.Status = providerValid
+	status.Subscription = os.Getenv("AZURE_SUBSCRIPTION_ID")
+	claims := jwtClaims(token.Token)
+	status.Tenant = claims["tid"]
+	for _, claim := range []string{"upn", "unique_name", "appid", "oid"} {
+		if claims[claim] != "" {
+			status.Identity = claims[claim]
+			break
+		}
+	}
+
+	clientID, secret := os.Getenv("AZURE_CLIENT_ID"), os.Getenv("AZURE_CLIENT_SECRET")
+	if clientID != "" && secret != "" {
+		if expires, ok := azureSecretExpiry(ctx, cred, clientID, secret); ok {
+			status.ExpiresAt = &expires
+			status.credential = "client secret"
+		}
+	}
+}
+
+// azureSecretExpiry looks up when a service principal's client secret expires in Microsoft
+// Graph, matching the secret by the first characters Graph keeps as its hint. It reports
+// false when Graph cannot be read, which needs the Application.Read.All permission.
+func azureSecretExpiry(ctx context.Context, cred *azidentity.DefaultAzureCredential, clientID, secret string) (time.Time, bool) {
+	token, err := cred.GetToken(ctx, policy.TokenRequestOptions{Scopes: []string{"https://graph.microsoft.com/.default"}})
+	if err != nil {
+		return time.Time{}, false
+	}
+	var application struct {
+		PasswordCredentials []struct {
+			Hint        string    `json:"hint"`
+			EndDateTime time.Time `json:"endDateTime"`
+		} `json:"passwordCredentials"`
+	}
+	endpoint := fmt.Sprintf("https://graph.microsoft.com/v1.0/applications(appId='%s')?$select=passwordCredentials", url.PathEscape(clientID))
+	if err := getJSON(ctx, http.DefaultClient, endpoint, "Bearer "+token.Token, &application); err != nil {
+		return time.Time{}, false
+	}
+	for _, credential := range application.PasswordCredentials {
+		if credential.Hint != "" && strings.HasPrefix(secret, credential.Hint) {
+			return credential.EndDateTime, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// checkGCPStatus fetches an access token with the GCP application default credentials
+func checkGCPStatus(ctx context.Context, status *ProviderStatus) {
+	creds, err := google.FindDefaultCredentials(ctx, "https://www.googleapis.com/auth/cloud-platform")
+	if err != nil {
+		status.Error = fmt.Sprintf("no GCP credentials found: %v", err)
+		return
+	}
+	if _, err := creds.TokenSource.Token(); err != nil {
+		status.refused(err)
+		return
+	}
+
+	status.Status = providerValid
+	status.Project = creds.ProjectID
+	if status.Project == "" {
+		status.Project = os.Getenv("GOOGLE_CLOUD_PROJECT")
+	}
+	var key struct {
+		Type         string `json:"type"`
+		ClientEmail  string `json:"client_email"`
+		PrivateKeyID string `json:"private_key_id"`
+	}
+	if json.Unmarshal(creds.JSON, &key) != nil {
+		return
+	}
+	status.Identity = key.ClientEmail
+	if key.Type == "service_account" && key.PrivateKeyID != "" {
+		gcpKeyValidity(ctx, creds.TokenSource, key.ClientEmail, key.PrivateKeyID, status)
+	}
+}
+
+// gcpKeyValidity records when a service account key was created and when it expires, from
+// the IAM API. It records nothing when the key cannot be read, which needs the
+// iam.serviceAccountKeys.get permission.
+func gcpKeyValidity(ctx context.Context, tokens oauth2.TokenSource, email, keyID string, status *ProviderStatus) {
+	var key struct {
+		ValidAfterTime  time.Time `json:"validAfterTime"`
+		ValidBeforeTime time.Time `json:"validBeforeTime"`
+	}
+	endpoint := fmt.Sprintf("https://iam.googleapis.com/v1/projects/-/serviceAccounts/%s/keys/%s", url.PathEscape(email), url.PathEscape(keyID))
+	if err := getJSON(ctx, oauth2.NewClient(ctx, tokens), endpoint, "", &key); err != nil {
+		return
+	}
+	if !key.ValidAfterTime.IsZero() {
+		status.KeyCreatedAt = &key.ValidAfterTime
+	}
+	// Keys without an expiry are valid until the end of year 9999
+	if !key.ValidBeforeTime.IsZero() && key.ValidBeforeTime.Year() < 9999 {
+		status.ExpiresAt = &key.ValidBeforeTime
+		status.credential = "service account key"
+	}
+}
+
+// getJSON decodes the JSON response of a GET request, sending authorization when set
+func getJSON(ctx context.Context, client *http.Client, endpoint, authorization string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	if authorization != "" {
+		req.Header.Set("Authorization", authorization)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s returned %s", endpoint, resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// jwtClaims returns the string claims of a JWT without verifying it, which is only safe for
+// a token the server was just issued
+func jwtClaims(token string) map[string]string {
+	claims := map[string]string{}
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return claims
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return claims
+	}
+	var raw map[string]interface{}
+	if json.Unmarshal(payload, &raw) != nil {
+		return claims
+	}
+	for name, value := range raw {
+		if text, ok := value.(string); ok {
+			claims[name] = text
+		}
+	}
+	return claims
+}
@@ -0,0 +1,105 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleProviderStatus(t *testing.T) {
+	var checks atomic.Int32
+	checkers := providerStatusCheckers
+	providerStatusCheckers = map[string]func(ctx context.Context, status *ProviderStatus){
+		"aws": func(ctx context.Context, status *ProviderStatus) {
+			checks.Add(1)
+			status.Status = providerValid
+			status.Account = "123456789012"
+		},
+		"azure": func(ctx context.Context, status *ProviderStatus) {
+			checks.Add(1)
+			status.refused(errors.New("AADSTS7000222: the provided client secret keys are expired"))
+		},
+		"gcp": func(ctx context.Context, status *ProviderStatus) {
+			checks.Add(1)
+			status.Error = "no GCP credentials found"
+		},
+	}
+	providerStatuses = &providerStatusCache{}
+	t.Cleanup(func() {
+		providerStatusCheckers = checkers
+		providerStatuses = &providerStatusCache{}
+	})
+
+	server := &Server{}
+	get := func(path string) ProviderStatusResponse {
+		w := httptest.NewRecorder()
+		server.handleProviderStatus(w, httptest.NewRequest(http.MethodGet, path, nil))
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var response ProviderStatusResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		return response
+	}
+
+	response := get("/api/v1/providers/status")
+	assert.False(t, response.Cached)
+	require.Len(t, response.Providers, 3)
+	aws, azure, gcp := response.Providers[0], response.Providers[1], response.Providers[2]
+	assert.Equal(t, "aws", aws.Provider)
+	assert.True(t, aws.Configured && aws.Valid)
+	assert.Equal(t, "123456789012", aws.Account)
+	assert.Equal(t, providerExpired, azure.Status)
+	assert.Equal(t, []string{"Azure credentials have expired"}, azure.Warnings)
+	assert.True(t, azure.Configured)
+	assert.False(t, azure.Valid)
+	assert.Equal(t, providerNotConfigured, gcp.Status)
+	assert.False(t, gcp.Configured)
+
+	assert.True(t, get("/api/v1/providers/status").Cached, "a second poll reuses the checks")
+	assert.Equal(t, int32(3), checks.Load())
+	assert.False(t, get("/api/v1/providers/status?refresh=true").Cached)
+	assert.Equal(t, int32(6), checks.Load())
+}
+
+func TestProviderStatusWarnings(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	at := func(d time.Duration) *time.Time {
+		t := now.Add(d)
+		return &t
+	}
+	tests := []struct {
+		name   string
+		status ProviderStatus
+		want   []string
+	}{
+		{"session token nearing expiry", ProviderStatus{Provider: "aws", Status: providerValid, ExpiresAt: at(2*time.Hour + 10*time.Minute)}, []string{"AWS credentials expire in 2h"}},
+		{"expiry outside the window", ProviderStatus{Provider: "aws", Status: providerValid, ExpiresAt: at(30 * time.Hour)}, nil},
+		{"client secret", ProviderStatus{Provider: "azure", Status: providerValid, ExpiresAt: at(20 * time.Minute), credential: "client secret"}, []string{"Azure client secret expires in 20m"}},
+		{"already expired", ProviderStatus{Provider: "azure", Status: providerValid, ExpiresAt: at(-72 * time.Hour), credential: "client secret"}, []string{"Azure client secret expired 3d ago"}},
+		{"old service account key", ProviderStatus{Provider: "gcp", Status: providerValid, KeyCreatedAt: at(-120 * 24 * time.Hour)}, []string{"GCP service account key is 120d old; rotate it"}},
+		{"recent service account key", ProviderStatus{Provider: "gcp", Status: providerValid, KeyCreatedAt: at(-10 * 24 * time.Hour)}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.status.addWarnings(now, 24*time.Hour)
+			assert.Equal(t, tt.want, tt.status.Warnings)
+			assert.Len(t, tt.status.warningKeys, len(tt.want))
+		})
+	}
+}
+
+func TestJWTClaims(t *testing.T) {
+	token := "eyJhbGciOiJub25lIn0.eyJ0aWQiOiJ0ZW5hbnQiLCJvaWQiOiJvYmplY3QiLCJleHAiOjF9.sig"
+	claims := jwtClaims(token)
+	assert.Equal(t, "tenant", claims["tid"])
+	assert.Equal(t, "object", claims["oid"])
+	assert.NotContains(t, claims, "exp", "only string claims are returned")
+	assert.Empty(t, jwtClaims("not a token"))
+}
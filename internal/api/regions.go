@@ -0,0 +1,115 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	awsprovider "github.com/catherinevee/driftmgr/internal/providers/aws"
+	azureprovider "github.com/catherinevee/driftmgr/internal/providers/azure"
+	gcpprovider "github.com/catherinevee/driftmgr/internal/providers/gcp"
+	kubernetesprovider "github.com/catherinevee/driftmgr/internal/providers/kubernetes"
+)
+
+// allRegions is the regions value that discovers every region of the provider
+const allRegions = "all"
+
+// regionCacheTTL is how long a provider's region list is reused before it is listed again,
+// so regions that are added or opted in to are picked up within a day
+const regionCacheTTL = 24 * time.Hour
+
+// providerRegionListers list each provider's regions, split into those its credentials can
+// discover and those not enabled for the account, such as AWS opt-in regions
+var providerRegionListers = map[string]func(ctx context.Context) (enabled, disabled []string, err error){
+	"aws": func(ctx context.Context) ([]string, []string, error) {
+		return awsprovider.NewAWSProvider("us-east-1").RegionOptIns(ctx)
+	},
+	"azure": func(ctx context.Context) ([]string, []string, error) {
+		regions, err := azureprovider.NewAzureProviderComplete(os.Getenv("AZURE_SUBSCRIPTION_ID"), "").ListRegions(ctx)
+		return regions, nil, err
+	},
+	"gcp": func(ctx context.Context) ([]string, []string, error) {
+		regions, err := gcpprovider.NewGCPProviderComplete("").ListRegions(ctx)
+		return regions, nil, err
+	},
+	// Kubernetes discovers kubeconfig contexts in place of regions
+	"kubernetes": func(ctx context.Context) ([]string, []string, error) {
+		contexts, err := kubernetesprovider.NewKubernetesProvider("").ListRegions(ctx)
+		return contexts, nil, err
+	},
+}
+
+// providerRegions caches each provider's region list
+var providerRegions = &regionCache{entries: make(map[string]regionCacheEntry)}
+
+// regionCache holds provider region lists until regionCacheTTL passes
+type regionCache struct {
+	mu      sync.Mutex
+	entries map[string]regionCacheEntry
+}
+
+// regionCacheEntry is a provider's region list and when it stops being reused
+type regionCacheEntry struct {
+	enabled  []string
+	disabled []string
+	expires  time.Time
+}
+
+// get returns the provider's regions, listing them when the cached list is missing or stale.
+// Failed listings are not cached.
+func (c *regionCache) get(ctx context.Context, provider string) (regionCacheEntry, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if entry, ok := c.entries[provider]; ok && time.Now().Before(entry.expires) {
+		return entry, nil
+	}
+
+	list, ok := providerRegionListers[provider]
+	if !ok {
+		return regionCacheEntry{}, fmt.Errorf("listing regions is not supported for provider %s", provider)
+	}
+	enabled, disabled, err := list(ctx)
+	if err != nil {
+		return regionCacheEntry{}, fmt.Errorf("failed to list %s regions: %w", provider, err)
+	}
+	entry := regionCacheEntry{enabled: enabled, disabled: disabled, expires: time.Now().Add(regionCacheTTL)}
+	c.entries[provider] = entry
+	return entry, nil
+}
+
+// expandRegions replaces a regions list of "all" with every region of the provider,
+// including those not enabled for the account so scans can report them as skipped, and
+// returns other lists unchanged
+func expandRegions(ctx context.Context, provider string, regions []string) ([]string, error) {
+	if len(regions) != 1 || !strings.EqualFold(strings.TrimSpace(regions[0]), allRegions) {
+		return regions, nil
+	}
+	entry, err := providerRegions.get(ctx, provider)
+	if err != nil {
+		return nil, err
+	}
+	all := append(append([]string{}, entry.enabled...), entry.disabled...)
+	sort.Strings(all)
+	return all, nil
+}
+
+// disabledRegions returns the provider's regions that are not enabled for the account, so
+// scans skip them rather than fail in each. When the regions cannot be listed every region
+// is scanned.
+func disabledRegions(ctx context.Context, provider string) map[string]bool {
+	entry, err := providerRegions.get(ctx, provider)
+	if err != nil {
+		log.Printf("WARNING: %v; scanning every requested region", err)
+		return nil
+	}
+	disabled := make(map[string]bool, len(entry.disabled))
+	for _, region := range entry.disabled {
+		disabled[region] = true
+	}
+	return disabled
+}
@@ -0,0 +1,49 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpandRegions(t *testing.T) {
+	listers, cached := providerRegionListers, providerRegions
+	calls := 0
+	fail := true
+	providerRegionListers = map[string]func(ctx context.Context) ([]string, []string, error){
+		"aws": func(ctx context.Context) ([]string, []string, error) {
+			calls++
+			if fail {
+				return nil, nil, errors.New("throttled")
+			}
+			return []string{"ap-southeast-5", "us-east-1"}, []string{"me-south-1"}, nil
+		},
+	}
+	providerRegions = &regionCache{entries: make(map[string]regionCacheEntry)}
+	t.Cleanup(func() { providerRegionListers, providerRegions = listers, cached })
+	ctx := context.Background()
+
+	regions, err := expandRegions(ctx, "aws", []string{"us-west-2", "all"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"us-west-2", "all"}, regions)
+	assert.Zero(t, calls)
+
+	// A failed listing is not cached, and scans go ahead in every region
+	_, err = expandRegions(ctx, "aws", []string{"all"})
+	assert.Error(t, err)
+	assert.Empty(t, disabledRegions(ctx, "aws"))
+	fail = false
+	for i := 0; i < 2; i++ {
+		regions, err = expandRegions(ctx, "aws", []string{"ALL"})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"ap-southeast-5", "me-south-1", "us-east-1"}, regions)
+	}
+	assert.Equal(t, map[string]bool{"me-south-1": true}, disabledRegions(ctx, "aws"))
+	assert.Equal(t, 3, calls)
+
+	_, err = expandRegions(ctx, "digitalocean", []string{"all"})
+	assert.Error(t, err)
+}
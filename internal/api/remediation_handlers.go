@@ -0,0 +1,882 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/catherinevee/driftmgr/internal/auth"
+	"github.com/catherinevee/driftmgr/internal/drift/detector"
+	"github.com/catherinevee/driftmgr/internal/metrics"
+	"github.com/catherinevee/driftmgr/internal/remediation"
+	"github.com/catherinevee/driftmgr/internal/shared/tools"
+	"github.com/catherinevee/driftmgr/internal/websocket"
+)
+
+// maxJobIDLength bounds client-supplied job IDs echoed into WebSocket messages
+const maxJobIDLength = 128
+
+// Remediation history page sizes
+const (
+	defaultHistoryLimit = 100
+	maxHistoryLimit     = 1000
+)
+
+// RemediationHandlers handles remediation and snapshot API endpoints
+type RemediationHandlers struct {
+	remediationService *remediation.IntelligentRemediationService
+	snapshots          *remediation.SnapshotStore
+	driftStore         *DriftStore
+	tools              *tools.ToolResolver
+	progress           *websocket.Service
+	history            *remediation.HistoryStore
+	approvals          *remediation.ApprovalStore
+	repositoryRoot     string
+	requireApproval    bool
+	notifyApproval     func(*remediation.Approval)
+}
+
+// NewRemediationHandlers creates a new RemediationHandlers instance
+func NewRemediationHandlers(remediationService *remediation.IntelligentRemediationService, snapshots *remediation.SnapshotStore, toolResolver *tools.ToolResolver) *RemediationHandlers {
+	return &RemediationHandlers{
+		remediationService: remediationService,
+		snapshots:          snapshots,
+		driftStore:         GetGlobalDriftStore(),
+		tools:              toolResolver,
+	}
+}
+
+// SetProgressBroadcaster sets the WebSocket service used to publish batch progress
+func (h *RemediationHandlers) SetProgressBroadcaster(progress *websocket.Service) {
+	h.progress = progress
+}
+
+// SetHistoryStore sets the store remediation outcomes are recorded in
+func (h *RemediationHandlers) SetHistoryStore(history *remediation.HistoryStore) {
+	h.history = history
+}
+
+// SetRepositoryRoot sets the directory requested working directories are confined to. Without
+// it, remediation never runs terraform in a working directory.
+func (h *RemediationHandlers) SetRepositoryRoot(root string) {
+	h.repositoryRoot = root
+}
+
+// Remediate handles POST /api/v1/remediate
+func (h *RemediationHandlers) Remediate(w http.ResponseWriter, r *http.Request) {
+	// Set common headers
+	SetCommonHeaders(w)
+
+	var remediationRequest RemediationRequest
+	if err := decodeRequest(r, &remediationRequest); err != nil {
+		response := NewResponseWriter(w)
+		response.WriteRequestError(err)
+		return
+	}
+
+	workDir, ok := h.terraformWorkDir(w, remediationRequest.WorkDir, remediationRequest.DryRun)
+	if !ok {
+		return
+	}
+
+	drift, exists := h.driftStore.Get(remediationRequest.DriftID)
+	if !exists {
+		response := NewResponseWriter(w)
+		response.WriteNotFound("Drift result " + remediationRequest.DriftID)
+		return
+	}
+	if !checkDriftScope(w, r, drift) {
+		return
+	}
+
+	if !remediationRequest.DryRun && h.approvalRequired(remediationRequest.RequireApproval) {
+		h.requestApproval(w, r, []string{remediationRequest.DriftID}, remediationRequest.WorkDir, remediationRequest.Force)
+		return
+	}
+
+	// Capture the resource's current state so the change can be rolled back
+	var snapshotID string
+	if !remediationRequest.DryRun {
+		snapshot, err := h.createPreRemediationSnapshot(r.Context(), "remediation", []string{remediationRequest.DriftID},
+			map[string]*detector.DriftResult{remediationRequest.DriftID: drift})
+		if err != nil {
+			response := NewResponseWriter(w)
+			response.WriteInternalError("Failed to create pre-remediation snapshot: " + err.Error())
+			return
+		}
+		snapshotID = snapshot.ID
+	}
+
+	result := h.remediateDrift(r.Context(), remediationRequest.DriftID, drift, remediationRequest.DryRun, snapshotID, workDir, remediationRequest.Force, nil)
+	metrics.RecordRemediation(result.Status)
+
+	response := NewResponseWriter(w)
+	response.WriteSuccess(result, nil)
+}
+
+// RemediateBatch handles POST /api/v1/remediate/batch
+func (h *RemediationHandlers) RemediateBatch(w http.ResponseWriter, r *http.Request) {
+	// Set common headers
+	SetCommonHeaders(w)
+
+	var batchRequest BatchRemediationRequest
+	if err := decodeRequest(r, &batchRequest); err != nil {
+		response := NewResponseWriter(w)
+		response.WriteRequestError(err)
+		return
+	}
+
+	workDir, ok := h.terraformWorkDir(w, batchRequest.WorkDir, batchRequest.DryRun)
+	if !ok {
+		return
+	}
+
+	selected, drifts, ok := h.resolveDrifts(w, batchRequest.DriftIDs, batchRequest.SeverityFilter)
+	if !ok {
+		return
+	}
+	for _, drift := range drifts {
+		if !checkDriftScope(w, r, drift) {
+			return
+		}
+	}
+
+	// A real batch runs only the plan its caller previewed and reviewed
+	if !batchRequest.DryRun && len(selected) > 0 && !h.checkBatchPreview(r.Context(), w, batchRequest.PreviewToken, selected, drifts) {
+		return
+	}
+
+	if !batchRequest.DryRun && len(selected) > 0 && h.approvalRequired(batchRequest.RequireApproval) {
+		h.requestApproval(w, r, selected, batchRequest.WorkDir, batchRequest.Force)
+		return
+	}
+
+	batchResponse := BatchRemediationResponse{
+		JobID:       jobIDOrNew(batchRequest.JobID),
+		TotalDrifts: len(selected),
+		DryRun:      batchRequest.DryRun,
+		Results:     make([]RemediationResult, 0, len(selected)),
+		Timestamp:   time.Now().UTC(),
+	}
+
+	if err := h.runBatch(r.Context(), &batchResponse, selected, drifts, workDir, batchRequest.Force, nil); err != nil {
+		response := NewResponseWriter(w)
+		response.WriteInternalError(err.Error())
+		return
+	}
+
+	response := NewResponseWriter(w)
+	response.WriteSuccess(batchResponse, &APIMeta{
+		Count:     len(batchResponse.Results),
+		Timestamp: time.Now().UTC().Format(time.RFC3339),
+	})
+}
+
+// runBatch snapshots the selected drifts' resources and remediates them in order, publishing
+// progress for the batch's job. Drifts missing from drifts are reported as failed.
+func (h *RemediationHandlers) runBatch(ctx context.Context, batchResponse *BatchRemediationResponse, selected []string, drifts map[string]*detector.DriftResult, workDir string, force bool, approval *remediation.Approval) error {
+	// Capture the current state of every affected resource before changing anything
+	if !batchResponse.DryRun && len(drifts) > 0 {
+		var available []string
+		for _, id := range selected {
+			if drifts[id] != nil {
+				available = append(available, id)
+			}
+		}
+		snapshot, err := h.createPreRemediationSnapshot(ctx, "batch_remediation", available, drifts)
+		if err != nil {
+			return fmt.Errorf("failed to create pre-remediation snapshot: %w", err)
+		}
+		batchResponse.SnapshotID = snapshot.ID
+	}
+
+	for i, id := range selected {
+		var result RemediationResult
+		if drift := drifts[id]; drift != nil {
+			result = h.remediateDrift(ctx, id, drift, batchResponse.DryRun, batchResponse.SnapshotID, workDir, force, approval)
+		} else {
+			result = RemediationResult{DriftID: id, Status: "failed", ErrorMessage: "drift result not found", Timestamp: time.Now().UTC()}
+		}
+		metrics.RecordRemediation(result.Status)
+		switch result.Status {
+		case "success":
+			batchResponse.Remediated++
+		case "failed":
+			batchResponse.Failed++
+		}
+		batchResponse.Results = append(batchResponse.Results, result)
+
+		if h.progress != nil {
+			update := websocket.NewProgressUpdate(batchResponse.JobID, "remediating", i+1, len(selected))
+			update.Message = fmt.Sprintf("Remediated %s: %s", id, result.Status)
+			update.Error = result.ErrorMessage
+			update.User, _ = auth.GetUsernameFromContext(ctx)
+			h.progress.BroadcastProgress(websocket.MessageTypeRemediationProgress, update)
+		}
+	}
+	return nil
+}
+
+// PlanRemediation handles GET and POST /api/v1/remediate/plan
+func (h *RemediationHandlers) PlanRemediation(w http.ResponseWriter, r *http.Request) {
+	// Set common headers
+	SetCommonHeaders(w)
+
+	var planRequest RemediationPlanRequest
+	if r.Method == http.MethodPost {
+		if err := decodeRequest(r, &planRequest); err != nil {
+			response := NewResponseWriter(w)
+			response.WriteRequestError(err)
+			return
+		}
+	} else {
+		params := ParseQueryParams(r)
+		if ids := params["drift_ids"]; ids != "" {
+			planRequest.DriftIDs = strings.Split(ids, ",")
+		}
+		planRequest.SeverityFilter = params["severity"]
+	}
+
+	selected, drifts, ok := h.resolveDrifts(w, planRequest.DriftIDs, planRequest.SeverityFilter)
+	if !ok {
+		return
+	}
+	for _, drift := range drifts {
+		if !checkDriftScope(w, r, drift) {
+			return
+		}
+	}
+
+	plan, ok := h.batchPlan(r.Context(), w, selected, drifts)
+	if !ok {
+		return
+	}
+
+	planResponse := RemediationPlanResponse{
+		PlanID:            plan.ID,
+		RiskLevel:         plan.RiskLevel.String(),
+		RequiresApproval:  plan.RequiresApproval,
+		EstimatedDuration: plan.EstimatedDuration.String(),
+		ActionCount:       len(plan.Actions),
+		ExecutionOrder:    plan.ExecutionOrder,
+		Actions:           make([]PlannedAction, 0, len(plan.Actions)),
+		Strategies:        make(map[string][]string, len(selected)),
+		CreatedAt:         plan.CreatedAt,
+	}
+
+	// Order actions the way they would be executed
+	actionsByID := make(map[string]remediation.RemediationAction, len(plan.Actions))
+	for _, action := range plan.Actions {
+		actionsByID[action.ID] = action
+	}
+	for _, id := range plan.ExecutionOrder {
+		action, exists := actionsByID[id]
+		if !exists {
+			continue
+		}
+		planResponse.Actions = append(planResponse.Actions, plannedAction(action))
+	}
+
+	// Report the strategy chosen for each drift result
+	for _, id := range selected {
+		strategies := make([]string, 0)
+		for _, action := range plan.Actions {
+			if action.Resource == drifts[id].Resource {
+				strategies = append(strategies, string(action.Type))
+			}
+		}
+		planResponse.Strategies[id] = strategies
+	}
+
+	response := NewResponseWriter(w)
+	response.WriteSuccess(planResponse, nil)
+}
+
+// RollbackRemediation handles POST /api/v1/remediate/rollback
+func (h *RemediationHandlers) RollbackRemediation(w http.ResponseWriter, r *http.Request) {
+	// Set common headers
+	SetCommonHeaders(w)
+
+	var rollbackRequest RollbackRequest
+	if err := decodeRequest(r, &rollbackRequest); err != nil {
+		response := NewResponseWriter(w)
+		response.WriteRequestError(err)
+		return
+	}
+
+	if rollbackRequest.SnapshotID == "" {
+		response := NewResponseWriter(w)
+		response.WriteValidationError("Snapshot ID is required", "snapshot_id cannot be empty")
+		return
+	}
+
+	snapshot, ok := h.loadSnapshot(w, r, rollbackRequest.SnapshotID)
+	if !ok {
+		return
+	}
+	if !checkSnapshotScope(w, r, snapshot) {
+		return
+	}
+
+	if h.approvalRequired(rollbackRequest.RequireApproval) {
+		h.holdForApproval(w, r, func(ctx context.Context, user string) (*remediation.Approval, error) {
+			return h.approvals.CreateRollback(ctx, snapshot.ID, user)
+		})
+		return
+	}
+
+	result, err := h.rollback(r.Context(), snapshot)
+	if err != nil {
+		response := NewResponseWriter(w)
+		response.WriteInternalError("Failed to execute rollback: " + err.Error())
+		return
+	}
+
+	response := NewResponseWriter(w)
+	response.WriteSuccess(result, nil)
+}
+
+// loadSnapshot looks up a snapshot, writing the error response and returning false when it
+// cannot be loaded
+func (h *RemediationHandlers) loadSnapshot(w http.ResponseWriter, r *http.Request, id string) (*remediation.Snapshot, bool) {
+	response := NewResponseWriter(w)
+	if h.snapshots == nil {
+		response.WriteError(http.StatusServiceUnavailable, "SERVICE_UNAVAILABLE", "Remediation snapshots are not available", "")
+		return nil, false
+	}
+	snapshot, err := h.snapshots.GetSnapshot(r.Context(), id)
+	if errors.Is(err, remediation.ErrSnapshotNotFound) {
+		response.WriteNotFound("Snapshot " + id)
+		return nil, false
+	}
+	if err != nil {
+		response.WriteInternalError("Failed to load snapshot: " + err.Error())
+		return nil, false
+	}
+	return snapshot, true
+}
+
+// rollback restores each resource captured in a snapshot to its recorded state
+func (h *RemediationHandlers) rollback(ctx context.Context, snapshot *remediation.Snapshot) (*RollbackResult, error) {
+	plan := &remediation.RemediationPlan{
+		ID:          fmt.Sprintf("rollback-%s", snapshot.ID),
+		Name:        "Snapshot Rollback",
+		Description: fmt.Sprintf("Restore resources captured in snapshot %s", snapshot.ID),
+		CreatedAt:   time.Now(),
+		Actions:     make([]remediation.RemediationAction, 0, len(snapshot.Resources)),
+		RiskLevel:   remediation.RiskLevelMedium,
+	}
+	for i, resource := range snapshot.Resources {
+		plan.Actions = append(plan.Actions, remediation.RemediationAction{
+			ID:           fmt.Sprintf("rollback-%s-%d", resource.Resource, i),
+			Type:         remediation.ActionTypeUpdate,
+			Resource:     resource.Resource,
+			ResourceType: resource.ResourceType,
+			Provider:     resource.Provider,
+			Description:  fmt.Sprintf("Restore %s to snapshot %s", resource.Resource, snapshot.ID),
+			Parameters: map[string]interface{}{
+				"desired_state": resource.State,
+				"snapshot_id":   snapshot.ID,
+			},
+		})
+	}
+
+	actionResults, err := h.remediationService.ExecutePlan(ctx, plan)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &RollbackResult{
+		SnapshotID: snapshot.ID,
+		Timestamp:  time.Now().UTC(),
+	}
+	var errs []string
+	for _, actionResult := range actionResults {
+		if actionResult.Status == remediation.StatusSuccess {
+			result.Restored++
+			continue
+		}
+		result.Failed++
+		errs = append(errs, fmt.Sprintf("%s: %s", actionResult.ResourceID, actionResult.Error))
+	}
+
+	result.RolledBack = result.Failed == 0
+	result.Status = "success"
+	if !result.RolledBack {
+		result.Status = "failed"
+		result.ErrorMessage = strings.Join(errs, "; ")
+	}
+	return result, nil
+}
+
+// ListSnapshots handles GET /api/v1/snapshots
+func (h *RemediationHandlers) ListSnapshots(w http.ResponseWriter, r *http.Request) {
+	// Set common headers
+	SetCommonHeaders(w)
+
+	if h.snapshots == nil {
+		response := NewResponseWriter(w)
+		response.WriteError(http.StatusServiceUnavailable, "SERVICE_UNAVAILABLE", "Remediation snapshots are not available", "")
+		return
+	}
+	snapshots, err := h.snapshots.ListSnapshots(r.Context())
+	if err != nil {
+		response := NewResponseWriter(w)
+		response.WriteInternalError("Failed to list snapshots: " + err.Error())
+		return
+	}
+	if scopes, _ := auth.GetScopesFromContext(r.Context()); len(scopes) > 0 {
+		// Scoped users only see snapshots of resources they could roll back themselves
+		if snapshots, err = h.scopedSnapshots(r.Context(), snapshots); err != nil {
+			response := NewResponseWriter(w)
+			response.WriteInternalError("Failed to list snapshots: " + err.Error())
+			return
+		}
+	}
+
+	response := NewResponseWriter(w)
+	response.WriteSuccess(snapshots, &APIMeta{
+		Count:     len(snapshots),
+		Timestamp: time.Now().UTC().Format(time.RFC3339),
+	})
+}
+
+// scopedSnapshots keeps the snapshots whose every captured resource is within the user's scopes
+func (h *RemediationHandlers) scopedSnapshots(ctx context.Context, summaries []remediation.SnapshotSummary) ([]remediation.SnapshotSummary, error) {
+	allowed := summaries[:0]
+	for _, summary := range summaries {
+		snapshot, err := h.snapshots.GetSnapshot(ctx, summary.ID)
+		if errors.Is(err, remediation.ErrSnapshotNotFound) {
+			continue // deleted since it was listed
+		}
+		if err != nil {
+			return nil, err
+		}
+		if snapshotInScope(ctx, snapshot) {
+			allowed = append(allowed, summary)
+		}
+	}
+	return allowed, nil
+}
+
+// remediateDrift plans and, unless dry-running, executes remediation for one drift result.
+// snapshotID identifies the snapshot taken beforehand and is recorded in the result details.
+// Terraform commands are only run when workDir is set; otherwise they are returned for review.
+// Replacements and deletions refuse to destroy stateful resources such as databases unless
+// force is set.
+func (h *RemediationHandlers) remediateDrift(ctx context.Context, driftID string, drift *detector.DriftResult, dryRun bool, snapshotID, workDir string, force bool, approval *remediation.Approval) (result RemediationResult) {
+	result = RemediationResult{
+		DriftID:   driftID,
+		Details:   make(map[string]interface{}),
+		Timestamp: time.Now().UTC(),
+	}
+	if snapshotID != "" {
+		result.Details["snapshot_id"] = snapshotID
+	}
+
+	var strategies []string
+	if !dryRun {
+		defer func() { h.recordHistory(ctx, drift, strings.Join(strategies, ","), result, approval) }()
+	}
+
+	plan, err := h.remediationService.GeneratePlan(ctx, drift)
+	if err != nil {
+		result.Status = "failed"
+		result.ErrorMessage = err.Error()
+		return result
+	}
+	result.PlanID = plan.ID
+	result.ActionCount = len(plan.Actions)
+
+	for i := range plan.Actions {
+		action := &plan.Actions[i]
+		if !slices.Contains(strategies, string(action.Type)) {
+			strategies = append(strategies, string(action.Type))
+		}
+		if action.Command != "" {
+			result.Commands = append(result.Commands, action.Command)
+		}
+		if action.Parameters == nil {
+			action.Parameters = make(map[string]interface{})
+		}
+		if workDir != "" {
+			action.Parameters["work_dir"] = workDir
+		}
+		if force {
+			action.Parameters["force"] = true
+		}
+	}
+	if len(result.Commands) > 0 && workDir == "" {
+		// Commands were generated but not run; a human needs to review and apply them
+		result.Details["review_required"] = true
+	}
+
+	actionResults, err := h.remediationService.ExecutePlanWithOptions(ctx, plan, remediation.ExecuteOptions{DryRun: dryRun})
+	if err != nil {
+		result.Status = "failed"
+		result.ErrorMessage = err.Error()
+		return result
+	}
+
+	if dryRun {
+		result.Status = "dry_run"
+		return result
+	}
+	result.Executed = true
+
+	result.Status = "success"
+	var changes []string
+	for _, actionResult := range actionResults {
+		changes = append(changes, actionResult.Changes...)
+	}
+	if len(changes) > 0 {
+		// What each resource went through, such as the dependents a replacement also replaced
+		result.Details["changes"] = changes
+	}
+	for _, actionResult := range actionResults {
+		if actionResult.Status != remediation.StatusSuccess {
+			result.Status = "failed"
+			result.ErrorMessage = actionResult.Error
+			break
+		}
+	}
+
+	return result
+}
+
+// recordHistory persists the outcome of remediating a drift, with who approved it when it
+// was held for approval. Failing to record is logged rather than failing the remediation.
+func (h *RemediationHandlers) recordHistory(ctx context.Context, drift *detector.DriftResult, strategy string, result RemediationResult, approval *remediation.Approval) {
+	if h.history == nil {
+		return
+	}
+
+	details := map[string]interface{}{
+		"plan_id":      result.PlanID,
+		"action_count": result.ActionCount,
+	}
+	for key, value := range result.Details {
+		details[key] = value
+	}
+	if len(result.Commands) > 0 {
+		details["commands"] = result.Commands
+	}
+	if result.ErrorMessage != "" {
+		details["error"] = result.ErrorMessage
+	}
+	if approval != nil {
+		details["approval_id"] = approval.ID
+		details["requested_by"] = approval.RequestedBy
+		details["approved_by"] = approval.DecidedBy
+		if approval.DecidedAt != nil {
+			details["approved_at"] = approval.DecidedAt.Format(time.RFC3339)
+		}
+	} else if username, ok := auth.GetUsernameFromContext(ctx); ok && username != "" {
+		details["requested_by"] = username
+	}
+
+	entry := &remediation.HistoryEntry{
+		DriftID:   result.DriftID,
+		Strategy:  strategy,
+		Status:    result.Status,
+		Timestamp: result.Timestamp,
+		Details:   details,
+	}
+	if drift != nil {
+		entry.Provider = drift.Provider
+		entry.Severity = int(drift.Severity)
+		details["resource"] = drift.Resource
+		details["resource_type"] = drift.ResourceType
+		if account := driftAccount(drift); account != "" {
+			details["account_id"] = account
+		}
+		for _, state := range []map[string]interface{}{drift.ActualState, drift.DesiredState} {
+			if region, ok := state["region"].(string); ok && region != "" {
+				entry.Region = region
+				break
+			}
+		}
+	}
+
+	if err := h.history.Record(ctx, entry); err != nil {
+		log.Printf("Failed to record remediation history for %s: %v", result.DriftID, err)
+	}
+}
+
+// RemediationHistory handles GET /api/v1/remediate/history. It filters by drift_id,
+// strategy, status, provider and region, and by start_date and end_date (RFC 3339 or
+// YYYY-MM-DD). Pages are selected with limit and offset and ordered by sort (timestamp,
+// status or severity) and order (asc or desc), newest first by default. The X-Total-Count
+// header carries the number of matching entries. Users limited to scopes only see the entries
+// of drift within them.
+func (h *RemediationHandlers) RemediationHistory(w http.ResponseWriter, r *http.Request) {
+	SetCommonHeaders(w)
+	response := NewResponseWriter(w)
+
+	if h.history == nil {
+		response.WriteError(http.StatusServiceUnavailable, "SERVICE_UNAVAILABLE", "Remediation history is not available", "")
+		return
+	}
+
+	queryParams := ParseQueryParams(r)
+	filter := remediation.HistoryFilter{
+		DriftID:  queryParams["drift_id"],
+		Strategy: queryParams["strategy"],
+		Status:   queryParams["status"],
+		Provider: queryParams["provider"],
+		Region:   queryParams["region"],
+	}
+	var err error
+	if filter.StartTime, err = parseHistoryDate(queryParams["start_date"], false); err != nil {
+		response.WriteValidationError("Invalid start_date", err.Error())
+		return
+	}
+	if filter.EndTime, err = parseHistoryDate(queryParams["end_date"], true); err != nil {
+		response.WriteValidationError("Invalid end_date", err.Error())
+		return
+	}
+	filter.Limit = defaultHistoryLimit
+	if limit := queryParams["limit"]; limit != "" {
+		if filter.Limit, err = strconv.Atoi(limit); err != nil || filter.Limit < 1 || filter.Limit > maxHistoryLimit {
+			response.WriteValidationError("Invalid limit", fmt.Sprintf("limit must be between 1 and %d", maxHistoryLimit))
+			return
+		}
+	}
+	if offset := queryParams["offset"]; offset != "" {
+		if filter.Offset, err = strconv.Atoi(offset); err != nil || filter.Offset < 0 {
+			response.WriteValidationError("Invalid offset", "offset must be a non-negative integer")
+			return
+		}
+	}
+	switch sort := queryParams["sort"]; sort {
+	case "", "timestamp", "status", "severity":
+		filter.SortBy = sort
+	default:
+		response.WriteValidationError("Invalid sort", "sort must be timestamp, status or severity")
+		return
+	}
+	switch order := strings.ToLower(queryParams["order"]); order {
+	case "", "desc":
+	case "asc":
+		filter.Ascending = true
+	default:
+		response.WriteValidationError("Invalid order", "order must be asc or desc")
+		return
+	}
+
+	var total int
+	var entries []remediation.HistoryEntry
+	if scopes, _ := auth.GetScopesFromContext(r.Context()); len(scopes) > 0 {
+		// Scoped users only see the remediation of drift they could remediate themselves
+		if total, entries, err = h.scopedHistory(r.Context(), filter); err != nil {
+			response.WriteInternalError("Failed to query remediation history: " + err.Error())
+			return
+		}
+	} else {
+		if total, err = h.history.Count(r.Context(), filter); err != nil {
+			response.WriteInternalError("Failed to count remediation history: " + err.Error())
+			return
+		}
+		if entries, err = h.history.Query(r.Context(), filter); err != nil {
+			response.WriteInternalError("Failed to query remediation history: " + err.Error())
+			return
+		}
+	}
+
+	w.Header().Set("X-Total-Count", strconv.Itoa(total))
+	response.WriteSuccess(RemediationHistoryResponse{
+		Entries: entries,
+		Total:   total,
+		Limit:   filter.Limit,
+		Offset:  filter.Offset,
+	}, &APIMeta{
+		Timestamp: time.Now().UTC().Format(time.RFC3339),
+	})
+}
+
+// scopedHistory queries every history entry matching filter, keeps those within the user's
+// scopes and returns how many there are and the page of them filter selects
+func (h *RemediationHandlers) scopedHistory(ctx context.Context, filter remediation.HistoryFilter) (int, []remediation.HistoryEntry, error) {
+	all := filter
+	all.Limit, all.Offset = 0, 0
+	entries, err := h.history.Query(ctx, all)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	allowed := entries[:0]
+	for _, entry := range entries {
+		if h.historyInScope(ctx, entry) {
+			allowed = append(allowed, entry)
+		}
+	}
+	start := min(filter.Offset, len(allowed))
+	end := len(allowed)
+	if filter.Limit > 0 {
+		end = min(start+filter.Limit, end)
+	}
+	return len(allowed), allowed[start:end], nil
+}
+
+// historyInScope reports whether the user's scopes cover the provider and account of a
+// remediated drift. Entries recorded without an account fall back to the stored drift result.
+func (h *RemediationHandlers) historyInScope(ctx context.Context, entry remediation.HistoryEntry) bool {
+	account, _ := entry.Details["account_id"].(string)
+	if account == "" {
+		if drift, exists := h.driftStore.Get(entry.DriftID); exists {
+			account = driftAccount(drift)
+		}
+	}
+	return auth.CheckScope(ctx, entry.Provider, account) == nil
+}
+
+// parseHistoryDate parses an RFC 3339 timestamp or a YYYY-MM-DD date. A date used as an
+// end bound covers the whole day.
+func parseHistoryDate(value string, endOfDay bool) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("expected RFC 3339 or YYYY-MM-DD, got %q", value)
+	}
+	if endOfDay {
+		t = t.Add(24*time.Hour - time.Nanosecond)
+	}
+	return t, nil
+}
+
+// jobIDOrNew returns the client-supplied job ID, or generates one when it is empty or unusable
+func jobIDOrNew(jobID string) string {
+	jobID = strings.TrimSpace(jobID)
+	if jobID == "" || len(jobID) > maxJobIDLength {
+		return websocket.NewJobID()
+	}
+	return jobID
+}
+
+// terraformWorkDir resolves a requested working directory under the repository root and,
+// unless dry-running, rejects it when terraform could not be resolved at startup, writing the
+// error response itself. An empty workDir stays empty, since commands are then only returned
+// for review.
+func (h *RemediationHandlers) terraformWorkDir(w http.ResponseWriter, workDir string, dryRun bool) (string, bool) {
+	if workDir == "" {
+		return "", true
+	}
+	if h.repositoryRoot == "" {
+		response := NewResponseWriter(w)
+		response.WriteError(http.StatusServiceUnavailable, "SERVICE_UNAVAILABLE", "Remediation in a working directory is not configured", "repository_root is not set")
+		return "", false
+	}
+	resolved, status, message := resolveUnderRoot(h.repositoryRoot, workDir, "repository root", "Working directory", true)
+	if status != 0 {
+		response := NewResponseWriter(w)
+		response.WriteError(status, "INVALID_WORK_DIR", "Invalid working directory", message)
+		return "", false
+	}
+	if !dryRun && !h.canRunTerraform(w, resolved) {
+		return "", false
+	}
+	return resolved, true
+}
+
+// canRunTerraform rejects requests that would run terraform in a working directory when the
+// terraform binary could not be resolved at startup, writing the error response itself
+func (h *RemediationHandlers) canRunTerraform(w http.ResponseWriter, workDir string) bool {
+	if workDir == "" || h.tools == nil || h.tools.Available("terraform") {
+		return true
+	}
+	_, err := h.tools.Path("terraform")
+	response := NewResponseWriter(w)
+	response.WriteValidationError("Terraform is not available; remediation execution is disabled", err.Error())
+	return false
+}
+
+// resolveDrifts looks up the requested drift results, defaulting to all stored results, and
+// drops those below the severity filter. It writes an error response and returns false on failure.
+func (h *RemediationHandlers) resolveDrifts(w http.ResponseWriter, driftIDs []string, severityFilter string) ([]string, map[string]*detector.DriftResult, bool) {
+	minSeverity := detector.SeverityLow
+	if severityFilter != "" {
+		severity, err := parseDriftSeverity(severityFilter)
+		if err != nil {
+			response := NewResponseWriter(w)
+			response.WriteValidationError("Invalid severity filter", err.Error())
+			return nil, nil, false
+		}
+		minSeverity = severity
+	}
+
+	if len(driftIDs) == 0 {
+		driftIDs = h.driftStore.IDs()
+	}
+
+	drifts := make(map[string]*detector.DriftResult, len(driftIDs))
+	selected := make([]string, 0, len(driftIDs))
+	for _, id := range driftIDs {
+		drift, exists := h.driftStore.Get(id)
+		if !exists {
+			response := NewResponseWriter(w)
+			response.WriteNotFound("Drift result " + id)
+			return nil, nil, false
+		}
+		if drift.Severity < minSeverity {
+			continue
+		}
+		drifts[id] = drift
+		selected = append(selected, id)
+	}
+
+	return selected, drifts, true
+}
+
+// createPreRemediationSnapshot snapshots the resources behind the given drift results. Without
+// a snapshot store nothing is remediated, since the change could not be rolled back.
+func (h *RemediationHandlers) createPreRemediationSnapshot(ctx context.Context, trigger string, driftIDs []string, drifts map[string]*detector.DriftResult) (*remediation.Snapshot, error) {
+	if h.snapshots == nil {
+		return nil, errors.New("snapshots are not available")
+	}
+	resources := make([]remediation.ResourceSnapshot, 0, len(driftIDs))
+	for _, id := range driftIDs {
+		resources = append(resources, resourceSnapshotFromDrift(drifts[id]))
+	}
+
+	return h.snapshots.CreateSnapshot(ctx, "Pre-remediation snapshot", resources, map[string]string{
+		"trigger":   trigger,
+		"drift_ids": strings.Join(driftIDs, ","),
+	})
+}
+
+// resourceSnapshotFromDrift captures the live state recorded on a drift result
+func resourceSnapshotFromDrift(drift *detector.DriftResult) remediation.ResourceSnapshot {
+	return remediation.ResourceSnapshot{
+		Resource:     drift.Resource,
+		ResourceType: drift.ResourceType,
+		Provider:     drift.Provider,
+		State:        drift.ActualState,
+	}
+}
+
+// parseDriftSeverity converts a severity name to a detector severity
+func parseDriftSeverity(severity string) (detector.DriftSeverity, error) {
+	switch strings.ToLower(severity) {
+	case "low":
+		return detector.SeverityLow, nil
+	case "medium":
+		return detector.SeverityMedium, nil
+	case "high":
+		return detector.SeverityHigh, nil
+	case "critical":
+		return detector.SeverityCritical, nil
+	default:
+		return detector.SeverityLow, fmt.Errorf("unknown severity: %s", severity)
+	}
+}
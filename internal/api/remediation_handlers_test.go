@@ -0,0 +1,214 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/catherinevee/driftmgr/internal/drift/detector"
+	"github.com/catherinevee/driftmgr/internal/remediation"
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubUpdateExecutor succeeds at every update action and records the resources it changed
+type stubUpdateExecutor struct {
+	mu        sync.Mutex
+	resources []string
+}
+
+func (e *stubUpdateExecutor) Execute(ctx context.Context, action *remediation.RemediationAction) (*remediation.ActionResult, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.resources = append(e.resources, action.Resource)
+	return &remediation.ActionResult{ActionID: action.ID, ResourceID: action.Resource, Status: remediation.StatusSuccess}, nil
+}
+
+func (e *stubUpdateExecutor) GetType() string { return string(remediation.ActionTypeUpdate) }
+
+func (e *stubUpdateExecutor) GetDescription() string { return "stub update executor" }
+
+func (e *stubUpdateExecutor) Validate(action *remediation.RemediationAction) error { return nil }
+
+func (e *stubUpdateExecutor) executed() []string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return append([]string(nil), e.resources...)
+}
+
+// newTestRemediationHandlers creates remediation handlers over a temporary SQLite database
+// and a drift store holding one configuration drift on an AWS instance in account 111111111111
+func newTestRemediationHandlers(t *testing.T) (*RemediationHandlers, *stubUpdateExecutor) {
+	t.Helper()
+	db, err := remediation.OpenSQLiteDB(filepath.Join(t.TempDir(), "driftmgr.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	snapshots, err := remediation.NewSnapshotStore(db)
+	require.NoError(t, err)
+	approvals, err := remediation.NewApprovalStore(db)
+	require.NoError(t, err)
+
+	executor := &stubUpdateExecutor{}
+	service := remediation.NewIntelligentRemediationService(nil)
+	service.RegisterExecutor(executor)
+
+	h := NewRemediationHandlers(service, snapshots, nil)
+	h.driftStore = &DriftStore{results: make(map[string]*detector.DriftResult)}
+	h.driftStore.Store("drift-1", &detector.DriftResult{
+		Resource:     "i-123",
+		ResourceType: "aws_instance",
+		Provider:     "aws",
+		DriftType:    detector.ConfigurationDrift,
+		Severity:     detector.SeverityHigh,
+		ActualState:  map[string]interface{}{"instance_type": "t3.large", "account_id": "111111111111"},
+		DesiredState: map[string]interface{}{"instance_type": "t3.micro"},
+	})
+	h.SetApprovalGate(approvals, false, nil)
+	return h, executor
+}
+
+// serveJSON sends a JSON request to handler and decodes the response's data into out
+func serveJSON(t *testing.T, handler http.HandlerFunc, r *http.Request, out interface{}) *httptest.ResponseRecorder {
+	t.Helper()
+	w := httptest.NewRecorder()
+	handler(w, r)
+	if out != nil && w.Code < 300 {
+		response := struct {
+			Data json.RawMessage `json:"data"`
+		}{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response), w.Body.String())
+		require.NoError(t, json.Unmarshal(response.Data, out), string(response.Data))
+	}
+	return w
+}
+
+// jsonRequest builds a POST request with body encoded as JSON
+func jsonRequest(t *testing.T, path string, body interface{}) *http.Request {
+	t.Helper()
+	data, err := json.Marshal(body)
+	require.NoError(t, err)
+	return httptest.NewRequest(http.MethodPost, path, bytes.NewReader(data))
+}
+
+func TestRemediateLinksPreRemediationSnapshot(t *testing.T) {
+	h, executor := newTestRemediationHandlers(t)
+
+	var result RemediationResult
+	w := serveJSON(t, h.Remediate, jsonRequest(t, "/api/v1/remediate", RemediationRequest{DriftID: "drift-1"}), &result)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, "success", result.Status, result.ErrorMessage)
+
+	snapshotID, _ := result.Details["snapshot_id"].(string)
+	require.NotEmpty(t, snapshotID, "result details: %v", result.Details)
+	snapshot, err := h.snapshots.GetSnapshot(context.Background(), snapshotID)
+	require.NoError(t, err)
+	require.Len(t, snapshot.Resources, 1)
+	assert.Equal(t, "i-123", snapshot.Resources[0].Resource)
+	assert.Equal(t, "t3.large", snapshot.Resources[0].State["instance_type"])
+	assert.Equal(t, []string{"i-123"}, executor.executed())
+}
+
+func TestRemediateDryRunTakesNoSnapshot(t *testing.T) {
+	h, executor := newTestRemediationHandlers(t)
+
+	var result RemediationResult
+	w := serveJSON(t, h.Remediate, jsonRequest(t, "/api/v1/remediate", RemediationRequest{DriftID: "drift-1", DryRun: true}), &result)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, "dry_run", result.Status)
+	assert.NotContains(t, result.Details, "snapshot_id")
+	assert.Empty(t, executor.executed())
+
+	snapshots, err := h.snapshots.ListSnapshots(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, snapshots)
+}
+
+func TestRemediateConfinesWorkDirToRepositoryRoot(t *testing.T) {
+	h, executor := newTestRemediationHandlers(t)
+
+	w := serveJSON(t, h.Remediate, jsonRequest(t, "/api/v1/remediate", RemediationRequest{DriftID: "drift-1", WorkDir: "infra"}), nil)
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code, "a working directory needs a repository root: %s", w.Body.String())
+
+	root := t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(root, "infra"), 0o755))
+	h.SetRepositoryRoot(root)
+	for _, workDir := range []string{"../", "/etc", "infra/../../outside", "missing"} {
+		w := serveJSON(t, h.Remediate, jsonRequest(t, "/api/v1/remediate", RemediationRequest{DriftID: "drift-1", WorkDir: workDir}), nil)
+		assert.Contains(t, []int{http.StatusBadRequest, http.StatusNotFound}, w.Code, "work_dir %q: %s", workDir, w.Body.String())
+	}
+	assert.Empty(t, executor.executed(), "a rejected working directory runs nothing")
+
+	var result RemediationResult
+	w = serveJSON(t, h.Remediate, jsonRequest(t, "/api/v1/remediate", RemediationRequest{DriftID: "drift-1", WorkDir: "infra"}), &result)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, []string{"i-123"}, executor.executed())
+}
+
+func TestRemediationHistoryFiltersByScope(t *testing.T) {
+	h, _ := newTestRemediationHandlers(t)
+	db, err := remediation.OpenSQLiteDB(filepath.Join(t.TempDir(), "history.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	history, err := remediation.NewHistoryStore(db)
+	require.NoError(t, err)
+	h.SetHistoryStore(history)
+
+	w := serveJSON(t, h.Remediate, jsonRequest(t, "/api/v1/remediate", RemediationRequest{DriftID: "drift-1"}), nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var page RemediationHistoryResponse
+	req := asUser(httptest.NewRequest(http.MethodGet, "/api/v1/remediate/history", nil), "jane", false, "aws:111111111111")
+	w = serveJSON(t, h.RemediationHistory, req, &page)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.Len(t, page.Entries, 1)
+	assert.Equal(t, "drift-1", page.Entries[0].DriftID)
+	assert.Equal(t, "111111111111", page.Entries[0].Details["account_id"])
+
+	req = asUser(httptest.NewRequest(http.MethodGet, "/api/v1/remediate/history", nil), "joe", false, "aws:222222222222")
+	w = serveJSON(t, h.RemediationHistory, req, &page)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Empty(t, page.Entries, "history outside the user's scopes is not listed")
+	assert.Equal(t, 0, page.Total)
+}
+
+func TestPlanRemediationChecksScope(t *testing.T) {
+	h, _ := newTestRemediationHandlers(t)
+
+	req := asUser(jsonRequest(t, "/api/v1/remediate/plan", RemediationPlanRequest{DriftIDs: []string{"drift-1"}}), "joe", false, "aws:222222222222")
+	w := serveJSON(t, h.PlanRemediation, req, nil)
+	assert.Equal(t, http.StatusForbidden, w.Code, w.Body.String())
+
+	req = asUser(jsonRequest(t, "/api/v1/remediate/plan", RemediationPlanRequest{DriftIDs: []string{"drift-1"}}), "jane", false, "aws:111111111111")
+	w = serveJSON(t, h.PlanRemediation, req, nil)
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+}
+
+func TestListSnapshotsFiltersByScope(t *testing.T) {
+	h, _ := newTestRemediationHandlers(t)
+	ctx := context.Background()
+	for _, account := range []string{"111111111111", "222222222222"} {
+		_, err := h.snapshots.CreateSnapshot(ctx, "before remediation", []remediation.ResourceSnapshot{
+			{Resource: "i-" + account, ResourceType: "aws_instance", Provider: "aws", State: map[string]interface{}{"account_id": account}},
+		}, nil)
+		require.NoError(t, err)
+	}
+
+	var snapshots []remediation.SnapshotSummary
+	req := asUser(httptest.NewRequest(http.MethodGet, "/api/v1/snapshots", nil), "jane", false, "aws:111111111111")
+	w := serveJSON(t, h.ListSnapshots, req, &snapshots)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Len(t, snapshots, 1, "snapshots outside the user's scopes are not listed")
+
+	req = asUser(httptest.NewRequest(http.MethodGet, "/api/v1/snapshots", nil), "admin", true)
+	w = serveJSON(t, h.ListSnapshots, req, &snapshots)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Len(t, snapshots, 2)
+}
@@ -0,0 +1,417 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/catherinevee/driftmgr/internal/auth"
+	"github.com/catherinevee/driftmgr/internal/drift/detector"
+	"github.com/catherinevee/driftmgr/internal/metrics"
+	"github.com/catherinevee/driftmgr/internal/remediation"
+	"github.com/catherinevee/driftmgr/internal/scheduler"
+	"github.com/catherinevee/driftmgr/internal/websocket"
+)
+
+// remediationPolicyAlert is the WebSocket message type for drift remediation policies leave
+// for a person
+const remediationPolicyAlert = "remediation_policy_alert"
+
+// PolicyAlert is drift the remediation policies did not remediate unattended, with why
+type PolicyAlert struct {
+	DriftID      string `json:"drift_id"`
+	Resource     string `json:"resource"`
+	ResourceType string `json:"resource_type"`
+	Provider     string `json:"provider"`
+	Severity     string `json:"severity"`
+	PolicyID     string `json:"policy_id,omitempty"`
+	PolicyName   string `json:"policy_name,omitempty"`
+	Reason       string `json:"reason"`
+	ApprovalID   string `json:"approval_id,omitempty"`
+}
+
+// PolicyRunResult is the outcome of applying the remediation policies to drift results
+type PolicyRunResult struct {
+	SnapshotID string              `json:"snapshot_id,omitempty"`
+	Results    []RemediationResult `json:"results"`
+	Alerts     []PolicyAlert       `json:"alerts"`
+	Ignored    int                 `json:"ignored"`
+}
+
+// handleListRemediationPolicies handles GET /api/v1/remediate/policies, listing the policies
+// in the order they are evaluated
+func (s *Server) handleListRemediationPolicies(w http.ResponseWriter, r *http.Request) {
+	if !s.remediationPoliciesAvailable(w) {
+		return
+	}
+	policies, err := s.services.RemediationPolicies.List(r.Context())
+	if err != nil {
+		s.writeError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to list remediation policies: %v", err))
+		return
+	}
+	s.writeJSON(w, http.StatusOK, policies)
+}
+
+// handleCreateRemediationPolicy handles POST /api/v1/remediate/policies
+func (s *Server) handleCreateRemediationPolicy(w http.ResponseWriter, r *http.Request) {
+	if !s.remediationPoliciesAvailable(w) {
+		return
+	}
+	var policy remediation.RemediationPolicy
+	if err := decodeRequest(r, &policy); err != nil {
+		s.writeRequestError(w, err)
+		return
+	}
+	policy.CreatedBy = requestUser(r)
+	if err := policy.Validate(); err != nil {
+		s.writeError(w, http.StatusBadRequest, fmt.Sprintf("Invalid remediation policy: %v", err))
+		return
+	}
+	if !s.policyWorkDirAllowed(w, policy.WorkDir) {
+		return
+	}
+
+	if err := s.services.RemediationPolicies.Create(r.Context(), &policy); err != nil {
+		s.writeError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to create remediation policy: %v", err))
+		return
+	}
+	s.writeJSON(w, http.StatusCreated, policy)
+}
+
+// handleGetRemediationPolicy handles GET /api/v1/remediate/policies/{id}
+func (s *Server) handleGetRemediationPolicy(w http.ResponseWriter, r *http.Request) {
+	if !s.remediationPoliciesAvailable(w) {
+		return
+	}
+	id := r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:]
+	policy, err := s.services.RemediationPolicies.Get(r.Context(), id)
+	if err != nil {
+		s.writePolicyError(w, id, err)
+		return
+	}
+	s.writeJSON(w, http.StatusOK, policy)
+}
+
+// handleUpdateRemediationPolicy handles PUT /api/v1/remediate/policies/{id}, replacing the
+// policy with the request body
+func (s *Server) handleUpdateRemediationPolicy(w http.ResponseWriter, r *http.Request) {
+	if !s.remediationPoliciesAvailable(w) {
+		return
+	}
+	var policy remediation.RemediationPolicy
+	if err := decodeRequest(r, &policy); err != nil {
+		s.writeRequestError(w, err)
+		return
+	}
+	policy.ID = r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:]
+	if err := policy.Validate(); err != nil {
+		s.writeError(w, http.StatusBadRequest, fmt.Sprintf("Invalid remediation policy: %v", err))
+		return
+	}
+	if !s.policyWorkDirAllowed(w, policy.WorkDir) {
+		return
+	}
+
+	if err := s.services.RemediationPolicies.Update(r.Context(), &policy); err != nil {
+		s.writePolicyError(w, policy.ID, err)
+		return
+	}
+	s.writeJSON(w, http.StatusOK, policy)
+}
+
+// handleDeleteRemediationPolicy handles DELETE /api/v1/remediate/policies/{id}
+func (s *Server) handleDeleteRemediationPolicy(w http.ResponseWriter, r *http.Request) {
+	if !s.remediationPoliciesAvailable(w) {
+		return
+	}
+	id := r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:]
+	if err := s.services.RemediationPolicies.Delete(r.Context(), id); err != nil {
+		s.writePolicyError(w, id, err)
+		return
+	}
+	s.writeJSON(w, http.StatusOK, map[string]string{"id": id, "status": "deleted"})
+}
+
+// policyWorkDirAllowed writes an error response and returns false unless a policy's working
+// directory, if it has one, is a directory under the repository root
+func (s *Server) policyWorkDirAllowed(w http.ResponseWriter, workDir string) bool {
+	if workDir == "" {
+		return true
+	}
+	if s.config == nil || s.config.RepositoryRoot == "" {
+		s.writeError(w, http.StatusBadRequest, "A remediation policy work_dir requires repository_root to be configured")
+		return false
+	}
+	_, ok := s.directoryUnderRoot(w, s.config.RepositoryRoot, workDir, "repository root", "Working directory")
+	return ok
+}
+
+// remediationPoliciesAvailable writes 503 Service Unavailable and returns false when
+// remediation policies cannot be stored
+func (s *Server) remediationPoliciesAvailable(w http.ResponseWriter) bool {
+	if s.services == nil || s.services.RemediationPolicies == nil {
+		s.writeError(w, http.StatusServiceUnavailable, "Remediation policies not available")
+		return false
+	}
+	return true
+}
+
+// writePolicyError writes 404 Not Found for an unknown policy and 500 for other errors
+func (s *Server) writePolicyError(w http.ResponseWriter, id string, err error) {
+	if errors.Is(err, remediation.ErrPolicyNotFound) {
+		s.writeError(w, http.StatusNotFound, fmt.Sprintf("Remediation policy %s not found", id))
+		return
+	}
+	s.writeError(w, http.StatusInternalServerError, fmt.Sprintf("Remediation policy %s: %v", id, err))
+}
+
+// applyRemediationPolicies applies the remediation policies to the drift of a schedule's
+// provider detected since its previous run, and alerts connected clients to the drift they
+// leave for a person. The policies act as the schedule's creator: only drift within their
+// scopes is considered, and remediation is held for approval unless they may administer
+// remediation. It returns how many drifts were remediated and alerted. Without policies
+// nothing is remediated or alerted.
+func (s *Server) applyRemediationPolicies(ctx context.Context, schedule *scheduler.Schedule) (int, int) {
+	if s.services.RemediationPolicies == nil {
+		return 0, 0
+	}
+	policies, err := s.services.RemediationPolicies.List(ctx)
+	if err != nil {
+		log.Printf("Scheduled scan %s did not apply remediation policies: %v", schedule.Name, err)
+		return 0, 0
+	}
+	if len(policies) == 0 {
+		return 0, 0
+	}
+
+	ctx = scheduleActor(ctx, schedule)
+	store := GetGlobalDriftStore()
+	var ids []string
+	drifts := make(map[string]*detector.DriftResult)
+	for _, id := range store.IDs() {
+		drift, ok := store.Get(id)
+		if !ok || !strings.EqualFold(drift.Provider, schedule.Provider) {
+			continue
+		}
+		if auth.CheckScope(ctx, drift.Provider, driftAccount(drift)) != nil {
+			continue
+		}
+		if schedule.LastRun != nil && !drift.Timestamp.After(*schedule.LastRun) {
+			// Decided on by an earlier run
+			continue
+		}
+		ids = append(ids, id)
+		drifts[id] = drift
+	}
+	if len(ids) == 0 {
+		return 0, 0
+	}
+
+	run := s.newRemediationHandlers().applyPolicies(ctx, policies, ids, drifts, schedule.RemediationAdmin)
+	remediated := 0
+	for _, result := range run.Results {
+		if result.Status == "success" {
+			remediated++
+		}
+	}
+	log.Printf("Scheduled scan %s remediated %d of %d drift(s) by policy and alerted on %d",
+		schedule.Name, remediated, len(ids), len(run.Alerts))
+
+	if len(run.Alerts) > 0 && s.services.WebSocket != nil {
+		s.services.WebSocket.GetHub().BroadcastMessage(websocket.NewMessage(remediationPolicyAlert, map[string]interface{}{
+			"schedule_id":   schedule.ID,
+			"schedule_name": schedule.Name,
+			"provider":      schedule.Provider,
+			"snapshot_id":   run.SnapshotID,
+			"alerts":        run.Alerts,
+		}))
+	}
+	return remediated, len(run.Alerts)
+}
+
+// applyPolicies decides what to do with each drift by the first policy that selects it.
+// Drift a policy may remediate unattended is remediated with the policy's strategy after the
+// affected resources are snapshotted, and is never forced; it is alerted instead when its
+// remediation plan needs another strategy, and held for approval when every remediation
+// needs approval or the policies do not run unattended. Remediation that fails is alerted too.
+func (h *RemediationHandlers) applyPolicies(ctx context.Context, policies []*remediation.RemediationPolicy, ids []string, drifts map[string]*detector.DriftResult, unattended bool) PolicyRunResult {
+	run := PolicyRunResult{Results: []RemediationResult{}, Alerts: []PolicyAlert{}}
+	var apply []string
+	applyPolicy := make(map[string]*remediation.RemediationPolicy)
+	for _, id := range ids {
+		drift := drifts[id]
+		decision := remediation.Decide(policies, drift)
+		switch {
+		case decision.Action == remediation.PolicyIgnore:
+			run.Ignored++
+		case decision.Action == remediation.PolicyNotify:
+			reason := decision.Reason
+			if reason == "" {
+				reason = "the policy only notifies"
+			}
+			run.Alerts = append(run.Alerts, policyAlert(id, drift, decision.Policy, reason))
+		case !decision.AutoApply:
+			run.Alerts = append(run.Alerts, policyAlert(id, drift, decision.Policy, decision.Reason))
+		default:
+			if reason := h.autoApplyBlocked(ctx, decision.Policy, drift); reason != "" {
+				run.Alerts = append(run.Alerts, policyAlert(id, drift, decision.Policy, reason))
+				continue
+			}
+			apply = append(apply, id)
+			applyPolicy[id] = decision.Policy
+		}
+	}
+	if len(apply) == 0 {
+		return run
+	}
+
+	if h.requireApproval || !unattended {
+		h.holdPolicyRemediation(ctx, &run, apply, applyPolicy, drifts)
+		return run
+	}
+
+	snapshot, err := h.createPreRemediationSnapshot(ctx, "policy_remediation", apply, drifts)
+	if err != nil {
+		// Nothing is changed without a snapshot to roll back to
+		for _, id := range apply {
+			run.Alerts = append(run.Alerts, policyAlert(id, drifts[id], applyPolicy[id], "failed to create pre-remediation snapshot: "+err.Error()))
+		}
+		return run
+	}
+	run.SnapshotID = snapshot.ID
+
+	for _, id := range apply {
+		policy := applyPolicy[id]
+		// autoApplyBlocked has already resolved the working directory
+		workDir, _ := h.policyWorkDir(policy)
+		result := h.remediateDrift(ctx, id, drifts[id], false, snapshot.ID, workDir, false, nil)
+		metrics.RecordRemediation(result.Status)
+		run.Results = append(run.Results, result)
+		if result.Status != "success" {
+			run.Alerts = append(run.Alerts, policyAlert(id, drifts[id], policy, "remediation failed: "+result.ErrorMessage))
+		}
+	}
+	return run
+}
+
+// autoApplyBlocked returns why a drift cannot be remediated unattended under a policy, or ""
+// when it can: its plan must use only the policy's strategy, and a policy with a working
+// directory needs it under the repository root and Terraform to be available
+func (h *RemediationHandlers) autoApplyBlocked(ctx context.Context, policy *remediation.RemediationPolicy, drift *detector.DriftResult) string {
+	if _, err := h.policyWorkDir(policy); err != nil {
+		return "invalid working directory: " + err.Error()
+	}
+	if policy.WorkDir != "" && h.tools != nil && !h.tools.Available("terraform") {
+		return "terraform is not available"
+	}
+	plan, err := h.remediationService.GeneratePlan(ctx, drift)
+	if err != nil {
+		return "failed to plan remediation: " + err.Error()
+	}
+	if err := policy.Permits(plan); err != nil {
+		return err.Error()
+	}
+	return ""
+}
+
+// policyWorkDir resolves a policy's working directory under the repository root, returning
+// "" for a policy without one
+func (h *RemediationHandlers) policyWorkDir(policy *remediation.RemediationPolicy) (string, error) {
+	if policy.WorkDir == "" {
+		return "", nil
+	}
+	if h.repositoryRoot == "" {
+		return "", errors.New("repository_root is not set")
+	}
+	workDir, status, message := resolveUnderRoot(h.repositoryRoot, policy.WorkDir, "repository root", "Working directory", true)
+	if status != 0 {
+		return "", errors.New(message)
+	}
+	return workDir, nil
+}
+
+// scheduleActor returns the context a schedule's remediation policies run in, acting as the
+// user who created the schedule within their scopes
+func scheduleActor(ctx context.Context, schedule *scheduler.Schedule) context.Context {
+	if schedule.CreatedBy != "" {
+		ctx = context.WithValue(ctx, "username", schedule.CreatedBy)
+	}
+	return context.WithValue(ctx, "scopes", schedule.Scopes)
+}
+
+// holdPolicyRemediation records an approval for the drift policies would remediate, one per
+// working directory, and alerts on each drift with its approval
+func (h *RemediationHandlers) holdPolicyRemediation(ctx context.Context, run *PolicyRunResult, apply []string, applyPolicy map[string]*remediation.RemediationPolicy, drifts map[string]*detector.DriftResult) {
+	var workDirs []string
+	byWorkDir := make(map[string][]string)
+	for _, id := range apply {
+		workDir := applyPolicy[id].WorkDir
+		if _, ok := byWorkDir[workDir]; !ok {
+			workDirs = append(workDirs, workDir)
+		}
+		byWorkDir[workDir] = append(byWorkDir[workDir], id)
+	}
+
+	for _, workDir := range workDirs {
+		driftIDs := byWorkDir[workDir]
+		reason := "remediation requires approval"
+		var approvalID string
+		if h.approvals == nil {
+			reason = "remediation requires approval but approvals are not available"
+		} else if approval, err := h.approvals.Create(ctx, driftIDs, workDir, false, policyActor(ctx)); err != nil {
+			reason = "failed to request approval: " + err.Error()
+		} else {
+			approvalID = approval.ID
+			if h.notifyApproval != nil {
+				h.notifyApproval(approval)
+			}
+		}
+		for _, id := range driftIDs {
+			alert := policyAlert(id, drifts[id], applyPolicy[id], reason)
+			alert.ApprovalID = approvalID
+			run.Alerts = append(run.Alerts, alert)
+		}
+	}
+}
+
+// policyActor returns who remediation policies act as in ctx
+func policyActor(ctx context.Context) string {
+	if username, ok := auth.GetUsernameFromContext(ctx); ok && username != "" {
+		return username
+	}
+	return "remediation-policy"
+}
+
+// policyAlert describes a drift the policies left for a person
+func policyAlert(id string, drift *detector.DriftResult, policy *remediation.RemediationPolicy, reason string) PolicyAlert {
+	alert := PolicyAlert{
+		DriftID:      id,
+		Resource:     drift.Resource,
+		ResourceType: drift.ResourceType,
+		Provider:     drift.Provider,
+		Severity:     severityName(drift.Severity),
+		Reason:       reason,
+	}
+	if policy != nil {
+		alert.PolicyID = policy.ID
+		alert.PolicyName = policy.Name
+	}
+	return alert
+}
+
+// severityName returns the name of a drift severity
+func severityName(severity detector.DriftSeverity) string {
+	switch severity {
+	case detector.SeverityLow:
+		return "low"
+	case detector.SeverityMedium:
+		return "medium"
+	case detector.SeverityHigh:
+		return "high"
+	default:
+		return "critical"
+	}
+}
@@ -0,0 +1,146 @@
+package api
+
+import (
+	"context"
+	"testing"
+
+	"github.com/catherinevee/driftmgr/internal/drift/detector"
+	"github.com/catherinevee/driftmgr/internal/remediation"
+	"github.com/catherinevee/driftmgr/internal/scheduler"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// policyDrifts returns the drift results of the test drift store: drift-1, a high severity
+// drift on i-123, and drift-2, a low severity drift on i-456
+func policyDrifts(t *testing.T, h *RemediationHandlers) ([]string, map[string]*detector.DriftResult) {
+	t.Helper()
+	low := *mustGetDrift(t, h, "drift-1")
+	low.Resource = "i-456"
+	low.Severity = detector.SeverityLow
+	h.driftStore.Store("drift-2", &low)
+	return []string{"drift-1", "drift-2"}, map[string]*detector.DriftResult{
+		"drift-1": mustGetDrift(t, h, "drift-1"),
+		"drift-2": mustGetDrift(t, h, "drift-2"),
+	}
+}
+
+func mustGetDrift(t *testing.T, h *RemediationHandlers, id string) *detector.DriftResult {
+	t.Helper()
+	drift, ok := h.driftStore.Get(id)
+	require.True(t, ok, id)
+	return drift
+}
+
+func TestApplyPoliciesRemediatesLowRiskDrift(t *testing.T) {
+	h, executor := newTestRemediationHandlers(t)
+	ids, drifts := policyDrifts(t, h)
+	policies := []*remediation.RemediationPolicy{{
+		ID:        "policy-instances",
+		Name:      "instances",
+		Selector:  remediation.PolicySelector{Provider: "aws", ResourceType: "aws_*"},
+		Action:    remediation.PolicyRemediate,
+		Strategy:  remediation.ActionTypeUpdate,
+		AutoApply: true,
+	}}
+
+	run := h.applyPolicies(context.Background(), policies, ids, drifts, true)
+	assert.Equal(t, []string{"i-456"}, executor.executed(), "only low severity drift is applied")
+	require.Len(t, run.Results, 1)
+	assert.Equal(t, "success", run.Results[0].Status, run.Results[0].ErrorMessage)
+
+	require.Len(t, run.Alerts, 1)
+	assert.Equal(t, "drift-1", run.Alerts[0].DriftID)
+	assert.Equal(t, "instances", run.Alerts[0].PolicyName)
+	assert.Equal(t, "high", run.Alerts[0].Severity)
+
+	require.NotEmpty(t, run.SnapshotID)
+	snapshot, err := h.snapshots.GetSnapshot(context.Background(), run.SnapshotID)
+	require.NoError(t, err)
+	require.Len(t, snapshot.Resources, 1)
+	assert.Equal(t, "i-456", snapshot.Resources[0].Resource)
+	assert.Equal(t, "policy_remediation", snapshot.Metadata["trigger"])
+}
+
+func TestApplyPoliciesAlertsWhenPlanNeedsAnotherStrategy(t *testing.T) {
+	h, executor := newTestRemediationHandlers(t)
+	ids, drifts := policyDrifts(t, h)
+	policies := []*remediation.RemediationPolicy{
+		{Name: "ignore i-123 severity", Selector: remediation.PolicySelector{Severity: "high"}, Action: remediation.PolicyIgnore},
+		{Name: "refresh only", Action: remediation.PolicyRemediate, Strategy: remediation.ActionTypeRefresh, AutoApply: true},
+	}
+
+	run := h.applyPolicies(context.Background(), policies, ids, drifts, true)
+	assert.Empty(t, executor.executed())
+	assert.Empty(t, run.SnapshotID, "nothing is snapshotted when nothing is applied")
+	assert.Equal(t, 1, run.Ignored)
+	require.Len(t, run.Alerts, 1)
+	assert.Equal(t, "drift-2", run.Alerts[0].DriftID)
+	assert.Contains(t, run.Alerts[0].Reason, "only allows refresh")
+}
+
+func TestApplyPoliciesHoldsForApproval(t *testing.T) {
+	h, executor := newTestRemediationHandlers(t)
+	ids, drifts := policyDrifts(t, h)
+	var notified []*remediation.Approval
+	h.SetApprovalGate(h.approvals, true, func(approval *remediation.Approval) {
+		notified = append(notified, approval)
+	})
+	policies := []*remediation.RemediationPolicy{{
+		Name:                 "everything",
+		Action:               remediation.PolicyRemediate,
+		Strategy:             remediation.ActionTypeUpdate,
+		AutoApply:            true,
+		MaxAutoApplySeverity: "critical",
+	}}
+
+	run := h.applyPolicies(context.Background(), policies, ids, drifts, true)
+	assert.Empty(t, executor.executed(), "held remediation does not run")
+	require.Len(t, notified, 1)
+	assert.Equal(t, []string{"drift-1", "drift-2"}, notified[0].DriftIDs)
+	assert.False(t, notified[0].Force)
+	require.Len(t, run.Alerts, 2)
+	assert.Equal(t, notified[0].ID, run.Alerts[0].ApprovalID)
+}
+
+func TestApplyPoliciesActAsScheduleCreator(t *testing.T) {
+	h, executor := newTestRemediationHandlers(t)
+	ids, drifts := policyDrifts(t, h)
+	var notified []*remediation.Approval
+	h.SetApprovalGate(h.approvals, false, func(approval *remediation.Approval) {
+		notified = append(notified, approval)
+	})
+	policies := []*remediation.RemediationPolicy{{
+		Name:      "instances",
+		Action:    remediation.PolicyRemediate,
+		Strategy:  remediation.ActionTypeUpdate,
+		AutoApply: true,
+	}}
+
+	// A creator without remediation admin has the remediation held for approval
+	ctx := scheduleActor(context.Background(), &scheduler.Schedule{CreatedBy: "jane", Scopes: []string{"aws:111111111111"}})
+	run := h.applyPolicies(ctx, policies, ids, drifts, false)
+	assert.Empty(t, executor.executed())
+	require.Len(t, notified, 1)
+	assert.Equal(t, "jane", notified[0].RequestedBy)
+	require.Len(t, run.Alerts, 2)
+	assert.Equal(t, notified[0].ID, run.Alerts[1].ApprovalID)
+}
+
+func TestApplyPoliciesConfinesWorkDir(t *testing.T) {
+	h, executor := newTestRemediationHandlers(t)
+	ids, drifts := policyDrifts(t, h)
+	h.SetRepositoryRoot(t.TempDir())
+	policies := []*remediation.RemediationPolicy{{
+		Name:      "outside",
+		Action:    remediation.PolicyRemediate,
+		Strategy:  remediation.ActionTypeUpdate,
+		AutoApply: true,
+		WorkDir:   "../outside",
+	}}
+
+	run := h.applyPolicies(context.Background(), policies, ids, drifts, true)
+	assert.Empty(t, executor.executed(), "nothing runs outside the repository root")
+	require.Len(t, run.Alerts, 2)
+	assert.Contains(t, run.Alerts[1].Reason, "invalid working directory")
+}
@@ -0,0 +1,228 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/catherinevee/driftmgr/internal/drift/detector"
+	"github.com/catherinevee/driftmgr/internal/graph"
+	"github.com/catherinevee/driftmgr/internal/remediation"
+)
+
+// PreviewBatchRemediation handles POST /api/v1/remediate/batch/preview. It plans a batch
+// without running it, groups the drifted resources by whether their remediation is
+// destructive, safe or an import, and estimates the batch's blast radius from the discovered
+// resources that rely on them. The returned preview_token must be passed back to
+// /remediate/batch to run the batch.
+func (h *RemediationHandlers) PreviewBatchRemediation(w http.ResponseWriter, r *http.Request) {
+	// Set common headers
+	SetCommonHeaders(w)
+
+	var previewRequest RemediationPlanRequest
+	if err := decodeRequest(r, &previewRequest); err != nil {
+		response := NewResponseWriter(w)
+		response.WriteRequestError(err)
+		return
+	}
+
+	selected, drifts, ok := h.resolveDrifts(w, previewRequest.DriftIDs, previewRequest.SeverityFilter)
+	if !ok {
+		return
+	}
+	for _, drift := range drifts {
+		if !checkDriftScope(w, r, drift) {
+			return
+		}
+	}
+
+	plan, ok := h.batchPlan(r.Context(), w, selected, drifts)
+	if !ok {
+		return
+	}
+
+	live := graph.BuildLiveGraph(scopedResources(r.Context(), GetGlobalResourceStore().All()))
+	preview := buildBatchPreview(selected, drifts, plan, live)
+	preview.PreviewToken, preview.PreviewExpiresAt = h.remediationService.BatchPreviewToken(selected, plan)
+
+	response := NewResponseWriter(w)
+	response.WriteSuccess(preview, &APIMeta{
+		Count:     len(selected),
+		Timestamp: time.Now().UTC().Format(time.RFC3339),
+	})
+}
+
+// batchPlan plans the remediation of the selected drift results, writing the error response
+// itself when it cannot
+func (h *RemediationHandlers) batchPlan(ctx context.Context, w http.ResponseWriter, selected []string, drifts map[string]*detector.DriftResult) (*remediation.RemediationPlan, bool) {
+	driftResults := make([]detector.DriftResult, 0, len(selected))
+	for _, id := range selected {
+		driftResults = append(driftResults, *drifts[id])
+	}
+
+	plan, err := h.remediationService.PreviewPlan(ctx, driftResults)
+	if err != nil {
+		response := NewResponseWriter(w)
+		response.WriteInternalError("Failed to create remediation plan: " + err.Error())
+		return nil, false
+	}
+	return plan, true
+}
+
+// checkBatchPreview verifies the preview token sent with a batch against the batch's current
+// plan. It responds with 400 Bad Request when the token is missing, or 409 Conflict when it has
+// expired or previewed a different plan, and returns false.
+func (h *RemediationHandlers) checkBatchPreview(ctx context.Context, w http.ResponseWriter, token string, selected []string, drifts map[string]*detector.DriftResult) bool {
+	plan, ok := h.batchPlan(ctx, w, selected, drifts)
+	if !ok {
+		return false
+	}
+
+	err := h.remediationService.CheckBatchPreview(token, selected, plan)
+	switch {
+	case errors.Is(err, remediation.ErrBatchPreviewRequired):
+		NewResponseWriter(w).WriteBadRequest(err.Error())
+		return false
+	case err != nil:
+		NewResponseWriter(w).WriteConflict(err.Error())
+		return false
+	}
+	return true
+}
+
+// buildBatchPreview groups the planned actions by drift result and estimates the batch's blast
+// radius from the live graph of discovered resources. A drift whose actions include a
+// destructive one is destructive; otherwise one with an import is an import.
+func buildBatchPreview(selected []string, drifts map[string]*detector.DriftResult, plan *remediation.RemediationPlan, live *graph.LiveGraph) BatchRemediationPreview {
+	preview := BatchRemediationPreview{
+		DriftIDs:         selected,
+		RiskLevel:        plan.RiskLevel.String(),
+		RequiresApproval: plan.RequiresApproval,
+		Destructive:      []BatchPreviewEntry{},
+		Safe:             []BatchPreviewEntry{},
+		Import:           []BatchPreviewEntry{},
+		BlastRadius:      BatchBlastRadius{Dependents: []string{}, Disrupted: []string{}},
+	}
+
+	// Order actions the way they would be executed
+	ordered := make([]remediation.RemediationAction, 0, len(plan.Actions))
+	actionsByID := make(map[string]remediation.RemediationAction, len(plan.Actions))
+	for _, action := range plan.Actions {
+		actionsByID[action.ID] = action
+	}
+	for _, id := range plan.ExecutionOrder {
+		if action, exists := actionsByID[id]; exists {
+			ordered = append(ordered, action)
+		}
+	}
+
+	reliedOnBy := make(map[string][]string)
+	for _, edge := range live.Edges {
+		reliedOnBy[edge.TargetID] = append(reliedOnBy[edge.TargetID], edge.SourceID)
+	}
+
+	remediated := make(map[string]bool, len(selected))
+	for _, id := range selected {
+		remediated[drifts[id].Resource] = true
+	}
+
+	dependents := make(map[string]bool)
+	disrupted := make(map[string]bool)
+	for _, id := range selected {
+		drift := drifts[id]
+		entry := BatchPreviewEntry{
+			DriftID:      id,
+			Resource:     drift.Resource,
+			ResourceType: drift.ResourceType,
+			Provider:     drift.Provider,
+			Dependents:   reliantResources(reliedOnBy, drift.Resource),
+		}
+		impact := remediation.ActionImpactSafe
+		for _, action := range ordered {
+			if action.Resource != drift.Resource {
+				continue
+			}
+			entry.Actions = append(entry.Actions, plannedAction(action))
+			switch remediation.ImpactOf(action.Type) {
+			case remediation.ActionImpactDestructive:
+				impact = remediation.ActionImpactDestructive
+			case remediation.ActionImpactImport:
+				if impact == remediation.ActionImpactSafe {
+					impact = remediation.ActionImpactImport
+				}
+			}
+		}
+		if len(entry.Actions) == 0 {
+			continue
+		}
+
+		for _, dependent := range entry.Dependents {
+			if remediated[dependent] {
+				continue
+			}
+			dependents[dependent] = true
+			if impact == remediation.ActionImpactDestructive {
+				disrupted[dependent] = true
+			}
+		}
+
+		preview.BlastRadius.Resources++
+		switch impact {
+		case remediation.ActionImpactDestructive:
+			preview.Destructive = append(preview.Destructive, entry)
+		case remediation.ActionImpactImport:
+			preview.Import = append(preview.Import, entry)
+		default:
+			preview.Safe = append(preview.Safe, entry)
+		}
+	}
+
+	for dependent := range dependents {
+		preview.BlastRadius.Dependents = append(preview.BlastRadius.Dependents, dependent)
+	}
+	for dependent := range disrupted {
+		preview.BlastRadius.Disrupted = append(preview.BlastRadius.Disrupted, dependent)
+	}
+	slices.Sort(preview.BlastRadius.Dependents)
+	slices.Sort(preview.BlastRadius.Disrupted)
+	return preview
+}
+
+// reliantResources returns the resources that rely on a resource, directly or through other
+// resources, sorted. Edges of the live graph run from a resource to the one it relies on.
+func reliantResources(reliedOnBy map[string][]string, resource string) []string {
+	seen := map[string]bool{resource: true}
+	queue := []string{resource}
+	var reliant []string
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, source := range reliedOnBy[current] {
+			if seen[source] {
+				continue
+			}
+			seen[source] = true
+			reliant = append(reliant, source)
+			queue = append(queue, source)
+		}
+	}
+	slices.Sort(reliant)
+	return reliant
+}
+
+// plannedAction converts a remediation action to its preview form
+func plannedAction(action remediation.RemediationAction) PlannedAction {
+	return PlannedAction{
+		ID:           action.ID,
+		Type:         string(action.Type),
+		Resource:     action.Resource,
+		ResourceType: action.ResourceType,
+		Provider:     action.Provider,
+		Description:  action.Description,
+		Command:      action.Command,
+		RiskLevel:    action.RiskLevel.String(),
+		DependsOn:    action.DependsOn,
+	}
+}
@@ -0,0 +1,87 @@
+package api
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/catherinevee/driftmgr/internal/drift/comparator"
+	"github.com/catherinevee/driftmgr/internal/drift/detector"
+	"github.com/catherinevee/driftmgr/internal/graph"
+	internalModels "github.com/catherinevee/driftmgr/internal/models"
+	"github.com/catherinevee/driftmgr/internal/remediation"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRemediateBatchRequiresPreview(t *testing.T) {
+	h, executor := newTestRemediationHandlers(t)
+
+	w := serveJSON(t, h.RemediateBatch, jsonRequest(t, "/api/v1/remediate/batch", BatchRemediationRequest{DriftIDs: []string{"drift-1"}}), nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code, w.Body.String())
+	w = serveJSON(t, h.RemediateBatch, jsonRequest(t, "/api/v1/remediate/batch", BatchRemediationRequest{DriftIDs: []string{"drift-1"}, DryRun: true}), nil)
+	assert.Equal(t, http.StatusOK, w.Code, "dry runs need no preview: %s", w.Body.String())
+
+	var preview BatchRemediationPreview
+	w = serveJSON(t, h.PreviewBatchRemediation, jsonRequest(t, "/api/v1/remediate/batch/preview", RemediationPlanRequest{DriftIDs: []string{"drift-1"}}), &preview)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.NotEmpty(t, preview.PreviewToken)
+	require.Len(t, preview.Safe, 1)
+	assert.Equal(t, "i-123", preview.Safe[0].Resource)
+	assert.Empty(t, preview.Destructive)
+
+	// The token does not cover a batch whose plan has changed since the preview
+	drift, _ := h.driftStore.Get("drift-1")
+	changed := *drift
+	changed.Differences = []comparator.Difference{{Path: "instance_type", Type: comparator.DiffTypeModified}}
+	h.driftStore.Store("drift-1", &changed)
+	w = serveJSON(t, h.RemediateBatch, jsonRequest(t, "/api/v1/remediate/batch", BatchRemediationRequest{DriftIDs: []string{"drift-1"}, PreviewToken: preview.PreviewToken}), nil)
+	assert.Equal(t, http.StatusConflict, w.Code, w.Body.String())
+	assert.Empty(t, executor.executed())
+
+	h.driftStore.Store("drift-1", drift)
+	var batch BatchRemediationResponse
+	w = serveJSON(t, h.RemediateBatch, jsonRequest(t, "/api/v1/remediate/batch", BatchRemediationRequest{DriftIDs: []string{"drift-1"}, PreviewToken: preview.PreviewToken}), &batch)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, []string{"i-123"}, executor.executed())
+}
+
+func TestBuildBatchPreview(t *testing.T) {
+	drifts := map[string]*detector.DriftResult{
+		"drift-sg":  {Resource: "sg-1", ResourceType: "aws_security_group", Provider: "aws"},
+		"drift-vpc": {Resource: "vpc-1", ResourceType: "aws_vpc", Provider: "aws"},
+		"drift-s3":  {Resource: "bucket-1", ResourceType: "aws_s3_bucket", Provider: "aws"},
+	}
+	plan := &remediation.RemediationPlan{
+		Actions: []remediation.RemediationAction{
+			{ID: "taint-sg", Type: remediation.ActionTypeTaint, Resource: "sg-1"},
+			{ID: "replace-sg", Type: remediation.ActionTypeReplace, Resource: "sg-1"},
+			{ID: "update-vpc", Type: remediation.ActionTypeUpdate, Resource: "vpc-1"},
+			{ID: "import-s3", Type: remediation.ActionTypeImport, Resource: "bucket-1"},
+		},
+		ExecutionOrder: []string{"update-vpc", "taint-sg", "replace-sg", "import-s3"},
+		RiskLevel:      remediation.RiskLevelHigh,
+	}
+	// The instance is secured by the group, which sits in the VPC, and a load balancer
+	// routes to the instance
+	live := &graph.LiveGraph{Edges: []internalModels.ResourceRelationship{
+		{SourceID: "i-1", TargetID: "sg-1"},
+		{SourceID: "sg-1", TargetID: "vpc-1"},
+		{SourceID: "lb-1", TargetID: "i-1"},
+	}}
+
+	preview := buildBatchPreview([]string{"drift-sg", "drift-vpc", "drift-s3"}, drifts, plan, live)
+	assert.Equal(t, "high", preview.RiskLevel)
+	require.Len(t, preview.Destructive, 1)
+	assert.Equal(t, "sg-1", preview.Destructive[0].Resource)
+	assert.Equal(t, []string{"i-1", "lb-1"}, preview.Destructive[0].Dependents)
+	require.Len(t, preview.Destructive[0].Actions, 2)
+	assert.Equal(t, "taint", preview.Destructive[0].Actions[0].Type)
+	require.Len(t, preview.Safe, 1)
+	assert.Equal(t, []string{"i-1", "lb-1", "sg-1"}, preview.Safe[0].Dependents)
+	require.Len(t, preview.Import, 1)
+	assert.Empty(t, preview.Import[0].Dependents)
+
+	assert.Equal(t, 3, preview.BlastRadius.Resources)
+	assert.Equal(t, []string{"i-1", "lb-1"}, preview.BlastRadius.Dependents, "remediated resources are not counted as dependents")
+	assert.Equal(t, []string{"i-1", "lb-1"}, preview.BlastRadius.Disrupted)
+}
@@ -0,0 +1,314 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// FieldError describes what is wrong with one field of a request body
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ValidationErrors lists the field errors of a request body
+type ValidationErrors []FieldError
+
+// Error joins the field messages, such as "regions is required; provider must be one of aws, azure, gcp"
+func (e ValidationErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, fieldErr := range e {
+		messages[i] = fieldErr.Message
+	}
+	return strings.Join(messages, "; ")
+}
+
+// validatable is implemented by request bodies that check their own fields once decoded
+type validatable interface {
+	Validate() ValidationErrors
+}
+
+// fieldChecks collects the field errors of a request as its checks run
+type fieldChecks struct {
+	errs ValidationErrors
+}
+
+// add records a field error
+func (c *fieldChecks) add(field, format string, args ...interface{}) {
+	c.errs = append(c.errs, FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+// required records an error when the field is empty
+func (c *fieldChecks) required(field string, empty bool) {
+	if empty {
+		c.add(field, "%s is required", field)
+	}
+}
+
+// oneOf records an error when a non-empty value is not one of the allowed values
+func (c *fieldChecks) oneOf(field, value string, allowed ...string) {
+	if value == "" {
+		return
+	}
+	for _, option := range allowed {
+		if value == option {
+			return
+		}
+	}
+	c.add(field, "%s must be one of %s", field, strings.Join(allowed, ", "))
+}
+
+// nonNegative records an error when the value is negative
+func (c *fieldChecks) nonNegative(field string, value int) {
+	if value < 0 {
+		c.add(field, "%s must not be negative", field)
+	}
+}
+
+// noEmptyEntries records an error when a list has a blank entry
+func (c *fieldChecks) noEmptyEntries(field string, values []string) {
+	for _, value := range values {
+		if strings.TrimSpace(value) == "" {
+			c.add(field, "%s must not contain empty entries", field)
+			return
+		}
+	}
+}
+
+// result returns the recorded errors, or nil when every check passed
+func (c *fieldChecks) result() ValidationErrors {
+	if len(c.errs) == 0 {
+		return nil
+	}
+	return c.errs
+}
+
+// providerNames returns the sorted keys of a provider table, for oneOf
+func providerNames[T any](providers map[string]T) []string {
+	names := make([]string, 0, len(providers))
+	for name := range providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// decodeJSON decodes a request body into v. An empty body is an error wrapping io.EOF, so
+// handlers whose body is optional can ignore it, and fields of the wrong JSON type are
+// reported as ValidationErrors naming the field.
+func decodeJSON(r *http.Request, v interface{}) error {
+	err := json.NewDecoder(r.Body).Decode(v)
+	var typeErr *json.UnmarshalTypeError
+	var syntaxErr *json.SyntaxError
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, io.EOF):
+		return fmt.Errorf("request body is required: %w", err)
+	case errors.As(err, &typeErr) && typeErr.Field != "":
+		return ValidationErrors{{
+			Field:   typeErr.Field,
+			Message: fmt.Sprintf("%s must be %s, not %s", typeErr.Field, jsonTypeName(typeErr.Type), typeErr.Value),
+		}}
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		return errors.New("not valid JSON: the body ends before the JSON is complete")
+	case errors.As(err, &syntaxErr):
+		return fmt.Errorf("not valid JSON at offset %d: %w", syntaxErr.Offset, err)
+	default:
+		return err
+	}
+}
+
+// jsonTypeName names the JSON type a Go type decodes from
+func jsonTypeName(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.String:
+		return "a string"
+	case reflect.Bool:
+		return "a boolean"
+	case reflect.Slice, reflect.Array:
+		return "an array"
+	case reflect.Map, reflect.Struct:
+		return "an object"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return "a number"
+	default:
+		return "a valid " + t.String()
+	}
+}
+
+// decodeRequest decodes a request body into v and validates it when v checks its own fields
+func decodeRequest(r *http.Request, v interface{}) error {
+	if err := decodeJSON(r, v); err != nil {
+		return err
+	}
+	return validateRequest(v)
+}
+
+// validateRequest returns the field errors of a decoded request, or nil when it is valid or
+// does not check its fields
+func validateRequest(v interface{}) error {
+	if request, ok := v.(validatable); ok {
+		if errs := request.Validate(); len(errs) > 0 {
+			return errs
+		}
+	}
+	return nil
+}
+
+// writeRequestError writes a 400 response for a request body that could not be decoded or
+// failed validation, listing the field errors under fields
+func (s *Server) writeRequestError(w http.ResponseWriter, err error) {
+	response := map[string]interface{}{"error": "Invalid request body: " + err.Error()}
+	var fieldErrs ValidationErrors
+	if errors.As(err, &fieldErrs) {
+		response["fields"] = fieldErrs
+	}
+	s.writeJSON(w, http.StatusBadRequest, response)
+}
+
+// WriteRequestError writes a validation error response for a request body that could not be
+// decoded or failed validation, listing the field errors under error.fields
+func (rw *ResponseWriter) WriteRequestError(err error) error {
+	response := NewErrorResponse("VALIDATION_ERROR", "Invalid request body", err.Error())
+	var fieldErrs ValidationErrors
+	if errors.As(err, &fieldErrs) {
+		response.Error.Fields = fieldErrs
+	}
+	return rw.WriteJSON(http.StatusBadRequest, response)
+}
+
+// Validate checks a discovery request. Provider and mode may be empty, since the handler
+// defaults them to aws and full.
+func (r DiscoverRequest) Validate() ValidationErrors {
+	var checks fieldChecks
+	checks.oneOf("provider", r.Provider, "aws", "azure", "gcp", "kubernetes")
+	checks.oneOf("mode", r.Mode, "full", "delta", "fast")
+	if r.Mode == "fast" && r.Provider != "" && r.Provider != "aws" {
+		checks.add("mode", "mode fast is only supported for the aws provider")
+	}
+	checks.noEmptyEntries("regions", r.Regions)
+	checks.noEmptyEntries("tag_key_exists", r.TagKeyExists)
+	return checks.result()
+}
+
+// Validate checks a deletion request
+func (r DeletionRequest) Validate() ValidationErrors {
+	var checks fieldChecks
+	checks.required("provider", r.Provider == "")
+	checks.oneOf("provider", r.Provider, providerNames(deletionProviders)...)
+	checks.required("account_id", r.AccountID == "")
+	checks.noEmptyEntries("regions", r.Regions)
+	checks.noEmptyEntries("resource_types", r.ResourceTypes)
+	return checks.result()
+}
+
+// Validate checks a tag remediation request
+func (r TagRemediationRequest) Validate() ValidationErrors {
+	var checks fieldChecks
+	checks.required("provider", r.Provider == "")
+	checks.oneOf("provider", r.Provider, providerNames(resourceTaggers)...)
+	checks.required("tags", len(r.Tags) == 0)
+	for key := range r.Tags {
+		if strings.TrimSpace(key) == "" {
+			checks.add("tags", "tags must not have an empty key")
+			break
+		}
+	}
+	return checks.result()
+}
+
+// Validate checks a schedule request
+func (r ScheduleRequest) Validate() ValidationErrors {
+	var checks fieldChecks
+	checks.required("provider", r.Provider == "")
+	checks.oneOf("provider", r.Provider, providerNames(scheduleProviders)...)
+	checks.required("cron_expression", strings.TrimSpace(r.CronExpression) == "")
+	checks.nonNegative("drift_threshold", r.DriftThreshold)
+	return checks.result()
+}
+
+// Validate checks a remediation request
+func (r RemediationRequest) Validate() ValidationErrors {
+	var checks fieldChecks
+	checks.required("drift_id", r.DriftID == "")
+	return checks.result()
+}
+
+// Validate checks an inventory export request. An empty format defaults to tf-import.
+func (r InventoryExportRequest) Validate() ValidationErrors {
+	var checks fieldChecks
+	checks.oneOf("format", r.Format, "tf-import", "json")
+	return checks.result()
+}
+
+// Validate checks a Helm drift request. Values only apply when rendering a chart.
+func (r HelmDriftRequest) Validate() ValidationErrors {
+	var checks fieldChecks
+	checks.required("release", strings.TrimSpace(r.Release) == "")
+	if r.Chart == "" && (len(r.ValuesFiles) > 0 || len(r.Values) > 0) {
+		checks.add("chart", "chart is required when values or values_files are given")
+	}
+	checks.noEmptyEntries("values_files", r.ValuesFiles)
+	return checks.result()
+}
+
+// Validate checks a state set analysis request. A plan is analyzed on its own.
+func (r StateSetAnalysisRequest) Validate() ValidationErrors {
+	var checks fieldChecks
+	checks.oneOf("input_type", r.InputType, "state", "plan")
+	if r.InputType == "plan" {
+		checks.required("plan", len(r.Plan) == 0)
+		if len(r.StateFileIDs) > 0 || len(r.States) > 0 {
+			checks.add("input_type", "state_file_ids and states cannot be analyzed with a plan")
+		}
+	} else if len(r.Plan) > 0 {
+		checks.add("plan", "plan requires input_type plan")
+	}
+	return checks.result()
+}
+
+// Validate checks a refresh-only plan drift request
+func (r PlanDriftRequest) Validate() ValidationErrors {
+	var checks fieldChecks
+	checks.required("work_dir", strings.TrimSpace(r.WorkDir) == "")
+	for key := range r.BackendConfig {
+		if strings.TrimSpace(key) == "" {
+			checks.add("backend_config", "backend_config keys must not be empty")
+			break
+		}
+	}
+	checks.noEmptyEntries("backend_config_files", r.BackendConfigFiles)
+	checks.noEmptyEntries("var_files", r.VarFiles)
+	return checks.result()
+}
+
+// Validate checks an AWS organization discovery request
+func (r OrganizationDiscoveryRequest) Validate() ValidationErrors {
+	var checks fieldChecks
+	checks.noEmptyEntries("regions", r.Regions)
+	checks.noEmptyEntries("account_ids", r.AccountIDs)
+	checks.nonNegative("concurrency", r.Concurrency)
+	return checks.result()
+}
+
+// Validate checks a GCP organization discovery request
+func (r GCPOrganizationDiscoveryRequest) Validate() ValidationErrors {
+	var checks fieldChecks
+	if r.OrganizationID == "" && r.FolderID == "" {
+		checks.add("organization_id", "organization_id or folder_id is required")
+	}
+	if r.OrganizationID != "" && r.FolderID != "" {
+		checks.add("folder_id", "only one of organization_id and folder_id may be given")
+	}
+	checks.nonNegative("concurrency", r.Concurrency)
+	return checks.result()
+}
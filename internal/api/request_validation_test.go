@@ -0,0 +1,99 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeRequest(t *testing.T) {
+	decode := func(body string, v interface{}) error {
+		return decodeRequest(httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body)), v)
+	}
+
+	var deletion DeletionRequest
+	err := decode(`{"provider":"digitalocean"}`, &deletion)
+	var fieldErrs ValidationErrors
+	require.True(t, errors.As(err, &fieldErrs))
+	assert.Equal(t, ValidationErrors{
+		{Field: "provider", Message: "provider must be one of aws, azure, gcp"},
+		{Field: "account_id", Message: "account_id is required"},
+	}, fieldErrs)
+
+	err = decode(`{"provider":"aws","regions":"us-east-1"}`, &DiscoverRequest{})
+	require.True(t, errors.As(err, &fieldErrs))
+	assert.Equal(t, ValidationErrors{{Field: "regions", Message: "regions must be an array, not string"}}, fieldErrs)
+
+	err = decode(`{"provider":`, &DiscoverRequest{})
+	assert.Contains(t, err.Error(), "not valid JSON")
+
+	// An empty body can be told apart for handlers whose body is optional
+	err = decode(``, &DiscoverRequest{})
+	assert.True(t, errors.Is(err, io.EOF))
+
+	var discover DiscoverRequest
+	require.NoError(t, decode(`{"provider":"gcp","regions":["us-central1"]}`, &discover))
+	assert.Equal(t, "gcp", discover.Provider)
+}
+
+func TestRequestValidate(t *testing.T) {
+	assert.Empty(t, DiscoverRequest{}.Validate())
+	assert.Equal(t, ValidationErrors{
+		{Field: "mode", Message: "mode fast is only supported for the aws provider"},
+		{Field: "regions", Message: "regions must not contain empty entries"},
+	}, DiscoverRequest{Provider: "azure", Mode: "fast", Regions: []string{"eastus", ""}}.Validate())
+
+	assert.Equal(t, ValidationErrors{
+		{Field: "provider", Message: "provider is required"},
+		{Field: "tags", Message: "tags is required"},
+	}, TagRemediationRequest{}.Validate())
+
+	assert.Equal(t, ValidationErrors{
+		{Field: "cron_expression", Message: "cron_expression is required"},
+	}, ScheduleRequest{Provider: "aws"}.Validate())
+
+	assert.Equal(t, ValidationErrors{
+		{Field: "format", Message: "format must be one of tf-import, json"},
+	}, InventoryExportRequest{Format: "csv"}.Validate())
+
+	assert.Equal(t, ValidationErrors{
+		{Field: "release", Message: "release is required"},
+		{Field: "chart", Message: "chart is required when values or values_files are given"},
+	}, HelmDriftRequest{Values: map[string]interface{}{"replicas": 2}}.Validate())
+
+	assert.Equal(t, ValidationErrors{
+		{Field: "work_dir", Message: "work_dir is required"},
+		{Field: "backend_config", Message: "backend_config keys must not be empty"},
+		{Field: "var_files", Message: "var_files must not contain empty entries"},
+	}, PlanDriftRequest{BackendConfig: map[string]string{"": "x"}, VarFiles: []string{""}}.Validate())
+
+	assert.Len(t, GCPOrganizationDiscoveryRequest{}.Validate(), 1)
+	assert.Len(t, GCPOrganizationDiscoveryRequest{OrganizationID: "1", FolderID: "2", Concurrency: -1}.Validate(), 2)
+}
+
+func TestWriteRequestError(t *testing.T) {
+	w := httptest.NewRecorder()
+	(&Server{}).writeRequestError(w, ValidationErrors{{Field: "provider", Message: "provider is required"}})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	var body struct {
+		Error  string       `json:"error"`
+		Fields []FieldError `json:"fields"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, "Invalid request body: provider is required", body.Error)
+	assert.Equal(t, []FieldError{{Field: "provider", Message: "provider is required"}}, body.Fields)
+
+	w = httptest.NewRecorder()
+	NewResponseWriter(w).WriteRequestError(ValidationErrors{{Field: "drift_id", Message: "drift_id is required"}})
+	var response APIResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "VALIDATION_ERROR", response.Error.Code)
+	assert.Equal(t, []FieldError{{Field: "drift_id", Message: "drift_id is required"}}, response.Error.Fields)
+}
@@ -0,0 +1,217 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/catherinevee/driftmgr/internal/auth"
+	"github.com/catherinevee/driftmgr/internal/drift/detector"
+	"github.com/catherinevee/driftmgr/internal/graph"
+	"github.com/catherinevee/driftmgr/pkg/models"
+)
+
+// resourceProviders are the provider prefixes a resource ID may be qualified with
+var resourceProviders = []string{"aws", "azure", "gcp", "digitalocean"}
+
+// parseResourceID splits a resource reference into its provider and ID. References are either a
+// bare ID or provider/ID, as in aws/i-0abc; IDs that themselves contain slashes, such as ARNs
+// and Azure resource IDs, must be URL-encoded in the path.
+func parseResourceID(reference string) (provider, id string) {
+	for _, name := range resourceProviders {
+		if rest, ok := strings.CutPrefix(reference, name+"/"); ok {
+			return name, rest
+		}
+	}
+	return "", reference
+}
+
+// handleGetResource handles GET /api/v1/resources/{id}, returning a discovered resource in full
+// with the resources that depend on it, the drift recorded against it and its cost estimate.
+// Resources that were not discovered by this server are looked up with fallback.
+func (s *Server) handleGetResource(fallback http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		segments := strings.Split(r.URL.EscapedPath(), "/")
+		reference, err := url.PathUnescape(segments[len(segments)-1])
+		if err != nil || reference == "" {
+			s.writeError(w, http.StatusBadRequest, "Invalid resource ID")
+			return
+		}
+		provider, id := parseResourceID(reference)
+
+		inventory := GetGlobalResourceStore().All()
+		matches := findResources(inventory, provider, id)
+		switch {
+		case len(matches) == 0 && fallback != nil && s.services != nil:
+			fallback(w, r)
+			return
+		case len(matches) == 0:
+			s.writeError(w, http.StatusNotFound, fmt.Sprintf("Resource %s not found", reference))
+			return
+		case len(matches) > 1:
+			candidates := make([]string, len(matches))
+			for i, match := range matches {
+				candidates[i] = match.Provider + "/" + match.ID
+			}
+			s.writeError(w, http.StatusConflict, fmt.Sprintf("Resource ID %s matches several resources; qualify it with a provider: %s",
+				reference, strings.Join(candidates, ", ")))
+			return
+		}
+
+		resource := matches[0]
+		if err := auth.CheckScope(r.Context(), resource.Provider, resource.AccountID); err != nil {
+			s.writeError(w, http.StatusForbidden, err.Error())
+			return
+		}
+		if resource.CostEstimate == nil {
+			if estimator := s.costEstimator(); estimator != nil {
+				if estimate, ok := estimator.EstimateCost(r.Context(), resource); ok {
+					resource.CostEstimate = estimate
+				}
+			}
+		}
+
+		s.writeJSON(w, http.StatusOK, ResourceDetailResponse{
+			Resource:   resource,
+			Dependents: resourceDependents(inventory, resource.ID),
+			Drift:      resourceDrift(GetGlobalDriftStore().List(), resource.ID),
+		})
+	}
+}
+
+// findResources returns the resources with the given ID, or ARN, restricted to provider when
+// it is set
+func findResources(resources []models.Resource, provider, id string) []models.Resource {
+	var matches []models.Resource
+	for _, resource := range resources {
+		if provider != "" && resource.Provider != provider {
+			continue
+		}
+		arn, _ := resource.Properties["arn"].(string)
+		if resource.ID == id || (arn != "" && arn == id) {
+			matches = append(matches, resource)
+		}
+	}
+	return matches
+}
+
+// resourceDependents returns the IDs of resources that list id as a dependency, sorted
+func resourceDependents(resources []models.Resource, id string) []string {
+	dependents := []string{}
+	for _, resource := range resources {
+		for _, dependency := range resource.Dependencies {
+			if dependency == id {
+				dependents = append(dependents, resource.ID)
+				break
+			}
+		}
+	}
+	sort.Strings(dependents)
+	return dependents
+}
+
+// resourceDrift returns the stored drift results whose actual or desired state has the given
+// id, newest first
+func resourceDrift(results []*detector.DriftResult, id string) []*detector.DriftResult {
+	matched := []*detector.DriftResult{}
+	for _, result := range results {
+		for _, state := range []map[string]interface{}{result.ActualState, result.DesiredState} {
+			if stateID, _ := state["id"].(string); stateID == id {
+				matched = append(matched, result)
+				break
+			}
+		}
+	}
+	sort.Slice(matched, func(i, j int) bool {
+		return matched[i].Timestamp.After(matched[j].Timestamp)
+	})
+	return matched
+}
+
+// handleSearchResources handles GET /api/v1/resources/search over the most recently discovered
+// resources. q is free text matched against IDs, names, types and tags; provider, type and
+// region filter exactly, as do repeated tag=Key=Value and tag_key=Key parameters. Results are
+// sorted by sort (id, name, type, region, provider or created) in order asc or desc and paged
+// with page and limit. Only resources in accounts the user's scopes cover are returned.
+func (s *Server) handleSearchResources(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	page, limit := s.parsePagination(r)
+	search := ResourceQuery{
+		Text:     query.Get("q"),
+		Provider: query.Get("provider"),
+		Type:     query.Get("type"),
+		Region:   query.Get("region"),
+		TagKeys:  query["tag_key"],
+		Sort:     query.Get("sort"),
+		Offset:   (page - 1) * limit,
+		Limit:    limit,
+		Allow: func(resource *models.Resource) bool {
+			return auth.CheckScope(r.Context(), resource.Provider, resource.AccountID) == nil
+		},
+	}
+	for _, tag := range query["tag"] {
+		key, value, ok := strings.Cut(tag, "=")
+		if !ok || key == "" {
+			s.writeError(w, http.StatusBadRequest, fmt.Sprintf("Invalid tag filter %q: must be Key=Value", tag))
+			return
+		}
+		if search.Tags == nil {
+			search.Tags = make(map[string]string)
+		}
+		search.Tags[key] = value
+	}
+	switch order := query.Get("order"); order {
+	case "", "asc":
+	case "desc":
+		search.Desc = true
+	default:
+		s.writeError(w, http.StatusBadRequest, fmt.Sprintf("Invalid order %q: must be asc or desc", order))
+		return
+	}
+
+	resources, total, err := GetGlobalResourceStore().Search(search)
+	if err != nil {
+		s.writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	s.writeJSON(w, http.StatusOK, ResourceSearchResponse{
+		Resources:  resources,
+		Total:      total,
+		Page:       page,
+		Limit:      limit,
+		TotalPages: (total + limit - 1) / limit,
+	})
+}
+
+// handleResourceGraph handles GET /api/v1/graph, returning the topology of the most recently
+// discovered resources the user may see as nodes and edges inferred from the references between
+// them, optionally limited to one provider or region
+func (s *Server) handleResourceGraph(w http.ResponseWriter, r *http.Request) {
+	provider := r.URL.Query().Get("provider")
+	region := r.URL.Query().Get("region")
+
+	resources := scopedResources(r.Context(), GetGlobalResourceStore().All())
+	filtered := resources[:0:0]
+	for _, resource := range resources {
+		if (provider == "" || resource.Provider == provider) && (region == "" || resource.Region == region) {
+			filtered = append(filtered, resource)
+		}
+	}
+
+	s.writeJSON(w, http.StatusOK, graph.BuildLiveGraph(filtered))
+}
+
+// scopedResources returns the resources whose provider account the authenticated user's scopes
+// cover. Requests without authentication, from admins, or from users with no scopes see all of them.
+func scopedResources(ctx context.Context, resources []models.Resource) []models.Resource {
+	allowed := resources[:0:0]
+	for _, resource := range resources {
+		if auth.CheckScope(ctx, resource.Provider, resource.AccountID) == nil {
+			allowed = append(allowed, resource)
+		}
+	}
+	return allowed
+}
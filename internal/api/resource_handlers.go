@@ -1,9 +1,9 @@
 package api
 
 import (
-	"encoding/json"
 	"fmt"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/catherinevee/driftmgr/internal/services"
@@ -69,21 +69,17 @@ func (h *ResourceHandlers) ListResources(w http.ResponseWriter, r *http.Request)
 		filtered = append(filtered, apiResource)
 	}
 
-	// Apply pagination
-	start := (page - 1) * limit
-	end := start + limit
-
-	if start >= len(filtered) {
-		filtered = []Resource{}
-	} else {
-		if end > len(filtered) {
-			end = len(filtered)
-		}
-		filtered = filtered[start:end]
+	// The service returned only the requested page; count every match for the page metadata
+	total, err := h.resourceService.CountResources(r.Context(), filters)
+	if err != nil {
+		response := NewResponseWriter(w)
+		response.WriteInternalError("Failed to count resources: " + err.Error())
+		return
 	}
+	w.Header().Set("X-Total-Count", strconv.Itoa(total))
 
 	response := NewResponseWriter(w)
-	err := response.WritePaginationResponse(filtered, page, limit, len(filtered))
+	err = response.WritePaginationResponse(filtered, page, limit, total)
 	if err != nil {
 		response.WriteInternalError("Failed to encode response")
 		return
@@ -139,77 +135,6 @@ func (h *ResourceHandlers) GetResource(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-// SearchResources handles GET /api/v1/resources/search
-func (h *ResourceHandlers) SearchResources(w http.ResponseWriter, r *http.Request) {
-	// Set common headers
-	SetCommonHeaders(w)
-
-	// Parse query parameters
-	queryParams := ParseQueryParams(r)
-	query := queryParams["q"]
-	page, limit := ParsePaginationParams(r)
-
-	if query == "" {
-		response := NewResponseWriter(w)
-		response.WriteValidationError("Search query parameter 'q' is required", "")
-		return
-	}
-
-	// Create search query for resource service
-	searchQuery := services.ResourceSearchQuery{
-		Query:  query,
-		Limit:  limit,
-		Offset: (page - 1) * limit,
-	}
-
-	// Use real service to search resources
-	resourceModels, err := h.resourceService.SearchResources(r.Context(), searchQuery)
-	if err != nil {
-		response := NewResponseWriter(w)
-		response.WriteInternalError("Failed to search resources: " + err.Error())
-		return
-	}
-
-	// Convert to API response format
-	results := make([]Resource, 0, len(resourceModels))
-	for _, resource := range resourceModels {
-		apiResource := Resource{
-			ID:           resource.ID,
-			Name:         resource.Name,
-			Type:         resource.Type,
-			Provider:     string(resource.Provider),
-			Region:       resource.Region,
-			AccountID:    resource.AccountID,
-			State:        "present", // This would need to be determined from resource state
-			Tags:         resource.Tags,
-			Metadata:     convertMetadataToStringMap(resource.Metadata),
-			DiscoveredAt: resource.LastDiscovered,
-			UpdatedAt:    resource.UpdatedAt,
-		}
-		results = append(results, apiResource)
-	}
-
-	// Apply pagination
-	start := (page - 1) * limit
-	end := start + limit
-
-	if start >= len(results) {
-		results = []Resource{}
-	} else {
-		if end > len(results) {
-			end = len(results)
-		}
-		results = results[start:end]
-	}
-
-	response := NewResponseWriter(w)
-	err := response.WritePaginationResponse(results, page, limit, len(results))
-	if err != nil {
-		response.WriteInternalError("Failed to encode response")
-		return
-	}
-}
-
 // UpdateResourceTags handles PUT /api/v1/resources/{id}/tags
 func (h *ResourceHandlers) UpdateResourceTags(w http.ResponseWriter, r *http.Request) {
 	// Set common headers
@@ -231,9 +156,9 @@ func (h *ResourceHandlers) UpdateResourceTags(w http.ResponseWriter, r *http.Req
 		Tags map[string]string `json:"tags"`
 	}
 
-	if err := json.NewDecoder(r.Body).Decode(&tagRequest); err != nil {
+	if err := decodeRequest(r, &tagRequest); err != nil {
 		response := NewResponseWriter(w)
-		response.WriteValidationError("Invalid request body", err.Error())
+		response.WriteRequestError(err)
 		return
 	}
 
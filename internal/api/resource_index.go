@@ -0,0 +1,219 @@
+package api
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/catherinevee/driftmgr/pkg/models"
+)
+
+// ResourceQuery filters and orders a search over discovered resources. Text matches words in
+// a resource's ID, name, type and tags; every word must match, as a prefix of an indexed word.
+// The other filters are exact and combined with AND.
+type ResourceQuery struct {
+	Text     string
+	Provider string
+	Type     string
+	Region   string
+	Tags     map[string]string
+	TagKeys  []string
+	Sort     string // id (default), name, type, region, provider or created
+	Desc     bool
+	Offset   int
+	Limit    int // zero means no limit
+
+	// Allow, when set, excludes resources it rejects before sorting and paging, so totals
+	// only count resources the caller may see
+	Allow func(resource *models.Resource) bool
+}
+
+// resourceSortFields are the fields search results can be sorted by
+var resourceSortFields = map[string]func(a, b *models.Resource) int{
+	"id": func(a, b *models.Resource) int { return strings.Compare(a.ID, b.ID) },
+	"name": func(a, b *models.Resource) int {
+		return strings.Compare(strings.ToLower(a.Name), strings.ToLower(b.Name))
+	},
+	"type":     func(a, b *models.Resource) int { return strings.Compare(a.Type, b.Type) },
+	"region":   func(a, b *models.Resource) int { return strings.Compare(a.Region, b.Region) },
+	"provider": func(a, b *models.Resource) int { return strings.Compare(a.Provider, b.Provider) },
+	"created": func(a, b *models.Resource) int {
+		created, _ := a.CreationTime()
+		other, _ := b.CreationTime()
+		return created.Compare(other)
+	},
+}
+
+// resourceIndex is an inverted index over a fixed set of resources. Posting lists hold
+// positions in resources in ascending order.
+type resourceIndex struct {
+	resources  []models.Resource
+	providers  map[string][]int
+	types      map[string][]int
+	regions    map[string][]int
+	tagKeys    map[string][]int
+	tagValues  map[string][]int // keyed by key=value
+	words      map[string][]int
+	vocabulary []string // the keys of words, sorted for prefix lookups
+}
+
+// newResourceIndex indexes resources by provider, type, region, tag and word
+func newResourceIndex(resources []models.Resource) *resourceIndex {
+	index := &resourceIndex{
+		resources: resources,
+		providers: make(map[string][]int),
+		types:     make(map[string][]int),
+		regions:   make(map[string][]int),
+		tagKeys:   make(map[string][]int),
+		tagValues: make(map[string][]int),
+		words:     make(map[string][]int),
+	}
+	for i, resource := range resources {
+		index.providers[resource.Provider] = append(index.providers[resource.Provider], i)
+		index.types[resource.Type] = append(index.types[resource.Type], i)
+		index.regions[resource.Region] = append(index.regions[resource.Region], i)
+
+		text := []string{resource.ID, resource.Name, resource.Type}
+		for key, value := range resource.Tags {
+			index.tagKeys[key] = append(index.tagKeys[key], i)
+			index.tagValues[key+"="+value] = append(index.tagValues[key+"="+value], i)
+			text = append(text, key, value)
+		}
+		seen := make(map[string]bool)
+		for _, word := range searchWords(strings.Join(text, " ")) {
+			if !seen[word] {
+				seen[word] = true
+				index.words[word] = append(index.words[word], i)
+			}
+		}
+	}
+	for word := range index.words {
+		index.vocabulary = append(index.vocabulary, word)
+	}
+	sort.Strings(index.vocabulary)
+	return index
+}
+
+// search returns the page of resources matching query and the total number of matches
+func (index *resourceIndex) search(query ResourceQuery) ([]models.Resource, int, error) {
+	compare, ok := resourceSortFields[query.Sort]
+	if query.Sort == "" {
+		compare, ok = resourceSortFields["id"], true
+	}
+	if !ok {
+		return nil, 0, fmt.Errorf("cannot sort by %q", query.Sort)
+	}
+
+	var postings [][]int
+	filters := []struct {
+		values map[string][]int
+		key    string
+	}{
+		{index.providers, query.Provider},
+		{index.types, query.Type},
+		{index.regions, query.Region},
+	}
+	for _, filter := range filters {
+		if filter.key != "" {
+			postings = append(postings, filter.values[filter.key])
+		}
+	}
+	for key, value := range query.Tags {
+		postings = append(postings, index.tagValues[key+"="+value])
+	}
+	for _, key := range query.TagKeys {
+		postings = append(postings, index.tagKeys[key])
+	}
+	for _, word := range searchWords(query.Text) {
+		postings = append(postings, index.prefixPostings(word))
+	}
+
+	var matches []int
+	if len(postings) == 0 {
+		matches = make([]int, len(index.resources))
+		for i := range matches {
+			matches[i] = i
+		}
+	} else {
+		matches = intersectPostings(postings)
+	}
+
+	results := make([]models.Resource, 0, len(matches))
+	for _, position := range matches {
+		if query.Allow == nil || query.Allow(&index.resources[position]) {
+			results = append(results, index.resources[position])
+		}
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		order := compare(&results[i], &results[j])
+		if order == 0 {
+			order = strings.Compare(results[i].Provider+"/"+results[i].ID, results[j].Provider+"/"+results[j].ID)
+		}
+		if query.Desc {
+			return order > 0
+		}
+		return order < 0
+	})
+
+	total := len(results)
+	if query.Offset >= total {
+		return []models.Resource{}, total, nil
+	}
+	results = results[query.Offset:]
+	if query.Limit > 0 && query.Limit < len(results) {
+		results = results[:query.Limit]
+	}
+	return results, total, nil
+}
+
+// prefixPostings returns the positions of resources with an indexed word starting with prefix
+func (index *resourceIndex) prefixPostings(prefix string) []int {
+	start := sort.SearchStrings(index.vocabulary, prefix)
+	found := make(map[int]bool)
+	for _, word := range index.vocabulary[start:] {
+		if !strings.HasPrefix(word, prefix) {
+			break
+		}
+		for _, position := range index.words[word] {
+			found[position] = true
+		}
+	}
+	positions := make([]int, 0, len(found))
+	for position := range found {
+		positions = append(positions, position)
+	}
+	sort.Ints(positions)
+	return positions
+}
+
+// intersectPostings returns the positions present in every posting list
+func intersectPostings(postings [][]int) []int {
+	sort.Slice(postings, func(i, j int) bool { return len(postings[i]) < len(postings[j]) })
+	result := postings[0]
+	for _, list := range postings[1:] {
+		var merged []int
+		for i, j := 0, 0; i < len(result) && j < len(list); {
+			switch {
+			case result[i] == list[j]:
+				merged = append(merged, result[i])
+				i++
+				j++
+			case result[i] < list[j]:
+				i++
+			default:
+				j++
+			}
+		}
+		result = merged
+	}
+	return result
+}
+
+// searchWords lower-cases text and splits it into words at anything other than letters and
+// digits, so i-0abc, web-server_01 and arn:aws:s3:::logs are searchable by their parts
+func searchWords(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
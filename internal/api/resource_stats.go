@@ -0,0 +1,71 @@
+package api
+
+import (
+	"net/http"
+	"sort"
+
+	"github.com/catherinevee/driftmgr/pkg/models"
+)
+
+// ResourceStatsResponse counts the most recently discovered resources across clouds by
+// provider-neutral category, so compute is one figure whether it runs on AWS, Azure or GCP
+type ResourceStatsResponse struct {
+	Total      int             `json:"total"`
+	ByProvider map[string]int  `json:"by_provider"`
+	Categories []CategoryStats `json:"categories"`
+}
+
+// CategoryStats counts one category's resources, split by provider and native type
+type CategoryStats struct {
+	Category   models.ResourceCategory `json:"category"`
+	Total      int                     `json:"total"`
+	ByProvider map[string]int          `json:"by_provider"`
+	ByType     map[string]int          `json:"by_type"`
+}
+
+// handleResourceStats handles GET /api/v1/resources/stats, counting the most recently
+// discovered resources the user's scopes allow by category, largest first, optionally for
+// one provider
+func (s *Server) handleResourceStats(w http.ResponseWriter, r *http.Request) {
+	s.writeJSON(w, http.StatusOK, resourceStats(liveResources(r, r.URL.Query().Get("provider"))))
+}
+
+// resourceStats counts resources by category, provider and type
+func resourceStats(resources []models.Resource) ResourceStatsResponse {
+	response := ResourceStatsResponse{
+		ByProvider: make(map[string]int),
+		Categories: []CategoryStats{},
+	}
+	categories := make(map[models.ResourceCategory]*CategoryStats)
+	for _, resource := range resources {
+		category := resource.Category
+		if category == "" {
+			category = models.CategorizeResourceType(resource.Type)
+		}
+		stats, ok := categories[category]
+		if !ok {
+			stats = &CategoryStats{
+				Category:   category,
+				ByProvider: make(map[string]int),
+				ByType:     make(map[string]int),
+			}
+			categories[category] = stats
+		}
+		stats.Total++
+		stats.ByProvider[resource.Provider]++
+		stats.ByType[resource.Type]++
+		response.ByProvider[resource.Provider]++
+		response.Total++
+	}
+
+	for _, stats := range categories {
+		response.Categories = append(response.Categories, *stats)
+	}
+	sort.Slice(response.Categories, func(i, j int) bool {
+		if response.Categories[i].Total != response.Categories[j].Total {
+			return response.Categories[i].Total > response.Categories[j].Total
+		}
+		return response.Categories[i].Category < response.Categories[j].Category
+	})
+	return response
+}
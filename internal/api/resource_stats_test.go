@@ -0,0 +1,27 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/catherinevee/driftmgr/pkg/models"
+)
+
+func TestResourceStats(t *testing.T) {
+	stats := resourceStats([]models.Resource{
+		{ID: "i-1", Provider: "aws", Type: "aws_instance"},
+		{ID: "i-2", Provider: "aws", Type: "aws_instance"},
+		{ID: "vm-1", Provider: "azure", Type: "azurerm_virtual_machine", Category: models.CategoryCompute},
+		{ID: "bucket-1", Provider: "gcp", Type: "google_storage_bucket"},
+	})
+
+	if stats.Total != 4 || stats.ByProvider["aws"] != 2 {
+		t.Errorf("expected 4 resources with 2 in aws, got %+v", stats)
+	}
+	if len(stats.Categories) != 2 {
+		t.Fatalf("expected compute and storage, got %+v", stats.Categories)
+	}
+	compute := stats.Categories[0]
+	if compute.Category != models.CategoryCompute || compute.Total != 3 || compute.ByProvider["azure"] != 1 || compute.ByType["aws_instance"] != 2 {
+		t.Errorf("expected 3 compute resources across clouds first, got %+v", compute)
+	}
+}
@@ -0,0 +1,215 @@
+package api
+
+import (
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/catherinevee/driftmgr/pkg/models"
+)
+
+// ResourceSnapshot is the result of one discovery run for a scope
+type ResourceSnapshot struct {
+	Scope        string
+	Resources    []models.Resource
+	DiscoveredAt time.Time
+}
+
+// maxScopeSnapshots bounds the snapshot history kept per scope; older snapshots are
+// dropped first, as are those older than snapshotRetention
+const maxScopeSnapshots = 48
+
+// snapshotRetention is how long snapshots are kept for comparison, matching drift history
+const snapshotRetention = driftHistoryRetention
+
+// ResourceStore keeps the recent discovery snapshots per scope (provider, regions and account)
+// so later runs can report deltas and earlier runs can be compared
+type ResourceStore struct {
+	snapshots map[string][]*ResourceSnapshot // oldest first
+	index     *resourceIndex                 // built on the first search after a save
+	mu        sync.RWMutex
+}
+
+var (
+	globalResourceStore *ResourceStore
+	resourceStoreOnce   sync.Once
+)
+
+// GetGlobalResourceStore returns the global resource store instance
+func GetGlobalResourceStore() *ResourceStore {
+	resourceStoreOnce.Do(func() {
+		globalResourceStore = &ResourceStore{
+			snapshots: make(map[string][]*ResourceSnapshot),
+		}
+	})
+	return globalResourceStore
+}
+
+// Last returns the most recent snapshot for a scope
+func (rs *ResourceStore) Last(scope string) (*ResourceSnapshot, bool) {
+	rs.mu.RLock()
+	defer rs.mu.RUnlock()
+	history := rs.snapshots[scope]
+	if len(history) == 0 {
+		return nil, false
+	}
+	return history[len(history)-1], true
+}
+
+// At returns the most recent snapshot for a scope taken at or before t
+func (rs *ResourceStore) At(scope string, t time.Time) (*ResourceSnapshot, bool) {
+	rs.mu.RLock()
+	defer rs.mu.RUnlock()
+	history := rs.snapshots[scope]
+	i := sort.Search(len(history), func(i int) bool { return history[i].DiscoveredAt.After(t) })
+	if i == 0 {
+		return nil, false
+	}
+	return history[i-1], true
+}
+
+// Scopes returns the scopes with at least one snapshot, sorted
+func (rs *ResourceStore) Scopes() []string {
+	rs.mu.RLock()
+	defer rs.mu.RUnlock()
+	scopes := make([]string, 0, len(rs.snapshots))
+	for scope, history := range rs.snapshots {
+		if len(history) > 0 {
+			scopes = append(scopes, scope)
+		}
+	}
+	sort.Strings(scopes)
+	return scopes
+}
+
+// Save records a snapshot for a scope, making it the scope's latest, and drops the scope's
+// snapshots beyond maxScopeSnapshots or older than snapshotRetention. Resources without a
+// category are given one from their type.
+func (rs *ResourceStore) Save(scope string, resources []models.Resource, discoveredAt time.Time) {
+	models.CategorizeResources(resources)
+
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	history := rs.snapshots[scope]
+	snapshot := &ResourceSnapshot{
+		Scope:        scope,
+		Resources:    resources,
+		DiscoveredAt: discoveredAt,
+	}
+	i := sort.Search(len(history), func(i int) bool { return history[i].DiscoveredAt.After(discoveredAt) })
+	history = append(history, nil)
+	copy(history[i+1:], history[i:])
+	history[i] = snapshot
+
+	// The latest snapshot is always kept, however old, so deltas still have a baseline
+	cutoff := time.Now().Add(-snapshotRetention)
+	drop := 0
+	for drop < len(history)-1 && (len(history)-drop > maxScopeSnapshots || history[drop].DiscoveredAt.Before(cutoff)) {
+		drop++
+	}
+	history = append([]*ResourceSnapshot(nil), history[drop:]...)
+
+	rs.snapshots[scope] = history
+	rs.index = nil
+}
+
+// Search finds resources across every snapshot, returning the requested page and the total
+// number of matches
+func (rs *ResourceStore) Search(query ResourceQuery) ([]models.Resource, int, error) {
+	rs.mu.Lock()
+	if rs.index == nil {
+		rs.index = newResourceIndex(rs.all())
+	}
+	index := rs.index
+	rs.mu.Unlock()
+
+	return index.search(query)
+}
+
+// All returns the resources of every snapshot, keeping one copy of resources that appear in
+// several scopes, taken from the most recent snapshot
+func (rs *ResourceStore) All() []models.Resource {
+	rs.mu.RLock()
+	defer rs.mu.RUnlock()
+	return rs.all()
+}
+
+// all implements All. rs.mu must be held.
+func (rs *ResourceStore) all() []models.Resource {
+	snapshots := make([]*ResourceSnapshot, 0, len(rs.snapshots))
+	for _, history := range rs.snapshots {
+		if len(history) > 0 {
+			snapshots = append(snapshots, history[len(history)-1])
+		}
+	}
+	sort.Slice(snapshots, func(i, j int) bool {
+		return snapshots[i].DiscoveredAt.After(snapshots[j].DiscoveredAt)
+	})
+
+	seen := make(map[string]bool)
+	var resources []models.Resource
+	for _, snapshot := range snapshots {
+		for _, resource := range snapshot.Resources {
+			key := resource.Provider + "/" + resource.ID
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			resources = append(resources, resource)
+		}
+	}
+	return resources
+}
+
+// DiffResources compares two discovery runs by resource ID. A resource counts as changed
+// when its configuration differs; discovery and modification timestamps are ignored.
+func DiffResources(previous, current []models.Resource) ResourceDelta {
+	delta := ResourceDelta{
+		Added:   []models.Resource{},
+		Removed: []models.Resource{},
+		Changed: []models.Resource{},
+	}
+
+	before := make(map[string]models.Resource, len(previous))
+	for _, resource := range previous {
+		before[resource.ID] = resource
+	}
+
+	seen := make(map[string]bool, len(current))
+	for _, resource := range current {
+		seen[resource.ID] = true
+		old, existed := before[resource.ID]
+		switch {
+		case !existed:
+			delta.Added = append(delta.Added, resource)
+		case !sameConfiguration(old, resource):
+			delta.Changed = append(delta.Changed, resource)
+		}
+	}
+
+	for _, resource := range previous {
+		if !seen[resource.ID] {
+			delta.Removed = append(delta.Removed, resource)
+		}
+	}
+
+	for _, resources := range [][]models.Resource{delta.Added, delta.Removed, delta.Changed} {
+		sort.Slice(resources, func(i, j int) bool { return resources[i].ID < resources[j].ID })
+	}
+
+	return delta
+}
+
+// sameConfiguration reports whether two resources match, ignoring timestamps and cost estimates
+func sameConfiguration(a, b models.Resource) bool {
+	for _, r := range []*models.Resource{&a, &b} {
+		r.Created = time.Time{}
+		r.Updated = time.Time{}
+		r.CreatedAt = time.Time{}
+		r.LastModified = time.Time{}
+		r.CostEstimate = nil
+	}
+	return reflect.DeepEqual(a, b)
+}
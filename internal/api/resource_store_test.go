@@ -0,0 +1,203 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/catherinevee/driftmgr/internal/drift/detector"
+	"github.com/catherinevee/driftmgr/pkg/models"
+)
+
+func TestDiffResources(t *testing.T) {
+	previous := []models.Resource{
+		{ID: "bucket-1", Type: "aws_s3_bucket", Status: "active", LastModified: time.Now().Add(-time.Hour)},
+		{ID: "bucket-2", Type: "aws_s3_bucket", Status: "active"},
+		{ID: "instance-1", Type: "aws_instance", Attributes: map[string]interface{}{"instance_type": "t3.micro"}},
+	}
+	current := []models.Resource{
+		{ID: "bucket-1", Type: "aws_s3_bucket", Status: "active", LastModified: time.Now()},
+		{ID: "instance-1", Type: "aws_instance", Attributes: map[string]interface{}{"instance_type": "t3.large"}},
+		{ID: "bucket-3", Type: "aws_s3_bucket", Status: "active"},
+	}
+
+	delta := DiffResources(previous, current)
+
+	if len(delta.Added) != 1 || delta.Added[0].ID != "bucket-3" {
+		t.Errorf("expected bucket-3 to be added, got %v", delta.Added)
+	}
+	if len(delta.Removed) != 1 || delta.Removed[0].ID != "bucket-2" {
+		t.Errorf("expected bucket-2 to be removed, got %v", delta.Removed)
+	}
+	if len(delta.Changed) != 1 || delta.Changed[0].ID != "instance-1" {
+		t.Errorf("expected only instance-1 to be changed, got %v", delta.Changed)
+	}
+}
+
+func TestDiffResourcesFirstRun(t *testing.T) {
+	delta := DiffResources(nil, []models.Resource{{ID: "bucket-1"}})
+
+	if len(delta.Added) != 1 || len(delta.Removed) != 0 || len(delta.Changed) != 0 {
+		t.Errorf("expected every resource to be added on the first run, got %+v", delta)
+	}
+}
+
+func TestResourceStoreAll(t *testing.T) {
+	store := &ResourceStore{snapshots: make(map[string][]*ResourceSnapshot)}
+	now := time.Now()
+	store.Save("aws:us-east-1", []models.Resource{{ID: "i-1", Provider: "aws", Status: "stopped"}}, now.Add(-time.Hour))
+	store.Save("aws:us-east-1:tags", []models.Resource{{ID: "i-1", Provider: "aws", Status: "running"}}, now)
+	store.Save("azure:eastus", []models.Resource{{ID: "vm-1", Provider: "azure"}}, now)
+
+	resources := store.All()
+	if len(resources) != 2 {
+		t.Fatalf("expected 2 distinct resources, got %d", len(resources))
+	}
+	for _, resource := range resources {
+		if resource.ID == "i-1" && resource.Status != "running" {
+			t.Errorf("expected the most recent copy of i-1, got %s", resource.Status)
+		}
+	}
+}
+
+func TestParseResourceID(t *testing.T) {
+	if provider, id := parseResourceID("aws/i-0abc"); provider != "aws" || id != "i-0abc" {
+		t.Errorf("expected aws and i-0abc, got %q and %q", provider, id)
+	}
+	azureID := "/subscriptions/1/resourceGroups/rg"
+	if provider, id := parseResourceID(azureID); provider != "" || id != azureID {
+		t.Errorf("expected an unqualified Azure ID to be kept whole, got %q and %q", provider, id)
+	}
+}
+
+func TestResourceDetailLookup(t *testing.T) {
+	inventory := []models.Resource{
+		{ID: "web", Provider: "aws", Dependencies: []string{"vpc-1"}},
+		{ID: "web", Provider: "gcp"},
+		{ID: "vpc-1", Provider: "aws"},
+		{ID: "AROAEXAMPLE", Provider: "aws", Properties: map[string]interface{}{"arn": "arn:aws:iam::1:role/ci"}},
+	}
+	if matches := findResources(inventory, "", "web"); len(matches) != 2 {
+		t.Errorf("expected a bare ID to match both providers, got %d", len(matches))
+	}
+	if matches := findResources(inventory, "gcp", "web"); len(matches) != 1 || matches[0].Provider != "gcp" {
+		t.Errorf("expected only the gcp resource, got %v", matches)
+	}
+	if matches := findResources(inventory, "", "arn:aws:iam::1:role/ci"); len(matches) != 1 {
+		t.Errorf("expected the role to be found by ARN, got %v", matches)
+	}
+	if dependents := resourceDependents(inventory, "vpc-1"); len(dependents) != 1 || dependents[0] != "web" {
+		t.Errorf("expected web to depend on vpc-1, got %v", dependents)
+	}
+
+	older := &detector.DriftResult{ActualState: map[string]interface{}{"id": "vpc-1"}, Timestamp: time.Now().Add(-time.Hour)}
+	newer := &detector.DriftResult{DesiredState: map[string]interface{}{"id": "vpc-1"}, Timestamp: time.Now()}
+	other := &detector.DriftResult{ActualState: map[string]interface{}{"id": "web"}}
+	drift := resourceDrift([]*detector.DriftResult{older, other, newer}, "vpc-1")
+	if len(drift) != 2 || drift[0] != newer || drift[1] != older {
+		t.Errorf("expected the two vpc-1 results newest first, got %v", drift)
+	}
+}
+
+func TestResourceStoreSearch(t *testing.T) {
+	store := &ResourceStore{snapshots: make(map[string][]*ResourceSnapshot)}
+	now := time.Now()
+	store.Save("aws:us-east-1", []models.Resource{
+		{ID: "i-0abc", Name: "web-server-01", Type: "aws_instance", Provider: "aws", Region: "us-east-1",
+			Tags: map[string]string{"Environment": "production", "Team": "payments"}, Created: now.Add(-2 * time.Hour)},
+		{ID: "i-0def", Name: "web-server-02", Type: "aws_instance", Provider: "aws", Region: "us-east-1",
+			Tags: map[string]string{"Environment": "staging"}, Created: now.Add(-time.Hour)},
+		{ID: "logs", Name: "logs", Type: "aws_s3_bucket", Provider: "aws", Region: "us-east-1"},
+	}, now)
+	store.Save("azure:eastus", []models.Resource{
+		{ID: "vm-web", Name: "web-vm", Type: "azurerm_virtual_machine", Provider: "azure", Region: "eastus",
+			Tags: map[string]string{"Environment": "production"}},
+	}, now)
+
+	results, total, err := store.Search(ResourceQuery{Text: "web"})
+	if err != nil || total != 3 {
+		t.Fatalf("expected 3 resources matching web, got %d (%v)", total, err)
+	}
+
+	results, total, _ = store.Search(ResourceQuery{Text: "WEB prod", Provider: "aws"})
+	if total != 1 || results[0].ID != "i-0abc" {
+		t.Errorf("expected every word to match, got %v", results)
+	}
+
+	results, total, _ = store.Search(ResourceQuery{Tags: map[string]string{"Environment": "production"}, Sort: "name", Desc: true})
+	if total != 2 || results[0].ID != "vm-web" || results[1].ID != "i-0abc" {
+		t.Errorf("expected production resources sorted by name descending, got %v", results)
+	}
+
+	results, total, _ = store.Search(ResourceQuery{Type: "aws_instance", Sort: "created", Offset: 1, Limit: 1})
+	if total != 2 || len(results) != 1 || results[0].ID != "i-0def" {
+		t.Errorf("expected the second page of instances by creation time, got %d %v", total, results)
+	}
+
+	if _, _, err := store.Search(ResourceQuery{Sort: "cost"}); err == nil {
+		t.Error("expected an unknown sort field to be rejected")
+	}
+
+	// Saving a new snapshot rebuilds the index
+	store.Save("aws:us-east-1", []models.Resource{{ID: "i-0new", Provider: "aws"}}, now)
+	if _, total, _ := store.Search(ResourceQuery{Provider: "aws"}); total != 1 {
+		t.Errorf("expected the index to reflect the new snapshot, got %d resources", total)
+	}
+}
+
+func TestHandleSearchResourcesFiltersByScope(t *testing.T) {
+	store := GetGlobalResourceStore()
+	store.Save("scope-test", []models.Resource{
+		{ID: "i-allowed", Provider: "aws", AccountID: "111111111111"},
+		{ID: "i-hidden", Provider: "aws", AccountID: "222222222222"},
+	}, time.Now())
+	t.Cleanup(func() { store.Save("scope-test", nil, time.Now()) })
+	server := &Server{config: &Config{}}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/resources/search?q=i&limit=1", nil)
+	ctx := context.WithValue(req.Context(), "scopes", []string{"aws:111111111111"})
+	w := httptest.NewRecorder()
+	server.handleSearchResources(w, req.WithContext(ctx))
+
+	var response ResourceSearchResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("failed to decode response %s: %v", w.Body.String(), err)
+	}
+	if response.Total != 1 || len(response.Resources) != 1 || response.Resources[0].ID != "i-allowed" {
+		t.Errorf("expected only i-allowed to be found, got %d: %+v", response.Total, response.Resources)
+	}
+}
+
+func TestResourceStoreHistory(t *testing.T) {
+	store := &ResourceStore{snapshots: make(map[string][]*ResourceSnapshot)}
+	now := time.Now()
+	store.Save("aws:us-east-1", []models.Resource{{ID: "i-2"}}, now.Add(-time.Hour))
+	store.Save("aws:us-east-1", []models.Resource{{ID: "i-1"}}, now.Add(-2*time.Hour))
+	store.Save("aws:us-east-1", []models.Resource{{ID: "i-3"}}, now)
+
+	if last, ok := store.Last("aws:us-east-1"); !ok || last.Resources[0].ID != "i-3" {
+		t.Fatalf("expected the latest snapshot to hold i-3, got %+v", last)
+	}
+	if snapshot, ok := store.At("aws:us-east-1", now.Add(-90*time.Minute)); !ok || snapshot.Resources[0].ID != "i-1" {
+		t.Errorf("expected the snapshot before the given time to hold i-1, got %+v", snapshot)
+	}
+	if _, ok := store.At("aws:us-east-1", now.Add(-3*time.Hour)); ok {
+		t.Error("expected no snapshot before the first one")
+	}
+
+	for i := 0; i < maxScopeSnapshots+5; i++ {
+		store.Save("aws:us-east-1", nil, now.Add(time.Duration(i+1)*time.Minute))
+	}
+	if len(store.snapshots["aws:us-east-1"]) != maxScopeSnapshots {
+		t.Errorf("expected history to be capped at %d snapshots, got %d", maxScopeSnapshots, len(store.snapshots["aws:us-east-1"]))
+	}
+
+	store.Save("azure:eastus", nil, now.Add(-snapshotRetention-time.Hour))
+	store.Save("azure:eastus", nil, now.Add(-snapshotRetention-time.Minute))
+	if len(store.snapshots["azure:eastus"]) != 1 {
+		t.Errorf("expected expired snapshots to be dropped but the latest kept, got %d", len(store.snapshots["azure:eastus"]))
+	}
+}
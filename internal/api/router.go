@@ -49,9 +49,16 @@ func (r *Router) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 		return
 	}
 
-	// Find matching route
+	// Exact routes take precedence over patterns such as /api/v1/resources/{id}
+	if handler, ok := methodRoutes[req.URL.Path]; ok {
+		handler(w, req)
+		return
+	}
+
+	// Find matching route. Parameters are matched escaped so an encoded slash, as in an ARN,
+	// stays within its segment.
 	for pattern, handler := range methodRoutes {
-		if r.matchRoute(pattern, req.URL.Path) {
+		if r.matchRoute(pattern, req.URL.EscapedPath()) {
 			handler(w, req)
 			return
 		}
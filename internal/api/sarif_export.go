@@ -0,0 +1,292 @@
+package api
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/catherinevee/driftmgr/internal/drift/detector"
+	"github.com/catherinevee/driftmgr/internal/version"
+)
+
+const (
+	sarifVersion     = "2.1.0"
+	sarifSchema      = "https://json.schemastore.org/sarif-2.1.0.json"
+	sarifContentType = "application/sarif+json"
+	// sarifFingerprint keys the partial fingerprint code scanning matches results across
+	// uploads by, so a drift that persists is one alert rather than one per export
+	sarifFingerprint = "driftmgrResource/v1"
+)
+
+// sarifLevels maps drift severities to SARIF result levels
+var sarifLevels = map[detector.DriftSeverity]string{
+	detector.SeverityLow:      "note",
+	detector.SeverityMedium:   "warning",
+	detector.SeverityHigh:     "error",
+	detector.SeverityCritical: "error",
+}
+
+// listIndex matches the list indices of a field path, such as [0] in ingress[0].cidr_blocks
+var listIndex = regexp.MustCompile(`\[\d+\]`)
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	Version        string      `json:"version,omitempty"`
+	InformationURI string      `json:"informationUri,omitempty"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID                   string             `json:"id"`
+	ShortDescription     sarifMessage       `json:"shortDescription"`
+	DefaultConfiguration sarifConfiguration `json:"defaultConfiguration"`
+	Properties           map[string]string  `json:"properties,omitempty"`
+}
+
+type sarifConfiguration struct {
+	Level string `json:"level"`
+}
+
+type sarifResult struct {
+	RuleID              string                 `json:"ruleId"`
+	RuleIndex           int                    `json:"ruleIndex"`
+	Level               string                 `json:"level"`
+	Message             sarifMessage           `json:"message"`
+	Locations           []sarifLocation        `json:"locations"`
+	PartialFingerprints map[string]string      `json:"partialFingerprints"`
+	Properties          map[string]interface{} `json:"properties,omitempty"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation  `json:"physicalLocation"`
+	LogicalLocations []sarifLogicalLocation `json:"logicalLocations"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifLogicalLocation struct {
+	FullyQualifiedName string `json:"fullyQualifiedName"`
+	Kind               string `json:"kind"`
+}
+
+// renderDriftSARIF renders drift results as a SARIF 2.1.0 log for code scanning. Each field
+// that drifted is a result of the rule <resource type>/<field>, and a resource that is missing,
+// unmanaged or orphaned is a result of the rule <resource type>/<drift type>. Drift has no
+// source file, so results are located at the resource: its address is the logical location,
+// and <provider>/<resource> the artifact URI.
+func renderDriftSARIF(ids []string, drifts map[string]*detector.DriftResult) ([]byte, error) {
+	run := sarifRun{
+		Tool: sarifTool{Driver: sarifDriver{
+			Name:           "driftmgr",
+			Version:        version.Version,
+			InformationURI: "https://github.com/catherinevee/driftmgr",
+			Rules:          []sarifRule{},
+		}},
+		Results: []sarifResult{},
+	}
+	ruleIndex := make(map[string]int)
+	rule := func(id, description string, severity detector.DriftSeverity, resourceType string) int {
+		if index, ok := ruleIndex[id]; ok {
+			// A rule's default level is the most severe of its results
+			if level := sarifLevels[severity]; levelRank(level) > levelRank(run.Tool.Driver.Rules[index].DefaultConfiguration.Level) {
+				run.Tool.Driver.Rules[index].DefaultConfiguration.Level = level
+			}
+			return index
+		}
+		ruleIndex[id] = len(run.Tool.Driver.Rules)
+		run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, sarifRule{
+			ID:                   id,
+			ShortDescription:     sarifMessage{Text: description},
+			DefaultConfiguration: sarifConfiguration{Level: sarifLevels[severity]},
+			Properties:           map[string]string{"resource_type": resourceType},
+		})
+		return ruleIndex[id]
+	}
+
+	for _, id := range ids {
+		drift := drifts[id]
+		location := sarifLocation{
+			PhysicalLocation: sarifPhysicalLocation{ArtifactLocation: sarifArtifactLocation{
+				URI: url.PathEscape(drift.Provider) + "/" + url.PathEscape(drift.Resource),
+			}},
+			LogicalLocations: []sarifLogicalLocation{{FullyQualifiedName: drift.Resource, Kind: "resource"}},
+		}
+		result := func(ruleID, description, message string, properties map[string]interface{}) {
+			properties["drift_id"] = id
+			properties["provider"] = drift.Provider
+			properties["severity"] = driftSeverityNames[drift.Severity]
+			fingerprint := sha256.Sum256([]byte(drift.Provider + "\x00" + drift.Resource + "\x00" + ruleID))
+			run.Results = append(run.Results, sarifResult{
+				RuleID:              ruleID,
+				RuleIndex:           rule(ruleID, description, drift.Severity, drift.ResourceType),
+				Level:               sarifLevels[drift.Severity],
+				Message:             sarifMessage{Text: message},
+				Locations:           []sarifLocation{location},
+				PartialFingerprints: map[string]string{sarifFingerprint: hex.EncodeToString(fingerprint[:])},
+				Properties:          properties,
+			})
+		}
+
+		if drift.DriftType != detector.ConfigurationDrift || len(drift.Differences) == 0 {
+			kind := driftTypeNames[drift.DriftType]
+			message := fmt.Sprintf("%s %s is %s", drift.ResourceType, drift.Resource, kind)
+			if drift.Recommendation != "" {
+				message += ". " + drift.Recommendation
+			}
+			result(drift.ResourceType+"/"+kind, fmt.Sprintf("%s resource is %s", drift.ResourceType, kind),
+				message, map[string]interface{}{})
+			continue
+		}
+		for _, difference := range drift.Differences {
+			field := listIndex.ReplaceAllString(difference.Path, "")
+			message := difference.Message
+			if message == "" {
+				message = fmt.Sprintf("%s drifted", difference.Path)
+			}
+			result(drift.ResourceType+"/"+field, fmt.Sprintf("%s field %s drifted from its desired state", drift.ResourceType, field),
+				fmt.Sprintf("%s %s: %s", drift.ResourceType, drift.Resource, message),
+				map[string]interface{}{"field": difference.Path})
+		}
+	}
+
+	data, err := json.MarshalIndent(sarifLog{Schema: sarifSchema, Version: sarifVersion, Runs: []sarifRun{run}}, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal SARIF log: %w", err)
+	}
+	if err := validateSARIF(data); err != nil {
+		return nil, fmt.Errorf("invalid SARIF log: %w", err)
+	}
+	return data, nil
+}
+
+// levelRank orders SARIF levels by severity
+func levelRank(level string) int {
+	switch level {
+	case "error":
+		return 3
+	case "warning":
+		return 2
+	case "note":
+		return 1
+	default:
+		return 0
+	}
+}
+
+// validateSARIF checks a SARIF log against the constraints of the SARIF 2.1.0 schema that
+// code scanning relies on: the version, a named tool driver with unique rule IDs, and results
+// with a message, a known level, a rule index that names their rule, and locations whose
+// artifact URIs are URI references
+func validateSARIF(data []byte) error {
+	var log struct {
+		Version *string `json:"version"`
+		Runs    *[]struct {
+			Tool *struct {
+				Driver *struct {
+					Name  string `json:"name"`
+					Rules []struct {
+						ID string `json:"id"`
+					} `json:"rules"`
+				} `json:"driver"`
+			} `json:"tool"`
+			Results []struct {
+				RuleID    string `json:"ruleId"`
+				RuleIndex *int   `json:"ruleIndex"`
+				Level     string `json:"level"`
+				Message   *struct {
+					Text *string `json:"text"`
+				} `json:"message"`
+				Locations []struct {
+					PhysicalLocation *struct {
+						ArtifactLocation *struct {
+							URI string `json:"uri"`
+						} `json:"artifactLocation"`
+					} `json:"physicalLocation"`
+				} `json:"locations"`
+			} `json:"results"`
+		} `json:"runs"`
+	}
+	if err := json.Unmarshal(data, &log); err != nil {
+		return err
+	}
+	if log.Version == nil || *log.Version != sarifVersion {
+		return fmt.Errorf("version must be %s", sarifVersion)
+	}
+	if log.Runs == nil {
+		return errors.New("runs is required")
+	}
+
+	var errs []string
+	for r, run := range *log.Runs {
+		if run.Tool == nil || run.Tool.Driver == nil || run.Tool.Driver.Name == "" {
+			errs = append(errs, fmt.Sprintf("runs[%d].tool.driver.name is required", r))
+			continue
+		}
+		rules := run.Tool.Driver.Rules
+		seen := make(map[string]bool)
+		for i, rule := range rules {
+			if rule.ID == "" || seen[rule.ID] {
+				errs = append(errs, fmt.Sprintf("runs[%d].tool.driver.rules[%d].id must be unique and non-empty", r, i))
+			}
+			seen[rule.ID] = true
+		}
+		for i, result := range run.Results {
+			path := fmt.Sprintf("runs[%d].results[%d]", r, i)
+			if result.Message == nil || result.Message.Text == nil {
+				errs = append(errs, path+".message.text is required")
+			}
+			if result.Level != "" && levelRank(result.Level) == 0 && result.Level != "none" {
+				errs = append(errs, fmt.Sprintf("%s.level %q is not a SARIF level", path, result.Level))
+			}
+			if result.RuleIndex != nil {
+				if *result.RuleIndex < 0 || *result.RuleIndex >= len(rules) {
+					errs = append(errs, path+".ruleIndex is out of range")
+				} else if result.RuleID != "" && rules[*result.RuleIndex].ID != result.RuleID {
+					errs = append(errs, path+".ruleIndex does not name its ruleId")
+				}
+			}
+			for l, location := range result.Locations {
+				if location.PhysicalLocation == nil || location.PhysicalLocation.ArtifactLocation == nil {
+					continue
+				}
+				if _, err := url.Parse(location.PhysicalLocation.ArtifactLocation.URI); err != nil {
+					errs = append(errs, fmt.Sprintf("%s.locations[%d] uri is not a URI reference: %v", path, l, err))
+				}
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, "; "))
+	}
+	return nil
+}
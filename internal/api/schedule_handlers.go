@@ -0,0 +1,156 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/catherinevee/driftmgr/internal/auth"
+	"github.com/catherinevee/driftmgr/internal/scheduler"
+)
+
+// scheduleProviders lists the providers scheduled scans can discover
+var scheduleProviders = map[string]bool{
+	"aws":   true,
+	"azure": true,
+	"gcp":   true,
+}
+
+// ScheduleHandlers handles recurring drift scan API endpoints
+type ScheduleHandlers struct {
+	scheduler *scheduler.Scheduler
+}
+
+// NewScheduleHandlers creates a new ScheduleHandlers instance
+func NewScheduleHandlers(scanScheduler *scheduler.Scheduler) *ScheduleHandlers {
+	return &ScheduleHandlers{
+		scheduler: scanScheduler,
+	}
+}
+
+// CreateSchedule handles POST /api/v1/schedules
+func (h *ScheduleHandlers) CreateSchedule(w http.ResponseWriter, r *http.Request) {
+	// Set common headers
+	SetCommonHeaders(w)
+
+	var scheduleRequest ScheduleRequest
+	if err := decodeRequest(r, &scheduleRequest); err != nil {
+		response := NewResponseWriter(w)
+		response.WriteRequestError(err)
+		return
+	}
+
+	// Remediation policies applied after each scan act as the creator, within their scopes
+	var scopes []string
+	if isAdmin, _ := auth.GetIsAdminFromContext(r.Context()); !isAdmin {
+		scopes, _ = auth.GetScopesFromContext(r.Context())
+	}
+	schedule, err := h.scheduler.Create(&scheduler.Schedule{
+		Name:             scheduleRequest.Name,
+		Provider:         scheduleRequest.Provider,
+		CronExpression:   scheduleRequest.CronExpression,
+		DriftThreshold:   scheduleRequest.DriftThreshold,
+		CreatedBy:        requestUser(r),
+		Scopes:           scopes,
+		RemediationAdmin: requestHasPermission(r, auth.PermissionRemediationAdmin),
+	})
+	if err != nil {
+		response := NewResponseWriter(w)
+		response.WriteValidationError("Invalid schedule", err.Error())
+		return
+	}
+
+	response := NewResponseWriter(w)
+	response.WriteCreated(schedule)
+}
+
+// ListSchedules handles GET /api/v1/schedules
+func (h *ScheduleHandlers) ListSchedules(w http.ResponseWriter, r *http.Request) {
+	// Set common headers
+	SetCommonHeaders(w)
+
+	schedules := h.scheduler.List()
+
+	response := NewResponseWriter(w)
+	response.WriteSuccess(schedules, &APIMeta{
+		Count:     len(schedules),
+		Timestamp: time.Now().UTC().Format(time.RFC3339),
+	})
+}
+
+// GetSchedule handles GET /api/v1/schedules/{id}
+func (h *ScheduleHandlers) GetSchedule(w http.ResponseWriter, r *http.Request) {
+	// Set common headers
+	SetCommonHeaders(w)
+
+	scheduleID, ok := scheduleIDFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	schedule, err := h.scheduler.Get(scheduleID)
+	if err != nil {
+		response := NewResponseWriter(w)
+		response.WriteNotFound("Schedule " + scheduleID)
+		return
+	}
+
+	response := NewResponseWriter(w)
+	response.WriteSuccess(schedule, nil)
+}
+
+// DeleteSchedule handles DELETE /api/v1/schedules/{id}
+func (h *ScheduleHandlers) DeleteSchedule(w http.ResponseWriter, r *http.Request) {
+	// Set common headers
+	SetCommonHeaders(w)
+
+	scheduleID, ok := scheduleIDFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.scheduler.Delete(scheduleID); err != nil {
+		response := NewResponseWriter(w)
+		if errors.Is(err, scheduler.ErrScheduleNotFound) {
+			response.WriteNotFound("Schedule " + scheduleID)
+			return
+		}
+		response.WriteInternalError("Failed to delete schedule: " + err.Error())
+		return
+	}
+
+	response := NewResponseWriter(w)
+	response.WriteSuccess(map[string]string{"id": scheduleID, "status": "deleted"}, nil)
+}
+
+// RunSchedule handles POST /api/v1/schedules/{id}/run, running a scan immediately
+func (h *ScheduleHandlers) RunSchedule(w http.ResponseWriter, r *http.Request) {
+	// Set common headers
+	SetCommonHeaders(w)
+
+	scheduleID, ok := scheduleIDFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	result, err := h.scheduler.RunNow(r.Context(), scheduleID)
+	if err != nil {
+		response := NewResponseWriter(w)
+		response.WriteNotFound("Schedule " + scheduleID)
+		return
+	}
+
+	response := NewResponseWriter(w)
+	response.WriteSuccess(result, nil)
+}
+
+// scheduleIDFromPath extracts the schedule ID from /api/v1/schedules/{id}[/...]
+func scheduleIDFromPath(w http.ResponseWriter, r *http.Request) (string, bool) {
+	parts := splitPath(r.URL.Path)
+	if len(parts) < 4 {
+		response := NewResponseWriter(w)
+		response.WriteBadRequest("Invalid schedule ID")
+		return "", false
+	}
+	return parts[3], true
+}
@@ -0,0 +1,118 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/catherinevee/driftmgr/internal/auth"
+	"github.com/catherinevee/driftmgr/internal/scheduler"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryScheduleStore keeps schedules in memory for tests
+type memoryScheduleStore struct {
+	schedules []*scheduler.Schedule
+}
+
+func (m *memoryScheduleStore) Load() ([]*scheduler.Schedule, error) {
+	return m.schedules, nil
+}
+
+func (m *memoryScheduleStore) Save(schedules []*scheduler.Schedule) error {
+	m.schedules = schedules
+	return nil
+}
+
+// newAuthTestServer returns a server with authentication enabled and its JWT service
+func newAuthTestServer(t *testing.T) (*Server, *auth.JWTService) {
+	t.Helper()
+	jwtService := auth.NewJWTService("test-secret", "test-issuer", "test-audience", 15*time.Minute, time.Hour)
+	authService := auth.NewService(auth.NewMemoryUserRepository(), auth.NewMemoryRoleRepository(), auth.NewMemorySessionRepository(),
+		auth.NewMemoryAPIKeyRepository(), jwtService, auth.NewPasswordService())
+
+	server := &Server{
+		config:   &Config{AuthEnabled: true, DataDir: t.TempDir()},
+		services: &Services{Auth: authService},
+		router:   &Router{routes: make(map[string]map[string]http.HandlerFunc)},
+	}
+	return server, jwtService
+}
+
+// bearerToken issues an access token for a user holding role
+func bearerToken(t *testing.T, jwtService *auth.JWTService, username, role string) string {
+	t.Helper()
+	token, err := jwtService.GenerateAccessToken(&auth.User{ID: username, Username: username}, []string{role})
+	require.NoError(t, err)
+	return "Bearer " + token
+}
+
+func newScheduleAuthServer(t *testing.T) (*Server, *auth.JWTService, *scheduler.Schedule) {
+	t.Helper()
+	server, jwtService := newAuthTestServer(t)
+	server.services.Scheduler = scheduler.NewScheduler(&memoryScheduleStore{}, func(ctx context.Context, schedule *scheduler.Schedule) (*scheduler.RunResult, error) {
+		return &scheduler.RunResult{}, nil
+	})
+	schedule, err := server.services.Scheduler.Create(&scheduler.Schedule{Provider: "aws", CronExpression: "@hourly"})
+	require.NoError(t, err)
+	server.setupScheduleRoutes()
+	return server, jwtService, schedule
+}
+
+func serveSchedule(server *Server, method, path, authorization string) *httptest.ResponseRecorder {
+	body, _ := json.Marshal(ScheduleRequest{Provider: "aws", CronExpression: "@daily"})
+	req := httptest.NewRequest(method, path, bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if authorization != "" {
+		req.Header.Set("Authorization", authorization)
+	}
+	w := httptest.NewRecorder()
+	server.router.ServeHTTP(w, req)
+	return w
+}
+
+func TestScheduleRoutesRequireAuthentication(t *testing.T) {
+	server, _, schedule := newScheduleAuthServer(t)
+
+	routes := []struct{ method, path string }{
+		{http.MethodPost, "/api/v1/schedules"},
+		{http.MethodGet, "/api/v1/schedules"},
+		{http.MethodGet, "/api/v1/schedules/" + schedule.ID},
+		{http.MethodDelete, "/api/v1/schedules/" + schedule.ID},
+		{http.MethodPost, "/api/v1/schedules/" + schedule.ID + "/run"},
+	}
+	for _, route := range routes {
+		w := serveSchedule(server, route.method, route.path, "")
+		assert.Equal(t, http.StatusUnauthorized, w.Code, "%s %s", route.method, route.path)
+	}
+	assert.Len(t, server.services.Scheduler.List(), 1, "an unauthenticated request does not change schedules")
+}
+
+func TestScheduleRoutesRequireDriftAdmin(t *testing.T) {
+	server, jwtService, schedule := newScheduleAuthServer(t)
+	viewer := bearerToken(t, jwtService, "viewer", auth.RoleViewer)
+
+	routes := []struct{ method, path string }{
+		{http.MethodPost, "/api/v1/schedules"},
+		{http.MethodDelete, "/api/v1/schedules/" + schedule.ID},
+		{http.MethodPost, "/api/v1/schedules/" + schedule.ID + "/run"},
+	}
+	for _, route := range routes {
+		w := serveSchedule(server, route.method, route.path, viewer)
+		assert.Equal(t, http.StatusForbidden, w.Code, "%s %s", route.method, route.path)
+	}
+	assert.Len(t, server.services.Scheduler.List(), 1, "a viewer does not change schedules")
+
+	// Viewers can still read schedules
+	w := serveSchedule(server, http.MethodGet, "/api/v1/schedules/"+schedule.ID, viewer)
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	w = serveSchedule(server, http.MethodPost, "/api/v1/schedules", bearerToken(t, jwtService, "admin", auth.RoleAdmin))
+	assert.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	assert.Len(t, server.services.Scheduler.List(), 2)
+}
@@ -2,26 +2,61 @@ package api
 
 import (
 	"context"
+	"database/sql"
 	"encoding/json"
 	"fmt"
 	"log"
 	"net/http"
+	"path/filepath"
 	"sync"
 	"time"
 
 	"github.com/catherinevee/driftmgr/internal/analytics"
+	"github.com/catherinevee/driftmgr/internal/audit"
 	"github.com/catherinevee/driftmgr/internal/auth"
 	"github.com/catherinevee/driftmgr/internal/automation"
 	"github.com/catherinevee/driftmgr/internal/bi"
+	"github.com/catherinevee/driftmgr/internal/compliance"
 	"github.com/catherinevee/driftmgr/internal/cost"
+	"github.com/catherinevee/driftmgr/internal/drift/predictor"
+	"github.com/catherinevee/driftmgr/internal/events"
+	"github.com/catherinevee/driftmgr/internal/featureflags"
+	"github.com/catherinevee/driftmgr/internal/jobs"
+	"github.com/catherinevee/driftmgr/internal/metrics"
 	"github.com/catherinevee/driftmgr/internal/remediation"
 	"github.com/catherinevee/driftmgr/internal/repositories"
+	"github.com/catherinevee/driftmgr/internal/scheduler"
 	"github.com/catherinevee/driftmgr/internal/security"
 	"github.com/catherinevee/driftmgr/internal/services"
+	"github.com/catherinevee/driftmgr/internal/shared/backoff"
+	"github.com/catherinevee/driftmgr/internal/shared/cache"
+	"github.com/catherinevee/driftmgr/internal/shared/config"
+	sharedEvents "github.com/catherinevee/driftmgr/internal/shared/events"
+	"github.com/catherinevee/driftmgr/internal/shared/tools"
+	"github.com/catherinevee/driftmgr/internal/state"
+	"github.com/catherinevee/driftmgr/internal/state/backups"
 	"github.com/catherinevee/driftmgr/internal/tenant"
 	"github.com/catherinevee/driftmgr/internal/websocket"
 )
 
+// driftHistoryRetention is how long drift history entries are kept
+const driftHistoryRetention = 90 * 24 * time.Hour
+
+// serverDB is the SQLite database drift and remediation history, snapshots, approvals,
+// scan schedules and service tokens are persisted in
+const serverDB = ".driftmgr/driftmgr.db"
+
+// Discovery defaults, used when the discovery config leaves them unset
+const (
+	defaultDiscoveryCacheTTL     = 5 * time.Minute
+	defaultDiscoveryCacheMaxSize = 64 << 20 // 64MB
+	defaultDiscoveryMaxResources = 10000
+	defaultMaxConcurrentJobs     = 2
+	defaultDiscoveryTimeout      = 5 * time.Minute
+	defaultPolicyDir             = "policies/resources"
+	defaultFrameworkDir          = "policies/frameworks"
+)
+
 // Server represents the API server
 type Server struct {
 	httpServer *http.Server
@@ -30,6 +65,13 @@ type Server struct {
 	config     *Config
 	address    string
 	mu         sync.RWMutex
+	db         *sql.DB
+
+	// jobQueue runs discoveries, bounding how many run at once
+	jobQueue *jobs.Manager
+
+	// driftModel predicts drift from the drift history it was last trained on
+	driftModel *predictor.Model
 }
 
 // Services represents all available services
@@ -39,7 +81,21 @@ type Services struct {
 	Automation     *automation.AutomationService
 	BI             *bi.BIService
 	Cost           *cost.CostAnalyzer
+	CostEstimator  cost.CostEstimator
+	Policies       *compliance.OPAEngine
+	Frameworks     map[string]compliance.Framework
 	Remediation    *remediation.IntelligentRemediationService
+	Snapshots      *remediation.SnapshotStore
+	History        *remediation.HistoryStore
+	Approvals      *remediation.ApprovalStore
+	RemediationPolicies *remediation.PolicyStore
+	Notifications  *events.NotificationService
+	Deletion       *remediation.DeletionEngine
+	Tagging        *remediation.TagComplianceRemediator
+	Audit          *audit.Log
+	Tools          *tools.ToolResolver
+	Scheduler      *scheduler.Scheduler
+	DiscoveryCache *cache.GlobalCache
 	Security       *security.SecurityService
 	Tenant         *tenant.TenantService
 	WebSocket      *websocket.Service
@@ -47,6 +103,9 @@ type Services struct {
 	StateService   *services.StateService
 	ResourceService *services.ResourceService
 	DriftService   *services.DriftService
+	DriftPatterns  DriftPatternRepository
+	FeatureFlags   featureflags.Store
+	Backups        *backups.Store
 }
 
 // Config represents server configuration
@@ -63,6 +122,128 @@ type Config struct {
 	RateLimitRPS     int           `json:"rate_limit_rps"`
 	LoggingEnabled   bool          `json:"logging_enabled"`
 
+	// CORS lists the browser origins, other than the API's own, allowed to call it when
+	// CORSEnabled is set
+	CORS CORSConfig `json:"cors"`
+
+	// Metrics configuration
+	MetricsEnabled      bool `json:"metrics_enabled"`
+	MetricsAuthRequired bool `json:"metrics_auth_required"`
+
+	// Discovery configuration; CacheTTL and CacheMaxSize size the discovery result cache
+	// and MaxResources caps how many resources a single discovery run collects
+	Discovery config.DiscoveryConfig `json:"discovery"`
+
+	// RemediationRequireApproval holds every remediation that would change resources until
+	// an administrator approves it
+	RemediationRequireApproval bool `json:"remediation_require_approval"`
+
+	// ApprovalNotifications are the notification channels, such as Slack or webhook, that
+	// approvers are alerted through when a remediation is held for approval
+	ApprovalNotifications []events.NotificationChannel `json:"approval_notifications,omitempty"`
+
+	// PolicyDir is the directory of .rego policies resources are evaluated against;
+	// policies/resources when empty
+	PolicyDir string `json:"policy_dir"`
+
+	// FrameworkDir is the directory of JSON compliance framework definitions mapping
+	// controls to policies; policies/frameworks when empty
+	FrameworkDir string `json:"framework_dir"`
+
+	// TagPolicy lists the tags discovered resources must carry and the values allowed for
+	// them; GET /api/v1/tags/violations reports the resources that break it
+	TagPolicy compliance.TagPolicy `json:"tag_policy"`
+
+	// TerragruntRoot is the directory of Terragrunt stacks that can be analyzed; stack
+	// analysis is disabled when empty
+	TerragruntRoot string `json:"terragrunt_root"`
+
+	// HelmChartRoot is the directory of Helm charts and values files POST /api/v1/helm/drift
+	// can render releases from; comparing releases with charts is disabled when empty
+	HelmChartRoot string `json:"helm_chart_root"`
+
+	// RepositoryRoot is the directory of Terraform repositories whose backend configuration
+	// POST /api/v1/statefiles/discover can resolve and POST /api/v1/drift/plan can run
+	// refresh-only plans in; state file discovery and plan drift detection are disabled when empty
+	RepositoryRoot string `json:"repository_root"`
+
+	// StateBackup schedules backups of the states resolved under RepositoryRoot, kept in the
+	// backups directory of the data directory
+	StateBackup StateBackupConfig `json:"state_backup"`
+
+	// AWSOrganizationRole is the role POST /api/v1/discover/organization assumes in member
+	// accounts, OrganizationAccountAccessRole when empty
+	AWSOrganizationRole string `json:"aws_organization_role"`
+
+	// AWSEventToken is the shared secret EventBridge API destinations send in the
+	// X-Driftmgr-Event-Token header with CloudTrail events; event ingestion is disabled when
+	// empty
+	AWSEventToken string `json:"aws_event_token"`
+
+	// AzureEventToken is the shared secret Event Grid subscriptions and action groups send in
+	// the X-Driftmgr-Event-Token header or the token query parameter; event ingestion is
+	// disabled when empty
+	AzureEventToken string `json:"azure_event_token"`
+
+	// PricingFile is a JSON or YAML price list, such as negotiated rates, that overrides the
+	// bundled AWS list prices when estimating resource costs
+	PricingFile string `json:"pricing_file"`
+
+	// FeatureFlagStore selects where feature flags are kept: "database" shares them through the
+	// server's SQLite database, so every instance behind a load balancer evaluates the same
+	// flags; "file", the default, keeps them in feature_flags.json in the data directory
+	FeatureFlagStore string `json:"feature_flag_store"`
+
+	// DataDir is the base directory the server writes files under: exports in its outputs
+	// subdirectory and uploaded state files in uploads. When empty, DRIFTMGR_DATA_DIR is used, falling back to the
+	// working directory. It must be writable; Start fails otherwise.
+	DataDir string `json:"data_dir"`
+
+	// MaxStateUploadSize bounds a state file uploaded to POST /api/v1/state/upload, in bytes;
+	// 256 MB when zero
+	MaxStateUploadSize int64 `json:"max_state_upload_size"`
+
+	// HealthCheckProviders lists the providers, such as aws, whose credentials the readiness
+	// probe checks; each check may call the provider's identity service
+	HealthCheckProviders []string `json:"health_check_providers"`
+
+	// CredentialExpiryWarning is how long before provider credentials expire
+	// GET /api/v1/providers/status warns about them and a notification is sent; 24 hours
+	// when zero
+	CredentialExpiryWarning time.Duration `json:"credential_expiry_warning"`
+
+	// CredentialCheckSchedule is a cron expression, such as @hourly, on which provider
+	// credentials are checked so expiry warnings are notified without the status being
+	// polled; credentials are only checked on request when empty
+	CredentialCheckSchedule string `json:"credential_check_schedule"`
+
+	// LongRequestTimeout replaces ReadTimeout and WriteTimeout for long-running routes such as
+	// synchronous discovery, scans, remediation and state uploads; 30 minutes when zero
+	LongRequestTimeout time.Duration `json:"long_request_timeout"`
+
+	// ShutdownTimeout bounds Stop, which waits for running discovery jobs before cancelling
+	// them; 30 seconds when zero
+	ShutdownTimeout time.Duration `json:"shutdown_timeout"`
+
+	// AllowedOrigins lists browser origins allowed to open WebSocket connections.
+	// When empty, DRIFTMGR_ALLOWED_ORIGINS is used, falling back to same-origin only.
+	AllowedOrigins []string `json:"allowed_origins"`
+
+	// WebSocketPingInterval and WebSocketPongTimeout control how quickly dead WebSocket
+	// connections are detected. When zero, DRIFTMGR_WS_PING_INTERVAL and
+	// DRIFTMGR_WS_PONG_TIMEOUT are used, falling back to a 54s ping and 60s timeout.
+	WebSocketPingInterval time.Duration `json:"websocket_ping_interval"`
+	WebSocketPongTimeout  time.Duration `json:"websocket_pong_timeout"`
+
+	// ConfirmationKey signs the confirmation tokens of deletion and batch remediation previews,
+	// so they stay valid across restarts and on every instance sharing it. When empty,
+	// DRIFTMGR_CONFIRMATION_KEY is used, falling back to a random key per process.
+	ConfirmationKey string `json:"confirmation_key"`
+
+	// OIDC enables single sign-on through an OpenID Connect issuer; local logins remain
+	// available alongside it
+	OIDC *auth.OIDCConfig `json:"oidc"`
+
 	// Authentication configuration
 	JWTSecret          string        `json:"jwt_secret"`
 	JWTIssuer          string        `json:"jwt_issuer"`
@@ -91,6 +272,7 @@ func NewAPIServer(address string) *Server {
 		RateLimitEnabled: true,
 		RateLimitRPS:     100,
 		LoggingEnabled:   true,
+		MetricsEnabled:   true,
 	}
 
 	router := &Router{
@@ -134,6 +316,7 @@ func NewServer(config *Config, services *Services) *Server {
 			RateLimitEnabled: true,
 			RateLimitRPS:     100,
 			LoggingEnabled:   true,
+			MetricsEnabled:   true,
 
 			// Default authentication configuration
 			JWTSecret:          "driftmgr-secret-key-change-in-production",
@@ -164,19 +347,32 @@ func NewServer(config *Config, services *Services) *Server {
 		server.initializeWebSocket()
 	}
 
+	// Apply the configured retry policy before any cloud clients are created
+	backoff.SetDefaultPolicy(backoff.PolicyFromDiscoveryConfig(config.Discovery))
+
 	// Initialize business logic services
 	server.initializeBusinessServices()
 
+	// Initialize cost estimation
+	if services.CostEstimator == nil {
+		server.initializeCostEstimator()
+	}
+
+	// Initialize policy evaluation
+	if services.Policies == nil {
+		server.initializePolicyEngine()
+	}
+
 	// Setup routes
 	server.setupRoutes()
 
-	// Create HTTP server
+	// Create HTTP server; unset timeouts default to short ones, which long-running routes extend
 	server.httpServer = &http.Server{
 		Addr:           fmt.Sprintf("%s:%d", config.Host, config.Port),
 		Handler:        server,
-		ReadTimeout:    config.ReadTimeout,
-		WriteTimeout:   config.WriteTimeout,
-		IdleTimeout:    config.IdleTimeout,
+		ReadTimeout:    timeoutOr(config.ReadTimeout, defaultReadTimeout),
+		WriteTimeout:   timeoutOr(config.WriteTimeout, defaultWriteTimeout),
+		IdleTimeout:    timeoutOr(config.IdleTimeout, defaultIdleTimeout),
 		MaxHeaderBytes: config.MaxHeaderBytes,
 	}
 
@@ -213,10 +409,56 @@ func (s *Server) initializeAuth() {
 		passwordService,
 	)
 
+	// Service account tokens are persisted so they survive restarts
+	if db, err := s.database(); err != nil {
+		log.Printf("Service tokens are unavailable: %v", err)
+	} else if tokens, err := auth.NewServiceTokenStore(db); err != nil {
+		log.Printf("Service tokens are unavailable: %v", err)
+	} else {
+		authService.SetServiceTokens(tokens)
+	}
+
+	if s.config.OIDC != nil {
+		provider, err := auth.NewOIDCProvider(context.Background(), *s.config.OIDC)
+		if err != nil {
+			log.Printf("Single sign-on is unavailable: %v", err)
+		} else {
+			authService.SetOIDCProvider(provider)
+		}
+	}
+
 	// Set auth service
 	s.services.Auth = authService
 }
 
+// database opens the server's SQLite database on first use and returns it
+func (s *Server) database() (*sql.DB, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.db == nil {
+		db, err := remediation.OpenSQLiteDB(serverDB)
+		if err != nil {
+			return nil, err
+		}
+		s.db = db
+	}
+	return s.db, nil
+}
+
+// jobManager returns the server's job queue, creating it on first use
+func (s *Server) jobManager() *jobs.Manager {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.jobQueue == nil {
+		concurrency := defaultMaxConcurrentJobs
+		if s.config != nil && s.config.Discovery.MaxConcurrentJobs > 0 {
+			concurrency = s.config.Discovery.MaxConcurrentJobs
+		}
+		s.jobQueue = jobs.NewManager(concurrency)
+	}
+	return s.jobQueue
+}
+
 // initializeWebSocket initializes the WebSocket service
 func (s *Server) initializeWebSocket() {
 	// Create WebSocket service
@@ -226,6 +468,147 @@ func (s *Server) initializeWebSocket() {
 	s.services.WebSocket = wsService
 }
 
+// initializeCostEstimator sets up AWS cost estimation, layering the configured price file
+// over the bundled list prices
+func (s *Server) initializeCostEstimator() {
+	var source cost.PriceSource = cost.DefaultAWSPrices()
+	if s.config.PricingFile != "" {
+		custom, err := cost.LoadPriceFile(s.config.PricingFile)
+		if err != nil {
+			log.Printf("Failed to load pricing file, using list prices: %v", err)
+		} else {
+			source = cost.LayeredPriceSource{custom, source}
+		}
+	}
+	s.services.CostEstimator = cost.NewAWSCostEstimator(source)
+}
+
+// initializeRemediationDB opens the remediation database and the snapshot, history, approval
+// and policy stores kept in it. Without it, remediations that change resources cannot be
+// snapshotted and do not run, history is not recorded, and approvals and remediation policies
+// are unavailable.
+func (s *Server) initializeRemediationDB() {
+	db, err := s.database()
+	if err != nil {
+		log.Printf("Remediation snapshots, history, approvals and policies are unavailable: %v", err)
+		return
+	}
+	if s.services.Snapshots == nil {
+		if s.services.Snapshots, err = remediation.NewSnapshotStore(db); err != nil {
+			log.Printf("Remediation snapshots are unavailable: %v", err)
+		}
+	}
+	if s.services.History == nil {
+		if s.services.History, err = remediation.NewHistoryStore(db); err != nil {
+			log.Printf("Remediation history will not be recorded: %v", err)
+		}
+	}
+	if s.services.Approvals == nil {
+		if s.services.Approvals, err = remediation.NewApprovalStore(db); err != nil {
+			log.Printf("Remediation approvals are unavailable: %v", err)
+		}
+	}
+	if s.services.RemediationPolicies == nil {
+		if s.services.RemediationPolicies, err = remediation.NewPolicyStore(db); err != nil {
+			log.Printf("Remediation policies are unavailable: %v", err)
+		}
+	}
+}
+
+// initializeNotifications creates the notification service and subscribes approvers to
+// approval requests through the configured channels
+func (s *Server) initializeNotifications() {
+	s.services.Notifications = events.NewNotificationService(nil, nil)
+	if len(s.config.ApprovalNotifications) == 0 {
+		return
+	}
+	if _, err := s.services.Notifications.Subscribe("approvers", s.config.ApprovalNotifications, []events.EventFilter{
+		{EventTypes: []sharedEvents.EventType{approvalRequestedNotification}},
+	}); err != nil {
+		log.Printf("WARNING: approvers will not be notified of pending approvals: %v", err)
+	}
+}
+
+// driftHistoryRepository returns the repository drift history is recorded in: the server's
+// SQLite database, or memory when the database is unavailable
+func (s *Server) driftHistoryRepository() services.DriftHistoryRepository {
+	db, err := s.database()
+	if err == nil {
+		var history *repositories.SQLDriftHistoryRepository
+		if history, err = repositories.NewSQLDriftHistoryRepository(db); err == nil {
+			return history
+		}
+	}
+	log.Printf("WARNING: drift history will not survive restarts: %v", err)
+	return repositories.NewMemoryDriftHistoryRepository()
+}
+
+// driftPatternRepository returns the repository drift patterns are registered in: the
+// server's SQLite database, or memory when the database is unavailable
+func (s *Server) driftPatternRepository() DriftPatternRepository {
+	db, err := s.database()
+	if err == nil {
+		var patterns *repositories.SQLDriftPatternRepository
+		if patterns, err = repositories.NewSQLDriftPatternRepository(db); err == nil {
+			return patterns
+		}
+	}
+	log.Printf("WARNING: drift patterns will not survive restarts: %v", err)
+	return repositories.NewMemoryDriftPatternRepository()
+}
+
+// featureFlagStore returns the store feature flags are kept in: the server's SQLite database
+// when configured and available, otherwise the JSON file in the data directory
+func (s *Server) featureFlagStore() featureflags.Store {
+	if s.config != nil && s.config.FeatureFlagStore == featureFlagStoreDatabase {
+		db, err := s.database()
+		if err == nil {
+			var flags *featureflags.SQLStore
+			if flags, err = featureflags.NewSQLStore(db); err == nil {
+				return flags
+			}
+		}
+		log.Printf("WARNING: feature flags will not be shared between server instances: %v", err)
+	}
+	return featureflags.NewFileStore(filepath.Join(s.dataDir(), featureFlagFile))
+}
+
+// initializeAuditLog opens the audit log state-changing operations are recorded in
+func (s *Server) initializeAuditLog() {
+	db, err := s.database()
+	if err == nil {
+		s.services.Audit, err = audit.NewLog(db)
+	}
+	if err != nil {
+		log.Printf("WARNING: audit log is unavailable, operations will not be audited: %v", err)
+	}
+}
+
+// initializePolicyEngine loads the resource policies from the policy directory and the
+// compliance frameworks mapped onto them. A missing or invalid directory is logged and
+// leaves the engine without policies or frameworks.
+func (s *Server) initializePolicyEngine() {
+	dir := s.config.PolicyDir
+	if dir == "" {
+		dir = defaultPolicyDir
+	}
+	engine := compliance.NewOPAEngine(compliance.OPAConfig{LocalPolicies: dir})
+	if err := engine.LoadPolicies(context.Background()); err != nil {
+		log.Printf("Failed to load policies from %s: %v", dir, err)
+	}
+	s.services.Policies = engine
+
+	frameworkDir := s.config.FrameworkDir
+	if frameworkDir == "" {
+		frameworkDir = defaultFrameworkDir
+	}
+	frameworks, err := compliance.LoadFrameworks(frameworkDir)
+	if err != nil {
+		log.Printf("Failed to load compliance frameworks from %s: %v", frameworkDir, err)
+	}
+	s.services.Frameworks = frameworks
+}
+
 // initializeBusinessServices initializes the business logic services
 func (s *Server) initializeBusinessServices() {
 	// Create repositories
@@ -239,12 +622,226 @@ func (s *Server) initializeBusinessServices() {
 	stateService := services.NewStateService(stateRepo, backendService)
 	resourceService := services.NewResourceService(resourceRepo)
 	driftService := services.NewDriftService(driftRepo, stateService, resourceService)
+	driftService.SetHistoryRepository(s.driftHistoryRepository(), driftHistoryRetention)
 
 	// Set services
 	s.services.BackendService = backendService
 	s.services.StateService = stateService
 	s.services.ResourceService = resourceService
 	s.services.DriftService = driftService
+	s.services.DriftPatterns = s.driftPatternRepository()
+	s.services.FeatureFlags = s.featureFlagStore()
+
+	if s.services.Remediation == nil {
+		s.services.Remediation = remediation.NewIntelligentRemediationService(nil)
+	}
+	if s.services.Snapshots == nil || s.services.History == nil || s.services.Approvals == nil || s.services.RemediationPolicies == nil {
+		s.initializeRemediationDB()
+	}
+	if s.services.Deletion == nil {
+		s.services.Deletion = remediation.NewDeletionEngine()
+	}
+	s.services.Remediation.SetConfirmationKey(s.config.ConfirmationKey)
+	s.services.Deletion.SetConfirmationKey(s.config.ConfirmationKey)
+	if s.services.Tagging == nil {
+		s.services.Tagging = remediation.NewTagComplianceRemediator()
+	}
+	if s.services.Notifications == nil {
+		s.initializeNotifications()
+	}
+	if s.services.Audit == nil {
+		s.initializeAuditLog()
+	}
+	if s.services.Tools == nil {
+		s.services.Tools = tools.NewToolResolver()
+		s.services.Tools.ResolveAll(externalTools())
+	}
+	if s.services.DiscoveryCache == nil {
+		s.services.DiscoveryCache = newDiscoveryCache(s.config.Discovery)
+	}
+	if s.services.Scheduler == nil {
+		s.initializeScheduler()
+	}
+	if s.services.Backups == nil {
+		s.initializeStateBackups()
+		s.initializeCredentialChecks()
+	}
+}
+
+// newDiscoveryCache creates the cache for discovery results and exposes its hit ratio as a metric
+func newDiscoveryCache(cfg config.DiscoveryConfig) *cache.GlobalCache {
+	ttl := time.Duration(cfg.CacheTTL) * time.Second
+	if ttl <= 0 {
+		ttl = defaultDiscoveryCacheTTL
+	}
+	maxSize := cfg.CacheMaxSize
+	if maxSize <= 0 {
+		maxSize = defaultDiscoveryCacheMaxSize
+	}
+
+	discoveryCache := cache.NewGlobalCache(maxSize, ttl, "")
+	if err := metrics.RegisterCacheHitRatio("discovery", func() float64 {
+		return discoveryCache.HitRate() / 100
+	}); err != nil {
+		// Already registered by an earlier server in this process
+		log.Printf("Discovery cache hit ratio metric not registered: %v", err)
+	}
+	return discoveryCache
+}
+
+// initializeScheduler restores scan schedules from the server database and runs them. Without
+// the database, scheduled scans are unavailable.
+func (s *Server) initializeScheduler() {
+	db, err := s.database()
+	var store *scheduler.SQLStore
+	if err == nil {
+		store, err = scheduler.NewSQLStore(db)
+	}
+	if err != nil {
+		log.Printf("WARNING: scheduled drift scans are unavailable: %v", err)
+		return
+	}
+
+	s.services.Scheduler = scheduler.NewScheduler(store, s.runScheduledScan)
+	s.services.Scheduler.SetNotifier(s.notifyDriftThreshold)
+	if err := s.services.Scheduler.Load(); err != nil {
+		log.Printf("WARNING: failed to load drift scan schedules: %v", err)
+	}
+}
+
+// runScheduledScan discovers a schedule's provider and runs drift detection against the
+// discovered resources, which records drift history, then applies the remediation policies to
+// the provider's drift
+func (s *Server) runScheduledScan(ctx context.Context, schedule *scheduler.Schedule) (*scheduler.RunResult, error) {
+	resources, err := s.discoverCloudResources(ctx, schedule.Provider)
+	if err != nil {
+		return nil, fmt.Errorf("discovery failed: %w", err)
+	}
+
+	result := &scheduler.RunResult{ResourceCount: len(resources)}
+
+	drift, err := s.services.DriftService.DetectDrift(ctx, services.DriftConfig{
+		Provider:  schedule.Provider,
+		Resources: cloudResources(resources),
+	})
+	if err != nil {
+		return result, fmt.Errorf("drift detection failed: %w", err)
+	}
+
+	result.DriftCount = drift.DriftCount
+	result.DriftResultID = drift.ID
+	if drift.Status == "failed" {
+		return result, fmt.Errorf("drift detection failed: %v", drift.Summary["error"])
+	}
+
+	result.AutoRemediated, result.RemediationAlerts = s.applyRemediationPolicies(ctx, schedule)
+	return result, nil
+}
+
+// notifyDriftThreshold alerts connected clients that a scheduled scan crossed its drift threshold
+func (s *Server) notifyDriftThreshold(schedule *scheduler.Schedule, result *scheduler.RunResult) {
+	log.Printf("Scheduled scan %s found %d drifted resources (threshold %d)",
+		schedule.Name, result.DriftCount, schedule.DriftThreshold)
+
+	if s.services.WebSocket == nil {
+		return
+	}
+	s.services.WebSocket.GetHub().BroadcastMessage(websocket.NewMessage("drift_threshold_exceeded", map[string]interface{}{
+		"schedule_id":     schedule.ID,
+		"schedule_name":   schedule.Name,
+		"provider":        schedule.Provider,
+		"drift_count":     result.DriftCount,
+		"drift_threshold": schedule.DriftThreshold,
+		"drift_result_id": result.DriftResultID,
+	}))
+}
+
+// approvalRequestedNotification is the notification type approvers subscribe to
+const approvalRequestedNotification = "remediation_approval_requested"
+
+// notifyApprovalRequested alerts approvers through their notification channels, and
+// connected clients, that a remediation is waiting for approval
+func (s *Server) notifyApprovalRequested(approval *remediation.Approval) {
+	log.Printf("Remediation of %d drift(s) requested by %s is awaiting approval %s",
+		len(approval.DriftIDs), approval.RequestedBy, approval.ID)
+
+	// Delivered in the background so a slow channel does not hold up the request
+	if s.services.Notifications != nil {
+		go s.services.Notifications.BroadcastNotification(&events.NotificationMessage{
+			ID:        approval.ID,
+			Type:      approvalRequestedNotification,
+			Title:     "Remediation awaiting approval",
+			Message:   fmt.Sprintf("%s requested remediation of %d drift(s): approve or reject approval %s", approval.RequestedBy, len(approval.DriftIDs), approval.ID),
+			Severity:  "medium",
+			Timestamp: approval.RequestedAt,
+			Data: map[string]interface{}{
+				"approval_id": approval.ID,
+				"drift_ids":   approval.DriftIDs,
+			},
+			Metadata: map[string]string{"requested_by": approval.RequestedBy},
+		})
+	}
+
+	if s.services.WebSocket == nil {
+		return
+	}
+	s.services.WebSocket.GetHub().BroadcastMessage(websocket.NewMessage(approvalRequestedNotification, map[string]interface{}{
+		"approval_id":  approval.ID,
+		"drift_ids":    approval.DriftIDs,
+		"requested_by": approval.RequestedBy,
+		"requested_at": approval.RequestedAt,
+	}))
+}
+
+// newRemediationHandlers creates the remediation handlers with the server's snapshot, history
+// and approval stores
+func (s *Server) newRemediationHandlers() *RemediationHandlers {
+	handlers := NewRemediationHandlers(s.services.Remediation, s.services.Snapshots, s.services.Tools)
+	handlers.SetProgressBroadcaster(s.services.WebSocket)
+	handlers.SetHistoryStore(s.services.History)
+	handlers.SetRepositoryRoot(s.config.RepositoryRoot)
+	handlers.SetApprovalGate(s.services.Approvals, s.config.RemediationRequireApproval, s.notifyApprovalRequested)
+	return handlers
+}
+
+// setupScheduleRoutes registers the scheduled scan routes. Schedules run discovery and
+// remediation policies unattended, so only drift administrators may create, delete or run them.
+func (s *Server) setupScheduleRoutes() {
+	if s.services.Scheduler == nil {
+		return
+	}
+	scheduleHandlers := NewScheduleHandlers(s.services.Scheduler)
+	s.router.POST("/api/v1/schedules", s.requirePermission(auth.PermissionDriftAdmin, s.audited("schedule.create", scheduleHandlers.CreateSchedule)))
+	s.router.GET("/api/v1/schedules", s.requirePermission(auth.PermissionDriftRead, WithETag(scheduleHandlers.ListSchedules)))
+	s.router.GET("/api/v1/schedules/{id}", s.requirePermission(auth.PermissionDriftRead, WithETag(scheduleHandlers.GetSchedule)))
+	s.router.DELETE("/api/v1/schedules/{id}", s.requirePermission(auth.PermissionDriftAdmin, s.audited("schedule.delete", scheduleHandlers.DeleteSchedule)))
+	s.router.POST("/api/v1/schedules/{id}/run", s.longRunning(s.requirePermission(auth.PermissionDriftAdmin, s.audited("schedule.run", scheduleHandlers.RunSchedule))))
+}
+
+// requireAuth restricts a handler to authenticated users when authentication is enabled
+func (s *Server) requireAuth(next http.HandlerFunc) http.HandlerFunc {
+	if !s.config.AuthEnabled || s.services.Auth == nil {
+		return next
+	}
+	return auth.NewAuthMiddleware(s.services.Auth, s.services.Auth.JWTService()).RequireAuth(next)
+}
+
+// requirePermission restricts a handler to users holding permission when authentication is
+// enabled
+func (s *Server) requirePermission(permission string, next http.HandlerFunc) http.HandlerFunc {
+	if !s.config.AuthEnabled || s.services.Auth == nil {
+		return next
+	}
+	return auth.NewAuthMiddleware(s.services.Auth, s.services.Auth.JWTService()).RequirePermission(permission)(next)
+}
+
+// externalTools lists the binaries the server shells out to and the features that need them
+func externalTools() []tools.Tool {
+	return []tools.Tool{
+		{Name: "terraform", Binary: state.TerraformBinary(), Feature: "remediation execution and plan drift detection"},
+		{Name: "git", Binary: "git", Feature: "pull request remediation"},
+		{Name: "gh", Binary: "gh", Feature: "pull request remediation"},
+	}
 }
 
 // Start starts the API server
@@ -253,6 +850,14 @@ func (s *Server) Start(ctx context.Context) error {
 		log.Printf("Starting API server on %s:%d", s.config.Host, s.config.Port)
 	}
 
+	if err := s.prepareDataDir(); err != nil {
+		return err
+	}
+
+	if s.services.Scheduler != nil {
+		s.services.Scheduler.Start()
+	}
+
 	// Start server in goroutine
 	go func() {
 		if err := s.httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
@@ -270,14 +875,29 @@ func (s *Server) Stop(ctx context.Context) error {
 	}
 
 	// Create shutdown context with timeout
-	shutdownCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	timeout := s.shutdownTimeout()
+	shutdownCtx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
 
+	// Background jobs get most of the timeout, leaving time to cancel them and for the
+	// requests waiting on them to finish
+	drainTimeout := timeout - jobCancelGrace
+	if drainTimeout <= 0 {
+		drainTimeout = timeout / 2
+	}
+	drainCtx, cancelDrain := context.WithTimeout(shutdownCtx, drainTimeout)
+	s.drainJobs(drainCtx)
+	cancelDrain()
+
 	// Shutdown server
 	if err := s.httpServer.Shutdown(shutdownCtx); err != nil {
 		return fmt.Errorf("failed to shutdown server: %w", err)
 	}
 
+	if s.services.Scheduler != nil {
+		s.services.Scheduler.Stop(shutdownCtx)
+	}
+
 	if s.config.LoggingEnabled {
 		log.Println("API server stopped")
 	}
@@ -287,9 +907,9 @@ func (s *Server) Stop(ctx context.Context) error {
 
 // ServeHTTP implements http.Handler
 func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	// CORS handling
-	if s.config.CORSEnabled {
-		s.handleCORS(w, r)
+	// CORS handling; preflight requests are answered here
+	if s.config.CORSEnabled && !s.handleCORS(w, r) {
+		return
 	}
 
 	// Rate limiting
@@ -299,8 +919,8 @@ func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	// Authentication
-	if s.config.AuthEnabled {
+	// Authentication; scrapers may reach /metrics without credentials unless configured otherwise
+	if s.config.AuthEnabled && (r.URL.Path != "/metrics" || s.config.MetricsAuthRequired) {
 		if !s.handleAuth(w, r) {
 			return
 		}
@@ -319,60 +939,191 @@ func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 func (s *Server) setupRoutes() {
 	// Create enhanced handlers with real services
 	backendHandlers := NewBackendHandlers(s.services.BackendService)
+	backendHandlers.SetProgressBroadcaster(s.services.WebSocket)
 	stateHandlers := NewStateHandlers(s.services.StateService)
 	resourceHandlers := NewResourceHandlers(s.services.ResourceService)
 	driftHandlers := NewDriftHandlers(s.services.DriftService)
+	driftHandlers.outputDir = s.outputDir()
 
 	// Health check
 	s.router.GET("/health", s.handleHealth)
+	s.router.GET("/health/ready", s.handleHealth)
+	s.router.GET("/health/live", s.handleHealthLive)
 	s.router.GET("/api/v1/health", s.handleHealth)
 
 	// API version
 	s.router.GET("/api/v1/version", s.handleVersion)
 
+	// Prometheus metrics
+	if s.config.MetricsEnabled {
+		s.router.GET("/metrics", s.handleMetrics)
+	}
+
 	// Authentication routes
 	if s.config.AuthEnabled && s.services.Auth != nil {
 		s.setupAuthRoutes()
 	}
 
+	// Cloud Discovery Routes
+	// Discovery and remediation are authenticated so the user's account scopes can be enforced.
+	// Routes that can run for minutes, such as discovering every region, are longRunning so the
+	// server's write timeout does not cut off their responses.
+	s.router.GET("/api/v1/discover", s.longRunning(s.requirePermission(auth.PermissionResourceRead, s.handleDiscover)))
+	s.router.POST("/api/v1/discover", s.longRunning(s.requirePermission(auth.PermissionResourceRead, s.handleDiscover)))
+	s.router.POST("/api/v1/discover/organization", s.longRunning(s.requirePermission(auth.PermissionResourceRead, s.handleDiscoverOrganization)))
+	s.router.POST("/api/v1/discover/gcp/organization", s.longRunning(s.requirePermission(auth.PermissionResourceRead, s.handleDiscoverGCPOrganization)))
+	s.router.GET("/api/v1/discover/compare", s.requirePermission(auth.PermissionResourceRead, s.handleCompareDiscoveries))
+	s.router.DELETE("/api/v1/discover/{id}", s.requirePermission(auth.PermissionResourceRead, s.audited("discover.cancel", s.handleCancelDiscovery)))
+
+	// Job Routes
+	s.router.GET("/api/v1/jobs", s.requirePermission(auth.PermissionResourceRead, s.handleListJobs))
+	s.router.GET("/api/v1/jobs/{id}", s.requirePermission(auth.PermissionResourceRead, s.handleGetJob))
+	s.router.DELETE("/api/v1/jobs/{id}", s.requirePermission(auth.PermissionResourceRead, s.audited("job.cancel", s.handleCancelJob)))
+
 	// Backend Discovery Routes
 	s.router.GET("/api/v1/backends/list", backendHandlers.ListBackends)
 	s.router.POST("/api/v1/backends/discover", backendHandlers.DiscoverBackends)
 	s.router.GET("/api/v1/backends/{id}", backendHandlers.GetBackend)
-	s.router.PUT("/api/v1/backends/{id}", backendHandlers.UpdateBackend)
-	s.router.DELETE("/api/v1/backends/{id}", backendHandlers.DeleteBackend)
+	s.router.PUT("/api/v1/backends/{id}", s.audited("backend.update", backendHandlers.UpdateBackend))
+	s.router.DELETE("/api/v1/backends/{id}", s.audited("backend.delete", backendHandlers.DeleteBackend))
 	s.router.POST("/api/v1/backends/{id}/test", backendHandlers.TestBackend)
 
 	// State Management Routes
-	s.router.GET("/api/v1/state/list", stateHandlers.ListStateFiles)
-	s.router.GET("/api/v1/state/details", stateHandlers.GetStateDetails)
-	s.router.POST("/api/v1/state/import", stateHandlers.ImportResource)
-	s.router.DELETE("/api/v1/state/resources/{id}", stateHandlers.RemoveResource)
-	s.router.POST("/api/v1/state/move", stateHandlers.MoveResource)
-	s.router.POST("/api/v1/state/lock", stateHandlers.LockStateFile)
-	s.router.POST("/api/v1/state/unlock", stateHandlers.UnlockStateFile)
+	s.router.GET("/api/v1/state/list", WithETag(stateHandlers.ListStateFiles))
+	s.router.GET("/api/v1/state/details", WithETag(stateHandlers.GetStateDetails))
+	s.router.POST("/api/v1/state/import", s.audited("state.import", stateHandlers.ImportResource))
+	s.router.DELETE("/api/v1/state/resources/{id}", s.audited("state.remove", stateHandlers.RemoveResource))
+	s.router.POST("/api/v1/state/move", s.audited("state.move", stateHandlers.MoveResource))
+	s.router.POST("/api/v1/state/lock", s.audited("state.lock", stateHandlers.LockStateFile))
+	s.router.POST("/api/v1/state/unlock", s.audited("state.unlock", stateHandlers.UnlockStateFile))
+	s.router.POST("/api/v1/state/upload", s.longRunning(s.requirePermission(auth.PermissionStateWrite, s.audited("state.upload", s.handleStateUpload))))
+	s.router.POST("/api/v1/statefiles/discover", s.longRunning(s.requirePermission(auth.PermissionStateRead, s.handleDiscoverStateFiles)))
 
 	// Resource Management Routes
-	s.router.GET("/api/v1/resources", resourceHandlers.ListResources)
-	s.router.GET("/api/v1/resources/{id}", resourceHandlers.GetResource)
-	s.router.GET("/api/v1/resources/search", resourceHandlers.SearchResources)
-	s.router.PUT("/api/v1/resources/{id}/tags", resourceHandlers.UpdateResourceTags)
+	s.router.GET("/api/v1/resources", WithETag(resourceHandlers.ListResources))
+	s.router.GET("/api/v1/resources/{id}", s.requirePermission(auth.PermissionResourceRead, WithETag(s.handleGetResource(resourceHandlers.GetResource))))
+	s.router.GET("/api/v1/resources/search", s.requirePermission(auth.PermissionResourceRead, WithETag(s.handleSearchResources)))
+	s.router.GET("/api/v1/resources/stats", s.requirePermission(auth.PermissionResourceRead, WithETag(s.handleResourceStats)))
+	s.router.GET("/api/v1/resources/unused", s.longRunning(s.requirePermission(auth.PermissionResourceRead, s.handleUnusedResources)))
+	s.router.PUT("/api/v1/resources/{id}/tags", s.audited("resource.tags.update", resourceHandlers.UpdateResourceTags))
 	s.router.GET("/api/v1/resources/{id}/cost", resourceHandlers.GetResourceCost)
 	s.router.GET("/api/v1/resources/{id}/compliance", resourceHandlers.GetResourceCompliance)
+	s.router.GET("/api/v1/graph", s.requirePermission(auth.PermissionResourceRead, WithETag(s.handleResourceGraph)))
+
+	// Cost Routes
+	s.router.GET("/api/v1/cost/summary", s.requirePermission(auth.PermissionResourceRead, WithETag(s.handleCostSummary)))
+	s.router.GET("/api/v1/recommendations", s.requirePermission(auth.PermissionResourceRead, s.handleRecommendations))
+	s.router.GET("/api/v1/coverage", s.requirePermission(auth.PermissionResourceRead, WithETag(s.handleCoverage)))
+	s.router.POST("/api/v1/analyze", s.requirePermission(auth.PermissionStateRead, s.handleAnalyzeStateSet))
+	s.router.POST("/api/v1/environments/compare", s.handleCompareEnvironments)
+	s.router.POST("/api/v1/environments/promote/plan", s.handlePlanPromotion)
+	s.router.POST("/api/v1/terragrunt/analyze", s.requirePermission(auth.PermissionStateRead, s.handleTerragruntAnalyze))
+	s.router.POST("/api/v1/terragrunt/scan", s.longRunning(s.requirePermission(auth.PermissionDriftRead, s.handleTerragruntScan)))
+
+	// Policy Routes
+	s.router.POST("/api/v1/policy/evaluate", s.requirePermission(auth.PermissionResourceRead, s.handlePolicyEvaluate))
+	s.router.GET("/api/v1/compliance/{framework}", s.requirePermission(auth.PermissionResourceRead, WithETag(s.handleComplianceScorecard)))
+	s.router.GET("/api/v1/tags/violations", s.requirePermission(auth.PermissionResourceRead, WithETag(s.handleTagViolations)))
 
 	// Drift Detection Routes
-	s.router.POST("/api/v1/drift/detect", driftHandlers.DetectDrift)
-	s.router.GET("/api/v1/drift/results", driftHandlers.ListDriftResults)
-	s.router.GET("/api/v1/drift/results/{id}", driftHandlers.GetDriftResult)
-	s.router.DELETE("/api/v1/drift/results/{id}", driftHandlers.DeleteDriftResult)
-	s.router.GET("/api/v1/drift/history", driftHandlers.GetDriftHistory)
-	s.router.GET("/api/v1/drift/summary", driftHandlers.GetDriftSummary)
+	s.router.POST("/api/v1/drift/detect", s.longRunning(driftHandlers.DetectDrift))
+	s.router.GET("/api/v1/drift/results", WithETag(driftHandlers.ListDriftResults))
+	s.router.GET("/api/v1/drift/results/{id}", WithETag(driftHandlers.GetDriftResult))
+	s.router.DELETE("/api/v1/drift/results/{id}", s.audited("drift.delete", driftHandlers.DeleteDriftResult))
+	s.router.GET("/api/v1/drift/history", WithETag(driftHandlers.GetDriftHistory))
+	s.router.GET("/api/v1/drift/summary", WithETag(driftHandlers.GetDriftSummary))
+	s.router.POST("/api/v1/drift/export", s.requirePermission(auth.PermissionDriftRead, driftHandlers.ExportDriftResults))
+	s.router.GET("/api/v1/drift/{id}/diff", s.requirePermission(auth.PermissionDriftRead, WithETag(s.handleDriftDiff)))
+	s.router.POST("/api/v1/drift/plan", s.longRunning(s.requirePermission(auth.PermissionDriftRead, s.handlePlanDrift)))
+	s.router.POST("/api/v1/helm/drift", s.longRunning(s.requirePermission(auth.PermissionDriftRead, s.handleHelmDrift)))
+
+	// Change events pushed by the cloud, authenticated by a shared token rather than a user
+	s.router.POST("/api/v1/events/aws", s.handleAWSEvent)
+	s.router.POST("/api/v1/events/azure", s.handleAzureEvent)
+
+	// Drift Prediction Routes
+	s.router.POST("/api/v1/predict/train", s.requirePermission(auth.PermissionDriftAdmin, s.audited("predict.train", s.handleTrainPredictor)))
+	s.router.GET("/api/v1/predict/stats", s.requirePermission(auth.PermissionDriftRead, WithETag(s.handlePredictorStats)))
+	s.router.GET("/api/v1/predict", s.requirePermission(auth.PermissionDriftRead, s.handlePredictDrift))
+	s.router.GET("/api/v1/predict/patterns", s.requirePermission(auth.PermissionDriftRead, s.handleListDriftPatterns))
+	s.router.POST("/api/v1/predict/patterns", s.requirePermission(auth.PermissionDriftWrite, s.audited("predict.pattern.create", s.handleCreateDriftPattern)))
+	s.router.DELETE("/api/v1/predict/patterns/{id}", s.requirePermission(auth.PermissionDriftWrite, s.audited("predict.pattern.delete", s.handleDeleteDriftPattern)))
+
+	// Exported files, served only to the user who exported them
+	s.router.GET("/outputs/*", s.requireAuth(s.handleOutputFiles))
+
+	// Remediation Routes
+	remediationHandlers := s.newRemediationHandlers()
+	s.router.POST("/api/v1/remediate", s.longRunning(s.requirePermission(auth.PermissionRemediationWrite, s.audited("remediate", remediationHandlers.Remediate))))
+	s.router.GET("/api/v1/remediate/plan", s.requirePermission(auth.PermissionRemediationRead, remediationHandlers.PlanRemediation))
+	s.router.POST("/api/v1/remediate/plan", s.requirePermission(auth.PermissionRemediationRead, remediationHandlers.PlanRemediation))
+	s.router.POST("/api/v1/remediate/batch/preview", s.requirePermission(auth.PermissionRemediationRead, remediationHandlers.PreviewBatchRemediation))
+	s.router.POST("/api/v1/remediate/batch", s.longRunning(s.requirePermission(auth.PermissionRemediationWrite, s.audited("remediate.batch", remediationHandlers.RemediateBatch))))
+	s.router.POST("/api/v1/remediate/rollback", s.requirePermission(auth.PermissionRemediationWrite, s.audited("remediate.rollback", remediationHandlers.RollbackRemediation)))
+	s.router.GET("/api/v1/remediate/history", s.requirePermission(auth.PermissionRemediationRead, WithETag(remediationHandlers.RemediationHistory)))
+	s.router.GET("/api/v1/remediate/approvals", s.requirePermission(auth.PermissionRemediationRead, remediationHandlers.ListApprovals))
+	s.router.POST("/api/v1/remediate/approve/{id}", s.requirePermission(auth.PermissionRemediationAdmin, s.audited("remediate.approve", remediationHandlers.ApproveRemediation)))
+	s.router.POST("/api/v1/remediate/reject/{id}", s.requirePermission(auth.PermissionRemediationAdmin, s.audited("remediate.reject", remediationHandlers.RejectRemediation)))
+	s.router.GET("/api/v1/snapshots", s.requirePermission(auth.PermissionRemediationRead, WithETag(remediationHandlers.ListSnapshots)))
+	s.router.POST("/api/v1/remediate/tags", s.requirePermission(auth.PermissionRemediationWrite, s.audited("remediate.tags", s.handleTagRemediation)))
+
+	// Remediation policies; scheduled scans apply them to the drift they find
+	s.router.GET("/api/v1/remediate/policies", s.requirePermission(auth.PermissionRemediationRead, WithETag(s.handleListRemediationPolicies)))
+	s.router.POST("/api/v1/remediate/policies", s.requirePermission(auth.PermissionRemediationAdmin, s.audited("remediate.policy.create", s.handleCreateRemediationPolicy)))
+	s.router.GET("/api/v1/remediate/policies/{id}", s.requirePermission(auth.PermissionRemediationRead, WithETag(s.handleGetRemediationPolicy)))
+	s.router.PUT("/api/v1/remediate/policies/{id}", s.requirePermission(auth.PermissionRemediationAdmin, s.audited("remediate.policy.update", s.handleUpdateRemediationPolicy)))
+	s.router.DELETE("/api/v1/remediate/policies/{id}", s.requirePermission(auth.PermissionRemediationAdmin, s.audited("remediate.policy.delete", s.handleDeleteRemediationPolicy)))
+
+	// Account resource deletion, confirmed with a token from a preview
+	s.router.POST("/api/v1/deletion/preview", s.requirePermission(auth.PermissionResourceDelete, s.handlePreviewDeletion))
+	s.router.POST("/api/v1/deletion", s.requirePermission(auth.PermissionResourceDelete, s.audited("resources.delete", s.handleDeleteAccountResources)))
+
+	// Audit Routes; state-changing routes above are wrapped with s.audited
+	s.router.GET("/api/v1/audit", s.requirePermission(auth.PermissionSystemRead, WithETag(s.handleAuditLog)))
+
+	// Provider credential health, checked at most once a minute unless refreshed
+	s.router.GET("/api/v1/providers/status", s.requirePermission(auth.PermissionResourceRead, s.handleProviderStatus))
+	s.router.GET("/api/v1/providers/{provider}/permissions", s.requirePermission(auth.PermissionResourceRead, s.handleProviderPermissions))
+	s.router.GET("/api/v1/providers/aws/sso", s.requirePermission(auth.PermissionResourceRead, s.handleListAWSSSOProfiles))
+	s.router.POST("/api/v1/providers/aws/sso/login", s.requirePermission(auth.PermissionSystemAdmin, s.audited("aws.sso.login", s.handleAWSSSOLogin)))
+
+	// Feature Flag Routes; any user may evaluate the flags, administrators manage them
+	s.router.GET("/api/v1/feature-flags", s.requirePermission(auth.PermissionSystemRead, s.handleListFeatureFlags))
+	s.router.GET("/api/v1/feature-flags/evaluate", s.requirePermission(auth.PermissionDriftRead, s.handleEvaluateFeatureFlags))
+	s.router.PUT("/api/v1/feature-flags/{name}", s.requirePermission(auth.PermissionSystemAdmin, s.audited("feature_flag.set", s.handleSetFeatureFlag)))
+	s.router.DELETE("/api/v1/feature-flags/{name}", s.requirePermission(auth.PermissionSystemAdmin, s.audited("feature_flag.delete", s.handleDeleteFeatureFlag)))
+
+	// State Backup Routes
+	s.router.GET("/api/v1/backups", s.requirePermission(auth.PermissionStateRead, s.handleListBackups))
+	s.router.POST("/api/v1/backups/{id}/restore", s.requirePermission(auth.PermissionStateAdmin, s.audited("state.restore", s.handleRestoreBackup)))
+
+	// Scheduled Scan Routes
+	s.setupScheduleRoutes()
+
+	// Code Generation Routes
+	generateHandlers := NewGenerateHandlers()
+	s.router.POST("/api/v1/generate/hcl", s.requirePermission(auth.PermissionResourceRead, generateHandlers.GenerateHCL))
+	s.router.POST("/api/v1/export", s.requirePermission(auth.PermissionResourceRead, s.handleInventoryExport))
 
 	// WebSocket routes
 	if s.services.WebSocket != nil {
 		wsHandlers := s.services.WebSocket.GetHandlers()
-		s.router.GET("/ws", wsHandlers.HandleWebSocket)
-		s.router.GET("/api/v1/ws", wsHandlers.HandleWebSocket)
+		if len(s.config.AllowedOrigins) > 0 {
+			wsHandlers.SetAllowedOrigins(s.config.AllowedOrigins)
+		}
+		if s.config.WebSocketPingInterval > 0 || s.config.WebSocketPongTimeout > 0 {
+			ping, pong := websocket.KeepaliveFromEnv()
+			if s.config.WebSocketPingInterval > 0 {
+				ping = s.config.WebSocketPingInterval
+			}
+			if s.config.WebSocketPongTimeout > 0 {
+				pong = s.config.WebSocketPongTimeout
+			}
+			s.services.WebSocket.GetHub().SetKeepalive(ping, pong)
+		}
+		// Connections are authenticated so job progress only reaches the job's user
+		s.router.GET("/ws", s.requireAuth(wsHandlers.HandleWebSocket))
+		s.router.GET("/api/v1/ws", s.requireAuth(wsHandlers.HandleWebSocket))
 		s.router.GET("/api/v1/ws/stats", s.handleWebSocketStats)
 	}
 
@@ -393,19 +1144,26 @@ func (s *Server) setupAuthRoutes() {
 
 	// Public authentication routes
 	s.router.POST("/api/v1/auth/login", authHandlers.Login)
-	s.router.POST("/api/v1/auth/register", authHandlers.Register)
+	s.router.POST("/api/v1/auth/register", s.audited("user.register", authHandlers.Register))
 	s.router.POST("/api/v1/auth/refresh", authHandlers.RefreshToken)
 	s.router.POST("/api/v1/auth/logout", authMiddleware.RequireAuth(authHandlers.Logout))
+	s.router.GET("/api/v1/auth/oidc/login", authHandlers.OIDCLogin)
+	s.router.GET("/api/v1/auth/oidc/callback", authHandlers.OIDCCallback)
+
+	// Service account tokens for non-interactive clients such as CI, managed by admins
+	s.router.POST("/api/v1/auth/tokens", authMiddleware.RequireAdmin(s.audited("auth.token.create", authHandlers.CreateServiceToken)))
+	s.router.GET("/api/v1/auth/tokens", authMiddleware.RequireAdmin(authHandlers.ListServiceTokens))
+	s.router.DELETE("/api/v1/auth/tokens/{id}", authMiddleware.RequireAdmin(s.audited("auth.token.revoke", authHandlers.RevokeServiceToken)))
 
 	// Protected user profile routes
 	s.router.GET("/api/v1/auth/profile", authMiddleware.RequireAuth(authHandlers.GetProfile))
-	s.router.PUT("/api/v1/auth/profile", authMiddleware.RequireAuth(authHandlers.UpdateProfile))
-	s.router.POST("/api/v1/auth/change-password", authMiddleware.RequireAuth(authHandlers.ChangePassword))
+	s.router.PUT("/api/v1/auth/profile", authMiddleware.RequireAuth(s.audited("user.profile.update", authHandlers.UpdateProfile)))
+	s.router.POST("/api/v1/auth/change-password", authMiddleware.RequireAuth(s.audited("user.password.change", authHandlers.ChangePassword)))
 
 	// API key management routes
-	s.router.POST("/api/v1/auth/api-keys", authMiddleware.RequireAuth(authHandlers.CreateAPIKey))
+	s.router.POST("/api/v1/auth/api-keys", authMiddleware.RequireAuth(s.audited("auth.api_key.create", authHandlers.CreateAPIKey)))
 	s.router.GET("/api/v1/auth/api-keys", authMiddleware.RequireAuth(authHandlers.ListAPIKeys))
-	s.router.DELETE("/api/v1/auth/api-keys/{id}", authMiddleware.RequireAuth(authHandlers.DeleteAPIKey))
+	s.router.DELETE("/api/v1/auth/api-keys/{id}", authMiddleware.RequireAuth(s.audited("auth.api_key.delete", authHandlers.DeleteAPIKey)))
 
 	// OAuth2 routes
 	s.router.GET("/api/v1/auth/oauth2/providers", authHandlers.GetOAuth2Providers)
@@ -274,8 +274,8 @@ func TestAPIServer_MetricsEndpoint(t *testing.T) {
 
 	server.router.ServeHTTP(w, req)
 
-	// Metrics endpoint might return 200 or 404 depending on implementation
-	assert.True(t, w.Code == http.StatusOK || w.Code == http.StatusNotFound)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "driftmgr_")
 }
 
 func TestAPIServer_NotFound(t *testing.T) {
@@ -430,3 +430,23 @@ func BenchmarkAPIServer_ParallelRequests(b *testing.B) {
 		}
 	})
 }
+
+func TestRequireAuthRejectsAnonymousRequests(t *testing.T) {
+	server, jwtService := newAuthTestServer(t)
+	var username string
+	handler := server.requireAuth(func(w http.ResponseWriter, r *http.Request) {
+		username, _ = r.Context().Value("username").(string)
+		w.WriteHeader(http.StatusOK)
+	})
+
+	w := httptest.NewRecorder()
+	handler(w, httptest.NewRequest("GET", "/ws", nil))
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	req := httptest.NewRequest("GET", "/ws", nil)
+	req.Header.Set("Authorization", bearerToken(t, jwtService, "jane", "viewer"))
+	w = httptest.NewRecorder()
+	handler(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "jane", username)
+}
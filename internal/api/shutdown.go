@@ -0,0 +1,93 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/catherinevee/driftmgr/internal/jobs"
+	"github.com/catherinevee/driftmgr/internal/websocket"
+)
+
+const (
+	// defaultShutdownTimeout bounds Stop unless configured otherwise
+	defaultShutdownTimeout = 30 * time.Second
+	// jobCancelGrace is how long jobs cancelled at shutdown are given to stop and record
+	// their partial results
+	jobCancelGrace = 5 * time.Second
+	// drainedJobDir is the subdirectory of the data directory the jobs active at shutdown are
+	// written to
+	drainedJobDir = "jobs"
+	// shuttingDownNotification is the WebSocket message sent when the server begins to stop
+	shuttingDownNotification = "shutting_down"
+)
+
+// shutdownTimeout returns the configured shutdown timeout, else the default
+func (s *Server) shutdownTimeout() time.Duration {
+	if s.config != nil && s.config.ShutdownTimeout > 0 {
+		return s.config.ShutdownTimeout
+	}
+	return defaultShutdownTimeout
+}
+
+// drainJobs stops the job queue taking new jobs and waits for its queued and running jobs
+// until ctx is done, then cancels the rest. Each job that was active is written to the data
+// directory's jobs subdirectory with its outcome and any partial result, so what a restart
+// interrupted can still be looked up.
+func (s *Server) drainJobs(ctx context.Context) {
+	active := 0
+	for _, job := range s.jobManager().List() {
+		if !job.Finished() {
+			active++
+		}
+	}
+	s.notifyShuttingDown(ctx, active)
+	if active == 0 {
+		return
+	}
+
+	log.Printf("Waiting for %d job(s) to finish before stopping", active)
+	drained := s.jobManager().Shutdown(ctx, jobCancelGrace)
+	dir := filepath.Join(s.dataDir(), drainedJobDir)
+	for _, job := range drained {
+		if job.Status == jobs.StatusCancelled {
+			log.Printf("Job %s was cancelled at shutdown", job.ID)
+		}
+		if err := saveDrainedJob(dir, job); err != nil {
+			log.Printf("Failed to save job %s at shutdown: %v", job.ID, err)
+		}
+	}
+}
+
+// saveDrainedJob writes a job to dir as <id>.json
+func saveDrainedJob(dir string, job jobs.Job) error {
+	path, err := dataFilePath(dir, job.ID+".json")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create job directory: %w", err)
+	}
+	data, err := json.MarshalIndent(job, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode job: %w", err)
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// notifyShuttingDown tells connected clients the server is stopping, how many jobs it is
+// waiting for and when it will cancel them
+func (s *Server) notifyShuttingDown(ctx context.Context, activeJobs int) {
+	if s.services == nil || s.services.WebSocket == nil {
+		return
+	}
+	data := map[string]interface{}{"active_jobs": activeJobs}
+	if deadline, ok := ctx.Deadline(); ok {
+		data["deadline"] = deadline.UTC()
+	}
+	s.services.WebSocket.GetHub().BroadcastMessage(websocket.NewMessage(shuttingDownNotification, data))
+}
@@ -0,0 +1,41 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/catherinevee/driftmgr/internal/jobs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDrainJobs(t *testing.T) {
+	dir := t.TempDir()
+	s := &Server{config: &Config{DataDir: dir}}
+	_, err := s.jobManager().Submit(context.Background(), "job-drain", discoveryJobType, jobs.Owner{}, func(ctx context.Context) (interface{}, error) {
+		<-ctx.Done()
+		return &DiscoverResponse{JobID: "job-drain", Total: 1}, ctx.Err()
+	})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	s.drainJobs(ctx)
+
+	_, err = s.jobManager().Submit(context.Background(), "", discoveryJobType, jobs.Owner{}, nil)
+	assert.ErrorIs(t, err, jobs.ErrShuttingDown)
+
+	data, err := os.ReadFile(filepath.Join(dir, drainedJobDir, "job-drain.json"))
+	require.NoError(t, err, "jobs active at shutdown are saved")
+	var saved struct {
+		Status jobs.Status      `json:"status"`
+		Result DiscoverResponse `json:"result"`
+	}
+	require.NoError(t, json.Unmarshal(data, &saved))
+	assert.Equal(t, jobs.StatusCancelled, saved.Status)
+	assert.Equal(t, 1, saved.Result.Total, "the partial result is kept")
+}
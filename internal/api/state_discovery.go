@@ -0,0 +1,244 @@
+package api
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	backenddiscovery "github.com/catherinevee/driftmgr/internal/discovery/backend"
+	"github.com/catherinevee/driftmgr/internal/state"
+)
+
+const (
+	// defaultStateDiscoveryLimit and maxStateDiscoveryLimit bound how many state files one
+	// page of state file discovery loads
+	defaultStateDiscoveryLimit = 50
+	maxStateDiscoveryLimit     = 500
+
+	// stateDiscoveryWorkers bounds how many states of a page are loaded at once
+	stateDiscoveryWorkers = 8
+
+	// maxParsedStates bounds how many parsed states are remembered
+	maxParsedStates = 1024
+)
+
+// parsedStates remembers the states discovery has parsed by location and version, so loading
+// a dashboard again only reads and parses the states that changed
+var parsedStates = &stateSummaryCache{entries: make(map[string]parsedState)}
+
+// handleDiscoverStateFiles handles POST /api/v1/statefiles/discover. It resolves where each
+// Terraform configuration in a repository under the configured root keeps its state, from
+// its backend block and the backend config or tfvars files beside it, then loads a page of
+// the resolved states in parallel with the server's cloud credentials. Locations that cannot be loaded
+// are still listed with the error.
+func (s *Server) handleDiscoverStateFiles(w http.ResponseWriter, r *http.Request) {
+	var request StateDiscoveryRequest
+	if err := decodeRequest(r, &request); err != nil {
+		s.writeRequestError(w, err)
+		return
+	}
+	if request.Limit < 0 || request.Limit > maxStateDiscoveryLimit {
+		s.writeError(w, http.StatusBadRequest, fmt.Sprintf("limit must be between 1 and %d", maxStateDiscoveryLimit))
+		return
+	}
+	if request.Limit == 0 {
+		request.Limit = defaultStateDiscoveryLimit
+	}
+	offset, err := decodeStatePageToken(request.PageToken, request.Path)
+	if err != nil {
+		s.writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if s.config == nil || s.config.RepositoryRoot == "" {
+		s.writeError(w, http.StatusServiceUnavailable, "State file discovery is not configured")
+		return
+	}
+	root, ok := s.directoryUnderRoot(w, s.config.RepositoryRoot, request.Path, "repository root", "Repository")
+	if !ok {
+		return
+	}
+
+	locations, err := backenddiscovery.ResolveStateLocations(root)
+	if err != nil {
+		s.writeError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to scan repository: %v", err))
+		return
+	}
+
+	// Only the page's states are loaded; resolving locations does not read any state
+	page := locations[min(offset, len(locations)):min(offset+request.Limit, len(locations))]
+	response := StateDiscoveryResponse{
+		Path:       request.Path,
+		Discovered: len(locations),
+		Offset:     offset,
+		Limit:      request.Limit,
+	}
+	if next := offset + len(page); next < len(locations) {
+		response.NextPageToken = encodeStatePageToken(next, request.Path)
+	}
+	response.StateFiles = loadStateFiles(r.Context(), root, page, request.IncludeStates)
+	for _, stateFile := range response.StateFiles {
+		if stateFile.Loaded {
+			response.Loaded++
+		}
+	}
+	w.Header().Set("X-Total-Count", strconv.Itoa(len(locations)))
+	s.writeJSON(w, http.StatusOK, response)
+}
+
+// encodeStatePageToken builds the token for the page of a repository's state files starting
+// at offset
+func encodeStatePageToken(offset int, path string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.Itoa(offset) + ":" + path))
+}
+
+// decodeStatePageToken returns the offset a page token continues from, 0 without a token. A
+// token issued for another repository path is rejected.
+func decodeStatePageToken(token, path string) (int, error) {
+	if token == "" {
+		return 0, nil
+	}
+	decoded, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return 0, errors.New("invalid page_token")
+	}
+	offsetText, tokenPath, found := strings.Cut(string(decoded), ":")
+	offset, err := strconv.Atoi(offsetText)
+	if !found || err != nil || offset < 0 {
+		return 0, errors.New("invalid page_token")
+	}
+	if tokenPath != path {
+		return 0, errors.New("page_token was issued for another path")
+	}
+	return offset, nil
+}
+
+// loadStateFiles loads and summarizes the states of the locations with a pool of workers,
+// keeping the locations' order
+func loadStateFiles(ctx context.Context, root string, locations []backenddiscovery.StateLocation, includeStates bool) []DiscoveredStateFile {
+	stateFiles := make([]DiscoveredStateFile, len(locations))
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for range min(stateDiscoveryWorkers, len(locations)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				stateFiles[i] = loadStateFile(ctx, root, locations[i], includeStates)
+			}
+		}()
+	}
+	for i := range locations {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+	return stateFiles
+}
+
+// loadStateFile loads and summarizes the state of one location. A state whose version has
+// not changed since it was last parsed is not parsed again, and is only read when its
+// document was asked for; a state whose version cannot be read is always loaded.
+func loadStateFile(ctx context.Context, root string, location backenddiscovery.StateLocation, includeStates bool) DiscoveredStateFile {
+	stateFile := DiscoveredStateFile{StateLocation: location}
+	key := root + "|" + location.Directory + "|" + location.Location
+
+	// The version is read before the state, so a state that changes while it is read is
+	// remembered under the older version and parsed again next time
+	version, versionErr := backenddiscovery.StateVersion(ctx, root, location)
+	var summary stateSummary
+	cached := false
+	if versionErr == nil {
+		summary, cached = parsedStates.get(key, version)
+	}
+
+	var data []byte
+	if !cached || includeStates {
+		var err error
+		data, err = backenddiscovery.LoadState(ctx, root, location)
+		if err == nil && !cached {
+			if summary, err = summarizeState(data); err == nil && versionErr == nil {
+				parsedStates.put(key, version, summary)
+			}
+		}
+		if err != nil {
+			stateFile.Error = err.Error()
+			return stateFile
+		}
+	}
+
+	stateFile.Loaded = true
+	stateFile.TerraformVersion = summary.TerraformVersion
+	stateFile.Serial = summary.Serial
+	stateFile.Lineage = summary.Lineage
+	stateFile.Resources = summary.Resources
+	if includeStates {
+		stateFile.State = data
+	}
+	return stateFile
+}
+
+// stateSummary is what state file discovery reports of a parsed state
+type stateSummary struct {
+	TerraformVersion string
+	Serial           int
+	Lineage          string
+	Resources        int
+}
+
+// summarizeState parses a state; data sources are not counted as resources
+func summarizeState(data []byte) (stateSummary, error) {
+	parsed, err := state.NewStateParser().Parse(data)
+	if err != nil {
+		return stateSummary{}, err
+	}
+	summary := stateSummary{
+		TerraformVersion: parsed.TerraformVersion,
+		Serial:           parsed.Serial,
+		Lineage:          parsed.Lineage,
+	}
+	for _, resource := range parsed.Resources {
+		if resource.Mode != "data" {
+			summary.Resources++
+		}
+	}
+	return summary, nil
+}
+
+// parsedState is the summary of a location's state as of a version
+type parsedState struct {
+	version string
+	summary stateSummary
+}
+
+// stateSummaryCache remembers the summary of each location's state as of the version it was
+// parsed at
+type stateSummaryCache struct {
+	mu      sync.Mutex
+	entries map[string]parsedState
+}
+
+// get returns the summary of a location's state when it was parsed at the given version
+func (c *stateSummaryCache) get(key, version string) (stateSummary, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, exists := c.entries[key]
+	if !exists || entry.version != version {
+		return stateSummary{}, false
+	}
+	return entry.summary, true
+}
+
+// put remembers the summary of a location's state at a version, replacing older versions
+func (c *stateSummaryCache) put(key, version string, summary stateSummary) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, exists := c.entries[key]; !exists && len(c.entries) >= maxParsedStates {
+		clear(c.entries)
+	}
+	c.entries[key] = parsedState{version: version, summary: summary}
+}
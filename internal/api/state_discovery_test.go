@@ -0,0 +1,144 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleDiscoverStateFiles(t *testing.T) {
+	root := t.TempDir()
+	repo := filepath.Join(root, "infra")
+	for name, content := range map[string]string{
+		"local/main.tf":           `resource "aws_vpc" "main" {}`,
+		"local/terraform.tfstate": `{"version": 4, "terraform_version": "1.7.5", "serial": 3, "lineage": "abc", "resources": [{"mode": "managed", "type": "aws_vpc", "name": "main"}, {"mode": "data", "type": "aws_ami", "name": "ubuntu"}]}`,
+		"remote/main.tf":          "terraform {\n  backend \"s3\" {\n    key = \"remote.tfstate\"\n  }\n}",
+	} {
+		path := filepath.Join(repo, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	}
+
+	discover := func(server *Server, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		server.handleDiscoverStateFiles(w, httptest.NewRequest(http.MethodPost, "/api/v1/statefiles/discover", strings.NewReader(body)))
+		return w
+	}
+	assert.Equal(t, http.StatusServiceUnavailable, discover(&Server{config: &Config{}}, `{"path": "infra"}`).Code)
+
+	server := &Server{config: &Config{RepositoryRoot: root}}
+	assert.Equal(t, http.StatusBadRequest, discover(server, `{"path": "../etc"}`).Code)
+	assert.Equal(t, http.StatusNotFound, discover(server, `{"path": "missing"}`).Code)
+
+	w := discover(server, `{"path": "infra", "include_states": true}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var response StateDiscoveryResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, 2, response.Discovered)
+	assert.Equal(t, 1, response.Loaded)
+
+	byDirectory := make(map[string]DiscoveredStateFile)
+	for _, stateFile := range response.StateFiles {
+		byDirectory[stateFile.Directory] = stateFile
+	}
+	local := byDirectory["local"]
+	assert.True(t, local.Loaded)
+	assert.Equal(t, "local/terraform.tfstate", local.Location)
+	assert.Equal(t, "1.7.5", local.TerraformVersion)
+	assert.Equal(t, 3, local.Serial)
+	assert.Equal(t, 1, local.Resources, "data sources are not counted")
+	assert.NotEmpty(t, local.State)
+
+	remote := byDirectory["remote"]
+	assert.False(t, remote.Loaded)
+	assert.Equal(t, []string{"bucket", "region"}, remote.Missing)
+	assert.Contains(t, remote.Error, "bucket, region")
+}
+
+func TestHandleDiscoverStateFilesPaging(t *testing.T) {
+	root := t.TempDir()
+	for _, name := range []string{"a", "b", "c"} {
+		path := filepath.Join(root, "infra", name, "terraform.tfstate")
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(`{"version": 4, "serial": 1, "resources": []}`), 0o644))
+		require.NoError(t, os.WriteFile(filepath.Join(filepath.Dir(path), "main.tf"), []byte(`resource "aws_vpc" "main" {}`), 0o644))
+	}
+	server := &Server{config: &Config{RepositoryRoot: root}}
+
+	discover := func(body string) (*httptest.ResponseRecorder, StateDiscoveryResponse) {
+		w := httptest.NewRecorder()
+		server.handleDiscoverStateFiles(w, httptest.NewRequest(http.MethodPost, "/api/v1/statefiles/discover", strings.NewReader(body)))
+		var response StateDiscoveryResponse
+		if w.Code == http.StatusOK {
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		}
+		return w, response
+	}
+
+	var directories []string
+	token := ""
+	for page := 0; page < 2; page++ {
+		w, response := discover(`{"path": "infra", "limit": 2, "page_token": "` + token + `"}`)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		assert.Equal(t, "3", w.Header().Get("X-Total-Count"))
+		assert.Equal(t, 3, response.Discovered)
+		assert.Equal(t, len(response.StateFiles), response.Loaded)
+		for _, stateFile := range response.StateFiles {
+			directories = append(directories, stateFile.Directory)
+		}
+		token = response.NextPageToken
+	}
+	assert.Equal(t, []string{"a", "b", "c"}, directories)
+	assert.Empty(t, token, "the last page has no next page")
+
+	w, _ := discover(`{"path": "infra", "limit": 1000}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w, _ = discover(`{"path": "infra", "page_token": "not-a-token"}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w, _ = discover(`{"path": "other", "page_token": "` + encodeStatePageToken(2, "infra") + `"}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code, "tokens only continue the path they were issued for")
+}
+
+func TestHandleDiscoverStateFilesCache(t *testing.T) {
+	root := t.TempDir()
+	statePath := filepath.Join(root, "infra", "terraform.tfstate")
+	require.NoError(t, os.MkdirAll(filepath.Dir(statePath), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "infra", "main.tf"), []byte(`resource "aws_vpc" "main" {}`), 0o644))
+	server := &Server{config: &Config{RepositoryRoot: root}}
+
+	// States of the same size, so only the modification time tells them apart
+	writeState := func(serial int, modified time.Time) {
+		content := fmt.Sprintf(`{"version": 4, "serial": %d, "resources": []}`, serial)
+		require.NoError(t, os.WriteFile(statePath, []byte(content), 0o644))
+		require.NoError(t, os.Chtimes(statePath, modified, modified))
+	}
+	serial := func() int {
+		w := httptest.NewRecorder()
+		server.handleDiscoverStateFiles(w, httptest.NewRequest(http.MethodPost, "/api/v1/statefiles/discover", strings.NewReader(`{"path": "."}`)))
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var response StateDiscoveryResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		require.Len(t, response.StateFiles, 1)
+		require.True(t, response.StateFiles[0].Loaded, response.StateFiles[0].Error)
+		return response.StateFiles[0].Serial
+	}
+
+	modified := time.Now().Add(-time.Hour).Truncate(time.Second)
+	writeState(1, modified)
+	assert.Equal(t, 1, serial())
+
+	writeState(2, modified)
+	assert.Equal(t, 1, serial(), "an unchanged modification time reuses the parsed state")
+
+	writeState(3, modified.Add(time.Minute))
+	assert.Equal(t, 3, serial(), "a new modification time bypasses the cache")
+}
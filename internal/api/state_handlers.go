@@ -1,9 +1,9 @@
 package api
 
 import (
-	"encoding/json"
 	"fmt"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/catherinevee/driftmgr/internal/services"
@@ -64,21 +64,17 @@ func (h *StateHandlers) ListStateFiles(w http.ResponseWriter, r *http.Request) {
 		stateFiles = append(stateFiles, stateFile)
 	}
 
-	// Apply pagination
-	start := (page - 1) * limit
-	end := start + limit
-
-	if start >= len(stateFiles) {
-		stateFiles = []StateFile{}
-	} else {
-		if end > len(stateFiles) {
-			end = len(stateFiles)
-		}
-		stateFiles = stateFiles[start:end]
+	// The service returned only the requested page; count every match for the page metadata
+	total, err := h.stateService.CountStateFiles(r.Context(), filters)
+	if err != nil {
+		response := NewResponseWriter(w)
+		response.WriteInternalError("Failed to count state files: " + err.Error())
+		return
 	}
+	w.Header().Set("X-Total-Count", strconv.Itoa(total))
 
 	response := NewResponseWriter(w)
-	err = response.WritePaginationResponse(stateFiles, page, limit, len(stateFiles))
+	err = response.WritePaginationResponse(stateFiles, page, limit, total)
 	if err != nil {
 		response.WriteInternalError("Failed to encode response")
 		return
@@ -185,9 +181,9 @@ func (h *StateHandlers) ImportResource(w http.ResponseWriter, r *http.Request) {
 		Provider     string `json:"provider"`
 	}
 
-	if err := json.NewDecoder(r.Body).Decode(&importRequest); err != nil {
+	if err := decodeRequest(r, &importRequest); err != nil {
 		response := NewResponseWriter(w)
-		response.WriteValidationError("Invalid request body", err.Error())
+		response.WriteRequestError(err)
 		return
 	}
 
@@ -300,9 +296,9 @@ func (h *StateHandlers) MoveResource(w http.ResponseWriter, r *http.Request) {
 		NewResourceID string `json:"new_resource_id"`
 	}
 
-	if err := json.NewDecoder(r.Body).Decode(&moveRequest); err != nil {
+	if err := decodeRequest(r, &moveRequest); err != nil {
 		response := NewResponseWriter(w)
-		response.WriteValidationError("Invalid request body", err.Error())
+		response.WriteRequestError(err)
 		return
 	}
 
@@ -345,9 +341,9 @@ func (h *StateHandlers) LockStateFile(w http.ResponseWriter, r *http.Request) {
 		Who       string `json:"who"`
 	}
 
-	if err := json.NewDecoder(r.Body).Decode(&lockRequest); err != nil {
+	if err := decodeRequest(r, &lockRequest); err != nil {
 		response := NewResponseWriter(w)
-		response.WriteValidationError("Invalid request body", err.Error())
+		response.WriteRequestError(err)
 		return
 	}
 
@@ -399,9 +395,9 @@ func (h *StateHandlers) UnlockStateFile(w http.ResponseWriter, r *http.Request)
 		Force     bool   `json:"force,omitempty"`
 	}
 
-	if err := json.NewDecoder(r.Body).Decode(&unlockRequest); err != nil {
+	if err := decodeRequest(r, &unlockRequest); err != nil {
 		response := NewResponseWriter(w)
-		response.WriteValidationError("Invalid request body", err.Error())
+		response.WriteRequestError(err)
 		return
 	}
 
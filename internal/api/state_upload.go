@@ -0,0 +1,233 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/catherinevee/driftmgr/internal/auth"
+	"github.com/catherinevee/driftmgr/internal/state"
+	"github.com/catherinevee/driftmgr/internal/websocket"
+	"github.com/google/uuid"
+)
+
+const (
+	// stateUploadDir is the subdirectory of the data directory uploaded state files are kept in
+	stateUploadDir = "uploads"
+	// defaultMaxStateUploadSize bounds an uploaded state file unless configured otherwise
+	defaultMaxStateUploadSize = 256 << 20
+	// multipartOverhead allows for the multipart headers and boundaries around the file
+	multipartOverhead = 1 << 20
+	// uploadProgressStep is the least number of bytes between upload progress updates
+	uploadProgressStep = 4 << 20
+)
+
+// handleStateUpload handles POST /api/v1/state/upload, a multipart form whose file field holds
+// a Terraform state file. The file is streamed to disk rather than held in memory, with
+// progress broadcast over WebSocket under the job_id query parameter or a generated ID. The
+// client's file name is reduced to its base name and only reported back; the file is stored
+// under a generated name in the data directory's uploads subdirectory. Content that does not
+// parse as Terraform state is rejected with 400 and not kept.
+func (s *Server) handleStateUpload(w http.ResponseWriter, r *http.Request) {
+	limit := s.maxStateUploadSize()
+	r.Body = http.MaxBytesReader(w, r.Body, limit+multipartOverhead)
+	reader, err := r.MultipartReader()
+	if err != nil {
+		s.writeError(w, http.StatusBadRequest, fmt.Sprintf("Invalid upload: %v", err))
+		return
+	}
+
+	var part io.ReadCloser
+	var filename string
+	for {
+		next, err := reader.NextPart()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				s.writeError(w, http.StatusBadRequest, "Invalid upload: the file field is required")
+			} else {
+				s.writeUploadError(w, limit, err)
+			}
+			return
+		}
+		if next.FormName() == "file" {
+			part, filename = next, next.FileName()
+			break
+		}
+		next.Close()
+	}
+	defer part.Close()
+
+	dir := filepath.Join(s.dataDir(), stateUploadDir)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		s.writeError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to create upload directory: %v", err))
+		return
+	}
+	tmp, err := os.CreateTemp(dir, ".upload-*")
+	if err != nil {
+		s.writeError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to save upload: %v", err))
+		return
+	}
+	kept := false
+	defer func() {
+		tmp.Close()
+		if !kept {
+			os.Remove(tmp.Name())
+		}
+	}()
+
+	jobID := jobIDOrNew(r.URL.Query().Get("job_id"))
+	progress := &uploadProgressReader{
+		reader: io.LimitReader(part, limit+1),
+		total:  r.ContentLength,
+		report: func(read, total int64) {
+			update := websocket.NewProgressUpdate(jobID, "uploading", int(read), int(total))
+			update.Message = fmt.Sprintf("Received %d MB", read>>20)
+			s.broadcastStateUploadProgress(r, update)
+		},
+	}
+	size, err := io.Copy(tmp, progress)
+	if err != nil {
+		s.writeUploadError(w, limit, err)
+		return
+	}
+	if size > limit {
+		s.writeUploadError(w, limit, &http.MaxBytesError{Limit: limit})
+		return
+	}
+
+	if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+		s.writeError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to read upload: %v", err))
+		return
+	}
+	parsed, err := parseUploadedState(tmp)
+	if err != nil {
+		update := websocket.NewProgressUpdate(jobID, "failed", 0, 0)
+		update.Error = err.Error()
+		s.broadcastStateUploadProgress(r, update)
+		s.writeError(w, http.StatusBadRequest, fmt.Sprintf("Not a Terraform state file: %v", err))
+		return
+	}
+
+	id := "upload-" + uuid.New().String()
+	path, err := dataFilePath(dir, id+".tfstate")
+	if err != nil {
+		s.writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		s.writeError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to save upload: %v", err))
+		return
+	}
+	kept = true
+
+	update := websocket.NewProgressUpdate(jobID, "completed", 1, 1)
+	update.Message = fmt.Sprintf("Uploaded %d resources", len(parsed.Resources))
+	s.broadcastStateUploadProgress(r, update)
+	s.writeJSON(w, http.StatusCreated, StateUploadResponse{
+		ID:               id,
+		JobID:            jobID,
+		Filename:         uploadBaseName(filename),
+		Size:             size,
+		Version:          parsed.Version,
+		TerraformVersion: parsed.TerraformVersion,
+		Serial:           parsed.Serial,
+		Lineage:          parsed.Lineage,
+		Resources:        len(parsed.Resources),
+		Tool:             string(parsed.Tool),
+	})
+}
+
+// maxStateUploadSize returns the configured state upload limit, else the default
+func (s *Server) maxStateUploadSize() int64 {
+	if s.config != nil && s.config.MaxStateUploadSize > 0 {
+		return s.config.MaxStateUploadSize
+	}
+	return defaultMaxStateUploadSize
+}
+
+// writeUploadError responds 413 when an upload ran past the size limit, otherwise 400
+func (s *Server) writeUploadError(w http.ResponseWriter, limit int64, err error) {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		s.writeError(w, http.StatusRequestEntityTooLarge, fmt.Sprintf("State file exceeds %d MB", limit>>20))
+		return
+	}
+	s.writeError(w, http.StatusBadRequest, fmt.Sprintf("Invalid upload: %v", err))
+}
+
+// broadcastStateUploadProgress sends a state upload progress update to the uploading user's
+// WebSocket clients
+func (s *Server) broadcastStateUploadProgress(r *http.Request, update websocket.ProgressUpdate) {
+	if s.services == nil || s.services.WebSocket == nil {
+		return
+	}
+	update.User, _ = auth.GetUsernameFromContext(r.Context())
+	s.services.WebSocket.BroadcastProgress(websocket.MessageTypeStateUploadProgress, update)
+}
+
+// uploadProgressReader reports how much of an upload has been read, at most once per percent
+// of the total or per uploadProgressStep bytes, whichever is larger. A total of -1 means the
+// size is unknown.
+type uploadProgressReader struct {
+	reader io.Reader
+	total  int64
+	read   int64
+	next   int64
+	report func(read, total int64)
+}
+
+func (p *uploadProgressReader) Read(buf []byte) (int, error) {
+	n, err := p.reader.Read(buf)
+	p.read += int64(n)
+	if p.read >= p.next && n > 0 {
+		total := p.total
+		if total < 0 {
+			total = 0
+		}
+		p.report(p.read, total)
+		step := int64(uploadProgressStep)
+		if total/100 > step {
+			step = total / 100
+		}
+		p.next = p.read + step
+	}
+	return n, err
+}
+
+// parseUploadedState decodes uploaded content as Terraform state without holding it in memory
+// twice. Any JSON object would decode, so the content must also carry a state format version
+// and a resources list.
+func parseUploadedState(r io.Reader) (*state.StateFile, error) {
+	var content struct {
+		state.TerraformState
+		Generator string `json:"generator"`
+	}
+	if err := json.NewDecoder(r).Decode(&content); err != nil {
+		return nil, fmt.Errorf("failed to parse state: %w", err)
+	}
+	if content.Version == 0 {
+		return nil, fmt.Errorf("missing state format version")
+	}
+	if content.Resources == nil && len(content.Modules) == 0 {
+		return nil, fmt.Errorf("missing resources")
+	}
+	return &state.StateFile{
+		TerraformState: &content.TerraformState,
+		Tool:           state.DetectStateTool(&content.TerraformState, content.Generator),
+	}, nil
+}
+
+// uploadBaseName reduces a client-supplied file name, which may be a path in either
+// separator style, to its base name
+func uploadBaseName(name string) string {
+	name = filepath.Base(strings.ReplaceAll(name, `\`, "/"))
+	if name == "." || name == "/" || name == ".." {
+		return ""
+	}
+	return name
+}
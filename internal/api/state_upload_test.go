@@ -0,0 +1,97 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func uploadRequest(t *testing.T, filename, content string) *http.Request {
+	t.Helper()
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	part, err := form.CreateFormFile("file", filename)
+	require.NoError(t, err)
+	part.Write([]byte(content))
+	require.NoError(t, form.Close())
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/state/upload", &body)
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	return req
+}
+
+func TestHandleStateUpload(t *testing.T) {
+	dir := t.TempDir()
+	server := &Server{config: &Config{DataDir: dir}}
+	upload := func(filename, content string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		server.handleStateUpload(w, uploadRequest(t, filename, content))
+		return w
+	}
+
+	w := upload("../../etc/x", `{"version": 4, "terraform_version": "1.6.0", "serial": 2, "lineage": "l1", "resources": [{"mode": "managed", "type": "aws_vpc", "name": "main"}]}`)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var response StateUploadResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "x", response.Filename)
+	assert.Equal(t, 1, response.Resources)
+	assert.Equal(t, "1.6.0", response.TerraformVersion)
+
+	// The upload is stored under a generated name, never the client's path
+	_, err := os.Stat(filepath.Join(dir, stateUploadDir, response.ID+".tfstate"))
+	assert.NoError(t, err)
+
+	for _, content := range []string{"not json", `{"hello": "world"}`, `{"version": 4}`} {
+		assert.Equal(t, http.StatusBadRequest, upload("state.tfstate", content).Code, content)
+	}
+	entries, err := os.ReadDir(filepath.Join(dir, stateUploadDir))
+	require.NoError(t, err)
+	assert.Len(t, entries, 1, "rejected uploads are not kept")
+}
+
+func TestHandleStateUploadLimit(t *testing.T) {
+	server := &Server{config: &Config{DataDir: t.TempDir(), MaxStateUploadSize: 64}}
+	w := httptest.NewRecorder()
+	server.handleStateUpload(w, uploadRequest(t, "big.tfstate", `{"version": 4, "resources": [], "padding": "`+strings.Repeat("x", 128)+`"}`))
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+
+	w = httptest.NewRecorder()
+	server.handleStateUpload(w, httptest.NewRequest(http.MethodPost, "/api/v1/state/upload", strings.NewReader("{}")))
+	assert.Equal(t, http.StatusBadRequest, w.Code, "uploads must be multipart")
+}
+
+func TestUploadProgressReader(t *testing.T) {
+	total := int64(10 << 20)
+	var reported []int64
+	reader := &uploadProgressReader{
+		reader: bytes.NewReader(make([]byte, total)),
+		total:  total,
+		report: func(read, total int64) { reported = append(reported, read) },
+	}
+	n, err := io.Copy(io.Discard, reader)
+	require.NoError(t, err)
+	assert.Equal(t, total, n)
+	assert.Len(t, reported, 3, "progress is reported at the start and every 4 MB")
+}
+
+func TestUploadBaseName(t *testing.T) {
+	for name, want := range map[string]string{
+		"terraform.tfstate":       "terraform.tfstate",
+		"../../etc/x":             "x",
+		`..\..\windows\x.tfstate`: "x.tfstate",
+		"..":                      "",
+		"/":                       "",
+	} {
+		assert.Equal(t, want, uploadBaseName(name), name)
+	}
+}
@@ -0,0 +1,126 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/catherinevee/driftmgr/internal/auth"
+	"github.com/catherinevee/driftmgr/internal/compliance"
+	"github.com/catherinevee/driftmgr/internal/remediation"
+	"github.com/catherinevee/driftmgr/pkg/models"
+)
+
+// resourceTaggers create the taggers bulk tag remediation runs through. They load credentials
+// when created, so each is created on first use.
+var resourceTaggers = map[string]func() (remediation.ResourceTagger, error){
+	"aws":   func() (remediation.ResourceTagger, error) { return remediation.NewAWSProvider() },
+	"azure": func() (remediation.ResourceTagger, error) { return remediation.NewAzureProvider() },
+	"gcp":   func() (remediation.ResourceTagger, error) { return remediation.NewGCPProvider() },
+}
+
+// handleTagViolations handles GET /api/v1/tags/violations. It checks the most recently
+// discovered resources the user may see against the configured tag policy and lists each
+// resource with missing or invalid tags. required=Owner,CostCenter replaces the policy's
+// required keys for the request, and provider, type and region narrow the resources checked.
+func (s *Server) handleTagViolations(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	var policy compliance.TagPolicy
+	if s.config != nil {
+		policy = s.config.TagPolicy
+	}
+	if required := query.Get("required"); required != "" {
+		policy.Required = nil
+		for _, key := range strings.Split(required, ",") {
+			if key = strings.TrimSpace(key); key != "" {
+				policy.Required = append(policy.Required, key)
+			}
+		}
+	}
+	if len(policy.Required) == 0 && len(policy.AllowedValues) == 0 {
+		s.writeError(w, http.StatusBadRequest, "No tag policy is configured; set tag_policy or pass required tag keys")
+		return
+	}
+
+	analyzer, err := compliance.NewTagPolicyAnalyzer(policy)
+	if err != nil {
+		s.writeError(w, http.StatusInternalServerError, fmt.Sprintf("Invalid tag policy: %v", err))
+		return
+	}
+
+	resourceType, region := query.Get("type"), query.Get("region")
+	var resources []models.Resource
+	for _, resource := range liveResources(r, query.Get("provider")) {
+		if (resourceType == "" || resource.Type == resourceType) && (region == "" || resource.Region == region) {
+			resources = append(resources, resource)
+		}
+	}
+
+	violations := analyzer.Analyze(resources)
+	response := TagViolationsResponse{
+		Policy:    policy,
+		Checked:   len(resources),
+		Violating: len(violations),
+		Resources: violations,
+	}
+	s.writeJSON(w, http.StatusOK, response)
+}
+
+// handleTagRemediation handles POST /api/v1/remediate/tags, the tag_compliance remediation. It
+// adds the requested tags to the selected discovered resources that lack them and reports
+// which were tagged and which failed. Dry runs report the tags without applying them.
+func (s *Server) handleTagRemediation(w http.ResponseWriter, r *http.Request) {
+	var request TagRemediationRequest
+	if err := decodeRequest(r, &request); err != nil {
+		s.writeRequestError(w, err)
+		return
+	}
+	create, ok := resourceTaggers[request.Provider]
+	if !ok {
+		s.writeError(w, http.StatusBadRequest, fmt.Sprintf("Unsupported provider %q", request.Provider))
+		return
+	}
+	if err := auth.CheckScope(r.Context(), request.Provider, request.AccountID); err != nil {
+		s.writeError(w, http.StatusForbidden, err.Error())
+		return
+	}
+	if s.services == nil || s.services.Tagging == nil {
+		s.writeError(w, http.StatusServiceUnavailable, "Tag remediation is not available")
+		return
+	}
+
+	selected := make(map[string]bool, len(request.ResourceIDs))
+	for _, id := range request.ResourceIDs {
+		selected[id] = true
+	}
+	var resources []models.Resource
+	for _, resource := range liveResources(r, request.Provider) {
+		if (request.AccountID == "" || resource.AccountID == request.AccountID) &&
+			(request.Region == "" || resource.Region == request.Region) &&
+			(request.ResourceType == "" || resource.Type == request.ResourceType) &&
+			(len(selected) == 0 || selected[resource.ID]) {
+			resources = append(resources, resource)
+		}
+	}
+
+	tagging := s.services.Tagging
+	if !request.DryRun && !tagging.HasTagger(request.Provider) {
+		tagger, err := create()
+		if err != nil {
+			s.writeError(w, http.StatusServiceUnavailable, fmt.Sprintf("Failed to connect to %s: %v", request.Provider, err))
+			return
+		}
+		tagging.RegisterTagger(request.Provider, tagger)
+	}
+
+	result, err := tagging.Apply(r.Context(), resources, remediation.TagComplianceOptions{
+		Tags:      request.Tags,
+		Overwrite: request.Overwrite,
+		DryRun:    request.DryRun,
+	})
+	if err != nil {
+		s.writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	s.writeJSON(w, http.StatusOK, result)
+}
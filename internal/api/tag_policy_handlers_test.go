@@ -0,0 +1,91 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/catherinevee/driftmgr/internal/compliance"
+	"github.com/catherinevee/driftmgr/internal/remediation"
+	"github.com/catherinevee/driftmgr/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleTagViolations(t *testing.T) {
+	store := GetGlobalResourceStore()
+	store.Save("tag-violations-test", []models.Resource{
+		{ID: "i-tagged", Type: "aws_instance", Provider: "tag-test", Tags: map[string]string{"Owner": "a", "Environment": "prod"}},
+		{ID: "i-untagged", Type: "aws_instance", Provider: "tag-test"},
+		{ID: "b-invalid", Type: "aws_s3_bucket", Provider: "tag-test", Tags: map[string]string{"Owner": "b", "Environment": "qa"}},
+	}, time.Now())
+	t.Cleanup(func() { store.Save("tag-violations-test", nil, time.Now()) })
+
+	server := &Server{config: &Config{TagPolicy: compliance.TagPolicy{
+		Required:      []string{"Owner", "Environment"},
+		AllowedValues: map[string]string{"Environment": "dev|prod"},
+	}}}
+	get := func(target string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		server.handleTagViolations(w, httptest.NewRequest(http.MethodGet, target, nil))
+		return w
+	}
+
+	w := get("/api/v1/tags/violations?provider=tag-test")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var response TagViolationsResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, 3, response.Checked)
+	assert.Equal(t, 2, response.Violating)
+	require.Len(t, response.Resources, 2)
+	assert.Equal(t, "i-untagged", response.Resources[0].ResourceID)
+	assert.Len(t, response.Resources[0].Violations, 2)
+	assert.Equal(t, "b-invalid", response.Resources[1].ResourceID)
+	assert.Equal(t, compliance.TagInvalid, response.Resources[1].Violations[0].Problem)
+
+	w = get("/api/v1/tags/violations?provider=tag-test&type=aws_instance&required=Owner")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, 2, response.Checked)
+	require.Len(t, response.Resources, 1)
+	assert.Equal(t, []string{"Owner"}, response.Policy.Required, "required replaces the configured keys")
+
+	server.config = &Config{}
+	assert.Equal(t, http.StatusBadRequest, get("/api/v1/tags/violations").Code)
+}
+
+func TestHandleTagRemediationDryRun(t *testing.T) {
+	store := GetGlobalResourceStore()
+	store.Save("tag-remediation-test", []models.Resource{
+		{ID: "i-tag-1", Type: "aws_instance", Provider: "aws", AccountID: "tag-remediation", Region: "us-east-1"},
+		{ID: "i-tag-2", Type: "aws_instance", Provider: "aws", AccountID: "tag-remediation", Region: "us-east-1",
+			Tags: map[string]string{"Owner": "team-a", "Environment": "us-east-1"}},
+		{ID: "i-tag-3", Type: "aws_instance", Provider: "aws", AccountID: "tag-remediation", Region: "eu-west-1"},
+	}, time.Now())
+	t.Cleanup(func() { store.Save("tag-remediation-test", nil, time.Now()) })
+
+	server := &Server{services: &Services{Tagging: remediation.NewTagComplianceRemediator()}}
+	post := func(body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		server.handleTagRemediation(w, httptest.NewRequest(http.MethodPost, "/api/v1/remediate/tags", strings.NewReader(body)))
+		return w
+	}
+
+	w := post(`{"provider": "aws", "account_id": "tag-remediation", "region": "us-east-1", "dry_run": true,
+		"tags": {"Owner": "platform", "Environment": "{{.Region}}"}}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var result remediation.TagComplianceResult
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &result))
+	assert.True(t, result.DryRun)
+	assert.Equal(t, 1, result.Compliant)
+	require.Len(t, result.Tagged, 1)
+	assert.Equal(t, "i-tag-1", result.Tagged[0].ResourceID)
+	assert.Equal(t, map[string]string{"Owner": "platform", "Environment": "us-east-1"}, result.Tagged[0].Tags)
+
+	assert.Equal(t, http.StatusBadRequest, post(`{"provider": "aws"}`).Code)
+	assert.Equal(t, http.StatusBadRequest, post(`{"provider": "oracle", "tags": {"Owner": "x"}}`).Code)
+	assert.Equal(t, http.StatusBadRequest, post(`{"provider": "aws", "dry_run": true, "tags": {"Owner": "{{"}}`).Code)
+}
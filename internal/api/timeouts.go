@@ -0,0 +1,56 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+)
+
+const (
+	// Server timeouts used when the config leaves them unset
+	defaultReadTimeout  = 30 * time.Second
+	defaultWriteTimeout = 30 * time.Second
+	defaultIdleTimeout  = 120 * time.Second
+	// defaultLongRequestTimeout bounds long-running routes unless configured otherwise
+	defaultLongRequestTimeout = 30 * time.Minute
+)
+
+// timeoutOr returns timeout, or fallback when it is unset
+func timeoutOr(timeout, fallback time.Duration) time.Duration {
+	if timeout > 0 {
+		return timeout
+	}
+	return fallback
+}
+
+// longRequestTimeout returns the configured timeout of long-running routes, else the default
+func (s *Server) longRequestTimeout() time.Duration {
+	if s.config != nil {
+		return timeoutOr(s.config.LongRequestTimeout, defaultLongRequestTimeout)
+	}
+	return defaultLongRequestTimeout
+}
+
+// longRunning gives a long-running handler, such as a synchronous discovery of every region
+// or a large state upload, long_request_timeout to read its request and write its response in
+// place of the server's read and write timeouts, which would otherwise cut the response off.
+// The request's context ends at the same deadline, so the work stops once its response could
+// no longer be written.
+func (s *Server) longRunning(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		deadline := time.Now().Add(s.longRequestTimeout())
+		controller := http.NewResponseController(w)
+		if err := controller.SetReadDeadline(deadline); err != nil && !errors.Is(err, http.ErrNotSupported) {
+			log.Printf("Failed to extend read deadline for %s: %v", r.URL.Path, err)
+		}
+		if err := controller.SetWriteDeadline(deadline); err != nil && !errors.Is(err, http.ErrNotSupported) {
+			log.Printf("Failed to extend write deadline for %s: %v", r.URL.Path, err)
+		}
+
+		ctx, cancel := context.WithDeadline(r.Context(), deadline)
+		defer cancel()
+		next(w, r.WithContext(ctx))
+	}
+}
@@ -0,0 +1,43 @@
+package api
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLongRunningOutlivesWriteTimeout(t *testing.T) {
+	s := &Server{config: &Config{LongRequestTimeout: time.Minute}}
+	slow := func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(150 * time.Millisecond)
+		w.Write([]byte("discovered"))
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/short", slow)
+	mux.HandleFunc("/long", s.longRunning(slow))
+	server := httptest.NewUnstartedServer(mux)
+	server.Config.WriteTimeout = 50 * time.Millisecond
+	server.Start()
+	defer server.Close()
+
+	_, err := http.Get(server.URL + "/short")
+	assert.Error(t, err, "the write timeout cuts off a slow response")
+
+	response, err := http.Get(server.URL + "/long")
+	require.NoError(t, err)
+	defer response.Body.Close()
+	body, err := io.ReadAll(response.Body)
+	require.NoError(t, err)
+	assert.Equal(t, "discovered", string(body))
+}
+
+func TestLongRequestTimeout(t *testing.T) {
+	assert.Equal(t, defaultLongRequestTimeout, (&Server{config: &Config{}}).longRequestTimeout())
+	assert.Equal(t, time.Hour, (&Server{config: &Config{LongRequestTimeout: time.Hour}}).longRequestTimeout())
+	assert.Equal(t, defaultWriteTimeout, timeoutOr(0, defaultWriteTimeout))
+}
@@ -1,7 +1,23 @@
 package api
 
 import (
+	"encoding/json"
 	"time"
+
+	"github.com/catherinevee/driftmgr/internal/audit"
+	"github.com/catherinevee/driftmgr/internal/compliance"
+	"github.com/catherinevee/driftmgr/internal/cost"
+	backenddiscovery "github.com/catherinevee/driftmgr/internal/discovery/backend"
+	"github.com/catherinevee/driftmgr/internal/drift/detector"
+	"github.com/catherinevee/driftmgr/internal/drift/tfplan"
+	"github.com/catherinevee/driftmgr/internal/jobs"
+	awsprovider "github.com/catherinevee/driftmgr/internal/providers/aws"
+	azureprovider "github.com/catherinevee/driftmgr/internal/providers/azure"
+	gcpprovider "github.com/catherinevee/driftmgr/internal/providers/gcp"
+	"github.com/catherinevee/driftmgr/internal/remediation"
+	"github.com/catherinevee/driftmgr/internal/state"
+	"github.com/catherinevee/driftmgr/internal/terragrunt/resolver"
+	"github.com/catherinevee/driftmgr/pkg/models"
 )
 
 // APIResponse represents a standardized API response
@@ -14,9 +30,10 @@ type APIResponse struct {
 
 // APIError represents an API error
 type APIError struct {
-	Code    string `json:"code"`
-	Message string `json:"message"`
-	Details string `json:"details,omitempty"`
+	Code    string       `json:"code"`
+	Message string       `json:"message"`
+	Details string       `json:"details,omitempty"`
+	Fields  []FieldError `json:"fields,omitempty"`
 }
 
 // APIMeta represents response metadata
@@ -152,10 +169,12 @@ type BackendDiscoveryRequest struct {
 	Paths     []string `json:"paths"`
 	Recursive bool     `json:"recursive"`
 	Types     []string `json:"types,omitempty"`
+	JobID     string   `json:"job_id,omitempty"` // correlates WebSocket progress; generated when empty
 }
 
 // BackendDiscoveryResponse represents a backend discovery response
 type BackendDiscoveryResponse struct {
+	JobID    string    `json:"job_id"`
 	Count    int       `json:"count"`
 	Backends []Backend `json:"backends"`
 	Errors   []string  `json:"errors,omitempty"`
@@ -185,6 +204,9 @@ type DriftDetectionRequest struct {
 	Regions     []string `json:"regions,omitempty"`
 	Incremental bool     `json:"incremental"`
 	UseCache    bool     `json:"use_cache"`
+	// Tags and TagKeyExists limit detection to resources with matching tag values or keys
+	Tags         map[string]string `json:"tags,omitempty"`
+	TagKeyExists []string          `json:"tag_key_exists,omitempty"`
 }
 
 // DriftDetectionResponse represents a drift detection response
@@ -196,6 +218,702 @@ type DriftDetectionResponse struct {
 	Message    string        `json:"message"`
 }
 
+// ExportRequest represents a drift results export request
+type ExportRequest struct {
+	Format   string `json:"format"` // json, csv, sarif, junit
+	Provider string `json:"provider,omitempty"`
+	Status   string `json:"status,omitempty"`
+	Inline   bool   `json:"inline,omitempty"` // stream the file in the response instead of writing to disk
+}
+
+// InventoryExportRequest represents a request to export the discovered inventory
+type InventoryExportRequest struct {
+	Format    string `json:"format"` // tf-import, json
+	Provider  string `json:"provider,omitempty"`
+	AccountID string `json:"account_id,omitempty"`
+	Region    string `json:"region,omitempty"`
+	Inline    bool   `json:"inline,omitempty"`
+}
+
+// ExportResponse represents an export written to the output directory
+type ExportResponse struct {
+	Filename   string    `json:"filename"`
+	Format     string    `json:"format"`
+	Size       int64     `json:"size"`
+	Path       string    `json:"path"`
+	URL        string    `json:"url"`
+	Count      int       `json:"count"`
+	ExportedAt time.Time `json:"exported_at"`
+}
+
+// RemediationRequest represents a request to remediate a single drift result
+type RemediationRequest struct {
+	DriftID         string `json:"drift_id"`
+	DryRun          bool   `json:"dry_run"`
+	WorkDir         string `json:"work_dir,omitempty"`         // run generated terraform commands in this directory
+	RequireApproval bool   `json:"require_approval,omitempty"` // hold the remediation until an approver runs it
+	Force           bool   `json:"force,omitempty"`            // allow recreating or deleting stateful resources
+}
+
+// BatchRemediationRequest represents a request to remediate several drift results
+type BatchRemediationRequest struct {
+	DriftIDs        []string `json:"drift_ids,omitempty"`       // defaults to every stored drift result
+	SeverityFilter  string   `json:"severity_filter,omitempty"` // minimum severity: low, medium, high, critical
+	DryRun          bool     `json:"dry_run"`
+	WorkDir         string   `json:"work_dir,omitempty"`         // run generated terraform commands in this directory
+	JobID           string   `json:"job_id,omitempty"`           // correlates WebSocket progress; generated when empty
+	RequireApproval bool     `json:"require_approval,omitempty"` // hold the batch until an approver runs it
+	PreviewToken    string   `json:"preview_token,omitempty"`    // from /remediate/batch/preview; required unless dry_run
+	Force           bool     `json:"force,omitempty"`            // allow recreating or deleting stateful resources
+}
+
+// BatchRemediationPreview describes what a batch remediation would do, grouping the drifted
+// resources by whether their remediation is destructive, safe or an import. PreviewToken must
+// be passed back with the batch before PreviewExpiresAt to run exactly this plan.
+type BatchRemediationPreview struct {
+	DriftIDs         []string            `json:"drift_ids"`
+	RiskLevel        string              `json:"risk_level"`
+	RequiresApproval bool                `json:"requires_approval"`
+	Destructive      []BatchPreviewEntry `json:"destructive"` // recreated or removed from management
+	Safe             []BatchPreviewEntry `json:"safe"`        // updated in place or created
+	Import           []BatchPreviewEntry `json:"import"`      // imported unchanged
+	BlastRadius      BatchBlastRadius    `json:"blast_radius"`
+	PreviewToken     string              `json:"preview_token"`
+	PreviewExpiresAt time.Time           `json:"preview_expires_at"`
+}
+
+// BatchPreviewEntry is a drifted resource and the actions a batch would take on it
+type BatchPreviewEntry struct {
+	DriftID      string          `json:"drift_id"`
+	Resource     string          `json:"resource"`
+	ResourceType string          `json:"resource_type"`
+	Provider     string          `json:"provider"`
+	Actions      []PlannedAction `json:"actions"`
+	Dependents   []string        `json:"dependents,omitempty"` // discovered resources relying on it, directly or not
+}
+
+// BatchBlastRadius estimates how far a batch reaches beyond the resources it remediates, from
+// the relationships between discovered resources
+type BatchBlastRadius struct {
+	Resources  int      `json:"resources"`  // resources the batch remediates
+	Dependents []string `json:"dependents"` // other resources relying on them
+	Disrupted  []string `json:"disrupted"`  // those relying on a resource that is recreated or removed
+}
+
+// RemediationResult represents the outcome of remediating a single drift result
+type RemediationResult struct {
+	DriftID      string                 `json:"drift_id"`
+	PlanID       string                 `json:"plan_id,omitempty"`
+	Status       string                 `json:"status"`
+	Executed     bool                   `json:"executed"`
+	ActionCount  int                    `json:"action_count"`
+	Commands     []string               `json:"commands,omitempty"` // generated commands, e.g. terraform import
+	Details      map[string]interface{} `json:"details,omitempty"` // snapshot_id links to the pre-remediation snapshot
+	Timestamp    time.Time              `json:"timestamp"`
+	ErrorMessage string                 `json:"error_message,omitempty"`
+}
+
+// RemediationHistoryResponse is a page of recorded remediation outcomes. Total counts every
+// matching entry, not just this page.
+type RemediationHistoryResponse struct {
+	Entries []remediation.HistoryEntry `json:"entries"`
+	Total   int                        `json:"total"`
+	Limit   int                        `json:"limit"`
+	Offset  int                        `json:"offset"`
+}
+
+// AuditLogResponse is a page of audit entries. Total counts every matching entry, not just
+// this page.
+type AuditLogResponse struct {
+	Entries []audit.Entry `json:"entries"`
+	Total   int           `json:"total"`
+	Limit   int           `json:"limit"`
+	Offset  int           `json:"offset"`
+}
+
+// JobAcceptedResponse is returned when a job is queued to run in the background; poll
+// StatusURL for its progress and result
+type JobAcceptedResponse struct {
+	JobID     string      `json:"job_id"`
+	Status    jobs.Status `json:"status"`
+	StatusURL string      `json:"status_url"`
+}
+
+// ResourceDetailResponse is a discovered resource with the resources that depend on it and the
+// drift recorded against it
+type ResourceDetailResponse struct {
+	Resource   models.Resource         `json:"resource"`
+	Dependents []string                `json:"dependents"`
+	Drift      []*detector.DriftResult `json:"drift"`
+}
+
+// ResourceSearchResponse is a page of resource search results with the total number of matches
+type ResourceSearchResponse struct {
+	Resources  []models.Resource `json:"resources"`
+	Total      int               `json:"total"`
+	Page       int               `json:"page"`
+	Limit      int               `json:"limit"`
+	TotalPages int               `json:"total_pages"`
+}
+
+// JobListResponse lists the server's queued, running and recently finished jobs, without
+// results. Queued and Running count the listed jobs waiting for and holding a slot.
+type JobListResponse struct {
+	Jobs    []jobs.Job `json:"jobs"`
+	Total   int        `json:"total"`
+	Queued  int        `json:"queued"`
+	Running int        `json:"running"`
+}
+
+// BatchRemediationResponse represents the outcome of a batch remediation
+type BatchRemediationResponse struct {
+	JobID       string                `json:"job_id"`
+	SnapshotID  string                `json:"snapshot_id,omitempty"`
+	TotalDrifts int                   `json:"total_drifts"`
+	Remediated  int                   `json:"remediated"`
+	Failed      int                   `json:"failed"`
+	DryRun      bool                  `json:"dry_run"`
+	Results     []RemediationResult   `json:"results"`
+	Approval    *remediation.Approval `json:"approval,omitempty"` // set when the batch ran on approval
+	Timestamp   time.Time             `json:"timestamp"`
+}
+
+// ApprovalDecisionRequest optionally explains an approval decision
+type ApprovalDecisionRequest struct {
+	Reason string `json:"reason,omitempty"`
+}
+
+// RollbackRequest represents a request to restore resources from a snapshot
+type RollbackRequest struct {
+	SnapshotID      string `json:"snapshot_id"`
+	RequireApproval bool   `json:"require_approval,omitempty"` // hold the rollback until an approver runs it
+}
+
+// RollbackResult represents the outcome of a snapshot rollback
+type RollbackResult struct {
+	SnapshotID   string    `json:"snapshot_id"`
+	Status       string    `json:"status"`
+	RolledBack   bool      `json:"rolled_back"`
+	Restored     int       `json:"restored"`
+	Failed       int                   `json:"failed"`
+	Approval     *remediation.Approval `json:"approval,omitempty"` // set when the rollback ran on approval
+	Timestamp    time.Time             `json:"timestamp"`
+	ErrorMessage string                `json:"error_message,omitempty"`
+}
+
+// RemediationPlanRequest represents a request to preview remediation without executing it
+type RemediationPlanRequest struct {
+	DriftIDs       []string `json:"drift_ids,omitempty"`       // defaults to every stored drift result
+	SeverityFilter string   `json:"severity_filter,omitempty"` // minimum severity: low, medium, high, critical
+}
+
+// PlannedAction represents a single action in a remediation plan preview
+type PlannedAction struct {
+	ID           string   `json:"id"`
+	Type         string   `json:"type"`
+	Resource     string   `json:"resource"`
+	ResourceType string   `json:"resource_type"`
+	Provider     string   `json:"provider"`
+	Description  string   `json:"description"`
+	Command      string   `json:"command,omitempty"`
+	RiskLevel    string   `json:"risk_level"`
+	DependsOn    []string `json:"depends_on,omitempty"`
+}
+
+// RemediationPlanResponse represents a remediation plan preview
+type RemediationPlanResponse struct {
+	PlanID            string              `json:"plan_id"`
+	RiskLevel         string              `json:"risk_level"`
+	RequiresApproval  bool                `json:"requires_approval"`
+	EstimatedDuration string              `json:"estimated_duration"`
+	ActionCount       int                 `json:"action_count"`
+	ExecutionOrder    []string            `json:"execution_order"`
+	Actions           []PlannedAction     `json:"actions"`
+	Strategies        map[string][]string `json:"strategies"` // drift ID to planned action types
+	CreatedAt         time.Time           `json:"created_at"`
+}
+
+// UnmanagedResource represents a discovered resource that is not in Terraform state
+type UnmanagedResource struct {
+	ID         string                 `json:"id"`
+	Name       string                 `json:"name"`
+	Type       string                 `json:"type"`
+	Provider   string                 `json:"provider"`
+	AccountID  string                 `json:"account_id,omitempty"`
+	Region     string                 `json:"region,omitempty"`
+	Tags       map[string]string      `json:"tags,omitempty"`
+	Attributes map[string]interface{} `json:"attributes,omitempty"`
+}
+
+// HCLGenerationRequest represents a request to generate Terraform HCL for unmanaged resources
+type HCLGenerationRequest struct {
+	Resources []UnmanagedResource `json:"resources"`
+}
+
+// DiscoverRequest represents a request to discover a provider's resources.
+// Mode is "full" (the default), "delta" or "fast" (AWS tagging API inventory); Refresh
+// bypasses the discovery cache. JobID correlates the run's WebSocket progress updates.
+// Tags keeps resources with those tag values and TagKeyExists those with the tag keys.
+// Utilization adds CloudWatch CPU and network averages to AWS instances and databases.
+// For the kubernetes provider, Regions are kubeconfig contexts.
+type DiscoverRequest struct {
+	Provider     string            `json:"provider"`
+	Regions      []string          `json:"regions,omitempty"`
+	AccountID    string            `json:"account_id,omitempty"`
+	Tags         map[string]string `json:"tags,omitempty"`
+	TagKeyExists []string          `json:"tag_key_exists,omitempty"`
+	Mode         string            `json:"mode,omitempty"`
+	Refresh      bool              `json:"refresh,omitempty"`
+	JobID        string            `json:"job_id,omitempty"`
+	Utilization  bool              `json:"utilization,omitempty"`
+	Async        bool              `json:"async,omitempty"`
+}
+
+// ResourceDelta lists the resources that changed between two discovery runs
+type ResourceDelta struct {
+	Added   []models.Resource `json:"added"`
+	Removed []models.Resource `json:"removed"`
+	Changed []models.Resource `json:"changed"`
+}
+
+// DiscoverResponse represents the result of a discovery run. DiscoveredAt is when the cloud
+// APIs were last scanned, which predates the request when Cached is true. In delta mode Since
+// is the time of the previous run the delta was computed against; it is omitted on the first run.
+type DiscoverResponse struct {
+	JobID        string            `json:"job_id"`
+	Provider     string            `json:"provider"`
+	Mode         string            `json:"mode"`
+	DiscoveredAt time.Time         `json:"discovered_at"`
+	Cached       bool              `json:"cached"`
+	Since        *time.Time        `json:"since,omitempty"`
+	Total        int               `json:"total"`
+	Delta        *ResourceDelta    `json:"delta,omitempty"`
+	Resources    []models.Resource `json:"resources"`
+	DiscoveryLimit
+}
+
+// DiscoveryLimit reports the resource cap applied to a discovery run. When Truncated is set,
+// collection stopped at MaxResources and the regions and types not fully collected are listed.
+// TimedOut is set when the provider's discovery timeout expired, leaving the results partial.
+// Errors lists the services and regions that failed, such as EC2 in a region where the
+// credentials lack permission, whose resources are missing. DisabledRegions lists the regions
+// skipped because they are not enabled for the account, such as AWS opt-in regions.
+type DiscoveryLimit struct {
+	MaxResources    int                     `json:"max_resources,omitempty"`
+	Truncated       bool                    `json:"truncated"`
+	TimedOut        bool                    `json:"timed_out"`
+	Warning         string                  `json:"warning,omitempty"`
+	SkippedRegions  []string                `json:"skipped_regions,omitempty"`
+	SkippedTypes    []string                `json:"skipped_types,omitempty"`
+	DisabledRegions []string                `json:"disabled_regions,omitempty"`
+	Errors          []models.DiscoveryError `json:"errors,omitempty"`
+}
+
+// UnusedResource is a resource that looks unused, with the estimated monthly cost of what
+// is still billed for it
+type UnusedResource struct {
+	Resource     models.Resource `json:"resource"`
+	Reason       string          `json:"reason"`
+	MonthlyWaste float64         `json:"monthly_waste"`
+}
+
+// RightsizingRecommendation is a rightsizing recommendation with the remediation that
+// applies it; Operation and Parameters are passed to the cost executor
+type RightsizingRecommendation struct {
+	cost.RightsizingRecommendation
+	Remediation PlannedAction `json:"remediation"`
+}
+
+// RecommendationsResponse lists rightsizing recommendations, largest savings first
+type RecommendationsResponse struct {
+	Total               int                         `json:"total"`
+	TotalMonthlySavings float64                     `json:"total_monthly_savings"`
+	Currency            string                      `json:"currency"`
+	Recommendations     []RightsizingRecommendation `json:"recommendations"`
+}
+
+// PolicyEvaluateRequest selects the resources and policy packages to evaluate. Without
+// resources, the most recently discovered resources (optionally of one provider) are used;
+// without packages, every loaded policy is applied.
+type PolicyEvaluateRequest struct {
+	Provider  string            `json:"provider,omitempty"`
+	Resources []models.Resource `json:"resources,omitempty"`
+	Packages  []string          `json:"packages,omitempty"`
+}
+
+// PolicyEvaluateResponse reports pass or fail per resource with the violated rules
+type PolicyEvaluateResponse struct {
+	Total   int                               `json:"total"`
+	Passed  int                               `json:"passed"`
+	Failed  int                               `json:"failed"`
+	Results []compliance.ResourcePolicyResult `json:"results"`
+}
+
+// TagViolationsResponse lists the discovered resources that break the tag policy
+type TagViolationsResponse struct {
+	Policy    compliance.TagPolicy               `json:"policy"`
+	Checked   int                                `json:"checked"`
+	Violating int                                `json:"violating"`
+	Resources []compliance.ResourceTagViolations `json:"resources"`
+}
+
+// UnusedResourcesResponse lists likely-unused resources and their combined monthly waste.
+// TimedOut is set, with the unscanned regions, when the aws discovery timeout expired.
+type UnusedResourcesResponse struct {
+	Provider          string           `json:"provider"`
+	Regions           []string         `json:"regions"`
+	Total             int              `json:"total"`
+	TotalMonthlyWaste float64          `json:"total_monthly_waste"`
+	Currency          string           `json:"currency"`
+	Resources         []UnusedResource `json:"resources"`
+	DiscoveryLimit
+}
+
+// ScheduleRequest represents a request to create a recurring drift scan
+type ScheduleRequest struct {
+	Name           string `json:"name,omitempty"`
+	Provider       string `json:"provider"`
+	CronExpression string `json:"cron_expression"`
+	DriftThreshold int    `json:"drift_threshold,omitempty"`
+}
+
+// DeletionRequest selects an account's resources to delete. The filters narrow the resources
+// as in a dry run; Force skips the production and dependency safety checks. ConfirmationToken
+// is the token from a preview of the same request and is required to delete.
+type DeletionRequest struct {
+	Provider          string   `json:"provider"`
+	AccountID         string   `json:"account_id"`
+	ResourceTypes     []string `json:"resource_types,omitempty"`
+	Regions           []string `json:"regions,omitempty"`
+	ExcludeResources  []string `json:"exclude_resources,omitempty"`
+	IncludeResources  []string `json:"include_resources,omitempty"`
+	Force             bool     `json:"force,omitempty"`
+	ConfirmationToken string   `json:"confirmation_token,omitempty"`
+}
+
+// TagRemediationRequest selects discovered resources of one provider to tag in bulk. Tag
+// values may be templates over the resource, such as {{.Region}}; without resource_ids,
+// every resource matching the filters is considered. Only missing tags are added unless
+// Overwrite is set, and DryRun reports the changes without making them.
+type TagRemediationRequest struct {
+	Provider     string            `json:"provider"`
+	AccountID    string            `json:"account_id,omitempty"`
+	Region       string            `json:"region,omitempty"`
+	ResourceType string            `json:"resource_type,omitempty"`
+	ResourceIDs  []string          `json:"resource_ids,omitempty"`
+	Tags         map[string]string `json:"tags"`
+	Overwrite    bool              `json:"overwrite,omitempty"`
+	DryRun       bool              `json:"dry_run,omitempty"`
+}
+
+// StateSource selects state files: loaded state files by ID and Terraform state documents
+// passed inline, e.g. the output of terraform state pull for each Terragrunt unit
+type StateSource struct {
+	StateFileIDs []string          `json:"state_file_ids,omitempty"`
+	States       []json.RawMessage `json:"states,omitempty"`
+}
+
+// StateSetAnalysisRequest selects the state files analyzed together as one managed set. With
+// an InputType of plan, Plan is analyzed instead: the output of terraform show -json for a
+// saved plan, or a plan file as a base64 string.
+type StateSetAnalysisRequest struct {
+	StateSource
+	Provider  string          `json:"provider,omitempty"`
+	InputType string          `json:"input_type,omitempty"`
+	Plan      json.RawMessage `json:"plan,omitempty"`
+}
+
+// EnvironmentCompareRequest selects the states of a baseline environment, such as
+// production, and of a target environment that should mirror it
+type EnvironmentCompareRequest struct {
+	Baseline StateSource `json:"baseline"`
+	Target   StateSource `json:"target"`
+}
+
+// HelmDriftRequest selects a Helm release to check for drift. Chart and ValuesFiles are paths
+// under the server's helm_chart_root; without a chart, the release's live objects are compared
+// with the manifest it deployed.
+type HelmDriftRequest struct {
+	Release     string                 `json:"release"`
+	Namespace   string                 `json:"namespace,omitempty"`
+	Context     string                 `json:"context,omitempty"`
+	Chart       string                 `json:"chart,omitempty"`
+	ValuesFiles []string               `json:"values_files,omitempty"`
+	Values      map[string]interface{} `json:"values,omitempty"`
+}
+
+// PlanDriftRequest selects the Terraform working directory POST /api/v1/drift/plan runs a
+// refresh-only plan in. WorkDir, BackendConfigFiles and VarFiles are paths under the server's
+// repository_root; BackendConfig and BackendConfigFiles are passed to terraform init.
+type PlanDriftRequest struct {
+	WorkDir            string            `json:"work_dir"`
+	BackendConfig      map[string]string `json:"backend_config,omitempty"`
+	BackendConfigFiles []string          `json:"backend_config_files,omitempty"`
+	VarFiles           []string          `json:"var_files,omitempty"`
+}
+
+// StateSetAnalysisResponse reports the live resources missing from every state in the set
+// and the state resources no longer found live
+type StateSetAnalysisResponse struct {
+	StateFiles int                    `json:"state_files"`
+	Summary    models.AnalysisSummary `json:"summary"`
+	Coverage   *state.Coverage        `json:"coverage"`
+}
+
+// PlanAnalysisResponse reports the changes a saved plan would make, as drift results
+type PlanAnalysisResponse struct {
+	InputType string `json:"input_type"`
+	tfplan.PlanChanges
+}
+
+// TerragruntAnalysisRequest selects a Terragrunt stack by its path under the server's
+// terragrunt_root. States supplies the pulled state of units with remote backends, keyed by
+// unit path relative to the stack.
+type TerragruntAnalysisRequest struct {
+	Path     string                     `json:"path"`
+	States   map[string]json.RawMessage `json:"states,omitempty"`
+	Provider string                     `json:"provider,omitempty"`
+}
+
+// TerragruntAnalysisResponse reports a Terragrunt stack's units in dependency order and the
+// analysis of live resources against the states of all its units. Unresolved lists the
+// units whose state was neither found locally nor supplied.
+type TerragruntAnalysisResponse struct {
+	Stack      *resolver.Stack        `json:"stack"`
+	Unresolved []string               `json:"unresolved"`
+	Summary    models.AnalysisSummary `json:"summary"`
+	Coverage   *state.Coverage        `json:"coverage"`
+}
+
+// TerragruntScanRequest selects a Terragrunt stack to scan for drift unit by unit, as
+// TerragruntAnalysisRequest does. EnvironmentLevel is the directory of a unit's path that
+// names its environment, 0 for the first. Concurrency limits the units checked at once.
+type TerragruntScanRequest struct {
+	Path             string                     `json:"path"`
+	States           map[string]json.RawMessage `json:"states,omitempty"`
+	Provider         string                     `json:"provider,omitempty"`
+	EnvironmentLevel int                        `json:"environment_level,omitempty"`
+	Concurrency      int                        `json:"concurrency,omitempty"`
+	Async            bool                       `json:"async,omitempty"`
+	JobID            string                     `json:"job_id,omitempty"`
+}
+
+// OrganizationDiscoveryRequest discovers the accounts of the AWS organization the server's
+// credentials manage. AccountIDs limits discovery to some accounts, and RoleName overrides
+// the configured role assumed in member accounts.
+type OrganizationDiscoveryRequest struct {
+	Regions     []string `json:"regions,omitempty"`
+	AccountIDs  []string `json:"account_ids,omitempty"`
+	RoleName    string   `json:"role_name,omitempty"`
+	Concurrency int      `json:"concurrency,omitempty"`
+	Async       bool     `json:"async,omitempty"`
+	JobID       string   `json:"job_id,omitempty"`
+}
+
+// AccountDriftSummary rolls up one account of an organization discovery. Added, Removed and
+// Changed compare its resources with the account's previous discovery; Drifted counts its
+// resources with recorded drift detection results.
+type AccountDriftSummary struct {
+	Account   awsprovider.OrganizationAccount `json:"account"`
+	Resources int                             `json:"resources"`
+	Added     int                             `json:"added"`
+	Removed   int                             `json:"removed"`
+	Changed   int                             `json:"changed"`
+	Drifted   int                             `json:"drifted"`
+	Error     string                          `json:"error,omitempty"`
+}
+
+// OrganizationDiscoveryResponse reports an organization discovery: the organization's
+// structure, the drift roll-up of each discovered account and its resources keyed by
+// account ID. Skipped lists suspended accounts and those not selected or not permitted.
+type OrganizationDiscoveryResponse struct {
+	JobID           string                            `json:"job_id"`
+	DiscoveredAt    time.Time                         `json:"discovered_at"`
+	Organization    *awsprovider.Organization         `json:"organization"`
+	Accounts        []AccountDriftSummary             `json:"accounts"`
+	Skipped         []awsprovider.OrganizationAccount `json:"skipped"`
+	Resources       map[string][]models.Resource      `json:"resources"`
+	TotalResources  int                               `json:"total_resources"`
+	DriftedAccounts int                               `json:"drifted_accounts"`
+	FailedAccounts  int                               `json:"failed_accounts"`
+}
+
+// GCPOrganizationDiscoveryRequest discovers the projects under a GCP organization or folder;
+// exactly one of OrganizationID and FolderID is given. IncludeProjects limits discovery to
+// some projects and ExcludeProjects skips some.
+type GCPOrganizationDiscoveryRequest struct {
+	OrganizationID  string   `json:"organization_id,omitempty"`
+	FolderID        string   `json:"folder_id,omitempty"`
+	IncludeProjects []string `json:"include_projects,omitempty"`
+	ExcludeProjects []string `json:"exclude_projects,omitempty"`
+	Concurrency     int      `json:"concurrency,omitempty"`
+	Async           bool     `json:"async,omitempty"`
+	JobID           string   `json:"job_id,omitempty"`
+}
+
+// ProjectDriftSummary rolls up one project of a GCP organization discovery, as
+// AccountDriftSummary does for an AWS account
+type ProjectDriftSummary struct {
+	Project   gcpprovider.Project `json:"project"`
+	Resources int                 `json:"resources"`
+	Added     int                 `json:"added"`
+	Removed   int                 `json:"removed"`
+	Changed   int                 `json:"changed"`
+	Drifted   int                 `json:"drifted"`
+	Error     string              `json:"error,omitempty"`
+}
+
+// GCPOrganizationDiscoveryResponse reports a GCP organization discovery: the roll-up of each
+// discovered project and its resources keyed by project ID. Skipped lists inactive projects
+// and those not selected or not permitted.
+type GCPOrganizationDiscoveryResponse struct {
+	JobID           string                       `json:"job_id"`
+	DiscoveredAt    time.Time                    `json:"discovered_at"`
+	Parent          string                       `json:"parent"`
+	Projects        []ProjectDriftSummary        `json:"projects"`
+	Skipped         []gcpprovider.Project        `json:"skipped"`
+	Resources       map[string][]models.Resource `json:"resources"`
+	TotalResources  int                          `json:"total_resources"`
+	DriftedProjects int                          `json:"drifted_projects"`
+	FailedProjects  int                          `json:"failed_projects"`
+}
+
+// StateDiscoveryRequest selects a Terraform repository by its path under the server's
+// repository_root. IncludeStates returns each loaded state document, which can be passed as
+// states to the analysis endpoints.
+type StateDiscoveryRequest struct {
+	Path          string `json:"path"`
+	IncludeStates bool   `json:"include_states,omitempty"`
+	// Limit bounds how many state files are loaded and returned, 50 when zero and at most 500;
+	// PageToken continues from the next_page_token of the previous page
+	Limit     int    `json:"limit,omitempty"`
+	PageToken string `json:"page_token,omitempty"`
+}
+
+// DiscoveredStateFile is a state location resolved from a repository's backend
+// configuration and the outcome of loading its state
+type DiscoveredStateFile struct {
+	backenddiscovery.StateLocation
+	Loaded           bool            `json:"loaded"`
+	Error            string          `json:"error,omitempty"`
+	TerraformVersion string          `json:"terraform_version,omitempty"`
+	Serial           int             `json:"serial,omitempty"`
+	Lineage          string          `json:"lineage,omitempty"`
+	Resources        int             `json:"resources"`
+	State            json.RawMessage `json:"state,omitempty"`
+}
+
+// StateDiscoveryResponse lists a page of the state locations resolved in a repository.
+// Discovered counts every location; Loaded counts the state files on this page that loaded.
+// NextPageToken is empty on the last page.
+type StateDiscoveryResponse struct {
+	Path          string                `json:"path"`
+	Discovered    int                   `json:"discovered"`
+	Loaded        int                   `json:"loaded"`
+	Offset        int                   `json:"offset"`
+	Limit         int                   `json:"limit"`
+	NextPageToken string                `json:"next_page_token,omitempty"`
+	StateFiles    []DiscoveredStateFile `json:"state_files"`
+}
+
+// StateUploadResponse describes an accepted state file upload. ID names the stored copy;
+// Filename is the base name the client gave. JobID tags the upload's progress updates.
+type StateUploadResponse struct {
+	ID               string `json:"id"`
+	JobID            string `json:"job_id"`
+	Filename         string `json:"filename"`
+	Size             int64  `json:"size"`
+	Version          int    `json:"version"`
+	TerraformVersion string `json:"terraform_version"`
+	Serial           int    `json:"serial"`
+	Lineage          string `json:"lineage"`
+	Resources        int    `json:"resources"`
+	Tool             string `json:"tool,omitempty"`
+}
+
+// HealthResponse reports the server's health. Components lists the dependencies a readiness
+// probe checked; liveness probes check none.
+type HealthResponse struct {
+	Status     string            `json:"status"`
+	Version    string            `json:"version"`
+	Commit     string            `json:"commit"`
+	BuildDate  string            `json:"build_date"`
+	Time       time.Time         `json:"time"`
+	Components []ComponentHealth `json:"components,omitempty"`
+}
+
+// ComponentHealth is the outcome of checking one dependency
+type ComponentHealth struct {
+	Name      string `json:"name"`
+	Status    string `json:"status"`
+	Error     string `json:"error,omitempty"`
+	LatencyMS int64  `json:"latency_ms"`
+}
+
+// EventIgnoredResponse is returned for a cloud event that changed nothing to re-check, such
+// as a read-only or failed API call
+type EventIgnoredResponse struct {
+	Status string `json:"status"`
+	Reason string `json:"reason"`
+}
+
+// EventResource is the outcome of re-discovering one resource a cloud event changed: new,
+// changed or unchanged since it was last discovered, deleted, or failed
+type EventResource struct {
+	ID     string `json:"id"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// AWSEventResult is the result of an AWS event job: the event, each resource it changed, and
+// the drift check of the resources that were re-discovered
+type AWSEventResult struct {
+	Event         *awsprovider.ChangeEvent `json:"event"`
+	Resources     []EventResource          `json:"resources"`
+	DriftResultID string                   `json:"drift_result_id,omitempty"`
+	DriftCount    int                      `json:"drift_count"`
+}
+
+// DriftFieldDiff is the before and after value of one field of a drift result. Change is
+// added, removed, modified or type_mismatch.
+type DriftFieldDiff struct {
+	Field      string      `json:"field"`
+	Change     string      `json:"change"`
+	Before     interface{} `json:"before"`
+	After      interface{} `json:"after"`
+	Importance string      `json:"importance,omitempty"`
+	Message    string      `json:"message,omitempty"`
+}
+
+// DriftDiffResponse is the field-level diff of a drift result, from the desired values of the
+// IaC state before to the actual values in the cloud after
+type DriftDiffResponse struct {
+	DriftID      string           `json:"drift_id"`
+	Resource     string           `json:"resource"`
+	ResourceType string           `json:"resource_type"`
+	Provider     string           `json:"provider"`
+	DriftType    string           `json:"drift_type"`
+	Severity     string           `json:"severity"`
+	DetectedAt   time.Time        `json:"detected_at"`
+	Fields       []DriftFieldDiff `json:"fields"`
+}
+
+// AzureEventValidationResponse answers the Event Grid subscription validation handshake
+type AzureEventValidationResponse struct {
+	ValidationResponse string `json:"validationResponse"`
+}
+
+// AzureEventResult is the result of an Azure event job: the events of the delivery, the
+// reasons others were skipped, each resource the events changed, and the drift check of the
+// resources that were re-discovered
+type AzureEventResult struct {
+	Events        []*azureprovider.ChangeEvent `json:"events"`
+	Ignored       []string                     `json:"ignored,omitempty"`
+	Resources     []EventResource              `json:"resources"`
+	DriftResultID string                       `json:"drift_result_id,omitempty"`
+	DriftCount    int                          `json:"drift_count"`
+}
+
 // Helper functions for creating standardized responses
 
 // NewSuccessResponse creates a success response
@@ -0,0 +1,192 @@
+// Package audit records who changed what through the API, for change management and
+// incident forensics.
+package audit
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Results of an audited operation
+const (
+	ResultSuccess = "success"
+	ResultFailure = "failure"
+)
+
+// schema creates the audit log table and the indexes its filters use
+const schema = `
+CREATE TABLE IF NOT EXISTS audit_log (
+	id        TEXT PRIMARY KEY,
+	timestamp TIMESTAMP NOT NULL,
+	actor     TEXT NOT NULL,
+	action    TEXT NOT NULL,
+	method    TEXT NOT NULL DEFAULT '',
+	path      TEXT NOT NULL DEFAULT '',
+	target    TEXT NOT NULL DEFAULT '',
+	account   TEXT NOT NULL DEFAULT '',
+	params    TEXT,
+	status    INTEGER NOT NULL DEFAULT 0,
+	result    TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_audit_log_timestamp ON audit_log (timestamp);
+CREATE INDEX IF NOT EXISTS idx_audit_log_actor ON audit_log (actor);
+CREATE INDEX IF NOT EXISTS idx_audit_log_action ON audit_log (action);`
+
+// Entry records one state-changing operation
+type Entry struct {
+	ID        string                 `json:"id"`
+	Timestamp time.Time              `json:"timestamp"`
+	Actor     string                 `json:"actor"`
+	Action    string                 `json:"action"`
+	Method    string                 `json:"method,omitempty"`
+	Path      string                 `json:"path,omitempty"`
+	Target    string                 `json:"target,omitempty"`  // resource, drift or object acted on
+	Account   string                 `json:"account,omitempty"` // cloud account acted on
+	Params    map[string]interface{} `json:"params,omitempty"`
+	Status    int                    `json:"status"` // HTTP status of the response
+	Result    string                 `json:"result"`
+}
+
+// Filter selects audit entries. Zero fields do not filter; Limit 0 returns every matching
+// entry. Entries are returned newest first.
+type Filter struct {
+	Actor     string
+	Action    string
+	Target    string
+	Account   string
+	Result    string
+	StartTime time.Time
+	EndTime   time.Time
+	Limit     int
+	Offset    int
+}
+
+// Log persists audit entries in a SQL database
+type Log struct {
+	db *sql.DB
+}
+
+// NewLog creates an audit log on an open database, creating its table
+func NewLog(db *sql.DB) (*Log, error) {
+	if _, err := db.Exec(schema); err != nil {
+		return nil, fmt.Errorf("failed to create audit log table: %w", err)
+	}
+	return &Log{db: db}, nil
+}
+
+// Record stores an entry, assigning an ID and timestamp when they are unset
+func (l *Log) Record(ctx context.Context, entry *Entry) error {
+	if entry.ID == "" {
+		entry.ID = fmt.Sprintf("audit-%s", uuid.New().String())
+	}
+	if entry.Timestamp.IsZero() {
+		entry.Timestamp = time.Now().UTC()
+	}
+
+	var params []byte
+	if len(entry.Params) > 0 {
+		var err error
+		if params, err = json.Marshal(entry.Params); err != nil {
+			return fmt.Errorf("failed to marshal audit params: %w", err)
+		}
+	}
+
+	_, err := l.db.ExecContext(ctx, `
+		INSERT INTO audit_log (id, timestamp, actor, action, method, path, target, account, params, status, result)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		entry.ID, entry.Timestamp.UTC(), entry.Actor, entry.Action, entry.Method, entry.Path,
+		entry.Target, entry.Account, string(params), entry.Status, entry.Result)
+	if err != nil {
+		return fmt.Errorf("failed to record audit entry: %w", err)
+	}
+	return nil
+}
+
+// Query returns the page of entries matching the filter, newest first
+func (l *Log) Query(ctx context.Context, filter Filter) ([]Entry, error) {
+	where, args := filter.where()
+	query := `SELECT id, timestamp, actor, action, method, path, target, account, params, status, result FROM audit_log` +
+		where + ` ORDER BY timestamp DESC`
+	switch {
+	case filter.Limit > 0:
+		query += ` LIMIT ? OFFSET ?`
+		args = append(args, filter.Limit, filter.Offset)
+	case filter.Offset > 0:
+		// SQLite only accepts OFFSET after a LIMIT; -1 means no limit
+		query += ` LIMIT -1 OFFSET ?`
+		args = append(args, filter.Offset)
+	}
+
+	rows, err := l.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query audit log: %w", err)
+	}
+	defer rows.Close()
+
+	entries := []Entry{}
+	for rows.Next() {
+		var entry Entry
+		var params sql.NullString
+		if err := rows.Scan(&entry.ID, &entry.Timestamp, &entry.Actor, &entry.Action, &entry.Method, &entry.Path,
+			&entry.Target, &entry.Account, &params, &entry.Status, &entry.Result); err != nil {
+			return nil, fmt.Errorf("failed to read audit entry: %w", err)
+		}
+		if params.Valid && params.String != "" {
+			if err := json.Unmarshal([]byte(params.String), &entry.Params); err != nil {
+				return nil, fmt.Errorf("failed to parse params of %s: %w", entry.ID, err)
+			}
+		}
+		entries = append(entries, entry)
+	}
+	return entries, rows.Err()
+}
+
+// Count returns how many entries match the filter, ignoring its limit and offset
+func (l *Log) Count(ctx context.Context, filter Filter) (int, error) {
+	where, args := filter.where()
+	var count int
+	if err := l.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM audit_log`+where, args...).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count audit entries: %w", err)
+	}
+	return count, nil
+}
+
+// where translates the filter into a WHERE clause and its arguments
+func (f Filter) where() (string, []interface{}) {
+	var conditions []string
+	var args []interface{}
+	for _, column := range []struct {
+		name  string
+		value string
+	}{
+		{"actor", f.Actor},
+		{"action", f.Action},
+		{"target", f.Target},
+		{"account", f.Account},
+		{"result", f.Result},
+	} {
+		if column.value != "" {
+			conditions = append(conditions, column.name+" = ?")
+			args = append(args, column.value)
+		}
+	}
+	if !f.StartTime.IsZero() {
+		conditions = append(conditions, "timestamp >= ?")
+		args = append(args, f.StartTime.UTC())
+	}
+	if !f.EndTime.IsZero() {
+		conditions = append(conditions, "timestamp <= ?")
+		args = append(args, f.EndTime.UTC())
+	}
+
+	if len(conditions) == 0 {
+		return "", nil
+	}
+	return " WHERE " + strings.Join(conditions, " AND "), args
+}
@@ -0,0 +1,47 @@
+package audit
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestFilterWhere(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := start.Add(24 * time.Hour)
+
+	tests := []struct {
+		name   string
+		filter Filter
+		where  string
+		args   []interface{}
+	}{
+		{
+			name: "no filters",
+		},
+		{
+			name:   "actor and action",
+			filter: Filter{Actor: "jane", Action: "remediate"},
+			where:  " WHERE actor = ? AND action = ?",
+			args:   []interface{}{"jane", "remediate"},
+		},
+		{
+			name:   "account and date range",
+			filter: Filter{Account: "123456789012", StartTime: start, EndTime: end},
+			where:  " WHERE account = ? AND timestamp >= ? AND timestamp <= ?",
+			args:   []interface{}{"123456789012", start, end},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			where, args := tt.filter.where()
+			if where != tt.where {
+				t.Errorf("expected %q, got %q", tt.where, where)
+			}
+			if !reflect.DeepEqual(args, tt.args) {
+				t.Errorf("expected args %v, got %v", tt.args, args)
+			}
+		})
+	}
+}
@@ -1,7 +1,9 @@
 package auth
 
 import (
+	"crypto/subtle"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strings"
 
@@ -317,6 +319,177 @@ func (h *AuthHandlers) DeleteAPIKey(w http.ResponseWriter, r *http.Request) {
 	writeJSONResponse(w, http.StatusOK, map[string]string{"message": "API key deleted successfully"}, nil)
 }
 
+// CreateServiceToken handles POST /api/v1/auth/tokens. The token is only returned in this
+// response; afterwards just its hash is kept.
+func (h *AuthHandlers) CreateServiceToken(w http.ResponseWriter, r *http.Request) {
+	store := h.authService.ServiceTokens()
+	if store == nil {
+		writeErrorResponse(w, http.StatusServiceUnavailable, "SERVICE_UNAVAILABLE", "Service tokens are not available", "")
+		return
+	}
+
+	// Parse request
+	var req CreateServiceTokenRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "INVALID_REQUEST", "Invalid request payload", err.Error())
+		return
+	}
+
+	// Validate request
+	if err := h.validator.Struct(&req); err != nil {
+		writeValidationError(w, err.Error())
+		return
+	}
+
+	createdBy, _ := GetUsernameFromContext(r.Context())
+	token, err := store.Create(r.Context(), &req, createdBy)
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "SERVICE_TOKEN_CREATION_FAILED", "Service token creation failed", err.Error())
+		return
+	}
+
+	writeJSONResponse(w, http.StatusCreated, token, nil)
+}
+
+// ListServiceTokens handles GET /api/v1/auth/tokens
+func (h *AuthHandlers) ListServiceTokens(w http.ResponseWriter, r *http.Request) {
+	store := h.authService.ServiceTokens()
+	if store == nil {
+		writeErrorResponse(w, http.StatusServiceUnavailable, "SERVICE_UNAVAILABLE", "Service tokens are not available", "")
+		return
+	}
+
+	tokens, err := store.List(r.Context())
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, "FAILED_TO_GET_SERVICE_TOKENS", "Failed to get service tokens", err.Error())
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, tokens, nil)
+}
+
+// RevokeServiceToken handles DELETE /api/v1/auth/tokens/{id}
+func (h *AuthHandlers) RevokeServiceToken(w http.ResponseWriter, r *http.Request) {
+	store := h.authService.ServiceTokens()
+	if store == nil {
+		writeErrorResponse(w, http.StatusServiceUnavailable, "SERVICE_UNAVAILABLE", "Service tokens are not available", "")
+		return
+	}
+
+	// Extract token ID from URL
+	tokenID := r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:]
+	if tokenID == "" || tokenID == "tokens" {
+		writeErrorResponse(w, http.StatusBadRequest, "MISSING_SERVICE_TOKEN_ID", "Service token ID is required", "")
+		return
+	}
+
+	token, err := store.Revoke(r.Context(), tokenID)
+	if errors.Is(err, ErrServiceTokenNotFound) {
+		writeErrorResponse(w, http.StatusNotFound, "SERVICE_TOKEN_NOT_FOUND", "Service token not found", "")
+		return
+	}
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, "FAILED_TO_REVOKE_SERVICE_TOKEN", "Failed to revoke service token", err.Error())
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, token, nil)
+}
+
+// oidcStateCookie binds a started OIDC login to the browser that started it
+const oidcStateCookie = "oidc_state"
+
+// setOIDCStateCookie stores a login's state in the browser, or clears it when state is empty.
+// SameSite=Lax lets the cookie accompany the issuer's top-level redirect back to the callback.
+func setOIDCStateCookie(w http.ResponseWriter, state string) {
+	maxAge := int(oidcLoginTimeout.Seconds())
+	if state == "" {
+		maxAge = -1
+	}
+	http.SetCookie(w, &http.Cookie{
+		Name:     oidcStateCookie,
+		Value:    state,
+		Path:     "/api/v1/auth/oidc",
+		HttpOnly: true,
+		Secure:   true,
+		SameSite: http.SameSiteLaxMode,
+		MaxAge:   maxAge,
+	})
+}
+
+// OIDCLogin handles GET /api/v1/auth/oidc/login by redirecting to the OIDC issuer
+func (h *AuthHandlers) OIDCLogin(w http.ResponseWriter, r *http.Request) {
+	provider := h.authService.OIDCProvider()
+	if provider == nil {
+		writeErrorResponse(w, http.StatusNotFound, "OIDC_NOT_CONFIGURED", "Single sign-on is not configured", "")
+		return
+	}
+
+	authURL, state, err := provider.AuthCodeURL()
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, "OIDC_LOGIN_FAILED", "Failed to start single sign-on", err.Error())
+		return
+	}
+	setOIDCStateCookie(w, state)
+	http.Redirect(w, r, authURL, http.StatusFound)
+}
+
+// OIDCCallback handles GET /api/v1/auth/oidc/callback, where the OIDC issuer returns the
+// user after they sign in. The state must match the cookie set when this browser started the
+// login, so a callback URL from someone else's login cannot sign the user in as them.
+func (h *AuthHandlers) OIDCCallback(w http.ResponseWriter, r *http.Request) {
+	provider := h.authService.OIDCProvider()
+	if provider == nil {
+		writeErrorResponse(w, http.StatusNotFound, "OIDC_NOT_CONFIGURED", "Single sign-on is not configured", "")
+		return
+	}
+
+	query := r.URL.Query()
+	if issuerError := query.Get("error"); issuerError != "" {
+		writeErrorResponse(w, http.StatusUnauthorized, "AUTHENTICATION_FAILED", "Single sign-on failed", issuerError+": "+query.Get("error_description"))
+		return
+	}
+	code, state := query.Get("code"), query.Get("state")
+	if code == "" || state == "" {
+		writeErrorResponse(w, http.StatusBadRequest, "INVALID_REQUEST", "Code and state are required", "")
+		return
+	}
+	cookie, err := r.Cookie(oidcStateCookie)
+	setOIDCStateCookie(w, "")
+	if err != nil || subtle.ConstantTimeCompare([]byte(cookie.Value), []byte(state)) != 1 {
+		writeErrorResponse(w, http.StatusUnauthorized, "AUTHENTICATION_FAILED", "Single sign-on failed", "login was not started in this browser")
+		return
+	}
+
+	identity, err := provider.Exchange(r.Context(), code, state)
+	if err != nil {
+		writeErrorResponse(w, http.StatusUnauthorized, "AUTHENTICATION_FAILED", "Single sign-on failed", err.Error())
+		return
+	}
+	roles, err := provider.RolesForGroups(identity.Groups)
+	if err != nil {
+		writeErrorResponse(w, http.StatusForbidden, "FORBIDDEN", "Access denied", err.Error())
+		return
+	}
+
+	response, err := h.authService.LoginOIDC(identity, roles, r.Header.Get("User-Agent"), getClientIP(r))
+	if err != nil {
+		writeErrorResponse(w, http.StatusUnauthorized, "AUTHENTICATION_FAILED", "Single sign-on failed", err.Error())
+		return
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     "refresh_token",
+		Value:    response.RefreshToken,
+		HttpOnly: true,
+		Secure:   true,
+		SameSite: http.SameSiteStrictMode,
+		MaxAge:   int(response.ExpiresIn),
+	})
+
+	writeJSONResponse(w, http.StatusOK, response, nil)
+}
+
 // OAuth2Callback handles OAuth2 provider callbacks
 func (h *AuthHandlers) OAuth2Callback(w http.ResponseWriter, r *http.Request) {
 	// Extract provider from URL path
@@ -37,6 +37,7 @@ func (j *JWTService) GenerateAccessToken(user *User, roles []string) (string, er
 		Email:    user.Email,
 		Roles:    roles,
 		IsAdmin:  user.IsAdmin,
+		Scopes:   user.Scopes,
 		Exp:      now.Add(j.accessExpiry).Unix(),
 		Iat:      now.Unix(),
 		Iss:      j.issuer,
@@ -96,6 +96,19 @@ func (r *MemoryUserRepository) GetByEmail(email string) (*User, error) {
 	return user, nil
 }
 
+// GetByOIDCSubject retrieves the user linked to an OIDC issuer and subject
+func (r *MemoryUserRepository) GetByOIDCSubject(issuer, subject string) (*User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, user := range r.users {
+		if user.OIDCSubject != "" && user.OIDCIssuer == issuer && user.OIDCSubject == subject {
+			return user, nil
+		}
+	}
+	return nil, errors.New("user not found")
+}
+
 // Update updates a user
 func (r *MemoryUserRepository) Update(user *User) error {
 	r.mu.Lock()
@@ -3,6 +3,7 @@ package auth
 import (
 	"context"
 	"net/http"
+	"strings"
 )
 
 // AuthMiddleware handles authentication and authorization
@@ -30,6 +31,12 @@ func (m *AuthMiddleware) RequireAuth(next http.HandlerFunc) http.HandlerFunc {
 			return
 		}
 
+		// Service account tokens are validated against the token store rather than as JWTs
+		if strings.HasPrefix(token, ServiceTokenPrefix) {
+			m.serviceTokenAuth(w, r, token, next)
+			return
+		}
+
 		// Validate token
 		claims, err := m.jwtService.ValidateToken(token)
 		if err != nil {
@@ -43,6 +50,7 @@ func (m *AuthMiddleware) RequireAuth(next http.HandlerFunc) http.HandlerFunc {
 		ctx = context.WithValue(ctx, "email", claims.Email)
 		ctx = context.WithValue(ctx, "roles", claims.Roles)
 		ctx = context.WithValue(ctx, "is_admin", claims.IsAdmin)
+		ctx = context.WithValue(ctx, "scopes", claims.Scopes)
 		ctx = context.WithValue(ctx, "token", token)
 
 		// Call next handler with updated context
@@ -54,6 +62,17 @@ func (m *AuthMiddleware) RequireAuth(next http.HandlerFunc) http.HandlerFunc {
 func (m *AuthMiddleware) RequirePermission(permission string) func(http.HandlerFunc) http.HandlerFunc {
 	return func(next http.HandlerFunc) http.HandlerFunc {
 		return m.RequireAuth(func(w http.ResponseWriter, r *http.Request) {
+			// Service tokens carry their permissions directly rather than through roles
+			if GetAuthType(r.Context()) == AuthTypeServiceToken {
+				permissions, _ := GetAPIPermissionsFromContext(r.Context())
+				if !m.hasAPIPermission(permissions, permission) {
+					writeErrorResponse(w, http.StatusForbidden, "FORBIDDEN", "Access denied", "Service token lacks permission "+permission)
+					return
+				}
+				next.ServeHTTP(w, r)
+				return
+			}
+
 			// Get user roles from context
 			roles, ok := r.Context().Value("roles").([]string)
 			if !ok {
@@ -125,6 +144,7 @@ func (m *AuthMiddleware) OptionalAuth(next http.HandlerFunc) http.HandlerFunc {
 					ctx = context.WithValue(ctx, "email", claims.Email)
 					ctx = context.WithValue(ctx, "roles", claims.Roles)
 					ctx = context.WithValue(ctx, "is_admin", claims.IsAdmin)
+					ctx = context.WithValue(ctx, "scopes", claims.Scopes)
 					ctx = context.WithValue(ctx, "token", token)
 					ctx = context.WithValue(ctx, "authenticated", true)
 
@@ -163,6 +183,7 @@ func (m *AuthMiddleware) APIKeyAuth(next http.HandlerFunc) http.HandlerFunc {
 		ctx = context.WithValue(ctx, "username", user.Username)
 		ctx = context.WithValue(ctx, "email", user.Email)
 		ctx = context.WithValue(ctx, "is_admin", user.IsAdmin)
+		ctx = context.WithValue(ctx, "scopes", user.Scopes)
 		ctx = context.WithValue(ctx, "api_key_id", apiKeyObj.ID)
 		ctx = context.WithValue(ctx, "api_permissions", apiKeyObj.Permissions)
 		ctx = context.WithValue(ctx, "auth_type", "api_key")
@@ -172,6 +193,33 @@ func (m *AuthMiddleware) APIKeyAuth(next http.HandlerFunc) http.HandlerFunc {
 	}
 }
 
+// serviceTokenAuth authenticates a request with a service account token, adding the token's
+// permissions and scopes to the request context
+func (m *AuthMiddleware) serviceTokenAuth(w http.ResponseWriter, r *http.Request, token string, next http.HandlerFunc) {
+	store := m.authService.ServiceTokens()
+	if store == nil {
+		writeErrorResponse(w, http.StatusUnauthorized, "INVALID_TOKEN", "Invalid or expired token", "service tokens are not enabled")
+		return
+	}
+
+	serviceToken, err := store.Validate(r.Context(), token)
+	if err != nil {
+		writeErrorResponse(w, http.StatusUnauthorized, "INVALID_TOKEN", "Invalid or expired token", err.Error())
+		return
+	}
+
+	ctx := context.WithValue(r.Context(), "user_id", serviceToken.ID)
+	ctx = context.WithValue(ctx, "username", serviceToken.Name)
+	ctx = context.WithValue(ctx, "roles", []string{})
+	ctx = context.WithValue(ctx, "is_admin", false)
+	ctx = context.WithValue(ctx, "scopes", serviceToken.Scopes)
+	ctx = context.WithValue(ctx, "api_permissions", serviceToken.Permissions)
+	ctx = context.WithValue(ctx, "auth_type", AuthTypeServiceToken)
+	ctx = context.WithValue(ctx, "token", token)
+
+	next.ServeHTTP(w, r.WithContext(ctx))
+}
+
 // RequireAPIPermission middleware that requires a specific API permission
 func (m *AuthMiddleware) RequireAPIPermission(permission string) func(http.HandlerFunc) http.HandlerFunc {
 	return func(next http.HandlerFunc) http.HandlerFunc {
@@ -302,6 +350,17 @@ func GetIsAdminFromContext(ctx context.Context) (bool, bool) {
 	return isAdmin, ok
 }
 
+// HasPermission reports whether the authenticated user or service token in ctx holds a
+// permission
+func HasPermission(ctx context.Context, permission string) bool {
+	if GetAuthType(ctx) == AuthTypeServiceToken {
+		permissions, _ := GetAPIPermissionsFromContext(ctx)
+		return (&AuthMiddleware{}).hasAPIPermission(permissions, permission)
+	}
+	roles, _ := GetRolesFromContext(ctx)
+	return (&AuthMiddleware{}).hasPermission(roles, permission)
+}
+
 // GetTokenFromContext extracts token from request context
 func GetTokenFromContext(ctx context.Context) (string, bool) {
 	token, ok := ctx.Value("token").(string)
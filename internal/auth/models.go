@@ -16,6 +16,9 @@ type User struct {
 	LastName     string     `json:"last_name" db:"last_name" validate:"required,min=1,max=50"`
 	IsActive     bool       `json:"is_active" db:"is_active"`
 	IsAdmin      bool       `json:"is_admin" db:"is_admin"`
+	Scopes       []string   `json:"scopes,omitempty" db:"scopes"`             // providers and accounts the user may act on; empty is unrestricted
+	OIDCIssuer   string     `json:"oidc_issuer,omitempty" db:"oidc_issuer"`   // issuer of the OIDC identity the user signs in with
+	OIDCSubject  string     `json:"oidc_subject,omitempty" db:"oidc_subject"` // subject of that identity at its issuer
 	LastLogin    *time.Time `json:"last_login" db:"last_login"`
 	CreatedAt    time.Time  `json:"created_at" db:"created_at"`
 	UpdatedAt    time.Time  `json:"updated_at" db:"updated_at"`
@@ -116,6 +119,7 @@ type UserResponse struct {
 	IsActive  bool       `json:"is_active"`
 	IsAdmin   bool       `json:"is_admin"`
 	Roles     []Role     `json:"roles"`
+	Scopes    []string   `json:"scopes,omitempty"`
 	LastLogin *time.Time `json:"last_login"`
 	CreatedAt time.Time  `json:"created_at"`
 	UpdatedAt time.Time  `json:"updated_at"`
@@ -128,6 +132,7 @@ type JWTClaims struct {
 	Email    string   `json:"email"`
 	Roles    []string `json:"roles"`
 	IsAdmin  bool     `json:"is_admin"`
+	Scopes   []string `json:"scopes,omitempty"`
 	Exp      int64    `json:"exp"`
 	Iat      int64    `json:"iat"`
 	Iss      string   `json:"iss"`
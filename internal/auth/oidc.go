@@ -0,0 +1,392 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"golang.org/x/oauth2"
+)
+
+// oidcLoginTimeout is how long a user has to complete an OIDC login once it is started
+const oidcLoginTimeout = 10 * time.Minute
+
+// oidcKeyRefreshInterval limits how often the issuer's signing keys are refetched when a
+// token names an unknown key
+const oidcKeyRefreshInterval = time.Minute
+
+// OIDCConfig configures single sign-on against an OpenID Connect issuer such as Okta,
+// Azure AD or Google
+type OIDCConfig struct {
+	IssuerURL    string   `json:"issuer_url"`
+	ClientID     string   `json:"client_id"`
+	ClientSecret string   `json:"client_secret"`
+	RedirectURL  string   `json:"redirect_url"`
+	Scopes       []string `json:"scopes"` // openid, profile and email when empty
+
+	// GroupsClaim is the ID token claim listing the user's groups; "groups" when empty
+	GroupsClaim string `json:"groups_claim"`
+	// GroupRoles maps OIDC groups to driftmgr roles, which carry the user's permissions
+	GroupRoles map[string]string `json:"group_roles"`
+	// DefaultRole is given to users in no mapped group; when empty they are refused
+	DefaultRole string `json:"default_role"`
+}
+
+// OIDCIdentity is the user an ID token was issued for. Issuer and Subject together identify
+// the user; the email address is only trusted when EmailVerified is set.
+type OIDCIdentity struct {
+	Issuer            string   `json:"iss"`
+	Subject           string   `json:"sub"`
+	Email             string   `json:"email"`
+	EmailVerified     bool     `json:"email_verified"`
+	Name              string   `json:"name"`
+	GivenName         string   `json:"given_name"`
+	FamilyName        string   `json:"family_name"`
+	PreferredUsername string   `json:"preferred_username"`
+	Groups            []string `json:"groups"`
+}
+
+// OIDCProvider runs the authorization code flow against an OpenID Connect issuer and
+// verifies the ID tokens it returns
+type OIDCProvider struct {
+	config  OIDCConfig
+	oauth2  *oauth2.Config
+	issuer  string
+	jwksURI string
+	client  *http.Client
+
+	mu            sync.Mutex
+	keys          map[string]*rsa.PublicKey
+	keysFetchedAt time.Time
+	pending       map[string]oidcLogin
+}
+
+// oidcLogin is a started login awaiting its callback
+type oidcLogin struct {
+	nonce   string
+	expires time.Time
+}
+
+// oidcDiscovery is the part of the issuer's discovery document the provider uses
+type oidcDiscovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// NewOIDCProvider reads the issuer's discovery document and creates a provider for it
+func NewOIDCProvider(ctx context.Context, config OIDCConfig) (*OIDCProvider, error) {
+	if config.IssuerURL == "" || config.ClientID == "" {
+		return nil, errors.New("OIDC issuer URL and client ID are required")
+	}
+	if config.GroupsClaim == "" {
+		config.GroupsClaim = "groups"
+	}
+	scopes := config.Scopes
+	if len(scopes) == 0 {
+		scopes = []string{"openid", "profile", "email"}
+	}
+
+	provider := &OIDCProvider{
+		config:  config,
+		client:  &http.Client{Timeout: 30 * time.Second},
+		keys:    make(map[string]*rsa.PublicKey),
+		pending: make(map[string]oidcLogin),
+	}
+
+	var discovery oidcDiscovery
+	discoveryURL := strings.TrimSuffix(config.IssuerURL, "/") + "/.well-known/openid-configuration"
+	if err := provider.getJSON(ctx, discoveryURL, &discovery); err != nil {
+		return nil, fmt.Errorf("failed to read OIDC discovery document: %w", err)
+	}
+	if strings.TrimSuffix(discovery.Issuer, "/") != strings.TrimSuffix(config.IssuerURL, "/") {
+		return nil, fmt.Errorf("OIDC issuer %q does not match configured issuer %q", discovery.Issuer, config.IssuerURL)
+	}
+	if discovery.AuthorizationEndpoint == "" || discovery.TokenEndpoint == "" || discovery.JWKSURI == "" {
+		return nil, errors.New("OIDC discovery document is missing endpoints")
+	}
+
+	provider.issuer = discovery.Issuer
+	provider.jwksURI = discovery.JWKSURI
+	provider.oauth2 = &oauth2.Config{
+		ClientID:     config.ClientID,
+		ClientSecret: config.ClientSecret,
+		RedirectURL:  config.RedirectURL,
+		Scopes:       scopes,
+		Endpoint: oauth2.Endpoint{
+			AuthURL:  discovery.AuthorizationEndpoint,
+			TokenURL: discovery.TokenEndpoint,
+		},
+	}
+	return provider, nil
+}
+
+// AuthCodeURL starts a login, returning the issuer URL to send the user to and the login's
+// state. The state and the nonce the URL carries are checked when the user returns; the
+// caller should also bind the state to the user's browser so the callback cannot be replayed
+// in another one.
+func (p *OIDCProvider) AuthCodeURL() (string, string, error) {
+	state, err := randomToken()
+	if err != nil {
+		return "", "", err
+	}
+	nonce, err := randomToken()
+	if err != nil {
+		return "", "", err
+	}
+
+	p.mu.Lock()
+	now := time.Now()
+	for pendingState, login := range p.pending {
+		if now.After(login.expires) {
+			delete(p.pending, pendingState)
+		}
+	}
+	p.pending[state] = oidcLogin{nonce: nonce, expires: now.Add(oidcLoginTimeout)}
+	p.mu.Unlock()
+
+	return p.oauth2.AuthCodeURL(state, oauth2.SetAuthURLParam("nonce", nonce)), state, nil
+}
+
+// Exchange completes a login: it checks the state, exchanges the authorization code and
+// verifies the returned ID token against the login's nonce
+func (p *OIDCProvider) Exchange(ctx context.Context, code, state string) (*OIDCIdentity, error) {
+	p.mu.Lock()
+	login, exists := p.pending[state]
+	delete(p.pending, state)
+	p.mu.Unlock()
+	if !exists || time.Now().After(login.expires) {
+		return nil, errors.New("unknown or expired login state")
+	}
+
+	token, err := p.oauth2.Exchange(context.WithValue(ctx, oauth2.HTTPClient, p.client), code)
+	if err != nil {
+		return nil, fmt.Errorf("failed to exchange authorization code: %w", err)
+	}
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok || rawIDToken == "" {
+		return nil, errors.New("token response did not include an ID token")
+	}
+	return p.VerifyIDToken(ctx, rawIDToken, login.nonce)
+}
+
+// VerifyIDToken checks an ID token's signature against the issuer's keys, its issuer,
+// audience, expiry and nonce, and returns the identity it asserts
+func (p *OIDCProvider) VerifyIDToken(ctx context.Context, rawIDToken, nonce string) (*OIDCIdentity, error) {
+	claims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(rawIDToken, claims, p.keyFunc(ctx),
+		jwt.WithValidMethods([]string{"RS256", "RS384", "RS512"}),
+		jwt.WithIssuer(p.issuer),
+		jwt.WithAudience(p.config.ClientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ID token: %w", err)
+	}
+	if tokenNonce, _ := claims["nonce"].(string); nonce == "" || tokenNonce != nonce {
+		return nil, errors.New("invalid ID token: nonce does not match")
+	}
+
+	identity := &OIDCIdentity{
+		Issuer:        p.issuer,
+		EmailVerified: boolClaim(claims["email_verified"]),
+		Groups:        stringsClaim(claims[p.config.GroupsClaim]),
+	}
+	for claim, value := range map[string]*string{
+		"sub":                &identity.Subject,
+		"email":              &identity.Email,
+		"name":               &identity.Name,
+		"given_name":         &identity.GivenName,
+		"family_name":        &identity.FamilyName,
+		"preferred_username": &identity.PreferredUsername,
+	} {
+		*value, _ = claims[claim].(string)
+	}
+	if identity.Subject == "" {
+		return nil, errors.New("invalid ID token: missing subject")
+	}
+	return identity, nil
+}
+
+// RolesForGroups maps a user's OIDC groups to driftmgr roles, falling back to the default
+// role. It fails when neither applies, so unmapped users cannot sign in.
+func (p *OIDCProvider) RolesForGroups(groups []string) ([]string, error) {
+	var roles []string
+	for _, group := range groups {
+		role, ok := p.config.GroupRoles[group]
+		if !ok {
+			continue
+		}
+		if _, defined := DefaultRoles[role]; !defined {
+			return nil, fmt.Errorf("group %q is mapped to unknown role %q", group, role)
+		}
+		if !containsString(roles, role) {
+			roles = append(roles, role)
+		}
+	}
+	if len(roles) == 0 && p.config.DefaultRole != "" {
+		roles = []string{p.config.DefaultRole}
+	}
+	if len(roles) == 0 {
+		return nil, errors.New("none of the user's groups are mapped to a driftmgr role")
+	}
+	return roles, nil
+}
+
+// keyFunc returns the issuer's public key named by a token's kid header, refetching the
+// issuer's keys when the kid is unknown, as happens after key rotation
+func (p *OIDCProvider) keyFunc(ctx context.Context) jwt.Keyfunc {
+	return func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+
+		p.mu.Lock()
+		key, ok := p.keys[kid]
+		stale := time.Since(p.keysFetchedAt) > oidcKeyRefreshInterval
+		p.mu.Unlock()
+		if ok {
+			return key, nil
+		}
+		if !stale {
+			return nil, fmt.Errorf("unknown signing key %q", kid)
+		}
+
+		if err := p.fetchKeys(ctx); err != nil {
+			return nil, err
+		}
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		if key, ok := p.keys[kid]; ok {
+			return key, nil
+		}
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+}
+
+// fetchKeys replaces the cached signing keys with the issuer's current RSA keys
+func (p *OIDCProvider) fetchKeys(ctx context.Context) error {
+	var jwks struct {
+		Keys []struct {
+			Kid string `json:"kid"`
+			Kty string `json:"kty"`
+			Use string `json:"use"`
+			N   string `json:"n"`
+			E   string `json:"e"`
+		} `json:"keys"`
+	}
+	if err := p.getJSON(ctx, p.jwksURI, &jwks); err != nil {
+		return fmt.Errorf("failed to fetch OIDC signing keys: %w", err)
+	}
+
+	keys := make(map[string]*rsa.PublicKey, len(jwks.Keys))
+	for _, jwk := range jwks.Keys {
+		if jwk.Kty != "RSA" || (jwk.Use != "" && jwk.Use != "sig") {
+			continue
+		}
+		key, err := parseRSAJWK(jwk.N, jwk.E)
+		if err != nil {
+			return fmt.Errorf("invalid OIDC signing key %q: %w", jwk.Kid, err)
+		}
+		keys[jwk.Kid] = key
+	}
+
+	p.mu.Lock()
+	p.keys = keys
+	p.keysFetchedAt = time.Now()
+	p.mu.Unlock()
+	return nil
+}
+
+// getJSON fetches a URL and decodes its JSON body
+func (p *OIDCProvider) getJSON(ctx context.Context, url string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s returned status %d", url, resp.StatusCode)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// parseRSAJWK builds an RSA public key from a JWK's base64url modulus and exponent
+func parseRSAJWK(n, e string) (*rsa.PublicKey, error) {
+	modulus, err := base64.RawURLEncoding.DecodeString(n)
+	if err != nil {
+		return nil, fmt.Errorf("invalid modulus: %w", err)
+	}
+	exponent, err := base64.RawURLEncoding.DecodeString(e)
+	if err != nil {
+		return nil, fmt.Errorf("invalid exponent: %w", err)
+	}
+	if len(modulus) == 0 || len(exponent) == 0 || len(exponent) > 4 {
+		return nil, errors.New("invalid key size")
+	}
+	return &rsa.PublicKey{
+		N: new(big.Int).SetBytes(modulus),
+		E: int(new(big.Int).SetBytes(exponent).Int64()),
+	}, nil
+}
+
+// stringsClaim reads a claim holding a string or a list of strings
+func stringsClaim(value interface{}) []string {
+	switch v := value.(type) {
+	case string:
+		return []string{v}
+	case []interface{}:
+		values := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				values = append(values, s)
+			}
+		}
+		return values
+	}
+	return nil
+}
+
+// boolClaim reads a boolean claim; some issuers send booleans as strings
+func boolClaim(value interface{}) bool {
+	switch v := value.(type) {
+	case bool:
+		return v
+	case string:
+		return strings.EqualFold(v, "true")
+	}
+	return false
+}
+
+// containsString reports whether values contains value
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// randomToken returns a random URL-safe string for OIDC state and nonce values
+func randomToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate random value: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,308 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// testIssuer is an OIDC issuer serving discovery, signing keys and a token endpoint that
+// returns idToken
+type testIssuer struct {
+	server  *httptest.Server
+	key     *rsa.PrivateKey
+	idToken string
+}
+
+func newTestIssuer(t *testing.T) *testIssuer {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	issuer := &testIssuer{key: key}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 issuer.server.URL,
+			"authorization_endpoint": issuer.server.URL + "/authorize",
+			"token_endpoint":         issuer.server.URL + "/token",
+			"jwks_uri":               issuer.server.URL + "/keys",
+		})
+	})
+	mux.HandleFunc("/keys", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"keys": []map[string]string{{
+				"kid": "test-key",
+				"kty": "RSA",
+				"use": "sig",
+				"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			}},
+		})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"access_token": "access",
+			"token_type":   "Bearer",
+			"id_token":     issuer.idToken,
+		})
+	})
+	issuer.server = httptest.NewServer(mux)
+	t.Cleanup(issuer.server.Close)
+	return issuer
+}
+
+// sign returns an ID token for the claims, signed with key under kid
+func (i *testIssuer) sign(t *testing.T, key *rsa.PrivateKey, claims jwt.MapClaims) string {
+	t.Helper()
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = "test-key"
+	signed, err := token.SignedString(key)
+	if err != nil {
+		t.Fatalf("failed to sign token: %v", err)
+	}
+	return signed
+}
+
+func (i *testIssuer) claims(nonce string) jwt.MapClaims {
+	return jwt.MapClaims{
+		"iss":            i.server.URL,
+		"aud":            "driftmgr",
+		"sub":            "user-1",
+		"email":          "jane@example.com",
+		"email_verified": true,
+		"groups":         []string{"platform-admins", "everyone"},
+		"nonce":          nonce,
+		"iat":            time.Now().Unix(),
+		"exp":            time.Now().Add(time.Hour).Unix(),
+	}
+}
+
+func TestOIDCProviderVerifyIDToken(t *testing.T) {
+	issuer := newTestIssuer(t)
+	provider, err := NewOIDCProvider(context.Background(), OIDCConfig{IssuerURL: issuer.server.URL, ClientID: "driftmgr"})
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+
+	identity, err := provider.VerifyIDToken(context.Background(), issuer.sign(t, issuer.key, issuer.claims("n-1")), "n-1")
+	if err != nil {
+		t.Fatalf("expected a valid token, got %v", err)
+	}
+	if identity.Subject != "user-1" || identity.Email != "jane@example.com" || len(identity.Groups) != 2 {
+		t.Errorf("unexpected identity %+v", identity)
+	}
+	if identity.Issuer != issuer.server.URL || !identity.EmailVerified {
+		t.Errorf("expected issuer %s and a verified email, got %+v", issuer.server.URL, identity)
+	}
+
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	wrongAudience := issuer.claims("n-1")
+	wrongAudience["aud"] = "someone-else"
+	expired := issuer.claims("n-1")
+	expired["exp"] = time.Now().Add(-time.Minute).Unix()
+
+	for name, token := range map[string]string{
+		"wrong nonce":     issuer.sign(t, issuer.key, issuer.claims("n-2")),
+		"wrong signature": issuer.sign(t, otherKey, issuer.claims("n-1")),
+		"wrong audience":  issuer.sign(t, issuer.key, wrongAudience),
+		"expired":         issuer.sign(t, issuer.key, expired),
+		"unsigned":        mustUnsigned(t, issuer.claims("n-1")),
+	} {
+		if _, err := provider.VerifyIDToken(context.Background(), token, "n-1"); err == nil {
+			t.Errorf("%s: expected token to be rejected", name)
+		}
+	}
+}
+
+func TestOIDCProviderExchange(t *testing.T) {
+	issuer := newTestIssuer(t)
+	provider, err := NewOIDCProvider(context.Background(), OIDCConfig{
+		IssuerURL:   issuer.server.URL,
+		ClientID:    "driftmgr",
+		RedirectURL: "http://localhost:8080/api/v1/auth/oidc/callback",
+	})
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+
+	authURL, state, err := provider.AuthCodeURL()
+	if err != nil {
+		t.Fatalf("failed to start login: %v", err)
+	}
+	parsed, err := url.Parse(authURL)
+	if err != nil {
+		t.Fatalf("invalid auth URL: %v", err)
+	}
+	nonce := parsed.Query().Get("nonce")
+	if parsed.Query().Get("state") != state || nonce == "" {
+		t.Fatalf("expected state %s and a nonce in %s", state, authURL)
+	}
+
+	if _, err := provider.Exchange(context.Background(), "code", "forged-state"); err == nil {
+		t.Error("expected an unknown state to be rejected")
+	}
+
+	issuer.idToken = issuer.sign(t, issuer.key, issuer.claims(nonce))
+	identity, err := provider.Exchange(context.Background(), "code", state)
+	if err != nil {
+		t.Fatalf("expected login to complete, got %v", err)
+	}
+	if identity.Email != "jane@example.com" {
+		t.Errorf("expected jane@example.com, got %q", identity.Email)
+	}
+
+	// A state can only be used once
+	if _, err := provider.Exchange(context.Background(), "code", state); err == nil {
+		t.Error("expected a replayed state to be rejected")
+	}
+}
+
+func TestOIDCProviderRolesForGroups(t *testing.T) {
+	provider := &OIDCProvider{config: OIDCConfig{
+		GroupRoles: map[string]string{"platform-admins": RoleAdmin, "sre": RoleOperator},
+	}}
+
+	roles, err := provider.RolesForGroups([]string{"everyone", "platform-admins", "sre"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(roles) != 2 || roles[0] != RoleAdmin || roles[1] != RoleOperator {
+		t.Errorf("expected admin and operator roles, got %v", roles)
+	}
+
+	if _, err := provider.RolesForGroups([]string{"everyone"}); err == nil {
+		t.Error("expected users in no mapped group to be refused")
+	}
+
+	provider.config.DefaultRole = RoleViewer
+	if roles, err := provider.RolesForGroups([]string{"everyone"}); err != nil || len(roles) != 1 || roles[0] != RoleViewer {
+		t.Errorf("expected the default role, got %v, %v", roles, err)
+	}
+}
+
+func mustUnsigned(t *testing.T, claims jwt.MapClaims) string {
+	t.Helper()
+	token, err := jwt.NewWithClaims(jwt.SigningMethodNone, claims).SignedString(jwt.UnsafeAllowNoneSignatureType)
+	if err != nil {
+		t.Fatalf("failed to build unsigned token: %v", err)
+	}
+	return token
+}
+
+func newTestOIDCService() *Service {
+	return NewService(NewMemoryUserRepository(), NewMemoryRoleRepository(), NewMemorySessionRepository(),
+		NewMemoryAPIKeyRepository(), NewJWTService("test-secret", "test-issuer", "test-audience", 15*time.Minute, time.Hour),
+		NewPasswordService())
+}
+
+func TestLoginOIDCMatchesIssuerAndSubject(t *testing.T) {
+	service := newTestOIDCService()
+	identity := &OIDCIdentity{Issuer: "https://idp.example.com", Subject: "user-1", Email: "jane@example.com", EmailVerified: true, PreferredUsername: "jane"}
+
+	first, err := service.LoginOIDC(identity, []string{RoleViewer}, "test", "127.0.0.1")
+	if err != nil {
+		t.Fatalf("expected first login to create the user, got %v", err)
+	}
+
+	// The same subject signs in to the same user even after their email changes
+	renamed := *identity
+	renamed.Email = "jane.doe@example.com"
+	second, err := service.LoginOIDC(&renamed, []string{RoleViewer}, "test", "127.0.0.1")
+	if err != nil {
+		t.Fatalf("expected returning user to sign in, got %v", err)
+	}
+	if second.User.ID != first.User.ID {
+		t.Errorf("expected user %s, got %s", first.User.ID, second.User.ID)
+	}
+
+	// Another issuer's identity with the same email is not the same user
+	other := *identity
+	other.Issuer = "https://other-idp.example.com"
+	if _, err := service.LoginOIDC(&other, []string{RoleViewer}, "test", "127.0.0.1"); err == nil {
+		t.Error("expected another issuer's identity not to take over the account")
+	}
+}
+
+func TestLoginOIDCRequiresVerifiedEmail(t *testing.T) {
+	service := newTestOIDCService()
+	identity := &OIDCIdentity{Issuer: "https://idp.example.com", Subject: "user-1", Email: "jane@example.com"}
+
+	if _, err := service.LoginOIDC(identity, []string{RoleViewer}, "test", "127.0.0.1"); err == nil {
+		t.Error("expected an unverified email to be refused")
+	}
+}
+
+func TestLoginOIDCDoesNotTakeOverPasswordAccounts(t *testing.T) {
+	service := newTestOIDCService()
+	// The default admin account has a password
+	identity := &OIDCIdentity{Issuer: "https://idp.example.com", Subject: "attacker", Email: "admin@driftmgr.com", EmailVerified: true}
+
+	if _, err := service.LoginOIDC(identity, []string{RoleAdmin}, "test", "127.0.0.1"); err == nil {
+		t.Error("expected an OIDC login not to sign in to a password account with the same email")
+	}
+}
+
+func TestOIDCCallbackRequiresStateCookie(t *testing.T) {
+	issuer := newTestIssuer(t)
+	provider, err := NewOIDCProvider(context.Background(), OIDCConfig{IssuerURL: issuer.server.URL, ClientID: "driftmgr", DefaultRole: RoleViewer})
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+	service := newTestOIDCService()
+	service.SetOIDCProvider(provider)
+	handlers := NewAuthHandlers(service, nil)
+
+	w := httptest.NewRecorder()
+	handlers.OIDCLogin(w, httptest.NewRequest(http.MethodGet, "/api/v1/auth/oidc/login", nil))
+	if w.Code != http.StatusFound {
+		t.Fatalf("expected a redirect, got %d", w.Code)
+	}
+	var stateCookie *http.Cookie
+	for _, cookie := range w.Result().Cookies() {
+		if cookie.Name == oidcStateCookie {
+			stateCookie = cookie
+		}
+	}
+	if stateCookie == nil || !stateCookie.HttpOnly {
+		t.Fatalf("expected an HttpOnly %s cookie, got %v", oidcStateCookie, w.Result().Cookies())
+	}
+	location, err := url.Parse(w.Header().Get("Location"))
+	if err != nil {
+		t.Fatalf("invalid redirect: %v", err)
+	}
+	issuer.idToken = issuer.sign(t, issuer.key, issuer.claims(location.Query().Get("nonce")))
+	callback := "/api/v1/auth/oidc/callback?code=code&state=" + url.QueryEscape(stateCookie.Value)
+
+	// A victim's browser without the cookie cannot complete the attacker's login
+	w = httptest.NewRecorder()
+	handlers.OIDCCallback(w, httptest.NewRequest(http.MethodGet, callback, nil))
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("expected a callback without the state cookie to be refused, got %d", w.Code)
+	}
+
+	// The state is still pending, so the browser that started the login can complete it
+	req := httptest.NewRequest(http.MethodGet, callback, nil)
+	req.AddCookie(stateCookie)
+	w = httptest.NewRecorder()
+	handlers.OIDCCallback(w, req)
+	if w.Code != http.StatusOK {
+		t.Errorf("expected the login to complete, got %d: %s", w.Code, w.Body.String())
+	}
+}
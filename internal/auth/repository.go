@@ -12,6 +12,7 @@ type UserRepository interface {
 	GetByID(id string) (*User, error)
 	GetByUsername(username string) (*User, error)
 	GetByEmail(email string) (*User, error)
+	GetByOIDCSubject(issuer, subject string) (*User, error)
 	Update(user *User) error
 	Delete(id string) error
 	List(limit, offset int) ([]*User, error)
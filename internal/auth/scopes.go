@@ -0,0 +1,84 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// ScopeAll grants access to every provider and account
+const ScopeAll = "*"
+
+// ScopeError is returned when a user's scopes do not cover the provider or account a request
+// targets
+type ScopeError struct {
+	Provider string
+	Account  string
+	Scopes   []string
+}
+
+// Error describes the denied provider or account and what the user may access instead
+func (e *ScopeError) Error() string {
+	allowed := strings.Join(e.Scopes, ", ")
+	if e.Account == "" {
+		if e.Provider != "" && hasProviderScope(e.Scopes, e.Provider) {
+			return fmt.Sprintf("access to %s is limited to specific accounts (%s); an account_id is required", e.Provider, allowed)
+		}
+		return fmt.Sprintf("access to provider %s is not permitted (allowed: %s)", e.Provider, allowed)
+	}
+	return fmt.Sprintf("access to %s account %s is not permitted (allowed: %s)", e.Provider, e.Account, allowed)
+}
+
+// ScopeAllows reports whether scopes grant access to an account of a provider. A scope is
+// "*", a provider such as "aws", or a provider account such as "aws:123456789012". When
+// account is empty only a provider-wide scope allows the request, since the account the
+// provider's default credentials resolve to is unknown.
+func ScopeAllows(scopes []string, provider, account string) bool {
+	for _, scope := range scopes {
+		scopeProvider, scopeAccount, hasAccount := strings.Cut(scope, ":")
+		switch {
+		case scope == ScopeAll:
+			return true
+		case !strings.EqualFold(scopeProvider, provider):
+			continue
+		case !hasAccount || scopeAccount == ScopeAll:
+			return true
+		case account != "" && scopeAccount == account:
+			return true
+		}
+	}
+	return false
+}
+
+// CheckScope returns a *ScopeError when the authenticated user may not act on the provider
+// account. Requests without authentication, from admins, or from users with no scopes are
+// unrestricted.
+func CheckScope(ctx context.Context, provider, account string) error {
+	scopes, ok := GetScopesFromContext(ctx)
+	if !ok || len(scopes) == 0 {
+		return nil
+	}
+	if isAdmin, _ := GetIsAdminFromContext(ctx); isAdmin {
+		return nil
+	}
+	if !ScopeAllows(scopes, provider, account) {
+		return &ScopeError{Provider: provider, Account: account, Scopes: scopes}
+	}
+	return nil
+}
+
+// GetScopesFromContext extracts the user's provider and account scopes from request context
+func GetScopesFromContext(ctx context.Context) ([]string, bool) {
+	scopes, ok := ctx.Value("scopes").([]string)
+	return scopes, ok
+}
+
+// hasProviderScope reports whether any scope names the provider
+func hasProviderScope(scopes []string, provider string) bool {
+	for _, scope := range scopes {
+		if scopeProvider, _, _ := strings.Cut(scope, ":"); strings.EqualFold(scopeProvider, provider) {
+			return true
+		}
+	}
+	return false
+}
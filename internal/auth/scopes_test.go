@@ -0,0 +1,61 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestScopeAllows(t *testing.T) {
+	tests := []struct {
+		name     string
+		scopes   []string
+		provider string
+		account  string
+		allowed  bool
+	}{
+		{"all", []string{"*"}, "aws", "111111111111", true},
+		{"provider", []string{"aws"}, "aws", "111111111111", true},
+		{"provider without account", []string{"aws"}, "aws", "", true},
+		{"other provider", []string{"aws"}, "azure", "", false},
+		{"account", []string{"aws:111111111111"}, "aws", "111111111111", true},
+		{"other account", []string{"aws:111111111111"}, "aws", "222222222222", false},
+		{"account scope needs account", []string{"aws:111111111111"}, "aws", "", false},
+		{"account wildcard", []string{"gcp:*"}, "gcp", "my-project", true},
+		{"no scopes", nil, "aws", "111111111111", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if allowed := ScopeAllows(tt.scopes, tt.provider, tt.account); allowed != tt.allowed {
+				t.Errorf("expected %v, got %v", tt.allowed, allowed)
+			}
+		})
+	}
+}
+
+func TestCheckScope(t *testing.T) {
+	// Unauthenticated requests are not scoped
+	if err := CheckScope(context.Background(), "aws", "222222222222"); err != nil {
+		t.Fatalf("expected no error without authentication, got %v", err)
+	}
+
+	ctx := context.WithValue(context.Background(), "scopes", []string{"aws:111111111111"})
+	if err := CheckScope(ctx, "aws", "111111111111"); err != nil {
+		t.Errorf("expected account in scope to be allowed, got %v", err)
+	}
+
+	err := CheckScope(ctx, "aws", "222222222222")
+	var scopeErr *ScopeError
+	if !errors.As(err, &scopeErr) {
+		t.Fatalf("expected a ScopeError, got %v", err)
+	}
+	if scopeErr.Account != "222222222222" {
+		t.Errorf("expected denied account 222222222222, got %q", scopeErr.Account)
+	}
+
+	admin := context.WithValue(ctx, "is_admin", true)
+	if err := CheckScope(admin, "azure", ""); err != nil {
+		t.Errorf("expected admin to be unrestricted, got %v", err)
+	}
+}
@@ -16,6 +16,8 @@ type Service struct {
 	apiKeyRepo      APIKeyRepository
 	jwtService      *JWTService
 	passwordService *PasswordService
+	serviceTokens   *ServiceTokenStore
+	oidcProvider    *OIDCProvider
 }
 
 // JWTService returns the JWT service for middleware access
@@ -23,6 +25,16 @@ func (s *Service) JWTService() *JWTService {
 	return s.jwtService
 }
 
+// SetServiceTokens sets the store service account tokens are issued from and validated against
+func (s *Service) SetServiceTokens(store *ServiceTokenStore) {
+	s.serviceTokens = store
+}
+
+// ServiceTokens returns the service token store, or nil when service tokens are unavailable
+func (s *Service) ServiceTokens() *ServiceTokenStore {
+	return s.serviceTokens
+}
+
 // NewService creates a new authentication service
 func NewService(
 	userRepo UserRepository,
@@ -70,8 +82,126 @@ func (s *Service) Login(req *LoginRequest, userAgent, ipAddress string) (*AuthRe
 		return nil, fmt.Errorf("failed to get user roles: %w", err)
 	}
 
+	return s.issueSession(user, s.getRoleNames(roles), userAgent, ipAddress)
+}
+
+// LoginOIDC signs in a user an OIDC issuer has authenticated. Users are matched by the
+// identity's issuer and subject, and created on first login. The user's roles are replaced
+// with those mapped from their OIDC groups, so removing a user from a group takes effect at
+// their next login.
+func (s *Service) LoginOIDC(identity *OIDCIdentity, roleNames []string, userAgent, ipAddress string) (*AuthResponse, error) {
+	if identity.Issuer == "" || identity.Subject == "" {
+		return nil, errors.New("OIDC identity has no issuer or subject")
+	}
+
+	user, err := s.userRepo.GetByOIDCSubject(identity.Issuer, identity.Subject)
+	if err != nil {
+		if user, err = s.createOIDCUser(identity); err != nil {
+			return nil, err
+		}
+	}
+	if !user.IsActive {
+		return nil, errors.New("account is disabled")
+	}
+
+	if err := s.syncRoles(user, roleNames); err != nil {
+		return nil, err
+	}
+	user.IsAdmin = containsString(roleNames, RoleAdmin)
+
+	return s.issueSession(user, roleNames, userAgent, ipAddress)
+}
+
+// createOIDCUser links an OIDC identity signing in for the first time to a local user,
+// creating one. The issuer must have verified the email address. An existing user with that
+// address is only linked when it has no password and no OIDC identity, as left by OIDC logins
+// before identities were recorded; other accounts are never taken over by email alone.
+func (s *Service) createOIDCUser(identity *OIDCIdentity) (*User, error) {
+	if identity.Email == "" {
+		return nil, errors.New("OIDC identity has no email address")
+	}
+	if !identity.EmailVerified {
+		return nil, errors.New("OIDC identity's email address is not verified")
+	}
+
+	if existing, err := s.userRepo.GetByEmail(identity.Email); err == nil {
+		if existing.PasswordHash != "" || existing.OIDCSubject != "" {
+			return nil, errors.New("an account with this email address already exists and is not linked to this identity")
+		}
+		existing.OIDCIssuer = identity.Issuer
+		existing.OIDCSubject = identity.Subject
+		if err := s.userRepo.Update(existing); err != nil {
+			return nil, fmt.Errorf("failed to link OIDC user: %w", err)
+		}
+		return existing, nil
+	}
+
+	username := identity.PreferredUsername
+	if username == "" {
+		username = identity.Email
+	}
+	user := &User{
+		ID:          uuid.New().String(),
+		Username:    username,
+		Email:       identity.Email,
+		FirstName:   identity.GivenName,
+		LastName:    identity.FamilyName,
+		IsActive:    true,
+		OIDCIssuer:  identity.Issuer,
+		OIDCSubject: identity.Subject,
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+	}
+	// OIDC users have no password hash; they can only sign in through their issuer
+	if err := s.userRepo.Create(user); err != nil {
+		return nil, fmt.Errorf("failed to create OIDC user: %w", err)
+	}
+	return user, nil
+}
+
+// SetOIDCProvider enables single sign-on through an OIDC issuer alongside local logins
+func (s *Service) SetOIDCProvider(provider *OIDCProvider) {
+	s.oidcProvider = provider
+}
+
+// OIDCProvider returns the OIDC provider, or nil when single sign-on is not configured
+func (s *Service) OIDCProvider() *OIDCProvider {
+	return s.oidcProvider
+}
+
+// syncRoles assigns the user exactly the named roles
+func (s *Service) syncRoles(user *User, roleNames []string) error {
+	current, err := s.roleRepo.GetByUserID(user.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get user roles: %w", err)
+	}
+	for _, role := range current {
+		if !containsString(roleNames, role.Name) {
+			if err := s.roleRepo.RemoveRole(user.ID, role.ID); err != nil {
+				return fmt.Errorf("failed to remove role %s: %w", role.Name, err)
+			}
+		}
+	}
+	currentNames := s.getRoleNames(current)
+	for _, name := range roleNames {
+		if containsString(currentNames, name) {
+			continue
+		}
+		role, err := s.roleRepo.GetByName(name)
+		if err != nil {
+			return fmt.Errorf("failed to find role %s: %w", name, err)
+		}
+		if err := s.roleRepo.AssignRole(&UserRole{ID: uuid.New().String(), UserID: user.ID, RoleID: role.ID, CreatedAt: time.Now()}); err != nil {
+			return fmt.Errorf("failed to assign role %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// issueSession generates tokens for an authenticated user and records their session
+func (s *Service) issueSession(user *User, roleNames []string, userAgent, ipAddress string) (*AuthResponse, error) {
 	// Generate tokens
-	accessToken, err := s.jwtService.GenerateAccessToken(user, s.getRoleNames(roles))
+	accessToken, err := s.jwtService.GenerateAccessToken(user, roleNames)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate access token: %w", err)
 	}
@@ -490,6 +620,7 @@ func (s *Service) toUserResponse(user *User, roles []Role) *UserResponse {
 		IsActive:  user.IsActive,
 		IsAdmin:   user.IsAdmin,
 		Roles:     roles,
+		Scopes:    user.Scopes,
 		LastLogin: user.LastLogin,
 		CreatedAt: user.CreatedAt,
 		UpdatedAt: user.UpdatedAt,
@@ -0,0 +1,269 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ServiceTokenPrefix starts every service token, telling them apart from session JWTs
+const ServiceTokenPrefix = "dmt_"
+
+// AuthTypeServiceToken is the auth type of requests authenticated with a service token
+const AuthTypeServiceToken = "service_token"
+
+var (
+	// ErrServiceTokenNotFound is returned for an unknown service token ID
+	ErrServiceTokenNotFound = errors.New("service token not found")
+	// ErrServiceTokenInvalid is returned when a presented token does not match any service token
+	ErrServiceTokenInvalid = errors.New("invalid service token")
+	// ErrServiceTokenRevoked is returned when a presented token has been revoked
+	ErrServiceTokenRevoked = errors.New("service token has been revoked")
+	// ErrServiceTokenExpired is returned when a presented token is past its expiry
+	ErrServiceTokenExpired = errors.New("service token has expired")
+)
+
+// serviceTokenSchema creates the service tokens table. Only a SHA-256 hash of each token is
+// stored; the token itself is returned once, when it is created.
+const serviceTokenSchema = `
+CREATE TABLE IF NOT EXISTS service_tokens (
+	id           TEXT PRIMARY KEY,
+	name         TEXT NOT NULL,
+	token_hash   TEXT NOT NULL UNIQUE,
+	token_prefix TEXT NOT NULL,
+	permissions  TEXT NOT NULL,
+	scopes       TEXT NOT NULL DEFAULT '[]',
+	created_by   TEXT NOT NULL DEFAULT '',
+	created_at   TIMESTAMP NOT NULL,
+	expires_at   TIMESTAMP,
+	last_used_at TIMESTAMP,
+	revoked_at   TIMESTAMP
+);`
+
+// ServiceToken is a long-lived credential issued to a service account, such as a CI
+// pipeline, for non-interactive access
+type ServiceToken struct {
+	ID          string     `json:"id"`
+	Name        string     `json:"name"`
+	Prefix      string     `json:"prefix"`
+	Permissions []string   `json:"permissions"`
+	Scopes      []string   `json:"scopes,omitempty"`
+	CreatedBy   string     `json:"created_by,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt  *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt   *time.Time `json:"revoked_at,omitempty"`
+}
+
+// CreateServiceTokenRequest represents a request to issue a service token
+type CreateServiceTokenRequest struct {
+	Name        string     `json:"name" validate:"required,min=1,max=100"`
+	Permissions []string   `json:"permissions" validate:"required,min=1"`
+	Scopes      []string   `json:"scopes"`
+	ExpiresAt   *time.Time `json:"expires_at"`
+}
+
+// ServiceTokenResponse is a newly issued service token, the only time the token is shown
+type ServiceTokenResponse struct {
+	*ServiceToken
+	Token string `json:"token"`
+}
+
+// ServiceTokenStore persists service tokens in a SQL database
+type ServiceTokenStore struct {
+	db *sql.DB
+}
+
+// NewServiceTokenStore creates a service token store on an open database, creating its table
+func NewServiceTokenStore(db *sql.DB) (*ServiceTokenStore, error) {
+	if _, err := db.Exec(serviceTokenSchema); err != nil {
+		return nil, fmt.Errorf("failed to create service tokens table: %w", err)
+	}
+	return &ServiceTokenStore{db: db}, nil
+}
+
+// Create issues a service token with the requested permissions and scopes
+func (s *ServiceTokenStore) Create(ctx context.Context, req *CreateServiceTokenRequest, createdBy string) (*ServiceTokenResponse, error) {
+	if err := validatePermissions(req.Permissions); err != nil {
+		return nil, err
+	}
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		return nil, errors.New("expires_at must be in the future")
+	}
+
+	token, err := generateServiceToken()
+	if err != nil {
+		return nil, err
+	}
+	serviceToken := &ServiceToken{
+		ID:          fmt.Sprintf("token-%s", uuid.New().String()),
+		Name:        req.Name,
+		Prefix:      token[:len(ServiceTokenPrefix)+8],
+		Permissions: req.Permissions,
+		Scopes:      req.Scopes,
+		CreatedBy:   createdBy,
+		CreatedAt:   time.Now().UTC(),
+		ExpiresAt:   req.ExpiresAt,
+	}
+	permissions, err := json.Marshal(serviceToken.Permissions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal permissions: %w", err)
+	}
+	scopes, err := json.Marshal(serviceToken.Scopes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal scopes: %w", err)
+	}
+
+	_, err = s.db.ExecContext(ctx, `
+		INSERT INTO service_tokens (id, name, token_hash, token_prefix, permissions, scopes, created_by, created_at, expires_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		serviceToken.ID, serviceToken.Name, hashServiceToken(token), serviceToken.Prefix,
+		string(permissions), string(scopes), serviceToken.CreatedBy, serviceToken.CreatedAt, serviceToken.ExpiresAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create service token: %w", err)
+	}
+	return &ServiceTokenResponse{ServiceToken: serviceToken, Token: token}, nil
+}
+
+// Validate returns the service token matching a presented token, recording its use. Revoked
+// and expired tokens are rejected.
+func (s *ServiceTokenStore) Validate(ctx context.Context, token string) (*ServiceToken, error) {
+	row := s.db.QueryRowContext(ctx, `
+		SELECT id, name, token_prefix, permissions, scopes, created_by, created_at, expires_at, last_used_at, revoked_at
+		FROM service_tokens WHERE token_hash = ?`, hashServiceToken(token))
+	serviceToken, err := scanServiceToken(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrServiceTokenInvalid
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	switch {
+	case serviceToken.RevokedAt != nil:
+		return nil, ErrServiceTokenRevoked
+	case serviceToken.ExpiresAt != nil && now.After(*serviceToken.ExpiresAt):
+		return nil, ErrServiceTokenExpired
+	}
+
+	if _, err := s.db.ExecContext(ctx, `UPDATE service_tokens SET last_used_at = ? WHERE id = ?`, now, serviceToken.ID); err != nil {
+		return nil, fmt.Errorf("failed to record service token use: %w", err)
+	}
+	serviceToken.LastUsedAt = &now
+	return serviceToken, nil
+}
+
+// List returns every service token, including revoked ones, oldest first
+func (s *ServiceTokenStore) List(ctx context.Context) ([]*ServiceToken, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, name, token_prefix, permissions, scopes, created_by, created_at, expires_at, last_used_at, revoked_at
+		FROM service_tokens ORDER BY created_at ASC`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list service tokens: %w", err)
+	}
+	defer rows.Close()
+
+	tokens := []*ServiceToken{}
+	for rows.Next() {
+		serviceToken, err := scanServiceToken(rows)
+		if err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, serviceToken)
+	}
+	return tokens, rows.Err()
+}
+
+// Revoke revokes a service token so it is no longer accepted. Revoking a revoked token keeps
+// its original revocation time.
+func (s *ServiceTokenStore) Revoke(ctx context.Context, id string) (*ServiceToken, error) {
+	_, err := s.db.ExecContext(ctx, `UPDATE service_tokens SET revoked_at = ? WHERE id = ? AND revoked_at IS NULL`,
+		time.Now().UTC(), id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to revoke service token: %w", err)
+	}
+
+	row := s.db.QueryRowContext(ctx, `
+		SELECT id, name, token_prefix, permissions, scopes, created_by, created_at, expires_at, last_used_at, revoked_at
+		FROM service_tokens WHERE id = ?`, id)
+	serviceToken, err := scanServiceToken(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrServiceTokenNotFound
+	}
+	return serviceToken, err
+}
+
+// generateServiceToken returns a new random service token
+func generateServiceToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate service token: %w", err)
+	}
+	return ServiceTokenPrefix + hex.EncodeToString(b), nil
+}
+
+// hashServiceToken returns the hash a service token is stored and looked up by. Tokens carry
+// 256 random bits, so an unsalted SHA-256 is enough and allows lookup by hash.
+func hashServiceToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// validatePermissions checks that every permission is one the system defines
+func validatePermissions(permissions []string) error {
+	if len(permissions) == 0 {
+		return errors.New("at least one permission is required")
+	}
+	for _, permission := range permissions {
+		known := false
+		for _, defined := range DefaultRoles[RoleAdmin] {
+			if permission == defined {
+				known = true
+				break
+			}
+		}
+		if !known {
+			return fmt.Errorf("unknown permission %q", permission)
+		}
+	}
+	return nil
+}
+
+// scanServiceToken reads a service token from a row
+func scanServiceToken(row interface{ Scan(...interface{}) error }) (*ServiceToken, error) {
+	var serviceToken ServiceToken
+	var permissions, scopes string
+	var expiresAt, lastUsedAt, revokedAt sql.NullTime
+	if err := row.Scan(&serviceToken.ID, &serviceToken.Name, &serviceToken.Prefix, &permissions, &scopes,
+		&serviceToken.CreatedBy, &serviceToken.CreatedAt, &expiresAt, &lastUsedAt, &revokedAt); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to read service token: %w", err)
+	}
+	if err := json.Unmarshal([]byte(permissions), &serviceToken.Permissions); err != nil {
+		return nil, fmt.Errorf("failed to parse permissions of %s: %w", serviceToken.ID, err)
+	}
+	if err := json.Unmarshal([]byte(scopes), &serviceToken.Scopes); err != nil {
+		return nil, fmt.Errorf("failed to parse scopes of %s: %w", serviceToken.ID, err)
+	}
+	for _, t := range []struct {
+		src sql.NullTime
+		dst **time.Time
+	}{{expiresAt, &serviceToken.ExpiresAt}, {lastUsedAt, &serviceToken.LastUsedAt}, {revokedAt, &serviceToken.RevokedAt}} {
+		if t.src.Valid {
+			value := t.src.Time
+			*t.dst = &value
+		}
+	}
+	return &serviceToken, nil
+}
@@ -0,0 +1,44 @@
+package auth
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGenerateServiceToken(t *testing.T) {
+	token, err := generateServiceToken()
+	if err != nil {
+		t.Fatalf("failed to generate token: %v", err)
+	}
+	if !strings.HasPrefix(token, ServiceTokenPrefix) {
+		t.Errorf("expected token to start with %q, got %q", ServiceTokenPrefix, token)
+	}
+
+	other, err := generateServiceToken()
+	if err != nil {
+		t.Fatalf("failed to generate token: %v", err)
+	}
+	if token == other {
+		t.Error("expected generated tokens to differ")
+	}
+
+	hash := hashServiceToken(token)
+	if hash == token || strings.Contains(hash, token[len(ServiceTokenPrefix):]) {
+		t.Error("expected the stored hash not to contain the token")
+	}
+	if hashServiceToken(token) != hash {
+		t.Error("expected hashing to be deterministic so tokens can be looked up by hash")
+	}
+}
+
+func TestValidatePermissions(t *testing.T) {
+	if err := validatePermissions([]string{PermissionResourceRead, PermissionRemediationWrite}); err != nil {
+		t.Errorf("expected defined permissions to be valid, got %v", err)
+	}
+	if err := validatePermissions(nil); err == nil {
+		t.Error("expected an error without permissions")
+	}
+	if err := validatePermissions([]string{PermissionDriftRead, "drift:everything"}); err == nil {
+		t.Error("expected an error for an unknown permission")
+	}
+}
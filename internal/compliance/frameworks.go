@@ -0,0 +1,150 @@
+package compliance
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// Framework maps the controls of a compliance framework, such as the CIS AWS Foundations
+// Benchmark or PCI-DSS, to the policies that check them. Frameworks are defined in JSON so
+// new ones can be added without code changes.
+type Framework struct {
+	ID       string             `json:"id"`
+	Name     string             `json:"name"`
+	Version  string             `json:"version,omitempty"`
+	Controls []FrameworkControl `json:"controls"`
+}
+
+// FrameworkControl is a control checked by one or more policies. Policies name violation
+// rules (such as s3_no_public_access) or whole policy packages. ResourceTypes limits the
+// resources the control applies to; a control with no applicable resources is not assessed.
+type FrameworkControl struct {
+	ID            string   `json:"id"`
+	Title         string   `json:"title"`
+	Description   string   `json:"description,omitempty"`
+	Policies      []string `json:"policies"`
+	ResourceTypes []string `json:"resource_types,omitempty"`
+}
+
+// ControlResult is the outcome of a control across the evaluated resources
+type ControlResult struct {
+	ID               string            `json:"id"`
+	Title            string            `json:"title"`
+	Status           ControlStatus     `json:"status"`
+	Policies         []string          `json:"policies"`
+	Resources        int               `json:"resources"`
+	FailingResources []string          `json:"failing_resources,omitempty"`
+	Violations       []PolicyViolation `json:"violations,omitempty"`
+}
+
+// ComplianceScorecard is the pass/fail scorecard of a framework. Score is the percentage of
+// assessed controls that passed.
+type ComplianceScorecard struct {
+	Framework   string          `json:"framework"`
+	Name        string          `json:"name"`
+	Version     string          `json:"version,omitempty"`
+	Score       float64         `json:"score"`
+	Passed      int             `json:"passed"`
+	Failed      int             `json:"failed"`
+	NotAssessed int             `json:"not_assessed"`
+	Controls    []ControlResult `json:"controls"`
+}
+
+// LoadFrameworks reads every *.json framework definition in dir, keyed by framework ID
+func LoadFrameworks(dir string) (map[string]Framework, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list framework files: %w", err)
+	}
+
+	frameworks := make(map[string]Framework, len(files))
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read framework %s: %w", file, err)
+		}
+		var framework Framework
+		if err := json.Unmarshal(data, &framework); err != nil {
+			return nil, fmt.Errorf("failed to parse framework %s: %w", file, err)
+		}
+		if framework.ID == "" {
+			return nil, fmt.Errorf("framework %s has no id", file)
+		}
+		frameworks[framework.ID] = framework
+	}
+	return frameworks, nil
+}
+
+// BuildScorecard maps resource policy results to the framework's controls. A control fails
+// when any applicable resource violates one of its policies, passes when it has applicable
+// resources and none do, and is otherwise not assessed.
+func BuildScorecard(framework Framework, results []ResourcePolicyResult) ComplianceScorecard {
+	scorecard := ComplianceScorecard{
+		Framework: framework.ID,
+		Name:      framework.Name,
+		Version:   framework.Version,
+		Controls:  make([]ControlResult, 0, len(framework.Controls)),
+	}
+
+	for _, control := range framework.Controls {
+		policies := make(map[string]bool, len(control.Policies))
+		for _, policy := range control.Policies {
+			policies[policy] = true
+		}
+		types := make(map[string]bool, len(control.ResourceTypes))
+		for _, resourceType := range control.ResourceTypes {
+			types[resourceType] = true
+		}
+
+		outcome := ControlResult{
+			ID:       control.ID,
+			Title:    control.Title,
+			Policies: control.Policies,
+		}
+		failing := make(map[string]bool)
+		for _, result := range results {
+			if len(types) > 0 && !types[result.ResourceType] {
+				continue
+			}
+			outcome.Resources++
+			for _, violation := range result.Violations {
+				if !policies[violation.Rule] && !policies[violationPolicy(violation)] {
+					continue
+				}
+				outcome.Violations = append(outcome.Violations, violation)
+				if !failing[result.ResourceID] {
+					failing[result.ResourceID] = true
+					outcome.FailingResources = append(outcome.FailingResources, result.ResourceID)
+				}
+			}
+		}
+		sort.Strings(outcome.FailingResources)
+
+		switch {
+		case len(outcome.FailingResources) > 0:
+			outcome.Status = ControlStatusFailed
+			scorecard.Failed++
+		case outcome.Resources > 0:
+			outcome.Status = ControlStatusPassed
+			scorecard.Passed++
+		default:
+			outcome.Status = ControlStatusNotAssessed
+			scorecard.NotAssessed++
+		}
+		scorecard.Controls = append(scorecard.Controls, outcome)
+	}
+
+	if assessed := scorecard.Passed + scorecard.Failed; assessed > 0 {
+		scorecard.Score = float64(scorecard.Passed) / float64(assessed) * 100
+	}
+	return scorecard
+}
+
+// violationPolicy returns the package recorded on a violation by EvaluateResources
+func violationPolicy(violation PolicyViolation) string {
+	policy, _ := violation.Details["policy"].(string)
+	return policy
+}
@@ -28,6 +28,7 @@ type OPAEngine struct {
 	pluginMode    bool
 	localPolicies string
 	compiler      *ast.Compiler
+	prepared      map[string]rego.PreparedEvalQuery // compiled queries by package
 }
 
 // Policy represents an OPA policy
@@ -105,6 +106,7 @@ func NewOPAEngine(config OPAConfig) *OPAEngine {
 		policies:      make(map[string]*Policy),
 		cache:         make(map[string]*CachedDecision),
 		compiler:      ast.NewCompiler(),
+		prepared:      make(map[string]rego.PreparedEvalQuery),
 		httpClient: &http.Client{
 			Timeout: config.Timeout,
 		},
@@ -174,6 +176,7 @@ func (e *OPAEngine) loadLocalPolicies() error {
 	if e.compiler.Failed() {
 		return fmt.Errorf("failed to compile policies: %v", e.compiler.Errors)
 	}
+	e.prepared = make(map[string]rego.PreparedEvalQuery)
 
 	// Debug logging (commented out for production)
 	// fmt.Printf("DEBUG: Loaded %d policies\n", len(e.policies))
@@ -304,14 +307,12 @@ func (e *OPAEngine) evaluateLocal(ctx context.Context, policyPackage string, inp
 		"tags":      input.Tags,
 	}
 
-	// Create a query that returns both allow and violations
-	query := fmt.Sprintf("data.%s", strings.ReplaceAll(policyPackage, ".", "."))
-	results, err := rego.New(
-		rego.Query(query),
-		rego.Compiler(e.compiler),
-		rego.Input(inputMap),
-	).Eval(ctx)
-
+	// Evaluate the package's compiled query, which returns both allow and violations
+	query, err := e.preparedQuery(ctx, policyPackage)
+	if err != nil {
+		return nil, err
+	}
+	results, err := query.Eval(ctx, rego.EvalInput(inputMap))
 	if err != nil {
 		return nil, fmt.Errorf("failed to evaluate policy: %w", err)
 	}
@@ -367,9 +368,17 @@ func (e *OPAEngine) evaluateLocal(ctx context.Context, policyPackage string, inp
 							}
 						}
 					} else if violations, ok := dataMap["violations"].([]interface{}); ok {
-						// Handle violations as an array of strings
+						// Handle violations as an array of strings or violation objects
 						for _, violation := range violations {
-							if violationStr, ok := violation.(string); ok {
+							if violationMap, ok := violation.(map[string]interface{}); ok {
+								decision.Violations = append(decision.Violations, PolicyViolation{
+									Rule:        getString(violationMap, "rule"),
+									Message:     getString(violationMap, "message"),
+									Severity:    getString(violationMap, "severity"),
+									Resource:    getString(violationMap, "resource"),
+									Remediation: getString(violationMap, "remediation"),
+								})
+							} else if violationStr, ok := violation.(string); ok {
 								pv := PolicyViolation{
 									Rule:        "required_tags",
 									Message:     fmt.Sprintf("Policy violation: %s", violationStr),
@@ -394,6 +403,30 @@ func (e *OPAEngine) evaluateLocal(ctx context.Context, policyPackage string, inp
 	return decision, nil
 }
 
+// preparedQuery returns the compiled query for a policy package, preparing it on first use
+// so repeated evaluations skip parsing and planning
+func (e *OPAEngine) preparedQuery(ctx context.Context, policyPackage string) (rego.PreparedEvalQuery, error) {
+	e.mu.RLock()
+	query, ok := e.prepared[policyPackage]
+	e.mu.RUnlock()
+	if ok {
+		return query, nil
+	}
+
+	query, err := rego.New(
+		rego.Query("data."+policyPackage),
+		rego.Compiler(e.compiler),
+	).PrepareForEval(ctx)
+	if err != nil {
+		return query, fmt.Errorf("failed to prepare policy %s: %w", policyPackage, err)
+	}
+
+	e.mu.Lock()
+	e.prepared[policyPackage] = query
+	e.mu.Unlock()
+	return query, nil
+}
+
 // UploadPolicy uploads a new policy to OPA
 func (e *OPAEngine) UploadPolicy(ctx context.Context, policy *Policy) error {
 	if !e.pluginMode || e.endpoint == "" {
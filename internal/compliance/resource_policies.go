@@ -0,0 +1,99 @@
+package compliance
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/catherinevee/driftmgr/pkg/models"
+)
+
+// ResourcePolicyResult is the outcome of evaluating policies against one resource
+type ResourcePolicyResult struct {
+	ResourceID   string            `json:"resource_id"`
+	ResourceType string            `json:"resource_type"`
+	Provider     string            `json:"provider"`
+	Region       string            `json:"region,omitempty"`
+	Passed       bool              `json:"passed"`
+	Violations   []PolicyViolation `json:"violations,omitempty"`
+}
+
+// PolicyPackages returns the packages of the loaded policies, sorted
+func (e *OPAEngine) PolicyPackages() []string {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	seen := make(map[string]bool)
+	var packages []string
+	for _, policy := range e.policies {
+		if policy.Package != "" && !seen[policy.Package] {
+			seen[policy.Package] = true
+			packages = append(packages, policy.Package)
+		}
+	}
+	sort.Strings(packages)
+	return packages
+}
+
+// EvaluateResources evaluates each resource against the given policy packages, or every
+// loaded package when none are given. The resource is available to policies as
+// input.resource in its JSON form (input.resource.type, input.resource.attributes, ...).
+// Each violation's details record the package that raised it.
+func (e *OPAEngine) EvaluateResources(ctx context.Context, resources []models.Resource, packages []string) ([]ResourcePolicyResult, error) {
+	if len(packages) == 0 {
+		packages = e.PolicyPackages()
+	}
+
+	results := make([]ResourcePolicyResult, 0, len(resources))
+	for _, resource := range resources {
+		document, err := resourceDocument(resource)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode resource %s: %w", resource.ID, err)
+		}
+
+		result := ResourcePolicyResult{
+			ResourceID:   resource.ID,
+			ResourceType: resource.Type,
+			Provider:     resource.Provider,
+			Region:       resource.Region,
+			Passed:       true,
+		}
+		for _, policyPackage := range packages {
+			decision, err := e.Evaluate(ctx, policyPackage, PolicyInput{
+				Resource: document,
+				Action:   "evaluate",
+				Provider: resource.Provider,
+				Region:   resource.Region,
+				Tags:     resource.Tags,
+			})
+			if err != nil {
+				return nil, err
+			}
+			for _, violation := range decision.Violations {
+				violation.Details = map[string]interface{}{"policy": policyPackage}
+				if violation.Resource == "" {
+					violation.Resource = resource.ID
+				}
+				result.Violations = append(result.Violations, violation)
+			}
+		}
+		result.Passed = len(result.Violations) == 0
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+// resourceDocument converts a resource to the generic JSON form policies see
+func resourceDocument(resource models.Resource) (map[string]interface{}, error) {
+	data, err := json.Marshal(resource)
+	if err != nil {
+		return nil, err
+	}
+	var document map[string]interface{}
+	if err := json.Unmarshal(data, &document); err != nil {
+		return nil, err
+	}
+	return document, nil
+}
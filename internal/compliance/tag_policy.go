@@ -0,0 +1,117 @@
+package compliance
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+
+	"github.com/catherinevee/driftmgr/pkg/models"
+)
+
+// Tag violation problems
+const (
+	TagMissing = "missing"
+	TagInvalid = "invalid"
+)
+
+// TagPolicy lists the tags resources must carry. AllowedValues maps a tag key to a regular
+// expression its whole value must match; it applies whenever the tag is set, whether or not
+// the key is required.
+type TagPolicy struct {
+	Required      []string          `json:"required" yaml:"required"`
+	AllowedValues map[string]string `json:"allowed_values,omitempty" yaml:"allowed_values,omitempty"`
+}
+
+// TagViolation is one way a resource breaks the tag policy
+type TagViolation struct {
+	Key     string `json:"key"`
+	Problem string `json:"problem"` // missing or invalid
+	Value   string `json:"value,omitempty"`
+	Pattern string `json:"pattern,omitempty"`
+	Message string `json:"message"`
+}
+
+// ResourceTagViolations lists the tag policy violations of one resource
+type ResourceTagViolations struct {
+	ResourceID   string         `json:"resource_id"`
+	ResourceType string         `json:"resource_type"`
+	Provider     string         `json:"provider"`
+	Region       string         `json:"region,omitempty"`
+	AccountID    string         `json:"account_id,omitempty"`
+	Violations   []TagViolation `json:"violations"`
+}
+
+// TagPolicyAnalyzer checks discovered resources against a tag policy
+type TagPolicyAnalyzer struct {
+	policy   TagPolicy
+	patterns map[string]*regexp.Regexp
+}
+
+// NewTagPolicyAnalyzer compiles the allowed value patterns of a policy
+func NewTagPolicyAnalyzer(policy TagPolicy) (*TagPolicyAnalyzer, error) {
+	analyzer := &TagPolicyAnalyzer{policy: policy, patterns: make(map[string]*regexp.Regexp)}
+	for key, pattern := range policy.AllowedValues {
+		compiled, err := regexp.Compile("^(?:" + pattern + ")$")
+		if err != nil {
+			return nil, fmt.Errorf("invalid allowed values pattern for tag %s: %w", key, err)
+		}
+		analyzer.patterns[key] = compiled
+	}
+	return analyzer, nil
+}
+
+// Check returns the violations of one resource: required tags that are missing or empty,
+// then tags whose values do not match their pattern, each sorted by key
+func (a *TagPolicyAnalyzer) Check(resource models.Resource) []TagViolation {
+	var violations []TagViolation
+	for _, key := range a.policy.Required {
+		if resource.Tags[key] == "" {
+			violations = append(violations, TagViolation{
+				Key:     key,
+				Problem: TagMissing,
+				Message: fmt.Sprintf("Required tag %s is missing", key),
+			})
+		}
+	}
+	sort.Slice(violations, func(i, j int) bool { return violations[i].Key < violations[j].Key })
+
+	keys := make([]string, 0, len(a.patterns))
+	for key := range a.patterns {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		value, ok := resource.Tags[key]
+		if !ok || value == "" || a.patterns[key].MatchString(value) {
+			continue
+		}
+		violations = append(violations, TagViolation{
+			Key:     key,
+			Problem: TagInvalid,
+			Value:   value,
+			Pattern: a.policy.AllowedValues[key],
+			Message: fmt.Sprintf("Tag %s value %q does not match %s", key, value, a.policy.AllowedValues[key]),
+		})
+	}
+	return violations
+}
+
+// Analyze returns the resources that break the policy with their violations
+func (a *TagPolicyAnalyzer) Analyze(resources []models.Resource) []ResourceTagViolations {
+	results := make([]ResourceTagViolations, 0)
+	for _, resource := range resources {
+		violations := a.Check(resource)
+		if len(violations) == 0 {
+			continue
+		}
+		results = append(results, ResourceTagViolations{
+			ResourceID:   resource.ID,
+			ResourceType: resource.Type,
+			Provider:     resource.Provider,
+			Region:       resource.Region,
+			AccountID:    resource.AccountID,
+			Violations:   violations,
+		})
+	}
+	return results
+}
@@ -0,0 +1,40 @@
+package compliance
+
+import (
+	"testing"
+
+	"github.com/catherinevee/driftmgr/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTagPolicyAnalyzer(t *testing.T) {
+	analyzer, err := NewTagPolicyAnalyzer(TagPolicy{
+		Required:      []string{"Owner", "Environment", "CostCenter"},
+		AllowedValues: map[string]string{"Environment": "dev|staging|prod", "CostCenter": `CC-\d{4}`},
+	})
+	require.NoError(t, err)
+
+	results := analyzer.Analyze([]models.Resource{
+		{ID: "i-ok", Type: "aws_instance", Tags: map[string]string{"Owner": "team-a", "Environment": "prod", "CostCenter": "CC-1234"}},
+		{ID: "i-bad", Type: "aws_instance", Provider: "aws", Tags: map[string]string{"Environment": "production", "CostCenter": "", "Owner": "team-b"}},
+		{ID: "i-none", Type: "aws_instance"},
+	})
+
+	require.Len(t, results, 2)
+	assert.Equal(t, "i-bad", results[0].ResourceID)
+	assert.Equal(t, []TagViolation{
+		{Key: "CostCenter", Problem: TagMissing, Message: "Required tag CostCenter is missing"},
+		{Key: "Environment", Problem: TagInvalid, Value: "production", Pattern: "dev|staging|prod",
+			Message: `Tag Environment value "production" does not match dev|staging|prod`},
+	}, results[0].Violations, "patterns must match the whole value")
+
+	assert.Equal(t, "i-none", results[1].ResourceID)
+	require.Len(t, results[1].Violations, 3)
+	assert.Equal(t, "CostCenter", results[1].Violations[0].Key)
+}
+
+func TestNewTagPolicyAnalyzerRejectsInvalidPatterns(t *testing.T) {
+	_, err := NewTagPolicyAnalyzer(TagPolicy{AllowedValues: map[string]string{"Owner": "("}})
+	assert.Error(t, err)
+}
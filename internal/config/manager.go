@@ -28,6 +28,9 @@ type ServerConfig struct {
 	AuthEnabled  bool          `json:"auth_enabled"`
 	TLSCert      string        `json:"tls_cert,omitempty"`
 	TLSKey       string        `json:"tls_key,omitempty"`
+	// LongRequestTimeout replaces the read and write timeouts for long-running routes such as
+	// synchronous discovery
+	LongRequestTimeout time.Duration `json:"long_request_timeout"`
 }
 
 // BackendConfig represents backend configuration
@@ -253,6 +256,10 @@ func (cm *ConfigManager) validateConfig(config *Config) error {
 		return fmt.Errorf("invalid write timeout: %v", config.Server.WriteTimeout)
 	}
 
+	if config.Server.LongRequestTimeout < 0 {
+		return fmt.Errorf("invalid long request timeout: %v", config.Server.LongRequestTimeout)
+	}
+
 	// Validate backend configuration
 	if config.Backend.Type == "" {
 		return fmt.Errorf("backend type is required")
@@ -0,0 +1,268 @@
+package cost
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/catherinevee/driftmgr/pkg/models"
+	"gopkg.in/yaml.v3"
+)
+
+// hoursPerMonth is the number of hours cloud providers bill a month at
+const hoursPerMonth = 730
+
+// PriceSource supplies hourly USD prices for a resource type and size, where size is the
+// instance type, instance class, volume type or storage class depending on the resource
+type PriceSource interface {
+	HourlyPrice(resourceType, size, region string) (float64, bool)
+}
+
+// StaticPriceSource is a price list held in memory, keyed by resource type then size.
+// Prices are hourly; per GB-month storage prices are divided by 730.
+type StaticPriceSource struct {
+	Prices map[string]map[string]float64 `json:"prices" yaml:"prices"`
+	// RegionMultipliers scales prices for regions priced differently from the list, such as
+	// {"sa-east-1": 1.5}; regions without an entry use the list price
+	RegionMultipliers map[string]float64 `json:"region_multipliers,omitempty" yaml:"region_multipliers,omitempty"`
+}
+
+// HourlyPrice returns the listed price for a resource type and size
+func (s *StaticPriceSource) HourlyPrice(resourceType, size, region string) (float64, bool) {
+	if s == nil {
+		return 0, false
+	}
+	price, ok := s.Prices[resourceType][size]
+	if !ok {
+		return 0, false
+	}
+	if multiplier, ok := s.RegionMultipliers[region]; ok {
+		price *= multiplier
+	}
+	return price, true
+}
+
+// DefaultAWSPrices returns the bundled AWS on-demand list prices for us-east-1
+func DefaultAWSPrices() *StaticPriceSource {
+	return &StaticPriceSource{Prices: NewAWSCostProvider().pricing}
+}
+
+// LoadPriceFile reads a price list from a JSON or YAML file, for example negotiated rates:
+//
+//	prices:
+//	  aws_instance:
+//	    m5.large: 0.081
+func LoadPriceFile(path string) (*StaticPriceSource, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read price file: %w", err)
+	}
+
+	source := &StaticPriceSource{}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, source)
+	default:
+		err = json.Unmarshal(data, source)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse price file %s: %w", path, err)
+	}
+	if len(source.Prices) == 0 {
+		return nil, fmt.Errorf("price file %s has no prices", path)
+	}
+	return source, nil
+}
+
+// LayeredPriceSource asks each source in turn, so custom rates can override the bundled
+// list prices while falling back to them for anything they do not cover
+type LayeredPriceSource []PriceSource
+
+// HourlyPrice returns the price from the first source that has one
+func (l LayeredPriceSource) HourlyPrice(resourceType, size, region string) (float64, bool) {
+	for _, source := range l {
+		if price, ok := source.HourlyPrice(resourceType, size, region); ok {
+			return price, true
+		}
+	}
+	return 0, false
+}
+
+// CostEstimator estimates the running cost of a discovered resource. The boolean is false
+// when the resource type or its size has no price.
+type CostEstimator interface {
+	EstimateCost(ctx context.Context, resource models.Resource) (*models.CostEstimate, bool)
+}
+
+// AWSCostEstimator estimates AWS resource costs from a price source
+type AWSCostEstimator struct {
+	source PriceSource
+}
+
+// NewAWSCostEstimator creates an estimator using the given price source, or the bundled
+// list prices when source is nil
+func NewAWSCostEstimator(source PriceSource) *AWSCostEstimator {
+	if source == nil {
+		source = DefaultAWSPrices()
+	}
+	return &AWSCostEstimator{source: source}
+}
+
+// EstimateCost estimates the cost of an AWS resource from its size attributes
+func (e *AWSCostEstimator) EstimateCost(ctx context.Context, resource models.Resource) (*models.CostEstimate, bool) {
+	if resource.Provider != "" && resource.Provider != "aws" {
+		return nil, false
+	}
+
+	var size string
+	quantity := 1.0 // storage is priced per GB
+	confidence := "high"
+	switch resource.Type {
+	case "aws_instance":
+		size = resourceAttribute(resource, "instance_type")
+	case "aws_db_instance", "aws_rds_cluster_instance":
+		size = resourceAttribute(resource, "instance_class", "db_instance_class")
+	case "aws_ebs_volume":
+		size = resourceAttribute(resource, "volume_type", "type")
+		quantity, _ = strconv.ParseFloat(resourceAttribute(resource, "size"), 64)
+	case "aws_eip":
+		size = "standard"
+	case "aws_lb", "aws_alb", "aws_elb":
+		size = resourceAttribute(resource, "load_balancer_type")
+		if size == "" {
+			size = "application"
+			confidence = "medium"
+		}
+	default:
+		return nil, false
+	}
+	if size == "" || quantity <= 0 {
+		return nil, false
+	}
+
+	price, ok := e.source.HourlyPrice(resource.Type, size, resource.Region)
+	if !ok {
+		return nil, false
+	}
+	hourly := price * quantity
+
+	return &models.CostEstimate{
+		HourlyCost:       hourly,
+		MonthlyCost:      hourly * hoursPerMonth,
+		YearlyCost:       hourly * hoursPerMonth * 12,
+		Currency:         "USD",
+		EstimationMethod: "price_list",
+		Confidence:       confidence,
+		LastUpdated:      time.Now(),
+	}, true
+}
+
+// resourceAttribute returns the first of the keys set in the resource's attributes,
+// properties or metadata, rendered as a string
+func resourceAttribute(resource models.Resource, keys ...string) string {
+	for _, key := range keys {
+		for _, values := range []map[string]interface{}{resource.Attributes, resource.Properties} {
+			if value := attributeString(values[key]); value != "" {
+				return value
+			}
+		}
+		if value := resource.Metadata[key]; value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// attributeString renders scalar attribute values, dereferencing the pointers SDK types use
+func attributeString(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case *string:
+		if v != nil {
+			return *v
+		}
+	case *int32:
+		if v != nil {
+			return strconv.FormatInt(int64(*v), 10)
+		}
+	case *int64:
+		if v != nil {
+			return strconv.FormatInt(*v, 10)
+		}
+	case fmt.Stringer:
+		return v.String()
+	default:
+		return fmt.Sprint(v)
+	}
+	return ""
+}
+
+// AnnotateCosts sets CostEstimate on each resource the estimator can price and returns
+// how many were priced
+func AnnotateCosts(ctx context.Context, estimator CostEstimator, resources []models.Resource) int {
+	priced := 0
+	for i := range resources {
+		if estimate, ok := estimator.EstimateCost(ctx, resources[i]); ok {
+			resources[i].CostEstimate = estimate
+			priced++
+		}
+	}
+	return priced
+}
+
+// EstimateMonthlyCost totals the estimated monthly cost of resources. Resources the
+// estimator cannot price count as zero; the count of priced resources is returned too.
+func EstimateMonthlyCost(ctx context.Context, estimator CostEstimator, resources []models.Resource) (float64, int) {
+	total := 0.0
+	priced := 0
+	for _, resource := range resources {
+		if estimate, ok := estimator.EstimateCost(ctx, resource); ok {
+			total += estimate.MonthlyCost
+			priced++
+		}
+	}
+	return total, priced
+}
+
+// CostSummary aggregates estimated monthly costs across resources
+type CostSummary struct {
+	Currency         string             `json:"currency"`
+	TotalMonthlyCost float64            `json:"total_monthly_cost"`
+	TotalResources   int                `json:"total_resources"`
+	PricedResources  int                `json:"priced_resources"`
+	ByProvider       map[string]float64 `json:"by_provider"`
+	ByType           map[string]float64 `json:"by_type"`
+	ByRegion         map[string]float64 `json:"by_region"`
+}
+
+// SummarizeCosts totals the cost estimates of resources by provider, type and region.
+// Resources without an estimate are counted but not priced.
+func SummarizeCosts(resources []models.Resource) CostSummary {
+	summary := CostSummary{
+		Currency:       "USD",
+		TotalResources: len(resources),
+		ByProvider:     make(map[string]float64),
+		ByType:         make(map[string]float64),
+		ByRegion:       make(map[string]float64),
+	}
+	for _, resource := range resources {
+		if resource.CostEstimate == nil {
+			continue
+		}
+		monthly := resource.CostEstimate.MonthlyCost
+		summary.PricedResources++
+		summary.TotalMonthlyCost += monthly
+		summary.ByProvider[resource.Provider] += monthly
+		summary.ByType[resource.Type] += monthly
+		summary.ByRegion[resource.Region] += monthly
+	}
+	return summary
+}
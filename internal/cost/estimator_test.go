@@ -0,0 +1,148 @@
+package cost
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/catherinevee/driftmgr/pkg/models"
+)
+
+func TestAWSCostEstimator(t *testing.T) {
+	estimator := NewAWSCostEstimator(nil)
+	size := int32(100)
+
+	tests := []struct {
+		name     string
+		resource models.Resource
+		monthly  float64
+		priced   bool
+	}{
+		{
+			name:     "instance",
+			resource: models.Resource{Type: "aws_instance", Provider: "aws", Attributes: map[string]interface{}{"instance_type": "t3.micro"}},
+			monthly:  0.0104 * 730,
+			priced:   true,
+		},
+		{
+			name:     "volume priced per GB",
+			resource: models.Resource{Type: "aws_ebs_volume", Provider: "aws", Properties: map[string]interface{}{"volume_type": "gp3", "size": &size}},
+			monthly:  0.08 * 100,
+			priced:   true,
+		},
+		{
+			name:     "unknown size",
+			resource: models.Resource{Type: "aws_instance", Provider: "aws", Attributes: map[string]interface{}{"instance_type": "x9.huge"}},
+		},
+		{
+			name:     "unsupported type",
+			resource: models.Resource{Type: "aws_vpc", Provider: "aws"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			estimate, ok := estimator.EstimateCost(context.Background(), tt.resource)
+			if ok != tt.priced {
+				t.Fatalf("expected priced=%v, got %v", tt.priced, ok)
+			}
+			if ok && (estimate.MonthlyCost < tt.monthly-0.001 || estimate.MonthlyCost > tt.monthly+0.001) {
+				t.Errorf("expected a monthly cost of %.2f, got %.2f", tt.monthly, estimate.MonthlyCost)
+			}
+		})
+	}
+}
+
+func TestCustomPricesOverrideListPrices(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rates.yaml")
+	rates := "prices:\n  aws_instance:\n    t3.micro: 0.005\nregion_multipliers:\n  sa-east-1: 2\n"
+	if err := os.WriteFile(path, []byte(rates), 0600); err != nil {
+		t.Fatal(err)
+	}
+	custom, err := LoadPriceFile(path)
+	if err != nil {
+		t.Fatalf("failed to load price file: %v", err)
+	}
+
+	source := LayeredPriceSource{custom, DefaultAWSPrices()}
+	if price, _ := source.HourlyPrice("aws_instance", "t3.micro", "us-east-1"); price != 0.005 {
+		t.Errorf("expected the negotiated rate, got %v", price)
+	}
+	if price, _ := source.HourlyPrice("aws_instance", "t3.micro", "sa-east-1"); price != 0.01 {
+		t.Errorf("expected the region multiplier to apply, got %v", price)
+	}
+	if price, _ := source.HourlyPrice("aws_instance", "m5.large", "us-east-1"); price != 0.096 {
+		t.Errorf("expected the list price fallback, got %v", price)
+	}
+}
+
+func TestSummarizeCosts(t *testing.T) {
+	resources := []models.Resource{
+		{ID: "i-1", Type: "aws_instance", Provider: "aws", Region: "us-east-1", Attributes: map[string]interface{}{"instance_type": "m5.large"}},
+		{ID: "i-2", Type: "aws_instance", Provider: "aws", Region: "eu-west-1", Attributes: map[string]interface{}{"instance_type": "m5.large"}},
+		{ID: "vpc-1", Type: "aws_vpc", Provider: "aws", Region: "us-east-1"},
+	}
+	if priced := AnnotateCosts(context.Background(), NewAWSCostEstimator(nil), resources); priced != 2 {
+		t.Fatalf("expected 2 priced resources, got %d", priced)
+	}
+
+	summary := SummarizeCosts(resources)
+	if summary.TotalResources != 3 || summary.PricedResources != 2 {
+		t.Errorf("expected 3 resources with 2 priced, got %+v", summary)
+	}
+	if summary.ByRegion["us-east-1"] != 0.096*730 || summary.ByType["aws_instance"] != summary.TotalMonthlyCost {
+		t.Errorf("unexpected breakdown: %+v", summary)
+	}
+}
+
+func TestRecommendationEngine(t *testing.T) {
+	resources := []models.Resource{
+		{
+			ID: "i-idle", Type: "aws_instance", Provider: "aws",
+			Attributes: map[string]interface{}{"instance_type": "m5.2xlarge"},
+			Properties: map[string]interface{}{"cpu_utilization_avg": 4.0, "utilization_lookback_days": 14.0},
+		},
+		{
+			ID: "i-busy", Type: "aws_instance", Provider: "aws",
+			Attributes: map[string]interface{}{"instance_type": "m5.2xlarge"},
+			Properties: map[string]interface{}{"cpu_utilization_avg": 60.0},
+		},
+		{
+			ID: "i-critical", Type: "aws_instance", Provider: "aws",
+			Tags:       map[string]string{"Criticality": "Production-Critical"},
+			Attributes: map[string]interface{}{"instance_type": "m5.2xlarge"},
+			Properties: map[string]interface{}{"cpu_utilization_avg": 1.0},
+		},
+		{
+			ID: "db-quiet", Type: "aws_db_instance", Provider: "aws",
+			Attributes: map[string]interface{}{"instance_class": "db.m5.xlarge"},
+			Properties: map[string]interface{}{"cpu_utilization_avg": 15.0},
+		},
+		{
+			ID: "i-unenriched", Type: "aws_instance", Provider: "aws",
+			Attributes: map[string]interface{}{"instance_type": "m5.2xlarge"},
+		},
+	}
+
+	recommendations := NewRecommendationEngine(nil).Recommend(context.Background(), resources)
+	if len(recommendations) != 2 {
+		t.Fatalf("expected 2 recommendations, got %+v", recommendations)
+	}
+
+	// 4% doubles to 8% and 16% within the 50% target, so m5.2xlarge steps down twice
+	instance := recommendations[0]
+	if instance.ResourceID != "i-idle" || instance.RecommendedType != "m5.large" {
+		t.Errorf("expected i-idle to move to m5.large, got %+v", instance)
+	}
+	if saving := (0.384 - 0.096) * 730; instance.MonthlySavings < saving-0.01 || instance.MonthlySavings > saving+0.01 {
+		t.Errorf("expected savings of %.2f, got %.2f", saving, instance.MonthlySavings)
+	}
+	if instance.Parameters["new_instance_type"] != "m5.large" {
+		t.Errorf("expected resize parameters, got %v", instance.Parameters)
+	}
+
+	if db := recommendations[1]; db.ResourceID != "db-quiet" || db.RecommendedType != "db.m5.large" {
+		t.Errorf("expected db-quiet to move to db.m5.large, got %+v", db)
+	}
+}
@@ -83,6 +83,11 @@ func (p *AWSCostProvider) initializePricing() {
 		"network":     0.0225,
 		"gateway":     0.0125,
 	}
+
+	// Public IPv4 address pricing
+	p.pricing["aws_eip"] = map[string]float64{
+		"standard": 0.005,
+	}
 }
 
 func (p *AWSCostProvider) GetResourceCost(ctx context.Context, resourceType string,
@@ -0,0 +1,193 @@
+package cost
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/catherinevee/driftmgr/pkg/models"
+)
+
+// instanceSizes lists EC2 and RDS size suffixes from smallest to largest
+var instanceSizes = []string{
+	"nano", "micro", "small", "medium", "large", "xlarge",
+	"2xlarge", "4xlarge", "8xlarge", "12xlarge", "16xlarge", "24xlarge",
+}
+
+// RightsizingRecommendation suggests a smaller instance type or DB class for a resource whose
+// sustained CPU utilization is low. Operation and Parameters describe the cost remediation
+// that applies it.
+type RightsizingRecommendation struct {
+	ResourceID             string                 `json:"resource_id"`
+	ResourceName           string                 `json:"resource_name,omitempty"`
+	ResourceType           string                 `json:"resource_type"`
+	Region                 string                 `json:"region"`
+	CurrentType            string                 `json:"current_type"`
+	RecommendedType        string                 `json:"recommended_type"`
+	CPUUtilization         float64                `json:"cpu_utilization"`
+	CurrentMonthlyCost     float64                `json:"current_monthly_cost"`
+	RecommendedMonthlyCost float64                `json:"recommended_monthly_cost"`
+	MonthlySavings         float64                `json:"monthly_savings"`
+	Reason                 string                 `json:"reason"`
+	Operation              string                 `json:"operation"`
+	Parameters             map[string]interface{} `json:"parameters"`
+}
+
+// RecommendationEngine recommends smaller instance types and DB classes from the CPU
+// utilization CloudWatch enrichment attaches to resources. Resources carrying any of the
+// protected tag values are never downsized.
+type RecommendationEngine struct {
+	estimator CostEstimator
+	// CPUThreshold is the average CPU percentage below which a resource is a candidate
+	CPUThreshold float64
+	// TargetCPU is the highest average CPU percentage the smaller size is projected to reach
+	TargetCPU float64
+	// ProtectedTags maps tag keys (matched case-insensitively) to values that exempt a resource
+	ProtectedTags map[string][]string
+}
+
+// NewRecommendationEngine creates an engine pricing candidates with the given estimator
+func NewRecommendationEngine(estimator CostEstimator) *RecommendationEngine {
+	if estimator == nil {
+		estimator = NewAWSCostEstimator(nil)
+	}
+	return &RecommendationEngine{
+		estimator:    estimator,
+		CPUThreshold: 20,
+		TargetCPU:    50,
+		ProtectedTags: map[string][]string{
+			"criticality":      {"critical", "production-critical"},
+			"driftmgr:protect": {"true"},
+		},
+	}
+}
+
+// Recommend returns rightsizing recommendations for resources with utilization data, largest
+// savings first
+func (e *RecommendationEngine) Recommend(ctx context.Context, resources []models.Resource) []RightsizingRecommendation {
+	var recommendations []RightsizingRecommendation
+	for _, resource := range resources {
+		if recommendation, ok := e.recommend(ctx, resource); ok {
+			recommendations = append(recommendations, recommendation)
+		}
+	}
+	sort.SliceStable(recommendations, func(i, j int) bool {
+		return recommendations[i].MonthlySavings > recommendations[j].MonthlySavings
+	})
+	return recommendations
+}
+
+// recommend sizes a single resource down while its projected CPU stays under TargetCPU,
+// assuming halving the size doubles utilization
+func (e *RecommendationEngine) recommend(ctx context.Context, resource models.Resource) (RightsizingRecommendation, bool) {
+	var sizeKey string
+	switch resource.Type {
+	case "aws_instance":
+		sizeKey = "instance_type"
+	case "aws_db_instance":
+		sizeKey = "instance_class"
+	default:
+		return RightsizingRecommendation{}, false
+	}
+	if e.IsProtected(resource) {
+		return RightsizingRecommendation{}, false
+	}
+
+	cpu, ok := resource.Properties["cpu_utilization_avg"].(float64)
+	if !ok || cpu >= e.CPUThreshold {
+		return RightsizingRecommendation{}, false
+	}
+	currentType := resourceAttribute(resource, sizeKey, "db_instance_class")
+	current, ok := e.estimator.EstimateCost(ctx, sizedResource(resource, sizeKey, currentType))
+	if !ok {
+		return RightsizingRecommendation{}, false
+	}
+
+	recommendedType := ""
+	var recommended *models.CostEstimate
+	projected := cpu
+	for candidate := smallerSize(currentType); candidate != ""; candidate = smallerSize(candidate) {
+		projected *= 2
+		if projected > e.TargetCPU {
+			break
+		}
+		// Only sizes with a known price are recommended, which also skips sizes a family lacks
+		estimate, ok := e.estimator.EstimateCost(ctx, sizedResource(resource, sizeKey, candidate))
+		if !ok {
+			continue
+		}
+		recommendedType, recommended = candidate, estimate
+	}
+	if recommended == nil || recommended.MonthlyCost >= current.MonthlyCost {
+		return RightsizingRecommendation{}, false
+	}
+
+	savings := current.MonthlyCost - recommended.MonthlyCost
+	lookback := "the lookback window"
+	if days, ok := resource.Properties["utilization_lookback_days"].(float64); ok {
+		lookback = fmt.Sprintf("%.0f days", days)
+	}
+	return RightsizingRecommendation{
+		ResourceID:             resource.ID,
+		ResourceName:           resource.Name,
+		ResourceType:           resource.Type,
+		Region:                 resource.Region,
+		CurrentType:            currentType,
+		RecommendedType:        recommendedType,
+		CPUUtilization:         cpu,
+		CurrentMonthlyCost:     current.MonthlyCost,
+		RecommendedMonthlyCost: recommended.MonthlyCost,
+		MonthlySavings:         savings,
+		Reason:                 fmt.Sprintf("average CPU was %.1f%% over %s", cpu, lookback),
+		Operation:              "resize_instance",
+		Parameters: map[string]interface{}{
+			"operation":         "resize_instance",
+			"new_instance_type": recommendedType,
+			"estimated_savings": savings,
+		},
+	}, true
+}
+
+// IsProtected reports whether a resource carries a tag exempting it from downsizing
+func (e *RecommendationEngine) IsProtected(resource models.Resource) bool {
+	for key, value := range resource.Tags {
+		for protectedKey, protectedValues := range e.ProtectedTags {
+			if !strings.EqualFold(key, protectedKey) {
+				continue
+			}
+			for _, protected := range protectedValues {
+				if strings.EqualFold(value, protected) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// sizedResource is the resource with only the size attribute needed to price it
+func sizedResource(resource models.Resource, sizeKey, size string) models.Resource {
+	return models.Resource{
+		Type:       resource.Type,
+		Provider:   resource.Provider,
+		Region:     resource.Region,
+		Attributes: map[string]interface{}{sizeKey: size},
+	}
+}
+
+// smallerSize returns the next size down in the same family, such as m5.large for
+// m5.xlarge or db.t3.small for db.t3.medium, or "" when there is none
+func smallerSize(instanceType string) string {
+	dot := strings.LastIndex(instanceType, ".")
+	if dot < 0 {
+		return ""
+	}
+	family, size := instanceType[:dot], instanceType[dot+1:]
+	for i, candidate := range instanceSizes {
+		if candidate == size && i > 0 {
+			return family + "." + instanceSizes[i-1]
+		}
+	}
+	return ""
+}
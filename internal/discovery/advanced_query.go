@@ -74,10 +74,8 @@ func (aq *AdvancedQuery) AddResource(resource models.Resource) {
 	aq.updateIndex("id", resource.ID, index)
 
 	// Index tags
-	if tags, ok := resource.Tags.(map[string]string); ok {
-		for key, value := range tags {
-			aq.updateIndex(fmt.Sprintf("tag:%s", key), value, index)
-		}
+	for key, value := range resource.Tags {
+		aq.updateIndex(fmt.Sprintf("tag:%s", key), value, index)
 	}
 
 	// Clear cache as data has changed
@@ -190,18 +188,7 @@ func (aq *AdvancedQuery) FindByTags(tags map[string]string) []models.Resource {
 	results := make([]models.Resource, 0)
 
 	for _, resource := range aq.resources {
-		matches := true
-		if resourceTags, ok := resource.Tags.(map[string]string); ok {
-			for key, value := range tags {
-				if resourceValue, exists := resourceTags[key]; !exists || resourceValue != value {
-					matches = false
-					break
-				}
-			}
-		} else {
-			matches = false
-		}
-		if matches {
+		if resource.MatchesTags(tags, nil) {
 			results = append(results, resource)
 		}
 	}
@@ -410,10 +397,8 @@ func (aq *AdvancedQuery) getFieldValue(resource models.Resource, field string) s
 	default:
 		if strings.HasPrefix(field, "tag:") {
 			tagKey := field[4:]
-			if tags, ok := resource.Tags.(map[string]string); ok {
-				if value, exists := tags[tagKey]; exists {
-					return value
-				}
+			if value, exists := resource.Tags[tagKey]; exists {
+				return value
 			}
 		}
 		return ""
@@ -0,0 +1,335 @@
+package backend
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclparse"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// StateLocation is where one Terraform configuration keeps its default workspace state,
+// resolved from its backend block and the partial backend configuration beside it
+type StateLocation struct {
+	Type string `json:"type"`
+	// Directory is the configuration's directory, relative to the scanned root
+	Directory string `json:"directory"`
+	// Sources are the files the settings were read from, relative to the scanned root; a
+	// backend config or tfvars file listed after the backend block overrides its settings,
+	// as terraform init -backend-config does
+	Sources []string               `json:"sources"`
+	Config  map[string]interface{} `json:"config"`
+	// Location is the state's address, such as s3://bucket/key, when it is fully resolved
+	Location string `json:"location,omitempty"`
+	// Missing lists the required settings no source supplied
+	Missing []string `json:"missing,omitempty"`
+}
+
+// Resolved reports whether every setting needed to read the state was found
+func (l StateLocation) Resolved() bool {
+	return len(l.Missing) == 0
+}
+
+// backendSettings lists, per backend type, the settings needed to find the state and the
+// other settings a tfvars file may supply. Only these are taken from tfvars files, which
+// also hold the configuration's ordinary variables.
+var backendSettings = map[string]struct{ required, optional []string }{
+	"s3":      {[]string{"bucket", "key", "region"}, []string{"dynamodb_table", "encrypt", "endpoint", "profile", "role_arn", "workspace_key_prefix"}},
+	"azurerm": {[]string{"storage_account_name", "container_name", "key"}, []string{"resource_group_name", "subscription_id", "tenant_id", "use_azuread_auth"}},
+	"gcs":     {[]string{"bucket"}, []string{"prefix"}},
+	"local":   {nil, []string{"path"}},
+	"remote":  {[]string{"organization"}, []string{"hostname"}},
+}
+
+// secretSettings are never read into a location. States are loaded with the credentials
+// driftmgr itself runs with.
+var secretSettings = map[string]bool{
+	"access_key": true, "secret_key": true, "token": true, "session_token": true,
+	"sas_token": true, "client_secret": true, "client_certificate_password": true,
+	"credentials": true, "access_token": true,
+}
+
+// ignoredDirectories are not searched for Terraform configurations
+var ignoredDirectories = map[string]bool{"node_modules": true, "vendor": true}
+
+// ResolveStateLocations finds each Terraform configuration under root and resolves where its
+// state is kept. A configuration's backend block is combined with each *.tfbackend or
+// backend*.hcl file in its directory, and with each *.tfvars file when the block is partial,
+// giving one location per file that sets backend settings, so a directory initialized with
+// dev.tfbackend and prod.tfbackend yields both states. Configurations without a backend
+// block are reported only when a local terraform.tfstate exists beside them.
+func ResolveStateLocations(root string) ([]StateLocation, error) {
+	info, err := os.Stat(root)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("%s is not a directory", root)
+	}
+
+	var directories []string
+	err = filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return nil // Skip inaccessible paths
+		}
+		if !entry.IsDir() {
+			return nil
+		}
+		name := entry.Name()
+		if path != root && (strings.HasPrefix(name, ".") || ignoredDirectories[name]) {
+			return filepath.SkipDir
+		}
+		directories = append(directories, path)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	parser := hclparse.NewParser()
+	locations := make([]StateLocation, 0)
+	for _, dir := range directories {
+		locations = append(locations, resolveDirectory(parser, root, dir)...)
+	}
+	return locations, nil
+}
+
+// resolveDirectory resolves the state locations of the configuration in one directory
+func resolveDirectory(parser *hclparse.Parser, root, dir string) []StateLocation {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+
+	var backendType, backendFile string
+	var blockConfig map[string]interface{}
+	hasConfiguration := false
+	var configFiles []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		name := entry.Name()
+		switch {
+		case strings.HasSuffix(name, ".tf"):
+			hasConfiguration = true
+			if backendType != "" {
+				continue
+			}
+			if kind, config := parseBackendBlock(parser, filepath.Join(dir, name)); kind != "" {
+				backendType, backendFile, blockConfig = kind, name, config
+			}
+		case strings.HasSuffix(name, ".tfbackend"), strings.HasSuffix(name, ".tfvars"),
+			strings.HasSuffix(name, ".tfvars.json"),
+			strings.HasPrefix(name, "backend") && strings.HasSuffix(name, ".hcl"):
+			configFiles = append(configFiles, name)
+		}
+	}
+	if !hasConfiguration {
+		return nil
+	}
+
+	relative := func(name string) string {
+		rel, err := filepath.Rel(root, filepath.Join(dir, name))
+		if err != nil {
+			return name
+		}
+		return filepath.ToSlash(rel)
+	}
+	directory := relative(".")
+
+	if backendType == "" {
+		if _, err := os.Stat(filepath.Join(dir, "terraform.tfstate")); err != nil {
+			return nil
+		}
+		return []StateLocation{newStateLocation("local", directory, nil, map[string]interface{}{})}
+	}
+
+	// tfvars files usually hold ordinary variables such as region, so they are only read as
+	// backend configuration when the backend block leaves settings unset
+	blockResolved := newStateLocation(backendType, directory, nil, blockConfig).Resolved()
+	sort.Strings(configFiles)
+	var locations []StateLocation
+	for _, name := range configFiles {
+		backendConfig := strings.HasSuffix(name, ".tfbackend") || strings.HasSuffix(name, ".hcl")
+		if !backendConfig && blockResolved {
+			continue
+		}
+		settings := parseBackendSettings(parser, filepath.Join(dir, name), backendType, backendConfig)
+		if len(settings) == 0 {
+			continue
+		}
+		config := make(map[string]interface{}, len(blockConfig)+len(settings))
+		for key, value := range blockConfig {
+			config[key] = value
+		}
+		for key, value := range settings {
+			config[key] = value
+		}
+		locations = append(locations, newStateLocation(backendType, directory, []string{relative(backendFile), relative(name)}, config))
+	}
+	if len(locations) == 0 {
+		locations = append(locations, newStateLocation(backendType, directory, []string{relative(backendFile)}, blockConfig))
+	}
+	return locations
+}
+
+// newStateLocation builds a location from its combined settings
+func newStateLocation(backendType, directory string, sources []string, config map[string]interface{}) StateLocation {
+	location := StateLocation{
+		Type:      backendType,
+		Directory: directory,
+		Sources:   sources,
+		Config:    config,
+	}
+	if location.Sources == nil {
+		location.Sources = []string{}
+	}
+	for _, key := range backendSettings[backendType].required {
+		if setting(config, key) == "" {
+			location.Missing = append(location.Missing, key)
+		}
+	}
+	if location.Resolved() {
+		location.Location = stateAddress(backendType, directory, config)
+	}
+	return location
+}
+
+// stateAddress formats where the default workspace state of a resolved location is kept
+func stateAddress(backendType, directory string, config map[string]interface{}) string {
+	switch backendType {
+	case "s3":
+		return fmt.Sprintf("s3://%s/%s", setting(config, "bucket"), setting(config, "key"))
+	case "azurerm":
+		return fmt.Sprintf("https://%s.blob.core.windows.net/%s/%s",
+			setting(config, "storage_account_name"), setting(config, "container_name"), setting(config, "key"))
+	case "gcs":
+		return fmt.Sprintf("gs://%s/%s", setting(config, "bucket"), gcsStateObject(config))
+	case "local":
+		return path.Join(directory, localStatePath(config))
+	case "remote":
+		hostname := setting(config, "hostname")
+		if hostname == "" {
+			hostname = "app.terraform.io"
+		}
+		return fmt.Sprintf("https://%s/app/%s", hostname, setting(config, "organization"))
+	}
+	return ""
+}
+
+// gcsStateObject is the object the gcs backend keeps the default workspace state in
+func gcsStateObject(config map[string]interface{}) string {
+	prefix := strings.Trim(setting(config, "prefix"), "/")
+	if prefix == "" {
+		return "default.tfstate"
+	}
+	return prefix + "/default.tfstate"
+}
+
+// localStatePath is the state path of a local backend, relative to its configuration
+func localStatePath(config map[string]interface{}) string {
+	if statePath := setting(config, "path"); statePath != "" {
+		return filepath.ToSlash(statePath)
+	}
+	return "terraform.tfstate"
+}
+
+// parseBackendBlock returns the type and literal settings of the backend block in a
+// Terraform file's terraform block, or "" when the file has none
+func parseBackendBlock(parser *hclparse.Parser, filePath string) (string, map[string]interface{}) {
+	file, diags := parser.ParseHCLFile(filePath)
+	if diags.HasErrors() {
+		return "", nil
+	}
+	body, ok := file.Body.(*hclsyntax.Body)
+	if !ok {
+		return "", nil
+	}
+	for _, block := range body.Blocks {
+		if block.Type != "terraform" {
+			continue
+		}
+		for _, inner := range block.Body.Blocks {
+			if inner.Type != "backend" || len(inner.Labels) == 0 {
+				continue
+			}
+			config := make(map[string]interface{})
+			for name, attr := range inner.Body.Attributes {
+				if value, ok := literalValue(attr.Expr); ok && !secretSettings[name] {
+					config[name] = value
+				}
+			}
+			return inner.Labels[0], config
+		}
+	}
+	return "", nil
+}
+
+// parseBackendSettings reads the backend settings a partial configuration file sets.
+// Backend config files may set any setting; tfvars files only those of backendSettings.
+func parseBackendSettings(parser *hclparse.Parser, filePath, backendType string, backendConfig bool) map[string]interface{} {
+	var file *hcl.File
+	var diags hcl.Diagnostics
+	if strings.HasSuffix(filePath, ".json") {
+		file, diags = parser.ParseJSONFile(filePath)
+	} else {
+		file, diags = parser.ParseHCLFile(filePath)
+	}
+	if diags.HasErrors() {
+		return nil
+	}
+	attributes, diags := file.Body.JustAttributes()
+	if diags.HasErrors() {
+		return nil
+	}
+
+	allowed := make(map[string]bool)
+	for _, keys := range [][]string{backendSettings[backendType].required, backendSettings[backendType].optional} {
+		for _, key := range keys {
+			allowed[key] = true
+		}
+	}
+	settings := make(map[string]interface{})
+	for name, attr := range attributes {
+		if secretSettings[name] || (!backendConfig && !allowed[name]) {
+			continue
+		}
+		if value, ok := literalValue(attr.Expr); ok {
+			settings[name] = value
+		}
+	}
+	return settings
+}
+
+// literalValue evaluates an expression that needs no variables to a string, bool or number.
+// Backend settings cannot reference variables, so other expressions are skipped.
+func literalValue(expr hcl.Expression) (interface{}, bool) {
+	value, diags := expr.Value(nil)
+	if diags.HasErrors() || value.IsNull() || !value.IsKnown() {
+		return nil, false
+	}
+	switch value.Type() {
+	case cty.String:
+		return value.AsString(), true
+	case cty.Bool:
+		return value.True(), true
+	case cty.Number:
+		f, _ := value.AsBigFloat().Float64()
+		return f, true
+	}
+	return nil, false
+}
+
+// setting returns a string setting, or "" when it is unset or not a string
+func setting(config map[string]interface{}, key string) string {
+	value, _ := config[key].(string)
+	return value
+}
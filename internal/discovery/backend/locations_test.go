@@ -0,0 +1,107 @@
+package backend
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeFiles(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	}
+}
+
+func TestResolveStateLocations(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		// A complete backend block; the tfvars only hold ordinary variables
+		"network/main.tf": `terraform {
+  backend "s3" {
+    bucket     = "acme-state"
+    key        = "network/terraform.tfstate"
+    region     = "us-east-1"
+    access_key = "AKIAEXAMPLE"
+  }
+}`,
+		"network/terraform.tfvars": `region = "eu-west-1"`,
+		// A partial backend block completed per environment
+		"app/backend.tf":           "terraform {\n  backend \"azurerm\" {}\n}",
+		"app/dev.tfbackend":        "storage_account_name = \"acmedev\"\ncontainer_name = \"tfstate\"\nkey = \"app.tfstate\"",
+		"app/prod.tfvars":          "storage_account_name = \"acmeprod\"\ncontainer_name = \"tfstate\"\nkey = \"app.tfstate\"\nsku = \"large\"",
+		"app/staging.tfvars":       `sku = "small"`,
+		"data/main.tf":             "terraform {\n  backend \"gcs\" {\n    prefix = \"data\"\n  }\n}",
+		"legacy/main.tf":           `resource "null_resource" "x" {}`,
+		"legacy/terraform.tfstate": `{"version": 4, "resources": []}`,
+		"scratch/main.tf":          `resource "null_resource" "y" {}`,
+		".terraform/main.tf":       "terraform {\n  backend \"s3\" {}\n}",
+	})
+
+	locations, err := ResolveStateLocations(root)
+	require.NoError(t, err)
+	byLocation := make(map[string]StateLocation)
+	var unresolved []StateLocation
+	for _, location := range locations {
+		if location.Resolved() {
+			byLocation[location.Location] = location
+		} else {
+			unresolved = append(unresolved, location)
+		}
+	}
+	require.Len(t, locations, 5)
+
+	network := byLocation["s3://acme-state/network/terraform.tfstate"]
+	assert.Equal(t, "network", network.Directory)
+	assert.Equal(t, []string{"network/main.tf"}, network.Sources)
+	assert.Equal(t, "us-east-1", network.Config["region"], "tfvars must not override a complete backend block")
+	assert.NotContains(t, network.Config, "access_key", "secrets must not be read")
+
+	dev := byLocation["https://acmedev.blob.core.windows.net/tfstate/app.tfstate"]
+	assert.Equal(t, []string{"app/backend.tf", "app/dev.tfbackend"}, dev.Sources)
+	prod := byLocation["https://acmeprod.blob.core.windows.net/tfstate/app.tfstate"]
+	assert.Equal(t, []string{"app/backend.tf", "app/prod.tfvars"}, prod.Sources)
+	assert.NotContains(t, prod.Config, "sku", "only backend settings are read from tfvars")
+
+	legacy := byLocation["legacy/terraform.tfstate"]
+	assert.Equal(t, "local", legacy.Type)
+	assert.Empty(t, legacy.Sources)
+
+	require.Len(t, unresolved, 1)
+	assert.Equal(t, "data", unresolved[0].Directory)
+	assert.Equal(t, []string{"bucket"}, unresolved[0].Missing)
+}
+
+func TestLoadLocalState(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"live/main.tf":            "terraform {\n  backend \"local\" {\n    path = \"state/live.tfstate\"\n  }\n}",
+		"live/state/live.tfstate": `{"version": 4}`,
+		"escape/main.tf":          "terraform {\n  backend \"local\" {\n    path = \"../../outside.tfstate\"\n  }\n}",
+	})
+	locations, err := ResolveStateLocations(root)
+	require.NoError(t, err)
+	require.Len(t, locations, 2)
+
+	for _, location := range locations {
+		data, err := LoadState(context.Background(), root, location)
+		switch location.Directory {
+		case "live":
+			require.NoError(t, err)
+			assert.JSONEq(t, `{"version": 4}`, string(data))
+		case "escape":
+			assert.ErrorContains(t, err, "outside the repository")
+		}
+	}
+
+	_, err = LoadState(context.Background(), root, StateLocation{Type: "gcs", Missing: []string{"bucket"}})
+	assert.ErrorContains(t, err, "bucket")
+	_, err = LoadState(context.Background(), root, StateLocation{Type: "consul"})
+	assert.ErrorContains(t, err, "not supported")
+}
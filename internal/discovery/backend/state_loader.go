@@ -0,0 +1,321 @@
+package backend
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"cloud.google.com/go/storage"
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// stateReaders read the default workspace state of a resolved remote location
+var stateReaders = map[string]func(ctx context.Context, config map[string]interface{}) ([]byte, error){
+	"s3":      readS3State,
+	"azurerm": readAzureState,
+	"gcs":     readGCSState,
+}
+
+// stateWriters replace the default workspace state of a resolved remote location
+var stateWriters = map[string]func(ctx context.Context, config map[string]interface{}, data []byte) error{
+	"s3":      writeS3State,
+	"azurerm": writeAzureState,
+	"gcs":     writeGCSState,
+}
+
+// stateVersioners return the ETag of the default workspace state of a resolved remote location
+var stateVersioners = map[string]func(ctx context.Context, config map[string]interface{}) (string, error){
+	"s3":      s3StateETag,
+	"azurerm": azureStateETag,
+	"gcs":     gcsStateETag,
+}
+
+// LoadState reads the default workspace state of a resolved location. root is the directory
+// the location was resolved under; a local state must lie within it.
+func LoadState(ctx context.Context, root string, location StateLocation) ([]byte, error) {
+	if !location.Resolved() {
+		return nil, fmt.Errorf("backend settings %s are not set", strings.Join(location.Missing, ", "))
+	}
+	if location.Type == "local" {
+		statePath, err := localStateFile(root, location)
+		if err != nil {
+			return nil, err
+		}
+		return os.ReadFile(statePath)
+	}
+	read, ok := stateReaders[location.Type]
+	if !ok {
+		return nil, fmt.Errorf("loading state from the %s backend is not supported", location.Type)
+	}
+	return read(ctx, location.Config)
+}
+
+// WriteState replaces the default workspace state of a resolved location with data. It does
+// not take the backend's state lock, so no Terraform run should be using the state.
+func WriteState(ctx context.Context, root string, location StateLocation, data []byte) error {
+	if !location.Resolved() {
+		return fmt.Errorf("backend settings %s are not set", strings.Join(location.Missing, ", "))
+	}
+	if location.Type == "local" {
+		statePath, err := localStateFile(root, location)
+		if err != nil {
+			return err
+		}
+		return writeLocalState(statePath, data)
+	}
+	write, ok := stateWriters[location.Type]
+	if !ok {
+		return fmt.Errorf("writing state to the %s backend is not supported", location.Type)
+	}
+	return write(ctx, location.Config, data)
+}
+
+// StateVersion identifies the current version of a resolved location's default workspace
+// state without reading it: a local state's path, modification time and size, or a remote
+// state's ETag. The version changes whenever the state does, so what was read from one
+// version can be reused until it changes.
+func StateVersion(ctx context.Context, root string, location StateLocation) (string, error) {
+	if !location.Resolved() {
+		return "", fmt.Errorf("backend settings %s are not set", strings.Join(location.Missing, ", "))
+	}
+	if location.Type == "local" {
+		statePath, err := localStateFile(root, location)
+		if err != nil {
+			return "", err
+		}
+		info, err := os.Stat(statePath)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("%s@%d:%d", statePath, info.ModTime().UnixNano(), info.Size()), nil
+	}
+	version, ok := stateVersioners[location.Type]
+	if !ok {
+		return "", fmt.Errorf("loading state from the %s backend is not supported", location.Type)
+	}
+	etag, err := version(ctx, location.Config)
+	if err != nil {
+		return "", err
+	}
+	if etag == "" {
+		return "", fmt.Errorf("the %s backend returned no ETag for %s", location.Type, location.Location)
+	}
+	return location.Location + "@" + etag, nil
+}
+
+// localStateFile returns the path of a local backend's state file, which must lie within root
+func localStateFile(root string, location StateLocation) (string, error) {
+	statePath := filepath.Join(root, filepath.FromSlash(location.Directory), filepath.FromSlash(localStatePath(location.Config)))
+	rel, err := filepath.Rel(root, statePath)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("local state path %s is outside the repository", localStatePath(location.Config))
+	}
+	return statePath, nil
+}
+
+// writeLocalState replaces a local state file through a temporary file, so a failed write
+// never leaves it truncated
+func writeLocalState(statePath string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(statePath), ".terraform.tfstate-*")
+	if err != nil {
+		return fmt.Errorf("failed to write local state: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write local state: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write local state: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), statePath); err != nil {
+		return fmt.Errorf("failed to write local state: %w", err)
+	}
+	return nil
+}
+
+// s3Client creates an S3 client with the default AWS credentials, or the named profile when
+// the backend sets one
+func s3Client(ctx context.Context, config map[string]interface{}) (*s3.Client, error) {
+	options := []func(*awsconfig.LoadOptions) error{awsconfig.WithRegion(setting(config, "region"))}
+	if profile := setting(config, "profile"); profile != "" {
+		options = append(options, awsconfig.WithSharedConfigProfile(profile))
+	}
+	cfg, err := awsconfig.LoadDefaultConfig(ctx, options...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load AWS config: %w", err)
+	}
+
+	return s3.NewFromConfig(cfg, func(o *s3.Options) {
+		if endpoint := setting(config, "endpoint"); endpoint != "" {
+			o.BaseEndpoint = aws.String(endpoint)
+			o.UsePathStyle = true
+		}
+	}), nil
+}
+
+// readS3State reads a state object from S3
+func readS3State(ctx context.Context, config map[string]interface{}) ([]byte, error) {
+	client, err := s3Client(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+	output, err := client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(setting(config, "bucket")),
+		Key:    aws.String(setting(config, "key")),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get state from S3: %w", err)
+	}
+	defer output.Body.Close()
+	return io.ReadAll(output.Body)
+}
+
+// writeS3State replaces a state object in S3
+func writeS3State(ctx context.Context, config map[string]interface{}, data []byte) error {
+	client, err := s3Client(ctx, config)
+	if err != nil {
+		return err
+	}
+	if _, err := client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket: aws.String(setting(config, "bucket")),
+		Key:    aws.String(setting(config, "key")),
+		Body:   bytes.NewReader(data),
+	}); err != nil {
+		return fmt.Errorf("failed to put state to S3: %w", err)
+	}
+	return nil
+}
+
+// s3StateETag returns the ETag of a state object in S3
+func s3StateETag(ctx context.Context, config map[string]interface{}) (string, error) {
+	client, err := s3Client(ctx, config)
+	if err != nil {
+		return "", err
+	}
+	output, err := client.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(setting(config, "bucket")),
+		Key:    aws.String(setting(config, "key")),
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to get state ETag from S3: %w", err)
+	}
+	return aws.ToString(output.ETag), nil
+}
+
+// azureClient creates a client for the backend's Azure storage account with the default
+// Azure credentials
+func azureClient(config map[string]interface{}) (*azblob.Client, error) {
+	cred, err := azidentity.NewDefaultAzureCredential(nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load Azure credentials: %w", err)
+	}
+	serviceURL := fmt.Sprintf("https://%s.blob.core.windows.net/", setting(config, "storage_account_name"))
+	client, err := azblob.NewClient(serviceURL, cred, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Azure blob client: %w", err)
+	}
+	return client, nil
+}
+
+// readAzureState reads a state blob from an Azure storage account
+func readAzureState(ctx context.Context, config map[string]interface{}) ([]byte, error) {
+	client, err := azureClient(config)
+	if err != nil {
+		return nil, err
+	}
+	response, err := client.DownloadStream(ctx, setting(config, "container_name"), setting(config, "key"), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get state from Azure storage: %w", err)
+	}
+	defer response.Body.Close()
+	return io.ReadAll(response.Body)
+}
+
+// writeAzureState replaces a state blob in an Azure storage account
+func writeAzureState(ctx context.Context, config map[string]interface{}, data []byte) error {
+	client, err := azureClient(config)
+	if err != nil {
+		return err
+	}
+	if _, err := client.UploadBuffer(ctx, setting(config, "container_name"), setting(config, "key"), data, nil); err != nil {
+		return fmt.Errorf("failed to put state to Azure storage: %w", err)
+	}
+	return nil
+}
+
+// azureStateETag returns the ETag of a state blob in an Azure storage account
+func azureStateETag(ctx context.Context, config map[string]interface{}) (string, error) {
+	client, err := azureClient(config)
+	if err != nil {
+		return "", err
+	}
+	blob := client.ServiceClient().NewContainerClient(setting(config, "container_name")).NewBlobClient(setting(config, "key"))
+	properties, err := blob.GetProperties(ctx, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to get state ETag from Azure storage: %w", err)
+	}
+	if properties.ETag == nil {
+		return "", nil
+	}
+	return string(*properties.ETag), nil
+}
+
+// readGCSState reads a state object from Cloud Storage with the default Google credentials
+func readGCSState(ctx context.Context, config map[string]interface{}) ([]byte, error) {
+	client, err := storage.NewClient(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create GCS client: %w", err)
+	}
+	defer client.Close()
+
+	reader, err := client.Bucket(setting(config, "bucket")).Object(gcsStateObject(config)).NewReader(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get state from GCS: %w", err)
+	}
+	defer reader.Close()
+	return io.ReadAll(reader)
+}
+
+// writeGCSState replaces a state object in Cloud Storage
+func writeGCSState(ctx context.Context, config map[string]interface{}, data []byte) error {
+	client, err := storage.NewClient(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to create GCS client: %w", err)
+	}
+	defer client.Close()
+
+	writer := client.Bucket(setting(config, "bucket")).Object(gcsStateObject(config)).NewWriter(ctx)
+	writer.ContentType = "application/json"
+	if _, err := writer.Write(data); err != nil {
+		writer.Close()
+		return fmt.Errorf("failed to put state to GCS: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("failed to put state to GCS: %w", err)
+	}
+	return nil
+}
+
+// gcsStateETag returns the ETag of a state object in Cloud Storage
+func gcsStateETag(ctx context.Context, config map[string]interface{}) (string, error) {
+	client, err := storage.NewClient(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to create GCS client: %w", err)
+	}
+	defer client.Close()
+
+	attrs, err := client.Bucket(setting(config, "bucket")).Object(gcsStateObject(config)).Attrs(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to get state ETag from GCS: %w", err)
+	}
+	return attrs.Etag, nil
+}
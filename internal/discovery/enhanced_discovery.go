@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"log"
 	"os/exec"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -470,9 +471,9 @@ func (ed *EnhancedDiscoverer) shouldIncludeResource(resource models.Resource) bo
 		}
 	}
 
-	// Check age threshold
+	// Check age threshold, keeping resources whose age is unknown rather than guessing it
 	if filter.AgeThreshold > 0 {
-		if time.Since(resource.CreatedAt) < filter.AgeThreshold {
+		if age, ok := resource.Age(time.Now()); ok && age < filter.AgeThreshold {
 			return false
 		}
 	}
@@ -668,7 +669,6 @@ func (ed *EnhancedDiscoverer) discoverAWSWAF(ctx context.Context, region string)
 					Name:       parts[1],
 					Region:     region,
 					Provider:   "aws",
-					CreatedAt:  time.Now(),
 					Tags:       map[string]string{},
 					Properties: map[string]interface{}{"description": parts[2], "arn": parts[3]},
 				}
@@ -707,7 +707,6 @@ func (ed *EnhancedDiscoverer) discoverAWSShield(ctx context.Context, region stri
 					Name:       parts[1],
 					Region:     region,
 					Provider:   "aws",
-					CreatedAt:  time.Now(),
 					Tags:       map[string]string{},
 					Properties: map[string]interface{}{"resource_arn": parts[2], "protection_arn": parts[3]},
 				}
@@ -746,7 +745,6 @@ func (ed *EnhancedDiscoverer) discoverAWSConfig(ctx context.Context, region stri
 					Name:       parts[0],
 					Region:     region,
 					Provider:   "aws",
-					CreatedAt:  time.Now(),
 					Tags:       map[string]string{},
 					Properties: map[string]interface{}{"role_arn": parts[1], "all_supported": parts[2]},
 				}
@@ -784,7 +782,6 @@ func (ed *EnhancedDiscoverer) discoverAWSGuardDuty(ctx context.Context, region s
 				Name:       detectorId,
 				Region:     region,
 				Provider:   "aws",
-				CreatedAt:  time.Now(),
 				Tags:       map[string]string{},
 				Properties: map[string]interface{}{},
 			}
@@ -821,7 +818,6 @@ func (ed *EnhancedDiscoverer) discoverAWSCloudFront(ctx context.Context, region
 					Name:       parts[0],
 					Region:     "global", // CloudFront is global
 					Provider:   "aws",
-					CreatedAt:  time.Now(),
 					Tags:       map[string]string{},
 					Properties: map[string]interface{}{"domain_name": parts[1], "status": parts[2], "last_modified": parts[3]},
 				}
@@ -860,7 +856,7 @@ func (ed *EnhancedDiscoverer) discoverAWSAPIGateway(ctx context.Context, region
 					Name:       parts[1],
 					Region:     region,
 					Provider:   "aws",
-					CreatedAt:  time.Now(),
+					CreatedAt:  parseCreationTime(parts[3]),
 					Tags:       map[string]string{},
 					Properties: map[string]interface{}{"description": parts[2], "created_date": parts[3], "version": parts[4]},
 				}
@@ -899,7 +895,6 @@ func (ed *EnhancedDiscoverer) discoverAWSGlue(ctx context.Context, region string
 					Name:       parts[0],
 					Region:     region,
 					Provider:   "aws",
-					CreatedAt:  time.Now(),
 					Tags:       map[string]string{},
 					Properties: map[string]interface{}{"description": parts[1], "catalog_id": parts[2]},
 				}
@@ -938,7 +933,7 @@ func (ed *EnhancedDiscoverer) discoverAWSRedshift(ctx context.Context, region st
 					Name:       parts[0],
 					Region:     region,
 					Provider:   "aws",
-					CreatedAt:  time.Now(),
+					CreatedAt:  parseCreationTime(parts[3]),
 					Tags:       map[string]string{"VPC": parts[4]},
 					Properties: map[string]interface{}{"node_type": parts[1], "status": parts[2], "create_time": parts[3]},
 				}
@@ -977,7 +972,6 @@ func (ed *EnhancedDiscoverer) discoverAWSElasticsearch(ctx context.Context, regi
 					Name:       parts[0],
 					Region:     region,
 					Provider:   "aws",
-					CreatedAt:  time.Now(),
 					Tags:       map[string]string{},
 					Properties: map[string]interface{}{"engine_type": parts[1]},
 				}
@@ -1016,7 +1010,7 @@ func (ed *EnhancedDiscoverer) discoverAWSCloudWatch(ctx context.Context, region
 					Name:       parts[0],
 					Region:     region,
 					Provider:   "aws",
-					CreatedAt:  time.Now(),
+					CreatedAt:  parseCreationTime(parts[1]),
 					Tags:       map[string]string{},
 					Properties: map[string]interface{}{"creation_time": parts[1], "stored_bytes": parts[2]},
 				}
@@ -1055,7 +1049,6 @@ func (ed *EnhancedDiscoverer) discoverAWSSystemsManager(ctx context.Context, reg
 					Name:       parts[0],
 					Region:     region,
 					Provider:   "aws",
-					CreatedAt:  time.Now(),
 					Tags:       map[string]string{},
 					Properties: map[string]interface{}{"type": parts[1], "description": parts[2]},
 				}
@@ -1094,7 +1087,6 @@ func (ed *EnhancedDiscoverer) discoverAWSStepFunctions(ctx context.Context, regi
 					Name:       parts[0],
 					Region:     region,
 					Provider:   "aws",
-					CreatedAt:  time.Now(),
 					Tags:       map[string]string{},
 					Properties: map[string]interface{}{"arn": parts[1], "type": parts[2]},
 				}
@@ -1186,7 +1178,6 @@ func (ed *EnhancedDiscoverer) discoverAzureResourcesBuiltin(ctx context.Context,
 						Name:       name,
 						Region:     location,
 						Provider:   "azure",
-						CreatedAt:  time.Now(),
 						Tags:       make(map[string]string),
 						Properties: res,
 					}
@@ -1257,7 +1248,6 @@ func (ed *EnhancedDiscoverer) discoverGCPResourcesBuiltin(ctx context.Context, r
 					Name:       name,
 					Region:     location,
 					Provider:   "gcp",
-					CreatedAt:  time.Now(),
 					Tags:       make(map[string]string),
 					Properties: res,
 				}
@@ -1298,7 +1288,6 @@ func (ed *EnhancedDiscoverer) discoverAWSEC2(ctx context.Context, region string)
 					Name:       parts[4],
 					Region:     region,
 					Provider:   "aws",
-					CreatedAt:  time.Now(), // Would parse actual launch time
 					Tags:       map[string]string{"VPC": parts[5], "Subnet": parts[6]},
 					Properties: map[string]interface{}{"instance_type": parts[1], "state": parts[2]},
 				}
@@ -1337,7 +1326,6 @@ func (ed *EnhancedDiscoverer) discoverAWSRDS(ctx context.Context, region string)
 					Name:       parts[0],
 					Region:     region,
 					Provider:   "aws",
-					CreatedAt:  time.Now(), // Would parse actual creation time
 					Tags:       map[string]string{"VPC": parts[5]},
 					Properties: map[string]interface{}{"instance_class": parts[1], "engine": parts[2], "status": parts[3]},
 				}
@@ -1376,7 +1364,6 @@ func (ed *EnhancedDiscoverer) discoverAWSLambda(ctx context.Context, region stri
 					Name:       parts[0],
 					Region:     region,
 					Provider:   "aws",
-					CreatedAt:  time.Now(), // Would parse actual creation time
 					Tags:       map[string]string{},
 					Properties: map[string]interface{}{"runtime": parts[1], "code_size": parts[2], "last_modified": parts[3]},
 				}
@@ -1414,7 +1401,7 @@ func (ed *EnhancedDiscoverer) discoverAWSS3(ctx context.Context) []models.Resour
 					Name:       parts[0],
 					Region:     "global", // S3 is global
 					Provider:   "aws",
-					CreatedAt:  time.Now(), // Would parse actual creation time
+					CreatedAt:  parseCreationTime(parts[1]),
 					Tags:       map[string]string{},
 					Properties: map[string]interface{}{"creation_date": parts[1]},
 				}
@@ -1452,7 +1439,7 @@ func (ed *EnhancedDiscoverer) discoverAWSIAM(ctx context.Context) []models.Resou
 					Name:       parts[0],
 					Region:     "global", // IAM is global
 					Provider:   "aws",
-					CreatedAt:  time.Now(), // Would parse actual creation time
+					CreatedAt:  parseCreationTime(parts[1]),
 					Tags:       map[string]string{},
 					Properties: map[string]interface{}{"create_date": parts[1], "password_last_used": parts[2]},
 				}
@@ -1490,7 +1477,6 @@ func (ed *EnhancedDiscoverer) discoverAWSRoute53(ctx context.Context) []models.R
 					Name:       parts[1],
 					Region:     "global", // Route53 is global
 					Provider:   "aws",
-					CreatedAt:  time.Now(), // Would parse actual creation time
 					Tags:       map[string]string{},
 					Properties: map[string]interface{}{"caller_reference": parts[2]},
 				}
@@ -1530,7 +1516,7 @@ func (ed *EnhancedDiscoverer) discoverAWSCloudFormation(ctx context.Context, reg
 					Name:       parts[0],
 					Region:     region,
 					Provider:   "aws",
-					CreatedAt:  time.Now(), // Would parse actual creation time
+					CreatedAt:  parseCreationTime(parts[2]),
 					Tags:       map[string]string{},
 					Properties: map[string]interface{}{"status": parts[1], "creation_time": parts[2], "last_updated": parts[3]},
 				}
@@ -1569,7 +1555,6 @@ func (ed *EnhancedDiscoverer) discoverAWSElastiCache(ctx context.Context, region
 					Name:       parts[0],
 					Region:     region,
 					Provider:   "aws",
-					CreatedAt:  time.Now(), // Would parse actual creation time
 					Tags:       map[string]string{},
 					Properties: map[string]interface{}{"engine": parts[1], "node_type": parts[2], "status": parts[3]},
 				}
@@ -1607,7 +1592,6 @@ func (ed *EnhancedDiscoverer) discoverAWSECS(ctx context.Context, region string)
 				Name:       clusterName,
 				Region:     region,
 				Provider:   "aws",
-				CreatedAt:  time.Now(), // Would parse actual creation time
 				Tags:       map[string]string{},
 				Properties: map[string]interface{}{"arn": line},
 			}
@@ -1644,7 +1628,6 @@ func (ed *EnhancedDiscoverer) discoverAWSEKS(ctx context.Context, region string)
 				Name:       clusterName,
 				Region:     region,
 				Provider:   "aws",
-				CreatedAt:  time.Now(), // Would parse actual creation time
 				Tags:       map[string]string{},
 				Properties: map[string]interface{}{},
 			}
@@ -1681,7 +1664,6 @@ func (ed *EnhancedDiscoverer) discoverAWSSQS(ctx context.Context, region string)
 				Name:       queueName,
 				Region:     region,
 				Provider:   "aws",
-				CreatedAt:  time.Now(), // Would parse actual creation time
 				Tags:       map[string]string{},
 				Properties: map[string]interface{}{"url": line},
 			}
@@ -1718,7 +1700,6 @@ func (ed *EnhancedDiscoverer) discoverAWSSNS(ctx context.Context, region string)
 				Name:       topicName,
 				Region:     region,
 				Provider:   "aws",
-				CreatedAt:  time.Now(), // Would parse actual creation time
 				Tags:       map[string]string{},
 				Properties: map[string]interface{}{"arn": line},
 			}
@@ -1755,7 +1736,6 @@ func (ed *EnhancedDiscoverer) discoverAWSDynamoDB(ctx context.Context, region st
 				Name:       tableName,
 				Region:     region,
 				Provider:   "aws",
-				CreatedAt:  time.Now(), // Would parse actual creation time
 				Tags:       map[string]string{},
 				Properties: map[string]interface{}{},
 			}
@@ -1793,7 +1773,6 @@ func (ed *EnhancedDiscoverer) discoverAWSAutoScaling(ctx context.Context, region
 					Name:       parts[0],
 					Region:     region,
 					Provider:   "aws",
-					CreatedAt:  time.Now(), // Would parse actual creation time
 					Tags:       map[string]string{},
 					Properties: map[string]interface{}{"min_size": parts[1], "max_size": parts[2], "desired_capacity": parts[3]},
 				}
@@ -1833,7 +1812,6 @@ func (ed *EnhancedDiscoverer) discoverAzureVMs(ctx context.Context, region strin
 					Name:       vmName,
 					Region:     region,
 					Provider:   "azure",
-					CreatedAt:  time.Now(),
 					Tags:       map[string]string{"ResourceGroup": parts[4]},
 					Properties: map[string]interface{}{"vm_size": parts[1], "power_state": parts[2], "provisioning_state": parts[3]},
 				}
@@ -1872,7 +1850,6 @@ func (ed *EnhancedDiscoverer) discoverAzureStorageAccounts(ctx context.Context,
 					Name:       accountName,
 					Region:     region,
 					Provider:   "azure",
-					CreatedAt:  time.Now(),
 					Tags:       map[string]string{"ResourceGroup": parts[3]},
 					Properties: map[string]interface{}{"sku": parts[1], "status": parts[2]},
 				}
@@ -1911,7 +1888,6 @@ func (ed *EnhancedDiscoverer) discoverAzureSQLDatabases(ctx context.Context, reg
 					Name:       dbName,
 					Region:     region,
 					Provider:   "azure",
-					CreatedAt:  time.Now(),
 					Tags:       map[string]string{"ResourceGroup": parts[3]},
 					Properties: map[string]interface{}{"edition": parts[1], "status": parts[2]},
 				}
@@ -1950,7 +1926,6 @@ func (ed *EnhancedDiscoverer) discoverAzureWebApps(ctx context.Context, region s
 					Name:       appName,
 					Region:     region,
 					Provider:   "azure",
-					CreatedAt:  time.Now(),
 					Tags:       map[string]string{"ResourceGroup": parts[2]},
 					Properties: map[string]interface{}{"state": parts[1]},
 				}
@@ -1989,7 +1964,6 @@ func (ed *EnhancedDiscoverer) discoverAzureVirtualNetworks(ctx context.Context,
 					Name:       vnetName,
 					Region:     region,
 					Provider:   "azure",
-					CreatedAt:  time.Now(),
 					Tags:       map[string]string{"ResourceGroup": parts[2]},
 					Properties: map[string]interface{}{"address_prefix": parts[1]},
 				}
@@ -2028,7 +2002,6 @@ func (ed *EnhancedDiscoverer) discoverAzureLoadBalancers(ctx context.Context, re
 					Name:       lbName,
 					Region:     region,
 					Provider:   "azure",
-					CreatedAt:  time.Now(),
 					Tags:       map[string]string{"ResourceGroup": parts[2]},
 					Properties: map[string]interface{}{"sku": parts[1]},
 				}
@@ -2067,7 +2040,6 @@ func (ed *EnhancedDiscoverer) discoverAzureKeyVaults(ctx context.Context, region
 					Name:       vaultName,
 					Region:     region,
 					Provider:   "azure",
-					CreatedAt:  time.Now(),
 					Tags:       map[string]string{"ResourceGroup": parts[2]},
 					Properties: map[string]interface{}{"enabled_for_deployment": parts[1]},
 				}
@@ -2106,7 +2078,6 @@ func (ed *EnhancedDiscoverer) discoverAzureResourceGroups(ctx context.Context, r
 					Name:       rgName,
 					Region:     region,
 					Provider:   "azure",
-					CreatedAt:  time.Now(),
 					Tags:       map[string]string{},
 					Properties: map[string]interface{}{"provisioning_state": parts[1]},
 				}
@@ -2146,7 +2117,6 @@ func (ed *EnhancedDiscoverer) discoverAzureFunctions(ctx context.Context, region
 					Name:       appName,
 					Region:     region,
 					Provider:   "azure",
-					CreatedAt:  time.Now(),
 					Tags:       map[string]string{"ResourceGroup": parts[3]},
 					Properties: map[string]interface{}{"kind": parts[1], "state": parts[2]},
 				}
@@ -2185,7 +2155,6 @@ func (ed *EnhancedDiscoverer) discoverAzureLogicApps(ctx context.Context, region
 					Name:       appName,
 					Region:     region,
 					Provider:   "azure",
-					CreatedAt:  time.Now(),
 					Tags:       map[string]string{"ResourceGroup": parts[2]},
 					Properties: map[string]interface{}{"state": parts[1]},
 				}
@@ -2224,7 +2193,6 @@ func (ed *EnhancedDiscoverer) discoverAzureEventHubs(ctx context.Context, region
 					Name:       namespaceName,
 					Region:     region,
 					Provider:   "azure",
-					CreatedAt:  time.Now(),
 					Tags:       map[string]string{"ResourceGroup": parts[3]},
 					Properties: map[string]interface{}{"sku": parts[1], "status": parts[2]},
 				}
@@ -2263,7 +2231,6 @@ func (ed *EnhancedDiscoverer) discoverAzureServiceBus(ctx context.Context, regio
 					Name:       namespaceName,
 					Region:     region,
 					Provider:   "azure",
-					CreatedAt:  time.Now(),
 					Tags:       map[string]string{"ResourceGroup": parts[3]},
 					Properties: map[string]interface{}{"sku": parts[1], "status": parts[2]},
 				}
@@ -2302,7 +2269,6 @@ func (ed *EnhancedDiscoverer) discoverAzureCosmosDB(ctx context.Context, region
 					Name:       accountName,
 					Region:     region,
 					Provider:   "azure",
-					CreatedAt:  time.Now(),
 					Tags:       map[string]string{"ResourceGroup": parts[3]},
 					Properties: map[string]interface{}{"kind": parts[1], "provisioning_state": parts[2]},
 				}
@@ -2341,7 +2307,6 @@ func (ed *EnhancedDiscoverer) discoverAzureDataFactory(ctx context.Context, regi
 					Name:       factoryName,
 					Region:     region,
 					Provider:   "azure",
-					CreatedAt:  time.Now(),
 					Tags:       map[string]string{"ResourceGroup": parts[2]},
 					Properties: map[string]interface{}{"provisioning_state": parts[1]},
 				}
@@ -2380,7 +2345,6 @@ func (ed *EnhancedDiscoverer) discoverAzureSynapseAnalytics(ctx context.Context,
 					Name:       workspaceName,
 					Region:     region,
 					Provider:   "azure",
-					CreatedAt:  time.Now(),
 					Tags:       map[string]string{"ResourceGroup": parts[2]},
 					Properties: map[string]interface{}{"provisioning_state": parts[1]},
 				}
@@ -2419,7 +2383,6 @@ func (ed *EnhancedDiscoverer) discoverAzureApplicationInsights(ctx context.Conte
 					Name:       componentName,
 					Region:     region,
 					Provider:   "azure",
-					CreatedAt:  time.Now(),
 					Tags:       map[string]string{"ResourceGroup": parts[3]},
 					Properties: map[string]interface{}{"kind": parts[1], "provisioning_state": parts[2]},
 				}
@@ -2458,7 +2421,6 @@ func (ed *EnhancedDiscoverer) discoverAzurePolicy(ctx context.Context, region st
 					Name:       assignmentName,
 					Region:     region,
 					Provider:   "azure",
-					CreatedAt:  time.Now(),
 					Tags:       map[string]string{},
 					Properties: map[string]interface{}{"display_name": parts[1], "enforcement_mode": parts[2]},
 				}
@@ -2497,7 +2459,6 @@ func (ed *EnhancedDiscoverer) discoverAzureBastion(ctx context.Context, region s
 					Name:       bastionName,
 					Region:     region,
 					Provider:   "azure",
-					CreatedAt:  time.Now(),
 					Tags:       map[string]string{"ResourceGroup": parts[2]},
 					Properties: map[string]interface{}{"provisioning_state": parts[1]},
 				}
@@ -2536,7 +2497,6 @@ func (ed *EnhancedDiscoverer) discoverGCPCloudFunctions(ctx context.Context, reg
 					Name:       parts[0],
 					Region:     region,
 					Provider:   "gcp",
-					CreatedAt:  time.Now(),
 					Tags:       map[string]string{},
 					Properties: map[string]interface{}{"runtime": parts[1], "status": parts[2], "entry_point": parts[3]},
 				}
@@ -2574,7 +2534,6 @@ func (ed *EnhancedDiscoverer) discoverGCPCloudRun(ctx context.Context, region st
 					Name:       parts[0],
 					Region:     region,
 					Provider:   "gcp",
-					CreatedAt:  time.Now(),
 					Tags:       map[string]string{},
 					Properties: map[string]interface{}{"url": parts[1], "status": parts[2]},
 				}
@@ -2612,7 +2571,6 @@ func (ed *EnhancedDiscoverer) discoverGCPCloudBuild(ctx context.Context, region
 					Name:       parts[0],
 					Region:     region,
 					Provider:   "gcp",
-					CreatedAt:  time.Now(),
 					Tags:       map[string]string{},
 					Properties: map[string]interface{}{"create_time": parts[1], "status": parts[2]},
 				}
@@ -2649,7 +2607,6 @@ func (ed *EnhancedDiscoverer) discoverGCPCloudPubSub(ctx context.Context, region
 					Name:       parts[0],
 					Region:     region,
 					Provider:   "gcp",
-					CreatedAt:  time.Now(),
 					Tags:       map[string]string{},
 					Properties: map[string]interface{}{"message_retention_duration": parts[1]},
 				}
@@ -2686,7 +2643,7 @@ func (ed *EnhancedDiscoverer) discoverGCPBigQuery(ctx context.Context, region st
 					Name:       parts[0],
 					Region:     region,
 					Provider:   "gcp",
-					CreatedAt:  time.Now(),
+					CreatedAt:  parseCreationTime(parts[1]),
 					Tags:       map[string]string{},
 					Properties: map[string]interface{}{"creation_time": parts[1], "last_modified_time": parts[2]},
 				}
@@ -2723,7 +2680,6 @@ func (ed *EnhancedDiscoverer) discoverGCPCloudSpanner(ctx context.Context, regio
 					Name:       parts[0],
 					Region:     region,
 					Provider:   "gcp",
-					CreatedAt:  time.Now(),
 					Tags:       map[string]string{},
 					Properties: map[string]interface{}{"config": parts[1], "node_count": parts[2], "state": parts[3]},
 				}
@@ -2760,7 +2716,6 @@ func (ed *EnhancedDiscoverer) discoverGCPCloudFirestore(ctx context.Context, reg
 					Name:       parts[0],
 					Region:     region,
 					Provider:   "gcp",
-					CreatedAt:  time.Now(),
 					Tags:       map[string]string{},
 					Properties: map[string]interface{}{"type": parts[1], "location_id": parts[2]},
 				}
@@ -2797,7 +2752,6 @@ func (ed *EnhancedDiscoverer) discoverGCPCloudArmor(ctx context.Context, region
 					Name:       parts[0],
 					Region:     region,
 					Provider:   "gcp",
-					CreatedAt:  time.Now(),
 					Tags:       map[string]string{},
 					Properties: map[string]interface{}{"type": parts[1], "ddos_protection": parts[2]},
 				}
@@ -2834,7 +2788,6 @@ func (ed *EnhancedDiscoverer) discoverGCPCloudMonitoring(ctx context.Context, re
 					Name:       parts[0],
 					Region:     region,
 					Provider:   "gcp",
-					CreatedAt:  time.Now(),
 					Tags:       map[string]string{},
 					Properties: map[string]interface{}{"display_name": parts[1], "create_time": parts[2]},
 				}
@@ -2871,7 +2824,6 @@ func (ed *EnhancedDiscoverer) discoverGCPCloudLogging(ctx context.Context, regio
 					Name:       parts[0],
 					Region:     region,
 					Provider:   "gcp",
-					CreatedAt:  time.Now(),
 					Tags:       map[string]string{},
 					Properties: map[string]interface{}{"destination": parts[1], "filter": parts[2]},
 				}
@@ -2910,7 +2862,6 @@ func (ed *EnhancedDiscoverer) discoverGCPComputeInstances(ctx context.Context, r
 					Name:       parts[0],
 					Region:     region,
 					Provider:   "gcp",
-					CreatedAt:  time.Now(),
 					Tags:       map[string]string{},
 					Properties: map[string]interface{}{"machine_type": parts[1], "status": parts[2], "zone": parts[3]},
 				}
@@ -2946,7 +2897,6 @@ func (ed *EnhancedDiscoverer) discoverGCPStorageBuckets(ctx context.Context, reg
 				Name:       bucketName,
 				Region:     region,
 				Provider:   "gcp",
-				CreatedAt:  time.Now(),
 				Tags:       map[string]string{},
 				Properties: map[string]interface{}{"location": region},
 			}
@@ -2983,7 +2933,6 @@ func (ed *EnhancedDiscoverer) discoverGCPGKEClusters(ctx context.Context, region
 					Name:       parts[0],
 					Region:     region,
 					Provider:   "gcp",
-					CreatedAt:  time.Now(),
 					Tags:       map[string]string{},
 					Properties: map[string]interface{}{"location": parts[1], "status": parts[2], "master_version": parts[3]},
 				}
@@ -3020,7 +2969,6 @@ func (ed *EnhancedDiscoverer) discoverGCPCloudSQL(ctx context.Context, region st
 					Name:       parts[0],
 					Region:     region,
 					Provider:   "gcp",
-					CreatedAt:  time.Now(),
 					Tags:       map[string]string{},
 					Properties: map[string]interface{}{"region": parts[1], "database_version": parts[2], "state": parts[3]},
 				}
@@ -3057,7 +3005,6 @@ func (ed *EnhancedDiscoverer) discoverGCPVPCNetworks(ctx context.Context, region
 					Name:       parts[0],
 					Region:     region,
 					Provider:   "gcp",
-					CreatedAt:  time.Now(),
 					Tags:       map[string]string{},
 					Properties: map[string]interface{}{"network": parts[1], "subnetworks": parts[2]},
 				}
@@ -3097,7 +3044,6 @@ func (ed *EnhancedDiscoverer) discoverAWSVPC(ctx context.Context, region string)
 					Name:       parts[3],
 					Region:     region,
 					Provider:   "aws",
-					CreatedAt:  time.Now(),
 					Tags:       map[string]string{},
 					Properties: map[string]interface{}{"cidr_block": parts[1], "state": parts[2]},
 				}
@@ -3136,7 +3082,6 @@ func (ed *EnhancedDiscoverer) discoverAWSSubnets(ctx context.Context, region str
 					Name:       parts[4],
 					Region:     region,
 					Provider:   "aws",
-					CreatedAt:  time.Now(),
 					Tags:       map[string]string{},
 					Properties: map[string]interface{}{"cidr_block": parts[1], "availability_zone": parts[2], "state": parts[3]},
 				}
@@ -3175,7 +3120,6 @@ func (ed *EnhancedDiscoverer) discoverAWSLoadBalancers(ctx context.Context, regi
 					Name:       parts[1],
 					Region:     region,
 					Provider:   "aws",
-					CreatedAt:  time.Now(),
 					Tags:       map[string]string{},
 					Properties: map[string]interface{}{"type": parts[2], "state": parts[3], "scheme": parts[4]},
 				}
@@ -3214,7 +3158,6 @@ func (ed *EnhancedDiscoverer) discoverAWSSecretsManager(ctx context.Context, reg
 					Name:       parts[1],
 					Region:     region,
 					Provider:   "aws",
-					CreatedAt:  time.Now(),
 					Tags:       map[string]string{},
 					Properties: map[string]interface{}{"description": parts[2], "last_changed_date": parts[3]},
 				}
@@ -3253,7 +3196,6 @@ func (ed *EnhancedDiscoverer) discoverAWSKMS(ctx context.Context, region string)
 					Name:       parts[0],
 					Region:     region,
 					Provider:   "aws",
-					CreatedAt:  time.Now(),
 					Tags:       map[string]string{},
 					Properties: map[string]interface{}{"key_arn": parts[1]},
 				}
@@ -3292,7 +3234,6 @@ func (ed *EnhancedDiscoverer) discoverAWSCloudTrail(ctx context.Context, region
 					Name:       parts[0],
 					Region:     region,
 					Provider:   "aws",
-					CreatedAt:  time.Now(),
 					Tags:       map[string]string{},
 					Properties: map[string]interface{}{"trail_arn": parts[1], "home_region": parts[2]},
 				}
@@ -3332,7 +3273,7 @@ func (ed *EnhancedDiscoverer) discoverAWSCloudFormationStacks(ctx context.Contex
 					Name:       parts[0],
 					Region:     region,
 					Provider:   "aws",
-					CreatedAt:  time.Now(),
+					CreatedAt:  parseCreationTime(parts[2]),
 					Tags:       map[string]string{},
 					Properties: map[string]interface{}{"stack_status": parts[1], "creation_time": parts[2]},
 				}
@@ -3371,7 +3312,6 @@ func (ed *EnhancedDiscoverer) discoverAWSSecurityGroups(ctx context.Context, reg
 					Name:       parts[1],
 					Region:     region,
 					Provider:   "aws",
-					CreatedAt:  time.Now(),
 					Tags:       map[string]string{},
 					Properties: map[string]interface{}{"description": parts[2], "vpc_id": parts[3]},
 				}
@@ -3410,7 +3350,6 @@ func (ed *EnhancedDiscoverer) discoverAWSInternetGateway(ctx context.Context, re
 					Name:       parts[0],
 					Region:     region,
 					Provider:   "aws",
-					CreatedAt:  time.Now(),
 					Tags:       map[string]string{},
 					Properties: map[string]interface{}{"state": parts[1], "vpc_id": parts[2]},
 				}
@@ -3449,7 +3388,6 @@ func (ed *EnhancedDiscoverer) discoverAWSNATGateway(ctx context.Context, region
 					Name:       parts[0],
 					Region:     region,
 					Provider:   "aws",
-					CreatedAt:  time.Now(),
 					Tags:       map[string]string{},
 					Properties: map[string]interface{}{"state": parts[1], "subnet_id": parts[2], "vpc_id": parts[3]},
 				}
@@ -3488,7 +3426,6 @@ func (ed *EnhancedDiscoverer) discoverAWSCodeBuild(ctx context.Context, region s
 					Name:       projectName,
 					Region:     region,
 					Provider:   "aws",
-					CreatedAt:  time.Now(),
 					Tags:       map[string]string{},
 					Properties: map[string]interface{}{"service": "codebuild"},
 				}
@@ -3526,7 +3463,6 @@ func (ed *EnhancedDiscoverer) discoverAWSCodePipeline(ctx context.Context, regio
 					Name:       pipelineName,
 					Region:     region,
 					Provider:   "aws",
-					CreatedAt:  time.Now(),
 					Tags:       map[string]string{},
 					Properties: map[string]interface{}{"service": "codepipeline"},
 				}
@@ -3564,7 +3500,6 @@ func (ed *EnhancedDiscoverer) discoverAWSCodeDeploy(ctx context.Context, region
 					Name:       appName,
 					Region:     region,
 					Provider:   "aws",
-					CreatedAt:  time.Now(),
 					Tags:       map[string]string{},
 					Properties: map[string]interface{}{"service": "codedeploy"},
 				}
@@ -3604,7 +3539,6 @@ func (ed *EnhancedDiscoverer) discoverAWSBatch(ctx context.Context, region strin
 					Name:       queueName,
 					Region:     region,
 					Provider:   "aws",
-					CreatedAt:  time.Now(),
 					Tags:       map[string]string{},
 					Properties: map[string]interface{}{"service": "batch"},
 				}
@@ -3643,7 +3577,6 @@ func (ed *EnhancedDiscoverer) discoverAWSFargate(ctx context.Context, region str
 					Name:       taskDefName,
 					Region:     region,
 					Provider:   "aws",
-					CreatedAt:  time.Now(),
 					Tags:       map[string]string{},
 					Properties: map[string]interface{}{"service": "fargate"},
 				}
@@ -3683,7 +3616,6 @@ func (ed *EnhancedDiscoverer) discoverAWSEMR(ctx context.Context, region string)
 					Name:       clusterName,
 					Region:     region,
 					Provider:   "aws",
-					CreatedAt:  time.Now(),
 					Tags:       map[string]string{},
 					Properties: map[string]interface{}{"service": "emr"},
 				}
@@ -3723,7 +3655,6 @@ func (ed *EnhancedDiscoverer) discoverAWSNeptune(ctx context.Context, region str
 					Name:       clusterName,
 					Region:     region,
 					Provider:   "aws",
-					CreatedAt:  time.Now(),
 					Tags:       map[string]string{},
 					Properties: map[string]interface{}{"service": "neptune"},
 				}
@@ -3762,7 +3693,6 @@ func (ed *EnhancedDiscoverer) discoverAWSDocumentDB(ctx context.Context, region
 					Name:       clusterName,
 					Region:     region,
 					Provider:   "aws",
-					CreatedAt:  time.Now(),
 					Tags:       map[string]string{},
 					Properties: map[string]interface{}{"service": "documentdb"},
 				}
@@ -3801,7 +3731,6 @@ func (ed *EnhancedDiscoverer) discoverAWSMSK(ctx context.Context, region string)
 					Name:       clusterName,
 					Region:     region,
 					Provider:   "aws",
-					CreatedAt:  time.Now(),
 					Tags:       map[string]string{},
 					Properties: map[string]interface{}{"service": "msk"},
 				}
@@ -3840,7 +3769,6 @@ func (ed *EnhancedDiscoverer) discoverAWSMQ(ctx context.Context, region string)
 					Name:       brokerId,
 					Region:     region,
 					Provider:   "aws",
-					CreatedAt:  time.Now(),
 					Tags:       map[string]string{},
 					Properties: map[string]interface{}{"service": "mq"},
 				}
@@ -3880,7 +3808,6 @@ func (ed *EnhancedDiscoverer) discoverAWSTransfer(ctx context.Context, region st
 					Name:       serverId,
 					Region:     region,
 					Provider:   "aws",
-					CreatedAt:  time.Now(),
 					Tags:       map[string]string{},
 					Properties: map[string]interface{}{"service": "transfer"},
 				}
@@ -3920,7 +3847,6 @@ func (ed *EnhancedDiscoverer) discoverAWSDirectConnect(ctx context.Context, regi
 					Name:       connectionName,
 					Region:     region,
 					Provider:   "aws",
-					CreatedAt:  time.Now(),
 					Tags:       map[string]string{},
 					Properties: map[string]interface{}{"service": "directconnect"},
 				}
@@ -3960,7 +3886,6 @@ func (ed *EnhancedDiscoverer) discoverAWSVPN(ctx context.Context, region string)
 					Name:       vpnId,
 					Region:     region,
 					Provider:   "aws",
-					CreatedAt:  time.Now(),
 					Tags:       map[string]string{},
 					Properties: map[string]interface{}{"state": vpnState, "service": "vpn"},
 				}
@@ -4000,7 +3925,6 @@ func (ed *EnhancedDiscoverer) discoverAWSTransitGateway(ctx context.Context, reg
 					Name:       tgwId,
 					Region:     region,
 					Provider:   "aws",
-					CreatedAt:  time.Now(),
 					Tags:       map[string]string{},
 					Properties: map[string]interface{}{"state": tgwState, "service": "transitgateway"},
 				}
@@ -4040,7 +3964,6 @@ func (ed *EnhancedDiscoverer) discoverAWSAppMesh(ctx context.Context, region str
 					Name:       meshName,
 					Region:     region,
 					Provider:   "aws",
-					CreatedAt:  time.Now(),
 					Tags:       map[string]string{},
 					Properties: map[string]interface{}{"service": "appmesh"},
 				}
@@ -4079,7 +4002,6 @@ func (ed *EnhancedDiscoverer) discoverAWSXRay(ctx context.Context, region string
 					Name:       groupName,
 					Region:     region,
 					Provider:   "aws",
-					CreatedAt:  time.Now(),
 					Tags:       map[string]string{},
 					Properties: map[string]interface{}{"service": "xray"},
 				}
@@ -4119,7 +4041,6 @@ func (ed *EnhancedDiscoverer) discoverAWSCloud9(ctx context.Context, region stri
 					Name:       envId,
 					Region:     region,
 					Provider:   "aws",
-					CreatedAt:  time.Now(),
 					Tags:       map[string]string{},
 					Properties: map[string]interface{}{"service": "cloud9"},
 				}
@@ -4158,7 +4079,6 @@ func (ed *EnhancedDiscoverer) discoverAWSCodeStar(ctx context.Context, region st
 					Name:       projectId,
 					Region:     region,
 					Provider:   "aws",
-					CreatedAt:  time.Now(),
 					Tags:       map[string]string{},
 					Properties: map[string]interface{}{"service": "codestar"},
 				}
@@ -4197,7 +4117,6 @@ func (ed *EnhancedDiscoverer) discoverAWSAmplify(ctx context.Context, region str
 					Name:       appId,
 					Region:     region,
 					Provider:   "aws",
-					CreatedAt:  time.Now(),
 					Tags:       map[string]string{},
 					Properties: map[string]interface{}{"service": "amplify"},
 				}
@@ -4237,7 +4156,6 @@ func (ed *EnhancedDiscoverer) discoverAzureContainerInstances(ctx context.Contex
 					Name:       containerName,
 					Region:     region,
 					Provider:   "azure",
-					CreatedAt:  time.Now(),
 					Tags:       map[string]string{"ResourceGroup": parts[2]},
 					Properties: map[string]interface{}{"provisioning_state": parts[1]},
 				}
@@ -4276,7 +4194,6 @@ func (ed *EnhancedDiscoverer) discoverAzureContainerRegistry(ctx context.Context
 					Name:       registryName,
 					Region:     region,
 					Provider:   "azure",
-					CreatedAt:  time.Now(),
 					Tags:       map[string]string{"ResourceGroup": parts[2]},
 					Properties: map[string]interface{}{"login_server": parts[1]},
 				}
@@ -4315,7 +4232,6 @@ func (ed *EnhancedDiscoverer) discoverAzureAKS(ctx context.Context, region strin
 					Name:       clusterName,
 					Region:     region,
 					Provider:   "azure",
-					CreatedAt:  time.Now(),
 					Tags:       map[string]string{"ResourceGroup": parts[2]},
 					Properties: map[string]interface{}{"provisioning_state": parts[1]},
 				}
@@ -4354,7 +4270,6 @@ func (ed *EnhancedDiscoverer) discoverAzureServiceFabric(ctx context.Context, re
 					Name:       clusterName,
 					Region:     region,
 					Provider:   "azure",
-					CreatedAt:  time.Now(),
 					Tags:       map[string]string{"ResourceGroup": parts[2]},
 					Properties: map[string]interface{}{"provisioning_state": parts[1]},
 				}
@@ -4393,7 +4308,6 @@ func (ed *EnhancedDiscoverer) discoverAzureSpringCloud(ctx context.Context, regi
 					Name:       serviceName,
 					Region:     region,
 					Provider:   "azure",
-					CreatedAt:  time.Now(),
 					Tags:       map[string]string{"ResourceGroup": parts[2]},
 					Properties: map[string]interface{}{"provisioning_state": parts[1]},
 				}
@@ -4433,7 +4347,6 @@ func (ed *EnhancedDiscoverer) discoverAzureAPIManagement(ctx context.Context, re
 					Name:       serviceName,
 					Region:     region,
 					Provider:   "azure",
-					CreatedAt:  time.Now(),
 					Tags:       map[string]string{"ResourceGroup": parts[2]},
 					Properties: map[string]interface{}{"provisioning_state": parts[1]},
 				}
@@ -4472,7 +4385,6 @@ func (ed *EnhancedDiscoverer) discoverAzureEventGrid(ctx context.Context, region
 					Name:       topicName,
 					Region:     region,
 					Provider:   "azure",
-					CreatedAt:  time.Now(),
 					Tags:       map[string]string{"ResourceGroup": parts[2]},
 					Properties: map[string]interface{}{"provisioning_state": parts[1]},
 				}
@@ -4511,7 +4423,6 @@ func (ed *EnhancedDiscoverer) discoverAzureStreamAnalytics(ctx context.Context,
 					Name:       jobName,
 					Region:     region,
 					Provider:   "azure",
-					CreatedAt:  time.Now(),
 					Tags:       map[string]string{"ResourceGroup": parts[2]},
 					Properties: map[string]interface{}{"job_state": parts[1]},
 				}
@@ -4551,7 +4462,6 @@ func (ed *EnhancedDiscoverer) discoverAzureDataLakeStorage(ctx context.Context,
 					Name:       accountName,
 					Region:     region,
 					Provider:   "azure",
-					CreatedAt:  time.Now(),
 					Tags:       map[string]string{"ResourceGroup": parts[2]},
 					Properties: map[string]interface{}{"status": parts[1]},
 				}
@@ -4590,7 +4500,6 @@ func (ed *EnhancedDiscoverer) discoverAzureHDInsight(ctx context.Context, region
 					Name:       clusterName,
 					Region:     region,
 					Provider:   "azure",
-					CreatedAt:  time.Now(),
 					Tags:       map[string]string{"ResourceGroup": parts[2]},
 					Properties: map[string]interface{}{"cluster_state": parts[1]},
 				}
@@ -4629,7 +4538,6 @@ func (ed *EnhancedDiscoverer) discoverAzureDatabricks(ctx context.Context, regio
 					Name:       workspaceName,
 					Region:     region,
 					Provider:   "azure",
-					CreatedAt:  time.Now(),
 					Tags:       map[string]string{"ResourceGroup": parts[2]},
 					Properties: map[string]interface{}{"provisioning_state": parts[1]},
 				}
@@ -4668,7 +4576,6 @@ func (ed *EnhancedDiscoverer) discoverAzureMachineLearning(ctx context.Context,
 					Name:       workspaceName,
 					Region:     region,
 					Provider:   "azure",
-					CreatedAt:  time.Now(),
 					Tags:       map[string]string{"ResourceGroup": parts[2]},
 					Properties: map[string]interface{}{"provisioning_state": parts[1]},
 				}
@@ -4708,7 +4615,6 @@ func (ed *EnhancedDiscoverer) discoverAzureCognitiveServices(ctx context.Context
 					Name:       accountName,
 					Region:     region,
 					Provider:   "azure",
-					CreatedAt:  time.Now(),
 					Tags:       map[string]string{"ResourceGroup": parts[2]},
 					Properties: map[string]interface{}{"provisioning_state": parts[1]},
 				}
@@ -4747,7 +4653,6 @@ func (ed *EnhancedDiscoverer) discoverAzureBotService(ctx context.Context, regio
 					Name:       botName,
 					Region:     region,
 					Provider:   "azure",
-					CreatedAt:  time.Now(),
 					Tags:       map[string]string{"ResourceGroup": parts[2]},
 					Properties: map[string]interface{}{"provisioning_state": parts[1]},
 				}
@@ -4787,7 +4692,6 @@ func (ed *EnhancedDiscoverer) discoverAzureSignalR(ctx context.Context, region s
 					Name:       serviceName,
 					Region:     region,
 					Provider:   "azure",
-					CreatedAt:  time.Now(),
 					Tags:       map[string]string{"ResourceGroup": parts[2]},
 					Properties: map[string]interface{}{"provisioning_state": parts[1]},
 				}
@@ -4826,7 +4730,6 @@ func (ed *EnhancedDiscoverer) discoverAzureMediaServices(ctx context.Context, re
 					Name:       accountName,
 					Region:     region,
 					Provider:   "azure",
-					CreatedAt:  time.Now(),
 					Tags:       map[string]string{"ResourceGroup": parts[2]},
 					Properties: map[string]interface{}{"provisioning_state": parts[1]},
 				}
@@ -4865,7 +4768,6 @@ func (ed *EnhancedDiscoverer) discoverAzureVideoIndexer(ctx context.Context, reg
 					Name:       accountName,
 					Region:     region,
 					Provider:   "azure",
-					CreatedAt:  time.Now(),
 					Tags:       map[string]string{"ResourceGroup": parts[2]},
 					Properties: map[string]interface{}{"provisioning_state": parts[1]},
 				}
@@ -4905,7 +4807,6 @@ func (ed *EnhancedDiscoverer) discoverAzureMaps(ctx context.Context, region stri
 					Name:       accountName,
 					Region:     region,
 					Provider:   "azure",
-					CreatedAt:  time.Now(),
 					Tags:       map[string]string{"ResourceGroup": parts[2]},
 					Properties: map[string]interface{}{"provisioning_state": parts[1]},
 				}
@@ -4944,7 +4845,6 @@ func (ed *EnhancedDiscoverer) discoverAzureTimeSeriesInsights(ctx context.Contex
 					Name:       envName,
 					Region:     region,
 					Provider:   "azure",
-					CreatedAt:  time.Now(),
 					Tags:       map[string]string{"ResourceGroup": parts[2]},
 					Properties: map[string]interface{}{"provisioning_state": parts[1]},
 				}
@@ -4983,7 +4883,6 @@ func (ed *EnhancedDiscoverer) discoverAzureDigitalTwins(ctx context.Context, reg
 					Name:       instanceName,
 					Region:     region,
 					Provider:   "azure",
-					CreatedAt:  time.Now(),
 					Tags:       map[string]string{"ResourceGroup": parts[2]},
 					Properties: map[string]interface{}{"provisioning_state": parts[1]},
 				}
@@ -5022,7 +4921,6 @@ func (ed *EnhancedDiscoverer) discoverGCPCloudTasks(ctx context.Context, region
 					Name:       parts[0],
 					Region:     region,
 					Provider:   "gcp",
-					CreatedAt:  time.Now(),
 					Tags:       map[string]string{},
 					Properties: map[string]interface{}{"state": parts[1], "type": parts[2]},
 				}
@@ -5060,7 +4958,6 @@ func (ed *EnhancedDiscoverer) discoverGCPCloudScheduler(ctx context.Context, reg
 					Name:       parts[0],
 					Region:     region,
 					Provider:   "gcp",
-					CreatedAt:  time.Now(),
 					Tags:       map[string]string{},
 					Properties: map[string]interface{}{"state": parts[1], "schedule": parts[2]},
 				}
@@ -5098,7 +4995,6 @@ func (ed *EnhancedDiscoverer) discoverGCPCloudDNS(ctx context.Context, region st
 					Name:       parts[0],
 					Region:     region,
 					Provider:   "gcp",
-					CreatedAt:  time.Now(),
 					Tags:       map[string]string{},
 					Properties: map[string]interface{}{"dns_name": parts[1], "visibility": parts[2]},
 				}
@@ -5136,7 +5032,6 @@ func (ed *EnhancedDiscoverer) discoverGCPCloudCDN(ctx context.Context, region st
 					Name:       parts[0],
 					Region:     region,
 					Provider:   "gcp",
-					CreatedAt:  time.Now(),
 					Tags:       map[string]string{},
 					Properties: map[string]interface{}{"load_balancing_scheme": parts[1], "protocol": parts[2]},
 				}
@@ -5173,7 +5068,6 @@ func (ed *EnhancedDiscoverer) discoverGCPCloudLoadBalancing(ctx context.Context,
 					Name:       parts[0],
 					Region:     region,
 					Provider:   "gcp",
-					CreatedAt:  time.Now(),
 					Tags:       map[string]string{},
 					Properties: map[string]interface{}{"default_service": parts[1], "load_balancing_scheme": parts[2]},
 				}
@@ -5212,7 +5106,6 @@ func (ed *EnhancedDiscoverer) discoverGCPCloudNAT(ctx context.Context, region st
 					Name:       parts[0],
 					Region:     region,
 					Provider:   "gcp",
-					CreatedAt:  time.Now(),
 					Tags:       map[string]string{},
 					Properties: map[string]interface{}{"source_subnetwork_ip_ranges": parts[1], "nat_ips": parts[2]},
 				}
@@ -5250,7 +5143,6 @@ func (ed *EnhancedDiscoverer) discoverGCPCloudRouter(ctx context.Context, region
 					Name:       parts[0],
 					Region:     region,
 					Provider:   "gcp",
-					CreatedAt:  time.Now(),
 					Tags:       map[string]string{},
 					Properties: map[string]interface{}{"network": parts[1], "asn": parts[2]},
 				}
@@ -5288,7 +5180,6 @@ func (ed *EnhancedDiscoverer) discoverGCPCloudVPN(ctx context.Context, region st
 					Name:       parts[0],
 					Region:     region,
 					Provider:   "gcp",
-					CreatedAt:  time.Now(),
 					Tags:       map[string]string{},
 					Properties: map[string]interface{}{"network": parts[1], "vpn_interfaces": parts[2]},
 				}
@@ -5326,7 +5217,6 @@ func (ed *EnhancedDiscoverer) discoverGCPCloudInterconnect(ctx context.Context,
 					Name:       parts[0],
 					Region:     region,
 					Provider:   "gcp",
-					CreatedAt:  time.Now(),
 					Tags:       map[string]string{},
 					Properties: map[string]interface{}{"interconnect": parts[1], "router": parts[2], "operational_status": parts[3]},
 				}
@@ -5365,7 +5255,6 @@ func (ed *EnhancedDiscoverer) discoverGCPCloudKMS(ctx context.Context, region st
 					Name:       parts[0],
 					Region:     region,
 					Provider:   "gcp",
-					CreatedAt:  time.Now(),
 					Tags:       map[string]string{},
 					Properties: map[string]interface{}{"create_time": parts[1]},
 				}
@@ -5402,7 +5291,6 @@ func (ed *EnhancedDiscoverer) discoverGCPCloudIAM(ctx context.Context, region st
 					Name:       parts[0],
 					Region:     region,
 					Provider:   "gcp",
-					CreatedAt:  time.Now(),
 					Tags:       map[string]string{},
 					Properties: map[string]interface{}{"display_name": parts[1], "disabled": parts[2]},
 				}
@@ -5439,7 +5327,6 @@ func (ed *EnhancedDiscoverer) discoverGCPCloudResourceManager(ctx context.Contex
 					Name:       parts[1],
 					Region:     region,
 					Provider:   "gcp",
-					CreatedAt:  time.Now(),
 					Tags:       map[string]string{},
 					Properties: map[string]interface{}{"project_number": parts[2]},
 				}
@@ -5476,7 +5363,6 @@ func (ed *EnhancedDiscoverer) discoverGCPCloudBilling(ctx context.Context, regio
 					Name:       parts[1],
 					Region:     region,
 					Provider:   "gcp",
-					CreatedAt:  time.Now(),
 					Tags:       map[string]string{},
 					Properties: map[string]interface{}{"open": parts[2]},
 				}
@@ -5515,7 +5401,6 @@ func (ed *EnhancedDiscoverer) discoverGCPCloudTrace(ctx context.Context, region
 					Name:       parts[0],
 					Region:     region,
 					Provider:   "gcp",
-					CreatedAt:  time.Now(),
 					Tags:       map[string]string{},
 					Properties: map[string]interface{}{"start_time": parts[1]},
 				}
@@ -5593,7 +5478,6 @@ func (ed *EnhancedDiscoverer) discoverGCPCloudProfiler(ctx context.Context, regi
 					Name:       parts[1],
 					Region:     region,
 					Provider:   "gcp",
-					CreatedAt:  time.Now(),
 					Tags:       map[string]string{},
 					Properties: map[string]interface{}{"profile_type": parts[0], "labels": parts[2]},
 				}
@@ -5630,7 +5514,6 @@ func (ed *EnhancedDiscoverer) discoverGCPCloudErrorReporting(ctx context.Context
 					Name:       parts[1],
 					Region:     region,
 					Provider:   "gcp",
-					CreatedAt:  time.Now(),
 					Tags:       map[string]string{},
 					Properties: map[string]interface{}{"service_name": parts[0]},
 				}
@@ -5669,7 +5552,6 @@ func (ed *EnhancedDiscoverer) discoverAWSAthena(ctx context.Context, region stri
 					Name:       workgroupName,
 					Region:     region,
 					Provider:   "aws",
-					CreatedAt:  time.Now(),
 					Tags:       map[string]string{},
 					Properties: map[string]interface{}{"service": "athena"},
 				}
@@ -5707,7 +5589,6 @@ func (ed *EnhancedDiscoverer) discoverAWSKinesis(ctx context.Context, region str
 					Name:       streamName,
 					Region:     region,
 					Provider:   "aws",
-					CreatedAt:  time.Now(),
 					Tags:       map[string]string{},
 					Properties: map[string]interface{}{"service": "kinesis"},
 				}
@@ -5745,7 +5626,6 @@ func (ed *EnhancedDiscoverer) discoverAWSDataPipeline(ctx context.Context, regio
 					Name:       pipelineName,
 					Region:     region,
 					Provider:   "aws",
-					CreatedAt:  time.Now(),
 					Tags:       map[string]string{},
 					Properties: map[string]interface{}{"service": "datapipeline"},
 				}
@@ -5783,7 +5663,6 @@ func (ed *EnhancedDiscoverer) discoverAWSQuickSight(ctx context.Context, region
 					Name:       dashboardName,
 					Region:     region,
 					Provider:   "aws",
-					CreatedAt:  time.Now(),
 					Tags:       map[string]string{},
 					Properties: map[string]interface{}{"service": "quicksight"},
 				}
@@ -5821,7 +5700,6 @@ func (ed *EnhancedDiscoverer) discoverAWSDataSync(ctx context.Context, region st
 					Name:       taskName,
 					Region:     region,
 					Provider:   "aws",
-					CreatedAt:  time.Now(),
 					Tags:       map[string]string{},
 					Properties: map[string]interface{}{"service": "datasync"},
 				}
@@ -5859,7 +5737,6 @@ func (ed *EnhancedDiscoverer) discoverAWSStorageGateway(ctx context.Context, reg
 					Name:       gatewayName,
 					Region:     region,
 					Provider:   "aws",
-					CreatedAt:  time.Now(),
 					Tags:       map[string]string{},
 					Properties: map[string]interface{}{"service": "storagegateway"},
 				}
@@ -5897,7 +5774,6 @@ func (ed *EnhancedDiscoverer) discoverAWSBackup(ctx context.Context, region stri
 					Name:       vaultName,
 					Region:     region,
 					Provider:   "aws",
-					CreatedAt:  time.Now(),
 					Tags:       map[string]string{},
 					Properties: map[string]interface{}{"service": "backup"},
 				}
@@ -5935,7 +5811,6 @@ func (ed *EnhancedDiscoverer) discoverAWSFSx(ctx context.Context, region string)
 					Name:       fsName,
 					Region:     region,
 					Provider:   "aws",
-					CreatedAt:  time.Now(),
 					Tags:       map[string]string{},
 					Properties: map[string]interface{}{"service": "fsx"},
 				}
@@ -5973,7 +5848,6 @@ func (ed *EnhancedDiscoverer) discoverAWSWorkSpaces(ctx context.Context, region
 					Name:       workspaceName,
 					Region:     region,
 					Provider:   "aws",
-					CreatedAt:  time.Now(),
 					Tags:       map[string]string{},
 					Properties: map[string]interface{}{"service": "workspaces"},
 				}
@@ -6011,7 +5885,6 @@ func (ed *EnhancedDiscoverer) discoverAWSAppStream(ctx context.Context, region s
 					Name:       fleetName,
 					Region:     region,
 					Provider:   "aws",
-					CreatedAt:  time.Now(),
 					Tags:       map[string]string{},
 					Properties: map[string]interface{}{"service": "appstream"},
 				}
@@ -6051,7 +5924,6 @@ func (ed *EnhancedDiscoverer) discoverAzureDataExplorer(ctx context.Context, reg
 					Name:       clusterName,
 					Region:     region,
 					Provider:   "azure",
-					CreatedAt:  time.Now(),
 					Tags:       map[string]string{"ResourceGroup": parts[2]},
 					Properties: map[string]interface{}{"service": "dataexplorer"},
 				}
@@ -6090,7 +5962,6 @@ func (ed *EnhancedDiscoverer) discoverAzureDataShare(ctx context.Context, region
 					Name:       accountName,
 					Region:     region,
 					Provider:   "azure",
-					CreatedAt:  time.Now(),
 					Tags:       map[string]string{"ResourceGroup": parts[2]},
 					Properties: map[string]interface{}{"service": "datashare"},
 				}
@@ -6129,7 +6000,6 @@ func (ed *EnhancedDiscoverer) discoverAzureDataBricks(ctx context.Context, regio
 					Name:       workspaceName,
 					Region:     region,
 					Provider:   "azure",
-					CreatedAt:  time.Now(),
 					Tags:       map[string]string{"ResourceGroup": parts[2]},
 					Properties: map[string]interface{}{"service": "databricks"},
 				}
@@ -6168,7 +6038,6 @@ func (ed *EnhancedDiscoverer) discoverAzurePurview(ctx context.Context, region s
 					Name:       accountName,
 					Region:     region,
 					Provider:   "azure",
-					CreatedAt:  time.Now(),
 					Tags:       map[string]string{"ResourceGroup": parts[2]},
 					Properties: map[string]interface{}{"service": "purview"},
 				}
@@ -6207,7 +6076,6 @@ func (ed *EnhancedDiscoverer) discoverAzureDataFactoryV2(ctx context.Context, re
 					Name:       factoryName,
 					Region:     region,
 					Provider:   "azure",
-					CreatedAt:  time.Now(),
 					Tags:       map[string]string{"ResourceGroup": parts[2]},
 					Properties: map[string]interface{}{"service": "datafactory"},
 				}
@@ -6246,7 +6114,6 @@ func (ed *EnhancedDiscoverer) discoverAzureDataLakeAnalytics(ctx context.Context
 					Name:       accountName,
 					Region:     region,
 					Provider:   "azure",
-					CreatedAt:  time.Now(),
 					Tags:       map[string]string{"ResourceGroup": parts[2]},
 					Properties: map[string]interface{}{"service": "datalakeanalytics"},
 				}
@@ -6285,7 +6152,6 @@ func (ed *EnhancedDiscoverer) discoverAzureDataLakeStore(ctx context.Context, re
 					Name:       accountName,
 					Region:     region,
 					Provider:   "azure",
-					CreatedAt:  time.Now(),
 					Tags:       map[string]string{"ResourceGroup": parts[2]},
 					Properties: map[string]interface{}{"service": "datalakestore"},
 				}
@@ -6324,7 +6190,6 @@ func (ed *EnhancedDiscoverer) discoverAzureDataCatalog(ctx context.Context, regi
 					Name:       catalogName,
 					Region:     region,
 					Provider:   "azure",
-					CreatedAt:  time.Now(),
 					Tags:       map[string]string{"ResourceGroup": parts[2]},
 					Properties: map[string]interface{}{"service": "datacatalog"},
 				}
@@ -6363,7 +6228,6 @@ func (ed *EnhancedDiscoverer) discoverAzureDataBox(ctx context.Context, region s
 					Name:       jobName,
 					Region:     region,
 					Provider:   "azure",
-					CreatedAt:  time.Now(),
 					Tags:       map[string]string{"ResourceGroup": parts[2]},
 					Properties: map[string]interface{}{"service": "databox"},
 				}
@@ -6402,7 +6266,6 @@ func (ed *EnhancedDiscoverer) discoverGCPDataflow(ctx context.Context, region st
 					Name:       parts[1],
 					Region:     region,
 					Provider:   "gcp",
-					CreatedAt:  time.Now(),
 					Tags:       map[string]string{},
 					Properties: map[string]interface{}{"state": parts[2]},
 				}
@@ -6440,7 +6303,6 @@ func (ed *EnhancedDiscoverer) discoverGCPDataproc(ctx context.Context, region st
 					Name:       parts[0],
 					Region:     region,
 					Provider:   "gcp",
-					CreatedAt:  time.Now(),
 					Tags:       map[string]string{},
 					Properties: map[string]interface{}{"state": parts[1], "worker_instances": parts[2]},
 				}
@@ -6478,7 +6340,6 @@ func (ed *EnhancedDiscoverer) discoverGCPComposer(ctx context.Context, region st
 					Name:       parts[0],
 					Region:     region,
 					Provider:   "gcp",
-					CreatedAt:  time.Now(),
 					Tags:       map[string]string{},
 					Properties: map[string]interface{}{"state": parts[1], "image_version": parts[2]},
 				}
@@ -6516,7 +6377,6 @@ func (ed *EnhancedDiscoverer) discoverGCPDataCatalog(ctx context.Context, region
 					Name:       parts[1],
 					Region:     region,
 					Provider:   "gcp",
-					CreatedAt:  time.Now(),
 					Tags:       map[string]string{},
 					Properties: map[string]interface{}{"linked_resource": parts[1], "entry_type": parts[2]},
 				}
@@ -6554,7 +6414,6 @@ func (ed *EnhancedDiscoverer) discoverGCPDataFusion(ctx context.Context, region
 					Name:       parts[0],
 					Region:     region,
 					Provider:   "gcp",
-					CreatedAt:  time.Now(),
 					Tags:       map[string]string{},
 					Properties: map[string]interface{}{"instance_type": parts[1], "state": parts[2]},
 				}
@@ -6592,7 +6451,6 @@ func (ed *EnhancedDiscoverer) discoverGCPDataLabeling(ctx context.Context, regio
 					Name:       parts[1],
 					Region:     region,
 					Provider:   "gcp",
-					CreatedAt:  time.Now(),
 					Tags:       map[string]string{},
 					Properties: map[string]interface{}{"display_name": parts[1], "schema_uri": parts[2]},
 				}
@@ -6630,7 +6488,6 @@ func (ed *EnhancedDiscoverer) discoverGCPAutoML(ctx context.Context, region stri
 					Name:       parts[1],
 					Region:     region,
 					Provider:   "gcp",
-					CreatedAt:  time.Now(),
 					Tags:       map[string]string{},
 					Properties: map[string]interface{}{"display_name": parts[1], "default_version": parts[2]},
 				}
@@ -6668,7 +6525,6 @@ func (ed *EnhancedDiscoverer) discoverGCPVertexAI(ctx context.Context, region st
 					Name:       parts[1],
 					Region:     region,
 					Provider:   "gcp",
-					CreatedAt:  time.Now(),
 					Tags:       map[string]string{},
 					Properties: map[string]interface{}{"display_name": parts[1], "version_id": parts[2]},
 				}
@@ -6706,7 +6562,6 @@ func (ed *EnhancedDiscoverer) discoverGCPCloudDeploy(ctx context.Context, region
 					Name:       parts[0],
 					Region:     region,
 					Provider:   "gcp",
-					CreatedAt:  time.Now(),
 					Tags:       map[string]string{},
 					Properties: map[string]interface{}{"description": parts[1], "stages": parts[2]},
 				}
@@ -6717,3 +6572,20 @@ func (ed *EnhancedDiscoverer) discoverGCPCloudDeploy(ctx context.Context, region
 
 	return resources
 }
+
+// parseCreationTime parses a creation time printed by a cloud CLI: an RFC 3339 timestamp,
+// with or without a colon in its offset, or Unix epoch milliseconds as CloudWatch Logs and
+// BigQuery report. JSON quoting and separators around the value are ignored. An unparseable
+// value returns the zero time, leaving the resource's age unknown.
+func parseCreationTime(value string) time.Time {
+	value = strings.Trim(value, `",[]`)
+	for _, layout := range []string{time.RFC3339Nano, "2006-01-02T15:04:05.999999999-0700"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t.UTC()
+		}
+	}
+	if millis, err := strconv.ParseInt(value, 10, 64); err == nil && millis > 0 {
+		return time.UnixMilli(millis).UTC()
+	}
+	return time.Time{}
+}
@@ -383,3 +383,27 @@ func TestResourceCache_Concurrent(t *testing.T) {
 		assert.Equal(t, expectedValue, val)
 	}
 }
+
+func TestParseCreationTime(t *testing.T) {
+	created := time.Date(2023, 1, 15, 10, 30, 0, 0, time.UTC)
+	tests := []struct {
+		name  string
+		value string
+		want  time.Time
+	}{
+		{"S3 creation_date", `"2023-01-15T10:30:00+00:00",`, created},
+		{"IAM create_date", `"2023-01-15T12:30:00+02:00"`, created},
+		{"CloudFormation creation_time", "2023-01-15T10:30:00.000Z", created},
+		{"offset without colon", "2023-01-15T10:30:00+0000", created},
+		{"epoch milliseconds", "1673778600000", created},
+		{"unparseable", "None", time.Time{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.True(t, tt.want.Equal(parseCreationTime(tt.value)), "got %v", parseCreationTime(tt.value))
+		})
+	}
+
+	resource := models.Resource{CreatedAt: parseCreationTime("None")}
+	assert.True(t, resource.AgeUnknown(), "an unparseable creation time leaves the age unknown")
+}
@@ -32,6 +32,10 @@ type DetectorConfig struct {
 	ParallelDiscovery bool          `json:"parallel_discovery"`
 	RetryAttempts     int           `json:"retry_attempts"`
 	RetryDelay        time.Duration `json:"retry_delay"`
+
+	// SeverityRules raise the severity of drift that disables a protection; nil uses
+	// DefaultSeverityRules and an empty list disables them
+	SeverityRules []SeverityRule `json:"severity_rules,omitempty"`
 }
 
 // DriftResult represents the result of drift detection
@@ -269,8 +273,13 @@ func (dd *DriftDetector) checkResourceDrift(ctx context.Context, resource state.
 
 	// Compare states
 	differences := dd.comparator.Compare(instance.Attributes, actualResource.Attributes)
+	var exposures []string
+	if resource.Type == "aws_security_group" {
+		differences, exposures = securityGroupDifferences(differences, instance.Attributes, actualResource.Attributes)
+	}
+	protections := DisabledProtections(dd.severityRules(), resource.Type, instance.Attributes, actualResource.Attributes)
 
-	if len(differences) == 0 {
+	if len(differences) == 0 && len(protections) == 0 {
 		// No drift
 		return nil, nil
 	}
@@ -288,6 +297,11 @@ func (dd *DriftDetector) checkResourceDrift(ctx context.Context, resource state.
 		Impact:       dd.analyzeImpact(resource, differences),
 		Timestamp:    time.Now(),
 	}
+	applyProtections(result, protections)
+	if len(exposures) > 0 {
+		result.Severity = SeverityCritical
+		result.Impact = append(result.Impact, exposures...)
+	}
 
 	// Generate recommendation
 	result.Recommendation = dd.generateResourceRecommendation(result)
@@ -295,13 +309,15 @@ func (dd *DriftDetector) checkResourceDrift(ctx context.Context, resource state.
 	return result, nil
 }
 
-// findUnmanagedResources finds resources that exist in cloud but not in state
-func (dd *DriftDetector) findUnmanagedResources(ctx context.Context, state *state.TerraformState) ([]DriftResult, error) {
+// findUnmanagedResources finds resources that exist in cloud but not in state. Resources
+// managed by another tool, such as CloudFormation or Pulumi, are not reported.
+func (dd *DriftDetector) findUnmanagedResources(ctx context.Context, tfState *state.TerraformState) ([]DriftResult, error) {
 	unmanagedResults := make([]DriftResult, 0)
+	classifier := state.NewManagementClassifier()
 
 	// Build map of managed resources
 	managedResources := make(map[string]bool)
-	for _, resource := range state.Resources {
+	for _, resource := range tfState.Resources {
 		for _, instance := range resource.Instances {
 			if id, err := dd.extractResourceID(instance.Attributes); err == nil {
 				key := fmt.Sprintf("%s:%s", resource.Type, id)
@@ -320,7 +336,7 @@ func (dd *DriftDetector) findUnmanagedResources(ctx context.Context, state *stat
 
 		for _, cloudResource := range allResources {
 			key := fmt.Sprintf("%s:%s", cloudResource.Type, cloudResource.ID)
-			if !managedResources[key] {
+			if !managedResources[key] && classifier.Classify(cloudResource) == "" {
 				// Found unmanaged resource
 				unmanagedResults = append(unmanagedResults, DriftResult{
 					Resource:     fmt.Sprintf("%s.unmanaged_%s", cloudResource.Type, cloudResource.ID),
@@ -0,0 +1,180 @@
+package detector
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/catherinevee/driftmgr/internal/drift/comparator"
+)
+
+// SeverityRule flags a safety setting whose loss risks data: when Attribute is enabled in the
+// desired state but disabled on the cloud resource, the drift is raised to Severity.
+type SeverityRule struct {
+	ResourceType string `json:"resource_type"`
+	// Attribute is a dotted path into the resource's attributes; numeric segments index lists,
+	// as in versioning.0.enabled
+	Attribute string `json:"attribute"`
+	// Exposure inverts the rule for settings that are unsafe when on, such as a public bucket
+	// policy: the drift is flagged when Attribute is enabled on the cloud resource but not in
+	// the desired state
+	Exposure    bool   `json:"exposure,omitempty"`
+	Severity    string `json:"severity"` // low, medium, high or critical; empty means critical
+	Description string `json:"description"`
+}
+
+// DefaultSeverityRules cover the deletion-protection, lifecycle and S3 public-access settings
+// whose loss most often leads to data loss or exposure
+var DefaultSeverityRules = []SeverityRule{
+	{ResourceType: "aws_db_instance", Attribute: "deletion_protection", Description: "RDS deletion protection was disabled"},
+	{ResourceType: "aws_rds_cluster", Attribute: "deletion_protection", Description: "RDS cluster deletion protection was disabled"},
+	{ResourceType: "aws_dynamodb_table", Attribute: "deletion_protection_enabled", Description: "DynamoDB deletion protection was disabled"},
+	{ResourceType: "aws_instance", Attribute: "disable_api_termination", Description: "EC2 termination protection was disabled"},
+	{ResourceType: "aws_lb", Attribute: "enable_deletion_protection", Description: "Load balancer deletion protection was disabled"},
+	{ResourceType: "aws_s3_bucket", Attribute: "versioning.0.enabled", Description: "S3 versioning was disabled"},
+	{ResourceType: "aws_s3_bucket", Attribute: "versioning.0.mfa_delete", Description: "S3 MFA delete was disabled"},
+	{ResourceType: "aws_s3_bucket_versioning", Attribute: "versioning_configuration.0.status", Description: "S3 versioning was disabled"},
+	{ResourceType: "aws_s3_bucket_versioning", Attribute: "versioning_configuration.0.mfa_delete", Description: "S3 MFA delete was disabled"},
+	{ResourceType: "aws_s3_bucket", Attribute: "server_side_encryption_configuration.0.rule.0.apply_server_side_encryption_by_default.0.sse_algorithm", Description: "S3 default encryption was removed"},
+	{ResourceType: "aws_s3_bucket_server_side_encryption_configuration", Attribute: "rule.0.apply_server_side_encryption_by_default.0.sse_algorithm", Description: "S3 default encryption was removed"},
+	{ResourceType: "aws_s3_bucket", Attribute: "policy_is_public", Exposure: true, Description: "S3 bucket policy grants public access"},
+	{ResourceType: "aws_s3_bucket_public_access_block", Attribute: "block_public_acls", Description: "S3 public ACL blocking was disabled"},
+	{ResourceType: "aws_s3_bucket_public_access_block", Attribute: "block_public_policy", Description: "S3 public policy blocking was disabled"},
+	{ResourceType: "aws_s3_bucket_public_access_block", Attribute: "ignore_public_acls", Description: "S3 public ACLs are no longer ignored"},
+	{ResourceType: "aws_s3_bucket_public_access_block", Attribute: "restrict_public_buckets", Description: "S3 public bucket restriction was disabled"},
+	{ResourceType: "google_sql_database_instance", Attribute: "deletion_protection", Description: "Cloud SQL deletion protection was disabled"},
+}
+
+// ProtectionDrift is a protection that is on in the desired state and off in the cloud, or an
+// exposure that is on in the cloud and not in the desired state
+type ProtectionDrift struct {
+	Rule     SeverityRule
+	Expected interface{}
+	Actual   interface{}
+}
+
+// severityRules returns the configured rules, or the defaults when none are configured
+func (dd *DriftDetector) severityRules() []SeverityRule {
+	if dd.config != nil && dd.config.SeverityRules != nil {
+		return dd.config.SeverityRules
+	}
+	return DefaultSeverityRules
+}
+
+// DisabledProtections returns the rules for resourceType whose protection is enabled in
+// desired and disabled, or missing, in actual, along with exposure rules enabled in actual
+// but not in desired
+func DisabledProtections(rules []SeverityRule, resourceType string, desired, actual map[string]interface{}) []ProtectionDrift {
+	var drifts []ProtectionDrift
+	for _, rule := range rules {
+		if rule.ResourceType != resourceType {
+			continue
+		}
+		expected, ok := lookupAttribute(desired, rule.Attribute)
+		current, _ := lookupAttribute(actual, rule.Attribute)
+		if rule.Exposure {
+			if protectionEnabled(current) && !protectionEnabled(expected) {
+				drifts = append(drifts, ProtectionDrift{Rule: rule, Expected: expected, Actual: current})
+			}
+			continue
+		}
+		if ok && protectionEnabled(expected) && !protectionEnabled(current) {
+			drifts = append(drifts, ProtectionDrift{Rule: rule, Expected: expected, Actual: current})
+		}
+	}
+	return drifts
+}
+
+// applyProtections raises a drift result to the severity of each disabled protection and adds
+// a difference for any the comparator did not report
+func applyProtections(result *DriftResult, protections []ProtectionDrift) {
+	for _, protection := range protections {
+		if severity := parseSeverity(protection.Rule.Severity); severity > result.Severity {
+			result.Severity = severity
+		}
+		if protection.Rule.Description != "" {
+			result.Impact = append(result.Impact, protection.Rule.Description)
+		}
+		if !hasDifference(result.Differences, protection.Rule.Attribute) {
+			result.Differences = append(result.Differences, comparator.Difference{
+				Path:       protection.Rule.Attribute,
+				Type:       comparator.DiffTypeModified,
+				Expected:   protection.Expected,
+				Actual:     protection.Actual,
+				Message:    fmt.Sprintf("Protection disabled at %s", protection.Rule.Attribute),
+				Importance: comparator.ImportanceCritical,
+			})
+		}
+	}
+}
+
+// parseSeverity maps a rule severity name to a DriftSeverity; unknown names are critical so a
+// mistyped rule errs on the side of attention
+func parseSeverity(name string) DriftSeverity {
+	switch strings.ToLower(name) {
+	case "low":
+		return SeverityLow
+	case "medium":
+		return SeverityMedium
+	case "high":
+		return SeverityHigh
+	default:
+		return SeverityCritical
+	}
+}
+
+// protectionEnabled reports whether a setting's value turns it on. Strings other than the
+// usual off values count as on, so an encryption algorithm such as AES256 is enabled.
+func protectionEnabled(value interface{}) bool {
+	switch v := value.(type) {
+	case bool:
+		return v
+	case string:
+		switch strings.ToLower(v) {
+		case "", "false", "disabled", "suspended", "off", "none":
+			return false
+		}
+		return true
+	}
+	return false
+}
+
+// indexPattern matches the [i] list indexes in comparator paths
+var indexPattern = regexp.MustCompile(`\[(\d+)\]`)
+
+// hasDifference reports whether a difference covers the attribute path, either at the path
+// itself or at a parent that was compared as a whole
+func hasDifference(differences []comparator.Difference, attribute string) bool {
+	for _, diff := range differences {
+		path := indexPattern.ReplaceAllString(diff.Path, ".$1")
+		if path == attribute || strings.HasPrefix(attribute, path+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// lookupAttribute walks a dotted path through nested maps and lists
+func lookupAttribute(attributes map[string]interface{}, path string) (interface{}, bool) {
+	var current interface{} = attributes
+	for _, segment := range strings.Split(path, ".") {
+		switch node := current.(type) {
+		case map[string]interface{}:
+			value, ok := node[segment]
+			if !ok {
+				return nil, false
+			}
+			current = value
+		case []interface{}:
+			index, err := strconv.Atoi(segment)
+			if err != nil || index < 0 || index >= len(node) {
+				return nil, false
+			}
+			current = node[index]
+		default:
+			return nil, false
+		}
+	}
+	return current, true
+}
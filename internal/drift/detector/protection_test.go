@@ -0,0 +1,102 @@
+package detector
+
+import (
+	"testing"
+
+	"github.com/catherinevee/driftmgr/internal/drift/comparator"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDisabledProtections(t *testing.T) {
+	desired := map[string]interface{}{
+		"bucket": "logs",
+		"versioning": []interface{}{
+			map[string]interface{}{"enabled": true, "mfa_delete": false},
+		},
+	}
+	actual := map[string]interface{}{
+		"bucket": "logs",
+		"versioning": []interface{}{
+			map[string]interface{}{"enabled": false, "mfa_delete": false},
+		},
+	}
+
+	drifts := DisabledProtections(DefaultSeverityRules, "aws_s3_bucket", desired, actual)
+	require.Len(t, drifts, 1, "only versioning was on in state and off in the cloud")
+	assert.Equal(t, "versioning.0.enabled", drifts[0].Rule.Attribute)
+	assert.Equal(t, true, drifts[0].Expected)
+	assert.Equal(t, false, drifts[0].Actual)
+
+	// Enabling a protection in the cloud that state leaves off is not a protection drift
+	assert.Empty(t, DisabledProtections(DefaultSeverityRules, "aws_s3_bucket", actual, desired))
+
+	// A protection missing from the cloud resource counts as disabled
+	drifts = DisabledProtections(DefaultSeverityRules, "aws_db_instance",
+		map[string]interface{}{"deletion_protection": true}, map[string]interface{}{})
+	assert.Len(t, drifts, 1)
+
+	drifts = DisabledProtections(DefaultSeverityRules, "aws_s3_bucket_versioning",
+		map[string]interface{}{"versioning_configuration": []interface{}{map[string]interface{}{"status": "Enabled"}}},
+		map[string]interface{}{"versioning_configuration": []interface{}{map[string]interface{}{"status": "Suspended"}}})
+	assert.Len(t, drifts, 1)
+}
+
+func TestApplyProtections(t *testing.T) {
+	result := &DriftResult{
+		Severity: SeverityLow,
+		Differences: []comparator.Difference{
+			{Path: "versioning[0].enabled", Type: comparator.DiffTypeModified, Expected: true, Actual: false},
+		},
+	}
+	protections := []ProtectionDrift{
+		{Rule: DefaultSeverityRules[5], Expected: true, Actual: false},
+		{Rule: SeverityRule{ResourceType: "aws_instance", Attribute: "disable_api_termination", Severity: "high"}, Expected: true},
+	}
+
+	applyProtections(result, protections)
+
+	assert.Equal(t, SeverityCritical, result.Severity)
+	assert.Contains(t, result.Impact, "S3 versioning was disabled")
+	// The comparator already reported versioning, so only termination protection is added
+	require.Len(t, result.Differences, 2)
+	assert.Equal(t, "disable_api_termination", result.Differences[1].Path)
+}
+
+func TestSeverityRulesConfig(t *testing.T) {
+	detector := NewDriftDetector(nil)
+	assert.Equal(t, DefaultSeverityRules, detector.severityRules())
+
+	detector.SetConfig(&DetectorConfig{SeverityRules: []SeverityRule{}})
+	assert.Empty(t, detector.severityRules(), "an empty list disables the rules")
+}
+
+func TestS3ExposureRules(t *testing.T) {
+	desired := map[string]interface{}{"bucket": "logs"}
+	actual := map[string]interface{}{"bucket": "logs", "policy_is_public": true}
+
+	drifts := DisabledProtections(DefaultSeverityRules, "aws_s3_bucket", desired, actual)
+	require.Len(t, drifts, 1, "a bucket policy that turned public is flagged")
+	assert.Equal(t, "policy_is_public", drifts[0].Rule.Attribute)
+
+	// A bucket that is public in state too is intended
+	desired["policy_is_public"] = true
+	assert.Empty(t, DisabledProtections(DefaultSeverityRules, "aws_s3_bucket", desired, actual))
+
+	encrypted := map[string]interface{}{"server_side_encryption_configuration": []interface{}{
+		map[string]interface{}{"rule": []interface{}{
+			map[string]interface{}{"apply_server_side_encryption_by_default": []interface{}{
+				map[string]interface{}{"sse_algorithm": "AES256"},
+			}},
+		}},
+	}}
+	drifts = DisabledProtections(DefaultSeverityRules, "aws_s3_bucket", encrypted,
+		map[string]interface{}{"server_side_encryption_configuration": []interface{}{}})
+	require.Len(t, drifts, 1, "removing default encryption is flagged")
+
+	drifts = DisabledProtections(DefaultSeverityRules, "aws_s3_bucket_public_access_block",
+		map[string]interface{}{"block_public_acls": true, "block_public_policy": true},
+		map[string]interface{}{"block_public_acls": true, "block_public_policy": false})
+	require.Len(t, drifts, 1)
+	assert.Equal(t, "block_public_policy", drifts[0].Rule.Attribute)
+}
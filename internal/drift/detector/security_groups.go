@@ -0,0 +1,272 @@
+package detector
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/catherinevee/driftmgr/internal/drift/comparator"
+)
+
+// SensitivePorts are the ports whose exposure to the internet is flagged as critical
+var SensitivePorts = map[int]string{
+	21:    "FTP",
+	22:    "SSH",
+	23:    "Telnet",
+	445:   "SMB",
+	1433:  "SQL Server",
+	1521:  "Oracle",
+	2375:  "Docker",
+	2379:  "etcd",
+	3306:  "MySQL",
+	3389:  "RDP",
+	5432:  "PostgreSQL",
+	5900:  "VNC",
+	6379:  "Redis",
+	9200:  "Elasticsearch",
+	11211: "Memcached",
+	27017: "MongoDB",
+}
+
+// wideCIDRs are the source ranges that open a rule to the whole internet
+var wideCIDRs = map[string]bool{
+	"0.0.0.0/0": true,
+	"::/0":      true,
+}
+
+// SecurityGroupRule is one source of one security group rule. Terraform and the EC2 API both
+// group several sources under a single rule; splitting them lets rule sets be compared without
+// regard to order or grouping.
+type SecurityGroupRule struct {
+	Direction string `json:"direction"` // ingress or egress
+	Protocol  string `json:"protocol"`  // tcp, udp, icmp, a protocol number, or -1 for all
+	FromPort  int    `json:"from_port"`
+	ToPort    int    `json:"to_port"`
+	// Source is a CIDR, prefix list ID or security group ID, or "self"
+	Source string `json:"source"`
+}
+
+// String describes the rule, as in "ingress tcp 22 from 0.0.0.0/0"
+func (r SecurityGroupRule) String() string {
+	ports := "all ports"
+	switch {
+	case r.Protocol == "-1":
+	case r.FromPort == r.ToPort:
+		ports = strconv.Itoa(r.FromPort)
+	default:
+		ports = fmt.Sprintf("%d-%d", r.FromPort, r.ToPort)
+	}
+	protocol := r.Protocol
+	if protocol == "-1" {
+		protocol = "all traffic"
+	}
+	preposition := "from"
+	if r.Direction == "egress" {
+		preposition = "to"
+	}
+	return fmt.Sprintf("%s %s %s %s %s", r.Direction, protocol, ports, preposition, r.Source)
+}
+
+// ExposedPorts returns the sensitive ports an ingress rule opens to the internet, sorted; a
+// rule covering all traffic exposes every sensitive port
+func (r SecurityGroupRule) ExposedPorts() []int {
+	if r.Direction != "ingress" || !wideCIDRs[r.Source] {
+		return nil
+	}
+	var ports []int
+	for port := range SensitivePorts {
+		if r.Protocol == "-1" || (r.Protocol == "tcp" && port >= r.FromPort && port <= r.ToPort) {
+			ports = append(ports, port)
+		}
+	}
+	sort.Ints(ports)
+	return ports
+}
+
+// NormalizeSecurityGroupRules splits the ingress and egress blocks of aws_security_group
+// attributes into single-source rules
+func NormalizeSecurityGroupRules(attributes map[string]interface{}) []SecurityGroupRule {
+	var rules []SecurityGroupRule
+	for _, direction := range []string{"ingress", "egress"} {
+		for _, block := range toList(attributes[direction]) {
+			fields, ok := block.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			base := SecurityGroupRule{
+				Direction: direction,
+				Protocol:  normalizeProtocol(fields["protocol"]),
+				FromPort:  toInt(fields["from_port"]),
+				ToPort:    toInt(fields["to_port"]),
+			}
+			if base.Protocol == "-1" {
+				base.FromPort, base.ToPort = 0, 0
+			}
+			for _, key := range []string{"cidr_blocks", "ipv6_cidr_blocks", "prefix_list_ids", "security_groups"} {
+				for _, source := range toList(fields[key]) {
+					rule := base
+					rule.Source = fmt.Sprint(source)
+					rules = append(rules, rule)
+				}
+			}
+			if self, _ := fields["self"].(bool); self {
+				rule := base
+				rule.Source = "self"
+				rules = append(rules, rule)
+			}
+		}
+	}
+	return rules
+}
+
+// DiffSecurityGroupRules returns the rules in actual but not desired, and those in desired but
+// not actual
+func DiffSecurityGroupRules(desired, actual []SecurityGroupRule) (added, removed []SecurityGroupRule) {
+	want := make(map[SecurityGroupRule]bool, len(desired))
+	for _, rule := range desired {
+		want[rule] = true
+	}
+	have := make(map[SecurityGroupRule]bool, len(actual))
+	for _, rule := range actual {
+		have[rule] = true
+		if !want[rule] {
+			added = append(added, rule)
+		}
+	}
+	for _, rule := range desired {
+		if !have[rule] {
+			removed = append(removed, rule)
+		}
+	}
+	return dedupeRules(added), dedupeRules(removed)
+}
+
+// securityGroupDifferences replaces the comparator's index-by-index differences on ingress and
+// egress with one difference per added or removed rule, and flags rules that newly open
+// sensitive ports to the internet. It returns the differences and any impact statements.
+func securityGroupDifferences(differences []comparator.Difference, desired, actual map[string]interface{}) ([]comparator.Difference, []string) {
+	kept := make([]comparator.Difference, 0, len(differences))
+	for _, diff := range differences {
+		if !isRulePath(diff.Path) {
+			kept = append(kept, diff)
+		}
+	}
+
+	var impacts []string
+	added, removed := DiffSecurityGroupRules(NormalizeSecurityGroupRules(desired), NormalizeSecurityGroupRules(actual))
+	for _, rule := range added {
+		diff := comparator.Difference{
+			Path:       rule.Direction,
+			Type:       comparator.DiffTypeAdded,
+			Actual:     rule,
+			Message:    fmt.Sprintf("Rule added: %s", rule),
+			Importance: comparator.ImportanceHigh,
+		}
+		if ports := rule.ExposedPorts(); len(ports) > 0 {
+			diff.Importance = comparator.ImportanceCritical
+			impacts = append(impacts, fmt.Sprintf("%s opened to %s", describePorts(ports), rule.Source))
+		}
+		kept = append(kept, diff)
+	}
+	for _, rule := range removed {
+		kept = append(kept, comparator.Difference{
+			Path:       rule.Direction,
+			Type:       comparator.DiffTypeRemoved,
+			Expected:   rule,
+			Message:    fmt.Sprintf("Rule removed: %s", rule),
+			Importance: comparator.ImportanceHigh,
+		})
+	}
+	return kept, impacts
+}
+
+// isRulePath reports whether a comparator path is inside the ingress or egress blocks
+func isRulePath(path string) bool {
+	for _, direction := range []string{"ingress", "egress"} {
+		if path == direction || strings.HasPrefix(path, direction+"[") || strings.HasPrefix(path, direction+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// describePorts names exposed ports, as in "Ports 22 (SSH), 3389 (RDP)"
+func describePorts(ports []int) string {
+	names := make([]string, len(ports))
+	for i, port := range ports {
+		names[i] = fmt.Sprintf("%d (%s)", port, SensitivePorts[port])
+	}
+	if len(names) == 1 {
+		return "Port " + names[0]
+	}
+	return "Ports " + strings.Join(names, ", ")
+}
+
+// normalizeProtocol maps protocol numbers and aliases to the names the EC2 API returns
+func normalizeProtocol(value interface{}) string {
+	protocol := strings.ToLower(fmt.Sprint(value))
+	switch protocol {
+	case "all", "-1", "<nil>", "":
+		return "-1"
+	case "6":
+		return "tcp"
+	case "17":
+		return "udp"
+	case "1":
+		return "icmp"
+	}
+	return protocol
+}
+
+// dedupeRules drops repeated rules, which a set-valued block can decode into
+func dedupeRules(rules []SecurityGroupRule) []SecurityGroupRule {
+	seen := make(map[SecurityGroupRule]bool, len(rules))
+	unique := rules[:0]
+	for _, rule := range rules {
+		if !seen[rule] {
+			seen[rule] = true
+			unique = append(unique, rule)
+		}
+	}
+	return unique
+}
+
+// toList returns a list attribute as []interface{}, accepting the typed slices providers build
+func toList(value interface{}) []interface{} {
+	switch v := value.(type) {
+	case []interface{}:
+		return v
+	case []map[string]interface{}:
+		list := make([]interface{}, len(v))
+		for i, item := range v {
+			list[i] = item
+		}
+		return list
+	case []string:
+		list := make([]interface{}, len(v))
+		for i, item := range v {
+			list[i] = item
+		}
+		return list
+	}
+	return nil
+}
+
+// toInt converts the numeric types state and providers use for ports
+func toInt(value interface{}) int {
+	switch v := value.(type) {
+	case int:
+		return v
+	case int32:
+		return int(v)
+	case int64:
+		return int(v)
+	case float64:
+		return int(v)
+	case string:
+		n, _ := strconv.Atoi(v)
+		return n
+	}
+	return 0
+}
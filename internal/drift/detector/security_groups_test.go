@@ -0,0 +1,93 @@
+package detector
+
+import (
+	"testing"
+
+	"github.com/catherinevee/driftmgr/internal/drift/comparator"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffSecurityGroupRulesIgnoresOrder(t *testing.T) {
+	// State as decoded from JSON: numbers are float64 and one rule lists two CIDRs
+	desired := map[string]interface{}{
+		"ingress": []interface{}{
+			map[string]interface{}{"protocol": "tcp", "from_port": float64(443), "to_port": float64(443),
+				"cidr_blocks": []interface{}{"10.0.0.0/8", "172.16.0.0/12"}},
+			map[string]interface{}{"protocol": "tcp", "from_port": float64(22), "to_port": float64(22),
+				"security_groups": []interface{}{"sg-bastion"}},
+		},
+		"egress": []interface{}{
+			map[string]interface{}{"protocol": "-1", "from_port": float64(0), "to_port": float64(0),
+				"cidr_blocks": []interface{}{"0.0.0.0/0"}},
+		},
+	}
+	// The cloud returns the same rules in another order and grouping, with typed slices
+	actual := map[string]interface{}{
+		"ingress": []map[string]interface{}{
+			{"protocol": "6", "from_port": int32(22), "to_port": int32(22), "security_groups": []string{"sg-bastion"}},
+			{"protocol": "tcp", "from_port": 443, "to_port": 443, "cidr_blocks": []interface{}{"172.16.0.0/12"}},
+			{"protocol": "tcp", "from_port": 443, "to_port": 443, "cidr_blocks": []interface{}{"10.0.0.0/8"}},
+		},
+		"egress": []interface{}{
+			map[string]interface{}{"protocol": "all", "cidr_blocks": []interface{}{"0.0.0.0/0"}},
+		},
+	}
+
+	added, removed := DiffSecurityGroupRules(NormalizeSecurityGroupRules(desired), NormalizeSecurityGroupRules(actual))
+	assert.Empty(t, added)
+	assert.Empty(t, removed)
+
+	comparatorDiffs := []comparator.Difference{
+		{Path: "ingress[0].cidr_blocks[1]", Type: comparator.DiffTypeRemoved},
+		{Path: "tags.Name", Type: comparator.DiffTypeModified},
+	}
+	diffs, impacts := securityGroupDifferences(comparatorDiffs, desired, actual)
+	require.Len(t, diffs, 1, "reordered rules are not drift")
+	assert.Equal(t, "tags.Name", diffs[0].Path)
+	assert.Empty(t, impacts)
+}
+
+func TestSecurityGroupExposure(t *testing.T) {
+	desired := map[string]interface{}{
+		"ingress": []interface{}{
+			map[string]interface{}{"protocol": "tcp", "from_port": 22, "to_port": 22,
+				"cidr_blocks": []interface{}{"10.0.0.0/8"}},
+		},
+	}
+	actual := map[string]interface{}{
+		"ingress": []interface{}{
+			map[string]interface{}{"protocol": "tcp", "from_port": 22, "to_port": 22,
+				"cidr_blocks": []interface{}{"10.0.0.0/8", "0.0.0.0/0"}},
+			map[string]interface{}{"protocol": "tcp", "from_port": 443, "to_port": 443,
+				"ipv6_cidr_blocks": []interface{}{"::/0"}},
+		},
+	}
+
+	diffs, impacts := securityGroupDifferences(nil, desired, actual)
+	require.Len(t, diffs, 2)
+	assert.Equal(t, comparator.DiffTypeAdded, diffs[0].Type)
+	assert.Equal(t, comparator.ImportanceCritical, diffs[0].Importance)
+	assert.Equal(t, "Rule added: ingress tcp 22 from 0.0.0.0/0", diffs[0].Message)
+	assert.Equal(t, comparator.ImportanceHigh, diffs[1].Importance, "443 is not a sensitive port")
+	assert.Equal(t, []string{"Port 22 (SSH) opened to 0.0.0.0/0"}, impacts)
+
+	// Removing the wide rule again is drift but not an exposure
+	diffs, impacts = securityGroupDifferences(nil, actual, desired)
+	assert.Len(t, diffs, 2)
+	assert.Empty(t, impacts)
+}
+
+func TestSecurityGroupRuleExposedPorts(t *testing.T) {
+	all := SecurityGroupRule{Direction: "ingress", Protocol: "-1", Source: "0.0.0.0/0"}
+	assert.Len(t, all.ExposedPorts(), len(SensitivePorts))
+
+	databases := SecurityGroupRule{Direction: "ingress", Protocol: "tcp", FromPort: 3000, ToPort: 6000, Source: "0.0.0.0/0"}
+	assert.Equal(t, []int{3306, 3389, 5432, 5900}, databases.ExposedPorts())
+
+	private := SecurityGroupRule{Direction: "ingress", Protocol: "tcp", FromPort: 22, ToPort: 22, Source: "10.0.0.0/8"}
+	assert.Empty(t, private.ExposedPorts())
+
+	egress := SecurityGroupRule{Direction: "egress", Protocol: "-1", Source: "0.0.0.0/0"}
+	assert.Empty(t, egress.ExposedPorts())
+}
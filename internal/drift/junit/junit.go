@@ -0,0 +1,156 @@
+// Package junit renders drift results as a JUnit XML test report, so CI systems that display
+// JUnit reports show drift in the pipeline's test results. Each resource is a test case that
+// fails when it drifted, and test cases are grouped into a test suite per provider.
+package junit
+
+import (
+	"encoding/xml"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/catherinevee/driftmgr/internal/drift/detector"
+)
+
+// ContentType is the media type of JUnit XML reports
+const ContentType = "application/xml"
+
+var driftTypeNames = map[detector.DriftType]string{
+	detector.ResourceMissing:    "missing",
+	detector.ResourceUnmanaged:  "unmanaged",
+	detector.ConfigurationDrift: "modified",
+	detector.ResourceOrphaned:   "orphaned",
+}
+
+var severityNames = map[detector.DriftSeverity]string{
+	detector.SeverityLow:      "low",
+	detector.SeverityMedium:   "medium",
+	detector.SeverityHigh:     "high",
+	detector.SeverityCritical: "critical",
+}
+
+type testSuites struct {
+	XMLName  xml.Name    `xml:"testsuites"`
+	Name     string      `xml:"name,attr"`
+	Tests    int         `xml:"tests,attr"`
+	Failures int         `xml:"failures,attr"`
+	Time     string      `xml:"time,attr"`
+	Suites   []testSuite `xml:"testsuite"`
+}
+
+type testSuite struct {
+	Name      string     `xml:"name,attr"`
+	Tests     int        `xml:"tests,attr"`
+	Failures  int        `xml:"failures,attr"`
+	Errors    int        `xml:"errors,attr"`
+	Skipped   int        `xml:"skipped,attr"`
+	Time      string     `xml:"time,attr"`
+	Timestamp string     `xml:"timestamp,attr"`
+	Cases     []testCase `xml:"testcase"`
+}
+
+type testCase struct {
+	Name      string   `xml:"name,attr"`
+	ClassName string   `xml:"classname,attr"`
+	Time      string   `xml:"time,attr"`
+	Failure   *failure `xml:"failure,omitempty"`
+}
+
+type failure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Details string `xml:",chardata"`
+}
+
+// Render renders drift results as a JUnit XML report named name. A result without drift is a
+// passing test case of its resource; a drifted one fails with the kind and severity of the
+// drift as its message and the drifted fields, with their expected and actual values, as its
+// details. Suites and test cases are sorted, so reports of the same drift are identical but
+// for the timestamp and duration.
+func Render(name string, results []*detector.DriftResult, timestamp time.Time, duration time.Duration) ([]byte, error) {
+	suites := make(map[string]*testSuite)
+	report := testSuites{Name: name, Time: seconds(duration), Suites: []testSuite{}}
+	for _, result := range results {
+		provider := result.Provider
+		if provider == "" {
+			provider = "unknown"
+		}
+		suite, ok := suites[provider]
+		if !ok {
+			suite = &testSuite{Name: provider, Time: seconds(0), Timestamp: timestamp.UTC().Format("2006-01-02T15:04:05")}
+			suites[provider] = suite
+		}
+
+		test := testCase{Name: result.Resource, ClassName: provider + "." + result.ResourceType, Time: seconds(0)}
+		if result.DriftType != detector.NoDrift {
+			test.Failure = driftFailure(result)
+			suite.Failures++
+			report.Failures++
+		}
+		suite.Cases = append(suite.Cases, test)
+		suite.Tests++
+		report.Tests++
+	}
+
+	providers := make([]string, 0, len(suites))
+	for provider := range suites {
+		providers = append(providers, provider)
+	}
+	sort.Strings(providers)
+	for _, provider := range providers {
+		suite := suites[provider]
+		sort.SliceStable(suite.Cases, func(i, j int) bool {
+			if suite.Cases[i].ClassName != suite.Cases[j].ClassName {
+				return suite.Cases[i].ClassName < suite.Cases[j].ClassName
+			}
+			return suite.Cases[i].Name < suite.Cases[j].Name
+		})
+		report.Suites = append(report.Suites, *suite)
+	}
+
+	data, err := xml.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal JUnit report: %w", err)
+	}
+	return append([]byte(xml.Header), append(data, '\n')...), nil
+}
+
+// driftFailure describes the drift of a resource as a test failure
+func driftFailure(result *detector.DriftResult) *failure {
+	kind := driftTypeNames[result.DriftType]
+	severity := severityNames[result.Severity]
+	message := fmt.Sprintf("%s %s is %s (%s severity)", result.ResourceType, result.Resource, kind, severity)
+	if len(result.Differences) > 0 {
+		message = fmt.Sprintf("%s %s has %d drifted field(s) (%s severity)", result.ResourceType, result.Resource, len(result.Differences), severity)
+	}
+
+	var details strings.Builder
+	for _, difference := range result.Differences {
+		fmt.Fprintf(&details, "%s: expected %s, actual %s", difference.Path, value(difference.Expected), value(difference.Actual))
+		if difference.Message != "" {
+			fmt.Fprintf(&details, " (%s)", difference.Message)
+		}
+		details.WriteString("\n")
+	}
+	for _, impact := range result.Impact {
+		fmt.Fprintf(&details, "Impact: %s\n", impact)
+	}
+	if result.Recommendation != "" {
+		fmt.Fprintf(&details, "Recommendation: %s\n", result.Recommendation)
+	}
+	return &failure{Message: message, Type: kind, Details: details.String()}
+}
+
+// value formats a drifted value for a failure's details
+func value(v interface{}) string {
+	if v == nil {
+		return "<none>"
+	}
+	return fmt.Sprintf("%v", v)
+}
+
+// seconds formats a duration as JUnit's decimal seconds
+func seconds(d time.Duration) string {
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
@@ -0,0 +1,76 @@
+package junit
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/catherinevee/driftmgr/internal/drift/comparator"
+	"github.com/catherinevee/driftmgr/internal/drift/detector"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRender(t *testing.T) {
+	results := []*detector.DriftResult{
+		{Resource: "vm-1", ResourceType: "azurerm_virtual_machine", Provider: "azure", DriftType: detector.NoDrift},
+		{
+			Resource:     "i-123",
+			ResourceType: "aws_instance",
+			Provider:     "aws",
+			DriftType:    detector.ConfigurationDrift,
+			Severity:     detector.SeverityHigh,
+			Differences: []comparator.Difference{
+				{Path: "instance_type", Expected: "t3.micro", Actual: "t3.large"},
+				{Path: "tags.Owner", Expected: "alice", Message: "Field tags.Owner removed"},
+			},
+			Recommendation: "Run terraform apply",
+		},
+		{Resource: "bucket-1", ResourceType: "aws_s3_bucket", Provider: "aws", DriftType: detector.ResourceMissing, Severity: detector.SeverityCritical},
+		{Resource: "i-000", ResourceType: "aws_instance", Provider: "aws", DriftType: detector.NoDrift},
+	}
+
+	data, err := Render("driftmgr", results, time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC), 1500*time.Millisecond)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(data), `<?xml version="1.0" encoding="UTF-8"?>`))
+
+	var report testSuites
+	require.NoError(t, xml.Unmarshal(data, &report))
+	assert.Equal(t, 4, report.Tests)
+	assert.Equal(t, 2, report.Failures)
+	assert.Equal(t, "1.500", report.Time)
+
+	require.Len(t, report.Suites, 2)
+	aws := report.Suites[0]
+	assert.Equal(t, "aws", aws.Name, "suites are sorted by provider")
+	assert.Equal(t, 3, aws.Tests)
+	assert.Equal(t, 2, aws.Failures)
+	assert.Equal(t, "2026-01-02T03:04:05", aws.Timestamp)
+
+	require.Len(t, aws.Cases, 3)
+	assert.Equal(t, "i-000", aws.Cases[0].Name)
+	assert.Nil(t, aws.Cases[0].Failure, "resources without drift pass")
+
+	modified := aws.Cases[1]
+	assert.Equal(t, "aws.aws_instance", modified.ClassName)
+	require.NotNil(t, modified.Failure)
+	assert.Equal(t, "modified", modified.Failure.Type)
+	assert.Equal(t, "aws_instance i-123 has 2 drifted field(s) (high severity)", modified.Failure.Message)
+	assert.Equal(t, "instance_type: expected t3.micro, actual t3.large\n"+
+		"tags.Owner: expected alice, actual <none> (Field tags.Owner removed)\n"+
+		"Recommendation: Run terraform apply\n", modified.Failure.Details)
+
+	missing := aws.Cases[2]
+	assert.Equal(t, "aws_s3_bucket bucket-1 is missing (critical severity)", missing.Failure.Message)
+
+	azure := report.Suites[1]
+	assert.Equal(t, 1, azure.Tests)
+	assert.Zero(t, azure.Failures)
+}
+
+func TestRenderEmpty(t *testing.T) {
+	data, err := Render("driftmgr", nil, time.Now(), 0)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `<testsuites name="driftmgr" tests="0" failures="0" time="0.000"></testsuites>`)
+}
@@ -0,0 +1,211 @@
+package predictor
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/catherinevee/driftmgr/internal/drift/detector"
+	internalModels "github.com/catherinevee/driftmgr/internal/models"
+	"github.com/catherinevee/driftmgr/pkg/models"
+)
+
+// FieldPattern is how often one field of a resource type drifted. Share is the fraction of
+// the type's configuration drifts that touched the field.
+type FieldPattern struct {
+	Field  string  `json:"field"`
+	Drifts int     `json:"drifts"`
+	Share  float64 `json:"share"`
+}
+
+// Pattern is the drift history of one resource type. DriftRate is the fraction of the
+// type's resources that drifted, smoothed so a handful of observations is not taken as
+// certainty.
+type Pattern struct {
+	ResourceType     string         `json:"resource_type"`
+	Resources        int            `json:"resources"`
+	DriftedResources int            `json:"drifted_resources"`
+	Drifts           int            `json:"drifts"`
+	DriftRate        float64        `json:"drift_rate"`
+	Fields           []FieldPattern `json:"fields"`
+}
+
+// Model is a drift predictor trained from drift history
+type Model struct {
+	TrainedAt time.Time `json:"trained_at"`
+	Drifts    int       `json:"drifts"`
+	Patterns  []Pattern `json:"patterns"`
+
+	byType map[string]*Pattern
+}
+
+// Prediction is the likelihood that a resource drifts, with the fields it is most likely to
+// drift on. Window is set when the likelihood comes from a registered pattern with a time
+// window, and Patterns lists the IDs of the registered patterns the resource matched.
+type Prediction struct {
+	ResourceID   string   `json:"resource_id"`
+	ResourceType string   `json:"resource_type"`
+	Provider     string   `json:"provider"`
+	Confidence   float64  `json:"confidence"`
+	Window       string   `json:"window,omitempty"`
+	Fields       []string `json:"fields,omitempty"`
+	Patterns     []string `json:"patterns,omitempty"`
+}
+
+// maxPredictedFields caps the fields listed for each prediction
+const maxPredictedFields = 3
+
+// Train learns which resource types and fields drift most often from past drift results.
+// inventory is the resources that could have drifted, so a type's drift rate is measured
+// against how many of its resources there are. Drift results name resources by address or
+// ID, so a type has at least as many resources as drifted, not the union of both.
+func Train(history []*detector.DriftResult, inventory []models.Resource) *Model {
+	type observed struct {
+		resources map[string]bool
+		drifted   map[string]bool
+		drifts    int
+		fields    map[string]int
+		changes   int
+	}
+	types := make(map[string]*observed)
+	typeOf := func(resourceType string) *observed {
+		o, ok := types[resourceType]
+		if !ok {
+			o = &observed{resources: map[string]bool{}, drifted: map[string]bool{}, fields: map[string]int{}}
+			types[resourceType] = o
+		}
+		return o
+	}
+
+	for _, resource := range inventory {
+		typeOf(resource.Type).resources[resource.ID] = true
+	}
+
+	model := &Model{TrainedAt: time.Now(), Patterns: []Pattern{}, byType: make(map[string]*Pattern)}
+	for _, drift := range history {
+		if drift == nil || drift.DriftType == detector.NoDrift {
+			continue
+		}
+		model.Drifts++
+		o := typeOf(drift.ResourceType)
+		o.drifted[drift.Resource] = true
+		o.drifts++
+		for _, difference := range drift.Differences {
+			if field := topLevelField(difference.Path); field != "" {
+				o.fields[field]++
+				o.changes++
+			}
+		}
+	}
+
+	for resourceType, o := range types {
+		if len(o.drifted) == 0 {
+			continue
+		}
+		resources := len(o.resources)
+		if resources < len(o.drifted) {
+			resources = len(o.drifted)
+		}
+		pattern := Pattern{
+			ResourceType:     resourceType,
+			Resources:        resources,
+			DriftedResources: len(o.drifted),
+			Drifts:           o.drifts,
+			DriftRate:        float64(len(o.drifted)+1) / float64(resources+2),
+			Fields:           []FieldPattern{},
+		}
+		for field, drifts := range o.fields {
+			pattern.Fields = append(pattern.Fields, FieldPattern{
+				Field:  field,
+				Drifts: drifts,
+				Share:  float64(drifts) / float64(o.changes),
+			})
+		}
+		sort.Slice(pattern.Fields, func(i, j int) bool {
+			if pattern.Fields[i].Drifts != pattern.Fields[j].Drifts {
+				return pattern.Fields[i].Drifts > pattern.Fields[j].Drifts
+			}
+			return pattern.Fields[i].Field < pattern.Fields[j].Field
+		})
+		model.Patterns = append(model.Patterns, pattern)
+	}
+	sort.Slice(model.Patterns, func(i, j int) bool {
+		if model.Patterns[i].DriftRate != model.Patterns[j].DriftRate {
+			return model.Patterns[i].DriftRate > model.Patterns[j].DriftRate
+		}
+		return model.Patterns[i].ResourceType < model.Patterns[j].ResourceType
+	})
+	for i := range model.Patterns {
+		model.byType[model.Patterns[i].ResourceType] = &model.Patterns[i]
+	}
+	return model
+}
+
+// Predict returns the resources whose type has drifted before or matches a registered drift
+// pattern, most likely to drift first. A resource's confidence is the higher of its type's
+// drift rate and the likelihood of its patterns, and the fields of its patterns come first.
+func (m *Model) Predict(resources []models.Resource, patterns []*internalModels.DriftPattern) []Prediction {
+	registered := make(map[string][]*internalModels.DriftPattern)
+	for _, pattern := range patterns {
+		registered[pattern.ResourceType] = append(registered[pattern.ResourceType], pattern)
+	}
+	for _, matching := range registered {
+		sort.SliceStable(matching, func(i, j int) bool { return matching[i].Likelihood > matching[j].Likelihood })
+	}
+
+	predictions := []Prediction{}
+	for _, resource := range resources {
+		learned, ok := m.byType[resource.Type]
+		matching := registered[resource.Type]
+		if !ok && len(matching) == 0 {
+			continue
+		}
+		prediction := Prediction{
+			ResourceID:   resource.ID,
+			ResourceType: resource.Type,
+			Provider:     resource.Provider,
+		}
+		var fields []string
+		for _, pattern := range matching {
+			prediction.Patterns = append(prediction.Patterns, pattern.ID)
+			fields = append(fields, pattern.Field)
+			if pattern.Likelihood > prediction.Confidence {
+				prediction.Confidence = pattern.Likelihood
+				prediction.Window = pattern.Window
+			}
+		}
+		if ok {
+			if learned.DriftRate > prediction.Confidence {
+				prediction.Confidence = learned.DriftRate
+				prediction.Window = ""
+			}
+			for _, field := range learned.Fields {
+				fields = append(fields, field.Field)
+			}
+		}
+		seen := make(map[string]bool)
+		for _, field := range fields {
+			if len(prediction.Fields) == maxPredictedFields {
+				break
+			}
+			if !seen[field] {
+				seen[field] = true
+				prediction.Fields = append(prediction.Fields, field)
+			}
+		}
+		predictions = append(predictions, prediction)
+	}
+	sort.SliceStable(predictions, func(i, j int) bool {
+		return predictions[i].Confidence > predictions[j].Confidence
+	})
+	return predictions
+}
+
+// topLevelField returns the attribute a difference path starts with, so tags.env and
+// ingress[0].cidr_blocks count as drift of tags and ingress
+func topLevelField(path string) string {
+	if i := strings.IndexAny(path, ".["); i >= 0 {
+		return path[:i]
+	}
+	return path
+}
@@ -0,0 +1,92 @@
+package predictor
+
+import (
+	"testing"
+
+	"github.com/catherinevee/driftmgr/internal/drift/comparator"
+	"github.com/catherinevee/driftmgr/internal/drift/detector"
+	internalModels "github.com/catherinevee/driftmgr/internal/models"
+	"github.com/catherinevee/driftmgr/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTrainAndPredict(t *testing.T) {
+	history := []*detector.DriftResult{
+		{Resource: "sg-1", ResourceType: "aws_security_group", DriftType: detector.ConfigurationDrift, Differences: []comparator.Difference{
+			{Path: "ingress[0].cidr_blocks"}, {Path: "tags.owner"},
+		}},
+		{Resource: "sg-1", ResourceType: "aws_security_group", DriftType: detector.ConfigurationDrift, Differences: []comparator.Difference{
+			{Path: "ingress[1].from_port"},
+		}},
+		{Resource: "sg-2", ResourceType: "aws_security_group", DriftType: detector.ConfigurationDrift, Differences: []comparator.Difference{
+			{Path: "ingress"},
+		}},
+		{Resource: "i-1", ResourceType: "aws_instance", DriftType: detector.ResourceMissing},
+		{Resource: "vpc-1", ResourceType: "aws_vpc", DriftType: detector.NoDrift},
+	}
+	inventory := []models.Resource{
+		{ID: "sg-1", Type: "aws_security_group"}, {ID: "sg-2", Type: "aws_security_group"},
+		{ID: "i-1", Type: "aws_instance"}, {ID: "i-2", Type: "aws_instance"}, {ID: "i-3", Type: "aws_instance"}, {ID: "i-4", Type: "aws_instance"},
+		{ID: "vpc-1", Type: "aws_vpc"},
+	}
+
+	model := Train(history, inventory)
+	assert.Equal(t, 4, model.Drifts)
+	require.Len(t, model.Patterns, 2, "types that never drifted have no pattern")
+
+	groups := model.Patterns[0]
+	assert.Equal(t, "aws_security_group", groups.ResourceType)
+	assert.Equal(t, 2, groups.Resources)
+	assert.Equal(t, 2, groups.DriftedResources)
+	assert.Equal(t, 3, groups.Drifts)
+	assert.InDelta(t, 0.75, groups.DriftRate, 0.001)
+	assert.Equal(t, []FieldPattern{{Field: "ingress", Drifts: 3, Share: 0.75}, {Field: "tags", Drifts: 1, Share: 0.25}}, groups.Fields)
+
+	instances := model.Patterns[1]
+	assert.InDelta(t, 2.0/6.0, instances.DriftRate, 0.001)
+	assert.Empty(t, instances.Fields)
+
+	predictions := model.Predict([]models.Resource{
+		{ID: "i-9", Type: "aws_instance", Provider: "aws"},
+		{ID: "vpc-9", Type: "aws_vpc", Provider: "aws"},
+		{ID: "sg-9", Type: "aws_security_group", Provider: "aws"},
+	}, nil)
+	require.Len(t, predictions, 2)
+	assert.Equal(t, Prediction{
+		ResourceID: "sg-9", ResourceType: "aws_security_group", Provider: "aws",
+		Confidence: groups.DriftRate, Fields: []string{"ingress", "tags"},
+	}, predictions[0])
+	assert.Equal(t, "i-9", predictions[1].ResourceID)
+}
+
+func TestPredictWithPatterns(t *testing.T) {
+	model := Train([]*detector.DriftResult{
+		{Resource: "asg-1", ResourceType: "aws_autoscaling_group", DriftType: detector.ConfigurationDrift, Differences: []comparator.Difference{
+			{Path: "max_size"},
+		}},
+	}, []models.Resource{{ID: "asg-1", Type: "aws_autoscaling_group"}, {ID: "asg-2", Type: "aws_autoscaling_group"}})
+
+	patterns := []*internalModels.DriftPattern{
+		{ID: "resized", ResourceType: "aws_autoscaling_group", Field: "desired_capacity", Likelihood: 0.9, Window: "24h"},
+		{ID: "tagged", ResourceType: "aws_lambda_function", Field: "tags", Likelihood: 0.2},
+	}
+	predictions := model.Predict([]models.Resource{
+		{ID: "fn-1", Type: "aws_lambda_function"},
+		{ID: "asg-3", Type: "aws_autoscaling_group"},
+		{ID: "vpc-1", Type: "aws_vpc"},
+	}, patterns)
+
+	require.Len(t, predictions, 2, "patterns predict types with no drift history")
+	assert.Equal(t, Prediction{
+		ResourceID: "asg-3", ResourceType: "aws_autoscaling_group", Confidence: 0.9, Window: "24h",
+		Fields: []string{"desired_capacity", "max_size"}, Patterns: []string{"resized"},
+	}, predictions[0])
+	assert.Equal(t, Prediction{
+		ResourceID: "fn-1", ResourceType: "aws_lambda_function", Confidence: 0.2,
+		Fields: []string{"tags"}, Patterns: []string{"tagged"},
+	}, predictions[1])
+
+	untrained := &Model{}
+	assert.Len(t, untrained.Predict([]models.Resource{{ID: "fn-1", Type: "aws_lambda_function"}}, patterns), 1)
+}
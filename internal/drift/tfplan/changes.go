@@ -0,0 +1,166 @@
+package tfplan
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/catherinevee/driftmgr/pkg/models"
+)
+
+// ErrBinaryPlan is returned for a plan file saved by terraform plan -out. Reading one needs
+// Terraform and the providers that made it; terraform show -json converts it to JSON.
+var ErrBinaryPlan = errors.New("binary plan files cannot be read without Terraform; use the output of terraform show -json <plan>")
+
+// PlanChanges is what a saved plan would change, with each resource change as a drift result
+type PlanChanges struct {
+	TerraformVersion string               `json:"terraform_version,omitempty"`
+	Summary          PlanSummary          `json:"summary"`
+	Changes          []models.DriftResult `json:"changes"`
+}
+
+// PlanSummary counts the resource changes of a plan by action
+type PlanSummary struct {
+	Create    int `json:"create"`
+	Update    int `json:"update"`
+	Replace   int `json:"replace"`
+	Delete    int `json:"delete"`
+	Unchanged int `json:"unchanged"`
+}
+
+// ParseChanges reads the resource changes of a plan from the output of terraform show -json.
+// Each create, update, replace and delete becomes a drift result of type planned_<action>;
+// updates and replacements list the attributes that change, and the attributes that force a
+// replacement say so.
+func ParseChanges(planJSON []byte) (*PlanChanges, error) {
+	if bytes.HasPrefix(planJSON, []byte("PK\x03\x04")) {
+		return nil, ErrBinaryPlan
+	}
+	var plan struct {
+		TerraformVersion string          `json:"terraform_version"`
+		PlannedValues    json.RawMessage `json:"planned_values"`
+		ResourceChanges  []planResource  `json:"resource_changes"`
+	}
+	if err := json.Unmarshal(planJSON, &plan); err != nil {
+		return nil, fmt.Errorf("failed to read terraform plan: %w", err)
+	}
+	if plan.PlannedValues == nil && plan.ResourceChanges == nil {
+		return nil, errors.New("not a Terraform plan: no planned_values or resource_changes")
+	}
+
+	changes := &PlanChanges{TerraformVersion: plan.TerraformVersion, Changes: []models.DriftResult{}}
+	detectedAt := time.Now().UTC()
+	for _, resource := range plan.ResourceChanges {
+		if resource.Mode == "data" {
+			continue
+		}
+		action := plannedAction(resource.Change.Actions)
+		switch action {
+		case "create":
+			changes.Summary.Create++
+		case "update":
+			changes.Summary.Update++
+		case "replace":
+			changes.Summary.Replace++
+		case "delete":
+			changes.Summary.Delete++
+		default:
+			changes.Summary.Unchanged++
+			continue
+		}
+		changes.Changes = append(changes.Changes, plannedChange(resource, action, detectedAt))
+	}
+	return changes, nil
+}
+
+// plannedAction names the action of a resource change: create, update, replace, delete, or ""
+// for no-op and read
+func plannedAction(actions []string) string {
+	switch {
+	case contains(actions, "delete") && contains(actions, "create"):
+		return "replace"
+	case contains(actions, "create"):
+		return "create"
+	case contains(actions, "update"):
+		return "update"
+	case contains(actions, "delete"):
+		return "delete"
+	default:
+		return ""
+	}
+}
+
+// plannedChange maps a resource change to a drift result. Deleting or replacing a resource is
+// high severity, critical for databases and identities, whose data or grants are lost.
+func plannedChange(resource planResource, action string, detectedAt time.Time) models.DriftResult {
+	change := resource.Change
+	drift := models.DriftResult{
+		ResourceID:   resource.Address,
+		ResourceName: resource.Name,
+		ResourceType: resource.Type,
+		Provider:     providerName(resource.ProviderName),
+		Region:       region(change.After, change.Before),
+		DriftType:    "planned_" + action,
+		DetectedAt:   detectedAt,
+		Metadata:     map[string]string{"address": resource.Address, "action": action, "source": "terraform_plan_file"},
+	}
+	if resource.ModuleAddress != "" {
+		drift.Metadata["module"] = resource.ModuleAddress
+	}
+	if resource.ActionReason != "" {
+		drift.Metadata["action_reason"] = resource.ActionReason
+	}
+	category := models.CategorizeResourceType(resource.Type)
+	stateful := category == models.CategoryDatabase || category == models.CategoryIdentity
+
+	switch action {
+	case "create":
+		drift.Severity = "low"
+		drift.Description = fmt.Sprintf("%s will be created", resource.Address)
+	case "delete":
+		drift.Severity = "high"
+		drift.Description = fmt.Sprintf("%s will be destroyed", resource.Address)
+	default:
+		drift.Changes = diffValues("", change.Before, change.After, change.BeforeSensitive, change.AfterSensitive, change.AfterUnknown)
+		forces := make(map[string]bool)
+		for _, replacePath := range change.ReplacePaths {
+			forces[formatPath(replacePath)] = true
+		}
+		for i := range drift.Changes {
+			if forces[drift.Changes[i].Field] {
+				drift.Changes[i].Description = "forces replacement"
+			}
+		}
+		if action == "replace" {
+			drift.Severity = "high"
+			drift.Description = fmt.Sprintf("%s will be replaced", resource.Address)
+		} else {
+			drift.Severity = "medium"
+			if stateful {
+				drift.Severity = "high"
+			}
+			drift.Description = fmt.Sprintf("%d attribute(s) of %s will be updated in-place", len(drift.Changes), resource.Address)
+		}
+	}
+	if stateful && (action == "delete" || action == "replace") {
+		drift.Severity = "critical"
+	}
+	return drift
+}
+
+// formatPath renders a replace_paths entry, such as ["network_interface", 0, "subnet_id"], as
+// the field path diffValues reports, network_interface[0].subnet_id
+func formatPath(segments []interface{}) string {
+	path := ""
+	for _, segment := range segments {
+		switch segment := segment.(type) {
+		case string:
+			path = joinPath(path, segment)
+		case float64:
+			path = fmt.Sprintf("%s[%d]", path, int(segment))
+		}
+	}
+	return path
+}
@@ -0,0 +1,82 @@
+package tfplan
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const changesPlanJSON = `{"format_version": "1.2", "terraform_version": "1.9.5", "planned_values": {}, "resource_changes": [
+	{"address": "aws_instance.web", "mode": "managed", "type": "aws_instance", "name": "web",
+	 "provider_name": "registry.terraform.io/hashicorp/aws", "action_reason": "replace_because_cannot_update",
+	 "change": {"actions": ["delete", "create"],
+	  "before": {"ami": "ami-1", "id": "i-123", "network_interface": [{"subnet_id": "subnet-1"}], "region": "eu-west-1"},
+	  "after": {"ami": "ami-2", "network_interface": [{"subnet_id": "subnet-2"}], "region": "eu-west-1"},
+	  "after_unknown": {"id": true, "network_interface": [{}]},
+	  "replace_paths": [["ami"], ["network_interface", 0, "subnet_id"]]}},
+	{"address": "aws_s3_bucket.logs", "mode": "managed", "type": "aws_s3_bucket", "name": "logs",
+	 "provider_name": "registry.terraform.io/hashicorp/aws",
+	 "change": {"actions": ["update"], "before": {"tags": {"Env": "dev"}}, "after": {"tags": {"Env": "prod"}}}},
+	{"address": "aws_db_instance.main", "mode": "managed", "type": "aws_db_instance", "name": "main",
+	 "provider_name": "registry.terraform.io/hashicorp/aws",
+	 "change": {"actions": ["delete"], "before": {"identifier": "main"}, "after": null}},
+	{"address": "aws_sqs_queue.jobs", "mode": "managed", "type": "aws_sqs_queue", "name": "jobs",
+	 "provider_name": "registry.terraform.io/hashicorp/aws",
+	 "change": {"actions": ["create"], "before": null, "after": {"name": "jobs"}, "after_unknown": {"arn": true}}},
+	{"address": "aws_vpc.main", "mode": "managed", "type": "aws_vpc", "name": "main",
+	 "provider_name": "registry.terraform.io/hashicorp/aws",
+	 "change": {"actions": ["no-op"], "before": {}, "after": {}}},
+	{"address": "data.aws_ami.latest", "mode": "data", "type": "aws_ami", "name": "latest",
+	 "provider_name": "registry.terraform.io/hashicorp/aws", "change": {"actions": ["read"]}}
+]}`
+
+func TestParseChanges(t *testing.T) {
+	changes, err := ParseChanges([]byte(changesPlanJSON))
+	require.NoError(t, err)
+
+	assert.Equal(t, "1.9.5", changes.TerraformVersion)
+	assert.Equal(t, PlanSummary{Create: 1, Update: 1, Replace: 1, Delete: 1, Unchanged: 1}, changes.Summary)
+	require.Len(t, changes.Changes, 4)
+
+	replaced := changes.Changes[0]
+	assert.Equal(t, "planned_replace", replaced.DriftType)
+	assert.Equal(t, "high", replaced.Severity)
+	assert.Equal(t, "eu-west-1", replaced.Region)
+	assert.Equal(t, "replace_because_cannot_update", replaced.Metadata["action_reason"])
+	byField := make(map[string]interface{})
+	for _, change := range replaced.Changes {
+		byField[change.Field] = change.NewValue
+		if change.Field == "ami" || change.Field == "network_interface[0].subnet_id" {
+			assert.Equal(t, "forces replacement", change.Description, change.Field)
+		}
+	}
+	assert.Equal(t, map[string]interface{}{
+		"ami":                            "ami-2",
+		"id":                             unknownValue,
+		"network_interface[0].subnet_id": "subnet-2",
+	}, byField)
+
+	updated := changes.Changes[1]
+	assert.Equal(t, "planned_update", updated.DriftType)
+	assert.Equal(t, "medium", updated.Severity)
+	require.Len(t, updated.Changes, 1)
+	assert.Equal(t, "tags.Env", updated.Changes[0].Field)
+
+	deleted := changes.Changes[2]
+	assert.Equal(t, "planned_delete", deleted.DriftType)
+	assert.Equal(t, "critical", deleted.Severity, "destroying a database loses its data")
+
+	created := changes.Changes[3]
+	assert.Equal(t, "planned_create", created.DriftType)
+	assert.Equal(t, "low", created.Severity)
+	assert.Empty(t, created.Changes)
+}
+
+func TestParseChangesRejectsOtherInput(t *testing.T) {
+	_, err := ParseChanges([]byte("PK\x03\x04binary"))
+	assert.ErrorIs(t, err, ErrBinaryPlan)
+
+	_, err = ParseChanges([]byte(`{"version": 4, "resources": []}`))
+	assert.ErrorContains(t, err, "not a Terraform plan")
+}
@@ -0,0 +1,405 @@
+// Package tfplan reads drift from Terraform plans. A refresh-only plan refreshes every
+// resource Terraform manages and reports what changed outside of it, which is authoritative
+// for Terraform-managed resources where property comparison is a heuristic. A saved plan's
+// resource changes report what applying it would change.
+package tfplan
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/catherinevee/driftmgr/internal/state"
+	"github.com/catherinevee/driftmgr/pkg/models"
+)
+
+const (
+	// sensitiveValue replaces the values of sensitive attributes in drift changes
+	sensitiveValue = "(sensitive)"
+	// unknownValue stands in for planned values Terraform only learns when applying
+	unknownValue = "(known after apply)"
+)
+
+// Options selects the working directory a refresh-only plan runs in and how it is
+// initialized. Files are relative to WorkDir.
+type Options struct {
+	WorkDir string
+
+	// BackendConfig and BackendConfigFiles are passed to terraform init as -backend-config.
+	// Init runs with -reconfigure when either is set, and otherwise only when the working
+	// directory was never initialized.
+	BackendConfig      map[string]string
+	BackendConfigFiles []string
+
+	// VarFiles are passed to terraform plan as -var-file
+	VarFiles []string
+
+	// Terraform is the binary to run; state.TerraformBinary() when empty
+	Terraform string
+}
+
+// Result is the drift a refresh-only plan found. Warnings are the plan's warning diagnostics.
+type Result struct {
+	WorkDir          string               `json:"work_dir"`
+	TerraformVersion string               `json:"terraform_version,omitempty"`
+	Drifted          int                  `json:"drifted"`
+	Drifts           []models.DriftResult `json:"drifts"`
+	Warnings         []string             `json:"warnings,omitempty"`
+	DetectedAt       time.Time            `json:"detected_at"`
+}
+
+// Detect initializes the working directory when needed, runs terraform plan -refresh-only
+// and returns the resources the plan reports changed or deleted outside of Terraform
+func Detect(ctx context.Context, options Options) (*Result, error) {
+	terraform := options.Terraform
+	if terraform == "" {
+		terraform = state.TerraformBinary()
+	}
+
+	if err := initialize(ctx, terraform, options); err != nil {
+		return nil, err
+	}
+
+	planFile, err := os.CreateTemp("", "driftmgr-*.tfplan")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create plan file: %w", err)
+	}
+	planFile.Close()
+	defer os.Remove(planFile.Name())
+
+	args := []string{"plan", "-refresh-only", "-input=false", "-lock=false", "-json", "-out=" + planFile.Name()}
+	for _, file := range options.VarFiles {
+		args = append(args, "-var-file="+file)
+	}
+	cmd := exec.CommandContext(ctx, terraform, args...)
+	cmd.Dir = options.WorkDir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	output, runErr := cmd.Output()
+	errs, warnings := parseDiagnostics(output)
+	if runErr != nil {
+		if len(errs) == 0 {
+			errs = []string{strings.TrimSpace(stderr.String())}
+		}
+		return nil, fmt.Errorf("terraform plan failed: %w: %s", runErr, strings.Join(errs, "; "))
+	}
+
+	show := exec.CommandContext(ctx, terraform, "show", "-json", planFile.Name())
+	show.Dir = options.WorkDir
+	planJSON, err := show.Output()
+	if err != nil {
+		return nil, fmt.Errorf("terraform show failed: %w", err)
+	}
+
+	result, err := ParsePlan(planJSON)
+	if err != nil {
+		return nil, err
+	}
+	result.WorkDir = options.WorkDir
+	result.Warnings = warnings
+	return result, nil
+}
+
+// initialize runs terraform init with the backend configuration, or when the working
+// directory has no .terraform directory
+func initialize(ctx context.Context, terraform string, options Options) error {
+	reconfigure := len(options.BackendConfig) > 0 || len(options.BackendConfigFiles) > 0
+	if !reconfigure {
+		if _, err := os.Stat(filepath.Join(options.WorkDir, ".terraform")); err == nil {
+			return nil
+		}
+	}
+
+	args := []string{"init", "-input=false", "-no-color"}
+	if reconfigure {
+		args = append(args, "-reconfigure")
+	}
+	for _, file := range options.BackendConfigFiles {
+		args = append(args, "-backend-config="+file)
+	}
+	keys := make([]string, 0, len(options.BackendConfig))
+	for key := range options.BackendConfig {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		args = append(args, "-backend-config="+key+"="+options.BackendConfig[key])
+	}
+
+	cmd := exec.CommandContext(ctx, terraform, args...)
+	cmd.Dir = options.WorkDir
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("terraform init failed: %w: %s", err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// parseDiagnostics returns the summaries of the error and warning diagnostics in the
+// machine-readable output of terraform plan -json
+func parseDiagnostics(output []byte) (errs, warnings []string) {
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		var message struct {
+			Type       string `json:"type"`
+			Diagnostic struct {
+				Severity string `json:"severity"`
+				Summary  string `json:"summary"`
+				Detail   string `json:"detail"`
+				Address  string `json:"address"`
+			} `json:"diagnostic"`
+		}
+		if json.Unmarshal(scanner.Bytes(), &message) != nil || message.Type != "diagnostic" {
+			continue
+		}
+		diagnostic := message.Diagnostic
+		text := diagnostic.Summary
+		if diagnostic.Address != "" {
+			text = diagnostic.Address + ": " + text
+		}
+		if diagnostic.Detail != "" {
+			text += ": " + diagnostic.Detail
+		}
+		if diagnostic.Severity == "error" {
+			errs = append(errs, text)
+		} else {
+			warnings = append(warnings, text)
+		}
+	}
+	return errs, warnings
+}
+
+// planResource is a resource_drift or resource_changes entry of a JSON plan
+type planResource struct {
+	Address       string `json:"address"`
+	ModuleAddress string `json:"module_address"`
+	Mode          string `json:"mode"`
+	Type          string `json:"type"`
+	Name          string `json:"name"`
+	ProviderName  string `json:"provider_name"`
+	Change        struct {
+		Actions         []string        `json:"actions"`
+		Before          interface{}     `json:"before"`
+		After           interface{}     `json:"after"`
+		BeforeSensitive interface{}     `json:"before_sensitive"`
+		AfterSensitive  interface{}     `json:"after_sensitive"`
+		AfterUnknown    interface{}     `json:"after_unknown"`
+		ReplacePaths    [][]interface{} `json:"replace_paths"`
+	} `json:"change"`
+	ActionReason string `json:"action_reason"`
+}
+
+// ParsePlan reads the drift of a refresh-only plan from terraform show -json: one drift
+// result per resource in resource_drift, with the attributes that changed outside of
+// Terraform, or with no changes for resources deleted outside of it
+func ParsePlan(planJSON []byte) (*Result, error) {
+	var plan struct {
+		TerraformVersion string         `json:"terraform_version"`
+		ResourceDrift    []planResource `json:"resource_drift"`
+	}
+	if err := json.Unmarshal(planJSON, &plan); err != nil {
+		return nil, fmt.Errorf("failed to read terraform plan: %w", err)
+	}
+
+	result := &Result{
+		TerraformVersion: plan.TerraformVersion,
+		Drifts:           []models.DriftResult{},
+		DetectedAt:       time.Now().UTC(),
+	}
+	for _, resource := range plan.ResourceDrift {
+		if resource.Mode == "data" {
+			continue
+		}
+		drift, ok := driftResult(resource, result.DetectedAt)
+		if ok {
+			result.Drifts = append(result.Drifts, drift)
+		}
+	}
+	result.Drifted = len(result.Drifts)
+	return result, nil
+}
+
+// driftResult maps a resource_drift entry to a drift result; no-op entries are not drift
+func driftResult(resource planResource, detectedAt time.Time) (models.DriftResult, bool) {
+	change := resource.Change
+	drift := models.DriftResult{
+		ResourceID:   resource.Address,
+		ResourceName: resource.Name,
+		ResourceType: resource.Type,
+		Provider:     providerName(resource.ProviderName),
+		Region:       region(change.After, change.Before),
+		DetectedAt:   detectedAt,
+		Metadata:     map[string]string{"address": resource.Address, "source": "terraform_plan"},
+	}
+	if resource.ModuleAddress != "" {
+		drift.Metadata["module"] = resource.ModuleAddress
+	}
+
+	switch {
+	case contains(change.Actions, "delete"):
+		drift.DriftType = "resource_deletion"
+		drift.Severity = "high"
+		drift.Description = fmt.Sprintf("%s was deleted outside of Terraform", resource.Address)
+	case contains(change.Actions, "update"):
+		drift.DriftType = "attribute_change"
+		drift.Changes = diffValues("", change.Before, change.After, change.BeforeSensitive, change.AfterSensitive, nil)
+		if len(drift.Changes) == 0 {
+			return drift, false
+		}
+		drift.Severity = "medium"
+		switch models.CategorizeResourceType(resource.Type) {
+		case models.CategoryIdentity, models.CategoryDatabase:
+			drift.Severity = "high"
+		}
+		drift.Description = fmt.Sprintf("%d attribute(s) of %s changed outside of Terraform", len(drift.Changes), resource.Address)
+	default:
+		return drift, false
+	}
+	return drift, true
+}
+
+// diffValues lists the attributes that differ between a resource's before and after values,
+// descending into objects and lists. Sensitive values are redacted, and values afterUnknown
+// marks are reported as known after apply.
+func diffValues(path string, before, after, beforeSensitive, afterSensitive, afterUnknown interface{}) []models.DriftChange {
+	if marked(beforeSensitive) || marked(afterSensitive) {
+		if jsonEqual(before, after) {
+			return nil
+		}
+		return []models.DriftChange{{Field: path, OldValue: sensitiveValue, NewValue: sensitiveValue, ChangeType: "update",
+			Description: "sensitive value changed"}}
+	}
+	if marked(afterUnknown) {
+		change := models.DriftChange{Field: path, OldValue: before, NewValue: unknownValue, ChangeType: "update"}
+		if before == nil {
+			change.ChangeType = "create"
+		}
+		return []models.DriftChange{change}
+	}
+
+	beforeMap, beforeIsMap := before.(map[string]interface{})
+	afterMap, afterIsMap := after.(map[string]interface{})
+	if beforeIsMap && afterIsMap {
+		keys := make(map[string]bool)
+		for key := range beforeMap {
+			keys[key] = true
+		}
+		for key := range afterMap {
+			keys[key] = true
+		}
+		sorted := make([]string, 0, len(keys))
+		for key := range keys {
+			sorted = append(sorted, key)
+		}
+		sort.Strings(sorted)
+		var changes []models.DriftChange
+		for _, key := range sorted {
+			changes = append(changes, diffValues(joinPath(path, key), beforeMap[key], afterMap[key],
+				child(beforeSensitive, key), child(afterSensitive, key), child(afterUnknown, key))...)
+		}
+		return changes
+	}
+
+	beforeList, beforeIsList := before.([]interface{})
+	afterList, afterIsList := after.([]interface{})
+	if beforeIsList && afterIsList {
+		var changes []models.DriftChange
+		for i := 0; i < max(len(beforeList), len(afterList)); i++ {
+			var beforeItem, afterItem interface{}
+			if i < len(beforeList) {
+				beforeItem = beforeList[i]
+			}
+			if i < len(afterList) {
+				afterItem = afterList[i]
+			}
+			changes = append(changes, diffValues(fmt.Sprintf("%s[%d]", path, i), beforeItem, afterItem,
+				child(beforeSensitive, i), child(afterSensitive, i), child(afterUnknown, i))...)
+		}
+		return changes
+	}
+
+	if jsonEqual(before, after) {
+		return nil
+	}
+	change := models.DriftChange{Field: path, OldValue: before, NewValue: after, ChangeType: "update"}
+	switch {
+	case before == nil:
+		change.ChangeType = "create"
+	case after == nil:
+		change.ChangeType = "delete"
+	}
+	return []models.DriftChange{change}
+}
+
+// marked reports whether a sensitivity or unknown marker marks a whole value
+func marked(marker interface{}) bool {
+	whole, _ := marker.(bool)
+	return whole
+}
+
+// child returns the marker of an object attribute or list element
+func child(marker interface{}, key interface{}) interface{} {
+	switch marker := marker.(type) {
+	case map[string]interface{}:
+		if name, ok := key.(string); ok {
+			return marker[name]
+		}
+	case []interface{}:
+		if index, ok := key.(int); ok && index < len(marker) {
+			return marker[index]
+		}
+	}
+	return nil
+}
+
+// joinPath appends an attribute to a dotted path
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// jsonEqual compares two decoded JSON values
+func jsonEqual(a, b interface{}) bool {
+	left, errLeft := json.Marshal(a)
+	right, errRight := json.Marshal(b)
+	return errLeft == nil && errRight == nil && bytes.Equal(left, right)
+}
+
+// providerName returns the short name of a provider source address, such as aws for
+// registry.terraform.io/hashicorp/aws
+func providerName(source string) string {
+	return source[strings.LastIndex(source, "/")+1:]
+}
+
+// region returns the region or location attribute of the first value that has one
+func region(values ...interface{}) string {
+	for _, value := range values {
+		attributes, _ := value.(map[string]interface{})
+		for _, key := range []string{"region", "location", "zone"} {
+			if region, ok := attributes[key].(string); ok && region != "" {
+				return region
+			}
+		}
+	}
+	return ""
+}
+
+// contains reports whether actions includes action
+func contains(actions []string, action string) bool {
+	for _, candidate := range actions {
+		if candidate == action {
+			return true
+		}
+	}
+	return false
+}
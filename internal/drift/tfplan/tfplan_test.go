@@ -0,0 +1,130 @@
+package tfplan
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeTerraform writes a terraform stand-in that logs its arguments to terraform.log in the
+// working directory, prints planOutput for plan and exits with planStatus, and answers
+// show -json with planJSON
+func fakeTerraform(t *testing.T, planOutput string, planStatus int, planJSON string) (terraformPath, workDir string) {
+	t.Helper()
+	dir := t.TempDir()
+	workDir = t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "plan.out"), []byte(planOutput), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "plan.json"), []byte(planJSON), 0644))
+	script := "#!/bin/sh\necho \"$@\" >> terraform.log\n" +
+		"if [ \"$1\" = plan ]; then cat " + filepath.Join(dir, "plan.out") + "; exit " + strconv.Itoa(planStatus) + "; fi\n" +
+		"if [ \"$1\" = show ]; then cat " + filepath.Join(dir, "plan.json") + "; fi\n"
+	terraformPath = filepath.Join(dir, "terraform")
+	require.NoError(t, os.WriteFile(terraformPath, []byte(script), 0755))
+	return terraformPath, workDir
+}
+
+// terraformLog returns the commands the fake terraform ran
+func terraformLog(t *testing.T, workDir string) string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(workDir, "terraform.log"))
+	require.NoError(t, err)
+	return string(data)
+}
+
+const driftPlanJSON = `{"terraform_version": "1.9.5", "resource_drift": [
+	{"address": "module.web.aws_instance.web", "module_address": "module.web", "mode": "managed", "type": "aws_instance", "name": "web",
+	 "provider_name": "registry.terraform.io/hashicorp/aws",
+	 "change": {"actions": ["update"],
+	  "before": {"instance_type": "t3.micro", "tags": {"Owner": "alice"}, "security_groups": ["sg-1"], "user_data": "a", "region": "us-east-1"},
+	  "after": {"instance_type": "t3.large", "tags": {"Owner": "alice", "Env": "prod"}, "security_groups": [], "user_data": "b", "region": "us-east-1"},
+	  "before_sensitive": {"user_data": true}, "after_sensitive": {"user_data": true}}},
+	{"address": "aws_db_instance.main", "mode": "managed", "type": "aws_db_instance", "name": "main",
+	 "provider_name": "registry.terraform.io/hashicorp/aws",
+	 "change": {"actions": ["delete"], "before": {"identifier": "main"}, "after": null}},
+	{"address": "aws_vpc.main", "mode": "managed", "type": "aws_vpc", "name": "main",
+	 "provider_name": "registry.terraform.io/hashicorp/aws",
+	 "change": {"actions": ["no-op"], "before": {"cidr_block": "10.0.0.0/16"}, "after": {"cidr_block": "10.0.0.0/16"}}}
+]}`
+
+const planOutput = `{"@level":"info","type":"version","terraform":"1.9.5"}
+{"@level":"warn","type":"diagnostic","diagnostic":{"severity":"warning","summary":"Deprecated attribute","address":"aws_instance.web"}}
+{"@level":"info","type":"change_summary","changes":{"operation":"plan"}}
+`
+
+func TestDetect(t *testing.T) {
+	terraformPath, workDir := fakeTerraform(t, planOutput, 0, driftPlanJSON)
+
+	result, err := Detect(context.Background(), Options{
+		WorkDir:       workDir,
+		BackendConfig: map[string]string{"key": "prod.tfstate", "bucket": "states"},
+		VarFiles:      []string{"prod.tfvars"},
+		Terraform:     terraformPath,
+	})
+	require.NoError(t, err)
+
+	log := terraformLog(t, workDir)
+	assert.Contains(t, log, "init -input=false -no-color -reconfigure -backend-config=bucket=states -backend-config=key=prod.tfstate\n")
+	assert.Contains(t, log, "plan -refresh-only -input=false -lock=false -json -out=")
+	assert.Contains(t, log, "-var-file=prod.tfvars\n")
+
+	assert.Equal(t, workDir, result.WorkDir)
+	assert.Equal(t, "1.9.5", result.TerraformVersion)
+	assert.Equal(t, []string{"aws_instance.web: Deprecated attribute"}, result.Warnings)
+	require.Equal(t, 2, result.Drifted, "no-op entries are not drift")
+
+	instance := result.Drifts[0]
+	assert.Equal(t, "module.web.aws_instance.web", instance.ResourceID)
+	assert.Equal(t, "aws", instance.Provider)
+	assert.Equal(t, "us-east-1", instance.Region)
+	assert.Equal(t, "attribute_change", instance.DriftType)
+	assert.Equal(t, "medium", instance.Severity)
+	assert.Equal(t, "module.web", instance.Metadata["module"])
+	fields := make(map[string]string)
+	for _, change := range instance.Changes {
+		fields[change.Field] = change.ChangeType
+	}
+	assert.Equal(t, map[string]string{
+		"instance_type":      "update",
+		"security_groups[0]": "delete",
+		"tags.Env":           "create",
+		"user_data":          "update",
+	}, fields)
+	for _, change := range instance.Changes {
+		if change.Field == "user_data" {
+			assert.Equal(t, sensitiveValue, change.OldValue, "sensitive values are redacted")
+			assert.Equal(t, sensitiveValue, change.NewValue)
+		}
+	}
+
+	database := result.Drifts[1]
+	assert.Equal(t, "resource_deletion", database.DriftType)
+	assert.Equal(t, "high", database.Severity)
+}
+
+func TestDetectSkipsInitWhenInitialized(t *testing.T) {
+	terraformPath, workDir := fakeTerraform(t, planOutput, 0, `{"resource_drift": []}`)
+	require.NoError(t, os.Mkdir(filepath.Join(workDir, ".terraform"), 0755))
+
+	result, err := Detect(context.Background(), Options{WorkDir: workDir, Terraform: terraformPath})
+	require.NoError(t, err)
+	assert.NotContains(t, terraformLog(t, workDir), "init")
+	assert.Empty(t, result.Drifts)
+	assert.NotNil(t, result.Drifts)
+}
+
+func TestDetectReportsPlanErrors(t *testing.T) {
+	failed := `{"@level":"error","type":"diagnostic","diagnostic":{"severity":"error","summary":"No valid credential sources found","detail":"Please see the provider documentation"}}
+`
+	terraformPath, workDir := fakeTerraform(t, failed, 1, "")
+
+	_, err := Detect(context.Background(), Options{WorkDir: workDir, Terraform: terraformPath})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "terraform plan failed")
+	assert.Contains(t, err.Error(), "No valid credential sources found: Please see the provider documentation")
+	assert.NotContains(t, terraformLog(t, workDir), "show")
+}
@@ -1,9 +1,12 @@
 package events
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"log"
+	"net/http"
 	"sync"
 	"time"
 
@@ -385,19 +388,47 @@ func (ns *NotificationService) sendEmailNotification(channel NotificationChannel
 	return nil
 }
 
-// sendSlackNotification sends a Slack notification
+// sendSlackNotification posts a notification to the Slack incoming webhook in the channel's
+// webhook_url setting, or logs it when none is configured
 func (ns *NotificationService) sendSlackNotification(channel NotificationChannel, message *NotificationMessage) error {
-	// This would integrate with Slack API
-	// For now, we'll log the notification
-	log.Printf("Slack notification: %s - %s", message.Title, message.Message)
-	return nil
+	url, _ := channel.Config["webhook_url"].(string)
+	if url == "" {
+		log.Printf("Slack notification: %s - %s", message.Title, message.Message)
+		return nil
+	}
+	return postNotification(url, map[string]string{
+		"text": fmt.Sprintf("*%s*\n%s", message.Title, message.Message),
+	})
 }
 
-// sendWebhookNotification sends a webhook notification
+// sendWebhookNotification posts the notification as JSON to the channel's url setting, or
+// logs it when none is configured
 func (ns *NotificationService) sendWebhookNotification(channel NotificationChannel, message *NotificationMessage) error {
-	// This would send HTTP POST to webhook URL
-	// For now, we'll log the notification
-	log.Printf("Webhook notification: %s - %s", message.Title, message.Message)
+	url, _ := channel.Config["url"].(string)
+	if url == "" {
+		log.Printf("Webhook notification: %s - %s", message.Title, message.Message)
+		return nil
+	}
+	return postNotification(url, message)
+}
+
+// notificationClient delivers webhook and Slack notifications
+var notificationClient = &http.Client{Timeout: 10 * time.Second}
+
+// postNotification posts payload as JSON to url, failing on a non-2xx response
+func postNotification(url string, payload interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal notification: %w", err)
+	}
+	resp, err := notificationClient.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to post notification: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("notification endpoint returned %s", resp.Status)
+	}
 	return nil
 }
 
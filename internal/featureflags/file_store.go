@@ -0,0 +1,149 @@
+package featureflags
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+// FileStore keeps feature flags in a local JSON file. Writes replace the file atomically and
+// every call rereads it, but it is only safe within one process: server instances behind a
+// load balancer should share a SQLStore instead.
+type FileStore struct {
+	path string
+	mu   sync.Mutex
+}
+
+// NewFileStore creates a feature flag store on a JSON file, which is created on the first write
+func NewFileStore(path string) *FileStore {
+	return &FileStore{path: path}
+}
+
+// List retrieves all feature flags, by name
+func (s *FileStore) List(ctx context.Context) ([]*Flag, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	flags, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+	return sortedFlags(flags), nil
+}
+
+// Get retrieves a feature flag by name
+func (s *FileStore) Get(ctx context.Context, name string) (*Flag, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	flags, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+	flag, exists := flags[name]
+	if !exists {
+		return nil, ErrFlagNotFound
+	}
+	return flag, nil
+}
+
+// Set creates or replaces a feature flag, recording when it was set
+func (s *FileStore) Set(ctx context.Context, flag *Flag) error {
+	stampFlag(flag)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	flags, err := s.load()
+	if err != nil {
+		return err
+	}
+	flagCopy := *flag
+	flags[flag.Name] = &flagCopy
+	return s.save(flags)
+}
+
+// Delete removes a feature flag
+func (s *FileStore) Delete(ctx context.Context, name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	flags, err := s.load()
+	if err != nil {
+		return err
+	}
+	if _, exists := flags[name]; !exists {
+		return ErrFlagNotFound
+	}
+	delete(flags, name)
+	return s.save(flags)
+}
+
+// load reads the flags from the file; a missing file holds no flags
+func (s *FileStore) load() (map[string]*Flag, error) {
+	flags := make(map[string]*Flag)
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return flags, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read feature flags: %w", err)
+	}
+
+	var stored []*Flag
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return nil, fmt.Errorf("failed to parse feature flags in %s: %w", s.path, err)
+	}
+	for _, flag := range stored {
+		flags[flag.Name] = flag
+	}
+	return flags, nil
+}
+
+// save writes the flags to a temporary file and renames it over the file, so a crash never
+// leaves a partly written file behind
+func (s *FileStore) save(flags map[string]*Flag) error {
+	data, err := json.MarshalIndent(sortedFlags(flags), "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode feature flags: %w", err)
+	}
+
+	dir := filepath.Dir(s.path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create feature flag directory: %w", err)
+	}
+	tmp, err := os.CreateTemp(dir, ".feature-flags-*")
+	if err != nil {
+		return fmt.Errorf("failed to write feature flags: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write feature flags: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write feature flags: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		return fmt.Errorf("failed to write feature flags: %w", err)
+	}
+	return nil
+}
+
+// sortedFlags returns the flags ordered by name
+func sortedFlags(flags map[string]*Flag) []*Flag {
+	sorted := make([]*Flag, 0, len(flags))
+	for _, flag := range flags {
+		sorted = append(sorted, flag)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Name < sorted[j].Name
+	})
+	return sorted
+}
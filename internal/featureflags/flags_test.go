@@ -0,0 +1,70 @@
+package featureflags
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestFlagEvaluate(t *testing.T) {
+	flag := &Flag{Name: "remediation.auto-approve", Enabled: true, Users: []string{"alice"}, Accounts: []string{"123456789012"}}
+	tests := []struct {
+		name    string
+		flag    Flag
+		user    string
+		account string
+		want    bool
+	}{
+		{"targeted user", *flag, "alice", "", true},
+		{"targeted account", *flag, "bob", "123456789012", true},
+		{"untargeted user", *flag, "bob", "210987654321", false},
+		{"disabled flag ignores targeting", Flag{Name: flag.Name, Users: []string{"alice"}, Percentage: 100}, "alice", "", false},
+		{"full rollout", Flag{Name: flag.Name, Enabled: true, Percentage: 100}, "bob", "", true},
+		{"rollout needs a user", Flag{Name: flag.Name, Enabled: true, Percentage: 99}, "", "123456789012", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.flag.Evaluate(tt.user, tt.account); got != tt.want {
+				t.Errorf("Evaluate(%q, %q) = %v, want %v", tt.user, tt.account, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFlagEvaluatePercentageRollout(t *testing.T) {
+	flag := &Flag{Name: "dashboard.graph-view", Enabled: true, Percentage: 25}
+	on := 0
+	for i := 0; i < 2000; i++ {
+		user := fmt.Sprintf("user-%d", i)
+		evaluated := flag.Evaluate(user, "")
+		if evaluated != flag.Evaluate(user, "") {
+			t.Fatalf("expected %s to be evaluated the same way every time", user)
+		}
+		if evaluated {
+			on++
+		}
+	}
+	if on < 400 || on > 600 {
+		t.Errorf("expected about a quarter of 2000 users in a 25%% rollout, got %d", on)
+	}
+
+	// Widening a rollout keeps the users already in it
+	wider := *flag
+	wider.Percentage = 50
+	for i := 0; i < 2000; i++ {
+		user := fmt.Sprintf("user-%d", i)
+		if flag.Evaluate(user, "") && !wider.Evaluate(user, "") {
+			t.Fatalf("expected %s to stay in the rollout when it widened", user)
+		}
+	}
+}
+
+func TestFlagValidate(t *testing.T) {
+	if err := (&Flag{Name: "drift.predict_v2", Percentage: 10}).Validate(); err != nil {
+		t.Errorf("expected a valid flag, got %v", err)
+	}
+	for _, flag := range []*Flag{{Name: ""}, {Name: "Bad Name"}, {Name: "ok", Percentage: 101}, {Name: "ok", Percentage: -1}} {
+		if err := flag.Validate(); err == nil {
+			t.Errorf("expected %+v to be invalid", flag)
+		}
+	}
+}
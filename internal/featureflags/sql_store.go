@@ -0,0 +1,144 @@
+package featureflags
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// featureFlagSchema creates the table of feature flags; users and accounts are JSON arrays
+const featureFlagSchema = `
+CREATE TABLE IF NOT EXISTS feature_flags (
+	name        TEXT PRIMARY KEY,
+	description TEXT NOT NULL DEFAULT '',
+	enabled     BOOLEAN NOT NULL DEFAULT 0,
+	percentage  INTEGER NOT NULL DEFAULT 0,
+	users       TEXT NOT NULL DEFAULT '[]',
+	accounts    TEXT NOT NULL DEFAULT '[]',
+	updated_by  TEXT NOT NULL DEFAULT '',
+	updated_at  TIMESTAMP NOT NULL
+);`
+
+// SQLStore keeps feature flags in a SQLite database. Flags are read from the database on
+// every call rather than cached, so every server instance sharing the database evaluates
+// the same flags, and each write replaces a flag in a single statement.
+type SQLStore struct {
+	db *sql.DB
+}
+
+// NewSQLStore creates a feature flag store on an open SQLite database, creating its table
+func NewSQLStore(db *sql.DB) (*SQLStore, error) {
+	if _, err := db.Exec(featureFlagSchema); err != nil {
+		return nil, fmt.Errorf("failed to create feature flag table: %w", err)
+	}
+	return &SQLStore{db: db}, nil
+}
+
+// List retrieves all feature flags, by name
+func (s *SQLStore) List(ctx context.Context) ([]*Flag, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT name, description, enabled, percentage, users,
+		accounts, updated_by, updated_at FROM feature_flags ORDER BY name ASC`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list feature flags: %w", err)
+	}
+	defer rows.Close()
+
+	flags := []*Flag{}
+	for rows.Next() {
+		flag, err := scanFlag(rows)
+		if err != nil {
+			return nil, err
+		}
+		flags = append(flags, flag)
+	}
+	return flags, rows.Err()
+}
+
+// Get retrieves a feature flag by name
+func (s *SQLStore) Get(ctx context.Context, name string) (*Flag, error) {
+	row := s.db.QueryRowContext(ctx, `SELECT name, description, enabled, percentage, users,
+		accounts, updated_by, updated_at FROM feature_flags WHERE name = ?`, name)
+	flag, err := scanFlag(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrFlagNotFound
+	}
+	return flag, err
+}
+
+// Set creates or replaces a feature flag, recording when it was set
+func (s *SQLStore) Set(ctx context.Context, flag *Flag) error {
+	stampFlag(flag)
+
+	users, err := json.Marshal(nonNil(flag.Users))
+	if err != nil {
+		return fmt.Errorf("failed to encode flag users: %w", err)
+	}
+	accounts, err := json.Marshal(nonNil(flag.Accounts))
+	if err != nil {
+		return fmt.Errorf("failed to encode flag accounts: %w", err)
+	}
+
+	_, err = s.db.ExecContext(ctx, `
+		INSERT INTO feature_flags (name, description, enabled, percentage, users, accounts, updated_by, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(name) DO UPDATE SET
+			description = excluded.description,
+			enabled     = excluded.enabled,
+			percentage  = excluded.percentage,
+			users       = excluded.users,
+			accounts    = excluded.accounts,
+			updated_by  = excluded.updated_by,
+			updated_at  = excluded.updated_at`,
+		flag.Name, flag.Description, flag.Enabled, flag.Percentage, string(users), string(accounts),
+		flag.UpdatedBy, flag.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to set feature flag: %w", err)
+	}
+	return nil
+}
+
+// Delete removes a feature flag
+func (s *SQLStore) Delete(ctx context.Context, name string) error {
+	result, err := s.db.ExecContext(ctx, `DELETE FROM feature_flags WHERE name = ?`, name)
+	if err != nil {
+		return fmt.Errorf("failed to delete feature flag: %w", err)
+	}
+	removed, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get deleted row count: %w", err)
+	}
+	if removed == 0 {
+		return ErrFlagNotFound
+	}
+	return nil
+}
+
+// scanFlag reads a feature flag from a row of the feature_flags table
+func scanFlag(row interface{ Scan(...any) error }) (*Flag, error) {
+	var flag Flag
+	var users, accounts string
+	if err := row.Scan(&flag.Name, &flag.Description, &flag.Enabled, &flag.Percentage, &users,
+		&accounts, &flag.UpdatedBy, &flag.UpdatedAt); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to read feature flag: %w", err)
+	}
+	if err := json.Unmarshal([]byte(users), &flag.Users); err != nil {
+		return nil, fmt.Errorf("failed to decode users of feature flag %s: %w", flag.Name, err)
+	}
+	if err := json.Unmarshal([]byte(accounts), &flag.Accounts); err != nil {
+		return nil, fmt.Errorf("failed to decode accounts of feature flag %s: %w", flag.Name, err)
+	}
+	return &flag, nil
+}
+
+// nonNil returns an empty list in place of nil, so it is stored as [] rather than null
+func nonNil(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
@@ -0,0 +1,105 @@
+package featureflags
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func TestStores(t *testing.T) {
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "flags.db"))
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	defer db.Close()
+	sqlStore, err := NewSQLStore(db)
+	if err != nil {
+		t.Fatalf("failed to create SQL store: %v", err)
+	}
+
+	stores := map[string]Store{
+		"file": NewFileStore(filepath.Join(t.TempDir(), "flags", "feature_flags.json")),
+		"sql":  sqlStore,
+	}
+	for name, store := range stores {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			if flags, err := store.List(ctx); err != nil || len(flags) != 0 {
+				t.Fatalf("expected no flags in a new store, got %v, %v", flags, err)
+			}
+
+			graph := &Flag{Name: "dashboard.graph-view", Enabled: true, Percentage: 10, UpdatedBy: "alice"}
+			approve := &Flag{Name: "remediation.auto-approve", Accounts: []string{"123456789012"}}
+			for _, flag := range []*Flag{graph, approve} {
+				if err := store.Set(ctx, flag); err != nil {
+					t.Fatalf("failed to set flag: %v", err)
+				}
+				if flag.UpdatedAt.IsZero() {
+					t.Errorf("expected Set to record when %s was set", flag.Name)
+				}
+			}
+
+			// Setting a flag again replaces it
+			approve.Enabled = true
+			approve.Users = []string{"bob"}
+			if err := store.Set(ctx, approve); err != nil {
+				t.Fatalf("failed to replace flag: %v", err)
+			}
+			got, err := store.Get(ctx, approve.Name)
+			if err != nil {
+				t.Fatalf("failed to get flag: %v", err)
+			}
+			if !got.Enabled || len(got.Users) != 1 || got.Users[0] != "bob" || len(got.Accounts) != 1 {
+				t.Errorf("expected the replaced flag, got %+v", got)
+			}
+
+			if err := store.Delete(ctx, graph.Name); err != nil {
+				t.Fatalf("failed to delete flag: %v", err)
+			}
+			if err := store.Delete(ctx, graph.Name); !errors.Is(err, ErrFlagNotFound) {
+				t.Errorf("expected ErrFlagNotFound deleting twice, got %v", err)
+			}
+			if _, err := store.Get(ctx, graph.Name); !errors.Is(err, ErrFlagNotFound) {
+				t.Errorf("expected ErrFlagNotFound getting a deleted flag, got %v", err)
+			}
+
+			evaluated, err := EvaluateAll(ctx, store, "carol", "123456789012")
+			if err != nil {
+				t.Fatalf("failed to evaluate flags: %v", err)
+			}
+			if len(evaluated) != 1 || !evaluated[approve.Name] {
+				t.Errorf("expected only the auto-approve flag, on for the targeted account, got %v", evaluated)
+			}
+		})
+	}
+}
+
+func TestFileStoreConcurrentSets(t *testing.T) {
+	store := NewFileStore(filepath.Join(t.TempDir(), "feature_flags.json"))
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	for _, name := range []string{"a", "b", "c", "d", "e", "f", "g", "h"} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := store.Set(ctx, &Flag{Name: name, Enabled: true}); err != nil {
+				t.Errorf("failed to set flag %s: %v", name, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	flags, err := store.List(ctx)
+	if err != nil {
+		t.Fatalf("failed to list flags: %v", err)
+	}
+	if len(flags) != 8 {
+		t.Errorf("expected every concurrent set to be kept, got %d flags", len(flags))
+	}
+}
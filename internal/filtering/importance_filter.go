@@ -367,12 +367,8 @@ func (ifs *ImportanceFilterService) calculateDriftFrequencyImportance(resource m
 
 func (ifs *ImportanceFilterService) calculateResourceAgeImportance(resource models.Resource, drift *internalModels.DriftRecord) float64 {
 	// Calculate resource age
-	var resourceAge time.Duration
-	if !resource.CreatedAt.IsZero() {
-		resourceAge = time.Since(resource.CreatedAt)
-	} else if !resource.Created.IsZero() {
-		resourceAge = time.Since(resource.Created)
-	} else {
+	resourceAge, ok := resource.Age(time.Now())
+	if !ok {
 		return 0.5 // Default weight if no creation time
 	}
 
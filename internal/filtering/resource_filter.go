@@ -232,19 +232,8 @@ func (rfs *ResourceFilterService) getNestedFieldValue(resource models.Resource,
 
 	switch parentField {
 	case "tags":
-		if resource.Tags == nil {
-			return nil, nil
-		}
-		if tagsMap, ok := resource.Tags.(map[string]interface{}); ok {
-			return tagsMap[childField], nil
-		}
-		if tagsSlice, ok := resource.Tags.([]string); ok {
-			for _, tag := range tagsSlice {
-				if tag == childField {
-					return tag, nil
-				}
-			}
-			return nil, nil
+		if value, exists := resource.Tags[childField]; exists {
+			return value, nil
 		}
 		return nil, nil
 	case "attributes":
@@ -0,0 +1,172 @@
+package graph
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	internalModels "github.com/catherinevee/driftmgr/internal/models"
+	"github.com/catherinevee/driftmgr/pkg/models"
+	"github.com/google/uuid"
+)
+
+// LiveNode is a discovered resource in a live relationship graph
+type LiveNode struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Type     string `json:"type"`
+	Provider string `json:"provider"`
+	Region   string `json:"region,omitempty"`
+}
+
+// LiveGraph is the topology of discovered cloud resources, inferred from the references between
+// them rather than from Terraform state, so it covers unmanaged infrastructure too
+type LiveGraph struct {
+	Nodes []LiveNode                            `json:"nodes"`
+	Edges []internalModels.ResourceRelationship `json:"edges"`
+}
+
+// reference describes an attribute that refers to other resources. Edges run from the resource
+// holding the attribute to the one it names, unless reverse is set.
+type reference struct {
+	relationship internalModels.RelationshipType
+	reverse      bool
+}
+
+// referenceAttributes are the attributes and properties that name other resources by ID or ARN
+var referenceAttributes = map[string]reference{
+	"vpc_id":                 {relationship: internalModels.RelationshipTypeDependsOn},
+	"subnet_id":              {relationship: internalModels.RelationshipTypeDependsOn},
+	"subnet_ids":             {relationship: internalModels.RelationshipTypeDependsOn},
+	"subnets":                {relationship: internalModels.RelationshipTypeDependsOn},
+	"security_groups":        {relationship: internalModels.RelationshipTypeSecuredBy},
+	"security_group_ids":     {relationship: internalModels.RelationshipTypeSecuredBy},
+	"vpc_security_group_ids": {relationship: internalModels.RelationshipTypeSecuredBy},
+	"target_group_arns":      {relationship: internalModels.RelationshipTypeConnectedTo},
+	"load_balancer_arns":     {relationship: internalModels.RelationshipTypeConnectedTo, reverse: true},
+	"targets":                {relationship: internalModels.RelationshipTypeConnectedTo},
+	"kms_key_id":             {relationship: internalModels.RelationshipTypeUses},
+	"role":                   {relationship: internalModels.RelationshipTypeUses},
+	"role_arn":               {relationship: internalModels.RelationshipTypeUses},
+	"attached_policies":      {relationship: internalModels.RelationshipTypeUses},
+}
+
+// BuildLiveGraph infers the relationships between discovered resources: placement in subnets
+// and VPCs, security group membership and rules referencing other groups, load balancer to
+// target group to target, and declared dependencies. References to resources that were not
+// discovered are left out.
+func BuildLiveGraph(resources []models.Resource) *LiveGraph {
+	g := &LiveGraph{
+		Nodes: make([]LiveNode, 0, len(resources)),
+		Edges: []internalModels.ResourceRelationship{},
+	}
+
+	byReference := make(map[string]string, len(resources))
+	for _, resource := range resources {
+		byReference[resource.ID] = resource.ID
+		for _, fields := range []map[string]interface{}{resource.Attributes, resource.Properties} {
+			if arn, ok := fields["arn"].(string); ok && arn != "" {
+				byReference[arn] = resource.ID
+			}
+		}
+		g.Nodes = append(g.Nodes, LiveNode{
+			ID:       resource.ID,
+			Name:     resource.Name,
+			Type:     resource.Type,
+			Provider: resource.Provider,
+			Region:   resource.Region,
+		})
+	}
+
+	now := time.Now().UTC()
+	seen := make(map[string]bool)
+	addEdge := func(source, target string, relationship internalModels.RelationshipType, attribute string) {
+		if source == target {
+			return
+		}
+		key := fmt.Sprintf("%s|%s|%s", source, relationship, target)
+		if seen[key] {
+			return
+		}
+		seen[key] = true
+		g.Edges = append(g.Edges, internalModels.ResourceRelationship{
+			ID:         uuid.NewSHA1(uuid.NameSpaceOID, []byte(key)).String(),
+			SourceID:   source,
+			TargetID:   target,
+			Type:       relationship,
+			Direction:  internalModels.RelationshipDirectionUnidirectional,
+			Properties: map[string]interface{}{"attribute": attribute},
+			CreatedAt:  now,
+			UpdatedAt:  now,
+		})
+	}
+
+	for _, resource := range resources {
+		for _, fields := range []map[string]interface{}{resource.Attributes, resource.Properties} {
+			for attribute, ref := range referenceAttributes {
+				for _, value := range referenceValues(fields[attribute]) {
+					target, ok := byReference[value]
+					if !ok {
+						continue
+					}
+					if ref.reverse {
+						addEdge(target, resource.ID, ref.relationship, attribute)
+					} else {
+						addEdge(resource.ID, target, ref.relationship, attribute)
+					}
+				}
+			}
+			// Security group rules that admit traffic from another group
+			for _, direction := range []string{"ingress", "egress"} {
+				rules, _ := fields[direction].([]interface{})
+				for _, rule := range rules {
+					ruleFields, _ := rule.(map[string]interface{})
+					for _, value := range referenceValues(ruleFields["security_groups"]) {
+						if target, ok := byReference[value]; ok {
+							addEdge(resource.ID, target, internalModels.RelationshipTypeConnectedTo, direction)
+						}
+					}
+				}
+			}
+		}
+		for _, dependency := range resource.Dependencies {
+			if target, ok := byReference[dependency]; ok {
+				addEdge(resource.ID, target, internalModels.RelationshipTypeDependsOn, "dependencies")
+			}
+		}
+	}
+
+	sort.Slice(g.Nodes, func(i, j int) bool { return g.Nodes[i].ID < g.Nodes[j].ID })
+	sort.Slice(g.Edges, func(i, j int) bool {
+		if g.Edges[i].SourceID != g.Edges[j].SourceID {
+			return g.Edges[i].SourceID < g.Edges[j].SourceID
+		}
+		if g.Edges[i].TargetID != g.Edges[j].TargetID {
+			return g.Edges[i].TargetID < g.Edges[j].TargetID
+		}
+		return g.Edges[i].Type < g.Edges[j].Type
+	})
+	return g
+}
+
+// referenceValues returns the resource references in an attribute value, which may be a single
+// ID or a list of them
+func referenceValues(value interface{}) []string {
+	switch v := value.(type) {
+	case string:
+		if v != "" {
+			return []string{v}
+		}
+	case []string:
+		return v
+	case []interface{}:
+		values := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok && s != "" {
+				values = append(values, s)
+			}
+		}
+		return values
+	}
+	return nil
+}
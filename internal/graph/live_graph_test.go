@@ -0,0 +1,57 @@
+package graph
+
+import (
+	"testing"
+
+	internalModels "github.com/catherinevee/driftmgr/internal/models"
+	"github.com/catherinevee/driftmgr/pkg/models"
+)
+
+func TestBuildLiveGraph(t *testing.T) {
+	resources := []models.Resource{
+		{ID: "vpc-1", Type: "aws_vpc", Provider: "aws"},
+		{ID: "subnet-1", Type: "aws_subnet", Provider: "aws", Attributes: map[string]interface{}{"vpc_id": "vpc-1"}},
+		{ID: "sg-web", Type: "aws_security_group", Provider: "aws", Attributes: map[string]interface{}{"vpc_id": "vpc-1"}},
+		{ID: "sg-db", Type: "aws_security_group", Provider: "aws", Attributes: map[string]interface{}{
+			"vpc_id":  "vpc-1",
+			"ingress": []interface{}{map[string]interface{}{"security_groups": []string{"sg-web"}}},
+		}},
+		{ID: "i-1", Type: "aws_instance", Provider: "aws", Attributes: map[string]interface{}{
+			"subnet_id":       "subnet-1",
+			"vpc_id":          "vpc-1",
+			"security_groups": []string{"sg-web", "sg-missing"},
+		}},
+		{ID: "arn:lb", Type: "aws_lb", Provider: "aws", Attributes: map[string]interface{}{"arn": "arn:lb", "subnets": []string{"subnet-1"}}},
+		{ID: "arn:tg", Type: "aws_lb_target_group", Provider: "aws", Attributes: map[string]interface{}{
+			"arn":                "arn:tg",
+			"load_balancer_arns": []string{"arn:lb"},
+			"targets":            []string{"i-1"},
+		}},
+	}
+
+	g := BuildLiveGraph(resources)
+
+	if len(g.Nodes) != len(resources) {
+		t.Fatalf("expected %d nodes, got %d", len(resources), len(g.Nodes))
+	}
+	edges := make(map[string]internalModels.RelationshipType)
+	for _, edge := range g.Edges {
+		edges[edge.SourceID+"->"+edge.TargetID] = edge.Type
+	}
+	expected := map[string]internalModels.RelationshipType{
+		"subnet-1->vpc-1": internalModels.RelationshipTypeDependsOn,
+		"i-1->subnet-1":   internalModels.RelationshipTypeDependsOn,
+		"i-1->sg-web":     internalModels.RelationshipTypeSecuredBy,
+		"sg-db->sg-web":   internalModels.RelationshipTypeConnectedTo,
+		"arn:lb->arn:tg":  internalModels.RelationshipTypeConnectedTo,
+		"arn:tg->i-1":     internalModels.RelationshipTypeConnectedTo,
+	}
+	for key, relationship := range expected {
+		if edges[key] != relationship {
+			t.Errorf("expected %s edge %s, got %q", relationship, key, edges[key])
+		}
+	}
+	if _, ok := edges["i-1->sg-missing"]; ok {
+		t.Error("expected no edge to an undiscovered resource")
+	}
+}
@@ -0,0 +1,378 @@
+// Package jobs queues long-running work such as discovery and runs a bounded number of jobs at
+// once, so concurrent requests do not overwhelm the cloud APIs.
+package jobs
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Status is the lifecycle state of a job
+type Status string
+
+// Job statuses
+const (
+	StatusQueued    Status = "queued"
+	StatusRunning   Status = "running"
+	StatusCompleted Status = "completed"
+	StatusFailed    Status = "failed"
+	StatusCancelled Status = "cancelled"
+)
+
+// DefaultRetention is how long finished jobs are kept for polling
+const DefaultRetention = time.Hour
+
+var (
+	// ErrJobExists is returned when submitting a job whose ID is already queued or running
+	ErrJobExists = errors.New("job is already queued or running")
+	// ErrJobNotFound is returned for an unknown job ID
+	ErrJobNotFound = errors.New("job not found")
+	// ErrShuttingDown is returned when submitting a job once Shutdown has begun, and is the
+	// cause of the jobs Shutdown cancels
+	ErrShuttingDown = errors.New("job manager is shutting down")
+)
+
+// Func is the work a job runs. It should stop when ctx is cancelled.
+type Func func(ctx context.Context) (interface{}, error)
+
+// Owner identifies who submitted a job and the provider and account it works on, so access to
+// the job can be limited to the same user and scopes
+type Owner struct {
+	User     string
+	Provider string
+	Account  string
+}
+
+// Job describes a queued, running or finished job. QueuePosition is the 1-based place of a
+// queued job in line to start, and ElapsedSeconds how long the job has run or ran for; both
+// are computed when the job is read.
+type Job struct {
+	ID             string      `json:"id"`
+	Type           string      `json:"type"`
+	User           string      `json:"user,omitempty"`
+	Provider       string      `json:"provider,omitempty"`
+	Account        string      `json:"account,omitempty"`
+	Status         Status      `json:"status"`
+	Progress       float64     `json:"progress"` // percentage, as last reported
+	Message        string      `json:"message,omitempty"`
+	QueuePosition  int         `json:"queue_position,omitempty"`
+	ElapsedSeconds float64     `json:"elapsed_seconds"`
+	Result         interface{} `json:"result,omitempty"`
+	Error          string      `json:"error,omitempty"`
+	CreatedAt      time.Time   `json:"created_at"`
+	StartedAt      *time.Time  `json:"started_at,omitempty"`
+	FinishedAt     *time.Time  `json:"finished_at,omitempty"`
+}
+
+// Active reports whether the job is queued or running
+func (j Job) Active() bool {
+	return j.Status == StatusQueued || j.Status == StatusRunning
+}
+
+// Finished reports whether the job has stopped running
+func (j Job) Finished() bool {
+	return j.Status == StatusCompleted || j.Status == StatusFailed || j.Status == StatusCancelled
+}
+
+// entry is a job with its work and the handles to cancel and wait for it
+type entry struct {
+	job     Job
+	ctx     context.Context
+	fn      Func
+	cancel  context.CancelCauseFunc
+	started chan struct{}
+	done    chan struct{}
+}
+
+// Manager queues jobs and runs at most its concurrency at a time, in submission order
+type Manager struct {
+	mu        sync.Mutex
+	jobs      map[string]*entry
+	queue     []*entry
+	limit     int
+	running   int
+	retention time.Duration
+	closing   bool
+}
+
+// NewManager creates a job manager running at most concurrency jobs at once; values below 1
+// run one job at a time
+func NewManager(concurrency int) *Manager {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	return &Manager{
+		jobs:      make(map[string]*entry),
+		limit:     concurrency,
+		retention: DefaultRetention,
+	}
+}
+
+// Submit queues fn as a job and returns it. The job is cancelled when ctx is, so callers that
+// wait for the result pass their request context and background jobs context.Background().
+// An empty id is replaced with a generated one.
+func (m *Manager) Submit(ctx context.Context, id, jobType string, owner Owner, fn Func) (Job, error) {
+	if id == "" {
+		id = "job-" + uuid.New().String()
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.prune()
+	if m.closing {
+		return Job{}, ErrShuttingDown
+	}
+	if existing, ok := m.jobs[id]; ok && !existing.job.Finished() {
+		return Job{}, ErrJobExists
+	}
+	jobCtx, cancel := context.WithCancelCause(ctx)
+	e := &entry{
+		job: Job{
+			ID:        id,
+			Type:      jobType,
+			User:      owner.User,
+			Provider:  owner.Provider,
+			Account:   owner.Account,
+			Status:    StatusQueued,
+			CreatedAt: time.Now().UTC(),
+		},
+		ctx:     jobCtx,
+		fn:      fn,
+		cancel:  cancel,
+		started: make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	m.jobs[id] = e
+	m.queue = append(m.queue, e)
+	go m.watchQueued(e)
+	m.dispatch()
+	return m.view(e), nil
+}
+
+// dispatch starts queued jobs while there are free slots. m.mu must be held.
+func (m *Manager) dispatch() {
+	for m.running < m.limit && len(m.queue) > 0 {
+		e := m.queue[0]
+		m.queue = m.queue[1:]
+		if err := e.ctx.Err(); err != nil {
+			// Cancelled before its watcher could take it out of the queue
+			m.finish(e, nil, err)
+			close(e.done)
+			continue
+		}
+		m.running++
+
+		started := time.Now().UTC()
+		e.job.Status = StatusRunning
+		e.job.StartedAt = &started
+		close(e.started)
+		go m.run(e)
+	}
+}
+
+// run runs a started job, records its outcome and hands its slot to the next queued job
+func (m *Manager) run(e *entry) {
+	result, err := e.fn(e.ctx)
+
+	m.mu.Lock()
+	m.finish(e, result, err)
+	m.running--
+	m.dispatch()
+	m.mu.Unlock()
+
+	e.cancel(nil)
+	close(e.done)
+}
+
+// watchQueued takes a job out of the queue if it is cancelled before it starts
+func (m *Manager) watchQueued(e *entry) {
+	select {
+	case <-e.started:
+		return
+	case <-e.ctx.Done():
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i, queued := range m.queue {
+		if queued == e {
+			m.queue = append(m.queue[:i], m.queue[i+1:]...)
+			m.finish(e, nil, e.ctx.Err())
+			close(e.done)
+			return
+		}
+	}
+}
+
+// finish records a job's result, marking it cancelled when it failed because its context was
+// cancelled. A cancelled job keeps any partial result it returned. m.mu must be held.
+func (m *Manager) finish(e *entry, result interface{}, err error) {
+	finished := time.Now().UTC()
+	e.job.FinishedAt = &finished
+	switch {
+	case err != nil && e.ctx.Err() != nil:
+		e.job.Status = StatusCancelled
+		e.job.Error = "job was cancelled"
+		if errors.Is(context.Cause(e.ctx), ErrShuttingDown) {
+			e.job.Error = "job was cancelled because the server is shutting down"
+		}
+		e.job.Result = result
+	case err != nil:
+		e.job.Status = StatusFailed
+		e.job.Error = err.Error()
+	default:
+		e.job.Status = StatusCompleted
+		e.job.Progress = 100
+		e.job.Result = result
+	}
+}
+
+// Get returns a job by ID
+func (m *Manager) Get(id string) (Job, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e, ok := m.jobs[id]
+	if !ok {
+		return Job{}, false
+	}
+	return m.view(e), true
+}
+
+// List returns every retained job, oldest first
+func (m *Manager) List() []Job {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.prune()
+
+	jobs := make([]Job, 0, len(m.jobs))
+	for _, e := range m.jobs {
+		jobs = append(jobs, m.view(e))
+	}
+	sort.Slice(jobs, func(i, j int) bool {
+		return jobs[i].CreatedAt.Before(jobs[j].CreatedAt)
+	})
+	return jobs
+}
+
+// Wait blocks until the job finishes or ctx is done, returning the job as it stands
+func (m *Manager) Wait(ctx context.Context, id string) (Job, error) {
+	m.mu.Lock()
+	e, ok := m.jobs[id]
+	m.mu.Unlock()
+	if !ok {
+		return Job{}, ErrJobNotFound
+	}
+
+	select {
+	case <-e.done:
+	case <-ctx.Done():
+		return Job{}, ctx.Err()
+	}
+	job, _ := m.Get(id)
+	return job, nil
+}
+
+// Cancel cancels a queued or running job, reporting whether it was still active
+func (m *Manager) Cancel(id string) bool {
+	m.mu.Lock()
+	e, ok := m.jobs[id]
+	active := ok && !e.job.Finished()
+	m.mu.Unlock()
+	if active {
+		e.cancel(nil)
+	}
+	return active
+}
+
+// Shutdown stops the manager accepting jobs and waits for its queued and running jobs to
+// finish. Jobs still active when ctx is done are cancelled and given up to grace to stop. It
+// returns the jobs that were active when Shutdown began, oldest first, as they ended; a job
+// that ignored its cancellation is still reported as running.
+func (m *Manager) Shutdown(ctx context.Context, grace time.Duration) []Job {
+	m.mu.Lock()
+	m.closing = true
+	var active []*entry
+	for _, e := range m.jobs {
+		if !e.job.Finished() {
+			active = append(active, e)
+		}
+	}
+	m.mu.Unlock()
+
+	for _, e := range active {
+		select {
+		case <-e.done:
+		case <-ctx.Done():
+		}
+	}
+	for _, e := range active {
+		e.cancel(ErrShuttingDown)
+	}
+	graceCtx, cancel := context.WithTimeout(context.Background(), grace)
+	defer cancel()
+	for _, e := range active {
+		select {
+		case <-e.done:
+		case <-graceCtx.Done():
+		}
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	jobs := make([]Job, 0, len(active))
+	for _, e := range active {
+		jobs = append(jobs, m.view(e))
+	}
+	sort.Slice(jobs, func(i, j int) bool {
+		return jobs[i].CreatedAt.Before(jobs[j].CreatedAt)
+	})
+	return jobs
+}
+
+// Report updates a running job's progress. Unknown and finished jobs are ignored.
+func (m *Manager) Report(id string, progress float64, message string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if e, ok := m.jobs[id]; ok && e.job.Status == StatusRunning {
+		e.job.Progress = progress
+		e.job.Message = message
+	}
+}
+
+// view returns a copy of a job with its queue position and elapsed time filled in. m.mu must
+// be held.
+func (m *Manager) view(e *entry) Job {
+	job := e.job
+	if job.Status == StatusQueued {
+		for i, queued := range m.queue {
+			if queued == e {
+				job.QueuePosition = i + 1
+				break
+			}
+		}
+	}
+	if job.StartedAt != nil {
+		end := time.Now().UTC()
+		if job.FinishedAt != nil {
+			end = *job.FinishedAt
+		}
+		job.ElapsedSeconds = math.Round(end.Sub(*job.StartedAt).Seconds()*10) / 10
+	}
+	return job
+}
+
+// prune drops jobs that finished longer ago than the retention period. m.mu must be held.
+func (m *Manager) prune() {
+	cutoff := time.Now().Add(-m.retention)
+	for id, e := range m.jobs {
+		if e.job.FinishedAt != nil && e.job.FinishedAt.Before(cutoff) {
+			delete(m.jobs, id)
+		}
+	}
+}
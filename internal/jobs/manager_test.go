@@ -0,0 +1,164 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestManagerBoundsConcurrency(t *testing.T) {
+	manager := NewManager(1)
+	release := make(chan struct{})
+	blocking := func(ctx context.Context) (interface{}, error) {
+		<-release
+		return "done", nil
+	}
+
+	first, err := manager.Submit(context.Background(), "job-1", "discovery", Owner{User: "jane"}, blocking)
+	if err != nil {
+		t.Fatalf("failed to submit: %v", err)
+	}
+	second, err := manager.Submit(context.Background(), "job-2", "discovery", Owner{User: "jane"}, blocking)
+	if err != nil {
+		t.Fatalf("failed to submit: %v", err)
+	}
+	if _, err := manager.Submit(context.Background(), "job-1", "discovery", Owner{User: "jane"}, blocking); !errors.Is(err, ErrJobExists) {
+		t.Errorf("expected ErrJobExists for an active job ID, got %v", err)
+	}
+
+	waitForStatus(t, manager, first.ID, StatusRunning)
+	if job, _ := manager.Get(second.ID); job.Status != StatusQueued {
+		t.Errorf("expected the second job to be queued, got %s", job.Status)
+	}
+
+	close(release)
+	for _, id := range []string{first.ID, second.ID} {
+		job, err := manager.Wait(context.Background(), id)
+		if err != nil {
+			t.Fatalf("failed to wait for %s: %v", id, err)
+		}
+		if job.Status != StatusCompleted || job.Result != "done" || job.Progress != 100 {
+			t.Errorf("expected %s to complete, got %+v", id, job)
+		}
+	}
+	if jobs := manager.List(); len(jobs) != 2 || jobs[0].ID != "job-1" {
+		t.Errorf("expected both jobs listed oldest first, got %+v", jobs)
+	}
+}
+
+func TestManagerQueuePosition(t *testing.T) {
+	manager := NewManager(1)
+	release := make(chan struct{})
+	blocking := func(ctx context.Context) (interface{}, error) {
+		<-release
+		return nil, nil
+	}
+	running, _ := manager.Submit(context.Background(), "job-1", "discovery", Owner{}, blocking)
+	manager.Submit(context.Background(), "job-2", "discovery", Owner{}, blocking)
+	third, _ := manager.Submit(context.Background(), "job-3", "discovery", Owner{}, blocking)
+	waitForStatus(t, manager, running.ID, StatusRunning)
+
+	if third.QueuePosition != 2 {
+		t.Errorf("expected the third job second in the queue, got %d", third.QueuePosition)
+	}
+	if !manager.Cancel("job-2") {
+		t.Fatal("expected the queued job to be cancelled")
+	}
+	manager.Wait(context.Background(), "job-2")
+	if job, _ := manager.Get(third.ID); job.QueuePosition != 1 || !job.Active() {
+		t.Errorf("expected the third job first in the queue once the second was cancelled, got %+v", job)
+	}
+	if job, _ := manager.Get(running.ID); job.QueuePosition != 0 || job.StartedAt == nil {
+		t.Errorf("expected the running job to have no queue position, got %+v", job)
+	}
+
+	close(release)
+	job, _ := manager.Wait(context.Background(), third.ID)
+	if job.Active() || job.QueuePosition != 0 || job.ElapsedSeconds < 0 {
+		t.Errorf("expected a finished job without a queue position, got %+v", job)
+	}
+}
+
+func TestManagerCancel(t *testing.T) {
+	manager := NewManager(1)
+	running, _ := manager.Submit(context.Background(), "", "discovery", Owner{}, func(ctx context.Context) (interface{}, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	queued, _ := manager.Submit(context.Background(), "", "discovery", Owner{}, func(ctx context.Context) (interface{}, error) {
+		t.Error("a job cancelled while queued should not run")
+		return nil, nil
+	})
+	waitForStatus(t, manager, running.ID, StatusRunning)
+
+	if !manager.Cancel(queued.ID) || !manager.Cancel(running.ID) {
+		t.Fatal("expected active jobs to be cancelled")
+	}
+	for _, id := range []string{queued.ID, running.ID} {
+		if job, _ := manager.Wait(context.Background(), id); job.Status != StatusCancelled {
+			t.Errorf("expected %s to be cancelled, got %s", id, job.Status)
+		}
+	}
+	if manager.Cancel(running.ID) {
+		t.Error("expected a finished job not to be cancelled again")
+	}
+}
+
+func TestManagerFailure(t *testing.T) {
+	manager := NewManager(2)
+	job, _ := manager.Submit(context.Background(), "", "discovery", Owner{}, func(ctx context.Context) (interface{}, error) {
+		return nil, errors.New("throttled")
+	})
+	if job, _ = manager.Wait(context.Background(), job.ID); job.Status != StatusFailed || job.Error != "throttled" {
+		t.Errorf("expected a failed job, got %+v", job)
+	}
+	if _, err := manager.Wait(context.Background(), "missing"); !errors.Is(err, ErrJobNotFound) {
+		t.Errorf("expected ErrJobNotFound, got %v", err)
+	}
+}
+
+func TestManagerShutdown(t *testing.T) {
+	manager := NewManager(2)
+	finishing, _ := manager.Submit(context.Background(), "job-1", "discovery", Owner{}, func(ctx context.Context) (interface{}, error) {
+		return "done", nil
+	})
+	stuck, _ := manager.Submit(context.Background(), "job-2", "discovery", Owner{}, func(ctx context.Context) (interface{}, error) {
+		<-ctx.Done()
+		return "partial", ctx.Err()
+	})
+	waitForStatus(t, manager, stuck.ID, StatusRunning)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	drained := manager.Shutdown(ctx, time.Second)
+	if _, err := manager.Submit(context.Background(), "", "discovery", Owner{}, nil); !errors.Is(err, ErrShuttingDown) {
+		t.Errorf("expected ErrShuttingDown once shutdown began, got %v", err)
+	}
+
+	if job, _ := manager.Get(finishing.ID); job.Status != StatusCompleted {
+		t.Errorf("expected the finished job to complete, got %s", job.Status)
+	}
+	if len(drained) == 0 || drained[len(drained)-1].ID != stuck.ID {
+		t.Fatalf("expected the stuck job among the drained jobs, got %+v", drained)
+	}
+	job := drained[len(drained)-1]
+	if job.Status != StatusCancelled || job.Result != "partial" {
+		t.Errorf("expected the stuck job cancelled with its partial result, got %+v", job)
+	}
+	if job.Error != "job was cancelled because the server is shutting down" {
+		t.Errorf("expected the shutdown to be given as the reason, got %q", job.Error)
+	}
+}
+
+func waitForStatus(t *testing.T, manager *Manager, id string, status Status) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		if job, _ := manager.Get(id); job.Status == status {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("job %s never reached %s", id, status)
+}
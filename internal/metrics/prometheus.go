@@ -0,0 +1,127 @@
+package metrics
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/catherinevee/driftmgr/internal/shared/backoff"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// registry holds driftmgr's Prometheus metrics, separate from the global default
+// registry so library metrics don't leak into the /metrics output
+var registry = prometheus.NewRegistry()
+
+var (
+	resourcesDiscovered = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "driftmgr",
+		Name:      "resources_discovered_total",
+		Help:      "Number of cloud resources discovered, by provider.",
+	}, []string{"provider"})
+
+	discoveryDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "driftmgr",
+		Name:      "discovery_duration_seconds",
+		Help:      "Time taken to discover a provider's resources.",
+		Buckets:   []float64{0.5, 1, 2.5, 5, 10, 30, 60, 120, 300, 600},
+	}, []string{"provider"})
+
+	discoveryFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "driftmgr",
+		Name:      "discovery_failures_total",
+		Help:      "Number of failed discovery runs, by provider.",
+	}, []string{"provider"})
+
+	driftResults = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "driftmgr",
+		Name:      "drift_results_total",
+		Help:      "Number of drift results detected, by severity.",
+	}, []string{"severity"})
+
+	remediations = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "driftmgr",
+		Name:      "remediations_total",
+		Help:      "Number of remediation attempts, by outcome.",
+	}, []string{"status"})
+)
+
+func init() {
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		resourcesDiscovered,
+		discoveryDuration,
+		discoveryFailures,
+		driftResults,
+		remediations,
+		retryCollector{desc: prometheus.NewDesc(
+			"driftmgr_cloud_api_retries_total",
+			"Number of cloud API calls retried after throttling or transient errors, by provider.",
+			[]string{"provider"}, nil,
+		)},
+	)
+}
+
+// Registry returns the registry backing the /metrics endpoint
+func Registry() *prometheus.Registry {
+	return registry
+}
+
+// Handler returns an HTTP handler serving the metrics in Prometheus text format
+func Handler() http.Handler {
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
+}
+
+// RecordDiscovery records a completed discovery run for a provider
+func RecordDiscovery(provider string, resourceCount int, duration time.Duration) {
+	resourcesDiscovered.WithLabelValues(provider).Add(float64(resourceCount))
+	discoveryDuration.WithLabelValues(provider).Observe(duration.Seconds())
+}
+
+// RecordDiscoveryFailure records a failed discovery run for a provider
+func RecordDiscoveryFailure(provider string) {
+	discoveryFailures.WithLabelValues(provider).Inc()
+}
+
+// RecordDriftResult records a detected drift with the given severity
+func RecordDriftResult(severity string) {
+	if severity == "" {
+		severity = "unknown"
+	}
+	driftResults.WithLabelValues(strings.ToLower(severity)).Inc()
+}
+
+// RecordRemediation records the outcome of a remediation, e.g. "success" or "failed"
+func RecordRemediation(status string) {
+	remediations.WithLabelValues(status).Inc()
+}
+
+// RegisterCacheHitRatio exposes a cache's hit ratio (0-1) as a gauge labelled with its name
+func RegisterCacheHitRatio(cache string, ratio func() float64) error {
+	return registry.Register(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace:   "driftmgr",
+		Name:        "cache_hit_ratio",
+		Help:        "Ratio of cache lookups that were hits.",
+		ConstLabels: prometheus.Labels{"cache": cache},
+	}, ratio))
+}
+
+// retryCollector reports the retry counts kept by the shared backoff package
+type retryCollector struct {
+	desc *prometheus.Desc
+}
+
+// Describe implements prometheus.Collector
+func (c retryCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.desc
+}
+
+// Collect implements prometheus.Collector
+func (c retryCollector) Collect(ch chan<- prometheus.Metric) {
+	for provider, count := range backoff.RetryCounts() {
+		ch <- prometheus.MustNewConstMetric(c.desc, prometheus.CounterValue, float64(count), provider)
+	}
+}
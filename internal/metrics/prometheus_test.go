@@ -0,0 +1,54 @@
+package metrics
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/catherinevee/driftmgr/internal/shared/backoff"
+)
+
+func TestPrometheusHandler(t *testing.T) {
+	RecordDiscovery("aws", 12, 3*time.Second)
+	RecordDiscoveryFailure("oracle")
+	RecordDriftResult("HIGH")
+	RecordRemediation("success")
+	backoff.RecordRetry("aws")
+	if err := RegisterCacheHitRatio("discovery", func() float64 { return 0.75 }); err != nil {
+		t.Fatalf("failed to register cache hit ratio: %v", err)
+	}
+
+	w := httptest.NewRecorder()
+	Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+
+	body, _ := io.ReadAll(w.Body)
+	output := string(body)
+	for _, expected := range []string{
+		`driftmgr_resources_discovered_total{provider="aws"} 12`,
+		`driftmgr_discovery_duration_seconds_count{provider="aws"} 1`,
+		`driftmgr_discovery_failures_total{provider="oracle"} 1`,
+		`driftmgr_drift_results_total{severity="high"} 1`,
+		`driftmgr_remediations_total{status="success"} 1`,
+		`driftmgr_cache_hit_ratio{cache="discovery"} 0.75`,
+		`driftmgr_cloud_api_retries_total{provider="aws"}`,
+	} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("expected metrics output to contain %q", expected)
+		}
+	}
+}
+
+func TestRegisterCacheHitRatioDuplicate(t *testing.T) {
+	if err := RegisterCacheHitRatio("duplicate", func() float64 { return 1 }); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := RegisterCacheHitRatio("duplicate", func() float64 { return 1 }); err == nil {
+		t.Error("expected an error registering the same cache twice")
+	}
+}
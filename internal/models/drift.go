@@ -1,7 +1,10 @@
 package models
 
 import (
+	"fmt"
 	"time"
+
+	"github.com/go-playground/validator/v10"
 )
 
 // DriftRecord represents a drift detection record
@@ -84,3 +87,47 @@ type DriftTrend struct {
 	NewDrifts        int       `json:"new_drifts"`
 	ResolvedDrifts   int       `json:"resolved_drifts"`
 }
+
+// DriftHistoryEntry represents the summary of a single drift detection run
+type DriftHistoryEntry struct {
+	ID               string        `json:"id" db:"id"`
+	DriftResultID    string        `json:"drift_result_id" db:"drift_result_id"`
+	Timestamp        time.Time     `json:"timestamp" db:"timestamp"`
+	Provider         string        `json:"provider" db:"provider"`
+	Status           string        `json:"status" db:"status"`
+	TotalResources   int           `json:"total_resources" db:"total_resources"`
+	DriftedResources int           `json:"drifted_resources" db:"drifted_resources"`
+	DriftCount       int           `json:"drift_count" db:"drift_count"`
+	Duration         time.Duration `json:"duration" db:"duration"`
+}
+
+// DriftPattern is a recurring drift a team registers with the drift predictor, such as an
+// autoscaling group that is always resized by hand. Likelihood is the chance a resource of
+// the type drifts on the field within Window, a duration such as 24h.
+type DriftPattern struct {
+	ID           string    `json:"id" db:"id"`
+	ResourceType string    `json:"resource_type" db:"resource_type" validate:"required"`
+	Field        string    `json:"field" db:"field" validate:"required"`
+	Likelihood   float64   `json:"likelihood" db:"likelihood" validate:"gt=0,lte=1"`
+	Window       string    `json:"window,omitempty" db:"time_window"`
+	Description  string    `json:"description,omitempty" db:"description"`
+	CreatedBy    string    `json:"created_by" db:"created_by"`
+	CreatedAt    time.Time `json:"created_at" db:"created_at"`
+}
+
+// Validate validates the DriftPattern struct
+func (dp *DriftPattern) Validate() error {
+	if err := validator.New().Struct(dp); err != nil {
+		return err
+	}
+	if dp.Window != "" {
+		window, err := time.ParseDuration(dp.Window)
+		if err != nil {
+			return fmt.Errorf("invalid window %q: %w", dp.Window, err)
+		}
+		if window <= 0 {
+			return fmt.Errorf("window must be positive, got %s", dp.Window)
+		}
+	}
+	return nil
+}
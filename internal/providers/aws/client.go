@@ -31,6 +31,7 @@ func NewClient(region string, creds *models.ProviderCredentials) (*Client, error
 		// Use provided credentials
 		cfg, err = config.LoadDefaultConfig(context.Background(),
 			config.WithRegion(region),
+			config.WithRetryer(newRetryer),
 			config.WithCredentialsProvider(credentials.NewStaticCredentialsProvider(
 				creds.AccessKey,
 				creds.SecretKey,
@@ -41,6 +42,7 @@ func NewClient(region string, creds *models.ProviderCredentials) (*Client, error
 		// Use default credential chain (environment, IAM role, etc.)
 		cfg, err = config.LoadDefaultConfig(context.Background(),
 			config.WithRegion(region),
+			config.WithRetryer(newRetryer),
 		)
 	}
 
@@ -0,0 +1,178 @@
+package aws
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// cloudTrailDetailType is the detail-type EventBridge gives API calls recorded by CloudTrail
+const cloudTrailDetailType = "AWS API Call via CloudTrail"
+
+// ErrUnsupportedEvent is returned for a CloudTrail event that does not change a resource type
+// driftmgr can re-discover, such as a read-only or failed call
+var ErrUnsupportedEvent = errors.New("unsupported event")
+
+// eventResource says which resource type an API call changes and where its event records the
+// resource IDs, as dotted paths into the event detail that descend through arrays
+type eventResource struct {
+	ResourceType string
+	Paths        []string
+}
+
+// cloudTrailEvents maps the API calls that change resources to what they change
+var cloudTrailEvents = map[string]eventResource{
+	"RunInstances":                  {"aws_instance", []string{"responseElements.instancesSet.items.instanceId"}},
+	"StartInstances":                {"aws_instance", []string{"requestParameters.instancesSet.items.instanceId"}},
+	"StopInstances":                 {"aws_instance", []string{"requestParameters.instancesSet.items.instanceId"}},
+	"TerminateInstances":            {"aws_instance", []string{"requestParameters.instancesSet.items.instanceId"}},
+	"ModifyInstanceAttribute":       {"aws_instance", []string{"requestParameters.instanceId"}},
+	"CreateSecurityGroup":           {"aws_security_group", []string{"responseElements.groupId"}},
+	"DeleteSecurityGroup":           {"aws_security_group", []string{"requestParameters.groupId"}},
+	"AuthorizeSecurityGroupIngress": {"aws_security_group", []string{"requestParameters.groupId"}},
+	"AuthorizeSecurityGroupEgress":  {"aws_security_group", []string{"requestParameters.groupId"}},
+	"RevokeSecurityGroupIngress":    {"aws_security_group", []string{"requestParameters.groupId"}},
+	"RevokeSecurityGroupEgress":     {"aws_security_group", []string{"requestParameters.groupId"}},
+	"ModifySecurityGroupRules":      {"aws_security_group", []string{"requestParameters.ModifySecurityGroupRulesRequest.GroupId"}},
+	"CreateVpc":                     {"aws_vpc", []string{"responseElements.vpc.vpcId"}},
+	"DeleteVpc":                     {"aws_vpc", []string{"requestParameters.vpcId"}},
+	"ModifyVpcAttribute":            {"aws_vpc", []string{"requestParameters.vpcId"}},
+	"CreateSubnet":                  {"aws_subnet", []string{"responseElements.subnet.subnetId"}},
+	"DeleteSubnet":                  {"aws_subnet", []string{"requestParameters.subnetId"}},
+	"ModifySubnetAttribute":         {"aws_subnet", []string{"requestParameters.subnetId"}},
+	"CreateBucket":                  {"aws_s3_bucket", []string{"requestParameters.bucketName"}},
+	"DeleteBucket":                  {"aws_s3_bucket", []string{"requestParameters.bucketName"}},
+	"PutBucketAcl":                  {"aws_s3_bucket", []string{"requestParameters.bucketName"}},
+	"PutBucketPolicy":               {"aws_s3_bucket", []string{"requestParameters.bucketName"}},
+	"DeleteBucketPolicy":            {"aws_s3_bucket", []string{"requestParameters.bucketName"}},
+	"PutBucketVersioning":           {"aws_s3_bucket", []string{"requestParameters.bucketName"}},
+	"PutBucketEncryption":           {"aws_s3_bucket", []string{"requestParameters.bucketName"}},
+	"DeleteBucketEncryption":        {"aws_s3_bucket", []string{"requestParameters.bucketName"}},
+	"PutBucketPublicAccessBlock":    {"aws_s3_bucket", []string{"requestParameters.bucketName"}},
+	"DeleteBucketPublicAccessBlock": {"aws_s3_bucket", []string{"requestParameters.bucketName"}},
+	"PutBucketTagging":              {"aws_s3_bucket", []string{"requestParameters.bucketName"}},
+	"CreateDBInstance":              {"aws_db_instance", []string{"requestParameters.dBInstanceIdentifier"}},
+	"ModifyDBInstance":              {"aws_db_instance", []string{"requestParameters.dBInstanceIdentifier"}},
+	"DeleteDBInstance":              {"aws_db_instance", []string{"requestParameters.dBInstanceIdentifier"}},
+	"AttachRolePolicy":              {"aws_iam_role", []string{"requestParameters.roleName"}},
+	"DetachRolePolicy":              {"aws_iam_role", []string{"requestParameters.roleName"}},
+	"PutRolePolicy":                 {"aws_iam_role", []string{"requestParameters.roleName"}},
+	"DeleteRolePolicy":              {"aws_iam_role", []string{"requestParameters.roleName"}},
+	"UpdateAssumeRolePolicy":        {"aws_iam_role", []string{"requestParameters.roleName"}},
+	"UpdateFunctionConfiguration":   {"aws_lambda_function", []string{"requestParameters.functionName"}},
+	"UpdateFunctionCode":            {"aws_lambda_function", []string{"requestParameters.functionName"}},
+	"UpdateTable":                   {"aws_dynamodb_table", []string{"requestParameters.tableName"}},
+}
+
+// apiVersionSuffix matches the API version some services append to event names, such as
+// UpdateFunctionConfiguration20150331v2
+var apiVersionSuffix = regexp.MustCompile(`\d{8}(v\d+)?$`)
+
+// ChangeEvent is a resource change reported by a CloudTrail event
+type ChangeEvent struct {
+	EventName    string   `json:"event_name"`
+	Account      string   `json:"account"`
+	Region       string   `json:"region"`
+	User         string   `json:"user,omitempty"`
+	ResourceType string   `json:"resource_type"`
+	ResourceIDs  []string `json:"resource_ids"`
+}
+
+// ParseCloudTrailEvent reads an EventBridge notification of an API call recorded by CloudTrail
+// and returns the resources the call changed. It returns an error wrapping
+// ErrUnsupportedEvent for calls that changed nothing driftmgr can re-discover.
+func ParseCloudTrailEvent(data []byte) (*ChangeEvent, error) {
+	var event struct {
+		DetailType string `json:"detail-type"`
+		Source     string `json:"source"`
+		Account    string `json:"account"`
+		Region     string `json:"region"`
+		Detail     struct {
+			EventName    string `json:"eventName"`
+			EventSource  string `json:"eventSource"`
+			ReadOnly     bool   `json:"readOnly"`
+			ErrorCode    string `json:"errorCode"`
+			UserIdentity struct {
+				ARN string `json:"arn"`
+			} `json:"userIdentity"`
+			RequestParameters map[string]interface{} `json:"requestParameters"`
+			ResponseElements  map[string]interface{} `json:"responseElements"`
+		} `json:"detail"`
+	}
+	if err := json.Unmarshal(data, &event); err != nil {
+		return nil, fmt.Errorf("invalid event: %w", err)
+	}
+	if event.DetailType != cloudTrailDetailType {
+		return nil, fmt.Errorf("detail-type %q is not %q", event.DetailType, cloudTrailDetailType)
+	}
+	service := strings.TrimPrefix(event.Source, "aws.")
+	if service == event.Source || service == "" || !strings.HasPrefix(event.Detail.EventSource, service+".") {
+		return nil, fmt.Errorf("event source %q is not an AWS service matching %q", event.Source, event.Detail.EventSource)
+	}
+
+	name := apiVersionSuffix.ReplaceAllString(event.Detail.EventName, "")
+	switch {
+	case event.Detail.ReadOnly:
+		return nil, fmt.Errorf("%w: %s is read-only", ErrUnsupportedEvent, name)
+	case event.Detail.ErrorCode != "":
+		return nil, fmt.Errorf("%w: %s failed with %s", ErrUnsupportedEvent, name, event.Detail.ErrorCode)
+	}
+	changed, ok := cloudTrailEvents[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s does not change a supported resource type", ErrUnsupportedEvent, name)
+	}
+
+	detail := map[string]interface{}{
+		"requestParameters": event.Detail.RequestParameters,
+		"responseElements":  event.Detail.ResponseElements,
+	}
+	seen := make(map[string]bool)
+	ids := []string{}
+	for _, path := range changed.Paths {
+		for _, id := range valuesAt(detail, strings.Split(path, ".")) {
+			if !seen[id] {
+				seen[id] = true
+				ids = append(ids, id)
+			}
+		}
+	}
+	if len(ids) == 0 {
+		return nil, fmt.Errorf("%w: %s does not name the %s it changed", ErrUnsupportedEvent, name, changed.ResourceType)
+	}
+	sort.Strings(ids)
+
+	return &ChangeEvent{
+		EventName:    name,
+		Account:      event.Account,
+		Region:       event.Region,
+		User:         event.Detail.UserIdentity.ARN,
+		ResourceType: changed.ResourceType,
+		ResourceIDs:  ids,
+	}, nil
+}
+
+// valuesAt returns the non-empty strings at path in value, descending into every element of
+// the arrays along the way
+func valuesAt(value interface{}, path []string) []string {
+	switch v := value.(type) {
+	case []interface{}:
+		var values []string
+		for _, element := range v {
+			values = append(values, valuesAt(element, path)...)
+		}
+		return values
+	case map[string]interface{}:
+		if len(path) == 0 {
+			return nil
+		}
+		return valuesAt(v[path[0]], path[1:])
+	case string:
+		if len(path) == 0 && v != "" {
+			return []string{v}
+		}
+	}
+	return nil
+}
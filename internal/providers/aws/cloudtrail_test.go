@@ -0,0 +1,80 @@
+package aws
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestParseCloudTrailEvent(t *testing.T) {
+	event, err := ParseCloudTrailEvent([]byte(`{
+  "detail-type": "AWS API Call via CloudTrail",
+  "source": "aws.ec2",
+  "account": "123456789012",
+  "region": "us-east-1",
+  "detail": {
+    "eventName": "RunInstances",
+    "eventSource": "ec2.amazonaws.com",
+    "userIdentity": {"arn": "arn:aws:iam::123456789012:user/alice"},
+    "responseElements": {"instancesSet": {"items": [{"instanceId": "i-2"}, {"instanceId": "i-1"}]}}
+  }
+}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := &ChangeEvent{
+		EventName:    "RunInstances",
+		Account:      "123456789012",
+		Region:       "us-east-1",
+		User:         "arn:aws:iam::123456789012:user/alice",
+		ResourceType: "aws_instance",
+		ResourceIDs:  []string{"i-1", "i-2"},
+	}
+	if !reflect.DeepEqual(event, want) {
+		t.Errorf("expected %+v, got %+v", want, event)
+	}
+
+	event, err = ParseCloudTrailEvent([]byte(`{
+  "detail-type": "AWS API Call via CloudTrail",
+  "source": "aws.lambda",
+  "detail": {
+    "eventName": "UpdateFunctionConfiguration20150331v2",
+    "eventSource": "lambda.amazonaws.com",
+    "requestParameters": {"functionName": "orders"}
+  }
+}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if event.EventName != "UpdateFunctionConfiguration" || event.ResourceType != "aws_lambda_function" || event.ResourceIDs[0] != "orders" {
+		t.Errorf("expected the versioned event name to be mapped, got %+v", event)
+	}
+}
+
+func TestParseCloudTrailEventRejects(t *testing.T) {
+	tests := []struct {
+		name        string
+		event       string
+		unsupported bool
+	}{
+		{"not json", `{`, false},
+		{"not cloudtrail", `{"detail-type": "EC2 Instance State-change Notification", "source": "aws.ec2"}`, false},
+		{"not aws", `{"detail-type": "AWS API Call via CloudTrail", "source": "custom.app", "detail": {"eventSource": "app.example.com"}}`, false},
+		{"mismatched source", `{"detail-type": "AWS API Call via CloudTrail", "source": "aws.ec2", "detail": {"eventName": "DeleteBucket", "eventSource": "s3.amazonaws.com"}}`, false},
+		{"read-only", `{"detail-type": "AWS API Call via CloudTrail", "source": "aws.ec2", "detail": {"eventName": "DescribeInstances", "eventSource": "ec2.amazonaws.com", "readOnly": true}}`, true},
+		{"failed", `{"detail-type": "AWS API Call via CloudTrail", "source": "aws.ec2", "detail": {"eventName": "DeleteVpc", "eventSource": "ec2.amazonaws.com", "errorCode": "AccessDenied", "requestParameters": {"vpcId": "vpc-1"}}}`, true},
+		{"unmapped", `{"detail-type": "AWS API Call via CloudTrail", "source": "aws.ec2", "detail": {"eventName": "CreateKeyPair", "eventSource": "ec2.amazonaws.com"}}`, true},
+		{"no resource", `{"detail-type": "AWS API Call via CloudTrail", "source": "aws.ec2", "detail": {"eventName": "DeleteVpc", "eventSource": "ec2.amazonaws.com"}}`, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseCloudTrailEvent([]byte(tt.event))
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			if errors.Is(err, ErrUnsupportedEvent) != tt.unsupported {
+				t.Errorf("expected unsupported=%v, got %v", tt.unsupported, err)
+			}
+		})
+	}
+}
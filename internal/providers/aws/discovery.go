@@ -6,6 +6,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/cloudformation"
 	"github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
 	"github.com/aws/aws-sdk-go-v2/service/ec2"
@@ -263,8 +264,8 @@ func (d *DiscoveryEngine) discoverEC2Instances(ctx context.Context) ([]models.Cl
 				// Get instance type
 				instanceType := string(instance.InstanceType)
 
-				// Get creation time
-				createdAt := time.Now()
+				// Get creation time, left zero when unknown
+				var createdAt time.Time
 				if instance.LaunchTime != nil {
 					createdAt = *instance.LaunchTime
 				}
@@ -342,7 +343,7 @@ func (d *DiscoveryEngine) discoverSecurityGroups(ctx context.Context) ([]models.
 					"state":            "active",
 				},
 				LastDiscovered: time.Now(),
-				CreatedAt:      time.Now(), // Security groups don't have creation time
+				CreatedAt:      time.Time{}, // Security groups don't have creation time
 				UpdatedAt:      time.Now(),
 			}
 
@@ -381,8 +382,8 @@ func (d *DiscoveryEngine) discoverVolumes(ctx context.Context) ([]models.CloudRe
 				// Get volume state
 				state := string(volume.State)
 
-			// Get creation time
-			createdAt := time.Now()
+			// Get creation time, left zero when unknown
+			var createdAt time.Time
 			if volume.CreateTime != nil {
 				createdAt = *volume.CreateTime
 			}
@@ -459,7 +460,7 @@ func (d *DiscoveryEngine) discoverVPCs(ctx context.Context) ([]models.CloudResou
 					"state":            state,
 				},
 				LastDiscovered: time.Now(),
-				CreatedAt:      time.Now(), // VPCs don't have creation time
+				CreatedAt:      time.Time{}, // VPCs don't have creation time
 				UpdatedAt:      time.Now(),
 			}
 
@@ -533,8 +534,8 @@ func (d *DiscoveryEngine) DiscoverS3Resources(ctx context.Context) ([]models.Clo
 			encrypted = true
 		}
 
-		// Get creation time
-		createdAt := time.Now()
+		// Get creation time, left zero when unknown
+		var createdAt time.Time
 		if bucket.CreationDate != nil {
 			createdAt = *bucket.CreationDate
 		}
@@ -638,7 +639,7 @@ func (d *DiscoveryEngine) discoverRDSInstances(ctx context.Context) ([]models.Cl
 					"backup_retention":     instance.BackupRetentionPeriod,
 					"encrypted":            instance.StorageEncrypted,
 				},
-				CreatedAt: *instance.InstanceCreateTime,
+				CreatedAt: aws.ToTime(instance.InstanceCreateTime),
 				UpdatedAt: time.Now(),
 			}
 
@@ -701,7 +702,7 @@ func (d *DiscoveryEngine) discoverRDSClusters(ctx context.Context) ([]models.Clo
 					"multi_az":             cluster.MultiAZ,
 					"port":                 cluster.Port,
 				},
-				CreatedAt: *cluster.ClusterCreateTime,
+				CreatedAt: aws.ToTime(cluster.ClusterCreateTime),
 				UpdatedAt: time.Now(),
 			}
 
@@ -762,7 +763,7 @@ func (d *DiscoveryEngine) DiscoverLambdaResources(ctx context.Context) ([]models
 					"vpc_config":       function.VpcConfig,
 					"dead_letter_config": function.DeadLetterConfig,
 				},
-				CreatedAt: time.Now(), // Lambda doesn't provide creation time
+				CreatedAt: time.Time{}, // Lambda doesn't provide creation time
 				UpdatedAt: time.Now(),
 			}
 
@@ -845,7 +846,7 @@ func (d *DiscoveryEngine) discoverIAMUsers(ctx context.Context) ([]models.CloudR
 					"create_date":  user.CreateDate,
 					"password_last_used": user.PasswordLastUsed,
 				},
-				CreatedAt: *user.CreateDate,
+				CreatedAt: aws.ToTime(user.CreateDate),
 				UpdatedAt: time.Now(),
 			}
 
@@ -902,7 +903,7 @@ func (d *DiscoveryEngine) discoverIAMRoles(ctx context.Context) ([]models.CloudR
 					"description": role.Description,
 					"max_session_duration": role.MaxSessionDuration,
 				},
-				CreatedAt: *role.CreateDate,
+				CreatedAt: aws.ToTime(role.CreateDate),
 				UpdatedAt: time.Now(),
 			}
 
@@ -962,7 +963,7 @@ func (d *DiscoveryEngine) discoverIAMPolicies(ctx context.Context) ([]models.Clo
 					"description": policy.Description,
 					"attachment_count": policy.AttachmentCount,
 				},
-				CreatedAt: *policy.CreateDate,
+				CreatedAt: aws.ToTime(policy.CreateDate),
 				UpdatedAt: *policy.UpdateDate,
 			}
 
@@ -1040,7 +1041,7 @@ func (d *DiscoveryEngine) DiscoverCloudFormationResources(ctx context.Context) (
 					"role_arn":          stack.RoleARN,
 					"timeout_in_minutes": stack.TimeoutInMinutes,
 				},
-				CreatedAt: *stack.CreationTime,
+				CreatedAt: aws.ToTime(stack.CreationTime),
 				UpdatedAt: time.Now(),
 			}
 
@@ -0,0 +1,91 @@
+package aws
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+)
+
+// jsonProtocolClient calls an AWS JSON protocol API directly with SigV4-signed requests, for
+// services whose SDK modules driftmgr does not depend on
+type jsonProtocolClient struct {
+	config   aws.Config
+	service  string // the signing name, such as tagging
+	region   string // the signing region
+	endpoint string
+	target   string // the X-Amz-Target prefix of the service's operations
+	signer   *v4.Signer
+}
+
+// newJSONProtocolClient creates a client for one service endpoint
+func newJSONProtocolClient(cfg aws.Config, service, region, endpoint, target string) jsonProtocolClient {
+	return jsonProtocolClient{
+		config:   cfg,
+		service:  service,
+		region:   region,
+		endpoint: endpoint,
+		target:   target,
+		signer:   v4.NewSigner(),
+	}
+}
+
+// call sends a SigV4-signed operation and decodes its response into output
+func (c *jsonProtocolClient) call(ctx context.Context, operation string, input, output interface{}) error {
+	body, err := json.Marshal(input)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", c.target+operation)
+
+	creds, err := c.config.Credentials.Retrieve(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to retrieve AWS credentials: %w", err)
+	}
+	payloadHash := sha256.Sum256(body)
+	if err := c.signer.SignHTTP(ctx, creds, req, hex.EncodeToString(payloadHash[:]), c.service, c.region, time.Now()); err != nil {
+		return fmt.Errorf("failed to sign %s request: %w", c.service, err)
+	}
+
+	var httpClient aws.HTTPClient = http.DefaultClient
+	if c.config.HTTPClient != nil {
+		httpClient = c.config.HTTPClient
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		var apiErr struct {
+			Type    string `json:"__type"`
+			Message string `json:"message"`
+		}
+		json.Unmarshal(data, &apiErr)
+		return fmt.Errorf("%s %s failed (%d): %s %s", c.service, operation, resp.StatusCode, apiErr.Type, apiErr.Message)
+	}
+
+	if err := json.Unmarshal(data, output); err != nil {
+		return fmt.Errorf("failed to decode %s response: %w", c.service, err)
+	}
+	return nil
+}
@@ -0,0 +1,166 @@
+package aws
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	elbv2 "github.com/aws/aws-sdk-go-v2/service/elasticloadbalancingv2"
+	"github.com/catherinevee/driftmgr/pkg/models"
+)
+
+// listNetworkResources lists the VPCs, subnets, security groups, load balancers and target
+// groups that place and connect other resources, recording the references between them so
+// the topology can be rebuilt from discovery alone
+func (p *AWSProvider) listNetworkResources(ctx context.Context) ([]*models.Resource, error) {
+	resources := []*models.Resource{}
+
+	vpcs := ec2.NewDescribeVpcsPaginator(p.ec2Client, &ec2.DescribeVpcsInput{})
+	for vpcs.HasMorePages() {
+		page, err := vpcs.NextPage(ctx)
+		if err != nil {
+			return resources, err
+		}
+		for _, vpc := range page.Vpcs {
+			id := aws.ToString(vpc.VpcId)
+			resources = append(resources, &models.Resource{
+				ID:       id,
+				Type:     "aws_vpc",
+				Name:     p.getTagValue(vpc.Tags, "Name"),
+				Provider: "aws",
+				Region:   p.region,
+				Tags:     p.convertTags(vpc.Tags),
+				Attributes: map[string]interface{}{
+					"id":         id,
+					"cidr_block": aws.ToString(vpc.CidrBlock),
+					"is_default": aws.ToBool(vpc.IsDefault),
+				},
+			})
+		}
+	}
+
+	subnets := ec2.NewDescribeSubnetsPaginator(p.ec2Client, &ec2.DescribeSubnetsInput{})
+	for subnets.HasMorePages() {
+		page, err := subnets.NextPage(ctx)
+		if err != nil {
+			return resources, err
+		}
+		for _, subnet := range page.Subnets {
+			id := aws.ToString(subnet.SubnetId)
+			resources = append(resources, &models.Resource{
+				ID:       id,
+				Type:     "aws_subnet",
+				Name:     p.getTagValue(subnet.Tags, "Name"),
+				Provider: "aws",
+				Region:   p.region,
+				Tags:     p.convertTags(subnet.Tags),
+				Attributes: map[string]interface{}{
+					"id":                id,
+					"vpc_id":            aws.ToString(subnet.VpcId),
+					"cidr_block":        aws.ToString(subnet.CidrBlock),
+					"availability_zone": aws.ToString(subnet.AvailabilityZone),
+				},
+			})
+		}
+	}
+
+	groups := ec2.NewDescribeSecurityGroupsPaginator(p.ec2Client, &ec2.DescribeSecurityGroupsInput{})
+	for groups.HasMorePages() {
+		page, err := groups.NextPage(ctx)
+		if err != nil {
+			return resources, err
+		}
+		for _, sg := range page.SecurityGroups {
+			id := aws.ToString(sg.GroupId)
+			resources = append(resources, &models.Resource{
+				ID:       id,
+				Type:     "aws_security_group",
+				Name:     aws.ToString(sg.GroupName),
+				Provider: "aws",
+				Region:   p.region,
+				Tags:     p.convertTags(sg.Tags),
+				Attributes: map[string]interface{}{
+					"id":      id,
+					"name":    aws.ToString(sg.GroupName),
+					"vpc_id":  aws.ToString(sg.VpcId),
+					"ingress": SecurityGroupRules(id, sg.IpPermissions),
+					"egress":  SecurityGroupRules(id, sg.IpPermissionsEgress),
+				},
+			})
+		}
+	}
+
+	loadBalancing, err := p.listLoadBalancing(ctx, elbv2.NewFromConfig(p.awsConfig))
+	resources = append(resources, loadBalancing...)
+	return resources, err
+}
+
+// listLoadBalancing lists load balancers and their target groups with the registered targets
+func (p *AWSProvider) listLoadBalancing(ctx context.Context, client *elbv2.Client) ([]*models.Resource, error) {
+	resources := []*models.Resource{}
+
+	loadBalancers := elbv2.NewDescribeLoadBalancersPaginator(client, &elbv2.DescribeLoadBalancersInput{})
+	for loadBalancers.HasMorePages() {
+		page, err := loadBalancers.NextPage(ctx)
+		if err != nil {
+			return resources, err
+		}
+		for _, lb := range page.LoadBalancers {
+			arn := aws.ToString(lb.LoadBalancerArn)
+			var subnets []string
+			for _, zone := range lb.AvailabilityZones {
+				subnets = append(subnets, aws.ToString(zone.SubnetId))
+			}
+			resources = append(resources, &models.Resource{
+				ID:       arn,
+				Type:     "aws_lb",
+				Name:     aws.ToString(lb.LoadBalancerName),
+				Provider: "aws",
+				Region:   p.region,
+				Attributes: map[string]interface{}{
+					"arn":                arn,
+					"load_balancer_type": string(lb.Type),
+					"vpc_id":             aws.ToString(lb.VpcId),
+					"subnets":            subnets,
+					"security_groups":    lb.SecurityGroups,
+				},
+			})
+		}
+	}
+
+	targetGroups := elbv2.NewDescribeTargetGroupsPaginator(client, &elbv2.DescribeTargetGroupsInput{})
+	for targetGroups.HasMorePages() {
+		page, err := targetGroups.NextPage(ctx)
+		if err != nil {
+			return resources, err
+		}
+		for _, group := range page.TargetGroups {
+			arn := aws.ToString(group.TargetGroupArn)
+			var targets []string
+			health, err := client.DescribeTargetHealth(ctx, &elbv2.DescribeTargetHealthInput{TargetGroupArn: group.TargetGroupArn})
+			if err == nil {
+				for _, description := range health.TargetHealthDescriptions {
+					if description.Target != nil {
+						targets = append(targets, aws.ToString(description.Target.Id))
+					}
+				}
+			}
+			resources = append(resources, &models.Resource{
+				ID:       arn,
+				Type:     "aws_lb_target_group",
+				Name:     aws.ToString(group.TargetGroupName),
+				Provider: "aws",
+				Region:   p.region,
+				Attributes: map[string]interface{}{
+					"arn":                arn,
+					"vpc_id":             aws.ToString(group.VpcId),
+					"target_type":        string(group.TargetType),
+					"load_balancer_arns": group.LoadBalancerArns,
+					"targets":            targets,
+				},
+			})
+		}
+	}
+
+	return resources, nil
+}
@@ -0,0 +1,380 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+
+	"github.com/catherinevee/driftmgr/pkg/models"
+)
+
+const (
+	// DefaultOrganizationRole is the role AWS Organizations creates in the member accounts it
+	// creates, trusted by the management account
+	DefaultOrganizationRole = "OrganizationAccountAccessRole"
+	// organizationsTarget prefixes the JSON protocol operations of the Organizations API
+	organizationsTarget = "AWSOrganizationsV20161128."
+	// defaultOrganizationConcurrency is how many accounts are discovered at once by default
+	defaultOrganizationConcurrency = 4
+)
+
+// OrganizationAccount is a member account of an AWS organization
+type OrganizationAccount struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	Email string `json:"email,omitempty"`
+	// Status is ACTIVE, SUSPENDED or PENDING_CLOSURE; only active accounts are discovered
+	Status string `json:"status"`
+	// OrganizationalUnit is the path of the account's parent, such as Root/Workloads/Prod
+	OrganizationalUnit string `json:"organizational_unit"`
+	// Management reports whether this is the organization's management account, which is
+	// discovered with the caller's own credentials rather than an assumed role
+	Management bool `json:"management,omitempty"`
+}
+
+// OrganizationalUnit is an organizational unit, or the root, of an AWS organization
+type OrganizationalUnit struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	ParentID string `json:"parent_id,omitempty"`
+	Path     string `json:"path"`
+}
+
+// Organization is the structure of an AWS organization
+type Organization struct {
+	ID                  string                `json:"id"`
+	ManagementAccountID string                `json:"management_account_id"`
+	OrganizationalUnits []OrganizationalUnit  `json:"organizational_units"`
+	Accounts            []OrganizationAccount `json:"accounts"`
+}
+
+// organizationsPage is one page of a DescribeOrganization, ListRoots,
+// ListOrganizationalUnitsForParent or ListAccountsForParent response
+type organizationsPage struct {
+	NextToken    string `json:"NextToken"`
+	Organization struct {
+		ID              string `json:"Id"`
+		MasterAccountID string `json:"MasterAccountId"`
+	} `json:"Organization"`
+	Roots []struct {
+		ID   string `json:"Id"`
+		Name string `json:"Name"`
+	} `json:"Roots"`
+	OrganizationalUnits []struct {
+		ID   string `json:"Id"`
+		Name string `json:"Name"`
+	} `json:"OrganizationalUnits"`
+	Accounts []struct {
+		ID     string `json:"Id"`
+		Name   string `json:"Name"`
+		Email  string `json:"Email"`
+		Status string `json:"Status"`
+	} `json:"Accounts"`
+}
+
+// organizationsClient calls one Organizations API operation
+type organizationsClient interface {
+	Call(ctx context.Context, operation string, input map[string]interface{}) (*organizationsPage, error)
+}
+
+// httpOrganizationsClient calls the Organizations API directly with SigV4-signed requests.
+// The API is global and served from us-east-1.
+type httpOrganizationsClient struct {
+	jsonProtocolClient
+}
+
+// newOrganizationsClient creates an Organizations API client
+func newOrganizationsClient(cfg aws.Config) *httpOrganizationsClient {
+	return &httpOrganizationsClient{newJSONProtocolClient(cfg, "organizations", "us-east-1",
+		"https://organizations.us-east-1.amazonaws.com/", organizationsTarget)}
+}
+
+// Call sends an Organizations API operation and returns its response
+func (c *httpOrganizationsClient) Call(ctx context.Context, operation string, input map[string]interface{}) (*organizationsPage, error) {
+	var page organizationsPage
+	if err := c.call(ctx, operation, input, &page); err != nil {
+		return nil, err
+	}
+	return &page, nil
+}
+
+// ListOrganization returns the organizational units and accounts of the organization cfg's
+// credentials belong to. They must be the management account's, or a delegated
+// administrator's.
+func ListOrganization(ctx context.Context, cfg aws.Config) (*Organization, error) {
+	return listOrganization(ctx, newOrganizationsClient(cfg))
+}
+
+// listOrganization walks the organization from its roots, listing each parent's
+// organizational units and accounts
+func listOrganization(ctx context.Context, client organizationsClient) (*Organization, error) {
+	described, err := client.Call(ctx, "DescribeOrganization", map[string]interface{}{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe organization: %w", err)
+	}
+	organization := &Organization{
+		ID:                  described.Organization.ID,
+		ManagementAccountID: described.Organization.MasterAccountID,
+		OrganizationalUnits: []OrganizationalUnit{},
+		Accounts:            []OrganizationAccount{},
+	}
+
+	var queue []OrganizationalUnit
+	err = listPages(ctx, client, "ListRoots", map[string]interface{}{}, func(page *organizationsPage) {
+		for _, root := range page.Roots {
+			queue = append(queue, OrganizationalUnit{ID: root.ID, Name: root.Name, Path: root.Name})
+		}
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list organization roots: %w", err)
+	}
+
+	for len(queue) > 0 {
+		parent := queue[0]
+		queue = queue[1:]
+		organization.OrganizationalUnits = append(organization.OrganizationalUnits, parent)
+
+		input := map[string]interface{}{"ParentId": parent.ID}
+		err := listPages(ctx, client, "ListOrganizationalUnitsForParent", input, func(page *organizationsPage) {
+			for _, unit := range page.OrganizationalUnits {
+				queue = append(queue, OrganizationalUnit{
+					ID:       unit.ID,
+					Name:     unit.Name,
+					ParentID: parent.ID,
+					Path:     parent.Path + "/" + unit.Name,
+				})
+			}
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list organizational units of %s: %w", parent.Path, err)
+		}
+
+		err = listPages(ctx, client, "ListAccountsForParent", input, func(page *organizationsPage) {
+			for _, account := range page.Accounts {
+				organization.Accounts = append(organization.Accounts, OrganizationAccount{
+					ID:                 account.ID,
+					Name:               account.Name,
+					Email:              account.Email,
+					Status:             account.Status,
+					OrganizationalUnit: parent.Path,
+					Management:         account.ID == organization.ManagementAccountID,
+				})
+			}
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list accounts of %s: %w", parent.Path, err)
+		}
+	}
+
+	sort.Slice(organization.Accounts, func(i, j int) bool {
+		return organization.Accounts[i].ID < organization.Accounts[j].ID
+	})
+	return organization, nil
+}
+
+// listPages calls a paginated operation until it returns no NextToken
+func listPages(ctx context.Context, client organizationsClient, operation string, input map[string]interface{}, handle func(*organizationsPage)) error {
+	token := ""
+	for {
+		paged := make(map[string]interface{}, len(input)+1)
+		for key, value := range input {
+			paged[key] = value
+		}
+		if token != "" {
+			paged["NextToken"] = token
+		}
+		page, err := client.Call(ctx, operation, paged)
+		if err != nil {
+			return err
+		}
+		handle(page)
+		if page.NextToken == "" {
+			return nil
+		}
+		token = page.NextToken
+	}
+}
+
+// AssumeRoleConfig returns a copy of cfg whose credentials assume roleName in another
+// account. The credentials are fetched on first use and refreshed before they expire.
+func AssumeRoleConfig(cfg aws.Config, accountID, roleName string) aws.Config {
+	roleARN := fmt.Sprintf("arn:%s:iam::%s:role/%s", partitionForRegion(cfg.Region), accountID, roleName)
+	provider := stscreds.NewAssumeRoleProvider(sts.NewFromConfig(cfg), roleARN, func(o *stscreds.AssumeRoleOptions) {
+		o.RoleSessionName = "driftmgr-discovery"
+	})
+	assumed := cfg.Copy()
+	assumed.Credentials = aws.NewCredentialsCache(provider)
+	return assumed
+}
+
+// partitionForRegion returns the ARN partition of a region
+func partitionForRegion(region string) string {
+	switch {
+	case strings.HasPrefix(region, "cn-"):
+		return "aws-cn"
+	case strings.HasPrefix(region, "us-gov-"):
+		return "aws-us-gov"
+	}
+	return "aws"
+}
+
+// OrganizationDiscoveryOptions configures discovery across an organization's accounts
+type OrganizationDiscoveryOptions struct {
+	// RoleName is the role assumed in each member account; DefaultOrganizationRole when empty
+	RoleName string
+	// Regions are scanned in every account; us-east-1 when empty
+	Regions []string
+	// Include selects the accounts to discover; every active account when nil
+	Include func(OrganizationAccount) bool
+	// Concurrency limits how many accounts are discovered at once
+	Concurrency int
+	// Progress is called as each account finishes
+	Progress func(done, total int, account AccountDiscovery)
+}
+
+// AccountDiscovery is the outcome of discovering one account. Error is set when the role
+// could not be assumed or a region could not be scanned; Resources then holds what was
+// found before the failure. Errors lists the services that could not be discovered in the
+// regions that were scanned.
+type AccountDiscovery struct {
+	Account   OrganizationAccount     `json:"account"`
+	Resources []models.Resource       `json:"resources"`
+	Error     string                  `json:"error,omitempty"`
+	Errors    []models.DiscoveryError `json:"errors,omitempty"`
+}
+
+// OrganizationDiscovery is the outcome of discovering an organization's accounts. Skipped
+// lists the accounts that are not active or were not included.
+type OrganizationDiscovery struct {
+	Organization *Organization         `json:"organization"`
+	Accounts     []AccountDiscovery    `json:"accounts"`
+	Skipped      []OrganizationAccount `json:"skipped"`
+}
+
+// accountRegionDiscoverer discovers one account's resources in one region
+type accountRegionDiscoverer func(ctx context.Context, account OrganizationAccount, region string) (*models.DiscoveryResult, error)
+
+// DiscoverOrganization lists the organization cfg's credentials manage and discovers the
+// resources of its active accounts with the Resource Groups Tagging API, assuming the
+// configured role in each member account. The management account is discovered with cfg's
+// own credentials.
+func DiscoverOrganization(ctx context.Context, cfg aws.Config, options OrganizationDiscoveryOptions) (*OrganizationDiscovery, error) {
+	organization, err := ListOrganization(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+	roleName := options.RoleName
+	if roleName == "" {
+		roleName = DefaultOrganizationRole
+	}
+
+	var mu sync.Mutex
+	configs := make(map[string]aws.Config)
+	discover := func(ctx context.Context, account OrganizationAccount, region string) (*models.DiscoveryResult, error) {
+		mu.Lock()
+		accountConfig, ok := configs[account.ID]
+		if !ok {
+			accountConfig = cfg
+			if !account.Management {
+				accountConfig = AssumeRoleConfig(cfg, account.ID, roleName)
+			}
+			configs[account.ID] = accountConfig
+		}
+		mu.Unlock()
+
+		regional := accountConfig.Copy()
+		regional.Region = region
+		return NewAWSProviderWithConfig(regional).DiscoverResourcesFast(ctx, region)
+	}
+	return discoverAccounts(ctx, organization, options, discover), nil
+}
+
+// DiscoverOrganization discovers the organization the provider's credentials manage,
+// initializing the provider first when needed
+func (p *AWSProvider) DiscoverOrganization(ctx context.Context, options OrganizationDiscoveryOptions) (*OrganizationDiscovery, error) {
+	if p.ec2Client == nil {
+		if err := p.Initialize(ctx); err != nil {
+			return nil, err
+		}
+	}
+	return DiscoverOrganization(ctx, p.awsConfig, options)
+}
+
+// discoverAccounts discovers the included active accounts of an organization, a few at a
+// time, scanning each account's regions in turn
+func discoverAccounts(ctx context.Context, organization *Organization, options OrganizationDiscoveryOptions, discover accountRegionDiscoverer) *OrganizationDiscovery {
+	regions := options.Regions
+	if len(regions) == 0 {
+		regions = []string{"us-east-1"}
+	}
+	concurrency := options.Concurrency
+	if concurrency <= 0 {
+		concurrency = defaultOrganizationConcurrency
+	}
+
+	result := &OrganizationDiscovery{
+		Organization: organization,
+		Accounts:     []AccountDiscovery{},
+		Skipped:      []OrganizationAccount{},
+	}
+	var accounts []OrganizationAccount
+	for _, account := range organization.Accounts {
+		if account.Status != "ACTIVE" || (options.Include != nil && !options.Include(account)) {
+			result.Skipped = append(result.Skipped, account)
+			continue
+		}
+		accounts = append(accounts, account)
+	}
+
+	discovered := make([]AccountDiscovery, len(accounts))
+	var mu sync.Mutex
+	done := 0
+	var wg sync.WaitGroup
+	semaphore := make(chan struct{}, concurrency)
+	for i, account := range accounts {
+		wg.Add(1)
+		go func(i int, account OrganizationAccount) {
+			defer wg.Done()
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+
+			outcome := AccountDiscovery{Account: account, Resources: []models.Resource{}}
+			for _, region := range regions {
+				if err := ctx.Err(); err != nil {
+					outcome.Error = err.Error()
+					break
+				}
+				discovered, err := discover(ctx, account, strings.TrimSpace(region))
+				if err != nil {
+					outcome.Error = fmt.Sprintf("%s: %v", region, err)
+					break
+				}
+				outcome.Errors = append(outcome.Errors, discovered.Errors...)
+				for _, resource := range discovered.Resources {
+					if resource.AccountID == "" {
+						resource.AccountID = account.ID
+					}
+					outcome.Resources = append(outcome.Resources, resource)
+				}
+			}
+			discovered[i] = outcome
+
+			mu.Lock()
+			done++
+			if options.Progress != nil {
+				options.Progress(done, len(accounts), outcome)
+			}
+			mu.Unlock()
+		}(i, account)
+	}
+	wg.Wait()
+
+	result.Accounts = append(result.Accounts, discovered...)
+	return result
+}
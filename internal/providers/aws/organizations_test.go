@@ -0,0 +1,131 @@
+package aws
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/catherinevee/driftmgr/pkg/models"
+)
+
+// fakeOrganizationsClient serves canned responses keyed by operation, parent and page token
+type fakeOrganizationsClient struct {
+	responses map[string]string
+}
+
+func (f *fakeOrganizationsClient) Call(ctx context.Context, operation string, input map[string]interface{}) (*organizationsPage, error) {
+	key := fmt.Sprintf("%s %v %v", operation, input["ParentId"], input["NextToken"])
+	body, ok := f.responses[key]
+	if !ok {
+		body = "{}"
+	}
+	var page organizationsPage
+	if err := json.Unmarshal([]byte(body), &page); err != nil {
+		return nil, err
+	}
+	return &page, nil
+}
+
+func TestListOrganization(t *testing.T) {
+	client := &fakeOrganizationsClient{responses: map[string]string{
+		"DescribeOrganization <nil> <nil>":            `{"Organization": {"Id": "o-abc", "MasterAccountId": "111111111111"}}`,
+		"ListRoots <nil> <nil>":                       `{"Roots": [{"Id": "r-1", "Name": "Root"}]}`,
+		"ListOrganizationalUnitsForParent r-1 <nil>":  `{"OrganizationalUnits": [{"Id": "ou-1", "Name": "Workloads"}]}`,
+		"ListOrganizationalUnitsForParent ou-1 <nil>": `{"OrganizationalUnits": [{"Id": "ou-2", "Name": "Prod"}]}`,
+		"ListAccountsForParent r-1 <nil>":             `{"Accounts": [{"Id": "111111111111", "Name": "management", "Status": "ACTIVE"}]}`,
+		"ListAccountsForParent ou-2 <nil>":            `{"Accounts": [{"Id": "333333333333", "Name": "prod-a", "Status": "ACTIVE"}], "NextToken": "t1"}`,
+		"ListAccountsForParent ou-2 t1":               `{"Accounts": [{"Id": "222222222222", "Name": "prod-b", "Status": "SUSPENDED"}]}`,
+	}}
+
+	organization, err := listOrganization(context.Background(), client)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if organization.ManagementAccountID != "111111111111" {
+		t.Errorf("expected management account 111111111111, got %s", organization.ManagementAccountID)
+	}
+	if len(organization.OrganizationalUnits) != 3 {
+		t.Errorf("expected the root and 2 organizational units, got %d", len(organization.OrganizationalUnits))
+	}
+	if len(organization.Accounts) != 3 {
+		t.Fatalf("expected 3 accounts across pages, got %d", len(organization.Accounts))
+	}
+	want := []struct {
+		id, unit   string
+		management bool
+	}{
+		{"111111111111", "Root", true},
+		{"222222222222", "Root/Workloads/Prod", false},
+		{"333333333333", "Root/Workloads/Prod", false},
+	}
+	for i, expected := range want {
+		account := organization.Accounts[i]
+		if account.ID != expected.id || account.OrganizationalUnit != expected.unit || account.Management != expected.management {
+			t.Errorf("account %d: got %+v, want %+v", i, account, expected)
+		}
+	}
+}
+
+func TestDiscoverAccounts(t *testing.T) {
+	organization := &Organization{Accounts: []OrganizationAccount{
+		{ID: "111111111111", Status: "ACTIVE", Management: true},
+		{ID: "222222222222", Status: "SUSPENDED"},
+		{ID: "333333333333", Status: "ACTIVE"},
+		{ID: "444444444444", Status: "ACTIVE"},
+		{ID: "555555555555", Status: "ACTIVE"},
+	}}
+	discover := func(ctx context.Context, account OrganizationAccount, region string) (*models.DiscoveryResult, error) {
+		if account.ID == "444444444444" {
+			return nil, errors.New("AccessDenied")
+		}
+		result := &models.DiscoveryResult{Resources: []models.Resource{{ID: account.ID + "-" + region, Region: region}}}
+		if account.ID == "333333333333" && region == "eu-west-1" {
+			result.Errors = []models.DiscoveryError{{Service: "EC2", Region: region, Code: "UnauthorizedOperation"}}
+		}
+		return result, nil
+	}
+	progress := 0
+	result := discoverAccounts(context.Background(), organization, OrganizationDiscoveryOptions{
+		Regions:  []string{"us-east-1", "eu-west-1"},
+		Include:  func(account OrganizationAccount) bool { return account.ID != "555555555555" },
+		Progress: func(done, total int, account AccountDiscovery) { progress++ },
+	}, discover)
+
+	if len(result.Skipped) != 2 {
+		t.Errorf("expected the suspended and excluded accounts to be skipped, got %d", len(result.Skipped))
+	}
+	if len(result.Accounts) != 3 || progress != 3 {
+		t.Fatalf("expected 3 accounts discovered with progress, got %d and %d", len(result.Accounts), progress)
+	}
+	for _, account := range result.Accounts {
+		switch account.Account.ID {
+		case "444444444444":
+			if account.Error == "" {
+				t.Error("expected the failed account to report its error")
+			}
+		case "333333333333":
+			if account.Error != "" || len(account.Resources) != 2 || len(account.Errors) != 1 {
+				t.Errorf("expected the service failure reported alongside the resources, got %+v", account)
+			}
+		default:
+			if len(account.Resources) != 2 {
+				t.Errorf("expected a resource per region for %s, got %d", account.Account.ID, len(account.Resources))
+			}
+			for _, resource := range account.Resources {
+				if resource.AccountID != account.Account.ID {
+					t.Errorf("expected resources to carry account %s, got %q", account.Account.ID, resource.AccountID)
+				}
+			}
+		}
+	}
+}
+
+func TestPartitionForRegion(t *testing.T) {
+	for region, partition := range map[string]string{"us-east-1": "aws", "cn-north-1": "aws-cn", "us-gov-west-1": "aws-us-gov"} {
+		if got := partitionForRegion(region); got != partition {
+			t.Errorf("partitionForRegion(%s) = %s, want %s", region, got, partition)
+		}
+	}
+}
@@ -0,0 +1,18 @@
+package aws
+
+import (
+	_ "embed"
+
+	"github.com/catherinevee/driftmgr/pkg/models"
+)
+
+// discoveryPermissions lists the IAM actions discovery calls, by the service its listers
+// report failures as. Add a lister's actions here when adding the lister.
+//
+//go:embed discovery_permissions.json
+var discoveryPermissions []byte
+
+// DiscoveryPermissions returns the IAM actions discovery needs, by service
+func DiscoveryPermissions() ([]models.ServicePermissions, error) {
+	return models.ParseServicePermissions(discoveryPermissions)
+}
@@ -0,0 +1,28 @@
+package aws
+
+import "testing"
+
+func TestDiscoveryPermissionsCoverListers(t *testing.T) {
+	permissions, err := DiscoveryPermissions()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	services := make(map[string]bool)
+	for _, service := range permissions {
+		services[service.Service] = true
+	}
+
+	provider := NewAWSProvider("us-east-1")
+	expected := []string{taggingService}
+	for _, lister := range provider.serviceListers() {
+		expected = append(expected, lister.service)
+	}
+	for _, lister := range provider.taggingFallbackTypes() {
+		expected = append(expected, lister.service)
+	}
+	for _, service := range expected {
+		if !services[service] {
+			t.Errorf("expected the permissions discovery of %s needs to be listed", service)
+		}
+	}
+}
@@ -3,7 +3,9 @@ package aws
 import (
 	"context"
 	"fmt"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
@@ -49,11 +51,25 @@ func NewAWSProvider(region string) *AWSProvider {
 
 // Initialize initializes the AWS clients
 func (p *AWSProvider) Initialize(ctx context.Context) error {
-	cfg, err := config.LoadDefaultConfig(ctx, config.WithRegion(p.region))
+	cfg, err := config.LoadDefaultConfig(ctx, config.WithRegion(p.region), config.WithRetryer(newRetryer))
 	if err != nil {
 		return fmt.Errorf("failed to load AWS config: %w", err)
 	}
 
+	p.useConfig(cfg)
+	return nil
+}
+
+// NewAWSProviderWithConfig creates a provider using an already loaded config, such as one
+// with credentials for a role assumed in another account
+func NewAWSProviderWithConfig(cfg aws.Config) *AWSProvider {
+	p := &AWSProvider{region: cfg.Region}
+	p.useConfig(cfg)
+	return p
+}
+
+// useConfig creates the service clients from cfg
+func (p *AWSProvider) useConfig(cfg aws.Config) {
 	p.awsConfig = cfg
 	p.ec2Client = ec2.NewFromConfig(cfg)
 	p.s3Client = s3.NewFromConfig(cfg)
@@ -61,8 +77,6 @@ func (p *AWSProvider) Initialize(ctx context.Context) error {
 	p.iamClient = iam.NewFromConfig(cfg)
 	p.lambdaClient = lambda.NewFromConfig(cfg)
 	p.dynamoClient = dynamodb.NewFromConfig(cfg)
-
-	return nil
 }
 
 // GetResource retrieves a specific resource by ID (implements CloudProvider interface)
@@ -135,34 +149,75 @@ func (p *AWSProvider) GetResourceByType(ctx context.Context, resourceType string
 	}
 }
 
-// ListResources lists all AWS resources
-func (p *AWSProvider) ListResources(ctx context.Context) ([]*models.Resource, error) {
-	// Initialize if not already done
+// serviceLister lists the resources of one service
+type serviceLister struct {
+	service string
+	list    func(context.Context) ([]*models.Resource, error)
+}
+
+// serviceListers lists the services DiscoverServices scans
+func (p *AWSProvider) serviceListers() []serviceLister {
+	return []serviceLister{
+		{"EC2", p.listEC2Instances},
+		{"S3", p.listS3Buckets},
+		// The network resources that place and connect them
+		{"VPC", p.listNetworkResources},
+	}
+}
+
+// DiscoverServices lists the region's resources service by service. A service that fails,
+// such as EC2 when the role lacks ec2:DescribeInstances, is reported in the result's Errors
+// alongside the resources of the services that did not.
+func (p *AWSProvider) DiscoverServices(ctx context.Context) (*models.DiscoveryResult, error) {
 	if p.ec2Client == nil {
 		if err := p.Initialize(ctx); err != nil {
 			return nil, err
 		}
 	}
 
-	resources := []*models.Resource{}
-
-	// List EC2 instances
-	instances, err := p.listEC2Instances(ctx)
-	if err == nil {
-		resources = append(resources, instances...)
+	result := &models.DiscoveryResult{Resources: []models.Resource{}, Timestamp: time.Now()}
+	for _, lister := range p.serviceListers() {
+		resources, err := lister.list(ctx)
+		for _, resource := range resources {
+			result.Resources = append(result.Resources, *resource)
+		}
+		if err != nil {
+			result.Errors = append(result.Errors, p.discoveryError(lister.service, "", err))
+		}
 	}
+	return result, nil
+}
 
-	// List S3 buckets
-	buckets, err := p.listS3Buckets(ctx)
-	if err == nil {
-		resources = append(resources, buckets...)
+// ListResources lists all AWS resources. Services that fail are skipped, and an error is
+// returned only when every service fails; DiscoverServices reports each failure.
+func (p *AWSProvider) ListResources(ctx context.Context) ([]*models.Resource, error) {
+	result, err := p.DiscoverServices(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(result.Errors) == len(p.serviceListers()) {
+		return nil, fmt.Errorf("AWS discovery failed: %s", result.Errors[0])
 	}
 
-	// Add other resource types as needed
-
+	resources := make([]*models.Resource, len(result.Resources))
+	for i := range result.Resources {
+		resources[i] = &result.Resources[i]
+	}
 	return resources, nil
 }
 
+// discoveryError describes a service that could not be discovered in the provider's region
+func (p *AWSProvider) discoveryError(service, resourceType string, err error) models.DiscoveryError {
+	return models.DiscoveryError{
+		Service:      service,
+		ResourceType: resourceType,
+		Region:       p.region,
+		Code:         apiErrorCode(err),
+		Error:        err.Error(),
+		Timestamp:    time.Now(),
+	}
+}
+
 // GetProviderName returns the provider name
 func (p *AWSProvider) GetProviderName() string {
 	return "aws"
@@ -201,19 +256,61 @@ func (p *AWSProvider) DiscoverResources(ctx context.Context, region string) ([]m
 
 // ValidateCredentials checks if the provider credentials are valid (implements CloudProvider interface)
 func (p *AWSProvider) ValidateCredentials(ctx context.Context) error {
-	// Initialize will validate credentials
-	return p.Initialize(ctx)
+	if err := p.Initialize(ctx); err != nil {
+		return err
+	}
+	// Loading the config succeeds without credentials, so resolve them as well
+	if _, err := p.awsConfig.Credentials.Retrieve(ctx); err != nil {
+		return fmt.Errorf("failed to retrieve AWS credentials: %w", err)
+	}
+	return nil
 }
 
-// ListRegions returns available regions for the provider (implements CloudProvider interface)
+// ListRegions returns the regions enabled for the account, including opt-in regions it has
+// opted in to (implements CloudProvider interface)
 func (p *AWSProvider) ListRegions(ctx context.Context) ([]string, error) {
-	// Return common AWS regions
-	return []string{
-		"us-east-1", "us-east-2", "us-west-1", "us-west-2",
-		"eu-west-1", "eu-west-2", "eu-west-3", "eu-central-1",
-		"ap-southeast-1", "ap-southeast-2", "ap-northeast-1", "ap-northeast-2",
-		"ap-south-1", "sa-east-1", "ca-central-1",
-	}, nil
+	enabled, _, err := p.RegionOptIns(ctx)
+	return enabled, err
+}
+
+// RegionOptIns lists every region of the partition through EC2 DescribeRegions and splits
+// them by the account's opt-in status: enabled regions can be scanned, while disabled ones
+// are opt-in regions the account has not opted in to, where every call fails with
+// AuthFailure
+func (p *AWSProvider) RegionOptIns(ctx context.Context) (enabled, disabled []string, err error) {
+	if p.ec2Client == nil {
+		if err := p.Initialize(ctx); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	result, err := p.ec2Client.DescribeRegions(ctx, &ec2.DescribeRegionsInput{AllRegions: aws.Bool(true)})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list AWS regions: %w", err)
+	}
+	enabled, disabled = splitRegionOptIns(result.Regions)
+	return enabled, disabled, nil
+}
+
+// splitRegionOptIns sorts regions into those the account can use and those it has not opted
+// in to. Regions still being enabled or disabled count as disabled, since calls to them fail.
+func splitRegionOptIns(regions []ec2types.Region) (enabled, disabled []string) {
+	enabled, disabled = []string{}, []string{}
+	for _, region := range regions {
+		name := aws.ToString(region.RegionName)
+		if name == "" {
+			continue
+		}
+		switch aws.ToString(region.OptInStatus) {
+		case "opt-in-not-required", "opted-in", "":
+			enabled = append(enabled, name)
+		default:
+			disabled = append(disabled, name)
+		}
+	}
+	sort.Strings(enabled)
+	sort.Strings(disabled)
+	return enabled, disabled
 }
 
 // SupportedResourceTypes returns the list of supported resource types (implements CloudProvider interface)
@@ -250,11 +347,12 @@ func (p *AWSProvider) getEC2Instance(ctx context.Context, instanceID string) (*m
 
 	// Convert to models.Resource
 	resource := &models.Resource{
-		ID:     *instance.InstanceId,
-		Type:   "aws_instance",
-		Name:   p.getTagValue(instance.Tags, "Name"),
-		Region: p.region,
-		Tags:   p.convertTags(instance.Tags),
+		ID:      *instance.InstanceId,
+		Type:    "aws_instance",
+		Name:    p.getTagValue(instance.Tags, "Name"),
+		Region:  p.region,
+		Tags:    p.convertTags(instance.Tags),
+		Created: aws.ToTime(instance.LaunchTime),
 		Attributes: map[string]interface{}{
 			"id":                *instance.InstanceId,
 			"instance_type":     string(instance.InstanceType),
@@ -303,16 +401,25 @@ func (p *AWSProvider) listEC2Instances(ctx context.Context) ([]*models.Resource,
 					continue
 				}
 
+				var securityGroups []string
+				for _, sg := range instance.SecurityGroups {
+					securityGroups = append(securityGroups, aws.ToString(sg.GroupId))
+				}
 				resource := &models.Resource{
-					ID:     *instance.InstanceId,
-					Type:   "aws_instance",
-					Name:   p.getTagValue(instance.Tags, "Name"),
-					Region: p.region,
-					Tags:   p.convertTags(instance.Tags),
+					ID:       *instance.InstanceId,
+					Type:     "aws_instance",
+					Name:     p.getTagValue(instance.Tags, "Name"),
+					Provider: "aws",
+					Region:   p.region,
+					Tags:     p.convertTags(instance.Tags),
+					Created:  aws.ToTime(instance.LaunchTime),
 					Attributes: map[string]interface{}{
-						"id":            *instance.InstanceId,
-						"instance_type": string(instance.InstanceType),
-						"state":         string(instance.State.Name),
+						"id":              *instance.InstanceId,
+						"instance_type":   string(instance.InstanceType),
+						"state":           string(instance.State.Name),
+						"subnet_id":       aws.ToString(instance.SubnetId),
+						"vpc_id":          aws.ToString(instance.VpcId),
+						"security_groups": securityGroups,
 					},
 				}
 				resources = append(resources, resource)
@@ -334,54 +441,19 @@ func (p *AWSProvider) getS3Bucket(ctx context.Context, bucketName string) (*mode
 		return nil, &NotFoundError{ResourceType: "aws_s3_bucket", ResourceID: bucketName}
 	}
 
-	// Get bucket location
-	location, err := p.s3Client.GetBucketLocation(ctx, &s3.GetBucketLocationInput{
-		Bucket: aws.String(bucketName),
-	})
-
-	region := "us-east-1"
-	if err == nil && location.LocationConstraint != "" {
-		region = string(location.LocationConstraint)
-	}
-
-	// Get bucket tags
-	tags := make(map[string]string)
-	tagging, err := p.s3Client.GetBucketTagging(ctx, &s3.GetBucketTaggingInput{
-		Bucket: aws.String(bucketName),
-	})
-	if err == nil {
-		for _, tag := range tagging.TagSet {
-			tags[*tag.Key] = *tag.Value
-		}
-	}
+	region := s3BucketRegion(ctx, p.s3Client, bucketName)
+	tags, attributes := DescribeS3Bucket(ctx, p.s3Client, bucketName, region)
+	attributes["id"] = bucketName
+	attributes["bucket"] = bucketName
+	attributes["region"] = region
 
 	resource := &models.Resource{
-		ID:     bucketName,
-		Type:   "aws_s3_bucket",
-		Name:   bucketName,
-		Region: region,
-		Tags:   tags,
-		Attributes: map[string]interface{}{
-			"id":     bucketName,
-			"bucket": bucketName,
-			"region": region,
-		},
-	}
-
-	// Get versioning status
-	versioning, err := p.s3Client.GetBucketVersioning(ctx, &s3.GetBucketVersioningInput{
-		Bucket: aws.String(bucketName),
-	})
-	if err == nil && versioning.Status != "" {
-		resource.Attributes["versioning"] = string(versioning.Status)
-	}
-
-	// Get encryption
-	encryption, err := p.s3Client.GetBucketEncryption(ctx, &s3.GetBucketEncryptionInput{
-		Bucket: aws.String(bucketName),
-	})
-	if err == nil && encryption.ServerSideEncryptionConfiguration != nil {
-		resource.Attributes["encryption"] = true
+		ID:         bucketName,
+		Type:       "aws_s3_bucket",
+		Name:       bucketName,
+		Region:     region,
+		Tags:       tags,
+		Attributes: attributes,
 	}
 
 	return resource, nil
@@ -396,17 +468,25 @@ func (p *AWSProvider) listS3Buckets(ctx context.Context) ([]*models.Resource, er
 	}
 
 	for _, bucket := range result.Buckets {
-		resource := &models.Resource{
-			ID:   *bucket.Name,
-			Type: "aws_s3_bucket",
-			Name: *bucket.Name,
-			Attributes: map[string]interface{}{
-				"id":            *bucket.Name,
-				"bucket":        *bucket.Name,
-				"creation_date": bucket.CreationDate.Format("2006-01-02T15:04:05Z"),
-			},
+		name := aws.ToString(bucket.Name)
+		region := s3BucketRegion(ctx, p.s3Client, name)
+		tags, attributes := DescribeS3Bucket(ctx, p.s3Client, name, region)
+		attributes["id"] = name
+		attributes["bucket"] = name
+		attributes["region"] = region
+		if bucket.CreationDate != nil {
+			attributes["creation_date"] = bucket.CreationDate.Format("2006-01-02T15:04:05Z")
 		}
-		resources = append(resources, resource)
+
+		resources = append(resources, &models.Resource{
+			ID:         name,
+			Type:       "aws_s3_bucket",
+			Name:       name,
+			Region:     region,
+			Tags:       tags,
+			Created:    aws.ToTime(bucket.CreationDate),
+			Attributes: attributes,
+		})
 	}
 
 	return resources, nil
@@ -430,10 +510,11 @@ func (p *AWSProvider) getRDSInstance(ctx context.Context, dbInstanceID string) (
 	dbInstance := result.DBInstances[0]
 
 	resource := &models.Resource{
-		ID:     *dbInstance.DBInstanceIdentifier,
-		Type:   "aws_db_instance",
-		Name:   *dbInstance.DBInstanceIdentifier,
-		Region: p.region,
+		ID:      *dbInstance.DBInstanceIdentifier,
+		Type:    "aws_db_instance",
+		Name:    *dbInstance.DBInstanceIdentifier,
+		Region:  p.region,
+		Created: aws.ToTime(dbInstance.InstanceCreateTime),
 		Attributes: map[string]interface{}{
 			"id":                  *dbInstance.DBInstanceIdentifier,
 			"db_instance_class":   *dbInstance.DBInstanceClass,
@@ -444,6 +525,7 @@ func (p *AWSProvider) getRDSInstance(ctx context.Context, dbInstanceID string) (
 			"status":              *dbInstance.DBInstanceStatus,
 			"multi_az":            *dbInstance.MultiAZ,
 			"publicly_accessible": *dbInstance.PubliclyAccessible,
+			"storage_encrypted":   aws.ToBool(dbInstance.StorageEncrypted),
 		},
 	}
 
@@ -480,10 +562,11 @@ func (p *AWSProvider) getIAMRole(ctx context.Context, roleName string) (*models.
 	}
 
 	resource := &models.Resource{
-		ID:   *role.RoleName,
-		Type: "aws_iam_role",
-		Name: *role.RoleName,
-		Tags: tags,
+		ID:      *role.RoleName,
+		Type:    "aws_iam_role",
+		Name:    *role.RoleName,
+		Tags:    tags,
+		Created: aws.ToTime(role.CreateDate),
 		Attributes: map[string]interface{}{
 			"id":                   *role.RoleName,
 			"name":                 *role.RoleName,
@@ -576,11 +659,12 @@ func (p *AWSProvider) getDynamoDBTable(ctx context.Context, tableName string) (*
 	}
 
 	resource := &models.Resource{
-		ID:     *table.TableName,
-		Type:   "aws_dynamodb_table",
-		Name:   *table.TableName,
-		Region: p.region,
-		Tags:   tags,
+		ID:      *table.TableName,
+		Type:    "aws_dynamodb_table",
+		Name:    *table.TableName,
+		Region:  p.region,
+		Tags:    tags,
+		Created: aws.ToTime(table.CreationDateTime),
 		Attributes: map[string]interface{}{
 			"id":            *table.TableName,
 			"name":          *table.TableName,
@@ -631,23 +715,8 @@ func (p *AWSProvider) getSecurityGroup(ctx context.Context, groupID string) (*mo
 		},
 	}
 
-	// Add ingress rules
-	if len(sg.IpPermissions) > 0 {
-		ingress := make([]map[string]interface{}, len(sg.IpPermissions))
-		for i, rule := range sg.IpPermissions {
-			ingress[i] = p.convertSecurityGroupRule(rule)
-		}
-		resource.Attributes["ingress"] = ingress
-	}
-
-	// Add egress rules
-	if len(sg.IpPermissionsEgress) > 0 {
-		egress := make([]map[string]interface{}, len(sg.IpPermissionsEgress))
-		for i, rule := range sg.IpPermissionsEgress {
-			egress[i] = p.convertSecurityGroupRule(rule)
-		}
-		resource.Attributes["egress"] = egress
-	}
+	resource.Attributes["ingress"] = SecurityGroupRules(*sg.GroupId, sg.IpPermissions)
+	resource.Attributes["egress"] = SecurityGroupRules(*sg.GroupId, sg.IpPermissionsEgress)
 
 	return resource, nil
 }
@@ -745,36 +814,3 @@ func (p *AWSProvider) convertTags(tags []ec2types.Tag) map[string]string {
 	return result
 }
 
-func (p *AWSProvider) convertSecurityGroupRule(rule ec2types.IpPermission) map[string]interface{} {
-	result := map[string]interface{}{}
-
-	if rule.FromPort != nil {
-		result["from_port"] = *rule.FromPort
-	}
-	if rule.ToPort != nil {
-		result["to_port"] = *rule.ToPort
-	}
-	if rule.IpProtocol != nil {
-		result["protocol"] = *rule.IpProtocol
-	}
-
-	// Add CIDR blocks
-	if len(rule.IpRanges) > 0 {
-		cidrs := make([]string, len(rule.IpRanges))
-		for i, r := range rule.IpRanges {
-			cidrs[i] = *r.CidrIp
-		}
-		result["cidr_blocks"] = cidrs
-	}
-
-	// Add security groups
-	if len(rule.UserIdGroupPairs) > 0 {
-		sgs := make([]string, len(rule.UserIdGroupPairs))
-		for i, sg := range rule.UserIdGroupPairs {
-			sgs[i] = *sg.GroupId
-		}
-		result["security_groups"] = sgs
-	}
-
-	return result
-}
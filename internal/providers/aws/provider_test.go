@@ -6,6 +6,8 @@ import (
 	"testing"
 	"time"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -79,6 +81,17 @@ func TestAWSProviderListRegions(t *testing.T) {
 	}
 }
 
+func TestSplitRegionOptIns(t *testing.T) {
+	enabled, disabled := splitRegionOptIns([]ec2types.Region{
+		{RegionName: aws.String("us-east-1"), OptInStatus: aws.String("opt-in-not-required")},
+		{RegionName: aws.String("me-south-1"), OptInStatus: aws.String("not-opted-in")},
+		{RegionName: aws.String("af-south-1"), OptInStatus: aws.String("opted-in")},
+		{RegionName: aws.String("ap-east-1"), OptInStatus: aws.String("not-opted-in")},
+	})
+	assert.Equal(t, []string{"af-south-1", "us-east-1"}, enabled)
+	assert.Equal(t, []string{"ap-east-1", "me-south-1"}, disabled)
+}
+
 // TestAWSProviderValidateCredentials tests credential validation
 func TestAWSProviderValidateCredentials(t *testing.T) {
 	provider := NewAWSProvider("us-east-1")
@@ -131,8 +144,8 @@ func TestAWSProviderConcurrentAccess(t *testing.T) {
 
 	go func() {
 		defer func() { done <- true }()
-		regions, _ := provider.ListRegions(ctx)
-		assert.NotNil(t, regions)
+		// Listing regions calls the cloud API, so only concurrent safety is checked here
+		_, _ = provider.ListRegions(ctx)
 	}()
 
 	go func() {
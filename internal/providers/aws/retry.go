@@ -0,0 +1,34 @@
+package aws
+
+import (
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
+	"github.com/catherinevee/driftmgr/internal/shared/backoff"
+)
+
+// newRetryer builds the SDK retryer used by every AWS client. It applies the shared
+// backoff policy with jittered delays and counts each retry so throttling is visible.
+func newRetryer() aws.Retryer {
+	policy := backoff.CurrentPolicy()
+	standard := retry.NewStandard(func(o *retry.StandardOptions) {
+		o.MaxAttempts = policy.MaxAttempts
+		o.MaxBackoff = policy.MaxDelay
+		o.Backoff = retry.BackoffDelayerFunc(func(attempt int, err error) (time.Duration, error) {
+			return policy.Delay(attempt), nil
+		})
+	})
+	return &countingRetryer{Retryer: standard}
+}
+
+// countingRetryer records a retry metric each time the SDK schedules a retry
+type countingRetryer struct {
+	aws.Retryer
+}
+
+// RetryDelay records the retry and defers to the wrapped retryer
+func (r *countingRetryer) RetryDelay(attempt int, err error) (time.Duration, error) {
+	backoff.RecordRetry("aws")
+	return r.Retryer.RetryDelay(attempt, err)
+}
@@ -0,0 +1,163 @@
+package aws
+
+import (
+	"context"
+	"errors"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go"
+)
+
+// S3 error codes meaning a bucket has no such configuration, as opposed to it being unreadable
+const (
+	s3NoPublicAccessBlock = "NoSuchPublicAccessBlockConfiguration"
+	s3NoBucketPolicy      = "NoSuchBucketPolicy"
+	s3NoEncryptionConfig  = "ServerSideEncryptionConfigurationNotFoundError"
+)
+
+// s3VersioningNeverSet is the status recorded for a bucket that never had versioning enabled,
+// which S3 reports as an empty status
+const s3VersioningNeverSet = "Disabled"
+
+// DescribeS3Bucket reads a bucket's tags and its versioning, public access block, policy
+// status and default encryption, with each call made in the bucket's region. Settings are
+// returned as attributes shaped like the Terraform state of aws_s3_bucket and its companion
+// resources so drift can be detected on them. A setting that could not be read is left out
+// rather than reported as disabled.
+func DescribeS3Bucket(ctx context.Context, client *s3.Client, bucket, region string) (map[string]string, map[string]interface{}) {
+	inRegion := func(o *s3.Options) {
+		if region != "" {
+			o.Region = region
+		}
+	}
+	tags := make(map[string]string)
+	attributes := make(map[string]interface{})
+
+	tagging, err := client.GetBucketTagging(ctx, &s3.GetBucketTaggingInput{Bucket: aws.String(bucket)}, inRegion)
+	if err == nil {
+		for _, tag := range tagging.TagSet {
+			tags[aws.ToString(tag.Key)] = aws.ToString(tag.Value)
+		}
+	}
+
+	if versioning, err := client.GetBucketVersioning(ctx, &s3.GetBucketVersioningInput{Bucket: aws.String(bucket)}, inRegion); err == nil {
+		addS3VersioningAttributes(attributes, versioning.Status, versioning.MFADelete)
+	}
+
+	publicAccess, err := client.GetPublicAccessBlock(ctx, &s3.GetPublicAccessBlockInput{Bucket: aws.String(bucket)}, inRegion)
+	switch {
+	case err == nil:
+		addS3PublicAccessAttributes(attributes, publicAccess.PublicAccessBlockConfiguration)
+	case apiErrorCode(err) == s3NoPublicAccessBlock:
+		addS3PublicAccessAttributes(attributes, nil)
+	}
+
+	policyStatus, err := client.GetBucketPolicyStatus(ctx, &s3.GetBucketPolicyStatusInput{Bucket: aws.String(bucket)}, inRegion)
+	switch {
+	case err == nil && policyStatus.PolicyStatus != nil:
+		attributes["policy_is_public"] = aws.ToBool(policyStatus.PolicyStatus.IsPublic)
+	case apiErrorCode(err) == s3NoBucketPolicy:
+		attributes["policy_is_public"] = false
+	}
+
+	encryption, err := client.GetBucketEncryption(ctx, &s3.GetBucketEncryptionInput{Bucket: aws.String(bucket)}, inRegion)
+	switch {
+	case err == nil:
+		addS3EncryptionAttributes(attributes, encryption.ServerSideEncryptionConfiguration)
+	case apiErrorCode(err) == s3NoEncryptionConfig:
+		addS3EncryptionAttributes(attributes, nil)
+	}
+
+	return tags, attributes
+}
+
+// s3BucketRegion returns the region a bucket lives in, defaulting to us-east-1
+func s3BucketRegion(ctx context.Context, client *s3.Client, bucket string) string {
+	location, err := client.GetBucketLocation(ctx, &s3.GetBucketLocationInput{Bucket: aws.String(bucket)})
+	if err != nil || location.LocationConstraint == "" {
+		return "us-east-1"
+	}
+	return string(location.LocationConstraint)
+}
+
+// addS3VersioningAttributes records versioning as both the aws_s3_bucket versioning block and
+// the aws_s3_bucket_versioning configuration block
+func addS3VersioningAttributes(attributes map[string]interface{}, status s3types.BucketVersioningStatus, mfaDelete s3types.MFADeleteStatus) {
+	statusValue := string(status)
+	if statusValue == "" {
+		statusValue = s3VersioningNeverSet
+	}
+	mfaDeleteValue := string(mfaDelete)
+	if mfaDeleteValue == "" {
+		mfaDeleteValue = string(s3types.MFADeleteStatusDisabled)
+	}
+
+	attributes["versioning"] = []interface{}{map[string]interface{}{
+		"enabled":    statusValue == string(s3types.BucketVersioningStatusEnabled),
+		"mfa_delete": mfaDeleteValue == string(s3types.MFADeleteStatusEnabled),
+	}}
+	attributes["versioning_configuration"] = []interface{}{map[string]interface{}{
+		"status":     statusValue,
+		"mfa_delete": mfaDeleteValue,
+	}}
+}
+
+// addS3PublicAccessAttributes records the public access block as the flat
+// aws_s3_bucket_public_access_block fields and a public_access_block list, empty when the
+// bucket has none
+func addS3PublicAccessAttributes(attributes map[string]interface{}, block *s3types.PublicAccessBlockConfiguration) {
+	if block == nil {
+		block = &s3types.PublicAccessBlockConfiguration{}
+	}
+	settings := map[string]interface{}{
+		"block_public_acls":       aws.ToBool(block.BlockPublicAcls),
+		"block_public_policy":     aws.ToBool(block.BlockPublicPolicy),
+		"ignore_public_acls":      aws.ToBool(block.IgnorePublicAcls),
+		"restrict_public_buckets": aws.ToBool(block.RestrictPublicBuckets),
+	}
+	for key, value := range settings {
+		attributes[key] = value
+	}
+
+	attributes["public_access_block"] = []interface{}{}
+	if aws.ToBool(block.BlockPublicAcls) || aws.ToBool(block.BlockPublicPolicy) ||
+		aws.ToBool(block.IgnorePublicAcls) || aws.ToBool(block.RestrictPublicBuckets) {
+		attributes["public_access_block"] = []interface{}{settings}
+	}
+}
+
+// addS3EncryptionAttributes records default encryption as the aws_s3_bucket
+// server_side_encryption_configuration block, empty when the bucket has none
+func addS3EncryptionAttributes(attributes map[string]interface{}, config *s3types.ServerSideEncryptionConfiguration) {
+	var rules []interface{}
+	if config != nil {
+		for _, rule := range config.Rules {
+			defaults := map[string]interface{}{}
+			if rule.ApplyServerSideEncryptionByDefault != nil {
+				defaults["sse_algorithm"] = string(rule.ApplyServerSideEncryptionByDefault.SSEAlgorithm)
+				defaults["kms_master_key_id"] = aws.ToString(rule.ApplyServerSideEncryptionByDefault.KMSMasterKeyID)
+			}
+			rules = append(rules, map[string]interface{}{
+				"apply_server_side_encryption_by_default": []interface{}{defaults},
+				"bucket_key_enabled":                      aws.ToBool(rule.BucketKeyEnabled),
+			})
+		}
+	}
+
+	attributes["encryption"] = len(rules) > 0
+	attributes["server_side_encryption_configuration"] = []interface{}{}
+	if len(rules) > 0 {
+		attributes["server_side_encryption_configuration"] = []interface{}{map[string]interface{}{"rule": rules}}
+	}
+}
+
+// apiErrorCode returns the AWS API error code of err, or "" when it is not an API error
+func apiErrorCode(err error) string {
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.ErrorCode()
+	}
+	return ""
+}
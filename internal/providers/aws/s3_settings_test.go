@@ -0,0 +1,57 @@
+package aws
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestS3VersioningAttributes(t *testing.T) {
+	attributes := map[string]interface{}{}
+	addS3VersioningAttributes(attributes, "", "")
+
+	assert.Equal(t, []interface{}{map[string]interface{}{"enabled": false, "mfa_delete": false}}, attributes["versioning"])
+	assert.Equal(t, []interface{}{map[string]interface{}{"status": "Disabled", "mfa_delete": "Disabled"}}, attributes["versioning_configuration"])
+
+	addS3VersioningAttributes(attributes, s3types.BucketVersioningStatusEnabled, s3types.MFADeleteStatusEnabled)
+	assert.Equal(t, []interface{}{map[string]interface{}{"enabled": true, "mfa_delete": true}}, attributes["versioning"])
+}
+
+func TestS3PublicAccessAttributes(t *testing.T) {
+	attributes := map[string]interface{}{}
+	addS3PublicAccessAttributes(attributes, nil)
+	assert.Equal(t, false, attributes["block_public_policy"])
+	assert.Empty(t, attributes["public_access_block"], "a bucket without a block has an empty list")
+
+	addS3PublicAccessAttributes(attributes, &s3types.PublicAccessBlockConfiguration{
+		BlockPublicAcls:   aws.Bool(true),
+		BlockPublicPolicy: aws.Bool(true),
+	})
+	assert.Equal(t, true, attributes["block_public_acls"])
+	assert.Equal(t, false, attributes["restrict_public_buckets"])
+	assert.Len(t, attributes["public_access_block"], 1)
+}
+
+func TestS3EncryptionAttributes(t *testing.T) {
+	attributes := map[string]interface{}{}
+	addS3EncryptionAttributes(attributes, nil)
+	assert.Equal(t, false, attributes["encryption"])
+	assert.Empty(t, attributes["server_side_encryption_configuration"])
+
+	addS3EncryptionAttributes(attributes, &s3types.ServerSideEncryptionConfiguration{
+		Rules: []s3types.ServerSideEncryptionRule{{
+			ApplyServerSideEncryptionByDefault: &s3types.ServerSideEncryptionByDefault{
+				SSEAlgorithm:   s3types.ServerSideEncryptionAwsKms,
+				KMSMasterKeyID: aws.String("alias/logs"),
+			},
+		}},
+	})
+	assert.Equal(t, true, attributes["encryption"])
+	config := attributes["server_side_encryption_configuration"].([]interface{})[0].(map[string]interface{})
+	rule := config["rule"].([]interface{})[0].(map[string]interface{})
+	defaults := rule["apply_server_side_encryption_by_default"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, "aws:kms", defaults["sse_algorithm"])
+	assert.Equal(t, "alias/logs", defaults["kms_master_key_id"])
+}
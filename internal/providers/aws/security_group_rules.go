@@ -0,0 +1,64 @@
+package aws
+
+import (
+	"sort"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+)
+
+// SecurityGroupRules converts a security group's ingress or egress permissions to the shape of
+// the ingress and egress blocks in aws_security_group state: one map per permission with
+// protocol, from_port, to_port, cidr_blocks, ipv6_cidr_blocks, prefix_list_ids, security_groups
+// and self. Lists are sorted so the same rules always produce the same attributes.
+func SecurityGroupRules(groupID string, permissions []ec2types.IpPermission) []interface{} {
+	rules := make([]interface{}, 0, len(permissions))
+	for _, permission := range permissions {
+		rules = append(rules, securityGroupRule(groupID, permission))
+	}
+	return rules
+}
+
+// securityGroupRule converts one permission. A rule referencing the group itself is recorded
+// as self rather than in security_groups, as Terraform does.
+func securityGroupRule(groupID string, permission ec2types.IpPermission) map[string]interface{} {
+	var cidrs, ipv6Cidrs, prefixLists, groups []string
+	self := false
+	for _, r := range permission.IpRanges {
+		cidrs = append(cidrs, aws.ToString(r.CidrIp))
+	}
+	for _, r := range permission.Ipv6Ranges {
+		ipv6Cidrs = append(ipv6Cidrs, aws.ToString(r.CidrIpv6))
+	}
+	for _, r := range permission.PrefixListIds {
+		prefixLists = append(prefixLists, aws.ToString(r.PrefixListId))
+	}
+	for _, pair := range permission.UserIdGroupPairs {
+		if aws.ToString(pair.GroupId) == groupID {
+			self = true
+			continue
+		}
+		groups = append(groups, aws.ToString(pair.GroupId))
+	}
+
+	return map[string]interface{}{
+		"protocol":         aws.ToString(permission.IpProtocol),
+		"from_port":        int(aws.ToInt32(permission.FromPort)),
+		"to_port":          int(aws.ToInt32(permission.ToPort)),
+		"cidr_blocks":      sortedList(cidrs),
+		"ipv6_cidr_blocks": sortedList(ipv6Cidrs),
+		"prefix_list_ids":  sortedList(prefixLists),
+		"security_groups":  sortedList(groups),
+		"self":             self,
+	}
+}
+
+// sortedList returns values sorted as a []interface{}, the type decoded state lists have
+func sortedList(values []string) []interface{} {
+	sort.Strings(values)
+	list := make([]interface{}, len(values))
+	for i, value := range values {
+		list[i] = value
+	}
+	return list
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/aws/aws-sdk-go-v2/service/s3/types"
 
@@ -61,7 +62,7 @@ func (s *S3Service) convertBucketToResource(bucket types.Bucket) models.CloudRes
 			"bucket_name": bucket.Name,
 		},
 		LastDiscovered: time.Now(),
-		CreatedAt:      time.Now(),
+		CreatedAt:      aws.ToTime(bucket.CreationDate),
 		UpdatedAt:      time.Now(),
 	}
 
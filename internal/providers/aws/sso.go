@@ -0,0 +1,331 @@
+package aws
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials/ssocreds"
+	"github.com/aws/aws-sdk-go-v2/service/ssooidc"
+	ssooidctypes "github.com/aws/aws-sdk-go-v2/service/ssooidc/types"
+)
+
+const (
+	// ssoClientName is the name driftmgr registers with IAM Identity Center to sign in
+	ssoClientName = "driftmgr"
+	// ssoRefreshScope lets the SDK refresh the sign-in of sso-session profiles by itself
+	ssoRefreshScope = "sso:account:access"
+	// deviceCodeGrant is the OAuth grant type of the device authorization flow
+	deviceCodeGrant = "urn:ietf:params:oauth:grant-type:device_code"
+	// defaultSSOPollInterval is how often a device authorization is polled when IAM Identity
+	// Center does not say
+	defaultSSOPollInterval = 5 * time.Second
+)
+
+// ErrNotSSOProfile is returned for a profile that does not sign in through IAM Identity Center
+var ErrNotSSOProfile = errors.New("not an AWS SSO profile")
+
+// SSOProfile is a profile of the shared AWS config that signs in through IAM Identity Center,
+// either through an sso-session section or with the legacy sso_start_url setting
+type SSOProfile struct {
+	Name string `json:"name"`
+	// Session is the profile's sso-session section, empty for legacy profiles
+	Session   string `json:"session,omitempty"`
+	StartURL  string `json:"start_url"`
+	Region    string `json:"region"`
+	AccountID string `json:"account_id,omitempty"`
+	RoleName  string `json:"role_name,omitempty"`
+	// Active reports that AWS_PROFILE, or the default profile, selects this profile
+	Active bool `json:"active"`
+	// TokenExpiresAt is when the cached sign-in expires
+	TokenExpiresAt *time.Time `json:"token_expires_at,omitempty"`
+	// Refreshable reports that the SDK can refresh the cached sign-in by itself, which needs
+	// an sso-session profile signed in with a refresh token
+	Refreshable bool `json:"refreshable"`
+	// LoginRequired reports that there is no cached sign-in the SDK can use or refresh, so
+	// the device authorization flow has to run before the profile's credentials work
+	LoginRequired bool `json:"login_required"`
+}
+
+// ssoCachedToken is the sign-in the SDK and the AWS CLI cache in ~/.aws/sso/cache
+type ssoCachedToken struct {
+	AccessToken           string `json:"accessToken"`
+	ExpiresAt             string `json:"expiresAt"`
+	RefreshToken          string `json:"refreshToken,omitempty"`
+	ClientID              string `json:"clientId,omitempty"`
+	ClientSecret          string `json:"clientSecret,omitempty"`
+	RegistrationExpiresAt string `json:"registrationExpiresAt,omitempty"`
+	Region                string `json:"region,omitempty"`
+	StartURL              string `json:"startUrl,omitempty"`
+}
+
+// ActiveProfile returns the shared config profile the SDK loads credentials from
+func ActiveProfile() string {
+	for _, name := range []string{"AWS_PROFILE", "AWS_DEFAULT_PROFILE"} {
+		if profile := os.Getenv(name); profile != "" {
+			return profile
+		}
+	}
+	return "default"
+}
+
+// SSOProfiles returns the profiles of the shared AWS config that sign in through IAM
+// Identity Center, with the state of their cached sign-in
+func SSOProfiles(ctx context.Context) ([]SSOProfile, error) {
+	names, err := sharedConfigProfiles(sharedConfigFile())
+	if err != nil {
+		return nil, err
+	}
+	profiles := []SSOProfile{}
+	for _, name := range names {
+		profile, err := LoadSSOProfile(ctx, name)
+		if err != nil {
+			// Not an SSO profile, or one the SDK cannot load either
+			continue
+		}
+		profiles = append(profiles, *profile)
+	}
+	return profiles, nil
+}
+
+// LoadSSOProfile loads a profile of the shared AWS config, returning ErrNotSSOProfile when it
+// does not sign in through IAM Identity Center
+func LoadSSOProfile(ctx context.Context, name string) (*SSOProfile, error) {
+	shared, err := config.LoadSharedConfigProfile(ctx, name, func(o *config.LoadSharedConfigOptions) {
+		o.ConfigFiles = []string{sharedConfigFile()}
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load AWS profile %s: %w", name, err)
+	}
+
+	profile := &SSOProfile{
+		Name:      name,
+		StartURL:  shared.SSOStartURL,
+		Region:    shared.SSORegion,
+		AccountID: shared.SSOAccountID,
+		RoleName:  shared.SSORoleName,
+		Active:    name == ActiveProfile(),
+	}
+	if shared.SSOSession != nil {
+		profile.Session = shared.SSOSession.Name
+		profile.StartURL = shared.SSOSession.SSOStartURL
+		profile.Region = shared.SSOSession.SSORegion
+	}
+	if profile.StartURL == "" {
+		return nil, ErrNotSSOProfile
+	}
+	profile.readCachedToken(time.Now())
+	return profile, nil
+}
+
+// tokenCacheKey returns the key the SDK caches a profile's sign-in under: the sso-session
+// name, or the start URL of a legacy profile
+func (p *SSOProfile) tokenCacheKey() string {
+	if p.Session != "" {
+		return p.Session
+	}
+	return p.StartURL
+}
+
+// readCachedToken records when the profile's cached sign-in expires and whether it can be
+// used or refreshed
+func (p *SSOProfile) readCachedToken(now time.Time) {
+	p.LoginRequired = true
+	path, err := ssocreds.StandardCachedTokenFilepath(p.tokenCacheKey())
+	if err != nil {
+		return
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return
+	}
+	var token ssoCachedToken
+	if json.Unmarshal(data, &token) != nil || token.AccessToken == "" {
+		return
+	}
+	expires, err := time.Parse(time.RFC3339, token.ExpiresAt)
+	if err != nil {
+		return
+	}
+	p.TokenExpiresAt = &expires
+
+	// The SDK only refreshes the sign-in of sso-session profiles, while the client it
+	// signed in with is still registered
+	p.Refreshable = p.Session != "" && token.RefreshToken != "" && token.ClientID != "" && token.ClientSecret != ""
+	if registration, err := time.Parse(time.RFC3339, token.RegistrationExpiresAt); err == nil && !registration.After(now) {
+		p.Refreshable = false
+	}
+	p.LoginRequired = !expires.After(now) && !p.Refreshable
+}
+
+// SSOLogin is a device authorization started for an SSO profile: the user opens
+// VerificationURI and confirms UserCode, while Wait polls for the sign-in
+type SSOLogin struct {
+	Profile         string    `json:"profile"`
+	VerificationURI string    `json:"verification_uri"`
+	UserCode        string    `json:"user_code"`
+	ExpiresAt       time.Time `json:"expires_at"`
+
+	sso                   SSOProfile
+	client                *ssooidc.Client
+	clientID              string
+	clientSecret          string
+	registrationExpiresAt time.Time
+	deviceCode            string
+	interval              time.Duration
+}
+
+// StartSSOLogin registers driftmgr with the profile's IAM Identity Center and starts a
+// device authorization for it
+func StartSSOLogin(ctx context.Context, profile SSOProfile) (*SSOLogin, error) {
+	client := ssooidc.NewFromConfig(aws.Config{Region: profile.Region})
+
+	register := &ssooidc.RegisterClientInput{ClientName: aws.String(ssoClientName), ClientType: aws.String("public")}
+	if profile.Session != "" {
+		register.Scopes = []string{ssoRefreshScope}
+	}
+	registration, err := client.RegisterClient(ctx, register)
+	if err != nil {
+		return nil, fmt.Errorf("failed to register with IAM Identity Center: %w", err)
+	}
+	authorization, err := client.StartDeviceAuthorization(ctx, &ssooidc.StartDeviceAuthorizationInput{
+		ClientId:     registration.ClientId,
+		ClientSecret: registration.ClientSecret,
+		StartUrl:     aws.String(profile.StartURL),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to start device authorization: %w", err)
+	}
+
+	login := &SSOLogin{
+		Profile:               profile.Name,
+		VerificationURI:       aws.ToString(authorization.VerificationUriComplete),
+		UserCode:              aws.ToString(authorization.UserCode),
+		ExpiresAt:             time.Now().Add(time.Duration(authorization.ExpiresIn) * time.Second),
+		sso:                   profile,
+		client:                client,
+		clientID:              aws.ToString(registration.ClientId),
+		clientSecret:          aws.ToString(registration.ClientSecret),
+		registrationExpiresAt: time.Unix(registration.ClientSecretExpiresAt, 0),
+		deviceCode:            aws.ToString(authorization.DeviceCode),
+		interval:              time.Duration(authorization.Interval) * time.Second,
+	}
+	if login.VerificationURI == "" {
+		login.VerificationURI = aws.ToString(authorization.VerificationUri)
+	}
+	if login.interval <= 0 {
+		login.interval = defaultSSOPollInterval
+	}
+	return login, nil
+}
+
+// Wait polls until the user confirms the device authorization, then caches the sign-in
+// where the SDK and the AWS CLI look for it. It fails when the authorization is denied or
+// expires, or ctx is done.
+func (l *SSOLogin) Wait(ctx context.Context) error {
+	ctx, cancel := context.WithDeadline(ctx, l.ExpiresAt)
+	defer cancel()
+
+	interval := l.interval
+	for {
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("device authorization for %s was not confirmed: %w", l.Profile, ctx.Err())
+		case <-time.After(interval):
+		}
+
+		token, err := l.client.CreateToken(ctx, &ssooidc.CreateTokenInput{
+			ClientId:     aws.String(l.clientID),
+			ClientSecret: aws.String(l.clientSecret),
+			GrantType:    aws.String(deviceCodeGrant),
+			DeviceCode:   aws.String(l.deviceCode),
+		})
+		var pending *ssooidctypes.AuthorizationPendingException
+		var slowDown *ssooidctypes.SlowDownException
+		switch {
+		case errors.As(err, &pending):
+			continue
+		case errors.As(err, &slowDown):
+			interval += defaultSSOPollInterval
+			continue
+		case err != nil:
+			return fmt.Errorf("device authorization for %s failed: %w", l.Profile, err)
+		}
+
+		return writeCachedToken(l.sso.tokenCacheKey(), ssoCachedToken{
+			AccessToken:           aws.ToString(token.AccessToken),
+			ExpiresAt:             time.Now().Add(time.Duration(token.ExpiresIn) * time.Second).UTC().Format(time.RFC3339),
+			RefreshToken:          aws.ToString(token.RefreshToken),
+			ClientID:              l.clientID,
+			ClientSecret:          l.clientSecret,
+			RegistrationExpiresAt: l.registrationExpiresAt.UTC().Format(time.RFC3339),
+			Region:                l.sso.Region,
+			StartURL:              l.sso.StartURL,
+		})
+	}
+}
+
+// writeCachedToken caches a sign-in under key, readable only by the current user
+func writeCachedToken(key string, token ssoCachedToken) error {
+	path, err := ssocreds.StandardCachedTokenFilepath(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create SSO token cache: %w", err)
+	}
+	data, err := json.Marshal(token)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("failed to cache SSO sign-in: %w", err)
+	}
+	return nil
+}
+
+// sharedConfigFile returns the shared AWS config file the SDK reads
+func sharedConfigFile() string {
+	if path := os.Getenv("AWS_CONFIG_FILE"); path != "" {
+		return path
+	}
+	return config.DefaultSharedConfigFilename()
+}
+
+// sharedConfigProfiles returns the names of the profiles in a shared AWS config file, from
+// its [default] and [profile name] sections
+func sharedConfigProfiles(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read AWS config: %w", err)
+	}
+	defer file.Close()
+
+	var names []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if !strings.HasPrefix(line, "[") || !strings.HasSuffix(line, "]") {
+			continue
+		}
+		section := strings.Fields(strings.Trim(line, "[]"))
+		switch {
+		case len(section) == 1 && section[0] == "default":
+			names = append(names, "default")
+		case len(section) == 2 && section[0] == "profile":
+			names = append(names, section[1])
+		}
+	}
+	return names, scanner.Err()
+}
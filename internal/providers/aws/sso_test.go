@@ -0,0 +1,89 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+const testSharedConfig = `[default]
+region = us-east-1
+
+[profile platform]
+sso_session = corp
+sso_account_id = 111111111111
+sso_role_name = ReadOnly
+
+[profile legacy]
+sso_start_url = https://legacy.awsapps.com/start
+sso_region = eu-west-1
+sso_account_id = 222222222222
+sso_role_name = Admin
+
+[profile keys]
+aws_access_key_id = AKIAEXAMPLE
+aws_secret_access_key = secret
+
+[sso-session corp]
+sso_start_url = https://corp.awsapps.com/start
+sso_region = us-east-2
+sso_registration_scopes = sso:account:access
+`
+
+func TestSSOProfiles(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("AWS_PROFILE", "platform")
+	configFile := filepath.Join(home, "config")
+	if err := os.WriteFile(configFile, []byte(testSharedConfig), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("AWS_CONFIG_FILE", configFile)
+
+	// The sso-session sign-in has expired but can be refreshed; the legacy one cannot
+	expired := time.Now().Add(-time.Hour).UTC().Format(time.RFC3339)
+	registered := time.Now().Add(30 * 24 * time.Hour).UTC().Format(time.RFC3339)
+	if err := writeCachedToken("corp", ssoCachedToken{AccessToken: "a", ExpiresAt: expired, RefreshToken: "r", ClientID: "c", ClientSecret: "s", RegistrationExpiresAt: registered}); err != nil {
+		t.Fatalf("failed to cache token: %v", err)
+	}
+	if err := writeCachedToken("https://legacy.awsapps.com/start", ssoCachedToken{AccessToken: "a", ExpiresAt: expired}); err != nil {
+		t.Fatalf("failed to cache token: %v", err)
+	}
+
+	profiles, err := SSOProfiles(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(profiles) != 2 {
+		t.Fatalf("expected the two SSO profiles, got %+v", profiles)
+	}
+	platform, legacy := profiles[0], profiles[1]
+	if platform.Name != "platform" || platform.Session != "corp" || platform.StartURL != "https://corp.awsapps.com/start" || platform.Region != "us-east-2" || !platform.Active {
+		t.Errorf("expected the sso-session profile's settings, got %+v", platform)
+	}
+	if !platform.Refreshable || platform.LoginRequired || platform.TokenExpiresAt == nil {
+		t.Errorf("expected the expired sso-session sign-in to be refreshable, got %+v", platform)
+	}
+	if legacy.Name != "legacy" || legacy.Region != "eu-west-1" || legacy.AccountID != "222222222222" || legacy.Active {
+		t.Errorf("expected the legacy profile's settings, got %+v", legacy)
+	}
+	if legacy.Refreshable || !legacy.LoginRequired {
+		t.Errorf("expected the expired legacy sign-in to need a login, got %+v", legacy)
+	}
+
+	if _, err := LoadSSOProfile(context.Background(), "keys"); !errors.Is(err, ErrNotSSOProfile) {
+		t.Errorf("expected ErrNotSSOProfile for a profile with static keys, got %v", err)
+	}
+}
+
+func TestSSOProfileWithoutSignIn(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	profile := &SSOProfile{Name: "platform", Session: "corp", StartURL: "https://corp.awsapps.com/start"}
+	profile.readCachedToken(time.Now())
+	if !profile.LoginRequired || profile.TokenExpiresAt != nil {
+		t.Errorf("expected a profile that never signed in to need a login, got %+v", profile)
+	}
+}
@@ -0,0 +1,331 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+
+	"github.com/catherinevee/driftmgr/pkg/models"
+)
+
+const (
+	// taggingTarget prefixes the JSON protocol operations of the Resource Groups Tagging API
+	taggingTarget = "ResourceGroupsTaggingAPI_20170126."
+	// taggingService is the service tagging API failures are reported as
+	taggingService = "Resource Groups Tagging"
+	// taggingPageSize is the largest page GetResources returns
+	taggingPageSize = 100
+	// taggingBatchSize is the most ARNs a single TagResources call accepts
+	taggingBatchSize = 20
+)
+
+// taggingPage is one page of a GetResources response
+type taggingPage struct {
+	PaginationToken        string `json:"PaginationToken"`
+	ResourceTagMappingList []struct {
+		ResourceARN string `json:"ResourceARN"`
+		Tags        []struct {
+			Key   string `json:"Key"`
+			Value string `json:"Value"`
+		} `json:"Tags"`
+	} `json:"ResourceTagMappingList"`
+}
+
+// taggingClient fetches pages of tagged resources in a region
+type taggingClient interface {
+	GetResources(ctx context.Context, paginationToken string) (*taggingPage, error)
+}
+
+// httpTaggingClient calls the Resource Groups Tagging API directly with SigV4-signed requests
+type httpTaggingClient struct {
+	jsonProtocolClient
+}
+
+// newTaggingClient creates a tagging API client for a region
+func newTaggingClient(cfg aws.Config, region string) *httpTaggingClient {
+	return &httpTaggingClient{newJSONProtocolClient(cfg, "tagging", region,
+		fmt.Sprintf("https://tagging.%s.amazonaws.com/", region), taggingTarget)}
+}
+
+// GetResources returns one page of the resources tagged in the client's region
+func (c *httpTaggingClient) GetResources(ctx context.Context, paginationToken string) (*taggingPage, error) {
+	input := map[string]interface{}{"ResourcesPerPage": taggingPageSize}
+	if paginationToken != "" {
+		input["PaginationToken"] = paginationToken
+	}
+	var page taggingPage
+	if err := c.call(ctx, "GetResources", input, &page); err != nil {
+		return nil, err
+	}
+	return &page, nil
+}
+
+// TagResources adds tags to up to taggingBatchSize resources, returning the error message of
+// each ARN that could not be tagged
+func (c *httpTaggingClient) TagResources(ctx context.Context, arns []string, tags map[string]string) (map[string]string, error) {
+	var output struct {
+		FailedResourcesMap map[string]struct {
+			ErrorCode    string `json:"ErrorCode"`
+			ErrorMessage string `json:"ErrorMessage"`
+		} `json:"FailedResourcesMap"`
+	}
+	if err := c.call(ctx, "TagResources", map[string]interface{}{"ResourceARNList": arns, "Tags": tags}, &output); err != nil {
+		return nil, err
+	}
+	failed := make(map[string]string, len(output.FailedResourcesMap))
+	for arn, failure := range output.FailedResourcesMap {
+		failed[arn] = strings.TrimSpace(failure.ErrorCode + " " + failure.ErrorMessage)
+	}
+	return failed, nil
+}
+
+// arnResourceTypes maps "service:resource-type" ARN prefixes to Terraform resource types.
+// Services whose ARNs carry no resource type (S3, SNS, SQS) are keyed by service alone;
+// anything unlisted is named aws_<service>_<resource-type>.
+var arnResourceTypes = map[string]string{
+	"ec2:instance":                      "aws_instance",
+	"ec2:security-group":                "aws_security_group",
+	"ec2:vpc":                           "aws_vpc",
+	"ec2:subnet":                        "aws_subnet",
+	"ec2:volume":                        "aws_ebs_volume",
+	"ec2:internet-gateway":              "aws_internet_gateway",
+	"ec2:natgateway":                    "aws_nat_gateway",
+	"ec2:route-table":                   "aws_route_table",
+	"s3":                                "aws_s3_bucket",
+	"sns":                               "aws_sns_topic",
+	"sqs":                               "aws_sqs_queue",
+	"rds:db":                            "aws_db_instance",
+	"rds:cluster":                       "aws_rds_cluster",
+	"lambda:function":                   "aws_lambda_function",
+	"dynamodb:table":                    "aws_dynamodb_table",
+	"elasticloadbalancing:loadbalancer": "aws_lb",
+	"elasticloadbalancing:targetgroup":  "aws_lb_target_group",
+	"kms:key":                           "aws_kms_key",
+	"secretsmanager:secret":             "aws_secretsmanager_secret",
+	"ecs:cluster":                       "aws_ecs_cluster",
+	"ecs:service":                       "aws_ecs_service",
+	"eks:cluster":                       "aws_eks_cluster",
+	"ecr:repository":                    "aws_ecr_repository",
+	"elasticache:cluster":               "aws_elasticache_cluster",
+	"cloudformation:stack":              "aws_cloudformation_stack",
+	"logs:log-group":                    "aws_cloudwatch_log_group",
+	"ssm:parameter":                     "aws_ssm_parameter",
+}
+
+// taggingFallbackTypes lists resource types the tagging API cannot return, such as
+// security group rules which are not taggable resources, with their per-service listers
+func (p *AWSProvider) taggingFallbackTypes() map[string]serviceLister {
+	return map[string]serviceLister{
+		"aws_security_group_rule": {"EC2", p.listSecurityGroupRules},
+	}
+}
+
+// DiscoverResourcesFast enumerates a region's resources through the Resource Groups Tagging
+// API, which needs only tag:GetResources and a handful of paginated calls instead of a scan
+// of every service. Resources are mapped from their ARNs, so attributes are limited to the
+// ARN and tags. Types the tagging API does not cover fall back to per-service discovery.
+// A service that cannot be discovered is reported in the result's Errors rather than
+// failing the region; an error is returned only when the provider cannot be initialized or
+// ctx is done.
+func (p *AWSProvider) DiscoverResourcesFast(ctx context.Context, region string) (*models.DiscoveryResult, error) {
+	if region != "" && region != p.region {
+		p.region = region
+		if p.ec2Client != nil {
+			// Keep the loaded credentials, which may be for an assumed role
+			cfg := p.awsConfig.Copy()
+			cfg.Region = region
+			p.useConfig(cfg)
+		}
+	}
+	if p.ec2Client == nil {
+		if err := p.Initialize(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	result := &models.DiscoveryResult{Resources: []models.Resource{}, Timestamp: time.Now()}
+	tagged, err := discoverTaggedResources(ctx, newTaggingClient(p.awsConfig, p.region))
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, err
+		}
+		result.Errors = append(result.Errors, p.discoveryError(taggingService, "", err))
+	}
+	result.Resources = append(result.Resources, tagged...)
+
+	for resourceType, lister := range p.taggingFallbackTypes() {
+		fallback, err := lister.list(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, err
+			}
+			result.Errors = append(result.Errors, p.discoveryError(lister.service, resourceType, err))
+		}
+		for _, resource := range fallback {
+			result.Resources = append(result.Resources, *resource)
+		}
+	}
+
+	return result, nil
+}
+
+// discoverTaggedResources pages through GetResources and maps each ARN to a resource
+func discoverTaggedResources(ctx context.Context, client taggingClient) ([]models.Resource, error) {
+	var resources []models.Resource
+	token := ""
+	for {
+		page, err := client.GetResources(ctx, token)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, mapping := range page.ResourceTagMappingList {
+			tags := make(map[string]string, len(mapping.Tags))
+			for _, tag := range mapping.Tags {
+				tags[tag.Key] = tag.Value
+			}
+			resource, ok := resourceFromARN(mapping.ResourceARN, tags)
+			if !ok {
+				continue
+			}
+			resources = append(resources, resource)
+		}
+
+		if page.PaginationToken == "" {
+			break
+		}
+		token = page.PaginationToken
+	}
+	return resources, nil
+}
+
+// resourceFromARN builds a resource from an ARN of the form
+// arn:partition:service:region:account:resource, where resource is "id",
+// "type/id" or "type:id"
+func resourceFromARN(arn string, tags map[string]string) (models.Resource, bool) {
+	parts := strings.SplitN(arn, ":", 6)
+	if len(parts) != 6 || parts[0] != "arn" {
+		return models.Resource{}, false
+	}
+	service, region, accountID, resourcePart := parts[2], parts[3], parts[4], parts[5]
+
+	kind, id := "", resourcePart
+	if i := strings.IndexAny(resourcePart, "/:"); i >= 0 {
+		kind, id = resourcePart[:i], resourcePart[i+1:]
+	}
+
+	resourceType, ok := arnResourceTypes[service+":"+kind]
+	if !ok {
+		resourceType, ok = arnResourceTypes[service]
+		if ok {
+			// Services without resource types use the whole resource part as the ID
+			id = resourcePart
+		}
+	}
+	if !ok {
+		resourceType = "aws_" + strings.ReplaceAll(service, "-", "_")
+		if kind != "" {
+			resourceType += "_" + strings.ReplaceAll(kind, "-", "_")
+		}
+	}
+
+	name := tags["Name"]
+	if name == "" {
+		name = id
+	}
+
+	return models.Resource{
+		ID:        id,
+		Name:      name,
+		Type:      resourceType,
+		Provider:  "aws",
+		Region:    region,
+		AccountID: accountID,
+		Tags:      tags,
+		Status:    "active",
+		Attributes: map[string]interface{}{
+			"arn": arn,
+		},
+	}, true
+}
+
+// listSecurityGroupRules lists each security group ingress and egress rule as a resource
+func (p *AWSProvider) listSecurityGroupRules(ctx context.Context) ([]*models.Resource, error) {
+	resources := []*models.Resource{}
+
+	paginator := ec2.NewDescribeSecurityGroupsPaginator(p.ec2Client, &ec2.DescribeSecurityGroupsInput{})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, sg := range page.SecurityGroups {
+			groupID := aws.ToString(sg.GroupId)
+			for direction, rules := range map[string][]ec2types.IpPermission{
+				"ingress": sg.IpPermissions,
+				"egress":  sg.IpPermissionsEgress,
+			} {
+				for i, rule := range rules {
+					attributes := securityGroupRule(groupID, rule)
+					attributes["type"] = direction
+					attributes["security_group_id"] = groupID
+
+					ruleID := fmt.Sprintf("%s-%s-%d", groupID, direction, i)
+					resources = append(resources, &models.Resource{
+						ID:         ruleID,
+						Name:       ruleID,
+						Type:       "aws_security_group_rule",
+						Provider:   "aws",
+						Region:     p.region,
+						Status:     "active",
+						Attributes: attributes,
+					})
+				}
+			}
+		}
+	}
+
+	return resources, nil
+}
+
+// resourceTaggingClient tags batches of resources by ARN
+type resourceTaggingClient interface {
+	TagResources(ctx context.Context, arns []string, tags map[string]string) (map[string]string, error)
+}
+
+// TagResources adds tags to resources in a region by ARN through the Resource Groups Tagging
+// API, twenty per call. Existing tags with other keys are kept. The returned map holds the
+// error of each ARN that could not be tagged.
+func TagResources(ctx context.Context, cfg aws.Config, region string, arns []string, tags map[string]string) map[string]error {
+	return tagResources(ctx, newTaggingClient(cfg, region), arns, tags)
+}
+
+// tagResources tags ARNs in batches, failing every ARN of a batch whose call fails
+func tagResources(ctx context.Context, client resourceTaggingClient, arns []string, tags map[string]string) map[string]error {
+	failed := make(map[string]error)
+	for start := 0; start < len(arns); start += taggingBatchSize {
+		end := start + taggingBatchSize
+		if end > len(arns) {
+			end = len(arns)
+		}
+		batch := arns[start:end]
+		failures, err := client.TagResources(ctx, batch, tags)
+		if err != nil {
+			for _, arn := range batch {
+				failed[arn] = err
+			}
+			continue
+		}
+		for arn, message := range failures {
+			failed[arn] = errors.New(message)
+		}
+	}
+	return failed
+}
@@ -0,0 +1,128 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+// fakeTaggingClient serves canned GetResources pages keyed by pagination token
+type fakeTaggingClient struct {
+	pages map[string]*taggingPage
+	calls int
+}
+
+func (f *fakeTaggingClient) GetResources(ctx context.Context, paginationToken string) (*taggingPage, error) {
+	f.calls++
+	return f.pages[paginationToken], nil
+}
+
+func taggingPageOf(token string, arns ...string) *taggingPage {
+	page := &taggingPage{PaginationToken: token}
+	for _, arn := range arns {
+		page.ResourceTagMappingList = append(page.ResourceTagMappingList, struct {
+			ResourceARN string `json:"ResourceARN"`
+			Tags        []struct {
+				Key   string `json:"Key"`
+				Value string `json:"Value"`
+			} `json:"Tags"`
+		}{ResourceARN: arn})
+	}
+	return page
+}
+
+func TestDiscoverTaggedResources(t *testing.T) {
+	client := &fakeTaggingClient{pages: map[string]*taggingPage{
+		"": taggingPageOf("page-2",
+			"arn:aws:ec2:us-east-1:123456789012:instance/i-0abc",
+			"arn:aws:s3:::my-bucket",
+		),
+		"page-2": taggingPageOf("",
+			"arn:aws:rds:us-east-1:123456789012:db:orders",
+			"not-an-arn",
+		),
+	}}
+
+	resources, err := discoverTaggedResources(context.Background(), client)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if client.calls != 2 {
+		t.Errorf("expected 2 GetResources calls, got %d", client.calls)
+	}
+	if len(resources) != 3 {
+		t.Fatalf("expected 3 resources, got %d", len(resources))
+	}
+}
+
+func TestResourceFromARN(t *testing.T) {
+	tests := []struct {
+		arn      string
+		wantType string
+		wantID   string
+	}{
+		{"arn:aws:ec2:us-east-1:123456789012:instance/i-0abc", "aws_instance", "i-0abc"},
+		{"arn:aws:s3:::my-bucket", "aws_s3_bucket", "my-bucket"},
+		{"arn:aws:rds:us-east-1:123456789012:db:orders", "aws_db_instance", "orders"},
+		{"arn:aws:lambda:us-east-1:123456789012:function:handler", "aws_lambda_function", "handler"},
+		{"arn:aws:sqs:us-east-1:123456789012:jobs", "aws_sqs_queue", "jobs"},
+		{"arn:aws:glue:us-east-1:123456789012:job/etl", "aws_glue_job", "etl"},
+	}
+
+	for _, tt := range tests {
+		resource, ok := resourceFromARN(tt.arn, map[string]string{})
+		if !ok {
+			t.Errorf("%s: expected the ARN to parse", tt.arn)
+			continue
+		}
+		if resource.Type != tt.wantType || resource.ID != tt.wantID {
+			t.Errorf("%s: expected %s %s, got %s %s", tt.arn, tt.wantType, tt.wantID, resource.Type, resource.ID)
+		}
+	}
+
+	resource, _ := resourceFromARN("arn:aws:ec2:eu-west-1:123456789012:vpc/vpc-1", map[string]string{"Name": "main"})
+	if resource.Name != "main" || resource.Region != "eu-west-1" || resource.AccountID != "123456789012" {
+		t.Errorf("expected name, region and account from the tags and ARN, got %+v", resource)
+	}
+}
+
+// fakeResourceTagger records TagResources batches and fails the ARNs and batches it is told to
+type fakeResourceTagger struct {
+	batches   [][]string
+	failARN   string
+	failBatch int
+}
+
+func (f *fakeResourceTagger) TagResources(ctx context.Context, arns []string, tags map[string]string) (map[string]string, error) {
+	f.batches = append(f.batches, arns)
+	if len(f.batches) == f.failBatch {
+		return nil, errors.New("throttled")
+	}
+	failed := map[string]string{}
+	for _, arn := range arns {
+		if arn == f.failARN {
+			failed[arn] = "AccessDeniedException not authorized"
+		}
+	}
+	return failed, nil
+}
+
+func TestTagResourcesBatches(t *testing.T) {
+	var arns []string
+	for i := 0; i < 45; i++ {
+		arns = append(arns, fmt.Sprintf("arn:aws:sqs:us-east-1:123:queue-%d", i))
+	}
+	client := &fakeResourceTagger{failARN: arns[3], failBatch: 3}
+
+	failed := tagResources(context.Background(), client, arns, map[string]string{"Owner": "team"})
+	if len(client.batches) != 3 || len(client.batches[0]) != 20 || len(client.batches[2]) != 5 {
+		t.Fatalf("expected batches of 20, 20 and 5, got %d batches", len(client.batches))
+	}
+	if len(failed) != 6 {
+		t.Fatalf("expected the failed ARN and the 5 ARNs of the failed batch, got %d: %v", len(failed), failed)
+	}
+	if failed[arns[3]].Error() != "AccessDeniedException not authorized" || failed[arns[44]].Error() != "throttled" {
+		t.Errorf("unexpected errors %v", failed)
+	}
+}
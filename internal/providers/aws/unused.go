@@ -0,0 +1,362 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
+	cwtypes "github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	elbv2 "github.com/aws/aws-sdk-go-v2/service/elasticloadbalancingv2"
+	"github.com/aws/aws-sdk-go-v2/service/rds"
+
+	"github.com/catherinevee/driftmgr/pkg/models"
+)
+
+const (
+	// idleLookback is how far back CloudWatch metrics are checked when judging idleness
+	idleLookback = 14 * 24 * time.Hour
+	// idleCPUPercent is the daily average CPU below which a running instance counts as idle
+	idleCPUPercent = 2.0
+)
+
+// UnusedResource is a resource that looks unused but is still billed
+type UnusedResource struct {
+	Resource models.Resource `json:"resource"`
+	Reason   string          `json:"reason"`
+	// Billable holds what is still charged for, such as the volumes of a stopped instance
+	Billable []models.Resource `json:"-"`
+}
+
+// metricsClient is the CloudWatch call used to judge idleness
+type metricsClient interface {
+	GetMetricStatistics(ctx context.Context, params *cloudwatch.GetMetricStatisticsInput, optFns ...func(*cloudwatch.Options)) (*cloudwatch.GetMetricStatisticsOutput, error)
+}
+
+// FindUnusedResources looks for resources in a region that are billed but likely unused:
+// stopped instances whose volumes are still charged, unattached volumes, unassociated
+// Elastic IPs, load balancers without targets, and instances and databases whose
+// CloudWatch metrics show no activity over the last 14 days.
+func (p *AWSProvider) FindUnusedResources(ctx context.Context, region string) ([]UnusedResource, error) {
+	if region != "" && region != p.region {
+		p.region = region
+		p.ec2Client = nil
+	}
+	if p.ec2Client == nil {
+		if err := p.Initialize(ctx); err != nil {
+			return nil, err
+		}
+	}
+	metrics := cloudwatch.NewFromConfig(p.awsConfig)
+
+	unattached, volumesByInstance, err := p.listVolumesByAttachment(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list volumes: %w", err)
+	}
+	var unused []UnusedResource
+	for _, volume := range unattached {
+		unused = append(unused, UnusedResource{
+			Resource: volume,
+			Reason:   "volume is not attached to an instance",
+			Billable: []models.Resource{volume},
+		})
+	}
+
+	instances, err := p.findUnusedInstances(ctx, metrics, volumesByInstance)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check instances: %w", err)
+	}
+	unused = append(unused, instances...)
+
+	addresses, err := p.findUnassociatedAddresses(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check Elastic IPs: %w", err)
+	}
+	unused = append(unused, addresses...)
+
+	loadBalancers, err := p.findEmptyLoadBalancers(ctx, elbv2.NewFromConfig(p.awsConfig))
+	if err != nil {
+		return nil, fmt.Errorf("failed to check load balancers: %w", err)
+	}
+	unused = append(unused, loadBalancers...)
+
+	databases, err := p.findIdleDatabases(ctx, metrics)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check databases: %w", err)
+	}
+	unused = append(unused, databases...)
+
+	return unused, nil
+}
+
+// listVolumesByAttachment returns unattached volumes and the volumes attached to each instance
+func (p *AWSProvider) listVolumesByAttachment(ctx context.Context) ([]models.Resource, map[string][]models.Resource, error) {
+	var unattached []models.Resource
+	byInstance := make(map[string][]models.Resource)
+
+	paginator := ec2.NewDescribeVolumesPaginator(p.ec2Client, &ec2.DescribeVolumesInput{})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, volume := range page.Volumes {
+			resource := models.Resource{
+				ID:       aws.ToString(volume.VolumeId),
+				Name:     p.getTagValue(volume.Tags, "Name"),
+				Type:     "aws_ebs_volume",
+				Provider: "aws",
+				Region:   p.region,
+				Status:   string(volume.State),
+				Tags:     p.convertTags(volume.Tags),
+				Attributes: map[string]interface{}{
+					"volume_type": string(volume.VolumeType),
+					"size":        aws.ToInt32(volume.Size),
+				},
+			}
+			if volume.State == ec2types.VolumeStateAvailable {
+				unattached = append(unattached, resource)
+				continue
+			}
+			for _, attachment := range volume.Attachments {
+				instanceID := aws.ToString(attachment.InstanceId)
+				byInstance[instanceID] = append(byInstance[instanceID], resource)
+			}
+		}
+	}
+
+	return unattached, byInstance, nil
+}
+
+// findUnusedInstances flags stopped instances that still have volumes, and running
+// instances whose daily average CPU stayed below idleCPUPercent
+func (p *AWSProvider) findUnusedInstances(ctx context.Context, metrics metricsClient, volumesByInstance map[string][]models.Resource) ([]UnusedResource, error) {
+	var unused []UnusedResource
+
+	paginator := ec2.NewDescribeInstancesPaginator(p.ec2Client, &ec2.DescribeInstancesInput{
+		Filters: []ec2types.Filter{{
+			Name:   aws.String("instance-state-name"),
+			Values: []string{"running", "stopped"},
+		}},
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, reservation := range page.Reservations {
+			for _, instance := range reservation.Instances {
+				instanceID := aws.ToString(instance.InstanceId)
+				resource := models.Resource{
+					ID:       instanceID,
+					Name:     p.getTagValue(instance.Tags, "Name"),
+					Type:     "aws_instance",
+					Provider: "aws",
+					Region:   p.region,
+					Status:   string(instance.State.Name),
+					Tags:     p.convertTags(instance.Tags),
+					Attributes: map[string]interface{}{
+						"instance_type": string(instance.InstanceType),
+					},
+				}
+
+				if instance.State.Name == ec2types.InstanceStateNameStopped {
+					volumes := volumesByInstance[instanceID]
+					if len(volumes) > 0 {
+						unused = append(unused, UnusedResource{
+							Resource: resource,
+							Reason:   fmt.Sprintf("instance is stopped but its %d EBS volume(s) are still billed", len(volumes)),
+							Billable: volumes,
+						})
+					}
+					continue
+				}
+
+				idle, err := belowMetric(ctx, metrics, "AWS/EC2", "CPUUtilization", "InstanceId", instanceID, cwtypes.StatisticAverage, idleCPUPercent)
+				if err != nil {
+					return nil, err
+				}
+				if idle {
+					unused = append(unused, UnusedResource{
+						Resource: resource,
+						Reason:   fmt.Sprintf("average CPU stayed below %.0f%% every day for %d days", idleCPUPercent, int(idleLookback.Hours()/24)),
+						Billable: append([]models.Resource{resource}, volumesByInstance[instanceID]...),
+					})
+				}
+			}
+		}
+	}
+
+	return unused, nil
+}
+
+// findUnassociatedAddresses flags Elastic IPs not associated with an instance or interface
+func (p *AWSProvider) findUnassociatedAddresses(ctx context.Context) ([]UnusedResource, error) {
+	output, err := p.ec2Client.DescribeAddresses(ctx, &ec2.DescribeAddressesInput{})
+	if err != nil {
+		return nil, err
+	}
+
+	var unused []UnusedResource
+	for _, address := range output.Addresses {
+		if address.AssociationId != nil {
+			continue
+		}
+		resource := models.Resource{
+			ID:       aws.ToString(address.AllocationId),
+			Name:     aws.ToString(address.PublicIp),
+			Type:     "aws_eip",
+			Provider: "aws",
+			Region:   p.region,
+			Tags:     p.convertTags(address.Tags),
+			Attributes: map[string]interface{}{
+				"public_ip": aws.ToString(address.PublicIp),
+			},
+		}
+		unused = append(unused, UnusedResource{
+			Resource: resource,
+			Reason:   "Elastic IP is not associated with an instance or network interface",
+			Billable: []models.Resource{resource},
+		})
+	}
+
+	return unused, nil
+}
+
+// findEmptyLoadBalancers flags load balancers with no registered targets
+func (p *AWSProvider) findEmptyLoadBalancers(ctx context.Context, client *elbv2.Client) ([]UnusedResource, error) {
+	targets := make(map[string]int)
+	groups := elbv2.NewDescribeTargetGroupsPaginator(client, &elbv2.DescribeTargetGroupsInput{})
+	for groups.HasMorePages() {
+		page, err := groups.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, group := range page.TargetGroups {
+			if len(group.LoadBalancerArns) == 0 {
+				continue
+			}
+			health, err := client.DescribeTargetHealth(ctx, &elbv2.DescribeTargetHealthInput{
+				TargetGroupArn: group.TargetGroupArn,
+			})
+			if err != nil {
+				return nil, err
+			}
+			for _, arn := range group.LoadBalancerArns {
+				targets[arn] += len(health.TargetHealthDescriptions)
+			}
+		}
+	}
+
+	var unused []UnusedResource
+	loadBalancers := elbv2.NewDescribeLoadBalancersPaginator(client, &elbv2.DescribeLoadBalancersInput{})
+	for loadBalancers.HasMorePages() {
+		page, err := loadBalancers.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, lb := range page.LoadBalancers {
+			arn := aws.ToString(lb.LoadBalancerArn)
+			if targets[arn] > 0 {
+				continue
+			}
+			resource := models.Resource{
+				ID:       arn,
+				Name:     aws.ToString(lb.LoadBalancerName),
+				Type:     "aws_lb",
+				Provider: "aws",
+				Region:   p.region,
+				Attributes: map[string]interface{}{
+					"load_balancer_type": string(lb.Type),
+				},
+			}
+			unused = append(unused, UnusedResource{
+				Resource: resource,
+				Reason:   "load balancer has no registered targets",
+				Billable: []models.Resource{resource},
+			})
+		}
+	}
+
+	return unused, nil
+}
+
+// findIdleDatabases flags available RDS instances with no connections over the lookback
+func (p *AWSProvider) findIdleDatabases(ctx context.Context, metrics metricsClient) ([]UnusedResource, error) {
+	var unused []UnusedResource
+
+	paginator := rds.NewDescribeDBInstancesPaginator(p.rdsClient, &rds.DescribeDBInstancesInput{})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, instance := range page.DBInstances {
+			if aws.ToString(instance.DBInstanceStatus) != "available" {
+				continue
+			}
+			instanceID := aws.ToString(instance.DBInstanceIdentifier)
+			idle, err := belowMetric(ctx, metrics, "AWS/RDS", "DatabaseConnections", "DBInstanceIdentifier", instanceID, cwtypes.StatisticMaximum, 1)
+			if err != nil {
+				return nil, err
+			}
+			if !idle {
+				continue
+			}
+			resource := models.Resource{
+				ID:       instanceID,
+				Name:     instanceID,
+				Type:     "aws_db_instance",
+				Provider: "aws",
+				Region:   p.region,
+				Status:   "available",
+				Attributes: map[string]interface{}{
+					"instance_class":    aws.ToString(instance.DBInstanceClass),
+					"allocated_storage": aws.ToInt32(instance.AllocatedStorage),
+				},
+			}
+			unused = append(unused, UnusedResource{
+				Resource: resource,
+				Reason:   fmt.Sprintf("no database connections in %d days", int(idleLookback.Hours()/24)),
+				Billable: []models.Resource{resource},
+			})
+		}
+	}
+
+	return unused, nil
+}
+
+// belowMetric reports whether every daily value of a metric over the lookback was below the
+// threshold. Resources without datapoints are not judged idle, since they may be too new.
+func belowMetric(ctx context.Context, metrics metricsClient, namespace, metricName, dimension, value string, statistic cwtypes.Statistic, threshold float64) (bool, error) {
+	end := time.Now()
+	output, err := metrics.GetMetricStatistics(ctx, &cloudwatch.GetMetricStatisticsInput{
+		Namespace:  aws.String(namespace),
+		MetricName: aws.String(metricName),
+		Dimensions: []cwtypes.Dimension{{Name: aws.String(dimension), Value: aws.String(value)}},
+		StartTime:  aws.Time(end.Add(-idleLookback)),
+		EndTime:    aws.Time(end),
+		Period:     aws.Int32(int32((24 * time.Hour).Seconds())),
+		Statistics: []cwtypes.Statistic{statistic},
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to read %s %s for %s: %w", namespace, metricName, value, err)
+	}
+	if len(output.Datapoints) == 0 {
+		return false, nil
+	}
+
+	for _, datapoint := range output.Datapoints {
+		observed := aws.ToFloat64(datapoint.Average)
+		if statistic == cwtypes.StatisticMaximum {
+			observed = aws.ToFloat64(datapoint.Maximum)
+		}
+		if observed >= threshold {
+			return false, nil
+		}
+	}
+	return true, nil
+}
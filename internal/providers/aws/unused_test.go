@@ -0,0 +1,123 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
+	cwtypes "github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+
+	"github.com/catherinevee/driftmgr/pkg/models"
+)
+
+// fakeMetricsClient returns canned datapoints for every metric
+type fakeMetricsClient struct {
+	datapoints []cwtypes.Datapoint
+}
+
+func (f *fakeMetricsClient) GetMetricStatistics(ctx context.Context, params *cloudwatch.GetMetricStatisticsInput, optFns ...func(*cloudwatch.Options)) (*cloudwatch.GetMetricStatisticsOutput, error) {
+	return &cloudwatch.GetMetricStatisticsOutput{Datapoints: f.datapoints}, nil
+}
+
+func TestBelowMetric(t *testing.T) {
+	tests := []struct {
+		name       string
+		datapoints []cwtypes.Datapoint
+		statistic  cwtypes.Statistic
+		want       bool
+	}{
+		{
+			name:       "idle every day",
+			datapoints: []cwtypes.Datapoint{{Average: aws.Float64(0.4)}, {Average: aws.Float64(1.2)}},
+			statistic:  cwtypes.StatisticAverage,
+			want:       true,
+		},
+		{
+			name:       "busy one day",
+			datapoints: []cwtypes.Datapoint{{Average: aws.Float64(0.4)}, {Average: aws.Float64(35)}},
+			statistic:  cwtypes.StatisticAverage,
+		},
+		{
+			name:      "no datapoints",
+			statistic: cwtypes.StatisticAverage,
+		},
+		{
+			name:       "maximum statistic",
+			datapoints: []cwtypes.Datapoint{{Maximum: aws.Float64(0), Average: aws.Float64(5)}},
+			statistic:  cwtypes.StatisticMaximum,
+			want:       true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &fakeMetricsClient{datapoints: tt.datapoints}
+			idle, err := belowMetric(context.Background(), client, "AWS/EC2", "CPUUtilization", "InstanceId", "i-1", tt.statistic, idleCPUPercent)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if idle != tt.want {
+				t.Errorf("expected idle=%v, got %v", tt.want, idle)
+			}
+		})
+	}
+}
+
+// fakeMetricDataClient answers every query with the values for its metric name
+type fakeMetricDataClient struct {
+	values map[string][]float64
+	calls  int
+}
+
+func (f *fakeMetricDataClient) GetMetricData(ctx context.Context, params *cloudwatch.GetMetricDataInput, optFns ...func(*cloudwatch.Options)) (*cloudwatch.GetMetricDataOutput, error) {
+	f.calls++
+	output := &cloudwatch.GetMetricDataOutput{}
+	for _, query := range params.MetricDataQueries {
+		output.MetricDataResults = append(output.MetricDataResults, cwtypes.MetricDataResult{
+			Id:     query.Id,
+			Values: f.values[aws.ToString(query.MetricStat.Metric.MetricName)],
+		})
+	}
+	return output, nil
+}
+
+func TestEnrichUtilization(t *testing.T) {
+	resources := []models.Resource{
+		{ID: "vpc-1", Type: "aws_vpc"},
+		{ID: "orders", Type: "aws_db_instance"},
+	}
+	for i := 0; i < 200; i++ {
+		resources = append(resources, models.Resource{ID: fmt.Sprintf("i-%d", i), Type: "aws_instance"})
+	}
+	client := &fakeMetricDataClient{values: map[string][]float64{
+		"CPUUtilization":           {10, 20},
+		"NetworkIn":                {86400 * 2},
+		"NetworkReceiveThroughput": {512},
+	}}
+
+	err := enrichUtilization(context.Background(), client, resources, 7*24*time.Hour, time.Now())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// 603 queries fit in two GetMetricData calls
+	if client.calls != 2 {
+		t.Errorf("expected 2 batched calls, got %d", client.calls)
+	}
+	if resources[0].Properties != nil {
+		t.Errorf("expected unsupported types to be left alone, got %v", resources[0].Properties)
+	}
+
+	instance := resources[2].Properties
+	if instance["cpu_utilization_avg"] != 15.0 || instance["network_in_bytes_per_sec"] != 2.0 || instance["utilization_lookback_days"] != 7.0 {
+		t.Errorf("unexpected instance utilization: %v", instance)
+	}
+	if _, ok := instance["network_out_bytes_per_sec"]; ok {
+		t.Error("expected metrics without datapoints to be omitted")
+	}
+	if resources[1].Properties["network_in_bytes_per_sec"] != 512.0 {
+		t.Errorf("unexpected database utilization: %v", resources[1].Properties)
+	}
+}
@@ -0,0 +1,157 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
+	cwtypes "github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+
+	"github.com/catherinevee/driftmgr/pkg/models"
+)
+
+const (
+	// DefaultUtilizationLookback is the window utilization is averaged over by default
+	DefaultUtilizationLookback = 14 * 24 * time.Hour
+	// maxMetricDataQueries is the most queries GetMetricData accepts in one call
+	maxMetricDataQueries = 500
+)
+
+// utilizationMetric is a CloudWatch metric averaged into a resource property. Metrics
+// reported as per-period sums are divided by the period to give a per-second rate.
+type utilizationMetric struct {
+	property  string
+	namespace string
+	name      string
+	stat      string
+	perSecond bool
+}
+
+// utilizationMetrics lists the metrics collected per resource type and the dimension
+// identifying the resource
+var utilizationMetrics = map[string]struct {
+	dimension string
+	metrics   []utilizationMetric
+}{
+	"aws_instance": {
+		dimension: "InstanceId",
+		metrics: []utilizationMetric{
+			{property: "cpu_utilization_avg", namespace: "AWS/EC2", name: "CPUUtilization", stat: "Average"},
+			{property: "network_in_bytes_per_sec", namespace: "AWS/EC2", name: "NetworkIn", stat: "Sum", perSecond: true},
+			{property: "network_out_bytes_per_sec", namespace: "AWS/EC2", name: "NetworkOut", stat: "Sum", perSecond: true},
+		},
+	},
+	"aws_db_instance": {
+		dimension: "DBInstanceIdentifier",
+		metrics: []utilizationMetric{
+			{property: "cpu_utilization_avg", namespace: "AWS/RDS", name: "CPUUtilization", stat: "Average"},
+			{property: "network_in_bytes_per_sec", namespace: "AWS/RDS", name: "NetworkReceiveThroughput", stat: "Average"},
+			{property: "network_out_bytes_per_sec", namespace: "AWS/RDS", name: "NetworkTransmitThroughput", stat: "Average"},
+		},
+	},
+}
+
+// utilizationQuery ties a GetMetricData query back to the resource property it fills
+type utilizationQuery struct {
+	resource int
+	metric   utilizationMetric
+}
+
+// EnrichUtilization fetches average CPU and network utilization over the lookback for the
+// aws_instance and aws_db_instance resources and stores it in their Properties, along with
+// utilization_lookback_days. Queries are batched into as few GetMetricData calls as
+// possible. Resources without datapoints, such as ones launched since, are left unchanged.
+func (p *AWSProvider) EnrichUtilization(ctx context.Context, resources []models.Resource, lookback time.Duration) error {
+	if p.ec2Client == nil {
+		if err := p.Initialize(ctx); err != nil {
+			return err
+		}
+	}
+	return enrichUtilization(ctx, cloudwatch.NewFromConfig(p.awsConfig), resources, lookback, time.Now())
+}
+
+// enrichUtilization runs the batched metric queries for resources against client
+func enrichUtilization(ctx context.Context, client cloudwatch.GetMetricDataAPIClient, resources []models.Resource, lookback time.Duration, end time.Time) error {
+	if lookback <= 0 {
+		lookback = DefaultUtilizationLookback
+	}
+	// Daily datapoints keep the result small whatever the lookback
+	period := int32((24 * time.Hour).Seconds())
+	if lookback < 24*time.Hour {
+		period = int32(lookback.Truncate(time.Minute).Seconds())
+		if period < 60 {
+			period = 60
+		}
+	}
+
+	var queries []cwtypes.MetricDataQuery
+	targets := make(map[string]utilizationQuery)
+	for i, resource := range resources {
+		spec, ok := utilizationMetrics[resource.Type]
+		if !ok || resource.ID == "" {
+			continue
+		}
+		for _, metric := range spec.metrics {
+			id := fmt.Sprintf("m%d", len(queries))
+			queries = append(queries, cwtypes.MetricDataQuery{
+				Id: aws.String(id),
+				MetricStat: &cwtypes.MetricStat{
+					Metric: &cwtypes.Metric{
+						Namespace:  aws.String(metric.namespace),
+						MetricName: aws.String(metric.name),
+						Dimensions: []cwtypes.Dimension{{Name: aws.String(spec.dimension), Value: aws.String(resource.ID)}},
+					},
+					Period: aws.Int32(period),
+					Stat:   aws.String(metric.stat),
+				},
+			})
+			targets[id] = utilizationQuery{resource: i, metric: metric}
+		}
+	}
+
+	values := make(map[string][]float64, len(queries))
+	for start := 0; start < len(queries); start += maxMetricDataQueries {
+		batch := queries[start:min(start+maxMetricDataQueries, len(queries))]
+		paginator := cloudwatch.NewGetMetricDataPaginator(client, &cloudwatch.GetMetricDataInput{
+			MetricDataQueries: batch,
+			StartTime:         aws.Time(end.Add(-lookback)),
+			EndTime:           aws.Time(end),
+		})
+		for paginator.HasMorePages() {
+			page, err := paginator.NextPage(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to get utilization metrics: %w", err)
+			}
+			for _, result := range page.MetricDataResults {
+				id := aws.ToString(result.Id)
+				values[id] = append(values[id], result.Values...)
+			}
+		}
+	}
+
+	for id, target := range targets {
+		points := values[id]
+		if len(points) == 0 {
+			continue
+		}
+		total := 0.0
+		for _, point := range points {
+			total += point
+		}
+		average := total / float64(len(points))
+		if target.metric.perSecond {
+			average /= float64(period)
+		}
+
+		resource := &resources[target.resource]
+		if resource.Properties == nil {
+			resource.Properties = make(map[string]interface{})
+		}
+		resource.Properties[target.metric.property] = average
+		resource.Properties["utilization_lookback_days"] = lookback.Hours() / 24
+	}
+
+	return nil
+}
@@ -44,8 +44,7 @@ func (rds *ResourceDiscoveryService) DiscoverResources(ctx context.Context, scop
 				"osType":        "Linux",
 				"resourceGroup": rds.resourceGroup,
 			},
-			CreatedAt: time.Now(),
-			Updated:   time.Now(),
+			Updated: time.Now(),
 		},
 		{
 			ID:        fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.Storage/storageAccounts/examplestorage", rds.subscriptionID, rds.resourceGroup),
@@ -59,8 +58,7 @@ func (rds *ResourceDiscoveryService) DiscoverResources(ctx context.Context, scop
 				"accountType":   "Standard_LRS",
 				"resourceGroup": rds.resourceGroup,
 			},
-			CreatedAt: time.Now(),
-			Updated:   time.Now(),
+			Updated: time.Now(),
 		},
 	}
 
@@ -0,0 +1,224 @@
+package azure
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Event Grid event types of resource changes and of the handshake that registers a webhook
+const (
+	SubscriptionValidationEventType = "Microsoft.EventGrid.SubscriptionValidationEvent"
+	ResourceWriteSuccessEventType   = "Microsoft.Resources.ResourceWriteSuccess"
+	ResourceDeleteSuccessEventType  = "Microsoft.Resources.ResourceDeleteSuccess"
+	ResourceActionSuccessEventType  = "Microsoft.Resources.ResourceActionSuccess"
+)
+
+// activityLogSchema is the schema ID of Activity Log alerts sent by action group webhooks
+const activityLogSchema = "Microsoft.Insights/activityLogs"
+
+// ErrUnsupportedEvent is returned for an event that does not change a resource type driftmgr
+// can re-discover, such as a failed operation
+var ErrUnsupportedEvent = errors.New("unsupported event")
+
+// armResource says which resource type changes of an ARM resource type are re-checked as, and
+// how many names of the resource URI identify it. Changes of child resources such as security
+// rules re-check their parent.
+type armResource struct {
+	ResourceType string
+	Names        int
+}
+
+// armResources maps the lower-cased ARM resource types of operations to what they change
+var armResources = map[string]armResource{
+	"microsoft.compute/virtualmachines":                     {"azurerm_virtual_machine", 1},
+	"microsoft.compute/virtualmachines/extensions":          {"azurerm_virtual_machine", 1},
+	"microsoft.network/virtualnetworks":                     {"azurerm_virtual_network", 1},
+	"microsoft.network/virtualnetworks/subnets":             {"azurerm_subnet", 2},
+	"microsoft.network/networksecuritygroups":               {"azurerm_network_security_group", 1},
+	"microsoft.network/networksecuritygroups/securityrules": {"azurerm_network_security_group", 1},
+	"microsoft.storage/storageaccounts":                     {"azurerm_storage_account", 1},
+	"microsoft.sql/servers":                                 {"azurerm_sql_server", 1},
+	"microsoft.sql/servers/firewallrules":                   {"azurerm_sql_server", 1},
+	"microsoft.sql/servers/databases":                       {"azurerm_sql_database", 2},
+	"microsoft.keyvault/vaults":                             {"azurerm_key_vault", 1},
+	"microsoft.keyvault/vaults/accesspolicies":              {"azurerm_key_vault", 1},
+	"microsoft.web/sites":                                   {"azurerm_app_service", 1},
+	"microsoft.web/sites/config":                            {"azurerm_app_service", 1},
+	"microsoft.containerregistry/registries":                {"azurerm_container_registry", 1},
+	"microsoft.containerservice/managedclusters":            {"azurerm_kubernetes_cluster", 1},
+	"microsoft.containerservice/managedclusters/agentpools": {"azurerm_kubernetes_cluster", 1},
+}
+
+// ChangeEvent is a resource change reported by an Event Grid event or an Activity Log alert.
+// ResourceID is the resource name, or parent and child names joined by a slash, as
+// GetResourceByType takes it. Deleted is set when the resource itself was deleted, rather
+// than a child of it.
+type ChangeEvent struct {
+	Operation      string `json:"operation"`
+	SubscriptionID string `json:"subscription_id"`
+	ResourceGroup  string `json:"resource_group"`
+	ResourceURI    string `json:"resource_uri"`
+	ResourceType   string `json:"resource_type"`
+	ResourceID     string `json:"resource_id"`
+	Caller         string `json:"caller,omitempty"`
+	Deleted        bool   `json:"deleted"`
+}
+
+// Notification is what a webhook delivery carries: either the validation code of the Event
+// Grid subscription handshake, or resource changes. Ignored gives the reason each event that
+// changed nothing driftmgr can re-discover was skipped.
+type Notification struct {
+	ValidationCode string         `json:"validation_code,omitempty"`
+	Events         []*ChangeEvent `json:"events"`
+	Ignored        []string       `json:"ignored,omitempty"`
+}
+
+// ParseNotification reads an Event Grid delivery, an array of events in the Event Grid schema,
+// or an Activity Log alert sent by an action group webhook
+func ParseNotification(data []byte) (*Notification, error) {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) > 0 && trimmed[0] == '{' {
+		return parseActivityLogAlert(trimmed)
+	}
+
+	var events []struct {
+		EventType string `json:"eventType"`
+		Subject   string `json:"subject"`
+		Data      struct {
+			ValidationCode string `json:"validationCode"`
+			OperationName  string `json:"operationName"`
+			ResourceURI    string `json:"resourceUri"`
+			Claims         struct {
+				Name string `json:"name"`
+			} `json:"claims"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(trimmed, &events); err != nil {
+		return nil, fmt.Errorf("invalid event: %w", err)
+	}
+
+	notification := &Notification{Events: []*ChangeEvent{}}
+	for _, event := range events {
+		var (
+			change *ChangeEvent
+			err    error
+		)
+		switch event.EventType {
+		case SubscriptionValidationEventType:
+			if event.Data.ValidationCode == "" {
+				return nil, fmt.Errorf("subscription validation event has no validation code")
+			}
+			return &Notification{ValidationCode: event.Data.ValidationCode, Events: []*ChangeEvent{}}, nil
+		case ResourceWriteSuccessEventType, ResourceDeleteSuccessEventType, ResourceActionSuccessEventType:
+			uri := event.Data.ResourceURI
+			if uri == "" {
+				uri = event.Subject
+			}
+			change, err = parseOperation(event.Data.OperationName, uri)
+		default:
+			err = fmt.Errorf("%w: event type %s is not a successful resource change", ErrUnsupportedEvent, event.EventType)
+		}
+		if err != nil {
+			notification.Ignored = append(notification.Ignored, err.Error())
+			continue
+		}
+		change.Caller = event.Data.Claims.Name
+		notification.Events = append(notification.Events, change)
+	}
+	return notification, nil
+}
+
+// parseActivityLogAlert reads the Activity Log event of an alert
+func parseActivityLogAlert(data []byte) (*Notification, error) {
+	var alert struct {
+		SchemaID string `json:"schemaId"`
+		Data     struct {
+			Context struct {
+				ActivityLog struct {
+					OperationName string `json:"operationName"`
+					ResourceID    string `json:"resourceId"`
+					Status        string `json:"status"`
+					Caller        string `json:"caller"`
+				} `json:"activityLog"`
+			} `json:"context"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(data, &alert); err != nil {
+		return nil, fmt.Errorf("invalid event: %w", err)
+	}
+	if alert.SchemaID != activityLogSchema {
+		return nil, fmt.Errorf("schemaId %q is not %q", alert.SchemaID, activityLogSchema)
+	}
+
+	notification := &Notification{Events: []*ChangeEvent{}}
+	log := alert.Data.Context.ActivityLog
+	change, err := parseOperation(log.OperationName, log.ResourceID)
+	switch {
+	case err == nil && !strings.EqualFold(log.Status, "Succeeded"):
+		notification.Ignored = append(notification.Ignored,
+			fmt.Sprintf("%v: %s has status %s", ErrUnsupportedEvent, log.OperationName, log.Status))
+	case err != nil:
+		notification.Ignored = append(notification.Ignored, err.Error())
+	default:
+		change.Caller = log.Caller
+		notification.Events = append(notification.Events, change)
+	}
+	return notification, nil
+}
+
+// parseOperation maps an operation, such as Microsoft.Compute/virtualMachines/write, on the
+// resource at uri to the resource it changed
+func parseOperation(operation, uri string) (*ChangeEvent, error) {
+	segments := strings.Split(operation, "/")
+	if len(segments) < 3 {
+		return nil, fmt.Errorf("%w: operation %q is not a resource operation", ErrUnsupportedEvent, operation)
+	}
+	verb := strings.ToLower(segments[len(segments)-1])
+	segments = segments[:len(segments)-1]
+	if verb == "action" && len(segments) > 2 {
+		// Actions such as virtualMachines/restart/action change the resource they act on
+		segments = segments[:len(segments)-1]
+	}
+	armType := strings.ToLower(strings.Join(segments, "/"))
+	changed, ok := armResources[armType]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s does not change a supported resource type", ErrUnsupportedEvent, operation)
+	}
+
+	subscription, resourceGroup, names, err := parseResourceURI(uri)
+	if err != nil {
+		return nil, err
+	}
+	if len(names) < changed.Names {
+		return nil, fmt.Errorf("resource URI %q does not name the %s it changed", uri, changed.ResourceType)
+	}
+
+	return &ChangeEvent{
+		Operation:      operation,
+		SubscriptionID: subscription,
+		ResourceGroup:  resourceGroup,
+		ResourceURI:    uri,
+		ResourceType:   changed.ResourceType,
+		ResourceID:     strings.Join(names[:changed.Names], "/"),
+		Deleted:        verb == "delete" && len(names) == changed.Names,
+	}, nil
+}
+
+// parseResourceURI splits a resource URI such as
+// /subscriptions/{id}/resourceGroups/{group}/providers/Microsoft.Network/virtualNetworks/{vnet}/subnets/{subnet}
+// into its subscription, resource group and the names of the resource and its parents
+func parseResourceURI(uri string) (subscription, resourceGroup string, names []string, err error) {
+	parts := strings.Split(strings.Trim(uri, "/"), "/")
+	if len(parts) < 8 || !strings.EqualFold(parts[0], "subscriptions") ||
+		!strings.EqualFold(parts[2], "resourceGroups") || !strings.EqualFold(parts[4], "providers") {
+		return "", "", nil, fmt.Errorf("resource URI %q is not a resource of a resource group", uri)
+	}
+	// After the namespace, types and names alternate
+	for i := 7; i < len(parts); i += 2 {
+		names = append(names, parts[i])
+	}
+	return parts[1], parts[3], names, nil
+}
@@ -0,0 +1,107 @@
+package azure
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseNotification(t *testing.T) {
+	notification, err := ParseNotification([]byte(`[{
+		"eventType": "Microsoft.EventGrid.SubscriptionValidationEvent",
+		"data": {"validationCode": "512d38b6-c7b8-40c8-89fe-f46f9e9622b6"}
+	}]`))
+	require.NoError(t, err)
+	assert.Equal(t, "512d38b6-c7b8-40c8-89fe-f46f9e9622b6", notification.ValidationCode)
+
+	notification, err = ParseNotification([]byte(`[
+		{
+			"eventType": "Microsoft.Resources.ResourceWriteSuccess",
+			"subject": "/subscriptions/sub-1/resourceGroups/prod/providers/Microsoft.Network/networkSecurityGroups/web-nsg/securityRules/allow-ssh",
+			"data": {
+				"operationName": "Microsoft.Network/networkSecurityGroups/securityRules/write",
+				"resourceUri": "/subscriptions/sub-1/resourceGroups/prod/providers/Microsoft.Network/networkSecurityGroups/web-nsg/securityRules/allow-ssh",
+				"claims": {"name": "alice@example.com"}
+			}
+		},
+		{
+			"eventType": "Microsoft.Resources.ResourceDeleteSuccess",
+			"data": {
+				"operationName": "Microsoft.Sql/servers/databases/delete",
+				"resourceUri": "/subscriptions/sub-1/resourceGroups/prod/providers/Microsoft.Sql/servers/main/databases/orders"
+			}
+		},
+		{
+			"eventType": "Microsoft.Resources.ResourceActionSuccess",
+			"data": {
+				"operationName": "Microsoft.Compute/virtualMachines/restart/action",
+				"resourceUri": "/subscriptions/sub-1/resourceGroups/prod/providers/Microsoft.Compute/virtualMachines/web-1"
+			}
+		},
+		{
+			"eventType": "Microsoft.Resources.ResourceWriteFailure",
+			"data": {"operationName": "Microsoft.Compute/virtualMachines/write"}
+		},
+		{
+			"eventType": "Microsoft.Resources.ResourceWriteSuccess",
+			"data": {
+				"operationName": "Microsoft.Insights/diagnosticSettings/write",
+				"resourceUri": "/subscriptions/sub-1/resourceGroups/prod/providers/Microsoft.Insights/diagnosticSettings/logs"
+			}
+		}
+	]`))
+	require.NoError(t, err)
+	assert.Empty(t, notification.ValidationCode)
+	require.Len(t, notification.Events, 3)
+	assert.Equal(t, &ChangeEvent{
+		Operation:      "Microsoft.Network/networkSecurityGroups/securityRules/write",
+		SubscriptionID: "sub-1",
+		ResourceGroup:  "prod",
+		ResourceURI:    "/subscriptions/sub-1/resourceGroups/prod/providers/Microsoft.Network/networkSecurityGroups/web-nsg/securityRules/allow-ssh",
+		ResourceType:   "azurerm_network_security_group",
+		ResourceID:     "web-nsg",
+		Caller:         "alice@example.com",
+	}, notification.Events[0], "a rule change re-checks its security group")
+	assert.Equal(t, "azurerm_sql_database", notification.Events[1].ResourceType)
+	assert.Equal(t, "main/orders", notification.Events[1].ResourceID)
+	assert.True(t, notification.Events[1].Deleted)
+	assert.Equal(t, "azurerm_virtual_machine", notification.Events[2].ResourceType)
+	assert.False(t, notification.Events[2].Deleted)
+	assert.Len(t, notification.Ignored, 2)
+
+	_, err = ParseNotification([]byte(`{"schemaId": "azureMonitorCommonAlertSchema"}`))
+	assert.Error(t, err)
+	_, err = ParseNotification([]byte(`not json`))
+	assert.Error(t, err)
+}
+
+func TestParseActivityLogAlert(t *testing.T) {
+	alert := `{
+		"schemaId": "Microsoft.Insights/activityLogs",
+		"data": {"context": {"activityLog": {
+			"operationName": "Microsoft.Storage/storageAccounts/write",
+			"resourceId": "/subscriptions/sub-1/resourceGroups/data/providers/Microsoft.Storage/storageAccounts/logs01",
+			"status": "Succeeded",
+			"caller": "bob@example.com"
+		}}}
+	}`
+	notification, err := ParseNotification([]byte(alert))
+	require.NoError(t, err)
+	require.Len(t, notification.Events, 1)
+	assert.Equal(t, "azurerm_storage_account", notification.Events[0].ResourceType)
+	assert.Equal(t, "logs01", notification.Events[0].ResourceID)
+	assert.Equal(t, "bob@example.com", notification.Events[0].Caller)
+
+	notification, err = ParseNotification([]byte(`{
+		"schemaId": "Microsoft.Insights/activityLogs",
+		"data": {"context": {"activityLog": {
+			"operationName": "Microsoft.Storage/storageAccounts/write",
+			"resourceId": "/subscriptions/sub-1/resourceGroups/data/providers/Microsoft.Storage/storageAccounts/logs01",
+			"status": "Started"
+		}}}
+	}`))
+	require.NoError(t, err)
+	assert.Empty(t, notification.Events)
+	assert.Len(t, notification.Ignored, 1)
+}
@@ -0,0 +1,18 @@
+package azure
+
+import (
+	_ "embed"
+
+	"github.com/catherinevee/driftmgr/pkg/models"
+)
+
+// discoveryPermissions lists the permissions the resource listers call for, by service.
+// Add a lister's permissions here when adding the lister.
+//
+//go:embed discovery_permissions.json
+var discoveryPermissions []byte
+
+// DiscoveryPermissions returns the Azure RBAC actions discovery needs, by service
+func DiscoveryPermissions() ([]models.ServicePermissions, error) {
+	return models.ParseServicePermissions(discoveryPermissions)
+}
@@ -4,14 +4,17 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
 	"os"
+	"sort"
 	"strings"
 	"time"
 
+	"github.com/catherinevee/driftmgr/internal/shared/backoff"
 	"github.com/catherinevee/driftmgr/pkg/models"
 )
 
@@ -28,6 +31,9 @@ type AzureProviderComplete struct {
 	apiVersion     map[string]string
 }
 
+// ErrNotFound is returned when the Azure API reports that a resource does not exist
+var ErrNotFound = errors.New("resource not found")
+
 // AzureTokenResponse represents the OAuth token response
 type AzureTokenResponse struct {
 	TokenType    string `json:"token_type"`
@@ -42,7 +48,8 @@ func NewAzureProviderComplete(subscriptionID, resourceGroup string) *AzureProvid
 		subscriptionID: subscriptionID,
 		resourceGroup:  resourceGroup,
 		httpClient: &http.Client{
-			Timeout: 30 * time.Second,
+			Timeout:   30 * time.Second,
+			Transport: backoff.NewTransport(nil, "azure"),
 		},
 		baseURL: "https://management.azure.com",
 		apiVersion: map[string]string{
@@ -57,6 +64,7 @@ func NewAzureProviderComplete(subscriptionID, resourceGroup string) *AzureProvid
 			"Microsoft.Web/sites":                        "2023-01-01",
 			"Microsoft.ContainerRegistry/registries":     "2023-01-01-preview",
 			"Microsoft.ContainerService/managedClusters": "2023-05-01",
+			"Microsoft.Resources/locations":              "2022-12-01",
 		},
 	}
 }
@@ -191,6 +199,9 @@ func (p *AzureProviderComplete) makeAPIRequest(ctx context.Context, method, path
 		return nil, fmt.Errorf("failed to read response: %w", err)
 	}
 
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, respBody)
+	}
 	if resp.StatusCode >= 400 {
 		return nil, fmt.Errorf("API error (status %d): %s", resp.StatusCode, respBody)
 	}
@@ -256,15 +267,46 @@ func (p *AzureProviderComplete) ValidateCredentials(ctx context.Context) error {
 	return p.Connect(ctx)
 }
 
-// ListRegions returns available regions for the provider (implements CloudProvider interface)
+// ListRegions returns the locations available to the subscription through the Resource
+// Manager locations API, leaving out logical locations such as geographies that resources
+// cannot be deployed to (implements CloudProvider interface)
 func (p *AzureProviderComplete) ListRegions(ctx context.Context) ([]string, error) {
-	// Return common Azure regions
-	return []string{
-		"eastus", "eastus2", "westus", "westus2", "centralus",
-		"northeurope", "westeurope", "uksouth", "ukwest",
-		"eastasia", "southeastasia", "japaneast", "japanwest",
-		"australiaeast", "australiasoutheast", "canadacentral", "canadaeast",
-	}, nil
+	if p.subscriptionID == "" {
+		return nil, errors.New("no Azure subscription is configured")
+	}
+	if p.accessToken == "" {
+		if err := p.Connect(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	path := fmt.Sprintf("/subscriptions/%s/locations?api-version=%s", p.subscriptionID, p.apiVersion["Microsoft.Resources/locations"])
+	data, err := p.makeAPIRequest(ctx, "GET", path, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list Azure locations: %w", err)
+	}
+
+	var result struct {
+		Value []struct {
+			Name     string `json:"name"`
+			Metadata struct {
+				RegionType string `json:"regionType"`
+			} `json:"metadata"`
+		} `json:"value"`
+	}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal location list: %w", err)
+	}
+
+	regions := make([]string, 0, len(result.Value))
+	for _, location := range result.Value {
+		if location.Metadata.RegionType == "Logical" {
+			continue
+		}
+		regions = append(regions, location.Name)
+	}
+	sort.Strings(regions)
+	return regions, nil
 }
 
 // SupportedResourceTypes returns the list of supported resource types (implements CloudProvider interface)
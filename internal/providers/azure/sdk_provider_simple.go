@@ -11,6 +11,9 @@ import (
 	"github.com/catherinevee/driftmgr/pkg/models"
 )
 
+// createdTimeExpand asks the resources API to include each resource's creation time
+var createdTimeExpand = "createdTime"
+
 // AzureSDKProviderSimple implements CloudProvider for Azure using Azure SDK
 type AzureSDKProviderSimple struct {
 	subscriptionID    string
@@ -82,8 +85,8 @@ func (p *AzureSDKProviderSimple) discoverResourcesByResourceGroup(ctx context.Co
 func (p *AzureSDKProviderSimple) discoverResourcesBySubscription(ctx context.Context) ([]models.Resource, error) {
 	var resources []models.Resource
 
-	// List all resources in the subscription
-	pager := p.resourceClient.NewListPager(nil)
+	// List all resources in the subscription, with the creation times the API omits by default
+	pager := p.resourceClient.NewListPager(&armresources.ClientListOptions{Expand: &createdTimeExpand})
 	for pager.More() {
 		page, err := pager.NextPage(ctx)
 		if err != nil {
@@ -137,11 +140,20 @@ func (p *AzureSDKProviderSimple) convertAzureResourceToModel(azureResource *armr
 		Region:       *azureResource.Location,
 		Attributes:   attributes,
 		Tags:         tags,
-		CreatedAt:    time.Now(), // Use current time as fallback
+		CreatedAt:    createdTime(azureResource),
 		LastModified: time.Now(),
 	}
 }
 
+// createdTime returns when an Azure resource was created, or the zero time when it was listed
+// or fetched without its creation time
+func createdTime(resource *armresources.GenericResourceExpanded) time.Time {
+	if resource.CreatedTime == nil {
+		return time.Time{}
+	}
+	return *resource.CreatedTime
+}
+
 // getTerraformResourceType converts Azure resource type to Terraform resource type
 func (p *AzureSDKProviderSimple) getTerraformResourceType(azureType *string) string {
 	if azureType == nil {
@@ -10,6 +10,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/catherinevee/driftmgr/internal/shared/backoff"
 	"github.com/catherinevee/driftmgr/pkg/models"
 )
 
@@ -164,7 +165,8 @@ func NewDigitalOceanProvider(region string) *DigitalOceanProvider {
 
 	return &DigitalOceanProvider{
 		httpClient: &http.Client{
-			Timeout: 30 * time.Second,
+			Timeout:   30 * time.Second,
+			Transport: backoff.NewTransport(nil, "digitalocean"),
 		},
 		baseURL: "https://api.digitalocean.com/v2",
 		region:  region,
@@ -134,7 +134,7 @@ func (p *DigitalOceanSDKProvider) discoverDroplets(ctx context.Context) ([]model
 			Region:       droplet.Region.Slug,
 			Attributes:   attributes,
 			Tags:         tags,
-			CreatedAt:    time.Now(), // DigitalOcean API returns string, using current time as fallback
+			CreatedAt:    parseCreatedAt(droplet.Created),
 			LastModified: time.Now(),
 		}
 
@@ -225,7 +225,7 @@ func (p *DigitalOceanSDKProvider) discoverLoadBalancers(ctx context.Context) ([]
 			Region:       lb.Region.Slug,
 			Attributes:   attributes,
 			Tags:         make(map[string]string), // Load balancers don't have tags in the same way
-			CreatedAt:    time.Now(),              // DigitalOcean API returns string, using current time as fallback
+			CreatedAt:    parseCreatedAt(lb.Created),
 			LastModified: time.Now(),
 		}
 
@@ -360,7 +360,7 @@ func (p *DigitalOceanSDKProvider) GetResourceByType(ctx context.Context, resourc
 			Region:       droplet.Region.Slug,
 			Attributes:   attributes,
 			Tags:         tags,
-			CreatedAt:    time.Now(), // DigitalOcean API returns string, using current time as fallback
+			CreatedAt:    parseCreatedAt(droplet.Created),
 			LastModified: time.Now(),
 		}, nil
 
@@ -429,7 +429,7 @@ func (p *DigitalOceanSDKProvider) GetResourceByType(ctx context.Context, resourc
 			Region:       lb.Region.Slug,
 			Attributes:   attributes,
 			Tags:         make(map[string]string),
-			CreatedAt:    time.Now(), // DigitalOcean API returns string, using current time as fallback
+			CreatedAt:    parseCreatedAt(lb.Created),
 			LastModified: time.Now(),
 		}, nil
 
@@ -477,6 +477,16 @@ func (p *DigitalOceanSDKProvider) GetResourceByType(ctx context.Context, resourc
 	}
 }
 
+// parseCreatedAt parses the RFC 3339 created_at string of a droplet or load balancer, returning
+// the zero time when it is missing or malformed so the resource's age is reported as unknown
+func parseCreatedAt(createdAt string) time.Time {
+	created, err := time.Parse(time.RFC3339, createdAt)
+	if err != nil {
+		return time.Time{}
+	}
+	return created
+}
+
 // ValidateCredentials checks if the provider credentials are valid
 func (p *DigitalOceanSDKProvider) ValidateCredentials(ctx context.Context) error {
 	// Test connection by getting account info
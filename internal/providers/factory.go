@@ -10,6 +10,7 @@ import (
 	"github.com/catherinevee/driftmgr/internal/providers/azure"
 	"github.com/catherinevee/driftmgr/internal/providers/digitalocean"
 	"github.com/catherinevee/driftmgr/internal/providers/gcp"
+	"github.com/catherinevee/driftmgr/internal/providers/kubernetes"
 )
 
 // NewProvider creates a new provider based on the provider name
@@ -43,6 +44,12 @@ func NewProvider(providerName string, config map[string]interface{}) (CloudProvi
 			region = r
 		}
 		return digitalocean.NewDigitalOceanProvider(region), nil
+	case "kubernetes", "k8s":
+		kubeconfig := ""
+		if k, ok := config["kubeconfig"].(string); ok {
+			kubeconfig = k
+		}
+		return kubernetes.NewKubernetesProvider(kubeconfig), nil
 	default:
 		return nil, fmt.Errorf("unsupported provider: %s", providerName)
 	}
@@ -238,6 +245,14 @@ func (pf *ProviderFactory) CreateProvider(providerName string) (CloudProvider, e
 
 		return NewDigitalOceanProvider(region), nil
 
+	case "kubernetes", "k8s":
+		kubeconfig := ""
+		if k, ok := pf.config["kubeconfig"].(string); ok {
+			kubeconfig = k
+		}
+
+		return kubernetes.NewKubernetesProvider(kubeconfig), nil
+
 	default:
 		return nil, fmt.Errorf("unsupported provider: %s", providerName)
 	}
@@ -271,6 +286,13 @@ func DetectProviders() []string {
 		providers = append(providers, "digitalocean")
 	}
 
+	// Check for a kubeconfig
+	if os.Getenv("KUBECONFIG") != "" {
+		providers = append(providers, "kubernetes")
+	} else if _, err := os.Stat(os.ExpandEnv("$HOME/.kube/config")); err == nil {
+		providers = append(providers, "kubernetes")
+	}
+
 	return providers
 }
 
@@ -315,6 +337,13 @@ func ValidateProviderCredentials(providerName string) error {
 		}
 		return nil
 
+	case "kubernetes", "k8s":
+		// Check for a kubeconfig or an in-cluster service account
+		if _, err := kubernetes.LoadConfig(""); err != nil {
+			return err
+		}
+		return nil
+
 	default:
 		return fmt.Errorf("unsupported provider: %s", providerName)
 	}
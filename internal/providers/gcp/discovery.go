@@ -46,8 +46,7 @@ func (rds *ResourceDiscoveryService) DiscoverResources(ctx context.Context, scop
 				"status":      "RUNNING",
 				"zone":        rds.zone,
 			},
-			CreatedAt: time.Now(),
-			Updated:   time.Now(),
+			Updated: time.Now(),
 		},
 		{
 			ID:        fmt.Sprintf("projects/%s/buckets/example-bucket", rds.projectID),
@@ -61,8 +60,7 @@ func (rds *ResourceDiscoveryService) DiscoverResources(ctx context.Context, scop
 				"location":     rds.region,
 				"storageClass": "STANDARD",
 			},
-			CreatedAt: time.Now(),
-			Updated:   time.Now(),
+			Updated: time.Now(),
 		},
 	}
 
@@ -0,0 +1,268 @@
+package gcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/catherinevee/driftmgr/pkg/models"
+)
+
+// defaultOrganizationConcurrency is how many projects are discovered at once by default
+const defaultOrganizationConcurrency = 4
+
+// Project is a project found under an organization or folder
+type Project struct {
+	ID     string `json:"id"`
+	Number string `json:"number"`
+	Name   string `json:"name"`
+	State  string `json:"state"`
+	Parent string `json:"parent"`
+	// Path is the folder path from the scanned organization or folder, such as Prod/Web
+	Path string `json:"path,omitempty"`
+}
+
+// ProjectDiscovery is the outcome of discovering one project
+type ProjectDiscovery struct {
+	Project   Project           `json:"project"`
+	Resources []models.Resource `json:"resources"`
+	Error     string            `json:"error,omitempty"`
+}
+
+// OrganizationDiscovery is the outcome of discovering the projects under an organization or
+// folder. Skipped lists projects that are not active or were not selected.
+type OrganizationDiscovery struct {
+	Parent   string             `json:"parent"`
+	Projects []ProjectDiscovery `json:"projects"`
+	Skipped  []Project          `json:"skipped"`
+}
+
+// OrganizationDiscoveryOptions configures DiscoverOrganization
+type OrganizationDiscoveryOptions struct {
+	// Include limits discovery to these project IDs; Exclude skips them
+	Include []string
+	Exclude []string
+	// Filter, when set, also skips the projects it rejects
+	Filter func(Project) bool
+	// Concurrency is how many projects are discovered at once
+	Concurrency int
+	// Progress is called as each project finishes
+	Progress func(done, total int, project ProjectDiscovery)
+}
+
+// OrganizationParent returns the Cloud Resource Manager name of an organization or folder:
+// exactly one of organizationID and folderID must be given, with or without its prefix
+func OrganizationParent(organizationID, folderID string) (string, error) {
+	switch {
+	case organizationID != "" && folderID != "":
+		return "", fmt.Errorf("give an organization or a folder, not both")
+	case organizationID != "":
+		return "organizations/" + strings.TrimPrefix(organizationID, "organizations/"), nil
+	case folderID != "":
+		return "folders/" + strings.TrimPrefix(folderID, "folders/"), nil
+	default:
+		return "", fmt.Errorf("an organization or folder ID is required")
+	}
+}
+
+// ListProjects lists the projects under an organization or folder, descending into its
+// folders, sorted by project ID
+func (p *GCPProviderComplete) ListProjects(ctx context.Context, parent string) ([]Project, error) {
+	var projects []Project
+	type folder struct{ name, path string }
+	queue := []folder{{name: parent}}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+
+		err := p.listPages(ctx, "projects", current.name, func(data []byte) (string, error) {
+			var page struct {
+				Projects []struct {
+					Name        string `json:"name"`
+					ProjectID   string `json:"projectId"`
+					DisplayName string `json:"displayName"`
+					State       string `json:"state"`
+					Parent      string `json:"parent"`
+				} `json:"projects"`
+				NextPageToken string `json:"nextPageToken"`
+			}
+			if err := json.Unmarshal(data, &page); err != nil {
+				return "", fmt.Errorf("failed to unmarshal project list: %w", err)
+			}
+			for _, project := range page.Projects {
+				projects = append(projects, Project{
+					ID:     project.ProjectID,
+					Number: strings.TrimPrefix(project.Name, "projects/"),
+					Name:   project.DisplayName,
+					State:  project.State,
+					Parent: project.Parent,
+					Path:   current.path,
+				})
+			}
+			return page.NextPageToken, nil
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		err = p.listPages(ctx, "folders", current.name, func(data []byte) (string, error) {
+			var page struct {
+				Folders []struct {
+					Name        string `json:"name"`
+					DisplayName string `json:"displayName"`
+				} `json:"folders"`
+				NextPageToken string `json:"nextPageToken"`
+			}
+			if err := json.Unmarshal(data, &page); err != nil {
+				return "", fmt.Errorf("failed to unmarshal folder list: %w", err)
+			}
+			for _, child := range page.Folders {
+				path := child.DisplayName
+				if current.path != "" {
+					path = current.path + "/" + child.DisplayName
+				}
+				queue = append(queue, folder{name: child.Name, path: path})
+			}
+			return page.NextPageToken, nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	sort.Slice(projects, func(i, j int) bool { return projects[i].ID < projects[j].ID })
+	return projects, nil
+}
+
+// listPages lists a Cloud Resource Manager collection under parent page by page, handing
+// each page to read, which returns the next page token
+func (p *GCPProviderComplete) listPages(ctx context.Context, collection, parent string, read func([]byte) (string, error)) error {
+	token := ""
+	for {
+		query := url.Values{"parent": {parent}}
+		if token != "" {
+			query.Set("pageToken", token)
+		}
+		data, err := p.makeAPIRequest(ctx, "GET", fmt.Sprintf("%s/%s?%s", p.baseURLs["cloudresourcemanager"], collection, query.Encode()), nil)
+		if err != nil {
+			return fmt.Errorf("failed to list %s under %s: %w", collection, parent, err)
+		}
+		if token, err = read(data); err != nil {
+			return err
+		}
+		if token == "" {
+			return nil
+		}
+	}
+}
+
+// DiscoverProject discovers the supported resources of one project, recording the project
+// as each resource's account. Resource types whose API fails, such as one not enabled in the
+// project, are skipped; an error is returned only when every type fails.
+func (p *GCPProviderComplete) DiscoverProject(ctx context.Context, projectID string) ([]models.Resource, error) {
+	project := *p
+	project.projectID = projectID
+
+	var resources []models.Resource
+	var firstErr error
+	failed := 0
+	types := project.SupportedResourceTypes()
+	for _, resourceType := range types {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		listed, err := project.ListResources(ctx, resourceType)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			failed++
+			continue
+		}
+		for _, resource := range listed {
+			resource.Provider = "gcp"
+			resource.AccountID = projectID
+			if resource.Type == "" {
+				resource.Type = resourceType
+			}
+			resources = append(resources, *resource)
+		}
+	}
+	if failed == len(types) {
+		return nil, firstErr
+	}
+	return resources, nil
+}
+
+// DiscoverOrganization discovers every active project under an organization or folder, a few
+// projects at a time. The provider must be connected.
+func (p *GCPProviderComplete) DiscoverOrganization(ctx context.Context, parent string, options OrganizationDiscoveryOptions) (*OrganizationDiscovery, error) {
+	projects, err := p.ListProjects(ctx, parent)
+	if err != nil {
+		return nil, err
+	}
+	return discoverProjects(ctx, parent, projects, options, p.DiscoverProject), nil
+}
+
+// discoverProjects discovers the selected active projects with discover
+func discoverProjects(ctx context.Context, parent string, projects []Project, options OrganizationDiscoveryOptions, discover func(ctx context.Context, projectID string) ([]models.Resource, error)) *OrganizationDiscovery {
+	include := make(map[string]bool, len(options.Include))
+	for _, id := range options.Include {
+		include[id] = true
+	}
+	exclude := make(map[string]bool, len(options.Exclude))
+	for _, id := range options.Exclude {
+		exclude[id] = true
+	}
+
+	result := &OrganizationDiscovery{Parent: parent, Skipped: []Project{}}
+	var selected []Project
+	for _, project := range projects {
+		if project.State != "ACTIVE" || exclude[project.ID] || (len(include) > 0 && !include[project.ID]) ||
+			(options.Filter != nil && !options.Filter(project)) {
+			result.Skipped = append(result.Skipped, project)
+			continue
+		}
+		selected = append(selected, project)
+	}
+
+	concurrency := options.Concurrency
+	if concurrency <= 0 {
+		concurrency = defaultOrganizationConcurrency
+	}
+	result.Projects = make([]ProjectDiscovery, len(selected))
+	semaphore := make(chan struct{}, concurrency)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	done := 0
+	for i, project := range selected {
+		wg.Add(1)
+		go func(i int, project Project) {
+			defer wg.Done()
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+
+			discovered := ProjectDiscovery{Project: project}
+			resources, err := discover(ctx, project.ID)
+			if err != nil {
+				discovered.Error = err.Error()
+			} else {
+				discovered.Resources = resources
+			}
+			result.Projects[i] = discovered
+
+			if options.Progress != nil {
+				mu.Lock()
+				done++
+				options.Progress(done, len(selected), discovered)
+				mu.Unlock()
+			}
+		}(i, project)
+	}
+	wg.Wait()
+	return result
+}
@@ -0,0 +1,91 @@
+package gcp
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/catherinevee/driftmgr/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrganizationParent(t *testing.T) {
+	parent, err := OrganizationParent("123", "")
+	require.NoError(t, err)
+	assert.Equal(t, "organizations/123", parent)
+	parent, err = OrganizationParent("", "folders/456")
+	require.NoError(t, err)
+	assert.Equal(t, "folders/456", parent)
+
+	_, err = OrganizationParent("123", "456")
+	assert.Error(t, err)
+	_, err = OrganizationParent("", "")
+	assert.Error(t, err)
+}
+
+func TestListProjects(t *testing.T) {
+	responses := map[string]string{
+		"/projects?parent=organizations%2F1":          `{"projects": [{"name": "projects/11", "projectId": "shared", "state": "ACTIVE", "parent": "organizations/1"}]}`,
+		"/folders?parent=organizations%2F1":           `{"folders": [{"name": "folders/2", "displayName": "Prod"}]}`,
+		"/projects?parent=folders%2F2":                `{"projects": [{"name": "projects/12", "projectId": "web", "state": "ACTIVE"}], "nextPageToken": "next"}`,
+		"/projects?pageToken=next&parent=folders%2F2": `{"projects": [{"name": "projects/13", "projectId": "api", "state": "DELETE_REQUESTED"}]}`,
+		"/folders?parent=folders%2F2":                 `{"folders": [{"name": "folders/3", "displayName": "EU"}]}`,
+		"/projects?parent=folders%2F3":                `{"projects": [{"name": "projects/14", "projectId": "eu-web", "state": "ACTIVE"}]}`,
+		"/folders?parent=folders%2F3":                 `{}`,
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, ok := responses[r.URL.RequestURI()]
+		if !ok {
+			http.Error(w, r.URL.RequestURI(), http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(body))
+	}))
+	defer server.Close()
+
+	provider := NewGCPProviderComplete("")
+	provider.httpClient = server.Client()
+	provider.baseURLs["cloudresourcemanager"] = server.URL
+
+	projects, err := provider.ListProjects(context.Background(), "organizations/1")
+	require.NoError(t, err)
+	require.Len(t, projects, 4)
+	assert.Equal(t, []string{"api", "eu-web", "shared", "web"}, []string{projects[0].ID, projects[1].ID, projects[2].ID, projects[3].ID})
+	assert.Equal(t, "Prod/EU", projects[1].Path)
+	assert.Equal(t, "", projects[2].Path)
+	assert.Equal(t, "12", projects[3].Number)
+}
+
+func TestDiscoverProjects(t *testing.T) {
+	projects := []Project{
+		{ID: "api", State: "DELETE_REQUESTED"},
+		{ID: "broken", State: "ACTIVE"},
+		{ID: "sandbox", State: "ACTIVE"},
+		{ID: "shared", State: "ACTIVE"},
+		{ID: "web", State: "ACTIVE"},
+	}
+	discover := func(ctx context.Context, projectID string) ([]models.Resource, error) {
+		if projectID == "broken" {
+			return nil, errors.New("permission denied")
+		}
+		return []models.Resource{{ID: projectID + "-bucket", AccountID: projectID}}, nil
+	}
+	progress := 0
+	result := discoverProjects(context.Background(), "folders/2", projects, OrganizationDiscoveryOptions{
+		Exclude:  []string{"sandbox"},
+		Progress: func(done, total int, project ProjectDiscovery) { progress++ },
+	}, discover)
+
+	assert.Len(t, result.Skipped, 2, "inactive and excluded projects are skipped")
+	require.Len(t, result.Projects, 3)
+	assert.Equal(t, 3, progress)
+	assert.Equal(t, "permission denied", result.Projects[0].Error)
+	assert.Len(t, result.Projects[1].Resources, 1)
+
+	result = discoverProjects(context.Background(), "folders/2", projects, OrganizationDiscoveryOptions{Include: []string{"web"}}, discover)
+	require.Len(t, result.Projects, 1)
+	assert.Equal(t, "web", result.Projects[0].Project.ID)
+}
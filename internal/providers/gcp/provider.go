@@ -4,17 +4,21 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"net/http"
+	"net/url"
 	"os"
+	"sort"
 	"strings"
 	"time"
 
 	"golang.org/x/oauth2"
 	"golang.org/x/oauth2/google"
 
+	"github.com/catherinevee/driftmgr/internal/shared/backoff"
 	"github.com/catherinevee/driftmgr/pkg/models"
 )
 
@@ -35,7 +39,8 @@ func NewGCPProviderComplete(projectID string) *GCPProviderComplete {
 		region:    "us-central1",
 		zone:      "us-central1-a",
 		httpClient: &http.Client{
-			Timeout: 30 * time.Second,
+			Timeout:   30 * time.Second,
+			Transport: backoff.NewTransport(nil, "gcp"),
 		},
 		baseURLs: map[string]string{
 			"compute":              "https://compute.googleapis.com/compute/v1",
@@ -92,7 +97,9 @@ func (p *GCPProviderComplete) Connect(ctx context.Context) error {
 	}
 
 	p.tokenSource = creds.TokenSource
-	p.httpClient = oauth2.NewClient(ctx, p.tokenSource)
+	// Authenticated requests go through the throttling-aware transport as well
+	baseClient := &http.Client{Transport: backoff.NewTransport(nil, "gcp")}
+	p.httpClient = oauth2.NewClient(context.WithValue(ctx, oauth2.HTTPClient, baseClient), p.tokenSource)
 
 	// If project ID not set, try to get it from credentials
 	if p.projectID == "" && creds.ProjectID != "" {
@@ -205,16 +212,52 @@ func (p *GCPProviderComplete) ValidateCredentials(ctx context.Context) error {
 	return p.Connect(ctx)
 }
 
-// ListRegions returns available regions for the provider (implements CloudProvider interface)
+// ListRegions returns the Compute Engine regions available to the project that are up
+// (implements CloudProvider interface)
 func (p *GCPProviderComplete) ListRegions(ctx context.Context) ([]string, error) {
-	// Return common GCP regions
-	return []string{
-		"us-central1", "us-east1", "us-east4", "us-west1", "us-west2", "us-west3", "us-west4",
-		"europe-west1", "europe-west2", "europe-west3", "europe-west4", "europe-west6",
-		"asia-east1", "asia-east2", "asia-northeast1", "asia-northeast2", "asia-northeast3",
-		"asia-south1", "asia-southeast1", "asia-southeast2",
-		"australia-southeast1", "southamerica-east1", "northamerica-northeast1",
-	}, nil
+	if p.httpClient == nil || p.tokenSource == nil {
+		if err := p.Connect(ctx); err != nil {
+			return nil, err
+		}
+	}
+	if p.projectID == "" {
+		return nil, errors.New("no GCP project is configured")
+	}
+
+	var regions []string
+	pageToken := ""
+	for {
+		endpoint := fmt.Sprintf("%s/projects/%s/regions?fields=items(name,status),nextPageToken", p.baseURLs["compute"], url.PathEscape(p.projectID))
+		if pageToken != "" {
+			endpoint += "&pageToken=" + url.QueryEscape(pageToken)
+		}
+		data, err := p.makeAPIRequest(ctx, "GET", endpoint, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list GCP regions: %w", err)
+		}
+
+		var page struct {
+			Items []struct {
+				Name   string `json:"name"`
+				Status string `json:"status"`
+			} `json:"items"`
+			NextPageToken string `json:"nextPageToken"`
+		}
+		if err := json.Unmarshal(data, &page); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal region list: %w", err)
+		}
+		for _, region := range page.Items {
+			if region.Status == "UP" {
+				regions = append(regions, region.Name)
+			}
+		}
+		if page.NextPageToken == "" {
+			break
+		}
+		pageToken = page.NextPageToken
+	}
+	sort.Strings(regions)
+	return regions, nil
 }
 
 // SupportedResourceTypes returns the list of supported resource types (implements CloudProvider interface)
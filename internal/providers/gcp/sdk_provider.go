@@ -144,7 +144,7 @@ func (p *GCPSDKProvider) discoverComputeInstances(ctx context.Context) ([]models
 				Region:       zone,
 				Attributes:   attributes,
 				Tags:         labels,
-				CreatedAt:    time.Now(), // Use current time as fallback
+				CreatedAt:    parseCreationTimestamp(instance.CreationTimestamp),
 				LastModified: time.Now(),
 			}
 
@@ -211,6 +211,19 @@ func (p *GCPSDKProvider) extractZoneFromURL(url string) string {
 	return ""
 }
 
+// parseCreationTimestamp parses a Compute Engine RFC 3339 creation timestamp, returning the
+// zero time when it is missing or malformed so the resource's age is reported as unknown
+func parseCreationTimestamp(timestamp *string) time.Time {
+	if timestamp == nil {
+		return time.Time{}
+	}
+	created, err := time.Parse(time.RFC3339, *timestamp)
+	if err != nil {
+		return time.Time{}
+	}
+	return created
+}
+
 // extractMachineTypeFromURL extracts machine type from GCP resource URL
 func (p *GCPSDKProvider) extractMachineTypeFromURL(url string) string {
 	parts := strings.Split(url, "/")
@@ -293,7 +306,7 @@ func (p *GCPSDKProvider) GetResource(ctx context.Context, resourceID string) (*m
 		Region:       p.extractZoneFromURL(*instance.Zone),
 		Attributes:   attributes,
 		Tags:         labels,
-		CreatedAt:    time.Now(),
+		CreatedAt:    parseCreationTimestamp(instance.CreationTimestamp),
 		LastModified: time.Now(),
 	}, nil
 }
@@ -418,7 +431,7 @@ func (p *GCPSDKProvider) GetResourceByType(ctx context.Context, resourceType, re
 			Region:       p.extractZoneFromURL(*instance.Zone),
 			Attributes:   attributes,
 			Tags:         labels,
-			CreatedAt:    time.Now(),
+			CreatedAt:    parseCreationTimestamp(instance.CreationTimestamp),
 			LastModified: time.Now(),
 		}, nil
 
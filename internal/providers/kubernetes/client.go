@@ -0,0 +1,326 @@
+package kubernetes
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/catherinevee/driftmgr/internal/shared/backoff"
+)
+
+// listPageSize is how many objects each list call asks the API server for
+const listPageSize = 500
+
+// APIError is an error status returned by the Kubernetes API server
+type APIError struct {
+	StatusCode int
+	Reason     string // such as Forbidden or NotFound
+	Message    string
+}
+
+// Error implements the error interface
+func (e *APIError) Error() string {
+	if e.Message != "" {
+		return e.Message
+	}
+	return fmt.Sprintf("kubernetes API returned status %d", e.StatusCode)
+}
+
+// Forbidden reports whether RBAC denied the request
+func (e *APIError) Forbidden() bool {
+	return e.StatusCode == http.StatusForbidden
+}
+
+// object is the part of a Kubernetes object discovery reads
+type object struct {
+	APIVersion string                 `json:"apiVersion"`
+	Kind       string                 `json:"kind"`
+	Metadata   objectMeta             `json:"metadata"`
+	Spec       map[string]interface{} `json:"spec,omitempty"`
+	Status     map[string]interface{} `json:"status,omitempty"`
+	Data       map[string]string      `json:"data,omitempty"`
+}
+
+// objectMeta is an object's metadata
+type objectMeta struct {
+	Name              string            `json:"name"`
+	Namespace         string            `json:"namespace,omitempty"`
+	UID               string            `json:"uid"`
+	ResourceVersion   string            `json:"resourceVersion,omitempty"`
+	Generation        int64             `json:"generation,omitempty"`
+	CreationTimestamp time.Time         `json:"creationTimestamp"`
+	Labels            map[string]string `json:"labels,omitempty"`
+	Annotations       map[string]string `json:"annotations,omitempty"`
+}
+
+// objectList is a page of a list call
+type objectList struct {
+	Metadata struct {
+		Continue string `json:"continue,omitempty"`
+	} `json:"metadata"`
+	Items []object `json:"items"`
+}
+
+// client calls the API server of one context
+type client struct {
+	server     string
+	httpClient *http.Client
+	user       User
+
+	mu          sync.Mutex
+	execToken   string
+	execExpires time.Time
+
+	resourcesMu sync.Mutex
+	resources   map[string][]apiResource // by group version, such as apps/v1
+}
+
+// apiResource is a resource an API group version serves
+type apiResource struct {
+	Name       string `json:"name"` // plural name in API paths; subresources contain a slash
+	Kind       string `json:"kind"`
+	Namespaced bool   `json:"namespaced"`
+}
+
+// newClient creates a client for a context's cluster and user
+func newClient(cluster Cluster, user User) (*client, error) {
+	if cluster.Server == "" {
+		return nil, fmt.Errorf("cluster has no server")
+	}
+	if user.AuthProvider != nil {
+		return nil, fmt.Errorf("the %s auth-provider is not supported; use an exec credential plugin", user.AuthProvider.Name)
+	}
+
+	tlsConfig := &tls.Config{
+		InsecureSkipVerify: cluster.InsecureSkipTLSVerify,
+		ServerName:         cluster.TLSServerName,
+	}
+	ca, err := fileOrData(cluster.CertificateAuthority, cluster.CertificateAuthorityData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read certificate authority: %w", err)
+	}
+	if len(ca) > 0 {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(ca) {
+			return nil, fmt.Errorf("certificate authority has no PEM certificates")
+		}
+		tlsConfig.RootCAs = pool
+	}
+	cert, err := fileOrData(user.ClientCertificate, user.ClientCertificateData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read client certificate: %w", err)
+	}
+	key, err := fileOrData(user.ClientKey, user.ClientKeyData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read client key: %w", err)
+	}
+	if len(cert) > 0 {
+		pair, err := tls.X509KeyPair(cert, key)
+		if err != nil {
+			return nil, fmt.Errorf("invalid client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{pair}
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+	return &client{
+		server: strings.TrimSuffix(cluster.Server, "/"),
+		httpClient: &http.Client{
+			Timeout:   30 * time.Second,
+			Transport: backoff.NewTransport(transport, "kubernetes"),
+		},
+		user: user,
+	}, nil
+}
+
+// fileOrData returns inline base64 data, or else the contents of a file
+func fileOrData(path, data string) ([]byte, error) {
+	if data != "" {
+		return base64.StdEncoding.DecodeString(data)
+	}
+	if path != "" {
+		return os.ReadFile(path)
+	}
+	return nil, nil
+}
+
+// get decodes the JSON response to a GET of path into v
+func (c *client) get(ctx context.Context, path string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.server+path, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if err := c.authorize(ctx, req); err != nil {
+		return err
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to call %s: %w", c.server, err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		apiErr := &APIError{StatusCode: resp.StatusCode}
+		var status struct {
+			Reason  string `json:"reason"`
+			Message string `json:"message"`
+		}
+		if json.Unmarshal(body, &status) == nil {
+			apiErr.Reason, apiErr.Message = status.Reason, status.Message
+		}
+		return apiErr
+	}
+	return json.Unmarshal(body, v)
+}
+
+// list returns every object at a list path, following continue tokens. query adds
+// parameters such as labelSelector.
+func (c *client) list(ctx context.Context, path string, query url.Values) ([]object, error) {
+	var objects []object
+	token := ""
+	for {
+		params := url.Values{"limit": {fmt.Sprint(listPageSize)}}
+		for key, values := range query {
+			params[key] = values
+		}
+		if token != "" {
+			params.Set("continue", token)
+		}
+		var page objectList
+		if err := c.get(ctx, path+"?"+params.Encode(), &page); err != nil {
+			return nil, err
+		}
+		objects = append(objects, page.Items...)
+		if page.Metadata.Continue == "" {
+			return objects, nil
+		}
+		token = page.Metadata.Continue
+	}
+}
+
+// authorize sets the request's credentials from the context's user
+func (c *client) authorize(ctx context.Context, req *http.Request) error {
+	user := c.user
+	switch {
+	case user.Token != "":
+		req.Header.Set("Authorization", "Bearer "+user.Token)
+	case user.TokenFile != "":
+		token, err := os.ReadFile(user.TokenFile)
+		if err != nil {
+			return fmt.Errorf("failed to read token file: %w", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+strings.TrimSpace(string(token)))
+	case user.Exec != nil:
+		token, err := c.execCredential(ctx)
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+	case user.Username != "":
+		req.SetBasicAuth(user.Username, user.Password)
+	}
+	return nil
+}
+
+// execCredential runs the user's credential plugin, reusing its token until a minute before
+// it expires
+func (c *client) execCredential(ctx context.Context) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.execToken != "" && time.Now().Before(c.execExpires) {
+		return c.execToken, nil
+	}
+
+	plugin := c.user.Exec
+	cmd := exec.CommandContext(ctx, plugin.Command, plugin.Args...)
+	cmd.Env = os.Environ()
+	for _, env := range plugin.Env {
+		cmd.Env = append(cmd.Env, env.Name+"="+env.Value)
+	}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("credential plugin %s failed: %w: %s", plugin.Command, err, strings.TrimSpace(stderr.String()))
+	}
+
+	var credential struct {
+		Status struct {
+			Token               string    `json:"token"`
+			ExpirationTimestamp time.Time `json:"expirationTimestamp"`
+		} `json:"status"`
+	}
+	if err := json.Unmarshal(output, &credential); err != nil {
+		return "", fmt.Errorf("credential plugin %s printed an invalid ExecCredential: %w", plugin.Command, err)
+	}
+	if credential.Status.Token == "" {
+		return "", fmt.Errorf("credential plugin %s returned no token; client certificates from plugins are not supported", plugin.Command)
+	}
+	c.execToken = credential.Status.Token
+	c.execExpires = time.Now().Add(5 * time.Minute)
+	if !credential.Status.ExpirationTimestamp.IsZero() {
+		c.execExpires = credential.Status.ExpirationTimestamp.Add(-time.Minute)
+	}
+	return c.execToken, nil
+}
+
+// objectPath returns the API path of an object of any kind, looking up the kind's resource
+// name in the API server's discovery document for its group version. Namespaced objects
+// without a namespace are in defaultNamespace.
+func (c *client) objectPath(ctx context.Context, apiVersion, kind, namespace, name, defaultNamespace string) (string, error) {
+	prefix := "/apis/" + apiVersion
+	if !strings.Contains(apiVersion, "/") {
+		prefix = "/api/" + apiVersion
+	}
+
+	c.resourcesMu.Lock()
+	resources, ok := c.resources[apiVersion]
+	c.resourcesMu.Unlock()
+	if !ok {
+		var list struct {
+			Resources []apiResource `json:"resources"`
+		}
+		if err := c.get(ctx, prefix, &list); err != nil {
+			return "", fmt.Errorf("failed to discover the resources of %s: %w", apiVersion, err)
+		}
+		resources = list.Resources
+		c.resourcesMu.Lock()
+		if c.resources == nil {
+			c.resources = make(map[string][]apiResource)
+		}
+		c.resources[apiVersion] = resources
+		c.resourcesMu.Unlock()
+	}
+
+	for _, resource := range resources {
+		if resource.Kind != kind || strings.Contains(resource.Name, "/") {
+			continue
+		}
+		if !resource.Namespaced {
+			return prefix + "/" + resource.Name + "/" + url.PathEscape(name), nil
+		}
+		if namespace == "" {
+			namespace = defaultNamespace
+		}
+		return prefix + "/namespaces/" + url.PathEscape(namespace) + "/" + resource.Name + "/" + url.PathEscape(name), nil
+	}
+	return "", fmt.Errorf("%s does not serve kind %s", apiVersion, kind)
+}
@@ -0,0 +1,509 @@
+package kubernetes
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// helmCommand is the Helm CLI charts are rendered with
+var helmCommand = "helm"
+
+// ErrReleaseNotFound is returned when a namespace has no deployed revision of a Helm release
+var ErrReleaseNotFound = errors.New("helm release not found")
+
+// Object drift statuses
+const (
+	ObjectInSync     = "in_sync"
+	ObjectModified   = "modified"
+	ObjectMissing    = "missing"      // rendered by the chart but not in the cluster
+	ObjectNotInChart = "not_in_chart" // deployed by the release but no longer rendered
+)
+
+// Causes of a field difference
+const (
+	// CauseEdited is a live value changed since the release was deployed, such as by
+	// kubectl edit
+	CauseEdited = "edited"
+	// CauseUndeployed is a chart or values change the deployed release does not have yet
+	CauseUndeployed = "undeployed"
+)
+
+// helmRelease is a revision of a Helm release, read from the Secret Helm stores it in
+type helmRelease struct {
+	Name         string
+	Revision     int
+	Chart        string
+	ChartVersion string
+	Values       map[string]interface{} // the values given to the release, without chart defaults
+	Manifest     string
+}
+
+// HelmDriftOptions selects a release and, optionally, the chart and values it should match
+type HelmDriftOptions struct {
+	// Context is the kubeconfig context of the release's cluster; empty is the current context
+	Context string
+	// Release and Namespace name the release; an empty namespace is the context's namespace
+	Release   string
+	Namespace string
+	// Chart is the path of the chart the release should match. Without one, live objects are
+	// compared with the manifest the release deployed.
+	Chart string
+	// ValuesFiles and Values are the release's values, merged in order like helm -f and --set
+	ValuesFiles []string
+	Values      map[string]interface{}
+}
+
+// HelmDrift reports how a Helm release's live objects differ from its chart and values
+type HelmDrift struct {
+	Release        string            `json:"release"`
+	Namespace      string            `json:"namespace"`
+	Context        string            `json:"context"`
+	Revision       int               `json:"revision"`
+	Chart          string            `json:"chart"`
+	ChartVersion   string            `json:"chart_version"`
+	Drifted        bool              `json:"drifted"`
+	DriftedObjects int               `json:"drifted_objects"`
+	Values         []ValueDifference `json:"values,omitempty"`
+	Objects        []ObjectDrift     `json:"objects"`
+}
+
+// ValueDifference is a value the chart's values set differently from the deployed release
+type ValueDifference struct {
+	Path     string      `json:"path"`
+	Expected interface{} `json:"expected"`
+	Deployed interface{} `json:"deployed"`
+}
+
+// ObjectDrift is the drift of one object of a release
+type ObjectDrift struct {
+	APIVersion  string            `json:"api_version"`
+	Kind        string            `json:"kind"`
+	Namespace   string            `json:"namespace,omitempty"`
+	Name        string            `json:"name"`
+	Status      string            `json:"status"`
+	Differences []FieldDifference `json:"differences,omitempty"`
+}
+
+// FieldDifference is a field whose live value is not the one the chart renders. Deployed is
+// the value in the manifest of the deployed release, which tells edits in the cluster apart
+// from chart changes that are not deployed yet.
+type FieldDifference struct {
+	Path     string      `json:"path"`
+	Expected interface{} `json:"expected"`
+	Deployed interface{} `json:"deployed"`
+	Live     interface{} `json:"live"`
+	Cause    string      `json:"cause"`
+}
+
+// manifestObject is an object of a rendered manifest
+type manifestObject struct {
+	APIVersion string
+	Kind       string
+	Namespace  string
+	Name       string
+	Content    map[string]interface{}
+}
+
+func (o manifestObject) key() string {
+	return o.APIVersion + "/" + o.Kind + "/" + o.Namespace + "/" + o.Name
+}
+
+// HelmDrift compares the live objects of a release with the manifest its chart and values
+// render, or with the manifest it deployed when no chart is given. Only the fields the
+// manifest sets are compared, so defaults and fields set by controllers are not drift.
+func (p *KubernetesProvider) HelmDrift(ctx context.Context, options HelmDriftOptions) (*HelmDrift, error) {
+	api, contextName, kubeContext, err := p.clientFor(options.Context)
+	if err != nil {
+		return nil, err
+	}
+	namespace := options.Namespace
+	if namespace == "" {
+		namespace = kubeContext.Namespace
+	}
+	if namespace == "" {
+		namespace = "default"
+	}
+
+	release, err := getHelmRelease(ctx, api, namespace, options.Release)
+	if err != nil {
+		return nil, err
+	}
+	deployed, err := parseManifest(release.Manifest)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the manifest of release %s: %w", release.Name, err)
+	}
+
+	report := &HelmDrift{
+		Release:      release.Name,
+		Namespace:    namespace,
+		Context:      contextName,
+		Revision:     release.Revision,
+		Chart:        release.Chart,
+		ChartVersion: release.ChartVersion,
+		Objects:      []ObjectDrift{},
+	}
+	expected := deployed
+	if options.Chart != "" {
+		values, err := LoadHelmValues(options.ValuesFiles, options.Values)
+		if err != nil {
+			return nil, err
+		}
+		report.Values = diffValues("", values, normalize(release.Values))
+		rendered, err := renderChart(ctx, release.Name, namespace, options.Chart, values)
+		if err != nil {
+			return nil, err
+		}
+		if expected, err = parseManifest(rendered); err != nil {
+			return nil, fmt.Errorf("failed to parse the manifest rendered from %s: %w", options.Chart, err)
+		}
+	}
+
+	deployedByKey := make(map[string]manifestObject, len(deployed))
+	for _, object := range deployed {
+		deployedByKey[object.key()] = object
+	}
+	rendered := make(map[string]bool, len(expected))
+	for _, object := range expected {
+		rendered[object.key()] = true
+		drift, err := objectDrift(ctx, api, namespace, object, deployedByKey[object.key()].Content)
+		if err != nil {
+			return nil, err
+		}
+		report.Objects = append(report.Objects, drift)
+	}
+	for _, object := range deployed {
+		if !rendered[object.key()] {
+			report.Objects = append(report.Objects, ObjectDrift{
+				APIVersion: object.APIVersion,
+				Kind:       object.Kind,
+				Namespace:  object.Namespace,
+				Name:       object.Name,
+				Status:     ObjectNotInChart,
+			})
+		}
+	}
+
+	for _, object := range report.Objects {
+		if object.Status != ObjectInSync {
+			report.DriftedObjects++
+		}
+	}
+	report.Drifted = report.DriftedObjects > 0 || len(report.Values) > 0
+	return report, nil
+}
+
+// objectDrift compares the live copy of an expected object with it
+func objectDrift(ctx context.Context, api *client, namespace string, expected manifestObject, deployed map[string]interface{}) (ObjectDrift, error) {
+	drift := ObjectDrift{
+		APIVersion: expected.APIVersion,
+		Kind:       expected.Kind,
+		Namespace:  expected.Namespace,
+		Name:       expected.Name,
+		Status:     ObjectInSync,
+	}
+	path, err := api.objectPath(ctx, expected.APIVersion, expected.Kind, expected.Namespace, expected.Name, namespace)
+	if err != nil {
+		return drift, err
+	}
+	var live map[string]interface{}
+	if err := api.get(ctx, path, &live); err != nil {
+		var apiErr *APIError
+		if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound {
+			drift.Status = ObjectMissing
+			return drift, nil
+		}
+		return drift, fmt.Errorf("failed to get %s %s: %w", expected.Kind, expected.Name, err)
+	}
+
+	drift.Differences = diffFields("", expected.Content, deployed, live)
+	if len(drift.Differences) > 0 {
+		drift.Status = ObjectModified
+	}
+	return drift, nil
+}
+
+// diffFields returns the fields set in expected whose live value differs. Lists are compared
+// item by item, and live items past the expected ones are ignored, like sidecars injected by
+// admission webhooks.
+func diffFields(path string, expected, deployed, live interface{}) []FieldDifference {
+	switch expectedValue := expected.(type) {
+	case map[string]interface{}:
+		liveMap, ok := live.(map[string]interface{})
+		if !ok {
+			break
+		}
+		deployedMap, _ := deployed.(map[string]interface{})
+		var differences []FieldDifference
+		for _, key := range sortedKeys(expectedValue) {
+			differences = append(differences, diffFields(fieldPath(path, key), expectedValue[key], deployedMap[key], liveMap[key])...)
+		}
+		return differences
+	case []interface{}:
+		liveList, ok := live.([]interface{})
+		if !ok || len(liveList) < len(expectedValue) {
+			break
+		}
+		deployedList, _ := deployed.([]interface{})
+		var differences []FieldDifference
+		for i, item := range expectedValue {
+			var deployedItem interface{}
+			if i < len(deployedList) {
+				deployedItem = deployedList[i]
+			}
+			differences = append(differences, diffFields(path+"["+strconv.Itoa(i)+"]", item, deployedItem, liveList[i])...)
+		}
+		return differences
+	}
+
+	if reflect.DeepEqual(expected, live) {
+		return nil
+	}
+	cause := CauseEdited
+	if reflect.DeepEqual(deployed, live) {
+		cause = CauseUndeployed
+	}
+	return []FieldDifference{{Path: path, Expected: expected, Deployed: deployed, Live: live, Cause: cause}}
+}
+
+// diffValues returns the values that differ between expected and deployed, in either
+func diffValues(path string, expected, deployed map[string]interface{}) []ValueDifference {
+	keys := sortedKeys(expected)
+	for key := range deployed {
+		if _, ok := expected[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	var differences []ValueDifference
+	for _, key := range keys {
+		expectedValue, deployedValue := expected[key], deployed[key]
+		expectedMap, expectedIsMap := expectedValue.(map[string]interface{})
+		deployedMap, deployedIsMap := deployedValue.(map[string]interface{})
+		if expectedIsMap && deployedIsMap {
+			differences = append(differences, diffValues(fieldPath(path, key), expectedMap, deployedMap)...)
+		} else if !reflect.DeepEqual(expectedValue, deployedValue) {
+			differences = append(differences, ValueDifference{Path: fieldPath(path, key), Expected: expectedValue, Deployed: deployedValue})
+		}
+	}
+	return differences
+}
+
+// identifier matches map keys that can be written after a dot in a field path
+var identifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_-]*$`)
+
+// fieldPath appends a map key to a field path, quoting keys such as app.kubernetes.io/name
+func fieldPath(path, key string) string {
+	if !identifier.MatchString(key) {
+		return path + "[" + strconv.Quote(key) + "]"
+	}
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// getHelmRelease reads the latest deployed revision of a release from the Secrets Helm 3
+// stores releases in
+func getHelmRelease(ctx context.Context, api *client, namespace, name string) (*helmRelease, error) {
+	secrets, err := api.list(ctx, "/api/v1/namespaces/"+url.PathEscape(namespace)+"/secrets",
+		url.Values{"labelSelector": {"owner=helm,name=" + name}})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the revisions of release %s: %w", name, err)
+	}
+
+	var latest *object
+	latestRevision := 0
+	for i, secret := range secrets {
+		revision, _ := strconv.Atoi(secret.Metadata.Labels["version"])
+		if secret.Metadata.Labels["status"] == "deployed" && revision > latestRevision {
+			latest, latestRevision = &secrets[i], revision
+		}
+	}
+	if latest == nil {
+		return nil, fmt.Errorf("%w: %s has no deployed revision in namespace %s", ErrReleaseNotFound, name, namespace)
+	}
+	release, err := decodeHelmRelease(latest.Data["release"])
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode revision %d of release %s: %w", latestRevision, name, err)
+	}
+	return release, nil
+}
+
+// decodeHelmRelease decodes the release field of a Helm release Secret: the Secret's base64
+// of Helm's base64 of the gzipped release JSON
+func decodeHelmRelease(data string) (*helmRelease, error) {
+	encoded, err := base64.StdEncoding.DecodeString(data)
+	if err != nil {
+		return nil, err
+	}
+	raw, err := base64.StdEncoding.DecodeString(string(encoded))
+	if err != nil {
+		return nil, err
+	}
+	if bytes.HasPrefix(raw, []byte{0x1f, 0x8b}) {
+		reader, err := gzip.NewReader(bytes.NewReader(raw))
+		if err != nil {
+			return nil, err
+		}
+		defer reader.Close()
+		if raw, err = io.ReadAll(reader); err != nil {
+			return nil, err
+		}
+	}
+
+	var release struct {
+		Name     string                 `json:"name"`
+		Version  int                    `json:"version"`
+		Config   map[string]interface{} `json:"config"`
+		Manifest string                 `json:"manifest"`
+		Chart    struct {
+			Metadata struct {
+				Name    string `json:"name"`
+				Version string `json:"version"`
+			} `json:"metadata"`
+		} `json:"chart"`
+	}
+	if err := json.Unmarshal(raw, &release); err != nil {
+		return nil, err
+	}
+	return &helmRelease{
+		Name:         release.Name,
+		Revision:     release.Version,
+		Chart:        release.Chart.Metadata.Name,
+		ChartVersion: release.Chart.Metadata.Version,
+		Values:       release.Config,
+		Manifest:     release.Manifest,
+	}, nil
+}
+
+// LoadHelmValues merges values files in order and then values over them, as helm -f and --set
+// do: maps are merged key by key and other values replaced
+func LoadHelmValues(files []string, values map[string]interface{}) (map[string]interface{}, error) {
+	merged := map[string]interface{}{}
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read values file: %w", err)
+		}
+		var fileValues map[string]interface{}
+		if err := yaml.Unmarshal(data, &fileValues); err != nil {
+			return nil, fmt.Errorf("failed to parse values file %s: %w", file, err)
+		}
+		mergeValues(merged, normalize(fileValues))
+	}
+	mergeValues(merged, normalize(values))
+	return merged, nil
+}
+
+func mergeValues(dst, src map[string]interface{}) {
+	for key, value := range src {
+		srcMap, srcIsMap := value.(map[string]interface{})
+		dstMap, dstIsMap := dst[key].(map[string]interface{})
+		if srcIsMap && dstIsMap {
+			mergeValues(dstMap, srcMap)
+			continue
+		}
+		dst[key] = value
+	}
+}
+
+// normalize converts decoded YAML to the types decoded JSON has, so values read from YAML
+// compare equal to the same values read from the API server
+func normalize(m map[string]interface{}) map[string]interface{} {
+	if m == nil {
+		return map[string]interface{}{}
+	}
+	data, err := json.Marshal(m)
+	if err != nil {
+		return m
+	}
+	var normalized map[string]interface{}
+	if err := json.Unmarshal(data, &normalized); err != nil {
+		return m
+	}
+	return normalized
+}
+
+// renderChart renders a chart's manifest for a release with helm template
+func renderChart(ctx context.Context, release, namespace, chart string, values map[string]interface{}) (string, error) {
+	valuesFile, err := os.CreateTemp("", "driftmgr-helm-values-*.json")
+	if err != nil {
+		return "", fmt.Errorf("failed to create values file: %w", err)
+	}
+	defer os.Remove(valuesFile.Name())
+	err = json.NewEncoder(valuesFile).Encode(values)
+	if closeErr := valuesFile.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to write values file: %w", err)
+	}
+
+	cmd := exec.CommandContext(ctx, helmCommand, "template", release, chart,
+		"--namespace", namespace, "--values", valuesFile.Name())
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("helm template %s failed: %w: %s", chart, err, strings.TrimSpace(stderr.String()))
+	}
+	return string(output), nil
+}
+
+// parseManifest splits a rendered manifest into its objects, skipping empty documents
+func parseManifest(manifest string) ([]manifestObject, error) {
+	decoder := yaml.NewDecoder(strings.NewReader(manifest))
+	var objects []manifestObject
+	for {
+		var content map[string]interface{}
+		err := decoder.Decode(&content)
+		if errors.Is(err, io.EOF) {
+			return objects, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(content) == 0 {
+			continue
+		}
+		content = normalize(content)
+		object := manifestObject{Content: content}
+		object.APIVersion, _ = content["apiVersion"].(string)
+		object.Kind, _ = content["kind"].(string)
+		if metadata, ok := content["metadata"].(map[string]interface{}); ok {
+			object.Name, _ = metadata["name"].(string)
+			object.Namespace, _ = metadata["namespace"].(string)
+		}
+		if object.APIVersion == "" || object.Kind == "" || object.Name == "" {
+			return nil, fmt.Errorf("manifest has an object without apiVersion, kind or name")
+		}
+		objects = append(objects, object)
+	}
+}
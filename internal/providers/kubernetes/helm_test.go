@@ -0,0 +1,224 @@
+package kubernetes
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const deployedManifest = `---
+# Source: web/templates/deployment.yaml
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  labels:
+    app.kubernetes.io/name: web
+spec:
+  replicas: 2
+  template:
+    spec:
+      containers:
+        - name: web
+          image: nginx:1.26
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: web-settings
+data:
+  mode: production
+`
+
+// helmReleaseSecret encodes a release as Helm stores it: gzipped JSON in base64, which the
+// Secret's data holds in base64 again
+func helmReleaseSecret(t *testing.T, revision string, status string, release map[string]interface{}) object {
+	var compressed bytes.Buffer
+	writer := gzip.NewWriter(&compressed)
+	require.NoError(t, json.NewEncoder(writer).Encode(release))
+	require.NoError(t, writer.Close())
+	helmEncoded := base64.StdEncoding.EncodeToString(compressed.Bytes())
+	return object{
+		Kind:     "Secret",
+		Metadata: objectMeta{Name: "sh.helm.release.v1.web.v" + revision, Labels: map[string]string{"owner": "helm", "name": "web", "status": status, "version": revision}},
+		Data:     map[string]string{"release": base64.StdEncoding.EncodeToString([]byte(helmEncoded))},
+	}
+}
+
+// helmAPIServer serves a release, the discovery documents of its kinds and live objects
+func helmAPIServer(t *testing.T, live map[string]interface{}) *httptest.Server {
+	release := map[string]interface{}{
+		"name":     "web",
+		"version":  3,
+		"config":   map[string]interface{}{"replicas": 2, "image": map[string]interface{}{"tag": "1.26"}},
+		"manifest": deployedManifest,
+		"chart":    map[string]interface{}{"metadata": map[string]interface{}{"name": "web", "version": "1.4.0"}},
+	}
+	responses := map[string]interface{}{
+		"/api/v1/namespaces/apps/secrets": objectList{Items: []object{
+			helmReleaseSecret(t, "2", "superseded", map[string]interface{}{"name": "web", "version": 2}),
+			helmReleaseSecret(t, "3", "deployed", release),
+		}},
+		"/api/v1/namespaces/other/secrets": objectList{},
+		"/apis/apps/v1": map[string]interface{}{"resources": []apiResource{
+			{Name: "deployments", Kind: "Deployment", Namespaced: true},
+			{Name: "deployments/scale", Kind: "Scale", Namespaced: true},
+		}},
+		"/api/v1": map[string]interface{}{"resources": []apiResource{
+			{Name: "configmaps", Kind: "ConfigMap", Namespaced: true},
+		}},
+	}
+	for path, object := range live {
+		responses[path] = object
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		response, ok := responses[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(map[string]string{"reason": "NotFound"})
+			return
+		}
+		json.NewEncoder(w).Encode(response)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// liveDeployment is the web deployment as the API server returns it, with defaults and
+// an injected sidecar
+func liveDeployment(replicas int, image string) map[string]interface{} {
+	return map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata": map[string]interface{}{
+			"name": "web", "namespace": "apps", "uid": "uid-web",
+			"labels": map[string]interface{}{"app.kubernetes.io/name": "web"},
+		},
+		"spec": map[string]interface{}{
+			"replicas":             replicas,
+			"revisionHistoryLimit": 10,
+			"template": map[string]interface{}{"spec": map[string]interface{}{"containers": []interface{}{
+				map[string]interface{}{"name": "web", "image": image, "imagePullPolicy": "IfNotPresent"},
+				map[string]interface{}{"name": "istio-proxy", "image": "istio/proxyv2"},
+			}}},
+		},
+	}
+}
+
+func TestHelmDriftAgainstDeployedRelease(t *testing.T) {
+	server := helmAPIServer(t, map[string]interface{}{
+		"/apis/apps/v1/namespaces/apps/deployments/web": liveDeployment(5, "nginx:1.26"),
+	})
+	provider := NewKubernetesProvider(writeKubeconfig(t, t.TempDir(), "prod", map[string]string{"prod": server.URL}))
+
+	report, err := provider.HelmDrift(context.Background(), HelmDriftOptions{Release: "web"})
+	require.NoError(t, err)
+	assert.Equal(t, "apps", report.Namespace, "the context's namespace is the default")
+	assert.Equal(t, 3, report.Revision, "the latest deployed revision is compared")
+	assert.Equal(t, "web", report.Chart)
+	assert.Equal(t, "1.4.0", report.ChartVersion)
+	assert.True(t, report.Drifted)
+	assert.Equal(t, 2, report.DriftedObjects)
+	assert.Empty(t, report.Values)
+
+	require.Len(t, report.Objects, 2)
+	assert.Equal(t, ObjectModified, report.Objects[0].Status)
+	assert.Equal(t, []FieldDifference{
+		{Path: "spec.replicas", Expected: 2.0, Deployed: 2.0, Live: 5.0, Cause: CauseEdited},
+	}, report.Objects[0].Differences, "defaults and sidecars are not drift")
+	assert.Equal(t, "web-settings", report.Objects[1].Name)
+	assert.Equal(t, ObjectMissing, report.Objects[1].Status)
+
+	_, err = provider.HelmDrift(context.Background(), HelmDriftOptions{Release: "api", Namespace: "other"})
+	assert.ErrorIs(t, err, ErrReleaseNotFound)
+}
+
+func TestHelmDriftAgainstChart(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake helm is a shell script")
+	}
+	dir := t.TempDir()
+	// The chart now renders a newer image and no longer renders the config map
+	rendered := filepath.Join(dir, "rendered.yaml")
+	require.NoError(t, os.WriteFile(rendered, []byte(`apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  labels:
+    app.kubernetes.io/name: web
+spec:
+  replicas: 2
+  template:
+    spec:
+      containers:
+        - name: web
+          image: nginx:1.27
+`), 0600))
+	script := filepath.Join(dir, "helm")
+	require.NoError(t, os.WriteFile(script, []byte("#!/bin/sh\ncat "+rendered+"\n"), 0700))
+	defer func(command string) { helmCommand = command }(helmCommand)
+	helmCommand = script
+
+	valuesFile := filepath.Join(dir, "values.yaml")
+	require.NoError(t, os.WriteFile(valuesFile, []byte("replicas: 2\nimage:\n  tag: \"1.25\"\n"), 0600))
+
+	server := helmAPIServer(t, map[string]interface{}{
+		"/apis/apps/v1/namespaces/apps/deployments/web": liveDeployment(2, "nginx:1.26"),
+		"/api/v1/namespaces/apps/configmaps/web-settings": map[string]interface{}{
+			"apiVersion": "v1", "kind": "ConfigMap", "metadata": map[string]interface{}{"name": "web-settings"},
+		},
+	})
+	provider := NewKubernetesProvider(writeKubeconfig(t, dir, "prod", map[string]string{"prod": server.URL}))
+
+	report, err := provider.HelmDrift(context.Background(), HelmDriftOptions{
+		Release:     "web",
+		Namespace:   "apps",
+		Chart:       filepath.Join(dir, "chart"),
+		ValuesFiles: []string{valuesFile},
+		Values:      map[string]interface{}{"image": map[string]interface{}{"tag": "1.27"}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []ValueDifference{
+		{Path: "image.tag", Expected: "1.27", Deployed: "1.26"},
+	}, report.Values, "values given inline override the values files")
+
+	require.Len(t, report.Objects, 2)
+	assert.Equal(t, []FieldDifference{{
+		Path:     "spec.template.spec.containers[0].image",
+		Expected: "nginx:1.27",
+		Deployed: "nginx:1.26",
+		Live:     "nginx:1.26",
+		Cause:    CauseUndeployed,
+	}}, report.Objects[0].Differences)
+	assert.Equal(t, ObjectNotInChart, report.Objects[1].Status)
+	assert.Equal(t, 2, report.DriftedObjects)
+}
+
+func TestDiffValues(t *testing.T) {
+	expected := map[string]interface{}{
+		"image":   map[string]interface{}{"tag": "1.27", "repository": "nginx"},
+		"ingress": map[string]interface{}{"app.example.com/class": "public"},
+	}
+	deployed := map[string]interface{}{
+		"image":    map[string]interface{}{"tag": "1.26", "repository": "nginx"},
+		"ingress":  map[string]interface{}{"app.example.com/class": "public"},
+		"replicas": 3.0,
+	}
+	assert.Equal(t, []ValueDifference{
+		{Path: "image.tag", Expected: "1.27", Deployed: "1.26"},
+		{Path: "replicas", Expected: nil, Deployed: 3.0},
+	}, diffValues("", expected, deployed))
+
+	assert.Equal(t, `metadata.labels["app.kubernetes.io/name"]`, fieldPath("metadata.labels", "app.kubernetes.io/name"))
+}
@@ -0,0 +1,272 @@
+package kubernetes
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// InClusterContext names the context of the service account a pod runs as, used when there
+// is no kubeconfig and driftmgr runs inside a cluster
+const InClusterContext = "in-cluster"
+
+// serviceAccountDir holds the credentials Kubernetes mounts into pods
+const serviceAccountDir = "/var/run/secrets/kubernetes.io/serviceaccount"
+
+// Config is the part of a kubeconfig driftmgr uses: its clusters, users and the contexts
+// pairing them, as kubectl reads them
+type Config struct {
+	CurrentContext string         `yaml:"current-context"`
+	Clusters       []NamedCluster `yaml:"clusters"`
+	Users          []NamedUser    `yaml:"users"`
+	Contexts       []NamedContext `yaml:"contexts"`
+}
+
+// NamedCluster is a kubeconfig clusters entry
+type NamedCluster struct {
+	Name    string  `yaml:"name"`
+	Cluster Cluster `yaml:"cluster"`
+}
+
+// Cluster is the API server of a cluster and how to trust it
+type Cluster struct {
+	Server                   string `yaml:"server"`
+	CertificateAuthority     string `yaml:"certificate-authority"`
+	CertificateAuthorityData string `yaml:"certificate-authority-data"`
+	InsecureSkipTLSVerify    bool   `yaml:"insecure-skip-tls-verify"`
+	TLSServerName            string `yaml:"tls-server-name"`
+}
+
+// NamedUser is a kubeconfig users entry
+type NamedUser struct {
+	Name string `yaml:"name"`
+	User User   `yaml:"user"`
+}
+
+// User is how a context authenticates: a bearer token, a client certificate, basic auth or
+// a credential plugin such as aws eks get-token or gke-gcloud-auth-plugin
+type User struct {
+	Token                 string        `yaml:"token"`
+	TokenFile             string        `yaml:"tokenFile"`
+	ClientCertificate     string        `yaml:"client-certificate"`
+	ClientCertificateData string        `yaml:"client-certificate-data"`
+	ClientKey             string        `yaml:"client-key"`
+	ClientKeyData         string        `yaml:"client-key-data"`
+	Username              string        `yaml:"username"`
+	Password              string        `yaml:"password"`
+	Exec                  *ExecConfig   `yaml:"exec"`
+	AuthProvider          *AuthProvider `yaml:"auth-provider"`
+}
+
+// ExecConfig runs a credential plugin that prints an ExecCredential with a token
+type ExecConfig struct {
+	APIVersion string    `yaml:"apiVersion"`
+	Command    string    `yaml:"command"`
+	Args       []string  `yaml:"args"`
+	Env        []ExecEnv `yaml:"env"`
+}
+
+// ExecEnv is an environment variable set for a credential plugin
+type ExecEnv struct {
+	Name  string `yaml:"name"`
+	Value string `yaml:"value"`
+}
+
+// AuthProvider is a legacy in-tree authentication provider, which is not supported
+type AuthProvider struct {
+	Name string `yaml:"name"`
+}
+
+// NamedContext is a kubeconfig contexts entry
+type NamedContext struct {
+	Name    string  `yaml:"name"`
+	Context Context `yaml:"context"`
+}
+
+// Context pairs a cluster with a user and a default namespace
+type Context struct {
+	Cluster   string `yaml:"cluster"`
+	User      string `yaml:"user"`
+	Namespace string `yaml:"namespace"`
+}
+
+// LoadConfig reads the kubeconfig at path, or else the files listed in KUBECONFIG or
+// ~/.kube/config. Several files are merged as kubectl does: the first to define a cluster,
+// user or context or the current context wins. Without any kubeconfig, the service account
+// of the pod driftmgr runs in is used as the in-cluster context.
+func LoadConfig(path string) (*Config, error) {
+	var paths []string
+	switch {
+	case path != "":
+		paths = []string{path}
+	case os.Getenv("KUBECONFIG") != "":
+		paths = filepath.SplitList(os.Getenv("KUBECONFIG"))
+	default:
+		if home, err := os.UserHomeDir(); err == nil {
+			paths = []string{filepath.Join(home, ".kube", "config")}
+		}
+	}
+
+	merged := &Config{}
+	loaded := 0
+	for _, file := range paths {
+		data, err := os.ReadFile(file)
+		if os.IsNotExist(err) && path == "" {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read kubeconfig %s: %w", file, err)
+		}
+		var config Config
+		if err := yaml.Unmarshal(data, &config); err != nil {
+			return nil, fmt.Errorf("failed to parse kubeconfig %s: %w", file, err)
+		}
+		config.resolvePaths(filepath.Dir(file))
+		merged.merge(&config)
+		loaded++
+	}
+	if loaded == 0 {
+		if config, ok := inClusterConfig(); ok {
+			return config, nil
+		}
+		return nil, fmt.Errorf("no kubeconfig found: set KUBECONFIG or create ~/.kube/config")
+	}
+	return merged, nil
+}
+
+// inClusterConfig returns the in-cluster context when running in a pod with a service account
+func inClusterConfig() (*Config, bool) {
+	host, port := os.Getenv("KUBERNETES_SERVICE_HOST"), os.Getenv("KUBERNETES_SERVICE_PORT")
+	if host == "" || port == "" {
+		return nil, false
+	}
+	if _, err := os.Stat(filepath.Join(serviceAccountDir, "token")); err != nil {
+		return nil, false
+	}
+	namespace := "default"
+	if data, err := os.ReadFile(filepath.Join(serviceAccountDir, "namespace")); err == nil {
+		namespace = strings.TrimSpace(string(data))
+	}
+	if strings.Contains(host, ":") {
+		host = "[" + host + "]"
+	}
+	return &Config{
+		CurrentContext: InClusterContext,
+		Clusters: []NamedCluster{{Name: InClusterContext, Cluster: Cluster{
+			Server:               "https://" + host + ":" + port,
+			CertificateAuthority: filepath.Join(serviceAccountDir, "ca.crt"),
+		}}},
+		Users: []NamedUser{{Name: InClusterContext, User: User{
+			// The token is rotated, so it is read from the file for each client
+			TokenFile: filepath.Join(serviceAccountDir, "token"),
+		}}},
+		Contexts: []NamedContext{{Name: InClusterContext, Context: Context{
+			Cluster:   InClusterContext,
+			User:      InClusterContext,
+			Namespace: namespace,
+		}}},
+	}, true
+}
+
+// resolvePaths makes the file paths of a kubeconfig relative to its directory, as kubectl does
+func (c *Config) resolvePaths(dir string) {
+	resolve := func(path *string) {
+		if *path != "" && !filepath.IsAbs(*path) {
+			*path = filepath.Join(dir, *path)
+		}
+	}
+	for i := range c.Clusters {
+		resolve(&c.Clusters[i].Cluster.CertificateAuthority)
+	}
+	for i := range c.Users {
+		user := &c.Users[i].User
+		resolve(&user.TokenFile)
+		resolve(&user.ClientCertificate)
+		resolve(&user.ClientKey)
+	}
+}
+
+// merge adds the entries of other that c does not define yet
+func (c *Config) merge(other *Config) {
+	if c.CurrentContext == "" {
+		c.CurrentContext = other.CurrentContext
+	}
+	for _, cluster := range other.Clusters {
+		if _, ok := c.cluster(cluster.Name); !ok {
+			c.Clusters = append(c.Clusters, cluster)
+		}
+	}
+	for _, user := range other.Users {
+		if _, ok := c.user(user.Name); !ok {
+			c.Users = append(c.Users, user)
+		}
+	}
+	for _, context := range other.Contexts {
+		if _, ok := c.context(context.Name); !ok {
+			c.Contexts = append(c.Contexts, context)
+		}
+	}
+}
+
+// ContextNames returns the names of the kubeconfig's contexts, sorted
+func (c *Config) ContextNames() []string {
+	names := make([]string, 0, len(c.Contexts))
+	for _, context := range c.Contexts {
+		names = append(names, context.Name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Resolve returns the cluster, user and namespace of a context; an empty name is the
+// current context
+func (c *Config) Resolve(name string) (Cluster, User, Context, error) {
+	if name == "" {
+		name = c.CurrentContext
+	}
+	if name == "" {
+		return Cluster{}, User{}, Context{}, fmt.Errorf("no context given and the kubeconfig has no current-context")
+	}
+	context, ok := c.context(name)
+	if !ok {
+		return Cluster{}, User{}, Context{}, fmt.Errorf("context %q not found in kubeconfig", name)
+	}
+	cluster, ok := c.cluster(context.Cluster)
+	if !ok {
+		return Cluster{}, User{}, Context{}, fmt.Errorf("cluster %q of context %q not found in kubeconfig", context.Cluster, name)
+	}
+	// A context without a user connects anonymously
+	user, _ := c.user(context.User)
+	return cluster, user, context, nil
+}
+
+func (c *Config) cluster(name string) (Cluster, bool) {
+	for _, cluster := range c.Clusters {
+		if cluster.Name == name {
+			return cluster.Cluster, true
+		}
+	}
+	return Cluster{}, false
+}
+
+func (c *Config) user(name string) (User, bool) {
+	for _, user := range c.Users {
+		if user.Name == name {
+			return user.User, true
+		}
+	}
+	return User{}, false
+}
+
+func (c *Config) context(name string) (Context, bool) {
+	for _, context := range c.Contexts {
+		if context.Name == name {
+			return context.Context, true
+		}
+	}
+	return Context{}, false
+}
@@ -0,0 +1,379 @@
+// Package kubernetes discovers the objects of Kubernetes clusters, such as deployments and
+// config maps, so drift between Terraform's kubernetes provider and a live cluster can be
+// found. Clusters are reached through kubeconfig contexts, which play the part regions do
+// for the clouds.
+package kubernetes
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/catherinevee/driftmgr/pkg/models"
+)
+
+// objectKind is a kind of object discovery lists, by its API path and resource name
+type objectKind struct {
+	resourceType string
+	resource     string // plural name in API paths, such as deployments
+	prefix       string // API group path, such as /apis/apps/v1
+	namespaced   bool
+}
+
+// discoveredKinds are the kinds discovered in each context. Namespaces come first, since
+// namespaced kinds fall back to listing each namespace when RBAC denies a cluster-wide list.
+var discoveredKinds = []objectKind{
+	{"k8s_namespace", "namespaces", "/api/v1", false},
+	{"k8s_deployment", "deployments", "/apis/apps/v1", true},
+	{"k8s_service", "services", "/api/v1", true},
+	{"k8s_configmap", "configmaps", "/api/v1", true},
+}
+
+// lastAppliedAnnotation holds kubectl's copy of the applied object, which would duplicate
+// the object in every resource's attributes
+const lastAppliedAnnotation = "kubectl.kubernetes.io/last-applied-configuration"
+
+// KubernetesProvider discovers the objects of the clusters in a kubeconfig
+type KubernetesProvider struct {
+	kubeconfig string
+
+	mu      sync.Mutex
+	config  *Config
+	clients map[string]*client
+}
+
+// NewKubernetesProvider creates a provider for the kubeconfig at path; an empty path uses
+// KUBECONFIG, ~/.kube/config or the in-cluster service account, as kubectl does
+func NewKubernetesProvider(kubeconfig string) *KubernetesProvider {
+	return &KubernetesProvider{
+		kubeconfig: kubeconfig,
+		clients:    make(map[string]*client),
+	}
+}
+
+// Name returns the provider name
+func (p *KubernetesProvider) Name() string {
+	return "kubernetes"
+}
+
+// Connect loads the kubeconfig and checks the current context's credentials
+func (p *KubernetesProvider) Connect(ctx context.Context) error {
+	return p.ValidateCredentials(ctx)
+}
+
+// ValidateCredentials checks that the current context's API server accepts its credentials
+func (p *KubernetesProvider) ValidateCredentials(ctx context.Context) error {
+	api, _, _, err := p.clientFor("")
+	if err != nil {
+		return err
+	}
+	var versions map[string]interface{}
+	if err := api.get(ctx, "/api", &versions); err != nil {
+		return fmt.Errorf("failed to validate credentials: %w", err)
+	}
+	return nil
+}
+
+// ListRegions returns the kubeconfig's context names, which discovery takes as regions
+func (p *KubernetesProvider) ListRegions(ctx context.Context) ([]string, error) {
+	config, err := p.loadConfig()
+	if err != nil {
+		return nil, err
+	}
+	return config.ContextNames(), nil
+}
+
+// SupportedResourceTypes returns the resource types discovery returns
+func (p *KubernetesProvider) SupportedResourceTypes() []string {
+	types := make([]string, 0, len(discoveredKinds))
+	for _, kind := range discoveredKinds {
+		types = append(types, kind.resourceType)
+	}
+	return types
+}
+
+// DiscoverResources discovers the objects of a context; an empty region is the current
+// context. Kinds RBAC does not permit listing are left out.
+func (p *KubernetesProvider) DiscoverResources(ctx context.Context, region string) ([]models.Resource, error) {
+	result, err := p.DiscoverContext(ctx, region)
+	if err != nil {
+		return nil, err
+	}
+	return result.Resources, nil
+}
+
+// DiscoverContext discovers the namespaces, deployments, services and config maps of a
+// context, an empty name being the current context. Each resource's ID is its object's UID,
+// which the metadata block of Terraform's kubernetes resources also records. The discovery
+// respects RBAC: a kind the credentials cannot list cluster-wide is listed in each visible
+// namespace instead, or in the context's namespace when namespaces cannot be listed, and
+// what still cannot be listed is reported in the result's Errors. An error is returned when
+// the cluster cannot be reached or ctx is done.
+func (p *KubernetesProvider) DiscoverContext(ctx context.Context, contextName string) (*models.DiscoveryResult, error) {
+	api, name, kubeContext, err := p.clientFor(contextName)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &models.DiscoveryResult{Resources: []models.Resource{}, Timestamp: time.Now()}
+	var namespaces []string
+	for _, kind := range discoveredKinds {
+		if !kind.namespaced {
+			objects, err := api.list(ctx, kind.prefix+"/"+kind.resource, nil)
+			if err != nil {
+				if !isAPIError(err) {
+					return nil, err
+				}
+				result.Errors = append(result.Errors, discoveryError(name, kind, err))
+			}
+			for _, object := range objects {
+				namespaces = append(namespaces, object.Metadata.Name)
+				result.Resources = append(result.Resources, toResource(kind, object, name, kubeContext.Cluster))
+			}
+			continue
+		}
+
+		objects, err := listNamespaced(ctx, api, kind, namespaces, kubeContext.Namespace)
+		if err != nil {
+			if !isAPIError(err) {
+				return nil, err
+			}
+			result.Errors = append(result.Errors, discoveryError(name, kind, err))
+		}
+		for _, object := range objects {
+			result.Resources = append(result.Resources, toResource(kind, object, name, kubeContext.Cluster))
+		}
+	}
+	return result, nil
+}
+
+// listNamespaced lists a namespaced kind across the cluster. When RBAC forbids that, each
+// namespace is listed instead, falling back to the context's namespace when no namespaces
+// are known. The objects that could be listed are returned with an error naming the
+// namespaces that could not.
+func listNamespaced(ctx context.Context, api *client, kind objectKind, namespaces []string, contextNamespace string) ([]object, error) {
+	objects, err := api.list(ctx, kind.prefix+"/"+kind.resource, nil)
+	var apiErr *APIError
+	if err == nil || !errors.As(err, &apiErr) || !apiErr.Forbidden() {
+		return objects, err
+	}
+
+	if len(namespaces) == 0 {
+		if contextNamespace == "" {
+			contextNamespace = "default"
+		}
+		namespaces = []string{contextNamespace}
+	}
+	var forbidden []string
+	for _, namespace := range namespaces {
+		listed, err := api.list(ctx, kind.prefix+"/namespaces/"+url.PathEscape(namespace)+"/"+kind.resource, nil)
+		if errors.As(err, &apiErr) && apiErr.Forbidden() {
+			forbidden = append(forbidden, namespace)
+			continue
+		}
+		if err != nil {
+			return objects, err
+		}
+		objects = append(objects, listed...)
+	}
+	if len(forbidden) > 0 {
+		sort.Strings(forbidden)
+		return objects, &APIError{
+			StatusCode: apiErr.StatusCode,
+			Reason:     apiErr.Reason,
+			Message:    fmt.Sprintf("not permitted to list %s in namespaces %s", kind.resource, strings.Join(forbidden, ", ")),
+		}
+	}
+	return objects, nil
+}
+
+// GetResource retrieves an object in the current context by its Terraform ID: the name of a
+// namespace, or namespace/name for other kinds, which are tried in turn
+func (p *KubernetesProvider) GetResource(ctx context.Context, resourceID string) (*models.Resource, error) {
+	api, name, kubeContext, err := p.clientFor("")
+	if err != nil {
+		return nil, err
+	}
+
+	namespace, objectName, namespaced := strings.Cut(resourceID, "/")
+	var lastErr error
+	for _, kind := range discoveredKinds {
+		if kind.namespaced != namespaced {
+			continue
+		}
+		path := kind.prefix + "/" + kind.resource + "/" + url.PathEscape(namespace)
+		if namespaced {
+			path = kind.prefix + "/namespaces/" + url.PathEscape(namespace) + "/" + kind.resource + "/" + url.PathEscape(objectName)
+		}
+		var object object
+		if err := api.get(ctx, path, &object); err != nil {
+			var apiErr *APIError
+			if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound {
+				lastErr = err
+				continue
+			}
+			return nil, err
+		}
+		resource := toResource(kind, object, name, kubeContext.Cluster)
+		return &resource, nil
+	}
+	return nil, fmt.Errorf("kubernetes object %s not found: %w", resourceID, lastErr)
+}
+
+// loadConfig loads the kubeconfig once
+func (p *KubernetesProvider) loadConfig() (*Config, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.config == nil {
+		config, err := LoadConfig(p.kubeconfig)
+		if err != nil {
+			return nil, err
+		}
+		p.config = config
+	}
+	return p.config, nil
+}
+
+// clientFor returns the client of a context, an empty name being the current context, along
+// with the context's name and settings
+func (p *KubernetesProvider) clientFor(contextName string) (*client, string, Context, error) {
+	config, err := p.loadConfig()
+	if err != nil {
+		return nil, "", Context{}, err
+	}
+	if contextName == "" {
+		contextName = config.CurrentContext
+	}
+	cluster, user, kubeContext, err := config.Resolve(contextName)
+	if err != nil {
+		return nil, "", Context{}, err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if api, ok := p.clients[contextName]; ok {
+		return api, contextName, kubeContext, nil
+	}
+	api, err := newClient(cluster, user)
+	if err != nil {
+		return nil, "", Context{}, fmt.Errorf("context %s: %w", contextName, err)
+	}
+	p.clients[contextName] = api
+	return api, contextName, kubeContext, nil
+}
+
+// isAPIError reports whether err is an error status from the API server, as opposed to the
+// cluster being unreachable
+func isAPIError(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr)
+}
+
+// discoveryError records a kind that could not be listed in a context
+func discoveryError(contextName string, kind objectKind, err error) models.DiscoveryError {
+	failure := models.DiscoveryError{
+		Service:      kind.resource,
+		ResourceType: kind.resourceType,
+		Region:       contextName,
+		Error:        err.Error(),
+		Timestamp:    time.Now(),
+	}
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		failure.Code = apiErr.Reason
+	}
+	return failure
+}
+
+// toResource converts an object of a context to a resource. The context is its region and
+// the context's cluster its account, and its labels are its tags.
+func toResource(kind objectKind, object object, contextName, clusterName string) models.Resource {
+	meta := object.Metadata
+	annotations := make(map[string]string, len(meta.Annotations))
+	for key, value := range meta.Annotations {
+		if key != lastAppliedAnnotation {
+			annotations[key] = value
+		}
+	}
+	attributes := map[string]interface{}{
+		"name":             meta.Name,
+		"uid":              meta.UID,
+		"kind":             object.Kind,
+		"api_version":      object.APIVersion,
+		"resource_version": meta.ResourceVersion,
+		"labels":           meta.Labels,
+		"annotations":      annotations,
+		"context":          contextName,
+		"cluster":          clusterName,
+	}
+	terraformID := meta.Name
+	if kind.namespaced {
+		attributes["namespace"] = meta.Namespace
+		terraformID = meta.Namespace + "/" + meta.Name
+	}
+
+	status := "active"
+	switch kind.resourceType {
+	case "k8s_namespace":
+		if phase, _ := object.Status["phase"].(string); phase != "" {
+			attributes["phase"] = phase
+			status = strings.ToLower(phase)
+		}
+	case "k8s_deployment":
+		attributes["replicas"] = object.Spec["replicas"]
+		attributes["ready_replicas"] = object.Status["readyReplicas"]
+		attributes["strategy"] = object.Spec["strategy"]
+		attributes["images"] = containerImages(object.Spec)
+	case "k8s_service":
+		attributes["type"] = object.Spec["type"]
+		attributes["cluster_ip"] = object.Spec["clusterIP"]
+		attributes["ports"] = object.Spec["ports"]
+		attributes["selector"] = object.Spec["selector"]
+	case "k8s_configmap":
+		attributes["data"] = object.Data
+	}
+
+	id := meta.UID
+	if id == "" {
+		id = contextName + "/" + kind.resource + "/" + terraformID
+	}
+	return models.Resource{
+		ID:         id,
+		Name:       meta.Name,
+		Type:       kind.resourceType,
+		Provider:   "kubernetes",
+		Region:     contextName,
+		AccountID:  clusterName,
+		Tags:       meta.Labels,
+		Status:     status,
+		Created:    meta.CreationTimestamp,
+		CreatedAt:  meta.CreationTimestamp,
+		Attributes: attributes,
+		Metadata: map[string]string{
+			"namespace":    meta.Namespace,
+			"terraform_id": terraformID,
+		},
+	}
+}
+
+// containerImages returns the images of a pod template's containers, in order
+func containerImages(spec map[string]interface{}) []string {
+	template, _ := spec["template"].(map[string]interface{})
+	podSpec, _ := template["spec"].(map[string]interface{})
+	containers, _ := podSpec["containers"].([]interface{})
+	images := make([]string, 0, len(containers))
+	for _, container := range containers {
+		if fields, ok := container.(map[string]interface{}); ok {
+			if image, ok := fields["image"].(string); ok {
+				images = append(images, image)
+			}
+		}
+	}
+	return images
+}
@@ -0,0 +1,191 @@
+package kubernetes
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeKubeconfig writes a kubeconfig with a context for each server and returns its path
+func writeKubeconfig(t *testing.T, dir string, current string, servers map[string]string) string {
+	t.Helper()
+	var out strings.Builder
+	fmt.Fprintf(&out, "apiVersion: v1\nkind: Config\ncurrent-context: %s\nclusters:\n", current)
+	for name, server := range servers {
+		fmt.Fprintf(&out, "- name: %s-cluster\n  cluster:\n    server: %s\n", name, server)
+	}
+	out.WriteString("users:\n- name: reader\n  user:\n    token: secret-token\ncontexts:\n")
+	for name := range servers {
+		fmt.Fprintf(&out, "- name: %s\n  context:\n    cluster: %s-cluster\n    user: reader\n    namespace: apps\n", name, name)
+	}
+	path := filepath.Join(dir, "config")
+	require.NoError(t, os.WriteFile(path, []byte(out.String()), 0600))
+	return path
+}
+
+// fakeAPIServer serves object lists and single objects by path, forbidding the paths in
+// forbidden
+func fakeAPIServer(t *testing.T, lists map[string][]object, objects map[string]object, forbidden map[string]bool) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if forbidden[r.URL.Path] {
+			w.WriteHeader(http.StatusForbidden)
+			json.NewEncoder(w).Encode(map[string]string{"reason": "Forbidden", "message": "forbidden: " + r.URL.Path})
+			return
+		}
+		if object, ok := objects[r.URL.Path]; ok {
+			json.NewEncoder(w).Encode(object)
+			return
+		}
+		items, ok := lists[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(map[string]string{"reason": "NotFound"})
+			return
+		}
+		// Serve one object per page to exercise continue tokens
+		page := objectList{}
+		start := 0
+		fmt.Sscan(r.URL.Query().Get("continue"), &start)
+		if start < len(items) {
+			page.Items = items[start : start+1]
+			if start+1 < len(items) {
+				page.Metadata.Continue = fmt.Sprint(start + 1)
+			}
+		}
+		json.NewEncoder(w).Encode(page)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func namespaced(kind, namespace, name, uid string) object {
+	return object{Kind: kind, Metadata: objectMeta{Name: name, Namespace: namespace, UID: uid, Labels: map[string]string{"app": name}}}
+}
+
+func TestDiscoverContext(t *testing.T) {
+	deployment := namespaced("Deployment", "apps", "web", "uid-web")
+	deployment.Spec = map[string]interface{}{
+		"replicas": 3.0,
+		"template": map[string]interface{}{"spec": map[string]interface{}{
+			"containers": []interface{}{map[string]interface{}{"image": "nginx:1.27"}},
+		}},
+	}
+	server := fakeAPIServer(t, map[string][]object{
+		"/api/v1/namespaces": {
+			{Kind: "Namespace", Metadata: objectMeta{Name: "apps", UID: "uid-apps"}, Status: map[string]interface{}{"phase": "Active"}},
+			{Kind: "Namespace", Metadata: objectMeta{Name: "kube-system", UID: "uid-system"}},
+		},
+		"/apis/apps/v1/deployments":        {deployment},
+		"/api/v1/namespaces/apps/services": {namespaced("Service", "apps", "web", "uid-svc")},
+		"/api/v1/configmaps":               {namespaced("ConfigMap", "apps", "settings", "uid-cm")},
+	}, map[string]object{
+		"/api/v1/namespaces/apps/services/web": namespaced("Service", "apps", "web", "uid-svc"),
+	}, map[string]bool{
+		// RBAC allows services only in the apps namespace
+		"/api/v1/services":                        true,
+		"/api/v1/namespaces/kube-system/services": true,
+	})
+	provider := NewKubernetesProvider(writeKubeconfig(t, t.TempDir(), "prod", map[string]string{"prod": server.URL}))
+
+	result, err := provider.DiscoverContext(context.Background(), "")
+	require.NoError(t, err)
+
+	byID := make(map[string]string)
+	for _, resource := range result.Resources {
+		byID[resource.ID] = resource.Type
+		assert.Equal(t, "kubernetes", resource.Provider)
+		assert.Equal(t, "prod", resource.Region)
+		assert.Equal(t, "prod-cluster", resource.AccountID)
+	}
+	assert.Equal(t, map[string]string{
+		"uid-apps":   "k8s_namespace",
+		"uid-system": "k8s_namespace",
+		"uid-web":    "k8s_deployment",
+		"uid-svc":    "k8s_service",
+		"uid-cm":     "k8s_configmap",
+	}, byID)
+
+	require.Len(t, result.Errors, 1)
+	assert.Equal(t, "k8s_service", result.Errors[0].ResourceType)
+	assert.Equal(t, "Forbidden", result.Errors[0].Code)
+	assert.Contains(t, result.Errors[0].Error, "namespaces kube-system")
+
+	for _, resource := range result.Resources {
+		if resource.ID == "uid-web" {
+			assert.Equal(t, "apps/web", resource.Metadata["terraform_id"])
+			assert.Equal(t, []string{"nginx:1.27"}, resource.Attributes["images"])
+			assert.Equal(t, map[string]string{"app": "web"}, resource.Tags)
+		}
+	}
+
+	// Kinds without the object are skipped until one has it
+	found, err := provider.GetResource(context.Background(), "apps/web")
+	require.NoError(t, err)
+	assert.Equal(t, "uid-svc", found.ID)
+	assert.Equal(t, "k8s_service", found.Type)
+
+	_, err = provider.GetResource(context.Background(), "apps/missing")
+	assert.Error(t, err)
+}
+
+func TestDiscoverContextWithoutNamespaceAccess(t *testing.T) {
+	server := fakeAPIServer(t, map[string][]object{
+		"/apis/apps/v1/namespaces/apps/deployments": {namespaced("Deployment", "apps", "web", "uid-web")},
+		"/api/v1/namespaces/apps/services":          {},
+		"/api/v1/namespaces/apps/configmaps":        {},
+	}, nil, map[string]bool{
+		"/api/v1/namespaces":        true,
+		"/apis/apps/v1/deployments": true,
+		"/api/v1/services":          true,
+		"/api/v1/configmaps":        true,
+	})
+	provider := NewKubernetesProvider(writeKubeconfig(t, t.TempDir(), "dev", map[string]string{"dev": server.URL}))
+
+	// Namespaced kinds are listed in the context's namespace
+	result, err := provider.DiscoverContext(context.Background(), "dev")
+	require.NoError(t, err)
+	require.Len(t, result.Resources, 1)
+	assert.Equal(t, "uid-web", result.Resources[0].ID)
+	require.Len(t, result.Errors, 1)
+	assert.Equal(t, "k8s_namespace", result.Errors[0].ResourceType)
+
+	_, err = provider.DiscoverContext(context.Background(), "missing")
+	assert.ErrorContains(t, err, `context "missing" not found`)
+}
+
+func TestLoadConfigMergesFiles(t *testing.T) {
+	dir := t.TempDir()
+	first := writeKubeconfig(t, dir, "prod", map[string]string{"prod": "https://prod.example.com"})
+	secondDir := filepath.Join(dir, "second")
+	require.NoError(t, os.Mkdir(secondDir, 0700))
+	second := writeKubeconfig(t, secondDir, "dev", map[string]string{"dev": "https://dev.example.com", "prod": "https://other.example.com"})
+	t.Setenv("KUBECONFIG", first+string(os.PathListSeparator)+second)
+
+	config, err := LoadConfig("")
+	require.NoError(t, err)
+	assert.Equal(t, "prod", config.CurrentContext, "the first file's current context wins")
+	assert.Equal(t, []string{"dev", "prod"}, config.ContextNames())
+
+	cluster, user, kubeContext, err := config.Resolve("prod")
+	require.NoError(t, err)
+	assert.Equal(t, "https://prod.example.com", cluster.Server, "the first file's entries win")
+	assert.Equal(t, "secret-token", user.Token)
+	assert.Equal(t, "apps", kubeContext.Namespace)
+
+	regions, err := NewKubernetesProvider("").ListRegions(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"dev", "prod"}, regions)
+}
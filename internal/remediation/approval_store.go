@@ -0,0 +1,210 @@
+package remediation
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrApprovalNotFound is returned for an unknown approval ID
+var ErrApprovalNotFound = errors.New("approval not found")
+
+// ErrApprovalNotPending is returned when deciding an approval that was already decided
+var ErrApprovalNotPending = errors.New("approval is not pending")
+
+// ApprovalStatus is the state of a remediation approval
+type ApprovalStatus string
+
+const (
+	ApprovalPending  ApprovalStatus = "pending"
+	ApprovalApproved ApprovalStatus = "approved"
+	ApprovalRejected ApprovalStatus = "rejected"
+)
+
+// approvalSchema creates the remediation approvals table
+const approvalSchema = `
+CREATE TABLE IF NOT EXISTS remediation_approvals (
+	id           TEXT PRIMARY KEY,
+	drift_ids    TEXT NOT NULL,
+	work_dir     TEXT NOT NULL DEFAULT '',
+	status       TEXT NOT NULL,
+	requested_by TEXT NOT NULL DEFAULT '',
+	requested_at TIMESTAMP NOT NULL,
+	decided_by   TEXT NOT NULL DEFAULT '',
+	decided_at   TIMESTAMP,
+	reason       TEXT NOT NULL DEFAULT '',
+	snapshot_id  TEXT NOT NULL DEFAULT '',
+	force        BOOLEAN NOT NULL DEFAULT 0
+);
+CREATE INDEX IF NOT EXISTS idx_remediation_approvals_status ON remediation_approvals (status);`
+
+// Approval is a remediation, or a rollback to a snapshot when SnapshotID is set, held until
+// an approver allows it to run
+type Approval struct {
+	ID          string         `json:"id"`
+	DriftIDs    []string       `json:"drift_ids"`
+	SnapshotID  string         `json:"snapshot_id,omitempty"`
+	WorkDir     string         `json:"work_dir,omitempty"`
+	Force       bool           `json:"force,omitempty"` // allow destroying stateful resources
+	Status      ApprovalStatus `json:"status"`
+	RequestedBy string         `json:"requested_by,omitempty"`
+	RequestedAt time.Time      `json:"requested_at"`
+	DecidedBy   string         `json:"decided_by,omitempty"`
+	DecidedAt   *time.Time     `json:"decided_at,omitempty"`
+	Reason      string         `json:"reason,omitempty"`
+}
+
+// ApprovalStore persists remediation approvals in a SQL database
+type ApprovalStore struct {
+	db *sql.DB
+}
+
+// NewApprovalStore creates an approval store on an open database, creating its table
+func NewApprovalStore(db *sql.DB) (*ApprovalStore, error) {
+	if _, err := db.Exec(approvalSchema); err != nil {
+		return nil, fmt.Errorf("failed to create remediation approvals table: %w", err)
+	}
+	// Columns added after the table was first released
+	if err := addApprovalColumn(db, "snapshot_id", "TEXT NOT NULL DEFAULT ''"); err != nil {
+		return nil, err
+	}
+	if err := addApprovalColumn(db, "force", "BOOLEAN NOT NULL DEFAULT 0"); err != nil {
+		return nil, err
+	}
+	return &ApprovalStore{db: db}, nil
+}
+
+// addApprovalColumn adds a column to approvals tables created before it existed, such as
+// snapshot_id from before rollbacks could be held for approval
+func addApprovalColumn(db *sql.DB, name, definition string) error {
+	var count int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM pragma_table_info('remediation_approvals') WHERE name = ?`, name).Scan(&count); err != nil {
+		return fmt.Errorf("failed to inspect remediation approvals table: %w", err)
+	}
+	if count > 0 {
+		return nil
+	}
+	if _, err := db.Exec(fmt.Sprintf(`ALTER TABLE remediation_approvals ADD COLUMN %s %s`, name, definition)); err != nil {
+		return fmt.Errorf("failed to add %s to remediation approvals: %w", name, err)
+	}
+	return nil
+}
+
+// Create records a pending approval for remediating the given drifts; force allows the
+// remediation to destroy stateful resources once approved
+func (s *ApprovalStore) Create(ctx context.Context, driftIDs []string, workDir string, force bool, requestedBy string) (*Approval, error) {
+	return s.create(ctx, &Approval{DriftIDs: driftIDs, WorkDir: workDir, Force: force, RequestedBy: requestedBy})
+}
+
+// CreateRollback records a pending approval for rolling resources back to a snapshot
+func (s *ApprovalStore) CreateRollback(ctx context.Context, snapshotID, requestedBy string) (*Approval, error) {
+	return s.create(ctx, &Approval{DriftIDs: []string{}, SnapshotID: snapshotID, RequestedBy: requestedBy})
+}
+
+// create stores a new pending approval
+func (s *ApprovalStore) create(ctx context.Context, approval *Approval) (*Approval, error) {
+	approval.ID = fmt.Sprintf("approval-%s", uuid.New().String())
+	approval.Status = ApprovalPending
+	approval.RequestedAt = time.Now().UTC()
+	ids, err := json.Marshal(approval.DriftIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal drift IDs: %w", err)
+	}
+
+	_, err = s.db.ExecContext(ctx, `
+		INSERT INTO remediation_approvals (id, drift_ids, work_dir, status, requested_by, requested_at, snapshot_id, force)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		approval.ID, string(ids), approval.WorkDir, string(approval.Status), approval.RequestedBy, approval.RequestedAt,
+		approval.SnapshotID, approval.Force)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create approval: %w", err)
+	}
+	return approval, nil
+}
+
+// Get returns an approval by ID
+func (s *ApprovalStore) Get(ctx context.Context, id string) (*Approval, error) {
+	row := s.db.QueryRowContext(ctx, `
+		SELECT id, drift_ids, work_dir, status, requested_by, requested_at, decided_by, decided_at, reason, snapshot_id, force
+		FROM remediation_approvals WHERE id = ?`, id)
+	approval, err := scanApproval(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrApprovalNotFound
+	}
+	return approval, err
+}
+
+// List returns approvals with the given status, or all approvals when status is empty,
+// oldest request first
+func (s *ApprovalStore) List(ctx context.Context, status ApprovalStatus) ([]*Approval, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, drift_ids, work_dir, status, requested_by, requested_at, decided_by, decided_at, reason, snapshot_id, force
+		FROM remediation_approvals WHERE (? = '' OR status = ?) ORDER BY requested_at ASC`,
+		string(status), string(status))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list approvals: %w", err)
+	}
+	defer rows.Close()
+
+	approvals := []*Approval{}
+	for rows.Next() {
+		approval, err := scanApproval(rows)
+		if err != nil {
+			return nil, err
+		}
+		approvals = append(approvals, approval)
+	}
+	return approvals, rows.Err()
+}
+
+// Decide approves or rejects a pending approval. Only one decision is accepted; deciding an
+// approval twice returns ErrApprovalNotPending.
+func (s *ApprovalStore) Decide(ctx context.Context, id string, status ApprovalStatus, decidedBy, reason string) (*Approval, error) {
+	if status != ApprovalApproved && status != ApprovalRejected {
+		return nil, fmt.Errorf("invalid approval decision %q", status)
+	}
+
+	result, err := s.db.ExecContext(ctx, `
+		UPDATE remediation_approvals SET status = ?, decided_by = ?, decided_at = ?, reason = ?
+		WHERE id = ? AND status = ?`,
+		string(status), decidedBy, time.Now().UTC(), reason, id, string(ApprovalPending))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decide approval: %w", err)
+	}
+	if updated, err := result.RowsAffected(); err != nil {
+		return nil, fmt.Errorf("failed to decide approval: %w", err)
+	} else if updated == 0 {
+		if _, err := s.Get(ctx, id); err != nil {
+			return nil, err
+		}
+		return nil, ErrApprovalNotPending
+	}
+	return s.Get(ctx, id)
+}
+
+// scanApproval reads an approval from a row
+func scanApproval(row interface{ Scan(...interface{}) error }) (*Approval, error) {
+	var approval Approval
+	var ids, status string
+	var decidedAt sql.NullTime
+	if err := row.Scan(&approval.ID, &ids, &approval.WorkDir, &status, &approval.RequestedBy,
+		&approval.RequestedAt, &approval.DecidedBy, &decidedAt, &approval.Reason, &approval.SnapshotID, &approval.Force); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to read approval: %w", err)
+	}
+	if err := json.Unmarshal([]byte(ids), &approval.DriftIDs); err != nil {
+		return nil, fmt.Errorf("failed to parse drift IDs of %s: %w", approval.ID, err)
+	}
+	approval.Status = ApprovalStatus(status)
+	if decidedAt.Valid {
+		approval.DecidedAt = &decidedAt.Time
+	}
+	return &approval, nil
+}
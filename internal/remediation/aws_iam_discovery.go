@@ -0,0 +1,322 @@
+package remediation
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/url"
+	"sort"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+	iamtypes "github.com/aws/aws-sdk-go-v2/service/iam/types"
+	"github.com/catherinevee/driftmgr/pkg/models"
+)
+
+// discoverIAMResources discovers IAM users, groups, roles and customer managed policies.
+// Each principal's attached managed policies, group memberships and inline policy hashes are
+// recorded in Properties, along with a hash of each policy document, so a changed trust policy
+// or a newly attached permission shows up as drift. AWS managed policies are not listed as
+// resources; attachments to them are still recorded by ARN. A listing that fails, typically
+// for lack of permission, is skipped so the other principals are still discovered.
+func (ap *AWSProvider) discoverIAMResources(ctx context.Context, accountID string) ([]models.Resource, error) {
+	var resources []models.Resource
+
+	client := iam.NewFromConfig(ap.cfg)
+
+	users := iam.NewListUsersPaginator(client, &iam.ListUsersInput{})
+	for users.HasMorePages() {
+		page, err := users.NextPage(ctx)
+		if err != nil {
+			break
+		}
+		for _, user := range page.Users {
+			resources = append(resources, ap.describeIAMUser(ctx, client, user))
+		}
+	}
+
+	groups := iam.NewListGroupsPaginator(client, &iam.ListGroupsInput{})
+	for groups.HasMorePages() {
+		page, err := groups.NextPage(ctx)
+		if err != nil {
+			break
+		}
+		for _, group := range page.Groups {
+			resources = append(resources, ap.describeIAMGroup(ctx, client, group))
+		}
+	}
+
+	roles := iam.NewListRolesPaginator(client, &iam.ListRolesInput{})
+	for roles.HasMorePages() {
+		page, err := roles.NextPage(ctx)
+		if err != nil {
+			break
+		}
+		for _, role := range page.Roles {
+			resources = append(resources, ap.describeIAMRole(ctx, client, role))
+		}
+	}
+
+	policies := iam.NewListPoliciesPaginator(client, &iam.ListPoliciesInput{Scope: iamtypes.PolicyScopeTypeLocal})
+	for policies.HasMorePages() {
+		page, err := policies.NextPage(ctx)
+		if err != nil {
+			break
+		}
+		for _, policy := range page.Policies {
+			resources = append(resources, ap.describeIAMPolicy(ctx, client, policy))
+		}
+	}
+
+	return resources, nil
+}
+
+// describeIAMUser builds a user resource with its tags, groups and policies
+func (ap *AWSProvider) describeIAMUser(ctx context.Context, client *iam.Client, user iamtypes.User) models.Resource {
+	name := aws.ToString(user.UserName)
+	properties := map[string]interface{}{
+		"arn":  aws.ToString(user.Arn),
+		"path": aws.ToString(user.Path),
+	}
+
+	tags := make(map[string]string)
+	userTags := iam.NewListUserTagsPaginator(client, &iam.ListUserTagsInput{UserName: user.UserName})
+	for userTags.HasMorePages() {
+		page, err := userTags.NextPage(ctx)
+		if err != nil {
+			break
+		}
+		for _, tag := range page.Tags {
+			tags[aws.ToString(tag.Key)] = aws.ToString(tag.Value)
+		}
+	}
+
+	var memberOf []string
+	userGroups := iam.NewListGroupsForUserPaginator(client, &iam.ListGroupsForUserInput{UserName: user.UserName})
+	for userGroups.HasMorePages() {
+		page, err := userGroups.NextPage(ctx)
+		if err != nil {
+			break
+		}
+		for _, group := range page.Groups {
+			memberOf = append(memberOf, aws.ToString(group.GroupName))
+		}
+	}
+	sort.Strings(memberOf)
+	properties["groups"] = memberOf
+
+	var attached []string
+	attachments := iam.NewListAttachedUserPoliciesPaginator(client, &iam.ListAttachedUserPoliciesInput{UserName: user.UserName})
+	for attachments.HasMorePages() {
+		page, err := attachments.NextPage(ctx)
+		if err != nil {
+			break
+		}
+		attached = append(attached, attachedPolicyARNs(page.AttachedPolicies)...)
+	}
+	sort.Strings(attached)
+	properties["attached_policies"] = attached
+
+	inline := make(map[string]string)
+	inlineNames := iam.NewListUserPoliciesPaginator(client, &iam.ListUserPoliciesInput{UserName: user.UserName})
+	for inlineNames.HasMorePages() {
+		page, err := inlineNames.NextPage(ctx)
+		if err != nil {
+			break
+		}
+		for _, policyName := range page.PolicyNames {
+			policy, err := client.GetUserPolicy(ctx, &iam.GetUserPolicyInput{UserName: user.UserName, PolicyName: aws.String(policyName)})
+			if err == nil {
+				inline[policyName] = policyDocumentHash(aws.ToString(policy.PolicyDocument))
+			}
+		}
+	}
+	properties["inline_policies"] = inline
+
+	return models.Resource{
+		ID:         name,
+		Name:       name,
+		Type:       "iam_user",
+		Provider:   "aws",
+		Region:     "global",
+		State:      "active",
+		Tags:       tags,
+		Properties: properties,
+		Created:    aws.ToTime(user.CreateDate),
+	}
+}
+
+// describeIAMGroup builds a group resource with its policies
+func (ap *AWSProvider) describeIAMGroup(ctx context.Context, client *iam.Client, group iamtypes.Group) models.Resource {
+	name := aws.ToString(group.GroupName)
+	properties := map[string]interface{}{
+		"arn":  aws.ToString(group.Arn),
+		"path": aws.ToString(group.Path),
+	}
+
+	var attached []string
+	attachments := iam.NewListAttachedGroupPoliciesPaginator(client, &iam.ListAttachedGroupPoliciesInput{GroupName: group.GroupName})
+	for attachments.HasMorePages() {
+		page, err := attachments.NextPage(ctx)
+		if err != nil {
+			break
+		}
+		attached = append(attached, attachedPolicyARNs(page.AttachedPolicies)...)
+	}
+	sort.Strings(attached)
+	properties["attached_policies"] = attached
+
+	inline := make(map[string]string)
+	inlineNames := iam.NewListGroupPoliciesPaginator(client, &iam.ListGroupPoliciesInput{GroupName: group.GroupName})
+	for inlineNames.HasMorePages() {
+		page, err := inlineNames.NextPage(ctx)
+		if err != nil {
+			break
+		}
+		for _, policyName := range page.PolicyNames {
+			policy, err := client.GetGroupPolicy(ctx, &iam.GetGroupPolicyInput{GroupName: group.GroupName, PolicyName: aws.String(policyName)})
+			if err == nil {
+				inline[policyName] = policyDocumentHash(aws.ToString(policy.PolicyDocument))
+			}
+		}
+	}
+	properties["inline_policies"] = inline
+
+	return models.Resource{
+		ID:         name,
+		Name:       name,
+		Type:       "iam_group",
+		Provider:   "aws",
+		Region:     "global",
+		State:      "active",
+		Properties: properties,
+		Created:    aws.ToTime(group.CreateDate),
+	}
+}
+
+// describeIAMRole builds a role resource with its tags, trust policy hash and policies
+func (ap *AWSProvider) describeIAMRole(ctx context.Context, client *iam.Client, role iamtypes.Role) models.Resource {
+	name := aws.ToString(role.RoleName)
+	properties := map[string]interface{}{
+		"arn":                     aws.ToString(role.Arn),
+		"path":                    aws.ToString(role.Path),
+		"assume_role_policy_hash": policyDocumentHash(aws.ToString(role.AssumeRolePolicyDocument)),
+	}
+
+	tags := make(map[string]string)
+	roleTags := iam.NewListRoleTagsPaginator(client, &iam.ListRoleTagsInput{RoleName: role.RoleName})
+	for roleTags.HasMorePages() {
+		page, err := roleTags.NextPage(ctx)
+		if err != nil {
+			break
+		}
+		for _, tag := range page.Tags {
+			tags[aws.ToString(tag.Key)] = aws.ToString(tag.Value)
+		}
+	}
+
+	var attached []string
+	attachments := iam.NewListAttachedRolePoliciesPaginator(client, &iam.ListAttachedRolePoliciesInput{RoleName: role.RoleName})
+	for attachments.HasMorePages() {
+		page, err := attachments.NextPage(ctx)
+		if err != nil {
+			break
+		}
+		attached = append(attached, attachedPolicyARNs(page.AttachedPolicies)...)
+	}
+	sort.Strings(attached)
+	properties["attached_policies"] = attached
+
+	inline := make(map[string]string)
+	inlineNames := iam.NewListRolePoliciesPaginator(client, &iam.ListRolePoliciesInput{RoleName: role.RoleName})
+	for inlineNames.HasMorePages() {
+		page, err := inlineNames.NextPage(ctx)
+		if err != nil {
+			break
+		}
+		for _, policyName := range page.PolicyNames {
+			policy, err := client.GetRolePolicy(ctx, &iam.GetRolePolicyInput{RoleName: role.RoleName, PolicyName: aws.String(policyName)})
+			if err == nil {
+				inline[policyName] = policyDocumentHash(aws.ToString(policy.PolicyDocument))
+			}
+		}
+	}
+	properties["inline_policies"] = inline
+
+	return models.Resource{
+		ID:         name,
+		Name:       name,
+		Type:       "iam_role",
+		Provider:   "aws",
+		Region:     "global",
+		State:      "active",
+		Tags:       tags,
+		Properties: properties,
+		Created:    aws.ToTime(role.CreateDate),
+	}
+}
+
+// describeIAMPolicy builds a managed policy resource with a hash of its default version
+func (ap *AWSProvider) describeIAMPolicy(ctx context.Context, client *iam.Client, policy iamtypes.Policy) models.Resource {
+	properties := map[string]interface{}{
+		"arn":              aws.ToString(policy.Arn),
+		"path":             aws.ToString(policy.Path),
+		"default_version":  aws.ToString(policy.DefaultVersionId),
+		"attachment_count": aws.ToInt32(policy.AttachmentCount),
+	}
+
+	version, err := client.GetPolicyVersion(ctx, &iam.GetPolicyVersionInput{
+		PolicyArn: policy.Arn,
+		VersionId: policy.DefaultVersionId,
+	})
+	if err == nil && version.PolicyVersion != nil {
+		properties["policy_document_hash"] = policyDocumentHash(aws.ToString(version.PolicyVersion.Document))
+	}
+
+	tags := make(map[string]string)
+	for _, tag := range policy.Tags {
+		tags[aws.ToString(tag.Key)] = aws.ToString(tag.Value)
+	}
+
+	return models.Resource{
+		ID:         aws.ToString(policy.Arn),
+		Name:       aws.ToString(policy.PolicyName),
+		Type:       "iam_policy",
+		Provider:   "aws",
+		Region:     "global",
+		State:      "active",
+		Tags:       tags,
+		Properties: properties,
+		Created:    aws.ToTime(policy.CreateDate),
+	}
+}
+
+// attachedPolicyARNs returns the ARNs of attached managed policies
+func attachedPolicyARNs(policies []iamtypes.AttachedPolicy) []string {
+	arns := make([]string, 0, len(policies))
+	for _, policy := range policies {
+		arns = append(arns, aws.ToString(policy.PolicyArn))
+	}
+	return arns
+}
+
+// policyDocumentHash returns a SHA-256 hash of an IAM policy document. IAM returns documents
+// URL-encoded; they are decoded and re-marshalled so whitespace and key order do not change
+// the hash. Documents that are not valid JSON are hashed as returned.
+func policyDocumentHash(document string) string {
+	if document == "" {
+		return ""
+	}
+	if decoded, err := url.QueryUnescape(document); err == nil {
+		document = decoded
+	}
+	var parsed interface{}
+	if err := json.Unmarshal([]byte(document), &parsed); err == nil {
+		if canonical, err := json.Marshal(parsed); err == nil {
+			document = string(canonical)
+		}
+	}
+	sum := sha256.Sum256([]byte(document))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,25 @@
+package remediation
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPolicyDocumentHash(t *testing.T) {
+	compact := `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Action":"s3:GetObject","Resource":"*"}]}`
+	reordered := `{
+  "Statement": [{"Resource": "*", "Action": "s3:GetObject", "Effect": "Allow"}],
+  "Version": "2012-10-17"
+}`
+
+	hash := policyDocumentHash(compact)
+	assert.Len(t, hash, 64)
+	assert.Equal(t, hash, policyDocumentHash(reordered), "formatting and key order do not change the hash")
+	assert.Equal(t, hash, policyDocumentHash(url.QueryEscape(compact)), "IAM's URL-encoded documents are decoded")
+
+	widened := `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Action":"*","Resource":"*"}]}`
+	assert.NotEqual(t, hash, policyDocumentHash(widened))
+	assert.Empty(t, policyDocumentHash(""))
+}
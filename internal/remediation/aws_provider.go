@@ -52,6 +52,7 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/wafv2"
 	"github.com/aws/aws-sdk-go-v2/service/wafv2/types"
 	// "github.com/aws/smithy-go"
+	awsprovider "github.com/catherinevee/driftmgr/internal/providers/aws"
 	"github.com/catherinevee/driftmgr/pkg/models"
 )
 
@@ -504,14 +505,17 @@ func (ap *AWSProvider) discoverS3Resources(ctx context.Context, accountID string
 			// If we can't get location, default to us-east-1
 			location = "us-east-1"
 		}
+		tags, attributes := awsprovider.DescribeS3Bucket(ctx, client, *bucket.Name, location)
 
 		resources = append(resources, models.Resource{
-			ID:       *bucket.Name,
-			Name:     *bucket.Name,
-			Type:     "s3_bucket",
-			Provider: "aws",
-			Region:   location,
-			Created:  *bucket.CreationDate,
+			ID:         *bucket.Name,
+			Name:       *bucket.Name,
+			Type:       "s3_bucket",
+			Provider:   "aws",
+			Region:     location,
+			Tags:       tags,
+			Attributes: attributes,
+			Created:    *bucket.CreationDate,
 		})
 	}
 
@@ -779,62 +783,6 @@ func (ap *AWSProvider) discoverSQSResources(ctx context.Context, accountID strin
 	return resources, nil
 }
 
-func (ap *AWSProvider) discoverIAMResources(ctx context.Context, accountID string) ([]models.Resource, error) {
-	var resources []models.Resource
-
-	client := iam.NewFromConfig(ap.cfg)
-
-	// Discover IAM Users
-	users, err := client.ListUsers(ctx, &iam.ListUsersInput{})
-	if err == nil {
-		for _, user := range users.Users {
-			resources = append(resources, models.Resource{
-				ID:       *user.UserName,
-				Name:     *user.UserName,
-				Type:     "iam_user",
-				Provider: "aws",
-				Region:   "global",
-				State:    "active",
-				Created:  *user.CreateDate,
-			})
-		}
-	}
-
-	// Discover IAM Roles
-	roles, err := client.ListRoles(ctx, &iam.ListRolesInput{})
-	if err == nil {
-		for _, role := range roles.Roles {
-			resources = append(resources, models.Resource{
-				ID:       *role.RoleName,
-				Name:     *role.RoleName,
-				Type:     "iam_role",
-				Provider: "aws",
-				Region:   "global",
-				State:    "active",
-				Created:  *role.CreateDate,
-			})
-		}
-	}
-
-	// Discover IAM Policies
-	policies, err := client.ListPolicies(ctx, &iam.ListPoliciesInput{})
-	if err == nil {
-		for _, policy := range policies.Policies {
-			resources = append(resources, models.Resource{
-				ID:       *policy.Arn,
-				Name:     *policy.PolicyName,
-				Type:     "iam_policy",
-				Provider: "aws",
-				Region:   "global",
-				State:    "active",
-				Created:  *policy.CreateDate,
-			})
-		}
-	}
-
-	return resources, nil
-}
-
 func (ap *AWSProvider) discoverRoute53Resources(ctx context.Context, accountID string) ([]models.Resource, error) {
 	var resources []models.Resource
 
@@ -1120,7 +1068,12 @@ func (ap *AWSProvider) discoverSecurityGroupResources(ctx context.Context, accou
 				Region:   region,
 				State:    "active",
 				Tags:     ap.convertTags(sg.Tags),
-				Created:  time.Now(), // Security groups don't have creation time
+				Properties: map[string]interface{}{
+					"vpc_id":  aws.ToString(sg.VpcId),
+					"ingress": awsprovider.SecurityGroupRules(*sg.GroupId, sg.IpPermissions),
+					"egress":  awsprovider.SecurityGroupRules(*sg.GroupId, sg.IpPermissionsEgress),
+				},
+				Created: time.Now(), // Security groups don't have creation time
 			})
 		}
 	}
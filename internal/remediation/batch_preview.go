@@ -0,0 +1,86 @@
+package remediation
+
+import (
+	"errors"
+	"slices"
+	"time"
+)
+
+var (
+	// ErrBatchPreviewRequired is returned when a batch is run without a preview token
+	ErrBatchPreviewRequired = errors.New("preview token required: preview the batch first and pass its preview_token")
+
+	// ErrBatchPreviewMismatch is returned when the preview token does not match the batch's current plan
+	ErrBatchPreviewMismatch = errors.New("preview token does not match the current batch plan: preview the batch again")
+
+	// ErrBatchPreviewExpired is returned when the preview token's preview is too old
+	ErrBatchPreviewExpired = errors.New("preview token has expired: preview the batch again")
+)
+
+// ActionImpact is what a remediation action does to its resource
+type ActionImpact string
+
+const (
+	// ActionImpactDestructive actions recreate the resource or remove it from management
+	ActionImpactDestructive ActionImpact = "destructive"
+	// ActionImpactSafe actions change the resource in place, create a missing one or only
+	// touch state
+	ActionImpactSafe ActionImpact = "safe"
+	// ActionImpactImport actions bring an unmanaged resource under management unchanged
+	ActionImpactImport ActionImpact = "import"
+)
+
+// ImpactOf returns what an action of the given type does to its resource
+func ImpactOf(actionType ActionType) ActionImpact {
+	switch actionType {
+	case ActionTypeReplace, ActionTypeTaint, ActionTypeDelete:
+		return ActionImpactDestructive
+	case ActionTypeImport:
+		return ActionImpactImport
+	default:
+		return ActionImpactSafe
+	}
+}
+
+// BatchPreviewToken issues the token a batch remediation of the given drift results must carry
+// to run the plan previewed for them, and when it expires
+func (irs *IntelligentRemediationService) BatchPreviewToken(driftIDs []string, plan *RemediationPlan) (string, time.Time) {
+	irs.mu.RLock()
+	defer irs.mu.RUnlock()
+	return irs.confirmation.issue(batchFields(driftIDs, plan))
+}
+
+// CheckBatchPreview verifies the token supplied with a batch remediation against the plan for
+// the drift results about to be remediated
+func (irs *IntelligentRemediationService) CheckBatchPreview(token string, driftIDs []string, plan *RemediationPlan) error {
+	irs.mu.RLock()
+	err := irs.confirmation.verify(token, batchFields(driftIDs, plan))
+	irs.mu.RUnlock()
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, ErrConfirmationRequired):
+		return ErrBatchPreviewRequired
+	case errors.Is(err, ErrConfirmationExpired):
+		return ErrBatchPreviewExpired
+	default:
+		return ErrBatchPreviewMismatch
+	}
+}
+
+// batchFields describes a batch plan for its preview token: the drift IDs, then the type and
+// resource of every action, each sorted since action IDs differ between plans of the same drift
+func batchFields(driftIDs []string, plan *RemediationPlan) []string {
+	ids := slices.Sorted(slices.Values(driftIDs))
+	actions := make([]string, 0, len(plan.Actions))
+	for _, action := range plan.Actions {
+		actions = append(actions, string(action.Type)+" "+action.Resource)
+	}
+	slices.Sort(actions)
+
+	fields := make([]string, 0, len(ids)+len(actions)+2)
+	fields = append(fields, "batch")
+	fields = append(fields, ids...)
+	fields = append(fields, "actions")
+	return append(fields, actions...)
+}
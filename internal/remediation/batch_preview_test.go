@@ -0,0 +1,40 @@
+package remediation
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBatchPreviewToken(t *testing.T) {
+	service := NewIntelligentRemediationService(nil)
+	plan := &RemediationPlan{Actions: []RemediationAction{
+		{ID: "a1", Type: ActionTypeTaint, Resource: "sg-1"},
+		{ID: "a2", Type: ActionTypeReplace, Resource: "sg-1"},
+	}}
+	token, expires := service.BatchPreviewToken([]string{"drift-2", "drift-1"}, plan)
+	assert.WithinDuration(t, time.Now().Add(DefaultConfirmationTTL), expires, 2*time.Second)
+
+	// The same drifts planned again, with new action IDs and in another order, are accepted
+	replanned := &RemediationPlan{Actions: []RemediationAction{
+		{ID: "b2", Type: ActionTypeReplace, Resource: "sg-1"},
+		{ID: "b1", Type: ActionTypeTaint, Resource: "sg-1"},
+	}}
+	assert.NoError(t, service.CheckBatchPreview(token, []string{"drift-1", "drift-2"}, replanned))
+
+	assert.ErrorIs(t, service.CheckBatchPreview("", []string{"drift-1", "drift-2"}, plan), ErrBatchPreviewRequired)
+	assert.ErrorIs(t, service.CheckBatchPreview(token, []string{"drift-1"}, plan), ErrBatchPreviewMismatch)
+	updated := &RemediationPlan{Actions: []RemediationAction{{ID: "c1", Type: ActionTypeUpdate, Resource: "sg-1"}}}
+	assert.ErrorIs(t, service.CheckBatchPreview(token, []string{"drift-1", "drift-2"}, updated), ErrBatchPreviewMismatch)
+
+	service.confirmation.now = func() time.Time { return time.Now().Add(DefaultConfirmationTTL + time.Minute) }
+	assert.ErrorIs(t, service.CheckBatchPreview(token, []string{"drift-1", "drift-2"}, plan), ErrBatchPreviewExpired)
+}
+
+func TestImpactOf(t *testing.T) {
+	assert.Equal(t, ActionImpactDestructive, ImpactOf(ActionTypeReplace))
+	assert.Equal(t, ActionImpactDestructive, ImpactOf(ActionTypeDelete))
+	assert.Equal(t, ActionImpactImport, ImpactOf(ActionTypeImport))
+	assert.Equal(t, ActionImpactSafe, ImpactOf(ActionTypeUpdate))
+}
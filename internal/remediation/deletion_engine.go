@@ -13,8 +13,10 @@ import (
 
 // DeletionEngine provides comprehensive resource deletion capabilities
 type DeletionEngine struct {
-	providers map[string]CloudProvider
-	mu        sync.RWMutex
+	providers    map[string]CloudProvider
+	guardrails   *DeletionGuardrails
+	confirmation *confirmationSigner
+	mu           sync.RWMutex
 }
 
 // CloudProvider interface for different cloud providers
@@ -41,6 +43,9 @@ type DeletionOptions struct {
 	SafetyChecks     []SafetyCheck        `json:"safety_checks,omitempty"`
 	ProgressCallback func(ProgressUpdate) `json:"-"`
 	ErrorCallback    func(DeletionError)  `json:"-"`
+
+	// ConfirmationToken must be the token returned by a recent dry run of the same deletion
+	ConfirmationToken string `json:"confirmation_token,omitempty"`
 }
 
 // DeletionResult represents the result of a deletion operation
@@ -97,8 +102,39 @@ type ResourceDependency struct {
 // NewDeletionEngine creates a new deletion engine
 func NewDeletionEngine() *DeletionEngine {
 	return &DeletionEngine{
-		providers: make(map[string]CloudProvider),
+		providers:    make(map[string]CloudProvider),
+		guardrails:   DefaultDeletionGuardrails(),
+		confirmation: newConfirmationSigner(),
+	}
+}
+
+// SetConfirmationTTL sets how long confirmation tokens from dry runs are accepted; values of
+// zero or less restore DefaultConfirmationTTL
+func (de *DeletionEngine) SetConfirmationTTL(ttl time.Duration) {
+	de.mu.Lock()
+	defer de.mu.Unlock()
+	if ttl <= 0 {
+		ttl = DefaultConfirmationTTL
+	}
+	de.confirmation.ttl = ttl
+}
+
+// SetConfirmationKey keys confirmation tokens with a secret, so they survive restarts and are
+// accepted by every server sharing it. An empty secret keeps the current key.
+func (de *DeletionEngine) SetConfirmationKey(secret string) {
+	if secret == "" {
+		return
 	}
+	de.mu.Lock()
+	defer de.mu.Unlock()
+	de.confirmation.key = confirmationKey(secret)
+}
+
+// SetGuardrails replaces the guardrails that keep protected resources out of deletions
+func (de *DeletionEngine) SetGuardrails(guardrails *DeletionGuardrails) {
+	de.mu.Lock()
+	defer de.mu.Unlock()
+	de.guardrails = guardrails
 }
 
 // RegisterProvider registers a cloud provider
@@ -108,6 +144,23 @@ func (de *DeletionEngine) RegisterProvider(name string, provider CloudProvider)
 	de.providers[name] = provider
 }
 
+// GetOrRegisterProvider returns the cloud provider registered under name, creating and
+// registering it with create when there is none. The check and registration happen under one
+// lock, so concurrent callers create the provider at most once.
+func (de *DeletionEngine) GetOrRegisterProvider(name string, create func() (CloudProvider, error)) (CloudProvider, error) {
+	de.mu.Lock()
+	defer de.mu.Unlock()
+	if provider, exists := de.providers[name]; exists {
+		return provider, nil
+	}
+	provider, err := create()
+	if err != nil {
+		return nil, err
+	}
+	de.providers[name] = provider
+	return provider, nil
+}
+
 // GetProvider retrieves a cloud provider by name
 func (de *DeletionEngine) GetProvider(name string) (CloudProvider, bool) {
 	de.mu.RLock()
@@ -157,22 +210,110 @@ func (de *DeletionEngine) DeleteAccountResources(ctx context.Context, provider,
 	}
 
 	// Execute deletion with retry logic
-	return de.executeDeletionWithRetry(ctx, providerImpl, accountID, options)
+	return de.executeDeletionWithRetry(ctx, providerImpl, provider, accountID, options)
+}
+
+// PreviewDeletion returns the resources a deletion would remove, in the order they would be
+// deleted, the protected resources it would skip and the token needed to confirm it
+func (de *DeletionEngine) PreviewDeletion(ctx context.Context, provider, accountID string, options DeletionOptions) (*DeletionPreview, error) {
+	de.mu.RLock()
+	providerImpl, exists := de.providers[provider]
+	de.mu.RUnlock()
+
+	if !exists {
+		return nil, fmt.Errorf("provider %s not supported", provider)
+	}
+
+	resources, err := providerImpl.ListResources(ctx, accountID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list resources: %w", err)
+	}
+
+	_, preview := de.planDeletion(provider, accountID, resources, options)
+	return preview, nil
+}
+
+// planDeletion filters resources by the options, removes protected ones and orders the rest
+func (de *DeletionEngine) planDeletion(provider, accountID string, resources []models.Resource, options DeletionOptions) ([]models.Resource, *DeletionPreview) {
+	de.mu.RLock()
+	guardrails := de.guardrails
+	de.mu.RUnlock()
+
+	allowed, protected := guardrails.Partition(filterDeletionResources(resources, options))
+	ordered, steps := PlanDeletionOrder(allowed)
+	token, expires := de.confirmToken(provider, accountID, steps)
+	return ordered, &DeletionPreview{
+		Steps:                 steps,
+		Protected:             protected,
+		ConfirmationToken:     token,
+		ConfirmationExpiresAt: expires,
+	}
+}
+
+// confirmToken issues the confirmation token for a plan
+func (de *DeletionEngine) confirmToken(provider, accountID string, steps []DeletionStep) (string, time.Time) {
+	de.mu.RLock()
+	defer de.mu.RUnlock()
+	return de.confirmation.issue(deletionFields(provider, accountID, steps))
 }
 
-// executeDeletionWithRetry executes deletion with retry logic for failed resources
-func (de *DeletionEngine) executeDeletionWithRetry(ctx context.Context, provider CloudProvider, accountID string, options DeletionOptions) (*DeletionResult, error) {
-	// First attempt
-	result, err := provider.DeleteResources(ctx, accountID, options)
+// checkConfirmation verifies the token supplied for a deletion against the plan about to run
+func (de *DeletionEngine) checkConfirmation(supplied, provider, accountID string, steps []DeletionStep) error {
+	de.mu.RLock()
+	defer de.mu.RUnlock()
+	return de.confirmation.verify(supplied, deletionFields(provider, accountID, steps))
+}
+
+// executeDeletionWithRetry deletes the matching resources in dependency order, then retries
+// resources that failed with transient errors. Protected resources are skipped. Dry runs
+// report the order and a confirmation token without deleting; real runs require that token.
+func (de *DeletionEngine) executeDeletionWithRetry(ctx context.Context, provider CloudProvider, providerName, accountID string, options DeletionOptions) (*DeletionResult, error) {
+	startTime := time.Now()
+	result := &DeletionResult{
+		AccountID: accountID,
+		Provider:  providerName,
+		StartTime: startTime,
+		Errors:    []DeletionError{},
+		Warnings:  []string{},
+		Details:   make(map[string]interface{}),
+	}
+
+	resources, err := provider.ListResources(ctx, accountID)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("failed to list resources: %w", err)
+	}
+	result.TotalResources = len(resources)
+
+	ordered, preview := de.planDeletion(providerName, accountID, resources, options)
+	result.Details["deletion_order"] = preview.Steps
+	result.Details["confirmation_token"] = preview.ConfirmationToken
+	result.SkippedResources = len(resources) - len(ordered)
+	if len(preview.Protected) > 0 {
+		result.Details["protected_resources"] = preview.Protected
+		for _, protected := range preview.Protected {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("Skipped protected resource %s: %s", protected.ResourceID, protected.Reason))
+		}
 	}
 
-	// Retry failed resources
-	if len(result.Errors) > 0 && options.MaxRetries > 0 {
-		de.retryFailedResources(ctx, provider, accountID, options, result)
+	if !options.DryRun {
+		if err := de.checkConfirmation(options.ConfirmationToken, providerName, accountID, preview.Steps); err != nil {
+			return nil, err
+		}
+	}
+
+	if options.DryRun {
+		result.DeletedResources = len(ordered)
+	} else {
+		de.deleteInOrder(ctx, provider, ordered, options, result)
+
+		// Retry failed resources
+		if len(result.Errors) > 0 && options.MaxRetries > 0 {
+			de.retryFailedResources(ctx, provider, accountID, options, result)
+		}
 	}
 
+	result.EndTime = time.Now()
+	result.Duration = result.EndTime.Sub(startTime)
 	return result, nil
 }
 
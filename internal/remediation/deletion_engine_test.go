@@ -1,8 +1,13 @@
 package remediation
 
 import (
+	"context"
+	"errors"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/catherinevee/driftmgr/pkg/models"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -11,3 +16,256 @@ func TestDeletionEngine_NewEngine(t *testing.T) {
 	assert.NotNil(t, engine)
 	assert.NotNil(t, engine.providers)
 }
+
+// orderedDeleteProvider records deletions and fails a VPC while any subnet still exists
+type orderedDeleteProvider struct {
+	resources []models.Resource
+	deleted   []string
+}
+
+func (p *orderedDeleteProvider) DeleteResources(ctx context.Context, accountID string, options DeletionOptions) (*DeletionResult, error) {
+	return nil, errors.New("not used")
+}
+
+func (p *orderedDeleteProvider) DeleteResource(ctx context.Context, resource models.Resource) error {
+	if resource.Type == "vpc" {
+		for _, r := range p.resources {
+			if r.Type == "subnet" && !p.isDeleted(r.ID) {
+				return errors.New("DependencyViolation: the vpc has dependencies and cannot be deleted")
+			}
+		}
+	}
+	p.deleted = append(p.deleted, resource.ID)
+	return nil
+}
+
+func (p *orderedDeleteProvider) isDeleted(id string) bool {
+	for _, deleted := range p.deleted {
+		if deleted == id {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *orderedDeleteProvider) ListResources(ctx context.Context, accountID string) ([]models.Resource, error) {
+	return p.resources, nil
+}
+
+func (p *orderedDeleteProvider) ValidateCredentials(ctx context.Context, accountID string) error {
+	return nil
+}
+
+func deletionTestResources() []models.Resource {
+	return []models.Resource{
+		{ID: "vpc-1", Type: "vpc", Provider: "aws"},
+		{ID: "subnet-1", Type: "subnet", Provider: "aws", Metadata: map[string]string{"vpc_id": "vpc-1"}},
+		{ID: "i-1", Type: "ec2_instance", Provider: "aws", Metadata: map[string]string{"subnet_id": "subnet-1"}},
+	}
+}
+
+func TestPlanDeletionOrder(t *testing.T) {
+	ordered, steps := PlanDeletionOrder(deletionTestResources())
+
+	ids := make([]string, len(ordered))
+	for i, resource := range ordered {
+		ids[i] = resource.ID
+	}
+	assert.Equal(t, []string{"i-1", "subnet-1", "vpc-1"}, ids)
+	assert.Equal(t, 2, steps[1].Order)
+	assert.Equal(t, []string{"vpc-1"}, steps[1].DependsOn)
+}
+
+func TestDeletionEngine_DeletesInDependencyOrder(t *testing.T) {
+	provider := &orderedDeleteProvider{resources: deletionTestResources()}
+	engine := NewDeletionEngine()
+	engine.RegisterProvider("aws", provider)
+
+	preview, err := engine.PreviewDeletion(context.Background(), "aws", "123456789012", DeletionOptions{})
+	assert.NoError(t, err)
+
+	result, err := engine.DeleteAccountResources(context.Background(), "aws", "123456789012", DeletionOptions{
+		Force:             true,
+		RetryDelay:        time.Millisecond,
+		ConfirmationToken: preview.ConfirmationToken,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"i-1", "subnet-1", "vpc-1"}, provider.deleted)
+	assert.Equal(t, 3, result.DeletedResources)
+	assert.Equal(t, 0, result.FailedResources)
+}
+
+func TestDeletionEngine_RetriesDependencyViolations(t *testing.T) {
+	// With the subnet excluded from the plan, the VPC is blocked until it is deleted
+	provider := &orderedDeleteProvider{resources: deletionTestResources()}
+	engine := NewDeletionEngine()
+	engine.RegisterProvider("aws", provider)
+
+	options := DeletionOptions{
+		Force:            true,
+		IncludeResources: []string{"vpc-1"},
+		MaxRetries:       1,
+		RetryDelay:       time.Millisecond,
+	}
+	preview, err := engine.PreviewDeletion(context.Background(), "aws", "123456789012", options)
+	assert.NoError(t, err)
+	options.ConfirmationToken = preview.ConfirmationToken
+
+	result, err := engine.DeleteAccountResources(context.Background(), "aws", "123456789012", options)
+	assert.NoError(t, err)
+	assert.Empty(t, provider.deleted)
+	assert.Equal(t, 1, result.RetriedResources)
+	assert.Equal(t, 1, result.FailedResources)
+}
+
+func TestDeletionEngine_DryRunReportsOrder(t *testing.T) {
+	provider := &orderedDeleteProvider{resources: deletionTestResources()}
+	engine := NewDeletionEngine()
+	engine.RegisterProvider("aws", provider)
+
+	result, err := engine.DeleteAccountResources(context.Background(), "aws", "123456789012", DeletionOptions{DryRun: true})
+	assert.NoError(t, err)
+	assert.Empty(t, provider.deleted)
+	steps, ok := result.Details["deletion_order"].([]DeletionStep)
+	assert.True(t, ok)
+	assert.Len(t, steps, 3)
+	assert.Equal(t, "i-1", steps[0].ResourceID)
+	assert.NotEmpty(t, result.Details["confirmation_token"])
+}
+
+func TestDeletionEngine_RequiresConfirmationToken(t *testing.T) {
+	provider := &orderedDeleteProvider{resources: deletionTestResources()}
+	engine := NewDeletionEngine()
+	engine.RegisterProvider("aws", provider)
+
+	_, err := engine.DeleteAccountResources(context.Background(), "aws", "123456789012", DeletionOptions{Force: true})
+	assert.ErrorIs(t, err, ErrConfirmationRequired)
+
+	_, err = engine.DeleteAccountResources(context.Background(), "aws", "123456789012", DeletionOptions{
+		Force:             true,
+		ConfirmationToken: "stale",
+	})
+	assert.ErrorIs(t, err, ErrConfirmationMismatch)
+	assert.Empty(t, provider.deleted)
+}
+
+func TestDeletionEngine_RejectsForgedAndExpiredTokens(t *testing.T) {
+	provider := &orderedDeleteProvider{resources: deletionTestResources()}
+	engine := NewDeletionEngine()
+	engine.RegisterProvider("aws", provider)
+
+	preview, err := engine.PreviewDeletion(context.Background(), "aws", "123456789012", DeletionOptions{})
+	assert.NoError(t, err)
+	assert.WithinDuration(t, time.Now().Add(DefaultConfirmationTTL), preview.ConfirmationExpiresAt, 2*time.Second)
+
+	// A token from another engine's key previews the same plan but is not accepted
+	other := NewDeletionEngine()
+	other.RegisterProvider("aws", provider)
+	forged, err := other.PreviewDeletion(context.Background(), "aws", "123456789012", DeletionOptions{})
+	assert.NoError(t, err)
+	_, err = engine.DeleteAccountResources(context.Background(), "aws", "123456789012", DeletionOptions{
+		Force:             true,
+		ConfirmationToken: forged.ConfirmationToken,
+	})
+	assert.ErrorIs(t, err, ErrConfirmationMismatch)
+
+	// A token for another account is not accepted
+	_, err = engine.DeleteAccountResources(context.Background(), "aws", "210987654321", DeletionOptions{
+		Force:             true,
+		ConfirmationToken: preview.ConfirmationToken,
+	})
+	assert.ErrorIs(t, err, ErrConfirmationMismatch)
+
+	engine.confirmation.now = func() time.Time { return time.Now().Add(DefaultConfirmationTTL + time.Minute) }
+	_, err = engine.DeleteAccountResources(context.Background(), "aws", "123456789012", DeletionOptions{
+		Force:             true,
+		ConfirmationToken: preview.ConfirmationToken,
+	})
+	assert.ErrorIs(t, err, ErrConfirmationExpired)
+	assert.Empty(t, provider.deleted)
+}
+
+func TestDeletionEngine_ConfiguredKeyIsShared(t *testing.T) {
+	provider := &orderedDeleteProvider{resources: deletionTestResources()}
+	engine := NewDeletionEngine()
+	engine.SetConfirmationKey("shared-secret")
+	engine.RegisterProvider("aws", provider)
+	preview, err := engine.PreviewDeletion(context.Background(), "aws", "123456789012", DeletionOptions{})
+	assert.NoError(t, err)
+
+	// Another engine with the same key, such as after a restart, accepts the token
+	restarted := NewDeletionEngine()
+	restarted.SetConfirmationKey("shared-secret")
+	restarted.RegisterProvider("aws", provider)
+	_, err = restarted.DeleteAccountResources(context.Background(), "aws", "123456789012", DeletionOptions{
+		Force:             true,
+		RetryDelay:        time.Millisecond,
+		ConfirmationToken: preview.ConfirmationToken,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"i-1", "subnet-1", "vpc-1"}, provider.deleted)
+}
+
+func TestDeletionEngine_GetOrRegisterProviderCreatesOnce(t *testing.T) {
+	engine := NewDeletionEngine()
+	var mu sync.Mutex
+	created := 0
+	create := func() (CloudProvider, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		created++
+		return &orderedDeleteProvider{}, nil
+	}
+
+	var wg sync.WaitGroup
+	providers := make([]CloudProvider, 20)
+	for i := range providers {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			providers[i], _ = engine.GetOrRegisterProvider("aws", create)
+		}(i)
+	}
+	wg.Wait()
+
+	assert.Equal(t, 1, created, "concurrent requests create the provider once")
+	registered, ok := engine.GetProvider("aws")
+	assert.True(t, ok)
+	for _, provider := range providers {
+		assert.Same(t, registered, provider)
+	}
+
+	_, err := engine.GetOrRegisterProvider("azure", func() (CloudProvider, error) { return nil, errors.New("no credentials") })
+	assert.Error(t, err)
+	_, ok = engine.GetProvider("azure")
+	assert.False(t, ok, "a provider that fails to connect is not registered")
+}
+
+func TestDeletionEngine_SkipsProtectedResources(t *testing.T) {
+	resources := deletionTestResources()
+	resources[0].Tags = map[string]string{"driftmgr:protect": "true"}
+	resources = append(resources, models.Resource{ID: "trail-1", Name: "org-audit-trail", Type: "cloudtrail", Provider: "aws"})
+
+	provider := &orderedDeleteProvider{resources: resources}
+	engine := NewDeletionEngine()
+	engine.SetGuardrails(&DeletionGuardrails{
+		ProtectTag:   "driftmgr:protect",
+		ProtectValue: "true",
+		DeniedNames:  []string{"*-audit-*"},
+	})
+	engine.RegisterProvider("aws", provider)
+
+	preview, err := engine.PreviewDeletion(context.Background(), "aws", "123456789012", DeletionOptions{})
+	assert.NoError(t, err)
+	assert.Len(t, preview.Steps, 2)
+	assert.Len(t, preview.Protected, 2)
+
+	result, err := engine.DeleteAccountResources(context.Background(), "aws", "123456789012", DeletionOptions{
+		Force:             true,
+		ConfirmationToken: preview.ConfirmationToken,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"i-1", "subnet-1"}, provider.deleted)
+	assert.Equal(t, 2, result.SkippedResources)
+	assert.Len(t, result.Warnings, 2)
+}
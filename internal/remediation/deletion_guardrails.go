@@ -0,0 +1,198 @@
+package remediation
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/catherinevee/driftmgr/pkg/models"
+)
+
+var (
+	// ErrConfirmationRequired is returned when a deletion is attempted without a confirmation token
+	ErrConfirmationRequired = errors.New("confirmation token required: run a dry run first and pass its confirmation_token")
+
+	// ErrConfirmationMismatch is returned when the confirmation token does not match the current plan
+	ErrConfirmationMismatch = errors.New("confirmation token does not match the current deletion plan: run a new dry run")
+
+	// ErrConfirmationExpired is returned when the confirmation token's dry run is too old
+	ErrConfirmationExpired = errors.New("confirmation token has expired: run a new dry run")
+)
+
+// DefaultConfirmationTTL is how long a dry run's confirmation token is accepted
+const DefaultConfirmationTTL = 15 * time.Minute
+
+// ConfirmationKeyEnv names the environment variable holding the secret confirmation tokens are
+// signed with when none is configured
+const ConfirmationKeyEnv = "DRIFTMGR_CONFIRMATION_KEY"
+
+// DeletionGuardrails keeps resources out of bulk deletion whatever the request asks for.
+// Resources carrying the protect tag, or whose type or name is on a deny list, are skipped.
+type DeletionGuardrails struct {
+	ProtectTag   string   `json:"protect_tag"`
+	ProtectValue string   `json:"protect_value"`
+	DeniedTypes  []string `json:"denied_types,omitempty"`
+	DeniedNames  []string `json:"denied_names,omitempty"` // glob patterns matched against names and IDs
+}
+
+// ProtectedResource is a resource the guardrails kept out of a deletion
+type ProtectedResource struct {
+	ResourceID   string `json:"resource_id"`
+	ResourceType string `json:"resource_type"`
+	Name         string `json:"name"`
+	Reason       string `json:"reason"`
+}
+
+// DeletionPreview describes what a deletion would do. ConfirmationToken must be passed back
+// in DeletionOptions before ConfirmationExpiresAt to carry out exactly this plan.
+type DeletionPreview struct {
+	Steps                 []DeletionStep      `json:"steps"`
+	Protected             []ProtectedResource `json:"protected,omitempty"`
+	ConfirmationToken     string              `json:"confirmation_token"`
+	ConfirmationExpiresAt time.Time           `json:"confirmation_expires_at"`
+}
+
+// DefaultDeletionGuardrails returns guardrails protecting resources tagged driftmgr:protect=true
+func DefaultDeletionGuardrails() *DeletionGuardrails {
+	return &DeletionGuardrails{
+		ProtectTag:   "driftmgr:protect",
+		ProtectValue: "true",
+	}
+}
+
+// ProtectionReason reports why a resource is protected, if it is
+func (g *DeletionGuardrails) ProtectionReason(resource models.Resource) (string, bool) {
+	if g == nil {
+		return "", false
+	}
+
+	if g.ProtectTag != "" {
+		if value, ok := resource.Tags[g.ProtectTag]; ok && (g.ProtectValue == "" || strings.EqualFold(value, g.ProtectValue)) {
+			return fmt.Sprintf("tagged %s=%s", g.ProtectTag, value), true
+		}
+	}
+	for _, deniedType := range g.DeniedTypes {
+		if resource.Type == deniedType {
+			return fmt.Sprintf("type %s is on the deny list", resource.Type), true
+		}
+	}
+	for _, pattern := range g.DeniedNames {
+		for _, candidate := range []string{resource.Name, resource.ID} {
+			if candidate == "" {
+				continue
+			}
+			if matched, _ := path.Match(pattern, candidate); matched {
+				return fmt.Sprintf("%s matches deny-listed name %s", candidate, pattern), true
+			}
+		}
+	}
+	return "", false
+}
+
+// Partition splits resources into those that may be deleted and those the guardrails protect
+func (g *DeletionGuardrails) Partition(resources []models.Resource) ([]models.Resource, []ProtectedResource) {
+	var allowed []models.Resource
+	var protected []ProtectedResource
+	for _, resource := range resources {
+		if reason, ok := g.ProtectionReason(resource); ok {
+			protected = append(protected, ProtectedResource{
+				ResourceID:   resource.ID,
+				ResourceType: resource.Type,
+				Name:         resource.Name,
+				Reason:       reason,
+			})
+			continue
+		}
+		allowed = append(allowed, resource)
+	}
+	return allowed, protected
+}
+
+// confirmationSigner issues and verifies confirmation tokens. A token is an expiry time and an
+// HMAC of it with the fields describing a plan, such as the provider, account and ordered
+// resource IDs of a deletion, keyed with the configured secret or else one generated per
+// signer, so it cannot be derived without a preview, and is only accepted while the plan it
+// previewed is unchanged and until it expires.
+type confirmationSigner struct {
+	key []byte
+	ttl time.Duration
+	now func() time.Time
+}
+
+// newConfirmationSigner creates a signer keyed with DRIFTMGR_CONFIRMATION_KEY, or with a
+// random key when it is unset
+func newConfirmationSigner() *confirmationSigner {
+	return &confirmationSigner{key: confirmationKey(os.Getenv(ConfirmationKeyEnv)), ttl: DefaultConfirmationTTL, now: time.Now}
+}
+
+// confirmationKey derives a signing key from a configured secret, so tokens survive restarts
+// and are accepted by every server sharing the secret. Without a secret a random key is
+// generated, and tokens are only accepted by the process that issued them.
+func confirmationKey(secret string) []byte {
+	if secret != "" {
+		sum := sha256.Sum256([]byte(secret))
+		return sum[:]
+	}
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		panic(fmt.Sprintf("failed to generate confirmation key: %v", err))
+	}
+	return key
+}
+
+// issue returns a token for the plan described by fields and when it expires
+func (cs *confirmationSigner) issue(fields []string) (string, time.Time) {
+	expires := cs.now().Add(cs.ttl).UTC().Truncate(time.Second)
+	expiry := strconv.FormatInt(expires.Unix(), 10)
+	return expiry + "." + cs.sign(expiry, fields), expires
+}
+
+// verify checks a supplied token against the fields of the plan about to be carried out
+func (cs *confirmationSigner) verify(supplied string, fields []string) error {
+	if supplied == "" {
+		return ErrConfirmationRequired
+	}
+	expiry, mac, ok := strings.Cut(supplied, ".")
+	if !ok {
+		return ErrConfirmationMismatch
+	}
+	if !hmac.Equal([]byte(mac), []byte(cs.sign(expiry, fields))) {
+		return ErrConfirmationMismatch
+	}
+	expiresUnix, err := strconv.ParseInt(expiry, 10, 64)
+	if err != nil {
+		return ErrConfirmationMismatch
+	}
+	if !cs.now().Before(time.Unix(expiresUnix, 0)) {
+		return ErrConfirmationExpired
+	}
+	return nil
+}
+
+// sign computes the HMAC of an expiry and a plan's fields
+func (cs *confirmationSigner) sign(expiry string, fields []string) string {
+	mac := hmac.New(sha256.New, cs.key)
+	fmt.Fprintf(mac, "%s\n", expiry)
+	for _, field := range fields {
+		fmt.Fprintf(mac, "%s\n", field)
+	}
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// deletionFields describes a deletion plan for its confirmation token
+func deletionFields(provider, accountID string, steps []DeletionStep) []string {
+	fields := make([]string, 0, len(steps)+2)
+	fields = append(fields, provider, accountID)
+	for _, step := range steps {
+		fields = append(fields, step.ResourceID)
+	}
+	return fields
+}
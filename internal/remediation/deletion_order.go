@@ -0,0 +1,167 @@
+package remediation
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/catherinevee/driftmgr/pkg/models"
+)
+
+// DeletionStep is one resource in a deletion plan. Steps are ordered so every resource
+// is deleted before the resources it depends on.
+type DeletionStep struct {
+	Order        int      `json:"order"`
+	ResourceID   string   `json:"resource_id"`
+	ResourceType string   `json:"resource_type"`
+	Name         string   `json:"name"`
+	Region       string   `json:"region,omitempty"`
+	DependsOn    []string `json:"depends_on,omitempty"`
+}
+
+// PlanDeletionOrder orders resources so dependents come before their dependencies (for
+// example instances and subnets before their VPC) and describes the order as steps.
+// Dependencies come from the relationships DependencyValidator extracts from each resource.
+func PlanDeletionOrder(resources []models.Resource) ([]models.Resource, []DeletionStep) {
+	validator := NewDependencyValidator()
+	ordered := validator.OrderResourcesForDeletion(resources)
+
+	inPlan := make(map[string]bool, len(resources))
+	for _, resource := range resources {
+		inPlan[resource.ID] = true
+	}
+
+	steps := make([]DeletionStep, len(ordered))
+	for i, resource := range ordered {
+		step := DeletionStep{
+			Order:        i + 1,
+			ResourceID:   resource.ID,
+			ResourceType: resource.Type,
+			Name:         resource.Name,
+			Region:       resource.Region,
+		}
+		for _, dep := range validator.findDependencies(resource) {
+			if inPlan[dep] {
+				step.DependsOn = append(step.DependsOn, dep)
+			}
+		}
+		steps[i] = step
+	}
+
+	return ordered, steps
+}
+
+// filterDeletionResources applies the include, exclude, type and region filters of the options
+func filterDeletionResources(resources []models.Resource, options DeletionOptions) []models.Resource {
+	var filtered []models.Resource
+	for _, resource := range resources {
+		if matchesAnyResource(resource, options.ExcludeResources) {
+			continue
+		}
+		if len(options.IncludeResources) > 0 && !matchesAnyResource(resource, options.IncludeResources) {
+			continue
+		}
+		if len(options.ResourceTypes) > 0 && !containsValue(options.ResourceTypes, resource.Type) {
+			continue
+		}
+		if len(options.Regions) > 0 && !containsValue(options.Regions, resource.Region) {
+			continue
+		}
+		filtered = append(filtered, resource)
+	}
+	return filtered
+}
+
+// matchesAnyResource reports whether the resource's ID or name is in the list
+func matchesAnyResource(resource models.Resource, idsOrNames []string) bool {
+	for _, value := range idsOrNames {
+		if resource.ID == value || resource.Name == value {
+			return true
+		}
+	}
+	return false
+}
+
+// containsValue reports whether the slice contains the value
+func containsValue(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// isDependencyViolation reports whether a deletion failed because other resources still
+// depend on the resource, which usually clears once those are deleted
+func isDependencyViolation(err error) bool {
+	errStr := strings.ToLower(err.Error())
+	return strings.Contains(errStr, "dependencyviolation") ||
+		strings.Contains(errStr, "dependency violation") ||
+		strings.Contains(errStr, "has dependencies") ||
+		strings.Contains(errStr, "has dependent") ||
+		strings.Contains(errStr, "resourceinuse") ||
+		strings.Contains(errStr, "in use by")
+}
+
+// deleteInOrder deletes resources one at a time in the given order. Resources that fail with
+// a dependency violation are retried after the rest of the pass, up to MaxRetries passes,
+// since a dependent deleted later in the pass may have been what blocked them.
+func (de *DeletionEngine) deleteInOrder(ctx context.Context, provider CloudProvider, ordered []models.Resource, options DeletionOptions, result *DeletionResult) {
+	pending := ordered
+	for attempt := 0; len(pending) > 0; attempt++ {
+		var deferred []models.Resource
+		for _, resource := range pending {
+			err := ctx.Err()
+			if err == nil {
+				err = provider.DeleteResource(ctx, resource)
+			}
+
+			switch {
+			case err == nil:
+				result.DeletedResources++
+			case isDependencyViolation(err) && attempt < options.MaxRetries && ctx.Err() == nil:
+				deferred = append(deferred, resource)
+				continue
+			default:
+				result.FailedResources++
+				deletionErr := DeletionError{
+					ResourceID:   resource.ID,
+					ResourceType: resource.Type,
+					Error:        err.Error(),
+					RetryCount:   attempt,
+					Timestamp:    time.Now(),
+				}
+				result.Errors = append(result.Errors, deletionErr)
+				if options.ErrorCallback != nil {
+					options.ErrorCallback(deletionErr)
+				}
+			}
+
+			if options.ProgressCallback != nil {
+				options.ProgressCallback(ProgressUpdate{
+					Type:      "deletion_progress",
+					Message:   fmt.Sprintf("Processed %s: %s", resource.Type, resource.Name),
+					Progress:  result.DeletedResources + result.FailedResources,
+					Total:     len(ordered),
+					Current:   resource.Name,
+					Timestamp: time.Now(),
+				})
+			}
+		}
+
+		if len(deferred) == 0 {
+			return
+		}
+		log.Printf("Retrying %d resources blocked by dependencies (pass %d/%d)", len(deferred), attempt+1, options.MaxRetries)
+		result.RetriedResources += len(deferred)
+		pending = deferred
+
+		select {
+		case <-ctx.Done():
+		case <-time.After(options.RetryDelay):
+		}
+	}
+}
@@ -2,6 +2,7 @@ package remediation
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/catherinevee/driftmgr/pkg/models"
 )
@@ -85,18 +86,33 @@ func (dv *DependencyValidator) OrderResourcesForDeletion(resources []models.Reso
 	return ordered
 }
 
-// findDependencies finds resources that this resource depends on
+// findDependencies finds resources that this resource depends on, from its explicit
+// dependencies and the provider-specific references in its metadata
 func (dv *DependencyValidator) findDependencies(resource models.Resource) []string {
+	var deps []string
 	switch resource.Provider {
 	case "aws":
-		return dv.findAWSDependencies(resource)
+		deps = dv.findAWSDependencies(resource)
 	case "azure":
-		return dv.findAzureDependencies(resource)
+		deps = dv.findAzureDependencies(resource)
 	case "gcp":
-		return dv.findGCPDependencies(resource)
-	default:
-		return []string{}
+		deps = dv.findGCPDependencies(resource)
+	}
+	deps = append(deps, resource.Dependencies...)
+	return splitResourceIDs(deps)
+}
+
+// splitResourceIDs expands metadata values holding several IDs, such as "[sg-1 sg-2]" or
+// "subnet-1,subnet-2", into individual IDs
+func splitResourceIDs(values []string) []string {
+	var ids []string
+	for _, value := range values {
+		value = strings.Trim(value, "[]")
+		for _, id := range strings.FieldsFunc(value, func(r rune) bool { return r == ',' || r == ' ' }) {
+			ids = append(ids, id)
+		}
 	}
+	return ids
 }
 
 // findAWSDependencies finds AWS resource dependencies
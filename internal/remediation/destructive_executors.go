@@ -0,0 +1,353 @@
+package remediation
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/catherinevee/driftmgr/internal/state"
+)
+
+// ErrStatefulResource is returned when recreating or deleting would destroy a resource that
+// holds data, such as a database or volume, and the action does not set force
+var ErrStatefulResource = errors.New("refusing to destroy a stateful resource without force")
+
+// statefulResourceTypes are the resource types whose data is lost when they are destroyed
+var statefulResourceTypes = map[string]bool{
+	"aws_db_instance":                    true,
+	"aws_rds_cluster":                    true,
+	"aws_rds_cluster_instance":           true,
+	"aws_docdb_cluster":                  true,
+	"aws_neptune_cluster":                true,
+	"aws_redshift_cluster":               true,
+	"aws_dynamodb_table":                 true,
+	"aws_elasticache_cluster":            true,
+	"aws_elasticache_replication_group":  true,
+	"aws_opensearch_domain":              true,
+	"aws_elasticsearch_domain":           true,
+	"aws_ebs_volume":                     true,
+	"aws_efs_file_system":                true,
+	"aws_s3_bucket":                      true,
+	"azurerm_managed_disk":               true,
+	"azurerm_storage_account":            true,
+	"azurerm_mssql_database":             true,
+	"azurerm_sql_database":               true,
+	"azurerm_postgresql_server":          true,
+	"azurerm_postgresql_flexible_server": true,
+	"azurerm_mysql_server":               true,
+	"azurerm_mysql_flexible_server":      true,
+	"azurerm_cosmosdb_account":           true,
+	"azurerm_redis_cache":                true,
+	"google_sql_database_instance":       true,
+	"google_compute_disk":                true,
+	"google_compute_region_disk":         true,
+	"google_storage_bucket":              true,
+	"google_bigquery_dataset":            true,
+	"google_bigtable_instance":           true,
+	"google_spanner_instance":            true,
+	"google_redis_instance":              true,
+	"google_filestore_instance":          true,
+}
+
+// IsStatefulResourceType reports whether destroying a resource of the given type destroys
+// the data it holds
+func IsStatefulResourceType(resourceType string) bool {
+	return statefulResourceTypes[resourceType]
+}
+
+// plannedChange is what a recreate or delete does to one resource, read from its saved plan
+type plannedChange struct {
+	address  string
+	action   string // create, update, replace or delete
+	stateful bool
+}
+
+// String describes the change for an action result
+func (c plannedChange) String() string {
+	if c.destroysData() {
+		return fmt.Sprintf("%s %s (stateful)", c.action, c.address)
+	}
+	return c.action + " " + c.address
+}
+
+// destroysData reports whether the change destroys a stateful resource
+func (c plannedChange) destroysData() bool {
+	return c.stateful && (c.action == "replace" || c.action == "delete")
+}
+
+// ReplaceExecutor recreates Terraform-managed resources with terraform apply -replace.
+// Terraform destroys and recreates the resource, updating or replacing the resources that
+// depend on it in dependency order.
+type ReplaceExecutor struct {
+	terraformPath string
+}
+
+// NewReplaceExecutor creates a new replace executor
+func NewReplaceExecutor() *ReplaceExecutor {
+	return &ReplaceExecutor{terraformPath: state.TerraformBinary()}
+}
+
+// GetType returns the action type handled by this executor
+func (e *ReplaceExecutor) GetType() string {
+	return string(ActionTypeReplace)
+}
+
+// GetDescription returns a human-readable description
+func (e *ReplaceExecutor) GetDescription() string {
+	return "Recreates resources with terraform apply -replace, refusing to destroy stateful resources without force"
+}
+
+// Validate checks that the action names a resource and refuses stateful ones without force
+func (e *ReplaceExecutor) Validate(action *RemediationAction) error {
+	return validateDestructiveAction(action, ActionTypeReplace, e.terraformPath)
+}
+
+// Execute returns the replace command for review, or plans and applies it when the action
+// names a working directory
+func (e *ReplaceExecutor) Execute(ctx context.Context, action *RemediationAction) (*ActionResult, error) {
+	address := resourceAddress(action)
+	return runDestructiveAction(ctx, e.terraformPath, action, "apply -replace="+address, "-replace="+address)
+}
+
+// DeleteExecutor destroys Terraform-managed resources with terraform destroy -target, which
+// destroys the resources depending on the target first. Actions marked state_only, such as
+// those for orphaned resources, only remove the resource from state.
+type DeleteExecutor struct {
+	terraformPath string
+}
+
+// NewDeleteExecutor creates a new delete executor
+func NewDeleteExecutor() *DeleteExecutor {
+	return &DeleteExecutor{terraformPath: state.TerraformBinary()}
+}
+
+// GetType returns the action type handled by this executor
+func (e *DeleteExecutor) GetType() string {
+	return string(ActionTypeDelete)
+}
+
+// GetDescription returns a human-readable description
+func (e *DeleteExecutor) GetDescription() string {
+	return "Destroys resources and their dependents with terraform, refusing to destroy stateful resources without force"
+}
+
+// Validate checks that the action names a resource and refuses stateful ones without force
+func (e *DeleteExecutor) Validate(action *RemediationAction) error {
+	if stateOnly, _ := action.Parameters["state_only"].(bool); stateOnly {
+		return validateTerraformAction(action, ActionTypeDelete, e.terraformPath)
+	}
+	return validateDestructiveAction(action, ActionTypeDelete, e.terraformPath)
+}
+
+// Execute returns the delete command for review, or plans and applies it when the action
+// names a working directory
+func (e *DeleteExecutor) Execute(ctx context.Context, action *RemediationAction) (*ActionResult, error) {
+	address := resourceAddress(action)
+	if stateOnly, _ := action.Parameters["state_only"].(bool); stateOnly {
+		return runStateRemoval(ctx, e.terraformPath, action, address)
+	}
+	return runDestructiveAction(ctx, e.terraformPath, action, "destroy -target="+address, "-destroy", "-target="+address)
+}
+
+// resourceAddress returns the Terraform address an action applies to
+func resourceAddress(action *RemediationAction) string {
+	if address, _ := action.Parameters["resource_address"].(string); address != "" {
+		return address
+	}
+	return action.Resource
+}
+
+// validateTerraformAction checks an action's type and, when it names a working directory,
+// that the directory and the terraform binary exist
+func validateTerraformAction(action *RemediationAction, actionType ActionType, terraformPath string) error {
+	if action.Type != actionType {
+		return fmt.Errorf("%s executor cannot handle action type %s", actionType, action.Type)
+	}
+	if resourceAddress(action) == "" {
+		return fmt.Errorf("%s action %s has no resource address", actionType, action.ID)
+	}
+	if workDir, _ := action.Parameters["work_dir"].(string); workDir != "" {
+		info, err := os.Stat(workDir)
+		if err != nil || !info.IsDir() {
+			return fmt.Errorf("working directory %s does not exist", workDir)
+		}
+		if _, err := exec.LookPath(terraformPath); err != nil {
+			return fmt.Errorf("terraform binary %q not found; set %s to its path: %w", terraformPath, state.TerraformBinaryEnv, err)
+		}
+	}
+	return nil
+}
+
+// validateDestructiveAction is validateTerraformAction that also refuses to destroy a
+// stateful resource unless the action sets force
+func validateDestructiveAction(action *RemediationAction, actionType ActionType, terraformPath string) error {
+	if err := validateTerraformAction(action, actionType, terraformPath); err != nil {
+		return err
+	}
+	if force, _ := action.Parameters["force"].(bool); !force && IsStatefulResourceType(action.ResourceType) {
+		return fmt.Errorf("%w: %s is a %s", ErrStatefulResource, resourceAddress(action), action.ResourceType)
+	}
+	return nil
+}
+
+// runDestructiveAction saves a terraform plan with planArgs, reads every resource it would
+// change, and applies it unless it would destroy a stateful resource the action does not
+// force. Without a working directory the command is only returned for review.
+func runDestructiveAction(ctx context.Context, terraformPath string, action *RemediationAction, command string, planArgs ...string) (*ActionResult, error) {
+	result := &ActionResult{
+		ActionID:   action.ID,
+		ResourceID: action.Resource,
+		Action:     string(action.Type),
+		StartTime:  time.Now(),
+	}
+
+	workDir, _ := action.Parameters["work_dir"].(string)
+	if workDir == "" {
+		result.Status = StatusSuccess
+		result.Output = "Command generated for review: terraform " + command
+		result.Changes = []string{"terraform " + command}
+		result.EndTime = time.Now()
+		return result, nil
+	}
+	fail := func(err error) (*ActionResult, error) {
+		result.Status = StatusFailed
+		result.Error = err.Error()
+		result.EndTime = time.Now()
+		return result, err
+	}
+
+	planFile, err := os.CreateTemp("", "driftmgr-*.tfplan")
+	if err != nil {
+		return fail(fmt.Errorf("failed to create plan file: %w", err))
+	}
+	planFile.Close()
+	defer os.Remove(planFile.Name())
+
+	args := append([]string{"plan", "-input=false", "-out=" + planFile.Name()}, planArgs...)
+	if output, err := runTerraform(ctx, terraformPath, workDir, args...); err != nil {
+		result.Output = output
+		return fail(fmt.Errorf("terraform plan failed: %w", err))
+	}
+
+	show := exec.CommandContext(ctx, terraformPath, "show", "-json", planFile.Name())
+	show.Dir = workDir
+	planJSON, err := show.Output()
+	if err != nil {
+		return fail(fmt.Errorf("terraform show failed: %w", err))
+	}
+	changes, err := parsePlanChanges(planJSON)
+	if err != nil {
+		return fail(err)
+	}
+	for _, change := range changes {
+		result.Changes = append(result.Changes, change.String())
+	}
+
+	// The plan may destroy more than the target, such as a database that depends on it
+	if force, _ := action.Parameters["force"].(bool); !force {
+		var stateful []string
+		for _, change := range changes {
+			if change.destroysData() {
+				stateful = append(stateful, change.address)
+			}
+		}
+		if len(stateful) > 0 {
+			return fail(fmt.Errorf("%w: the plan would destroy %s", ErrStatefulResource, strings.Join(stateful, ", ")))
+		}
+	}
+
+	output, err := runTerraform(ctx, terraformPath, workDir, "apply", "-input=false", planFile.Name())
+	result.Output = output
+	if err != nil {
+		return fail(fmt.Errorf("terraform apply failed: %w", err))
+	}
+	result.Status = StatusSuccess
+	result.EndTime = time.Now()
+	return result, nil
+}
+
+// runStateRemoval removes a resource from Terraform state without touching the cloud
+// resource, or returns the command for review without a working directory
+func runStateRemoval(ctx context.Context, terraformPath string, action *RemediationAction, address string) (*ActionResult, error) {
+	result := &ActionResult{
+		ActionID:   action.ID,
+		ResourceID: action.Resource,
+		Action:     string(action.Type),
+		StartTime:  time.Now(),
+		Changes:    []string{"remove " + address + " from state"},
+	}
+
+	workDir, _ := action.Parameters["work_dir"].(string)
+	if workDir == "" {
+		result.Status = StatusSuccess
+		result.Output = "Command generated for review: terraform state rm " + address
+		result.EndTime = time.Now()
+		return result, nil
+	}
+
+	output, err := runTerraform(ctx, terraformPath, workDir, "state", "rm", address)
+	result.Output = output
+	result.EndTime = time.Now()
+	if err != nil {
+		result.Status = StatusFailed
+		result.Error = fmt.Sprintf("terraform state rm failed: %v", err)
+		return result, fmt.Errorf("terraform state rm %s failed: %w", address, err)
+	}
+	result.Status = StatusSuccess
+	return result, nil
+}
+
+// runTerraform runs terraform in dir and returns its combined output. Arguments are passed
+// directly so addresses containing shell metacharacters are not interpreted.
+func runTerraform(ctx context.Context, terraformPath, dir string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, terraformPath, args...)
+	cmd.Dir = dir
+	output, err := cmd.CombinedOutput()
+	return strings.TrimSpace(string(output)), err
+}
+
+// parsePlanChanges reads the resources a saved plan changes from terraform show -json,
+// leaving out those it does not touch
+func parsePlanChanges(planJSON []byte) ([]plannedChange, error) {
+	var plan struct {
+		ResourceChanges []struct {
+			Address string `json:"address"`
+			Type    string `json:"type"`
+			Change  struct {
+				Actions []string `json:"actions"`
+			} `json:"change"`
+		} `json:"resource_changes"`
+	}
+	if err := json.Unmarshal(planJSON, &plan); err != nil {
+		return nil, fmt.Errorf("failed to read terraform plan: %w", err)
+	}
+
+	var changes []plannedChange
+	for _, rc := range plan.ResourceChanges {
+		var action string
+		switch actions := rc.Change.Actions; {
+		case slices.Contains(actions, "delete") && slices.Contains(actions, "create"):
+			action = "replace"
+		case slices.Contains(actions, "delete"):
+			action = "delete"
+		case slices.Contains(actions, "create"):
+			action = "create"
+		case slices.Contains(actions, "update"):
+			action = "update"
+		default:
+			continue
+		}
+		changes = append(changes, plannedChange{
+			address:  rc.Address,
+			action:   action,
+			stateful: IsStatefulResourceType(rc.Type),
+		})
+	}
+	return changes, nil
+}
@@ -0,0 +1,105 @@
+package remediation
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeTerraform writes a terraform stand-in that logs its arguments to terraform.log in the
+// working directory and answers show -json with planJSON
+func fakeTerraform(t *testing.T, planJSON string) (terraformPath, workDir string) {
+	t.Helper()
+	dir := t.TempDir()
+	workDir = t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "plan.json"), []byte(planJSON), 0644))
+	script := "#!/bin/sh\necho \"$@\" >> terraform.log\nif [ \"$1\" = show ]; then cat " + filepath.Join(dir, "plan.json") + "; fi\n"
+	terraformPath = filepath.Join(dir, "terraform")
+	require.NoError(t, os.WriteFile(terraformPath, []byte(script), 0755))
+	return terraformPath, workDir
+}
+
+// terraformLog returns the commands the fake terraform ran
+func terraformLog(t *testing.T, workDir string) string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(workDir, "terraform.log"))
+	if os.IsNotExist(err) {
+		return ""
+	}
+	require.NoError(t, err)
+	return string(data)
+}
+
+const replacePlanJSON = `{"resource_changes": [
+	{"address": "aws_security_group.web", "type": "aws_security_group", "change": {"actions": ["delete", "create"]}},
+	{"address": "aws_db_instance.main", "type": "aws_db_instance", "change": {"actions": ["create", "delete"]}},
+	{"address": "aws_instance.web", "type": "aws_instance", "change": {"actions": ["update"]}},
+	{"address": "aws_vpc.main", "type": "aws_vpc", "change": {"actions": ["no-op"]}}
+]}`
+
+func TestReplaceExecutor_RefusesToDestroyStatefulDependents(t *testing.T) {
+	terraformPath, workDir := fakeTerraform(t, replacePlanJSON)
+	executor := &ReplaceExecutor{terraformPath: terraformPath}
+	action := &RemediationAction{
+		ID:           "replace-sg",
+		Type:         ActionTypeReplace,
+		Resource:     "aws_security_group.web",
+		ResourceType: "aws_security_group",
+		Parameters:   map[string]interface{}{"work_dir": workDir},
+	}
+	require.NoError(t, executor.Validate(action))
+
+	result, err := executor.Execute(context.Background(), action)
+	assert.ErrorIs(t, err, ErrStatefulResource)
+	assert.Equal(t, StatusFailed, result.Status)
+	assert.Contains(t, result.Error, "aws_db_instance.main")
+	assert.Equal(t, []string{
+		"replace aws_security_group.web",
+		"replace aws_db_instance.main (stateful)",
+		"update aws_instance.web",
+	}, result.Changes)
+	assert.Contains(t, terraformLog(t, workDir), "-replace=aws_security_group.web")
+	assert.NotContains(t, terraformLog(t, workDir), "apply")
+
+	action.Parameters["force"] = true
+	result, err = executor.Execute(context.Background(), action)
+	require.NoError(t, err)
+	assert.Equal(t, StatusSuccess, result.Status)
+	assert.Contains(t, terraformLog(t, workDir), "apply -input=false")
+}
+
+func TestDestructiveExecutors_ValidateStatefulTargets(t *testing.T) {
+	action := &RemediationAction{ID: "delete-db", Type: ActionTypeDelete, Resource: "aws_db_instance.main", ResourceType: "aws_db_instance"}
+	assert.ErrorIs(t, NewDeleteExecutor().Validate(action), ErrStatefulResource)
+
+	action.Parameters = map[string]interface{}{"force": true}
+	assert.NoError(t, NewDeleteExecutor().Validate(action))
+
+	// Removing an orphan from state destroys nothing
+	orphan := &RemediationAction{ID: "rm-db", Type: ActionTypeDelete, Resource: "aws_db_instance.old", ResourceType: "aws_db_instance",
+		Parameters: map[string]interface{}{"state_only": true}}
+	assert.NoError(t, NewDeleteExecutor().Validate(orphan))
+
+	replace := &RemediationAction{ID: "replace-db", Type: ActionTypeDelete, Resource: "aws_db_instance.main"}
+	assert.Error(t, NewReplaceExecutor().Validate(replace), "the replace executor only handles replace actions")
+}
+
+func TestDeleteExecutor_StateOnlyRemovesFromState(t *testing.T) {
+	terraformPath, workDir := fakeTerraform(t, `{}`)
+	executor := &DeleteExecutor{terraformPath: terraformPath}
+	action := &RemediationAction{
+		ID:         "rm-bucket",
+		Type:       ActionTypeDelete,
+		Resource:   "aws_s3_bucket.logs",
+		Parameters: map[string]interface{}{"work_dir": workDir, "state_only": true, "resource_address": "aws_s3_bucket.logs"},
+	}
+
+	result, err := executor.Execute(context.Background(), action)
+	require.NoError(t, err)
+	assert.Equal(t, StatusSuccess, result.Status)
+	assert.Equal(t, "state rm aws_s3_bucket.logs\n", terraformLog(t, workDir))
+}
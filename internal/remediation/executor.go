@@ -77,7 +77,7 @@ func NewRemediationExecutor(stateManager *state.StateManager, workDir string) *R
 	return &RemediationExecutor{
 		stateManager:   stateManager,
 		backupManager:  state.NewBackupManager(filepath.Join(workDir, "backups")),
-		terraformPath:  "terraform",
+		terraformPath:  state.TerraformBinary(),
 		workDir:        workDir,
 		parallelism:    1,
 		timeout:        30 * time.Minute,
@@ -98,6 +98,16 @@ func (re *RemediationExecutor) Execute(ctx context.Context, plan *RemediationPla
 		ActionsTotal: len(plan.Actions),
 	}
 
+	// Fail before touching state if terraform cannot be run at all
+	if !re.dryRun {
+		if err := re.checkTerraform(); err != nil {
+			result.Status = StatusFailed
+			result.Error = err.Error()
+			result.EndTime = time.Now()
+			return result, err
+		}
+	}
+
 	// Create backup before execution
 	backupID := fmt.Sprintf("backup-%s-%d", plan.ID, time.Now().Unix())
 	if err := re.createBackup(ctx, backupID); err != nil {
@@ -199,6 +209,14 @@ func (re *RemediationExecutor) Execute(ctx context.Context, plan *RemediationPla
 	return result, nil
 }
 
+// checkTerraform verifies the configured terraform binary can be found
+func (re *RemediationExecutor) checkTerraform() error {
+	if _, err := exec.LookPath(re.terraformPath); err != nil {
+		return fmt.Errorf("terraform binary %q not found; set %s to its path: %w", re.terraformPath, state.TerraformBinaryEnv, err)
+	}
+	return nil
+}
+
 func (re *RemediationExecutor) executeAction(ctx context.Context, action *RemediationAction) ActionResult {
 	result := ActionResult{
 		ActionID:   action.ID,
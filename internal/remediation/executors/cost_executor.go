@@ -119,8 +119,11 @@ func (ce *CostExecutor) resizeInstance(ctx context.Context, action *remediation.
 	changeDesc := fmt.Sprintf("Resized instance to %s", newInstanceType)
 	result.Changes = append(result.Changes, changeDesc)
 
-	// Calculate estimated cost savings (this would be more sophisticated in real implementation)
+	// Use the savings a rightsizing recommendation estimated, falling back to a flat figure
 	estimatedSavings := 50.0 // $50/month estimated savings
+	if savings, ok := action.Parameters["estimated_savings"].(float64); ok {
+		estimatedSavings = savings
+	}
 
 	result.Output = fmt.Sprintf("Resized instance %s to %s (estimated savings: $%.2f/month)", action.Resource, newInstanceType, estimatedSavings)
 
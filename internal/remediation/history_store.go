@@ -0,0 +1,240 @@
+package remediation
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SQLiteDriver is the database/sql driver name remediation databases are opened with. The
+// binary must link a driver registered under this name, such as github.com/mattn/go-sqlite3.
+const SQLiteDriver = "sqlite3"
+
+// historySchema creates the remediation history table and the indexes its filters use
+const historySchema = `
+CREATE TABLE IF NOT EXISTS remediation_history (
+	id        TEXT PRIMARY KEY,
+	drift_id  TEXT NOT NULL,
+	strategy  TEXT NOT NULL DEFAULT '',
+	status    TEXT NOT NULL,
+	provider  TEXT NOT NULL DEFAULT '',
+	region    TEXT NOT NULL DEFAULT '',
+	severity  INTEGER NOT NULL DEFAULT 0,
+	timestamp TIMESTAMP NOT NULL,
+	details   TEXT
+);
+CREATE INDEX IF NOT EXISTS idx_remediation_history_timestamp ON remediation_history (timestamp);
+CREATE INDEX IF NOT EXISTS idx_remediation_history_drift_id ON remediation_history (drift_id);`
+
+// historySortColumns are the columns history can be sorted by
+var historySortColumns = map[string]string{
+	"":          "timestamp",
+	"timestamp": "timestamp",
+	"status":    "status",
+	"severity":  "severity",
+}
+
+// HistoryEntry records the outcome of remediating one drift
+type HistoryEntry struct {
+	ID        string                 `json:"id"`
+	DriftID   string                 `json:"drift_id"`
+	Strategy  string                 `json:"strategy"`
+	Status    string                 `json:"status"`
+	Provider  string                 `json:"provider,omitempty"`
+	Region    string                 `json:"region,omitempty"`
+	Severity  int                    `json:"severity"` // drift severity, 0 (low) to 3 (critical)
+	Timestamp time.Time              `json:"timestamp"`
+	Details   map[string]interface{} `json:"details,omitempty"`
+}
+
+// HistoryFilter selects and orders history entries. Zero fields do not filter; Limit 0
+// returns every matching entry. Entries are sorted by SortBy (timestamp, status or severity),
+// descending unless Ascending is set, with ties broken newest first.
+type HistoryFilter struct {
+	DriftID   string
+	Strategy  string
+	Status    string
+	Provider  string
+	Region    string
+	StartTime time.Time
+	EndTime   time.Time
+	Limit     int
+	Offset    int
+	SortBy    string
+	Ascending bool
+}
+
+// HistoryStore persists remediation history in a SQL database so it survives restarts
+type HistoryStore struct {
+	db *sql.DB
+}
+
+// OpenSQLiteDB opens, creating if needed, the SQLite database at path that remediation
+// history and approvals are stored in
+func OpenSQLiteDB(path string) (*sql.DB, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, fmt.Errorf("failed to create database directory: %w", err)
+	}
+	db, err := sql.Open(SQLiteDriver, path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+	// SQLite allows a single writer
+	db.SetMaxOpenConns(1)
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+	return db, nil
+}
+
+// NewHistoryStore creates a history store on an open database, creating its table
+func NewHistoryStore(db *sql.DB) (*HistoryStore, error) {
+	if _, err := db.Exec(historySchema); err != nil {
+		return nil, fmt.Errorf("failed to create remediation history table: %w", err)
+	}
+	return &HistoryStore{db: db}, nil
+}
+
+// Record stores a history entry, assigning an ID and timestamp when they are unset
+func (s *HistoryStore) Record(ctx context.Context, entry *HistoryEntry) error {
+	if entry.ID == "" {
+		entry.ID = fmt.Sprintf("remediation-%s", uuid.New().String())
+	}
+	if entry.Timestamp.IsZero() {
+		entry.Timestamp = time.Now().UTC()
+	}
+
+	var details []byte
+	if len(entry.Details) > 0 {
+		var err error
+		if details, err = json.Marshal(entry.Details); err != nil {
+			return fmt.Errorf("failed to marshal remediation details: %w", err)
+		}
+	}
+
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO remediation_history (id, drift_id, strategy, status, provider, region, severity, timestamp, details)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		entry.ID, entry.DriftID, entry.Strategy, entry.Status, entry.Provider, entry.Region,
+		entry.Severity, entry.Timestamp.UTC(), string(details))
+	if err != nil {
+		return fmt.Errorf("failed to record remediation history: %w", err)
+	}
+	return nil
+}
+
+// Query returns the page of entries matching the filter in the filter's order
+func (s *HistoryStore) Query(ctx context.Context, filter HistoryFilter) ([]HistoryEntry, error) {
+	orderBy, err := filter.orderBy()
+	if err != nil {
+		return nil, err
+	}
+	where, args := filter.where()
+	query := `SELECT id, drift_id, strategy, status, provider, region, severity, timestamp, details FROM remediation_history` +
+		where + orderBy
+	switch {
+	case filter.Limit > 0:
+		query += ` LIMIT ? OFFSET ?`
+		args = append(args, filter.Limit, filter.Offset)
+	case filter.Offset > 0:
+		// SQLite only accepts OFFSET after a LIMIT; -1 means no limit
+		query += ` LIMIT -1 OFFSET ?`
+		args = append(args, filter.Offset)
+	}
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query remediation history: %w", err)
+	}
+	defer rows.Close()
+
+	entries := []HistoryEntry{}
+	for rows.Next() {
+		var entry HistoryEntry
+		var details sql.NullString
+		if err := rows.Scan(&entry.ID, &entry.DriftID, &entry.Strategy, &entry.Status,
+			&entry.Provider, &entry.Region, &entry.Severity, &entry.Timestamp, &details); err != nil {
+			return nil, fmt.Errorf("failed to read remediation history: %w", err)
+		}
+		if details.Valid && details.String != "" {
+			if err := json.Unmarshal([]byte(details.String), &entry.Details); err != nil {
+				return nil, fmt.Errorf("failed to parse details of %s: %w", entry.ID, err)
+			}
+		}
+		entries = append(entries, entry)
+	}
+	return entries, rows.Err()
+}
+
+// Count returns how many entries match the filter, ignoring its limit and offset
+func (s *HistoryStore) Count(ctx context.Context, filter HistoryFilter) (int, error) {
+	where, args := filter.where()
+	var count int
+	if err := s.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM remediation_history`+where, args...).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count remediation history: %w", err)
+	}
+	return count, nil
+}
+
+// Close closes the underlying database
+func (s *HistoryStore) Close() error {
+	return s.db.Close()
+}
+
+// orderBy translates the filter's sort into an ORDER BY clause
+func (f HistoryFilter) orderBy() (string, error) {
+	column, ok := historySortColumns[f.SortBy]
+	if !ok {
+		return "", fmt.Errorf("cannot sort remediation history by %q", f.SortBy)
+	}
+	direction := "DESC"
+	if f.Ascending {
+		direction = "ASC"
+	}
+	if column == "timestamp" {
+		return " ORDER BY timestamp " + direction, nil
+	}
+	return " ORDER BY " + column + " " + direction + ", timestamp DESC", nil
+}
+
+// where translates the filter into a WHERE clause and its arguments
+func (f HistoryFilter) where() (string, []interface{}) {
+	var conditions []string
+	var args []interface{}
+	for _, column := range []struct {
+		name  string
+		value string
+	}{
+		{"drift_id", f.DriftID},
+		{"strategy", f.Strategy},
+		{"status", f.Status},
+		{"provider", f.Provider},
+		{"region", f.Region},
+	} {
+		if column.value != "" {
+			conditions = append(conditions, column.name+" = ?")
+			args = append(args, column.value)
+		}
+	}
+	if !f.StartTime.IsZero() {
+		conditions = append(conditions, "timestamp >= ?")
+		args = append(args, f.StartTime.UTC())
+	}
+	if !f.EndTime.IsZero() {
+		conditions = append(conditions, "timestamp <= ?")
+		args = append(args, f.EndTime.UTC())
+	}
+
+	if len(conditions) == 0 {
+		return "", nil
+	}
+	return " WHERE " + strings.Join(conditions, " AND "), args
+}
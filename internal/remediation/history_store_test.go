@@ -0,0 +1,70 @@
+package remediation
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestHistoryFilterWhere(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := start.Add(24 * time.Hour)
+
+	tests := []struct {
+		name   string
+		filter HistoryFilter
+		where  string
+		args   []interface{}
+	}{
+		{
+			name: "no filters",
+		},
+		{
+			name:   "provider and status",
+			filter: HistoryFilter{Provider: "aws", Status: "failed"},
+			where:  " WHERE status = ? AND provider = ?",
+			args:   []interface{}{"failed", "aws"},
+		},
+		{
+			name:   "date range",
+			filter: HistoryFilter{DriftID: "drift-1", StartTime: start, EndTime: end},
+			where:  " WHERE drift_id = ? AND timestamp >= ? AND timestamp <= ?",
+			args:   []interface{}{"drift-1", start, end},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			where, args := tt.filter.where()
+			if where != tt.where {
+				t.Errorf("expected %q, got %q", tt.where, where)
+			}
+			if !reflect.DeepEqual(args, tt.args) {
+				t.Errorf("expected args %v, got %v", tt.args, args)
+			}
+		})
+	}
+}
+
+func TestHistoryFilterOrderBy(t *testing.T) {
+	tests := []struct {
+		filter  HistoryFilter
+		orderBy string
+		wantErr bool
+	}{
+		{filter: HistoryFilter{}, orderBy: " ORDER BY timestamp DESC"},
+		{filter: HistoryFilter{SortBy: "timestamp", Ascending: true}, orderBy: " ORDER BY timestamp ASC"},
+		{filter: HistoryFilter{SortBy: "severity"}, orderBy: " ORDER BY severity DESC, timestamp DESC"},
+		{filter: HistoryFilter{SortBy: "details; DROP TABLE remediation_history"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		orderBy, err := tt.filter.orderBy()
+		if (err != nil) != tt.wantErr {
+			t.Fatalf("sort %q: expected error %v, got %v", tt.filter.SortBy, tt.wantErr, err)
+		}
+		if orderBy != tt.orderBy {
+			t.Errorf("sort %q: expected %q, got %q", tt.filter.SortBy, tt.orderBy, orderBy)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package remediation
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/catherinevee/driftmgr/internal/drift/detector"
+	"github.com/catherinevee/driftmgr/internal/remediation/tfimport"
+	"github.com/catherinevee/driftmgr/internal/state"
+	"github.com/catherinevee/driftmgr/pkg/models"
+)
+
+// ImportExecutor reconciles unmanaged resources by generating terraform import commands.
+// Commands are only run when the action names a working directory; otherwise they are
+// returned for review.
+type ImportExecutor struct {
+	generator     *tfimport.ImportGenerator
+	terraformPath string
+}
+
+// NewImportExecutor creates a new import executor
+func NewImportExecutor() *ImportExecutor {
+	return &ImportExecutor{
+		generator:     tfimport.NewImportGenerator(),
+		terraformPath: state.TerraformBinary(),
+	}
+}
+
+// GetType returns the action type handled by this executor
+func (e *ImportExecutor) GetType() string {
+	return string(ActionTypeImport)
+}
+
+// GetDescription returns a human-readable description
+func (e *ImportExecutor) GetDescription() string {
+	return "Generates and optionally runs terraform import commands for unmanaged resources"
+}
+
+// Validate checks that the action carries an import address and ID
+func (e *ImportExecutor) Validate(action *RemediationAction) error {
+	if action.Type != ActionTypeImport {
+		return fmt.Errorf("import executor cannot handle action type %s", action.Type)
+	}
+	if address, _ := action.Parameters["resource_address"].(string); address == "" {
+		return fmt.Errorf("import action %s has no resource address", action.ID)
+	}
+	if id, _ := action.Parameters["resource_id"].(string); id == "" {
+		return fmt.Errorf("import action %s has no resource ID", action.ID)
+	}
+	if workDir, _ := action.Parameters["work_dir"].(string); workDir != "" {
+		info, err := os.Stat(workDir)
+		if err != nil || !info.IsDir() {
+			return fmt.Errorf("working directory %s does not exist", workDir)
+		}
+		if _, err := exec.LookPath(e.terraformPath); err != nil {
+			return fmt.Errorf("terraform binary %q not found; set %s to its path: %w", e.terraformPath, state.TerraformBinaryEnv, err)
+		}
+	}
+	return nil
+}
+
+// Execute returns the import command and runs it when a working directory is set
+func (e *ImportExecutor) Execute(ctx context.Context, action *RemediationAction) (*ActionResult, error) {
+	result := &ActionResult{
+		ActionID:   action.ID,
+		ResourceID: action.Resource,
+		Action:     string(action.Type),
+		StartTime:  time.Now(),
+		Changes:    []string{action.Command},
+	}
+
+	workDir, _ := action.Parameters["work_dir"].(string)
+	if workDir == "" {
+		result.Status = StatusSuccess
+		result.Output = "Import command generated for review: " + action.Command
+		result.EndTime = time.Now()
+		return result, nil
+	}
+
+	address := action.Parameters["resource_address"].(string)
+	resourceID := action.Parameters["resource_id"].(string)
+
+	// Pass arguments directly so IDs containing shell metacharacters are not interpreted
+	cmd := exec.CommandContext(ctx, e.terraformPath, "import", "-input=false", address, resourceID)
+	cmd.Dir = workDir
+	output, err := cmd.CombinedOutput()
+	result.Output = strings.TrimSpace(string(output))
+	result.EndTime = time.Now()
+	if err != nil {
+		result.Status = StatusFailed
+		result.Error = fmt.Sprintf("terraform import failed: %v", err)
+		return result, fmt.Errorf("terraform import %s failed: %w", address, err)
+	}
+
+	result.Status = StatusSuccess
+	return result, nil
+}
+
+// ImportCommandForDrift builds the terraform import command for an unmanaged resource
+func ImportCommandForDrift(generator *tfimport.ImportGenerator, driftResult *detector.DriftResult) (*tfimport.ImportCommand, error) {
+	resource := models.Resource{
+		ID:         driftResult.Resource,
+		Name:       driftResult.Resource,
+		Type:       driftResult.ResourceType,
+		Provider:   driftResult.Provider,
+		Attributes: driftResult.ActualState,
+		Properties: driftResult.ActualState,
+	}
+
+	// Prefer the cloud-side identifiers when the drift recorded them
+	if id, ok := driftResult.ActualState["id"].(string); ok && id != "" {
+		resource.ID = id
+	}
+	if name, ok := driftResult.ActualState["name"].(string); ok && name != "" {
+		resource.Name = name
+	}
+	if region, ok := driftResult.ActualState["region"].(string); ok {
+		resource.Region = region
+	}
+
+	return generator.GenerateImportCommand(resource)
+}
@@ -3,11 +3,13 @@ package remediation
 import (
 	"context"
 	"fmt"
+	"log"
 	"sync"
 	"time"
 
 	"github.com/catherinevee/driftmgr/internal/drift/detector"
 	"github.com/catherinevee/driftmgr/internal/graph"
+	"github.com/catherinevee/driftmgr/internal/remediation/tfimport"
 	"github.com/catherinevee/driftmgr/internal/shared/events"
 	"github.com/catherinevee/driftmgr/internal/state"
 	"github.com/catherinevee/driftmgr/pkg/models"
@@ -23,6 +25,8 @@ type IntelligentRemediationService struct {
 	stopChan  chan struct{}
 	running   bool
 	auditLog  []AuditEntry
+	// confirmation issues the preview tokens batch remediations must carry
+	confirmation *confirmationSigner
 }
 
 // ActionExecutor interface for executing remediation actions
@@ -54,6 +58,12 @@ type AuditEntry struct {
 	Details   map[string]interface{} `json:"details,omitempty"`
 }
 
+// ExecuteOptions controls how a remediation plan is executed
+type ExecuteOptions struct {
+	// DryRun logs the intended changes without invoking any executor
+	DryRun bool `json:"dry_run"`
+}
+
 // ResourceChange represents a change made to a resource
 type ResourceChange struct {
 	ResourceID string                 `json:"resource_id"`
@@ -78,6 +88,8 @@ func NewIntelligentRemediationService(eventBus events.EventBusInterface) *Intell
 		interval:  10 * time.Minute,
 		stopChan:  make(chan struct{}),
 		auditLog:  make([]AuditEntry, 0),
+
+		confirmation: newConfirmationSigner(),
 	}
 
 	// Register default executors
@@ -88,8 +100,9 @@ func NewIntelligentRemediationService(eventBus events.EventBusInterface) *Intell
 
 // registerDefaultExecutors registers default action executors
 func (irs *IntelligentRemediationService) registerDefaultExecutors() {
-	// In a real implementation, you would register actual executors
-	// For now, this is a placeholder
+	irs.executors[string(ActionTypeImport)] = NewImportExecutor()
+	irs.executors[string(ActionTypeReplace)] = NewReplaceExecutor()
+	irs.executors[string(ActionTypeDelete)] = NewDeleteExecutor()
 }
 
 // RegisterExecutor registers an action executor
@@ -115,7 +128,23 @@ func (irs *IntelligentRemediationService) GeneratePlan(ctx context.Context, drif
 	}
 
 	// Add action based on drift type
-	if driftResult.DriftType == detector.ConfigurationDrift {
+	if driftResult.DriftType == detector.ConfigurationDrift && irs.planner.shouldReplace(*driftResult) {
+		// A change to a field that cannot be updated in place recreates the resource
+		action := RemediationAction{
+			ID:           fmt.Sprintf("action-%s-%d", driftResult.Resource, time.Now().UnixNano()),
+			Type:         ActionTypeReplace,
+			Resource:     driftResult.Resource,
+			ResourceType: driftResult.ResourceType,
+			Provider:     driftResult.Provider,
+			Description:  fmt.Sprintf("Replace %s with a new resource", driftResult.Resource),
+			Command:      fmt.Sprintf("terraform apply -replace=%s", driftResult.Resource),
+			Parameters: map[string]interface{}{
+				"resource_address": driftResult.Resource,
+			},
+		}
+		plan.Actions = append(plan.Actions, action)
+		plan.RiskLevel = RiskLevelHigh
+	} else if driftResult.DriftType == detector.ConfigurationDrift {
 		action := RemediationAction{
 			ID:           fmt.Sprintf("action-%s-%d", driftResult.Resource, time.Now().UnixNano()),
 			Type:         ActionTypeUpdate,
@@ -138,6 +167,11 @@ func (irs *IntelligentRemediationService) GeneratePlan(ctx context.Context, drif
 		}
 		plan.Actions = append(plan.Actions, action)
 	} else if driftResult.DriftType == detector.ResourceUnmanaged {
+		importCmd, err := ImportCommandForDrift(tfimport.NewImportGenerator(), driftResult)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate import command for %s: %w", driftResult.Resource, err)
+		}
+
 		action := RemediationAction{
 			ID:           fmt.Sprintf("action-%s-%d", driftResult.Resource, time.Now().UnixNano()),
 			Type:         ActionTypeImport,
@@ -145,7 +179,11 @@ func (irs *IntelligentRemediationService) GeneratePlan(ctx context.Context, drif
 			ResourceType: driftResult.ResourceType,
 			Provider:     driftResult.Provider,
 			Description:  fmt.Sprintf("Import unmanaged resource %s", driftResult.Resource),
-			Parameters:   make(map[string]interface{}),
+			Command:      importCmd.Command,
+			Parameters: map[string]interface{}{
+				"resource_address": importCmd.Address,
+				"resource_id":      importCmd.ResourceID,
+			},
 		}
 		plan.Actions = append(plan.Actions, action)
 	}
@@ -153,6 +191,23 @@ func (irs *IntelligentRemediationService) GeneratePlan(ctx context.Context, drif
 	return plan, nil
 }
 
+// PreviewPlan builds an ordered remediation plan for a set of drift results without executing it
+func (irs *IntelligentRemediationService) PreviewPlan(ctx context.Context, driftResults []detector.DriftResult) (*RemediationPlan, error) {
+	driftReport := &detector.DriftReport{
+		Timestamp:        time.Now(),
+		TotalResources:   len(driftResults),
+		DriftedResources: len(driftResults),
+		DriftResults:     driftResults,
+	}
+
+	plan, err := irs.planner.CreatePlan(ctx, driftReport, &state.TerraformState{Version: 4})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create remediation plan: %w", err)
+	}
+
+	return plan, nil
+}
+
 // GetPlan retrieves a remediation plan by ID
 func (irs *IntelligentRemediationService) GetPlan(ctx context.Context, planID string) (*RemediationPlan, error) {
 	// In a real implementation, this would fetch from storage
@@ -232,6 +287,15 @@ func (irs *IntelligentRemediationService) AnalyzeAndRemediate(ctx context.Contex
 
 // ExecutePlan executes a remediation plan
 func (irs *IntelligentRemediationService) ExecutePlan(ctx context.Context, plan *RemediationPlan) ([]*ActionResult, error) {
+	return irs.ExecutePlanWithOptions(ctx, plan, ExecuteOptions{})
+}
+
+// ExecutePlanWithOptions executes a remediation plan using the given options
+func (irs *IntelligentRemediationService) ExecutePlanWithOptions(ctx context.Context, plan *RemediationPlan, opts ExecuteOptions) ([]*ActionResult, error) {
+	if opts.DryRun {
+		return irs.dryRunPlan(plan), nil
+	}
+
 	var results []*ActionResult
 	var wg sync.WaitGroup
 	var mu sync.Mutex
@@ -269,6 +333,27 @@ func (irs *IntelligentRemediationService) ExecutePlan(ctx context.Context, plan
 	return results, nil
 }
 
+// dryRunPlan logs each action in a plan and reports it without calling any executor
+func (irs *IntelligentRemediationService) dryRunPlan(plan *RemediationPlan) []*ActionResult {
+	results := make([]*ActionResult, 0, len(plan.Actions))
+	for _, action := range plan.Actions {
+		output := fmt.Sprintf("[DRY RUN] Would execute: %s on %s", action.Type, action.Resource)
+		log.Printf("remediation plan %s: %s", plan.ID, output)
+
+		now := time.Now()
+		results = append(results, &ActionResult{
+			ActionID:   action.ID,
+			ResourceID: action.Resource,
+			Action:     string(action.Type),
+			Status:     StatusSuccess,
+			StartTime:  now,
+			EndTime:    now,
+			Output:     output,
+		})
+	}
+	return results
+}
+
 // ExecuteAction executes a single remediation action
 func (irs *IntelligentRemediationService) ExecuteAction(ctx context.Context, action *RemediationAction) (*ActionResult, error) {
 	irs.mu.Lock()
@@ -408,6 +493,17 @@ func (irs *IntelligentRemediationService) performPeriodicRemediation(ctx context
 	fmt.Println("Performing periodic intelligent remediation checks...")
 }
 
+// SetConfirmationKey keys batch preview tokens with a secret, so they survive restarts and are
+// accepted by every server sharing it. An empty secret keeps the current key.
+func (irs *IntelligentRemediationService) SetConfirmationKey(secret string) {
+	if secret == "" {
+		return
+	}
+	irs.mu.Lock()
+	defer irs.mu.Unlock()
+	irs.confirmation.key = confirmationKey(secret)
+}
+
 // SetMonitoringInterval sets the monitoring interval
 func (irs *IntelligentRemediationService) SetMonitoringInterval(interval time.Duration) {
 	irs.mu.Lock()
@@ -0,0 +1,67 @@
+package remediation
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countingExecutor records the actions it executes
+type countingExecutor struct {
+	mu       sync.Mutex
+	executed []string
+}
+
+func (e *countingExecutor) Execute(ctx context.Context, action *RemediationAction) (*ActionResult, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.executed = append(e.executed, action.ID)
+	return &ActionResult{ActionID: action.ID, ResourceID: action.Resource, Action: string(action.Type), Status: StatusSuccess}, nil
+}
+
+func (e *countingExecutor) GetType() string                          { return string(ActionTypeUpdate) }
+func (e *countingExecutor) GetDescription() string                   { return "counts executions" }
+func (e *countingExecutor) Validate(action *RemediationAction) error { return nil }
+
+func testPlan() *RemediationPlan {
+	return &RemediationPlan{
+		ID: "plan-1",
+		Actions: []RemediationAction{
+			{ID: "action-1", Type: ActionTypeUpdate, Resource: "i-123"},
+			{ID: "action-2", Type: ActionTypeUpdate, Resource: "i-456"},
+		},
+	}
+}
+
+func TestExecutePlanWithOptions_DryRunNeverExecutes(t *testing.T) {
+	service := NewIntelligentRemediationService(nil)
+	executor := &countingExecutor{}
+	service.RegisterExecutor(executor)
+
+	results, err := service.ExecutePlanWithOptions(context.Background(), testPlan(), ExecuteOptions{DryRun: true})
+	require.NoError(t, err)
+
+	assert.Empty(t, executor.executed)
+	require.Len(t, results, 2)
+	for _, result := range results {
+		assert.Equal(t, StatusSuccess, result.Status)
+		assert.True(t, strings.HasPrefix(result.Output, "[DRY RUN]"), result.Output)
+	}
+	assert.Equal(t, "i-123", results[0].ResourceID)
+}
+
+func TestExecutePlanWithOptions_Executes(t *testing.T) {
+	service := NewIntelligentRemediationService(nil)
+	executor := &countingExecutor{}
+	service.RegisterExecutor(executor)
+
+	results, err := service.ExecutePlanWithOptions(context.Background(), testPlan(), ExecuteOptions{})
+	require.NoError(t, err)
+
+	assert.Len(t, results, 2)
+	assert.ElementsMatch(t, []string{"action-1", "action-2"}, executor.executed)
+}
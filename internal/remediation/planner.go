@@ -8,16 +8,18 @@ import (
 
 	"github.com/catherinevee/driftmgr/internal/drift/detector"
 	"github.com/catherinevee/driftmgr/internal/graph"
+	"github.com/catherinevee/driftmgr/internal/remediation/tfimport"
 	"github.com/catherinevee/driftmgr/internal/state"
 	"github.com/google/uuid"
 )
 
 // RemediationPlanner creates remediation plans for drift
 type RemediationPlanner struct {
-	validator    *ActionValidator
-	riskAnalyzer *RiskAnalyzer
-	depGraph     *graph.DependencyGraph
-	config       *PlannerConfig
+	validator       *ActionValidator
+	riskAnalyzer    *RiskAnalyzer
+	depGraph        *graph.DependencyGraph
+	config          *PlannerConfig
+	importGenerator *tfimport.ImportGenerator
 }
 
 // PlannerConfig contains configuration for the planner
@@ -92,6 +94,22 @@ const (
 	RiskLevelCritical
 )
 
+// String returns the name of the risk level
+func (r RiskLevel) String() string {
+	switch r {
+	case RiskLevelLow:
+		return "low"
+	case RiskLevelMedium:
+		return "medium"
+	case RiskLevelHigh:
+		return "high"
+	case RiskLevelCritical:
+		return "critical"
+	default:
+		return "unknown"
+	}
+}
+
 // PreCheck defines a check to run before an action
 type PreCheck struct {
 	Name        string `json:"name"`
@@ -128,9 +146,10 @@ type RollbackAction struct {
 // NewRemediationPlanner creates a new remediation planner
 func NewRemediationPlanner(depGraph *graph.DependencyGraph) *RemediationPlanner {
 	return &RemediationPlanner{
-		validator:    NewActionValidator(),
-		riskAnalyzer: NewRiskAnalyzer(),
-		depGraph:     depGraph,
+		validator:       NewActionValidator(),
+		riskAnalyzer:    NewRiskAnalyzer(),
+		depGraph:        depGraph,
+		importGenerator: tfimport.NewImportGenerator(),
 		config: &PlannerConfig{
 			AutoApprove:        false,
 			MaxParallelActions: 5,
@@ -253,6 +272,15 @@ func (rp *RemediationPlanner) createImportAction(drift detector.DriftResult) Rem
 			resourceID = id
 		}
 	}
+	address := drift.Resource
+	command := fmt.Sprintf("terraform import %s %s", drift.Resource, resourceID)
+
+	// Use the provider-specific address and ID format when the resource type is known
+	if importCmd, err := ImportCommandForDrift(rp.importGenerator, &drift); err == nil {
+		address = importCmd.Address
+		resourceID = importCmd.ResourceID
+		command = importCmd.Command
+	}
 
 	return RemediationAction{
 		ID:           uuid.New().String(),
@@ -261,9 +289,9 @@ func (rp *RemediationPlanner) createImportAction(drift detector.DriftResult) Rem
 		ResourceType: drift.ResourceType,
 		Provider:     drift.Provider,
 		Description:  fmt.Sprintf("Import %s into Terraform state", drift.Resource),
-		Command:      fmt.Sprintf("terraform import %s %s", drift.Resource, resourceID),
+		Command:      command,
 		Parameters: map[string]interface{}{
-			"resource_address": drift.Resource,
+			"resource_address": address,
 			"resource_id":      resourceID,
 		},
 		PreChecks: []PreCheck{
@@ -436,6 +464,7 @@ func (rp *RemediationPlanner) createRemoveFromStateAction(drift detector.DriftRe
 		Command:      fmt.Sprintf("terraform state rm %s", drift.Resource),
 		Parameters: map[string]interface{}{
 			"resource_address": drift.Resource,
+			"state_only":       true,
 		},
 		PreChecks: []PreCheck{
 			{
@@ -0,0 +1,363 @@
+package remediation
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/catherinevee/driftmgr/internal/drift/detector"
+	"github.com/google/uuid"
+)
+
+// ErrPolicyNotFound is returned for an unknown remediation policy ID
+var ErrPolicyNotFound = errors.New("remediation policy not found")
+
+// PolicyAction is what a remediation policy does with the drift it selects
+type PolicyAction string
+
+const (
+	PolicyRemediate PolicyAction = "remediate" // remediate with the policy's strategy
+	PolicyNotify    PolicyAction = "notify"    // alert without changing anything
+	PolicyIgnore    PolicyAction = "ignore"    // neither remediate nor alert
+)
+
+// severityNames are the names policies use for drift severities
+var severityNames = map[string]detector.DriftSeverity{
+	"low":      detector.SeverityLow,
+	"medium":   detector.SeverityMedium,
+	"high":     detector.SeverityHigh,
+	"critical": detector.SeverityCritical,
+}
+
+// policyStrategies are the remediation action types a policy may remediate with
+var policyStrategies = map[ActionType]bool{
+	ActionTypeImport:  true,
+	ActionTypeUpdate:  true,
+	ActionTypeDelete:  true,
+	ActionTypeCreate:  true,
+	ActionTypeRefresh: true,
+	ActionTypeMove:    true,
+	ActionTypeReplace: true,
+	ActionTypeTaint:   true,
+	ActionTypeUntaint: true,
+}
+
+// policySchema creates the remediation policies table
+const policySchema = `
+CREATE TABLE IF NOT EXISTS remediation_policies (
+	id                      TEXT PRIMARY KEY,
+	name                    TEXT NOT NULL,
+	priority                INTEGER NOT NULL DEFAULT 0,
+	provider                TEXT NOT NULL DEFAULT '',
+	resource_type           TEXT NOT NULL DEFAULT '',
+	field                   TEXT NOT NULL DEFAULT '',
+	severity                TEXT NOT NULL DEFAULT '',
+	action                  TEXT NOT NULL,
+	strategy                TEXT NOT NULL DEFAULT '',
+	auto_apply              BOOLEAN NOT NULL DEFAULT 0,
+	max_auto_apply_severity TEXT NOT NULL DEFAULT '',
+	work_dir                TEXT NOT NULL DEFAULT '',
+	created_by              TEXT NOT NULL DEFAULT '',
+	created_at              TIMESTAMP NOT NULL,
+	updated_at              TIMESTAMP NOT NULL
+);`
+
+// PolicySelector selects the drift a policy applies to. Empty fields select any drift.
+// ResourceType and Field are glob patterns, such as aws_s3_* or tags.*; Field also selects
+// the fields nested under it, so tags selects tags.Owner.
+type PolicySelector struct {
+	Provider     string `json:"provider,omitempty"`
+	ResourceType string `json:"resource_type,omitempty"`
+	Field        string `json:"field,omitempty"`    // selects drift with any matching field
+	Severity     string `json:"severity,omitempty"` // low, medium, high or critical
+}
+
+// RemediationPolicy declares what to do with the drift its selector selects: remediate it
+// with a strategy, only notify, or ignore it. Remediation runs unattended only when AutoApply
+// is set and the drift is no more severe than MaxAutoApplySeverity; other remediation is
+// notified for a person to apply.
+type RemediationPolicy struct {
+	ID       string         `json:"id"`
+	Name     string         `json:"name"`
+	Priority int            `json:"priority"` // lower priorities are evaluated first
+	Selector PolicySelector `json:"selector"`
+	Action   PolicyAction   `json:"action"`
+
+	// Strategy is the remediation action type, such as update or refresh, that remediation
+	// plans for the drift must consist of; required to remediate
+	Strategy ActionType `json:"strategy,omitempty"`
+
+	AutoApply            bool   `json:"auto_apply,omitempty"`
+	MaxAutoApplySeverity string `json:"max_auto_apply_severity,omitempty"` // low when empty
+
+	// WorkDir is the Terraform working directory remediation commands run in; without it
+	// the commands are recorded for review instead of run
+	WorkDir string `json:"work_dir,omitempty"`
+
+	CreatedBy string    `json:"created_by,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Validate checks a policy's action, strategy, severities and selector patterns
+func (p *RemediationPolicy) Validate() error {
+	if strings.TrimSpace(p.Name) == "" {
+		return errors.New("name is required")
+	}
+	switch p.Action {
+	case PolicyRemediate:
+		if p.Strategy == "" {
+			return errors.New("strategy is required to remediate")
+		}
+		if !policyStrategies[p.Strategy] {
+			return fmt.Errorf("unknown strategy %q", p.Strategy)
+		}
+	case PolicyNotify, PolicyIgnore:
+		if p.Strategy != "" {
+			return fmt.Errorf("strategy only applies to the %s action", PolicyRemediate)
+		}
+		if p.AutoApply {
+			return fmt.Errorf("auto_apply only applies to the %s action", PolicyRemediate)
+		}
+	default:
+		return fmt.Errorf("action must be one of %s, %s or %s", PolicyRemediate, PolicyNotify, PolicyIgnore)
+	}
+	if _, ok := severityNames[p.Selector.Severity]; p.Selector.Severity != "" && !ok {
+		return fmt.Errorf("unknown selector severity %q", p.Selector.Severity)
+	}
+	if _, ok := severityNames[p.MaxAutoApplySeverity]; p.MaxAutoApplySeverity != "" && !ok {
+		return fmt.Errorf("unknown max_auto_apply_severity %q", p.MaxAutoApplySeverity)
+	}
+	if _, err := path.Match(p.Selector.ResourceType, ""); err != nil {
+		return fmt.Errorf("invalid resource_type pattern %q: %w", p.Selector.ResourceType, err)
+	}
+	if _, err := path.Match(p.Selector.Field, ""); err != nil {
+		return fmt.Errorf("invalid field pattern %q: %w", p.Selector.Field, err)
+	}
+	return nil
+}
+
+// Selects reports whether the policy's selector selects a drift result
+func (p *RemediationPolicy) Selects(drift *detector.DriftResult) bool {
+	selector := p.Selector
+	if selector.Provider != "" && !strings.EqualFold(selector.Provider, drift.Provider) {
+		return false
+	}
+	if selector.ResourceType != "" {
+		if matched, _ := path.Match(selector.ResourceType, drift.ResourceType); !matched {
+			return false
+		}
+	}
+	if selector.Severity != "" && severityNames[selector.Severity] != drift.Severity {
+		return false
+	}
+	if selector.Field == "" {
+		return true
+	}
+	for _, difference := range drift.Differences {
+		if fieldSelected(selector.Field, difference.Path) {
+			return true
+		}
+	}
+	return false
+}
+
+// fieldSelected reports whether a field pattern matches a drifted field or one of the fields
+// it is nested under
+func fieldSelected(pattern, field string) bool {
+	for {
+		if matched, _ := path.Match(pattern, field); matched {
+			return true
+		}
+		parent := strings.LastIndexAny(field, ".[")
+		if parent <= 0 {
+			return false
+		}
+		field = field[:parent]
+	}
+}
+
+// Permits checks that a remediation plan uses only the policy's strategy, so a policy that
+// allows updates never applies a plan that replaces or deletes resources
+func (p *RemediationPolicy) Permits(plan *RemediationPlan) error {
+	if len(plan.Actions) == 0 {
+		return errors.New("the remediation plan has no actions")
+	}
+	for _, action := range plan.Actions {
+		if action.Type != p.Strategy {
+			return fmt.Errorf("the remediation plan would %s %s, but policy %s only allows %s",
+				action.Type, action.Resource, p.Name, p.Strategy)
+		}
+	}
+	return nil
+}
+
+// PolicyDecision is what the remediation policies decide for one drift result
+type PolicyDecision struct {
+	Policy    *RemediationPolicy `json:"policy,omitempty"` // nil when no policy selects the drift
+	Action    PolicyAction       `json:"action"`
+	AutoApply bool               `json:"auto_apply"` // remediate without a person applying it
+	Reason    string             `json:"reason,omitempty"`
+}
+
+// Decide returns the decision of the first policy that selects the drift, taking policies in
+// the order given, which is List's priority order. Drift no policy selects is notified, as is
+// remediation that may not be applied unattended.
+func Decide(policies []*RemediationPolicy, drift *detector.DriftResult) PolicyDecision {
+	for _, policy := range policies {
+		if !policy.Selects(drift) {
+			continue
+		}
+		decision := PolicyDecision{Policy: policy, Action: policy.Action}
+		if policy.Action != PolicyRemediate {
+			return decision
+		}
+		maxSeverity := detector.SeverityLow
+		if policy.MaxAutoApplySeverity != "" {
+			maxSeverity = severityNames[policy.MaxAutoApplySeverity]
+		}
+		switch {
+		case !policy.AutoApply:
+			decision.Reason = "the policy requires remediation to be applied by a person"
+		case drift.Severity > maxSeverity:
+			decision.Reason = "the drift is too severe to remediate automatically"
+		default:
+			decision.AutoApply = true
+		}
+		return decision
+	}
+	return PolicyDecision{Action: PolicyNotify, Reason: "no remediation policy selects the drift"}
+}
+
+// PolicyStore persists remediation policies in a SQL database
+type PolicyStore struct {
+	db *sql.DB
+}
+
+// NewPolicyStore creates a policy store on an open database, creating its table
+func NewPolicyStore(db *sql.DB) (*PolicyStore, error) {
+	if _, err := db.Exec(policySchema); err != nil {
+		return nil, fmt.Errorf("failed to create remediation policies table: %w", err)
+	}
+	return &PolicyStore{db: db}, nil
+}
+
+// Create validates and stores a new policy, assigning its ID and timestamps
+func (s *PolicyStore) Create(ctx context.Context, policy *RemediationPolicy) error {
+	if err := policy.Validate(); err != nil {
+		return err
+	}
+	policy.ID = fmt.Sprintf("policy-%s", uuid.New().String())
+	policy.CreatedAt = time.Now().UTC()
+	policy.UpdatedAt = policy.CreatedAt
+
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO remediation_policies (id, name, priority, provider, resource_type, field, severity, action,
+			strategy, auto_apply, max_auto_apply_severity, work_dir, created_by, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		policy.ID, policy.Name, policy.Priority, policy.Selector.Provider, policy.Selector.ResourceType,
+		policy.Selector.Field, policy.Selector.Severity, string(policy.Action), string(policy.Strategy),
+		policy.AutoApply, policy.MaxAutoApplySeverity, policy.WorkDir, policy.CreatedBy, policy.CreatedAt, policy.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create remediation policy: %w", err)
+	}
+	return nil
+}
+
+// Update validates and replaces a stored policy, keeping its creator and creation time
+func (s *PolicyStore) Update(ctx context.Context, policy *RemediationPolicy) error {
+	if err := policy.Validate(); err != nil {
+		return err
+	}
+	existing, err := s.Get(ctx, policy.ID)
+	if err != nil {
+		return err
+	}
+	policy.CreatedBy = existing.CreatedBy
+	policy.CreatedAt = existing.CreatedAt
+	policy.UpdatedAt = time.Now().UTC()
+
+	_, err = s.db.ExecContext(ctx, `
+		UPDATE remediation_policies SET name = ?, priority = ?, provider = ?, resource_type = ?, field = ?,
+			severity = ?, action = ?, strategy = ?, auto_apply = ?, max_auto_apply_severity = ?, work_dir = ?,
+			updated_at = ?
+		WHERE id = ?`,
+		policy.Name, policy.Priority, policy.Selector.Provider, policy.Selector.ResourceType, policy.Selector.Field,
+		policy.Selector.Severity, string(policy.Action), string(policy.Strategy), policy.AutoApply,
+		policy.MaxAutoApplySeverity, policy.WorkDir, policy.UpdatedAt, policy.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update remediation policy: %w", err)
+	}
+	return nil
+}
+
+// Get returns a policy by ID
+func (s *PolicyStore) Get(ctx context.Context, id string) (*RemediationPolicy, error) {
+	row := s.db.QueryRowContext(ctx, `
+		SELECT id, name, priority, provider, resource_type, field, severity, action, strategy, auto_apply,
+			max_auto_apply_severity, work_dir, created_by, created_at, updated_at
+		FROM remediation_policies WHERE id = ?`, id)
+	policy, err := scanPolicy(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrPolicyNotFound
+	}
+	return policy, err
+}
+
+// List returns every policy in the order they are evaluated: by priority, then oldest first
+func (s *PolicyStore) List(ctx context.Context) ([]*RemediationPolicy, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, name, priority, provider, resource_type, field, severity, action, strategy, auto_apply,
+			max_auto_apply_severity, work_dir, created_by, created_at, updated_at
+		FROM remediation_policies ORDER BY priority ASC, created_at ASC, id ASC`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list remediation policies: %w", err)
+	}
+	defer rows.Close()
+
+	policies := []*RemediationPolicy{}
+	for rows.Next() {
+		policy, err := scanPolicy(rows)
+		if err != nil {
+			return nil, err
+		}
+		policies = append(policies, policy)
+	}
+	return policies, rows.Err()
+}
+
+// Delete removes a policy
+func (s *PolicyStore) Delete(ctx context.Context, id string) error {
+	result, err := s.db.ExecContext(ctx, `DELETE FROM remediation_policies WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete remediation policy: %w", err)
+	}
+	if deleted, err := result.RowsAffected(); err != nil {
+		return fmt.Errorf("failed to delete remediation policy: %w", err)
+	} else if deleted == 0 {
+		return ErrPolicyNotFound
+	}
+	return nil
+}
+
+// scanPolicy reads a policy from a row
+func scanPolicy(row interface{ Scan(...interface{}) error }) (*RemediationPolicy, error) {
+	var policy RemediationPolicy
+	var action, strategy string
+	if err := row.Scan(&policy.ID, &policy.Name, &policy.Priority, &policy.Selector.Provider,
+		&policy.Selector.ResourceType, &policy.Selector.Field, &policy.Selector.Severity, &action, &strategy,
+		&policy.AutoApply, &policy.MaxAutoApplySeverity, &policy.WorkDir, &policy.CreatedBy,
+		&policy.CreatedAt, &policy.UpdatedAt); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to read remediation policy: %w", err)
+	}
+	policy.Action = PolicyAction(action)
+	policy.Strategy = ActionType(strategy)
+	return &policy, nil
+}
@@ -0,0 +1,104 @@
+package remediation
+
+import (
+	"context"
+	"testing"
+
+	"github.com/catherinevee/driftmgr/internal/drift/comparator"
+	"github.com/catherinevee/driftmgr/internal/drift/detector"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPolicyStore_CRUD(t *testing.T) {
+	db := openTestDB(t)
+	store, err := NewPolicyStore(db)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	tags := &RemediationPolicy{
+		Name:      "fix tags",
+		Priority:  10,
+		Selector:  PolicySelector{Provider: "aws", Field: "tags"},
+		Action:    PolicyRemediate,
+		Strategy:  ActionTypeUpdate,
+		AutoApply: true,
+		CreatedBy: "alice",
+	}
+	require.NoError(t, store.Create(ctx, tags))
+	assert.NotEmpty(t, tags.ID)
+	databases := &RemediationPolicy{Name: "databases", Priority: 1, Selector: PolicySelector{ResourceType: "aws_db_*"}, Action: PolicyNotify}
+	require.NoError(t, store.Create(ctx, databases))
+
+	assert.Error(t, store.Create(ctx, &RemediationPolicy{Name: "no strategy", Action: PolicyRemediate}))
+
+	// A fresh store on the same database sees the policies, as after a restart
+	reopened, err := NewPolicyStore(db)
+	require.NoError(t, err)
+	policies, err := reopened.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, policies, 2)
+	assert.Equal(t, "databases", policies[0].Name, "lower priorities come first")
+	assert.Equal(t, tags.Selector, policies[1].Selector)
+	assert.Equal(t, ActionTypeUpdate, policies[1].Strategy)
+	assert.True(t, policies[1].AutoApply)
+
+	updated := *tags
+	updated.CreatedBy = "mallory"
+	updated.AutoApply = false
+	require.NoError(t, store.Update(ctx, &updated))
+	loaded, err := store.Get(ctx, tags.ID)
+	require.NoError(t, err)
+	assert.False(t, loaded.AutoApply)
+	assert.Equal(t, "alice", loaded.CreatedBy, "the creator is kept")
+
+	require.NoError(t, store.Delete(ctx, tags.ID))
+	_, err = store.Get(ctx, tags.ID)
+	assert.ErrorIs(t, err, ErrPolicyNotFound)
+	assert.ErrorIs(t, store.Delete(ctx, tags.ID), ErrPolicyNotFound)
+	assert.ErrorIs(t, store.Update(ctx, &updated), ErrPolicyNotFound)
+}
+
+func TestDecide(t *testing.T) {
+	policies := []*RemediationPolicy{
+		{Name: "ignore dev", Selector: PolicySelector{ResourceType: "aws_*_dev"}, Action: PolicyIgnore},
+		{Name: "fix tags", Selector: PolicySelector{Provider: "aws", Field: "tags"}, Action: PolicyRemediate, Strategy: ActionTypeUpdate, AutoApply: true},
+		{Name: "review instances", Selector: PolicySelector{ResourceType: "aws_instance"}, Action: PolicyRemediate, Strategy: ActionTypeUpdate},
+	}
+	drift := func(resourceType, field string, severity detector.DriftSeverity) *detector.DriftResult {
+		return &detector.DriftResult{
+			Provider:     "aws",
+			ResourceType: resourceType,
+			Severity:     severity,
+			Differences:  []comparator.Difference{{Path: field}},
+		}
+	}
+
+	decision := Decide(policies, drift("aws_s3_bucket", "tags.Owner", detector.SeverityLow))
+	assert.Equal(t, "fix tags", decision.Policy.Name, "tags selects the fields nested under it")
+	assert.True(t, decision.AutoApply)
+
+	decision = Decide(policies, drift("aws_s3_bucket", "tags.Owner", detector.SeverityMedium))
+	assert.Equal(t, PolicyRemediate, decision.Action)
+	assert.False(t, decision.AutoApply, "only low severity drift is applied by default")
+
+	decision = Decide(policies, drift("aws_instance", "instance_type", detector.SeverityLow))
+	assert.Equal(t, "review instances", decision.Policy.Name)
+	assert.False(t, decision.AutoApply)
+
+	assert.Equal(t, PolicyIgnore, Decide(policies, drift("aws_instance_dev", "tags", detector.SeverityLow)).Action,
+		"the first policy that selects the drift decides")
+
+	decision = Decide(policies, drift("aws_vpc", "cidr_block", detector.SeverityLow))
+	assert.Nil(t, decision.Policy)
+	assert.Equal(t, PolicyNotify, decision.Action)
+}
+
+func TestPolicyPermits(t *testing.T) {
+	policy := &RemediationPolicy{Name: "fix tags", Action: PolicyRemediate, Strategy: ActionTypeUpdate}
+	assert.NoError(t, policy.Permits(&RemediationPlan{Actions: []RemediationAction{{Type: ActionTypeUpdate}}}))
+	assert.ErrorContains(t, policy.Permits(&RemediationPlan{Actions: []RemediationAction{
+		{Type: ActionTypeUpdate}, {Type: ActionTypeReplace, Resource: "i-123"},
+	}}), "would replace i-123")
+	assert.Error(t, policy.Permits(&RemediationPlan{}))
+}
@@ -0,0 +1,175 @@
+package remediation
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrSnapshotNotFound is returned for an unknown snapshot ID
+var ErrSnapshotNotFound = errors.New("snapshot not found")
+
+// snapshotSchema creates the remediation snapshots table
+const snapshotSchema = `
+CREATE TABLE IF NOT EXISTS remediation_snapshots (
+	id             TEXT PRIMARY KEY,
+	description    TEXT NOT NULL DEFAULT '',
+	created_at     TIMESTAMP NOT NULL,
+	resource_count INTEGER NOT NULL DEFAULT 0,
+	resources      TEXT NOT NULL,
+	metadata       TEXT
+);
+CREATE INDEX IF NOT EXISTS idx_remediation_snapshots_created_at ON remediation_snapshots (created_at);`
+
+// Snapshot captures the state of a set of resources before they are remediated
+type Snapshot struct {
+	ID          string             `json:"id"`
+	Description string             `json:"description"`
+	CreatedAt   time.Time          `json:"created_at"`
+	Resources   []ResourceSnapshot `json:"resources"`
+	Metadata    map[string]string  `json:"metadata,omitempty"`
+}
+
+// ResourceSnapshot is the captured state of a single resource
+type ResourceSnapshot struct {
+	Resource     string                 `json:"resource"`
+	ResourceType string                 `json:"resource_type"`
+	Provider     string                 `json:"provider"`
+	State        map[string]interface{} `json:"state"`
+}
+
+// SnapshotSummary describes a snapshot without its resource state
+type SnapshotSummary struct {
+	ID            string            `json:"id"`
+	Description   string            `json:"description"`
+	CreatedAt     time.Time         `json:"created_at"`
+	ResourceCount int               `json:"resource_count"`
+	Metadata      map[string]string `json:"metadata,omitempty"`
+}
+
+// SnapshotStore persists snapshots in a SQL database so rollbacks work across restarts
+type SnapshotStore struct {
+	db *sql.DB
+}
+
+// NewSnapshotStore creates a snapshot store on an open database, creating its table
+func NewSnapshotStore(db *sql.DB) (*SnapshotStore, error) {
+	if _, err := db.Exec(snapshotSchema); err != nil {
+		return nil, fmt.Errorf("failed to create remediation snapshots table: %w", err)
+	}
+	return &SnapshotStore{db: db}, nil
+}
+
+// CreateSnapshot captures the given resources and stores the snapshot
+func (s *SnapshotStore) CreateSnapshot(ctx context.Context, description string, resources []ResourceSnapshot, metadata map[string]string) (*Snapshot, error) {
+	snapshot := &Snapshot{
+		ID:          fmt.Sprintf("snapshot-%s", uuid.New().String()),
+		Description: description,
+		CreatedAt:   time.Now().UTC(),
+		Resources:   resources,
+		Metadata:    metadata,
+	}
+	if snapshot.Resources == nil {
+		snapshot.Resources = []ResourceSnapshot{}
+	}
+
+	state, err := json.Marshal(snapshot.Resources)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal snapshot resources: %w", err)
+	}
+	var meta []byte
+	if len(metadata) > 0 {
+		if meta, err = json.Marshal(metadata); err != nil {
+			return nil, fmt.Errorf("failed to marshal snapshot metadata: %w", err)
+		}
+	}
+
+	_, err = s.db.ExecContext(ctx, `
+		INSERT INTO remediation_snapshots (id, description, created_at, resource_count, resources, metadata)
+		VALUES (?, ?, ?, ?, ?, ?)`,
+		snapshot.ID, snapshot.Description, snapshot.CreatedAt, len(snapshot.Resources), string(state), string(meta))
+	if err != nil {
+		return nil, fmt.Errorf("failed to save snapshot: %w", err)
+	}
+	return snapshot, nil
+}
+
+// GetSnapshot loads a snapshot by ID
+func (s *SnapshotStore) GetSnapshot(ctx context.Context, id string) (*Snapshot, error) {
+	var snapshot Snapshot
+	var state string
+	var meta sql.NullString
+	err := s.db.QueryRowContext(ctx, `
+		SELECT id, description, created_at, resources, metadata FROM remediation_snapshots WHERE id = ?`, id).
+		Scan(&snapshot.ID, &snapshot.Description, &snapshot.CreatedAt, &state, &meta)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrSnapshotNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snapshot: %w", err)
+	}
+
+	if err := json.Unmarshal([]byte(state), &snapshot.Resources); err != nil {
+		return nil, fmt.Errorf("failed to decode snapshot %s: %w", id, err)
+	}
+	if snapshot.Metadata, err = decodeSnapshotMetadata(meta); err != nil {
+		return nil, fmt.Errorf("failed to decode metadata of snapshot %s: %w", id, err)
+	}
+	return &snapshot, nil
+}
+
+// ListSnapshots returns summaries of all stored snapshots, newest first
+func (s *SnapshotStore) ListSnapshots(ctx context.Context) ([]SnapshotSummary, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, description, created_at, resource_count, metadata FROM remediation_snapshots
+		ORDER BY created_at DESC`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list snapshots: %w", err)
+	}
+	defer rows.Close()
+
+	summaries := []SnapshotSummary{}
+	for rows.Next() {
+		var summary SnapshotSummary
+		var meta sql.NullString
+		if err := rows.Scan(&summary.ID, &summary.Description, &summary.CreatedAt, &summary.ResourceCount, &meta); err != nil {
+			return nil, fmt.Errorf("failed to read snapshot: %w", err)
+		}
+		if summary.Metadata, err = decodeSnapshotMetadata(meta); err != nil {
+			return nil, fmt.Errorf("failed to decode metadata of snapshot %s: %w", summary.ID, err)
+		}
+		summaries = append(summaries, summary)
+	}
+	return summaries, rows.Err()
+}
+
+// DeleteSnapshot removes a snapshot by ID
+func (s *SnapshotStore) DeleteSnapshot(ctx context.Context, id string) error {
+	result, err := s.db.ExecContext(ctx, `DELETE FROM remediation_snapshots WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete snapshot: %w", err)
+	}
+	if deleted, err := result.RowsAffected(); err != nil {
+		return fmt.Errorf("failed to delete snapshot: %w", err)
+	} else if deleted == 0 {
+		return ErrSnapshotNotFound
+	}
+	return nil
+}
+
+// decodeSnapshotMetadata parses a snapshot's stored metadata, which may be absent
+func decodeSnapshotMetadata(meta sql.NullString) (map[string]string, error) {
+	if !meta.Valid || meta.String == "" {
+		return nil, nil
+	}
+	var metadata map[string]string
+	if err := json.Unmarshal([]byte(meta.String), &metadata); err != nil {
+		return nil, err
+	}
+	return metadata, nil
+}
@@ -0,0 +1,63 @@
+package remediation
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// openTestDB opens a SQLite database in a temporary directory
+func openTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := OpenSQLiteDB(filepath.Join(t.TempDir(), "driftmgr.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestSnapshotStore_CreateAndGet(t *testing.T) {
+	db := openTestDB(t)
+	store, err := NewSnapshotStore(db)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	created, err := store.CreateSnapshot(ctx, "before batch", []ResourceSnapshot{
+		{Resource: "i-123", ResourceType: "aws_instance", Provider: "aws", State: map[string]interface{}{"instance_type": "t3.micro"}},
+	}, map[string]string{"trigger": "test"})
+	require.NoError(t, err)
+
+	// A fresh store on the same database sees the snapshot, as after a restart
+	reopened, err := NewSnapshotStore(db)
+	require.NoError(t, err)
+	loaded, err := reopened.GetSnapshot(ctx, created.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "before batch", loaded.Description)
+	assert.Equal(t, "test", loaded.Metadata["trigger"])
+	require.Len(t, loaded.Resources, 1)
+	assert.Equal(t, "t3.micro", loaded.Resources[0].State["instance_type"])
+
+	summaries, err := store.ListSnapshots(ctx)
+	require.NoError(t, err)
+	require.Len(t, summaries, 1)
+	assert.Equal(t, created.ID, summaries[0].ID)
+	assert.Equal(t, 1, summaries[0].ResourceCount)
+}
+
+func TestSnapshotStore_Delete(t *testing.T) {
+	store, err := NewSnapshotStore(openTestDB(t))
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	created, err := store.CreateSnapshot(ctx, "", nil, nil)
+	require.NoError(t, err)
+
+	require.NoError(t, store.DeleteSnapshot(ctx, created.ID))
+	_, err = store.GetSnapshot(ctx, created.ID)
+	assert.ErrorIs(t, err, ErrSnapshotNotFound)
+	assert.ErrorIs(t, store.DeleteSnapshot(ctx, created.ID), ErrSnapshotNotFound)
+}
@@ -14,6 +14,7 @@ import (
 	"github.com/catherinevee/driftmgr/internal/drift/comparator"
 	"github.com/catherinevee/driftmgr/internal/drift/detector"
 	importgen "github.com/catherinevee/driftmgr/internal/remediation/tfimport"
+	"github.com/catherinevee/driftmgr/internal/state"
 	"github.com/catherinevee/driftmgr/pkg/models"
 	"github.com/google/uuid"
 )
@@ -29,7 +30,7 @@ type CloudAsTruth struct {
 func NewCloudAsTruthStrategy(config *StrategyConfig) *CloudAsTruth {
 	if config == nil {
 		config = &StrategyConfig{
-			TerraformPath: "terraform",
+			TerraformPath: state.TerraformBinary(),
 			Timeout:       15 * time.Minute,
 			GitBranch:     "drift-fix",
 		}
@@ -37,7 +38,7 @@ func NewCloudAsTruthStrategy(config *StrategyConfig) *CloudAsTruth {
 
 	// Set defaults
 	if config.TerraformPath == "" {
-		config.TerraformPath = "terraform"
+		config.TerraformPath = state.TerraformBinary()
 	}
 	if config.Timeout == 0 {
 		config.Timeout = 15 * time.Minute
@@ -12,6 +12,7 @@ import (
 
 	"github.com/catherinevee/driftmgr/internal/drift/comparator"
 	"github.com/catherinevee/driftmgr/internal/drift/detector"
+	"github.com/catherinevee/driftmgr/internal/state"
 	"github.com/google/uuid"
 )
 
@@ -25,7 +26,7 @@ type CodeAsTruth struct {
 func NewCodeAsTruthStrategy(config *StrategyConfig) *CodeAsTruth {
 	if config == nil {
 		config = &StrategyConfig{
-			TerraformPath: "terraform",
+			TerraformPath: state.TerraformBinary(),
 			Timeout:       30 * time.Minute,
 			MaxParallel:   1,
 		}
@@ -33,7 +34,7 @@ func NewCodeAsTruthStrategy(config *StrategyConfig) *CodeAsTruth {
 
 	// Set defaults
 	if config.TerraformPath == "" {
-		config.TerraformPath = "terraform"
+		config.TerraformPath = state.TerraformBinary()
 	}
 	if config.Timeout == 0 {
 		config.Timeout = 30 * time.Minute
@@ -0,0 +1,217 @@
+package remediation
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"text/template"
+
+	"github.com/catherinevee/driftmgr/pkg/models"
+)
+
+// TagComplianceStrategy names the remediation that applies required tags to resources in bulk
+const TagComplianceStrategy = "tag_compliance"
+
+// ResourceTagger adds the same tags to a set of one provider's resources, keeping their other
+// tags. The returned map holds the error of each resource ID that could not be tagged.
+type ResourceTagger interface {
+	TagResources(ctx context.Context, resources []models.Resource, tags map[string]string) map[string]error
+}
+
+// TagComplianceOptions configures a bulk tag remediation. Tag values are text/template
+// templates rendered against each resource, such as {{.Region}}, {{index .Tags "Team"}} or
+// {{index .Attributes "vpc_id"}}. Only tags that are missing or empty are added unless
+// Overwrite is set, in which case tags with a different value are replaced too.
+type TagComplianceOptions struct {
+	Tags      map[string]string `json:"tags"`
+	Overwrite bool              `json:"overwrite,omitempty"`
+	DryRun    bool              `json:"dry_run,omitempty"`
+}
+
+// TaggedResource is a resource that was tagged, or would be in a dry run, with the tags applied
+type TaggedResource struct {
+	ResourceID   string            `json:"resource_id"`
+	ResourceType string            `json:"resource_type"`
+	Provider     string            `json:"provider"`
+	Tags         map[string]string `json:"tags"`
+}
+
+// TagFailure is a resource that could not be tagged
+type TagFailure struct {
+	ResourceID   string `json:"resource_id"`
+	ResourceType string `json:"resource_type"`
+	Provider     string `json:"provider"`
+	Error        string `json:"error"`
+}
+
+// TagComplianceResult reports the outcome of a bulk tag remediation. Compliant counts the
+// resources that already carried every tag.
+type TagComplianceResult struct {
+	Strategy  string           `json:"strategy"`
+	DryRun    bool             `json:"dry_run"`
+	Tagged    []TaggedResource `json:"tagged"`
+	Failed    []TagFailure     `json:"failed"`
+	Compliant int              `json:"compliant"`
+}
+
+// TagComplianceRemediator applies tags to resources through each provider's tagger
+type TagComplianceRemediator struct {
+	taggers map[string]ResourceTagger
+	mu      sync.RWMutex
+}
+
+// NewTagComplianceRemediator creates a remediator with no taggers registered
+func NewTagComplianceRemediator() *TagComplianceRemediator {
+	return &TagComplianceRemediator{taggers: make(map[string]ResourceTagger)}
+}
+
+// RegisterTagger sets the tagger used for a provider's resources
+func (r *TagComplianceRemediator) RegisterTagger(provider string, tagger ResourceTagger) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.taggers[provider] = tagger
+}
+
+// HasTagger reports whether a tagger is registered for a provider
+func (r *TagComplianceRemediator) HasTagger(provider string) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	_, ok := r.taggers[provider]
+	return ok
+}
+
+// tagBatch is the resources of one provider that receive the same tags
+type tagBatch struct {
+	provider  string
+	tags      map[string]string
+	resources []models.Resource
+}
+
+// Apply adds the configured tags to each resource that lacks them. Resources needing the same
+// tags from the same provider are tagged in one batch. In a dry run nothing is changed and
+// the resources that would be tagged are reported as tagged.
+func (r *TagComplianceRemediator) Apply(ctx context.Context, resources []models.Resource, options TagComplianceOptions) (*TagComplianceResult, error) {
+	if len(options.Tags) == 0 {
+		return nil, fmt.Errorf("no tags to apply")
+	}
+	keys := make([]string, 0, len(options.Tags))
+	templates := make(map[string]*template.Template, len(options.Tags))
+	for key, value := range options.Tags {
+		tmpl, err := template.New(key).Option("missingkey=zero").Parse(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value template for tag %s: %w", key, err)
+		}
+		keys = append(keys, key)
+		templates[key] = tmpl
+	}
+	sort.Strings(keys)
+
+	result := &TagComplianceResult{
+		Strategy: TagComplianceStrategy,
+		DryRun:   options.DryRun,
+		Tagged:   []TaggedResource{},
+		Failed:   []TagFailure{},
+	}
+	var batches []*tagBatch
+	batchIndex := make(map[string]*tagBatch)
+	for _, resource := range resources {
+		tags, err := missingTags(resource, keys, templates, options.Overwrite)
+		if err != nil {
+			result.Failed = append(result.Failed, tagFailure(resource, err))
+			continue
+		}
+		if len(tags) == 0 {
+			result.Compliant++
+			continue
+		}
+
+		id := batchKey(resource.Provider, tags)
+		batch, ok := batchIndex[id]
+		if !ok {
+			batch = &tagBatch{provider: resource.Provider, tags: tags}
+			batchIndex[id] = batch
+			batches = append(batches, batch)
+		}
+		batch.resources = append(batch.resources, resource)
+	}
+
+	for _, batch := range batches {
+		var failures map[string]error
+		if !options.DryRun {
+			r.mu.RLock()
+			tagger, ok := r.taggers[batch.provider]
+			r.mu.RUnlock()
+			if !ok {
+				for _, resource := range batch.resources {
+					result.Failed = append(result.Failed, tagFailure(resource, fmt.Errorf("tagging is not supported for provider %q", batch.provider)))
+				}
+				continue
+			}
+			failures = tagger.TagResources(ctx, batch.resources, batch.tags)
+		}
+		for _, resource := range batch.resources {
+			if err := failures[resource.ID]; err != nil {
+				result.Failed = append(result.Failed, tagFailure(resource, err))
+				continue
+			}
+			result.Tagged = append(result.Tagged, TaggedResource{
+				ResourceID:   resource.ID,
+				ResourceType: resource.Type,
+				Provider:     resource.Provider,
+				Tags:         batch.tags,
+			})
+		}
+	}
+	return result, nil
+}
+
+// missingTags renders the tags a resource should carry and returns those it lacks, or those
+// with a different value when overwrite is set
+func missingTags(resource models.Resource, keys []string, templates map[string]*template.Template, overwrite bool) (map[string]string, error) {
+	tags := make(map[string]string)
+	for _, key := range keys {
+		current := resource.Tags[key]
+		if current != "" && !overwrite {
+			continue
+		}
+		var value strings.Builder
+		if err := templates[key].Execute(&value, resource); err != nil {
+			return nil, fmt.Errorf("failed to render tag %s: %w", key, err)
+		}
+		rendered := strings.TrimSpace(value.String())
+		if rendered == "" || rendered == "<no value>" {
+			return nil, fmt.Errorf("tag %s rendered an empty value", key)
+		}
+		if rendered != current {
+			tags[key] = rendered
+		}
+	}
+	return tags, nil
+}
+
+// batchKey identifies the batch of resources of a provider receiving the same tags
+func batchKey(provider string, tags map[string]string) string {
+	keys := make([]string, 0, len(tags))
+	for key := range tags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var id strings.Builder
+	id.WriteString(provider)
+	for _, key := range keys {
+		fmt.Fprintf(&id, "\x00%s=%s", key, tags[key])
+	}
+	return id.String()
+}
+
+// tagFailure records why a resource could not be tagged
+func tagFailure(resource models.Resource, err error) TagFailure {
+	return TagFailure{
+		ResourceID:   resource.ID,
+		ResourceType: resource.Type,
+		Provider:     resource.Provider,
+		Error:        err.Error(),
+	}
+}
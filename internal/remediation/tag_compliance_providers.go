@@ -0,0 +1,139 @@
+package remediation
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"cloud.google.com/go/storage"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	awsprovider "github.com/catherinevee/driftmgr/internal/providers/aws"
+	"github.com/catherinevee/driftmgr/pkg/models"
+	"google.golang.org/api/compute/v1"
+)
+
+// TagResources adds tags to AWS resources by ARN through the Resource Groups Tagging API,
+// batched per region
+func (ap *AWSProvider) TagResources(ctx context.Context, resources []models.Resource, tags map[string]string) map[string]error {
+	failed := make(map[string]error)
+	byRegion := make(map[string][]string)
+	ids := make(map[string]string)
+	for _, resource := range resources {
+		arn, _ := resource.Attributes["arn"].(string)
+		if arn == "" && strings.HasPrefix(resource.ID, "arn:") {
+			arn = resource.ID
+		}
+		if arn == "" {
+			failed[resource.ID] = fmt.Errorf("resource has no ARN to tag")
+			continue
+		}
+		// Global resources such as S3 buckets have no region in their ARN
+		region := resource.Region
+		if parts := strings.SplitN(arn, ":", 5); len(parts) == 5 && parts[3] != "" {
+			region = parts[3]
+		}
+		if region == "" || region == "global" {
+			region = "us-east-1"
+		}
+		byRegion[region] = append(byRegion[region], arn)
+		ids[arn] = resource.ID
+	}
+
+	for region, arns := range byRegion {
+		for arn, err := range awsprovider.TagResources(ctx, ap.cfg, region, arns, tags) {
+			failed[ids[arn]] = err
+		}
+	}
+	return failed
+}
+
+// TagResources merges tags into Azure resources' tags through the Tags API
+func (ap *AzureProvider) TagResources(ctx context.Context, resources []models.Resource, tags map[string]string) map[string]error {
+	failed := make(map[string]error)
+	client, err := armresources.NewTagsClient(ap.subscriptionID, ap.cred, nil)
+	if err != nil {
+		for _, resource := range resources {
+			failed[resource.ID] = fmt.Errorf("failed to create Azure tags client: %w", err)
+		}
+		return failed
+	}
+
+	values := make(map[string]*string, len(tags))
+	for key, value := range tags {
+		value := value
+		values[key] = &value
+	}
+	merge := armresources.TagsPatchOperationMerge
+	patch := armresources.TagsPatchResource{
+		Operation:  &merge,
+		Properties: &armresources.Tags{Tags: values},
+	}
+	for _, resource := range resources {
+		if !strings.HasPrefix(resource.ID, "/subscriptions/") {
+			failed[resource.ID] = fmt.Errorf("resource ID is not an Azure resource ID")
+			continue
+		}
+		if _, err := client.UpdateAtScope(ctx, resource.ID, patch, nil); err != nil {
+			failed[resource.ID] = err
+		}
+	}
+	return failed
+}
+
+// TagResources merges labels into GCP compute instances' and storage buckets' labels. GCP
+// label keys and values must be lowercase, so tags are applied as they are given.
+func (gp *GCPProvider) TagResources(ctx context.Context, resources []models.Resource, tags map[string]string) map[string]error {
+	failed := make(map[string]error)
+	for _, resource := range resources {
+		name := resource.Name
+		if name == "" {
+			name = resource.ID
+		}
+
+		var err error
+		switch resource.Type {
+		case "compute.googleapis.com/instances", "google_compute_instance":
+			err = gp.labelComputeInstance(ctx, resource, name, tags)
+		case "storage.googleapis.com/buckets", "google_storage_bucket":
+			update := storage.BucketAttrsToUpdate{}
+			for key, value := range tags {
+				update.SetLabel(key, value)
+			}
+			_, err = gp.storageClient.Bucket(name).Update(ctx, update)
+		default:
+			err = fmt.Errorf("labels are not supported for %s", resource.Type)
+		}
+		if err != nil {
+			failed[resource.ID] = err
+		}
+	}
+	return failed
+}
+
+// labelComputeInstance adds labels to an instance. setLabels replaces every label and needs
+// the current fingerprint, so the instance's labels are read and merged first.
+func (gp *GCPProvider) labelComputeInstance(ctx context.Context, resource models.Resource, name string, tags map[string]string) error {
+	zone := resource.Region
+	if z, ok := resource.Metadata["zone"]; ok {
+		zone = z
+	} else if z, ok := resource.Attributes["zone"].(string); ok && z != "" {
+		zone = z
+	}
+
+	instance, err := gp.computeService.Instances.Get(gp.projectID, zone, name).Context(ctx).Do()
+	if err != nil {
+		return fmt.Errorf("failed to get compute instance %s: %w", name, err)
+	}
+	labels := make(map[string]string, len(instance.Labels)+len(tags))
+	for key, value := range instance.Labels {
+		labels[key] = value
+	}
+	for key, value := range tags {
+		labels[key] = value
+	}
+	request := &compute.InstancesSetLabelsRequest{Labels: labels, LabelFingerprint: instance.LabelFingerprint}
+	if _, err := gp.computeService.Instances.SetLabels(gp.projectID, zone, name, request).Context(ctx).Do(); err != nil {
+		return fmt.Errorf("failed to set labels on compute instance %s: %w", name, err)
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package remediation
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/catherinevee/driftmgr/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeResourceTagger records the batches it is asked to tag and fails the IDs it is told to
+type fakeResourceTagger struct {
+	batches []map[string]string
+	tagged  []string
+	fail    map[string]bool
+}
+
+func (f *fakeResourceTagger) TagResources(ctx context.Context, resources []models.Resource, tags map[string]string) map[string]error {
+	f.batches = append(f.batches, tags)
+	failed := make(map[string]error)
+	for _, resource := range resources {
+		if f.fail[resource.ID] {
+			failed[resource.ID] = errors.New("access denied")
+			continue
+		}
+		f.tagged = append(f.tagged, resource.ID)
+	}
+	return failed
+}
+
+func TestTagComplianceRemediator(t *testing.T) {
+	resources := []models.Resource{
+		{ID: "i-1", Type: "aws_instance", Provider: "aws", Region: "us-east-1", Tags: map[string]string{"Owner": "team-a"}},
+		{ID: "i-2", Type: "aws_instance", Provider: "aws", Region: "us-east-1"},
+		{ID: "i-3", Type: "aws_instance", Provider: "aws", Region: "eu-west-1", Tags: map[string]string{"Owner": "team-b", "Environment": "eu-west-1"}},
+		{ID: "i-4", Type: "aws_instance", Provider: "aws", Region: "us-east-1"},
+		{ID: "vm-1", Type: "azurerm_virtual_machine", Provider: "azure", Region: "westeurope"},
+	}
+	options := TagComplianceOptions{Tags: map[string]string{"Owner": "platform", "Environment": "{{.Region}}"}}
+
+	tagger := &fakeResourceTagger{fail: map[string]bool{"i-4": true}}
+	remediator := NewTagComplianceRemediator()
+	remediator.RegisterTagger("aws", tagger)
+
+	dryRun := options
+	dryRun.DryRun = true
+	result, err := remediator.Apply(context.Background(), resources, dryRun)
+	require.NoError(t, err)
+	assert.True(t, result.DryRun)
+	assert.Len(t, result.Tagged, 4)
+	assert.Empty(t, tagger.batches, "dry runs change nothing")
+
+	result, err = remediator.Apply(context.Background(), resources, options)
+	require.NoError(t, err)
+	assert.Equal(t, TagComplianceStrategy, result.Strategy)
+	assert.Equal(t, 1, result.Compliant)
+	assert.Equal(t, []map[string]string{
+		{"Environment": "us-east-1"},
+		{"Owner": "platform", "Environment": "us-east-1"},
+	}, tagger.batches, "existing tags are kept and resources needing the same tags share a batch")
+	assert.Equal(t, []string{"i-1", "i-2"}, tagger.tagged)
+
+	require.Len(t, result.Tagged, 2)
+	assert.Equal(t, map[string]string{"Environment": "us-east-1"}, result.Tagged[0].Tags)
+	require.Len(t, result.Failed, 2)
+	assert.Equal(t, "i-4", result.Failed[0].ResourceID)
+	assert.Equal(t, "access denied", result.Failed[0].Error)
+	assert.Equal(t, "vm-1", result.Failed[1].ResourceID)
+	assert.Contains(t, result.Failed[1].Error, "not supported")
+
+	result, err = remediator.Apply(context.Background(), resources[2:3], TagComplianceOptions{
+		Tags: map[string]string{"Owner": "platform"}, Overwrite: true, DryRun: true,
+	})
+	require.NoError(t, err)
+	require.Len(t, result.Tagged, 1)
+	assert.Equal(t, map[string]string{"Owner": "platform"}, result.Tagged[0].Tags, "overwrite replaces other values")
+}
+
+func TestTagComplianceRemediatorTemplates(t *testing.T) {
+	remediator := NewTagComplianceRemediator()
+
+	_, err := remediator.Apply(context.Background(), nil, TagComplianceOptions{Tags: map[string]string{"Owner": "{{.Name"}})
+	assert.Error(t, err)
+
+	result, err := remediator.Apply(context.Background(), []models.Resource{
+		{ID: "i-1", Provider: "aws", Attributes: map[string]interface{}{"vpc_id": "vpc-1"}},
+		{ID: "i-2", Provider: "aws"},
+	}, TagComplianceOptions{Tags: map[string]string{"Network": `{{index .Attributes "vpc_id"}}`}, DryRun: true})
+	require.NoError(t, err)
+	require.Len(t, result.Tagged, 1)
+	assert.Equal(t, "vpc-1", result.Tagged[0].Tags["Network"])
+	require.Len(t, result.Failed, 1)
+	assert.Contains(t, result.Failed[0].Error, "empty value")
+}
@@ -11,6 +11,7 @@ import (
 type ImportCommand struct {
 	ResourceType string `json:"resource_type"`
 	ResourceName string `json:"resource_name"`
+	Address      string `json:"address"`
 	ResourceID   string `json:"resource_id"`
 	Provider     string `json:"provider"`
 	Command      string `json:"command"`
@@ -50,7 +51,7 @@ func NewImportGenerator() *ImportGenerator {
 
 // GenerateImportCommand generates a Terraform import command for a resource
 func (g *ImportGenerator) GenerateImportCommand(resource models.Resource) (*ImportCommand, error) {
-	provider := strings.ToLower(resource.Provider)
+	provider := normalizeProvider(resource.Provider)
 	resourceType := resource.Type
 
 	// Get provider mappings
@@ -85,21 +86,23 @@ func (g *ImportGenerator) GenerateImportCommand(resource models.Resource) (*Impo
 		resourceName = g.sanitizeResourceName(resource.ID)
 	}
 
+	if resourceID == "" {
+		return nil, fmt.Errorf("could not determine import ID for %s %s", resourceType, resource.Name)
+	}
+
 	// Build the import command
+	address := fmt.Sprintf("%s.%s", mapping.ResourceType, resourceName)
 	var command string
 	if mapping.CustomCommand != nil {
 		command = mapping.CustomCommand(resource)
 	} else {
-		command = fmt.Sprintf("terraform import %s.%s %s",
-			mapping.ResourceType,
-			resourceName,
-			resourceID,
-		)
+		command = fmt.Sprintf("terraform import %s %s", address, shellQuote(resourceID))
 	}
 
 	return &ImportCommand{
 		ResourceType: mapping.ResourceType,
 		ResourceName: resourceName,
+		Address:      address,
 		ResourceID:   resourceID,
 		Provider:     provider,
 		Command:      command,
@@ -314,7 +317,7 @@ func (g *ImportGenerator) initializeAWSMappings() {
 		"aws_ecs_service": {
 			ResourceType: "aws_ecs_service",
 			IDExtractor: func(r models.Resource) string {
-				cluster := r.Properties["cluster"].(string)
+				cluster := propertyString(r, "cluster")
 				return fmt.Sprintf("%s/%s", cluster, r.Name)
 			},
 		},
@@ -582,15 +585,15 @@ func (g *ImportGenerator) initializeGCPMappings() {
 		"google_compute_instance": {
 			ResourceType: "google_compute_instance",
 			IDExtractor: func(r models.Resource) string {
-				zone := r.Properties["zone"].(string)
+				zone := propertyString(r, "zone")
 				return fmt.Sprintf("projects/%s/zones/%s/instances/%s",
-					r.Properties["project"].(string), zone, r.Name)
+					propertyString(r, "project"), zone, r.Name)
 			},
 		},
 		"compute_instance": {
 			ResourceType: "google_compute_instance",
 			IDExtractor: func(r models.Resource) string {
-				zone := r.Properties["zone"].(string)
+				zone := propertyString(r, "zone")
 				return fmt.Sprintf("%s/%s", zone, r.Name)
 			},
 		},
@@ -621,15 +624,15 @@ func (g *ImportGenerator) initializeGCPMappings() {
 		"google_compute_subnetwork": {
 			ResourceType: "google_compute_subnetwork",
 			IDExtractor: func(r models.Resource) string {
-				region := r.Properties["region"].(string)
+				region := propertyString(r, "region")
 				return fmt.Sprintf("projects/%s/regions/%s/subnetworks/%s",
-					r.Properties["project"].(string), region, r.Name)
+					propertyString(r, "project"), region, r.Name)
 			},
 		},
 		"compute_subnetwork": {
 			ResourceType: "google_compute_subnetwork",
 			IDExtractor: func(r models.Resource) string {
-				region := r.Properties["region"].(string)
+				region := propertyString(r, "region")
 				return fmt.Sprintf("%s/%s", region, r.Name)
 			},
 		},
@@ -660,15 +663,15 @@ func (g *ImportGenerator) initializeGCPMappings() {
 		"google_container_cluster": {
 			ResourceType: "google_container_cluster",
 			IDExtractor: func(r models.Resource) string {
-				location := r.Properties["location"].(string)
+				location := propertyString(r, "location")
 				return fmt.Sprintf("projects/%s/locations/%s/clusters/%s",
-					r.Properties["project"].(string), location, r.Name)
+					propertyString(r, "project"), location, r.Name)
 			},
 		},
 		"container_cluster": {
 			ResourceType: "google_container_cluster",
 			IDExtractor: func(r models.Resource) string {
-				location := r.Properties["location"].(string)
+				location := propertyString(r, "location")
 				return fmt.Sprintf("%s/%s", location, r.Name)
 			},
 		},
@@ -712,7 +715,7 @@ func (g *ImportGenerator) initializeGCPMappings() {
 			ResourceType: "google_bigquery_dataset",
 			IDExtractor: func(r models.Resource) string {
 				return fmt.Sprintf("projects/%s/datasets/%s",
-					r.Properties["project"].(string), r.Name)
+					propertyString(r, "project"), r.Name)
 			},
 		},
 		"bigquery_dataset": {
@@ -736,6 +739,37 @@ func (g *ImportGenerator) initializeGCPMappings() {
 	}
 }
 
+// normalizeProvider maps provider aliases to the keys used by the import mappings
+func normalizeProvider(provider string) string {
+	switch strings.ToLower(provider) {
+	case "azurerm":
+		return "azure"
+	case "google":
+		return "gcp"
+	default:
+		return strings.ToLower(provider)
+	}
+}
+
+// propertyString returns a string property, falling back to attributes, or "" if unset
+func propertyString(resource models.Resource, key string) string {
+	if value, ok := resource.Properties[key].(string); ok {
+		return value
+	}
+	if value, ok := resource.Attributes[key].(string); ok {
+		return value
+	}
+	return ""
+}
+
+// shellQuote quotes an import ID when it contains characters the shell would interpret
+func shellQuote(value string) string {
+	if !strings.ContainsAny(value, " \t\"'$`\\|&;<>()*?[]{}!#~") {
+		return value
+	}
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
+
 // formatResourceID formats a resource ID based on a template
 func (g *ImportGenerator) formatResourceID(format string, resource models.Resource) string {
 	result := format
@@ -0,0 +1,72 @@
+package tfimport
+
+import (
+	"testing"
+
+	"github.com/catherinevee/driftmgr/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateImportCommand_ProviderFormats(t *testing.T) {
+	gen := NewImportGenerator()
+
+	tests := []struct {
+		name     string
+		resource models.Resource
+		address  string
+		id       string
+	}{
+		{
+			name:     "aws instance",
+			resource: models.Resource{ID: "i-0abc123", Name: "web", Type: "aws_instance", Provider: "aws"},
+			address:  "aws_instance.web",
+			id:       "i-0abc123",
+		},
+		{
+			name: "azurerm alias",
+			resource: models.Resource{
+				ID:       "/subscriptions/sub/resourceGroups/rg",
+				Name:     "rg",
+				Type:     "azurerm_resource_group",
+				Provider: "azurerm",
+			},
+			address: "azurerm_resource_group.rg",
+			id:      "/subscriptions/sub/resourceGroups/rg",
+		},
+		{
+			name: "google instance",
+			resource: models.Resource{
+				Name:       "vm-1",
+				Type:       "google_compute_instance",
+				Provider:   "google",
+				Properties: map[string]interface{}{"project": "proj", "zone": "us-central1-a"},
+			},
+			address: "google_compute_instance.vm_1",
+			id:      "projects/proj/zones/us-central1-a/instances/vm-1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd, err := gen.GenerateImportCommand(tt.resource)
+			require.NoError(t, err)
+			assert.Equal(t, tt.address, cmd.Address)
+			assert.Equal(t, tt.id, cmd.ResourceID)
+			assert.Equal(t, "terraform import "+tt.address+" "+tt.id, cmd.Command)
+		})
+	}
+}
+
+func TestGenerateImportCommand_MissingPropertiesDoNotPanic(t *testing.T) {
+	gen := NewImportGenerator()
+
+	assert.NotPanics(t, func() {
+		_, _ = gen.GenerateImportCommand(models.Resource{Name: "svc", Type: "aws_ecs_service", Provider: "aws"})
+	})
+}
+
+func TestGenerateImportCommand_UnsupportedType(t *testing.T) {
+	_, err := NewImportGenerator().GenerateImportCommand(models.Resource{ID: "x", Type: "aws_unknown", Provider: "aws"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,273 @@
+package tfimport
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/catherinevee/driftmgr/pkg/models"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// AttributeMapping defines which attributes are written for a Terraform resource type
+type AttributeMapping struct {
+	ResourceType string
+	// Attributes maps each Terraform argument to the resource keys it is read from, in
+	// priority order. "$name", "$id" and "$region" read the resource's own fields.
+	Attributes map[string][]string
+	// IncludeTags writes the resource's tags as a tags argument
+	IncludeTags bool
+}
+
+// GeneratedResource is a single resource block produced by the HCL generator
+type GeneratedResource struct {
+	Address       string         `json:"address"`
+	ResourceType  string         `json:"resource_type"`
+	ResourceID    string         `json:"resource_id"`
+	HCL           string         `json:"hcl"`
+	ImportCommand *ImportCommand `json:"import_command"`
+}
+
+// SkippedResource is a resource the HCL generator could not handle
+type SkippedResource struct {
+	ResourceID   string `json:"resource_id"`
+	ResourceType string `json:"resource_type"`
+	Reason       string `json:"reason"`
+}
+
+// HCLResult is the output of generating HCL for a set of resources
+type HCLResult struct {
+	HCL       string              `json:"hcl"`
+	Resources []GeneratedResource `json:"resources"`
+	Skipped   []SkippedResource   `json:"skipped,omitempty"`
+}
+
+// HCLGenerator writes skeleton Terraform resource blocks for unmanaged resources
+type HCLGenerator struct {
+	importGenerator *ImportGenerator
+	mappings        map[string]AttributeMapping
+}
+
+// NewHCLGenerator creates a new HCL generator with the default attribute mappings
+func NewHCLGenerator() *HCLGenerator {
+	gen := &HCLGenerator{
+		importGenerator: NewImportGenerator(),
+		mappings:        make(map[string]AttributeMapping),
+	}
+
+	gen.initializeAWSAttributeMappings()
+
+	return gen
+}
+
+// RegisterMapping adds or replaces the attribute mapping for a resource type
+func (g *HCLGenerator) RegisterMapping(mapping AttributeMapping) {
+	g.mappings[mapping.ResourceType] = mapping
+}
+
+// Generate produces HCL resource blocks and matching import commands for the resources
+func (g *HCLGenerator) Generate(resources []models.Resource) *HCLResult {
+	result := &HCLResult{
+		Resources: make([]GeneratedResource, 0, len(resources)),
+	}
+
+	file := hclwrite.NewEmptyFile()
+	for _, resource := range resources {
+		importCmd, err := g.importGenerator.GenerateImportCommand(resource)
+		if err != nil {
+			result.Skipped = append(result.Skipped, SkippedResource{
+				ResourceID:   resource.ID,
+				ResourceType: resource.Type,
+				Reason:       err.Error(),
+			})
+			continue
+		}
+
+		mapping, exists := g.mappings[importCmd.ResourceType]
+		if !exists {
+			result.Skipped = append(result.Skipped, SkippedResource{
+				ResourceID:   resource.ID,
+				ResourceType: resource.Type,
+				Reason:       fmt.Sprintf("no attribute mapping for %s", importCmd.ResourceType),
+			})
+			continue
+		}
+
+		block := g.buildBlock(resource, mapping, importCmd.ResourceName)
+
+		single := hclwrite.NewEmptyFile()
+		single.Body().AppendBlock(block)
+
+		if len(file.Body().Blocks()) > 0 {
+			file.Body().AppendNewline()
+		}
+		file.Body().AppendBlock(block)
+
+		result.Resources = append(result.Resources, GeneratedResource{
+			Address:       importCmd.Address,
+			ResourceType:  importCmd.ResourceType,
+			ResourceID:    importCmd.ResourceID,
+			HCL:           string(single.Bytes()),
+			ImportCommand: importCmd,
+		})
+	}
+
+	result.HCL = string(file.Bytes())
+	return result
+}
+
+// buildBlock writes a resource block using the mapping's attributes
+func (g *HCLGenerator) buildBlock(resource models.Resource, mapping AttributeMapping, name string) *hclwrite.Block {
+	block := hclwrite.NewBlock("resource", []string{mapping.ResourceType, name})
+	body := block.Body()
+
+	// Sort argument names so output is stable between runs
+	arguments := make([]string, 0, len(mapping.Attributes))
+	for argument := range mapping.Attributes {
+		arguments = append(arguments, argument)
+	}
+	sort.Strings(arguments)
+
+	for _, argument := range arguments {
+		for _, source := range mapping.Attributes[argument] {
+			value, ok := ctyValue(lookupAttribute(resource, source))
+			if ok {
+				body.SetAttributeValue(argument, value)
+				break
+			}
+		}
+	}
+
+	if mapping.IncludeTags {
+		tags := resource.GetTagsAsMap()
+		if len(tags) > 0 {
+			values := make(map[string]cty.Value, len(tags))
+			for key, value := range tags {
+				values[key] = cty.StringVal(value)
+			}
+			body.SetAttributeValue("tags", cty.MapVal(values))
+		}
+	}
+
+	return block
+}
+
+// lookupAttribute reads a value from the resource's fields, attributes or properties
+func lookupAttribute(resource models.Resource, key string) interface{} {
+	switch key {
+	case "$name":
+		return resource.Name
+	case "$id":
+		return resource.ID
+	case "$region":
+		return resource.Region
+	}
+
+	if value, exists := resource.Attributes[key]; exists {
+		return value
+	}
+	if value, exists := resource.Properties[key]; exists {
+		return value
+	}
+	return nil
+}
+
+// ctyValue converts a decoded JSON value to a cty value, reporting false for empty values
+func ctyValue(value interface{}) (cty.Value, bool) {
+	switch v := value.(type) {
+	case string:
+		if strings.TrimSpace(v) == "" {
+			return cty.NilVal, false
+		}
+		return cty.StringVal(v), true
+	case bool:
+		return cty.BoolVal(v), true
+	case int:
+		return cty.NumberIntVal(int64(v)), true
+	case int64:
+		return cty.NumberIntVal(v), true
+	case float64:
+		return cty.NumberFloatVal(v), true
+	case []string:
+		if len(v) == 0 {
+			return cty.NilVal, false
+		}
+		values := make([]cty.Value, 0, len(v))
+		for _, item := range v {
+			values = append(values, cty.StringVal(item))
+		}
+		return cty.ListVal(values), true
+	case []interface{}:
+		values := make([]cty.Value, 0, len(v))
+		for _, item := range v {
+			converted, ok := ctyValue(item)
+			if !ok {
+				continue
+			}
+			values = append(values, converted)
+		}
+		if len(values) == 0 {
+			return cty.NilVal, false
+		}
+		return cty.TupleVal(values), true
+	case map[string]string:
+		if len(v) == 0 {
+			return cty.NilVal, false
+		}
+		values := make(map[string]cty.Value, len(v))
+		for key, item := range v {
+			values[key] = cty.StringVal(item)
+		}
+		return cty.MapVal(values), true
+	case map[string]interface{}:
+		values := make(map[string]cty.Value, len(v))
+		for key, item := range v {
+			converted, ok := ctyValue(item)
+			if !ok {
+				continue
+			}
+			values[key] = converted
+		}
+		if len(values) == 0 {
+			return cty.NilVal, false
+		}
+		return cty.ObjectVal(values), true
+	default:
+		return cty.NilVal, false
+	}
+}
+
+// initializeAWSAttributeMappings sets up the AWS attribute mappings
+func (g *HCLGenerator) initializeAWSAttributeMappings() {
+	g.RegisterMapping(AttributeMapping{
+		ResourceType: "aws_instance",
+		Attributes: map[string][]string{
+			"ami":                    {"ami", "image_id"},
+			"instance_type":          {"instance_type"},
+			"subnet_id":              {"subnet_id"},
+			"key_name":               {"key_name"},
+			"vpc_security_group_ids": {"vpc_security_group_ids", "security_group_ids"},
+			"availability_zone":      {"availability_zone"},
+		},
+		IncludeTags: true,
+	})
+
+	g.RegisterMapping(AttributeMapping{
+		ResourceType: "aws_s3_bucket",
+		Attributes: map[string][]string{
+			"bucket": {"bucket", "$name"},
+		},
+		IncludeTags: true,
+	})
+
+	g.RegisterMapping(AttributeMapping{
+		ResourceType: "aws_security_group",
+		Attributes: map[string][]string{
+			"name":        {"group_name", "name", "$name"},
+			"description": {"description"},
+			"vpc_id":      {"vpc_id"},
+		},
+		IncludeTags: true,
+	})
+}
@@ -0,0 +1,75 @@
+package tfimport
+
+import (
+	"testing"
+
+	"github.com/catherinevee/driftmgr/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHCLGenerator_Generate(t *testing.T) {
+	gen := NewHCLGenerator()
+
+	result := gen.Generate([]models.Resource{
+		{
+			ID:       "i-0abc123",
+			Name:     "web",
+			Type:     "aws_instance",
+			Provider: "aws",
+			Tags:     map[string]string{"Name": "web"},
+			Attributes: map[string]interface{}{
+				"ami":           "ami-123",
+				"instance_type": "t3.micro",
+			},
+		},
+		{
+			ID:       "logs-bucket",
+			Name:     "logs-bucket",
+			Type:     "s3_bucket",
+			Provider: "aws",
+		},
+		{
+			ID:       "unknown",
+			Type:     "aws_unknown",
+			Provider: "aws",
+		},
+	})
+
+	require.Len(t, result.Resources, 2)
+	require.Len(t, result.Skipped, 1)
+	assert.Equal(t, "unknown", result.Skipped[0].ResourceID)
+
+	instance := result.Resources[0]
+	assert.Equal(t, "aws_instance.web", instance.Address)
+	assert.Contains(t, instance.HCL, `resource "aws_instance" "web"`)
+	assert.Contains(t, instance.HCL, `instance_type = "t3.micro"`)
+	assert.Contains(t, instance.HCL, `Name = "web"`)
+	assert.Equal(t, "terraform import aws_instance.web i-0abc123", instance.ImportCommand.Command)
+
+	bucket := result.Resources[1]
+	assert.Equal(t, "aws_s3_bucket.logs_bucket", bucket.Address)
+	assert.Contains(t, bucket.HCL, `bucket = "logs-bucket"`)
+
+	assert.Contains(t, result.HCL, instance.HCL)
+	assert.Contains(t, result.HCL, bucket.HCL)
+}
+
+func TestHCLGenerator_RegisterMapping(t *testing.T) {
+	gen := NewHCLGenerator()
+	gen.RegisterMapping(AttributeMapping{
+		ResourceType: "aws_vpc",
+		Attributes:   map[string][]string{"cidr_block": {"cidr_block"}},
+	})
+
+	result := gen.Generate([]models.Resource{{
+		ID:         "vpc-1",
+		Name:       "main",
+		Type:       "aws_vpc",
+		Provider:   "aws",
+		Attributes: map[string]interface{}{"cidr_block": "10.0.0.0/16"},
+	}})
+
+	require.Len(t, result.Resources, 1)
+	assert.Contains(t, result.Resources[0].HCL, `cidr_block = "10.0.0.0/16"`)
+}
@@ -0,0 +1,115 @@
+package tfimport
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/catherinevee/driftmgr/pkg/models"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// ImportBlock is a Terraform 1.5+ config-driven import of one resource. HCL holds the
+// resource block followed by the import block, or only the import block when there is no
+// attribute mapping for the type, in which case terraform plan -generate-config-out writes
+// the resource block.
+type ImportBlock struct {
+	Address      string `json:"address"`
+	ResourceType string `json:"resource_type"`
+	Provider     string `json:"provider"`
+	ResourceID   string `json:"resource_id"`
+	ImportID     string `json:"import_id"`
+	HCL          string `json:"hcl"`
+	HasResource  bool   `json:"has_resource"`
+}
+
+// ImportBlockResult is the output of generating import blocks for a set of resources
+type ImportBlockResult struct {
+	HCL     string            `json:"hcl"`
+	Blocks  []ImportBlock     `json:"blocks"`
+	Skipped []SkippedResource `json:"skipped,omitempty"`
+}
+
+// GenerateImportBlocks produces an import block for each resource, with its resource block
+// when the type has an attribute mapping. Output is grouped by provider, and addresses that
+// would repeat get a numeric suffix so the file is valid as a whole.
+func (g *HCLGenerator) GenerateImportBlocks(resources []models.Resource) *ImportBlockResult {
+	result := &ImportBlockResult{Blocks: make([]ImportBlock, 0, len(resources))}
+
+	used := make(map[string]int)
+	byProvider := make(map[string][]ImportBlock)
+	for _, resource := range resources {
+		importCmd, err := g.importGenerator.GenerateImportCommand(resource)
+		if err != nil {
+			result.Skipped = append(result.Skipped, SkippedResource{
+				ResourceID:   resource.ID,
+				ResourceType: resource.Type,
+				Reason:       err.Error(),
+			})
+			continue
+		}
+
+		name := importCmd.ResourceName
+		if name == "" {
+			name = "resource"
+		}
+		address := importCmd.ResourceType + "." + name
+		if used[address]++; used[address] > 1 {
+			name = fmt.Sprintf("%s_%d", name, used[address])
+			address = importCmd.ResourceType + "." + name
+			used[address]++
+		}
+
+		file := hclwrite.NewEmptyFile()
+		mapping, hasResource := g.mappings[importCmd.ResourceType]
+		if hasResource {
+			file.Body().AppendBlock(g.buildBlock(resource, mapping, name))
+			file.Body().AppendNewline()
+		}
+		file.Body().AppendBlock(importBlock(importCmd.ResourceType, name, importCmd.ResourceID))
+
+		byProvider[importCmd.Provider] = append(byProvider[importCmd.Provider], ImportBlock{
+			Address:      address,
+			ResourceType: importCmd.ResourceType,
+			Provider:     importCmd.Provider,
+			ResourceID:   resource.ID,
+			ImportID:     importCmd.ResourceID,
+			HCL:          string(file.Bytes()),
+			HasResource:  hasResource,
+		})
+	}
+
+	providers := make([]string, 0, len(byProvider))
+	for provider := range byProvider {
+		providers = append(providers, provider)
+	}
+	sort.Strings(providers)
+
+	var out strings.Builder
+	for _, provider := range providers {
+		if out.Len() > 0 {
+			out.WriteString("\n")
+		}
+		fmt.Fprintf(&out, "# %s resources\n", strings.ToUpper(provider))
+		for _, block := range byProvider[provider] {
+			out.WriteString("\n")
+			out.WriteString(block.HCL)
+			result.Blocks = append(result.Blocks, block)
+		}
+	}
+	result.HCL = out.String()
+	return result
+}
+
+// importBlock writes an import block adopting id as resourceType.name
+func importBlock(resourceType, name, id string) *hclwrite.Block {
+	block := hclwrite.NewBlock("import", nil)
+	block.Body().SetAttributeTraversal("to", hcl.Traversal{
+		hcl.TraverseRoot{Name: resourceType},
+		hcl.TraverseAttr{Name: name},
+	})
+	block.Body().SetAttributeValue("id", cty.StringVal(id))
+	return block
+}
@@ -0,0 +1,79 @@
+package tfimport
+
+import (
+	"testing"
+
+	"github.com/catherinevee/driftmgr/pkg/models"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHCLGenerator_GenerateImportBlocks(t *testing.T) {
+	gen := NewHCLGenerator()
+
+	result := gen.GenerateImportBlocks([]models.Resource{
+		{
+			ID:         "i-0abc123",
+			Name:       "web",
+			Type:       "aws_instance",
+			Provider:   "aws",
+			Attributes: map[string]interface{}{"ami": "ami-123", "instance_type": "t3.micro"},
+		},
+		{
+			ID:       "i-0def456",
+			Name:     "web",
+			Type:     "aws_instance",
+			Provider: "aws",
+		},
+		{
+			ID:       "projects/p/zones/us-central1-a/instances/vm-1",
+			Name:     "vm-1",
+			Type:     "google_compute_instance",
+			Provider: "gcp",
+			Properties: map[string]interface{}{
+				"project": "p",
+				"zone":    "us-central1-a",
+			},
+		},
+		{
+			ID:       "unknown",
+			Type:     "aws_unknown",
+			Provider: "aws",
+		},
+	})
+
+	require.Len(t, result.Skipped, 1)
+	assert.Equal(t, "unknown", result.Skipped[0].ResourceID)
+	require.Len(t, result.Blocks, 3)
+
+	first := result.Blocks[0]
+	assert.Equal(t, "aws_instance.web", first.Address)
+	assert.True(t, first.HasResource)
+	assert.Contains(t, first.HCL, `resource "aws_instance" "web"`)
+	assert.Contains(t, first.HCL, "to = aws_instance.web\n")
+	assert.Contains(t, first.HCL, `id = "i-0abc123"`)
+
+	assert.Equal(t, "aws_instance.web_2", result.Blocks[1].Address, "repeated addresses are made unique")
+	assert.Contains(t, result.Blocks[1].HCL, "to = aws_instance.web_2\n")
+
+	assert.Equal(t, "gcp", result.Blocks[2].Provider, "providers are grouped in order")
+	assert.NotEmpty(t, result.Blocks[2].ImportID)
+
+	assert.Contains(t, result.HCL, "# AWS resources\n")
+	assert.Contains(t, result.HCL, "# GCP resources\n")
+
+	// The whole file must parse as HCL
+	_, diags := hclsyntax.ParseConfig([]byte(result.HCL), "imports.tf", hcl.InitialPos)
+	assert.False(t, diags.HasErrors(), diags.Error())
+}
+
+func TestImportBlockEscapesTemplates(t *testing.T) {
+	file := hclwrite.NewEmptyFile()
+	file.Body().AppendBlock(importBlock("aws_ssm_parameter", "param", "/app/${env}/key"))
+	_, diags := hclsyntax.ParseConfig(file.Bytes(), "import.tf", hcl.InitialPos)
+	require.False(t, diags.HasErrors(), diags.Error())
+	assert.Contains(t, string(file.Bytes()), `id = "/app/$${env}/key"`)
+}
@@ -0,0 +1,179 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/catherinevee/driftmgr/internal/models"
+	"github.com/google/uuid"
+)
+
+// driftHistorySchema creates the drift history table and the timestamp index trend queries
+// and pruning scan
+const driftHistorySchema = `
+CREATE TABLE IF NOT EXISTS drift_history (
+	id                TEXT PRIMARY KEY,
+	drift_result_id   TEXT NOT NULL DEFAULT '',
+	timestamp         TIMESTAMP NOT NULL,
+	provider          TEXT NOT NULL DEFAULT '',
+	status            TEXT NOT NULL,
+	total_resources   INTEGER NOT NULL DEFAULT 0,
+	drifted_resources INTEGER NOT NULL DEFAULT 0,
+	drift_count       INTEGER NOT NULL DEFAULT 0,
+	duration          INTEGER NOT NULL DEFAULT 0
+);
+CREATE INDEX IF NOT EXISTS idx_drift_history_timestamp ON drift_history (timestamp);`
+
+// MemoryDriftHistoryRepository is an in-memory implementation of DriftHistoryRepository
+type MemoryDriftHistoryRepository struct {
+	entries []*models.DriftHistoryEntry
+	mu      sync.RWMutex
+}
+
+// NewMemoryDriftHistoryRepository creates a new in-memory drift history repository
+func NewMemoryDriftHistoryRepository() *MemoryDriftHistoryRepository {
+	return &MemoryDriftHistoryRepository{
+		entries: make([]*models.DriftHistoryEntry, 0),
+	}
+}
+
+// Record stores a drift history entry
+func (r *MemoryDriftHistoryRepository) Record(ctx context.Context, entry *models.DriftHistoryEntry) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	entryCopy := *entry
+	r.entries = append(r.entries, &entryCopy)
+
+	return nil
+}
+
+// List retrieves history entries recorded since the given time, oldest first
+func (r *MemoryDriftHistoryRepository) List(ctx context.Context, provider string, since time.Time) ([]*models.DriftHistoryEntry, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var entries []*models.DriftHistoryEntry
+	for _, entry := range r.entries {
+		if provider != "" && entry.Provider != provider {
+			continue
+		}
+		if entry.Timestamp.Before(since) {
+			continue
+		}
+
+		// Return a copy to prevent external modifications
+		entryCopy := *entry
+		entries = append(entries, &entryCopy)
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Timestamp.Before(entries[j].Timestamp)
+	})
+
+	return entries, nil
+}
+
+// Prune removes history entries older than the given time
+func (r *MemoryDriftHistoryRepository) Prune(ctx context.Context, olderThan time.Time) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	kept := r.entries[:0]
+	removed := 0
+	for _, entry := range r.entries {
+		if entry.Timestamp.Before(olderThan) {
+			removed++
+			continue
+		}
+		kept = append(kept, entry)
+	}
+	r.entries = kept
+
+	return removed, nil
+}
+
+// SQLDriftHistoryRepository persists drift history in a SQLite database so trends survive restarts
+type SQLDriftHistoryRepository struct {
+	db *sql.DB
+}
+
+// NewSQLDriftHistoryRepository creates a drift history repository on an open SQLite database,
+// creating its table
+func NewSQLDriftHistoryRepository(db *sql.DB) (*SQLDriftHistoryRepository, error) {
+	if _, err := db.Exec(driftHistorySchema); err != nil {
+		return nil, fmt.Errorf("failed to create drift history table: %w", err)
+	}
+	return &SQLDriftHistoryRepository{db: db}, nil
+}
+
+// Record stores a drift history entry, assigning an ID and timestamp when they are unset
+func (r *SQLDriftHistoryRepository) Record(ctx context.Context, entry *models.DriftHistoryEntry) error {
+	if entry.ID == "" {
+		entry.ID = fmt.Sprintf("history-%s", uuid.New().String())
+	}
+	if entry.Timestamp.IsZero() {
+		entry.Timestamp = time.Now().UTC()
+	}
+
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO drift_history (id, drift_result_id, timestamp, provider, status,
+			total_resources, drifted_resources, drift_count, duration)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		entry.ID, entry.DriftResultID, entry.Timestamp.UTC(), entry.Provider, entry.Status,
+		entry.TotalResources, entry.DriftedResources, entry.DriftCount, int64(entry.Duration))
+	if err != nil {
+		return fmt.Errorf("failed to record drift history: %w", err)
+	}
+	return nil
+}
+
+// List retrieves history entries recorded since the given time, oldest first
+func (r *SQLDriftHistoryRepository) List(ctx context.Context, provider string, since time.Time) ([]*models.DriftHistoryEntry, error) {
+	query := `SELECT id, drift_result_id, timestamp, provider, status,
+		total_resources, drifted_resources, drift_count, duration
+		FROM drift_history WHERE timestamp >= ?`
+	args := []interface{}{since.UTC()}
+	if provider != "" {
+		query += ` AND provider = ?`
+		args = append(args, provider)
+	}
+	query += ` ORDER BY timestamp ASC`
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list drift history: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []*models.DriftHistoryEntry
+	for rows.Next() {
+		var entry models.DriftHistoryEntry
+		var duration int64
+		if err := rows.Scan(&entry.ID, &entry.DriftResultID, &entry.Timestamp, &entry.Provider, &entry.Status,
+			&entry.TotalResources, &entry.DriftedResources, &entry.DriftCount, &duration); err != nil {
+			return nil, fmt.Errorf("failed to read drift history: %w", err)
+		}
+		entry.Duration = time.Duration(duration)
+		entries = append(entries, &entry)
+	}
+	return entries, rows.Err()
+}
+
+// Prune removes history entries older than the given time
+func (r *SQLDriftHistoryRepository) Prune(ctx context.Context, olderThan time.Time) (int, error) {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM drift_history WHERE timestamp < ?`, olderThan.UTC())
+	if err != nil {
+		return 0, fmt.Errorf("failed to prune drift history: %w", err)
+	}
+
+	removed, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get pruned row count: %w", err)
+	}
+	return int(removed), nil
+}
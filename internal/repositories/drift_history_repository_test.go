@@ -0,0 +1,70 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/catherinevee/driftmgr/internal/models"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func TestSQLDriftHistoryRepository(t *testing.T) {
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "history.db"))
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	repo, err := NewSQLDriftHistoryRepository(db)
+	if err != nil {
+		t.Fatalf("failed to create repository: %v", err)
+	}
+	ctx := context.Background()
+	now := time.Now().UTC()
+
+	entries := []*models.DriftHistoryEntry{
+		{Provider: "aws", Status: "completed", DriftCount: 1, Timestamp: now.Add(-100 * 24 * time.Hour)},
+		{Provider: "aws", Status: "completed", DriftCount: 3, TotalResources: 10, Duration: 2 * time.Second, Timestamp: now.Add(-2 * time.Hour)},
+		{Provider: "azure", Status: "completed", DriftCount: 2, Timestamp: now.Add(-time.Hour)},
+	}
+	for _, entry := range entries {
+		if err := repo.Record(ctx, entry); err != nil {
+			t.Fatalf("failed to record entry: %v", err)
+		}
+		if entry.ID == "" {
+			t.Error("expected Record to assign an ID")
+		}
+	}
+
+	// Reopening the table on the same database keeps what was recorded
+	if repo, err = NewSQLDriftHistoryRepository(db); err != nil {
+		t.Fatalf("failed to reopen repository: %v", err)
+	}
+
+	aws, err := repo.List(ctx, "aws", now.Add(-7*24*time.Hour))
+	if err != nil {
+		t.Fatalf("failed to list history: %v", err)
+	}
+	if len(aws) != 1 || aws[0].DriftCount != 3 || aws[0].TotalResources != 10 || aws[0].Duration != 2*time.Second {
+		t.Errorf("expected the recent aws entry, got %+v", aws)
+	}
+
+	all, err := repo.List(ctx, "", time.Time{})
+	if err != nil {
+		t.Fatalf("failed to list history: %v", err)
+	}
+	if len(all) != 3 || all[0].DriftCount != 1 || all[2].Provider != "azure" {
+		t.Errorf("expected all entries oldest first, got %+v", all)
+	}
+
+	removed, err := repo.Prune(ctx, now.Add(-90*24*time.Hour))
+	if err != nil {
+		t.Fatalf("failed to prune history: %v", err)
+	}
+	if removed != 1 {
+		t.Errorf("expected 1 pruned entry, got %d", removed)
+	}
+}
@@ -0,0 +1,162 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/catherinevee/driftmgr/internal/models"
+	"github.com/google/uuid"
+)
+
+// ErrDriftPatternNotFound is returned when deleting a drift pattern that does not exist
+var ErrDriftPatternNotFound = errors.New("drift pattern not found")
+
+// driftPatternSchema creates the table of drift patterns registered with the predictor
+const driftPatternSchema = `
+CREATE TABLE IF NOT EXISTS drift_patterns (
+	id            TEXT PRIMARY KEY,
+	resource_type TEXT NOT NULL,
+	field         TEXT NOT NULL,
+	likelihood    REAL NOT NULL,
+	time_window   TEXT NOT NULL DEFAULT '',
+	description   TEXT NOT NULL DEFAULT '',
+	created_by    TEXT NOT NULL DEFAULT '',
+	created_at    TIMESTAMP NOT NULL
+);`
+
+// MemoryDriftPatternRepository is an in-memory drift pattern repository
+type MemoryDriftPatternRepository struct {
+	patterns map[string]*models.DriftPattern
+	mu       sync.RWMutex
+}
+
+// NewMemoryDriftPatternRepository creates a new in-memory drift pattern repository
+func NewMemoryDriftPatternRepository() *MemoryDriftPatternRepository {
+	return &MemoryDriftPatternRepository{
+		patterns: make(map[string]*models.DriftPattern),
+	}
+}
+
+// Create stores a drift pattern, assigning an ID and creation time when they are unset
+func (r *MemoryDriftPatternRepository) Create(ctx context.Context, pattern *models.DriftPattern) error {
+	stampPattern(pattern)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	patternCopy := *pattern
+	r.patterns[pattern.ID] = &patternCopy
+	return nil
+}
+
+// List retrieves all drift patterns, oldest first
+func (r *MemoryDriftPatternRepository) List(ctx context.Context) ([]*models.DriftPattern, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	patterns := make([]*models.DriftPattern, 0, len(r.patterns))
+	for _, pattern := range r.patterns {
+		patternCopy := *pattern
+		patterns = append(patterns, &patternCopy)
+	}
+	sort.Slice(patterns, func(i, j int) bool {
+		if !patterns[i].CreatedAt.Equal(patterns[j].CreatedAt) {
+			return patterns[i].CreatedAt.Before(patterns[j].CreatedAt)
+		}
+		return patterns[i].ID < patterns[j].ID
+	})
+	return patterns, nil
+}
+
+// Delete removes a drift pattern
+func (r *MemoryDriftPatternRepository) Delete(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.patterns[id]; !exists {
+		return ErrDriftPatternNotFound
+	}
+	delete(r.patterns, id)
+	return nil
+}
+
+// SQLDriftPatternRepository persists drift patterns in a SQLite database so they survive restarts
+type SQLDriftPatternRepository struct {
+	db *sql.DB
+}
+
+// NewSQLDriftPatternRepository creates a drift pattern repository on an open SQLite database,
+// creating its table
+func NewSQLDriftPatternRepository(db *sql.DB) (*SQLDriftPatternRepository, error) {
+	if _, err := db.Exec(driftPatternSchema); err != nil {
+		return nil, fmt.Errorf("failed to create drift pattern table: %w", err)
+	}
+	return &SQLDriftPatternRepository{db: db}, nil
+}
+
+// Create stores a drift pattern, assigning an ID and creation time when they are unset
+func (r *SQLDriftPatternRepository) Create(ctx context.Context, pattern *models.DriftPattern) error {
+	stampPattern(pattern)
+
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO drift_patterns (id, resource_type, field, likelihood, time_window, description, created_by, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		pattern.ID, pattern.ResourceType, pattern.Field, pattern.Likelihood, pattern.Window,
+		pattern.Description, pattern.CreatedBy, pattern.CreatedAt.UTC())
+	if err != nil {
+		return fmt.Errorf("failed to create drift pattern: %w", err)
+	}
+	return nil
+}
+
+// List retrieves all drift patterns, oldest first
+func (r *SQLDriftPatternRepository) List(ctx context.Context) ([]*models.DriftPattern, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT id, resource_type, field, likelihood, time_window,
+		description, created_by, created_at FROM drift_patterns ORDER BY created_at ASC, id ASC`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list drift patterns: %w", err)
+	}
+	defer rows.Close()
+
+	patterns := []*models.DriftPattern{}
+	for rows.Next() {
+		var pattern models.DriftPattern
+		if err := rows.Scan(&pattern.ID, &pattern.ResourceType, &pattern.Field, &pattern.Likelihood, &pattern.Window,
+			&pattern.Description, &pattern.CreatedBy, &pattern.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to read drift pattern: %w", err)
+		}
+		patterns = append(patterns, &pattern)
+	}
+	return patterns, rows.Err()
+}
+
+// Delete removes a drift pattern
+func (r *SQLDriftPatternRepository) Delete(ctx context.Context, id string) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM drift_patterns WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete drift pattern: %w", err)
+	}
+	removed, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get deleted row count: %w", err)
+	}
+	if removed == 0 {
+		return ErrDriftPatternNotFound
+	}
+	return nil
+}
+
+// stampPattern assigns a new pattern its ID and creation time
+func stampPattern(pattern *models.DriftPattern) {
+	if pattern.ID == "" {
+		pattern.ID = fmt.Sprintf("pattern-%s", uuid.New().String())
+	}
+	if pattern.CreatedAt.IsZero() {
+		pattern.CreatedAt = time.Now().UTC()
+	}
+}
@@ -0,0 +1,56 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/catherinevee/driftmgr/internal/models"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func TestSQLDriftPatternRepository(t *testing.T) {
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "patterns.db"))
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	repo, err := NewSQLDriftPatternRepository(db)
+	if err != nil {
+		t.Fatalf("failed to create repository: %v", err)
+	}
+	ctx := context.Background()
+
+	resized := &models.DriftPattern{ResourceType: "aws_autoscaling_group", Field: "desired_capacity", Likelihood: 0.9, Window: "24h"}
+	tagged := &models.DriftPattern{ResourceType: "aws_instance", Field: "tags", Likelihood: 0.3}
+	for _, pattern := range []*models.DriftPattern{resized, tagged} {
+		if err := repo.Create(ctx, pattern); err != nil {
+			t.Fatalf("failed to create pattern: %v", err)
+		}
+		if pattern.ID == "" || pattern.CreatedAt.IsZero() {
+			t.Errorf("expected Create to assign an ID and creation time, got %+v", pattern)
+		}
+	}
+
+	// Reopening the table on the same database keeps what was created
+	if repo, err = NewSQLDriftPatternRepository(db); err != nil {
+		t.Fatalf("failed to reopen repository: %v", err)
+	}
+	if err := repo.Delete(ctx, tagged.ID); err != nil {
+		t.Fatalf("failed to delete pattern: %v", err)
+	}
+	if err := repo.Delete(ctx, tagged.ID); !errors.Is(err, ErrDriftPatternNotFound) {
+		t.Errorf("expected ErrDriftPatternNotFound deleting twice, got %v", err)
+	}
+
+	patterns, err := repo.List(ctx)
+	if err != nil {
+		t.Fatalf("failed to list patterns: %v", err)
+	}
+	if len(patterns) != 1 || patterns[0].ID != resized.ID || patterns[0].Window != "24h" || patterns[0].Likelihood != 0.9 {
+		t.Errorf("expected only the autoscaling pattern, got %+v", patterns)
+	}
+}
@@ -0,0 +1,24 @@
+package repositories
+
+import (
+	"sort"
+)
+
+// paginate sorts results by ID, so pages of a map-backed repository are stable between calls,
+// and returns the page starting at offset of at most limit results; a limit of zero or less
+// returns every result from offset
+func paginate[T any](results []T, id func(T) string, offset, limit int) []T {
+	sort.Slice(results, func(i, j int) bool {
+		return id(results[i]) < id(results[j])
+	})
+	if offset >= len(results) {
+		return results[:0]
+	}
+	if offset > 0 {
+		results = results[offset:]
+	}
+	if limit > 0 && limit < len(results) {
+		results = results[:limit]
+	}
+	return results
+}
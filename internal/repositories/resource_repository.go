@@ -97,6 +97,9 @@ func (r *MemoryResourceRepository) GetAll(ctx context.Context, filters services.
 				continue
 			}
 		}
+		if !hasTagKeys(resource, filters.TagKeys) {
+			continue
+		}
 
 		// Return a copy to prevent external modifications
 		resourceCopy := *resource
@@ -104,14 +107,7 @@ func (r *MemoryResourceRepository) GetAll(ctx context.Context, filters services.
 	}
 
 	// Apply pagination
-	if filters.Offset > 0 && filters.Offset < len(results) {
-		results = results[filters.Offset:]
-	}
-	if filters.Limit > 0 && filters.Limit < len(results) {
-		results = results[:filters.Limit]
-	}
-
-	return results, nil
+	return paginate(results, func(r *models.CloudResource) string { return r.ID }, filters.Offset, filters.Limit), nil
 }
 
 // Update updates an existing resource
@@ -203,14 +199,7 @@ func (r *MemoryResourceRepository) Search(ctx context.Context, query services.Re
 	}
 
 	// Apply pagination
-	if query.Offset > 0 && query.Offset < len(results) {
-		results = results[query.Offset:]
-	}
-	if query.Limit > 0 && query.Limit < len(results) {
-		results = results[:query.Limit]
-	}
-
-	return results, nil
+	return paginate(results, func(r *models.CloudResource) string { return r.ID }, query.Offset, query.Limit), nil
 }
 
 // UpdateTags updates tags for a resource
@@ -353,3 +342,13 @@ func containsIgnoreCase(s, substr string) bool {
 	// Simple implementation - in production, you'd use a proper case-insensitive search
 	return len(s) >= len(substr) && (s == substr || len(substr) == 0)
 }
+
+// hasTagKeys reports whether the resource has every given tag key, whatever its value
+func hasTagKeys(resource *models.CloudResource, keys []string) bool {
+	for _, key := range keys {
+		if _, ok := resource.GetTag(key); !ok {
+			return false
+		}
+	}
+	return true
+}
@@ -78,14 +78,7 @@ func (r *MemoryStateRepository) GetAll(ctx context.Context, filters services.Sta
 	}
 
 	// Apply pagination
-	if filters.Offset > 0 && filters.Offset < len(results) {
-		results = results[filters.Offset:]
-	}
-	if filters.Limit > 0 && filters.Limit < len(results) {
-		results = results[:filters.Limit]
-	}
-
-	return results, nil
+	return paginate(results, func(r *models.StateFile) string { return r.ID }, filters.Offset, filters.Limit), nil
 }
 
 // Update updates an existing state file
@@ -0,0 +1,341 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/robfig/cron/v3"
+)
+
+// defaultRunTimeout bounds a single scheduled scan
+const defaultRunTimeout = 30 * time.Minute
+
+// ErrScheduleNotFound is returned when a schedule ID is unknown
+var ErrScheduleNotFound = errors.New("schedule not found")
+
+// cronParser accepts standard five-field expressions and descriptors such as @hourly or @every 15m
+var cronParser = cron.NewParser(cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
+
+// Schedule is a recurring discovery and drift scan for a provider
+type Schedule struct {
+	ID             string     `json:"id"`
+	Name           string     `json:"name"`
+	Provider       string     `json:"provider"`
+	CronExpression string     `json:"cron_expression"`
+	DriftThreshold int        `json:"drift_threshold,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	LastRun        *time.Time `json:"last_run,omitempty"`
+	NextRun        *time.Time `json:"next_run,omitempty"`
+	LastResult     *RunResult `json:"last_result,omitempty"`
+
+	// Remediation policies applied after a scan act as CreatedBy, limited to the drift within
+	// Scopes; no scopes leave them unrestricted. They remediate without approval only when
+	// RemediationAdmin records that CreatedBy held the remediation admin permission.
+	CreatedBy        string   `json:"created_by,omitempty"`
+	Scopes           []string `json:"scopes,omitempty"`
+	RemediationAdmin bool     `json:"remediation_admin"`
+}
+
+// RunResult summarizes one scheduled scan
+type RunResult struct {
+	StartedAt     time.Time     `json:"started_at"`
+	Duration      time.Duration `json:"duration"`
+	ResourceCount int           `json:"resource_count"`
+	DriftCount    int           `json:"drift_count"`
+	DriftResultID string        `json:"drift_result_id,omitempty"`
+	Error         string        `json:"error,omitempty"`
+
+	// AutoRemediated counts the drift remediation policies applied unattended, and
+	// RemediationAlerts the drift they left for a person
+	AutoRemediated    int `json:"auto_remediated,omitempty"`
+	RemediationAlerts int `json:"remediation_alerts,omitempty"`
+}
+
+// RunFunc performs the discovery and drift scan for a schedule
+type RunFunc func(ctx context.Context, schedule *Schedule) (*RunResult, error)
+
+// NotifyFunc is called when a scan's drift count crosses the schedule's threshold
+type NotifyFunc func(schedule *Schedule, result *RunResult)
+
+// Scheduler runs schedules on their cron expressions and persists them in a Store
+type Scheduler struct {
+	cron      *cron.Cron
+	store     Store
+	run       RunFunc
+	notify    NotifyFunc
+	schedules map[string]*Schedule
+	specs     map[string]cron.Schedule
+	entries   map[string]cron.EntryID
+	mu        sync.RWMutex
+}
+
+// NewScheduler creates a scheduler that executes run for each due schedule.
+// Overlapping runs of the same schedule are skipped.
+func NewScheduler(store Store, run RunFunc) *Scheduler {
+	return &Scheduler{
+		cron:      cron.New(cron.WithParser(cronParser), cron.WithChain(cron.Recover(cron.DefaultLogger))),
+		store:     store,
+		run:       run,
+		schedules: make(map[string]*Schedule),
+		specs:     make(map[string]cron.Schedule),
+		entries:   make(map[string]cron.EntryID),
+	}
+}
+
+// SetNotifier sets the function called when a drift threshold is crossed
+func (s *Scheduler) SetNotifier(notify NotifyFunc) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.notify = notify
+}
+
+// Load restores persisted schedules
+func (s *Scheduler) Load() error {
+	schedules, err := s.store.Load()
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, schedule := range schedules {
+		if err := s.addLocked(schedule); err != nil {
+			log.Printf("Skipping schedule %s: %v", schedule.ID, err)
+		}
+	}
+	return nil
+}
+
+// Start begins running schedules in the background
+func (s *Scheduler) Start() {
+	s.cron.Start()
+}
+
+// Stop stops scheduling new runs and waits for running scans to finish or ctx to expire
+func (s *Scheduler) Stop(ctx context.Context) {
+	select {
+	case <-s.cron.Stop().Done():
+	case <-ctx.Done():
+	}
+}
+
+// Create validates and adds a new schedule
+func (s *Scheduler) Create(schedule *Schedule) (*Schedule, error) {
+	if strings.TrimSpace(schedule.Provider) == "" {
+		return nil, fmt.Errorf("provider is required")
+	}
+	if schedule.DriftThreshold < 0 {
+		return nil, fmt.Errorf("drift_threshold must not be negative")
+	}
+
+	created := *schedule
+	created.ID = "schedule-" + uuid.New().String()
+	created.CreatedAt = time.Now().UTC()
+	created.LastRun = nil
+	created.LastResult = nil
+	if created.Name == "" {
+		created.Name = fmt.Sprintf("%s drift scan", created.Provider)
+	}
+
+	s.mu.Lock()
+	if err := s.addLocked(&created); err != nil {
+		s.mu.Unlock()
+		return nil, err
+	}
+	result := s.copyLocked(created.ID)
+	snapshot := s.listLocked()
+	s.mu.Unlock()
+
+	if err := s.store.Save(snapshot); err != nil {
+		s.Delete(created.ID)
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// List returns all schedules, oldest first
+func (s *Scheduler) List() []*Schedule {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.listLocked()
+}
+
+// Get returns a schedule by ID
+func (s *Scheduler) Get(id string) (*Schedule, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if _, ok := s.schedules[id]; !ok {
+		return nil, ErrScheduleNotFound
+	}
+	return s.copyLocked(id), nil
+}
+
+// Delete removes a schedule
+func (s *Scheduler) Delete(id string) error {
+	s.mu.Lock()
+	entry, ok := s.entries[id]
+	if !ok {
+		s.mu.Unlock()
+		return ErrScheduleNotFound
+	}
+	s.cron.Remove(entry)
+	delete(s.entries, id)
+	delete(s.specs, id)
+	delete(s.schedules, id)
+	snapshot := s.listLocked()
+	s.mu.Unlock()
+
+	return s.store.Save(snapshot)
+}
+
+// RunNow runs a schedule immediately, outside its cron expression
+func (s *Scheduler) RunNow(ctx context.Context, id string) (*RunResult, error) {
+	s.mu.RLock()
+	_, ok := s.schedules[id]
+	s.mu.RUnlock()
+	if !ok {
+		return nil, ErrScheduleNotFound
+	}
+
+	return s.execute(ctx, id), nil
+}
+
+// AddJob runs job on a cron expression until the scheduler stops, skipping a run while the
+// previous one is still going. Unlike schedules, jobs are set up by the server itself: they
+// are not persisted or listed.
+func (s *Scheduler) AddJob(name, expression string, job func(ctx context.Context)) error {
+	spec, err := cronParser.Parse(expression)
+	if err != nil {
+		return fmt.Errorf("invalid cron expression %q: %w", expression, err)
+	}
+	s.cron.Schedule(spec, cron.FuncJob(skipIfRunning(name, func() {
+		ctx, cancel := context.WithTimeout(context.Background(), defaultRunTimeout)
+		defer cancel()
+		job(ctx)
+	})))
+	return nil
+}
+
+// skipIfRunning wraps a cron job so a run is skipped while the previous one is still going
+func skipIfRunning(name string, run func()) func() {
+	running := make(chan struct{}, 1)
+	return func() {
+		select {
+		case running <- struct{}{}:
+			defer func() { <-running }()
+		default:
+			log.Printf("Skipping %s: previous run still in progress", name)
+			return
+		}
+		run()
+	}
+}
+
+// addLocked registers a schedule with the cron runner. The caller must hold s.mu.
+func (s *Scheduler) addLocked(schedule *Schedule) error {
+	spec, err := cronParser.Parse(schedule.CronExpression)
+	if err != nil {
+		return fmt.Errorf("invalid cron expression %q: %w", schedule.CronExpression, err)
+	}
+
+	id := schedule.ID
+	entry := s.cron.Schedule(spec, cron.FuncJob(skipIfRunning("scheduled scan "+id, func() {
+		ctx, cancel := context.WithTimeout(context.Background(), defaultRunTimeout)
+		defer cancel()
+		s.execute(ctx, id)
+	})))
+
+	s.schedules[id] = schedule
+	s.specs[id] = spec
+	s.entries[id] = entry
+	return nil
+}
+
+// execute runs a schedule, records the result and notifies when the drift threshold is crossed
+func (s *Scheduler) execute(ctx context.Context, id string) *RunResult {
+	schedule, err := s.Get(id)
+	if err != nil {
+		return &RunResult{Error: err.Error()}
+	}
+
+	start := time.Now().UTC()
+	result, err := s.run(ctx, schedule)
+	if result == nil {
+		result = &RunResult{}
+	}
+	result.StartedAt = start
+	result.Duration = time.Since(start)
+	if err != nil {
+		result.Error = err.Error()
+		log.Printf("Scheduled scan %s (%s) failed: %v", schedule.Name, id, err)
+	}
+
+	s.mu.Lock()
+	current, ok := s.schedules[id]
+	if !ok {
+		// Deleted while running
+		s.mu.Unlock()
+		return result
+	}
+	previous := current.LastResult
+	current.LastRun = &start
+	current.LastResult = result
+	notify := s.notify
+	updated := s.copyLocked(id)
+	snapshot := s.listLocked()
+	s.mu.Unlock()
+
+	if err := s.store.Save(snapshot); err != nil {
+		log.Printf("Failed to save schedule %s after run: %v", id, err)
+	}
+
+	if notify != nil && thresholdCrossed(updated.DriftThreshold, previous, result) {
+		notify(updated, result)
+	}
+
+	return result
+}
+
+// thresholdCrossed reports whether a run reached the threshold when the previous run had not,
+// so a schedule that stays above its threshold notifies once rather than on every run
+func thresholdCrossed(threshold int, previous, current *RunResult) bool {
+	if threshold <= 0 || current.Error != "" || current.DriftCount < threshold {
+		return false
+	}
+	return previous == nil || previous.Error != "" || previous.DriftCount < threshold
+}
+
+// copyLocked returns a copy of a schedule with its next run time filled in. The caller must hold s.mu.
+func (s *Scheduler) copyLocked(id string) *Schedule {
+	schedule := *s.schedules[id]
+	if spec, ok := s.specs[id]; ok {
+		next := spec.Next(time.Now()).UTC()
+		schedule.NextRun = &next
+	}
+	return &schedule
+}
+
+// listLocked returns copies of all schedules, oldest first. The caller must hold s.mu.
+func (s *Scheduler) listLocked() []*Schedule {
+	schedules := make([]*Schedule, 0, len(s.schedules))
+	for id := range s.schedules {
+		schedules = append(schedules, s.copyLocked(id))
+	}
+	sort.Slice(schedules, func(i, j int) bool {
+		if schedules[i].CreatedAt.Equal(schedules[j].CreatedAt) {
+			return schedules[i].ID < schedules[j].ID
+		}
+		return schedules[i].CreatedAt.Before(schedules[j].CreatedAt)
+	})
+	return schedules
+}
@@ -0,0 +1,202 @@
+package scheduler
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// openTestStore opens a schedule store on the SQLite database at path, closing it when the
+// test ends
+func openTestStore(t *testing.T, path string) *SQLStore {
+	t.Helper()
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	store, err := NewSQLStore(db)
+	if err != nil {
+		t.Fatalf("failed to create schedule store: %v", err)
+	}
+	return store
+}
+
+func TestCreateValidatesCronExpression(t *testing.T) {
+	s := NewScheduler(openTestStore(t, filepath.Join(t.TempDir(), "driftmgr.db")), nil)
+
+	if _, err := s.Create(&Schedule{Provider: "aws", CronExpression: "not a cron"}); err == nil {
+		t.Error("expected an error for an invalid cron expression")
+	}
+	if _, err := s.Create(&Schedule{CronExpression: "@hourly"}); err == nil {
+		t.Error("expected an error when provider is missing")
+	}
+
+	schedule, err := s.Create(&Schedule{Provider: "aws", CronExpression: "*/15 * * * *"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if schedule.ID == "" || schedule.NextRun == nil {
+		t.Errorf("expected an ID and next run time, got %+v", schedule)
+	}
+}
+
+func TestSchedulesSurviveRestart(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "driftmgr.db")
+
+	first := NewScheduler(openTestStore(t, path), nil)
+	created, err := first.Create(&Schedule{Name: "nightly", Provider: "azure", CronExpression: "0 2 * * *",
+		CreatedBy: "jane", Scopes: []string{"azure:sub-1"}, RemediationAdmin: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := first.Create(&Schedule{Provider: "gcp", CronExpression: "@daily"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	second := NewScheduler(openTestStore(t, path), nil)
+	if err := second.Load(); err != nil {
+		t.Fatalf("failed to load schedules: %v", err)
+	}
+	if got := len(second.List()); got != 2 {
+		t.Fatalf("expected 2 schedules after restart, got %d", got)
+	}
+	restored, err := second.Get(created.ID)
+	if err != nil {
+		t.Fatalf("failed to get schedule: %v", err)
+	}
+	if restored.CreatedBy != "jane" || len(restored.Scopes) != 1 || restored.Scopes[0] != "azure:sub-1" || !restored.RemediationAdmin {
+		t.Errorf("expected the creator, scopes and permission to survive restart, got %+v", restored)
+	}
+
+	if err := second.Delete(created.ID); err != nil {
+		t.Fatalf("failed to delete schedule: %v", err)
+	}
+	if _, err := second.Get(created.ID); !errors.Is(err, ErrScheduleNotFound) {
+		t.Errorf("expected ErrScheduleNotFound, got %v", err)
+	}
+
+	third := NewScheduler(openTestStore(t, path), nil)
+	if err := third.Load(); err != nil {
+		t.Fatalf("failed to load schedules: %v", err)
+	}
+	if got := len(third.List()); got != 1 {
+		t.Errorf("expected 1 schedule after delete, got %d", got)
+	}
+}
+
+func TestRunNotifiesWhenThresholdCrossed(t *testing.T) {
+	driftCounts := []int{1, 5, 7, 2, 6}
+	run := 0
+	s := NewScheduler(openTestStore(t, filepath.Join(t.TempDir(), "driftmgr.db")), func(ctx context.Context, schedule *Schedule) (*RunResult, error) {
+		count := driftCounts[run]
+		run++
+		return &RunResult{ResourceCount: 10, DriftCount: count}, nil
+	})
+
+	var mu sync.Mutex
+	var notified []int
+	s.SetNotifier(func(schedule *Schedule, result *RunResult) {
+		mu.Lock()
+		defer mu.Unlock()
+		notified = append(notified, result.DriftCount)
+	})
+
+	schedule, err := s.Create(&Schedule{Provider: "aws", CronExpression: "@hourly", DriftThreshold: 5})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for range driftCounts {
+		if _, err := s.RunNow(context.Background(), schedule.ID); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	// Notified when the count first reaches 5 and again after dropping back below and rising to 6
+	if len(notified) != 2 || notified[0] != 5 || notified[1] != 6 {
+		t.Errorf("expected notifications for 5 and 6, got %v", notified)
+	}
+
+	updated, _ := s.Get(schedule.ID)
+	if updated.LastRun == nil || updated.LastResult == nil || updated.LastResult.DriftCount != 6 {
+		t.Errorf("expected last run to be recorded, got %+v", updated)
+	}
+}
+
+func TestRunRecordsFailure(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "driftmgr.db")
+	s := NewScheduler(openTestStore(t, path), func(ctx context.Context, schedule *Schedule) (*RunResult, error) {
+		return nil, errors.New("credentials expired")
+	})
+
+	schedule, err := s.Create(&Schedule{Provider: "aws", CronExpression: "@hourly"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	result, err := s.RunNow(context.Background(), schedule.ID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Error != "credentials expired" {
+		t.Errorf("expected run error to be recorded, got %q", result.Error)
+	}
+
+	restarted := NewScheduler(openTestStore(t, path), nil)
+	if err := restarted.Load(); err != nil {
+		t.Fatalf("failed to load schedules: %v", err)
+	}
+	reloaded, err := restarted.Get(schedule.ID)
+	if err != nil {
+		t.Fatalf("failed to get schedule: %v", err)
+	}
+	if reloaded.LastRun == nil || reloaded.LastResult == nil || reloaded.LastResult.Error != "credentials expired" {
+		t.Errorf("expected the last run to survive a restart, got %+v", reloaded)
+	}
+}
+
+func TestAddJob(t *testing.T) {
+	s := NewScheduler(openTestStore(t, filepath.Join(t.TempDir(), "driftmgr.db")), nil)
+	if err := s.AddJob("backups", "not a cron", func(ctx context.Context) {}); err == nil {
+		t.Error("expected an invalid cron expression to be rejected")
+	}
+	if err := s.AddJob("backups", "@every 1h", func(ctx context.Context) {}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := len(s.List()); got != 0 {
+		t.Errorf("expected jobs not to be listed as schedules, got %d", got)
+	}
+}
+
+func TestSkipIfRunning(t *testing.T) {
+	started, release := make(chan struct{}), make(chan struct{})
+	runs := 0
+	job := skipIfRunning("test job", func() {
+		runs++
+		if runs == 1 {
+			close(started)
+			<-release
+		}
+	})
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		job()
+	}()
+	<-started
+	job() // skipped: the first run is still going
+	close(release)
+	<-done
+	job()
+
+	if runs != 2 {
+		t.Errorf("expected the overlapping run to be skipped, got %d runs", runs)
+	}
+}
@@ -0,0 +1,159 @@
+package scheduler
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"sync"
+)
+
+// Store persists schedules so they survive restarts
+type Store interface {
+	Load() ([]*Schedule, error)
+	Save(schedules []*Schedule) error
+}
+
+// scheduleSchema creates the scan schedules table
+const scheduleSchema = `
+CREATE TABLE IF NOT EXISTS scan_schedules (
+	id                TEXT PRIMARY KEY,
+	name              TEXT NOT NULL DEFAULT '',
+	provider          TEXT NOT NULL,
+	cron_expression   TEXT NOT NULL,
+	drift_threshold   INTEGER NOT NULL DEFAULT 0,
+	created_at        TIMESTAMP NOT NULL,
+	last_run          TIMESTAMP,
+	last_result       TEXT,
+	created_by        TEXT NOT NULL DEFAULT '',
+	scopes            TEXT NOT NULL DEFAULT '[]',
+	remediation_admin BOOLEAN NOT NULL DEFAULT 0
+);`
+
+// SQLStore persists schedules in a SQL database
+type SQLStore struct {
+	db *sql.DB
+	mu sync.Mutex
+}
+
+// NewSQLStore creates a schedule store on an open database, creating its table
+func NewSQLStore(db *sql.DB) (*SQLStore, error) {
+	if _, err := db.Exec(scheduleSchema); err != nil {
+		return nil, fmt.Errorf("failed to create scan schedules table: %w", err)
+	}
+	// Tables created before schedules recorded their creator lack these columns
+	for _, column := range []struct{ name, definition string }{
+		{"created_by", "TEXT NOT NULL DEFAULT ''"},
+		{"scopes", "TEXT NOT NULL DEFAULT '[]'"},
+		{"remediation_admin", "BOOLEAN NOT NULL DEFAULT 0"},
+	} {
+		if err := addScheduleColumn(db, column.name, column.definition); err != nil {
+			return nil, err
+		}
+	}
+	return &SQLStore{db: db}, nil
+}
+
+// addScheduleColumn adds a column to the scan schedules table unless it already exists
+func addScheduleColumn(db *sql.DB, name, definition string) error {
+	var count int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM pragma_table_info('scan_schedules') WHERE name = ?`, name).Scan(&count); err != nil {
+		return fmt.Errorf("failed to inspect scan schedules table: %w", err)
+	}
+	if count > 0 {
+		return nil
+	}
+	if _, err := db.Exec(fmt.Sprintf(`ALTER TABLE scan_schedules ADD COLUMN %s %s`, name, definition)); err != nil {
+		return fmt.Errorf("failed to add %s to scan schedules: %w", name, err)
+	}
+	return nil
+}
+
+// Load reads all schedules, oldest first
+func (s *SQLStore) Load() ([]*Schedule, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rows, err := s.db.Query(`
+		SELECT id, name, provider, cron_expression, drift_threshold, created_at, last_run, last_result,
+			created_by, scopes, remediation_admin
+		FROM scan_schedules ORDER BY created_at ASC`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schedules: %w", err)
+	}
+	defer rows.Close()
+
+	var schedules []*Schedule
+	for rows.Next() {
+		var schedule Schedule
+		var lastRun sql.NullTime
+		var lastResult sql.NullString
+		var scopes string
+		if err := rows.Scan(&schedule.ID, &schedule.Name, &schedule.Provider, &schedule.CronExpression,
+			&schedule.DriftThreshold, &schedule.CreatedAt, &lastRun, &lastResult,
+			&schedule.CreatedBy, &scopes, &schedule.RemediationAdmin); err != nil {
+			return nil, fmt.Errorf("failed to read schedule: %w", err)
+		}
+		if err := json.Unmarshal([]byte(scopes), &schedule.Scopes); err != nil {
+			return nil, fmt.Errorf("failed to parse scopes of %s: %w", schedule.ID, err)
+		}
+		if lastRun.Valid {
+			schedule.LastRun = &lastRun.Time
+		}
+		if lastResult.Valid && lastResult.String != "" {
+			if err := json.Unmarshal([]byte(lastResult.String), &schedule.LastResult); err != nil {
+				return nil, fmt.Errorf("failed to parse last result of %s: %w", schedule.ID, err)
+			}
+		}
+		schedules = append(schedules, &schedule)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read schedules: %w", err)
+	}
+
+	return schedules, nil
+}
+
+// Save replaces the stored schedules in a single transaction, so a failure never leaves a
+// partial set
+func (s *SQLStore) Save(schedules []*Schedule) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to save schedules: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM scan_schedules`); err != nil {
+		return fmt.Errorf("failed to save schedules: %w", err)
+	}
+	for _, schedule := range schedules {
+		var lastResult interface{}
+		if schedule.LastResult != nil {
+			data, err := json.Marshal(schedule.LastResult)
+			if err != nil {
+				return fmt.Errorf("failed to marshal last result of %s: %w", schedule.ID, err)
+			}
+			lastResult = string(data)
+		}
+		scopes, err := json.Marshal(schedule.Scopes)
+		if err != nil {
+			return fmt.Errorf("failed to marshal scopes of %s: %w", schedule.ID, err)
+		}
+		if _, err := tx.Exec(`
+			INSERT INTO scan_schedules (id, name, provider, cron_expression, drift_threshold, created_at, last_run, last_result,
+				created_by, scopes, remediation_admin)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			schedule.ID, schedule.Name, schedule.Provider, schedule.CronExpression, schedule.DriftThreshold,
+			schedule.CreatedAt, schedule.LastRun, lastResult,
+			schedule.CreatedBy, string(scopes), schedule.RemediationAdmin); err != nil {
+			return fmt.Errorf("failed to save schedule %s: %w", schedule.ID, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to save schedules: %w", err)
+	}
+	return nil
+}
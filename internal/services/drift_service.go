@@ -10,9 +10,11 @@ import (
 
 // DriftService handles drift detection and management business logic
 type DriftService struct {
-	repository      DriftRepository
-	stateService    *StateService
-	resourceService *ResourceService
+	repository       DriftRepository
+	history          DriftHistoryRepository
+	historyRetention time.Duration
+	stateService     *StateService
+	resourceService  *ResourceService
 }
 
 // DriftRepository defines the interface for drift data persistence
@@ -26,6 +28,13 @@ type DriftRepository interface {
 	GetDriftSummary(ctx context.Context, filters DriftFilters) (*DriftSummary, error)
 }
 
+// DriftHistoryRepository defines the interface for drift history persistence
+type DriftHistoryRepository interface {
+	Record(ctx context.Context, entry *models.DriftHistoryEntry) error
+	List(ctx context.Context, provider string, since time.Time) ([]*models.DriftHistoryEntry, error)
+	Prune(ctx context.Context, olderThan time.Time) (int, error)
+}
+
 // DriftFilters represents filters for drift queries
 type DriftFilters struct {
 	ResourceID   string    `json:"resource_id,omitempty"`
@@ -43,12 +52,18 @@ type DriftFilters struct {
 
 // DriftConfig represents configuration for drift detection
 type DriftConfig struct {
-	ResourceID   string       `json:"resource_id,omitempty"`
-	ResourceType string       `json:"resource_type,omitempty"`
-	Provider     string       `json:"provider,omitempty"`
-	Region       string       `json:"region,omitempty"`
-	StateID      string       `json:"state_id,omitempty"`
-	Options      DriftOptions `json:"options,omitempty"`
+	ResourceID   string            `json:"resource_id,omitempty"`
+	ResourceType string            `json:"resource_type,omitempty"`
+	Provider     string            `json:"provider,omitempty"`
+	Region       string            `json:"region,omitempty"`
+	StateID      string            `json:"state_id,omitempty"`
+	Tags         map[string]string `json:"tags,omitempty"`
+	TagKeyExists []string          `json:"tag_key_exists,omitempty"`
+	Options      DriftOptions      `json:"options,omitempty"`
+
+	// Resources, when set, are checked in provider-wide detection instead of the provider's
+	// resources in the repository, e.g. those just found by a live discovery
+	Resources []*models.CloudResource `json:"-"`
 }
 
 // DriftOptions represents additional options for drift detection
@@ -111,6 +126,14 @@ func NewDriftService(repository DriftRepository, stateService *StateService, res
 	}
 }
 
+// SetHistoryRepository sets the repository used to record drift history.
+// Entries older than retention are pruned on each new record; a zero
+// retention keeps history forever.
+func (s *DriftService) SetHistoryRepository(history DriftHistoryRepository, retention time.Duration) {
+	s.history = history
+	s.historyRetention = retention
+}
+
 // DetectDrift performs drift detection for resources
 func (s *DriftService) DetectDrift(ctx context.Context, config DriftConfig) (*DriftResults, error) {
 	start := time.Now()
@@ -176,9 +199,89 @@ func (s *DriftService) DetectDrift(ctx context.Context, config DriftConfig) (*Dr
 		return nil, fmt.Errorf("failed to save drift result: %w", err)
 	}
 
+	if err := s.recordHistory(ctx, result); err != nil {
+		return nil, err
+	}
+
 	return result, nil
 }
 
+// recordHistory records the summary of a drift detection run
+func (s *DriftService) recordHistory(ctx context.Context, result *DriftResults) error {
+	if s.history == nil {
+		return nil
+	}
+
+	totalResources, _ := result.Summary["total_resources"].(int)
+	driftedResources, _ := result.Summary["drifted_resources"].(int)
+
+	entry := &models.DriftHistoryEntry{
+		ID:               fmt.Sprintf("history-%d-%s", time.Now().Unix(), generateShortID()),
+		DriftResultID:    result.ID,
+		Timestamp:        result.DetectedAt,
+		Provider:         result.Provider,
+		Status:           result.Status,
+		TotalResources:   totalResources,
+		DriftedResources: driftedResources,
+		DriftCount:       result.DriftCount,
+		Duration:         result.Duration,
+	}
+
+	if err := s.history.Record(ctx, entry); err != nil {
+		return fmt.Errorf("failed to record drift history: %w", err)
+	}
+
+	if s.historyRetention > 0 {
+		if _, err := s.history.Prune(ctx, time.Now().Add(-s.historyRetention)); err != nil {
+			return fmt.Errorf("failed to prune drift history: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// GetDriftTrend returns a daily drift time series covering the last number of days
+func (s *DriftService) GetDriftTrend(ctx context.Context, provider string, days int) ([]models.DriftTrend, error) {
+	if days <= 0 {
+		days = 30
+	}
+
+	end := time.Now().UTC().Truncate(24 * time.Hour)
+	start := end.AddDate(0, 0, -(days - 1))
+
+	// Pre-fill every day so the series has no gaps
+	trend := make([]models.DriftTrend, days)
+	for i := range trend {
+		trend[i].Date = start.AddDate(0, 0, i)
+	}
+
+	if s.history == nil {
+		return trend, nil
+	}
+
+	entries, err := s.history.List(ctx, provider, start)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list drift history: %w", err)
+	}
+
+	for _, entry := range entries {
+		day := int(entry.Timestamp.UTC().Truncate(24*time.Hour).Sub(start).Hours() / 24)
+		if day < 0 || day >= days {
+			continue
+		}
+
+		point := &trend[day]
+		point.NewDrifts += entry.DriftCount
+		point.TotalResources = entry.TotalResources
+		point.DriftedResources = entry.DriftedResources
+		if entry.TotalResources > 0 {
+			point.DriftPercentage = float64(entry.DriftedResources) / float64(entry.TotalResources) * 100
+		}
+	}
+
+	return trend, nil
+}
+
 // GetDriftResults retrieves drift detection results with optional filtering
 func (s *DriftService) GetDriftResults(ctx context.Context, filters DriftFilters) ([]*models.DriftResult, error) {
 	if filters.Limit <= 0 {
@@ -264,6 +367,10 @@ func (s *DriftService) detectResourceDrift(ctx context.Context, result *DriftRes
 	result.DriftDetails = driftDetails
 	result.DriftDetected = len(driftDetails) > 0
 	result.DriftCount = len(driftDetails)
+	result.Summary = map[string]interface{}{
+		"total_resources":   1,
+		"drifted_resources": boolToInt(result.DriftDetected),
+	}
 
 	// Update result fields
 	result.ResourceID = resource.ID
@@ -281,6 +388,8 @@ func (s *DriftService) detectResourceTypeDrift(ctx context.Context, result *Drif
 		Type:     config.ResourceType,
 		Provider: config.Provider,
 		Region:   config.Region,
+		Tags:     config.Tags,
+		TagKeys:  config.TagKeyExists,
 		Limit:    1000, // Large limit for type-wide detection
 	}
 
@@ -315,6 +424,10 @@ func (s *DriftService) detectResourceTypeDrift(ctx context.Context, result *Drif
 	result.DriftDetails = allDriftDetails
 	result.DriftDetected = len(allDriftDetails) > 0
 	result.DriftCount = len(allDriftDetails)
+	result.Summary = map[string]interface{}{
+		"total_resources":   len(resources),
+		"drifted_resources": driftedCount,
+	}
 	result.ResourceType = config.ResourceType
 	result.Provider = config.Provider
 	result.Region = config.Region
@@ -328,12 +441,17 @@ func (s *DriftService) detectProviderDrift(ctx context.Context, result *DriftRes
 	filters := ResourceFilters{
 		Provider: config.Provider,
 		Region:   config.Region,
+		Tags:     config.Tags,
+		TagKeys:  config.TagKeyExists,
 		Limit:    1000, // Large limit for provider-wide detection
 	}
 
-	resources, err := s.resourceService.ListResources(ctx, filters)
-	if err != nil {
-		return fmt.Errorf("failed to list resources: %w", err)
+	resources := config.Resources
+	if resources == nil {
+		var err error
+		if resources, err = s.resourceService.ListResources(ctx, filters); err != nil {
+			return fmt.Errorf("failed to list resources: %w", err)
+		}
 	}
 
 	// Perform drift detection for each resource
@@ -362,6 +480,10 @@ func (s *DriftService) detectProviderDrift(ctx context.Context, result *DriftRes
 	result.DriftDetails = allDriftDetails
 	result.DriftDetected = len(allDriftDetails) > 0
 	result.DriftCount = len(allDriftDetails)
+	result.Summary = map[string]interface{}{
+		"total_resources":   len(resources),
+		"drifted_resources": driftedCount,
+	}
 	result.Provider = config.Provider
 	result.Region = config.Region
 
@@ -31,3 +31,11 @@ func generateShortID() string {
 func generateDriftID() string {
 	return fmt.Sprintf("drift-%d-%s", time.Now().Unix(), generateShortID())
 }
+
+// boolToInt converts a boolean to 1 or 0
+func boolToInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -37,6 +37,7 @@ type ResourceFilters struct {
 	AccountID string            `json:"account_id,omitempty"`
 	ProjectID string            `json:"project_id,omitempty"`
 	Tags      map[string]string `json:"tags,omitempty"`
+	TagKeys   []string          `json:"tag_keys,omitempty"`
 	State     string            `json:"state,omitempty"`
 	CreatedBy string            `json:"created_by,omitempty"`
 	Limit     int               `json:"limit,omitempty"`
@@ -116,6 +117,17 @@ func (s *ResourceService) ListResources(ctx context.Context, filters ResourceFil
 	return resources, nil
 }
 
+// CountResources counts the resources matching the filters, ignoring their limit and offset,
+// so a page of ListResources can report how many resources there are in all
+func (s *ResourceService) CountResources(ctx context.Context, filters ResourceFilters) (int, error) {
+	filters.Limit, filters.Offset = 0, 0
+	resources, err := s.repository.GetAll(ctx, filters)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count resources: %w", err)
+	}
+	return len(resources), nil
+}
+
 // GetResource retrieves a specific resource by ID
 func (s *ResourceService) GetResource(ctx context.Context, id string) (*models.CloudResource, error) {
 	resource, err := s.repository.GetByID(ctx, id)
@@ -155,6 +155,17 @@ func (s *StateService) ListStateFiles(ctx context.Context, filters StateFilters)
 	return states, nil
 }
 
+// CountStateFiles counts the state files matching the filters, ignoring their limit and
+// offset, so a page of ListStateFiles can report how many state files there are in all
+func (s *StateService) CountStateFiles(ctx context.Context, filters StateFilters) (int, error) {
+	filters.Limit, filters.Offset = 0, 0
+	states, err := s.repository.GetAll(ctx, filters)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count state files: %w", err)
+	}
+	return len(states), nil
+}
+
 // GetStateFile retrieves a specific state file by ID
 func (s *StateService) GetStateFile(ctx context.Context, id string) (*models.StateFile, error) {
 	state, err := s.repository.GetByID(ctx, id)
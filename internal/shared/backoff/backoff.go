@@ -0,0 +1,131 @@
+package backoff
+
+import (
+	"context"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/catherinevee/driftmgr/internal/shared/config"
+)
+
+// Policy configures retries of throttled cloud API calls
+type Policy struct {
+	MaxAttempts int           // total attempts including the first call
+	BaseDelay   time.Duration // delay ceiling for the first retry
+	MaxDelay    time.Duration // upper bound on any single delay
+}
+
+// DefaultPolicy returns the retry policy used when none is configured
+func DefaultPolicy() Policy {
+	return Policy{
+		MaxAttempts: 5,
+		BaseDelay:   500 * time.Millisecond,
+		MaxDelay:    20 * time.Second,
+	}
+}
+
+// PolicyFromDiscoveryConfig builds a policy from discovery configuration, falling back
+// to the defaults for unset fields
+func PolicyFromDiscoveryConfig(cfg config.DiscoveryConfig) Policy {
+	policy := DefaultPolicy()
+	if cfg.RetryCount > 0 {
+		policy.MaxAttempts = cfg.RetryCount + 1
+	}
+	if cfg.RetryDelay > 0 {
+		policy.BaseDelay = cfg.RetryDelay
+	}
+	if cfg.MaxRetryDelay > 0 {
+		policy.MaxDelay = cfg.MaxRetryDelay
+	}
+	if policy.MaxDelay < policy.BaseDelay {
+		policy.MaxDelay = policy.BaseDelay
+	}
+	return policy
+}
+
+// Delay returns the wait before the given retry attempt (1 for the first retry) using
+// exponential backoff with full jitter, so concurrent callers don't retry in lockstep
+func (p Policy) Delay(attempt int) time.Duration {
+	if attempt < 1 {
+		attempt = 1
+	}
+
+	ceiling := p.BaseDelay
+	for i := 1; i < attempt && ceiling < p.MaxDelay; i++ {
+		ceiling *= 2
+	}
+	if ceiling > p.MaxDelay {
+		ceiling = p.MaxDelay
+	}
+	if ceiling <= 0 {
+		return 0
+	}
+
+	return time.Duration(rand.Int63n(int64(ceiling) + 1))
+}
+
+var (
+	policyMu      sync.RWMutex
+	currentPolicy = DefaultPolicy()
+
+	retryMu     sync.Mutex
+	retryCounts = make(map[string]int64)
+)
+
+// SetDefaultPolicy replaces the policy applied to newly constructed cloud clients
+func SetDefaultPolicy(policy Policy) {
+	if policy.MaxAttempts < 1 {
+		policy.MaxAttempts = 1
+	}
+	policyMu.Lock()
+	currentPolicy = policy
+	policyMu.Unlock()
+}
+
+// CurrentPolicy returns the policy applied to newly constructed cloud clients
+func CurrentPolicy() Policy {
+	policyMu.RLock()
+	defer policyMu.RUnlock()
+	return currentPolicy
+}
+
+// RecordRetry counts a retried call against a provider
+func RecordRetry(provider string) {
+	retryMu.Lock()
+	retryCounts[provider]++
+	retryMu.Unlock()
+}
+
+// RetryCounts returns the number of retried calls per provider
+func RetryCounts() map[string]int64 {
+	retryMu.Lock()
+	defer retryMu.Unlock()
+
+	counts := make(map[string]int64, len(retryCounts))
+	for provider, count := range retryCounts {
+		counts[provider] = count
+	}
+	return counts
+}
+
+// Do runs operation until it succeeds, returns an error retryable rejects, or the
+// policy's attempts are exhausted
+func Do(ctx context.Context, policy Policy, provider string, retryable func(error) bool, operation func() error) error {
+	var err error
+	for attempt := 1; ; attempt++ {
+		err = operation()
+		if err == nil || attempt >= policy.MaxAttempts || (retryable != nil && !retryable(err)) {
+			return err
+		}
+
+		RecordRetry(provider)
+		timer := time.NewTimer(policy.Delay(attempt))
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,151 @@
+package backoff
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/catherinevee/driftmgr/internal/shared/config"
+)
+
+func TestPolicyFromDiscoveryConfig(t *testing.T) {
+	policy := PolicyFromDiscoveryConfig(config.DiscoveryConfig{
+		RetryCount: 2,
+		RetryDelay: 100 * time.Millisecond,
+	})
+
+	if policy.MaxAttempts != 3 {
+		t.Errorf("expected 3 attempts, got %d", policy.MaxAttempts)
+	}
+	if policy.BaseDelay != 100*time.Millisecond {
+		t.Errorf("expected base delay 100ms, got %v", policy.BaseDelay)
+	}
+	if policy.MaxDelay != DefaultPolicy().MaxDelay {
+		t.Errorf("expected default max delay, got %v", policy.MaxDelay)
+	}
+}
+
+func TestPolicyDelayBounded(t *testing.T) {
+	policy := Policy{MaxAttempts: 10, BaseDelay: 10 * time.Millisecond, MaxDelay: 50 * time.Millisecond}
+
+	for attempt := 1; attempt <= 10; attempt++ {
+		for i := 0; i < 20; i++ {
+			if delay := policy.Delay(attempt); delay < 0 || delay > policy.MaxDelay {
+				t.Fatalf("attempt %d: delay %v outside [0, %v]", attempt, delay, policy.MaxDelay)
+			}
+		}
+	}
+}
+
+func TestDoRetriesUntilSuccess(t *testing.T) {
+	policy := Policy{MaxAttempts: 4, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond}
+	before := RetryCounts()["test-do"]
+
+	calls := 0
+	err := Do(context.Background(), policy, "test-do", nil, func() error {
+		calls++
+		if calls < 3 {
+			return errors.New("throttled")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if calls != 3 {
+		t.Errorf("expected 3 calls, got %d", calls)
+	}
+	if retries := RetryCounts()["test-do"] - before; retries != 2 {
+		t.Errorf("expected 2 recorded retries, got %d", retries)
+	}
+}
+
+func TestDoStopsOnNonRetryableError(t *testing.T) {
+	policy := Policy{MaxAttempts: 4, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond}
+	permanent := errors.New("access denied")
+
+	calls := 0
+	err := Do(context.Background(), policy, "test-permanent", func(err error) bool {
+		return !errors.Is(err, permanent)
+	}, func() error {
+		calls++
+		return permanent
+	})
+	if !errors.Is(err, permanent) {
+		t.Fatalf("expected permanent error, got %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("expected 1 call, got %d", calls)
+	}
+}
+
+func TestTransportRetriesThrottledResponses(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&requests, 1) < 3 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	policy := Policy{MaxAttempts: 5, BaseDelay: time.Millisecond, MaxDelay: 5 * time.Millisecond}
+	transport := NewTransport(nil, "test-transport")
+	transport.Policy = &policy
+	client := &http.Client{Transport: transport}
+
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("expected 200, got %d", resp.StatusCode)
+	}
+	if got := atomic.LoadInt32(&requests); got != 3 {
+		t.Errorf("expected 3 requests, got %d", got)
+	}
+	if retries := RetryCounts()["test-transport"]; retries != 2 {
+		t.Errorf("expected 2 recorded retries, got %d", retries)
+	}
+}
+
+func TestTransportRetriesGatewayErrorsOnlyWhenIdempotent(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	policy := Policy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: 5 * time.Millisecond}
+	transport := NewTransport(nil, "test-gateway")
+	transport.Policy = &policy
+	client := &http.Client{Transport: transport}
+
+	resp, err := client.Post(server.URL, "application/json", strings.NewReader("{}"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	resp.Body.Close()
+	if got := atomic.LoadInt32(&requests); got != 1 {
+		t.Errorf("expected a POST to be sent once, got %d requests", got)
+	}
+
+	atomic.StoreInt32(&requests, 0)
+	resp, err = client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	resp.Body.Close()
+	if got := atomic.LoadInt32(&requests); got != 3 {
+		t.Errorf("expected a GET to be retried up to 3 attempts, got %d requests", got)
+	}
+}
@@ -0,0 +1,106 @@
+package backoff
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Transport retries throttled and temporarily unavailable HTTP responses for REST-based
+// cloud clients. Gateway errors are only retried for idempotent methods, since the request
+// may already have reached the service.
+type Transport struct {
+	Base     http.RoundTripper
+	Provider string
+	Policy   *Policy // nil uses the current default policy
+}
+
+// NewTransport wraps base with throttling retries; a nil base uses http.DefaultTransport
+func NewTransport(base http.RoundTripper, provider string) *Transport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &Transport{Base: base, Provider: provider}
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	policy := CurrentPolicy()
+	if t.Policy != nil {
+		policy = *t.Policy
+	}
+
+	// Requests whose body can't be replayed are sent once
+	replayable := req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+
+	for attempt := 1; ; attempt++ {
+		resp, err := t.Base.RoundTrip(req)
+		if err != nil || !isRetryable(req.Method, resp.StatusCode) || !replayable || attempt >= policy.MaxAttempts {
+			return resp, err
+		}
+
+		delay := policy.Delay(attempt)
+		if retryAfter := parseRetryAfter(resp.Header.Get("Retry-After")); retryAfter > delay {
+			delay = retryAfter
+		}
+		if delay > policy.MaxDelay {
+			delay = policy.MaxDelay
+		}
+		resp.Body.Close()
+
+		RecordRetry(t.Provider)
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		}
+
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req = req.Clone(req.Context())
+			req.Body = body
+		}
+	}
+}
+
+// isRetryable reports whether a response can be retried: throttling and unavailability
+// mean the request was not processed, while a gateway error may follow a request that was,
+// so it is only retried when repeating the method is safe
+func isRetryable(method string, status int) bool {
+	switch status {
+	case http.StatusTooManyRequests, http.StatusServiceUnavailable:
+		return true
+	case http.StatusBadGateway, http.StatusGatewayTimeout:
+		return isIdempotent(method)
+	}
+	return false
+}
+
+// isIdempotent reports whether repeating a request with the method has the same effect as
+// sending it once
+func isIdempotent(method string) bool {
+	switch method {
+	case "", http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// parseRetryAfter reads a Retry-After header given in seconds or as an HTTP date
+func parseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if when, err := http.ParseTime(value); err == nil {
+		return time.Until(when)
+	}
+	return 0
+}
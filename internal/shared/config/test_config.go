@@ -4,9 +4,23 @@ import "time"
 
 // DiscoveryConfig represents discovery configuration
 type DiscoveryConfig struct {
-	MaxConcurrency int           `json:"max_concurrency"`
-	Timeout        int           `json:"timeout"` // seconds
-	RetryCount     int           `json:"retry_count"`
-	RetryDelay     time.Duration `json:"retry_delay"`
-	CacheTTL       int           `json:"cache_ttl"` // seconds
+	MaxConcurrency int           `json:"max_concurrency" yaml:"max_concurrency"`
+	Timeout        int           `json:"timeout" yaml:"timeout"` // seconds
+	RetryCount     int           `json:"retry_count" yaml:"retry_count"`
+	RetryDelay     time.Duration `json:"retry_delay" yaml:"retry_delay"`
+	MaxRetryDelay  time.Duration `json:"max_retry_delay" yaml:"max_retry_delay"`
+	CacheTTL       int           `json:"cache_ttl" yaml:"cache_ttl"`           // seconds
+	CacheMaxSize   int64         `json:"cache_max_size" yaml:"cache_max_size"` // bytes
+	MaxResources   int           `json:"max_resources" yaml:"max_resources"`   // 0 uses the default, negative disables the cap
+
+	// UtilizationLookbackDays is the window CloudWatch utilization is averaged over; 0 uses 14 days
+	UtilizationLookbackDays int `json:"utilization_lookback_days" yaml:"utilization_lookback_days"`
+
+	// ProviderTimeouts bounds a provider's discovery in seconds, keyed by provider; providers
+	// without one use Timeout, and 5 minutes when that is unset too
+	ProviderTimeouts map[string]int `json:"provider_timeouts" yaml:"provider_timeouts"`
+
+	// MaxConcurrentJobs bounds how many discoveries the API server runs at once, queueing the
+	// rest; 0 uses 2
+	MaxConcurrentJobs int `json:"max_concurrent_jobs" yaml:"max_concurrent_jobs"`
 }
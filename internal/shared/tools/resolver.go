@@ -0,0 +1,121 @@
+package tools
+
+import (
+	"fmt"
+	"log"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Tool describes an external binary driftmgr shells out to
+type Tool struct {
+	Name    string // identifier used to look the tool up, e.g. "terraform"
+	Binary  string // configured binary name or path
+	Feature string // feature that is disabled when the tool is missing
+}
+
+// ToolStatus reports the outcome of resolving a tool
+type ToolStatus struct {
+	Name      string `json:"name"`
+	Binary    string `json:"binary"`
+	Path      string `json:"path,omitempty"`
+	Feature   string `json:"feature"`
+	Available bool   `json:"available"`
+	Error     string `json:"error,omitempty"`
+}
+
+// ToolResolver resolves external binaries to validated absolute paths once, so
+// features that depend on a missing tool can be disabled up front instead of
+// failing deep inside an execution
+type ToolResolver struct {
+	mu       sync.RWMutex
+	statuses map[string]ToolStatus
+	lookPath func(string) (string, error)
+}
+
+// NewToolResolver creates a new tool resolver
+func NewToolResolver() *ToolResolver {
+	return &ToolResolver{
+		statuses: make(map[string]ToolStatus),
+		lookPath: exec.LookPath,
+	}
+}
+
+// Resolve locates a tool, records its status and logs a warning when it is missing
+func (r *ToolResolver) Resolve(tool Tool) ToolStatus {
+	status := ToolStatus{
+		Name:    tool.Name,
+		Binary:  strings.TrimSpace(tool.Binary),
+		Feature: tool.Feature,
+	}
+	if status.Binary == "" {
+		status.Binary = tool.Name
+	}
+
+	path, err := r.lookPath(status.Binary)
+	if err == nil {
+		path, err = filepath.Abs(path)
+	}
+	if err != nil {
+		status.Error = err.Error()
+		log.Printf("WARNING: %s binary %q not found (%v); %s is disabled", tool.Name, status.Binary, err, tool.Feature)
+	} else {
+		status.Path = path
+		status.Available = true
+	}
+
+	r.mu.Lock()
+	r.statuses[tool.Name] = status
+	r.mu.Unlock()
+
+	return status
+}
+
+// ResolveAll resolves each of the given tools
+func (r *ToolResolver) ResolveAll(tools []Tool) []ToolStatus {
+	statuses := make([]ToolStatus, 0, len(tools))
+	for _, tool := range tools {
+		statuses = append(statuses, r.Resolve(tool))
+	}
+	return statuses
+}
+
+// Available reports whether a tool was resolved successfully
+func (r *ToolResolver) Available(name string) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.statuses[name].Available
+}
+
+// Path returns the resolved absolute path of a tool
+func (r *ToolResolver) Path(name string) (string, error) {
+	r.mu.RLock()
+	status, exists := r.statuses[name]
+	r.mu.RUnlock()
+
+	if !exists {
+		return "", fmt.Errorf("tool %s has not been resolved", name)
+	}
+	if !status.Available {
+		return "", fmt.Errorf("%s binary %q is not available: %s", name, status.Binary, status.Error)
+	}
+	return status.Path, nil
+}
+
+// Statuses returns the status of every resolved tool, sorted by name
+func (r *ToolResolver) Statuses() []ToolStatus {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	statuses := make([]ToolStatus, 0, len(r.statuses))
+	for _, status := range r.statuses {
+		statuses = append(statuses, status)
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Name < statuses[j].Name
+	})
+	return statuses
+}
@@ -0,0 +1,86 @@
+package tools
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestToolResolver_ResolveAbsolutePath(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("executable bit not supported on windows")
+	}
+
+	dir := t.TempDir()
+	binary := filepath.Join(dir, "terraform")
+	if err := os.WriteFile(binary, []byte("#!/bin/sh\n"), 0755); err != nil {
+		t.Fatalf("failed to write fake binary: %v", err)
+	}
+
+	resolver := NewToolResolver()
+	status := resolver.Resolve(Tool{Name: "terraform", Binary: binary, Feature: "remediation"})
+
+	if !status.Available {
+		t.Fatalf("expected terraform to be available, got error %q", status.Error)
+	}
+	if !filepath.IsAbs(status.Path) {
+		t.Errorf("expected absolute path, got %q", status.Path)
+	}
+
+	path, err := resolver.Path("terraform")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if path != status.Path {
+		t.Errorf("expected path %q, got %q", status.Path, path)
+	}
+}
+
+func TestToolResolver_MissingTool(t *testing.T) {
+	resolver := NewToolResolver()
+	resolver.lookPath = func(string) (string, error) {
+		return "", errors.New("executable file not found in $PATH")
+	}
+
+	status := resolver.Resolve(Tool{Name: "terraform", Feature: "remediation"})
+	if status.Available {
+		t.Fatal("expected terraform to be unavailable")
+	}
+	if status.Binary != "terraform" {
+		t.Errorf("expected binary to default to the tool name, got %q", status.Binary)
+	}
+	if resolver.Available("terraform") {
+		t.Error("expected Available to report false")
+	}
+	if _, err := resolver.Path("terraform"); err == nil {
+		t.Error("expected an error for a missing tool")
+	}
+	if _, err := resolver.Path("git"); err == nil {
+		t.Error("expected an error for an unresolved tool")
+	}
+}
+
+func TestToolResolver_StatusesSorted(t *testing.T) {
+	resolver := NewToolResolver()
+	resolver.lookPath = func(binary string) (string, error) {
+		return "/usr/bin/" + binary, nil
+	}
+
+	resolver.ResolveAll([]Tool{
+		{Name: "terraform", Feature: "remediation"},
+		{Name: "gh", Feature: "pull requests"},
+		{Name: "git", Feature: "pull requests"},
+	})
+
+	statuses := resolver.Statuses()
+	if len(statuses) != 3 {
+		t.Fatalf("expected 3 statuses, got %d", len(statuses))
+	}
+	for i, name := range []string{"gh", "git", "terraform"} {
+		if statuses[i].Name != name {
+			t.Errorf("expected status %d to be %s, got %s", i, name, statuses[i].Name)
+		}
+	}
+}
@@ -0,0 +1,286 @@
+// Package backups keeps timestamped copies of Terraform state documents, so a state that is
+// corrupted or loses resources to an accidental terraform state rm can be put back.
+package backups
+
+import (
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	backenddiscovery "github.com/catherinevee/driftmgr/internal/discovery/backend"
+)
+
+// ErrBackupNotFound is returned when a backup ID is unknown
+var ErrBackupNotFound = errors.New("backup not found")
+
+// backupIDPattern matches backup IDs: the state's key, then when the backup was taken
+var backupIDPattern = regexp.MustCompile(`^([0-9a-f]{16})-([0-9]+)$`)
+
+// Backup describes one stored copy of a state
+type Backup struct {
+	ID string `json:"id"`
+	// StateKey identifies the state the backup was taken of; backups of the same state share it
+	StateKey  string                         `json:"state_key"`
+	Location  backenddiscovery.StateLocation `json:"location"`
+	Serial    int                            `json:"serial"`
+	Lineage   string                         `json:"lineage,omitempty"`
+	Size      int64                          `json:"size"`
+	SHA256    string                         `json:"sha256"`
+	CreatedAt time.Time                      `json:"created_at"`
+}
+
+// Store keeps backups in a directory, one subdirectory per state holding each backup's
+// compressed document beside its description
+type Store struct {
+	dir string
+	mu  sync.Mutex
+}
+
+// NewStore creates a backup store in dir, which is created on the first backup
+func NewStore(dir string) *Store {
+	return &Store{dir: dir}
+}
+
+// StateKey returns the key backups of a state location are stored under
+func StateKey(location backenddiscovery.StateLocation) string {
+	sum := sha256.Sum256([]byte(location.Type + "|" + location.Directory + "|" + location.Location))
+	return hex.EncodeToString(sum[:8])
+}
+
+// Create backs up a state document read from location. A document identical to the state's
+// latest backup is not stored again; that backup is returned with created false.
+func (s *Store) Create(location backenddiscovery.StateLocation, data []byte) (backup *Backup, created bool, err error) {
+	var header struct {
+		Serial  int    `json:"serial"`
+		Lineage string `json:"lineage"`
+	}
+	if err := json.Unmarshal(data, &header); err != nil {
+		return nil, false, fmt.Errorf("state is not valid JSON: %w", err)
+	}
+	sum := sha256.Sum256(data)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := StateKey(location)
+	existing, err := s.listLocked(key)
+	if err != nil {
+		return nil, false, err
+	}
+	if len(existing) > 0 && existing[0].SHA256 == hex.EncodeToString(sum[:]) {
+		return existing[0], false, nil
+	}
+
+	now := time.Now().UTC()
+	if len(existing) > 0 && !now.After(existing[0].CreatedAt) {
+		// Keep IDs unique and ordered when the clock has not moved on
+		now = existing[0].CreatedAt.Add(time.Nanosecond)
+	}
+	backup = &Backup{
+		ID:        fmt.Sprintf("%s-%d", key, now.UnixNano()),
+		StateKey:  key,
+		Location:  location,
+		Serial:    header.Serial,
+		Lineage:   header.Lineage,
+		Size:      int64(len(data)),
+		SHA256:    hex.EncodeToString(sum[:]),
+		CreatedAt: now,
+	}
+	if err := s.write(backup, data); err != nil {
+		return nil, false, err
+	}
+	return backup, true, nil
+}
+
+// List returns every backup, newest first
+func (s *Store) List() ([]*Backup, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entries, err := os.ReadDir(s.dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return []*Backup{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list backups: %w", err)
+	}
+
+	backups := []*Backup{}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		stateBackups, err := s.listLocked(entry.Name())
+		if err != nil {
+			return nil, err
+		}
+		backups = append(backups, stateBackups...)
+	}
+	sortNewestFirst(backups)
+	return backups, nil
+}
+
+// Get returns a backup and its state document
+func (s *Store) Get(id string) (*Backup, []byte, error) {
+	match := backupIDPattern.FindStringSubmatch(id)
+	if match == nil {
+		return nil, nil, ErrBackupNotFound
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	base := filepath.Join(s.dir, match[1], match[2])
+	backup, err := readDescription(base + ".json")
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil, ErrBackupNotFound
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	data, err := readCompressed(base + ".tfstate.gz")
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read backup %s: %w", id, err)
+	}
+	return backup, data, nil
+}
+
+// Prune removes a state's backups beyond the newest keep, and those older than maxAge when
+// it is positive. The newest backup of a state is always kept.
+func (s *Store) Prune(stateKey string, keep int, maxAge time.Duration) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	backups, err := s.listLocked(stateKey)
+	if err != nil {
+		return 0, err
+	}
+	cutoff := time.Now().Add(-maxAge)
+	removed := 0
+	for i, backup := range backups {
+		if i == 0 || (i < keep && (maxAge <= 0 || backup.CreatedAt.After(cutoff))) {
+			continue
+		}
+		base := s.basePath(backup)
+		if err := os.Remove(base + ".tfstate.gz"); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return removed, fmt.Errorf("failed to remove backup %s: %w", backup.ID, err)
+		}
+		if err := os.Remove(base + ".json"); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return removed, fmt.Errorf("failed to remove backup %s: %w", backup.ID, err)
+		}
+		removed++
+	}
+	return removed, nil
+}
+
+// listLocked returns the backups of one state, newest first. The caller must hold s.mu.
+func (s *Store) listLocked(stateKey string) ([]*Backup, error) {
+	descriptions, err := filepath.Glob(filepath.Join(s.dir, stateKey, "*.json"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list backups: %w", err)
+	}
+	backups := make([]*Backup, 0, len(descriptions))
+	for _, description := range descriptions {
+		backup, err := readDescription(description)
+		if err != nil {
+			return nil, err
+		}
+		backups = append(backups, backup)
+	}
+	sortNewestFirst(backups)
+	return backups, nil
+}
+
+// write stores a backup's document before its description, so a listed backup always has
+// its document
+func (s *Store) write(backup *Backup, data []byte) error {
+	base := s.basePath(backup)
+	if err := os.MkdirAll(filepath.Dir(base), 0700); err != nil {
+		return fmt.Errorf("failed to create backup directory: %w", err)
+	}
+	if err := writeCompressed(base+".tfstate.gz", data); err != nil {
+		return fmt.Errorf("failed to write backup: %w", err)
+	}
+	description, err := json.MarshalIndent(backup, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode backup description: %w", err)
+	}
+	if err := os.WriteFile(base+".json", description, 0600); err != nil {
+		os.Remove(base + ".tfstate.gz")
+		return fmt.Errorf("failed to write backup description: %w", err)
+	}
+	return nil
+}
+
+// basePath returns the path of a backup's files without their extensions
+func (s *Store) basePath(backup *Backup) string {
+	return filepath.Join(s.dir, backup.StateKey, strconv.FormatInt(backup.CreatedAt.UnixNano(), 10))
+}
+
+// readDescription reads a backup's description
+func readDescription(path string) (*Backup, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var backup Backup
+	if err := json.Unmarshal(data, &backup); err != nil {
+		return nil, fmt.Errorf("failed to parse backup description %s: %w", filepath.Base(path), err)
+	}
+	return &backup, nil
+}
+
+// writeCompressed gzips data into a new file, which state documents can contain secrets so
+// only the server may read
+func writeCompressed(path string, data []byte) error {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return err
+	}
+	gz := gzip.NewWriter(file)
+	if _, err := gz.Write(data); err != nil {
+		file.Close()
+		return err
+	}
+	if err := gz.Close(); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// readCompressed reads a gzipped file
+func readCompressed(path string) ([]byte, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		return nil, err
+	}
+	defer gz.Close()
+	return io.ReadAll(gz)
+}
+
+// sortNewestFirst orders backups by when they were taken, newest first
+func sortNewestFirst(backups []*Backup) {
+	sort.Slice(backups, func(i, j int) bool {
+		if !backups[i].CreatedAt.Equal(backups[j].CreatedAt) {
+			return backups[i].CreatedAt.After(backups[j].CreatedAt)
+		}
+		return backups[i].ID > backups[j].ID
+	})
+}
@@ -0,0 +1,93 @@
+package backups
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	backenddiscovery "github.com/catherinevee/driftmgr/internal/discovery/backend"
+)
+
+func testState(serial int) []byte {
+	return []byte(fmt.Sprintf(`{"version": 4, "serial": %d, "lineage": "4f2a", "resources": []}`, serial))
+}
+
+func TestStoreCreate(t *testing.T) {
+	store := NewStore(t.TempDir())
+	location := backenddiscovery.StateLocation{Type: "s3", Directory: "network", Location: "s3://states/network.tfstate"}
+
+	first, created, err := store.Create(location, testState(1))
+	if err != nil || !created {
+		t.Fatalf("expected a new backup, got %v, %v", created, err)
+	}
+	if first.Serial != 1 || first.Lineage != "4f2a" || first.StateKey != StateKey(location) {
+		t.Errorf("expected the backup to describe the state, got %+v", first)
+	}
+
+	// An unchanged state is not backed up again
+	again, created, err := store.Create(location, testState(1))
+	if err != nil || created || again.ID != first.ID {
+		t.Errorf("expected the existing backup for an unchanged state, got %+v, %v, %v", again, created, err)
+	}
+
+	second, created, err := store.Create(location, testState(2))
+	if err != nil || !created {
+		t.Fatalf("expected a new backup of the changed state, got %v, %v", created, err)
+	}
+	other := backenddiscovery.StateLocation{Type: "local", Directory: "app", Location: "app/terraform.tfstate"}
+	if _, _, err := store.Create(other, testState(1)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, _, err := store.Create(location, []byte("not json")); err == nil {
+		t.Error("expected a document that is not JSON to be refused")
+	}
+
+	list, err := store.List()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(list) != 3 || list[1].ID != second.ID || list[2].ID != first.ID {
+		t.Errorf("expected three backups, newest first, got %+v", list)
+	}
+
+	backup, data, err := store.Get(first.ID)
+	if err != nil || backup.Serial != 1 || string(data) != string(testState(1)) {
+		t.Errorf("expected the first backup's document, got %s, %v", data, err)
+	}
+	for _, id := range []string{"unknown", "0123456789abcdef-1", "../" + first.ID} {
+		if _, _, err := store.Get(id); !errors.Is(err, ErrBackupNotFound) {
+			t.Errorf("expected ErrBackupNotFound for %q, got %v", id, err)
+		}
+	}
+}
+
+func TestStorePrune(t *testing.T) {
+	store := NewStore(t.TempDir())
+	location := backenddiscovery.StateLocation{Type: "gcs", Location: "gs://states/default.tfstate"}
+	for serial := 1; serial <= 5; serial++ {
+		if _, _, err := store.Create(location, testState(serial)); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	key := StateKey(location)
+
+	removed, err := store.Prune(key, 3, 0)
+	if err != nil || removed != 2 {
+		t.Fatalf("expected 2 backups beyond the retention to be removed, got %d, %v", removed, err)
+	}
+	list, _ := store.List()
+	if len(list) != 3 || list[0].Serial != 5 || list[2].Serial != 3 {
+		t.Errorf("expected the newest 3 backups to be kept, got %+v", list)
+	}
+
+	// Every backup is older than a nanosecond, but the newest is always kept
+	time.Sleep(time.Millisecond)
+	if removed, err := store.Prune(key, 3, time.Nanosecond); err != nil || removed != 2 {
+		t.Fatalf("expected 2 expired backups to be removed, got %d, %v", removed, err)
+	}
+	list, _ = store.List()
+	if len(list) != 1 || list[0].Serial != 5 {
+		t.Errorf("expected only the newest backup to be kept, got %+v", list)
+	}
+}
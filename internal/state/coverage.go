@@ -0,0 +1,194 @@
+package state
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/catherinevee/driftmgr/pkg/models"
+)
+
+// coverageIDAttributes are the state attributes that can hold a live resource's ID
+var coverageIDAttributes = []string{"id", "arn", "self_link"}
+
+// CoverageBreakdown counts the live resources of one provider or type that are in state
+type CoverageBreakdown struct {
+	LiveResources      int     `json:"live_resources"`
+	ManagedResources   int     `json:"managed_resources"`
+	CoveragePercentage float64 `json:"coverage_percentage"`
+}
+
+// Coverage reports how much of the live infrastructure is managed by state files.
+// CoveragePercentage is the share of live resources found in state, and
+// PerspectivePercentage the share of state resources still found live. Resources that are
+// not in state but are managed by another tool, such as CloudFormation, are counted in
+// ManagedElsewhere by tool rather than as unmanaged.
+type Coverage struct {
+	LiveResources         int                          `json:"live_resources"`
+	ManagedResources      int                          `json:"managed_resources"`
+	UnmanagedResources    int                          `json:"unmanaged_resources"`
+	ManagedElsewhere      int                          `json:"managed_elsewhere"`
+	ByTool                map[string]int               `json:"by_tool,omitempty"`
+	StateResources        int                          `json:"state_resources"`
+	MissingResources      int                          `json:"missing_resources"`
+	CoveragePercentage    float64                      `json:"coverage_percentage"`
+	PerspectivePercentage float64                      `json:"perspective_percentage"`
+	ByProvider            map[string]CoverageBreakdown `json:"by_provider"`
+	ByType                map[string]CoverageBreakdown `json:"by_type"`
+	Unmanaged             []string                     `json:"unmanaged"`
+	Missing               []string                     `json:"missing"`
+}
+
+// CoverageCalculator matches live resources against the resources recorded in state files
+type CoverageCalculator struct {
+	// ids maps each ID a state resource is known by to the resource's address
+	ids       map[string]string
+	addresses map[string]bool
+}
+
+// NewCoverageCalculator creates a calculator with no state loaded
+func NewCoverageCalculator() *CoverageCalculator {
+	return &CoverageCalculator{
+		ids:       make(map[string]string),
+		addresses: make(map[string]bool),
+	}
+}
+
+// AddState records the managed resources of a parsed state file, addressed within source so
+// that states with the same resource addresses are kept apart. Data sources are skipped.
+func (c *CoverageCalculator) AddState(source string, state *TerraformState) {
+	if state == nil {
+		return
+	}
+	for _, resource := range state.Resources {
+		if resource.Mode == "data" {
+			continue
+		}
+		for i, instance := range resource.Instances {
+			address := instanceAddress(resource, i)
+			if source != "" {
+				address = source + ":" + address
+			}
+			c.AddResource(address, instance.Attributes)
+		}
+	}
+}
+
+// instanceAddress returns the address of the i'th instance of a resource, such as
+// module.app.aws_instance.web[0]
+func instanceAddress(resource Resource, i int) string {
+	address := resource.Type + "." + resource.Name
+	if resource.Module != "" {
+		address = resource.Module + "." + address
+	}
+	switch key := resource.Instances[i].IndexKey.(type) {
+	case string:
+		return fmt.Sprintf("%s[%q]", address, key)
+	case nil:
+	default:
+		return fmt.Sprintf("%s[%v]", address, key)
+	}
+	if len(resource.Instances) > 1 {
+		return fmt.Sprintf("%s[%d]", address, i)
+	}
+	return address
+}
+
+// AddResource records one state resource by its address and attributes
+func (c *CoverageCalculator) AddResource(address string, attributes map[string]interface{}) {
+	if c.addresses[address] {
+		return
+	}
+	c.addresses[address] = true
+	for _, key := range coverageIDAttributes {
+		if id, ok := attributes[key].(string); ok && id != "" {
+			c.ids[id] = address
+		}
+	}
+	// Kubernetes objects are discovered by UID, which the kubernetes provider records in
+	// a metadata block rather than in id
+	if metadata, ok := attributes["metadata"].([]interface{}); ok && len(metadata) > 0 {
+		if block, ok := metadata[0].(map[string]interface{}); ok {
+			if uid, ok := block["uid"].(string); ok && uid != "" {
+				c.ids[uid] = address
+			}
+		}
+	}
+}
+
+// Calculate reports the share of live resources that are in state, in total and by
+// provider and type
+func (c *CoverageCalculator) Calculate(live []models.Resource) *Coverage {
+	coverage := &Coverage{
+		LiveResources:  len(live),
+		StateResources: len(c.addresses),
+		ByProvider:     make(map[string]CoverageBreakdown),
+		ByType:         make(map[string]CoverageBreakdown),
+		Unmanaged:      []string{},
+		Missing:        []string{},
+	}
+
+	classifier := NewManagementClassifier()
+	found := make(map[string]bool)
+	for _, resource := range live {
+		address, managed := c.ids[resource.ID]
+		provider := coverage.ByProvider[resource.Provider]
+		resourceType := coverage.ByType[resource.Type]
+		provider.LiveResources++
+		resourceType.LiveResources++
+		if managed {
+			found[address] = true
+			coverage.ManagedResources++
+			provider.ManagedResources++
+			resourceType.ManagedResources++
+		} else if tool := classifier.Classify(resource); tool != "" {
+			if coverage.ByTool == nil {
+				coverage.ByTool = make(map[string]int)
+			}
+			coverage.ManagedElsewhere++
+			coverage.ByTool[string(tool)]++
+		} else {
+			coverage.Unmanaged = append(coverage.Unmanaged, resource.ID)
+		}
+		coverage.ByProvider[resource.Provider] = provider
+		coverage.ByType[resource.Type] = resourceType
+	}
+	for address := range c.addresses {
+		if !found[address] {
+			coverage.Missing = append(coverage.Missing, address)
+		}
+	}
+	sort.Strings(coverage.Unmanaged)
+	sort.Strings(coverage.Missing)
+
+	coverage.UnmanagedResources = len(coverage.Unmanaged)
+	coverage.MissingResources = len(coverage.Missing)
+	coverage.CoveragePercentage = percentage(coverage.ManagedResources, coverage.LiveResources)
+	coverage.PerspectivePercentage = percentage(len(found), coverage.StateResources)
+	for key, breakdown := range coverage.ByProvider {
+		breakdown.CoveragePercentage = percentage(breakdown.ManagedResources, breakdown.LiveResources)
+		coverage.ByProvider[key] = breakdown
+	}
+	for key, breakdown := range coverage.ByType {
+		breakdown.CoveragePercentage = percentage(breakdown.ManagedResources, breakdown.LiveResources)
+		coverage.ByType[key] = breakdown
+	}
+	return coverage
+}
+
+// ApplyTo fills the state and live resource counts and percentages of an analysis summary
+func (c *Coverage) ApplyTo(summary *models.AnalysisSummary) {
+	summary.TotalStateResources = c.StateResources
+	summary.TotalLiveResources = c.LiveResources
+	summary.Missing = c.MissingResources
+	summary.Extra = c.UnmanagedResources
+	summary.CoveragePercentage = c.CoveragePercentage
+	summary.PerspectivePercentage = c.PerspectivePercentage
+}
+
+// percentage returns part as a percentage of total, or 0 when total is 0
+func percentage(part, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(part) / float64(total) * 100
+}
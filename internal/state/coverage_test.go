@@ -0,0 +1,116 @@
+package state
+
+import (
+	"testing"
+
+	"github.com/catherinevee/driftmgr/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCoverageCalculator(t *testing.T) {
+	calculator := NewCoverageCalculator()
+	calculator.AddState("", &TerraformState{Resources: []Resource{
+		{Mode: "managed", Type: "aws_instance", Name: "web", Instances: []Instance{
+			{IndexKey: float64(0), Attributes: map[string]interface{}{"id": "i-1"}},
+			{IndexKey: float64(1), Attributes: map[string]interface{}{"id": "i-2"}},
+		}},
+		{Mode: "managed", Type: "aws_s3_bucket", Name: "logs", Instances: []Instance{
+			{Attributes: map[string]interface{}{"id": "logs", "arn": "arn:aws:s3:::logs"}},
+		}},
+		{Mode: "data", Type: "aws_ami", Name: "ubuntu", Instances: []Instance{
+			{Attributes: map[string]interface{}{"id": "ami-1"}},
+		}},
+	}})
+	calculator.AddResource("google_compute_instance.db", map[string]interface{}{
+		"self_link": "projects/p/zones/z/instances/db",
+	})
+
+	coverage := calculator.Calculate([]models.Resource{
+		{ID: "i-1", Type: "ec2_instance", Provider: "aws"},
+		{ID: "i-3", Type: "ec2_instance", Provider: "aws"},
+		{ID: "arn:aws:s3:::logs", Type: "s3_bucket", Provider: "aws"},
+		{ID: "ami-1", Type: "ami", Provider: "aws"},
+		{ID: "projects/p/zones/z/instances/db", Type: "compute_instance", Provider: "gcp"},
+	})
+
+	assert.Equal(t, 5, coverage.LiveResources)
+	assert.Equal(t, 3, coverage.ManagedResources)
+	assert.Equal(t, 2, coverage.UnmanagedResources)
+	assert.Equal(t, 4, coverage.StateResources, "data sources are not managed resources")
+	assert.Equal(t, 1, coverage.MissingResources, "aws_instance.web[1] is not live")
+	assert.InDelta(t, 60.0, coverage.CoveragePercentage, 0.001)
+	assert.InDelta(t, 75.0, coverage.PerspectivePercentage, 0.001)
+	assert.Equal(t, []string{"ami-1", "i-3"}, coverage.Unmanaged)
+	assert.Equal(t, []string{"aws_instance.web[1]"}, coverage.Missing)
+
+	require.Contains(t, coverage.ByProvider, "aws")
+	assert.Equal(t, CoverageBreakdown{LiveResources: 4, ManagedResources: 2, CoveragePercentage: 50}, coverage.ByProvider["aws"])
+	assert.Equal(t, CoverageBreakdown{LiveResources: 1, ManagedResources: 1, CoveragePercentage: 100}, coverage.ByProvider["gcp"])
+	assert.Equal(t, CoverageBreakdown{LiveResources: 2, ManagedResources: 1, CoveragePercentage: 50}, coverage.ByType["ec2_instance"])
+
+	var summary models.AnalysisSummary
+	coverage.ApplyTo(&summary)
+	assert.Equal(t, 4, summary.TotalStateResources)
+	assert.Equal(t, 5, summary.TotalLiveResources)
+	assert.Equal(t, 1, summary.Missing)
+	assert.Equal(t, 2, summary.Extra)
+	assert.InDelta(t, 60.0, summary.CoveragePercentage, 0.001)
+}
+
+func TestCoverageCalculatorManagedElsewhere(t *testing.T) {
+	coverage := NewCoverageCalculator().Calculate([]models.Resource{
+		{ID: "q-1", Provider: "aws", Tags: map[string]string{"aws:cloudformation:stack-name": "orders"}},
+		{ID: "b-1", Provider: "aws", Tags: map[string]string{"pulumi:project": "site"}},
+		{ID: "i-1", Provider: "aws"},
+	})
+	assert.Equal(t, 2, coverage.ManagedElsewhere)
+	assert.Equal(t, map[string]int{"cloudformation": 1, "pulumi": 1}, coverage.ByTool)
+	assert.Equal(t, 1, coverage.UnmanagedResources)
+	assert.Equal(t, []string{"i-1"}, coverage.Unmanaged)
+}
+
+func TestCoverageCalculatorWithoutResources(t *testing.T) {
+	coverage := NewCoverageCalculator().Calculate(nil)
+	assert.Zero(t, coverage.CoveragePercentage)
+	assert.Zero(t, coverage.PerspectivePercentage)
+	assert.Empty(t, coverage.Unmanaged)
+}
+
+func TestCoverageCalculatorMergesStates(t *testing.T) {
+	network := &TerraformState{Resources: []Resource{
+		{Mode: "managed", Type: "aws_vpc", Name: "main", Instances: []Instance{{Attributes: map[string]interface{}{"id": "vpc-1"}}}},
+	}}
+	compute := &TerraformState{Resources: []Resource{
+		{Mode: "managed", Type: "aws_instance", Name: "main", Instances: []Instance{{Attributes: map[string]interface{}{"id": "i-1"}}}},
+		{Mode: "managed", Type: "aws_vpc", Name: "main", Instances: []Instance{{Attributes: map[string]interface{}{"id": "vpc-2"}}}},
+	}}
+	live := []models.Resource{
+		{ID: "vpc-1", Type: "vpc", Provider: "aws"},
+		{ID: "i-1", Type: "ec2_instance", Provider: "aws"},
+	}
+
+	calculator := NewCoverageCalculator()
+	calculator.AddState("network", network)
+	assert.Equal(t, []string{"i-1"}, calculator.Calculate(live).Unmanaged)
+
+	calculator.AddState("compute", compute)
+	coverage := calculator.Calculate(live)
+	assert.Empty(t, coverage.Unmanaged, "a resource in any state is managed")
+	assert.Equal(t, 3, coverage.StateResources, "states with the same addresses are kept apart")
+	assert.Equal(t, []string{"compute:aws_vpc.main"}, coverage.Missing)
+}
+
+func TestCoverageCalculatorMatchesKubernetesUIDs(t *testing.T) {
+	calculator := NewCoverageCalculator()
+	calculator.AddResource("kubernetes_deployment.web", map[string]interface{}{
+		"id":       "apps/web",
+		"metadata": []interface{}{map[string]interface{}{"name": "web", "namespace": "apps", "uid": "uid-web"}},
+	})
+	coverage := calculator.Calculate([]models.Resource{
+		{ID: "uid-web", Type: "k8s_deployment", Provider: "kubernetes"},
+		{ID: "uid-api", Type: "k8s_deployment", Provider: "kubernetes"},
+	})
+	assert.Equal(t, 1, coverage.ManagedResources)
+	assert.Equal(t, []string{"uid-api"}, coverage.Unmanaged)
+}
@@ -0,0 +1,117 @@
+package state
+
+import (
+	"strings"
+
+	"github.com/catherinevee/driftmgr/pkg/models"
+)
+
+// ManagementTool identifies the infrastructure-as-code tool, other than the Terraform states
+// driftmgr reads, that manages a live resource
+type ManagementTool string
+
+const (
+	ManagedByCloudFormation   ManagementTool = "cloudformation"
+	ManagedByCDK              ManagementTool = "cdk"
+	ManagedByPulumi           ManagementTool = "pulumi"
+	ManagedByElasticBeanstalk ManagementTool = "elasticbeanstalk"
+	ManagedByTerragrunt       ManagementTool = "terragrunt"
+)
+
+// ManagedByProperty is the resource property discovery records the managing tool in
+const ManagedByProperty = "managed_by"
+
+// managedByTagKeys are the conventional tags teams set to name the tool that owns a resource
+var managedByTagKeys = map[string]bool{"managedby": true, "managed-by": true, "managed_by": true}
+
+// managementRule recognizes one tool by a tag key it leaves on the resources it creates
+type managementRule struct {
+	tool   ManagementTool
+	prefix string
+}
+
+// managementRules are checked in order. Elastic Beanstalk and CDK deploy through
+// CloudFormation, so their resources also carry the stack tags and must be matched first.
+var managementRules = []managementRule{
+	{ManagedByElasticBeanstalk, "elasticbeanstalk:"},
+	{ManagedByCDK, "aws:cdk:"},
+	{ManagedByCloudFormation, "aws:cloudformation:"},
+	{ManagedByPulumi, "pulumi:"},
+	{ManagedByPulumi, "pulumi-"},
+	{ManagedByPulumi, "pulumi_"},
+	{ManagedByTerragrunt, "terragrunt"},
+}
+
+// ManagementClassifier recognizes resources created by other infrastructure-as-code tools
+// from their tags, so they are not reported as unmanaged for being absent from Terraform state
+type ManagementClassifier struct{}
+
+// NewManagementClassifier creates a classifier with the built-in rules
+func NewManagementClassifier() *ManagementClassifier {
+	return &ManagementClassifier{}
+}
+
+// Classify returns the tool that manages a resource, or "" when no tool is recognized. Tags
+// set by the tools themselves, such as aws:cloudformation:stack-name, take precedence over a
+// ManagedBy tag naming one of the tools.
+func (c *ManagementClassifier) Classify(resource models.Resource) ManagementTool {
+	// CDK bootstrap resources belong to the CDKToolkit stack
+	if resource.Tags["aws:cloudformation:stack-name"] == "CDKToolkit" {
+		return ManagedByCDK
+	}
+
+	var declared ManagementTool
+	rule := len(managementRules)
+	for key, value := range resource.Tags {
+		key = strings.ToLower(key)
+		for i := 0; i < rule; i++ {
+			if strings.HasPrefix(key, managementRules[i].prefix) {
+				rule = i
+				break
+			}
+		}
+		if managedByTagKeys[key] {
+			declared = declaredTool(value)
+		}
+	}
+	if rule < len(managementRules) {
+		return managementRules[rule].tool
+	}
+	return declared
+}
+
+// Annotate returns a copy of resources with the managing tool recorded in the Properties of
+// each resource another tool manages. The input's property maps are not modified.
+func (c *ManagementClassifier) Annotate(resources []models.Resource) []models.Resource {
+	annotated := make([]models.Resource, len(resources))
+	for i, resource := range resources {
+		if tool := c.Classify(resource); tool != "" {
+			properties := make(map[string]interface{}, len(resource.Properties)+1)
+			for key, value := range resource.Properties {
+				properties[key] = value
+			}
+			properties[ManagedByProperty] = string(tool)
+			resource.Properties = properties
+		}
+		annotated[i] = resource
+	}
+	return annotated
+}
+
+// declaredTool maps the value of a ManagedBy tag to a tool, ignoring Terraform and unknown values
+func declaredTool(value string) ManagementTool {
+	value = strings.ToLower(strings.TrimSpace(value))
+	switch {
+	case strings.Contains(value, "beanstalk"):
+		return ManagedByElasticBeanstalk
+	case strings.Contains(value, "cdk"):
+		return ManagedByCDK
+	case strings.Contains(value, "cloudformation"), value == "cfn":
+		return ManagedByCloudFormation
+	case strings.Contains(value, "pulumi"):
+		return ManagedByPulumi
+	case strings.Contains(value, "terragrunt"):
+		return ManagedByTerragrunt
+	}
+	return ""
+}
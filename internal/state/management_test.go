@@ -0,0 +1,79 @@
+package state
+
+import (
+	"testing"
+
+	"github.com/catherinevee/driftmgr/pkg/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestManagementClassifier(t *testing.T) {
+	tests := []struct {
+		name string
+		tags map[string]string
+		want ManagementTool
+	}{
+		{
+			name: "cloudformation stack",
+			tags: map[string]string{"aws:cloudformation:stack-name": "orders", "aws:cloudformation:logical-id": "Queue"},
+			want: ManagedByCloudFormation,
+		},
+		{
+			name: "elastic beanstalk environment",
+			tags: map[string]string{"aws:cloudformation:stack-name": "awseb-e-123-stack", "elasticbeanstalk:environment-name": "web-prod"},
+			want: ManagedByElasticBeanstalk,
+		},
+		{
+			name: "cdk bootstrap",
+			tags: map[string]string{"aws:cloudformation:stack-name": "CDKToolkit"},
+			want: ManagedByCDK,
+		},
+		{
+			name: "cdk tag",
+			tags: map[string]string{"aws:cloudformation:stack-name": "App", "aws:cdk:path": "App/Bucket/Resource"},
+			want: ManagedByCDK,
+		},
+		{
+			name: "pulumi label",
+			tags: map[string]string{"pulumi-stack": "prod"},
+			want: ManagedByPulumi,
+		},
+		{
+			name: "managed by tag",
+			tags: map[string]string{"ManagedBy": "Pulumi"},
+			want: ManagedByPulumi,
+		},
+		{
+			name: "terragrunt",
+			tags: map[string]string{"managed-by": "terragrunt"},
+			want: ManagedByTerragrunt,
+		},
+		{
+			name: "terraform",
+			tags: map[string]string{"ManagedBy": "terraform"},
+		},
+		{
+			name: "untagged",
+		},
+	}
+
+	classifier := NewManagementClassifier()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, classifier.Classify(models.Resource{ID: "r-1", Tags: tt.tags}))
+		})
+	}
+}
+
+func TestManagementClassifierAnnotate(t *testing.T) {
+	properties := map[string]interface{}{"size": 10}
+	resources := []models.Resource{
+		{ID: "q-1", Tags: map[string]string{"aws:cloudformation:stack-name": "orders"}, Properties: properties},
+		{ID: "i-1"},
+	}
+
+	annotated := NewManagementClassifier().Annotate(resources)
+	assert.Equal(t, map[string]interface{}{"size": 10, ManagedByProperty: "cloudformation"}, annotated[0].Properties)
+	assert.Nil(t, annotated[1].Properties)
+	assert.NotContains(t, properties, ManagedByProperty, "the input is not modified")
+}
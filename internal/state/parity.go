@@ -0,0 +1,195 @@
+package state
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// Parity gap kinds
+const (
+	GapMissing = "missing"
+	GapExtra   = "extra"
+	GapConfig  = "config"
+)
+
+// Parity gap severities
+const (
+	SeverityHigh   = "high"
+	SeverityMedium = "medium"
+	SeverityLow    = "low"
+)
+
+// parityAttributes are the key configuration attributes compared between environments for
+// each resource type, with the severity of a difference
+var parityAttributes = map[string]map[string]string{
+	"aws_instance":                      {"instance_type": SeverityMedium, "ami": SeverityLow},
+	"aws_launch_template":               {"instance_type": SeverityMedium, "image_id": SeverityLow},
+	"aws_autoscaling_group":             {"min_size": SeverityLow, "max_size": SeverityLow},
+	"aws_db_instance":                   {"engine": SeverityHigh, "engine_version": SeverityHigh, "instance_class": SeverityMedium, "multi_az": SeverityMedium, "allocated_storage": SeverityLow},
+	"aws_rds_cluster":                   {"engine": SeverityHigh, "engine_version": SeverityHigh},
+	"aws_elasticache_cluster":           {"engine": SeverityHigh, "engine_version": SeverityHigh, "node_type": SeverityMedium},
+	"aws_elasticache_replication_group": {"engine_version": SeverityHigh, "node_type": SeverityMedium},
+	"aws_eks_cluster":                   {"version": SeverityHigh},
+	"aws_lambda_function":               {"runtime": SeverityHigh, "memory_size": SeverityLow, "timeout": SeverityLow},
+	"aws_ecs_service":                   {"launch_type": SeverityMedium, "desired_count": SeverityLow},
+	"google_compute_instance":           {"machine_type": SeverityMedium},
+	"google_container_cluster":          {"min_master_version": SeverityHigh},
+	"google_sql_database_instance":      {"database_version": SeverityHigh},
+	"azurerm_linux_virtual_machine":     {"size": SeverityMedium},
+	"azurerm_windows_virtual_machine":   {"size": SeverityMedium},
+	"azurerm_virtual_machine":           {"vm_size": SeverityMedium},
+	"azurerm_kubernetes_cluster":        {"kubernetes_version": SeverityHigh},
+}
+
+// ParityGap is one difference between a baseline environment and a target that should
+// mirror it
+type ParityGap struct {
+	Address   string      `json:"address"`
+	Type      string      `json:"type"`
+	Kind      string      `json:"kind"`
+	Attribute string      `json:"attribute,omitempty"`
+	Baseline  interface{} `json:"baseline,omitempty"`
+	Target    interface{} `json:"target,omitempty"`
+	Severity  string      `json:"severity"`
+	Message   string      `json:"message"`
+}
+
+// ParityReport compares the resources and key configuration of two environments.
+// ParityPercentage is the share of baseline resources present in the target without gaps.
+type ParityReport struct {
+	BaselineResources int            `json:"baseline_resources"`
+	TargetResources   int            `json:"target_resources"`
+	Matched           int            `json:"matched"`
+	ParityPercentage  float64        `json:"parity_percentage"`
+	BySeverity        map[string]int `json:"by_severity"`
+	Gaps              []ParityGap    `json:"gaps"`
+}
+
+// environmentResource is a managed resource of an environment
+type environmentResource struct {
+	Type       string
+	Attributes map[string]interface{}
+}
+
+// Environment is the set of managed resources of one environment, keyed by address, such as
+// the states of a workspace or a Terragrunt environment
+type Environment struct {
+	resources map[string]environmentResource
+}
+
+// NewEnvironment creates an environment with no resources
+func NewEnvironment() *Environment {
+	return &Environment{resources: make(map[string]environmentResource)}
+}
+
+// AddState adds the managed resources of a parsed state file. Data sources are skipped, and
+// a resource already added under the same address is kept.
+func (e *Environment) AddState(state *TerraformState) {
+	if state == nil {
+		return
+	}
+	for _, resource := range state.Resources {
+		if resource.Mode == "data" {
+			continue
+		}
+		for i, instance := range resource.Instances {
+			e.AddResource(instanceAddress(resource, i), resource.Type, instance.Attributes)
+		}
+	}
+}
+
+// AddResource adds one managed resource unless one is already added under its address
+func (e *Environment) AddResource(address, resourceType string, attributes map[string]interface{}) {
+	if _, exists := e.resources[address]; exists {
+		return
+	}
+	e.resources[address] = environmentResource{Type: resourceType, Attributes: attributes}
+}
+
+// CompareEnvironments reports the parity gaps of target against baseline: baseline resources
+// target lacks, resources only target has, and differences in the key configuration of the
+// resources both have, such as instance sizes and engine versions
+func CompareEnvironments(baseline, target *Environment) *ParityReport {
+	report := &ParityReport{
+		BaselineResources: len(baseline.resources),
+		TargetResources:   len(target.resources),
+		BySeverity:        make(map[string]int),
+		Gaps:              []ParityGap{},
+	}
+
+	for _, address := range sortedAddresses(baseline.resources) {
+		expected := baseline.resources[address]
+		actual, ok := target.resources[address]
+		if !ok {
+			report.add(ParityGap{
+				Address:  address,
+				Type:     expected.Type,
+				Kind:     GapMissing,
+				Severity: SeverityHigh,
+				Message:  fmt.Sprintf("%s is in the baseline but missing from the target", address),
+			})
+			continue
+		}
+
+		matched := true
+		attributes := parityAttributes[expected.Type]
+		names := make([]string, 0, len(attributes))
+		for name := range attributes {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			want, got := expected.Attributes[name], actual.Attributes[name]
+			if reflect.DeepEqual(want, got) {
+				continue
+			}
+			matched = false
+			report.add(ParityGap{
+				Address:   address,
+				Type:      expected.Type,
+				Kind:      GapConfig,
+				Attribute: name,
+				Baseline:  want,
+				Target:    got,
+				Severity:  attributes[name],
+				Message:   fmt.Sprintf("%s %s is %v in the baseline but %v in the target", address, name, want, got),
+			})
+		}
+		if matched {
+			report.Matched++
+		}
+	}
+
+	for _, address := range sortedAddresses(target.resources) {
+		if _, ok := baseline.resources[address]; ok {
+			continue
+		}
+		report.add(ParityGap{
+			Address:  address,
+			Type:     target.resources[address].Type,
+			Kind:     GapExtra,
+			Severity: SeverityLow,
+			Message:  fmt.Sprintf("%s is only in the target", address),
+		})
+	}
+
+	report.ParityPercentage = percentage(report.Matched, report.BaselineResources)
+	return report
+}
+
+// add records a gap and counts it by severity
+func (r *ParityReport) add(gap ParityGap) {
+	r.Gaps = append(r.Gaps, gap)
+	r.BySeverity[gap.Severity]++
+}
+
+// sortedAddresses returns the addresses of resources in order
+func sortedAddresses(resources map[string]environmentResource) []string {
+	addresses := make([]string, 0, len(resources))
+	for address := range resources {
+		addresses = append(addresses, address)
+	}
+	sort.Strings(addresses)
+	return addresses
+}
@@ -0,0 +1,61 @@
+package state
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompareEnvironments(t *testing.T) {
+	prod := NewEnvironment()
+	prod.AddState(&TerraformState{Resources: []Resource{
+		{Mode: "managed", Type: "aws_instance", Name: "web", Instances: []Instance{
+			{Attributes: map[string]interface{}{"id": "i-prod", "instance_type": "m5.xlarge", "ami": "ami-1"}},
+		}},
+		{Mode: "managed", Type: "aws_db_instance", Name: "main", Instances: []Instance{
+			{Attributes: map[string]interface{}{"engine": "postgres", "engine_version": "15.4", "instance_class": "db.r5.large"}},
+		}},
+		{Mode: "managed", Type: "aws_s3_bucket", Name: "logs", Instances: []Instance{
+			{Attributes: map[string]interface{}{"bucket": "prod-logs"}},
+		}},
+		{Mode: "managed", Type: "aws_wafv2_web_acl", Name: "edge", Instances: []Instance{
+			{Attributes: map[string]interface{}{"name": "edge"}},
+		}},
+		{Mode: "data", Type: "aws_ami", Name: "ubuntu", Instances: []Instance{{}}},
+	}})
+
+	staging := NewEnvironment()
+	staging.AddState(&TerraformState{Resources: []Resource{
+		{Mode: "managed", Type: "aws_instance", Name: "web", Instances: []Instance{
+			{Attributes: map[string]interface{}{"id": "i-staging", "instance_type": "t3.medium", "ami": "ami-1"}},
+		}},
+		{Mode: "managed", Type: "aws_db_instance", Name: "main", Instances: []Instance{
+			{Attributes: map[string]interface{}{"engine": "postgres", "engine_version": "14.9", "instance_class": "db.r5.large"}},
+		}},
+		{Mode: "managed", Type: "aws_s3_bucket", Name: "logs", Instances: []Instance{
+			{Attributes: map[string]interface{}{"bucket": "staging-logs"}},
+		}},
+	}})
+	staging.AddResource("aws_instance.debug", "aws_instance", map[string]interface{}{"instance_type": "t3.micro"})
+
+	report := CompareEnvironments(prod, staging)
+	assert.Equal(t, 4, report.BaselineResources)
+	assert.Equal(t, 4, report.TargetResources)
+	assert.Equal(t, 1, report.Matched, "only the bucket matches; names and IDs are not compared")
+	assert.InDelta(t, 25.0, report.ParityPercentage, 0.001)
+	assert.Equal(t, map[string]int{SeverityHigh: 2, SeverityMedium: 1, SeverityLow: 1}, report.BySeverity)
+
+	require.Len(t, report.Gaps, 4)
+	assert.Equal(t, ParityGap{
+		Address: "aws_db_instance.main", Type: "aws_db_instance", Kind: GapConfig, Attribute: "engine_version",
+		Baseline: "15.4", Target: "14.9", Severity: SeverityHigh,
+		Message: "aws_db_instance.main engine_version is 15.4 in the baseline but 14.9 in the target",
+	}, report.Gaps[0])
+	assert.Equal(t, "instance_type", report.Gaps[1].Attribute)
+	assert.Equal(t, SeverityMedium, report.Gaps[1].Severity)
+	assert.Equal(t, GapMissing, report.Gaps[2].Kind)
+	assert.Equal(t, "aws_wafv2_web_acl.edge", report.Gaps[2].Address)
+	assert.Equal(t, GapExtra, report.Gaps[3].Kind)
+	assert.Equal(t, "aws_instance.debug", report.Gaps[3].Address)
+}
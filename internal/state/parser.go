@@ -10,7 +10,8 @@ import (
 // StateFile represents a Terraform state file
 type StateFile struct {
 	*TerraformState
-	Path string `json:"path,omitempty"`
+	Path string    `json:"path,omitempty"`
+	Tool StateTool `json:"tool,omitempty"`
 }
 
 // State is an alias for TerraformState
@@ -46,8 +47,15 @@ func (p *StateParser) Parse(data []byte) (*StateFile, error) {
 		return nil, fmt.Errorf("failed to parse state: %w", err)
 	}
 
+	// Some tooling records the producing binary alongside the standard fields
+	var producer struct {
+		Generator string `json:"generator"`
+	}
+	_ = json.Unmarshal(data, &producer)
+
 	return &StateFile{
 		TerraformState: &state,
+		Tool:           DetectStateTool(&state, producer.Generator),
 	}, nil
 }
 
@@ -248,7 +256,7 @@ func (p *Parser) normalizeState(state *TerraformState) error {
 // normalizeProviderName normalizes provider names to a consistent format
 func (p *Parser) normalizeProviderName(provider string) string {
 	// Remove registry prefix if present
-	if strings.Contains(provider, "registry.terraform.io/") {
+	if strings.Contains(provider, "registry.terraform.io/") || strings.Contains(provider, "registry.opentofu.org/") {
 		parts := strings.Split(provider, "/")
 		if len(parts) >= 3 {
 			provider = parts[len(parts)-1]
@@ -0,0 +1,92 @@
+package state
+
+import "sort"
+
+// Promotion change actions
+const (
+	PromotionCreate = "create"
+	PromotionUpdate = "update"
+	PromotionDelete = "delete"
+)
+
+// AttributeChange is the change of one attribute from its target value to its baseline value
+type AttributeChange struct {
+	From interface{} `json:"from"`
+	To   interface{} `json:"to"`
+}
+
+// PromotionChange is one change promoting a baseline environment would make to the target
+type PromotionChange struct {
+	Action     string                     `json:"action"`
+	Address    string                     `json:"address"`
+	Type       string                     `json:"type"`
+	Attributes map[string]AttributeChange `json:"attributes,omitempty"`
+}
+
+// PromotionPlan lists the changes that would make a target environment mirror a baseline:
+// resources to create, key configuration to update and resources to delete
+type PromotionPlan struct {
+	Creates int               `json:"creates"`
+	Updates int               `json:"updates"`
+	Deletes int               `json:"deletes"`
+	Changes []PromotionChange `json:"changes"`
+}
+
+// PlanPromotion plans the promotion of baseline to target without applying it. Updates
+// cover the key configuration compared for parity, such as instance sizes and versions,
+// since names and IDs are expected to differ between environments.
+func PlanPromotion(baseline, target *Environment) *PromotionPlan {
+	plan := &PromotionPlan{Changes: []PromotionChange{}}
+	updates := make(map[string]int)
+
+	for _, gap := range CompareEnvironments(baseline, target).Gaps {
+		switch gap.Kind {
+		case GapMissing:
+			plan.Creates++
+			plan.Changes = append(plan.Changes, PromotionChange{
+				Action:     PromotionCreate,
+				Address:    gap.Address,
+				Type:       gap.Type,
+				Attributes: creation(baseline.resources[gap.Address]),
+			})
+		case GapExtra:
+			plan.Deletes++
+			plan.Changes = append(plan.Changes, PromotionChange{Action: PromotionDelete, Address: gap.Address, Type: gap.Type})
+		case GapConfig:
+			i, ok := updates[gap.Address]
+			if !ok {
+				plan.Updates++
+				i = len(plan.Changes)
+				updates[gap.Address] = i
+				plan.Changes = append(plan.Changes, PromotionChange{
+					Action:     PromotionUpdate,
+					Address:    gap.Address,
+					Type:       gap.Type,
+					Attributes: make(map[string]AttributeChange),
+				})
+			}
+			plan.Changes[i].Attributes[gap.Attribute] = AttributeChange{From: gap.Target, To: gap.Baseline}
+		}
+	}
+
+	order := map[string]int{PromotionCreate: 0, PromotionUpdate: 1, PromotionDelete: 2}
+	sort.SliceStable(plan.Changes, func(i, j int) bool {
+		return order[plan.Changes[i].Action] < order[plan.Changes[j].Action]
+	})
+	return plan
+}
+
+// creation returns the key configuration a created resource takes from the baseline
+func creation(resource environmentResource) map[string]AttributeChange {
+	attributes := parityAttributes[resource.Type]
+	if len(attributes) == 0 {
+		return nil
+	}
+	changes := make(map[string]AttributeChange)
+	for name := range attributes {
+		if value, ok := resource.Attributes[name]; ok {
+			changes[name] = AttributeChange{To: value}
+		}
+	}
+	return changes
+}
@@ -0,0 +1,43 @@
+package state
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlanPromotion(t *testing.T) {
+	prod := NewEnvironment()
+	prod.AddResource("aws_db_instance.main", "aws_db_instance", map[string]interface{}{
+		"engine": "postgres", "engine_version": "15.4", "instance_class": "db.r5.large",
+	})
+	prod.AddResource("aws_instance.web", "aws_instance", map[string]interface{}{"instance_type": "m5.large", "ami": "ami-2"})
+	prod.AddResource("aws_sqs_queue.jobs", "aws_sqs_queue", map[string]interface{}{"name": "prod-jobs"})
+
+	staging := NewEnvironment()
+	staging.AddResource("aws_db_instance.main", "aws_db_instance", map[string]interface{}{
+		"engine": "postgres", "engine_version": "14.9", "instance_class": "db.t3.medium",
+	})
+	staging.AddResource("aws_instance.web", "aws_instance", map[string]interface{}{"instance_type": "m5.large", "ami": "ami-1"})
+	staging.AddResource("aws_instance.debug", "aws_instance", map[string]interface{}{"instance_type": "t3.micro"})
+
+	plan := PlanPromotion(prod, staging)
+	assert.Equal(t, 1, plan.Creates)
+	assert.Equal(t, 2, plan.Updates)
+	assert.Equal(t, 1, plan.Deletes)
+	require.Len(t, plan.Changes, 4)
+
+	assert.Equal(t, PromotionChange{Action: PromotionCreate, Address: "aws_sqs_queue.jobs", Type: "aws_sqs_queue"}, plan.Changes[0])
+	assert.Equal(t, PromotionChange{
+		Action: PromotionUpdate, Address: "aws_db_instance.main", Type: "aws_db_instance",
+		Attributes: map[string]AttributeChange{
+			"engine_version": {From: "14.9", To: "15.4"},
+			"instance_class": {From: "db.t3.medium", To: "db.r5.large"},
+		},
+	}, plan.Changes[1])
+	assert.Equal(t, map[string]AttributeChange{"ami": {From: "ami-1", To: "ami-2"}}, plan.Changes[2].Attributes)
+	assert.Equal(t, PromotionChange{Action: PromotionDelete, Address: "aws_instance.debug", Type: "aws_instance"}, plan.Changes[3])
+
+	assert.Empty(t, PlanPromotion(prod, prod).Changes, "an environment mirroring the baseline needs no changes")
+}
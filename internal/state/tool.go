@@ -0,0 +1,54 @@
+package state
+
+import (
+	"os"
+	"strings"
+)
+
+// StateTool identifies the tool that produced a state file
+type StateTool string
+
+const (
+	ToolTerraform StateTool = "terraform"
+	ToolOpenTofu  StateTool = "opentofu"
+)
+
+// TerraformBinaryEnv names the environment variable that overrides the terraform binary,
+// e.g. DRIFTMGR_TF_BINARY=tofu for OpenTofu users
+const TerraformBinaryEnv = "DRIFTMGR_TF_BINARY"
+
+// TerraformBinary returns the binary used to run terraform commands
+func TerraformBinary() string {
+	if binary := strings.TrimSpace(os.Getenv(TerraformBinaryEnv)); binary != "" {
+		return binary
+	}
+	return "terraform"
+}
+
+// DetectStateTool determines whether a state was written by Terraform or OpenTofu.
+// OpenTofu keeps the terraform_version field for compatibility, so the provider
+// registry host is the most reliable signal; generator or version strings that
+// name tofu are also honoured.
+func DetectStateTool(state *TerraformState, generator string) StateTool {
+	if isOpenTofuMarker(generator) {
+		return ToolOpenTofu
+	}
+	if state == nil {
+		return ToolTerraform
+	}
+	if isOpenTofuMarker(state.TerraformVersion) {
+		return ToolOpenTofu
+	}
+	for _, resource := range state.Resources {
+		if strings.Contains(resource.Provider, "registry.opentofu.org") {
+			return ToolOpenTofu
+		}
+	}
+	return ToolTerraform
+}
+
+// isOpenTofuMarker reports whether a generator or version string names OpenTofu
+func isOpenTofuMarker(value string) bool {
+	value = strings.ToLower(value)
+	return strings.Contains(value, "opentofu") || strings.Contains(value, "tofu")
+}
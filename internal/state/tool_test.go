@@ -0,0 +1,70 @@
+package state
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStateParser_DetectsOpenTofu(t *testing.T) {
+	parser := NewStateParser()
+
+	stateFile, err := parser.ParseFile(filepath.Join("testdata", "opentofu.tfstate"))
+	require.NoError(t, err)
+
+	assert.Equal(t, ToolOpenTofu, stateFile.Tool)
+	assert.Equal(t, "1.6.2", stateFile.TerraformVersion)
+	require.Len(t, stateFile.Resources, 1)
+	assert.Equal(t, "aws_s3_bucket", stateFile.Resources[0].Type)
+}
+
+func TestDetectStateTool(t *testing.T) {
+	tests := []struct {
+		name      string
+		state     *TerraformState
+		generator string
+		want      StateTool
+	}{
+		{
+			name: "terraform registry",
+			state: &TerraformState{
+				TerraformVersion: "1.5.0",
+				Resources:        []Resource{{Provider: `provider["registry.terraform.io/hashicorp/aws"]`}},
+			},
+			want: ToolTerraform,
+		},
+		{
+			name:      "generator field",
+			state:     &TerraformState{TerraformVersion: "1.6.0"},
+			generator: "OpenTofu 1.6.0",
+			want:      ToolOpenTofu,
+		},
+		{
+			name: "opentofu registry",
+			state: &TerraformState{
+				Resources: []Resource{{Provider: `provider["registry.opentofu.org/hashicorp/aws"]`}},
+			},
+			want: ToolOpenTofu,
+		},
+		{
+			name: "nil state",
+			want: ToolTerraform,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, DetectStateTool(tt.state, tt.generator))
+		})
+	}
+}
+
+func TestTerraformBinary(t *testing.T) {
+	t.Setenv(TerraformBinaryEnv, "")
+	assert.Equal(t, "terraform", TerraformBinary())
+
+	t.Setenv(TerraformBinaryEnv, "tofu")
+	assert.Equal(t, "tofu", TerraformBinary())
+}
@@ -528,6 +528,11 @@ func FindTerragruntFiles(rootDir string) ([]string, error) {
 			return err
 		}
 
+		// Skip the copies Terragrunt makes of each unit in its cache
+		if info.IsDir() && info.Name() == ".terragrunt-cache" {
+			return filepath.SkipDir
+		}
+
 		if !info.IsDir() && strings.HasSuffix(path, "terragrunt.hcl") {
 			files = append(files, path)
 		}
@@ -200,12 +200,20 @@ func (r *DependencyResolver) GetExecutionOrder(includeSkipped bool) (*ExecutionO
 	// Perform topological sort
 	indegree := make(map[string]int)
 
-	// Calculate indegree for each module
+	// Calculate indegree for each module, counting only the dependencies being ordered so
+	// dependencies outside the tree or skipped do not block their dependents
 	for module := range r.graph.Modules {
 		if !includeSkipped && r.graph.Modules[module].Config.Skip {
 			continue
 		}
-		indegree[module] = len(r.graph.Dependencies[module])
+		indegree[module] = 0
+	}
+	for module := range indegree {
+		for _, dep := range r.graph.Dependencies[module] {
+			if _, ordered := indegree[dep]; ordered {
+				indegree[module]++
+			}
+		}
 	}
 
 	var groups [][]string
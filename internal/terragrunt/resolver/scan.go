@@ -0,0 +1,160 @@
+package resolver
+
+import (
+	"context"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/catherinevee/driftmgr/internal/state"
+	"github.com/catherinevee/driftmgr/pkg/models"
+)
+
+// UnitDrift is the drift of one unit: the resources of its state no longer found live.
+// Unresolved units had no state to check.
+type UnitDrift struct {
+	Path        string   `json:"path"`
+	Environment string   `json:"environment"`
+	Resources   int      `json:"resources"`
+	Drifted     int      `json:"drifted"`
+	Missing     []string `json:"missing,omitempty"`
+	Unresolved  bool     `json:"unresolved,omitempty"`
+	Error       string   `json:"error,omitempty"`
+}
+
+// EnvironmentDrift totals the drift of the units of one environment
+type EnvironmentDrift struct {
+	Units        int `json:"units"`
+	DriftedUnits int `json:"drifted_units"`
+	Unresolved   int `json:"unresolved"`
+	Resources    int `json:"resources"`
+	Drifted      int `json:"drifted"`
+	FailedUnits  int `json:"failed_units"`
+}
+
+// DriftMatrix reports the drift of a stack's units by environment
+type DriftMatrix struct {
+	Environments  []string                    `json:"environments"`
+	ByEnvironment map[string]EnvironmentDrift `json:"by_environment"`
+	Units         []UnitDrift                 `json:"units"`
+}
+
+// ScanOptions controls a drift scan. EnvironmentLevel is the segment of a unit's path that
+// names its environment, 0 for the first, so prod/us-east-1/vpc is in prod. Progress, when
+// set, is called as each unit finishes.
+type ScanOptions struct {
+	States           map[string]*state.TerraformState
+	EnvironmentLevel int
+	Concurrency      int
+	Progress         func(done, total int, unit UnitDrift)
+}
+
+// Scan checks each unit's state against the live resources, at most opts.Concurrency units
+// at a time, and totals the drift by environment. It stops early when ctx is cancelled.
+func (s *Stack) Scan(ctx context.Context, live []models.Resource, opts ScanOptions) (*DriftMatrix, error) {
+	concurrency := opts.Concurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	results := make([]UnitDrift, len(s.Units))
+	slots := make(chan struct{}, concurrency)
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		done int
+	)
+	for i, unit := range s.Units {
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+			wg.Wait()
+			return nil, ctx.Err()
+		}
+		wg.Add(1)
+		go func(i int, unit *StackUnit) {
+			defer wg.Done()
+			defer func() { <-slots }()
+			results[i] = s.scanUnit(unit, live, opts)
+
+			mu.Lock()
+			defer mu.Unlock()
+			done++
+			if opts.Progress != nil {
+				opts.Progress(done, len(s.Units), results[i])
+			}
+		}(i, unit)
+	}
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	matrix := &DriftMatrix{
+		Environments:  []string{},
+		ByEnvironment: make(map[string]EnvironmentDrift),
+		Units:         results,
+	}
+	for _, result := range results {
+		totals, seen := matrix.ByEnvironment[result.Environment]
+		if !seen {
+			matrix.Environments = append(matrix.Environments, result.Environment)
+		}
+		totals.Units++
+		totals.Resources += result.Resources
+		totals.Drifted += result.Drifted
+		switch {
+		case result.Error != "":
+			totals.FailedUnits++
+		case result.Unresolved:
+			totals.Unresolved++
+		case result.Drifted > 0:
+			totals.DriftedUnits++
+		}
+		matrix.ByEnvironment[result.Environment] = totals
+	}
+	sort.Strings(matrix.Environments)
+	return matrix, nil
+}
+
+// scanUnit checks one unit's state against the live resources
+func (s *Stack) scanUnit(unit *StackUnit, live []models.Resource, opts ScanOptions) UnitDrift {
+	result := UnitDrift{Path: unit.Path, Environment: environment(unit.Path, opts.EnvironmentLevel)}
+
+	unitStack := &Stack{Root: s.Root, Units: []*StackUnit{{Path: unit.Path, StatePath: unit.StatePath}}}
+	calculator := state.NewCoverageCalculator()
+	unresolved, err := unitStack.AddStates(calculator, opts.States)
+	switch {
+	case err != nil:
+		result.Error = err.Error()
+		return result
+	case len(unresolved) > 0:
+		result.Unresolved = true
+		return result
+	}
+
+	coverage := calculator.Calculate(live)
+	result.Resources = coverage.StateResources
+	result.Drifted = coverage.MissingResources
+	for _, address := range coverage.Missing {
+		result.Missing = append(result.Missing, strings.TrimPrefix(address, unit.Path+":"))
+	}
+	return result
+}
+
+// environment returns the directory of a unit path at level, or the unit's parent directory
+// when the path is not that deep. Units at the stack root are in ".".
+func environment(unitPath string, level int) string {
+	segments := strings.Split(filepath.ToSlash(unitPath), "/")
+	if level < 0 {
+		level = 0
+	}
+	if level >= len(segments)-1 {
+		level = len(segments) - 2
+	}
+	if level < 0 {
+		return "."
+	}
+	return segments[level]
+}
@@ -0,0 +1,153 @@
+package resolver
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/catherinevee/driftmgr/internal/state"
+)
+
+// StackUnit is one Terragrunt unit of a stack, with paths relative to the stack root.
+// StateKey is where a remote backend keeps the unit's state, e.g. its S3 key, and StatePath
+// the unit's local state file when one was found.
+type StackUnit struct {
+	Path         string   `json:"path"`
+	Dependencies []string `json:"dependencies"`
+	Backend      string   `json:"backend,omitempty"`
+	StateKey     string   `json:"state_key,omitempty"`
+	StatePath    string   `json:"state_path,omitempty"`
+	Resources    int      `json:"resources"`
+	Skip         bool     `json:"skip,omitempty"`
+}
+
+// Stack is a tree of Terragrunt units in dependency order: each unit comes after the
+// units it depends on, and each group only depends on earlier groups
+type Stack struct {
+	Root   string       `json:"-"`
+	Units  []*StackUnit `json:"units"`
+	Groups [][]string   `json:"groups"`
+}
+
+// ResolveStack parses the Terragrunt units under rootDir and orders them by their
+// dependency blocks
+func ResolveStack(rootDir string) (*Stack, error) {
+	resolver := NewDependencyResolver()
+	graph, err := resolver.ResolveDirectory(rootDir)
+	if err != nil {
+		return nil, err
+	}
+	order, err := resolver.GetExecutionOrder(true)
+	if err != nil {
+		return nil, err
+	}
+
+	stack := &Stack{Root: rootDir, Units: []*StackUnit{}, Groups: [][]string{}}
+	for _, group := range order.Groups {
+		paths := make([]string, 0, len(group))
+		for _, modulePath := range group {
+			module := graph.Modules[modulePath]
+			unit := &StackUnit{
+				Path:         stack.relative(modulePath),
+				Dependencies: []string{},
+				Skip:         module.Config.Skip,
+			}
+			for _, dep := range module.Dependencies {
+				unit.Dependencies = append(unit.Dependencies, stack.relative(dep))
+			}
+			sort.Strings(unit.Dependencies)
+			if remoteState := module.Config.RemoteState; remoteState != nil {
+				unit.Backend = remoteState.Backend
+				unit.StateKey = stateKey(remoteState.Config)
+			}
+			if unit.Backend == "" || unit.Backend == "local" {
+				if statePath := findLocalState(module); statePath != "" {
+					unit.StatePath = stack.relative(statePath)
+				}
+			}
+			stack.Units = append(stack.Units, unit)
+			paths = append(paths, unit.Path)
+		}
+		stack.Groups = append(stack.Groups, paths)
+	}
+	return stack, nil
+}
+
+// AddStates records the resources of each unit's state in calculator, addressed within the
+// unit's path. States are taken from states, keyed by unit path, before the unit's local
+// state file. It returns the paths of the units without a state.
+func (s *Stack) AddStates(calculator *state.CoverageCalculator, states map[string]*state.TerraformState) ([]string, error) {
+	parser := state.NewStateParser()
+	unresolved := []string{}
+	for _, unit := range s.Units {
+		unitState, ok := states[unit.Path]
+		if !ok && unit.StatePath != "" {
+			parsed, err := parser.ParseFile(filepath.Join(s.Root, unit.StatePath))
+			if err != nil {
+				return nil, fmt.Errorf("failed to read state of %s: %w", unit.Path, err)
+			}
+			unitState, ok = parsed.TerraformState, true
+		}
+		if !ok {
+			unresolved = append(unresolved, unit.Path)
+			continue
+		}
+		unit.Resources = len(unitState.Resources)
+		calculator.AddState(unit.Path, unitState)
+	}
+	return unresolved, nil
+}
+
+// relative returns path relative to the stack root, or path itself when it is outside it
+func (s *Stack) relative(path string) string {
+	rel, err := filepath.Rel(s.Root, path)
+	if err != nil {
+		return path
+	}
+	return filepath.ToSlash(rel)
+}
+
+// stateKey returns where a remote_state config keeps the state
+func stateKey(config map[string]interface{}) string {
+	for _, key := range []string{"key", "prefix", "path"} {
+		if value, ok := config[key].(string); ok {
+			return value
+		}
+	}
+	return ""
+}
+
+// findLocalState returns the path of a unit's local state: the configured path of a local
+// backend, terraform.tfstate in the unit, or the most recently written one in the unit's
+// Terragrunt cache
+func findLocalState(module *Module) string {
+	candidates := []string{filepath.Join(module.Path, "terraform.tfstate")}
+	if remoteState := module.Config.RemoteState; remoteState != nil {
+		if path, ok := remoteState.Config["path"].(string); ok && path != "" {
+			candidates = []string{filepath.Join(module.Path, path)}
+		}
+	}
+	cached, _ := filepath.Glob(filepath.Join(module.Path, ".terragrunt-cache", "*", "*", "terraform.tfstate"))
+	sort.Slice(cached, func(i, j int) bool {
+		return modTime(cached[i]).After(modTime(cached[j]))
+	})
+	candidates = append(candidates, cached...)
+
+	for _, candidate := range candidates {
+		if info, err := os.Stat(candidate); err == nil && !info.IsDir() {
+			return candidate
+		}
+	}
+	return ""
+}
+
+// modTime returns when a file was last written, or the zero time when it cannot be read
+func modTime(path string) time.Time {
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
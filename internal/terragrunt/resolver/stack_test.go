@@ -0,0 +1,135 @@
+package resolver
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/catherinevee/driftmgr/internal/state"
+	"github.com/catherinevee/driftmgr/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeFile writes content to path under dir, creating its directories
+func writeFile(t *testing.T, dir, path, content string) {
+	t.Helper()
+	path = filepath.Join(dir, path)
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+}
+
+func TestResolveStack(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, root, "vpc/terragrunt.hcl", `terraform {
+  source = "../modules/vpc"
+}`)
+	writeFile(t, root, "vpc/terraform.tfstate", `{"version": 4, "resources": [
+  {"mode": "managed", "type": "aws_vpc", "name": "main", "instances": [{"attributes": {"id": "vpc-1"}}]}
+]}`)
+	writeFile(t, root, "app/terragrunt.hcl", `dependency "vpc" {
+  config_path = "../vpc"
+}
+dependency "shared" {
+  config_path = "../../shared"
+}`)
+	writeFile(t, root, "app/.terragrunt-cache/abc/def/terraform.tfstate", `{"version": 4, "resources": [
+  {"mode": "managed", "type": "aws_instance", "name": "web", "instances": [{"attributes": {"id": "i-1"}}]}
+]}`)
+	writeFile(t, root, "app/.terragrunt-cache/abc/def/terragrunt.hcl", `terraform {}`)
+	writeFile(t, root, "db/terragrunt.hcl", `remote_state {
+  backend = "s3"
+  config = {
+    bucket = "states"
+    key    = "db/terraform.tfstate"
+  }
+}
+dependency "vpc" {
+  config_path = "../vpc"
+}`)
+
+	stack, err := ResolveStack(root)
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{{"vpc"}, {"app", "db"}}, stack.Groups, "units come after their dependencies")
+	require.Len(t, stack.Units, 3, "copies in the Terragrunt cache are not units")
+
+	units := map[string]*StackUnit{}
+	for _, unit := range stack.Units {
+		units[unit.Path] = unit
+	}
+	assert.Equal(t, []string{"../shared", "vpc"}, units["app"].Dependencies)
+	assert.Equal(t, "vpc/terraform.tfstate", units["vpc"].StatePath)
+	assert.Equal(t, "app/.terragrunt-cache/abc/def/terraform.tfstate", units["app"].StatePath)
+	assert.Equal(t, "s3", units["db"].Backend)
+	assert.Equal(t, "db/terraform.tfstate", units["db"].StateKey)
+	assert.Empty(t, units["db"].StatePath)
+
+	calculator := state.NewCoverageCalculator()
+	unresolved, err := stack.AddStates(calculator, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"db"}, unresolved)
+
+	coverage := calculator.Calculate([]models.Resource{{ID: "vpc-1"}, {ID: "i-1"}, {ID: "db-1"}})
+	assert.Equal(t, 2, coverage.ManagedResources, "resources of every unit are managed")
+	assert.Equal(t, []string{"db-1"}, coverage.Unmanaged)
+
+	calculator = state.NewCoverageCalculator()
+	unresolved, err = stack.AddStates(calculator, map[string]*state.TerraformState{
+		"db": {Resources: []state.Resource{
+			{Mode: "managed", Type: "aws_db_instance", Name: "main", Instances: []state.Instance{{Attributes: map[string]interface{}{"id": "db-1"}}}},
+		}},
+	})
+	require.NoError(t, err)
+	assert.Empty(t, unresolved)
+	assert.Empty(t, calculator.Calculate([]models.Resource{{ID: "db-1"}}).Unmanaged)
+	assert.Equal(t, 1, units["db"].Resources)
+}
+
+func TestStackScan(t *testing.T) {
+	root := t.TempDir()
+	for _, env := range []string{"dev", "prod"} {
+		writeFile(t, root, env+"/us-east-1/vpc/terragrunt.hcl", `terraform {}`)
+		writeFile(t, root, env+"/us-east-1/vpc/terraform.tfstate", `{"version": 4, "resources": [
+  {"mode": "managed", "type": "aws_vpc", "name": "main", "instances": [{"attributes": {"id": "vpc-`+env+`"}}]},
+  {"mode": "managed", "type": "aws_subnet", "name": "a", "instances": [{"attributes": {"id": "subnet-`+env+`"}}]}
+]}`)
+	}
+	writeFile(t, root, "prod/us-east-1/app/terragrunt.hcl", `remote_state {
+  backend = "s3"
+}`)
+
+	stack, err := ResolveStack(root)
+	require.NoError(t, err)
+
+	var progress []int
+	matrix, err := stack.Scan(context.Background(), []models.Resource{
+		{ID: "vpc-dev"}, {ID: "subnet-dev"}, {ID: "vpc-prod"},
+	}, ScanOptions{
+		Concurrency: 2,
+		Progress:    func(done, total int, unit UnitDrift) { progress = append(progress, done) },
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []int{1, 2, 3}, progress)
+	assert.Equal(t, []string{"dev", "prod"}, matrix.Environments)
+	assert.Equal(t, EnvironmentDrift{Units: 1, Resources: 2}, matrix.ByEnvironment["dev"])
+	assert.Equal(t, EnvironmentDrift{Units: 2, DriftedUnits: 1, Unresolved: 1, Resources: 2, Drifted: 1}, matrix.ByEnvironment["prod"])
+	for _, unit := range matrix.Units {
+		if unit.Path == "prod/us-east-1/vpc" {
+			assert.Equal(t, []string{"aws_subnet.a"}, unit.Missing)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = stack.Scan(ctx, nil, ScanOptions{})
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestEnvironment(t *testing.T) {
+	assert.Equal(t, "prod", environment("prod/us-east-1/vpc", 0))
+	assert.Equal(t, "us-east-1", environment("prod/us-east-1/vpc", 1))
+	assert.Equal(t, "prod", environment("prod/vpc", 3))
+	assert.Equal(t, ".", environment("vpc", 0))
+}
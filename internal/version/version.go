@@ -0,0 +1,46 @@
+// Package version holds the build information of the driftmgr binaries. The values are set
+// at build time with the linker, for example:
+//
+//	go build -ldflags "-X github.com/catherinevee/driftmgr/internal/version.Version=v3.1.0 \
+//	  -X github.com/catherinevee/driftmgr/internal/version.Commit=$(git rev-parse --short HEAD) \
+//	  -X github.com/catherinevee/driftmgr/internal/version.BuildDate=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+//
+// A binary built without them, such as with go run, reports "dev".
+package version
+
+import (
+	"fmt"
+	"runtime"
+)
+
+var (
+	// Version is the release version
+	Version = "dev"
+	// Commit is the git commit the binary was built from
+	Commit = "dev"
+	// BuildDate is when the binary was built
+	BuildDate = "dev"
+)
+
+// Info is the build information of the running binary
+type Info struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	BuildDate string `json:"build_date"`
+	GoVersion string `json:"go_version"`
+}
+
+// Get returns the build information of the running binary
+func Get() Info {
+	return Info{
+		Version:   Version,
+		Commit:    Commit,
+		BuildDate: BuildDate,
+		GoVersion: runtime.Version(),
+	}
+}
+
+// String formats the build information for a --version flag
+func (i Info) String() string {
+	return fmt.Sprintf("%s (commit %s, built %s, %s)", i.Version, i.Commit, i.BuildDate, i.GoVersion)
+}
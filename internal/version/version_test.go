@@ -0,0 +1,21 @@
+package version
+
+import (
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGet(t *testing.T) {
+	info := Get()
+	assert.Equal(t, "dev", info.Version, "unset build variables default to dev")
+	assert.Equal(t, "dev", info.Commit)
+	assert.Equal(t, "dev", info.BuildDate)
+	assert.Equal(t, runtime.Version(), info.GoVersion)
+
+	original := Version
+	Version, Commit = "v3.1.0", "abc1234"
+	t.Cleanup(func() { Version, Commit = original, "dev" })
+	assert.Contains(t, Get().String(), "v3.1.0 (commit abc1234")
+}
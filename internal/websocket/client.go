@@ -3,7 +3,7 @@ package websocket
 import (
 	"encoding/json"
 	"log"
-	"net/http"
+	"net"
 	"time"
 
 	"github.com/gorilla/websocket"
@@ -23,16 +23,6 @@ const (
 	maxMessageSize = 512
 )
 
-var upgrader = websocket.Upgrader{
-	ReadBufferSize:  1024,
-	WriteBufferSize: 1024,
-	CheckOrigin: func(r *http.Request) bool {
-		// Allow connections from any origin
-		// In production, you should implement proper origin checking
-		return true
-	},
-}
-
 // Client is a middleman between the websocket connection and the hub
 type Client struct {
 	// The websocket connection
@@ -47,11 +37,17 @@ type Client struct {
 	// User ID for targeted messaging
 	userID string
 
+	// Username, matched against the user that started a job
+	username string
+
 	// User roles for authorization
 	roles []string
 
 	// Connection timestamp
 	connectedAt time.Time
+
+	// Broadcast filters requested by the client
+	subscription *Subscription
 }
 
 // Message represents a WebSocket message
@@ -60,6 +56,7 @@ type Message struct {
 	Data      interface{} `json:"data"`
 	Timestamp time.Time   `json:"timestamp"`
 	UserID    string      `json:"user_id,omitempty"`
+	JobID     string      `json:"job_id,omitempty"`
 }
 
 // NewMessage creates a new WebSocket message
@@ -88,27 +85,33 @@ func (c *Client) readPump() {
 		c.conn.Close()
 	}()
 
+	pongTimeout := c.hub.keepaliveSettings().pongWait
 	c.conn.SetReadLimit(maxMessageSize)
-	c.conn.SetReadDeadline(time.Now().Add(pongWait))
+	c.conn.SetReadDeadline(time.Now().Add(pongTimeout))
 	c.conn.SetPongHandler(func(string) error {
-		c.conn.SetReadDeadline(time.Now().Add(pongWait))
+		c.conn.SetReadDeadline(time.Now().Add(pongTimeout))
 		return nil
 	})
 
 	for {
-		_, _, err := c.conn.ReadMessage()
+		_, data, err := c.conn.ReadMessage()
 		if err != nil {
-			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
+			if netErr, ok := err.(net.Error); ok && netErr.Timeout() {
+				log.Printf("WebSocket client %s timed out waiting for pong, closing", c.conn.RemoteAddr())
+			} else if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
 				log.Printf("WebSocket error: %v", err)
 			}
 			break
 		}
+		c.handleInbound(data)
 	}
 }
 
 // writePump pumps messages from the hub to the websocket connection
 func (c *Client) writePump() {
-	ticker := time.NewTicker(pingPeriod)
+	keepalive := c.hub.keepaliveSettings()
+	writeTimeout := keepalive.writeWait
+	ticker := time.NewTicker(keepalive.pingPeriod)
 	defer func() {
 		ticker.Stop()
 		c.conn.Close()
@@ -117,7 +120,7 @@ func (c *Client) writePump() {
 	for {
 		select {
 		case message, ok := <-c.send:
-			c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+			c.conn.SetWriteDeadline(time.Now().Add(writeTimeout))
 			if !ok {
 				c.conn.WriteMessage(websocket.CloseMessage, []byte{})
 				return
@@ -127,13 +130,17 @@ func (c *Client) writePump() {
 			if err != nil {
 				return
 			}
-			w.Write(message)
+			if _, err := w.Write(message); err != nil {
+				return
+			}
 
 			// Add queued chat messages to the current websocket message
 			n := len(c.send)
 			for i := 0; i < n; i++ {
 				w.Write([]byte{'\n'})
-				w.Write(<-c.send)
+				if _, err := w.Write(<-c.send); err != nil {
+					return
+				}
 			}
 
 			if err := w.Close(); err != nil {
@@ -141,7 +148,7 @@ func (c *Client) writePump() {
 			}
 
 		case <-ticker.C:
-			c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+			c.conn.SetWriteDeadline(time.Now().Add(writeTimeout))
 			if err := c.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
 				return
 			}
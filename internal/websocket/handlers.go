@@ -7,24 +7,40 @@ import (
 	"time"
 
 	"github.com/catherinevee/driftmgr/internal/auth"
+	"github.com/gorilla/websocket"
 )
 
 // WebSocketHandlers handles WebSocket connections and messages
 type WebSocketHandlers struct {
-	hub *Hub
+	hub      *Hub
+	origins  *OriginPolicy
+	upgrader websocket.Upgrader
 }
 
-// NewWebSocketHandlers creates a new WebSocket handlers instance
+// NewWebSocketHandlers creates a new WebSocket handlers instance. Allowed origins are
+// read from DRIFTMGR_ALLOWED_ORIGINS and default to same-origin only.
 func NewWebSocketHandlers(hub *Hub) *WebSocketHandlers {
+	origins := OriginPolicyFromEnv()
 	return &WebSocketHandlers{
-		hub: hub,
+		hub:     hub,
+		origins: origins,
+		upgrader: websocket.Upgrader{
+			ReadBufferSize:  1024,
+			WriteBufferSize: 1024,
+			CheckOrigin:     origins.CheckOrigin,
+		},
 	}
 }
 
+// SetAllowedOrigins replaces the origins allowed to open WebSocket connections
+func (h *WebSocketHandlers) SetAllowedOrigins(origins []string) {
+	h.origins.SetAllowedOrigins(origins)
+}
+
 // HandleWebSocket handles WebSocket connections
 func (h *WebSocketHandlers) HandleWebSocket(w http.ResponseWriter, r *http.Request) {
 	// Upgrade HTTP connection to WebSocket
-	conn, err := upgrader.Upgrade(w, r, nil)
+	conn, err := h.upgrader.Upgrade(w, r, nil)
 	if err != nil {
 		log.Printf("WebSocket upgrade error: %v", err)
 		return
@@ -32,16 +48,19 @@ func (h *WebSocketHandlers) HandleWebSocket(w http.ResponseWriter, r *http.Reque
 
 	// Extract user information from context (if authenticated)
 	userID, _ := auth.GetUserIDFromContext(r.Context())
+	username, _ := auth.GetUsernameFromContext(r.Context())
 	roles, _ := auth.GetRolesFromContext(r.Context())
 
 	// Create client
 	client := &Client{
-		conn:        conn,
-		send:        make(chan []byte, 256),
-		hub:         h.hub,
-		userID:      userID,
-		roles:       roles,
-		connectedAt: time.Now(),
+		conn:         conn,
+		send:         make(chan []byte, 256),
+		hub:          h.hub,
+		userID:       userID,
+		username:     username,
+		roles:        roles,
+		connectedAt:  time.Now(),
+		subscription: newSubscription(),
 	}
 
 	// Register client with hub
@@ -63,43 +82,37 @@ func (h *WebSocketHandlers) HandleWebSocket(w http.ResponseWriter, r *http.Reque
 // BroadcastDriftDetection sends a drift detection update to all connected clients
 func (h *WebSocketHandlers) BroadcastDriftDetection(driftData interface{}) {
 	message := NewMessage("drift_detection", driftData)
-	data, _ := json.Marshal(message)
-	h.hub.Broadcast(data)
+	h.hub.BroadcastMessage(message)
 }
 
 // BroadcastRemediationUpdate sends a remediation update to all connected clients
 func (h *WebSocketHandlers) BroadcastRemediationUpdate(remediationData interface{}) {
 	message := NewMessage("remediation_update", remediationData)
-	data, _ := json.Marshal(message)
-	h.hub.Broadcast(data)
+	h.hub.BroadcastMessage(message)
 }
 
 // BroadcastResourceUpdate sends a resource update to all connected clients
 func (h *WebSocketHandlers) BroadcastResourceUpdate(resourceData interface{}) {
 	message := NewMessage("resource_update", resourceData)
-	data, _ := json.Marshal(message)
-	h.hub.Broadcast(data)
+	h.hub.BroadcastMessage(message)
 }
 
 // BroadcastStateUpdate sends a state management update to all connected clients
 func (h *WebSocketHandlers) BroadcastStateUpdate(stateData interface{}) {
 	message := NewMessage("state_update", stateData)
-	data, _ := json.Marshal(message)
-	h.hub.Broadcast(data)
+	h.hub.BroadcastMessage(message)
 }
 
 // BroadcastBackendUpdate sends a backend discovery update to all connected clients
 func (h *WebSocketHandlers) BroadcastBackendUpdate(backendData interface{}) {
 	message := NewMessage("backend_update", backendData)
-	data, _ := json.Marshal(message)
-	h.hub.Broadcast(data)
+	h.hub.BroadcastMessage(message)
 }
 
 // BroadcastSystemAlert sends a system alert to all connected clients
 func (h *WebSocketHandlers) BroadcastSystemAlert(alertData interface{}) {
 	message := NewMessage("system_alert", alertData)
-	data, _ := json.Marshal(message)
-	h.hub.Broadcast(data)
+	h.hub.BroadcastMessage(message)
 }
 
 // SendToUser sends a message to a specific user
@@ -114,8 +127,8 @@ func (h *WebSocketHandlers) BroadcastToAdmins(messageType string, data interface
 	message := NewMessage(messageType, data)
 	messageData, _ := json.Marshal(message)
 
-	// Send to all admin clients
-	h.hub.mu.RLock()
+	// Send to all admin clients; write lock because slow clients are removed
+	h.hub.mu.Lock()
 	for client := range h.hub.clients {
 		if client.IsAdmin() {
 			select {
@@ -126,7 +139,7 @@ func (h *WebSocketHandlers) BroadcastToAdmins(messageType string, data interface
 			}
 		}
 	}
-	h.hub.mu.RUnlock()
+	h.hub.mu.Unlock()
 }
 
 // GetConnectionStats returns statistics about WebSocket connections
@@ -1,8 +1,18 @@
 package websocket
 
 import (
+	"encoding/json"
 	"log"
+	"os"
 	"sync"
+	"time"
+)
+
+// PingIntervalEnv and PongTimeoutEnv name the environment variables holding the keepalive
+// ping interval and pong timeout as Go durations, e.g. "30s"
+const (
+	PingIntervalEnv = "DRIFTMGR_WS_PING_INTERVAL"
+	PongTimeoutEnv  = "DRIFTMGR_WS_PONG_TIMEOUT"
 )
 
 // Hub maintains the set of active clients and broadcasts messages to the clients
@@ -10,8 +20,8 @@ type Hub struct {
 	// Registered clients
 	clients map[*Client]bool
 
-	// Inbound messages from the clients
-	broadcast chan []byte
+	// Outbound messages to fan out to subscribed clients
+	broadcast chan outbound
 
 	// Register requests from the clients
 	register chan *Client
@@ -19,18 +29,83 @@ type Hub struct {
 	// Unregister requests from clients
 	unregister chan *Client
 
+	// Ping, pong and write timings applied to client connections
+	keepalive keepaliveConfig
+
 	// Mutex for thread safety
 	mu sync.RWMutex
 }
 
-// NewHub creates a new WebSocket hub
+// keepaliveConfig controls how dead connections are detected
+type keepaliveConfig struct {
+	writeWait  time.Duration
+	pongWait   time.Duration
+	pingPeriod time.Duration
+}
+
+// NewHub creates a new WebSocket hub. Keepalive timings are read from
+// DRIFTMGR_WS_PING_INTERVAL and DRIFTMGR_WS_PONG_TIMEOUT when set.
 func NewHub() *Hub {
-	return &Hub{
+	hub := &Hub{
 		clients:    make(map[*Client]bool),
-		broadcast:  make(chan []byte),
+		broadcast:  make(chan outbound),
 		register:   make(chan *Client),
 		unregister: make(chan *Client),
+		keepalive: keepaliveConfig{
+			writeWait:  writeWait,
+			pongWait:   pongWait,
+			pingPeriod: pingPeriod,
+		},
+	}
+	hub.SetKeepalive(KeepaliveFromEnv())
+	return hub
+}
+
+// KeepaliveFromEnv reads the ping interval and pong timeout from the environment. Unset or
+// invalid values are returned as zero, which SetKeepalive replaces with the defaults.
+func KeepaliveFromEnv() (pingInterval, pongTimeout time.Duration) {
+	return durationFromEnv(PingIntervalEnv), durationFromEnv(PongTimeoutEnv)
+}
+
+// durationFromEnv parses a duration environment variable, logging invalid values
+func durationFromEnv(name string) time.Duration {
+	value := os.Getenv(name)
+	if value == "" {
+		return 0
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil || duration <= 0 {
+		log.Printf("Ignoring invalid %s %q: must be a positive duration", name, value)
+		return 0
+	}
+	return duration
+}
+
+// SetKeepalive changes the ping interval and pong timeout for clients connecting after
+// the call. A pong timeout of zero or less keeps the default, and the ping interval is
+// clamped below the pong timeout so live clients always answer in time.
+func (h *Hub) SetKeepalive(pingInterval, pongTimeout time.Duration) {
+	if pongTimeout <= 0 {
+		pongTimeout = pongWait
 	}
+	if pingInterval <= 0 || pingInterval >= pongTimeout {
+		pingInterval = (pongTimeout * 9) / 10
+	}
+	if pingInterval <= 0 {
+		// Too short a timeout to ping within; tickers need a positive period
+		pingInterval, pongTimeout = pingPeriod, pongWait
+	}
+	h.mu.Lock()
+	h.keepalive.pingPeriod = pingInterval
+	h.keepalive.pongWait = pongTimeout
+	h.mu.Unlock()
+}
+
+// keepaliveSettings returns the current keepalive timings
+func (h *Hub) keepaliveSettings() keepaliveConfig {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.keepalive
 }
 
 // Run starts the hub
@@ -40,8 +115,9 @@ func (h *Hub) Run() {
 		case client := <-h.register:
 			h.mu.Lock()
 			h.clients[client] = true
+			total := len(h.clients)
 			h.mu.Unlock()
-			log.Printf("WebSocket client connected. Total clients: %d", len(h.clients))
+			log.Printf("WebSocket client connected. Total clients: %d", total)
 
 		case client := <-h.unregister:
 			h.mu.Lock()
@@ -49,27 +125,68 @@ func (h *Hub) Run() {
 				delete(h.clients, client)
 				close(client.send)
 			}
+			total := len(h.clients)
 			h.mu.Unlock()
-			log.Printf("WebSocket client disconnected. Total clients: %d", len(h.clients))
+			log.Printf("WebSocket client disconnected. Total clients: %d", total)
 
 		case message := <-h.broadcast:
-			h.mu.RLock()
+			h.mu.Lock()
 			for client := range h.clients {
+				if !message.deliverTo(client) {
+					continue
+				}
 				select {
-				case client.send <- message:
+				case client.send <- message.data:
 				default:
 					close(client.send)
 					delete(h.clients, client)
 				}
 			}
-			h.mu.RUnlock()
+			h.mu.Unlock()
 		}
 	}
 }
 
-// Broadcast sends a message to all connected clients
+// outbound is a broadcast payload along with the metadata used to filter recipients
+type outbound struct {
+	data        []byte
+	messageType string
+	jobID       string
+	user        string
+}
+
+// deliverTo reports whether the client may see the message and its subscription accepts it.
+// Raw payloads without a message type go to every client.
+func (o outbound) deliverTo(client *Client) bool {
+	if o.user != "" && client.username != "" && client.username != o.user && !client.IsAdmin() {
+		return false
+	}
+	if o.messageType == "" || client.subscription == nil {
+		return true
+	}
+	return client.subscription.Matches(o.messageType, o.jobID)
+}
+
+// Broadcast sends a raw payload to all connected clients, ignoring subscriptions
 func (h *Hub) Broadcast(message []byte) {
-	h.broadcast <- message
+	h.broadcast <- outbound{data: message}
+}
+
+// BroadcastMessage sends a message to every client subscribed to its type and job
+func (h *Hub) BroadcastMessage(message *Message) {
+	h.BroadcastJobMessage("", message)
+}
+
+// BroadcastJobMessage sends a message about a job started by user to the subscribed clients
+// allowed to see the job: the user's own connections, admins, and any client when
+// authentication is disabled. An empty user sends it to every subscribed client.
+func (h *Hub) BroadcastJobMessage(user string, message *Message) {
+	data, err := json.Marshal(message)
+	if err != nil {
+		log.Printf("Failed to marshal %s message: %v", message.Type, err)
+		return
+	}
+	h.broadcast <- outbound{data: data, messageType: message.Type, jobID: message.JobID, user: user}
 }
 
 // GetClientCount returns the number of connected clients
@@ -81,8 +198,9 @@ func (h *Hub) GetClientCount() int {
 
 // BroadcastToUser sends a message to a specific user
 func (h *Hub) BroadcastToUser(userID string, message []byte) {
-	h.mu.RLock()
-	defer h.mu.RUnlock()
+	// Write lock: slow clients are removed while iterating
+	h.mu.Lock()
+	defer h.mu.Unlock()
 
 	for client := range h.clients {
 		if client.userID == userID {
@@ -0,0 +1,95 @@
+package websocket
+
+import (
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+
+	monitoring "github.com/catherinevee/driftmgr/internal/shared/logger"
+)
+
+// AllowedOriginsEnv names the environment variable holding a comma-separated list of
+// origins allowed to open WebSocket connections, e.g. "https://drift.example.com"
+const AllowedOriginsEnv = "DRIFTMGR_ALLOWED_ORIGINS"
+
+// OriginPolicy decides which browser origins may open WebSocket connections. With no
+// allowed origins configured only same-origin requests are accepted; "*" allows any origin.
+type OriginPolicy struct {
+	mu       sync.RWMutex
+	allowed  map[string]bool
+	allowAll bool
+}
+
+// NewOriginPolicy creates a policy allowing the given origins in addition to same-origin requests
+func NewOriginPolicy(origins []string) *OriginPolicy {
+	policy := &OriginPolicy{}
+	policy.SetAllowedOrigins(origins)
+	return policy
+}
+
+// OriginPolicyFromEnv creates a policy from DRIFTMGR_ALLOWED_ORIGINS
+func OriginPolicyFromEnv() *OriginPolicy {
+	return NewOriginPolicy(ParseAllowedOrigins(os.Getenv(AllowedOriginsEnv)))
+}
+
+// ParseAllowedOrigins splits a comma-separated origin list, dropping empty entries
+func ParseAllowedOrigins(value string) []string {
+	var origins []string
+	for _, origin := range strings.Split(value, ",") {
+		if origin = strings.TrimSpace(origin); origin != "" {
+			origins = append(origins, origin)
+		}
+	}
+	return origins
+}
+
+// SetAllowedOrigins replaces the allowed origin list
+func (p *OriginPolicy) SetAllowedOrigins(origins []string) {
+	allowed := make(map[string]bool, len(origins))
+	allowAll := false
+	for _, origin := range origins {
+		if origin == "*" {
+			allowAll = true
+			continue
+		}
+		allowed[normalizeOrigin(origin)] = true
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.allowed = allowed
+	p.allowAll = allowAll
+}
+
+// CheckOrigin implements websocket.Upgrader.CheckOrigin. Requests without an Origin
+// header come from non-browser clients and are not subject to cross-site hijacking.
+func (p *OriginPolicy) CheckOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+
+	p.mu.RLock()
+	allowAll := p.allowAll
+	allowed := p.allowed[normalizeOrigin(origin)]
+	p.mu.RUnlock()
+
+	if allowAll || allowed {
+		return true
+	}
+
+	u, err := url.Parse(origin)
+	if err == nil && strings.EqualFold(u.Host, r.Host) {
+		return true
+	}
+
+	monitoring.GetGlobalLogger().Debug("Rejected WebSocket connection from origin %q (host %q)", origin, r.Host)
+	return false
+}
+
+// normalizeOrigin lowercases an origin and strips any trailing slash
+func normalizeOrigin(origin string) string {
+	return strings.TrimSuffix(strings.ToLower(strings.TrimSpace(origin)), "/")
+}
@@ -0,0 +1,110 @@
+package websocket
+
+import (
+	"github.com/catherinevee/driftmgr/pkg/models"
+	"github.com/google/uuid"
+)
+
+const (
+	// MessageTypeDiscoveryProgress carries progress of a discovery job
+	MessageTypeDiscoveryProgress = "discovery_progress"
+
+	// MessageTypeRemediationProgress carries progress of a remediation job
+	MessageTypeRemediationProgress = "remediation_progress"
+
+	// MessageTypeTerragruntScanProgress carries progress of a Terragrunt drift scan
+	MessageTypeTerragruntScanProgress = "terragrunt_scan_progress"
+
+	// MessageTypeStateUploadProgress carries the bytes received of a state file upload
+	MessageTypeStateUploadProgress = "state_upload_progress"
+
+	// MessageTypeDiscoveryBatch carries resources a discovery job has found so far
+	MessageTypeDiscoveryBatch = "discovery_batch"
+)
+
+// ProgressUpdate reports the progress of a long-running job. The JobID matches the ID
+// returned by the HTTP request that started the job, so clients can tell concurrent
+// jobs apart.
+type ProgressUpdate struct {
+	JobID      string  `json:"job_id"`
+	Stage      string  `json:"stage"`
+	Message    string  `json:"message,omitempty"`
+	Current    int     `json:"current"`
+	Total      int     `json:"total"`
+	Percentage float64 `json:"percentage"`
+	Completed  bool    `json:"completed"`
+	Error      string  `json:"error,omitempty"`
+
+	// Resources and MaxResources report discovery's resource count against its cap
+	Resources    int `json:"resources,omitempty"`
+	MaxResources int `json:"max_resources,omitempty"`
+
+	// Errors lists the services a discovery step could not scan, whose resources are missing
+	Errors []models.DiscoveryError `json:"errors,omitempty"`
+
+	// User is the username that started the job. When set, the update only goes to that
+	// user's connections and to admins.
+	User string `json:"-"`
+}
+
+// DiscoveryBatch is the resources a discovery job found in one region and service, sent as
+// soon as they are found so clients can show results before the job completes. Discovered
+// counts the resources of every batch of the job so far, this one included.
+type DiscoveryBatch struct {
+	JobID      string            `json:"job_id"`
+	Provider   string            `json:"provider"`
+	Region     string            `json:"region"`
+	Service    string            `json:"service"`
+	Resources  []models.Resource `json:"resources"`
+	Discovered int               `json:"discovered"`
+
+	// User is the username that started the job. When set, the batch only goes to that
+	// user's connections and to admins.
+	User string `json:"-"`
+}
+
+// NewJobID generates an ID for correlating a job's progress updates
+func NewJobID() string {
+	return "job-" + uuid.New().String()
+}
+
+// NewProgressUpdate creates a progress update, deriving the percentage from current and total
+func NewProgressUpdate(jobID, stage string, current, total int) ProgressUpdate {
+	update := ProgressUpdate{
+		JobID:     jobID,
+		Stage:     stage,
+		Current:   current,
+		Total:     total,
+		Completed: total > 0 && current >= total,
+	}
+	if total > 0 {
+		update.Percentage = float64(current) / float64(total) * 100
+	}
+	return update
+}
+
+// BroadcastProgress sends a progress update to the clients allowed to see its job, tagged
+// with its job ID
+func (h *WebSocketHandlers) BroadcastProgress(messageType string, update ProgressUpdate) {
+	message := NewMessage(messageType, update)
+	message.JobID = update.JobID
+	h.hub.BroadcastJobMessage(update.User, message)
+}
+
+// BroadcastProgress broadcasts a job progress update
+func (s *Service) BroadcastProgress(messageType string, update ProgressUpdate) {
+	s.handlers.BroadcastProgress(messageType, update)
+}
+
+// BroadcastDiscoveryBatch sends a batch of discovered resources to the clients allowed to see
+// its job, tagged with its job ID
+func (h *WebSocketHandlers) BroadcastDiscoveryBatch(batch DiscoveryBatch) {
+	message := NewMessage(MessageTypeDiscoveryBatch, batch)
+	message.JobID = batch.JobID
+	h.hub.BroadcastJobMessage(batch.User, message)
+}
+
+// BroadcastDiscoveryBatch broadcasts a batch of discovered resources
+func (s *Service) BroadcastDiscoveryBatch(batch DiscoveryBatch) {
+	s.handlers.BroadcastDiscoveryBatch(batch)
+}
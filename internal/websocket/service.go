@@ -34,6 +34,11 @@ func (s *Service) GetHandlers() *WebSocketHandlers {
 	return s.handlers
 }
 
+// SetAllowedOrigins replaces the origins allowed to open WebSocket connections
+func (s *Service) SetAllowedOrigins(origins []string) {
+	s.handlers.SetAllowedOrigins(origins)
+}
+
 // GetHub returns the WebSocket hub
 func (s *Service) GetHub() *Hub {
 	return s.hub
@@ -94,14 +99,7 @@ func (s *Service) startStatsBroadcast(ctx context.Context) {
 		case <-ticker.C:
 			stats := s.handlers.GetConnectionStats()
 			statsMsg := NewMessage("connection_stats", stats)
-
-			data, err := json.Marshal(statsMsg)
-			if err != nil {
-				log.Printf("Failed to marshal stats: %v", err)
-				continue
-			}
-
-			s.hub.Broadcast(data)
+			s.hub.BroadcastMessage(statsMsg)
 		}
 	}
 }
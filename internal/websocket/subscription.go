@@ -0,0 +1,116 @@
+package websocket
+
+import (
+	"encoding/json"
+	"sort"
+	"sync"
+)
+
+// controlMessageTypes are always delivered regardless of a client's subscription
+var controlMessageTypes = map[string]bool{
+	"connection_established": true,
+	"heartbeat":              true,
+	"subscription_updated":   true,
+	"subscription_error":     true,
+}
+
+// SubscriptionRequest is sent by clients to choose which broadcasts they receive, e.g.
+// {"subscribe":["discovery_progress"]} or {"job_id":"job-..."}
+type SubscriptionRequest struct {
+	Subscribe   []string `json:"subscribe,omitempty"`
+	Unsubscribe []string `json:"unsubscribe,omitempty"`
+	JobID       string   `json:"job_id,omitempty"`
+	Reset       bool     `json:"reset,omitempty"`
+}
+
+// Subscription holds a client's broadcast filters. An empty subscription receives
+// every event, so clients that never send a request keep the original behaviour.
+type Subscription struct {
+	mu         sync.RWMutex
+	eventTypes map[string]bool
+	jobs       map[string]bool
+}
+
+// newSubscription creates an empty subscription
+func newSubscription() *Subscription {
+	return &Subscription{
+		eventTypes: make(map[string]bool),
+		jobs:       make(map[string]bool),
+	}
+}
+
+// Apply updates the subscription from a client request
+func (s *Subscription) Apply(req SubscriptionRequest) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if req.Reset {
+		s.eventTypes = make(map[string]bool)
+		s.jobs = make(map[string]bool)
+	}
+	for _, eventType := range req.Subscribe {
+		if eventType != "" {
+			s.eventTypes[eventType] = true
+		}
+	}
+	for _, eventType := range req.Unsubscribe {
+		delete(s.eventTypes, eventType)
+	}
+	if req.JobID != "" {
+		s.jobs[req.JobID] = true
+	}
+}
+
+// Matches reports whether a broadcast of the given type and job should be delivered.
+// Event type filters apply to all messages; job filters only to messages tagged with a job.
+func (s *Subscription) Matches(messageType, jobID string) bool {
+	if controlMessageTypes[messageType] {
+		return true
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if len(s.eventTypes) > 0 && !s.eventTypes[messageType] {
+		return false
+	}
+	if len(s.jobs) > 0 && jobID != "" && !s.jobs[jobID] {
+		return false
+	}
+	return true
+}
+
+// Snapshot returns the subscribed event types and jobs, sorted
+func (s *Subscription) Snapshot() map[string][]string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return map[string][]string{
+		"event_types": sortedKeys(s.eventTypes),
+		"jobs":        sortedKeys(s.jobs),
+	}
+}
+
+// handleInbound applies a subscription request received from the client and acknowledges it
+func (c *Client) handleInbound(data []byte) {
+	var req SubscriptionRequest
+	if err := json.Unmarshal(data, &req); err != nil {
+		c.SendMessage(NewMessage("subscription_error", map[string]string{
+			"error": "invalid subscription request: " + err.Error(),
+		}))
+		return
+	}
+
+	c.subscription.Apply(req)
+	c.SendMessage(NewMessage("subscription_updated", c.subscription.Snapshot()))
+}
+
+// sortedKeys returns the keys of a set in sorted order
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -273,7 +273,7 @@ func TestWebSocketHub(t *testing.T) {
 
 	// Test broadcast to empty hub (should not block)
 	select {
-	case hub.broadcast <- []byte("test message"):
+	case hub.broadcast <- outbound{data: []byte("test message")}:
 		// Message sent successfully
 	case <-time.After(100 * time.Millisecond):
 		// Timeout - this is expected for empty hub
@@ -281,7 +281,7 @@ func TestWebSocketHub(t *testing.T) {
 
 	// Test broadcast to user (should not block)
 	select {
-	case hub.broadcast <- []byte("test message"):
+	case hub.broadcast <- outbound{data: []byte("test message")}:
 		// Message sent successfully
 	case <-time.After(100 * time.Millisecond):
 		// Timeout - this is expected for empty hub
@@ -366,3 +366,338 @@ func TestWebSocketMessage(t *testing.T) {
 		t.Error("Marshaled message should not be empty")
 	}
 }
+
+func TestNewProgressUpdate(t *testing.T) {
+	update := NewProgressUpdate("job-1", "remediating", 1, 4)
+	if update.Percentage != 25 {
+		t.Errorf("Expected 25%%, got %v", update.Percentage)
+	}
+	if update.Completed {
+		t.Error("Update should not be completed")
+	}
+
+	final := NewProgressUpdate("job-1", "remediating", 4, 4)
+	if !final.Completed {
+		t.Error("Final update should be completed")
+	}
+
+	if !strings.HasPrefix(NewJobID(), "job-") {
+		t.Error("Job IDs should start with job-")
+	}
+}
+
+func TestBroadcastProgressIncludesJobID(t *testing.T) {
+	service := NewService()
+	defer service.Stop(context.Background())
+
+	server := httptest.NewServer(http.HandlerFunc(service.GetHandlers().HandleWebSocket))
+	defer server.Close()
+
+	wsURL := "ws" + strings.TrimPrefix(server.URL, "http")
+	conn, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
+	if err != nil {
+		t.Fatalf("Failed to connect to WebSocket: %v", err)
+	}
+	defer conn.Close()
+
+	// Wait for the welcome message so the client is registered before broadcasting
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	if _, _, err := conn.ReadMessage(); err != nil {
+		t.Fatalf("Failed to read welcome message: %v", err)
+	}
+
+	service.BroadcastProgress(MessageTypeDiscoveryProgress, NewProgressUpdate("job-42", "started", 0, 2))
+
+	for {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			t.Fatalf("Failed to read progress message: %v", err)
+		}
+		// Queued messages may share a frame, separated by newlines
+		for _, line := range strings.Split(string(data), "\n") {
+			var message Message
+			if err := json.Unmarshal([]byte(line), &message); err != nil {
+				t.Fatalf("Failed to decode message: %v", err)
+			}
+			if message.Type != MessageTypeDiscoveryProgress {
+				continue
+			}
+			if message.JobID != "job-42" {
+				t.Errorf("Expected job-42, got %s", message.JobID)
+			}
+			return
+		}
+	}
+}
+
+func TestSubscriptionMatches(t *testing.T) {
+	subscription := newSubscription()
+	if !subscription.Matches("drift_detection", "") {
+		t.Error("Empty subscription should receive every event")
+	}
+
+	subscription.Apply(SubscriptionRequest{Subscribe: []string{"discovery_progress"}, JobID: "job-1"})
+	if !subscription.Matches("discovery_progress", "job-1") {
+		t.Error("Subscribed event for subscribed job should match")
+	}
+	if subscription.Matches("discovery_progress", "job-2") {
+		t.Error("Event for another job should not match")
+	}
+	if subscription.Matches("drift_detection", "") {
+		t.Error("Unsubscribed event type should not match")
+	}
+	if !subscription.Matches("heartbeat", "") {
+		t.Error("Control messages should always match")
+	}
+
+	subscription.Apply(SubscriptionRequest{Reset: true})
+	if !subscription.Matches("drift_detection", "") {
+		t.Error("Reset subscription should receive every event")
+	}
+}
+
+func TestSubscriptionFiltersBroadcasts(t *testing.T) {
+	service := NewService()
+	defer service.Stop(context.Background())
+
+	server := httptest.NewServer(http.HandlerFunc(service.GetHandlers().HandleWebSocket))
+	defer server.Close()
+
+	wsURL := "ws" + strings.TrimPrefix(server.URL, "http")
+	conn, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
+	if err != nil {
+		t.Fatalf("Failed to connect to WebSocket: %v", err)
+	}
+	defer conn.Close()
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+
+	if err := conn.WriteJSON(SubscriptionRequest{Subscribe: []string{MessageTypeRemediationProgress}}); err != nil {
+		t.Fatalf("Failed to send subscription: %v", err)
+	}
+
+	// Read until the subscription is acknowledged
+	readTypes := func() []string {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			t.Fatalf("Failed to read message: %v", err)
+		}
+		var types []string
+		for _, line := range strings.Split(string(data), "\n") {
+			var message Message
+			if err := json.Unmarshal([]byte(line), &message); err != nil {
+				t.Fatalf("Failed to decode message: %v", err)
+			}
+			types = append(types, message.Type)
+		}
+		return types
+	}
+	for acknowledged := false; !acknowledged; {
+		for _, messageType := range readTypes() {
+			acknowledged = acknowledged || messageType == "subscription_updated"
+		}
+	}
+
+	service.BroadcastProgress(MessageTypeDiscoveryProgress, NewProgressUpdate("job-1", "started", 0, 1))
+	service.BroadcastProgress(MessageTypeRemediationProgress, NewProgressUpdate("job-2", "started", 0, 1))
+
+	for {
+		for _, messageType := range readTypes() {
+			if messageType == MessageTypeDiscoveryProgress {
+				t.Fatal("Unsubscribed discovery progress should be filtered")
+			}
+			if messageType == MessageTypeRemediationProgress {
+				return
+			}
+		}
+	}
+}
+
+func TestJobProgressOnlyReachesItsUser(t *testing.T) {
+	service := NewService()
+	defer service.Stop(context.Background())
+
+	// Authenticate connections as the user named in the query
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := context.WithValue(r.Context(), "username", r.URL.Query().Get("user"))
+		service.GetHandlers().HandleWebSocket(w, r.WithContext(ctx))
+	}))
+	defer server.Close()
+
+	connect := func(user string) *websocket.Conn {
+		conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"?user="+user, nil)
+		if err != nil {
+			t.Fatalf("Failed to connect to WebSocket: %v", err)
+		}
+		conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+		if _, _, err := conn.ReadMessage(); err != nil {
+			t.Fatalf("Failed to read welcome message: %v", err)
+		}
+		return conn
+	}
+	alice := connect("alice")
+	defer alice.Close()
+	bob := connect("bob")
+	defer bob.Close()
+
+	// readJobs returns the job IDs of the progress messages in the next frame
+	readJobs := func(conn *websocket.Conn) []string {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			t.Fatalf("Failed to read message: %v", err)
+		}
+		var jobIDs []string
+		for _, line := range strings.Split(string(data), "\n") {
+			var message Message
+			if err := json.Unmarshal([]byte(line), &message); err != nil {
+				t.Fatalf("Failed to decode message: %v", err)
+			}
+			if message.Type == MessageTypeDiscoveryProgress {
+				jobIDs = append(jobIDs, message.JobID)
+			}
+		}
+		return jobIDs
+	}
+
+	update := NewProgressUpdate("job-alice", "started", 0, 1)
+	update.User = "alice"
+	service.BroadcastProgress(MessageTypeDiscoveryProgress, update)
+	service.BroadcastProgress(MessageTypeDiscoveryProgress, NewProgressUpdate("job-any", "started", 0, 1))
+
+	if jobIDs := readJobs(alice); len(jobIDs) == 0 || jobIDs[0] != "job-alice" {
+		t.Errorf("Expected alice to receive her job's progress first, got %v", jobIDs)
+	}
+	for received := false; !received; {
+		for _, jobID := range readJobs(bob) {
+			if jobID == "job-alice" {
+				t.Fatal("Another user's job progress should not be delivered")
+			}
+			received = received || jobID == "job-any"
+		}
+	}
+}
+
+func TestUnresponsiveClientIsRemoved(t *testing.T) {
+	service := NewService()
+	defer service.Stop(context.Background())
+	service.GetHub().SetKeepalive(20*time.Millisecond, 100*time.Millisecond)
+
+	server := httptest.NewServer(http.HandlerFunc(service.GetHandlers().HandleWebSocket))
+	defer server.Close()
+
+	// The client never reads, so it never answers pings
+	wsURL := "ws" + strings.TrimPrefix(server.URL, "http")
+	conn, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
+	if err != nil {
+		t.Fatalf("Failed to connect to WebSocket: %v", err)
+	}
+	defer conn.Close()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for service.GetHub().GetClientCount() == 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if service.GetHub().GetClientCount() != 1 {
+		t.Fatal("Client should be registered")
+	}
+
+	for service.GetHub().GetClientCount() != 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if count := service.GetHub().GetClientCount(); count != 0 {
+		t.Errorf("Unresponsive client should be removed after the pong timeout, %d still connected", count)
+	}
+}
+
+func TestOriginPolicy(t *testing.T) {
+	tests := []struct {
+		name    string
+		allowed []string
+		origin  string
+		host    string
+		want    bool
+	}{
+		{"no origin header", nil, "", "driftmgr.local:8080", true},
+		{"same origin", nil, "http://driftmgr.local:8080", "driftmgr.local:8080", true},
+		{"cross origin rejected by default", nil, "https://evil.example.com", "driftmgr.local:8080", false},
+		{"allowed origin", []string{"https://dashboard.example.com/"}, "https://Dashboard.example.com", "driftmgr.local:8080", true},
+		{"origin not in list", []string{"https://dashboard.example.com"}, "https://evil.example.com", "driftmgr.local:8080", false},
+		{"wildcard", []string{"*"}, "https://anything.example.com", "driftmgr.local:8080", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/ws", nil)
+			req.Host = tt.host
+			if tt.origin != "" {
+				req.Header.Set("Origin", tt.origin)
+			}
+			if got := NewOriginPolicy(tt.allowed).CheckOrigin(req); got != tt.want {
+				t.Errorf("CheckOrigin() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseAllowedOrigins(t *testing.T) {
+	origins := ParseAllowedOrigins(" https://a.example.com, ,https://b.example.com ")
+	if len(origins) != 2 || origins[0] != "https://a.example.com" || origins[1] != "https://b.example.com" {
+		t.Errorf("unexpected origins: %v", origins)
+	}
+}
+
+func TestWebSocketRejectsCrossOrigin(t *testing.T) {
+	service := NewService()
+	defer service.Stop(context.Background())
+
+	server := httptest.NewServer(http.HandlerFunc(service.GetHandlers().HandleWebSocket))
+	defer server.Close()
+
+	wsURL := "ws" + strings.TrimPrefix(server.URL, "http")
+	header := http.Header{"Origin": []string{"https://evil.example.com"}}
+	if _, resp, err := websocket.DefaultDialer.Dial(wsURL, header); err == nil {
+		t.Fatal("expected cross-origin connection to be rejected")
+	} else if resp == nil || resp.StatusCode != http.StatusForbidden {
+		t.Errorf("expected 403 for cross-origin connection, got %v", resp)
+	}
+
+	service.SetAllowedOrigins([]string{"https://evil.example.com"})
+	conn, _, err := websocket.DefaultDialer.Dial(wsURL, header)
+	if err != nil {
+		t.Fatalf("expected allowed origin to connect: %v", err)
+	}
+	conn.Close()
+}
+
+func TestSetKeepaliveFallsBackToDefaults(t *testing.T) {
+	hub := NewHub()
+
+	hub.SetKeepalive(0, 0)
+	if got := hub.keepaliveSettings(); got.pongWait != pongWait || got.pingPeriod != pingPeriod {
+		t.Errorf("expected the default keepalive for a zero pong timeout, got %+v", got)
+	}
+
+	hub.SetKeepalive(time.Minute, -time.Second)
+	if got := hub.keepaliveSettings(); got.pongWait != pongWait || got.pingPeriod != pingPeriod {
+		t.Errorf("expected the default keepalive for a negative pong timeout, got %+v", got)
+	}
+
+	hub.SetKeepalive(time.Nanosecond, time.Nanosecond)
+	if got := hub.keepaliveSettings(); got.pingPeriod <= 0 {
+		t.Errorf("expected a positive ping period, got %+v", got)
+	}
+
+	hub.SetKeepalive(time.Minute, 10*time.Second)
+	if got := hub.keepaliveSettings(); got.pongWait != 10*time.Second || got.pingPeriod != 9*time.Second {
+		t.Errorf("expected the ping period clamped below the pong timeout, got %+v", got)
+	}
+}
+
+func TestKeepaliveFromEnv(t *testing.T) {
+	t.Setenv(PingIntervalEnv, "15s")
+	t.Setenv(PongTimeoutEnv, "not-a-duration")
+
+	ping, pong := KeepaliveFromEnv()
+	if ping != 15*time.Second || pong != 0 {
+		t.Errorf("expected a 15s ping interval and no pong timeout, got %s and %s", ping, pong)
+	}
+}
@@ -0,0 +1,169 @@
+package models
+
+import "strings"
+
+// ResourceCategory is a provider-neutral grouping of resource types, so resources can be
+// counted across clouds: an aws_instance, azurerm_virtual_machine and google_compute_instance
+// are all compute
+type ResourceCategory string
+
+const (
+	CategoryCompute  ResourceCategory = "compute"
+	CategoryStorage  ResourceCategory = "storage"
+	CategoryNetwork  ResourceCategory = "network"
+	CategoryDatabase ResourceCategory = "database"
+	// CategoryIdentity covers identity and access, including keys and secrets
+	CategoryIdentity ResourceCategory = "identity"
+	// CategoryOther is every type not in the category tables, such as queues and log groups
+	CategoryOther ResourceCategory = "other"
+)
+
+// resourceCategories maps native resource types to their category. Types not listed here are
+// matched by resourceCategoryPrefixes; add new types to whichever is narrower.
+var resourceCategories = map[string]ResourceCategory{
+	// AWS
+	"aws_instance":                CategoryCompute,
+	"aws_launch_template":         CategoryCompute,
+	"aws_autoscaling_group":       CategoryCompute,
+	"aws_lambda_function":         CategoryCompute,
+	"aws_ecs_cluster":             CategoryCompute,
+	"aws_ecs_service":             CategoryCompute,
+	"aws_eks_cluster":             CategoryCompute,
+	"aws_s3_bucket":               CategoryStorage,
+	"aws_ebs_volume":              CategoryStorage,
+	"aws_efs_file_system":         CategoryStorage,
+	"aws_ecr_repository":          CategoryStorage,
+	"aws_vpc":                     CategoryNetwork,
+	"aws_subnet":                  CategoryNetwork,
+	"aws_security_group":          CategoryNetwork,
+	"aws_security_group_rule":     CategoryNetwork,
+	"aws_internet_gateway":        CategoryNetwork,
+	"aws_nat_gateway":             CategoryNetwork,
+	"aws_route_table":             CategoryNetwork,
+	"aws_eip":                     CategoryNetwork,
+	"aws_lb":                      CategoryNetwork,
+	"aws_lb_target_group":         CategoryNetwork,
+	"aws_route53_zone":            CategoryNetwork,
+	"aws_cloudfront_distribution": CategoryNetwork,
+	"aws_db_instance":             CategoryDatabase,
+	"aws_rds_cluster":             CategoryDatabase,
+	"aws_dynamodb_table":          CategoryDatabase,
+	"aws_elasticache_cluster":     CategoryDatabase,
+	"aws_kms_key":                 CategoryIdentity,
+	"aws_secretsmanager_secret":   CategoryIdentity,
+
+	// Azure
+	"azurerm_virtual_machine":            CategoryCompute,
+	"azurerm_linux_virtual_machine":      CategoryCompute,
+	"azurerm_windows_virtual_machine":    CategoryCompute,
+	"azurerm_app_service":                CategoryCompute,
+	"azurerm_function_app":               CategoryCompute,
+	"azurerm_kubernetes_cluster":         CategoryCompute,
+	"azurerm_storage_account":            CategoryStorage,
+	"azurerm_managed_disk":               CategoryStorage,
+	"azurerm_container_registry":         CategoryStorage,
+	"azurerm_virtual_network":            CategoryNetwork,
+	"azurerm_subnet":                     CategoryNetwork,
+	"azurerm_network_security_group":     CategoryNetwork,
+	"azurerm_network_interface":          CategoryNetwork,
+	"azurerm_public_ip":                  CategoryNetwork,
+	"azurerm_lb":                         CategoryNetwork,
+	"azurerm_application_gateway":        CategoryNetwork,
+	"azurerm_sql_server":                 CategoryDatabase,
+	"azurerm_sql_database":               CategoryDatabase,
+	"azurerm_cosmosdb_account":           CategoryDatabase,
+	"azurerm_postgresql_flexible_server": CategoryDatabase,
+	"azurerm_redis_cache":                CategoryDatabase,
+	"azurerm_key_vault":                  CategoryIdentity,
+	"azurerm_user_assigned_identity":     CategoryIdentity,
+	"azurerm_role_assignment":            CategoryIdentity,
+
+	// Google Cloud
+	"google_compute_instance":        CategoryCompute,
+	"google_container_cluster":       CategoryCompute,
+	"google_cloud_function":          CategoryCompute,
+	"google_cloudfunctions_function": CategoryCompute,
+	"google_cloud_run_service":       CategoryCompute,
+	"google_compute_disk":            CategoryStorage,
+	"google_storage_bucket":          CategoryStorage,
+	"google_compute_network":         CategoryNetwork,
+	"google_compute_subnetwork":      CategoryNetwork,
+	"google_compute_firewall":        CategoryNetwork,
+	"google_compute_address":         CategoryNetwork,
+	"google_sql_database_instance":   CategoryDatabase,
+	"google_sql_database":            CategoryDatabase,
+	"google_redis_instance":          CategoryDatabase,
+	"google_bigquery_dataset":        CategoryDatabase,
+	"google_service_account":         CategoryIdentity,
+	"google_kms_key_ring":            CategoryIdentity,
+	"google_kms_crypto_key":          CategoryIdentity,
+
+	// DigitalOcean
+	"digitalocean_droplet":          CategoryCompute,
+	"digitalocean_volume":           CategoryStorage,
+	"digitalocean_loadbalancer":     CategoryNetwork,
+	"digitalocean_database_cluster": CategoryDatabase,
+
+	// Kubernetes
+	"k8s_deployment": CategoryCompute,
+	"k8s_service":    CategoryNetwork,
+}
+
+// resourceCategoryPrefixes categorizes types missing from resourceCategories by prefix, such
+// as the services fast discovery names from ARNs and Azure Resource Manager types. The first
+// matching prefix wins, so narrower prefixes come first.
+var resourceCategoryPrefixes = []struct {
+	prefix   string
+	category ResourceCategory
+}{
+	{"aws_iam_", CategoryIdentity},
+	{"aws_db_", CategoryDatabase},
+	{"aws_rds_", CategoryDatabase},
+	{"aws_s3_", CategoryStorage},
+	{"aws_vpc_", CategoryNetwork},
+	{"aws_lb_", CategoryNetwork},
+	{"aws_route53_", CategoryNetwork},
+	{"azurerm_mssql_", CategoryDatabase},
+	{"azurerm_mysql_", CategoryDatabase},
+	{"azurerm_postgresql_", CategoryDatabase},
+	{"azurerm_storage_", CategoryStorage},
+	{"google_sql_", CategoryDatabase},
+	{"google_storage_", CategoryStorage},
+	{"google_project_iam_", CategoryIdentity},
+	{"microsoft.compute/disks", CategoryStorage},
+	{"microsoft.compute/", CategoryCompute},
+	{"microsoft.web/", CategoryCompute},
+	{"microsoft.containerservice/", CategoryCompute},
+	{"microsoft.storage/", CategoryStorage},
+	{"microsoft.network/", CategoryNetwork},
+	{"microsoft.sql/", CategoryDatabase},
+	{"microsoft.dbforpostgresql/", CategoryDatabase},
+	{"microsoft.dbformysql/", CategoryDatabase},
+	{"microsoft.documentdb/", CategoryDatabase},
+	{"microsoft.keyvault/", CategoryIdentity},
+	{"microsoft.managedidentity/", CategoryIdentity},
+}
+
+// CategorizeResourceType returns the category of a native resource type, CategoryOther when
+// it is in neither category table
+func CategorizeResourceType(resourceType string) ResourceCategory {
+	if category, ok := resourceCategories[resourceType]; ok {
+		return category
+	}
+	lower := strings.ToLower(resourceType)
+	for _, rule := range resourceCategoryPrefixes {
+		if strings.HasPrefix(lower, rule.prefix) {
+			return rule.category
+		}
+	}
+	return CategoryOther
+}
+
+// CategorizeResources sets the category of each resource that lacks one from its type
+func CategorizeResources(resources []Resource) {
+	for i := range resources {
+		if resources[i].Category == "" {
+			resources[i].Category = CategorizeResourceType(resources[i].Type)
+		}
+	}
+}
@@ -1,6 +1,8 @@
 package models
 
 import (
+	"encoding/json"
+	"fmt"
 	"time"
 )
 
@@ -47,14 +49,15 @@ type Resource struct {
 	ID           string                 `json:"id"`
 	Name         string                 `json:"name"`
 	Type         string                 `json:"type"`
+	Category     ResourceCategory       `json:"category,omitempty"` // provider-neutral grouping of Type
 	Provider     string                 `json:"provider"`
 	Region       string                 `json:"region"`
 	AccountID    string                 `json:"account_id,omitempty"`
 	AccountName  string                 `json:"account_name,omitempty"`
-	Tags         interface{}            `json:"tags,omitempty"`  // Can be map[string]string or []string
+	Tags         map[string]string      `json:"tags,omitempty"`
 	State        interface{}            `json:"state,omitempty"` // Can be string or map[string]interface{}
 	Status       string                 `json:"status,omitempty"`
-	Created      time.Time              `json:"created,omitempty"`
+	Created      time.Time              `json:"created,omitempty"` // zero when the provider does not report it
 	Updated      time.Time              `json:"updated,omitempty"`
 	CreatedAt    time.Time              `json:"created_at,omitempty"`
 	LastModified time.Time              `json:"last_modified,omitempty"`
@@ -65,40 +68,142 @@ type Resource struct {
 	CostEstimate *CostEstimate          `json:"cost_estimate,omitempty"`
 }
 
-// GetTagsAsMap returns tags as a map[string]string
-func (r *Resource) GetTagsAsMap() map[string]string {
-	if r.Tags == nil {
-		return make(map[string]string)
+// UnmarshalJSON decodes a resource, coercing tags that were serialized as a map with
+// non-string values, a list of keys or a list of {"Key": ..., "Value": ...} pairs
+func (r *Resource) UnmarshalJSON(data []byte) error {
+	type resourceFields Resource
+	decoded := struct {
+		*resourceFields
+		Tags interface{} `json:"tags,omitempty"`
+	}{resourceFields: (*resourceFields)(r)}
+
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
 	}
+	r.Tags = coerceTags(decoded.Tags)
+	return nil
+}
+
+// MarshalJSON encodes a resource with age_unknown set when its creation time is unknown, so
+// age-based reports can tell a resource without one from a resource created at the zero time
+func (r Resource) MarshalJSON() ([]byte, error) {
+	type resourceFields Resource
+	return json.Marshal(struct {
+		resourceFields
+		AgeUnknown bool `json:"age_unknown,omitempty"`
+	}{resourceFields: resourceFields(r), AgeUnknown: r.AgeUnknown()})
+}
+
+// CreationTime returns when the resource was created, from Created or else CreatedAt, and
+// whether it is known. Discovery leaves both zero when the provider's API does not report a
+// creation time rather than guessing one.
+func (r *Resource) CreationTime() (time.Time, bool) {
+	if !r.Created.IsZero() {
+		return r.Created, true
+	}
+	if !r.CreatedAt.IsZero() {
+		return r.CreatedAt, true
+	}
+	return time.Time{}, false
+}
 
-	// If tags are already a map[string]string, return them
-	if tags, ok := r.Tags.(map[string]string); ok {
-		return tags
+// Age returns how long before now the resource was created, and false when its creation
+// time is unknown
+func (r *Resource) Age(now time.Time) (time.Duration, bool) {
+	created, ok := r.CreationTime()
+	if !ok {
+		return 0, false
 	}
+	return now.Sub(created), true
+}
+
+// AgeUnknown reports whether the resource's creation time is unknown
+func (r *Resource) AgeUnknown() bool {
+	_, ok := r.CreationTime()
+	return !ok
+}
 
-	// If tags are map[string]interface{}, convert them
-	if tags, ok := r.Tags.(map[string]interface{}); ok {
-		result := make(map[string]string)
-		for k, v := range tags {
-			if str, ok := v.(string); ok {
-				result[k] = str
-			} else {
-				result[k] = ""
+// coerceTags converts decoded JSON tags into a string map
+func coerceTags(value interface{}) map[string]string {
+	switch tags := value.(type) {
+	case map[string]interface{}:
+		result := make(map[string]string, len(tags))
+		for key, tagValue := range tags {
+			result[key] = tagString(tagValue)
+		}
+		return result
+	case []interface{}:
+		result := make(map[string]string, len(tags))
+		for _, tag := range tags {
+			switch t := tag.(type) {
+			case string:
+				result[t] = ""
+			case map[string]interface{}:
+				if key, ok := t["Key"].(string); ok {
+					result[key] = tagString(t["Value"])
+				}
 			}
 		}
 		return result
+	default:
+		return nil
 	}
+}
 
-	// If tags are []string, convert to map with empty values
-	if tags, ok := r.Tags.([]string); ok {
-		result := make(map[string]string)
-		for _, tag := range tags {
-			result[tag] = ""
+// tagString renders a decoded tag value as a string, with null as empty
+func tagString(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// GetTagsAsMap returns the resource's tags, never nil
+func (r *Resource) GetTagsAsMap() map[string]string {
+	if r.Tags == nil {
+		return make(map[string]string)
+	}
+	return r.Tags
+}
+
+// MatchesTags reports whether the resource has every tag in tags with the given value
+// and every key in keys with any value. Empty filters match all resources.
+func (r *Resource) MatchesTags(tags map[string]string, keys []string) bool {
+	if len(tags) == 0 && len(keys) == 0 {
+		return true
+	}
+
+	resourceTags := r.GetTagsAsMap()
+	for key, value := range tags {
+		if actual, ok := resourceTags[key]; !ok || actual != value {
+			return false
+		}
+	}
+	for _, key := range keys {
+		if _, ok := resourceTags[key]; !ok {
+			return false
 		}
-		return result
 	}
+	return true
+}
 
-	return make(map[string]string)
+// FilterResourcesByTags returns the resources matching the tag filters
+func FilterResourcesByTags(resources []Resource, tags map[string]string, keys []string) []Resource {
+	if len(tags) == 0 && len(keys) == 0 {
+		return resources
+	}
+
+	filtered := make([]Resource, 0, len(resources))
+	for i := range resources {
+		if resources[i].MatchesTags(tags, keys) {
+			filtered = append(filtered, resources[i])
+		}
+	}
+	return filtered
 }
 
 // CostEstimate provides cost estimation information for a resource
@@ -137,13 +242,38 @@ type DriftChange struct {
 	Description string      `json:"description,omitempty"`
 }
 
-// DiscoveryResult represents the result of a resource discovery
+// DiscoveryResult represents the result of a resource discovery. Errors lists the services
+// that could not be discovered, whose resources are missing from Resources.
 type DiscoveryResult struct {
 	Resources []Resource       `json:"resources"`
+	Errors    []DiscoveryError `json:"errors,omitempty"`
 	Summary   DiscoverySummary `json:"summary"`
 	Timestamp time.Time        `json:"timestamp"`
 }
 
+// ServicePermissions are the permissions discovery needs to read one cloud service. Service
+// matches the Service of the DiscoveryError reported when the service cannot be discovered,
+// and Purpose says which kind of discovery needs it when not every scan does.
+type ServicePermissions struct {
+	Service     string   `json:"service"`
+	Purpose     string   `json:"purpose,omitempty"`
+	Permissions []string `json:"permissions"`
+}
+
+// ParseServicePermissions parses a provider's list of the permissions discovery needs
+func ParseServicePermissions(data []byte) ([]ServicePermissions, error) {
+	var permissions []ServicePermissions
+	if err := json.Unmarshal(data, &permissions); err != nil {
+		return nil, fmt.Errorf("invalid discovery permissions: %w", err)
+	}
+	for _, service := range permissions {
+		if service.Service == "" || len(service.Permissions) == 0 {
+			return nil, fmt.Errorf("invalid discovery permissions: service %q needs a name and permissions", service.Service)
+		}
+	}
+	return permissions, nil
+}
+
 // DiscoverySummary provides a summary of discovered resources
 type DiscoverySummary struct {
 	TotalResources int            `json:"total_resources"`
@@ -333,10 +463,12 @@ type TerraformResourceInstance struct {
 
 // DiscoveryRequest represents a resource discovery request
 type DiscoveryRequest struct {
-	Provider  string   `json:"provider"`
-	Providers []string `json:"providers,omitempty"`
-	Regions   []string `json:"regions"`
-	Account   string   `json:"account"`
+	Provider     string            `json:"provider"`
+	Providers    []string          `json:"providers,omitempty"`
+	Regions      []string          `json:"regions"`
+	Account      string            `json:"account"`
+	Tags         map[string]string `json:"tags,omitempty"`           // only resources with these tag values
+	TagKeyExists []string          `json:"tag_key_exists,omitempty"` // only resources with these tag keys
 }
 
 // DiscoveryResponse represents a resource discovery response
@@ -808,10 +940,32 @@ type DiscoveryResults struct {
 	Metadata          map[string]interface{}   `json:"metadata,omitempty"`
 }
 
-// DiscoveryError represents an error during discovery
+// DiscoveryError represents an error during discovery. Service is the cloud service that
+// failed, such as EC2, and Code the provider's error code, such as AccessDenied, when known.
 type DiscoveryError struct {
-	ResourceType string `json:"resource_type,omitempty"`
-	Region       string `json:"region,omitempty"`
-	Error        string `json:"error"`
+	Service      string    `json:"service,omitempty"`
+	ResourceType string    `json:"resource_type,omitempty"`
+	Region       string    `json:"region,omitempty"`
+	Code         string    `json:"code,omitempty"`
+	Error        string    `json:"error"`
 	Timestamp    time.Time `json:"timestamp"`
 }
+
+// String describes the failure, such as "EC2 in eu-west-1 failed: AccessDenied"
+func (e DiscoveryError) String() string {
+	subject := e.Service
+	if subject == "" {
+		subject = e.ResourceType
+	}
+	if subject == "" {
+		subject = "Discovery"
+	}
+	if e.Region != "" {
+		subject += " in " + e.Region
+	}
+	reason := e.Code
+	if reason == "" {
+		reason = e.Error
+	}
+	return subject + " failed: " + reason
+}
@@ -0,0 +1,205 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestFilterResourcesByTags(t *testing.T) {
+	resources := []Resource{
+		{ID: "web-prod", Tags: map[string]string{"Environment": "production", "Owner": "web"}},
+		{ID: "web-dev", Tags: map[string]string{"Environment": "dev", "Owner": "web"}},
+		{ID: "db-prod", Tags: map[string]string{"Environment": "production"}},
+		{ID: "untagged"},
+	}
+
+	tests := []struct {
+		name string
+		tags map[string]string
+		keys []string
+		want []string
+	}{
+		{name: "no filters", want: []string{"web-prod", "web-dev", "db-prod", "untagged"}},
+		{name: "tag value", tags: map[string]string{"Environment": "production"}, want: []string{"web-prod", "db-prod"}},
+		{name: "tag key", keys: []string{"Owner"}, want: []string{"web-prod", "web-dev"}},
+		{
+			name: "value and key",
+			tags: map[string]string{"Environment": "production"},
+			keys: []string{"Owner"},
+			want: []string{"web-prod"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filtered := FilterResourcesByTags(resources, tt.tags, tt.keys)
+			if len(filtered) != len(tt.want) {
+				t.Fatalf("expected %v, got %d resources", tt.want, len(filtered))
+			}
+			for i, resource := range filtered {
+				if resource.ID != tt.want[i] {
+					t.Errorf("expected %s at %d, got %s", tt.want[i], i, resource.ID)
+				}
+			}
+		})
+	}
+}
+
+func TestResourceTagsJSONRoundTrip(t *testing.T) {
+	resource := Resource{
+		ID:   "i-0abc",
+		Type: "aws_instance",
+		Tags: map[string]string{"Environment": "production", "Owner": "web"},
+	}
+
+	data, err := json.Marshal(resource)
+	if err != nil {
+		t.Fatalf("marshal failed: %v", err)
+	}
+	var decoded Resource
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("unmarshal failed: %v", err)
+	}
+
+	if decoded.ID != "i-0abc" || decoded.Type != "aws_instance" {
+		t.Errorf("expected the other fields to survive, got %+v", decoded)
+	}
+	if len(decoded.Tags) != 2 || decoded.Tags["Environment"] != "production" || decoded.Tags["Owner"] != "web" {
+		t.Errorf("expected tags to survive the round trip, got %v", decoded.Tags)
+	}
+	if !decoded.MatchesTags(map[string]string{"Environment": "production"}, nil) {
+		t.Error("expected the decoded resource to match its tags")
+	}
+}
+
+func TestResourceAge(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	launched := now.Add(-90 * 24 * time.Hour)
+
+	for _, resource := range []Resource{{Created: launched}, {CreatedAt: launched}} {
+		if age, ok := resource.Age(now); !ok || age != 90*24*time.Hour {
+			t.Errorf("expected an age of 90 days, got %v, %v", age, ok)
+		}
+		if resource.AgeUnknown() {
+			t.Error("expected a resource with a creation time to have a known age")
+		}
+	}
+
+	unknown := Resource{ID: "sg-0abc", Updated: now}
+	if age, ok := unknown.Age(now); ok || age != 0 {
+		t.Errorf("expected an unknown age, got %v, %v", age, ok)
+	}
+	if !unknown.AgeUnknown() {
+		t.Error("expected a resource without a creation time to have an unknown age")
+	}
+}
+
+func TestResourceMarshalAgeUnknown(t *testing.T) {
+	for _, tt := range []struct {
+		resource Resource
+		want     bool
+	}{
+		{Resource{ID: "bucket-1", Created: time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC)}, false},
+		{Resource{ID: "sg-0abc"}, true},
+	} {
+		data, err := json.Marshal(tt.resource)
+		if err != nil {
+			t.Fatalf("marshal failed: %v", err)
+		}
+		var fields map[string]interface{}
+		if err := json.Unmarshal(data, &fields); err != nil {
+			t.Fatalf("unmarshal failed: %v", err)
+		}
+		if fields["id"] != tt.resource.ID {
+			t.Errorf("expected the resource's fields, got %s", data)
+		}
+		if unknown, _ := fields["age_unknown"].(bool); unknown != tt.want {
+			t.Errorf("expected age_unknown %v for %s, got %s", tt.want, tt.resource.ID, data)
+		}
+	}
+}
+
+func TestResourceUnmarshalCoercesTags(t *testing.T) {
+	tests := []struct {
+		name string
+		json string
+		want map[string]string
+	}{
+		{"non-string values", `{"tags":{"Team":"web","Replicas":3,"Public":false}}`, map[string]string{"Team": "web", "Replicas": "3", "Public": "false"}},
+		{"key list", `{"tags":["web","prod"]}`, map[string]string{"web": "", "prod": ""}},
+		{"key value pairs", `{"tags":[{"Key":"Team","Value":"web"}]}`, map[string]string{"Team": "web"}},
+		{"missing", `{"id":"bucket-1"}`, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var resource Resource
+			if err := json.Unmarshal([]byte(tt.json), &resource); err != nil {
+				t.Fatalf("unmarshal failed: %v", err)
+			}
+			if len(resource.Tags) != len(tt.want) {
+				t.Fatalf("expected %v, got %v", tt.want, resource.Tags)
+			}
+			for key, value := range tt.want {
+				if resource.Tags[key] != value {
+					t.Errorf("expected %s=%q, got %q", key, value, resource.Tags[key])
+				}
+			}
+		})
+	}
+}
+
+func TestDiscoveryErrorString(t *testing.T) {
+	for _, test := range []struct {
+		err  DiscoveryError
+		want string
+	}{
+		{DiscoveryError{Service: "EC2", Region: "eu-west-1", Code: "AccessDenied", Error: "api error AccessDenied"}, "EC2 in eu-west-1 failed: AccessDenied"},
+		{DiscoveryError{ResourceType: "aws_security_group_rule", Error: "throttled"}, "aws_security_group_rule failed: throttled"},
+		{DiscoveryError{Region: "us-east-1", Error: "no credentials"}, "Discovery in us-east-1 failed: no credentials"},
+	} {
+		if got := test.err.String(); got != test.want {
+			t.Errorf("String() = %q, want %q", got, test.want)
+		}
+	}
+}
+
+func TestParseServicePermissions(t *testing.T) {
+	permissions, err := ParseServicePermissions([]byte(`[{"service": "EC2", "permissions": ["ec2:DescribeInstances"]}]`))
+	if err != nil || len(permissions) != 1 || permissions[0].Permissions[0] != "ec2:DescribeInstances" {
+		t.Errorf("expected the EC2 permissions, got %+v, %v", permissions, err)
+	}
+	for _, data := range []string{`{}`, `[{"service": "EC2"}]`, `[{"permissions": ["s3:ListAllMyBuckets"]}]`} {
+		if _, err := ParseServicePermissions([]byte(data)); err == nil {
+			t.Errorf("expected %s to be refused", data)
+		}
+	}
+}
+
+func TestCategorizeResourceType(t *testing.T) {
+	for resourceType, want := range map[string]ResourceCategory{
+		"aws_instance":                              CategoryCompute,
+		"azurerm_virtual_machine":                   CategoryCompute,
+		"google_compute_instance":                   CategoryCompute,
+		"google_compute_firewall":                   CategoryNetwork,
+		"aws_s3_bucket_policy":                      CategoryStorage,
+		"aws_iam_role":                              CategoryIdentity,
+		"Microsoft.Compute/virtualMachines":         CategoryCompute,
+		"Microsoft.Compute/disks":                   CategoryStorage,
+		"Microsoft.DBforPostgreSQL/flexibleServers": CategoryDatabase,
+		"aws_sqs_queue":                             CategoryOther,
+		"k8s_deployment":                            CategoryCompute,
+		"k8s_configmap":                             CategoryOther,
+	} {
+		if got := CategorizeResourceType(resourceType); got != want {
+			t.Errorf("expected %s to be %s, got %s", resourceType, want, got)
+		}
+	}
+
+	resources := []Resource{{Type: "aws_db_instance"}, {Type: "aws_instance", Category: CategoryOther}}
+	CategorizeResources(resources)
+	if resources[0].Category != CategoryDatabase || resources[1].Category != CategoryOther {
+		t.Errorf("expected only uncategorized resources to be categorized, got %+v", resources)
+	}
+}
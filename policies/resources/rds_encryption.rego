@@ -0,0 +1,29 @@
+package driftmgr.resources.rds
+
+# RDS instances must encrypt their storage
+
+violations[violation] {
+    input.resource.type == "aws_db_instance"
+    input.resource.attributes.storage_encrypted == false
+    violation := {
+        "rule": "rds_storage_encrypted",
+        "message": sprintf("RDS instance %s does not encrypt its storage", [input.resource.id]),
+        "severity": "high",
+        "resource": input.resource.id,
+        "remediation": "Restore the instance from an encrypted snapshot copy with storage encryption enabled"
+    }
+}
+
+# Databases must not be reachable from the internet
+
+violations[violation] {
+    input.resource.type == "aws_db_instance"
+    input.resource.attributes.publicly_accessible == true
+    violation := {
+        "rule": "rds_not_public",
+        "message": sprintf("RDS instance %s is publicly accessible", [input.resource.id]),
+        "severity": "high",
+        "resource": input.resource.id,
+        "remediation": "Set publicly_accessible to false and reach the database through a private subnet"
+    }
+}
@@ -0,0 +1,23 @@
+package driftmgr.resources.s3
+
+# S3 buckets must block public ACLs and public bucket policies
+
+violations[violation] {
+    input.resource.type == "aws_s3_bucket"
+    public_access_allowed
+    violation := {
+        "rule": "s3_no_public_access",
+        "message": sprintf("S3 bucket %s does not block public access", [input.resource.id]),
+        "severity": "high",
+        "resource": input.resource.id,
+        "remediation": "Enable S3 Block Public Access (BlockPublicAcls and BlockPublicPolicy) on the bucket"
+    }
+}
+
+public_access_allowed {
+    input.resource.attributes.block_public_acls == false
+}
+
+public_access_allowed {
+    input.resource.attributes.block_public_policy == false
+}
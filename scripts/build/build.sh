@@ -3,8 +3,10 @@
 set -e
 
 VERSION=${VERSION:-$(git describe --tags --always --dirty)}
-BUILD_TIME=$(date -u '+%Y-%m-%d_%H:%M:%S')
-LDFLAGS="-X main.Version=$VERSION -X main.BuildTime=$BUILD_TIME"
+COMMIT=$(git rev-parse --short HEAD)
+BUILD_TIME=$(date -u '+%Y-%m-%dT%H:%M:%SZ')
+VERSION_PKG=github.com/catherinevee/driftmgr/internal/version
+LDFLAGS="-X $VERSION_PKG.Version=$VERSION -X $VERSION_PKG.Commit=$COMMIT -X $VERSION_PKG.BuildDate=$BUILD_TIME"
 
 echo "Building DriftMgr version $VERSION..."
 
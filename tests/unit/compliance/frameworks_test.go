@@ -0,0 +1,69 @@
+package compliance
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/catherinevee/driftmgr/internal/compliance"
+	"github.com/catherinevee/driftmgr/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadFrameworks(t *testing.T) {
+	tempDir := t.TempDir()
+	definition := `{
+  "id": "custom",
+  "name": "Custom Framework",
+  "controls": [{"id": "C-1", "title": "Buckets are private", "policies": ["s3_no_public_access"]}]
+}`
+	require.NoError(t, os.WriteFile(filepath.Join(tempDir, "custom.json"), []byte(definition), 0644))
+
+	frameworks, err := compliance.LoadFrameworks(tempDir)
+	require.NoError(t, err)
+	require.Contains(t, frameworks, "custom")
+	assert.Equal(t, []string{"s3_no_public_access"}, frameworks["custom"].Controls[0].Policies)
+
+	// The bundled frameworks load too
+	bundled, err := compliance.LoadFrameworks(filepath.Join("..", "..", "..", "policies", "frameworks"))
+	require.NoError(t, err)
+	assert.Contains(t, bundled, "cis-aws")
+	assert.Contains(t, bundled, "pci-dss")
+}
+
+func TestBuildScorecard(t *testing.T) {
+	engine := compliance.NewOPAEngine(compliance.OPAConfig{
+		LocalPolicies: filepath.Join("..", "..", "..", "policies", "resources"),
+	})
+	require.NoError(t, engine.LoadPolicies(context.Background()))
+
+	resources := []models.Resource{
+		{ID: "public-db", Type: "aws_db_instance", Provider: "aws", Attributes: map[string]interface{}{"storage_encrypted": true, "publicly_accessible": true}},
+		{ID: "private-db", Type: "aws_db_instance", Provider: "aws", Attributes: map[string]interface{}{"storage_encrypted": true, "publicly_accessible": false}},
+	}
+	results, err := engine.EvaluateResources(context.Background(), resources, nil)
+	require.NoError(t, err)
+
+	framework := compliance.Framework{
+		ID:   "test",
+		Name: "Test Framework",
+		Controls: []compliance.FrameworkControl{
+			{ID: "1", Policies: []string{"rds_storage_encrypted"}, ResourceTypes: []string{"aws_db_instance"}},
+			{ID: "2", Policies: []string{"rds_not_public"}, ResourceTypes: []string{"aws_db_instance"}},
+			{ID: "3", Policies: []string{"s3_no_public_access"}, ResourceTypes: []string{"aws_s3_bucket"}},
+		},
+	}
+	scorecard := compliance.BuildScorecard(framework, results)
+
+	require.Len(t, scorecard.Controls, 3)
+	assert.Equal(t, compliance.ControlStatusPassed, scorecard.Controls[0].Status)
+	assert.Equal(t, compliance.ControlStatusFailed, scorecard.Controls[1].Status)
+	assert.Equal(t, []string{"public-db"}, scorecard.Controls[1].FailingResources)
+	assert.Equal(t, compliance.ControlStatusNotAssessed, scorecard.Controls[2].Status)
+	assert.Equal(t, 1, scorecard.Passed)
+	assert.Equal(t, 1, scorecard.Failed)
+	assert.Equal(t, 1, scorecard.NotAssessed)
+	assert.Equal(t, 50.0, scorecard.Score)
+}
@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/catherinevee/driftmgr/internal/compliance"
+	"github.com/catherinevee/driftmgr/pkg/models"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -402,3 +403,32 @@ func TestOPAEngine_EmptyDirectory(t *testing.T) {
 	policies := engine.ListPolicies()
 	assert.Len(t, policies, 0)
 }
+
+func TestOPAEngine_EvaluateResources(t *testing.T) {
+	// Evaluate the bundled resource policies
+	engine := compliance.NewOPAEngine(compliance.OPAConfig{
+		LocalPolicies: filepath.Join("..", "..", "..", "policies", "resources"),
+	})
+	require.NoError(t, engine.LoadPolicies(context.Background()))
+	assert.Equal(t, []string{"driftmgr.resources.rds", "driftmgr.resources.s3"}, engine.PolicyPackages())
+
+	resources := []models.Resource{
+		{ID: "public-bucket", Type: "aws_s3_bucket", Provider: "aws", Attributes: map[string]interface{}{"block_public_acls": false, "block_public_policy": true}},
+		{ID: "private-bucket", Type: "aws_s3_bucket", Provider: "aws", Attributes: map[string]interface{}{"block_public_acls": true, "block_public_policy": true}},
+		{ID: "orders", Type: "aws_db_instance", Provider: "aws", Attributes: map[string]interface{}{"storage_encrypted": false, "publicly_accessible": true}},
+	}
+
+	results, err := engine.EvaluateResources(context.Background(), resources, nil)
+	require.NoError(t, err)
+	require.Len(t, results, 3)
+
+	assert.False(t, results[0].Passed)
+	require.Len(t, results[0].Violations, 1)
+	assert.Equal(t, "s3_no_public_access", results[0].Violations[0].Rule)
+	assert.Equal(t, "driftmgr.resources.s3", results[0].Violations[0].Details["policy"])
+
+	assert.True(t, results[1].Passed)
+
+	assert.False(t, results[2].Passed)
+	assert.Len(t, results[2].Violations, 2)
+}
@@ -698,7 +698,52 @@ class FormManager {
 
     async createRemediationJob(data) {
         console.log('Creating remediation job:', data);
-        await new Promise(resolve => setTimeout(resolve, 1000));
+
+        // Preview the batch before running anything destructive; the server only runs a
+        // batch carrying its preview's token
+        const previewResponse = await fetch('/api/v1/remediate/batch/preview', {
+            method: 'POST',
+            headers: { 'Content-Type': 'application/json' },
+            body: JSON.stringify({ drift_ids: data.resources })
+        });
+        const previewResult = await previewResponse.json();
+        if (!previewResponse.ok || !previewResult.success) {
+            throw new Error(previewResult.error?.message || 'Failed to preview remediation');
+        }
+
+        const preview = previewResult.data;
+        const group = (label, entries) => entries.length === 0 ? '' :
+            `${label}:\n` + entries
+                .map(entry => `  ${entry.resource} (${entry.actions.map(action => action.type).join(', ')})`)
+                .join('\n') + '\n';
+        const radius = preview.blast_radius;
+        const proceed = confirm(
+            `Risk: ${preview.risk_level}\n\n` +
+            group('Recreated or removed', preview.destructive) +
+            group('Updated', preview.safe) +
+            group('Imported', preview.import) +
+            `\nDependent resources affected: ${radius.dependents.length}` +
+            (radius.disrupted.length > 0 ? `, ${radius.disrupted.length} by a recreated resource` : '') +
+            `\n\nRemediate ${radius.resources} resource(s)?`
+        );
+        if (!proceed) {
+            throw new Error('Remediation cancelled');
+        }
+
+        // Tag the job so this page only shows its own progress updates
+        const jobId = `job-${crypto.randomUUID()}`;
+        window.driftMgrWS?.trackJob(jobId);
+
+        const response = await fetch('/api/v1/remediate/batch', {
+            method: 'POST',
+            headers: { 'Content-Type': 'application/json' },
+            body: JSON.stringify({ drift_ids: data.resources, job_id: jobId, preview_token: preview.preview_token })
+        });
+        const result = await response.json();
+        if (!response.ok || !result.success) {
+            throw new Error(result.error?.message || 'Failed to execute remediation');
+        }
+        return result.data;
     }
 
     // Refresh methods (mock implementations)
@@ -233,7 +233,8 @@ class DriftMgrVisualizations {
                 labels: ['Security', 'Performance', 'Availability', 'Cost Optimization', 'Compliance', 'Automation'],
                 datasets: [{
                     label: 'Current Score',
-                    data: [85, 92, 88, 75, 90, 82],
+                    // Security and Compliance are filled in from the compliance scorecards
+                    data: [0, 92, 88, 75, 0, 82],
                     borderColor: '#3b82f6',
                     backgroundColor: 'rgba(59, 130, 246, 0.2)',
                     pointBackgroundColor: '#3b82f6',
@@ -274,6 +275,8 @@ class DriftMgrVisualizations {
                 }
             }
         });
+
+        this.loadComplianceScores();
     }
 
     // Remediation Success Chart
@@ -417,7 +420,7 @@ class DriftMgrVisualizations {
                 });
                 break;
             case 'healthScore':
-                chart.data.datasets[0].data = chart.data.datasets[0].data.map(() => Math.floor(Math.random() * 20) + 80);
+                this.loadComplianceScores();
                 break;
             case 'remediationSuccess':
                 const total = 100;
@@ -487,6 +490,32 @@ class DriftMgrVisualizations {
         }
     }
 
+    // Computes the Security score from the CIS AWS Foundations scorecard and the Compliance
+    // score as the average of the assessed framework scorecards
+    async loadComplianceScores() {
+        const frameworks = ['cis-aws', 'pci-dss'];
+        const scorecards = await Promise.all(frameworks.map(async (framework) => {
+            try {
+                const response = await fetch(`/api/v1/compliance/${framework}`);
+                return response.ok ? await response.json() : null;
+            } catch (error) {
+                console.error(`Failed to load ${framework} scorecard:`, error);
+                return null;
+            }
+        }));
+
+        const assessed = scorecards.filter(scorecard => scorecard && scorecard.passed + scorecard.failed > 0);
+        if (!this.charts.healthScore || assessed.length === 0) return;
+
+        const data = this.charts.healthScore.data.datasets[0].data;
+        const cis = assessed.find(scorecard => scorecard.framework === 'cis-aws');
+        if (cis) {
+            data[0] = Math.round(cis.score);
+        }
+        data[4] = Math.round(assessed.reduce((sum, scorecard) => sum + scorecard.score, 0) / assessed.length);
+        this.charts.healthScore.update();
+    }
+
     updateHealthScore(newScore) {
         if (this.charts.healthScore) {
             const chart = this.charts.healthScore;
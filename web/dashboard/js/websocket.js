@@ -12,6 +12,7 @@ class DriftMgrWebSocket {
         this.messageHandlers = new Map();
         this.connectionCallbacks = [];
         this.disconnectionCallbacks = [];
+        this.trackedJobs = new Set();
         
         // Bind methods
         this.connect = this.connect.bind(this);
@@ -172,6 +173,13 @@ class DriftMgrWebSocket {
             case 'connection_stats':
                 this.handleConnectionStats(message);
                 break;
+            case 'discovery_progress':
+            case 'remediation_progress':
+                this.handleProgress(message);
+                break;
+            case 'discovery_batch':
+                this.handleDiscoveryBatch(message);
+                break;
             default:
                 console.log('Unknown message type:', message.type);
         }
@@ -187,6 +195,24 @@ class DriftMgrWebSocket {
         this.messageHandlers.get(messageType).push(handler);
     }
 
+    /**
+     * Limit broadcasts to the given event types and/or job; an empty call resets to all events
+     */
+    subscribe(eventTypes = [], jobId = null) {
+        const request = eventTypes.length || jobId ? { subscribe: eventTypes } : { reset: true };
+        if (jobId) {
+            request.job_id = jobId;
+        }
+        return this.send(request);
+    }
+
+    /**
+     * Track a job started from this page so only its progress is shown
+     */
+    trackJob(jobId) {
+        this.trackedJobs.add(jobId);
+    }
+
     /**
      * Register a connection callback
      */
@@ -311,6 +337,52 @@ class DriftMgrWebSocket {
         }
     }
 
+    /**
+     * Handle job progress updates, ignoring jobs started elsewhere
+     */
+    handleProgress(message) {
+        const jobId = message.job_id;
+        if (jobId && !this.trackedJobs.has(jobId)) {
+            return;
+        }
+
+        const update = message.data;
+        const title = message.type === 'discovery_progress' ? 'Discovery' : 'Remediation';
+        const text = update.message || `${update.stage}: ${Math.round(update.percentage)}%`;
+        this.showNotification(title, text, update.error ? 'warning' : 'info');
+        if (update.errors && update.errors.length > 0) {
+            const failures = update.errors.map(error => this.describeDiscoveryError(error));
+            this.showNotification('Resources Missing', failures.join('<br>'), 'warning');
+        }
+
+        if (update.completed) {
+            this.trackedJobs.delete(jobId);
+        }
+    }
+
+    /**
+     * Describe a service discovery could not scan, such as "EC2 in eu-west-1 failed: AccessDenied"
+     */
+    describeDiscoveryError(error) {
+        let subject = error.service || error.resource_type || 'Discovery';
+        if (error.region) {
+            subject += ` in ${error.region}`;
+        }
+        return `${subject} failed: ${error.code || error.error}`;
+    }
+
+    /**
+     * Pass resources a tracked discovery has found so far to the page, so tables can fill
+     * in region by region instead of waiting for the whole scan
+     */
+    handleDiscoveryBatch(message) {
+        const jobId = message.job_id;
+        if (jobId && !this.trackedJobs.has(jobId)) {
+            return;
+        }
+        window.dispatchEvent(new CustomEvent('driftmgr:discovery-batch', { detail: message.data }));
+    }
+
     /**
      * Handle system alerts
      */
@@ -346,6 +346,28 @@ class DriftMgrDashboard {
             this.charts.cost.data.datasets[0].data = newData;
             this.charts.cost.update();
         }
+
+        if (this.charts.drift) {
+            this.updateDriftChart();
+        }
+    }
+
+    async updateDriftChart() {
+        // Load the drift trend recorded by the server
+        try {
+            const response = await fetch('/api/v1/drift/history?days=30');
+            if (!response.ok) {
+                return;
+            }
+            const result = await response.json();
+            const series = (result.data && result.data.series) || [];
+
+            this.charts.drift.data.labels = series.map(point => point.date.substring(5, 10));
+            this.charts.drift.data.datasets[0].data = series.map(point => point.new_drifts);
+            this.charts.drift.update();
+        } catch (error) {
+            console.error('Failed to load drift history:', error);
+        }
     }
 
     updateRecentActivity() {